@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/saaga0h/jeeves-platform/internal/backfill"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+func main() {
+	// Backfill-specific flags are registered before LoadFromFlags parses the command line
+	input := pflag.String("input", "", "Historical data to replay (MQTT capture JSON/JSONL or Home Assistant history CSV)")
+	format := pflag.String("format", "", "Input format: mqtt or csv (default: detected from file extension)")
+	window := pflag.Duration("window", 24*time.Hour, "Consolidation window (at most 24h)")
+	consolidate := pflag.Bool("consolidate", true, "Trigger behavior consolidation after each window")
+	discover := pflag.Bool("discover", true, "Trigger pattern discovery after the replay")
+	waitTimeout := pflag.Duration("wait-timeout", 10*time.Minute, "How long to wait for each consolidation/discovery to complete")
+
+	// Load configuration with hierarchy: defaults → env → flags
+	cfg := config.NewConfig()
+	cfg.ServiceName = "backfill"
+	cfg.LoadFromEnv()
+	cfg.LoadFromFlags()
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "Configuration error: --input is required")
+		os.Exit(1)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	levels := logging.NewLevels("backfill", parseLogLevel(cfg.LogLevel))
+	logger := logging.New(os.Stdout, cfg.LogFormat, levels)
+	if err := levels.Apply(cfg.LogLevels); err != nil {
+		logger.Warn("Ignoring invalid log level overrides", "log_levels", cfg.LogLevels, "error", err)
+	}
+	slog.SetDefault(logger)
+
+	logger.Info("Starting J.E.E.V.E.S. Backfill",
+		"input", *input,
+		"format", *format,
+		"window", *window,
+		"mqtt_broker", cfg.MQTTAddress(),
+		"redis_host", cfg.RedisAddress())
+
+	events, err := backfill.ReadFile(*input, *format)
+	if err != nil {
+		logger.Error("Failed to read input", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("Loaded historical events", "count", len(events))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("Shutdown signal received, stopping backfill")
+		cancel()
+	}()
+
+	mqttClient := mqtt.NewClient(cfg, logger)
+	if err := mqttClient.Connect(ctx); err != nil {
+		logger.Error("Failed to connect to MQTT", "error", err)
+		os.Exit(1)
+	}
+	defer mqttClient.Disconnect()
+
+	redisClient := redis.NewClient(cfg, logger)
+	if err := redisClient.Ping(ctx); err != nil {
+		logger.Error("Failed to connect to Redis", "error", err)
+		os.Exit(1)
+	}
+	defer redisClient.Close()
+
+	replayer := backfill.NewReplayer(cfg, redisClient, mqttClient, backfill.Options{
+		Window:      *window,
+		Consolidate: *consolidate,
+		Discover:    *discover,
+		MinAnchors:  cfg.PatternMinAnchorsForDiscovery,
+		WaitTimeout: *waitTimeout,
+	}, logger)
+
+	summary, err := replayer.Run(ctx, events)
+	if err != nil {
+		logger.Error("Backfill failed", "error", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Backfill complete: %d events stored, %d skipped, %d windows, %d consolidated, discovery=%t\n",
+		summary.EventsStored, summary.EventsSkipped, summary.Windows, summary.Consolidations, summary.Discovered)
+}
+
+// parseLogLevel converts string log level to slog.Level
+func parseLogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/saaga0h/jeeves-platform/internal/behavior"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/distance"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/portability"
+	"github.com/saaga0h/jeeves-platform/pkg/chaos"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 	"github.com/saaga0h/jeeves-platform/pkg/postgres"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
@@ -27,9 +32,11 @@ func main() {
 		os.Exit(1)
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
+	levels := logging.NewLevels("behavior", logging.ParseLevel(cfg.LogLevel))
+	logger := logging.New(os.Stdout, cfg.LogFormat, levels)
+	if err := levels.Apply(cfg.LogLevels); err != nil {
+		logger.Warn("Ignoring invalid log level overrides", "log_levels", cfg.LogLevels, "error", err)
+	}
 	slog.SetDefault(logger)
 
 	logger.Info("Starting Behavior Agent",
@@ -43,22 +50,55 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Fault injection for e2e resilience tests, nil unless enabled
+	injector := chaos.NewInjector(cfg, logger)
+	http.DefaultTransport = injector.WrapTransport(http.DefaultTransport)
+
 	// Initialize clients
-	mqttClient := mqtt.NewClient(cfg, logger)
-	redisClient := redis.NewClient(cfg, logger)
+	mqttClient := injector.WrapMQTT(mqtt.NewClient(cfg, logger))
+	redisClient := injector.WrapRedis(redis.NewClient(cfg, logger))
 
 	// Initialize and connect postgres client
 	pgClient := postgres.NewClient(cfg, logger)
 	if err := pgClient.Connect(ctx); err != nil {
 		logger.Error("Failed to connect to postgres", "error", err)
 		os.Exit(1)
-	} // Create behavior agent
+	}
+
+	// One-shot pattern export/import, then exit
+	if cfg.ExportPatternsPath != "" || cfg.ImportPatternsPath != "" {
+		if err := runPatternTransfer(ctx, cfg, pgClient, logger); err != nil {
+			logger.Error("Pattern transfer failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// One-shot distance weight fit, then exit
+	if cfg.FitDistanceWeights {
+		if err := runWeightFit(ctx, pgClient, logger); err != nil {
+			logger.Error("Distance weight fit failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Create behavior agent
 	agent, err := behavior.NewAgent(mqttClient, redisClient, pgClient, cfg, logger)
 	if err != nil {
 		logger.Error("Failed to create agent", "error", err)
 		os.Exit(1)
 	}
 
+	// One-shot anchor re-embedding, then exit
+	if cfg.ReembedAnchors {
+		if err := agent.ReembedAnchors(ctx, cfg.PatternDiscoveryBatchSize); err != nil {
+			logger.Error("Anchor re-embedding failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Start agent
 	agentErr := make(chan error, 1)
 	go func() {
@@ -79,3 +119,58 @@ func main() {
 	agent.Stop()
 	logger.Info("Behavior agent stopped")
 }
+
+// runPatternTransfer exports learned patterns to, or imports them from, a JSON bundle
+func runPatternTransfer(ctx context.Context, cfg *config.Config, pgClient postgres.Client, logger *slog.Logger) error {
+	pg, ok := pgClient.(*postgres.PostgresClient)
+	if !ok || pg.DB() == nil {
+		return fmt.Errorf("postgres client does not expose a database connection")
+	}
+	exporter := portability.NewExporter(pg.DB(), logger)
+
+	if cfg.ExportPatternsPath != "" {
+		bundle, err := exporter.Export(ctx)
+		if err != nil {
+			return err
+		}
+		if err := portability.WriteFile(cfg.ExportPatternsPath, bundle); err != nil {
+			return err
+		}
+		logger.Info("Patterns exported",
+			"path", cfg.ExportPatternsPath,
+			"behavioral_patterns", len(bundle.BehavioralPatterns),
+			"learned_patterns", len(bundle.LearnedPatterns))
+	}
+
+	if cfg.ImportPatternsPath != "" {
+		bundle, err := portability.ReadFile(cfg.ImportPatternsPath)
+		if err != nil {
+			return err
+		}
+		if _, err := exporter.Import(ctx, bundle); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runWeightFit fits structured distance block weights to accumulated LLM distances
+func runWeightFit(ctx context.Context, pgClient postgres.Client, logger *slog.Logger) error {
+	pg, ok := pgClient.(*postgres.PostgresClient)
+	if !ok || pg.DB() == nil {
+		return fmt.Errorf("postgres client does not expose a database connection")
+	}
+
+	fitted, saved, err := distance.OptimizeBlockWeights(ctx, pg.DB(), 50, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Distance weight fit complete",
+		"saved", saved,
+		"samples", fitted.SampleCount,
+		"mse", fitted.MSE,
+		"baseline_mse", fitted.BaselineMSE)
+	return nil
+}
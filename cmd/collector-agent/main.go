@@ -11,8 +11,10 @@ import (
 	"time"
 
 	"github.com/saaga0h/jeeves-platform/internal/collector"
+	"github.com/saaga0h/jeeves-platform/pkg/chaos"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/health"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
@@ -32,9 +34,11 @@ func main() {
 
 	// Set up structured logging
 	logLevel := parseLogLevel(cfg.LogLevel)
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+	levels := logging.NewLevels("collector", logLevel)
+	logger := logging.New(os.Stdout, cfg.LogFormat, levels)
+	if err := levels.Apply(cfg.LogLevels); err != nil {
+		logger.Warn("Ignoring invalid log level overrides", "log_levels", cfg.LogLevels, "error", err)
+	}
 	slog.SetDefault(logger)
 
 	logger.Info("Starting J.E.E.V.E.S. Collector Agent",
@@ -52,14 +56,21 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Fault injection for e2e resilience tests, nil unless enabled
+	injector := chaos.NewInjector(cfg, logger)
+
 	// Initialize MQTT client
-	mqttClient := mqtt.NewClient(cfg, logger)
+	mqttClient := injector.WrapMQTT(mqtt.NewClient(cfg, logger))
 
 	// Initialize Redis client
-	redisClient := redis.NewClient(cfg, logger)
+	redisClient := injector.WrapRedis(redis.NewClient(cfg, logger))
 
 	// Create collector agent
-	agent := collector.NewAgent(mqttClient, redisClient, cfg, logger)
+	agent, err := collector.NewAgent(mqttClient, redisClient, cfg, logger)
+	if err != nil {
+		logger.Error("Failed to create collector agent", "error", err)
+		os.Exit(1)
+	}
 
 	// Start health check server
 	healthChecker := health.NewChecker(mqttClient, redisClient, logger)
@@ -11,8 +11,10 @@ import (
 	"time"
 
 	"github.com/saaga0h/jeeves-platform/internal/illuminance"
+	"github.com/saaga0h/jeeves-platform/pkg/chaos"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/health"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
@@ -32,9 +34,11 @@ func main() {
 
 	// Set up structured logging
 	logLevel := parseLogLevel(cfg.LogLevel)
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+	levels := logging.NewLevels("illuminance", logLevel)
+	logger := logging.New(os.Stdout, cfg.LogFormat, levels)
+	if err := levels.Apply(cfg.LogLevels); err != nil {
+		logger.Warn("Ignoring invalid log level overrides", "log_levels", cfg.LogLevels, "error", err)
+	}
 	slog.SetDefault(logger)
 
 	logger.Info("Starting J.E.E.V.E.S. Illuminance Agent",
@@ -55,11 +59,14 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Fault injection for e2e resilience tests, nil unless enabled
+	injector := chaos.NewInjector(cfg, logger)
+
 	// Initialize MQTT client
-	mqttClient := mqtt.NewClient(cfg, logger)
+	mqttClient := injector.WrapMQTT(mqtt.NewClient(cfg, logger))
 
 	// Initialize Redis client
-	redisClient := redis.NewClient(cfg, logger)
+	redisClient := injector.WrapRedis(redis.NewClient(cfg, logger))
 
 	// Create illuminance agent
 	agent := illuminance.NewAgent(mqttClient, redisClient, cfg, logger)
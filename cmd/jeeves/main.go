@@ -0,0 +1,276 @@
+// Command jeeves runs several agents as goroutines of one process, for small
+// deployments (e.g. a Raspberry Pi) where a container per agent is too heavy.
+// Agents are selected with --run-{behavior,illuminance,occupancy,light,observer}
+// (JEEVES_RUN_*); the collector and bridges still run as their own binaries.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior"
+	"github.com/saaga0h/jeeves-platform/internal/illuminance"
+	"github.com/saaga0h/jeeves-platform/internal/light"
+	"github.com/saaga0h/jeeves-platform/internal/observer"
+	"github.com/saaga0h/jeeves-platform/internal/occupancy"
+	"github.com/saaga0h/jeeves-platform/pkg/auth"
+	"github.com/saaga0h/jeeves-platform/pkg/chaos"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/health"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// agent is a supervised agent: start blocks or returns after setup, stop
+// releases it
+type agent struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func() error
+}
+
+// sharedRedis is the Redis client handed to agents; agents close their
+// client on Stop, the supervisor closes the real one last
+type sharedRedis struct {
+	redis.Client
+}
+
+func (sharedRedis) Close() error { return nil }
+
+// sharedPostgres is the Postgres client handed to agents, disconnected by the
+// supervisor only
+type sharedPostgres struct {
+	*postgres.PostgresClient
+}
+
+func (sharedPostgres) Disconnect() error { return nil }
+
+func main() {
+	cfg := config.NewConfig()
+	cfg.ServiceName = "jeeves"
+	cfg.LoadFromEnv()
+	cfg.LoadFromFlags()
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	levels := logging.NewLevels("jeeves", logging.ParseLevel(cfg.LogLevel))
+	logger := logging.New(os.Stdout, cfg.LogFormat, levels)
+	if err := levels.Apply(cfg.LogLevels); err != nil {
+		logger.Warn("Ignoring invalid log level overrides", "log_levels", cfg.LogLevels, "error", err)
+	}
+	slog.SetDefault(logger)
+
+	logger.Info("Starting J.E.E.V.E.S. supervisor",
+		"behavior", cfg.RunBehavior,
+		"illuminance", cfg.RunIlluminance,
+		"occupancy", cfg.RunOccupancy,
+		"light", cfg.RunLight,
+		"observer", cfg.RunObserver,
+		"mqtt_broker", cfg.MQTTAddress(),
+		"redis_host", cfg.RedisAddress())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Fault injection for e2e resilience tests, nil unless enabled
+	injector := chaos.NewInjector(cfg, logger)
+	http.DefaultTransport = injector.WrapTransport(http.DefaultTransport)
+
+	// Redis and Postgres pools are shared; every agent gets its own MQTT
+	// connection, as topic handlers of one connection would collide
+	redisClient := injector.WrapRedis(redis.NewClient(cfg, logger))
+	defer redisClient.Close()
+	shared := sharedRedis{redisClient}
+
+	var pg *postgres.PostgresClient
+	var db *sql.DB
+	if cfg.RunBehavior || cfg.RunObserver ||
+		cfg.RunOccupancy && cfg.OccupancyTrainingMode ||
+		cfg.RunLight && (cfg.LightPatternScenes || cfg.LightPreferenceLearning || cfg.LightScenes) {
+		pg = postgres.NewClient(cfg, logger).(*postgres.PostgresClient)
+		if err := pg.Connect(ctx); err != nil {
+			logger.Error("Failed to connect to postgres", "error", err)
+			os.Exit(1)
+		}
+		defer pg.Disconnect()
+		db = pg.DB()
+	}
+
+	// /health plus agent APIs that would have run on an agent's health port
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", health.NewChecker(nil, redisClient, logger).HandlerFunc())
+
+	agents, obs, err := buildAgents(cfg, logger, injector, shared, pg, db, mux)
+	if err != nil {
+		logger.Error("Failed to create agents", "error", err)
+		os.Exit(1)
+	}
+	if len(agents) == 0 {
+		logger.Error("No agents enabled")
+		os.Exit(1)
+	}
+
+	// The observer serves /health itself when it shares the health port. The
+	// scene routes authenticate themselves.
+	var healthServer *http.Server
+	if obs != nil && cfg.ObserverPort == cfg.HealthPort {
+		for _, pattern := range []string{"/health", "/api/scenes", "/api/scenes/"} {
+			obs.HandlePublic(pattern, mux)
+		}
+	} else {
+		healthServer = startHealthServer(cfg.HealthPort, mux, logger)
+	}
+
+	agentErr := make(chan error, len(agents))
+	for _, a := range agents {
+		go func(a agent) {
+			if err := a.start(ctx); err != nil {
+				agentErr <- fmt.Errorf("%s: %w", a.name, err)
+			}
+		}(a)
+	}
+
+	select {
+	case <-sigChan:
+		logger.Info("Shutdown signal received (SIGTERM/SIGINT)")
+	case err := <-agentErr:
+		logger.Error("Agent failed", "error", err)
+	}
+
+	logger.Info("Initiating graceful shutdown")
+	cancel()
+
+	// Reverse start order: the behavior agent, started first, stops last
+	for i := len(agents) - 1; i >= 0; i-- {
+		if err := agents[i].stop(); err != nil {
+			logger.Error("Error stopping agent", "agent", agents[i].name, "error", err)
+		}
+	}
+
+	if healthServer != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := healthServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Error shutting down health server", "error", err)
+		}
+	}
+
+	logger.Info("Supervisor shutdown complete")
+}
+
+// agentConfig copies cfg for one agent, so MQTT client IDs and log-level
+// addressing match a separately deployed agent
+func agentConfig(cfg *config.Config, service string) *config.Config {
+	c := *cfg
+	c.ServiceName = service
+	if c.MQTTClientID != "" {
+		c.MQTTClientID += "-" + service
+	}
+	return &c
+}
+
+// buildAgents creates the enabled agents in start order, and returns the
+// observer when it is one of them
+func buildAgents(cfg *config.Config, logger *slog.Logger, injector *chaos.Injector, redisClient redis.Client, pg *postgres.PostgresClient, db *sql.DB, mux *http.ServeMux) ([]agent, *observer.Server, error) {
+	var agents []agent
+	var obs *observer.Server
+	newMQTT := func(c *config.Config, l *slog.Logger) mqtt.Client {
+		return injector.WrapMQTT(mqtt.NewClient(c, l))
+	}
+
+	if cfg.RunBehavior {
+		c := agentConfig(cfg, "behavior-agent")
+		l := logger.With("component", "behavior")
+		a, err := behavior.NewAgent(newMQTT(c, l), redisClient, sharedPostgres{pg}, c, l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create behavior agent: %w", err)
+		}
+		agents = append(agents, agent{name: "behavior", start: a.Start, stop: a.Stop})
+	}
+
+	if cfg.RunIlluminance {
+		c := agentConfig(cfg, "illuminance-agent")
+		l := logger.With("component", "illuminance")
+		a := illuminance.NewAgent(newMQTT(c, l), redisClient, c, l)
+		agents = append(agents, agent{name: "illuminance", start: a.Start, stop: a.Stop})
+	}
+
+	if cfg.RunOccupancy {
+		c := agentConfig(cfg, "occupancy-agent")
+		l := logger.With("component", "occupancy")
+		a := occupancy.NewAgent(newMQTT(c, l), redisClient, c, l)
+		if cfg.OccupancyTrainingMode {
+			a.SetLabelStore(occupancy.NewLabelStore(db))
+		}
+		agents = append(agents, agent{name: "occupancy", start: a.Start, stop: a.Stop})
+	}
+
+	if cfg.RunLight {
+		c := agentConfig(cfg, "light-agent")
+		l := logger.With("component", "light")
+		a := light.NewAgent(newMQTT(c, l), redisClient, c, l)
+		if db != nil {
+			store := light.NewPreferenceStore(db)
+			if cfg.LightPatternScenes {
+				a.SetScenePreferences(store)
+			}
+			if cfg.LightPreferenceLearning {
+				a.SetLearnedPreferences(store)
+			}
+			if cfg.LightScenes {
+				authn, err := auth.New(c, l)
+				if err != nil {
+					return nil, nil, fmt.Errorf("invalid API auth configuration: %w", err)
+				}
+				a.SetSceneManager(light.NewSceneManager(light.NewSceneStore(db)))
+				light.NewSceneAPI(a, l).Register(mux, authn)
+			}
+		}
+		agents = append(agents, agent{name: "light", start: a.Start, stop: a.Stop})
+	}
+
+	if cfg.RunObserver {
+		c := agentConfig(cfg, "observer-agent")
+		l := logger.With("component", "observer")
+		server, err := observer.New(c, sharedPostgres{pg}, newMQTT(c, l), redisClient, l)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create observer: %w", err)
+		}
+		obs = server
+		// Run returns once ctx is cancelled
+		agents = append(agents, agent{name: "observer", start: server.Run, stop: func() error { return nil }})
+	}
+
+	return agents, obs, nil
+}
+
+func startHealthServer(port int, mux *http.ServeMux, logger *slog.Logger) *http.Server {
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", port),
+		Handler: mux,
+	}
+
+	go func() {
+		logger.Info("Starting health check server", "port", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Health server error", "error", err)
+		}
+	}()
+
+	return server
+}
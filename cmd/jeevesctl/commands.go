@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/internal/observer"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// jobPollInterval is how often --wait polls a running job
+const jobPollInterval = 2 * time.Second
+
+func newFlagSet(name string) *pflag.FlagSet {
+	return pflag.NewFlagSet("jeevesctl "+name, pflag.ContinueOnError)
+}
+
+func runConsolidate(ctx context.Context, c *client, args []string) error {
+	var req behavior.ConsolidateJobRequest
+	var wait bool
+	flags := newFlagSet("consolidate")
+	flags.IntVar(&req.LookbackHours, "lookback-hours", 0, "Hours of episodes to consolidate (0 = agent default)")
+	flags.StringVar(&req.Location, "location", "", "Only consolidate this location")
+	flags.BoolVar(&wait, "wait", false, "Wait for the job to finish")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	return c.startJob(ctx, "/api/admin/consolidate", req, wait)
+}
+
+func runDiscover(ctx context.Context, c *client, args []string) error {
+	var req behavior.DiscoverJobRequest
+	var wait bool
+	flags := newFlagSet("discover")
+	flags.IntVar(&req.MinAnchors, "min-anchors", 0, "Minimum anchors to run discovery (0 = agent default)")
+	flags.IntVar(&req.LookbackHours, "lookback-hours", 0, "Hours of anchors to cluster (0 = agent default)")
+	flags.BoolVar(&wait, "wait", false, "Wait for the job to finish")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	return c.startJob(ctx, "/api/admin/discover", req, wait)
+}
+
+// startJob posts an admin job and, with wait, polls it until it finishes
+func (c *client) startJob(ctx context.Context, path string, req interface{}, wait bool) error {
+	var started behavior.JobStartedResponse
+	if err := c.do(ctx, http.MethodPost, c.behaviorURL+path, req, &started); err != nil {
+		return err
+	}
+	if !c.jsonOutput {
+		fmt.Printf("Started job %s\n", started.JobID)
+	}
+	if !wait {
+		return nil
+	}
+
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		var job behavior.Job
+		if err := c.getJSON(ctx, c.behaviorURL+"/api/jobs/"+started.JobID.String(), &job); err != nil {
+			return err
+		}
+		if job.Status == behavior.JobRunning {
+			continue
+		}
+		if !c.jsonOutput {
+			printJob(&job)
+		}
+		if job.Status == behavior.JobFailed {
+			return fmt.Errorf("job %s failed: %s", job.ID, job.Error)
+		}
+		return nil
+	}
+}
+
+// getJSON is a GET through do that leaves --json printing to the caller's
+// final response only
+func (c *client) getJSON(ctx context.Context, url string, out interface{}) error {
+	jsonOutput := c.jsonOutput
+	c.jsonOutput = false
+	defer func() { c.jsonOutput = jsonOutput }()
+	if err := c.do(ctx, http.MethodGet, url, nil, out); err != nil {
+		return err
+	}
+	if jsonOutput {
+		payload, _ := json.Marshal(out)
+		fmt.Println(string(payload))
+	}
+	return nil
+}
+
+func runJob(ctx context.Context, c *client, args []string) error {
+	flags := newFlagSet("job")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		var jobs []behavior.Job
+		if err := c.do(ctx, http.MethodGet, c.behaviorURL+"/api/jobs", nil, &jobs); err != nil {
+			return err
+		}
+		if c.jsonOutput {
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tKIND\tSTATUS\tCREATED\tFINISHED")
+		for _, job := range jobs {
+			finished := "-"
+			if job.FinishedAt != nil {
+				finished = job.FinishedAt.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", job.ID, job.Kind, job.Status,
+				job.CreatedAt.Local().Format(time.DateTime), finished)
+		}
+		return w.Flush()
+	}
+
+	var job behavior.Job
+	if err := c.do(ctx, http.MethodGet, c.behaviorURL+"/api/jobs/"+flags.Arg(0), nil, &job); err != nil {
+		return err
+	}
+	if !c.jsonOutput {
+		printJob(&job)
+	}
+	return nil
+}
+
+func printJob(job *behavior.Job) {
+	fmt.Printf("Job %s (%s): %s\n", job.ID, job.Kind, job.Status)
+	for _, phase := range job.Phases {
+		detail := ""
+		if len(phase.Detail) > 0 {
+			payload, _ := json.Marshal(phase.Detail)
+			detail = " " + string(payload)
+		}
+		fmt.Printf("  %s  %s%s\n", phase.StartedAt.Local().Format(time.TimeOnly), phase.Name, detail)
+	}
+	if job.FinishedAt != nil {
+		fmt.Printf("Finished after %s\n", job.FinishedAt.Sub(job.CreatedAt).Round(time.Millisecond))
+	}
+	if job.Error != "" {
+		fmt.Printf("Error: %s\n", job.Error)
+	}
+}
+
+func runPatterns(ctx context.Context, c *client, args []string) error {
+	var archived bool
+	flags := newFlagSet("patterns")
+	flags.BoolVar(&archived, "archived", false, "Include archived patterns")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	url := c.observerURL + "/api/patterns"
+	if archived {
+		url += "?include_archived=true"
+	}
+	var patterns []observer.PatternData
+	if err := c.do(ctx, http.MethodGet, url, nil, &patterns); err != nil {
+		return err
+	}
+	if c.jsonOutput {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTYPE\tSOURCE\tWEIGHT\tANCHORS\tACCEPTANCE\tLAST SEEN\tLOCATIONS")
+	for _, p := range patterns {
+		acceptance := "-"
+		if p.AcceptanceRate != nil {
+			acceptance = fmt.Sprintf("%.0f%%", *p.AcceptanceRate*100)
+		}
+		name := p.Name
+		if p.ArchivedAt != nil {
+			name += " (archived)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.2f\t%d\t%s\t%s\t%s\n", p.ID, name, p.PatternType, p.Source, p.Weight,
+			p.AnchorCount, acceptance, p.LastSeen.Local().Format(time.DateTime), strings.Join(p.Locations, ","))
+	}
+	return w.Flush()
+}
+
+func runEpisode(ctx context.Context, c *client, args []string) error {
+	flags := newFlagSet("episode")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: jeevesctl episode <id>")
+	}
+
+	var episode observer.EpisodeDetail
+	if err := c.do(ctx, http.MethodGet, c.observerURL+"/api/episodes/"+flags.Arg(0), nil, &episode); err != nil {
+		return err
+	}
+	if c.jsonOutput {
+		return nil
+	}
+
+	fmt.Printf("Episode %s (%s)\n", episode.ID, episode.Kind)
+	if episode.PatternType != "" {
+		fmt.Printf("Type:      %s\n", episode.PatternType)
+	}
+	fmt.Printf("Locations: %s\n", strings.Join(episode.Locations, ", "))
+	end := "open"
+	if episode.EndTime != nil {
+		end = episode.EndTime.Local().Format(time.DateTime)
+	}
+	fmt.Printf("Time:      %s - %s\n", episode.StartTime.Local().Format(time.DateTime), end)
+	if episode.MacroID != "" {
+		fmt.Printf("Macro:     %s\n", episode.MacroID)
+	}
+	if len(episode.MicroIDs) > 0 {
+		fmt.Printf("Micro:     %s\n", strings.Join(episode.MicroIDs, ", "))
+	}
+	if episode.Summary != "" {
+		fmt.Printf("Summary:   %s\n", episode.Summary)
+	}
+	for _, a := range episode.Annotations {
+		fmt.Printf("Annotation: %s %s %s (%s, %s)\n", a.Type, a.Label, a.Note, a.Author,
+			a.CreatedAt.Local().Format(time.DateTime))
+	}
+	if len(episode.Document) > 0 {
+		doc, err := json.MarshalIndent(episode.Document, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+		fmt.Printf("\n%s\n", doc)
+	}
+	return nil
+}
+
+func runTail(ctx context.Context, c *client, args []string) error {
+	var topic string
+	flags := newFlagSet("tail")
+	flags.StringVar(&topic, "topic", "automation/behavior/#", "MQTT topic filter")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// A fixed client ID would disconnect the agent configured with it
+	cfg := *c.cfg
+	cfg.MQTTClientID = ""
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	client := mqtt.NewClient(&cfg, logger)
+	if err := client.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	defer client.Disconnect()
+
+	messages := make(chan mqtt.Message, 64)
+	err := client.Subscribe(topic, 0, func(msg mqtt.Message) {
+		select {
+		case messages <- msg:
+		default:
+			fmt.Fprintf(os.Stderr, "dropped message on %s\n", msg.Topic())
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-messages:
+			fmt.Printf("%s %s %s\n", time.Now().Format(time.TimeOnly), msg.Topic(), msg.Payload())
+		}
+	}
+}
+
+func runPurge(ctx context.Context, c *client, args []string) error {
+	var filter storage.PurgeFilter
+	var since, until string
+	var yes bool
+	flags := newFlagSet("purge")
+	flags.StringVar(&filter.Location, "location", "", "Only purge this location")
+	flags.StringVar(&since, "since", "", "Purge from this time, inclusive (RFC3339)")
+	flags.StringVar(&until, "until", "", "Purge up to this time, exclusive (RFC3339)")
+	flags.BoolVar(&yes, "yes", false, "Do not ask for confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	if since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	if !yes && !confirm(os.Stdin, describePurge(filter)) {
+		return fmt.Errorf("aborted")
+	}
+
+	var resp behavior.PurgeResponse
+	if err := c.do(ctx, http.MethodPost, c.behaviorURL+"/api/purge", filter, &resp); err != nil {
+		return err
+	}
+	if c.jsonOutput || resp.Result == nil {
+		return nil
+	}
+
+	r := resp.Result
+	fmt.Printf("Deleted %d anchors, %d distances, %d observations, %d episodes, %d macro episodes, %d annotations\n",
+		r.Anchors, r.Distances, r.Observations, r.Episodes, r.MacroEpisodes, r.Annotations)
+	fmt.Printf("Patterns: %d deleted, %d resized\n", r.PatternsDeleted, r.PatternsResized)
+	return nil
+}
+
+func describePurge(f storage.PurgeFilter) string {
+	var parts []string
+	if f.Location != "" {
+		parts = append(parts, "in "+f.Location)
+	}
+	if !f.Since.IsZero() {
+		parts = append(parts, "from "+f.Since.Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		parts = append(parts, "until "+f.Until.Format(time.RFC3339))
+	}
+	return "Delete all behavior data " + strings.Join(parts, " ") + "?"
+}
+
+// confirm asks a yes/no question on stderr
+func confirm(in io.Reader, question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func runHealth(ctx context.Context, c *client, args []string) error {
+	flags := newFlagSet("health")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	urls := flags.Args()
+	if len(urls) == 0 {
+		urls = []string{fmt.Sprintf("http://localhost:%d/health", c.cfg.HealthPort)}
+	}
+
+	failed := 0
+	for _, url := range urls {
+		var status map[string]interface{}
+		err := c.getJSON(ctx, url, &status)
+		if c.jsonOutput {
+			if err != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "%s: %v\n", url, err)
+			}
+			continue
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("%-40s UNHEALTHY  %v\n", url, err)
+			continue
+		}
+		fmt.Printf("%-40s %v\n", url, status["status"])
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d endpoints unhealthy", failed, len(urls))
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Command jeevesctl manages a running Jeeves installation over the behavior
+// agent and observer HTTP APIs and MQTT.
+//
+//	jeevesctl [global flags] <command> [flags] [args]
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+// command is a jeevesctl subcommand
+type command struct {
+	summary string
+	run     func(ctx context.Context, c *client, args []string) error
+}
+
+var commands = map[string]command{
+	"consolidate": {"Run consolidation on the behavior agent", runConsolidate},
+	"discover":    {"Run pattern discovery on the behavior agent", runDiscover},
+	"job":         {"Show an admin job, or list recent jobs", runJob},
+	"patterns":    {"List discovered patterns", runPatterns},
+	"episode":     {"Show one episode with its document and annotations", runEpisode},
+	"tail":        {"Print behavior events from MQTT as they arrive", runTail},
+	"purge":       {"Delete recorded data for a location and/or time range", runPurge},
+	"health":      {"Check agent health endpoints", runHealth},
+}
+
+// client reaches the agents' APIs
+type client struct {
+	cfg         *config.Config
+	behaviorURL string
+	observerURL string
+	token       string
+	jsonOutput  bool
+	http        *http.Client
+}
+
+func main() {
+	cfg := config.NewConfig()
+	cfg.ServiceName = "jeevesctl"
+	cfg.LoadFromEnv()
+
+	c := &client{cfg: cfg, http: &http.Client{Timeout: 30 * time.Second}}
+
+	flags := pflag.NewFlagSet("jeevesctl", pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.StringVar(&c.behaviorURL, "behavior-url", envOr("JEEVES_BEHAVIOR_URL", fmt.Sprintf("http://localhost:%d", cfg.BehaviorAPIPort)), "Behavior agent API base URL")
+	flags.StringVar(&c.observerURL, "observer-url", envOr("JEEVES_OBSERVER_URL", fmt.Sprintf("http://localhost:%d", cfg.ObserverPort)), "Observer API base URL")
+	flags.StringVar(&c.token, "token", os.Getenv("JEEVES_OBSERVER_TOKEN"), "Bearer token for the behavior and observer APIs (admin for consolidate, discover, job and purge)")
+	flags.StringVar(&cfg.MQTTBroker, "mqtt-broker", cfg.MQTTBroker, "MQTT broker hostname (tail)")
+	flags.IntVar(&cfg.MQTTPort, "mqtt-port", cfg.MQTTPort, "MQTT broker port (tail)")
+	flags.BoolVar(&c.jsonOutput, "json", false, "Print raw JSON responses")
+	flags.Usage = func() { usage(flags) }
+
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+	if flags.NArg() == 0 {
+		usage(flags)
+		os.Exit(2)
+	}
+
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "jeevesctl: unknown command %q\n\n", name)
+		usage(flags)
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := cmd.run(ctx, c, flags.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "jeevesctl %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func usage(flags *pflag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "Usage: jeevesctl [global flags] <command> [flags] [args]\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nGlobal flags:\n%s", flags.FlagUsages())
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response
+// into out (when non-nil). Non-2xx responses are returned as errors.
+func (c *client) do(ctx context.Context, method, url string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" {
+		return fmt.Errorf("%s %s: %s (set --token or JEEVES_OBSERVER_TOKEN)", method, url, resp.Status)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s: %s", method, url, resp.Status, strings.TrimSpace(string(raw)))
+	}
+
+	if c.jsonOutput {
+		fmt.Println(strings.TrimSpace(string(raw)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -11,9 +11,13 @@ import (
 	"time"
 
 	"github.com/saaga0h/jeeves-platform/internal/light"
+	"github.com/saaga0h/jeeves-platform/pkg/auth"
+	"github.com/saaga0h/jeeves-platform/pkg/chaos"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/health"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
 
@@ -32,9 +36,11 @@ func main() {
 
 	// Set up structured logging
 	logLevel := parseLogLevel(cfg.LogLevel)
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+	levels := logging.NewLevels("light", logLevel)
+	logger := logging.New(os.Stdout, cfg.LogFormat, levels)
+	if err := levels.Apply(cfg.LogLevels); err != nil {
+		logger.Warn("Ignoring invalid log level overrides", "log_levels", cfg.LogLevels, "error", err)
+	}
 	slog.SetDefault(logger)
 
 	logger.Info("Starting J.E.E.V.E.S. Light Agent",
@@ -53,18 +59,56 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Fault injection for e2e resilience tests, nil unless enabled
+	injector := chaos.NewInjector(cfg, logger)
+
 	// Initialize MQTT client
-	mqttClient := mqtt.NewClient(cfg, logger)
+	mqttClient := injector.WrapMQTT(mqtt.NewClient(cfg, logger))
 
 	// Initialize Redis client
-	redisClient := redis.NewClient(cfg, logger)
+	redisClient := injector.WrapRedis(redis.NewClient(cfg, logger))
 
 	// Create light agent
 	agent := light.NewAgent(mqttClient, redisClient, cfg, logger)
 
+	// Pattern scenes, learned preferences and scenes are kept in Postgres
+	var sceneAPI *light.SceneAPI
+	var sceneAuth auth.Authenticator
+	if cfg.LightPatternScenes || cfg.LightPreferenceLearning || cfg.LightScenes {
+		pgClient := postgres.NewClient(cfg, logger)
+		if err := pgClient.Connect(ctx); err != nil {
+			logger.Error("Failed to connect to postgres", "error", err)
+			os.Exit(1)
+		}
+		defer pgClient.Disconnect()
+
+		pg, ok := pgClient.(*postgres.PostgresClient)
+		if !ok {
+			logger.Error("Postgres client does not expose a database connection")
+			os.Exit(1)
+		}
+		store := light.NewPreferenceStore(pg.DB())
+		if cfg.LightPatternScenes {
+			agent.SetScenePreferences(store)
+		}
+		if cfg.LightPreferenceLearning {
+			agent.SetLearnedPreferences(store)
+		}
+		if cfg.LightScenes {
+			agent.SetSceneManager(light.NewSceneManager(light.NewSceneStore(pg.DB())))
+			sceneAPI = light.NewSceneAPI(agent, logger)
+			authn, err := auth.New(cfg, logger)
+			if err != nil {
+				logger.Error("Invalid API auth configuration", "error", err)
+				os.Exit(1)
+			}
+			sceneAuth = authn
+		}
+	}
+
 	// Start health check server
 	healthChecker := health.NewChecker(mqttClient, redisClient, logger)
-	httpServer := startHealthServer(cfg.HealthPort, healthChecker, logger)
+	httpServer := startHealthServer(cfg.HealthPort, healthChecker, sceneAPI, sceneAuth, logger)
 
 	// Start agent in a goroutine
 	agentErr := make(chan error, 1)
@@ -101,9 +145,12 @@ func main() {
 	logger.Info("Light agent shutdown complete")
 }
 
-func startHealthServer(port int, checker *health.Checker, logger *slog.Logger) *http.Server {
+func startHealthServer(port int, checker *health.Checker, sceneAPI *light.SceneAPI, sceneAuth auth.Authenticator, logger *slog.Logger) *http.Server {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", checker.HandlerFunc())
+	if sceneAPI != nil {
+		sceneAPI.Register(mux, sceneAuth)
+	}
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
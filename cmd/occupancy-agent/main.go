@@ -11,9 +11,12 @@ import (
 	"time"
 
 	"github.com/saaga0h/jeeves-platform/internal/occupancy"
+	"github.com/saaga0h/jeeves-platform/pkg/chaos"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/health"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
 
@@ -32,9 +35,11 @@ func main() {
 
 	// Set up structured logging
 	logLevel := parseLogLevel(cfg.LogLevel)
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+	levels := logging.NewLevels("occupancy", logLevel)
+	logger := logging.New(os.Stdout, cfg.LogFormat, levels)
+	if err := levels.Apply(cfg.LogLevels); err != nil {
+		logger.Warn("Ignoring invalid log level overrides", "log_levels", cfg.LogLevels, "error", err)
+	}
 	slog.SetDefault(logger)
 
 	logger.Info("Starting J.E.E.V.E.S. Occupancy Agent",
@@ -55,14 +60,59 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// One-shot corpus evaluation, then exit
+	if cfg.OccupancyEvalCorpus != "" {
+		os.Exit(runCorpusEvaluation(ctx, cfg, logger))
+	}
+
+	// Fault injection for e2e resilience tests, nil unless enabled
+	injector := chaos.NewInjector(cfg, logger)
+	http.DefaultTransport = injector.WrapTransport(http.DefaultTransport)
+
 	// Initialize MQTT client
-	mqttClient := mqtt.NewClient(cfg, logger)
+	mqttClient := injector.WrapMQTT(mqtt.NewClient(cfg, logger))
 
 	// Initialize Redis client
-	redisClient := redis.NewClient(cfg, logger)
+	redisClient := injector.WrapRedis(redis.NewClient(cfg, logger))
+
+	// Label storage for training mode and the accuracy report
+	var labels *occupancy.LabelStore
+	if cfg.OccupancyTrainingMode || cfg.OccupancyLabelReport > 0 {
+		pgClient := postgres.NewClient(cfg, logger)
+		if err := pgClient.Connect(ctx); err != nil {
+			logger.Error("Failed to connect to postgres", "error", err)
+			os.Exit(1)
+		}
+		defer pgClient.Disconnect()
+
+		pg, ok := pgClient.(*postgres.PostgresClient)
+		if !ok {
+			logger.Error("Postgres client does not expose a database connection")
+			os.Exit(1)
+		}
+		labels = occupancy.NewLabelStore(pg.DB())
+	}
+
+	// One-shot label accuracy report, then exit
+	if cfg.OccupancyLabelReport > 0 {
+		since := time.Now().AddDate(0, 0, -cfg.OccupancyLabelReport)
+		report, err := labels.Report(ctx, since)
+		if err != nil {
+			logger.Error("Failed to build label report", "error", err)
+			os.Exit(1)
+		}
+		if err := occupancy.WriteLabelReport(os.Stdout, report); err != nil {
+			logger.Error("Failed to write label report", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Create occupancy agent
 	agent := occupancy.NewAgent(mqttClient, redisClient, cfg, logger)
+	if cfg.OccupancyTrainingMode {
+		agent.SetLabelStore(labels)
+	}
 
 	// Start health check server
 	healthChecker := health.NewChecker(mqttClient, redisClient, logger)
@@ -103,6 +153,59 @@ func main() {
 	logger.Info("Occupancy agent shutdown complete")
 }
 
+// runCorpusEvaluation runs the labeled corpus through LLM and fallback, prints
+// the report and returns a non-zero exit code when a gate fails
+func runCorpusEvaluation(ctx context.Context, cfg *config.Config, logger *slog.Logger) int {
+	cases, err := occupancy.LoadCorpus(cfg.OccupancyEvalCorpus)
+	if err != nil {
+		logger.Error("Failed to load corpus", "error", err)
+		return 1
+	}
+
+	report := occupancy.EvaluateCorpus(ctx, cases, occupancy.LLMAnalyzer(cfg, logger))
+	if err := occupancy.WriteCorpusReport(os.Stdout, report); err != nil {
+		logger.Error("Failed to write corpus report", "error", err)
+		return 1
+	}
+
+	failed := false
+	if threshold := cfg.OccupancyEvalMinAccuracy; threshold > 0 {
+		if report.LLM.Answered > 0 && report.LLM.Accuracy() < threshold {
+			logger.Error("LLM accuracy below gate", "accuracy", report.LLM.Accuracy(), "min_accuracy", threshold)
+			failed = true
+		}
+		if report.Fallback.Accuracy() < threshold {
+			logger.Error("Fallback accuracy below gate", "accuracy", report.Fallback.Accuracy(), "min_accuracy", threshold)
+			failed = true
+		}
+	}
+
+	if path := cfg.OccupancyEvalBaseline; path != "" {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := occupancy.WriteCorpusReportJSON(path, report); err != nil {
+				logger.Error("Failed to write corpus baseline", "error", err)
+				return 1
+			}
+			logger.Info("Wrote corpus baseline", "path", path)
+		} else {
+			baseline, err := occupancy.ReadCorpusReport(path)
+			if err != nil {
+				logger.Error("Failed to load corpus baseline", "error", err)
+				return 1
+			}
+			for _, r := range report.Regressions(baseline) {
+				logger.Error("Corpus regression", "case", r.Case, "analyzer", r.Analyzer)
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
 func startHealthServer(port int, checker *health.Checker, logger *slog.Logger) *http.Server {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", checker.HandlerFunc())
@@ -35,6 +35,7 @@ JEEVES_REDIS_PORT=6379
 JEEVES_LOG_LEVEL=info
 JEEVES_SERVICE_NAME=collector-agent
 JEEVES_MAX_SENSOR_HISTORY=1000
+JEEVES_COLLECTOR_SPOOL_DIR={{ env "NOMAD_ALLOC_DIR" }}/data
 EOH
         destination = "secrets/jeeves.env"
         env         = true
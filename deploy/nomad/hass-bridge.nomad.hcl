@@ -0,0 +1,70 @@
+job "hass-bridge" {
+  datacenters = ["dc1"]
+  type        = "service"
+
+  group "hass-bridge" {
+    count = 1
+
+    network {
+      port "health" {
+        to = 8080
+      }
+    }
+
+    task "hass-bridge" {
+      driver = "raw_exec"
+
+      artifact {
+        source      = "http://artifacts.internal/jeeves/hass-bridge-${attr.kernel.name}-${attr.cpu.arch}"
+        destination = "local/"
+        mode        = "file"
+      }
+
+      vault {
+        policies = ["jeeves-hass-bridge"]
+      }
+
+      template {
+        data = <<EOH
+JEEVES_MQTT_USER={{ with secret "secret/data/jeeves/mqtt" }}{{ .Data.data.username }}{{ end }}
+JEEVES_MQTT_PASSWORD={{ with secret "secret/data/jeeves/mqtt" }}{{ .Data.data.password }}{{ end }}
+JEEVES_MQTT_BROKER=mqtt.service.consul
+JEEVES_MQTT_PORT=1883
+JEEVES_LOG_LEVEL=info
+JEEVES_SERVICE_NAME=hass-bridge
+JEEVES_HASS_DISCOVERY_PREFIX=homeassistant
+JEEVES_HASS_EVENT_TOPIC=homeassistant/events
+JEEVES_HASS_ENTITY_MAP={{ key "jeeves/hass-bridge/entity-map" }}
+EOH
+        destination = "secrets/jeeves.env"
+        env         = true
+      }
+
+      config {
+        command = "local/hass-bridge-${attr.kernel.name}-${attr.cpu.arch}"
+        args    = [
+          "-health-port", "${NOMAD_PORT_health}",
+          "-log-level", "info"
+        ]
+      }
+
+      resources {
+        cpu    = 100
+        memory = 64
+      }
+
+      service {
+        name = "hass-bridge"
+        port = "health"
+        tags = ["jeeves", "hass"]
+
+        check {
+          type     = "http"
+          path     = "/health"
+          interval = "10s"
+          timeout  = "2s"
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,71 @@
+job "media-agent" {
+  datacenters = ["dc1"]
+  type        = "service"
+
+  group "media-agent" {
+    count = 1
+
+    network {
+      port "health" {
+        to = 8080
+      }
+    }
+
+    task "media-agent" {
+      driver = "raw_exec"
+
+      artifact {
+        source      = "http://artifacts.internal/jeeves/media-agent-${attr.kernel.name}-${attr.cpu.arch}"
+        destination = "local/"
+        mode        = "file"
+      }
+
+      vault {
+        policies = ["jeeves-media-agent"]
+      }
+
+      template {
+        data = <<EOH
+JEEVES_MQTT_USER={{ with secret "secret/data/jeeves/mqtt" }}{{ .Data.data.username }}{{ end }}
+JEEVES_MQTT_PASSWORD={{ with secret "secret/data/jeeves/mqtt" }}{{ .Data.data.password }}{{ end }}
+JEEVES_MQTT_BROKER=mqtt.service.consul
+JEEVES_MQTT_PORT=1883
+JEEVES_LOG_LEVEL=info
+JEEVES_SERVICE_NAME=media-agent
+JEEVES_HASS_EVENT_TOPIC=homeassistant/events
+JEEVES_MEDIA_SONOS_TOPIC=sonos
+JEEVES_MEDIA_CHROMECAST_TOPIC=chromecast
+JEEVES_MEDIA_PLAYER_MAP={{ key "jeeves/media-agent/player-map" }}
+EOH
+        destination = "secrets/jeeves.env"
+        env         = true
+      }
+
+      config {
+        command = "local/media-agent-${attr.kernel.name}-${attr.cpu.arch}"
+        args    = [
+          "-health-port", "${NOMAD_PORT_health}",
+          "-log-level", "info"
+        ]
+      }
+
+      resources {
+        cpu    = 100
+        memory = 64
+      }
+
+      service {
+        name = "media-agent"
+        port = "health"
+        tags = ["jeeves", "media"]
+
+        check {
+          type     = "http"
+          path     = "/health"
+          interval = "10s"
+          timeout  = "2s"
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,71 @@
+job "notify-agent" {
+  datacenters = ["dc1"]
+  type        = "service"
+
+  group "notify-agent" {
+    count = 1
+
+    network {
+      port "health" {
+        to = 8080
+      }
+    }
+
+    task "notify-agent" {
+      driver = "raw_exec"
+
+      artifact {
+        source      = "http://artifacts.internal/jeeves/notify-agent-${attr.kernel.name}-${attr.cpu.arch}"
+        destination = "local/"
+        mode        = "file"
+      }
+
+      vault {
+        policies = ["jeeves-notify-agent"]
+      }
+
+      template {
+        data = <<EOH
+JEEVES_MQTT_USER={{ with secret "secret/data/jeeves/mqtt" }}{{ .Data.data.username }}{{ end }}
+JEEVES_MQTT_PASSWORD={{ with secret "secret/data/jeeves/mqtt" }}{{ .Data.data.password }}{{ end }}
+JEEVES_MQTT_BROKER=mqtt.service.consul
+JEEVES_MQTT_PORT=1883
+JEEVES_LOG_LEVEL=info
+JEEVES_SERVICE_NAME=notify-agent
+JEEVES_PUSHOVER_APP_TOKEN={{ with secret "secret/data/jeeves/pushover" }}{{ .Data.data.app_token }}{{ end }}
+JEEVES_PUSHOVER_USER_KEY={{ with secret "secret/data/jeeves/pushover" }}{{ .Data.data.user_key }}{{ end }}
+JEEVES_NOTIFY_QUIET_START_HOUR=22
+JEEVES_NOTIFY_QUIET_END_HOUR=7
+EOH
+        destination = "secrets/jeeves.env"
+        env         = true
+      }
+
+      config {
+        command = "local/notify-agent-${attr.kernel.name}-${attr.cpu.arch}"
+        args    = [
+          "-health-port", "${NOMAD_PORT_health}",
+          "-log-level", "info"
+        ]
+      }
+
+      resources {
+        cpu    = 100
+        memory = 64
+      }
+
+      service {
+        name = "notify-agent"
+        port = "health"
+        tags = ["jeeves", "notify"]
+
+        check {
+          type     = "http"
+          path     = "/health"
+          interval = "10s"
+          timeout  = "2s"
+        }
+      }
+    }
+  }
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,13 +19,54 @@ func main() {
 	mqttBroker := flag.String("mqtt-broker", "mqtt://mosquitto:1883", "MQTT broker URL")
 	outputDir := flag.String("output-dir", "./test-output/captures", "Output directory for captures")
 	snapshotInterval := flag.Int("snapshot-interval", 30, "Snapshot interval in seconds")
+	include := flag.String("include", "", "Comma-separated topic patterns to capture (MQTT wildcards; default: all)")
+	exclude := flag.String("exclude", "", "Comma-separated topic patterns to leave out")
+	schemas := flag.String("schemas", "", "JSON file mapping topic patterns to payload schemas")
+	maxMessages := flag.Int("max-messages", 0, "Keep only the latest N messages (rolling capture; 0 keeps all)")
+	convert := flag.String("convert", "", "Convert this capture or snapshot into a scenario file and exit")
+	scenarioOut := flag.String("scenario-out", "", "Scenario file written by --convert (default: capture name with .yaml)")
+	timeScale := flag.Int("time-scale", 1, "Playback acceleration of the converted scenario")
+	expectTopics := flag.String("expect-topics", "automation/context/,automation/command/", "Comma-separated topic prefixes whose final messages become expectations")
+	settle := flag.Duration("settle", 30*time.Second, "Wait after the last recorded input before checking expectations")
 	flag.Parse()
 
 	// Set up logger
 	logger := log.New(os.Stdout, "", log.Ltime)
 
+	if *convert != "" {
+		out := *scenarioOut
+		if out == "" {
+			out = strings.TrimSuffix(*convert, filepath.Ext(*convert)) + ".yaml"
+		}
+		if err := convertCapture(*convert, out, observer.ConvertOptions{
+			Name:           observer.ScenarioName(*convert),
+			TimeScale:      *timeScale,
+			ExpectPrefixes: strings.Split(*expectTopics, ","),
+			Settle:         *settle,
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to convert capture: %v\n", err)
+			os.Exit(1)
+		}
+		logger.Printf("Scenario written to %s", out)
+		return
+	}
+
 	// Create observer
 	obs := observer.NewObserver(*mqttBroker, logger)
+	obs.SetTopicFilter(observer.TopicFilter{
+		Include: observer.ParseTopicList(*include),
+		Exclude: observer.ParseTopicList(*exclude),
+	})
+	obs.SetMaxMessages(*maxMessages)
+	if *schemas != "" {
+		set, err := observer.LoadSchemas(*schemas)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load schemas: %v\n", err)
+			os.Exit(1)
+		}
+		obs.SetSchemas(set)
+		logger.Printf("Validating payloads against %d topic schemas", len(set))
+	}
 
 	// Start observing
 	logger.Printf("Starting MQTT observer...")
@@ -71,8 +113,26 @@ func main() {
 			} else {
 				logger.Printf("Final capture saved: %s (%d messages)", filename, obs.GetMessageCount())
 			}
+			if violations := obs.GetSchemaViolations(); len(violations) > 0 {
+				logger.Printf("%d captured messages violated their schema", len(violations))
+			}
 
 			return
 		}
 	}
 }
+
+// convertCapture writes the scenario replaying the capture at path to out
+func convertCapture(path, out string, opts observer.ConvertOptions) error {
+	messages, err := observer.LoadCapture(path)
+	if err != nil {
+		return err
+	}
+
+	s, err := observer.CaptureToScenario(messages, opts)
+	if err != nil {
+		return err
+	}
+
+	return observer.SaveScenario(s, out)
+}
@@ -8,10 +8,15 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/saaga0h/jeeves-platform/e2e/internal/executor"
+	"github.com/saaga0h/jeeves-platform/e2e/internal/golden"
+	"github.com/saaga0h/jeeves-platform/e2e/internal/observer"
 	"github.com/saaga0h/jeeves-platform/e2e/internal/reporter"
 	"github.com/saaga0h/jeeves-platform/e2e/internal/scenario"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
@@ -20,11 +25,17 @@ import (
 
 func main() {
 	// Parse CLI arguments - environment variables will override flag defaults
-	scenarioPath := flag.String("scenario", "", "Path to YAML scenario file (required)")
+	scenarioPath := flag.String("scenario", "", "Path to YAML scenario file")
 	mqttBroker := flag.String("mqtt-broker", "mqtt://mosquitto:1883", "MQTT broker URL")
 	redisHost := flag.String("redis-host", "redis:6379", "Redis host")
 	postgresHost := flag.String("postgres-host", "postgres:5432", "PostgreSQL host:port")
+	scenariosGlob := flag.String("scenarios", "", "Glob of YAML scenario files to run as a suite, e.g. /scenarios/*.yaml")
+	parallel := flag.Int("parallel", 1, "Scenarios run concurrently with --scenarios, each in its own namespace")
 	outputDir := flag.String("output-dir", "./test-output", "Output directory for test artifacts")
+	goldenDir := flag.String("golden-dir", "", "Directory of consolidation golden files ({scenario}.json); empty disables the check")
+	updateGolden := flag.Bool("update-golden", false, "Record this run's consolidation output as the golden files")
+	schemasPath := flag.String("schemas", "", "JSON file mapping topic patterns to payload schemas; violations fail the scenario")
+	goldenTolerance := flag.Duration("golden-tolerance", golden.DefaultTolerance.Duration, "Allowed macro-episode start and duration drift")
 	verbose := flag.Bool("verbose", false, "Enable verbose logging")
 	flag.Parse()
 
@@ -39,8 +50,8 @@ func main() {
 		*postgresHost = postgres
 	}
 
-	if *scenarioPath == "" {
-		fmt.Fprintf(os.Stderr, "Error: --scenario is required\n")
+	if *scenarioPath == "" && *scenariosGlob == "" {
+		fmt.Fprintf(os.Stderr, "Error: --scenario or --scenarios is required\n")
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -51,14 +62,6 @@ func main() {
 		logger.SetOutput(os.Stderr)
 	}
 
-	// Load scenario
-	logger.Printf("Loading scenario from %s", *scenarioPath)
-	scen, err := scenario.LoadScenario(*scenarioPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load scenario: %v\n", err)
-		os.Exit(1)
-	}
-
 	// Parse PostgreSQL host:port from flag (which gets env override) or use default
 	postgresHostPort := *postgresHost
 
@@ -81,41 +84,117 @@ func main() {
 	cfg.PostgresDB = "jeeves_behavior"
 	cfg.PostgresSSLMode = "disable"
 
-	// Create slog logger from log logger
-	slogger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
-
-	pgClient := postgres.NewClient(cfg, slogger)
-	if err := pgClient.Connect(context.Background()); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to connect to postgres: %v\n", err)
-		os.Exit(1)
+	var schemas observer.SchemaSet
+	if *schemasPath != "" {
+		set, err := observer.LoadSchemas(*schemasPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load schemas: %v\n", err)
+			os.Exit(1)
+		}
+		schemas = set
 	}
 
-	runner := executor.NewRunner(*mqttBroker, *redisHost, pgClient, logger)
+	env := &environment{
+		mqttBroker:   *mqttBroker,
+		redisHost:    *redisHost,
+		cfg:          cfg,
+		outputDir:    *outputDir,
+		verbose:      *verbose,
+		schemas:      schemas,
+		goldenDir:    *goldenDir,
+		updateGolden: *updateGolden,
+		goldenTolerance: golden.Tolerance{
+			Start:    *goldenTolerance,
+			Duration: *goldenTolerance,
+		},
+	}
 
-	// postgresConn := fmt.Sprintf("host=%s port=5432 user=jeeves password=jeeves_test dbname=jeeves_behavior sslmode=disable",
-	// 	os.Getenv("POSTGRES_HOST"))
-	// // Create runner
-	// runner := executor.NewRunner(*mqttBroker, *redisHost, postgresConn, logger)
+	if *scenariosGlob != "" {
+		os.Exit(runSuite(env, *scenariosGlob, *parallel))
+	}
 
 	// Run scenario
-	ctx := context.Background()
-	result, timelineEvents, err := runner.Run(ctx, scen)
+	report, timeline, err := env.runScenario(context.Background(), *scenarioPath, executor.Namespace{}, logger)
+	env.saveReports(report.Name, []reporter.ScenarioReport{report}, logger)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Test execution failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(timeline)
+
+	// Exit with appropriate status code
+	if report.Result.Passed {
+		os.Exit(0)
+	} else {
 		os.Exit(1)
 	}
+}
+
+// environment is what every scenario run connects to
+type environment struct {
+	mqttBroker string
+	redisHost  string
+	cfg        *config.Config
+	outputDir  string
+	verbose    bool
+	schemas    observer.SchemaSet
+
+	goldenDir       string
+	updateGolden    bool
+	goldenTolerance golden.Tolerance
+}
 
+// runScenario runs the scenario at path in ns and saves its timeline, MQTT
+// capture and summary. The returned timeline is also printed by the caller.
+// The report is filled in even when the scenario fails to run.
+func (e *environment) runScenario(ctx context.Context, path string, ns executor.Namespace, logger *log.Logger) (reporter.ScenarioReport, string, error) {
 	// Extract scenario name for filenames
-	scenarioName := strings.TrimSuffix(filepath.Base(*scenarioPath), ".yaml")
+	scenarioName := strings.TrimSuffix(filepath.Base(path), ".yaml")
+	report := reporter.ScenarioReport{Name: scenarioName, Namespace: ns.Name}
+	fail := func(err error) (reporter.ScenarioReport, string, error) {
+		report.Error = err.Error()
+		return report, "", err
+	}
+
+	// Load scenario
+	logger.Printf("Loading scenario from %s", path)
+	scen, err := scenario.LoadScenario(path)
+	if err != nil {
+		return fail(fmt.Errorf("failed to load scenario: %w", err))
+	}
+
+	cfg := *e.cfg
+	cfg.PostgresSchema = ns.Schema
+
+	// Create slog logger from log logger
+	slogger := slog.New(slog.NewTextHandler(logger.Writer(), &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+
+	pgClient := postgres.NewClient(&cfg, slogger)
+	if err := pgClient.Connect(ctx); err != nil {
+		return fail(fmt.Errorf("failed to connect to postgres: %w", err))
+	}
+
+	runner := executor.NewRunner(e.mqttBroker, e.redisHost, pgClient, logger)
+	runner.SetNamespace(ns)
+	runner.SetSchemas(e.schemas)
+	if e.goldenDir != "" {
+		runner.SetGolden(filepath.Join(e.goldenDir, scenarioName+".json"), e.updateGolden, e.goldenTolerance)
+	}
+
+	result, timelineEvents, err := runner.Run(ctx, scen)
+	if err != nil {
+		return fail(fmt.Errorf("test execution failed: %w", err))
+	}
+	report.Result = result
+	report.Events = timelineEvents
 
 	// Generate timeline report
 	timeline := reporter.GenerateTimeline(result, timelineEvents)
-	fmt.Println(timeline)
 
 	// Save timeline to file
-	timelinePath := filepath.Join(*outputDir, "timelines", scenarioName+".txt")
+	timelinePath := filepath.Join(e.outputDir, "timelines", scenarioName+".txt")
 	if err := reporter.SaveTimeline(timeline, timelinePath); err != nil {
 		logger.Printf("Warning: Failed to save timeline: %v", err)
 	} else {
@@ -123,25 +202,138 @@ func main() {
 	}
 
 	// Save MQTT capture
-	capturePath := filepath.Join(*outputDir, "captures", scenarioName+".json")
+	capturePath := filepath.Join(e.outputDir, "captures", scenarioName+".json")
 	if err := runner.SaveCapture(capturePath); err != nil {
 		logger.Printf("Warning: Failed to save capture: %v", err)
 	} else {
 		logger.Printf("MQTT capture saved to %s", capturePath)
+		report.CapturePath = "../captures/" + scenarioName + ".json"
 	}
 
 	// Save summary
-	summaryPath := filepath.Join(*outputDir, "summaries", scenarioName+".json")
+	summaryPath := filepath.Join(e.outputDir, "summaries", scenarioName+".json")
 	if err := reporter.SaveSummary(result, summaryPath); err != nil {
 		logger.Printf("Warning: Failed to save summary: %v", err)
 	} else {
 		logger.Printf("Summary saved to %s", summaryPath)
 	}
 
-	// Exit with appropriate status code
-	if result.Passed {
-		os.Exit(0)
+	return report, timeline, nil
+}
+
+// saveReports writes the JUnit XML and HTML reports as reports/{name}.xml and .html
+func (e *environment) saveReports(name string, reports []reporter.ScenarioReport, logger *log.Logger) {
+	junitPath := filepath.Join(e.outputDir, "reports", name+".xml")
+	if err := reporter.SaveJUnit(reports, junitPath); err != nil {
+		logger.Printf("Warning: Failed to save JUnit report: %v", err)
 	} else {
-		os.Exit(1)
+		logger.Printf("JUnit report saved to %s", junitPath)
+	}
+
+	htmlPath := filepath.Join(e.outputDir, "reports", name+".html")
+	if err := reporter.SaveHTML("J.E.E.V.E.S. E2E: "+name, reports, htmlPath); err != nil {
+		logger.Printf("Warning: Failed to save HTML report: %v", err)
+	} else {
+		logger.Printf("HTML report saved to %s", htmlPath)
+	}
+}
+
+// runSuite runs every scenario matching pattern, parallel at a time. With
+// parallel > 1 worker i runs its scenarios in namespace ns{i}, which needs an
+// agent stack of its own. Returns the process exit code.
+func runSuite(e *environment, pattern string, parallel int) int {
+	paths, err := filepath.Glob(pattern)
+	if err != nil || len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "No scenarios match %s\n", pattern)
+		return 1
+	}
+	sort.Strings(paths)
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	if parallel > executor.MaxNamespaces {
+		fmt.Fprintf(os.Stderr, "Error: --parallel is limited to %d namespaces\n", executor.MaxNamespaces)
+		return 1
+	}
+	if parallel > len(paths) {
+		parallel = len(paths)
+	}
+
+	start := time.Now()
+	entries := make([]reporter.SuiteEntry, len(paths))
+	timelines := make([]string, len(paths))
+	reports := make([]reporter.ScenarioReport, len(paths))
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 1; worker <= parallel; worker++ {
+		ns := executor.Namespace{}
+		if parallel > 1 {
+			ns = executor.NewNamespace(worker)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				name := strings.TrimSuffix(filepath.Base(paths[i]), ".yaml")
+				prefix := fmt.Sprintf("[%s] ", name)
+				if !ns.IsDefault() {
+					prefix = fmt.Sprintf("[%s %s] ", ns.Name, name)
+				}
+				logger := log.New(os.Stdout, prefix, log.Ltime)
+				if !e.verbose {
+					logger.SetOutput(os.Stderr)
+				}
+
+				scenarioStart := time.Now()
+				report, timeline, err := e.runScenario(context.Background(), paths[i], ns, logger)
+
+				entry := reporter.SuiteEntry{
+					Scenario:  name,
+					Namespace: ns.Name,
+					Duration:  time.Since(scenarioStart),
+				}
+				if err != nil {
+					entry.Error = err.Error()
+					logger.Printf("Scenario failed to run: %v", err)
+				} else {
+					entry.Passed = report.Result.Passed
+					entry.PassedCount = report.Result.PassedCount
+					entry.FailedCount = report.Result.FailedCount
+				}
+				entries[i] = entry
+				timelines[i] = timeline
+				reports[i] = report
+			}
+		}()
+	}
+
+	for i := range paths {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	// Timelines are printed once all scenarios are done so they do not interleave
+	for _, timeline := range timelines {
+		if timeline != "" {
+			fmt.Println(timeline)
+		}
+	}
+
+	summary := reporter.NewSuiteSummary(entries, time.Since(start))
+	fmt.Println(summary.Format())
+
+	suitePath := filepath.Join(e.outputDir, "summaries", "suite.json")
+	if err := reporter.SaveSuiteSummary(summary, suitePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to save suite summary: %v\n", err)
+	}
+	e.saveReports("suite", reports, log.New(os.Stderr, "", log.Ltime))
+
+	if summary.Failed > 0 {
+		return 1
 	}
+	return 0
 }
@@ -0,0 +1,19 @@
+-- e2e/init-scripts/06_multi_occupant.sql
+-- Occupant dimension for episodes and anchors
+-- Occupants are attributed from BLE/phone presence events (sensor:presence:{location})
+
+-- Episodes: occupant is stored in JSON-LD as jeeves:occupant and exposed as a generated column
+ALTER TABLE behavioral_episodes
+ADD COLUMN occupant TEXT GENERATED ALWAYS AS (
+    jsonld->>'jeeves:occupant'
+) STORED;
+
+CREATE INDEX idx_episodes_occupant ON behavioral_episodes(occupant) WHERE occupant IS NOT NULL;
+
+-- Anchors: nullable occupant (NULL = unattributed / household-level)
+ALTER TABLE semantic_anchors
+ADD COLUMN occupant TEXT;
+
+CREATE INDEX idx_anchors_occupant ON semantic_anchors(occupant) WHERE occupant IS NOT NULL;
+
+COMMENT ON COLUMN semantic_anchors.occupant IS 'Occupant attributed from presence signals (NULL when unknown)';
@@ -0,0 +1,37 @@
+-- e2e/init-scripts/07_episode_annotations.sql
+-- User annotations on episodes ("this was cooking", "merge these two")
+-- Annotations correct episode labels, feed pattern weights, and serve as
+-- few-shot examples for LLM consolidation
+
+CREATE TABLE episode_annotations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Annotated episode (micro or macro)
+    episode_id UUID NOT NULL,
+    episode_kind TEXT NOT NULL CHECK (episode_kind IN ('micro', 'macro')),
+
+    -- 'label' corrects what the activity was, 'merge' joins episodes into one activity
+    annotation_type TEXT NOT NULL CHECK (annotation_type IN ('label', 'merge')),
+    label TEXT,
+    note TEXT,
+
+    -- Other micro-episodes merged with episode_id (merge annotations only)
+    related_episode_ids UUID[] NOT NULL DEFAULT '{}',
+
+    -- Snapshot of the annotated activity, used for LLM few-shot examples
+    locations TEXT[] NOT NULL DEFAULT '{}',
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ NOT NULL,
+
+    -- Macro-episode created or relabeled by this annotation
+    macro_episode_id UUID REFERENCES macro_episodes(id) ON DELETE SET NULL,
+
+    author TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_annotations_episode ON episode_annotations(episode_id);
+CREATE INDEX idx_annotations_created ON episode_annotations(created_at DESC);
+CREATE INDEX idx_annotations_label ON episode_annotations(label) WHERE label IS NOT NULL;
+
+COMMENT ON TABLE episode_annotations IS 'User labels and corrections for behavioral episodes';
@@ -0,0 +1,13 @@
+-- e2e/init-scripts/08_pattern_lifecycle.sql
+-- Pattern lifecycle: near-duplicate merging, weight decay, and archiving
+-- Weight still never drops below 0.1; decay moves unseen patterns back toward it
+
+ALTER TABLE behavioral_patterns
+ADD COLUMN archived_at TIMESTAMPTZ,
+ADD COLUMN archive_reason TEXT,  -- 'merged', 'unseen'
+ADD COLUMN merged_into UUID REFERENCES behavioral_patterns(id);
+
+CREATE INDEX idx_patterns_active ON behavioral_patterns(weight DESC) WHERE archived_at IS NULL;
+
+COMMENT ON COLUMN behavioral_patterns.archived_at IS 'When the pattern was retired by lifecycle maintenance (NULL = active)';
+COMMENT ON COLUMN behavioral_patterns.merged_into IS 'Surviving pattern when this one was merged as a near-duplicate';
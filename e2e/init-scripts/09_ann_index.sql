@@ -0,0 +1,16 @@
+-- e2e/init-scripts/09_ann_index.sql
+-- Approximate nearest neighbor index for anchor similarity search
+-- The IVFFlat index from 02 is built on an empty table, so its list centroids are
+-- meaningless; HNSW needs no training data and keeps recall as anchors accumulate.
+-- Query-time recall is tuned with hnsw.ef_search (JEEVES_ANN_EF_SEARCH) and, for
+-- IVFFlat, ivfflat.probes (JEEVES_ANN_PROBES).
+
+DROP INDEX IF EXISTS idx_semantic_similarity;
+
+CREATE INDEX idx_semantic_similarity_hnsw
+ON semantic_anchors
+USING hnsw (semantic_embedding vector_cosine_ops)
+WITH (m = 16, ef_construction = 64);
+
+-- Supports similarity searches restricted to a location and time window
+CREATE INDEX idx_anchors_location_time ON semantic_anchors(location, timestamp);
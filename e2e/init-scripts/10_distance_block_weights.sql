@@ -0,0 +1,16 @@
+-- e2e/init-scripts/10_distance_block_weights.sql
+-- Block weights for structured vector distance, fitted to LLM-labeled pairs
+
+CREATE TABLE IF NOT EXISTS distance_block_weights (
+    id SERIAL PRIMARY KEY,
+    -- [temporal, seasonal, day_type, spatial, weather, lighting, activity, rhythm]
+    weights JSONB NOT NULL,
+    sample_count INT NOT NULL,
+    mse FLOAT NOT NULL,
+    baseline_mse FLOAT NOT NULL,
+    fitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_distance_block_weights_fitted ON distance_block_weights(fitted_at DESC);
+
+COMMENT ON TABLE distance_block_weights IS 'History of structuredDist block weights fitted against recent_llm_distances; the latest row is used.';
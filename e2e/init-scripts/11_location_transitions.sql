@@ -0,0 +1,19 @@
+-- e2e/init-scripts/11_location_transitions.sql
+-- Room-to-room transition counts learned from consecutive episodes
+
+CREATE TABLE IF NOT EXISTS location_transitions (
+    -- Canonical pair order: location1 < location2
+    location1 TEXT NOT NULL,
+    location2 TEXT NOT NULL,
+    transition_count INT NOT NULL,
+    avg_gap_sec FLOAT NOT NULL DEFAULT 0,
+    -- Whether the pair passed the learner's thresholds and is treated as adjacent
+    adjacent BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (location1, location2),
+    CHECK (location1 < location2)
+);
+
+CREATE INDEX IF NOT EXISTS idx_location_transitions_adjacent ON location_transitions(adjacent) WHERE adjacent;
+
+COMMENT ON TABLE location_transitions IS 'Snapshot of episode transition counts per room pair; adjacent pairs are merged into the configured home topology.';
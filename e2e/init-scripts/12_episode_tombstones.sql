@@ -0,0 +1,37 @@
+-- e2e/init-scripts/12_episode_tombstones.sql
+-- Manual episode edits from the observer UI (split, merge, delete)
+-- A tombstone records what was removed so consolidation runs don't recreate
+-- deleted micro-episodes from sensor data or re-consolidate the micro-episodes
+-- of a deleted macro-episode
+
+CREATE TABLE episode_tombstones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- Removed episode
+    episode_id UUID NOT NULL,
+    episode_kind TEXT NOT NULL CHECK (episode_kind IN ('micro', 'macro')),
+
+    -- 'deleted' by the user, 'merged' into another micro, 'split' into two macros
+    reason TEXT NOT NULL CHECK (reason IN ('deleted', 'merged', 'split')),
+
+    -- Span of the removed episode; location is set for micro-episodes only
+    location TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ NOT NULL,
+
+    -- Micro-episodes a removed macro-episode claimed
+    micro_episode_ids UUID[] NOT NULL DEFAULT '{}',
+
+    -- Episodes created by the edit (merged micro, split halves)
+    replaced_by UUID[] NOT NULL DEFAULT '{}',
+
+    note TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_tombstones_location_time ON episode_tombstones(location, started_at)
+    WHERE episode_kind = 'micro';
+CREATE INDEX idx_tombstones_micro_ids ON episode_tombstones USING GIN (micro_episode_ids)
+    WHERE episode_kind = 'macro';
+
+COMMENT ON TABLE episode_tombstones IS 'Episodes removed by manual edits, consulted by consolidation';
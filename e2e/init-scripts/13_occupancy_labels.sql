@@ -0,0 +1,34 @@
+-- e2e/init-scripts/13_occupancy_labels.sql
+-- Ground-truth occupancy labels from the occupancy agent's training mode
+-- Each row pairs a user's "this room is occupied/empty" with the temporal
+-- abstraction at that moment and what the LLM and fallback analysis made of it
+
+CREATE TABLE occupancy_labels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    location TEXT NOT NULL,
+    labeled_at TIMESTAMPTZ NOT NULL,
+    actual_occupied BOOLEAN NOT NULL,
+    author TEXT,
+
+    -- TemporalAbstraction JSON (motion windows, sensor signals, decay profile)
+    abstraction JSONB NOT NULL,
+
+    -- LLM columns are NULL when the LLM was unavailable
+    llm_occupied BOOLEAN,
+    llm_confidence DOUBLE PRECISION,
+    llm_reasoning TEXT,
+
+    fallback_occupied BOOLEAN NOT NULL,
+    fallback_confidence DOUBLE PRECISION NOT NULL,
+    fallback_reasoning TEXT,
+
+    -- State the agent was publishing (NULL = not yet known)
+    published_state TEXT,
+
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_occupancy_labels_location_time ON occupancy_labels(location, labeled_at);
+
+COMMENT ON TABLE occupancy_labels IS 'Ground-truth occupancy labels for comparing LLM and fallback accuracy';
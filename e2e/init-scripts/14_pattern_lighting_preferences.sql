@@ -0,0 +1,26 @@
+-- e2e/init-scripts/14_pattern_lighting_preferences.sql
+-- Lighting scenes the light agent applies while a behavioral pattern is active
+-- One row per pattern and room; defaults are seeded from the pattern type and
+-- the feedback counters decide whether the scene keeps being applied
+
+CREATE TABLE pattern_lighting_preferences (
+    pattern_id UUID NOT NULL REFERENCES behavioral_patterns(id) ON DELETE CASCADE,
+    location TEXT NOT NULL,
+
+    brightness INT NOT NULL CHECK (brightness BETWEEN 0 AND 100),
+    color_temp INT NOT NULL,  -- Kelvin
+    source TEXT NOT NULL DEFAULT 'default',  -- 'default', 'user'
+
+    -- Feedback: a manual change shortly after the scene is applied is an override
+    applied_count INT NOT NULL DEFAULT 0,
+    accepted_count INT NOT NULL DEFAULT 0,
+    overridden_count INT NOT NULL DEFAULT 0,
+    last_applied_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (pattern_id, location)
+);
+
+COMMENT ON TABLE pattern_lighting_preferences IS 'Per-pattern lighting scenes and their acceptance feedback';
@@ -0,0 +1,38 @@
+-- e2e/init-scripts/15_light_preferences.sql
+-- Lighting preferences learned from manual adjustments
+-- Each manual "on" adjustment is logged as an observation and folded into a
+-- running preference per room, time of day and active pattern
+
+CREATE TABLE light_preference_observations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    location TEXT NOT NULL,
+    time_of_day TEXT NOT NULL,  -- early_morning, morning, midday, afternoon, evening, late_evening, night
+    pattern_id TEXT NOT NULL DEFAULT '',  -- '' = recorded for any pattern
+
+    brightness INT NOT NULL,
+    color_temp INT,  -- Kelvin, NULL if the light did not report one
+
+    observed_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_light_preference_observations_key
+    ON light_preference_observations(location, time_of_day, pattern_id, observed_at);
+
+CREATE TABLE light_preferences (
+    location TEXT NOT NULL,
+    time_of_day TEXT NOT NULL,
+    pattern_id TEXT NOT NULL DEFAULT '',
+
+    -- Running mean of the observations, an exponential average after ten
+    brightness DOUBLE PRECISION NOT NULL,
+    color_temp DOUBLE PRECISION,
+    observations INT NOT NULL DEFAULT 0,
+
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (location, time_of_day, pattern_id)
+);
+
+COMMENT ON TABLE light_preferences IS 'Brightness and color temperature learned from repeated manual adjustments';
@@ -0,0 +1,21 @@
+-- e2e/init-scripts/16_light_scenes.sql
+-- Named multi-light scenes per room for the light agent
+-- Decisions pick the closest scene; wall switches activate scenes by name
+
+CREATE TABLE light_scenes (
+    location TEXT NOT NULL,
+    name TEXT NOT NULL,
+
+    -- [{"light": "ceiling", "state": "on", "brightness": 40, "color_temp": 2700}, ...]
+    lights JSONB NOT NULL,
+
+    -- Only activated on request, never selected by lighting decisions
+    manual_only BOOLEAN NOT NULL DEFAULT FALSE,
+
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+
+    PRIMARY KEY (location, name)
+);
+
+COMMENT ON TABLE light_scenes IS 'Named per-light states for a room, selected by the light agent or activated by wall switches';
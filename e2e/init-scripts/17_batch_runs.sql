@@ -0,0 +1,34 @@
+-- e2e/init-scripts/17_batch_runs.sql
+-- One row per sliding-window batch run by the behavior agent's batch coordinator
+-- The same result is published on automation/behavior/batch_complete
+
+CREATE TABLE batch_runs (
+    id UUID PRIMARY KEY,
+
+    -- schedule or mqtt
+    trigger TEXT NOT NULL,
+
+    -- completed or failed
+    status TEXT NOT NULL,
+
+    -- window_start is batch_start minus the overlap with the previous batch
+    window_start TIMESTAMPTZ NOT NULL,
+    batch_start TIMESTAMPTZ NOT NULL,
+    batch_end TIMESTAMPTZ NOT NULL,
+
+    episodes INTEGER NOT NULL DEFAULT 0,
+    anchors INTEGER NOT NULL DEFAULT 0,
+    distances INTEGER NOT NULL DEFAULT 0,
+    patterns INTEGER NOT NULL DEFAULT 0,
+
+    -- [{"name": "distances", "duration_ms": 5230, "error": "..."}, ...]
+    phases JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+
+    started_at TIMESTAMPTZ NOT NULL,
+    duration_ms BIGINT NOT NULL
+);
+
+CREATE INDEX idx_batch_runs_started_at ON batch_runs (started_at DESC);
+
+COMMENT ON TABLE batch_runs IS 'Per-phase results of sliding-window batch processing';
@@ -0,0 +1,11 @@
+-- e2e/init-scripts/18_batch_checkpoints.sql
+-- Batch runs are checkpointed after every phase so a restarted behavior agent
+-- resumes interrupted batches instead of starting over
+
+-- running rows are in progress, or were interrupted and resume on startup
+ALTER TABLE batch_runs ALTER COLUMN duration_ms SET DEFAULT 0;
+ALTER TABLE batch_runs ADD COLUMN resumes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE batch_runs ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_batch_runs_running ON batch_runs (batch_end) WHERE status = 'running';
+CREATE INDEX idx_batch_runs_last_completed ON batch_runs (trigger, batch_end DESC) WHERE status = 'completed';
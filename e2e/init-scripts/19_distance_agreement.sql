@@ -0,0 +1,18 @@
+-- e2e/init-scripts/19_distance_agreement.sql
+-- Periodic cross-validation of vector and learned distances against LLM labels
+
+CREATE TABLE IF NOT EXISTS distance_agreement_reports (
+    id SERIAL PRIMARY KEY,
+    samples INT NOT NULL,
+    -- {"locations": "*", "vector": {"samples", "correlation", "bias", "mae"}, "learned": {...}}
+    overall JSONB NOT NULL,
+    -- the same per location pair ("bedroom|kitchen"), most samples first
+    locations JSONB NOT NULL DEFAULT '[]',
+    -- block weights the vector distances were computed with
+    weights JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_distance_agreement_reports_created ON distance_agreement_reports(created_at DESC);
+
+COMMENT ON TABLE distance_agreement_reports IS 'Agreement of vector and learned distances with sampled recent_llm_distances; served by the observer at /api/distance-agreement.';
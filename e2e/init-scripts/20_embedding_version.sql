@@ -0,0 +1,9 @@
+-- e2e/init-scripts/20_embedding_version.sql
+-- Version of the embedding generator (embedding.Version) each anchor was embedded with
+
+-- 0 = embedded before versioning; re-embedding treats it as outdated
+ALTER TABLE semantic_anchors ADD COLUMN IF NOT EXISTS embedding_version INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_semantic_anchors_embedding_version ON semantic_anchors(embedding_version);
+
+COMMENT ON COLUMN semantic_anchors.embedding_version IS 'embedding.Version that generated semantic_embedding; behavior-agent --reembed-anchors upgrades older anchors';
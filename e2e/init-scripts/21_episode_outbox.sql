@@ -0,0 +1,19 @@
+-- e2e/init-scripts/21_episode_outbox.sql
+-- Episode lifecycle events written with the episode change and relayed to MQTT
+
+CREATE TABLE IF NOT EXISTS episode_outbox (
+    -- published as event_id; stays the same when an event is redelivered
+    id BIGSERIAL PRIMARY KEY,
+    -- started | closed (automation/behavior/episode/{event_type})
+    event_type TEXT NOT NULL,
+    -- {"episode_id", "location", "trigger_type" | "end_reason"}
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- NULL until the relay has published the event
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_episode_outbox_pending ON episode_outbox(id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_episode_outbox_published ON episode_outbox(published_at) WHERE published_at IS NOT NULL;
+
+COMMENT ON TABLE episode_outbox IS 'Transactional outbox for automation/behavior/episode/* events; published in id order by the behavior agent relay and pruned 7 days after publication.';
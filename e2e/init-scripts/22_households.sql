@@ -0,0 +1,116 @@
+-- e2e/init-scripts/22_households.sql
+-- Household dimension: one database serves several homes without mixing data.
+-- Agents connect with jeeves.household_id set (JEEVES_HOUSEHOLD_ID); rows are
+-- stamped with it on insert and row security hides other households' rows.
+-- Rows written without a household belong to 'default'.
+
+CREATE OR REPLACE FUNCTION jeeves_household() RETURNS TEXT AS $$
+    SELECT COALESCE(NULLIF(current_setting('jeeves.household_id', true), ''), 'default')
+$$ LANGUAGE sql STABLE;
+
+-- Every table: household_id column, index and a policy scoping reads and writes.
+-- FORCE applies the policy to the table owner too; superusers and BYPASSRLS
+-- roles still see every household, so agents must use a regular role.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOR t IN SELECT tablename FROM pg_tables WHERE schemaname = current_schema() LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS household_id TEXT NOT NULL DEFAULT jeeves_household()', t);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I(household_id)', 'idx_' || t || '_household', t);
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('DROP POLICY IF EXISTS household_isolation ON %I', t);
+        EXECUTE format('CREATE POLICY household_isolation ON %I USING (household_id = jeeves_household()) WITH CHECK (household_id = jeeves_household())', t);
+    END LOOP;
+END
+$$;
+
+-- Natural keys are unique per household
+ALTER TABLE pattern_observations DROP CONSTRAINT pattern_observations_pattern_key_fkey;
+ALTER TABLE pattern_relearning_queue DROP CONSTRAINT pattern_relearning_queue_pattern_key_fkey;
+
+ALTER TABLE learned_patterns DROP CONSTRAINT learned_patterns_pkey;
+ALTER TABLE learned_patterns ADD PRIMARY KEY (household_id, pattern_key);
+
+ALTER TABLE pattern_relearning_queue DROP CONSTRAINT pattern_relearning_queue_pkey;
+ALTER TABLE pattern_relearning_queue ADD PRIMARY KEY (household_id, pattern_key);
+ALTER TABLE pattern_relearning_queue ADD FOREIGN KEY (household_id, pattern_key)
+    REFERENCES learned_patterns(household_id, pattern_key) ON DELETE CASCADE;
+ALTER TABLE pattern_observations ADD FOREIGN KEY (household_id, pattern_key)
+    REFERENCES learned_patterns(household_id, pattern_key) ON DELETE CASCADE;
+
+ALTER TABLE location_embeddings DROP CONSTRAINT location_embeddings_pkey;
+ALTER TABLE location_embeddings ADD PRIMARY KEY (household_id, location);
+
+ALTER TABLE activity_embeddings DROP CONSTRAINT activity_embeddings_pkey;
+ALTER TABLE activity_embeddings ADD PRIMARY KEY (household_id, fingerprint_hash);
+
+ALTER TABLE location_transitions DROP CONSTRAINT location_transitions_pkey;
+ALTER TABLE location_transitions ADD PRIMARY KEY (household_id, location1, location2);
+
+ALTER TABLE light_preferences DROP CONSTRAINT light_preferences_pkey;
+ALTER TABLE light_preferences ADD PRIMARY KEY (household_id, location, time_of_day, pattern_id);
+
+ALTER TABLE light_scenes DROP CONSTRAINT light_scenes_pkey;
+ALTER TABLE light_scenes ADD PRIMARY KEY (household_id, location, name);
+
+ALTER TABLE behavioral_vector_edges DROP CONSTRAINT behavioral_vector_edges_from_location_to_location_key;
+ALTER TABLE behavioral_vector_edges ADD UNIQUE (household_id, from_location, to_location);
+
+-- Edge upsert on the per-household key
+CREATE OR REPLACE FUNCTION record_behavioral_vector(
+  p_timestamp TIMESTAMPTZ,
+  p_sequence JSONB,
+  p_context JSONB,
+  p_edge_stats JSONB,
+  p_micro_episode_ids INTEGER[],
+  p_scenario_name TEXT DEFAULT NULL
+)
+RETURNS INTEGER AS $$
+DECLARE
+  v_id INTEGER;
+  edge JSONB;
+  from_loc TEXT;
+  to_loc TEXT;
+  gap_sec INTEGER;
+BEGIN
+  INSERT INTO behavioral_vectors (
+    timestamp, sequence, context, edge_stats,
+    micro_episode_ids, scenario_name
+  ) VALUES (
+    p_timestamp, p_sequence, p_context, p_edge_stats,
+    p_micro_episode_ids, p_scenario_name
+  ) RETURNING id INTO v_id;
+
+  FOR i IN 0..(jsonb_array_length(p_sequence) - 2) LOOP
+    from_loc := p_sequence->i->>'location';
+    to_loc := p_sequence->(i+1)->>'location';
+    gap_sec := (p_sequence->i->>'gap_to_next')::int;
+
+    INSERT INTO behavioral_vector_edges (
+      from_location, to_location,
+      min_gap_sec, max_gap_sec, avg_gap_sec, median_gap_sec,
+      observation_count, last_seen
+    ) VALUES (
+      from_loc, to_loc,
+      gap_sec, gap_sec, gap_sec, gap_sec,
+      1, p_timestamp
+    )
+    ON CONFLICT (household_id, from_location, to_location) DO UPDATE SET
+      observation_count = behavioral_vector_edges.observation_count + 1,
+      min_gap_sec = LEAST(behavioral_vector_edges.min_gap_sec, gap_sec),
+      max_gap_sec = GREATEST(behavioral_vector_edges.max_gap_sec, gap_sec),
+      avg_gap_sec = (behavioral_vector_edges.avg_gap_sec * behavioral_vector_edges.observation_count + gap_sec)
+                    / (behavioral_vector_edges.observation_count + 1),
+      last_seen = p_timestamp,
+      updated_at = NOW();
+  END LOOP;
+
+  PERFORM update_edge_frequency_scores();
+
+  RETURN v_id;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION jeeves_household() IS 'Household of the session (jeeves.household_id, set by JEEVES_HOUSEHOLD_ID), default when unset';
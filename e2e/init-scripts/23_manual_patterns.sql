@@ -0,0 +1,14 @@
+-- e2e/init-scripts/23_manual_patterns.sql
+-- Manual patterns: user-defined routines (ordered locations within a daily
+-- time window) that predict and raise anomalies like discovered patterns.
+-- They have no anchors, so clustering never merges, decays or archives them.
+
+ALTER TABLE behavioral_patterns
+ADD COLUMN source TEXT NOT NULL DEFAULT 'discovered' CHECK (source IN ('discovered', 'manual')),
+ADD COLUMN window_start TIME,  -- local time of day the routine starts (manual patterns)
+ADD COLUMN window_end TIME;    -- local time of day it ends; before window_start when it spans midnight
+
+CREATE INDEX idx_patterns_manual ON behavioral_patterns(source) WHERE source = 'manual' AND archived_at IS NULL;
+
+COMMENT ON COLUMN behavioral_patterns.source IS 'discovered (clustering) or manual (defined through the behavior API)';
+COMMENT ON COLUMN behavioral_patterns.locations IS 'Locations involved in the pattern; for manual patterns, in the order they are visited';
@@ -0,0 +1,8 @@
+-- e2e/init-scripts/24_pattern_interpretation.sql
+-- The LLM's interpretation of a discovered cluster (model, confidence, key
+-- characteristics, reasoning), served by GET /api/patterns/{id}/explain
+
+ALTER TABLE behavioral_patterns
+ADD COLUMN interpretation JSONB;
+
+COMMENT ON COLUMN behavioral_patterns.interpretation IS 'LLM interpretation of the cluster when the pattern was discovered (NULL for manual and older patterns)';
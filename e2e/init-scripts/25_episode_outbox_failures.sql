@@ -0,0 +1,13 @@
+-- e2e/init-scripts/25_episode_outbox_failures.sql
+-- Outbox events the relay can never publish are set aside instead of
+-- blocking every later event
+
+ALTER TABLE episode_outbox
+ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS error TEXT;
+
+DROP INDEX IF EXISTS idx_episode_outbox_pending;
+CREATE INDEX IF NOT EXISTS idx_episode_outbox_pending ON episode_outbox(id) WHERE published_at IS NULL AND failed_at IS NULL;
+
+COMMENT ON COLUMN episode_outbox.failed_at IS 'When the relay gave up on the event (undecodable payload); failed events are skipped and not pruned';
+COMMENT ON COLUMN episode_outbox.error IS 'Why the relay gave up on the event';
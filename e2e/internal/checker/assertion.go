@@ -0,0 +1,139 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saaga0h/jeeves-platform/e2e/internal/scenario"
+)
+
+// countQuery builds SELECT COUNT(*) FROM table with ANDed conditions
+type countQuery struct {
+	table      string
+	conditions []string
+	args       []interface{}
+	filters    []string // human-readable, for failure reasons
+}
+
+func (q *countQuery) where(condition, filter string, arg interface{}) {
+	q.args = append(q.args, arg)
+	q.conditions = append(q.conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(q.args))))
+	q.filters = append(q.filters, filter)
+}
+
+func (q *countQuery) whereRange(column string, r *scenario.Range) {
+	if r == nil {
+		return
+	}
+	if r.Min != nil {
+		q.where(column+" >= ?", fmt.Sprintf("%s >= %g", column, *r.Min), *r.Min)
+	}
+	if r.Max != nil {
+		q.where(column+" <= ?", fmt.Sprintf("%s <= %g", column, *r.Max), *r.Max)
+	}
+}
+
+func (q *countQuery) sql() string {
+	query := "SELECT COUNT(*) FROM " + q.table
+	if len(q.conditions) > 0 {
+		query += " WHERE " + strings.Join(q.conditions, " AND ")
+	}
+	return query
+}
+
+func (q *countQuery) describe() string {
+	if len(q.filters) == 0 {
+		return q.table
+	}
+	return fmt.Sprintf("%s (%s)", q.table, strings.Join(q.filters, ", "))
+}
+
+// CheckAssertion evaluates a declarative state assertion. Every item is
+// checked; the reason lists all failing ones and the actual value maps each
+// item to its count.
+func (p *PostgresChecker) CheckAssertion(ctx context.Context, a *scenario.StateAssertion) (bool, string, interface{}) {
+	type item struct {
+		query *countQuery
+		count *scenario.Range
+	}
+	var items []item
+
+	for _, e := range a.Episodes {
+		q := &countQuery{table: "behavioral_episodes"}
+		if e.Location != "" {
+			q.where("location = ?", "location = "+e.Location, e.Location)
+		}
+		if e.Since != "" {
+			q.where("started_at_text::timestamptz >= ?", "since "+e.Since, e.Since)
+		}
+		items = append(items, item{q, e.Count})
+	}
+
+	for _, m := range a.MacroEpisodes {
+		q := &countQuery{table: "macro_episodes"}
+		if m.Location != "" {
+			q.where("? = ANY(locations)", "location = "+m.Location, m.Location)
+		}
+		if m.PatternType != "" {
+			q.where("pattern_type = ?", "pattern_type = "+m.PatternType, m.PatternType)
+		}
+		q.whereRange("duration_minutes", m.DurationMinutes)
+		items = append(items, item{q, m.Count})
+	}
+
+	for _, an := range a.Anchors {
+		q := &countQuery{table: "semantic_anchors"}
+		if an.Location != "" {
+			q.where("location = ?", "location = "+an.Location, an.Location)
+		}
+		if an.WithPattern != nil {
+			q.where("(pattern_id IS NOT NULL) = ?", fmt.Sprintf("with_pattern = %t", *an.WithPattern), *an.WithPattern)
+		}
+		items = append(items, item{q, an.Count})
+	}
+
+	for _, pa := range a.Patterns {
+		q := &countQuery{table: "behavioral_patterns"}
+		q.conditions = append(q.conditions, "archived_at IS NULL", "merged_into IS NULL")
+		if pa.PatternType != "" {
+			q.where("pattern_type = ?", "pattern_type = "+pa.PatternType, pa.PatternType)
+		}
+		if pa.Location != "" {
+			q.where("? = ANY(locations)", "location = "+pa.Location, pa.Location)
+		}
+		q.whereRange("weight", pa.Weight)
+		q.whereRange("observations", pa.Observations)
+		q.whereRange("cluster_size", pa.ClusterSize)
+		items = append(items, item{q, pa.Count})
+	}
+
+	var failures []string
+	actual := make(map[string]interface{}, len(items))
+	for _, it := range items {
+		expected := scenario.AtLeastOne
+		if it.count != nil {
+			expected = *it.count
+		}
+
+		description := it.query.describe()
+		p.logger.Printf("Executing assertion query: %s %v", it.query.sql(), it.query.args)
+
+		var count int64
+		if err := p.pgClient.QueryRow(ctx, it.query.sql(), it.query.args...).Scan(&count); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: query failed: %v", description, err))
+			actual[description] = nil
+			continue
+		}
+		actual[description] = count
+
+		if !expected.Contains(float64(count)) {
+			failures = append(failures, fmt.Sprintf("%s: expected count %s, got %d", description, expected, count))
+		}
+	}
+
+	if len(failures) > 0 {
+		return false, strings.Join(failures, "; "), actual
+	}
+	return true, "postgres assertions passed", actual
+}
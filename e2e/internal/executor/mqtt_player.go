@@ -12,19 +12,20 @@ import (
 
 // MQTTPlayer publishes sensor events to MQTT broker
 type MQTTPlayer struct {
-	client mqtt.Client
-	logger *log.Logger
+	client      mqtt.Client
+	topicPrefix string
+	logger      *log.Logger
 }
 
-// NewMQTTPlayer creates a new MQTT player
-func NewMQTTPlayer(broker string, logger *log.Logger) (*MQTTPlayer, error) {
+// NewMQTTPlayer creates a new MQTT player publishing under topicPrefix
+func NewMQTTPlayer(broker, topicPrefix string, logger *log.Logger) (*MQTTPlayer, error) {
 	if logger == nil {
 		logger = log.Default()
 	}
 
 	opts := mqtt.NewClientOptions()
 	opts.AddBroker(broker)
-	opts.SetClientID("jeeves-test-player")
+	opts.SetClientID("jeeves-test-player" + strings.TrimSuffix(topicPrefix, "/"))
 	opts.SetCleanSession(true)
 	opts.SetAutoReconnect(true)
 
@@ -38,8 +39,9 @@ func NewMQTTPlayer(broker string, logger *log.Logger) (*MQTTPlayer, error) {
 	logger.Printf("Connected to MQTT broker at %s", broker)
 
 	return &MQTTPlayer{
-		client: client,
-		logger: logger,
+		client:      client,
+		topicPrefix: topicPrefix,
+		logger:      logger,
 	}, nil
 }
 
@@ -102,7 +104,7 @@ func (p *MQTTPlayer) PublishEvent(event scenario.SensorEvent) error {
 	}
 
 	// Publish with QoS 1 to ensure delivery
-	token := p.client.Publish(topic, 1, false, payloadBytes)
+	token := p.client.Publish(p.topicPrefix+topic, 1, false, payloadBytes)
 	token.Wait()
 	if token.Error() != nil {
 		return fmt.Errorf("failed to publish to %s: %w", topic, token.Error())
@@ -123,7 +125,7 @@ func (p *MQTTPlayer) PublishContextEvent(eventType, location string, data map[st
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	token := p.client.Publish(topic, 1, false, payloadBytes)
+	token := p.client.Publish(p.topicPrefix+topic, 1, false, payloadBytes)
 	token.Wait()
 	if token.Error() != nil {
 		return fmt.Errorf("failed to publish to %s: %w", topic, token.Error())
@@ -148,7 +150,7 @@ func (p *MQTTPlayer) PublishLightingEvent(location string, data map[string]inter
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	token := p.client.Publish(topic, 1, false, payloadBytes)
+	token := p.client.Publish(p.topicPrefix+topic, 1, false, payloadBytes)
 	token.Wait()
 	if token.Error() != nil {
 		return fmt.Errorf("failed to publish to %s: %w", topic, token.Error())
@@ -173,7 +175,7 @@ func (p *MQTTPlayer) PublishMediaEvent(location string, data map[string]interfac
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	token := p.client.Publish(topic, 1, false, payloadBytes)
+	token := p.client.Publish(p.topicPrefix+topic, 1, false, payloadBytes)
 	token.Wait()
 	if token.Error() != nil {
 		return fmt.Errorf("failed to publish to %s: %w", topic, token.Error())
@@ -189,7 +191,7 @@ func (p *MQTTPlayer) Publish(topic string, qos byte, retain bool, payload []byte
 		return fmt.Errorf("MQTT client not connected")
 	}
 
-	token := p.client.Publish(topic, qos, retain, payload)
+	token := p.client.Publish(p.topicPrefix+topic, qos, retain, payload)
 	token.Wait()
 	if token.Error() != nil {
 		return fmt.Errorf("failed to publish to %s: %w", topic, token.Error())
@@ -244,7 +246,7 @@ func (p *MQTTPlayer) PublishBehaviorEvent(location string, data map[string]inter
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	token := p.client.Publish(topic, 1, false, payloadBytes)
+	token := p.client.Publish(p.topicPrefix+topic, 1, false, payloadBytes)
 	token.Wait()
 	if token.Error() != nil {
 		return fmt.Errorf("failed to publish to %s: %w", topic, token.Error())
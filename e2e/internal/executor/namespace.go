@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// MaxNamespaces is limited by the 16 databases of a default Redis server,
+// database 0 being the shared default namespace
+const MaxNamespaces = 15
+
+// Namespace isolates a scenario from others running in parallel. The agent
+// stack serving it must run with the same JEEVES_MQTT_TOPIC_PREFIX,
+// JEEVES_REDIS_DB and JEEVES_POSTGRES_SCHEMA.
+type Namespace struct {
+	Name        string // empty for the shared default namespace
+	TopicPrefix string
+	RedisDB     int
+	Schema      string
+}
+
+// NewNamespace returns namespace index (1..MaxNamespaces): topic prefix
+// "ns{index}/", Redis database index and Postgres schema "ns{index}"
+func NewNamespace(index int) Namespace {
+	name := fmt.Sprintf("ns%d", index)
+	return Namespace{
+		Name:        name,
+		TopicPrefix: name + "/",
+		RedisDB:     index,
+		Schema:      name,
+	}
+}
+
+// IsDefault reports whether this is the shared, unprefixed namespace
+func (ns Namespace) IsDefault() bool {
+	return ns.Name == ""
+}
+
+// resetNamespace clears state left by an earlier scenario in the namespace:
+// its Redis database is flushed and its schema recreated from public's tables
+func (r *Runner) resetNamespace(ctx context.Context) error {
+	if err := r.redisClient.FlushDB(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to flush Redis database %d: %w", r.ns.RedisDB, err)
+	}
+
+	if r.pgClient == nil {
+		return nil
+	}
+
+	schema := pq.QuoteIdentifier(r.ns.Schema)
+	if _, err := r.pgClient.Exec(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", schema)); err != nil {
+		return fmt.Errorf("failed to drop schema %s: %w", r.ns.Schema, err)
+	}
+	if _, err := r.pgClient.Exec(ctx, fmt.Sprintf("CREATE SCHEMA %s", schema)); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", r.ns.Schema, err)
+	}
+
+	rows, err := r.pgClient.Query(ctx, `SELECT tablename FROM pg_tables WHERE schemaname = 'public' ORDER BY tablename`)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	// Columns, defaults, constraints and indexes; foreign keys and triggers
+	// are not copied
+	for _, table := range tables {
+		name := pq.QuoteIdentifier(table)
+		if _, err := r.pgClient.Exec(ctx, fmt.Sprintf(
+			"CREATE TABLE %s.%s (LIKE public.%s INCLUDING ALL)", schema, name, name)); err != nil {
+			return fmt.Errorf("failed to create %s.%s: %w", r.ns.Schema, table, err)
+		}
+	}
+
+	r.logger.Printf("Reset namespace %s (Redis DB %d, %d tables)", r.ns.Name, r.ns.RedisDB, len(tables))
+	return nil
+}
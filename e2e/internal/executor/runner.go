@@ -3,16 +3,22 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/saaga0h/jeeves-platform/e2e/internal/checker"
+	"github.com/saaga0h/jeeves-platform/e2e/internal/generator"
+	"github.com/saaga0h/jeeves-platform/e2e/internal/golden"
 	"github.com/saaga0h/jeeves-platform/e2e/internal/observer"
 	"github.com/saaga0h/jeeves-platform/e2e/internal/reporter"
 	"github.com/saaga0h/jeeves-platform/e2e/internal/scenario"
+	"github.com/saaga0h/jeeves-platform/pkg/chaos"
 	"github.com/saaga0h/jeeves-platform/pkg/postgres"
 )
 
@@ -26,6 +32,14 @@ type Runner struct {
 	player          *MQTTPlayer
 	redisClient     *redis.Client
 	postgresChecker *checker.PostgresChecker
+	ns              Namespace
+	pacer           Pacer
+
+	schemas observer.SchemaSet
+
+	goldenPath      string // consolidation baseline; empty disables the check
+	goldenUpdate    bool
+	goldenTolerance golden.Tolerance
 }
 
 // NewRunner creates a new test runner
@@ -42,6 +56,26 @@ func NewRunner(mqttBroker, redisHost string, pgClient postgres.Client, logger *l
 	}
 }
 
+// SetNamespace isolates the runner's scenario in ns. pgClient must already
+// use ns.Schema as its search_path.
+func (r *Runner) SetNamespace(ns Namespace) {
+	r.ns = ns
+}
+
+// SetSchemas validates captured agent output; any violation fails the run
+func (r *Runner) SetSchemas(schemas observer.SchemaSet) {
+	r.schemas = schemas
+}
+
+// SetGolden compares the scenario's consolidation output with the baseline
+// at path after the expectations. With update the baseline is rewritten
+// from this run instead.
+func (r *Runner) SetGolden(path string, update bool, tolerance golden.Tolerance) {
+	r.goldenPath = path
+	r.goldenUpdate = update
+	r.goldenTolerance = tolerance
+}
+
 // Run executes a test scenario
 func (r *Runner) Run(ctx context.Context, s *scenario.Scenario) (*scenario.TestResult, []reporter.TimelineEvent, error) {
 	r.logger.Printf("Starting scenario: %s", s.Name)
@@ -53,17 +87,34 @@ func (r *Runner) Run(ctx context.Context, s *scenario.Scenario) (*scenario.TestR
 			s.TestMode.VirtualStart, s.TestMode.TimeScale)
 	}
 
+	// Expand the generated routine, if any, into events
+	if s.Generate != nil {
+		if err := generator.Expand(s); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate events: %w", err)
+		}
+		r.logger.Printf("Generated routine expanded to %d events", len(s.Events))
+	}
+
 	// Initialize connections
 	if err := r.initialize(); err != nil {
 		return nil, nil, fmt.Errorf("initialization failed: %w", err)
 	}
 	defer r.cleanup()
 
+	if !r.ns.IsDefault() {
+		if err := r.resetNamespace(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to reset namespace: %w", err)
+		}
+	}
+
 	// Publish test mode configuration to MQTT for agents BEFORE waiting for startup
 	if s.TestMode != nil {
-		if err := r.publishTestMode(s.TestMode); err != nil {
+		if err := r.publishTestMode(s.TestMode, time.Time{}); err != nil {
 			return nil, nil, fmt.Errorf("failed to publish test mode: %w", err)
 		}
+
+		// Give agents time to receive and process configuration
+		time.Sleep(1 * time.Second)
 	}
 
 	// Wait for agents to start up
@@ -78,15 +129,59 @@ func (r *Runner) Run(ctx context.Context, s *scenario.Scenario) (*scenario.TestR
 	startTime := time.Now()
 	var timelineEvents []reporter.TimelineEvent
 
+	// Pin virtual_start to startTime so agent clocks match event times; at
+	// high compression the startup wait alone would be hours of virtual time
+	if s.TestMode != nil {
+		if err := r.publishTestMode(s.TestMode, startTime); err != nil {
+			return nil, nil, fmt.Errorf("failed to publish test mode: %w", err)
+		}
+	}
+
 	// Determine time scale for event timing
 	timeScale := 1
 	if s.TestMode != nil {
 		timeScale = s.TestMode.TimeScale
 	}
 
+	// Fault injection steps fire in time order, before any step due at the
+	// same time
+	faults := make([]scenario.ChaosStep, len(s.Chaos))
+	copy(faults, s.Chaos)
+	sort.SliceStable(faults, func(i, j int) bool {
+		return faults[i].Time < faults[j].Time
+	})
+	injectFaultsUntil := func(t int) error {
+		for len(faults) > 0 && faults[0].Time <= t {
+			step := faults[0]
+			faults = faults[1:]
+
+			r.pacer.WaitUntil(startTime, step.Time, timeScale)
+			elapsed := GetElapsed(startTime)
+			if err := r.injectFault(step); err != nil {
+				return err
+			}
+
+			description := fmt.Sprintf("%s for %s (%s)", step.Fault, step.Duration, step.Description)
+			if step.Service != "" {
+				description = step.Service + ": " + description
+			}
+			r.logger.Printf("[%.2fs] Injecting fault: %s", elapsed, description)
+			timelineEvents = append(timelineEvents, reporter.TimelineEvent{
+				Elapsed:     elapsed,
+				Layer:       "chaos",
+				Description: description,
+				IsCheck:     false,
+			})
+		}
+		return nil
+	}
+
 	// Execute events
 	for _, event := range s.Events {
-		WaitUntil(startTime, event.Time, timeScale)
+		if err := injectFaultsUntil(event.Time); err != nil {
+			return nil, nil, err
+		}
+		r.pacer.WaitUntil(startTime, event.Time, timeScale)
 		elapsed := GetElapsed(startTime)
 
 		// Determine event description
@@ -132,7 +227,10 @@ func (r *Runner) Run(ctx context.Context, s *scenario.Scenario) (*scenario.TestR
 
 	// Execute wait periods
 	for _, wait := range s.Wait {
-		WaitUntil(startTime, wait.Time, timeScale)
+		if err := injectFaultsUntil(wait.Time); err != nil {
+			return nil, nil, err
+		}
+		r.pacer.WaitUntil(startTime, wait.Time, timeScale)
 		elapsed := GetElapsed(startTime)
 
 		r.logger.Printf("[%.2fs] Wait: %s", elapsed, wait.Description)
@@ -164,7 +262,10 @@ func (r *Runner) Run(ctx context.Context, s *scenario.Scenario) (*scenario.TestR
 	})
 
 	for _, le := range allExpectations {
-		WaitUntil(startTime, le.exp.Time, timeScale)
+		if err := injectFaultsUntil(le.exp.Time); err != nil {
+			return nil, nil, err
+		}
+		r.pacer.WaitUntil(startTime, le.exp.Time, timeScale)
 		elapsed := GetElapsed(startTime)
 
 		var checkDesc string
@@ -172,6 +273,8 @@ func (r *Runner) Run(ctx context.Context, s *scenario.Scenario) (*scenario.TestR
 			checkDesc = le.exp.Topic
 		} else if le.exp.PostgresQuery != "" {
 			checkDesc = "postgres query"
+		} else if le.exp.Assert != nil {
+			checkDesc = "postgres assertions"
 		}
 
 		r.logger.Printf("[%.2fs] Checking expectation: %s - %s",
@@ -185,6 +288,9 @@ func (r *Runner) Run(ctx context.Context, s *scenario.Scenario) (*scenario.TestR
 		if le.exp.PostgresQuery != "" {
 			// Postgres expectation
 			passed, reason, actualPayload = r.checkPostgresExpectation(ctx, le.exp)
+		} else if le.exp.Assert != nil {
+			// Declarative Postgres state assertions
+			passed, reason, actualPayload = r.checkStateAssertion(ctx, le.exp.Assert)
 		} else if le.exp.RedisKey != "" {
 			// Redis expectation
 			passed, reason, actualPayload = checker.CheckRedisExpectation(ctx, r.redisClient, le.exp)
@@ -222,6 +328,31 @@ func (r *Runner) Run(ctx context.Context, s *scenario.Scenario) (*scenario.TestR
 		})
 	}
 
+	if r.schemas != nil {
+		result := r.checkSchemas()
+		expectationResults = append(expectationResults, result)
+		timelineEvents = append(timelineEvents, reporter.TimelineEvent{
+			Elapsed:     GetElapsed(startTime),
+			Layer:       result.Layer,
+			Description: result.Expectation.Description,
+			Success:     result.Passed,
+			IsCheck:     true,
+		})
+	}
+
+	if r.goldenPath != "" {
+		if result, ok := r.checkGolden(ctx, s, startTime); ok {
+			expectationResults = append(expectationResults, result)
+			timelineEvents = append(timelineEvents, reporter.TimelineEvent{
+				Elapsed:     GetElapsed(startTime),
+				Layer:       result.Layer,
+				Description: result.Expectation.Description,
+				Success:     result.Passed,
+				IsCheck:     true,
+			})
+		}
+	}
+
 	endTime := time.Now()
 
 	// Calculate results
@@ -262,13 +393,101 @@ func (r *Runner) checkPostgresExpectation(ctx context.Context, exp scenario.Expe
 	return true, "postgres check passed", exp.PostgresExpected
 }
 
+// checkStateAssertion evaluates declarative Postgres state assertions
+func (r *Runner) checkStateAssertion(ctx context.Context, a *scenario.StateAssertion) (bool, string, interface{}) {
+	if r.postgresChecker == nil {
+		return false, "postgres checker not initialized", nil
+	}
+	return r.postgresChecker.CheckAssertion(ctx, a)
+}
+
+// checkSchemas reports the captured messages that violated their schema
+func (r *Runner) checkSchemas() scenario.ExpectationResult {
+	result := scenario.ExpectationResult{
+		Layer:       "schema",
+		Expectation: scenario.Expectation{Description: "agent output matches payload schemas"},
+	}
+
+	violations := r.observer.GetSchemaViolations()
+	if len(violations) == 0 {
+		result.Passed = true
+		return result
+	}
+
+	var reasons []string
+	for _, msg := range violations {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", msg.Topic, strings.Join(msg.SchemaErrors, ", ")))
+	}
+	result.Reason = fmt.Sprintf("%d malformed messages: %s", len(violations), strings.Join(reasons, "; "))
+	result.ActualPayload = violations
+	return result
+}
+
+// checkGolden diffs the consolidation output against the golden file. It
+// reports no result when there is no golden file yet and update is off.
+func (r *Runner) checkGolden(ctx context.Context, s *scenario.Scenario, startTime time.Time) (scenario.ExpectationResult, bool) {
+	result := scenario.ExpectationResult{
+		Layer:       "golden",
+		Expectation: scenario.Expectation{Description: "consolidation output matches " + r.goldenPath},
+	}
+	if r.pgClient == nil {
+		return result, false
+	}
+
+	since := startTime
+	if s.TestMode != nil && s.TestMode.VirtualStart != "" {
+		if virtualStart, err := time.Parse(time.RFC3339, s.TestMode.VirtualStart); err == nil {
+			since = virtualStart
+		}
+	}
+
+	actual, err := golden.Snapshot(ctx, r.pgClient, since)
+	if err != nil {
+		result.Reason = fmt.Sprintf("failed to snapshot consolidation output: %v", err)
+		return result, true
+	}
+	result.ActualPayload = actual
+
+	if r.goldenUpdate {
+		if err := golden.Save(actual, r.goldenPath); err != nil {
+			result.Reason = err.Error()
+			return result, true
+		}
+		r.logger.Printf("Golden file updated: %s (%d macro-episodes, %d patterns)",
+			r.goldenPath, len(actual.MacroEpisodes), len(actual.Patterns))
+		result.Passed = true
+		result.Reason = "golden file updated"
+		return result, true
+	}
+
+	baseline, err := golden.Load(r.goldenPath)
+	if errors.Is(err, os.ErrNotExist) {
+		r.logger.Printf("No golden file at %s, run with --update-golden to record one", r.goldenPath)
+		return result, false
+	}
+	if err != nil {
+		result.Reason = err.Error()
+		return result, true
+	}
+
+	drift := golden.Diff(baseline, actual, r.goldenTolerance)
+	if len(drift) > 0 {
+		result.Reason = "consolidation drifted from golden file: " + strings.Join(drift, "; ")
+		return result, true
+	}
+	result.Passed = true
+	return result, true
+}
+
 // initialize sets up connections
 func (r *Runner) initialize() error {
 	// Create observer
 	r.observer = observer.NewObserver(r.mqttBroker, r.logger)
+	r.observer.SetTopicPrefix(r.ns.TopicPrefix)
+	r.observer.SetSchemas(r.schemas)
 
 	// Create MQTT player
-	player, err := NewMQTTPlayer(r.mqttBroker, r.logger)
+	player, err := NewMQTTPlayer(r.mqttBroker, r.ns.TopicPrefix, r.logger)
 	if err != nil {
 		return fmt.Errorf("failed to create MQTT player: %w", err)
 	}
@@ -277,6 +496,7 @@ func (r *Runner) initialize() error {
 	// Create Redis client
 	r.redisClient = redis.NewClient(&redis.Options{
 		Addr: r.redisHost,
+		DB:   r.ns.RedisDB,
 	})
 
 	// Test Redis connection
@@ -285,7 +505,7 @@ func (r *Runner) initialize() error {
 		return fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	r.logger.Printf("Connected to Redis at %s", r.redisHost)
+	r.logger.Printf("Connected to Redis at %s (DB %d)", r.redisHost, r.ns.RedisDB)
 
 	// Create Postgres checker (if client provided)
 	if r.pgClient != nil {
@@ -324,8 +544,27 @@ func (r *Runner) SaveCapture(filename string) error {
 	return r.observer.SaveCapture(filename)
 }
 
-// publishTestMode publishes test mode configuration to MQTT for agents
-func (r *Runner) publishTestMode(tm *scenario.TestModeConfig) error {
+// injectFault asks the agents to start a fault
+func (r *Runner) injectFault(step scenario.ChaosStep) error {
+	payload, err := json.Marshal(chaos.Fault{
+		Fault:           step.Fault,
+		Service:         step.Service,
+		DurationSeconds: int(step.Duration.Seconds()),
+		DelayMs:         int(step.Delay.Milliseconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal fault: %w", err)
+	}
+
+	if err := r.player.Publish(chaos.Topic, 1, false, payload); err != nil {
+		return fmt.Errorf("failed to inject fault: %w", err)
+	}
+	return nil
+}
+
+// publishTestMode publishes test mode configuration to MQTT for agents. A
+// non-zero realStart is the wall time virtual_start corresponds to.
+func (r *Runner) publishTestMode(tm *scenario.TestModeConfig, realStart time.Time) error {
 	topic := "automation/test/time_config"
 
 	payload := map[string]interface{}{
@@ -333,6 +572,9 @@ func (r *Runner) publishTestMode(tm *scenario.TestModeConfig) error {
 		"time_scale":    tm.TimeScale,
 		"test_mode":     true,
 	}
+	if !realStart.IsZero() {
+		payload["real_start"] = realStart.UTC().Format(time.RFC3339Nano)
+	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -345,8 +587,5 @@ func (r *Runner) publishTestMode(tm *scenario.TestModeConfig) error {
 
 	r.logger.Printf("Published test mode configuration to %s", topic)
 
-	// Give agents time to receive and process configuration
-	time.Sleep(1 * time.Second)
-
 	return nil
 }
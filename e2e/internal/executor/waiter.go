@@ -5,24 +5,35 @@ import (
 	"time"
 )
 
-var (
+const (
+	// minVirtualSpacing separates events when the runner falls behind schedule
+	minVirtualSpacing = 3 * time.Minute
+
+	// minEventSpacing is the least real time between two events, enough for
+	// MQTT delivery order and distinct agent timestamps
+	minEventSpacing = 20 * time.Millisecond
+)
+
+// Pacer spaces out a scenario's steps. Each runner has its own so scenarios
+// running in parallel do not delay each other.
+type Pacer struct {
 	lastEventTime time.Time
 	eventMutex    sync.Mutex
-)
+}
 
 // WaitUntil waits until a specific time relative to start, with optional time scaling
-func WaitUntil(startTime time.Time, targetSeconds int, timeScale int) {
+func (p *Pacer) WaitUntil(startTime time.Time, targetSeconds int, timeScale int) {
 	if timeScale < 1 {
 		timeScale = 1 // Default to no scaling
 	}
 
-	// Scale the target time
-	scaledSeconds := targetSeconds / timeScale
-	targetTime := startTime.Add(time.Duration(scaledSeconds) * time.Second)
+	// Scale the target time, keeping sub-second precision so events stay
+	// apart at high compression
+	targetTime := startTime.Add(time.Duration(targetSeconds) * time.Second / time.Duration(timeScale))
 	now := time.Now()
 
-	eventMutex.Lock()
-	defer eventMutex.Unlock()
+	p.eventMutex.Lock()
+	defer p.eventMutex.Unlock()
 
 	// Calculate how long to wait
 	var sleepDuration time.Duration
@@ -33,11 +44,14 @@ func WaitUntil(startTime time.Time, targetSeconds int, timeScale int) {
 	} else {
 		// We're behind schedule - ensure minimum spacing from last event
 		// This prevents all events from bunching together if test runner falls behind
-		// Use 3 seconds minimum to ensure proper virtual time spacing at 60x scale
-		// (3 real seconds * 60 = 180 virtual seconds = 3 virtual minutes)
-		minDelay := 3 * time.Second
-		if !lastEventTime.IsZero() {
-			timeSinceLastEvent := now.Sub(lastEventTime)
+		// Keep 3 virtual minutes between events (3 real seconds at 60x scale),
+		// but no less than minEventSpacing so ordering survives high compression
+		minDelay := minVirtualSpacing / time.Duration(timeScale)
+		if minDelay < minEventSpacing {
+			minDelay = minEventSpacing
+		}
+		if !p.lastEventTime.IsZero() {
+			timeSinceLastEvent := now.Sub(p.lastEventTime)
 			if timeSinceLastEvent < minDelay {
 				sleepDuration = minDelay - timeSinceLastEvent
 			}
@@ -49,7 +63,7 @@ func WaitUntil(startTime time.Time, targetSeconds int, timeScale int) {
 	}
 
 	// Update last event time to now + sleep duration
-	lastEventTime = time.Now()
+	p.lastEventTime = time.Now()
 }
 
 // GetElapsed returns elapsed seconds since start
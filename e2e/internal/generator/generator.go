@@ -0,0 +1,211 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/e2e/internal/scenario"
+)
+
+const (
+	// defaultMotionInterval is the mean time between motion triggers while
+	// someone is in a room, when noise.motion_interval is not set
+	defaultMotionInterval = 5 * time.Minute
+
+	// minStay keeps jittered stays from collapsing to nothing
+	minStay = 2 * time.Minute
+)
+
+// visit is one generated stay of the occupant in a location
+type visit struct {
+	step  scenario.RoutineStep
+	start time.Time
+	end   time.Time
+}
+
+// Generate expands a routine into sensor events over cfg.Days days from
+// virtualStart. Event times are seconds from virtualStart, so they play back
+// at the scenario's time_scale like hand-written events. The result is
+// deterministic for a given seed.
+func Generate(cfg *scenario.GenerateConfig, virtualStart time.Time) []scenario.SensorEvent {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	end := virtualStart.AddDate(0, 0, cfg.Days)
+
+	var events []scenario.SensorEvent
+	emit := func(at time.Time, event scenario.SensorEvent) {
+		if at.Before(virtualStart) || !at.Before(end) {
+			return
+		}
+		event.Time = int(at.Sub(virtualStart).Seconds())
+		events = append(events, event)
+	}
+
+	firstDay := time.Date(virtualStart.Year(), virtualStart.Month(), virtualStart.Day(), 0, 0, 0, 0, virtualStart.Location())
+	for day := 0; day <= cfg.Days; day++ {
+		date := firstDay.AddDate(0, 0, day)
+		for _, v := range dayVisits(cfg, date, rng) {
+			visitEvents(cfg, v, rng, emit)
+		}
+		falseMotions(cfg, date, rng, emit)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+	return events
+}
+
+// dayVisits returns the day's stays in start order. A stay is cut short when
+// the next one starts, there is a single occupant.
+func dayVisits(cfg *scenario.GenerateConfig, date time.Time, rng *rand.Rand) []visit {
+	var visits []visit
+	for _, step := range cfg.Routine {
+		if !runsOn(step.On, date.Weekday()) {
+			continue
+		}
+		probability := step.Probability
+		if probability == 0 {
+			probability = 1
+		}
+		if rng.Float64() >= probability {
+			continue
+		}
+
+		at, _ := time.Parse("15:04", step.At)
+		start := date.Add(time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute)
+		start = start.Add(time.Duration(rng.NormFloat64() * float64(cfg.Noise.StartJitter)))
+
+		duration := time.Duration(float64(step.Duration) * (1 + rng.NormFloat64()*cfg.Noise.DurationJitter))
+		if duration < minStay {
+			duration = minStay
+		}
+
+		visits = append(visits, visit{step: step, start: start, end: start.Add(duration)})
+	}
+
+	sort.Slice(visits, func(i, j int) bool {
+		return visits[i].start.Before(visits[j].start)
+	})
+	for i := 0; i+1 < len(visits); i++ {
+		if visits[i].end.After(visits[i+1].start) {
+			visits[i].end = visits[i+1].start
+		}
+	}
+	return visits
+}
+
+func runsOn(on string, weekday time.Weekday) bool {
+	weekend := weekday == time.Saturday || weekday == time.Sunday
+	switch on {
+	case "weekdays":
+		return !weekend
+	case "weekends":
+		return weekend
+	}
+	return true
+}
+
+// visitEvents emits arrival, repeated motion while present, and departure
+func visitEvents(cfg *scenario.GenerateConfig, v visit, rng *rand.Rand, emit func(time.Time, scenario.SensorEvent)) {
+	location := v.step.Location
+	label := location
+	if v.step.Activity != "" {
+		label = fmt.Sprintf("%s (%s)", location, v.step.Activity)
+	}
+
+	emit(v.start, scenario.SensorEvent{
+		Sensor:      "motion:" + location,
+		Value:       true,
+		Description: "Generated: arrive in " + label,
+	})
+	if cfg.Occupancy {
+		emit(v.start.Add(5*time.Second), scenario.SensorEvent{
+			Type:        "occupancy",
+			Location:    location,
+			Data:        map[string]interface{}{"state": "occupied", "confidence": 0.85},
+			Description: "Generated: " + location + " occupied",
+		})
+	}
+	if v.step.Lights {
+		emit(v.start.Add(10*time.Second), scenario.SensorEvent{
+			Type:        "lighting",
+			Location:    location,
+			Data:        map[string]interface{}{"state": "on", "brightness": 70, "color_temp": 3500, "source": "manual"},
+			Description: "Generated: " + location + " lights on",
+		})
+	}
+
+	interval := cfg.Noise.MotionInterval
+	if interval == 0 {
+		interval = defaultMotionInterval
+	}
+	// Exponentially distributed gaps, as people move at irregular intervals
+	for at := v.start.Add(time.Duration(rng.ExpFloat64() * float64(interval))); at.Before(v.end); at = at.Add(time.Duration(rng.ExpFloat64() * float64(interval))) {
+		if rng.Float64() < cfg.Noise.MotionDropout {
+			continue
+		}
+		emit(at, scenario.SensorEvent{
+			Sensor:      "motion:" + location,
+			Value:       true,
+			Description: "Generated: motion in " + label,
+		})
+	}
+
+	if v.step.Lights {
+		emit(v.end.Add(-10*time.Second), scenario.SensorEvent{
+			Type:        "lighting",
+			Location:    location,
+			Data:        map[string]interface{}{"state": "off", "source": "manual"},
+			Description: "Generated: " + location + " lights off",
+		})
+	}
+	if cfg.Occupancy {
+		emit(v.end.Add(-5*time.Second), scenario.SensorEvent{
+			Type:        "occupancy",
+			Location:    location,
+			Data:        map[string]interface{}{"state": "empty", "confidence": 0.9},
+			Description: "Generated: leave " + location,
+		})
+	}
+}
+
+// falseMotions emits spurious motion (pets, sensor glitches) in random
+// routine locations at random times of the day
+func falseMotions(cfg *scenario.GenerateConfig, date time.Time, rng *rand.Rand, emit func(time.Time, scenario.SensorEvent)) {
+	if cfg.Noise.FalseMotions <= 0 {
+		return
+	}
+
+	// Poisson number of events via exponential inter-arrival times over one day
+	for t := rng.ExpFloat64() / cfg.Noise.FalseMotions; t < 1; t += rng.ExpFloat64() / cfg.Noise.FalseMotions {
+		step := cfg.Routine[rng.Intn(len(cfg.Routine))]
+		emit(date.Add(time.Duration(t*float64(24*time.Hour))), scenario.SensorEvent{
+			Sensor:      "motion:" + step.Location,
+			Value:       true,
+			Description: "Generated: spurious motion in " + step.Location,
+		})
+	}
+}
+
+// Expand merges the scenario's generated events into its event list. It is a
+// no-op for scenarios without a generate section.
+func Expand(s *scenario.Scenario) error {
+	if s.Generate == nil {
+		return nil
+	}
+
+	virtualStart, err := time.Parse(time.RFC3339, s.TestMode.VirtualStart)
+	if err != nil {
+		return fmt.Errorf("invalid virtual_start: %w", err)
+	}
+
+	events := append(Generate(s.Generate, virtualStart), s.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+	s.Events = events
+	s.Generate = nil
+	return nil
+}
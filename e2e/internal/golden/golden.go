@@ -0,0 +1,224 @@
+// Package golden records a scenario's consolidation output as a baseline and
+// reports drift from it on later runs.
+package golden
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// Baseline is the consolidation output of one scenario run
+type Baseline struct {
+	MacroEpisodes []MacroEpisode `json:"macro_episodes"`
+	Patterns      []Pattern      `json:"patterns"`
+}
+
+// MacroEpisode is the comparable part of a macro-episode
+type MacroEpisode struct {
+	PatternType     string    `json:"pattern_type"`
+	Locations       []string  `json:"locations"` // sorted
+	Start           time.Time `json:"start"`     // virtual time
+	DurationMinutes int       `json:"duration_minutes"`
+	MicroEpisodes   int       `json:"micro_episodes"`
+}
+
+// Pattern is the comparable part of a discovered pattern. Names and
+// descriptions come from the LLM and are left out.
+type Pattern struct {
+	PatternType string   `json:"pattern_type"`
+	Locations   []string `json:"locations"` // sorted
+	ClusterSize int      `json:"cluster_size"`
+}
+
+// Tolerance bounds the differences that do not count as drift
+type Tolerance struct {
+	Start       time.Duration
+	Duration    time.Duration
+	ClusterSize int
+}
+
+// DefaultTolerance absorbs the timing jitter of a scenario run
+var DefaultTolerance = Tolerance{Start: 2 * time.Minute, Duration: 2 * time.Minute}
+
+// Snapshot reads the macro-episodes starting at or after since and the
+// active patterns
+func Snapshot(ctx context.Context, pg postgres.Client, since time.Time) (*Baseline, error) {
+	baseline := &Baseline{MacroEpisodes: []MacroEpisode{}, Patterns: []Pattern{}}
+
+	rows, err := pg.Query(ctx, `
+		SELECT pattern_type, locations, start_time, duration_minutes, COALESCE(array_length(micro_episode_ids, 1), 0)
+		FROM macro_episodes
+		WHERE start_time >= $1
+		ORDER BY start_time, pattern_type`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query macro-episodes: %w", err)
+	}
+	for rows.Next() {
+		var m MacroEpisode
+		if err := rows.Scan(&m.PatternType, pq.Array(&m.Locations), &m.Start, &m.DurationMinutes, &m.MicroEpisodes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan macro-episode: %w", err)
+		}
+		sort.Strings(m.Locations)
+		m.Start = m.Start.UTC()
+		baseline.MacroEpisodes = append(baseline.MacroEpisodes, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read macro-episodes: %w", err)
+	}
+
+	rows, err = pg.Query(ctx, `
+		SELECT COALESCE(pattern_type, ''), locations, cluster_size
+		FROM behavioral_patterns
+		WHERE archived_at IS NULL AND merged_into IS NULL
+		ORDER BY pattern_type, cluster_size DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query patterns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p Pattern
+		if err := rows.Scan(&p.PatternType, pq.Array(&p.Locations), &p.ClusterSize); err != nil {
+			return nil, fmt.Errorf("failed to scan pattern: %w", err)
+		}
+		sort.Strings(p.Locations)
+		baseline.Patterns = append(baseline.Patterns, p)
+	}
+	return baseline, rows.Err()
+}
+
+// Load reads a baseline file
+func Load(filename string) (*Baseline, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse golden file: %w", err)
+	}
+	return &baseline, nil
+}
+
+// Save writes a baseline file
+func Save(baseline *Baseline, filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal golden file: %w", err)
+	}
+
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// Diff lists how actual drifted from golden, empty when within tolerance.
+// Items are paired greedily in golden order with the first unmatched actual
+// item of the same type and locations that is within tolerance.
+func Diff(golden, actual *Baseline, tol Tolerance) []string {
+	var drift []string
+
+	used := make([]bool, len(actual.MacroEpisodes))
+	for _, want := range golden.MacroEpisodes {
+		match := -1
+		for i, got := range actual.MacroEpisodes {
+			if used[i] || got.PatternType != want.PatternType || !sameLocations(got.Locations, want.Locations) {
+				continue
+			}
+			if abs(got.Start.Sub(want.Start)) <= tol.Start &&
+				abs(time.Duration(got.DurationMinutes-want.DurationMinutes)*time.Minute) <= tol.Duration {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			drift = append(drift, "missing macro-episode "+want.String())
+			continue
+		}
+		used[match] = true
+		if got := actual.MacroEpisodes[match]; got.MicroEpisodes != want.MicroEpisodes {
+			drift = append(drift, fmt.Sprintf("macro-episode %s has %d micro-episodes, golden %d", want.String(), got.MicroEpisodes, want.MicroEpisodes))
+		}
+	}
+	for i, got := range actual.MacroEpisodes {
+		if !used[i] {
+			drift = append(drift, "unexpected macro-episode "+got.String())
+		}
+	}
+
+	usedPatterns := make([]bool, len(actual.Patterns))
+	for _, want := range golden.Patterns {
+		match := -1
+		for i, got := range actual.Patterns {
+			if !usedPatterns[i] && got.PatternType == want.PatternType && sameLocations(got.Locations, want.Locations) &&
+				absInt(got.ClusterSize-want.ClusterSize) <= tol.ClusterSize {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			drift = append(drift, "missing pattern "+want.String())
+			continue
+		}
+		usedPatterns[match] = true
+	}
+	for i, got := range actual.Patterns {
+		if !usedPatterns[i] {
+			drift = append(drift, "unexpected pattern "+got.String())
+		}
+	}
+
+	return drift
+}
+
+func (m MacroEpisode) String() string {
+	return fmt.Sprintf("%s [%s] at %s for %dm",
+		m.PatternType, strings.Join(m.Locations, ","), m.Start.Format(time.RFC3339), m.DurationMinutes)
+}
+
+func (p Pattern) String() string {
+	return fmt.Sprintf("%s [%s] of %d anchors", p.PatternType, strings.Join(p.Locations, ","), p.ClusterSize)
+}
+
+func sameLocations(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
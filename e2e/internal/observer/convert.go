@@ -0,0 +1,226 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/saaga0h/jeeves-platform/e2e/internal/scenario"
+)
+
+const rawTopicPrefix = "automation/raw/"
+
+// ConvertOptions controls how a capture becomes a scenario
+type ConvertOptions struct {
+	Name           string
+	TimeScale      int           // playback acceleration, 1 keeps the recorded pace
+	ExpectPrefixes []string      // agent output topics turned into expectations
+	Settle         time.Duration // wait after the last input before checking expectations
+}
+
+// LoadCapture reads a capture or snapshot saved by SaveCapture
+func LoadCapture(filename string) ([]CapturedMessage, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+
+	var messages []CapturedMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse capture: %w", err)
+	}
+	return messages, nil
+}
+
+// CaptureToScenario turns recorded traffic into a runnable scenario. Raw
+// sensor messages (automation/raw/...) become events at their offset from the
+// first one, under a test_mode starting at the recorded time. The last
+// message on each topic under opts.ExpectPrefixes becomes an expectation on
+// its string and boolean fields, which are stable across runs.
+func CaptureToScenario(messages []CapturedMessage, opts ConvertOptions) (*scenario.Scenario, error) {
+	sorted := make([]CapturedMessage, len(messages))
+	copy(sorted, messages)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var start time.Time
+	var events []scenario.SensorEvent
+	locations := make(map[string]int)
+	lastOutput := make(map[string]CapturedMessage)
+
+	for _, msg := range sorted {
+		if strings.HasPrefix(msg.Topic, rawTopicPrefix) {
+			if start.IsZero() {
+				start = msg.Timestamp
+			}
+			event, location, ok := rawToEvent(msg)
+			if !ok {
+				continue
+			}
+			event.Time = int(msg.Timestamp.Sub(start).Seconds())
+			events = append(events, event)
+			locations[location]++
+			continue
+		}
+
+		for _, prefix := range opts.ExpectPrefixes {
+			if strings.HasPrefix(msg.Topic, prefix) {
+				lastOutput[msg.Topic] = msg
+				break
+			}
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, fmt.Errorf("capture has no raw sensor messages (%s...)", rawTopicPrefix)
+	}
+
+	timeScale := opts.TimeScale
+	if timeScale < 1 {
+		timeScale = 1
+	}
+	lastEvent := events[len(events)-1].Time
+	checkAt := lastEvent + int(opts.Settle.Seconds())
+
+	s := &scenario.Scenario{
+		Name:        opts.Name,
+		Description: fmt.Sprintf("Replay of %d sensor messages recorded %s", len(events), start.UTC().Format(time.RFC3339)),
+		Setup:       scenario.SetupConfig{Location: busiestLocation(locations)},
+		TestMode: &scenario.TestModeConfig{
+			VirtualStart: start.UTC().Format(time.RFC3339),
+			TimeScale:    timeScale,
+		},
+		Events:       events,
+		Wait:         []scenario.WaitPeriod{{Time: checkAt, Description: "Let agents settle after the recorded traffic"}},
+		Expectations: make(map[string][]scenario.Expectation),
+	}
+
+	topics := make([]string, 0, len(lastOutput))
+	for topic := range lastOutput {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	for _, topic := range topics {
+		payload := stableFields(lastOutput[topic].Payload)
+		if len(payload) == 0 {
+			continue
+		}
+		layer := expectationLayer(topic)
+		s.Expectations[layer] = append(s.Expectations[layer], scenario.Expectation{
+			Time:        checkAt,
+			Description: "Recorded final state of " + topic,
+			Topic:       topic,
+			Payload:     payload,
+		})
+	}
+	if len(s.Expectations) == 0 {
+		return nil, fmt.Errorf("capture has no agent output under %s to expect", strings.Join(opts.ExpectPrefixes, ", "))
+	}
+
+	if err := scenario.ValidateScenario(s); err != nil {
+		return nil, fmt.Errorf("converted scenario is invalid: %w", err)
+	}
+	return s, nil
+}
+
+// rawToEvent maps automation/raw/{type}/{location} to the event the MQTT
+// player publishes the same way
+func rawToEvent(msg CapturedMessage) (scenario.SensorEvent, string, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(msg.Topic, rawTopicPrefix), "/", 2)
+	if len(parts) != 2 {
+		return scenario.SensorEvent{}, "", false
+	}
+	sensorType, location := parts[0], parts[1]
+
+	payload, _ := msg.Payload.(map[string]interface{})
+	data, _ := payload["data"].(map[string]interface{})
+	if data == nil {
+		return scenario.SensorEvent{}, "", false
+	}
+
+	event := scenario.SensorEvent{Description: fmt.Sprintf("Recorded %s %s", sensorType, location)}
+	switch sensorType {
+	case "motion":
+		event.Sensor = "motion:" + location
+		event.Value = data["state"] == "on"
+	case "lighting", "media":
+		event.Type = sensorType
+		event.Location = location
+		event.Data = data
+	default:
+		if data["value"] == nil {
+			return scenario.SensorEvent{}, "", false
+		}
+		event.Sensor = sensorType + ":" + location
+		event.Value = data["value"]
+	}
+	return event, location, true
+}
+
+// stableFields keeps the string and boolean fields of a payload; numbers
+// (confidence, counts) and timestamps differ between runs
+func stableFields(payload interface{}) map[string]interface{} {
+	fields, ok := payload.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	stable := make(map[string]interface{})
+	for key, value := range fields {
+		switch v := value.(type) {
+		case bool:
+			stable[key] = v
+		case string:
+			if _, err := time.Parse(time.RFC3339, v); err == nil {
+				continue
+			}
+			stable[key] = v
+		}
+	}
+	return stable
+}
+
+// expectationLayer groups expectations by agent output, e.g.
+// automation/context/occupancy/study → occupancy
+func expectationLayer(topic string) string {
+	parts := strings.Split(topic, "/")
+	if len(parts) >= 3 {
+		return parts[2]
+	}
+	return "recorded"
+}
+
+func busiestLocation(counts map[string]int) string {
+	best, bestCount := "universe", 0
+	for location, count := range counts {
+		if count > bestCount || (count == bestCount && location < best) {
+			best, bestCount = location, count
+		}
+	}
+	return best
+}
+
+// SaveScenario writes a scenario as YAML
+func SaveScenario(s *scenario.Scenario, filename string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scenario: %w", err)
+	}
+
+	if err := saveToFile(filename, data); err != nil {
+		return fmt.Errorf("failed to save scenario: %w", err)
+	}
+	return nil
+}
+
+// ScenarioName derives a scenario name from a capture file name
+func ScenarioName(filename string) string {
+	return "Replay " + strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+}
@@ -0,0 +1,59 @@
+package observer
+
+import "strings"
+
+// TopicFilter decides which topics are captured. Patterns use MQTT
+// wildcards (+ for one level, # for the rest). An empty include list
+// captures everything that is not excluded.
+type TopicFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Allows reports whether topic passes the filter
+func (f TopicFilter) Allows(topic string) bool {
+	for _, pattern := range f.Exclude {
+		if TopicMatches(pattern, topic) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if TopicMatches(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// TopicMatches reports whether topic matches an MQTT subscription pattern
+func TopicMatches(pattern, topic string) bool {
+	patternLevels := strings.Split(pattern, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, level := range patternLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+	return len(patternLevels) == len(topicLevels)
+}
+
+// ParseTopicList splits a comma-separated list of topic patterns
+func ParseTopicList(list string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/saaga0h/jeeves-platform/pkg/events"
 )
 
 // CapturedMessage represents a single MQTT message captured during observation
@@ -16,6 +19,8 @@ type CapturedMessage struct {
 	Topic     string      `json:"topic"`
 	Payload   interface{} `json:"payload"`
 	QoS       byte        `json:"qos"`
+
+	SchemaErrors []string `json:"schema_errors,omitempty"`
 }
 
 // Observer captures all MQTT traffic for later analysis
@@ -25,7 +30,14 @@ type Observer struct {
 	startTime time.Time
 	mutex     sync.RWMutex
 	broker    string
+	prefix    string
 	logger    *log.Logger
+
+	filter      TopicFilter
+	schemas     SchemaSet
+	maxMessages int // ring buffer size, 0 keeps every message
+	next        int // oldest message once the ring buffer is full
+	dropped     int
 }
 
 // NewObserver creates a new MQTT observer
@@ -41,13 +53,38 @@ func NewObserver(broker string, logger *log.Logger) *Observer {
 	}
 }
 
+// SetTopicPrefix limits capture to topics under prefix, which is stripped
+// from captured topics
+func (o *Observer) SetTopicPrefix(prefix string) {
+	o.prefix = prefix
+}
+
+// SetTopicFilter limits capture to the topics the filter allows. Patterns
+// apply to topics without the namespace prefix.
+func (o *Observer) SetTopicFilter(filter TopicFilter) {
+	o.filter = filter
+}
+
+// SetSchemas validates captured payloads, recording violations on the message
+func (o *Observer) SetSchemas(schemas SchemaSet) {
+	o.schemas = schemas
+}
+
+// SetMaxMessages keeps only the latest n messages, overwriting the oldest
+// once full. Zero keeps every message.
+func (o *Observer) SetMaxMessages(n int) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.maxMessages = n
+}
+
 // Start begins capturing MQTT traffic
 func (o *Observer) Start() error {
 	o.startTime = time.Now()
 
 	opts := mqtt.NewClientOptions()
 	opts.AddBroker(o.broker)
-	opts.SetClientID("jeeves-observer")
+	opts.SetClientID("jeeves-observer" + strings.TrimSuffix(o.prefix, "/"))
 	opts.SetCleanSession(true)
 	opts.SetAutoReconnect(true)
 	opts.SetConnectionLostHandler(func(client mqtt.Client, err error) {
@@ -56,12 +93,12 @@ func (o *Observer) Start() error {
 	opts.SetOnConnectHandler(func(client mqtt.Client) {
 		o.logger.Printf("Connected to MQTT broker at %s", o.broker)
 		// Subscribe to all topics
-		token := client.Subscribe("#", 0, o.messageHandler)
+		token := client.Subscribe(o.prefix+"#", 0, o.messageHandler)
 		token.Wait()
 		if token.Error() != nil {
 			o.logger.Printf("Failed to subscribe to all topics: %v", token.Error())
 		} else {
-			o.logger.Printf("Subscribed to all topics (#)")
+			o.logger.Printf("Subscribed to all topics (%s#)", o.prefix)
 		}
 	})
 
@@ -79,27 +116,63 @@ func (o *Observer) Start() error {
 func (o *Observer) messageHandler(client mqtt.Client, msg mqtt.Message) {
 	elapsed := time.Since(o.startTime).Seconds()
 
-	// Try to parse payload as JSON
+	topic := strings.TrimPrefix(msg.Topic(), o.prefix)
+	if !o.filter.Allows(topic) {
+		return
+	}
+
+	// Try to parse payload as JSON, unwrapped from its event envelope so
+	// expectations match the event itself
+	raw := events.Payload(msg.Payload())
 	var payload interface{}
-	if err := json.Unmarshal(msg.Payload(), &payload); err != nil {
+	if err := json.Unmarshal(raw, &payload); err != nil {
 		// If not JSON, store as string
-		payload = string(msg.Payload())
+		payload = string(raw)
 	}
 
 	captured := CapturedMessage{
 		Timestamp: time.Now(),
-		Topic:     msg.Topic(),
+		Topic:     topic,
 		Payload:   payload,
 		QoS:       msg.Qos(),
 	}
+	if o.schemas != nil {
+		captured.SchemaErrors = o.schemas.Validate(topic, payload)
+	}
 
 	o.mutex.Lock()
-	o.messages = append(o.messages, captured)
+	o.store(captured)
 	o.mutex.Unlock()
 
 	// Log with elapsed time
 	payloadStr, _ := json.Marshal(payload)
-	o.logger.Printf("[%7.2fs] %s: %s", elapsed, msg.Topic(), string(payloadStr))
+	o.logger.Printf("[%7.2fs] %s: %s", elapsed, topic, string(payloadStr))
+	for _, violation := range captured.SchemaErrors {
+		o.logger.Printf("          schema violation on %s: %s", topic, violation)
+	}
+}
+
+// store appends a message, overwriting the oldest once the ring buffer is
+// full. Callers hold the write lock.
+func (o *Observer) store(msg CapturedMessage) {
+	if o.maxMessages <= 0 || len(o.messages) < o.maxMessages {
+		o.messages = append(o.messages, msg)
+		return
+	}
+
+	o.messages[o.next] = msg
+	o.next = (o.next + 1) % len(o.messages)
+	o.dropped++
+}
+
+// ordered returns the captured messages oldest first. Callers hold the lock.
+func (o *Observer) ordered() []CapturedMessage {
+	if o.next == 0 {
+		return o.messages
+	}
+	messages := make([]CapturedMessage, 0, len(o.messages))
+	messages = append(messages, o.messages[o.next:]...)
+	return append(messages, o.messages[:o.next]...)
 }
 
 // GetMessagesByTopic returns all messages for a specific topic
@@ -108,7 +181,7 @@ func (o *Observer) GetMessagesByTopic(topic string) []CapturedMessage {
 	defer o.mutex.RUnlock()
 
 	var matches []CapturedMessage
-	for _, msg := range o.messages {
+	for _, msg := range o.ordered() {
 		if msg.Topic == topic {
 			matches = append(matches, msg)
 		}
@@ -123,7 +196,7 @@ func (o *Observer) GetMessagesInTimeRange(start, end time.Time) []CapturedMessag
 	defer o.mutex.RUnlock()
 
 	var matches []CapturedMessage
-	for _, msg := range o.messages {
+	for _, msg := range o.ordered() {
 		if msg.Timestamp.After(start) && msg.Timestamp.Before(end) {
 			matches = append(matches, msg)
 		}
@@ -138,7 +211,7 @@ func (o *Observer) GetMessagesSince(since time.Time) []CapturedMessage {
 	defer o.mutex.RUnlock()
 
 	var matches []CapturedMessage
-	for _, msg := range o.messages {
+	for _, msg := range o.ordered() {
 		if msg.Timestamp.After(since) || msg.Timestamp.Equal(since) {
 			matches = append(matches, msg)
 		}
@@ -153,17 +226,42 @@ func (o *Observer) GetAllMessages() []CapturedMessage {
 	defer o.mutex.RUnlock()
 
 	// Return a copy
-	messages := make([]CapturedMessage, len(o.messages))
-	copy(messages, o.messages)
+	ordered := o.ordered()
+	messages := make([]CapturedMessage, len(ordered))
+	copy(messages, ordered)
 	return messages
 }
 
+// GetSchemaViolations returns the captured messages whose payload did not
+// match its schema
+func (o *Observer) GetSchemaViolations() []CapturedMessage {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	var matches []CapturedMessage
+	for _, msg := range o.ordered() {
+		if len(msg.SchemaErrors) > 0 {
+			matches = append(matches, msg)
+		}
+	}
+
+	return matches
+}
+
+// GetDroppedCount returns how many messages the ring buffer overwrote
+func (o *Observer) GetDroppedCount() int {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return o.dropped
+}
+
 // SaveCapture saves all captured messages to a JSON file
 func (o *Observer) SaveCapture(filename string) error {
 	o.mutex.RLock()
 	defer o.mutex.RUnlock()
 
-	data, err := json.MarshalIndent(o.messages, "", "  ")
+	messages := o.ordered()
+	data, err := json.MarshalIndent(messages, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal messages: %w", err)
 	}
@@ -172,7 +270,11 @@ func (o *Observer) SaveCapture(filename string) error {
 		return fmt.Errorf("failed to save capture: %w", err)
 	}
 
-	o.logger.Printf("Saved %d messages to %s", len(o.messages), filename)
+	if o.dropped > 0 {
+		o.logger.Printf("Saved %d messages to %s (%d older messages dropped)", len(messages), filename, o.dropped)
+	} else {
+		o.logger.Printf("Saved %d messages to %s", len(messages), filename)
+	}
 	return nil
 }
 
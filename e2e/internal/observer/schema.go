@@ -0,0 +1,182 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema used to check agent output: type,
+// required, properties, additionalProperties (false only), enum, items,
+// minimum, maximum and format "date-time".
+type Schema struct {
+	Type                 interface{}        `json:"type,omitempty"` // string or list of strings
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Format               string             `json:"format,omitempty"`
+}
+
+// SchemaSet maps topic patterns (MQTT wildcards) to payload schemas
+type SchemaSet map[string]*Schema
+
+// LoadSchemas reads a schema set from a JSON file of the form
+// {"automation/context/occupancy/+": {"type": "object", ...}, ...}
+func LoadSchemas(filename string) (SchemaSet, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schemas: %w", err)
+	}
+
+	var set SchemaSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse schemas: %w", err)
+	}
+	return set, nil
+}
+
+// Validate checks a payload against every schema whose pattern matches the
+// topic and returns the violations, empty when the payload conforms or no
+// schema applies
+func (s SchemaSet) Validate(topic string, payload interface{}) []string {
+	patterns := make([]string, 0, len(s))
+	for pattern := range s {
+		if TopicMatches(pattern, topic) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Strings(patterns)
+
+	var violations []string
+	for _, pattern := range patterns {
+		violations = append(violations, s[pattern].validate("payload", payload)...)
+	}
+	return violations
+}
+
+func (s *Schema) validate(path string, value interface{}) []string {
+	if s == nil {
+		return nil
+	}
+
+	if types := s.types(); len(types) > 0 && !hasType(types, value) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(types, " or "), jsonType(value))}
+	}
+
+	var violations []string
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		violations = append(violations, fmt.Sprintf("%s: %v is not one of %v", path, value, s.Enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: missing required field %q", path, key))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					violations = append(violations, fmt.Sprintf("%s: unexpected field %q", path, key))
+				}
+				continue
+			}
+			violations = append(violations, property.validate(path+"."+key, v[key])...)
+		}
+
+	case []interface{}:
+		for i, item := range v {
+			violations = append(violations, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+		}
+
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			violations = append(violations, fmt.Sprintf("%s: %v is below minimum %v", path, v, *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			violations = append(violations, fmt.Sprintf("%s: %v is above maximum %v", path, v, *s.Maximum))
+		}
+
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				violations = append(violations, fmt.Sprintf("%s: %q is not an RFC 3339 date-time", path, v))
+			}
+		}
+	}
+
+	return violations
+}
+
+func (s *Schema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func hasType(types []string, value interface{}) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual {
+			return true
+		}
+		// Whole numbers are integers as well as numbers
+		if t == "integer" && actual == "number" && value.(float64) == float64(int64(value.(float64))) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of a value decoded by encoding/json
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) && jsonType(allowed) == jsonType(value) {
+			return true
+		}
+	}
+	return false
+}
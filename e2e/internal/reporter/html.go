@@ -0,0 +1,135 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// htmlTemplate renders a self-contained report: styles are inline and the
+// only outside reference is the link to each scenario's MQTT capture
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"name":     ExpectationName,
+	"duration": func(d time.Duration) string { return formatDuration(d) },
+	"json": func(v interface{}) string {
+		if v == nil {
+			return ""
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; }
+h2 { font-size: 1.15em; margin-top: 2em; border-bottom: 1px solid #ddd; padding-bottom: .3em; }
+table { border-collapse: collapse; width: 100%; margin: .8em 0; font-size: .9em; }
+th, td { text-align: left; padding: .3em .6em; border-bottom: 1px solid #eee; vertical-align: top; }
+th { background: #f6f6f6; }
+.pass { color: #1a7f37; font-weight: 600; }
+.fail { color: #cf222e; font-weight: 600; }
+.muted { color: #777; }
+pre { margin: 0; white-space: pre-wrap; font-size: .85em; }
+details summary { cursor: pointer; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated {{.Generated}} &middot; <span class="pass">{{.Passed}} passed</span> &middot; <span class="{{if .Failed}}fail{{else}}muted{{end}}">{{.Failed}} failed</span></p>
+
+<table>
+<tr><th>Scenario</th><th>Status</th><th>Expectations</th><th>Duration</th></tr>
+{{range .Reports}}<tr>
+<td><a href="#{{.Name}}">{{.Name}}</a>{{if .Namespace}} <span class="muted">({{.Namespace}})</span>{{end}}</td>
+{{if .Result}}<td class="{{if .Result.Passed}}pass">PASS{{else}}fail">FAIL{{end}}</td>
+<td>{{.Result.PassedCount}} passed, {{.Result.FailedCount}} failed</td>
+<td>{{duration (.Result.EndTime.Sub .Result.StartTime)}}</td>
+{{else}}<td class="fail">ERROR</td><td colspan="2">{{.Error}}</td>{{end}}
+</tr>{{end}}
+</table>
+
+{{range .Reports}}
+<h2 id="{{.Name}}">{{.Name}}{{if .Result}} &mdash; {{.Result.Scenario.Name}}{{end}}</h2>
+{{if .Result}}
+<p>{{.Result.Scenario.Description}}</p>
+{{if .CapturePath}}<p><a href="{{.CapturePath}}">MQTT capture</a></p>{{end}}
+
+<h3>Expectations</h3>
+<table>
+<tr><th></th><th>Time</th><th>Layer</th><th>Expectation</th><th>Result</th></tr>
+{{range .Result.Expectations}}<tr>
+<td class="{{if .Passed}}pass">✓{{else}}fail">✗{{end}}</td>
+<td>{{.Expectation.Time}}s</td>
+<td>{{.Layer}}</td>
+<td>{{name .Expectation}}</td>
+<td>{{if not .Passed}}{{.Reason}}{{end}}{{with json .ActualPayload}}<details><summary>actual</summary><pre>{{.}}</pre></details>{{end}}</td>
+</tr>{{end}}
+</table>
+
+<details>
+<summary>Timeline ({{len .Events}} steps)</summary>
+<table>
+<tr><th>Elapsed</th><th>Layer</th><th>Step</th></tr>
+{{range .Events}}<tr>
+<td>{{printf "%.2f" .Elapsed}}s</td>
+<td>{{.Layer}}</td>
+<td>{{if .IsCheck}}<span class="{{if .Success}}pass">✓{{else}}fail">✗{{end}}</span> {{end}}{{.Description}}</td>
+</tr>{{end}}
+</table>
+</details>
+{{else}}
+<p class="fail">{{.Error}}</p>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// SaveHTML writes reports as one self-contained HTML page
+func SaveHTML(title string, reports []ScenarioReport, filename string) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data := struct {
+		Title     string
+		Generated string
+		Passed    int
+		Failed    int
+		Reports   []ScenarioReport
+	}{
+		Title:     title,
+		Generated: time.Now().Format(time.RFC1123),
+		Reports:   reports,
+	}
+	for _, report := range reports {
+		if report.Result != nil && report.Result.Passed {
+			data.Passed++
+		} else {
+			data.Failed++
+		}
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	if err := htmlTemplate.Execute(f, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package reporter
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/e2e/internal/scenario"
+)
+
+// ScenarioReport is everything the JUnit and HTML reports show for one scenario
+type ScenarioReport struct {
+	Name        string // scenario file name without extension
+	Namespace   string
+	Result      *scenario.TestResult // nil when the scenario could not run
+	Events      []TimelineEvent
+	CapturePath string // MQTT capture, relative to the report
+	Error       string
+}
+
+// ExpectationName names an expectation in reports: its description, or what it checks
+func ExpectationName(exp scenario.Expectation) string {
+	switch {
+	case exp.Description != "":
+		return exp.Description
+	case exp.Topic != "":
+		return exp.Topic
+	case exp.RedisKey != "":
+		return fmt.Sprintf("redis %s.%s", exp.RedisKey, exp.RedisField)
+	case exp.Assert != nil:
+		return "postgres assertions"
+	case exp.PostgresQuery != "":
+		return "postgres query"
+	}
+	return "expectation"
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// junitSuite maps a scenario to a test suite with one test case per
+// expectation, classname being the expectation layer
+func junitSuite(report ScenarioReport) junitTestSuite {
+	suite := junitTestSuite{Name: report.Name}
+
+	if report.Result == nil {
+		suite.Tests, suite.Errors, suite.Time = 1, 1, seconds(0)
+		suite.Cases = []junitTestCase{{
+			Name:      "run",
+			Classname: report.Name,
+			Time:      seconds(0),
+			Error:     &junitProblem{Message: report.Error, Type: "ExecutionError", Body: report.Error},
+		}}
+		return suite
+	}
+
+	result := report.Result
+	suite.Time = seconds(result.EndTime.Sub(result.StartTime))
+	suite.Timestamp = result.StartTime.UTC().Format("2006-01-02T15:04:05")
+
+	for _, exp := range result.Expectations {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("[t=%ds] %s", exp.Expectation.Time, ExpectationName(exp.Expectation)),
+			Classname: report.Name + "." + exp.Layer,
+			Time:      seconds(0),
+		}
+		if exp.ActualPayload != nil {
+			if actual, err := json.MarshalIndent(exp.ActualPayload, "", "  "); err == nil {
+				tc.SystemOut = string(actual)
+			}
+		}
+		if !exp.Passed {
+			tc.Failure = &junitProblem{Message: exp.Reason, Type: "ExpectationFailed", Body: exp.Reason}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+	return suite
+}
+
+// SaveJUnit writes reports as JUnit XML, one test suite per scenario
+func SaveJUnit(reports []ScenarioReport, filename string) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	doc := junitTestSuites{}
+	var total time.Duration
+	for _, report := range reports {
+		suite := junitSuite(report)
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		if report.Result != nil {
+			total += report.Result.EndTime.Sub(report.Result.StartTime)
+		}
+		doc.Suites = append(doc.Suites, suite)
+	}
+	doc.Time = seconds(total)
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+
+	if err := os.WriteFile(filename, append([]byte(xml.Header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
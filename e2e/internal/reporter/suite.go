@@ -0,0 +1,83 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SuiteEntry is the outcome of one scenario in a multi-scenario run
+type SuiteEntry struct {
+	Scenario    string        `json:"scenario"`
+	Namespace   string        `json:"namespace,omitempty"`
+	Passed      bool          `json:"passed"`
+	PassedCount int           `json:"passed_count"`
+	FailedCount int           `json:"failed_count"`
+	Duration    time.Duration `json:"duration_ns"`
+	Error       string        `json:"error,omitempty"` // scenario could not be executed
+}
+
+// SuiteSummary aggregates a multi-scenario run
+type SuiteSummary struct {
+	Passed    int          `json:"passed"`
+	Failed    int          `json:"failed"`
+	Duration  string       `json:"duration"`
+	Scenarios []SuiteEntry `json:"scenarios"`
+}
+
+// NewSuiteSummary totals entries
+func NewSuiteSummary(entries []SuiteEntry, duration time.Duration) *SuiteSummary {
+	summary := &SuiteSummary{Duration: duration.Round(time.Second).String(), Scenarios: entries}
+	for _, entry := range entries {
+		if entry.Passed {
+			summary.Passed++
+		} else {
+			summary.Failed++
+		}
+	}
+	return summary
+}
+
+// Format renders the summary as a table
+func (s *SuiteSummary) Format() string {
+	var b strings.Builder
+	b.WriteString("=========================================\n")
+	b.WriteString("Test Suite Summary\n")
+	b.WriteString("=========================================\n")
+	for _, entry := range s.Scenarios {
+		status := "✓ PASS"
+		if !entry.Passed {
+			status = "✗ FAIL"
+		}
+		detail := fmt.Sprintf("%d passed, %d failed", entry.PassedCount, entry.FailedCount)
+		if entry.Error != "" {
+			detail = entry.Error
+		}
+		fmt.Fprintf(&b, "%s  %-40s %-5s %8s  %s\n",
+			status, entry.Scenario, entry.Namespace, entry.Duration.Round(time.Second), detail)
+	}
+	fmt.Fprintf(&b, "\nPassed: %d  Failed: %d  Duration: %s\n", s.Passed, s.Failed, s.Duration)
+	return b.String()
+}
+
+// SaveSuiteSummary saves the suite summary as JSON
+func SaveSuiteSummary(summary *SuiteSummary, filename string) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal suite summary: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package scenario
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StateAssertion declares the expected Postgres state at a checkpoint. Every
+// item counts the rows matching its filters and checks the count.
+type StateAssertion struct {
+	Episodes      []EpisodeAssertion      `yaml:"episodes,omitempty"`
+	MacroEpisodes []MacroEpisodeAssertion `yaml:"macro_episodes,omitempty"`
+	Anchors       []AnchorAssertion       `yaml:"anchors,omitempty"`
+	Patterns      []PatternAssertion      `yaml:"patterns,omitempty"`
+}
+
+// EpisodeAssertion counts micro-episodes
+type EpisodeAssertion struct {
+	Location string `yaml:"location,omitempty"`
+	Since    string `yaml:"since,omitempty"` // RFC3339, episodes started at or after
+	Count    *Range `yaml:"count,omitempty"` // default: at least one
+}
+
+// MacroEpisodeAssertion counts macro-episodes
+type MacroEpisodeAssertion struct {
+	Location        string `yaml:"location,omitempty"` // one of the macro-episode's locations
+	PatternType     string `yaml:"pattern_type,omitempty"`
+	DurationMinutes *Range `yaml:"duration_minutes,omitempty"`
+	Count           *Range `yaml:"count,omitempty"`
+}
+
+// AnchorAssertion counts semantic anchors
+type AnchorAssertion struct {
+	Location    string `yaml:"location,omitempty"`
+	WithPattern *bool  `yaml:"with_pattern,omitempty"` // assigned to a pattern or not
+	Count       *Range `yaml:"count,omitempty"`
+}
+
+// PatternAssertion counts active (not archived or merged) behavioral patterns
+type PatternAssertion struct {
+	PatternType  string `yaml:"pattern_type,omitempty"`
+	Location     string `yaml:"location,omitempty"` // one of the pattern's locations
+	Weight       *Range `yaml:"weight,omitempty"`
+	Observations *Range `yaml:"observations,omitempty"`
+	ClusterSize  *Range `yaml:"cluster_size,omitempty"`
+	Count        *Range `yaml:"count,omitempty"`
+}
+
+// Range bounds a value. In YAML it is either a number (exact) or a mapping
+// with min and/or max, both inclusive.
+type Range struct {
+	Min *float64 `yaml:"min,omitempty"`
+	Max *float64 `yaml:"max,omitempty"`
+}
+
+// AtLeastOne is the count expected when an assertion does not give one
+var AtLeastOne = Range{Min: floatPtr(1)}
+
+// UnmarshalYAML accepts a scalar as an exact value
+func (r *Range) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		exact, err := strconv.ParseFloat(value.Value, 64)
+		if err != nil {
+			return fmt.Errorf("range must be a number or {min, max}, got %q", value.Value)
+		}
+		r.Min, r.Max = floatPtr(exact), floatPtr(exact)
+		return nil
+	}
+
+	type plain Range
+	return value.Decode((*plain)(r))
+}
+
+// Contains reports whether v is within the range
+func (r Range) Contains(v float64) bool {
+	return (r.Min == nil || v >= *r.Min) && (r.Max == nil || v <= *r.Max)
+}
+
+func (r Range) String() string {
+	switch {
+	case r.Min != nil && r.Max != nil && *r.Min == *r.Max:
+		return strconv.FormatFloat(*r.Min, 'f', -1, 64)
+	case r.Min != nil && r.Max != nil:
+		return fmt.Sprintf("%g..%g", *r.Min, *r.Max)
+	case r.Min != nil:
+		return fmt.Sprintf(">= %g", *r.Min)
+	case r.Max != nil:
+		return fmt.Sprintf("<= %g", *r.Max)
+	}
+	return "any"
+}
+
+func (r *Range) validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.Min == nil && r.Max == nil {
+		return fmt.Errorf("range needs min or max")
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("range min %g is greater than max %g", *r.Min, *r.Max)
+	}
+	return nil
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
@@ -3,6 +3,9 @@ package scenario
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,6 +22,10 @@ func LoadScenario(filepath string) (*Scenario, error) {
 		return nil, fmt.Errorf("failed to parse scenario YAML: %w", err)
 	}
 
+	if err := resolveTimeCompression(&scenario); err != nil {
+		return nil, fmt.Errorf("scenario validation failed: %w", err)
+	}
+
 	// Validate the loaded scenario
 	if err := ValidateScenario(&scenario); err != nil {
 		return nil, fmt.Errorf("scenario validation failed: %w", err)
@@ -34,9 +41,73 @@ func LoadScenarioFromBytes(data []byte) (*Scenario, error) {
 		return nil, fmt.Errorf("failed to parse scenario YAML: %w", err)
 	}
 
+	if err := resolveTimeCompression(&scenario); err != nil {
+		return nil, fmt.Errorf("scenario validation failed: %w", err)
+	}
+
 	if err := ValidateScenario(&scenario); err != nil {
 		return nil, fmt.Errorf("scenario validation failed: %w", err)
 	}
 
 	return &scenario, nil
 }
+
+// resolveTimeCompression sets test_mode.time_scale from time_compression
+func resolveTimeCompression(s *Scenario) error {
+	tm := s.TestMode
+	if tm == nil || tm.TimeCompression == "" {
+		return nil
+	}
+
+	scale, err := ParseTimeCompression(tm.TimeCompression)
+	if err != nil {
+		return err
+	}
+	if tm.TimeScale != 0 && tm.TimeScale != scale {
+		return fmt.Errorf("time_compression %q (%dx) conflicts with time_scale %d", tm.TimeCompression, scale, tm.TimeScale)
+	}
+	tm.TimeScale = scale
+	return nil
+}
+
+// ParseTimeCompression converts "<simulated>/<wall>" such as "1h/s", "30m/s"
+// or "1d/10s" into a time scale factor. The wall side may omit the number.
+func ParseTimeCompression(value string) (int, error) {
+	simulated, wall, ok := strings.Cut(strings.ReplaceAll(value, " ", ""), "/")
+	if !ok {
+		return 0, fmt.Errorf("time_compression must look like 1h/s, got %q", value)
+	}
+
+	parse := func(d string) (time.Duration, error) {
+		if d != "" && (d[0] < '0' || d[0] > '9') {
+			d = "1" + d
+		}
+		// time.ParseDuration has no day unit
+		if strings.HasSuffix(d, "d") {
+			days, err := strconv.ParseFloat(strings.TrimSuffix(d, "d"), 64)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(days * float64(24*time.Hour)), nil
+		}
+		return time.ParseDuration(d)
+	}
+
+	simulatedDuration, err := parse(simulated)
+	if err != nil {
+		return 0, fmt.Errorf("invalid simulated time in time_compression %q: %w", value, err)
+	}
+	wallDuration, err := parse(wall)
+	if err != nil {
+		return 0, fmt.Errorf("invalid wall time in time_compression %q: %w", value, err)
+	}
+	if simulatedDuration <= 0 || wallDuration <= 0 {
+		return 0, fmt.Errorf("time_compression %q must be positive", value)
+	}
+
+	scale := int(simulatedDuration / wallDuration)
+	if scale < 1 {
+		return 0, fmt.Errorf("time_compression %q is slower than real time", value)
+	}
+	return scale, nil
+}
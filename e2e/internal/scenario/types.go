@@ -8,7 +8,9 @@ type Scenario struct {
 	Description  string                   `yaml:"description"`
 	Setup        SetupConfig              `yaml:"setup"`
 	TestMode     *TestModeConfig          `yaml:"test_mode,omitempty"` // Optional virtual time configuration
+	Generate     *GenerateConfig          `yaml:"generate,omitempty"`  // Optional synthetic events, merged with Events
 	Events       []SensorEvent            `yaml:"events"`
+	Chaos        []ChaosStep              `yaml:"chaos,omitempty"` // Optional fault injection, interleaved with events
 	Wait         []WaitPeriod             `yaml:"wait"`
 	Expectations map[string][]Expectation `yaml:"expectations"`
 }
@@ -17,6 +19,52 @@ type Scenario struct {
 type TestModeConfig struct {
 	VirtualStart string `yaml:"virtual_start"` // ISO 8601 timestamp, e.g., "2025-10-14T19:00:00Z"
 	TimeScale    int    `yaml:"time_scale"`    // Acceleration factor, e.g., 60 means 60x faster
+
+	// TimeCompression is simulated time per wall-clock time, e.g. "1h/s" for
+	// one simulated hour per second. It sets TimeScale when loading.
+	TimeCompression string `yaml:"time_compression,omitempty"`
+}
+
+// GenerateConfig describes a household routine that the generator expands
+// into sensor events, starting from test_mode.virtual_start
+type GenerateConfig struct {
+	Seed      int64         `yaml:"seed"` // same seed, same events
+	Days      int           `yaml:"days"`
+	Occupancy bool          `yaml:"occupancy"` // also publish occupancy context events
+	Routine   []RoutineStep `yaml:"routine"`
+	Noise     NoiseConfig   `yaml:"noise"`
+}
+
+// RoutineStep is one recurring stay in a location, e.g. "cook in the kitchen
+// at 18:00 for 45 minutes"
+type RoutineStep struct {
+	At          string        `yaml:"at"` // HH:MM virtual time of day
+	Location    string        `yaml:"location"`
+	Activity    string        `yaml:"activity,omitempty"` // only used in event descriptions
+	Duration    time.Duration `yaml:"duration"`
+	On          string        `yaml:"on,omitempty"`          // daily (default), weekdays or weekends
+	Probability float64       `yaml:"probability,omitempty"` // chance the step happens on a day, default 1
+	Lights      bool          `yaml:"lights,omitempty"`      // lights on while in the location
+}
+
+// NoiseConfig randomizes the generated routine
+type NoiseConfig struct {
+	StartJitter    time.Duration `yaml:"start_jitter"`    // standard deviation of step start times
+	DurationJitter float64       `yaml:"duration_jitter"` // standard deviation of durations, as a fraction
+	MotionInterval time.Duration `yaml:"motion_interval"` // mean time between motion triggers while present
+	MotionDropout  float64       `yaml:"motion_dropout"`  // chance a motion trigger is missed
+	FalseMotions   float64       `yaml:"false_motions"`   // mean spurious motion events per day
+}
+
+// ChaosStep injects a fault into the agents, which must run with
+// JEEVES_CHAOS_ENABLED
+type ChaosStep struct {
+	Time        int           `yaml:"time"`              // Seconds from start, scaled like event times
+	Fault       string        `yaml:"fault"`             // mqtt_drop, redis_error or llm_delay
+	Service     string        `yaml:"service,omitempty"` // e.g. "occupancy-agent"; empty targets every agent
+	Duration    time.Duration `yaml:"duration"`          // Wall-clock time the fault lasts
+	Delay       time.Duration `yaml:"delay,omitempty"`   // llm_delay: added to each LLM request
+	Description string        `yaml:"description"`
 }
 
 // SetupConfig defines the initial state for a test scenario
@@ -61,9 +109,10 @@ type WaitPeriod struct {
 
 // Expectation represents an expected outcome to verify
 type Expectation struct {
-	Time    int                    `yaml:"time"`    // Seconds from start
-	Topic   string                 `yaml:"topic"`   // MQTT topic
-	Payload map[string]interface{} `yaml:"payload"` // Expected payload (supports special matchers)
+	Time        int                    `yaml:"time"` // Seconds from start
+	Description string                 `yaml:"description,omitempty"`
+	Topic       string                 `yaml:"topic"`   // MQTT topic
+	Payload     map[string]interface{} `yaml:"payload"` // Expected payload (supports special matchers)
 
 	// Optional: Redis state checks
 	RedisKey   string `yaml:"redis_key,omitempty"`
@@ -73,6 +122,9 @@ type Expectation struct {
 	// Optional: Postgres state checks
 	PostgresQuery    string      `yaml:"postgres_query,omitempty"`
 	PostgresExpected interface{} `yaml:"postgres_expected,omitempty"`
+
+	// Optional: declarative Postgres state assertions
+	Assert *StateAssertion `yaml:"assert,omitempty"`
 }
 
 // TestResult represents the outcome of running a scenario
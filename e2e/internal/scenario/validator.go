@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/chaos"
 )
 
 // ValidateScenario performs validation checks on a loaded scenario
@@ -21,10 +23,22 @@ func ValidateScenario(s *Scenario) error {
 	}
 
 	// Validate events
-	if err := validateEvents(s.Events); err != nil {
+	if err := validateEvents(s.Events, s.Generate != nil); err != nil {
 		return fmt.Errorf("events validation failed: %w", err)
 	}
 
+	// Validate generated routine
+	if s.Generate != nil {
+		if err := validateGenerate(s.Generate, s.TestMode); err != nil {
+			return fmt.Errorf("generate validation failed: %w", err)
+		}
+	}
+
+	// Validate fault injection
+	if err := validateChaos(s.Chaos); err != nil {
+		return fmt.Errorf("chaos validation failed: %w", err)
+	}
+
 	// Validate wait periods
 	if err := validateWaitPeriods(s.Wait); err != nil {
 		return fmt.Errorf("wait periods validation failed: %w", err)
@@ -48,8 +62,8 @@ func ValidateScenario(s *Scenario) error {
 	return nil
 }
 
-func validateEvents(events []SensorEvent) error {
-	if len(events) == 0 {
+func validateEvents(events []SensorEvent, generated bool) error {
+	if len(events) == 0 && !generated {
 		return fmt.Errorf("at least one event is required")
 	}
 
@@ -84,6 +98,76 @@ func validateEvents(events []SensorEvent) error {
 	return nil
 }
 
+func validateGenerate(g *GenerateConfig, tm *TestModeConfig) error {
+	if tm == nil || tm.VirtualStart == "" {
+		return fmt.Errorf("test_mode.virtual_start is required")
+	}
+	if g.Days < 1 {
+		return fmt.Errorf("days must be >= 1 (got %d)", g.Days)
+	}
+	if len(g.Routine) == 0 {
+		return fmt.Errorf("routine needs at least one step")
+	}
+
+	for i, step := range g.Routine {
+		if _, err := time.Parse("15:04", step.At); err != nil {
+			return fmt.Errorf("routine step %d: at must be HH:MM, got %q", i, step.At)
+		}
+		if step.Location == "" {
+			return fmt.Errorf("routine step %d: location is required", i)
+		}
+		if step.Duration <= 0 {
+			return fmt.Errorf("routine step %d: duration must be positive", i)
+		}
+		switch step.On {
+		case "", "daily", "weekdays", "weekends":
+		default:
+			return fmt.Errorf("routine step %d: on must be daily, weekdays or weekends, got %q", i, step.On)
+		}
+		if step.Probability < 0 || step.Probability > 1 {
+			return fmt.Errorf("routine step %d: probability must be between 0 and 1", i)
+		}
+	}
+
+	n := g.Noise
+	if n.StartJitter < 0 || n.DurationJitter < 0 || n.MotionInterval < 0 || n.FalseMotions < 0 {
+		return fmt.Errorf("noise values cannot be negative")
+	}
+	if n.MotionDropout < 0 || n.MotionDropout >= 1 {
+		return fmt.Errorf("noise.motion_dropout must be in [0, 1)")
+	}
+
+	return nil
+}
+
+func validateChaos(steps []ChaosStep) error {
+	for i, step := range steps {
+		if step.Time < 0 {
+			return fmt.Errorf("chaos step %d: time cannot be negative", i)
+		}
+
+		switch step.Fault {
+		case chaos.FaultMQTTDrop, chaos.FaultRedisError:
+		case chaos.FaultLLMDelay:
+			if step.Delay <= 0 {
+				return fmt.Errorf("chaos step %d: llm_delay needs a positive delay", i)
+			}
+		default:
+			return fmt.Errorf("chaos step %d: unknown fault %q (use mqtt_drop, redis_error or llm_delay)", i, step.Fault)
+		}
+
+		if step.Duration < time.Second {
+			return fmt.Errorf("chaos step %d: duration must be at least 1s", i)
+		}
+
+		if step.Description == "" {
+			return fmt.Errorf("chaos step %d: description is required", i)
+		}
+	}
+
+	return nil
+}
+
 func validateWaitPeriods(waits []WaitPeriod) error {
 	for i, wait := range waits {
 		if wait.Time < 0 {
@@ -113,8 +197,8 @@ func validateExpectations(expectations map[string][]Expectation) error {
 				return fmt.Errorf("layer %s, expectation %d: time cannot be negative", layer, i)
 			}
 
-			if exp.Topic == "" && exp.PostgresQuery == "" {
-				return fmt.Errorf("layer %s, expectation %d: either topic or postgres_query is required", layer, i)
+			if exp.Topic == "" && exp.PostgresQuery == "" && exp.Assert == nil {
+				return fmt.Errorf("layer %s, expectation %d: one of topic, postgres_query or assert is required", layer, i)
 			}
 
 			// MQTT expectations: payload or redis checks
@@ -140,6 +224,51 @@ func validateExpectations(expectations map[string][]Expectation) error {
 			if exp.PostgresQuery != "" && exp.PostgresExpected == nil {
 				return fmt.Errorf("layer %s, expectation %d: postgres_expected is required when postgres_query is specified", layer, i)
 			}
+
+			if exp.Assert != nil {
+				if err := validateAssertion(exp.Assert); err != nil {
+					return fmt.Errorf("layer %s, expectation %d: %w", layer, i, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateAssertion(a *StateAssertion) error {
+	if len(a.Episodes)+len(a.MacroEpisodes)+len(a.Anchors)+len(a.Patterns) == 0 {
+		return fmt.Errorf("assert needs at least one of episodes, macro_episodes, anchors or patterns")
+	}
+
+	for i, e := range a.Episodes {
+		if e.Since != "" {
+			if _, err := time.Parse(time.RFC3339, e.Since); err != nil {
+				return fmt.Errorf("assert.episodes[%d]: since must be an RFC3339 timestamp: %w", i, err)
+			}
+		}
+		if err := e.Count.validate(); err != nil {
+			return fmt.Errorf("assert.episodes[%d].count: %w", i, err)
+		}
+	}
+	for i, m := range a.MacroEpisodes {
+		for field, r := range map[string]*Range{"count": m.Count, "duration_minutes": m.DurationMinutes} {
+			if err := r.validate(); err != nil {
+				return fmt.Errorf("assert.macro_episodes[%d].%s: %w", i, field, err)
+			}
+		}
+	}
+	for i, an := range a.Anchors {
+		if err := an.Count.validate(); err != nil {
+			return fmt.Errorf("assert.anchors[%d].count: %w", i, err)
+		}
+	}
+	for i, p := range a.Patterns {
+		ranges := map[string]*Range{"count": p.Count, "weight": p.Weight, "observations": p.Observations, "cluster_size": p.ClusterSize}
+		for field, r := range ranges {
+			if err := r.validate(); err != nil {
+				return fmt.Errorf("assert.patterns[%d].%s: %w", i, field, err)
+			}
 		}
 	}
 
@@ -0,0 +1,257 @@
+// Package backfill replays historical sensor data into the collector's Redis
+// schema and drives behavior consolidation and pattern discovery over it.
+package backfill
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Input formats
+const (
+	FormatCapture = "mqtt" // MQTT capture (JSON array or JSON lines of {timestamp, topic, payload})
+	FormatCSV     = "csv"  // Home Assistant history export (entity_id, state, last_changed)
+)
+
+const rawTopicPrefix = "automation/raw/"
+
+// Event is a single historical sensor message
+type Event struct {
+	Timestamp time.Time
+	Topic     string // automation/raw/{sensor_type}/{location}
+	Payload   []byte // {"data": {...}}
+}
+
+// capturedMessage matches the e2e observer's capture format
+type capturedMessage struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Topic     string          `json:"topic"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// ReadFile loads events from path, sorted by timestamp. An empty format is
+// detected from the file extension (.csv → csv, anything else → mqtt).
+func ReadFile(path, format string) ([]Event, error) {
+	if format == "" {
+		format = FormatCapture
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			format = FormatCSV
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	switch format {
+	case FormatCapture:
+		events, err = ReadCapture(f)
+	case FormatCSV:
+		events, err = ReadCSV(f)
+	default:
+		return nil, fmt.Errorf("unknown input format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	return events, nil
+}
+
+// ReadCapture parses an MQTT capture, keeping only automation/raw/* messages
+func ReadCapture(r io.Reader) ([]Event, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+
+	var messages []capturedMessage
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("failed to parse capture: %w", err)
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var msg capturedMessage
+			if err := decoder.Decode(&msg); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("failed to parse capture line %d: %w", len(messages)+1, err)
+			}
+			messages = append(messages, msg)
+		}
+	}
+
+	events := make([]Event, 0, len(messages))
+	for _, msg := range messages {
+		if !strings.HasPrefix(msg.Topic, rawTopicPrefix) || msg.Timestamp.IsZero() {
+			continue
+		}
+
+		// Non-JSON payloads are captured as JSON strings
+		payload := []byte(msg.Payload)
+		var text string
+		if json.Unmarshal(payload, &text) == nil {
+			payload = []byte(text)
+		}
+
+		events = append(events, Event{
+			Timestamp: msg.Timestamp,
+			Topic:     msg.Topic,
+			Payload:   payload,
+		})
+	}
+
+	return events, nil
+}
+
+// ReadCSV parses a Home Assistant history export. Rows for entities that don't
+// map onto a J.E.E.V.E.S. sensor type, or with non-numeric environmental
+// states (unavailable, unknown), are skipped.
+func ReadCSV(r io.Reader) ([]Event, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"entity_id", "state", "last_changed"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing required column %q", required)
+		}
+	}
+
+	var events []Event
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		timestamp, err := time.Parse(time.RFC3339Nano, field("last_changed"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid last_changed on CSV line %d: %w", line, err)
+		}
+
+		event, ok := entityEvent(field("entity_id"), field("state"), timestamp)
+		if ok {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// locationStopwords are entity ID tokens that describe the device rather than the room
+var locationStopwords = map[string]bool{
+	"motion": true, "occupancy": true, "sensor": true, "illuminance": true,
+	"lux": true, "temperature": true, "temp": true, "light": true,
+	"lights": true, "lamp": true, "media": true, "player": true, "tv": true,
+}
+
+// entityEvent maps a Home Assistant entity state change onto a raw sensor event
+func entityEvent(entityID, state string, timestamp time.Time) (Event, bool) {
+	domain, objectID, ok := strings.Cut(entityID, ".")
+	if !ok || objectID == "" {
+		return Event{}, false
+	}
+
+	var sensorType string
+	var data map[string]interface{}
+
+	switch {
+	case domain == "binary_sensor" && (strings.Contains(objectID, "motion") || strings.Contains(objectID, "occupancy")):
+		sensorType = "motion"
+		data = map[string]interface{}{"state": state, "entity_id": entityID}
+
+	case domain == "light":
+		// History exports carry no trigger source; pre-J.E.E.V.E.S. changes are treated as manual
+		sensorType = "lighting"
+		data = map[string]interface{}{"state": state, "source": "manual"}
+
+	case domain == "media_player":
+		sensorType = "media"
+		data = map[string]interface{}{"state": state}
+
+	case domain == "sensor" && (strings.Contains(objectID, "illuminance") || strings.Contains(objectID, "lux")):
+		value, err := strconv.ParseFloat(state, 64)
+		if err != nil {
+			return Event{}, false
+		}
+		sensorType = "illuminance"
+		data = map[string]interface{}{"value": value, "unit": "lx"}
+
+	case domain == "sensor" && (strings.Contains(objectID, "temperature") || strings.Contains(objectID, "temp")):
+		value, err := strconv.ParseFloat(state, 64)
+		if err != nil {
+			return Event{}, false
+		}
+		sensorType = "temperature"
+		data = map[string]interface{}{"value": value, "unit": "°C"}
+
+	default:
+		return Event{}, false
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return Event{}, false
+	}
+
+	return Event{
+		Timestamp: timestamp,
+		Topic:     rawTopicPrefix + sensorType + "/" + entityLocation(objectID),
+		Payload:   payload,
+	}, true
+}
+
+// entityLocation derives a location from an entity object ID by dropping
+// device tokens, e.g. "kitchen_motion_sensor" → "kitchen"
+func entityLocation(objectID string) string {
+	var parts []string
+	for _, token := range strings.Split(objectID, "_") {
+		if token != "" && !locationStopwords[token] {
+			parts = append(parts, token)
+		}
+	}
+	if len(parts) == 0 {
+		return objectID
+	}
+	return strings.Join(parts, "_")
+}
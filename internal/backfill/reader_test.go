@@ -0,0 +1,91 @@
+package backfill
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReadCapture(t *testing.T) {
+	array := `[
+  {"timestamp": "2025-03-01T07:00:00Z", "topic": "automation/raw/motion/kitchen", "payload": {"data": {"state": "on"}}, "qos": 0},
+  {"timestamp": "2025-03-01T07:00:01Z", "topic": "automation/sensor/motion/kitchen", "payload": {"data": {}}, "qos": 0}
+]`
+	lines := `{"timestamp": "2025-03-01T07:00:00Z", "topic": "automation/raw/motion/kitchen", "payload": {"data": {"state": "on"}}}
+{"timestamp": "2025-03-01T07:05:00Z", "topic": "automation/raw/illuminance/kitchen", "payload": "{\"data\": {\"value\": 120}}"}
+`
+
+	events, err := ReadCapture(strings.NewReader(array))
+	if err != nil {
+		t.Fatalf("ReadCapture(array) error: %v", err)
+	}
+	if len(events) != 1 || events[0].Topic != "automation/raw/motion/kitchen" {
+		t.Fatalf("expected only the raw motion event, got %+v", events)
+	}
+
+	events, err = ReadCapture(strings.NewReader(lines))
+	if err != nil {
+		t.Fatalf("ReadCapture(lines) error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	// String payloads are unwrapped to their JSON content
+	var payload map[string]map[string]float64
+	if err := json.Unmarshal(events[1].Payload, &payload); err != nil {
+		t.Fatalf("string payload not unwrapped: %v", err)
+	}
+	if payload["data"]["value"] != 120 {
+		t.Errorf("expected value 120, got %v", payload["data"]["value"])
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	csv := `entity_id,state,last_changed
+binary_sensor.kitchen_motion,on,2025-03-01T07:00:00.000Z
+sensor.living_room_temperature,21.5,2025-03-01T07:01:00.000Z
+sensor.living_room_temperature,unavailable,2025-03-01T07:02:00.000Z
+light.bedroom_ceiling_lamp,off,2025-03-01T23:10:00.000Z
+switch.coffee_maker,on,2025-03-01T07:03:00.000Z
+`
+
+	events, err := ReadCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ReadCSV error: %v", err)
+	}
+
+	expected := []string{
+		"automation/raw/motion/kitchen",
+		"automation/raw/temperature/living_room",
+		"automation/raw/lighting/bedroom_ceiling",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i, topic := range expected {
+		if events[i].Topic != topic {
+			t.Errorf("event %d: expected topic %s, got %s", i, topic, events[i].Topic)
+		}
+	}
+}
+
+func TestReadCSV_MissingColumn(t *testing.T) {
+	if _, err := ReadCSV(strings.NewReader("entity_id,state\nlight.kitchen,on\n")); err == nil {
+		t.Error("expected error for missing last_changed column")
+	}
+}
+
+func TestEntityLocation(t *testing.T) {
+	tests := map[string]string{
+		"kitchen_motion":        "kitchen",
+		"motion_sensor_hallway": "hallway",
+		"living_room_lux":       "living_room",
+		"motion":                "motion",
+	}
+	for objectID, want := range tests {
+		if got := entityLocation(objectID); got != want {
+			t.Errorf("entityLocation(%q) = %q, want %q", objectID, got, want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/collector"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+const (
+	timeConfigTopic         = "automation/test/time_config"
+	consolidationDoneTopic  = events.ConsolidationCompletedTopic
+	discoverPatternsTopic   = "automation/behavior/discover_patterns"
+	patternsDiscoveredTopic = events.PatternDiscoveryCompletedTopic
+)
+
+// Options controls how a replay is driven
+type Options struct {
+	Window      time.Duration // Consolidation window; at most 24h (collector retention)
+	Consolidate bool          // Trigger consolidation after each window
+	Discover    bool          // Trigger pattern discovery once all windows are consolidated
+	MinAnchors  int           // min_anchors for the discovery trigger
+	WaitTimeout time.Duration // How long to wait for each completion message
+}
+
+// Summary reports what a replay did
+type Summary struct {
+	EventsStored   int
+	EventsSkipped  int
+	Windows        int
+	Consolidations int // Windows whose consolidation completed before the timeout
+	Discovered     bool
+}
+
+// Replayer writes historical events to Redis through the collector's storage
+// path, with the collector's virtual clock pinned to each event's timestamp,
+// and steps the behavior agent's virtual clock window by window.
+type Replayer struct {
+	mqtt        mqtt.Client
+	options     Options
+	logger      *slog.Logger
+	timeManager *clock.TimeManager
+	processor   *collector.Processor
+	storage     *collector.Storage
+
+	consolidated chan struct{}
+	discovered   chan struct{}
+}
+
+// quietClient drops publishes from collector storage so replayed history
+// doesn't fire live sensor triggers (lights, occupancy) for past events
+type quietClient struct {
+	mqtt.Client
+}
+
+func (quietClient) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	return nil
+}
+
+// NewReplayer creates a replayer. mqttClient must already be connected.
+func NewReplayer(cfg *config.Config, redisClient redis.Client, mqttClient mqtt.Client, options Options, logger *slog.Logger) *Replayer {
+	logger = logger.With("component", "backfill")
+	timeManager := clock.NewTimeManager(logger)
+	processor := collector.NewProcessor(logger, timeManager)
+
+	return &Replayer{
+		mqtt:         mqttClient,
+		options:      options,
+		logger:       logger,
+		timeManager:  timeManager,
+		processor:    processor,
+		storage:      collector.NewStorage(redisClient, quietClient{mqttClient}, cfg, logger, timeManager),
+		consolidated: make(chan struct{}, 1),
+		discovered:   make(chan struct{}, 1),
+	}
+}
+
+// Run replays events (sorted by timestamp) and returns a summary
+func (r *Replayer) Run(ctx context.Context, events []Event) (*Summary, error) {
+	summary := &Summary{}
+	if len(events) == 0 {
+		return summary, nil
+	}
+
+	if r.options.Window <= 0 || r.options.Window > 24*time.Hour {
+		return nil, fmt.Errorf("window must be between 0 and 24h, got %s", r.options.Window)
+	}
+
+	if r.options.Consolidate {
+		if err := r.mqtt.Subscribe(consolidationDoneTopic, 0, notifyHandler(r.consolidated)); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to consolidation results: %w", err)
+		}
+	}
+	if r.options.Discover {
+		if err := r.mqtt.Subscribe(patternsDiscoveredTopic, 0, notifyHandler(r.discovered)); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to discovery results: %w", err)
+		}
+	}
+
+	// Hand the behavior agent back to real time however the replay ends
+	defer r.publishTimeConfig(time.Time{}, false)
+
+	first := events[0].Timestamp
+	windowEnd := first.Truncate(r.options.Window).Add(r.options.Window)
+
+	r.logger.Info("Starting backfill",
+		"events", len(events),
+		"from", first.Format(time.RFC3339),
+		"to", events[len(events)-1].Timestamp.Format(time.RFC3339),
+		"window", r.options.Window)
+
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		if !event.Timestamp.Before(windowEnd) {
+			r.closeWindow(ctx, windowEnd, summary)
+			// Skip empty windows entirely
+			windowEnd = event.Timestamp.Truncate(r.options.Window).Add(r.options.Window)
+		}
+
+		r.timeManager.SetVirtualTime(event.Timestamp)
+
+		msg, err := r.processor.ParseMessage(event.Topic, event.Payload)
+		if err != nil {
+			summary.EventsSkipped++
+			continue
+		}
+		if err := r.storage.StoreSensorData(ctx, msg, r.processor); err != nil {
+			r.logger.Warn("Failed to store event", "topic", event.Topic, "error", err)
+			summary.EventsSkipped++
+			continue
+		}
+		summary.EventsStored++
+	}
+
+	r.closeWindow(ctx, windowEnd, summary)
+
+	if r.options.Discover {
+		lookback := windowEnd.Sub(first)
+		summary.Discovered = r.triggerDiscovery(ctx, lookback)
+	}
+
+	r.logger.Info("Backfill complete",
+		"events_stored", summary.EventsStored,
+		"events_skipped", summary.EventsSkipped,
+		"windows", summary.Windows,
+		"consolidations", summary.Consolidations,
+		"discovered", summary.Discovered)
+
+	return summary, nil
+}
+
+// closeWindow moves the behavior agent's clock to the end of the window and
+// consolidates it
+func (r *Replayer) closeWindow(ctx context.Context, end time.Time, summary *Summary) {
+	summary.Windows++
+	if !r.options.Consolidate {
+		return
+	}
+
+	r.publishTimeConfig(end, true)
+
+	trigger := events.ConsolidationTrigger{
+		Action:        events.ConsolidateAction,
+		LookbackHours: int(math.Ceil(r.options.Window.Hours())),
+		Location:      "universe",
+	}
+
+	r.logger.Info("Consolidating window", "window_end", end.Format(time.RFC3339))
+
+	if r.publishAndWait(ctx, trigger.Topic(), trigger, r.consolidated) {
+		summary.Consolidations++
+	} else {
+		r.logger.Warn("Consolidation did not complete before timeout",
+			"window_end", end.Format(time.RFC3339),
+			"timeout", r.options.WaitTimeout)
+	}
+}
+
+// triggerDiscovery runs pattern discovery over the whole replayed span
+func (r *Replayer) triggerDiscovery(ctx context.Context, lookback time.Duration) bool {
+	trigger := map[string]interface{}{
+		"min_anchors":    r.options.MinAnchors,
+		"lookback_hours": int(math.Ceil(lookback.Hours())),
+	}
+
+	r.logger.Info("Triggering pattern discovery", "lookback", lookback)
+
+	if !r.publishAndWait(ctx, discoverPatternsTopic, trigger, r.discovered) {
+		r.logger.Warn("Pattern discovery did not complete before timeout",
+			"timeout", r.options.WaitTimeout)
+		return false
+	}
+	return true
+}
+
+// publishAndWait publishes a trigger and waits for its completion signal
+func (r *Replayer) publishAndWait(ctx context.Context, topic string, trigger interface{}, done chan struct{}) bool {
+	// Drop any stale completion from an earlier run
+	select {
+	case <-done:
+	default:
+	}
+
+	payload, err := json.Marshal(trigger)
+	if err != nil {
+		r.logger.Error("Failed to marshal trigger", "topic", topic, "error", err)
+		return false
+	}
+	if err := r.mqtt.Publish(topic, 0, false, payload); err != nil {
+		r.logger.Error("Failed to publish trigger", "topic", topic, "error", err)
+		return false
+	}
+
+	timer := time.NewTimer(r.options.WaitTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// publishTimeConfig sets the behavior agent's virtual clock (or disables test mode)
+func (r *Replayer) publishTimeConfig(virtualStart time.Time, testMode bool) {
+	config := map[string]interface{}{
+		"test_mode": testMode,
+	}
+	if testMode {
+		config["virtual_start"] = virtualStart.Format(time.RFC3339)
+		config["time_scale"] = 1
+	}
+
+	payload, _ := json.Marshal(config)
+	if err := r.mqtt.Publish(timeConfigTopic, 1, false, payload); err != nil {
+		r.logger.Error("Failed to publish time config", "error", err)
+	}
+}
+
+// notifyHandler signals done (without blocking) whenever a message arrives
+func notifyHandler(done chan struct{}) mqtt.MessageHandler {
+	return func(msg mqtt.Message) {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package adjacency
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
+)
+
+// TimeManager interface for getting current time (real or virtual)
+type TimeManager interface {
+	Now() time.Time
+}
+
+// Config configures adjacency learning
+type Config struct {
+	Interval       time.Duration // how often the adjacency map is refreshed
+	LookbackDays   int           // how much episode history to count transitions over
+	MaxGap         time.Duration // longest gap between episodes counted as a direct transition
+	MinTransitions int           // minimum transitions for a pair to count as adjacent
+	MinShare       float64       // minimum share of either room's transitions
+}
+
+// Transition counts how often people moved directly between two rooms
+type Transition struct {
+	Location1 string  `json:"location1"`
+	Location2 string  `json:"location2"`
+	Count     int     `json:"count"`
+	AvgGapSec float64 `json:"avg_gap_sec"`
+	Adjacent  bool    `json:"adjacent"`
+}
+
+// Learner derives the adjacency graph from observed episode transitions. Rooms
+// people frequently move directly between are treated as adjacent, in addition
+// to the configured topology.
+type Learner struct {
+	config      Config
+	db          *sql.DB
+	base        *ontology.Topology
+	mqtt        mqtt.Client
+	logger      *slog.Logger
+	timeManager TimeManager
+
+	mu       sync.RWMutex
+	current  *ontology.Topology
+	onUpdate []func(*ontology.Topology)
+
+	triggers chan struct{}
+}
+
+// NewLearner creates a new adjacency learner on top of the configured topology
+func NewLearner(
+	config Config,
+	db *sql.DB,
+	base *ontology.Topology,
+	mqttClient mqtt.Client,
+	logger *slog.Logger,
+	timeManager TimeManager,
+) *Learner {
+	return &Learner{
+		config:      config,
+		db:          db,
+		base:        base,
+		mqtt:        mqttClient,
+		logger:      logger.With("component", "adjacency_learner"),
+		timeManager: timeManager,
+		current:     base,
+		triggers:    make(chan struct{}, 1),
+	}
+}
+
+// OnUpdate registers a callback invoked with the merged topology after each refresh
+func (l *Learner) OnUpdate(fn func(*ontology.Topology)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onUpdate = append(l.onUpdate, fn)
+}
+
+// Topology returns the configured topology merged with learned adjacency
+func (l *Learner) Topology() *ontology.Topology {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// Start refreshes once, then on the configured interval and on MQTT triggers
+func (l *Learner) Start(ctx context.Context) error {
+	if err := l.mqtt.Subscribe("automation/behavior/learn_adjacency", 0, l.handleTrigger); err != nil {
+		return fmt.Errorf("failed to subscribe to triggers: %w", err)
+	}
+
+	l.logger.Info("Adjacency learner started",
+		"interval", l.config.Interval,
+		"lookback_days", l.config.LookbackDays,
+		"min_transitions", l.config.MinTransitions,
+		"min_share", l.config.MinShare)
+
+	if _, err := l.Refresh(ctx); err != nil {
+		l.logger.Error("Adjacency refresh failed", "error", err)
+	}
+
+	ticker := time.NewTicker(l.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-l.triggers:
+		}
+
+		if _, err := l.Refresh(ctx); err != nil {
+			l.logger.Error("Adjacency refresh failed", "error", err)
+		}
+	}
+}
+
+func (l *Learner) handleTrigger(msg mqtt.Message) {
+	select {
+	case l.triggers <- struct{}{}:
+	default:
+		// A refresh is already queued
+	}
+}
+
+// Refresh recounts transitions, stores them, and publishes the merged topology
+// to registered consumers
+func (l *Learner) Refresh(ctx context.Context) ([]Transition, error) {
+	since := l.timeManager.Now().AddDate(0, 0, -l.config.LookbackDays)
+
+	transitions, err := l.countTransitions(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	learnAdjacency(transitions, l.config.MinTransitions, l.config.MinShare)
+
+	if err := l.saveTransitions(ctx, transitions); err != nil {
+		return nil, err
+	}
+
+	var pairs [][2]string
+	for _, t := range transitions {
+		if t.Adjacent {
+			pairs = append(pairs, [2]string{t.Location1, t.Location2})
+		}
+	}
+	merged := l.base.WithAdjacent(pairs)
+
+	l.mu.Lock()
+	l.current = merged
+	callbacks := append([]func(*ontology.Topology){}, l.onUpdate...)
+	l.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(merged)
+	}
+
+	l.logger.Info("Adjacency refreshed",
+		"since", since,
+		"pairs_observed", len(transitions),
+		"pairs_learned", len(pairs),
+		"adjacent_pairs", len(merged.AdjacentPairs()))
+
+	return transitions, nil
+}
+
+// learnAdjacency marks transitions as adjacent when the pair was observed at
+// least minTransitions times and accounts for at least minShare of either
+// room's transitions. The share test filters out pairs that only pass the
+// count threshold because the household has a lot of history.
+func learnAdjacency(transitions []Transition, minTransitions int, minShare float64) {
+	totals := make(map[string]int)
+	for _, t := range transitions {
+		totals[t.Location1] += t.Count
+		totals[t.Location2] += t.Count
+	}
+
+	for i := range transitions {
+		t := &transitions[i]
+		if t.Count < minTransitions {
+			t.Adjacent = false
+			continue
+		}
+		share1 := float64(t.Count) / float64(totals[t.Location1])
+		share2 := float64(t.Count) / float64(totals[t.Location2])
+		t.Adjacent = share1 >= minShare || share2 >= minShare
+	}
+}
+
+// countTransitions counts consecutive episodes in different rooms, in both
+// directions combined, with location1 < location2
+func (l *Learner) countTransitions(ctx context.Context, since time.Time) ([]Transition, error) {
+	query := `
+		WITH ordered AS (
+			SELECT
+				location,
+				started_at_text::timestamptz AS started_at,
+				LAG(location) OVER w AS prev_location,
+				LAG(COALESCE(ended_at_text, started_at_text)::timestamptz) OVER w AS prev_ended_at
+			FROM behavioral_episodes
+			WHERE location IS NOT NULL
+			  AND started_at_text::timestamptz >= $1
+			WINDOW w AS (ORDER BY started_at_text::timestamptz)
+		)
+		SELECT
+			LEAST(prev_location, location) AS location1,
+			GREATEST(prev_location, location) AS location2,
+			COUNT(*) AS transition_count,
+			AVG(GREATEST(EXTRACT(EPOCH FROM (started_at - prev_ended_at)), 0)) AS avg_gap_sec
+		FROM ordered
+		WHERE prev_location IS NOT NULL
+		  AND prev_location <> location
+		  AND started_at - prev_ended_at <= make_interval(secs => $2)
+		GROUP BY 1, 2
+	`
+
+	rows, err := l.db.QueryContext(ctx, query, since, l.config.MaxGap.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count transitions: %w", err)
+	}
+	defer rows.Close()
+
+	var transitions []Transition
+	for rows.Next() {
+		var t Transition
+		if err := rows.Scan(&t.Location1, &t.Location2, &t.Count, &t.AvgGapSec); err != nil {
+			return nil, fmt.Errorf("failed to scan transition: %w", err)
+		}
+		transitions = append(transitions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transitions: %w", err)
+	}
+
+	sort.Slice(transitions, func(i, j int) bool {
+		return transitions[i].Count > transitions[j].Count
+	})
+	return transitions, nil
+}
+
+// saveTransitions replaces the stored transition counts with a fresh snapshot
+func (l *Learner) saveTransitions(ctx context.Context, transitions []Transition) error {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM location_transitions`); err != nil {
+		return fmt.Errorf("failed to clear transitions: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO location_transitions (location1, location2, transition_count, avg_gap_sec, adjacent, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, t := range transitions {
+		if _, err := stmt.ExecContext(ctx, t.Location1, t.Location2, t.Count, t.AvgGapSec, t.Adjacent); err != nil {
+			return fmt.Errorf("failed to save transition %s|%s: %w", t.Location1, t.Location2, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transitions: %w", err)
+	}
+	return nil
+}
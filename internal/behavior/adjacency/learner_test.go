@@ -0,0 +1,30 @@
+package adjacency
+
+import "testing"
+
+func TestLearnAdjacency(t *testing.T) {
+	transitions := []Transition{
+		{Location1: "dining_room", Location2: "kitchen", Count: 400},
+		{Location1: "bedroom", Location2: "garage", Count: 2},
+		{Location1: "kitchen", Location2: "living_room", Count: 300},
+		{Location1: "garage", Location2: "hallway", Count: 30},
+		// Frequent in absolute terms but a tiny share of both rooms' traffic
+		{Location1: "dining_room", Location2: "living_room", Count: 12},
+	}
+
+	learnAdjacency(transitions, 10, 0.05)
+
+	want := map[string]bool{
+		"dining_room|kitchen":     true,
+		"bedroom|garage":          false,
+		"kitchen|living_room":     true,
+		"garage|hallway":          true,
+		"dining_room|living_room": false,
+	}
+	for _, tr := range transitions {
+		key := tr.Location1 + "|" + tr.Location2
+		if tr.Adjacent != want[key] {
+			t.Errorf("%s: adjacent = %v, want %v", key, tr.Adjacent, want[key])
+		}
+	}
+}
@@ -6,22 +6,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/adjacency"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/anchor"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/clustering"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/distance"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/embedding"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/patterns"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/prediction"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/prompts"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/progress"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/internal/notify"
+	"github.com/saaga0h/jeeves-platform/pkg/auth"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/encryption"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
 	"github.com/saaga0h/jeeves-platform/pkg/llm"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+	"github.com/saaga0h/jeeves-platform/pkg/metrics"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 	"github.com/saaga0h/jeeves-platform/pkg/ontology"
 	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+	"github.com/saaga0h/jeeves-platform/pkg/privacy"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
 
@@ -32,24 +47,70 @@ type Agent struct {
 	cfg      *config.Config
 	logger   *slog.Logger
 
-	timeManager         *TimeManager      // NEW
+	timeManager         *clock.TimeManager      // NEW
+
+	// Scheduled runs; nil consolidation schedule leaves consolidation to triggers,
+	// nil discovery schedule runs every PatternDiscoveryIntervalHours
+	consolidationSchedule clock.Schedule
+	discoverySchedule     clock.Schedule
+
 	activeEpisodes      map[string]string // location → episode ID
 	lastEpisodeEndTime  map[string]time.Time // location → when last episode ended
 	lastOccupancyState  map[string]string // location → "occupied" | "empty"
 	lastLightState      map[string]string // location → "on" | "off" | "unknown"
+	sleepPeriods        []SleepPeriod     // recently detected sleep periods
+	reportedDropouts    map[string]time.Time // sensor dropouts already published
 	stateMux            sync.RWMutex
 
 	// Semantic anchor system (optional - Phase 3)
 	anchorCreator       *anchor.AnchorCreator
+	rhythmProfiler      *embedding.RhythmProfiler // nil when the rhythm block uses heuristics
 
 	// Pattern discovery system (optional - Phase 4)
 	distanceAgent       *distance.ComputationAgent
 	clusteringEngine    *clustering.ClusteringEngine
 	patternInterpreter  *patterns.PatternInterpreter
 	discoveryAgent      *patterns.DiscoveryAgent
+	lifecycleManager    *patterns.LifecycleManager
+	predictionEngine    *prediction.Engine
 
 	// Batch processing coordinator (optional - Phase 5)
 	batchCoordinator    *BatchCoordinator
+
+	// Household away/vacation detection (optional)
+	houseState          *HouseStateDetector
+
+	// HTTP API for episode annotations (optional)
+	apiServer           *http.Server
+	apiAuth             auth.Authenticator
+	jobs                *jobTracker
+	jobGate             *jobGate
+
+	// Home layout (rooms, floors, adjacency)
+	topology            *ontology.Topology
+	adjacencyLearner    *adjacency.Learner
+
+	// Webhook delivery of behavior events (nil when not configured)
+	notifier            *notify.Notifier
+
+	// VictoriaMetrics forwarding (nil when disabled)
+	metrics             *metrics.Writer
+
+	// LLM prompt templates (built-in, overridable from JEEVES_LLM_PROMPT_DIR)
+	prompts             *llm.Prompts
+
+	// Ollama client routing each task to its configured model
+	llmRouter           *llm.Router
+	llmMonitor          *LLMMonitor // nil when probing is disabled
+
+	// Wakes the episode event relay after an outbox insert
+	outboxWake          chan struct{}
+
+	// Locations and sensor types never learned from (nil when not configured)
+	privacy             *privacy.Zones
+
+	// Encrypts episode documents and anchor context (nil when not configured)
+	sealer              *encryption.Sealer
 }
 
 // Event represents a sensor event used for episode detection and anchor creation
@@ -59,20 +120,92 @@ type Event struct {
 	Type      string // "motion", "presence", "lighting"
 	State     string // "on"/"off" for motion, "occupied"/"empty" for presence
 	Source    string // "manual"/"automated" for lighting events
+	Occupant  string // attributed from presence events, "" when unknown
+	Device    string // entity_id of the reporting sensor, "" when unknown
 }
 
 func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, pgClient postgres.Client, cfg *config.Config, logger *slog.Logger) (*Agent, error) {
+	topology, err := ontology.LoadTopologyOrDefault(cfg.HomeTopologyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load home topology: %w", err)
+	}
+
+	notifier, err := notify.NewFromFile(cfg.WebhookConfigPath, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	promptSet, err := prompts.New(cfg.LLMPromptDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load LLM prompts: %w", err)
+	}
+
+	sealer, err := encryption.NewSealer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption key: %w", err)
+	}
+
+	// Same tokens/OIDC provider as the observer API
+	apiAuth, err := auth.New(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API auth configuration: %w", err)
+	}
+
+	var consolidationSchedule, discoverySchedule clock.Schedule
+	if cfg.ConsolidationSchedule == "" && cfg.ConsolidationIntervalHours > 0 {
+		logger.Warn("JEEVES_CONSOLIDATION_INTERVAL_HOURS is deprecated, use JEEVES_CONSOLIDATION_SCHEDULE",
+			"schedule", cfg.EffectiveConsolidationSchedule())
+	}
+	if spec := cfg.EffectiveConsolidationSchedule(); spec != "" {
+		if consolidationSchedule, err = clock.ParseSchedule(spec); err != nil {
+			return nil, fmt.Errorf("invalid consolidation schedule: %w", err)
+		}
+	}
+	if cfg.PatternDiscoverySchedule != "" {
+		if discoverySchedule, err = clock.ParseSchedule(cfg.PatternDiscoverySchedule); err != nil {
+			return nil, fmt.Errorf("invalid pattern discovery schedule: %w", err)
+		}
+	}
+
+	llmRouter := llm.NewRouter(llm.NewOllamaClient(cfg.LLMEndpoint, logger), cfg.LLMModel, map[string]string{
+		llm.TaskDistance:       cfg.LLMDistanceModel,
+		llm.TaskConsolidation:  cfg.LLMConsolidationModel,
+		llm.TaskInterpretation: cfg.LLMInterpretationModel,
+	})
+
 	agent := &Agent{
 		mqtt:               mqttClient,
 		redis:              redisClient,
 		pgClient:           pgClient,
 		cfg:                cfg,
 		logger:             logger,
-		timeManager:        NewTimeManager(logger),
+		timeManager:        clock.NewTimeManager(logger),
+		consolidationSchedule: consolidationSchedule,
+		discoverySchedule:     discoverySchedule,
 		activeEpisodes:     make(map[string]string),
 		lastEpisodeEndTime: make(map[string]time.Time),
 		lastOccupancyState: make(map[string]string),
 		lastLightState:     make(map[string]string),
+		reportedDropouts:   make(map[string]time.Time),
+		topology:           topology,
+		jobs:               newJobTracker(logger),
+		notifier:           notifier,
+		metrics:            metrics.NewFromConfig(cfg, logger),
+		prompts:            promptSet,
+		llmRouter:          llmRouter,
+		llmMonitor:         NewLLMMonitor(cfg, llmRouter, mqttClient, logger),
+		outboxWake:         make(chan struct{}, 1),
+		privacy:            privacy.NewZones(cfg),
+		sealer:             sealer,
+		apiAuth:            apiAuth,
+	}
+
+	// One consolidation and one discovery run at a time, across agents
+	agent.jobGate = newJobGate(redisClient, mqttClient, agent.timeManager, cfg.ServiceName, logger)
+
+	// Initialize house state detection if enabled
+	if cfg.HouseStateEnabled {
+		agent.houseState = NewHouseStateDetector(cfg, mqttClient, agent.timeManager, logger)
 	}
 
 	// Initialize pattern discovery if enabled
@@ -96,6 +229,7 @@ func (a *Agent) initializePatternDiscovery() error {
 	a.logger.Info("Initializing pattern discovery system",
 		"strategy", a.cfg.PatternDistanceStrategy,
 		"interval_hours", a.cfg.PatternDiscoveryIntervalHours,
+		"algorithm", a.cfg.PatternClusteringAlgorithm,
 		"epsilon", a.cfg.PatternClusteringEpsilon,
 		"min_points", a.cfg.PatternClusteringMinPoints)
 
@@ -108,24 +242,47 @@ func (a *Agent) initializePatternDiscovery() error {
 	// Create storage instance (will be used by multiple components)
 	anchorStorage := a.createAnchorStorage(db)
 
-	// Create LLM client for pattern interpretation and distance computation
-	llmClient := llm.NewOllamaClient(a.cfg.LLMEndpoint, a.logger)
+	// Pattern interpretation and distance computation use their own models
+	llmClient := a.llmRouter
 
 	// Initialize distance computation agent
 	distanceConfig := distance.ComputationConfig{
 		Strategy:  a.cfg.PatternDistanceStrategy,
-		Model:     a.cfg.LLMModel,
+		Model:     llmClient.Model(llm.TaskDistance),
 		BatchSize: a.cfg.PatternDiscoveryBatchSize,
 		Interval:  time.Duration(a.cfg.PatternDiscoveryIntervalHours) * time.Hour,
+		Jitter:    a.cfg.ScheduleJitter,
+		Prompts:   a.prompts,
+		AutoTune: distance.AutoTuneConfig{
+			MaxBatchSize: a.cfg.DistanceMaxBatchSize,
+			MinInterval:  a.cfg.DistanceMinInterval,
+			TargetDrain:  a.cfg.DistanceTargetDrain,
+		},
+		Agreement: distance.AgreementConfig{
+			Interval:   a.cfg.DistanceAgreementInterval,
+			SampleSize: a.cfg.DistanceAgreementSamples,
+		},
+	}
+	if a.cfg.VerifyQueueEnabled {
+		distanceConfig.Verification = distance.VerificationConfig{
+			Interval:      15 * time.Minute,
+			RatePerMinute: a.cfg.VerifyRatePerMinute,
+			BatchSize:     20,
+			IdleStartHour: a.cfg.VerifyIdleStartHour,
+			IdleEndHour:   a.cfg.VerifyIdleEndHour,
+
+			SeasonalRefresh: a.cfg.VerifySeasonalRefresh,
+		}
 	}
 	a.distanceAgent = distance.NewComputationAgent(
 		distanceConfig,
 		anchorStorage,
 		llmClient,
 		a.mqtt,
-		a.logger,
+		a.logger.With("component", "distance"),
 		a.timeManager,
 	)
+	a.distanceAgent.SetTopology(a.topology)
 
 	// Set learned pattern storage with DB access
 	if dbGetter, ok := a.pgClient.(interface{ DB() *sql.DB }); ok {
@@ -135,10 +292,36 @@ func (a *Agent) initializePatternDiscovery() error {
 		a.logger.Warn("Could not initialize learned pattern storage: DB access not available")
 	}
 
+	// Extend the configured topology with adjacency learned from episode transitions
+	if a.cfg.AdjacencyLearningEnabled {
+		a.adjacencyLearner = adjacency.NewLearner(
+			adjacency.Config{
+				Interval:       a.cfg.AdjacencyLearningInterval,
+				LookbackDays:   90,
+				MaxGap:         5 * time.Minute,
+				MinTransitions: a.cfg.AdjacencyMinTransitions,
+				MinShare:       0.05,
+			},
+			db,
+			a.topology,
+			a.mqtt,
+			a.logger,
+			a.timeManager,
+		)
+		a.adjacencyLearner.OnUpdate(func(topology *ontology.Topology) {
+			anchorStorage.SetTopology(topology)
+			a.distanceAgent.SetTopology(topology)
+		})
+	}
+
 	// Initialize clustering engine
 	clusteringConfig := clustering.DBSCANConfig{
 		Epsilon:   a.cfg.PatternClusteringEpsilon,
 		MinPoints: a.cfg.PatternClusteringMinPoints,
+		Algorithm: a.cfg.PatternClusteringAlgorithm,
+		Workers:   a.cfg.PatternClusteringWorkers,
+		CacheSize: a.cfg.PatternDistanceCacheSize,
+		MemoryMB:  a.cfg.PatternClusteringMemoryMB,
 	}
 	a.clusteringEngine = clustering.NewClusteringEngine(
 		clusteringConfig,
@@ -150,7 +333,8 @@ func (a *Agent) initializePatternDiscovery() error {
 	a.patternInterpreter = patterns.NewPatternInterpreter(
 		anchorStorage,
 		llmClient,
-		a.cfg.LLMModel,
+		llmClient.Model(llm.TaskInterpretation),
+		a.prompts,
 		a.logger,
 	)
 
@@ -159,10 +343,13 @@ func (a *Agent) initializePatternDiscovery() error {
 		MinAnchors:                    a.cfg.PatternMinAnchorsForDiscovery,
 		LookbackHours:                 a.cfg.PatternLookbackHours,
 		Interval:                      time.Duration(a.cfg.PatternDiscoveryIntervalHours) * time.Hour,
+		Schedule:                      a.discoverySchedule,
+		Jitter:                        a.cfg.ScheduleJitter,
 		TemporalGroupingEnabled:       a.cfg.TemporalGroupingEnabled,
 		TemporalGroupingWindow:        time.Duration(a.cfg.TemporalGroupingWindowMinutes) * time.Minute,
 		TemporalGroupingOverlapRatio:  a.cfg.TemporalGroupingOverlapRatio,
 		UseLocationTemporalClustering: a.cfg.UseLocationTemporalClustering,
+		PerOccupantClustering:         a.cfg.PerOccupantClustering,
 	}
 	a.discoveryAgent = patterns.NewDiscoveryAgent(
 		discoveryConfig,
@@ -173,6 +360,54 @@ func (a *Agent) initializePatternDiscovery() error {
 		a.logger,
 		a.timeManager,
 	)
+	a.discoveryAgent.SetNotifier(a.notifier)
+	a.discoveryAgent.SetJobGate(a.jobGate)
+
+	// Attach new anchors to existing patterns as they are created; discovery
+	// then only clusters the anchors no pattern claimed
+	if a.cfg.PatternIncrementalAssignment && a.anchorCreator != nil {
+		a.anchorCreator.SetPatternAssigner(patterns.NewPatternAssigner(
+			a.cfg.PatternClusteringEpsilon,
+			anchorStorage,
+			a.mqtt,
+			a.logger,
+		))
+	}
+
+	// Initialize pattern lifecycle maintenance (merge, decay, archive)
+	if a.cfg.PatternLifecycleEnabled {
+		week := 7 * 24 * time.Hour
+		a.lifecycleManager = patterns.NewLifecycleManager(
+			patterns.LifecycleConfig{
+				Interval:         a.cfg.PatternLifecycleInterval,
+				MergeSimilarity:  a.cfg.PatternMergeSimilarity,
+				DecayAfter:       time.Duration(a.cfg.PatternDecayAfterWeeks) * week,
+				DecayFactor:      a.cfg.PatternDecayFactor,
+				ArchiveAfter:     time.Duration(a.cfg.PatternArchiveAfterWeeks) * week,
+				ReassignDistance: a.cfg.PatternClusteringEpsilon,
+			},
+			anchorStorage,
+			a.mqtt,
+			a.logger,
+			a.timeManager,
+		)
+	}
+
+	// Initialize next-activity prediction from discovered patterns
+	if a.cfg.PredictionEnabled {
+		a.predictionEngine = prediction.NewEngine(
+			prediction.Config{
+				MinConfidence:    a.cfg.PredictionMinConfidence,
+				Horizon:          a.cfg.PredictionHorizon,
+				MaxTransitionGap: a.cfg.PredictionMaxTransitionGap,
+				SnoozeDuration:   a.cfg.PredictionSnoozeDuration,
+			},
+			anchorStorage,
+			a.mqtt,
+			a.logger,
+			a.timeManager,
+		)
+	}
 
 	// Initialize batch coordinator if batch processing is enabled
 	if a.cfg.BatchProcessingEnabled {
@@ -186,6 +421,7 @@ func (a *Agent) initializePatternDiscovery() error {
 			a.distanceAgent,
 			a.discoveryAgent,
 			a.mqtt,
+			db,
 			a.logger,
 		)
 	}
@@ -196,7 +432,13 @@ func (a *Agent) initializePatternDiscovery() error {
 
 // createAnchorStorage creates a new AnchorStorage instance from a database connection
 func (a *Agent) createAnchorStorage(db *sql.DB) *storage.AnchorStorage {
-	return storage.NewAnchorStorage(db)
+	anchorStorage := storage.NewAnchorStorage(db)
+	anchorStorage.SetSearchParams(a.cfg.ANNProbes, a.cfg.ANNEfSearch)
+	anchorStorage.SetTopology(a.topology)
+	anchorStorage.SetSealer(a.sealer)
+	anchorStorage.SetPairPriority(storage.DefaultPairPriority(
+		time.Duration(a.cfg.PatternLookbackHours)*time.Hour, a.timeManager.Now))
+	return anchorStorage
 }
 
 func (a *Agent) Start(ctx context.Context) error {
@@ -221,6 +463,72 @@ func (a *Agent) Start(ctx context.Context) error {
 	a.logger.Info("Behavior agent subscribed to consolidation trigger only",
 		"note", "Episodes will be created during consolidation from Redis sensor data")
 
+	// Episode annotations (user labels and corrections)
+	if err := a.mqtt.Subscribe(annotateTopic, 0, a.handleAnnotationMessage); err != nil {
+		a.logger.Warn("Failed to subscribe to annotation requests", "error", err)
+	}
+
+	// Deletion of all data for a location or time range (e.g. guests visiting)
+	if err := a.mqtt.Subscribe(purgeTopic, 0, a.handlePurgeMessage); err != nil {
+		a.logger.Warn("Failed to subscribe to purge requests", "error", err)
+	}
+
+	// Runtime log level changes
+	if levels := logging.LevelsOf(a.logger); levels != nil {
+		if err := a.mqtt.Subscribe(logging.LevelsTopic, 0, levels.HandleMessage(a.cfg.ServiceName, a.logger)); err != nil {
+			a.logger.Warn("Failed to subscribe to log level changes", "error", err)
+		}
+	}
+	if a.cfg.BehaviorAPIEnabled {
+		a.startAPIServer()
+	}
+
+	// Upgrade episode documents written by older ontology versions
+	go a.upgradeEpisodeDocuments(ctx)
+
+	// Delete what was learned in newly excluded privacy zones
+	if a.privacy != nil {
+		go a.purgePrivacyZones(ctx)
+	}
+
+	// Deliver episode/pattern events to configured webhooks
+	a.notifier.Start(ctx)
+
+	// Forward episode durations and pattern statistics to VictoriaMetrics
+	if a.metrics != nil {
+		a.metrics.Start(ctx)
+		go a.runPatternMetrics(ctx)
+		go mqtt.ReportDeliveryMetrics(ctx, a.mqtt, a.metrics, a.cfg.ServiceName)
+	}
+
+	// Start house state detection (publishes automation/behavior/house_state)
+	if a.houseState != nil {
+		if err := a.houseState.Start(ctx); err != nil {
+			a.logger.Error("Failed to start house state detector", "error", err)
+		}
+	}
+
+	// Probe Ollama and warm the models up (publishes automation/behavior/llm/status)
+	if a.llmMonitor != nil {
+		a.llmMonitor.Start(ctx)
+	}
+
+	// Fire delayed episode closures (persisted in Redis, driven by virtual time)
+	go a.runTimers(ctx)
+
+	// Publish episode lifecycle events from the outbox
+	go a.runOutboxRelay(ctx)
+
+	// Re-consolidate windows that receive sensor events after consolidation
+	if a.cfg.LateDataEnabled {
+		go a.runLateDataCheck(ctx)
+	}
+
+	// Start nightly sleep analysis
+	if a.cfg.SleepDetectionEnabled {
+		go a.runSleepAnalysisJob(ctx)
+	}
+
 	// Start pattern discovery agents if enabled
 	if a.cfg.PatternDiscoveryEnabled {
 		a.logger.Info("Starting pattern discovery agents")
@@ -234,6 +542,15 @@ func (a *Agent) Start(ctx context.Context) error {
 			}()
 		}
 
+		// Start household rhythm profiling (feeds anchor embeddings)
+		if a.rhythmProfiler != nil {
+			go func() {
+				if err := a.rhythmProfiler.Start(ctx); err != nil {
+					a.logger.Error("Rhythm profiler error", "error", err)
+				}
+			}()
+		}
+
 		// Start pattern discovery agent
 		if a.discoveryAgent != nil {
 			go func() {
@@ -243,6 +560,31 @@ func (a *Agent) Start(ctx context.Context) error {
 			}()
 		}
 
+		// Start adjacency learning (refreshes the map used for distance candidates)
+		if a.adjacencyLearner != nil {
+			go func() {
+				if err := a.adjacencyLearner.Start(ctx); err != nil {
+					a.logger.Error("Adjacency learner error", "error", err)
+				}
+			}()
+		}
+
+		// Start pattern lifecycle maintenance
+		if a.lifecycleManager != nil {
+			go func() {
+				if err := a.lifecycleManager.Start(ctx); err != nil {
+					a.logger.Error("Pattern lifecycle manager error", "error", err)
+				}
+			}()
+		}
+
+		// Start prediction engine (publishes automation/behavior/prediction)
+		if a.predictionEngine != nil {
+			if err := a.predictionEngine.Start(ctx); err != nil {
+				a.logger.Error("Failed to start prediction engine", "error", err)
+			}
+		}
+
 		// Start batch coordinator if enabled
 		if a.batchCoordinator != nil {
 			if err := a.batchCoordinator.Start(ctx); err != nil {
@@ -251,7 +593,10 @@ func (a *Agent) Start(ctx context.Context) error {
 		}
 	}
 
-	// go a.runConsolidationJob(ctx)
+	// Periodic consolidation only when scheduled; otherwise triggers drive it
+	if a.consolidationSchedule != nil {
+		go a.runConsolidationJob(ctx)
+	}
 
 	// Block until context cancelled
 	<-ctx.Done()
@@ -266,6 +611,17 @@ func (a *Agent) Stop() error {
 		a.batchCoordinator.Stop()
 	}
 
+	if a.houseState != nil {
+		a.houseState.Stop()
+	}
+
+	if a.predictionEngine != nil {
+		a.predictionEngine.Stop()
+	}
+
+	a.stopAPIServer()
+	a.jobs.stop()
+
 	a.mqtt.Disconnect()
 	return a.pgClient.Disconnect()
 }
@@ -281,7 +637,7 @@ func (a *Agent) checkShouldCloseEpisode(location string) {
 			"location", location)
 
 		// Schedule delayed check (10 minutes)
-		go a.scheduleDelayedCheck(location, 10*time.Minute)
+		a.scheduleTimer(ctx, timerDelayedCheck, location, 10*time.Minute)
 		return
 	}
 
@@ -291,7 +647,7 @@ func (a *Agent) checkShouldCloseEpisode(location string) {
 			"location", location)
 
 		// Schedule delayed check (5 minutes)
-		go a.scheduleDelayedCheck(location, 5*time.Minute)
+		a.scheduleTimer(ctx, timerDelayedCheck, location, 5*time.Minute)
 		return
 	}
 
@@ -459,10 +815,8 @@ func (a *Agent) hasRecentManualLighting(ctx context.Context, location string, no
 	return isManual
 }
 
-// scheduleDelayedCheck schedules a delayed check to close episode later
-func (a *Agent) scheduleDelayedCheck(location string, delay time.Duration) {
-	time.Sleep(delay)
-
+// runDelayedCheck re-checks a kept-open episode when its delayed check timer fires
+func (a *Agent) runDelayedCheck(location string, scheduledFor time.Time) error {
 	// Re-check if episode should close now
 	a.stateMux.RLock()
 	_, exists := a.activeEpisodes[location]
@@ -470,11 +824,12 @@ func (a *Agent) scheduleDelayedCheck(location string, delay time.Duration) {
 	a.stateMux.RUnlock()
 
 	if !exists {
-		return // Episode already closed
+		return nil // Episode already closed
 	}
 
-	// If still empty and no activity context, close now
-	if currentOccupancy == "empty" {
+	// If still empty and no activity context, close now. No occupancy state
+	// (timer restored after a restart) means nothing changed since scheduling.
+	if currentOccupancy == "empty" || currentOccupancy == "" {
 		ctx := context.Background()
 		now := a.timeManager.Now()
 
@@ -482,13 +837,13 @@ func (a *Agent) scheduleDelayedCheck(location string, delay time.Duration) {
 			!a.hasRecentManualLighting(ctx, location, now) {
 			a.logger.Info("Delayed check: closing episode now",
 				"location", location,
-				"delay_was", delay)
-			a.endEpisode(location, "activity_complete")
-		} else {
-			a.logger.Debug("Delayed check: activity still present, keeping open",
-				"location", location)
+				"scheduled_for", scheduledFor.Format(time.RFC3339))
+			return a.endEpisode(location, "activity_complete")
 		}
+		a.logger.Debug("Delayed check: activity still present, keeping open",
+			"location", location)
 	}
+	return nil
 }
 
 func (a *Agent) handleMessage(msg mqtt.Message) {
@@ -512,16 +867,19 @@ func (a *Agent) handleOccupancyMessage(msg mqtt.Message) {
 	}
 	location := parts[3]
 
-	// Try simple format first ({"state": "occupied", "confidence": 0.85})
-	var simple struct {
-		State      string  `json:"state"`
-		Confidence float64 `json:"confidence"`
+	occupancy, _, err := events.Parse[events.OccupancyState](msg.Payload())
+	if err != nil {
+		a.logger.Warn("Failed to parse occupancy message in any known format",
+			"topic", msg.Topic(),
+			"payload", string(msg.Payload()))
+		return
 	}
 
-	if err := json.Unmarshal(msg.Payload(), &simple); err == nil && simple.State != "" {
+	// Simple format first ({"state": "occupied", "confidence": 0.85})
+	if occupancy.State != "" {
 		a.stateMux.Lock()
 		previousState := a.lastOccupancyState[location]
-		currentState := simple.State
+		currentState := occupancy.State
 		a.lastOccupancyState[location] = currentState
 		a.stateMux.Unlock()
 
@@ -537,48 +895,39 @@ func (a *Agent) handleOccupancyMessage(msg mqtt.Message) {
 		}
 
 		// Check if episode should close when occupancy becomes empty
-		if previousState == "occupied" && currentState == "empty" {
+		// (directly or after the occupancy agent's likely_empty stage)
+		if (previousState == "occupied" || previousState == "likely_empty") && currentState == "empty" {
 			a.checkShouldCloseEpisode(location)
 		}
 		return
 	}
 
-	// Try nested format ({"data": {"occupied": true, "confidence": 0.8, ...}})
-	var nested struct {
-		Data struct {
-			Occupied   bool    `json:"occupied"`
-			Confidence float64 `json:"confidence"`
-		} `json:"data"`
+	// Nested format ({"data": {"occupied": true, "confidence": 0.8, ...}})
+	a.stateMux.Lock()
+	previousState := a.lastOccupancyState[location]
+	currentState := "empty"
+	if occupancy.Data.Occupied {
+		currentState = "occupied"
 	}
+	a.lastOccupancyState[location] = currentState
+	a.stateMux.Unlock()
 
-	if err := json.Unmarshal(msg.Payload(), &nested); err == nil {
-		a.stateMux.Lock()
-		previousState := a.lastOccupancyState[location]
-		currentState := "empty"
-		if nested.Data.Occupied {
-			currentState = "occupied"
-		}
-		a.lastOccupancyState[location] = currentState
-		a.stateMux.Unlock()
-
-		// Detect transitions
-		if previousState != "occupied" && currentState == "occupied" {
-			a.startEpisode(location, "occupancy_transition")
-		}
-
-		// Check if episode should close when occupancy becomes empty
-		if previousState == "occupied" && currentState == "empty" {
-			a.checkShouldCloseEpisode(location)
-		}
-		return
+	// Detect transitions
+	if previousState != "occupied" && currentState == "occupied" {
+		a.startEpisode(location, "occupancy_transition")
 	}
 
-	a.logger.Warn("Failed to parse occupancy message in any known format",
-		"topic", msg.Topic(),
-		"payload", string(msg.Payload()))
+	// Check if episode should close when occupancy becomes empty
+	if previousState == "occupied" && currentState == "empty" {
+		a.checkShouldCloseEpisode(location)
+	}
 }
 
 func (a *Agent) startEpisode(location, triggerType string) {
+	if a.privacy.ExcludesLocation(location) {
+		return
+	}
+
 	now := a.timeManager.Now() // Changed from time.Now()
 
 	a.stateMux.Lock()
@@ -586,6 +935,14 @@ func (a *Agent) startEpisode(location, triggerType string) {
 	lastEndTime, hasRecentEnd := a.lastEpisodeEndTime[location]
 	a.stateMux.Unlock()
 
+	// No episodes while the household is away (stray pet/device activity)
+	if a.houseState != nil && a.houseState.IsAway() {
+		a.logger.Debug("House is away, skipping episode creation",
+			"location", location,
+			"trigger_type", triggerType)
+		return
+	}
+
 	// Check if episode already active
 	if exists {
 		a.logger.Debug("Episode already active, skipping duplicate creation",
@@ -629,12 +986,33 @@ func (a *Agent) startEpisode(location, triggerType string) {
 	json.Unmarshal(episodeJSON, &episodeMap)
 	episodeMap["jeeves:triggerType"] = triggerType
 	jsonld, _ := json.Marshal(episodeMap)
+	if err := ontology.ValidateEpisodeJSON(jsonld); err != nil {
+		a.logger.Error("Invalid episode document", "error", err)
+		return
+	}
+	id := uuid.New().String()
+	jsonld, err := a.sealer.Seal(jsonld, ontology.EpisodeIndexFields, id)
+	if err != nil {
+		a.logger.Error("Failed to encrypt episode document", "error", err)
+		return
+	}
 
-	var id string
-	err := a.pgClient.QueryRow(context.Background(),
-		"INSERT INTO behavioral_episodes (jsonld) VALUES ($1) RETURNING id",
-		jsonld,
-	).Scan(&id)
+	// The started event is queued in the same transaction as the episode
+	ctx := context.Background()
+	err = a.pgClient.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO behavioral_episodes (id, jsonld) VALUES ($1, $2)",
+			id, jsonld,
+		); err != nil {
+			return err
+		}
+		return insertEpisodeEvent(ctx, tx, events.EpisodeEvent{
+			Kind:        events.EpisodeStarted,
+			EpisodeID:   id,
+			Location:    location,
+			TriggerType: triggerType,
+		})
+	})
 
 	if err != nil {
 		a.logger.Error("Failed to create episode", "error", err)
@@ -646,34 +1024,43 @@ func (a *Agent) startEpisode(location, triggerType string) {
 	a.stateMux.Unlock()
 
 	a.logger.Info("Episode started", "location", location, "id", id, "trigger_type", triggerType)
-
-	// Publish event
-	a.publishEpisodeEvent("started", map[string]interface{}{
-		"location":     location,
-		"trigger_type": triggerType,
-	})
+	a.wakeOutboxRelay()
 }
 
-func (a *Agent) endEpisode(location string, reason string) {
+// endEpisode closes the open episode in location. On error the episode
+// stays open.
+func (a *Agent) endEpisode(location string, reason string) error {
 	a.stateMux.Lock()
 	id, exists := a.activeEpisodes[location]
 	a.stateMux.Unlock()
 
 	if !exists {
-		return
+		return nil
 	}
 
 	now := a.timeManager.Now() // Changed from time.Now()
 
-	_, err := a.pgClient.Exec(context.Background(),
-		"UPDATE behavioral_episodes SET jsonld = jsonb_set(jsonld, '{jeeves:endedAt}', to_jsonb($1::text)) WHERE id = $2",
-		now.Format(time.RFC3339),
-		id,
-	)
+	// The closed event is queued in the same transaction as the update
+	ctx := context.Background()
+	err := a.pgClient.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE behavioral_episodes SET jsonld = jsonb_set(jsonld, '{jeeves:endedAt}', to_jsonb($1::text)) WHERE id = $2",
+			now.Format(time.RFC3339),
+			id,
+		); err != nil {
+			return err
+		}
+		return insertEpisodeEvent(ctx, tx, events.EpisodeEvent{
+			Kind:      events.EpisodeClosed,
+			EpisodeID: id,
+			Location:  location,
+			EndReason: reason,
+		})
+	})
 
 	if err != nil {
 		a.logger.Error("Failed to end episode", "error", err)
-		return
+		return fmt.Errorf("failed to end episode %s: %w", id, err)
 	}
 
 	a.stateMux.Lock()
@@ -682,19 +1069,18 @@ func (a *Agent) endEpisode(location string, reason string) {
 	a.stateMux.Unlock()
 
 	a.logger.Info("Episode ended", "location", location, "id", id, "ended_at", now.Format(time.RFC3339))
-
-	// Publish event
-	a.publishEpisodeEvent("closed", map[string]interface{}{
+	a.notifier.Notify(notify.EventEpisodeClosed, map[string]interface{}{
+		"episode_id": id,
 		"location":   location,
+		"ended_at":   now.Format(time.RFC3339),
 		"end_reason": reason,
 	})
+	a.wakeOutboxRelay()
+	return nil
 }
 
-func (a *Agent) publishEpisodeEvent(eventType string, data map[string]interface{}) {
-	topic := fmt.Sprintf("automation/behavior/episode/%s", eventType)
-	payload, _ := json.Marshal(data)
-	a.mqtt.Publish(topic, 0, false, payload)
-}
+// sensorLocations are the locations whose sensor data episodes are created from
+var sensorLocations = []string{"bedroom", "bathroom", "kitchen", "dining_room", "hallway", "study", "living_room"}
 
 // createEpisodesFromSensors creates episodes by analyzing sensor data in Redis
 // Uses location transitions (motion/presence) to detect episode boundaries
@@ -702,211 +1088,155 @@ func (a *Agent) createEpisodesFromSensors(ctx context.Context, sinceTime time.Ti
 	virtualNow := a.timeManager.Now()
 
 	// Get all locations to process
-	locations := []string{"bedroom", "bathroom", "kitchen", "dining_room", "hallway", "study", "living_room"}
+	locations := sensorLocations
 	if location != "" && location != "universe" {
 		locations = []string{location}
 	}
 
-	// Collect all motion/presence events across all locations
-	var allEvents []Event
-
-	// Gather motion sensor events from all locations
-	for _, loc := range locations {
-		key := fmt.Sprintf("sensor:motion:%s", loc)
-
-		// Query Redis for motion events in the time range
-		// Collector now stores virtual timestamps (from timeManager.Now().UnixMilli())
-		// so this query will correctly filter by virtual time in test scenarios
-		minScore := float64(sinceTime.UnixMilli())
-		maxScore := float64(virtualNow.UnixMilli())
-
-		members, err := a.redis.ZRangeByScoreWithScores(ctx, key, minScore, maxScore)
-		if err != nil {
-			a.logger.Debug("No motion data for location", "location", loc, "error", err)
-			continue
-		}
-
-		a.logger.Debug("Retrieved motion data from Redis",
-			"location", loc,
-			"count", len(members),
-			"time_range", fmt.Sprintf("%s to %s", sinceTime.Format("15:04:05"), virtualNow.Format("15:04:05")))
-
-		for _, member := range members {
-			var motionData struct {
-				Timestamp string `json:"timestamp"`
-				State     string `json:"state"`
-			}
-			if err := json.Unmarshal([]byte(member.Member), &motionData); err != nil {
-				continue
-			}
-
-			ts, _ := time.Parse(time.RFC3339, motionData.Timestamp)
-			allEvents = append(allEvents, Event{
-				Location:  loc,
-				Timestamp: ts,
-				Type:      "motion",
-				State:     motionData.State,
-			})
-		}
-	}
-
-	// TODO: Add presence sensor events when available
-
-	// Gather lighting sensor events from all locations
+	// Read motion and lighting events for every location on the worker pool
 	// Lighting events help detect occupancy in rooms without motion sensors (e.g., dining room)
-	for _, loc := range locations {
-		key := fmt.Sprintf("sensor:lighting:%s", loc)
-
-		minScore := float64(sinceTime.UnixMilli())
-		maxScore := float64(virtualNow.UnixMilli())
-
-		members, err := a.redis.ZRangeByScoreWithScores(ctx, key, minScore, maxScore)
-		if err != nil {
-			a.logger.Debug("No lighting data for location", "location", loc, "error", err)
-			continue
-		}
-
-		a.logger.Debug("Retrieved lighting data from Redis",
-			"location", loc,
-			"count", len(members),
-			"time_range", fmt.Sprintf("%s to %s", sinceTime.Format("15:04:05"), virtualNow.Format("15:04:05")))
-
-		for _, member := range members {
-			var lightingData struct {
-				Timestamp string `json:"timestamp"`
-				State     string `json:"state"`
-				Source    string `json:"source"`
-			}
-			if err := json.Unmarshal([]byte(member.Member), &lightingData); err != nil {
-				continue
-			}
-
-			ts, _ := time.Parse(time.RFC3339, lightingData.Timestamp)
-			allEvents = append(allEvents, Event{
-				Location:  loc,
-				Timestamp: ts,
-				Type:      "lighting",
-				State:     lightingData.State,
-				Source:    lightingData.Source,
-			})
-		}
-	}
+	// Collector stores virtual timestamps, so this filters by virtual time in test scenarios
+	// Episode detection below stays sequential: a transition between rooms ends the previous episode
+	allEvents := a.readEventsConcurrently(ctx, "episode_creation", locations, []string{"motion", "lighting"}, sinceTime, virtualNow)
 
 	// Sort all events by timestamp
-	sort.Slice(allEvents, func(i, j int) bool {
+	sort.SliceStable(allEvents, func(i, j int) bool {
 		return allEvents[i].Timestamp.Before(allEvents[j].Timestamp)
 	})
 
+	// Find sensors that went silent mid-activity; their gaps are not absence
+	dropouts := a.detectDropouts(allEvents, virtualNow)
+	a.publishSensorHealth(dropouts)
+
+	// Drop events that fall inside detected away periods
+	allEvents = a.filterAwayEvents(allEvents)
+
+	// Attribute events to occupants from presence data
+	// Without presence sensors every event stays unattributed and is tracked as one household track
+	a.attributeEvents(ctx, allEvents, sinceTime, virtualNow)
+
 	// Detect episodes using location transitions AND temporal gaps
 	// Key insights:
 	// 1. Motion in new location ENDS previous episode and STARTS new one
-	// 2. Large gap (>5min) in same location also ends episode and starts new one
+	// 2. Large gap (>5min) in same location also ends episode and starts new one,
+	//    unless the gap is inside a detected sleep period or a sensor dropout
+	// 3. Each occupant has an independent track, so concurrent activity in
+	//    different rooms by different people does not look like a transition
 	const maxGapMinutes = 5
-	var currentLocation string
-	var episodeStart time.Time
-	var lastEventTime time.Time
+	tracks := make(map[string]*episodeTrack) // occupant → current episode
 	episodesCreated := 0
 
+	closeEpisode := func(occupant string, track *episodeTrack, endTime time.Time, reason string) {
+		if a.isTombstoned(ctx, track.location, track.start, endTime) {
+			a.logger.Debug("Skipping episode removed by manual edit",
+				"location", track.location,
+				"start", track.start.Format(time.RFC3339),
+				"end", endTime.Format(time.RFC3339))
+			return
+		}
+		affected := dropoutsDuring(dropouts, track.location, track.start, endTime)
+		if err := a.createEpisodeInDB(ctx, track.location, occupant, track.start, endTime, reason, affected); err != nil {
+			a.logger.Error("Failed to create episode",
+				"location", track.location,
+				"occupant", occupant,
+				"error", err)
+			return
+		}
+		episodesCreated++
+		a.recordEpisodeMetric(track.location, occupant, track.start, endTime, reason)
+		a.notifier.Notify(notify.EventEpisodeClosed, map[string]interface{}{
+			"location":         track.location,
+			"occupant":         occupant,
+			"started_at":       track.start.Format(time.RFC3339),
+			"ended_at":         endTime.Format(time.RFC3339),
+			"duration_minutes": int(endTime.Sub(track.start).Minutes()),
+			"end_reason":       reason,
+		})
+		a.logger.Info("Episode created",
+			"location", track.location,
+			"occupant", occupant,
+			"start", track.start.Format(time.RFC3339),
+			"end", endTime.Format(time.RFC3339),
+			"duration_min", int(endTime.Sub(track.start).Minutes()),
+			"reason", reason)
+	}
+
 	for _, event := range allEvents {
 		// Process motion ON and lighting ON events (episode starts)
 		if (event.Type == "motion" && event.State == "on") || (event.Type == "lighting" && event.State == "on") {
-			// Check if we need to close current episode
-			shouldCloseEpisode := false
-			closeReason := ""
-			var episodeEndTime time.Time
+			track, exists := tracks[event.Occupant]
 
-			if currentLocation != "" {
-				if currentLocation != event.Location {
+			if exists {
+				if track.location != event.Location {
 					// Location transition - close previous episode
 					// End time is when new location activity detected (person has moved)
-					shouldCloseEpisode = true
-					closeReason = fmt.Sprintf("%s_transition", event.Type)
-					episodeEndTime = event.Timestamp
-				} else {
-					// Same location - check for temporal gap
-					gap := event.Timestamp.Sub(lastEventTime)
-					if gap > maxGapMinutes*time.Minute {
-						// Temporal gap - end at last event before gap
-						shouldCloseEpisode = true
-						closeReason = "temporal_gap"
-						episodeEndTime = lastEventTime
-						a.logger.Debug("Temporal gap detected in same location",
-							"location", currentLocation,
-							"gap_minutes", int(gap.Minutes()),
-							"previous_event", lastEventTime.Format("15:04:05"),
-							"current_event", event.Timestamp.Format("15:04:05"))
-					}
-				}
-			}
-
-			// Close previous episode if needed
-			if shouldCloseEpisode {
-				if err := a.createEpisodeInDB(ctx, currentLocation, episodeStart, episodeEndTime, closeReason); err != nil {
-					a.logger.Error("Failed to create episode",
-						"location", currentLocation,
-						"error", err)
-				} else {
-					episodesCreated++
-					a.logger.Info("Episode created",
-						"location", currentLocation,
-						"start", episodeStart.Format(time.RFC3339),
-						"end", episodeEndTime.Format(time.RFC3339),
-						"duration_min", int(episodeEndTime.Sub(episodeStart).Minutes()),
-						"reason", closeReason)
+					closeEpisode(event.Occupant, track, event.Timestamp, fmt.Sprintf("%s_transition", event.Type))
+					exists = false
+				} else if gap := event.Timestamp.Sub(track.lastEvent); gap > maxGapMinutes*time.Minute &&
+					!a.sleptThrough(track.location, track.lastEvent, event.Timestamp) &&
+					!droppedOutThrough(dropouts, track.location, track.lastEvent, event.Timestamp, maxGapMinutes*time.Minute) {
+					// Temporal gap - end at last event before gap
+					a.logger.Debug("Temporal gap detected in same location",
+						"location", track.location,
+						"occupant", event.Occupant,
+						"gap_minutes", int(gap.Minutes()),
+						"previous_event", track.lastEvent.Format("15:04:05"),
+						"current_event", event.Timestamp.Format("15:04:05"))
+					closeEpisode(event.Occupant, track, track.lastEvent, "temporal_gap")
+					exists = false
 				}
 			}
 
 			// Start new episode if transitioning or after gap
-			if currentLocation == "" || shouldCloseEpisode {
-				currentLocation = event.Location
-				episodeStart = event.Timestamp
+			if !exists {
+				track = &episodeTrack{location: event.Location, start: event.Timestamp}
+				tracks[event.Occupant] = track
 			}
 
-			lastEventTime = event.Timestamp
+			track.lastEvent = event.Timestamp
 		} else if event.Type == "lighting" && event.State == "off" && event.Source == "manual" {
-			// Manual lighting OFF - explicit episode end for current location
+			// Manual lighting OFF - explicit episode end for everyone in the location
 			// Automated lighting OFF events are ignored (status updates, not occupancy changes)
-			if currentLocation == event.Location {
-				if err := a.createEpisodeInDB(ctx, currentLocation, episodeStart, event.Timestamp, "lighting_off"); err != nil {
-					a.logger.Error("Failed to create episode from lighting off",
-						"location", currentLocation,
-						"error", err)
-				} else {
-					episodesCreated++
-					a.logger.Info("Episode created from manual lighting off",
-						"location", currentLocation,
-						"start", episodeStart.Format(time.RFC3339),
-						"end", event.Timestamp.Format(time.RFC3339),
-						"duration_min", int(event.Timestamp.Sub(episodeStart).Minutes()),
-						"source", event.Source)
+			for _, occupant := range sortedOccupants(tracks) {
+				track := tracks[occupant]
+				if track.location != event.Location {
+					continue
 				}
-				// Clear current location since episode ended
-				currentLocation = ""
-				episodeStart = time.Time{}
+				closeEpisode(occupant, track, event.Timestamp, "lighting_off")
+				delete(tracks, occupant)
 			}
 		}
 	}
 
-	// Close final episode if exists
-	if currentLocation != "" {
-		if err := a.createEpisodeInDB(ctx, currentLocation, episodeStart, virtualNow, "motion_transition"); err != nil {
-			a.logger.Error("Failed to create final episode",
-				"location", currentLocation,
-				"error", err)
-		} else {
-			episodesCreated++
-			a.logger.Info("Final episode created",
-				"location", currentLocation,
-				"start", episodeStart.Format(time.RFC3339),
-				"end", virtualNow.Format(time.RFC3339))
-		}
+	// Close final episodes if they exist
+	for _, occupant := range sortedOccupants(tracks) {
+		closeEpisode(occupant, tracks[occupant], virtualNow, "motion_transition")
 	}
 
 	return episodesCreated, nil
 }
 
+// episodeTrack is the open episode for one occupant during batch episode detection
+type episodeTrack struct {
+	location  string
+	start     time.Time
+	lastEvent time.Time
+}
+
+// sortedOccupants returns track keys in stable order
+func sortedOccupants(tracks map[string]*episodeTrack) []string {
+	occupants := make([]string, 0, len(tracks))
+	for occupant := range tracks {
+		occupants = append(occupants, occupant)
+	}
+	sort.Strings(occupants)
+	return occupants
+}
+
 // createEpisodeInDB inserts an episode directly into the database
-func (a *Agent) createEpisodeInDB(ctx context.Context, location string, startTime, endTime time.Time, triggerType string) error {
+// An empty occupant means the episode is not attributed to a specific person
+// Sensor dropouts inside the episode are recorded as jeeves:sensorDropouts
+func (a *Agent) createEpisodeInDB(ctx context.Context, location, occupant string, startTime, endTime time.Time, triggerType string, dropouts []map[string]interface{}) error {
 	episode := ontology.NewEpisode(
 		ontology.Activity{
 			Type: "adl:Present",
@@ -927,23 +1257,61 @@ func (a *Agent) createEpisodeInDB(ctx context.Context, location string, startTim
 	json.Unmarshal(episodeJSON, &episodeMap)
 	episodeMap["jeeves:triggerType"] = triggerType
 	episodeMap["jeeves:endedAt"] = endTime.Format(time.RFC3339)
+	if occupant != "" {
+		episodeMap["jeeves:occupant"] = occupant
+	}
+	if len(dropouts) > 0 {
+		episodeMap["jeeves:sensorDropouts"] = dropouts
+	}
 	jsonld, _ := json.Marshal(episodeMap)
+	if err := ontology.ValidateEpisodeJSON(jsonld); err != nil {
+		return fmt.Errorf("failed to build episode document: %w", err)
+	}
+	id := uuid.New().String()
+	jsonld, err := a.sealer.Seal(jsonld, ontology.EpisodeIndexFields, id)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt episode document: %w", err)
+	}
 
-	_, err := a.pgClient.Exec(ctx,
-		"INSERT INTO behavioral_episodes (jsonld) VALUES ($1)",
-		jsonld,
+	_, err = a.pgClient.Exec(ctx,
+		"INSERT INTO behavioral_episodes (id, jsonld) VALUES ($1, $2)",
+		id, jsonld,
 	)
 
 	return err
 }
 
-// createAnchorsFromEpisodes creates semantic anchors from behavioral episodes
+// createAnchorsFromEpisodes creates semantic anchors from behavioral episodes.
+// For the universe each location's episodes are processed on the worker pool.
 func (a *Agent) createAnchorsFromEpisodes(ctx context.Context, sinceTime time.Time, location string) (int, error) {
 	if a.anchorCreator == nil {
 		a.logger.Debug("Anchor creator not initialized, skipping anchor creation")
 		return 0, nil
 	}
 
+	if location != "" && location != "universe" {
+		return a.createAnchorsFromLocationEpisodes(ctx, sinceTime, location)
+	}
+
+	locations, err := a.episodeLocations(ctx, sinceTime)
+	if err != nil {
+		return 0, err
+	}
+	anchorsCreated := a.runLocations(ctx, "anchor_creation", locations, func(ctx context.Context, location string) (int, error) {
+		return a.createAnchorsFromLocationEpisodes(ctx, sinceTime, location)
+	})
+
+	a.logger.Info("Anchor creation from episodes completed",
+		"anchors_created", anchorsCreated,
+		"locations", len(locations),
+		"since", sinceTime.Format(time.RFC3339))
+
+	return anchorsCreated, nil
+}
+
+// createAnchorsFromLocationEpisodes creates anchors for one location's episodes in time order
+func (a *Agent) createAnchorsFromLocationEpisodes(ctx context.Context, sinceTime time.Time, location string) (int, error) {
+
 	// Query episodes created since sinceTime
 	query := `
 		SELECT id, jsonld
@@ -969,6 +1337,11 @@ func (a *Agent) createAnchorsFromEpisodes(ctx context.Context, sinceTime time.Ti
 			a.logger.Warn("Failed to scan episode", "error", err)
 			continue
 		}
+		jsonldData, err = a.sealer.Open(jsonldData, episodeID)
+		if err != nil {
+			a.logger.Warn("Failed to decrypt episode", "episode_id", episodeID, "error", err)
+			continue
+		}
 
 		// Parse episode JSON
 		var episode map[string]interface{}
@@ -1005,6 +1378,17 @@ func (a *Agent) createAnchorsFromEpisodes(ctx context.Context, sinceTime time.Ti
 		// Gather sensor signals from Redis for this episode
 		signals := a.gatherSignalsForEpisode(ctx, locationName, timestamp)
 
+		// Mark anchors inside sleep periods so discovery doesn't treat them as gaps
+		if period, ok := a.sleepPeriodAt(locationName, timestamp); ok && len(signals) > 0 {
+			signals = append(signals, sleepSignal(period, timestamp))
+		}
+
+		// Carry occupant attribution into the anchor
+		occupant, _ := episode["jeeves:occupant"].(string)
+		if occupant != "" && len(signals) > 0 {
+			signals = append(signals, presenceSignal(occupant, timestamp))
+		}
+
 		if len(signals) == 0 {
 			a.logger.Debug("No signals found for episode, skipping anchor",
 				"episode_id", episodeID,
@@ -1027,6 +1411,7 @@ func (a *Agent) createAnchorsFromEpisodes(ctx context.Context, sinceTime time.Ti
 			"anchor_id", anchor.ID,
 			"episode_id", episodeID,
 			"location", locationName,
+			"occupant", occupant,
 			"timestamp", timestamp.Format(time.RFC3339))
 	}
 
@@ -1034,9 +1419,9 @@ func (a *Agent) createAnchorsFromEpisodes(ctx context.Context, sinceTime time.Ti
 		return anchorsCreated, fmt.Errorf("error iterating episodes: %w", err)
 	}
 
-	a.logger.Info("Anchor creation from episodes completed",
-		"anchors_created", anchorsCreated,
-		"since", sinceTime.Format(time.RFC3339))
+	a.logger.Debug("Anchor creation from location episodes completed",
+		"location", location,
+		"anchors_created", anchorsCreated)
 
 	return anchorsCreated, nil
 }
@@ -1107,35 +1492,89 @@ func (a *Agent) gatherSignalsForEpisode(ctx context.Context, location string, ti
 		}
 	}
 
+	// Get power signal; appliance signatures need a longer window than the
+	// other sensors (a washing machine heats for several minutes)
+	powerKey := redis.PowerSensorKey(location)
+	members, err = a.redis.ZRangeByScoreWithScores(ctx, powerKey,
+		float64(timestamp.Add(-30*time.Minute).UnixMilli()),
+		float64(timestamp.UnixMilli()))
+
+	if err == nil && len(members) > 0 {
+		readings := make([]embedding.PowerReading, 0, len(members))
+		for _, member := range members {
+			var powerData struct {
+				Watts     float64 `json:"watts"`
+				Appliance string  `json:"appliance"`
+			}
+			if err := json.Unmarshal([]byte(member.Member), &powerData); err != nil {
+				continue
+			}
+			readings = append(readings, embedding.PowerReading{
+				Timestamp: time.UnixMilli(int64(member.Score)),
+				Watts:     powerData.Watts,
+				Appliance: powerData.Appliance,
+			})
+		}
+
+		if len(readings) > 0 {
+			appliance, confidence := embedding.MatchAppliance(readings, timestamp)
+			if confidence == 0 {
+				confidence = 0.5
+			}
+			signals = append(signals, types.ActivitySignal{
+				Type:       "power",
+				Confidence: confidence,
+				Timestamp:  timestamp,
+				Value: map[string]interface{}{
+					"watts":     readings[len(readings)-1].Watts,
+					"appliance": appliance,
+				},
+			})
+		}
+	}
+
 	return signals
 }
 
 func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, location string) error {
-	a.logger.Info("=== CONSOLIDATION ORCHESTRATION START ===",
+	a.logger.InfoContext(ctx, "=== CONSOLIDATION ORCHESTRATION START ===",
 		"since", sinceTime.Format(time.RFC3339),
 		"location", location,
 		"virtual_time", a.timeManager.Now().Format(time.RFC3339))
 
+	// STEP -1: Detect sleep periods so overnight gaps aren't split into separate episodes
+	var sleepPeriods []SleepPeriod
+	if a.cfg.SleepDetectionEnabled {
+		a.logger.InfoContext(ctx, "--- PHASE -1: SLEEP DETECTION ---")
+		progress.Phase(ctx, "sleep_detection", nil)
+		sleepPeriods = a.analyzeSleep(ctx, sinceTime, a.timeManager.Now())
+	}
+
 	// STEP 0: Create episodes from Redis sensor data
-	a.logger.Info("--- PHASE 0: EPISODE CREATION FROM SENSORS ---")
+	a.logger.InfoContext(ctx, "--- PHASE 0: EPISODE CREATION FROM SENSORS ---")
+	progress.Phase(ctx, "episode_creation", nil)
+	consolidatedAt := a.timeManager.Now()
 	episodesCreated, err := a.createEpisodesFromSensors(ctx, sinceTime, location)
 	if err != nil {
-		a.logger.Error("Failed to create episodes from sensors", "error", err)
+		a.logger.ErrorContext(ctx, "Failed to create episodes from sensors", "error", err)
 		// Continue anyway - work with existing episodes
 	} else {
-		a.logger.Info("Episodes created from sensor data",
+		a.logger.InfoContext(ctx, "Episodes created from sensor data",
 			"count", episodesCreated,
 			"since", sinceTime.Format(time.RFC3339))
 	}
+	// Sensor events collected from now on for this window arrive late
+	a.markConsolidated(ctx, location, consolidatedAt)
 
 	// STEP 0.5: Create semantic anchors from episodes (OLD PATH)
 	if a.anchorCreator != nil {
-		a.logger.Info("--- PHASE 0.5: SEMANTIC ANCHOR CREATION (from episodes) ---")
+		a.logger.InfoContext(ctx, "--- PHASE 0.5: SEMANTIC ANCHOR CREATION (from episodes) ---")
+		progress.Phase(ctx, "anchor_creation", map[string]interface{}{"episodes_created": episodesCreated})
 		anchorsCreated, err := a.createAnchorsFromEpisodes(ctx, sinceTime, location)
 		if err != nil {
-			a.logger.Error("Failed to create anchors from episodes", "error", err)
+			a.logger.ErrorContext(ctx, "Failed to create anchors from episodes", "error", err)
 		} else {
-			a.logger.Info("Semantic anchors created from episodes",
+			a.logger.InfoContext(ctx, "Semantic anchors created from episodes",
 				"count", anchorsCreated,
 				"since", sinceTime.Format(time.RFC3339))
 		}
@@ -1143,7 +1582,8 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 
 	// STEP 0.6: Create semantic anchors directly from sensor events (NEW PATH - parallel execution)
 	if a.anchorCreator != nil {
-		a.logger.Info("--- PHASE 0.6: DIRECT ANCHOR CREATION (from sensor events) ---")
+		a.logger.InfoContext(ctx, "--- PHASE 0.6: DIRECT ANCHOR CREATION (from sensor events) ---")
+		progress.Phase(ctx, "direct_anchor_creation", nil)
 
 		// Determine locations to process
 		locations := []string{}
@@ -1158,29 +1598,46 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 		virtualNow := a.timeManager.Now()
 		directAnchorsCreated, err := a.createAnchorsDirectlyFromSensorEvents(ctx, sinceTime, virtualNow, locations)
 		if err != nil {
-			a.logger.Error("Failed to create anchors directly from sensor events", "error", err)
+			a.logger.ErrorContext(ctx, "Failed to create anchors directly from sensor events", "error", err)
 		} else {
-			a.logger.Info("Semantic anchors created directly from sensor events",
+			a.logger.InfoContext(ctx, "Semantic anchors created directly from sensor events",
 				"count", directAnchorsCreated,
 				"locations", locations,
 				"since", sinceTime.Format(time.RFC3339))
 		}
 	}
 
+	// STEP 0.7: Store sleep as "Sleeping" macro-episodes (claims the overnight micro-episodes)
+	if len(sleepPeriods) > 0 {
+		stored := a.storeSleepEpisodes(ctx, sleepPeriods)
+		a.logger.InfoContext(ctx, "Sleep episodes stored",
+			"detected", len(sleepPeriods),
+			"stored", stored)
+	}
+
+	return a.consolidateEpisodes(ctx, sinceTime, location)
+}
+
+// consolidateEpisodes detects vectors and builds macro-episodes from the
+// unconsolidated micro-episodes started since sinceTime
+func (a *Agent) consolidateEpisodes(ctx context.Context, sinceTime time.Time, location string) error {
 	// STEP 1: Get unconsolidated episodes from database
+	progress.Phase(ctx, "loading_episodes", nil)
 	episodes, err := a.getUnconsolidatedEpisodes(ctx, sinceTime, location)
 	if err != nil {
-		a.logger.Error("Failed to get unconsolidated episodes", "error", err)
+		a.logger.ErrorContext(ctx, "Failed to get unconsolidated episodes", "error", err)
 		return fmt.Errorf("failed to get unconsolidated episodes: %w", err)
 	}
 
 	if len(episodes) == 0 {
-		a.logger.Info("No episodes to consolidate - orchestration complete")
+		a.logger.InfoContext(ctx, "No episodes to consolidate - orchestration complete")
+		progress.Phase(ctx, "complete", map[string]interface{}{"episodes": 0, "macros_created": 0})
+		a.publishConsolidationResult(0, 0)
 		return nil
 	}
 
 	// Log what we found
-	a.logger.Info("Episodes retrieved for consolidation",
+	a.logger.InfoContext(ctx, "Episodes retrieved for consolidation",
 		"count", len(episodes),
 		"time_range", fmt.Sprintf("%s to %s",
 			episodes[0].StartedAt.Format("15:04:05"),
@@ -1192,7 +1649,7 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 		if ep.EndedAt != nil {
 			duration = fmt.Sprintf("%.1fm", ep.EndedAt.Sub(ep.StartedAt).Minutes())
 		}
-		a.logger.Debug("Episode details",
+		a.logger.DebugContext(ctx, "Episode details",
 			"index", i,
 			"id", ep.ID,
 			"location", ep.Location,
@@ -1202,27 +1659,28 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 	}
 
 	// NEW: STEP 1.5: DETECT BEHAVIORAL VECTORS
-	a.logger.Info("--- PHASE 0: VECTOR DETECTION ---")
+	a.logger.InfoContext(ctx, "--- PHASE 0: VECTOR DETECTION ---")
+	progress.Phase(ctx, "vector_detection", map[string]interface{}{"episodes": len(episodes)})
 
 	// Detect vectors with max 300 second (5 minute) gaps
 	maxGapSeconds := 300
 	vectors := detectVectors(episodes, maxGapSeconds, a.logger)
 
-	a.logger.Info("Vector detection completed",
+	a.logger.InfoContext(ctx, "Vector detection completed",
 		"vectors_detected", len(vectors),
 		"max_gap_seconds", maxGapSeconds)
 
 	// Store vectors in database
 	vectorsStored := 0
 	for i, vector := range vectors {
-		a.logger.Debug("Storing vector",
+		a.logger.DebugContext(ctx, "Storing vector",
 			"index", i,
 			"id", vector.ID,
 			"sequence_length", len(vector.Sequence),
 			"quality_score", vector.QualityScore)
 
 		if err := a.storeVector(ctx, vector); err != nil {
-			a.logger.Error("Failed to store vector",
+			a.logger.ErrorContext(ctx, "Failed to store vector",
 				"error", err,
 				"vector_id", vector.ID)
 		} else {
@@ -1234,7 +1692,7 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 				locations[j] = node.Location
 			}
 
-			a.logger.Info("Vector stored successfully",
+			a.logger.InfoContext(ctx, "Vector stored successfully",
 				"vector_id", vector.ID,
 				"locations", fmt.Sprintf("%v", locations),
 				"time_of_day", vector.Context.TimeOfDay,
@@ -1243,24 +1701,25 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 		}
 	}
 
-	a.logger.Info("Vector storage completed",
+	a.logger.InfoContext(ctx, "Vector storage completed",
 		"vectors_stored", vectorsStored,
 		"vectors_failed", len(vectors)-vectorsStored)
 
 	totalMacrosCreated := 0
 
 	// STEP 2: Rule-based consolidation
-	a.logger.Info("--- PHASE 1: RULE-BASED CONSOLIDATION ---")
+	a.logger.InfoContext(ctx, "--- PHASE 1: RULE-BASED CONSOLIDATION ---")
+	progress.Phase(ctx, "rule_consolidation", map[string]interface{}{"episodes": len(episodes), "vectors_stored": vectorsStored})
 
 	ruleMacros := consolidateMicroEpisodesRuleBased(episodes, a.cfg.ConsolidationMaxGapMinutes, a.logger)
 
-	a.logger.Info("Rule-based consolidation completed",
+	a.logger.InfoContext(ctx, "Rule-based consolidation completed",
 		"macros_generated", len(ruleMacros),
 		"max_gap_minutes", a.cfg.ConsolidationMaxGapMinutes)
 
 	// Store rule-based macros
 	for i, macro := range ruleMacros {
-		a.logger.Debug("Storing rule-based macro",
+		a.logger.DebugContext(ctx, "Storing rule-based macro",
 			"index", i,
 			"id", macro.ID,
 			"pattern", macro.PatternType,
@@ -1269,43 +1728,50 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 			"micro_count", len(macro.MicroEpisodeIDs))
 
 		if err := a.createMacroEpisode(ctx, macro); err != nil {
-			a.logger.Error("Failed to create rule-based macro-episode",
+			a.logger.ErrorContext(ctx, "Failed to create rule-based macro-episode",
 				"error", err,
 				"macro_id", macro.ID)
 		} else {
 			totalMacrosCreated++
-			a.logger.Info("Rule-based macro-episode stored",
+			a.logger.InfoContext(ctx, "Rule-based macro-episode stored",
 				"macro_id", macro.ID,
 				"summary", macro.Summary)
 		}
 	}
 
 	// STEP 3: Get remaining episodes for LLM
-	a.logger.Info("--- PHASE 2: LLM CONSOLIDATION ---")
+	a.logger.InfoContext(ctx, "--- PHASE 2: LLM CONSOLIDATION ---")
+	progress.Phase(ctx, "llm_consolidation", map[string]interface{}{"macros_created": totalMacrosCreated})
 
 	remainingEpisodes, err := a.getUnconsolidatedEpisodes(ctx, sinceTime, location)
 	if err != nil {
-		a.logger.Error("Failed to get remaining episodes for LLM", "error", err)
+		a.logger.ErrorContext(ctx, "Failed to get remaining episodes for LLM", "error", err)
 	} else {
-		a.logger.Info("Remaining episodes after rule-based consolidation",
+		a.logger.InfoContext(ctx, "Remaining episodes after rule-based consolidation",
 			"count", len(remainingEpisodes))
 
 		if len(remainingEpisodes) >= 2 {
-			// Create LLM client
-			llmClient := llm.NewOllamaClient(a.cfg.LLMEndpoint, a.logger)
+			llmClient := a.llmRouter
+			model := llmClient.Model(llm.TaskConsolidation)
 
-			// Check LLM health
-			healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-			defer cancel()
+			// Check LLM health: the monitor's last probe, or a direct check when probing is disabled
+			var llmErr error
+			if a.llmMonitor != nil {
+				llmErr = a.llmMonitor.Err()
+			} else {
+				healthCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+				defer cancel()
+				llmErr = llmClient.Health(healthCtx)
+			}
 
-			if err := llmClient.Health(healthCtx); err != nil {
-				a.logger.Warn("LLM not available, skipping LLM consolidation",
+			if err := llmErr; err != nil {
+				a.logger.WarnContext(ctx, "LLM not available, skipping LLM consolidation",
 					"error", err,
 					"endpoint", a.cfg.LLMEndpoint)
 			} else {
-				a.logger.Info("LLM available, starting LLM consolidation",
+				a.logger.InfoContext(ctx, "LLM available, starting LLM consolidation",
 					"endpoint", a.cfg.LLMEndpoint,
-					"model", a.cfg.LLMModel,
+					"model", model,
 					"min_confidence", a.cfg.LLMMinConfidence)
 
 				// LLM consolidation
@@ -1313,20 +1779,23 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 					ctx,
 					remainingEpisodes,
 					llmClient,
+					model,
+					a.loadAnnotationExamples(ctx, a.cfg.AnnotationFewShotLimit),
+					a.prompts,
 					a.cfg,
 					a.logger,
 					a.timeManager.Now(),
 				)
 
 				if err != nil {
-					a.logger.Error("LLM consolidation failed", "error", err)
+					a.logger.ErrorContext(ctx, "LLM consolidation failed", "error", err)
 				} else {
-					a.logger.Info("LLM consolidation completed",
+					a.logger.InfoContext(ctx, "LLM consolidation completed",
 						"macros_generated", len(llmMacros))
 
 					// Store LLM macros
 					for i, macro := range llmMacros {
-						a.logger.Debug("Storing LLM macro",
+						a.logger.DebugContext(ctx, "Storing LLM macro",
 							"index", i,
 							"id", macro.ID,
 							"pattern", macro.PatternType,
@@ -1335,12 +1804,12 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 							"micro_count", len(macro.MicroEpisodeIDs))
 
 						if err := a.createMacroEpisode(ctx, macro); err != nil {
-							a.logger.Error("Failed to create LLM macro-episode",
+							a.logger.ErrorContext(ctx, "Failed to create LLM macro-episode",
 								"error", err,
 								"macro_id", macro.ID)
 						} else {
 							totalMacrosCreated++
-							a.logger.Info("LLM macro-episode stored",
+							a.logger.InfoContext(ctx, "LLM macro-episode stored",
 								"macro_id", macro.ID,
 								"summary", macro.Summary)
 						}
@@ -1348,20 +1817,24 @@ func (a *Agent) performConsolidation(ctx context.Context, sinceTime time.Time, l
 				}
 			}
 		} else {
-			a.logger.Info("Not enough remaining episodes for LLM consolidation",
+			a.logger.InfoContext(ctx, "Not enough remaining episodes for LLM consolidation",
 				"count", len(remainingEpisodes),
 				"required", 2)
 		}
 	}
 
 	// STEP 4: Final summary
-	a.logger.Info("=== CONSOLIDATION ORCHESTRATION COMPLETE ===",
+	a.logger.InfoContext(ctx, "=== CONSOLIDATION ORCHESTRATION COMPLETE ===",
 		"total_episodes_input", len(episodes),
 		"vectors_detected", len(vectors),
 		"vectors_stored", vectorsStored,
 		"rule_based_macros", len(ruleMacros),
 		"total_macros_created", totalMacrosCreated)
 
+	progress.Phase(ctx, "complete", map[string]interface{}{
+		"episodes":       len(episodes),
+		"macros_created": totalMacrosCreated,
+	})
 	a.publishConsolidationResult(totalMacrosCreated, len(episodes))
 
 	return nil
@@ -1377,15 +1850,8 @@ func (a *Agent) handleLightingMessage(msg mqtt.Message) {
 	location := parts[3]
 
 	// Parse lighting data
-	var lightData struct {
-		State      string  `json:"state"`      // "on" | "off"
-		Brightness *int    `json:"brightness"` // 0-100
-		ColorTemp  *int    `json:"color_temp"` // Kelvin
-		Source     string  `json:"source"`     // "manual" | "automated"
-		Timestamp  *string `json:"timestamp"`
-	}
-
-	if err := json.Unmarshal(msg.Payload(), &lightData); err != nil {
+	lightData, _, err := events.Parse[events.LightingEvent](msg.Payload())
+	if err != nil {
 		a.logger.Warn("Failed to parse lighting message",
 			"topic", msg.Topic(),
 			"payload", string(msg.Payload()),
@@ -1439,19 +1905,14 @@ func (a *Agent) handleLightingMessage(msg mqtt.Message) {
 		if hasActiveEpisode {
 			// For light-based episodes, schedule delayed closure (more patient than motion)
 			// Check if this episode was created by lighting
-			go a.scheduleLightBasedClosure(location, 5*time.Minute)
+			a.scheduleTimer(context.Background(), timerLightBasedClosure, location, 5*time.Minute)
 		}
 	}
 }
 
-// scheduleLightBasedClosure schedules delayed closure for light-based episodes
-func (a *Agent) scheduleLightBasedClosure(location string, delay time.Duration) {
-	a.logger.Debug("Scheduling light-based episode closure",
-		"location", location,
-		"delay", delay)
-
-	time.Sleep(delay)
-
+// runLightBasedClosure closes a light-based episode when its closure timer
+// fires and the light is still off
+func (a *Agent) runLightBasedClosure(location string, scheduledFor time.Time) error {
 	// Check if episode should still be closed
 	a.stateMux.RLock()
 	_, exists := a.activeEpisodes[location]
@@ -1461,19 +1922,20 @@ func (a *Agent) scheduleLightBasedClosure(location string, delay time.Duration)
 	if !exists {
 		a.logger.Debug("Episode already closed during delay",
 			"location", location)
-		return // Episode already closed
+		return nil // Episode already closed
 	}
 
-	// If light is still off after delay period, close the episode
-	if currentLightState == "off" {
+	// If light is still off after delay period, close the episode. No light
+	// state (timer restored after a restart) means it hasn't been turned on.
+	if currentLightState == "off" || currentLightState == "" {
 		a.logger.Info("Closing light-based episode after delay",
 			"location", location,
-			"delay_was", delay)
-		a.endEpisode(location, "lighting_off_delay")
-	} else {
-		a.logger.Debug("Light turned back on during delay, keeping episode open",
-			"location", location)
+			"scheduled_for", scheduledFor.Format(time.RFC3339))
+		return a.endEpisode(location, "lighting_off_delay")
 	}
+	a.logger.Debug("Light turned back on during delay, keeping episode open",
+		"location", location)
+	return nil
 }
 
 func (a *Agent) handleMediaMessage(msg mqtt.Message) {
@@ -12,6 +12,7 @@ import (
 
 	behaviorcontext "github.com/saaga0h/jeeves-platform/internal/behavior/context"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/embedding"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/patterns"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
 )
@@ -28,6 +29,9 @@ type AnchorCreator struct {
 
 	// Optional: Progressive activity embedding agent (nil = use rule-based)
 	activityEmbeddingAgent *embedding.ActivityEmbeddingAgent
+
+	// Optional: attaches new anchors to existing patterns (nil = wait for discovery)
+	patternAssigner *patterns.PatternAssigner
 }
 
 // NewAnchorCreator creates a new anchor creator instance.
@@ -50,6 +54,12 @@ func (c *AnchorCreator) SetActivityEmbeddingAgent(agent *embedding.ActivityEmbed
 	c.logger.Info("Progressive activity embeddings enabled")
 }
 
+// SetPatternAssigner sets the incremental pattern assigner (optional)
+func (c *AnchorCreator) SetPatternAssigner(assigner *patterns.PatternAssigner) {
+	c.patternAssigner = assigner
+	c.logger.Info("Incremental pattern assignment enabled")
+}
+
 // CreateAnchor creates a semantic anchor from observed activity signals.
 // This is the main entry point for anchor creation.
 func (c *AnchorCreator) CreateAnchor(
@@ -66,30 +76,9 @@ func (c *AnchorCreator) CreateAnchor(
 	}
 
 	// Compute semantic embedding (128-dimensional vector)
-	var embeddingVec pgvector.Vector
-	if c.activityEmbeddingAgent != nil {
-		// Use progressive activity embeddings (LLM-based with caching)
-		embeddingVec, err = c.activityEmbeddingAgent.ComputeSemanticEmbeddingProgressive(
-			ctx,
-			location,
-			timestamp,
-			semanticContext,
-			signals,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compute progressive embedding: %w", err)
-		}
-	} else {
-		// Fallback to rule-based embeddings
-		embeddingVec, err = embedding.ComputeSemanticEmbedding(
-			location,
-			timestamp,
-			semanticContext,
-			signals,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to compute embedding: %w", err)
-		}
+	embeddingVec, err := c.embed(ctx, location, timestamp, semanticContext, signals)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create anchor structure
@@ -98,20 +87,30 @@ func (c *AnchorCreator) CreateAnchor(
 		Timestamp:         timestamp,
 		Location:          location,
 		SemanticEmbedding: embeddingVec,
+		EmbeddingVersion:  embedding.Version,
 		Context:           semanticContext,
 		Signals:           signals,
 		CreatedAt:         time.Now(),
 	}
 
+	// Attribute anchor to an occupant if a presence signal identifies one
+	chainKey := location
+	occupant := occupantFromSignals(signals)
+	if occupant != "" {
+		anchor.Occupant = &occupant
+		chainKey = location + ":" + occupant
+	}
+
 	// Link to previous anchor in this location (creates graph structure)
+	// Attributed anchors are chained per occupant so concurrent people don't interleave
 	c.lastAnchorsMux.Lock()
-	if lastID, exists := c.lastAnchors[location]; exists {
+	if lastID, exists := c.lastAnchors[chainKey]; exists {
 		anchor.PrecedingAnchorID = &lastID
 		// Note: We could update the previous anchor's FollowingAnchorID here,
 		// but that would require an additional database UPDATE.
 		// For now, we can traverse the graph using PrecedingAnchorID.
 	}
-	c.lastAnchors[location] = anchor.ID
+	c.lastAnchors[chainKey] = anchor.ID
 	c.lastAnchorsMux.Unlock()
 
 	// Store anchor in database
@@ -122,10 +121,21 @@ func (c *AnchorCreator) CreateAnchor(
 	c.logger.Info("Created semantic anchor",
 		"id", anchor.ID,
 		"location", location,
+		"occupant", occupant,
 		"timestamp", timestamp.Format(time.RFC3339),
 		"signals", len(signals),
 		"context_keys", len(semanticContext))
 
+	// Attach to an existing pattern right away if one is close enough
+	if c.patternAssigner != nil {
+		if _, _, err := c.patternAssigner.AssignAnchor(ctx, anchor); err != nil {
+			c.logger.Warn("Failed to assign anchor to pattern",
+				"anchor_id", anchor.ID,
+				"error", err)
+			// Discovery will pick it up later
+		}
+	}
+
 	// Detect and store multiple interpretations (parallel activities)
 	interpretations := c.detectInterpretations(anchor)
 	if len(interpretations) > 0 {
@@ -147,6 +157,42 @@ func (c *AnchorCreator) CreateAnchor(
 	return anchor, nil
 }
 
+// embed computes the semantic embedding of an anchor
+func (c *AnchorCreator) embed(
+	ctx context.Context,
+	location string,
+	timestamp time.Time,
+	semanticContext map[string]interface{},
+	signals []types.ActivitySignal,
+) (pgvector.Vector, error) {
+	if c.activityEmbeddingAgent != nil {
+		// Use progressive activity embeddings (LLM-based with caching)
+		embeddingVec, err := c.activityEmbeddingAgent.ComputeSemanticEmbeddingProgressive(
+			ctx,
+			location,
+			timestamp,
+			semanticContext,
+			signals,
+		)
+		if err != nil {
+			return pgvector.Vector{}, fmt.Errorf("failed to compute progressive embedding: %w", err)
+		}
+		return embeddingVec, nil
+	}
+
+	// Fallback to rule-based embeddings
+	embeddingVec, err := embedding.ComputeSemanticEmbedding(
+		location,
+		timestamp,
+		semanticContext,
+		signals,
+	)
+	if err != nil {
+		return pgvector.Vector{}, fmt.Errorf("failed to compute embedding: %w", err)
+	}
+	return embeddingVec, nil
+}
+
 // detectInterpretations identifies possible concurrent activities at this anchor.
 // This enables detection of parallel activities (e.g., watching TV while eating).
 func (c *AnchorCreator) detectInterpretations(anchor *types.SemanticAnchor) []types.ActivityInterpretation {
@@ -322,6 +368,19 @@ func (c *AnchorCreator) CreateAnchorFromEvent(
 	return c.CreateAnchor(ctx, location, timestamp, []types.ActivitySignal{signal})
 }
 
+// occupantFromSignals returns the occupant named by a presence signal, or "" if none
+func occupantFromSignals(signals []types.ActivitySignal) string {
+	for _, signal := range signals {
+		if signal.Type != "presence" {
+			continue
+		}
+		if occupant, ok := signal.Value["occupant"].(string); ok && occupant != "" {
+			return occupant
+		}
+	}
+	return ""
+}
+
 // min returns the smaller of two float64 values
 func min(a, b float64) float64 {
 	if a < b {
@@ -0,0 +1,65 @@
+package anchor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/embedding"
+)
+
+// ReembedResult summarizes a re-embedding run
+type ReembedResult struct {
+	Anchors              int   // re-embedded
+	Failed               int   // left at their old version
+	DistancesInvalidated int64 // embedding-derived distances deleted for recomputation
+}
+
+// Reembed recomputes the embedding of every anchor stored with an older
+// embedding.Version from its stored context and signals, batchSize at a time.
+// Anchors that fail are logged and skipped; a rerun retries them.
+func (c *AnchorCreator) Reembed(ctx context.Context, batchSize int) (ReembedResult, error) {
+	var result ReembedResult
+
+	outdated, err := c.storage.CountAnchorsBelowEmbeddingVersion(ctx, embedding.Version)
+	if err != nil {
+		return result, err
+	}
+	c.logger.Info("Re-embedding anchors", "anchors", outdated, "version", embedding.Version)
+
+	after := uuid.Nil
+	for {
+		anchors, err := c.storage.GetAnchorsBelowEmbeddingVersion(ctx, embedding.Version, after, batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(anchors) == 0 {
+			return result, nil
+		}
+
+		for _, anchor := range anchors {
+			if anchor.ID.String() > after.String() {
+				after = anchor.ID
+			}
+
+			vec, err := c.embed(ctx, anchor.Location, anchor.Timestamp, anchor.Context, anchor.Signals)
+			if err != nil {
+				c.logger.Warn("Failed to re-embed anchor", "anchor_id", anchor.ID, "error", err)
+				result.Failed++
+				continue
+			}
+			deleted, err := c.storage.UpdateAnchorEmbedding(ctx, anchor.ID, vec, embedding.Version)
+			if err != nil {
+				return result, fmt.Errorf("failed to store embedding of anchor %s: %w", anchor.ID, err)
+			}
+			result.Anchors++
+			result.DistancesInvalidated += deleted
+		}
+
+		c.logger.Info("Re-embedding progress",
+			"done", result.Anchors+result.Failed,
+			"anchors", outdated,
+			"distances_invalidated", result.DistancesInvalidated)
+	}
+}
@@ -3,8 +3,8 @@ package behavior
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	goredis "github.com/redis/go-redis/v9"
@@ -12,10 +12,8 @@ import (
 	"github.com/saaga0h/jeeves-platform/internal/behavior/anchor"
 	behaviorcontext "github.com/saaga0h/jeeves-platform/internal/behavior/context"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/embedding"
-	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
-	"github.com/saaga0h/jeeves-platform/pkg/llm"
 )
 
 // initializeAnchorCreator sets up the semantic anchor creation system.
@@ -30,7 +28,7 @@ func (a *Agent) initializeAnchorCreator(cfg *config.Config) error {
 	}
 
 	// Initialize location embedding system (dynamic LLM-based classification)
-	llmClient := llm.NewOllamaClient(cfg.LLMEndpoint, a.logger)
+	llmClient := a.llmRouter
 	locationClassifier := embedding.NewLocationClassifier(llmClient, cfg.LLMModel, a.logger)
 	locationStorage := embedding.NewLocationEmbeddingStorage(db, locationClassifier, a.logger)
 
@@ -45,21 +43,36 @@ func (a *Agent) initializeAnchorCreator(cfg *config.Config) error {
 		a.logger.Info("Location embeddings cache preloaded", "count", locationStorage.GetCacheSize())
 	}
 
+	// Learn the household rhythm block from episode history
+	if cfg.RhythmProfileWeeks > 0 {
+		a.rhythmProfiler = embedding.NewRhythmProfiler(
+			embedding.RhythmConfig{
+				Weeks:    cfg.RhythmProfileWeeks,
+				Interval: cfg.RhythmProfileInterval,
+			},
+			db,
+			a.timeManager,
+			a.logger,
+		)
+		embedding.SetRhythmProfiler(a.rhythmProfiler)
+	}
+
 	// Create storage layer
-	anchorStorage := storage.NewAnchorStorage(db)
+	anchorStorage := a.createAnchorStorage(db)
 
 	// Create context gatherer
 	// Note: Need to convert redis.Client interface to *redis.Client
 	// We need the underlying go-redis client for ZRevRangeWithScores
 	redisClient := a.getRedisClient()
 	contextGatherer := behaviorcontext.NewContextGatherer(redisClient, a.logger)
+	contextGatherer.SetLocation(cfg.Latitude, cfg.Longitude)
 
 	// Create anchor creator
 	a.anchorCreator = anchor.NewAnchorCreator(anchorStorage, contextGatherer, a.logger)
 
 	// Initialize progressive activity embeddings (optional feature)
 	if cfg.ProgressiveActivityEmbeddings {
-		llmClient := llm.NewOllamaClient(cfg.LLMEndpoint, a.logger)
+		llmClient := a.llmRouter
 		activityStorage := embedding.NewActivityEmbeddingStorage(db)
 		activityLLM := embedding.NewActivityLLMEmbeddingGenerator(
 			llmClient,
@@ -78,6 +91,35 @@ func (a *Agent) initializeAnchorCreator(cfg *config.Config) error {
 	return nil
 }
 
+// ReembedAnchors recomputes the embeddings of anchors generated by an older
+// embedding.Version and invalidates their vector distances
+func (a *Agent) ReembedAnchors(ctx context.Context, batchSize int) error {
+	if a.anchorCreator == nil {
+		if err := a.initializeAnchorCreator(a.cfg); err != nil {
+			return err
+		}
+	}
+
+	// Embed with the current rhythm profile rather than the heuristics
+	if a.rhythmProfiler != nil {
+		if err := a.rhythmProfiler.Refresh(ctx); err != nil {
+			a.logger.Warn("Failed to refresh rhythm profile", "error", err)
+		}
+	}
+
+	result, err := a.anchorCreator.Reembed(ctx, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to re-embed anchors: %w", err)
+	}
+
+	a.logger.Info("Anchors re-embedded",
+		"version", embedding.Version,
+		"anchors", result.Anchors,
+		"failed", result.Failed,
+		"distances_invalidated", result.DistancesInvalidated)
+	return nil
+}
+
 // createAnchorFromEvent creates a semantic anchor from an event during episode detection.
 // This is called for significant events (motion ON, lighting ON) to create anchor points.
 func (a *Agent) createAnchorFromEvent(ctx context.Context, event Event) error {
@@ -194,116 +236,35 @@ func (a *Agent) createAnchorsDirectlyFromSensorEvents(ctx context.Context, since
 		"until", virtualNow.Format(time.RFC3339),
 		"locations", locations)
 
-	// Gather all sensor events from Redis
-	var allEvents []Event
-
-	// Gather motion sensor events
-	for _, loc := range locations {
-		key := fmt.Sprintf("sensor:motion:%s", loc)
-		minScore := float64(sinceTime.UnixMilli())
-		maxScore := float64(virtualNow.UnixMilli())
-
-		members, err := a.redis.ZRangeByScoreWithScores(ctx, key, minScore, maxScore)
-		if err != nil {
-			a.logger.Debug("No motion data for location", "location", loc, "error", err)
-			continue
-		}
-
-		for _, member := range members {
-			var motionData struct {
-				Timestamp string `json:"timestamp"`
-				State     string `json:"state"`
-			}
-			if err := json.Unmarshal([]byte(member.Member), &motionData); err != nil {
-				continue
-			}
-
-			ts, _ := time.Parse(time.RFC3339, motionData.Timestamp)
-			allEvents = append(allEvents, Event{
-				Location:  loc,
-				Timestamp: ts,
-				Type:      "motion",
-				State:     motionData.State,
-			})
-		}
-	}
-
-	// Gather lighting sensor events
-	for _, loc := range locations {
-		key := fmt.Sprintf("sensor:lighting:%s", loc)
-		minScore := float64(sinceTime.UnixMilli())
-		maxScore := float64(virtualNow.UnixMilli())
-
-		members, err := a.redis.ZRangeByScoreWithScores(ctx, key, minScore, maxScore)
-		if err != nil {
-			a.logger.Debug("No lighting data for location", "location", loc, "error", err)
-			continue
-		}
-
-		for _, member := range members {
-			var lightingData struct {
-				Timestamp string `json:"timestamp"`
-				State     string `json:"state"`
-				Source    string `json:"source"`
-			}
-			if err := json.Unmarshal([]byte(member.Member), &lightingData); err != nil {
-				continue
-			}
+	// Locations are independent (deduplication below is per location), so
+	// each one runs on the worker pool
+	anchorsCreated := a.runLocations(ctx, "direct_anchor_creation", locations, func(ctx context.Context, location string) (int, error) {
+		return a.createDirectAnchorsForLocation(ctx, sinceTime, virtualNow, location)
+	})
 
-			ts, _ := time.Parse(time.RFC3339, lightingData.Timestamp)
-			allEvents = append(allEvents, Event{
-				Location:  loc,
-				Timestamp: ts,
-				Type:      "lighting",
-				State:     lightingData.State,
-				Source:    lightingData.Source,
-			})
-		}
-	}
-
-	// Gather media sensor events
-	for _, loc := range locations {
-		key := fmt.Sprintf("sensor:media:%s", loc)
-		minScore := float64(sinceTime.UnixMilli())
-		maxScore := float64(virtualNow.UnixMilli())
-
-		members, err := a.redis.ZRangeByScoreWithScores(ctx, key, minScore, maxScore)
-		if err != nil {
-			a.logger.Debug("No media data for location", "location", loc, "error", err)
-			continue
-		}
+	a.logger.Info("Direct anchor creation completed",
+		"anchors_created", anchorsCreated,
+		"locations", len(locations))
 
-		for _, member := range members {
-			var mediaData struct {
-				Timestamp string `json:"timestamp"`
-				State     string `json:"state"`
-			}
-			if err := json.Unmarshal([]byte(member.Member), &mediaData); err != nil {
-				continue
-			}
+	return anchorsCreated, nil
+}
 
-			ts, _ := time.Parse(time.RFC3339, mediaData.Timestamp)
-			allEvents = append(allEvents, Event{
-				Location:  loc,
-				Timestamp: ts,
-				Type:      "media",
-				State:     mediaData.State,
-			})
-		}
-	}
+// createDirectAnchorsForLocation creates anchors from one location's motion,
+// lighting and media state changes
+func (a *Agent) createDirectAnchorsForLocation(ctx context.Context, sinceTime, virtualNow time.Time, location string) (int, error) {
+	allEvents := a.readLocationEvents(ctx, location, []string{"motion", "lighting", "media"}, sinceTime, virtualNow)
 
-	a.logger.Info("Gathered sensor events for direct anchor creation",
+	a.logger.Debug("Gathered sensor events for direct anchor creation",
+		"location", location,
 		"total_events", len(allEvents))
 
-	// Sort events by timestamp
-	// Note: Using custom sort instead of sort.Slice to avoid issues
-	for i := 0; i < len(allEvents); i++ {
-		for j := i + 1; j < len(allEvents); j++ {
-			if allEvents[j].Timestamp.Before(allEvents[i].Timestamp) {
-				allEvents[i], allEvents[j] = allEvents[j], allEvents[i]
-			}
-		}
-	}
+	// Attribute events to occupants from presence data (no-op without presence sensors)
+	a.attributeEvents(ctx, allEvents, sinceTime, virtualNow)
+
+	// Sort events by timestamp, keeping the read order for equal timestamps
+	sort.SliceStable(allEvents, func(i, j int) bool {
+		return allEvents[i].Timestamp.Before(allEvents[j].Timestamp)
+	})
 
 	// Track last significant state per location to avoid redundant anchors
 	// Redis would be better for production, but using in-memory for now
@@ -371,6 +332,9 @@ func (a *Agent) createAnchorsDirectlyFromSensorEvents(ctx context.Context, since
 				Value:      a.buildSignalValue(event),
 			},
 		}
+		if event.Occupant != "" {
+			signals = append(signals, presenceSignal(event.Occupant, event.Timestamp))
+		}
 
 		// Create the anchor
 		anchor, err := a.anchorCreator.CreateAnchor(ctx, event.Location, event.Timestamp, signals)
@@ -387,11 +351,13 @@ func (a *Agent) createAnchorsDirectlyFromSensorEvents(ctx context.Context, since
 		a.logger.Debug("Created direct anchor",
 			"anchor_id", anchor.ID,
 			"location", event.Location,
+			"occupant", event.Occupant,
 			"event_type", event.Type,
 			"timestamp", event.Timestamp.Format(time.RFC3339))
 	}
 
-	a.logger.Info("Direct anchor creation completed",
+	a.logger.Debug("Direct anchor creation for location completed",
+		"location", location,
 		"anchors_created", anchorsCreated,
 		"events_processed", len(allEvents))
 
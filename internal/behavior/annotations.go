@@ -0,0 +1,614 @@
+package behavior
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// Annotation types accepted by the annotation API
+const (
+	AnnotationTypeLabel = "label" // "this was cooking"
+	AnnotationTypeMerge = "merge" // "merge these two"
+)
+
+const (
+	annotateTopic          = "automation/behavior/annotate"
+	annotationCreatedTopic = "automation/behavior/annotation/created"
+
+	// annotationWeightBoost is added to a pattern's weight when a user label confirms it
+	annotationWeightBoost = 0.1
+)
+
+// errEpisodeNotFound is returned when an annotated episode does not exist
+var errEpisodeNotFound = errors.New("episode not found")
+
+// AnnotationRequest is a user label or correction for an episode, received over HTTP or MQTT
+type AnnotationRequest struct {
+	EpisodeID uuid.UUID   `json:"episode_id"`
+	Type      string      `json:"type"`
+	Label     string      `json:"label,omitempty"`
+	Note      string      `json:"note,omitempty"`
+	MergeWith []uuid.UUID `json:"merge_with,omitempty"`
+	Author    string      `json:"author,omitempty"`
+}
+
+// Validate checks that the request is complete for its type
+func (r AnnotationRequest) Validate() error {
+	if r.EpisodeID == uuid.Nil {
+		return fmt.Errorf("episode_id is required")
+	}
+
+	switch r.Type {
+	case AnnotationTypeLabel:
+		if strings.TrimSpace(r.Label) == "" {
+			return fmt.Errorf("label is required for label annotations")
+		}
+	case AnnotationTypeMerge:
+		if len(r.MergeWith) == 0 {
+			return fmt.Errorf("merge_with is required for merge annotations")
+		}
+	default:
+		return fmt.Errorf("unknown annotation type %q (expected %q or %q)", r.Type, AnnotationTypeLabel, AnnotationTypeMerge)
+	}
+
+	return nil
+}
+
+// EpisodeAnnotation is a stored annotation
+type EpisodeAnnotation struct {
+	ID                uuid.UUID   `json:"id"`
+	EpisodeID         uuid.UUID   `json:"episode_id"`
+	EpisodeKind       string      `json:"episode_kind"` // "micro" or "macro"
+	Type              string      `json:"type"`
+	Label             string      `json:"label,omitempty"`
+	Note              string      `json:"note,omitempty"`
+	RelatedEpisodeIDs []uuid.UUID `json:"related_episode_ids,omitempty"`
+	Locations         []string    `json:"locations"`
+	StartedAt         time.Time   `json:"started_at"`
+	EndedAt           time.Time   `json:"ended_at"`
+	MacroEpisodeID    *uuid.UUID  `json:"macro_episode_id,omitempty"`
+	Author            string      `json:"author,omitempty"`
+	CreatedAt         time.Time   `json:"created_at"`
+}
+
+// AnnotationExample is a user-confirmed activity used as an LLM few-shot example
+type AnnotationExample struct {
+	Type      string
+	Label     string
+	Locations []string
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// annotateEpisode applies a user annotation, persists it, and feeds it back into pattern weights
+func (a *Agent) annotateEpisode(ctx context.Context, req AnnotationRequest) (*EpisodeAnnotation, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	req.Label = strings.TrimSpace(req.Label)
+
+	annotation := &EpisodeAnnotation{
+		ID:        uuid.New(),
+		EpisodeID: req.EpisodeID,
+		Type:      req.Type,
+		Label:     req.Label,
+		Note:      req.Note,
+		Author:    req.Author,
+		CreatedAt: a.timeManager.Now(),
+	}
+
+	// Each annotation type stores the annotation in the same transaction as its changes
+	var err error
+	switch req.Type {
+	case AnnotationTypeLabel:
+		err = a.applyLabelAnnotation(ctx, req, annotation)
+	case AnnotationTypeMerge:
+		err = a.applyMergeAnnotation(ctx, req, annotation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a.applyAnnotationFeedback(ctx, annotation)
+
+	a.logger.Info("Episode annotated",
+		"annotation_id", annotation.ID,
+		"episode_id", annotation.EpisodeID,
+		"kind", annotation.EpisodeKind,
+		"type", annotation.Type,
+		"label", annotation.Label)
+
+	a.publishAnnotation(annotation)
+	return annotation, nil
+}
+
+// applyLabelAnnotation relabels a macro-episode, or records the label on a
+// micro-episode, and stores annotation
+func (a *Agent) applyLabelAnnotation(ctx context.Context, req AnnotationRequest, annotation *EpisodeAnnotation) error {
+	var locations []string
+	err := a.pgClient.QueryRow(ctx, `
+		SELECT start_time, end_time, locations
+		FROM macro_episodes
+		WHERE id = $1`,
+		req.EpisodeID,
+	).Scan(&annotation.StartedAt, &annotation.EndedAt, pq.Array(&locations))
+
+	switch {
+	case err == nil:
+		macroID := req.EpisodeID
+		annotation.EpisodeKind = "macro"
+		annotation.Locations = locations
+		annotation.MacroEpisodeID = &macroID
+
+		return a.pgClient.Transaction(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE macro_episodes
+				SET pattern_type = $2,
+					semantic_tags = array_append(array_remove(COALESCE(semantic_tags, '{}'), $2), $2),
+					context_features = COALESCE(context_features, '{}'::jsonb) || jsonb_build_object('jeeves:userLabel', $2::text)
+				WHERE id = $1`,
+				req.EpisodeID, req.Label); err != nil {
+				return fmt.Errorf("failed to relabel macro-episode: %w", err)
+			}
+			return insertAnnotation(ctx, tx, annotation)
+		})
+
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("failed to load macro-episode: %w", err)
+	}
+
+	episodes, err := a.getMicroEpisodesByID(ctx, []uuid.UUID{req.EpisodeID})
+	if err != nil {
+		return err
+	}
+	if len(episodes) == 0 {
+		return errEpisodeNotFound
+	}
+	episode := episodes[0]
+
+	annotation.EpisodeKind = "micro"
+	annotation.Locations = []string{episode.Location}
+	annotation.StartedAt = episode.StartedAt
+	annotation.EndedAt = episodeEnd(episode)
+
+	return a.pgClient.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE behavioral_episodes
+			SET jsonld = jsonld || jsonb_build_object('jeeves:userLabel', $2::text)
+			WHERE id = $1`,
+			req.EpisodeID, req.Label); err != nil {
+			return fmt.Errorf("failed to label episode: %w", err)
+		}
+		return insertAnnotation(ctx, tx, annotation)
+	})
+}
+
+// applyMergeAnnotation joins micro-episodes into a single user-confirmed macro-episode
+// and stores annotation. Episodes already claimed by other macros are detached from
+// them first, and macros left without episodes by this are deleted. All of it
+// happens in one transaction, so a failed merge leaves the episodes as they were.
+//
+// Unlike storage.MergeMicroEpisodes, which replaces episodes in one location with a
+// single micro-episode, the episodes stay as they are and may span locations.
+func (a *Agent) applyMergeAnnotation(ctx context.Context, req AnnotationRequest, annotation *EpisodeAnnotation) error {
+	ids := []uuid.UUID{req.EpisodeID}
+	related := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{req.EpisodeID: true}
+	for _, id := range req.MergeWith {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+			related = append(related, id)
+		}
+	}
+	if len(ids) < 2 {
+		return fmt.Errorf("merge requires at least two distinct episodes")
+	}
+
+	episodes, err := a.getMicroEpisodesByID(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if len(episodes) != len(ids) {
+		return errEpisodeNotFound
+	}
+
+	sortEpisodesByStartTime(episodes)
+	macro := mergeMicroEpisodes(episodes)
+
+	// mergeMicroEpisodes takes the end of the last-started episode
+	for _, ep := range episodes {
+		if end := episodeEnd(ep); end.After(macro.EndTime) {
+			macro.EndTime = end
+		}
+	}
+	macro.DurationMinutes = int(macro.EndTime.Sub(macro.StartTime).Minutes())
+	macro.CreatedAt = a.timeManager.Now()
+	macro.ContextFeatures["jeeves:userAnnotated"] = true
+
+	if req.Label != "" {
+		macro.PatternType = req.Label
+		macro.SemanticTags = append([]string{req.Label}, macro.SemanticTags[1:]...)
+		macro.ContextFeatures["jeeves:userLabel"] = req.Label
+		macro.Summary = fmt.Sprintf("%s across %s for %d minutes (merged by user)",
+			req.Label, strings.Join(macro.Locations, ", "), macro.DurationMinutes)
+	}
+
+	annotation.EpisodeKind = "micro"
+	annotation.RelatedEpisodeIDs = related
+	annotation.Locations = macro.Locations
+	annotation.StartedAt = macro.StartTime
+	annotation.EndedAt = macro.EndTime
+	annotation.MacroEpisodeID = &macro.ID
+
+	var removed int
+	err = a.pgClient.Transaction(ctx, func(tx *sql.Tx) error {
+		if removed, err = detachFromMacroEpisodes(ctx, tx, ids); err != nil {
+			return err
+		}
+		if err := insertMacroEpisode(ctx, tx, macro); err != nil {
+			return err
+		}
+		return insertAnnotation(ctx, tx, annotation)
+	})
+	if err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		a.logger.Info("Removed macro-episodes superseded by merge annotation", "count", removed)
+	}
+	a.logger.Info("Macro-episode created",
+		"id", macro.ID,
+		"pattern", macro.PatternType,
+		"duration", macro.DurationMinutes,
+		"micro_episodes", len(macro.MicroEpisodeIDs))
+	return nil
+}
+
+// detachFromMacroEpisodes removes micro-episodes from any macro that claims them
+// and deletes the macros left empty, returning how many were deleted
+func detachFromMacroEpisodes(ctx context.Context, tx *sql.Tx, ids []uuid.UUID) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE macro_episodes m
+		SET micro_episode_ids = ARRAY(
+			SELECT id FROM unnest(m.micro_episode_ids) AS id
+			WHERE NOT (id = ANY($1::uuid[]))
+		)
+		WHERE m.micro_episode_ids && $1::uuid[]
+		RETURNING m.id, cardinality(m.micro_episode_ids)`,
+		pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to detach episodes from macro-episodes: %w", err)
+	}
+
+	var emptied []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		var remaining int
+		if err := rows.Scan(&id, &remaining); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan failed: %w", err)
+		}
+		if remaining == 0 {
+			emptied = append(emptied, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to detach episodes from macro-episodes: %w", err)
+	}
+
+	if len(emptied) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM macro_episodes WHERE id = ANY($1::uuid[])`, pq.Array(emptied)); err != nil {
+		return 0, fmt.Errorf("failed to delete emptied macro-episodes: %w", err)
+	}
+	return len(emptied), nil
+}
+
+// getMicroEpisodesByID loads micro-episodes by ID, ignoring IDs that do not exist
+func (a *Agent) getMicroEpisodesByID(ctx context.Context, ids []uuid.UUID) ([]*MicroEpisode, error) {
+	rows, err := a.pgClient.Query(ctx, `
+		SELECT
+			id,
+			COALESCE(jsonld->>'jeeves:triggerType', 'occupancy_transition') as trigger_type,
+			started_at_text::timestamptz as started_at,
+			ended_at_text::timestamptz as ended_at,
+			location
+		FROM behavioral_episodes
+		WHERE id = ANY($1::uuid[])`,
+		pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var episodes []*MicroEpisode
+	for rows.Next() {
+		ep := &MicroEpisode{ManualActions: []map[string]interface{}{}}
+		if err := rows.Scan(&ep.ID, &ep.TriggerType, &ep.StartedAt, &ep.EndedAt, &ep.Location); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		episodes = append(episodes, ep)
+	}
+
+	return episodes, rows.Err()
+}
+
+// episodeEnd returns when an episode ended, or when it started if it is still open
+func episodeEnd(ep *MicroEpisode) time.Time {
+	if ep.EndedAt != nil {
+		return *ep.EndedAt
+	}
+	return ep.StartedAt
+}
+
+// insertAnnotation persists an annotation as part of tx
+func insertAnnotation(ctx context.Context, tx *sql.Tx, annotation *EpisodeAnnotation) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO episode_annotations (
+			id, episode_id, episode_kind, annotation_type, label, note,
+			related_episode_ids, locations, started_at, ended_at,
+			macro_episode_id, author, created_at
+		)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10, $11, NULLIF($12, ''), $13)`,
+		annotation.ID,
+		annotation.EpisodeID,
+		annotation.EpisodeKind,
+		annotation.Type,
+		annotation.Label,
+		annotation.Note,
+		pq.Array(annotation.RelatedEpisodeIDs),
+		pq.Array(annotation.Locations),
+		annotation.StartedAt,
+		annotation.EndedAt,
+		annotation.MacroEpisodeID,
+		annotation.Author,
+		annotation.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert annotation: %w", err)
+	}
+	return nil
+}
+
+// listAnnotations returns the most recent annotations, optionally for a single episode
+func (a *Agent) listAnnotations(ctx context.Context, episodeID *uuid.UUID, limit int) ([]*EpisodeAnnotation, error) {
+	query := `
+		SELECT id, episode_id, episode_kind, annotation_type,
+			COALESCE(label, ''), COALESCE(note, ''), related_episode_ids,
+			locations, started_at, ended_at, macro_episode_id,
+			COALESCE(author, ''), created_at
+		FROM episode_annotations`
+	args := []interface{}{limit}
+	if episodeID != nil {
+		query += " WHERE episode_id = $2 OR $2 = ANY(related_episode_ids)"
+		args = append(args, *episodeID)
+	}
+	query += " ORDER BY created_at DESC LIMIT $1"
+
+	rows, err := a.pgClient.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	annotations := []*EpisodeAnnotation{}
+	for rows.Next() {
+		ann := &EpisodeAnnotation{}
+		var related []uuid.UUID
+		var macroID uuid.NullUUID
+		err := rows.Scan(
+			&ann.ID,
+			&ann.EpisodeID,
+			&ann.EpisodeKind,
+			&ann.Type,
+			&ann.Label,
+			&ann.Note,
+			pq.Array(&related),
+			pq.Array(&ann.Locations),
+			&ann.StartedAt,
+			&ann.EndedAt,
+			&macroID,
+			&ann.Author,
+			&ann.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		ann.RelatedEpisodeIDs = related
+		if macroID.Valid {
+			ann.MacroEpisodeID = &macroID.UUID
+		}
+		annotations = append(annotations, ann)
+	}
+
+	return annotations, rows.Err()
+}
+
+// applyAnnotationFeedback adjusts the patterns whose anchors fall inside the annotated
+// episode. Patterns matching the user's label gain weight and an acceptance; others get
+// a rejection (weights only increase, so disagreement is recorded in the counters).
+func (a *Agent) applyAnnotationFeedback(ctx context.Context, annotation *EpisodeAnnotation) {
+	if annotation.Label == "" || len(annotation.Locations) == 0 {
+		return
+	}
+
+	db, err := a.getDBConnection()
+	if err != nil {
+		a.logger.Debug("Skipping annotation feedback, no database connection", "error", err)
+		return
+	}
+	anchorStorage := a.createAnchorStorage(db)
+
+	rows, err := a.pgClient.Query(ctx, `
+		SELECT DISTINCT p.id, p.name, COALESCE(p.pattern_type, '')
+		FROM semantic_anchors sa
+		JOIN behavioral_patterns p ON p.id = sa.pattern_id
+		WHERE sa.location = ANY($1)
+			AND sa.timestamp >= $2
+			AND sa.timestamp <= $3`,
+		pq.Array(annotation.Locations), annotation.StartedAt, annotation.EndedAt)
+	if err != nil {
+		a.logger.Error("Failed to find patterns for annotation", "error", err)
+		return
+	}
+
+	type patternRef struct {
+		id          uuid.UUID
+		name        string
+		patternType string
+	}
+	var refs []patternRef
+	for rows.Next() {
+		var ref patternRef
+		if err := rows.Scan(&ref.id, &ref.name, &ref.patternType); err != nil {
+			a.logger.Error("Failed to scan pattern for annotation", "error", err)
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	rows.Close()
+
+	for _, ref := range refs {
+		matched := labelMatchesPattern(annotation.Label, ref.name, ref.patternType)
+
+		if err := anchorStorage.UpdatePatternPrediction(ctx, ref.id, matched); err != nil {
+			a.logger.Error("Failed to record annotation feedback", "pattern_id", ref.id, "error", err)
+			continue
+		}
+		if matched {
+			if err := anchorStorage.UpdatePatternWeight(ctx, ref.id, annotationWeightBoost); err != nil {
+				a.logger.Error("Failed to boost pattern weight", "pattern_id", ref.id, "error", err)
+			}
+		}
+
+		a.logger.Debug("Applied annotation feedback to pattern",
+			"pattern_id", ref.id,
+			"pattern", ref.name,
+			"label", annotation.Label,
+			"matched", matched)
+	}
+}
+
+// labelMatchesPattern reports whether a user label agrees with a pattern's name or type.
+// Comparison ignores case and separators, so "Meal prep" matches "meal_prep".
+func labelMatchesPattern(label, name, patternType string) bool {
+	normalized := normalizeLabel(label)
+	if normalized == "" {
+		return false
+	}
+	for _, candidate := range []string{name, patternType} {
+		c := normalizeLabel(candidate)
+		if c != "" && (strings.Contains(c, normalized) || strings.Contains(normalized, c)) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeLabel(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// loadAnnotationExamples returns the most recent labeled annotations for LLM few-shot prompting
+func (a *Agent) loadAnnotationExamples(ctx context.Context, limit int) []AnnotationExample {
+	if limit <= 0 {
+		return nil
+	}
+
+	rows, err := a.pgClient.Query(ctx, `
+		SELECT annotation_type, label, locations, started_at, ended_at
+		FROM episode_annotations
+		WHERE label IS NOT NULL
+		ORDER BY created_at DESC
+		LIMIT $1`,
+		limit)
+	if err != nil {
+		a.logger.Warn("Failed to load annotation examples", "error", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var examples []AnnotationExample
+	for rows.Next() {
+		var ex AnnotationExample
+		if err := rows.Scan(&ex.Type, &ex.Label, pq.Array(&ex.Locations), &ex.StartedAt, &ex.EndedAt); err != nil {
+			a.logger.Warn("Failed to scan annotation example", "error", err)
+			continue
+		}
+		examples = append(examples, ex)
+	}
+
+	// Oldest first reads naturally in the prompt
+	sort.Slice(examples, func(i, j int) bool {
+		return examples[i].StartedAt.Before(examples[j].StartedAt)
+	})
+
+	return examples
+}
+
+// formatAnnotationExample renders an example as a prompt line
+func formatAnnotationExample(ex AnnotationExample) string {
+	verdict := "✓ MERGE"
+	if ex.Type == AnnotationTypeLabel && len(ex.Locations) == 1 {
+		verdict = "✓ LABEL"
+	}
+	return fmt.Sprintf("%s: %s(%s-%s, %s %s) - user says %q",
+		verdict,
+		strings.Join(ex.Locations, "→"),
+		ex.StartedAt.Format("15:04"),
+		ex.EndedAt.Format("15:04"),
+		ex.StartedAt.Weekday(),
+		categorizeTimeOfDay(ex.StartedAt),
+		ex.Label)
+}
+
+// handleAnnotationMessage handles annotations submitted over MQTT
+func (a *Agent) handleAnnotationMessage(msg mqtt.Message) {
+	var req AnnotationRequest
+	if err := json.Unmarshal(msg.Payload(), &req); err != nil {
+		a.logger.Error("Failed to parse annotation request", "error", err)
+		return
+	}
+
+	if _, err := a.annotateEpisode(context.Background(), req); err != nil {
+		a.logger.Error("Failed to annotate episode",
+			"episode_id", req.EpisodeID,
+			"type", req.Type,
+			"error", err)
+	}
+}
+
+// publishAnnotation announces a stored annotation
+func (a *Agent) publishAnnotation(annotation *EpisodeAnnotation) {
+	payload, err := events.Marshal(events.ProducerBehavior, annotation)
+	if err != nil {
+		a.logger.Error("Failed to marshal annotation", "error", err)
+		return
+	}
+
+	if err := a.mqtt.Publish(annotationCreatedTopic, 0, false, payload); err != nil {
+		a.logger.Error("Failed to publish annotation", "error", err)
+	}
+}
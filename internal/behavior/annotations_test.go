@@ -0,0 +1,109 @@
+package behavior
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+)
+
+// newMergeTestAgent returns an agent on db holding a kitchen and a dining room
+// episode, the first claimed by a macro-episode the merge empties
+func newMergeTestAgent(db *fakeDB) (*Agent, AnnotationRequest) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	t0 := time.Date(2025, 10, 17, 18, 0, 0, 0, time.UTC)
+	kitchen, dining := uuid.New(), uuid.New()
+
+	db.on("FROM behavioral_episodes",
+		[]string{"id", "trigger_type", "started_at", "ended_at", "location"},
+		[]driver.Value{kitchen.String(), "occupancy_transition", t0, t0.Add(30 * time.Minute), "kitchen"},
+		[]driver.Value{dining.String(), "occupancy_transition", t0.Add(35 * time.Minute), t0.Add(time.Hour), "dining_room"},
+	)
+	db.on("UPDATE macro_episodes m", []string{"id", "cardinality"},
+		[]driver.Value{uuid.New().String(), int64(0)},
+	)
+
+	agent := &Agent{
+		pgClient:    db.client(),
+		logger:      logger,
+		timeManager: clock.NewTimeManager(logger),
+	}
+	req := AnnotationRequest{
+		EpisodeID: kitchen,
+		Type:      AnnotationTypeMerge,
+		MergeWith: []uuid.UUID{dining},
+		Label:     "cooking",
+	}
+	return agent, req
+}
+
+func TestApplyMergeAnnotation(t *testing.T) {
+	db := &fakeDB{}
+	agent, req := newMergeTestAgent(db)
+	annotation := &EpisodeAnnotation{ID: uuid.New(), EpisodeID: req.EpisodeID, Type: req.Type, Label: req.Label}
+
+	if err := agent.applyMergeAnnotation(context.Background(), req, annotation); err != nil {
+		t.Fatalf("applyMergeAnnotation failed: %v", err)
+	}
+
+	if db.commits != 1 || db.rollbacks != 0 {
+		t.Errorf("Expected one committed transaction, got %d commits and %d rollbacks", db.commits, db.rollbacks)
+	}
+	for _, fragment := range []string{
+		"UPDATE macro_episodes m",
+		"DELETE FROM macro_episodes",
+		"INSERT INTO macro_episodes",
+		"INSERT INTO episode_annotations",
+	} {
+		stmts := db.executed(fragment)
+		if len(stmts) != 1 {
+			t.Errorf("Expected one %q statement, got %d", fragment, len(stmts))
+			continue
+		}
+		if !stmts[0].inTx {
+			t.Errorf("Expected %q to run in the transaction", fragment)
+		}
+	}
+
+	if annotation.MacroEpisodeID == nil || annotation.EpisodeKind != "micro" {
+		t.Fatalf("Expected the annotation to reference the merged macro-episode, got %+v", annotation)
+	}
+	if len(annotation.RelatedEpisodeIDs) != 1 || annotation.RelatedEpisodeIDs[0] != req.MergeWith[0] {
+		t.Errorf("Expected related episodes %v, got %v", req.MergeWith, annotation.RelatedEpisodeIDs)
+	}
+	if want := time.Hour; annotation.EndedAt.Sub(annotation.StartedAt) != want {
+		t.Errorf("Expected the merged span to last %s, got %s", want, annotation.EndedAt.Sub(annotation.StartedAt))
+	}
+}
+
+func TestAnnotateEpisode_MergeRollsBackOnFailure(t *testing.T) {
+	for _, failing := range []string{"INSERT INTO macro_episodes", "INSERT INTO episode_annotations"} {
+		t.Run(failing, func(t *testing.T) {
+			db := &fakeDB{}
+			db.fail(failing, errors.New("connection reset"))
+			agent, req := newMergeTestAgent(db)
+
+			if _, err := agent.annotateEpisode(context.Background(), req); err == nil {
+				t.Fatal("Expected the merge to fail")
+			}
+
+			if db.commits != 0 || db.rollbacks != 1 {
+				t.Errorf("Expected one rolled back transaction, got %d commits and %d rollbacks", db.commits, db.rollbacks)
+			}
+			for _, fragment := range []string{"UPDATE macro_episodes m", "DELETE FROM macro_episodes"} {
+				for _, stmt := range db.executed(fragment) {
+					if !stmt.inTx {
+						t.Errorf("Expected %q to run in the rolled back transaction", fragment)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/patterns"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/prediction"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/pkg/auth"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+)
+
+const (
+	// defaultAnnotationListLimit bounds GET /api/annotations when no limit is given
+	defaultAnnotationListLimit = 50
+
+	// defaultExplainAnchorLimit bounds the anchors listed by GET /api/patterns/{id}/explain
+	defaultExplainAnchorLimit = 20
+)
+
+// startAPIServer serves the behavior HTTP API (episode annotations, data purge,
+// admin jobs, pattern definitions, prediction feedback)
+func (a *Agent) startAPIServer() {
+	mux := http.NewServeMux()
+	viewer := func(h http.HandlerFunc) http.HandlerFunc {
+		return auth.RequireRole(a.apiAuth, auth.RoleViewer, a.logger, h)
+	}
+	admin := func(h http.HandlerFunc) http.HandlerFunc {
+		return auth.RequireRole(a.apiAuth, auth.RoleAdmin, a.logger, h)
+	}
+
+	mux.HandleFunc("POST /api/episodes/{id}/annotations", admin(a.handleCreateAnnotation))
+	mux.HandleFunc("GET /api/episodes/{id}/annotations", viewer(a.handleListAnnotations))
+	mux.HandleFunc("GET /api/annotations", viewer(a.handleListAnnotations))
+	mux.HandleFunc("POST /api/purge", admin(a.handlePurge))
+	mux.HandleFunc("POST /api/admin/consolidate", admin(a.handleAdminConsolidate))
+	mux.HandleFunc("POST /api/admin/discover", admin(a.handleAdminDiscover))
+	mux.HandleFunc("GET /api/jobs", admin(a.handleListJobs))
+	mux.HandleFunc("GET /api/jobs/{id}", admin(a.handleGetJob))
+	mux.HandleFunc("GET /api/patterns", viewer(a.handleListPatterns))
+	mux.HandleFunc("POST /api/patterns", admin(a.handleCreatePattern))
+	mux.HandleFunc("GET /api/patterns/{id}", viewer(a.handleGetPattern))
+	mux.HandleFunc("PUT /api/patterns/{id}", admin(a.handleUpdatePattern))
+	mux.HandleFunc("DELETE /api/patterns/{id}", admin(a.handleDeletePattern))
+	mux.HandleFunc("GET /api/patterns/{id}/explain", viewer(a.handleExplainPattern))
+	mux.HandleFunc("POST /api/feedback", admin(a.handleFeedback))
+	mux.HandleFunc("GET /api/llm/models", viewer(a.handleLLMModels))
+	if levels := logging.LevelsOf(a.logger); levels != nil {
+		mux.HandleFunc("GET /api/log-levels", viewer(levels.ServeHTTP))
+		mux.HandleFunc("PUT /api/log-levels", admin(levels.ServeHTTP))
+		mux.HandleFunc("POST /api/log-levels", admin(levels.ServeHTTP))
+	}
+
+	a.apiServer = &http.Server{
+		Addr:    fmt.Sprintf(":%d", a.cfg.BehaviorAPIPort),
+		Handler: mux,
+	}
+
+	go func() {
+		a.logger.Info("Starting behavior API server", "port", a.cfg.BehaviorAPIPort)
+		if err := a.apiServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			a.logger.Error("Behavior API server error", "error", err)
+		}
+	}()
+}
+
+// stopAPIServer gracefully shuts down the HTTP API
+func (a *Agent) stopAPIServer() {
+	if a.apiServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := a.apiServer.Shutdown(ctx); err != nil {
+		a.logger.Error("Failed to shut down behavior API server", "error", err)
+	}
+}
+
+// handleCreateAnnotation handles POST /api/episodes/{id}/annotations
+func (a *Agent) handleCreateAnnotation(w http.ResponseWriter, r *http.Request) {
+	episodeID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid episode id", http.StatusBadRequest)
+		return
+	}
+
+	var req AnnotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	req.EpisodeID = episodeID
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	annotation, err := a.annotateEpisode(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, errEpisodeNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		a.logger.Error("Failed to annotate episode", "episode_id", episodeID, "error", err)
+		http.Error(w, "failed to annotate episode", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(annotation)
+}
+
+// handleListAnnotations handles GET /api/annotations and GET /api/episodes/{id}/annotations
+func (a *Agent) handleListAnnotations(w http.ResponseWriter, r *http.Request) {
+	var episodeID *uuid.UUID
+	if raw := r.PathValue("id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			http.Error(w, "invalid episode id", http.StatusBadRequest)
+			return
+		}
+		episodeID = &id
+	}
+
+	limit := defaultAnnotationListLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	annotations, err := a.listAnnotations(r.Context(), episodeID, limit)
+	if err != nil {
+		a.logger.Error("Failed to list annotations", "error", err)
+		http.Error(w, "failed to list annotations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(annotations)
+}
+
+// handlePurge handles POST /api/purge
+func (a *Agent) handlePurge(w http.ResponseWriter, r *http.Request) {
+	var filter storage.PurgeFilter
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := a.purgeData(r.Context(), filter)
+	if err != nil {
+		a.logger.Error("Failed to purge data", "location", filter.Location, "error", err)
+		http.Error(w, "failed to purge data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PurgeResponse{Filter: filter, Result: result})
+}
+
+// handleAdminConsolidate handles POST /api/admin/consolidate
+func (a *Agent) handleAdminConsolidate(w http.ResponseWriter, r *http.Request) {
+	var req ConsolidateJobRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.LookbackHours < 0 {
+		http.Error(w, "lookback_hours must not be negative", http.StatusBadRequest)
+		return
+	}
+	if req.LookbackHours == 0 {
+		req.LookbackHours = a.cfg.ConsolidationLookbackHours
+	}
+
+	job, err := a.jobs.start("consolidate", req, func(ctx context.Context) error {
+		return a.jobGate.Run(ctx, events.JobConsolidation, logging.CorrelationID(ctx), func(ctx context.Context) error {
+			sinceTime := a.timeManager.Now().Add(-time.Duration(req.LookbackHours) * time.Hour)
+			return a.performConsolidation(ctx, sinceTime, req.Location)
+		})
+	})
+	a.writeJobStarted(w, job, err)
+}
+
+// handleAdminDiscover handles POST /api/admin/discover
+func (a *Agent) handleAdminDiscover(w http.ResponseWriter, r *http.Request) {
+	if a.discoveryAgent == nil {
+		http.Error(w, "pattern discovery is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req DiscoverJobRequest
+	if err := decodeOptionalJSON(r, &req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.MinAnchors < 0 || req.LookbackHours < 0 {
+		http.Error(w, "min_anchors and lookback_hours must not be negative", http.StatusBadRequest)
+		return
+	}
+	if req.MinAnchors == 0 {
+		req.MinAnchors = a.cfg.PatternMinAnchorsForDiscovery
+	}
+	if req.LookbackHours == 0 {
+		req.LookbackHours = a.cfg.PatternLookbackHours
+	}
+
+	job, err := a.jobs.start("discover", req, func(ctx context.Context) error {
+		_, err := a.discoveryAgent.DiscoverPatternsWithLookback(ctx, req.MinAnchors, req.LookbackHours)
+		return err
+	})
+	a.writeJobStarted(w, job, err)
+}
+
+func (a *Agent) writeJobStarted(w http.ResponseWriter, job Job, err error) {
+	if err != nil {
+		if errors.Is(err, errJobAlreadyRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "failed to start job", http.StatusInternalServerError)
+		return
+	}
+
+	statusURL := "/api/jobs/" + job.ID.String()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", statusURL)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(JobStartedResponse{JobID: job.ID, StatusURL: statusURL})
+}
+
+// handleGetJob handles GET /api/jobs/{id}
+func (a *Agent) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	job, ok := a.jobs.get(id)
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleListJobs handles GET /api/jobs
+func (a *Agent) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.jobs.list())
+}
+
+// handleExplainPattern handles GET /api/patterns/{id}/explain?limit=20: the
+// member anchors nearest the centroid, the context they share and the LLM's
+// interpretation of the cluster
+func (a *Agent) handleExplainPattern(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid pattern id", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultExplainAnchorLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	store, err := a.patternStorage()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	pattern, err := store.GetPattern(r.Context(), id)
+	if err != nil {
+		a.writePatternError(w, id, err)
+		return
+	}
+
+	anchors, err := store.GetAnchorsByPattern(r.Context(), id)
+	if err != nil {
+		a.logger.Error("Failed to load pattern anchors", "pattern_id", id, "error", err)
+		http.Error(w, "failed to load pattern anchors", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(patterns.Explain(pattern, anchors, limit))
+}
+
+// handleFeedback handles POST /api/feedback: accept, reject or snooze a
+// prediction or a pattern's automation
+func (a *Agent) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	if a.predictionEngine == nil {
+		http.Error(w, "prediction is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req prediction.Feedback
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	feedback, err := a.predictionEngine.ApplyFeedback(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, prediction.ErrUnknownPrediction) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		a.logger.Error("Failed to apply feedback", "prediction_id", req.PredictionID, "error", err)
+		http.Error(w, "failed to apply feedback", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(feedback)
+}
+
+// handleLLMModels serves GET /api/llm/models: the model of each task, the
+// latency and error rate of every model used since startup and, when probing
+// is enabled, the last probe
+func (a *Agent) handleLLMModels(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"routes": a.llmRouter.Routes(),
+		"models": a.llmRouter.Stats(),
+	}
+	if a.llmMonitor != nil {
+		response["status"] = a.llmMonitor.Status()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// decodeOptionalJSON decodes the request body into v, treating an empty body
+// as "use defaults"
+func decodeOptionalJSON(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package behavior
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -12,15 +13,24 @@ import (
 	"github.com/saaga0h/jeeves-platform/internal/behavior/distance"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/patterns"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 )
 
+// maxCatchUpBatches bounds the scheduled windows run after a restart
+const maxCatchUpBatches = 24
+
+// BatchCompleteTopic carries a BatchResult after every batch, failed or not
+const BatchCompleteTopic = "automation/behavior/batch_complete"
+
 // BatchCoordinator manages sliding window batch processing
 type BatchCoordinator struct {
 	config            *config.Config
 	distanceAgent     *distance.ComputationAgent
 	discoveryAgent    *patterns.DiscoveryAgent
 	mqtt              mqtt.Client
+	db                *sql.DB
 	logger            *slog.Logger
 	lastBatchEnd      time.Time
 	schedulerStopChan chan struct{}
@@ -32,6 +42,7 @@ func NewBatchCoordinator(
 	distanceAgent *distance.ComputationAgent,
 	discoveryAgent *patterns.DiscoveryAgent,
 	mqttClient mqtt.Client,
+	db *sql.DB,
 	logger *slog.Logger,
 ) *BatchCoordinator {
 	return &BatchCoordinator{
@@ -39,6 +50,7 @@ func NewBatchCoordinator(
 		distanceAgent:     distanceAgent,
 		discoveryAgent:    discoveryAgent,
 		mqtt:              mqttClient,
+		db:                db,
 		logger:            logger.With("component", "batch_coordinator"),
 		schedulerStopChan: make(chan struct{}),
 	}
@@ -60,6 +72,7 @@ func (bc *BatchCoordinator) Start(ctx context.Context) error {
 
 	if !bc.config.BatchScheduleEnabled {
 		bc.logger.Info("Batch scheduling disabled, waiting for MQTT triggers")
+		go bc.resumeInterrupted(ctx)
 		return nil
 	}
 
@@ -68,10 +81,86 @@ func (bc *BatchCoordinator) Start(ctx context.Context) error {
 		"batch_duration", bc.config.BatchDuration,
 		"overlap", bc.config.BatchOverlap)
 
-	go bc.schedulerLoop(ctx)
+	go func() {
+		bc.resumeInterrupted(ctx)
+		bc.catchUp(ctx)
+		bc.schedulerLoop(ctx)
+	}()
 	return nil
 }
 
+// resumeInterrupted finishes the batches a previous run of the agent left
+// running, skipping the phases they completed
+func (bc *BatchCoordinator) resumeInterrupted(ctx context.Context) {
+	if bc.db == nil {
+		return
+	}
+
+	interrupted, err := loadInterruptedBatches(ctx, bc.db)
+	if err != nil {
+		bc.logger.Error("Failed to load interrupted batches", "error", err)
+		return
+	}
+
+	for _, result := range interrupted {
+		result.Resumes++
+		bc.logger.Info("Resuming interrupted batch",
+			"batch_id", result.BatchID,
+			"batch_end", result.BatchEnd,
+			"completed_phases", len(result.Phases),
+			"resumes", result.Resumes)
+
+		if err := bc.runBatch(ctx, result); err != nil {
+			bc.logger.Error("Resumed batch failed", "batch_id", result.BatchID, "error", err)
+			continue
+		}
+		if result.Trigger == BatchTriggerSchedule && result.BatchEnd.After(bc.lastBatchEnd) {
+			bc.lastBatchEnd = result.BatchEnd
+		}
+	}
+}
+
+// catchUp continues the schedule from the last completed window, running the
+// windows missed while the agent was down (at most maxCatchUpBatches)
+func (bc *BatchCoordinator) catchUp(ctx context.Context) {
+	if bc.db == nil {
+		return
+	}
+
+	lastEnd, err := lastCompletedBatchEnd(ctx, bc.db, BatchTriggerSchedule)
+	if err != nil {
+		bc.logger.Error("Failed to load last completed batch", "error", err)
+		return
+	}
+	if lastEnd.After(bc.lastBatchEnd) {
+		bc.lastBatchEnd = lastEnd
+	}
+	if bc.lastBatchEnd.IsZero() {
+		return
+	}
+
+	interval := bc.config.BatchScheduleInterval
+	missed := int(time.Since(bc.lastBatchEnd) / interval)
+	if missed == 0 {
+		return
+	}
+	if missed > maxCatchUpBatches {
+		bc.logger.Warn("Too many missed batches, skipping the oldest",
+			"missed", missed,
+			"catching_up", maxCatchUpBatches)
+		bc.lastBatchEnd = bc.lastBatchEnd.Add(time.Duration(missed-maxCatchUpBatches) * interval)
+		missed = maxCatchUpBatches
+	}
+
+	bc.logger.Info("Catching up on missed batches", "from", bc.lastBatchEnd, "batches", missed)
+	for i := 0; i < missed && ctx.Err() == nil; i++ {
+		if err := bc.ProcessBatch(ctx, bc.lastBatchEnd.Add(interval)); err != nil {
+			bc.logger.Error("Failed to process missed batch", "error", err)
+			return
+		}
+	}
+}
+
 // handleBatchTrigger handles MQTT messages to trigger batch processing
 func (bc *BatchCoordinator) handleBatchTrigger(msg mqtt.Message) {
 	var trigger struct {
@@ -137,8 +226,6 @@ func (bc *BatchCoordinator) schedulerLoop(ctx context.Context) {
 
 // ProcessBatch executes a single batch: distance computation + pattern detection
 func (bc *BatchCoordinator) ProcessBatch(ctx context.Context, batchEnd time.Time) error {
-	batchID := uuid.New().String()
-
 	// Calculate time windows
 	batchStart := batchEnd.Add(-bc.config.BatchDuration)
 	overlapStart := batchStart.Add(-bc.config.BatchOverlap)
@@ -149,28 +236,14 @@ func (bc *BatchCoordinator) ProcessBatch(ctx context.Context, batchEnd time.Time
 		bc.logger.Info("Cold start: running first batch without overlap")
 	}
 
-	bc.logger.Info("Starting batch processing",
-		"batch_id", batchID,
-		"overlap_start", overlapStart,
-		"batch_start", batchStart,
-		"batch_end", batchEnd,
-		"total_window", batchEnd.Sub(overlapStart))
-
-	// Step 1: Compute distances for the batch window
-	if err := bc.computeDistancesForWindow(ctx, batchID, overlapStart, batchEnd); err != nil {
-		return fmt.Errorf("distance computation failed: %w", err)
-	}
-
-	// Step 2: Discover patterns for the batch window
-	if err := bc.discoverPatternsForWindow(ctx, batchID, overlapStart, batchEnd); err != nil {
-		return fmt.Errorf("pattern discovery failed: %w", err)
+	result := newBatchResult(BatchTriggerSchedule, overlapStart, batchStart, batchEnd)
+	if err := bc.runBatch(ctx, result); err != nil {
+		return err
 	}
 
 	// Update last batch end for next iteration
 	bc.lastBatchEnd = batchEnd
 
-	bc.logger.Info("Batch processing complete", "batch_id", batchID)
-
 	return nil
 }
 
@@ -182,23 +255,162 @@ func (bc *BatchCoordinator) ProcessBatchFromMessage(
 	batchDuration time.Duration,
 	overlapDuration time.Duration,
 ) error {
-	batchID := uuid.New().String()
-
 	batchStart := batchEnd.Add(-batchDuration)
 	overlapStart := batchStart.Add(-overlapDuration)
 
-	bc.logger.Info("Processing batch from MQTT trigger",
-		"batch_id", batchID,
-		"overlap_start", overlapStart,
-		"batch_start", batchStart,
-		"batch_end", batchEnd)
+	return bc.runBatch(ctx, newBatchResult(BatchTriggerMQTT, overlapStart, batchStart, batchEnd))
+}
+
+// newBatchResult starts the result of a batch over [windowStart, batchEnd]
+func newBatchResult(trigger string, windowStart, batchStart, batchEnd time.Time) *BatchResult {
+	return &BatchResult{
+		BatchID:     uuid.New().String(),
+		Trigger:     trigger,
+		Status:      BatchStatusRunning,
+		WindowStart: windowStart,
+		BatchStart:  batchStart,
+		BatchEnd:    batchEnd,
+		Phases:      []PhaseResult{},
+		StartedAt:   time.Now(),
+	}
+}
+
+// runBatch runs the phases the result has not completed yet, checkpointing
+// to batch_runs after each one, then publishes the result. A batch cut short
+// by shutdown stays running and is resumed on the next start.
+func (bc *BatchCoordinator) runBatch(ctx context.Context, result *BatchResult) error {
+	windowStart, batchEnd := result.WindowStart, result.BatchEnd
+
+	// The batch ID correlates the logs of every phase, including the
+	// distance agent's and the LLM client's
+	ctx = logging.WithCorrelationID(ctx, result.BatchID)
+
+	bc.logger.InfoContext(ctx, "Starting batch processing",
+		"batch_id", result.BatchID,
+		"trigger", result.Trigger,
+		"overlap_start", windowStart,
+		"batch_start", result.BatchStart,
+		"batch_end", batchEnd,
+		"total_window", batchEnd.Sub(windowStart))
 
-	if err := bc.computeDistancesForWindow(ctx, batchID, overlapStart, batchEnd); err != nil {
-		return fmt.Errorf("distance computation failed: %w", err)
+	completed := make(map[string]bool)
+	for _, phase := range result.Phases {
+		completed[phase.Name] = phase.Error == ""
 	}
+	bc.checkpoint(result)
+
+	phases := []struct {
+		name string
+		run  func() error
+	}{
+		{"inputs", func() error {
+			return bc.countInputs(ctx, result)
+		}},
+		{"distances", func() error {
+			return bc.computeDistancesForWindow(ctx, result, windowStart, batchEnd)
+		}},
+		{"patterns", func() error {
+			return bc.discoverPatternsForWindow(ctx, result, windowStart, batchEnd)
+		}},
+	}
+
+	for _, phase := range phases {
+		if completed[phase.name] {
+			continue
+		}
+
+		phaseStart := time.Now()
+		err := phase.run()
+		duration := time.Since(phaseStart).Milliseconds()
+		result.DurationMs += duration
+
+		if err != nil && ctx.Err() != nil {
+			// Shutting down: leave the batch running to resume it later
+			bc.logger.InfoContext(ctx, "Batch interrupted", "batch_id", result.BatchID, "phase", phase.name)
+			return fmt.Errorf("%s phase interrupted: %w", phase.name, ctx.Err())
+		}
+
+		phaseResult := PhaseResult{Name: phase.name, DurationMs: duration}
+		if err != nil {
+			phaseResult.Error = err.Error()
+			result.Status = BatchStatusFailed
+			result.Error = fmt.Sprintf("%s: %v", phase.name, err)
+		}
+		result.Phases = append(result.Phases, phaseResult)
 
-	if err := bc.discoverPatternsForWindow(ctx, batchID, overlapStart, batchEnd); err != nil {
-		return fmt.Errorf("pattern discovery failed: %w", err)
+		if err != nil {
+			bc.finish(result)
+			return fmt.Errorf("%s phase failed: %w", phase.name, err)
+		}
+		bc.checkpoint(result)
+	}
+
+	result.Status = BatchStatusCompleted
+	bc.finish(result)
+	return nil
+}
+
+// checkpoint stores the batch's progress so far. A fresh context is used so
+// progress is kept during shutdown.
+func (bc *BatchCoordinator) checkpoint(result *BatchResult) {
+	saveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := saveBatchResult(saveCtx, bc.db, result); err != nil {
+		bc.logger.Error("Failed to checkpoint batch", "batch_id", result.BatchID, "error", err)
+	}
+}
+
+// finish stores and publishes the final result
+func (bc *BatchCoordinator) finish(result *BatchResult) {
+
+	bc.logger.Info("Batch processing finished",
+		"batch_id", result.BatchID,
+		"status", result.Status,
+		"episodes", result.Episodes,
+		"anchors", result.Anchors,
+		"distances", result.Distances,
+		"patterns", result.Patterns,
+		"duration_ms", result.DurationMs,
+		"resumes", result.Resumes)
+
+	bc.checkpoint(result)
+	bc.publishResult(result)
+}
+
+// publishResult publishes the batch result on BatchCompleteTopic
+func (bc *BatchCoordinator) publishResult(result *BatchResult) {
+	payload, err := events.Marshal(events.ProducerBehavior, result)
+	if err != nil {
+		bc.logger.Error("Failed to marshal batch result", "error", err)
+		return
+	}
+
+	if err := bc.mqtt.Publish(BatchCompleteTopic, 0, false, payload); err != nil {
+		bc.logger.Error("Failed to publish batch result", "batch_id", result.BatchID, "error", err)
+	}
+}
+
+// countInputs records the episodes and anchors in the batch window
+func (bc *BatchCoordinator) countInputs(ctx context.Context, result *BatchResult) error {
+	if bc.db == nil {
+		return nil
+	}
+
+	err := bc.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM behavioral_episodes
+		WHERE started_at_text::timestamptz >= $1 AND started_at_text::timestamptz < $2`,
+		result.WindowStart, result.BatchEnd).Scan(&result.Episodes)
+	if err != nil {
+		return fmt.Errorf("failed to count episodes: %w", err)
+	}
+
+	err = bc.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM semantic_anchors
+		WHERE timestamp >= $1 AND timestamp < $2`,
+		result.WindowStart, result.BatchEnd).Scan(&result.Anchors)
+	if err != nil {
+		return fmt.Errorf("failed to count anchors: %w", err)
 	}
 
 	return nil
@@ -207,13 +419,13 @@ func (bc *BatchCoordinator) ProcessBatchFromMessage(
 // computeDistancesForWindow computes distances for anchors in the time window
 func (bc *BatchCoordinator) computeDistancesForWindow(
 	ctx context.Context,
-	batchID string,
+	result *BatchResult,
 	windowStart, windowEnd time.Time,
 ) error {
 	startTime := time.Now()
 
-	bc.logger.Info("Computing distances for batch window",
-		"batch_id", batchID,
+	bc.logger.InfoContext(ctx, "Computing distances for batch window",
+		"batch_id", result.BatchID,
 		"window_start", windowStart,
 		"window_end", windowEnd)
 
@@ -228,9 +440,19 @@ func (bc *BatchCoordinator) computeDistancesForWindow(
 		return fmt.Errorf("failed to compute distances: %w", err)
 	}
 
+	// Distances written during this phase
+	if bc.db != nil {
+		err := bc.db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM anchor_distances WHERE computed_at >= $1`, startTime).Scan(&result.Distances)
+		if err != nil {
+			return fmt.Errorf("failed to count distances: %w", err)
+		}
+	}
+
 	elapsed := time.Since(startTime)
-	bc.logger.Info("Distance computation complete",
-		"batch_id", batchID,
+	bc.logger.InfoContext(ctx, "Distance computation complete",
+		"batch_id", result.BatchID,
+		"distances", result.Distances,
 		"elapsed", elapsed)
 
 	return nil
@@ -239,13 +461,13 @@ func (bc *BatchCoordinator) computeDistancesForWindow(
 // discoverPatternsForWindow discovers patterns for anchors in the time window
 func (bc *BatchCoordinator) discoverPatternsForWindow(
 	ctx context.Context,
-	batchID string,
+	result *BatchResult,
 	windowStart, windowEnd time.Time,
 ) error {
 	startTime := time.Now()
 
-	bc.logger.Info("Discovering patterns for batch window",
-		"batch_id", batchID,
+	bc.logger.InfoContext(ctx, "Discovering patterns for batch window",
+		"batch_id", result.BatchID,
 		"window_start", windowStart,
 		"window_end", windowEnd)
 
@@ -259,10 +481,11 @@ func (bc *BatchCoordinator) discoverPatternsForWindow(
 	if err != nil {
 		return fmt.Errorf("failed to discover patterns: %w", err)
 	}
+	result.Patterns = patternsCreated
 
 	elapsed := time.Since(startTime)
-	bc.logger.Info("Pattern discovery complete",
-		"batch_id", batchID,
+	bc.logger.InfoContext(ctx, "Pattern discovery complete",
+		"batch_id", result.BatchID,
 		"patterns_created", patternsCreated,
 		"elapsed", elapsed)
 
@@ -0,0 +1,138 @@
+package behavior
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Batch run statuses
+const (
+	BatchStatusRunning   = "running" // in progress, or interrupted and waiting to resume
+	BatchStatusCompleted = "completed"
+	BatchStatusFailed    = "failed"
+)
+
+// Batch triggers
+const (
+	BatchTriggerSchedule = "schedule"
+	BatchTriggerMQTT     = "mqtt"
+)
+
+// BatchResult describes one sliding-window batch. It is published on
+// BatchCompleteTopic and stored in batch_runs.
+type BatchResult struct {
+	BatchID     string        `json:"batch_id"`
+	Trigger     string        `json:"trigger"` // schedule or mqtt
+	Status      string        `json:"status"`
+	WindowStart time.Time     `json:"window_start"` // batch_start minus the overlap
+	BatchStart  time.Time     `json:"batch_start"`
+	BatchEnd    time.Time     `json:"batch_end"`
+	Episodes    int           `json:"episodes"`  // episodes starting in the window
+	Anchors     int           `json:"anchors"`   // anchors in the window
+	Distances   int           `json:"distances"` // distances computed by this batch
+	Patterns    int           `json:"patterns"`  // patterns created by this batch
+	Phases      []PhaseResult `json:"phases"`    // completed phases, and the failed one
+	Error       string        `json:"error,omitempty"`
+	StartedAt   time.Time     `json:"started_at"`
+	DurationMs  int64         `json:"duration_ms"` // sum of the phase durations
+	Resumes     int           `json:"resumes"`     // restarts the batch survived
+}
+
+// PhaseResult is the outcome of one batch phase. Phases after a failed one
+// are not run.
+type PhaseResult struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// saveBatchResult inserts or updates the result in batch_runs
+func saveBatchResult(ctx context.Context, db *sql.DB, result *BatchResult) error {
+	if db == nil {
+		return nil
+	}
+
+	phases, err := json.Marshal(result.Phases)
+	if err != nil {
+		return fmt.Errorf("failed to marshal phases: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO batch_runs (
+			id, trigger, status, window_start, batch_start, batch_end,
+			episodes, anchors, distances, patterns, phases, error,
+			started_at, duration_ms, resumes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13, $14, $15)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			episodes = EXCLUDED.episodes,
+			anchors = EXCLUDED.anchors,
+			distances = EXCLUDED.distances,
+			patterns = EXCLUDED.patterns,
+			phases = EXCLUDED.phases,
+			error = EXCLUDED.error,
+			duration_ms = EXCLUDED.duration_ms,
+			resumes = EXCLUDED.resumes,
+			updated_at = NOW()`,
+		result.BatchID, result.Trigger, result.Status,
+		result.WindowStart, result.BatchStart, result.BatchEnd,
+		result.Episodes, result.Anchors, result.Distances, result.Patterns,
+		phases, result.Error, result.StartedAt, result.DurationMs, result.Resumes)
+	if err != nil {
+		return fmt.Errorf("failed to save batch run: %w", err)
+	}
+
+	return nil
+}
+
+// loadInterruptedBatches returns the batches still marked running, oldest
+// first, with the phases they completed
+func loadInterruptedBatches(ctx context.Context, db *sql.DB) ([]*BatchResult, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id::text, trigger, window_start, batch_start, batch_end,
+			episodes, anchors, distances, patterns, phases,
+			started_at, duration_ms, resumes
+		FROM batch_runs
+		WHERE status = $1
+		ORDER BY batch_end`, BatchStatusRunning)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query interrupted batches: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*BatchResult
+	for rows.Next() {
+		result := &BatchResult{Status: BatchStatusRunning}
+		var phases []byte
+		if err := rows.Scan(
+			&result.BatchID, &result.Trigger,
+			&result.WindowStart, &result.BatchStart, &result.BatchEnd,
+			&result.Episodes, &result.Anchors, &result.Distances, &result.Patterns,
+			&phases, &result.StartedAt, &result.DurationMs, &result.Resumes,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan interrupted batch: %w", err)
+		}
+		if err := json.Unmarshal(phases, &result.Phases); err != nil {
+			return nil, fmt.Errorf("failed to parse phases of batch %s: %w", result.BatchID, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// lastCompletedBatchEnd returns the end of the latest completed batch with
+// the given trigger, zero when there is none
+func lastCompletedBatchEnd(ctx context.Context, db *sql.DB, trigger string) (time.Time, error) {
+	var end sql.NullTime
+	err := db.QueryRowContext(ctx, `
+		SELECT MAX(batch_end) FROM batch_runs
+		WHERE status = $1 AND trigger = $2`, BatchStatusCompleted, trigger).Scan(&end)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query last completed batch: %w", err)
+	}
+	return end.Time, nil
+}
@@ -0,0 +1,179 @@
+package clustering
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// pairBytes estimates the memory one anchor pair takes while a block is
+	// clustered: its distance map entry plus HDBSCAN's dense matrix cells
+	pairBytes = 160
+
+	// cacheEntryBytes estimates the memory one distance cache entry takes: its
+	// key, map slot and LRU list element
+	cacheEntryBytes = 200
+
+	// blockOverlap is the share of each block repeated in the next one, so
+	// clusters crossing a block boundary are found on both sides and merged
+	blockOverlap = 4 // one quarter
+)
+
+// blockSize returns how many anchors fit in the memory budget, or 0 when the
+// budget is unbounded. The distance cache stays resident beside each block, so
+// its full capacity is taken off the budget first.
+func (e *ClusteringEngine) blockSize() int {
+	if e.config.MemoryMB <= 0 {
+		return 0
+	}
+
+	budget := float64(e.config.MemoryMB)*1024*1024 - float64(max(e.config.CacheSize, 0))*cacheEntryBytes
+
+	// k anchors hold k(k-1)/2 pairs
+	pairs := math.Max(budget, 0) / pairBytes
+	size := int(math.Sqrt(2 * pairs))
+
+	// The overlap must still hold a cluster's worth of anchors
+	if minSize := blockOverlap * e.minClusterSize() * 2; size < minSize {
+		return minSize
+	}
+	return size
+}
+
+// clusterBlocked clusters a run too large for the memory budget. Anchors are
+// ordered by time and clustered in blocks of size anchors, each overlapping
+// the previous one by a quarter; clusters sharing an anchor in an overlap are
+// merged. A routine recurring across the lookback thus ends up in one cluster
+// while only one block's distances are held at a time. Anchors near a block
+// edge see fewer neighbours than in a single run, so results can differ
+// slightly from the in-memory clustering.
+func (e *ClusteringEngine) clusterBlocked(
+	ctx context.Context,
+	anchorIDs []uuid.UUID,
+	epsilon float64,
+	size int,
+) ([]*Cluster, error) {
+	ordered, err := e.temporalOrder(ctx, anchorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	step := size - size/blockOverlap
+	blocks := 1
+	if len(ordered) > size {
+		blocks += (len(ordered) - size + step - 1) / step
+	}
+
+	e.logger.Info("Starting blocked clustering",
+		"anchors", len(ordered),
+		"block_size", size,
+		"blocks", blocks,
+		"memory_mb", e.config.MemoryMB,
+		"algorithm", e.config.Algorithm)
+
+	// Union-find over the clusters of all blocks
+	parent := []int{}
+	find := func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	label := make(map[uuid.UUID]int, len(ordered)) // anchor → first block cluster it joined
+
+	for start, block := 0, 1; ; start, block = start+step, block+1 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		end := min(start+size, len(ordered))
+		clusters, err := e.cluster(ctx, ordered[start:end], epsilon)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cluster block %d of %d: %w", block, blocks, err)
+		}
+
+		merged := 0
+		for _, cluster := range clusters {
+			if cluster.Noise {
+				continue
+			}
+			id := len(parent)
+			parent = append(parent, id)
+			for _, member := range cluster.Members {
+				prev, ok := label[member]
+				if !ok {
+					label[member] = id
+					continue
+				}
+				if root, other := find(prev), find(id); root != other {
+					parent[other] = root
+					merged++
+				}
+			}
+		}
+
+		e.logger.Debug("Clustered block",
+			"block", block,
+			"anchors", end-start,
+			"clusters", len(clusters),
+			"merged_across_boundary", merged)
+
+		if end == len(ordered) {
+			break
+		}
+	}
+
+	// Number merged clusters in order of their earliest anchor
+	ids := make(map[int]int)
+	var clusters []*Cluster
+	noise := &Cluster{ID: -1, Members: []uuid.UUID{}, Noise: true}
+	for _, anchorID := range ordered {
+		l, ok := label[anchorID]
+		if !ok {
+			noise.Members = append(noise.Members, anchorID)
+			continue
+		}
+		root := find(l)
+		idx, ok := ids[root]
+		if !ok {
+			idx = len(clusters)
+			ids[root] = idx
+			clusters = append(clusters, &Cluster{ID: idx + 1, Members: []uuid.UUID{}})
+		}
+		clusters[idx].Members = append(clusters[idx].Members, anchorID)
+	}
+	if len(noise.Members) > 0 {
+		clusters = append(clusters, noise)
+	}
+
+	e.logger.Info("Blocked clustering completed",
+		"clusters_found", len(ids),
+		"noise_points", len(noise.Members))
+
+	return clusters, nil
+}
+
+// temporalOrder returns anchorIDs sorted by anchor timestamp. Anchors missing
+// from storage keep their relative order at the end.
+func (e *ClusteringEngine) temporalOrder(ctx context.Context, anchorIDs []uuid.UUID) ([]uuid.UUID, error) {
+	timestamps, err := e.storage.GetAnchorTimestamps(ctx, anchorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load anchor timestamps: %w", err)
+	}
+
+	ordered := append([]uuid.UUID(nil), anchorIDs...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ti, okI := timestamps[ordered[i]]
+		tj, okJ := timestamps[ordered[j]]
+		if okI != okJ {
+			return okI
+		}
+		return ti.Before(tj)
+	})
+	return ordered, nil
+}
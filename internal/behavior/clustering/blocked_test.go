@@ -0,0 +1,105 @@
+package clustering
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+// groupEmbedding returns an embedding that differs between groups only in its
+// spatial dimensions
+func groupEmbedding(group int) pgvector.Vector {
+	v := make([]float32, 128)
+	for i := 1; i < 8; i += 2 {
+		v[i] = 1 // cos 0 in the temporal and seasonal pairs
+	}
+	v[12+group] = 1
+	return pgvector.NewVector(v)
+}
+
+func TestClusterBlocked_MergesAcrossBlocks(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryAnchorStorage()
+	t0 := time.Date(2025, 10, 1, 7, 0, 0, 0, time.UTC)
+
+	// Two routines alternating over 30 anchors, so each spans every block
+	ids := make([]uuid.UUID, 30)
+	group := make(map[uuid.UUID]int)
+	for i := range ids {
+		anchor := &types.SemanticAnchor{
+			ID:                uuid.New(),
+			Timestamp:         t0.Add(time.Duration(i) * time.Minute),
+			Location:          "kitchen",
+			SemanticEmbedding: groupEmbedding(i % 2),
+			EmbeddingVersion:  1,
+		}
+		if err := store.CreateAnchor(ctx, anchor); err != nil {
+			t.Fatalf("Failed to create anchor: %v", err)
+		}
+		ids[i] = anchor.ID
+		group[anchor.ID] = i % 2
+	}
+	rand.New(rand.NewSource(1)).Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	engine := NewClusteringEngine(DBSCANConfig{Algorithm: AlgorithmDBSCAN, Epsilon: 0.1, MinPoints: 3}, store, logger)
+
+	// Blocks of 12 with a step of 9: anchors 0-11, 9-20 and 18-29
+	clusters, err := engine.clusterBlocked(ctx, ids, 0.1, 12)
+	if err != nil {
+		t.Fatalf("clusterBlocked failed: %v", err)
+	}
+
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %d", len(clusters))
+	}
+	for _, cluster := range clusters {
+		if cluster.Noise {
+			t.Fatalf("Expected no noise, got %d noise points", len(cluster.Members))
+		}
+		if len(cluster.Members) != 15 {
+			t.Errorf("Expected cluster %d to hold 15 anchors, got %d", cluster.ID, len(cluster.Members))
+		}
+		for _, member := range cluster.Members {
+			if group[member] != group[cluster.Members[0]] {
+				t.Errorf("Expected cluster %d to hold one routine", cluster.ID)
+				break
+			}
+		}
+	}
+	if group[clusters[0].Members[0]] == group[clusters[1].Members[0]] {
+		t.Error("Expected the two routines in different clusters")
+	}
+}
+
+func TestBlockSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		memoryMB  int
+		cacheSize int
+		want      int
+	}{
+		{"unbounded", 0, 500000, 0},
+		{"no cache", 1, 0, 114},
+		{"cache counts against the budget", 1, 2000, 90},
+		{"cache larger than the budget", 1, 10000, 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := testEngine(DBSCANConfig{MinPoints: 3, MemoryMB: tt.memoryMB, CacheSize: tt.cacheSize})
+			if got := engine.blockSize(); got != tt.want {
+				t.Errorf("Expected block size %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pgvector/pgvector-go"
 
-	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
 )
 
@@ -101,10 +103,15 @@ func cosineSimilaritySlice(v1, v2 []float32) float64 {
 	return dot / (math.Sqrt(mag1) * math.Sqrt(mag2))
 }
 
-// DBSCANConfig configures the DBSCAN clustering algorithm
+// DBSCANConfig configures the clustering algorithm
 type DBSCANConfig struct {
-	Epsilon   float64 // maximum distance for neighborhood (default: 0.3)
-	MinPoints int     // minimum points to form cluster (default: 5)
+	Epsilon        float64 // maximum distance for neighborhood (default: 0.3, DBSCAN only)
+	MinPoints      int     // minimum points to form cluster (default: 5)
+	Algorithm      string  // "dbscan" (default) or "hdbscan"
+	MinClusterSize int     // smallest HDBSCAN cluster (default: MinPoints)
+	Workers        int     // goroutines for distance and neighborhood computation (default: NumCPU)
+	CacheSize      int     // anchor pairs kept in the distance LRU cache (0 = no caching)
+	MemoryMB       int     // distance matrix budget; larger runs are clustered in temporal blocks (0 = unbounded)
 }
 
 // Cluster represents a group of semantically similar anchors
@@ -114,24 +121,41 @@ type Cluster struct {
 	Noise   bool        // true if this is noise cluster
 }
 
-// ClusteringEngine performs DBSCAN clustering on semantic anchors
+// AnchorSource is the anchor storage clustering reads from. AnchorStorage
+// implements it on PostgreSQL and MemoryAnchorStorage in memory.
+type AnchorSource interface {
+	GetAnchorsByIDs(ctx context.Context, ids []uuid.UUID) ([]*types.SemanticAnchor, error)
+	GetAnchorTimestamps(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]time.Time, error)
+}
+
+// ClusteringEngine performs DBSCAN or HDBSCAN clustering on semantic anchors
 type ClusteringEngine struct {
 	config  DBSCANConfig
-	storage *storage.AnchorStorage
+	storage AnchorSource
 	logger  *slog.Logger
+	cache   *distanceCache
 }
 
 // NewClusteringEngine creates a new clustering engine
 func NewClusteringEngine(
 	config DBSCANConfig,
-	storage *storage.AnchorStorage,
+	storage AnchorSource,
 	logger *slog.Logger,
 ) *ClusteringEngine {
 	return &ClusteringEngine{
 		config:  config,
 		storage: storage,
 		logger:  logger,
+		cache:   newDistanceCache(config.CacheSize),
+	}
+}
+
+// workers returns the number of goroutines to use for parallel work
+func (e *ClusteringEngine) workers() int {
+	if e.config.Workers > 0 {
+		return e.config.Workers
 	}
+	return runtime.NumCPU()
 }
 
 // ClusterAnchors performs DBSCAN clustering on anchor set
@@ -142,7 +166,14 @@ func (e *ClusteringEngine) ClusterAnchors(
 	return e.ClusterAnchorsWithEpsilon(ctx, anchorIDs, e.config.Epsilon)
 }
 
-// ClusterAnchorsWithEpsilon performs DBSCAN clustering with custom epsilon
+// UsesEpsilon reports whether clustering depends on a fixed epsilon. HDBSCAN adapts
+// to local density, so callers can skip per-phase epsilon tuning.
+func (e *ClusteringEngine) UsesEpsilon() bool {
+	return e.config.Algorithm != AlgorithmHDBSCAN
+}
+
+// ClusterAnchorsWithEpsilon performs DBSCAN clustering with custom epsilon.
+// With HDBSCAN the epsilon is ignored.
 func (e *ClusteringEngine) ClusterAnchorsWithEpsilon(
 	ctx context.Context,
 	anchorIDs []uuid.UUID,
@@ -154,21 +185,26 @@ func (e *ClusteringEngine) ClusterAnchorsWithEpsilon(
 			len(anchorIDs), e.config.MinPoints)
 	}
 
-	e.logger.Info("Starting DBSCAN clustering",
-		"anchors", len(anchorIDs),
-		"epsilon", epsilon,
-		"min_points", e.config.MinPoints)
-
-	// Load distance matrix
-	distances, err := e.loadDistanceMatrix(ctx, anchorIDs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load distances: %w", err)
+	if size := e.blockSize(); size > 0 && len(anchorIDs) > size {
+		return e.clusterBlocked(ctx, anchorIDs, epsilon, size)
 	}
 
-	e.logger.Debug("Loaded distance matrix", "pairs", len(distances))
+	if e.UsesEpsilon() {
+		e.logger.Info("Starting DBSCAN clustering",
+			"anchors", len(anchorIDs),
+			"epsilon", epsilon,
+			"min_points", e.config.MinPoints)
+	} else {
+		e.logger.Info("Starting HDBSCAN clustering",
+			"anchors", len(anchorIDs),
+			"min_points", e.config.MinPoints,
+			"min_cluster_size", e.minClusterSize())
+	}
 
-	// Run DBSCAN with custom epsilon
-	clusters := e.dbscanWithEpsilon(anchorIDs, distances, epsilon)
+	clusters, err := e.cluster(ctx, anchorIDs, epsilon)
+	if err != nil {
+		return nil, err
+	}
 
 	// Count noise points
 	noiseCount := 0
@@ -188,6 +224,27 @@ func (e *ClusteringEngine) ClusterAnchorsWithEpsilon(
 	return clusters, nil
 }
 
+// cluster loads the distance matrix of anchorIDs and runs the configured algorithm
+func (e *ClusteringEngine) cluster(
+	ctx context.Context,
+	anchorIDs []uuid.UUID,
+	epsilon float64,
+) ([]*Cluster, error) {
+	// Load distance matrix
+	distances, err := e.loadDistanceMatrix(ctx, anchorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load distances: %w", err)
+	}
+
+	e.logger.Debug("Loaded distance matrix", "pairs", len(distances))
+
+	if e.UsesEpsilon() {
+		// Run DBSCAN with custom epsilon
+		return e.dbscanWithEpsilon(anchorIDs, distances, epsilon), nil
+	}
+	return e.hdbscan(anchorIDs, distances), nil
+}
+
 func (e *ClusteringEngine) loadDistanceMatrix(
 	ctx context.Context,
 	anchorIDs []uuid.UUID,
@@ -207,27 +264,73 @@ func (e *ClusteringEngine) loadDistanceMatrix(
 		anchorMap[anchors[i].ID] = anchors[i]
 	}
 
-	// Compute all pairwise distances in-memory using structured distance
-	missingCount := 0
-	for i := 0; i < len(anchorIDs); i++ {
-		for j := i + 1; j < len(anchorIDs); j++ {
-			anchor1, ok1 := anchorMap[anchorIDs[i]]
-			anchor2, ok2 := anchorMap[anchorIDs[j]]
-
-			if !ok1 || !ok2 {
-				e.logger.Warn("Missing anchor in map",
-					"anchor1_found", ok1,
-					"anchor2_found", ok2)
-				continue
+	// Compute all pairwise distances in-memory using structured distance, spreading
+	// rows of the matrix across workers and reusing cached pairs
+	var (
+		mu            sync.Mutex
+		wg            sync.WaitGroup
+		computedFresh int
+		cachedCount   int
+	)
+	rows := make(chan int)
+
+	for w := 0; w < e.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			local := make(map[string]float64)
+			fresh, cached := 0, 0
+
+			for i := range rows {
+				for j := i + 1; j < len(anchorIDs); j++ {
+					anchor1, ok1 := anchorMap[anchorIDs[i]]
+					anchor2, ok2 := anchorMap[anchorIDs[j]]
+
+					if !ok1 || !ok2 {
+						e.logger.Warn("Missing anchor in map",
+							"anchor1_found", ok1,
+							"anchor2_found", ok2)
+						continue
+					}
+
+					key := distanceKey(anchorIDs[i], anchorIDs[j])
+					versionedKey := cacheKey(anchor1, anchor2)
+					if dist, ok := e.cache.Get(versionedKey); ok {
+						local[key] = dist
+						cached++
+						continue
+					}
+
+					// Compute structured distance in-memory
+					dist := structuredDist(anchor1.SemanticEmbedding, anchor2.SemanticEmbedding)
+					e.cache.Put(versionedKey, dist)
+					local[key] = dist
+					fresh++
+				}
 			}
 
-			// Compute structured distance in-memory
-			dist := structuredDist(anchor1.SemanticEmbedding, anchor2.SemanticEmbedding)
+			mu.Lock()
+			for key, dist := range local {
+				distances[key] = dist
+			}
+			computedFresh += fresh
+			cachedCount += cached
+			mu.Unlock()
+		}()
+	}
 
-			key := distanceKey(anchorIDs[i], anchorIDs[j])
-			distances[key] = dist
-			missingCount++
+	for i := 0; i < len(anchorIDs); i++ {
+		if ctx.Err() != nil {
+			break
 		}
+		rows <- i
+	}
+	close(rows)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// Calculate distance statistics
@@ -258,15 +361,32 @@ func (e *ClusteringEngine) loadDistanceMatrix(
 
 	e.logger.Info("Computed distance matrix in-memory",
 		"total_pairs", len(distances),
-		"computed_fresh", missingCount,
+		"computed_fresh", computedFresh,
+		"from_cache", cachedCount,
 		"min_distance", minDist,
 		"max_distance", maxDist,
 		"avg_distance", avgDist,
 		"std_dev", stdDev)
 
+	if cacheSize, hits, misses := e.cache.Stats(); hits+misses > 0 {
+		e.logger.Debug("Distance cache stats",
+			"cached_pairs", cacheSize,
+			"hits", hits,
+			"misses", misses)
+	}
+
 	return distances, nil
 }
 
+// cacheKey extends distanceKey with the embedding version of each anchor, so a
+// re-embedded anchor never hits distances computed from its old embedding
+func cacheKey(a1, a2 *types.SemanticAnchor) string {
+	if a1.ID.String() > a2.ID.String() {
+		a1, a2 = a2, a1
+	}
+	return fmt.Sprintf("%s-%s@%d/%d", a1.ID, a2.ID, a1.EmbeddingVersion, a2.EmbeddingVersion)
+}
+
 // distanceKey creates a canonical key for distance lookup
 func distanceKey(id1, id2 uuid.UUID) string {
 	// Ensure consistent ordering
@@ -291,6 +411,9 @@ func (e *ClusteringEngine) dbscanWithEpsilon(
 	epsilon float64,
 ) []*Cluster {
 
+	// Region queries are independent, so run them all up front in parallel
+	neighborhoods := e.neighborhoods(anchorIDs, distances, epsilon)
+
 	// Track visited and cluster assignments
 	visited := make(map[uuid.UUID]bool)
 	clusterID := make(map[uuid.UUID]int)
@@ -305,7 +428,7 @@ func (e *ClusteringEngine) dbscanWithEpsilon(
 		visited[anchorID] = true
 
 		// Get neighbors within epsilon
-		neighbors := e.getNeighborsWithEpsilon(anchorID, anchorIDs, distances, epsilon)
+		neighbors := append([]uuid.UUID(nil), neighborhoods[anchorID]...)
 
 		if len(neighbors) < e.config.MinPoints {
 			// Mark as noise (will be cluster -1)
@@ -326,7 +449,7 @@ func (e *ClusteringEngine) dbscanWithEpsilon(
 			"neighbors", len(neighbors))
 
 		// Expand cluster
-		e.expandClusterWithEpsilon(anchorID, neighbors, currentCluster, anchorIDs, distances, visited, clusterID, epsilon)
+		e.expandClusterIndexed(anchorID, neighbors, currentCluster, neighborhoods, visited, clusterID)
 	}
 
 	// Build cluster objects
@@ -385,27 +508,48 @@ func (e *ClusteringEngine) getNeighborsWithEpsilon(
 	return neighbors
 }
 
-func (e *ClusteringEngine) expandCluster(
-	anchorID uuid.UUID,
-	neighbors []uuid.UUID,
-	clusterNum int,
-	allAnchors []uuid.UUID,
+// neighborhoods computes the epsilon-neighborhood of every anchor using a pool of workers
+func (e *ClusteringEngine) neighborhoods(
+	anchorIDs []uuid.UUID,
 	distances map[string]float64,
-	visited map[uuid.UUID]bool,
-	clusterID map[uuid.UUID]int,
-) {
-	e.expandClusterWithEpsilon(anchorID, neighbors, clusterNum, allAnchors, distances, visited, clusterID, e.config.Epsilon)
+	epsilon float64,
+) map[uuid.UUID][]uuid.UUID {
+
+	results := make([][]uuid.UUID, len(anchorIDs))
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < e.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i] = e.getNeighborsWithEpsilon(anchorIDs[i], anchorIDs, distances, epsilon)
+			}
+		}()
+	}
+
+	for i := range anchorIDs {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	neighborhoods := make(map[uuid.UUID][]uuid.UUID, len(anchorIDs))
+	for i, anchorID := range anchorIDs {
+		neighborhoods[anchorID] = results[i]
+	}
+	return neighborhoods
 }
 
-func (e *ClusteringEngine) expandClusterWithEpsilon(
+// expandClusterIndexed grows a cluster using precomputed neighborhoods
+func (e *ClusteringEngine) expandClusterIndexed(
 	anchorID uuid.UUID,
 	neighbors []uuid.UUID,
 	clusterNum int,
-	allAnchors []uuid.UUID,
-	distances map[string]float64,
+	neighborhoods map[uuid.UUID][]uuid.UUID,
 	visited map[uuid.UUID]bool,
 	clusterID map[uuid.UUID]int,
-	epsilon float64,
 ) {
 
 	i := 0
@@ -416,7 +560,7 @@ func (e *ClusteringEngine) expandClusterWithEpsilon(
 			visited[neighborID] = true
 
 			// Get neighbors of neighbor
-			neighborNeighbors := e.getNeighborsWithEpsilon(neighborID, allAnchors, distances, epsilon)
+			neighborNeighbors := neighborhoods[neighborID]
 
 			if len(neighborNeighbors) >= e.config.MinPoints {
 				// Add new neighbors to expansion list
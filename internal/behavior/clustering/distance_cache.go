@@ -0,0 +1,84 @@
+package clustering
+
+import (
+	"container/list"
+	"sync"
+)
+
+// distanceCache is a thread-safe LRU cache of structured distances keyed by
+// anchor pair and embedding versions (see cacheKey). Cached distances stay valid
+// across discovery runs and clustering phases; after a re-embedding the old
+// versions' entries are never hit again and age out.
+type distanceCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front = most recently used
+
+	hits   int64
+	misses int64
+}
+
+type distanceCacheEntry struct {
+	key      string
+	distance float64
+}
+
+// newDistanceCache creates a cache holding up to capacity pairs (0 disables caching)
+func newDistanceCache(capacity int) *distanceCache {
+	return &distanceCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached distance for key, if present
+func (c *distanceCache) Get(key string) (float64, bool) {
+	if c.capacity <= 0 {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return 0, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*distanceCacheEntry).distance, true
+}
+
+// Put stores a distance, evicting the least recently used pair when full
+func (c *distanceCache) Put(key string, distance float64) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*distanceCacheEntry).distance = distance
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&distanceCacheEntry{key: key, distance: distance})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*distanceCacheEntry).key)
+	}
+}
+
+// Stats returns the number of cached pairs and lifetime hit/miss counts
+func (c *distanceCache) Stats() (size int, hits, misses int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len(), c.hits, c.misses
+}
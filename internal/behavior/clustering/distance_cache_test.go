@@ -0,0 +1,60 @@
+package clustering
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestCacheKey(t *testing.T) {
+	a := &types.SemanticAnchor{ID: uuid.New(), EmbeddingVersion: 1}
+	b := &types.SemanticAnchor{ID: uuid.New(), EmbeddingVersion: 1}
+
+	if cacheKey(a, b) != cacheKey(b, a) {
+		t.Errorf("Expected the key to ignore pair order, got %q and %q", cacheKey(a, b), cacheKey(b, a))
+	}
+
+	before := cacheKey(a, b)
+	b.EmbeddingVersion = 2
+	if cacheKey(a, b) == before {
+		t.Error("Expected a re-embedded anchor to change the key")
+	}
+}
+
+func TestDistanceCache_ReembeddedAnchorMisses(t *testing.T) {
+	cache := newDistanceCache(10)
+	a := &types.SemanticAnchor{ID: uuid.New(), EmbeddingVersion: 1}
+	b := &types.SemanticAnchor{ID: uuid.New(), EmbeddingVersion: 1}
+
+	cache.Put(cacheKey(a, b), 0.4)
+	if d, ok := cache.Get(cacheKey(b, a)); !ok || d != 0.4 {
+		t.Fatalf("Expected cached distance 0.4, got %v (found %v)", d, ok)
+	}
+
+	a.EmbeddingVersion = 2
+	if _, ok := cache.Get(cacheKey(a, b)); ok {
+		t.Error("Expected no cached distance after re-embedding")
+	}
+}
+
+func TestDistanceCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newDistanceCache(2)
+	cache.Put("a", 0.1)
+	cache.Put("b", 0.2)
+	cache.Get("a")
+	cache.Put("c", 0.3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected %s to be cached", key)
+		}
+	}
+	if size, _, _ := cache.Stats(); size != 2 {
+		t.Errorf("Expected 2 cached pairs, got %d", size)
+	}
+}
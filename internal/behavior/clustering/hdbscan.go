@@ -0,0 +1,371 @@
+package clustering
+
+import (
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Clustering algorithms supported by ClusteringEngine
+const (
+	AlgorithmDBSCAN  = "dbscan"
+	AlgorithmHDBSCAN = "hdbscan"
+)
+
+// linkageNode is a merge in the single-linkage hierarchy. Leaves (anchors) are
+// nodes 0..n-1; merge i is node n+i.
+type linkageNode struct {
+	left, right int
+	distance    float64
+	size        int
+}
+
+// condensedCluster is a cluster in the HDBSCAN condensed tree
+type condensedCluster struct {
+	parent    int
+	children  []int
+	points    []int // points that fell out of this cluster directly
+	birth     float64
+	stability float64
+}
+
+// hdbscan clusters anchors by density without a global epsilon. Distances are
+// transformed into mutual reachability distances (using each point's distance to
+// its MinPoints-th neighbour), a minimum spanning tree over them is turned into a
+// cluster hierarchy, and the most stable clusters of at least MinClusterSize
+// anchors are selected. Tight same-location groups and looser cross-location
+// groups can therefore come out of a single run.
+func (e *ClusteringEngine) hdbscan(
+	anchorIDs []uuid.UUID,
+	distances map[string]float64,
+) []*Cluster {
+	n := len(anchorIDs)
+	minClusterSize := e.minClusterSize()
+
+	// Dense distance matrix; missing pairs are treated as maximally distant
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d, ok := distances[distanceKey(anchorIDs[i], anchorIDs[j])]
+			if !ok {
+				d = 1.0
+			}
+			dist[i][j] = d
+			dist[j][i] = d
+		}
+	}
+
+	core := coreDistances(dist, e.config.MinPoints)
+	hierarchy := singleLinkage(mutualReachabilityMST(dist, core), n)
+	tree := condenseTree(hierarchy, n, minClusterSize)
+	selected := selectClusters(tree, minClusterSize)
+
+	// Label each point with its selected cluster (-1 = noise)
+	labels := make([]int, n)
+	for i := range labels {
+		labels[i] = -1
+	}
+	for label, root := range selected {
+		for _, point := range clusterPoints(tree, root) {
+			labels[point] = label + 1
+		}
+	}
+
+	clusterMap := make(map[int]*Cluster)
+	for i, label := range labels {
+		if _, exists := clusterMap[label]; !exists {
+			clusterMap[label] = &Cluster{
+				ID:      label,
+				Members: []uuid.UUID{},
+				Noise:   label == -1,
+			}
+		}
+		clusterMap[label].Members = append(clusterMap[label].Members, anchorIDs[i])
+	}
+
+	var clusters []*Cluster
+	for _, cluster := range clusterMap {
+		clusters = append(clusters, cluster)
+	}
+
+	e.logger.Debug("HDBSCAN hierarchy condensed",
+		"condensed_clusters", len(tree),
+		"selected", len(selected),
+		"min_cluster_size", minClusterSize)
+
+	return clusters
+}
+
+// minClusterSize returns the configured minimum cluster size, defaulting to MinPoints
+func (e *ClusteringEngine) minClusterSize() int {
+	size := e.config.MinClusterSize
+	if size <= 0 {
+		size = e.config.MinPoints
+	}
+	if size < 2 {
+		size = 2
+	}
+	return size
+}
+
+// coreDistances returns each point's distance to its k-th nearest neighbour
+// (excluding itself), matching DBSCAN's "at least MinPoints neighbours" rule
+func coreDistances(dist [][]float64, k int) []float64 {
+	n := len(dist)
+	core := make([]float64, n)
+	if k < 1 {
+		k = 1
+	}
+
+	for i := 0; i < n; i++ {
+		others := make([]float64, 0, n-1)
+		for j := 0; j < n; j++ {
+			if i != j {
+				others = append(others, dist[i][j])
+			}
+		}
+		sort.Float64s(others)
+
+		idx := k - 1
+		if idx >= len(others) {
+			idx = len(others) - 1
+		}
+		if idx >= 0 {
+			core[i] = others[idx]
+		}
+	}
+
+	return core
+}
+
+// mstEdge is an edge of the mutual reachability minimum spanning tree
+type mstEdge struct {
+	a, b     int
+	distance float64
+}
+
+// mutualReachabilityMST builds a minimum spanning tree (Prim's algorithm) over
+// mutual reachability distances max(core[a], core[b], dist[a][b])
+func mutualReachabilityMST(dist [][]float64, core []float64) []mstEdge {
+	n := len(dist)
+	if n == 0 {
+		return nil
+	}
+
+	inTree := make([]bool, n)
+	best := make([]float64, n)
+	from := make([]int, n)
+	for i := range best {
+		best[i] = math.Inf(1)
+	}
+
+	edges := make([]mstEdge, 0, n-1)
+	current := 0
+	inTree[current] = true
+
+	for len(edges) < n-1 {
+		next := -1
+		for j := 0; j < n; j++ {
+			if inTree[j] {
+				continue
+			}
+			reach := math.Max(dist[current][j], math.Max(core[current], core[j]))
+			if reach < best[j] {
+				best[j] = reach
+				from[j] = current
+			}
+			if next == -1 || best[j] < best[next] {
+				next = j
+			}
+		}
+
+		edges = append(edges, mstEdge{a: from[next], b: next, distance: best[next]})
+		inTree[next] = true
+		current = next
+	}
+
+	return edges
+}
+
+// singleLinkage turns MST edges into a merge hierarchy using union-find
+func singleLinkage(edges []mstEdge, n int) []linkageNode {
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].distance < edges[j].distance
+	})
+
+	parent := make([]int, 2*n-1)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+
+	sizes := make([]int, 2*n-1)
+	for i := 0; i < n; i++ {
+		sizes[i] = 1
+	}
+
+	hierarchy := make([]linkageNode, 0, n-1)
+	for _, edge := range edges {
+		ra, rb := find(edge.a), find(edge.b)
+		node := n + len(hierarchy)
+		sizes[node] = sizes[ra] + sizes[rb]
+		hierarchy = append(hierarchy, linkageNode{
+			left:     ra,
+			right:    rb,
+			distance: edge.distance,
+			size:     sizes[node],
+		})
+		parent[ra] = node
+		parent[rb] = node
+	}
+
+	return hierarchy
+}
+
+// condenseTree walks the hierarchy from the root, keeping only splits where both
+// sides have at least minClusterSize points. Smaller branches are points falling
+// out of their cluster; each cluster's stability sums how long (in lambda = 1/distance)
+// its points stayed in it.
+func condenseTree(hierarchy []linkageNode, n, minClusterSize int) []*condensedCluster {
+	tree := []*condensedCluster{{parent: -1}}
+	if n < 2 {
+		if n == 1 {
+			tree[0].points = []int{0}
+		}
+		return tree
+	}
+
+	sizeOf := func(node int) int {
+		if node < n {
+			return 1
+		}
+		return hierarchy[node-n].size
+	}
+
+	var leaves func(node int, out []int) []int
+	leaves = func(node int, out []int) []int {
+		if node < n {
+			return append(out, node)
+		}
+		h := hierarchy[node-n]
+		out = leaves(h.left, out)
+		return leaves(h.right, out)
+	}
+
+	var walk func(node, cluster int)
+	walk = func(node, cluster int) {
+		if node < n {
+			// Reached a single point still attached to the cluster
+			tree[cluster].points = append(tree[cluster].points, node)
+			return
+		}
+
+		h := hierarchy[node-n]
+		lambda := 1.0 / math.Max(h.distance, 1e-9)
+		c := tree[cluster]
+		leftSize, rightSize := sizeOf(h.left), sizeOf(h.right)
+
+		switch {
+		case leftSize >= minClusterSize && rightSize >= minClusterSize:
+			// True split: the cluster ends and two children are born
+			c.stability += (lambda - c.birth) * float64(h.size)
+			for _, child := range []int{h.left, h.right} {
+				id := len(tree)
+				tree = append(tree, &condensedCluster{parent: cluster, birth: lambda})
+				c.children = append(c.children, id)
+				walk(child, id)
+			}
+
+		case leftSize >= minClusterSize:
+			c.stability += (lambda - c.birth) * float64(rightSize)
+			c.points = leaves(h.right, c.points)
+			walk(h.left, cluster)
+
+		case rightSize >= minClusterSize:
+			c.stability += (lambda - c.birth) * float64(leftSize)
+			c.points = leaves(h.left, c.points)
+			walk(h.right, cluster)
+
+		default:
+			// Everything remaining falls out at once
+			c.stability += (lambda - c.birth) * float64(h.size)
+			c.points = leaves(node, c.points)
+		}
+	}
+
+	walk(2*n-2, 0)
+	return tree
+}
+
+// selectClusters picks the most stable set of non-overlapping clusters ("excess
+// of mass"). The root is only selected when it never split, so a single dense
+// group is still reported as one cluster.
+func selectClusters(tree []*condensedCluster, minClusterSize int) []int {
+	if len(tree) == 1 {
+		if len(tree[0].points) >= minClusterSize {
+			return []int{0}
+		}
+		return nil
+	}
+
+	score := make([]float64, len(tree))
+	selected := make([]bool, len(tree))
+
+	// Children always have higher IDs than their parent
+	for id := len(tree) - 1; id > 0; id-- {
+		c := tree[id]
+		if len(c.children) == 0 {
+			selected[id] = true
+			score[id] = c.stability
+			continue
+		}
+
+		var childScore float64
+		for _, child := range c.children {
+			childScore += score[child]
+		}
+
+		if c.stability >= childScore {
+			selected[id] = true
+			score[id] = c.stability
+			deselectDescendants(tree, id, selected)
+		} else {
+			score[id] = childScore
+		}
+	}
+
+	var result []int
+	for id := 1; id < len(tree); id++ {
+		if selected[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func deselectDescendants(tree []*condensedCluster, id int, selected []bool) {
+	for _, child := range tree[id].children {
+		selected[child] = false
+		deselectDescendants(tree, child, selected)
+	}
+}
+
+// clusterPoints returns all points in a condensed cluster and its descendants
+func clusterPoints(tree []*condensedCluster, id int) []int {
+	points := append([]int(nil), tree[id].points...)
+	for _, child := range tree[id].children {
+		points = append(points, clusterPoints(tree, child)...)
+	}
+	return points
+}
@@ -0,0 +1,237 @@
+package clustering
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// lineMatrix returns the distance matrix of points on a line
+func lineMatrix(positions []float64) [][]float64 {
+	dist := make([][]float64, len(positions))
+	for i := range positions {
+		dist[i] = make([]float64, len(positions))
+		for j := range positions {
+			dist[i][j] = math.Abs(positions[i] - positions[j])
+		}
+	}
+	return dist
+}
+
+// lineDistances returns anchor IDs and the pairwise distance map of points on a line
+func lineDistances(positions []float64) ([]uuid.UUID, map[string]float64) {
+	ids := make([]uuid.UUID, len(positions))
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	distances := make(map[string]float64)
+	for i := range positions {
+		for j := i + 1; j < len(positions); j++ {
+			distances[distanceKey(ids[i], ids[j])] = math.Abs(positions[i] - positions[j])
+		}
+	}
+	return ids, distances
+}
+
+func testEngine(config DBSCANConfig) *ClusteringEngine {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewClusteringEngine(config, nil, logger)
+}
+
+func TestCoreDistances(t *testing.T) {
+	dist := lineMatrix([]float64{0, 0.1, 0.3, 0.6})
+
+	tests := []struct {
+		k    int
+		want []float64
+	}{
+		{1, []float64{0.1, 0.1, 0.2, 0.3}},
+		{2, []float64{0.3, 0.2, 0.3, 0.5}},
+		{5, []float64{0.6, 0.5, 0.3, 0.6}}, // k beyond n-1 uses the farthest point
+	}
+
+	for _, tt := range tests {
+		core := coreDistances(dist, tt.k)
+		for i := range tt.want {
+			if math.Abs(core[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("k=%d: expected core distances %v, got %v", tt.k, tt.want, core)
+				break
+			}
+		}
+	}
+}
+
+func TestMutualReachabilityMST(t *testing.T) {
+	dist := lineMatrix([]float64{0, 0.1, 0.3, 0.6})
+	core := []float64{0.1, 0.1, 0.2, 0.3}
+
+	edges := mutualReachabilityMST(dist, core)
+
+	if len(edges) != 3 {
+		t.Fatalf("Expected 3 edges, got %d", len(edges))
+	}
+
+	// On a line the MST links neighbours; each edge weighs the largest of
+	// the two core distances and the distance itself
+	got := make([]float64, len(edges))
+	for i, edge := range edges {
+		got[i] = edge.distance
+	}
+	sort.Float64s(got)
+	want := []float64{0.1, 0.2, 0.3}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("Expected edge weights %v, got %v", want, got)
+		}
+	}
+
+	seen := map[int]bool{}
+	for _, edge := range edges {
+		seen[edge.a], seen[edge.b] = true, true
+	}
+	if len(seen) != 4 {
+		t.Errorf("Expected the tree to span all 4 points, got %v", seen)
+	}
+}
+
+func TestSingleLinkage(t *testing.T) {
+	edges := []mstEdge{
+		{a: 2, b: 3, distance: 0.5},
+		{a: 0, b: 1, distance: 0.1},
+		{a: 1, b: 2, distance: 0.2},
+	}
+
+	hierarchy := singleLinkage(edges, 4)
+
+	if len(hierarchy) != 3 {
+		t.Fatalf("Expected 3 merges, got %d", len(hierarchy))
+	}
+	for i := 1; i < len(hierarchy); i++ {
+		if hierarchy[i].distance < hierarchy[i-1].distance {
+			t.Errorf("Expected merges in increasing distance, got %+v", hierarchy)
+		}
+	}
+
+	wantSizes := []int{2, 3, 4}
+	for i, node := range hierarchy {
+		if node.size != wantSizes[i] {
+			t.Errorf("Merge %d: expected size %d, got %d", i, wantSizes[i], node.size)
+		}
+	}
+
+	// The second merge joins point 2 with the first merge (node 4)
+	if second := hierarchy[1]; !(second.left == 4 && second.right == 2 || second.left == 2 && second.right == 4) {
+		t.Errorf("Expected merge of node 4 and point 2, got %+v", second)
+	}
+}
+
+func TestCondenseTreeAndSelectClusters(t *testing.T) {
+	// Two tight groups of four, far apart
+	positions := []float64{0, 0.01, 0.02, 0.03, 0.5, 0.51, 0.52, 0.53}
+	dist := lineMatrix(positions)
+	n := len(positions)
+
+	hierarchy := singleLinkage(mutualReachabilityMST(dist, coreDistances(dist, 2)), n)
+	tree := condenseTree(hierarchy, n, 3)
+
+	// Root plus the two groups
+	if len(tree) != 3 {
+		t.Fatalf("Expected 3 condensed clusters, got %d", len(tree))
+	}
+	if len(tree[0].children) != 2 {
+		t.Fatalf("Expected the root to split in two, got children %v", tree[0].children)
+	}
+	for _, child := range tree[0].children {
+		if tree[child].stability <= 0 {
+			t.Errorf("Expected positive stability for cluster %d, got %f", child, tree[child].stability)
+		}
+	}
+
+	selected := selectClusters(tree, 3)
+	if len(selected) != 2 {
+		t.Fatalf("Expected 2 selected clusters, got %v", selected)
+	}
+
+	groups := map[int]bool{}
+	for _, id := range selected {
+		points := clusterPoints(tree, id)
+		if len(points) != 4 {
+			t.Errorf("Expected 4 points in cluster %d, got %v", id, points)
+		}
+		groups[points[0]/4] = true
+		for _, p := range points {
+			if p/4 != points[0]/4 {
+				t.Errorf("Expected cluster %d to hold one group, got %v", id, points)
+			}
+		}
+	}
+	if len(groups) != 2 {
+		t.Errorf("Expected one cluster per group, got %v", groups)
+	}
+}
+
+func TestSelectClusters_SingleGroupIsRoot(t *testing.T) {
+	tree := []*condensedCluster{{parent: -1, points: []int{0, 1, 2, 3}}}
+
+	if selected := selectClusters(tree, 3); len(selected) != 1 || selected[0] != 0 {
+		t.Errorf("Expected the unsplit root to be selected, got %v", selected)
+	}
+	if selected := selectClusters(tree, 5); len(selected) != 0 {
+		t.Errorf("Expected no cluster below the minimum size, got %v", selected)
+	}
+}
+
+func TestHDBSCAN(t *testing.T) {
+	// A tight group, a looser group and one outlier
+	positions := []float64{0, 0.01, 0.02, 0.03, 0.04, 0.4, 0.45, 0.5, 0.55, 0.6, 1.5}
+	ids, distances := lineDistances(positions)
+	engine := testEngine(DBSCANConfig{Algorithm: AlgorithmHDBSCAN, MinPoints: 3})
+
+	clusters := engine.hdbscan(ids, distances)
+
+	label := make(map[uuid.UUID]int)
+	var noise []uuid.UUID
+	for _, cluster := range clusters {
+		for _, member := range cluster.Members {
+			label[member] = cluster.ID
+		}
+		if cluster.Noise {
+			noise = cluster.Members
+		}
+	}
+
+	if len(label) != len(ids) {
+		t.Fatalf("Expected every anchor labelled, got %d of %d", len(label), len(ids))
+	}
+	for _, group := range [][]int{{0, 1, 2, 3, 4}, {5, 6, 7, 8, 9}} {
+		first := label[ids[group[0]]]
+		if first == -1 {
+			t.Errorf("Expected group %v to form a cluster", group)
+			continue
+		}
+		for _, i := range group[1:] {
+			if label[ids[i]] != first {
+				t.Errorf("Expected anchor %d in cluster %d, got %d", i, first, label[ids[i]])
+			}
+		}
+	}
+	if label[ids[0]] == label[ids[5]] {
+		t.Error("Expected the two groups in different clusters")
+	}
+	if len(noise) != 1 || noise[0] != ids[10] {
+		t.Errorf("Expected the outlier as the only noise point, got %v", noise)
+	}
+}
+
+func TestUsesEpsilon(t *testing.T) {
+	if !testEngine(DBSCANConfig{Algorithm: AlgorithmDBSCAN}).UsesEpsilon() {
+		t.Error("Expected DBSCAN to use epsilon")
+	}
+	if testEngine(DBSCANConfig{Algorithm: AlgorithmHDBSCAN}).UsesEpsilon() {
+		t.Error("Expected HDBSCAN not to use epsilon")
+	}
+}
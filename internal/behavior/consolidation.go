@@ -2,13 +2,15 @@ package behavior
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 )
 
@@ -241,32 +243,25 @@ func sortEpisodesByStartTime(episodes []*MicroEpisode) {
 
 // publishConsolidationResult publishes consolidation metrics
 func (a *Agent) publishConsolidationResult(macroCreated, microProcessed int) {
-	topic := "automation/behavior/consolidation/completed"
-
-	payload := map[string]interface{}{
-		"timestamp":                a.timeManager.Now().Format(time.RFC3339),
-		"macro_episodes_created":   macroCreated,
-		"micro_episodes_processed": microProcessed,
+	result := events.ConsolidationResult{
+		MacroEpisodesCreated:   macroCreated,
+		MicroEpisodesProcessed: microProcessed,
+		Timestamp:              a.timeManager.Now().Format(time.RFC3339),
 	}
 
-	payloadBytes, _ := json.Marshal(payload)
-	a.mqtt.Publish(topic, 0, false, payloadBytes)
+	payloadBytes, _ := events.Marshal(events.ProducerBehavior, result)
+	a.mqtt.Publish(result.Topic(), 0, false, payloadBytes)
 }
 
 // handleConsolidationTrigger handles manual consolidation requests
 func (a *Agent) handleConsolidationTrigger(msg mqtt.Message) {
-	var trigger struct {
-		Action        string `json:"action"`
-		LookbackHours int    `json:"lookback_hours"`
-		Location      string `json:"location"`
-	}
-
-	if err := json.Unmarshal(msg.Payload(), &trigger); err != nil {
+	trigger, _, err := events.Parse[events.ConsolidationTrigger](msg.Payload())
+	if err != nil {
 		a.logger.Error("Failed to parse consolidation trigger", "error", err)
 		return
 	}
 
-	if trigger.Action != "consolidate" {
+	if trigger.Action != events.ConsolidateAction {
 		a.logger.Warn("Unknown consolidation action", "action", trigger.Action)
 		return
 	}
@@ -279,26 +274,28 @@ func (a *Agent) handleConsolidationTrigger(msg mqtt.Message) {
 	now := a.timeManager.Now()
 	sinceTime := now.Add(-time.Duration(lookbackHours) * time.Hour)
 
-	a.logger.Info("Manual consolidation triggered",
+	ctx := logging.Correlate(context.Background(), trigger.CorrelationID)
+	a.logger.InfoContext(ctx, "Manual consolidation triggered",
 		"lookback_hours", lookbackHours,
 		"location", trigger.Location,
 		"virtual_time", now)
 
-	ctx := context.Background()
-	if err := a.performConsolidation(ctx, sinceTime, trigger.Location); err != nil {
-		a.logger.Error("Manual consolidation failed", "error", err)
+	err = a.jobGate.Run(ctx, events.JobConsolidation, trigger.CorrelationID, func(ctx context.Context) error {
+		return a.performConsolidation(ctx, sinceTime, trigger.Location)
+	})
+	if err != nil {
+		a.logger.ErrorContext(ctx, "Manual consolidation failed", "error", err)
 	}
 }
 
-// runConsolidationJob runs periodic consolidation in the background
+// runConsolidationJob runs consolidation on JEEVES_CONSOLIDATION_SCHEDULE in
+// the background
 func (a *Agent) runConsolidationJob(ctx context.Context) {
-	interval := time.Duration(a.cfg.ConsolidationIntervalHours) * time.Hour
-
 	a.logger.Info("Starting consolidation job",
-		"interval", interval,
+		"schedule", a.cfg.EffectiveConsolidationSchedule(),
 		"lookback", a.cfg.ConsolidationLookbackHours)
 
-	ticker := time.NewTicker(interval)
+	ticker := clock.NewScheduler(a.timeManager, a.consolidationSchedule, a.cfg.ScheduleJitter)
 	defer ticker.Stop()
 
 	for {
@@ -307,10 +304,14 @@ func (a *Agent) runConsolidationJob(ctx context.Context) {
 			now := a.timeManager.Now()
 			sinceTime := now.Add(-time.Duration(a.cfg.ConsolidationLookbackHours) * time.Hour)
 
-			a.logger.Info("Running periodic consolidation", "virtual_time", now)
+			runCtx := logging.Correlate(ctx, "")
+			a.logger.InfoContext(runCtx, "Running periodic consolidation", "virtual_time", now)
 
-			if err := a.performConsolidation(ctx, sinceTime, ""); err != nil {
-				a.logger.Error("Periodic consolidation failed", "error", err)
+			err := a.jobGate.Run(runCtx, events.JobConsolidation, logging.CorrelationID(runCtx), func(ctx context.Context) error {
+				return a.performConsolidation(ctx, sinceTime, "")
+			})
+			if err != nil {
+				a.logger.ErrorContext(runCtx, "Periodic consolidation failed", "error", err)
 			}
 
 		case <-ctx.Done():
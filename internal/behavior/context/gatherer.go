@@ -8,12 +8,18 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/saaga0h/jeeves-platform/pkg/solar"
 )
 
 // ContextGatherer collects semantic context dimensions for anchor creation.
 type ContextGatherer struct {
 	redis  *redis.Client
 	logger *slog.Logger
+
+	// Home coordinates for solar context
+	lat, lon    float64
+	hasLocation bool
 }
 
 // NewContextGatherer creates a new context gatherer instance.
@@ -27,6 +33,12 @@ func NewContextGatherer(
 	}
 }
 
+// SetLocation enables solar context (circadian phase, sun elevation) for
+// the given home coordinates.
+func (g *ContextGatherer) SetLocation(lat, lon float64) {
+	g.lat, g.lon, g.hasLocation = lat, lon, true
+}
+
 // GatherContext collects all semantic context dimensions for an anchor.
 // Returns a context map with time, weather, lighting, and household mode.
 func (g *ContextGatherer) GatherContext(
@@ -37,11 +49,18 @@ func (g *ContextGatherer) GatherContext(
 	contextMap := make(map[string]interface{})
 
 	// Time-based context (always available)
-	contextMap["time_of_day"] = categorizeTimeOfDay(timestamp)
+	contextMap["time_of_day"] = CategorizeTimeOfDay(timestamp)
 	contextMap["day_type"] = categorizeDayType(timestamp)
 	contextMap["season"] = categorizeSeason(timestamp)
 	contextMap["household_mode"] = categorizeHouseholdMode(timestamp)
 
+	// Solar context (when home coordinates are known)
+	if g.hasLocation {
+		circadian := solar.CircadianAt(timestamp, g.lat, g.lon)
+		contextMap["circadian_phase"] = string(circadian.Phase)
+		contextMap["sun_elevation"] = circadian.Elevation
+	}
+
 	// Weather context (best effort - non-blocking)
 	if weather, err := g.getWeatherContext(ctx); err == nil {
 		contextMap["weather"] = weather
@@ -62,8 +81,8 @@ func (g *ContextGatherer) GatherContext(
 	return contextMap, nil
 }
 
-// categorizeTimeOfDay returns the time period category.
-func categorizeTimeOfDay(t time.Time) string {
+// CategorizeTimeOfDay returns the time period category.
+func CategorizeTimeOfDay(t time.Time) string {
 	hour := t.Hour()
 	switch {
 	case hour >= 5 && hour < 12:
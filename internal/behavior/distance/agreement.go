@@ -0,0 +1,336 @@
+package distance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+// AgreementConfig controls the job comparing vector and learned distances to
+// LLM labels. A zero Interval disables it.
+type AgreementConfig struct {
+	Interval   time.Duration // how often a report is computed
+	SampleSize int           // LLM-labeled pairs sampled per report (default 500)
+	Lookback   time.Duration // pairs labeled within this are sampled (default 30 days)
+}
+
+// AgreementSample is an LLM-labeled pair with the distances the other sources
+// give it
+type AgreementSample struct {
+	Location1, Location2 string
+	LLM                  float64
+	Vector               float64  // structured distance with the current block weights
+	Learned              *float64 // learned pattern distance, nil without a pattern
+}
+
+// AgreementStats compares one distance source to the LLM labels
+type AgreementStats struct {
+	Samples     int     `json:"samples"`
+	Correlation float64 `json:"correlation"` // Pearson; 0 when either side is constant
+	Bias        float64 `json:"bias"`        // mean(source - llm); positive = source rates pairs as further apart
+	MAE         float64 `json:"mae"`
+}
+
+// LocationAgreement is the agreement for one location pair; Locations is
+// "*" for all pairs
+type LocationAgreement struct {
+	Locations string         `json:"locations"` // "bedroom|kitchen", sorted
+	Vector    AgreementStats `json:"vector"`
+	Learned   AgreementStats `json:"learned"`
+}
+
+// AgreementReport is a stored cross-validation run
+type AgreementReport struct {
+	Samples   int                 `json:"samples"`
+	Overall   LocationAgreement   `json:"overall"`
+	Locations []LocationAgreement `json:"locations"` // most samples first
+	Weights   BlockWeights        `json:"weights"`   // block weights the vector distances used
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+// runAgreementWorker computes an agreement report every interval
+func (a *ComputationAgent) runAgreementWorker(ctx context.Context) {
+	cfg := a.config.Agreement
+
+	a.logger.Info("Starting distance agreement worker",
+		"interval", cfg.Interval,
+		"sample_size", cfg.SampleSize)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			report, err := a.ComputeAgreementReport(ctx)
+			if err != nil {
+				a.logger.Error("Distance agreement report failed", "error", err)
+				continue
+			}
+			if report.Samples == 0 {
+				a.logger.Debug("No LLM-labeled pairs for distance agreement report")
+				continue
+			}
+			a.logger.Info("Distance agreement report",
+				"samples", report.Samples,
+				"vector_correlation", report.Overall.Vector.Correlation,
+				"vector_bias", report.Overall.Vector.Bias,
+				"learned_correlation", report.Overall.Learned.Correlation,
+				"learned_bias", report.Overall.Learned.Bias)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ComputeAgreementReport samples LLM-labeled pairs, compares the vector and
+// learned distances to the labels and stores the report when it has samples
+func (a *ComputationAgent) ComputeAgreementReport(ctx context.Context) (*AgreementReport, error) {
+	if a.learnedPatternStorage == nil {
+		return nil, fmt.Errorf("learned pattern storage not configured")
+	}
+
+	sampleSize := a.config.Agreement.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = 500
+	}
+	lookback := a.config.Agreement.Lookback
+	if lookback <= 0 {
+		lookback = 30 * 24 * time.Hour
+	}
+
+	pairs, err := a.learnedPatternStorage.SampleLabeledPairs(ctx, a.timeManager.Now().Add(-lookback), sampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(pairs))
+	for i, pair := range pairs {
+		keys[i] = generatePatternKey(pair.Anchor1, pair.Anchor2)
+	}
+	learned, err := a.learnedPatternStorage.LoadLearnedDistances(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	a.cacheMutex.RLock()
+	weights := a.blockWeights
+	a.cacheMutex.RUnlock()
+	if weights == (BlockWeights{}) {
+		weights = DefaultBlockWeights
+	}
+
+	samples := make([]AgreementSample, len(pairs))
+	for i, pair := range pairs {
+		samples[i] = AgreementSample{
+			Location1: pair.Anchor1.Location,
+			Location2: pair.Anchor2.Location,
+			LLM:       pair.Distance,
+			Vector:    weights.distance(blockDistances(pair.Anchor1.SemanticEmbedding, pair.Anchor2.SemanticEmbedding)),
+		}
+		if d, ok := learned[keys[i]]; ok {
+			samples[i].Learned = &d
+		}
+	}
+
+	report := computeAgreement(samples)
+	report.Weights = weights
+	report.CreatedAt = a.timeManager.Now()
+	if report.Samples == 0 {
+		return report, nil
+	}
+
+	if err := a.learnedPatternStorage.SaveAgreementReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// computeAgreement groups samples by location pair and compares each source
+// to the LLM labels
+func computeAgreement(samples []AgreementSample) *AgreementReport {
+	groups := make(map[string][]AgreementSample)
+	for _, s := range samples {
+		key := locationPairKey(s.Location1, s.Location2)
+		groups[key] = append(groups[key], s)
+	}
+
+	report := &AgreementReport{
+		Samples:   len(samples),
+		Overall:   locationAgreement("*", samples),
+		Locations: make([]LocationAgreement, 0, len(groups)),
+	}
+	for key, group := range groups {
+		report.Locations = append(report.Locations, locationAgreement(key, group))
+	}
+	sort.Slice(report.Locations, func(i, j int) bool {
+		li, lj := report.Locations[i], report.Locations[j]
+		if li.Vector.Samples != lj.Vector.Samples {
+			return li.Vector.Samples > lj.Vector.Samples
+		}
+		return li.Locations < lj.Locations
+	})
+	return report
+}
+
+func locationAgreement(key string, samples []AgreementSample) LocationAgreement {
+	var llm, vector, learnedLLM, learned []float64
+	for _, s := range samples {
+		llm = append(llm, s.LLM)
+		vector = append(vector, s.Vector)
+		if s.Learned != nil {
+			learnedLLM = append(learnedLLM, s.LLM)
+			learned = append(learned, *s.Learned)
+		}
+	}
+	return LocationAgreement{
+		Locations: key,
+		Vector:    agreementStats(vector, llm),
+		Learned:   agreementStats(learned, learnedLLM),
+	}
+}
+
+// agreementStats compares source to reference, element by element
+func agreementStats(source, reference []float64) AgreementStats {
+	n := len(source)
+	if n == 0 {
+		return AgreementStats{}
+	}
+
+	var meanS, meanR, absErr float64
+	for i := range source {
+		meanS += source[i]
+		meanR += reference[i]
+		absErr += math.Abs(source[i] - reference[i])
+	}
+	meanS /= float64(n)
+	meanR /= float64(n)
+
+	var cov, varS, varR float64
+	for i := range source {
+		ds, dr := source[i]-meanS, reference[i]-meanR
+		cov += ds * dr
+		varS += ds * ds
+		varR += dr * dr
+	}
+
+	stats := AgreementStats{
+		Samples: n,
+		Bias:    meanS - meanR,
+		MAE:     absErr / float64(n),
+	}
+	if varS > 0 && varR > 0 {
+		stats.Correlation = cov / math.Sqrt(varS*varR)
+	}
+	return stats
+}
+
+// locationPairKey is the order-independent key of two locations
+func locationPairKey(loc1, loc2 string) string {
+	if loc1 > loc2 {
+		loc1, loc2 = loc2, loc1
+	}
+	return loc1 + "|" + loc2
+}
+
+// LabeledPair is an anchor pair with its LLM distance
+type LabeledPair struct {
+	Anchor1, Anchor2 *types.SemanticAnchor // location, context and embedding only
+	Distance         float64
+}
+
+// SampleLabeledPairs returns up to limit random LLM-labeled pairs computed since
+func (s *LearnedPatternStorage) SampleLabeledPairs(ctx context.Context, since time.Time, limit int) ([]LabeledPair, error) {
+	query := `
+		SELECT location1, location2, context1, context2, embedding1, embedding2, distance
+		FROM recent_llm_distances
+		WHERE computed_at >= $1
+		ORDER BY random()
+		LIMIT $2
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LLM-labeled pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []LabeledPair
+	for rows.Next() {
+		a1, a2 := &types.SemanticAnchor{}, &types.SemanticAnchor{}
+		var context1, context2 []byte
+		var e1, e2 pgvector.Vector
+		var distance float64
+		if err := rows.Scan(&a1.Location, &a2.Location, &context1, &context2, &e1, &e2, &distance); err != nil {
+			return nil, fmt.Errorf("failed to scan LLM-labeled pair: %w", err)
+		}
+		if err := json.Unmarshal(context1, &a1.Context); err != nil {
+			return nil, fmt.Errorf("failed to parse anchor context: %w", err)
+		}
+		if err := json.Unmarshal(context2, &a2.Context); err != nil {
+			return nil, fmt.Errorf("failed to parse anchor context: %w", err)
+		}
+		a1.SemanticEmbedding, a2.SemanticEmbedding = e1, e2
+		pairs = append(pairs, LabeledPair{Anchor1: a1, Anchor2: a2, Distance: distance})
+	}
+
+	return pairs, rows.Err()
+}
+
+// LoadLearnedDistances returns the weighted distance of each learned pattern
+// in keys
+func (s *LearnedPatternStorage) LoadLearnedDistances(ctx context.Context, keys []string) (map[string]float64, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT pattern_key, weighted_distance FROM learned_patterns WHERE pattern_key = ANY($1)`,
+		pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query learned distances: %w", err)
+	}
+	defer rows.Close()
+
+	distances := make(map[string]float64)
+	for rows.Next() {
+		var key string
+		var distance float64
+		if err := rows.Scan(&key, &distance); err != nil {
+			return nil, fmt.Errorf("failed to scan learned distance: %w", err)
+		}
+		distances[key] = distance
+	}
+
+	return distances, rows.Err()
+}
+
+// SaveAgreementReport stores a cross-validation report
+func (s *LearnedPatternStorage) SaveAgreementReport(ctx context.Context, report *AgreementReport) error {
+	overall, err := json.Marshal(report.Overall)
+	if err != nil {
+		return fmt.Errorf("failed to marshal agreement: %w", err)
+	}
+	locations, err := json.Marshal(report.Locations)
+	if err != nil {
+		return fmt.Errorf("failed to marshal agreement: %w", err)
+	}
+	weights, err := json.Marshal(report.Weights)
+	if err != nil {
+		return fmt.Errorf("failed to marshal weights: %w", err)
+	}
+
+	query := `
+		INSERT INTO distance_agreement_reports (samples, overall, locations, weights, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, report.Samples, overall, locations, weights, report.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save agreement report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package distance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeAgreement(t *testing.T) {
+	learned := func(d float64) *float64 { return &d }
+	samples := []AgreementSample{
+		// Vector tracks the LLM, 0.1 further apart
+		{Location1: "kitchen", Location2: "dining_room", LLM: 0.2, Vector: 0.3, Learned: learned(0.2)},
+		{Location1: "dining_room", Location2: "kitchen", LLM: 0.4, Vector: 0.5},
+		{Location1: "kitchen", Location2: "dining_room", LLM: 0.6, Vector: 0.7, Learned: learned(0.6)},
+		// Vector runs against the LLM
+		{Location1: "bedroom", Location2: "bedroom", LLM: 0.1, Vector: 0.9},
+		{Location1: "bedroom", Location2: "bedroom", LLM: 0.9, Vector: 0.1},
+	}
+
+	report := computeAgreement(samples)
+	if report.Samples != 5 || report.Overall.Locations != "*" || report.Overall.Vector.Samples != 5 {
+		t.Fatalf("unexpected overall: %+v", report.Overall)
+	}
+	if len(report.Locations) != 2 {
+		t.Fatalf("got %d location pairs, want 2", len(report.Locations))
+	}
+
+	kitchen := report.Locations[0]
+	if kitchen.Locations != "dining_room|kitchen" {
+		t.Fatalf("most sampled pair is %q, want dining_room|kitchen", kitchen.Locations)
+	}
+	if math.Abs(kitchen.Vector.Correlation-1) > 1e-9 || math.Abs(kitchen.Vector.Bias-0.1) > 1e-9 {
+		t.Errorf("kitchen vector: got %+v, want correlation 1, bias 0.1", kitchen.Vector)
+	}
+	if kitchen.Learned.Samples != 2 || kitchen.Learned.MAE != 0 {
+		t.Errorf("kitchen learned: got %+v, want 2 exact samples", kitchen.Learned)
+	}
+
+	bedroom := report.Locations[1]
+	if math.Abs(bedroom.Vector.Correlation+1) > 1e-9 || bedroom.Learned.Samples != 0 {
+		t.Errorf("bedroom: got %+v, want vector correlation -1 and no learned samples", bedroom)
+	}
+
+	// Constant sources have no correlation
+	if stats := agreementStats([]float64{0.5, 0.5}, []float64{0.1, 0.9}); stats.Correlation != 0 || stats.MAE != 0.4 {
+		t.Errorf("constant source: got %+v", stats)
+	}
+}
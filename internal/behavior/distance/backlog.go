@@ -0,0 +1,181 @@
+package distance
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+)
+
+const (
+	// backlogTopic carries the un-computed pair backlog after each scheduled
+	// run (retained)
+	backlogTopic = "automation/behavior/distances/backlog"
+
+	// runHeadroom is the share of the interval a tuned batch may take at the
+	// measured throughput, so runs do not overlap the next tick
+	runHeadroom = 0.8
+)
+
+// AutoTuneConfig bounds how the batch size and interval follow the backlog.
+// The configured BatchSize and Interval are the floor and ceiling; the zero
+// value (no TargetDrain) keeps them fixed.
+type AutoTuneConfig struct {
+	MaxBatchSize int           // largest batch size
+	MinInterval  time.Duration // shortest interval
+	TargetDrain  time.Duration // the backlog should drain within this
+}
+
+// Backlog is the payload of automation/behavior/distances/backlog
+type Backlog struct {
+	Pairs           int       `json:"pairs"`            // candidate pairs without a distance
+	Throughput      float64   `json:"throughput"`       // pairs per second, 0 before the first run
+	DrainSeconds    float64   `json:"drain_seconds"`    // estimated time to compute every pair
+	BatchSize       int       `json:"batch_size"`       // for the next runs
+	IntervalSeconds float64   `json:"interval_seconds"` // between the next runs
+	BehindTarget    bool      `json:"behind_target"`    // the drain estimate exceeds the target
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// batchSize returns the batch size of the next run
+func (a *ComputationAgent) batchSize() int {
+	a.tuneMutex.Lock()
+	defer a.tuneMutex.Unlock()
+	if a.tunedBatch > 0 {
+		return a.tunedBatch
+	}
+	return a.config.BatchSize
+}
+
+// recordThroughput folds a run of pairs taking elapsed into the throughput
+// estimate
+func (a *ComputationAgent) recordThroughput(pairs int, elapsed time.Duration) {
+	if pairs == 0 || elapsed <= 0 {
+		return
+	}
+	rate := float64(pairs) / elapsed.Seconds()
+
+	a.tuneMutex.Lock()
+	defer a.tuneMutex.Unlock()
+	if a.throughput == 0 {
+		a.throughput = rate
+	} else {
+		a.throughput = 0.5*a.throughput + 0.5*rate
+	}
+}
+
+// checkBacklog counts the backlog, re-tunes the batch size and returns the
+// interval until the next scheduled run
+func (a *ComputationAgent) checkBacklog(ctx context.Context) time.Duration {
+	interval := a.config.Interval
+	pairs, err := a.storage.CountAnchorsNeedingDistances(ctx)
+	if err != nil {
+		a.logger.WarnContext(ctx, "Failed to count distance backlog", "error", err)
+		return a.currentInterval()
+	}
+
+	a.tuneMutex.Lock()
+	throughput := a.throughput
+	a.tuneMutex.Unlock()
+
+	batch := a.config.BatchSize
+	var drain time.Duration
+	if a.config.AutoTune.TargetDrain > 0 {
+		batch, interval, drain = tune(a.config.AutoTune, a.config.BatchSize, a.config.Interval, pairs, throughput)
+	} else {
+		drain = drainTime(pairs, batch, interval, throughput)
+	}
+
+	a.tuneMutex.Lock()
+	a.tunedBatch = batch
+	a.tunedInterval = interval
+	a.tuneMutex.Unlock()
+
+	backlog := Backlog{
+		Pairs:           pairs,
+		Throughput:      throughput,
+		DrainSeconds:    drain.Seconds(),
+		BatchSize:       batch,
+		IntervalSeconds: interval.Seconds(),
+		BehindTarget:    a.config.AutoTune.TargetDrain > 0 && drain > a.config.AutoTune.TargetDrain,
+		Timestamp:       time.Now().UTC(),
+	}
+	if backlog.BehindTarget {
+		a.logger.WarnContext(ctx, "Distance backlog will not drain within target",
+			"pairs", pairs,
+			"drain", drain.Round(time.Minute),
+			"target", a.config.AutoTune.TargetDrain,
+			"batch_size", batch,
+			"interval", interval)
+	} else {
+		a.logger.InfoContext(ctx, "Distance backlog",
+			"pairs", pairs,
+			"drain", drain.Round(time.Minute),
+			"batch_size", batch,
+			"interval", interval)
+	}
+
+	payload, _ := events.Marshal(events.ProducerBehavior, backlog)
+	if err := a.mqtt.Publish(backlogTopic, 0, true, payload); err != nil {
+		a.logger.WarnContext(ctx, "Failed to publish distance backlog", "error", err)
+	}
+	return interval
+}
+
+// currentInterval returns the tuned interval, the configured one before tuning
+func (a *ComputationAgent) currentInterval() time.Duration {
+	a.tuneMutex.Lock()
+	defer a.tuneMutex.Unlock()
+	if a.tunedInterval > 0 {
+		return a.tunedInterval
+	}
+	return a.config.Interval
+}
+
+// tune returns the batch size and interval that drain backlog pairs within
+// cfg.TargetDrain, staying within [baseBatch, cfg.MaxBatchSize] and
+// [cfg.MinInterval, baseInterval], and the drain time they give. The batch is
+// grown first; the interval is shortened only once the batch is at its bound.
+// A known throughput (pairs per second) caps both so a run fits its interval.
+func tune(cfg AutoTuneConfig, baseBatch int, baseInterval time.Duration, backlog int, throughput float64) (int, time.Duration, time.Duration) {
+	batch, interval := baseBatch, baseInterval
+	if backlog <= 0 || cfg.TargetDrain <= 0 || baseInterval <= 0 {
+		return batch, interval, drainTime(backlog, batch, interval, throughput)
+	}
+
+	maxBatch := max(cfg.MaxBatchSize, baseBatch)
+	if throughput > 0 {
+		capacity := int(throughput * interval.Seconds() * runHeadroom)
+		maxBatch = max(min(maxBatch, capacity), baseBatch)
+	}
+
+	runs := cfg.TargetDrain.Seconds() / interval.Seconds()
+	batch = min(max(int(math.Ceil(float64(backlog)/runs)), baseBatch), maxBatch)
+
+	if batch == maxBatch && float64(batch)*runs < float64(backlog) {
+		minInterval := min(max(cfg.MinInterval, 0), baseInterval)
+		if throughput > 0 {
+			// A run of batch pairs must still fit
+			runTime := time.Duration(float64(batch) / throughput / runHeadroom * float64(time.Second))
+			minInterval = min(max(minInterval, runTime), baseInterval)
+		}
+		needed := time.Duration(float64(cfg.TargetDrain) * float64(batch) / float64(backlog))
+		interval = min(max(needed, minInterval), baseInterval)
+	}
+
+	return batch, interval, drainTime(backlog, batch, interval, throughput)
+}
+
+// drainTime estimates how long backlog pairs take at batch pairs per
+// interval, or at throughput when that is slower
+func drainTime(backlog, batch int, interval time.Duration, throughput float64) time.Duration {
+	if backlog <= 0 || batch <= 0 {
+		return 0
+	}
+	drain := time.Duration(math.Ceil(float64(backlog)/float64(batch))) * interval
+	if throughput > 0 {
+		drain = max(drain, time.Duration(float64(backlog)/throughput*float64(time.Second)))
+	}
+	return drain
+}
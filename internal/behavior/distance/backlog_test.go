@@ -0,0 +1,43 @@
+package distance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTune(t *testing.T) {
+	cfg := AutoTuneConfig{MaxBatchSize: 1000, MinInterval: 30 * time.Minute, TargetDrain: 24 * time.Hour}
+	base := 6 * time.Hour
+
+	tests := []struct {
+		name         string
+		backlog      int
+		throughput   float64
+		wantBatch    int
+		wantInterval time.Duration
+		wantDrain    time.Duration
+	}{
+		{"empty backlog keeps the base", 0, 0, 100, base, 0},
+		{"small backlog keeps the base", 300, 0, 100, base, 18 * time.Hour},
+		{"batch grows first", 2000, 0, 500, base, 24 * time.Hour},
+		{"interval shortens at the largest batch", 8000, 0, 1000, 3 * time.Hour, 24 * time.Hour},
+		{"interval stops at its bound", 100000, 0, 1000, 30 * time.Minute, 50 * time.Hour},
+		// At 0.02 pairs/s, 345 pairs fill 80% of 6h: neither can move much
+		{"throughput caps the batch", 8000, 0.02, 345, 21562500 * time.Millisecond, 144 * time.Hour},
+	}
+	for _, tt := range tests {
+		batch, interval, drain := tune(cfg, 100, base, tt.backlog, tt.throughput)
+		if batch != tt.wantBatch || interval != tt.wantInterval {
+			t.Errorf("%s: got batch %d every %v, want %d every %v", tt.name, batch, interval, tt.wantBatch, tt.wantInterval)
+		}
+		if drain.Round(time.Hour) != tt.wantDrain {
+			t.Errorf("%s: got drain %v, want %v", tt.name, drain, tt.wantDrain)
+		}
+	}
+
+	// Disabled: the configured values are kept
+	batch, interval, _ := tune(AutoTuneConfig{}, 100, base, 100000, 0)
+	if batch != 100 || interval != base {
+		t.Errorf("disabled tuning changed batch %d, interval %v", batch, interval)
+	}
+}
@@ -39,6 +39,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
@@ -46,12 +47,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
 
+	"github.com/saaga0h/jeeves-platform/internal/behavior/prompts"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
 	"github.com/saaga0h/jeeves-platform/pkg/llm"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
 )
 
 // TimeManager interface for getting current time (real or virtual)
@@ -65,14 +73,19 @@ type ComputationConfig struct {
 	Strategy      string        // "llm_first", "progressive_learned"
 	Model         string        // LLM model name (e.g., "mixtral:8x7b")
 	Interval      time.Duration // production: 6h, tests: triggered
+	Jitter        time.Duration // random delay added to each scheduled run
 	BatchSize     int           // default: 100
 	LookbackHours int           // how far back to compute distances
+	Verification  VerificationConfig
+	Agreement     AgreementConfig
+	Prompts       *llm.Prompts // nil uses the built-in prompts
+	AutoTune      AutoTuneConfig
 }
 
 // ComputationAgent computes semantic distances between anchor pairs
 type ComputationAgent struct {
 	config      ComputationConfig
-	storage     *storage.AnchorStorage
+	storage     storage.AnchorRepository
 	llm         llm.Client
 	mqtt        mqtt.Client
 	logger      *slog.Logger
@@ -82,6 +95,16 @@ type ComputationAgent struct {
 	testMode     bool
 	testTriggers chan TriggerEvent
 
+	// Running computation, for progress and cancellation
+	run      *distanceRun
+	runMutex sync.Mutex
+
+	// Backlog auto-tuning; zero until the first check
+	tunedBatch    int
+	tunedInterval time.Duration
+	throughput    float64 // pairs per second
+	tuneMutex     sync.Mutex
+
 	// Learned patterns with temporal decay (NEW!)
 	learnedPatternStorage *LearnedPatternStorage
 	learnedPatternConfig  LearnedPatternConfig
@@ -91,17 +114,24 @@ type ComputationAgent struct {
 
 	// Progressive learned tracking
 	totalComputations   int // Track how many computations we've done
+
+	// structuredDist block weights (fitted to LLM labels when available)
+	blockWeights BlockWeights
+
+	// Home layout for adjacency decisions and LLM prompts
+	topology *ontology.Topology
 }
 
 // TriggerEvent represents a manual trigger for distance computation
 type TriggerEvent struct {
 	LookbackHours int
+	CorrelationID string // from the trigger payload; empty starts a new one
 }
 
 // NewComputationAgent creates a new distance computation agent
 func NewComputationAgent(
 	config ComputationConfig,
-	storage *storage.AnchorStorage,
+	storage storage.AnchorRepository,
 	llmClient llm.Client,
 	mqttClient mqtt.Client,
 	logger *slog.Logger,
@@ -118,6 +148,7 @@ func NewComputationAgent(
 		patternCache:        make(map[string]*LearnedPattern),
 		observationCache:    make(map[string][]Observation),
 		learnedPatternConfig: DefaultLearnedPatternConfig(),
+		blockWeights:        DefaultBlockWeights,
 		// Note: learnedPatternStorage will be set via SetLearnedPatternStorage() after construction
 	}
 }
@@ -127,6 +158,13 @@ func (a *ComputationAgent) SetLearnedPatternStorage(db *sql.DB) {
 	a.learnedPatternStorage = NewLearnedPatternStorage(db, a.logger)
 }
 
+// SetTopology sets the home topology used for adjacency and LLM prompts
+func (a *ComputationAgent) SetTopology(topology *ontology.Topology) {
+	a.cacheMutex.Lock()
+	a.topology = topology
+	a.cacheMutex.Unlock()
+}
+
 // EnableTestMode switches to test mode (trigger-based instead of interval-based)
 func (a *ComputationAgent) EnableTestMode() {
 	a.testMode = true
@@ -138,6 +176,21 @@ func (a *ComputationAgent) Start(ctx context.Context) error {
 	if err := a.mqtt.Subscribe("automation/behavior/compute_distances", 0, a.handleTrigger); err != nil {
 		return fmt.Errorf("failed to subscribe to triggers: %w", err)
 	}
+	if err := a.mqtt.Subscribe(cancelTopic, 0, a.handleCancel); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", cancelTopic, err)
+	}
+
+	if a.learnedPatternStorage != nil {
+		a.loadBlockWeights(ctx)
+	}
+
+	if a.config.Verification.Interval > 0 && a.learnedPatternStorage != nil {
+		go a.runVerificationWorker(ctx)
+	}
+
+	if a.config.Agreement.Interval > 0 && a.learnedPatternStorage != nil {
+		go a.runAgreementWorker(ctx)
+	}
 
 	if a.testMode {
 		// Test mode: wait for explicit triggers only
@@ -145,9 +198,8 @@ func (a *ComputationAgent) Start(ctx context.Context) error {
 		for {
 			select {
 			case trigger := <-a.testTriggers:
-				if err := a.computeDistances(ctx, trigger.LookbackHours); err != nil {
-					a.logger.Error("Distance computation failed", "error", err)
-				}
+				runCtx := logging.Correlate(ctx, trigger.CorrelationID)
+				a.logRunError(runCtx, a.computeDistances(runCtx, trigger.LookbackHours))
 			case <-ctx.Done():
 				return nil
 			}
@@ -158,19 +210,23 @@ func (a *ComputationAgent) Start(ctx context.Context) error {
 	a.logger.Info("Distance computation agent running in production mode",
 		"interval", a.config.Interval)
 
-	ticker := time.NewTicker(a.config.Interval)
+	interval := a.checkBacklog(ctx)
+	ticker := clock.NewScheduler(a.timeManager, clock.Every(interval), a.config.Jitter)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case trigger := <-a.testTriggers:
 			// Also process MQTT triggers in production mode (for test scenarios)
-			if err := a.computeDistances(ctx, trigger.LookbackHours); err != nil {
-				a.logger.Error("Distance computation failed", "error", err)
-			}
+			runCtx := logging.Correlate(ctx, trigger.CorrelationID)
+			a.logRunError(runCtx, a.computeDistances(runCtx, trigger.LookbackHours))
 		case <-ticker.C:
-			if err := a.computeDistances(ctx, a.config.LookbackHours); err != nil {
-				a.logger.Error("Distance computation failed", "error", err)
+			runCtx := logging.Correlate(ctx, "")
+			a.logRunError(runCtx, a.computeDistances(runCtx, a.config.LookbackHours))
+			if next := a.checkBacklog(runCtx); next != interval {
+				a.logger.InfoContext(runCtx, "Distance interval adjusted", "from", interval, "to", next)
+				interval = next
+				ticker.Reset(clock.Every(interval))
 			}
 		case <-ctx.Done():
 			return nil
@@ -178,9 +234,21 @@ func (a *ComputationAgent) Start(ctx context.Context) error {
 	}
 }
 
+// logRunError logs the outcome of a failed or cancelled computation
+func (a *ComputationAgent) logRunError(ctx context.Context, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrCancelled):
+		a.logger.InfoContext(ctx, "Distance computation cancelled")
+	default:
+		a.logger.ErrorContext(ctx, "Distance computation failed", "error", err)
+	}
+}
+
 func (a *ComputationAgent) handleTrigger(msg mqtt.Message) {
 	var trigger struct {
-		LookbackHours int `json:"lookback_hours"`
+		LookbackHours int    `json:"lookback_hours"`
+		CorrelationID string `json:"correlation_id"`
 	}
 
 	if err := json.Unmarshal(msg.Payload(), &trigger); err != nil {
@@ -190,9 +258,10 @@ func (a *ComputationAgent) handleTrigger(msg mqtt.Message) {
 
 	a.logger.Info("Received distance computation trigger",
 		"topic", msg.Topic(),
-		"lookback_hours", trigger.LookbackHours)
+		"lookback_hours", trigger.LookbackHours,
+		"correlation_id", trigger.CorrelationID)
 
-	a.testTriggers <- TriggerEvent{LookbackHours: trigger.LookbackHours}
+	a.testTriggers <- TriggerEvent{LookbackHours: trigger.LookbackHours, CorrelationID: trigger.CorrelationID}
 }
 
 // ComputeDistancesWithLookback is a public method for triggering distance computation (used by batch coordinator)
@@ -200,38 +269,74 @@ func (a *ComputationAgent) ComputeDistancesWithLookback(ctx context.Context, loo
 	return a.computeDistances(ctx, lookbackHours)
 }
 
+// distanceFlushSize is how many computed distances are buffered before a bulk
+// write, so a failure late in a large batch loses at most one flush
+const distanceFlushSize = 500
+
 // computeDistances performs batch distance computation
 func (a *ComputationAgent) computeDistances(ctx context.Context, lookbackHours int) error {
 	startTime := a.timeManager.Now()
 
-	a.logger.Info("Starting distance computation",
+	a.logger.InfoContext(ctx, "Starting distance computation",
 		"lookback_hours", lookbackHours,
 		"strategy", a.config.Strategy,
-		"batch_size", a.config.BatchSize)
+		"batch_size", a.batchSize())
 
 	// Get anchor pairs needing distances
 	since := a.timeManager.Now().Add(-time.Duration(lookbackHours) * time.Hour)
-	pairs, err := a.storage.GetAnchorsNeedingDistances(ctx, a.config.BatchSize)
+	pairs, err := a.storage.GetAnchorsNeedingDistances(ctx, a.batchSize())
 	if err != nil {
 		return fmt.Errorf("failed to get anchor pairs: %w", err)
 	}
 
 	if len(pairs) == 0 {
-		a.logger.Info("No anchor pairs need distance computation")
-		a.publishCompletion(0)
+		a.logger.InfoContext(ctx, "No anchor pairs need distance computation")
+		a.publishCompletion(0, false)
 		return nil
 	}
 
-	a.logger.Info("Computing distances", "pairs", len(pairs))
+	a.logger.InfoContext(ctx, "Computing distances", "pairs", len(pairs))
 
-	// Compute distances for each pair
+	run := a.beginRun(ctx, len(pairs))
+	defer a.endRun(run)
+
+	// Compute distances for each pair, writing them in bulk
 	distancesComputed := 0
+	pending := make([]*types.AnchorDistance, 0, min(len(pairs), distanceFlushSize))
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := a.storage.StoreDistances(ctx, pending); err != nil {
+			a.logger.ErrorContext(ctx, "Failed to store distances", "count", len(pending), "error", err)
+		} else {
+			distancesComputed += len(pending)
+		}
+		pending = pending[:0]
+	}
+
+	for i, pair := range pairs {
+		// Stop between pairs when cancelled, keeping what was computed
+		if run.cancelled.Load() {
+			flush()
+			a.logger.InfoContext(ctx, "Distance computation cancelled",
+				"pairs_done", i,
+				"pairs_total", len(pairs),
+				"distances_computed", distancesComputed)
+			a.publishCompletion(distancesComputed, true)
+			return ErrCancelled
+		}
+		a.reportProgress(ctx, run, i, distancesComputed+len(pending))
 
-	for _, pair := range pairs {
-		// Load both anchors
+		// Load both anchors. Skip pairs that cannot be loaded, but stop when
+		// Postgres is unavailable: the remaining pairs are retried next run.
 		anchor1, err := a.storage.GetAnchor(ctx, pair[0])
 		if err != nil {
-			a.logger.Warn("Failed to load anchor",
+			if errcode.Retryable(err) {
+				flush()
+				return fmt.Errorf("failed to load anchor %s: %w", pair[0], err)
+			}
+			a.logger.WarnContext(ctx, "Failed to load anchor",
 				"anchor_id", pair[0],
 				"error", err)
 			continue
@@ -239,7 +344,11 @@ func (a *ComputationAgent) computeDistances(ctx context.Context, lookbackHours i
 
 		anchor2, err := a.storage.GetAnchor(ctx, pair[1])
 		if err != nil {
-			a.logger.Warn("Failed to load anchor",
+			if errcode.Retryable(err) {
+				flush()
+				return fmt.Errorf("failed to load anchor %s: %w", pair[1], err)
+			}
+			a.logger.WarnContext(ctx, "Failed to load anchor",
 				"anchor_id", pair[1],
 				"error", err)
 			continue
@@ -253,39 +362,36 @@ func (a *ComputationAgent) computeDistances(ctx context.Context, lookbackHours i
 		// Compute distance using configured strategy
 		distance, source, err := a.computeDistance(ctx, anchor1, anchor2)
 		if err != nil {
-			a.logger.Warn("Failed to compute distance",
+			a.logger.WarnContext(ctx, "Failed to compute distance",
 				"anchor1", anchor1.ID,
 				"anchor2", anchor2.ID,
 				"error", err)
 			continue
 		}
 
-		// Store distance
-		distanceRecord := &types.AnchorDistance{
+		pending = append(pending, &types.AnchorDistance{
 			Anchor1ID:  pair[0],
 			Anchor2ID:  pair[1],
 			Distance:   distance,
 			Source:     source,
 			ComputedAt: a.timeManager.Now(),
+		})
+		if len(pending) >= distanceFlushSize {
+			flush()
 		}
-
-		if err := a.storage.StoreDistance(ctx, distanceRecord); err != nil {
-			a.logger.Error("Failed to store distance", "error", err)
-			continue
-		}
-
-		distancesComputed++
 	}
+	flush()
+	a.recordThroughput(len(pairs), time.Since(run.started))
 
 	duration := time.Since(startTime)
 
-	a.logger.Info("Distance computation completed",
+	a.logger.InfoContext(ctx, "Distance computation completed",
 		"pairs_processed", len(pairs),
 		"distances_computed", distancesComputed,
 		"duration", duration)
 
 	// Publish completion event (for tests)
-	a.publishCompletion(distancesComputed)
+	a.publishCompletion(distancesComputed, false)
 
 	return nil
 }
@@ -319,7 +425,7 @@ func (a *ComputationAgent) computeVectorDistance(
 ) (float64, string, error) {
 
 	// Use structured distance that respects semantic blocks
-	distance := structuredDist(anchor1.SemanticEmbedding, anchor2.SemanticEmbedding)
+	distance := a.vectorDistance(anchor1, anchor2)
 
 	a.logger.Debug("Computed structured vector distance",
 		"anchor1", anchor1.ID,
@@ -341,46 +447,33 @@ func (a *ComputationAgent) computeVectorDistance(
 // [80-95]: Household rhythm
 // [96-127]: Reserved for learned features
 func structuredDist(v1, v2 pgvector.Vector) float64 {
+	return DefaultBlockWeights.distance(blockDistances(v1, v2))
+}
+
+// blockDistances computes the per-block distances combined by structuredDist
+func blockDistances(v1, v2 pgvector.Vector) [numBlocks]float64 {
 	s1 := v1.Slice()
 	s2 := v2.Slice()
 
-	// 1. Temporal distance (cyclic, dimensions 0-3)
-	temporalDist := cyclicDistance(s1[0:4], s2[0:4])
-
-	// 2. Seasonal distance (cyclic, dimensions 4-7)
-	seasonalDist := cyclicDistance(s1[4:8], s2[4:8])
-
-	// 3. Day type distance (categorical, dimensions 8-11)
-	dayTypeDist := euclideanDistance(s1[8:12], s2[8:12])
-
-	// 4. Spatial/Location distance (semantic, dimensions 12-27)
-	// Use cosine for LLM-derived embeddings
-	spatialDist := 1.0 - cosineSimilaritySlice(s1[12:28], s2[12:28])
-
-	// 5. Weather distance (continuous, dimensions 28-43)
-	weatherDist := euclideanDistance(s1[28:44], s2[28:44])
-
-	// 6. Lighting distance (dimensions 44-59)
-	lightingDist := euclideanDistance(s1[44:60], s2[44:60])
-
-	// 7. Activity signals (dimensions 60-79)
-	activityDist := euclideanDistance(s1[60:80], s2[60:80])
-
-	// 8. Household rhythm (dimensions 80-95)
-	rhythmDist := euclideanDistance(s1[80:96], s2[80:96])
-
-	// Weighted combination
-	// Location and activity are most important for semantic distance
-	distance := 0.10*temporalDist +
-		0.05*seasonalDist +
-		0.10*dayTypeDist +
-		0.30*spatialDist +
-		0.05*weatherDist +
-		0.10*lightingDist +
-		0.25*activityDist +
-		0.05*rhythmDist
-
-	return math.Max(0, math.Min(1, distance))
+	return [numBlocks]float64{
+		// 1. Temporal distance (cyclic, dimensions 0-3)
+		cyclicDistance(s1[0:4], s2[0:4]),
+		// 2. Seasonal distance (cyclic, dimensions 4-7)
+		cyclicDistance(s1[4:8], s2[4:8]),
+		// 3. Day type distance (categorical, dimensions 8-11)
+		euclideanDistance(s1[8:12], s2[8:12]),
+		// 4. Spatial/Location distance (semantic, dimensions 12-27)
+		// Use cosine for LLM-derived embeddings
+		1.0 - cosineSimilaritySlice(s1[12:28], s2[12:28]),
+		// 5. Weather distance (continuous, dimensions 28-43)
+		euclideanDistance(s1[28:44], s2[28:44]),
+		// 6. Lighting distance (dimensions 44-59)
+		euclideanDistance(s1[44:60], s2[44:60]),
+		// 7. Activity signals (dimensions 60-79)
+		euclideanDistance(s1[60:80], s2[60:80]),
+		// 8. Household rhythm (dimensions 80-95)
+		euclideanDistance(s1[80:96], s2[80:96]),
+	}
 }
 
 // cyclicDistance computes distance for cyclic dimensions (sin/cos encoded)
@@ -438,35 +531,21 @@ func cosineSimilaritySlice(v1, v2 []float32) float64 {
 	return dot / (math.Sqrt(mag1) * math.Sqrt(mag2))
 }
 
-// isAdjacentLocations checks if two locations are typically part of sequential routines
+// isAdjacentLocations checks if two locations are adjacent in the built-in floor plan
 func isAdjacentLocations(loc1, loc2 string) bool {
-	// Define location pairs that often appear in routines
-	adjacentPairs := map[string][]string{
-		"bedroom":     {"bathroom", "kitchen"},
-		"bathroom":    {"bedroom", "kitchen"},
-		"kitchen":     {"dining_room", "bedroom", "bathroom"},
-		"dining_room": {"kitchen", "living_room"},
-		"living_room": {"dining_room", "study"},
-		"study":       {"living_room"},
-	}
+	return ontology.DefaultTopology().IsAdjacent(loc1, loc2)
+}
 
-	if neighbors, ok := adjacentPairs[loc1]; ok {
-		for _, neighbor := range neighbors {
-			if neighbor == loc2 {
-				return true
-			}
-		}
-	}
+// homeTopology returns the configured home topology (built-in floor plan if unset)
+func (a *ComputationAgent) homeTopology() *ontology.Topology {
+	a.cacheMutex.RLock()
+	topology := a.topology
+	a.cacheMutex.RUnlock()
 
-	if neighbors, ok := adjacentPairs[loc2]; ok {
-		for _, neighbor := range neighbors {
-			if neighbor == loc1 {
-				return true
-			}
-		}
+	if topology == nil {
+		return ontology.DefaultTopology()
 	}
-
-	return false
+	return topology
 }
 
 // SimilarPairCandidate represents a similar pair found in the database
@@ -495,7 +574,8 @@ func (a *ComputationAgent) findSimilarComputedPairs(
 
 	// Determine location adjacency type for filtering
 	sameLocation := anchor1.Location == anchor2.Location
-	adjacent := isAdjacentLocations(anchor1.Location, anchor2.Location)
+	topology := a.homeTopology()
+	adjacent := topology.IsAdjacent(anchor1.Location, anchor2.Location)
 
 	// Calculate time gap (in minutes) between anchors
 	timeGap := math.Abs(anchor2.Timestamp.Sub(anchor1.Timestamp).Minutes())
@@ -515,9 +595,11 @@ func (a *ComputationAgent) findSimilarComputedPairs(
 				-- Both same location
 				($2 = true AND location1 = location2)
 				-- Both adjacent locations
-				OR ($3 = true AND location1 != location2 AND is_adjacent(location1, location2))
+				OR ($3 = true AND location1 != location2
+					AND ((location1 || '|' || location2) = ANY($5) OR (location2 || '|' || location1) = ANY($5)))
 				-- Both distant locations
-				OR ($2 = false AND $3 = false AND location1 != location2 AND NOT is_adjacent(location1, location2))
+				OR ($2 = false AND $3 = false AND location1 != location2
+					AND NOT ((location1 || '|' || location2) = ANY($5) OR (location2 || '|' || location1) = ANY($5)))
 			)
 			-- Similar time gap (within 30 minutes)
 			AND ABS(EXTRACT(EPOCH FROM (timestamp2 - timestamp1))/60 - $4) < 30
@@ -528,7 +610,7 @@ func (a *ComputationAgent) findSimilarComputedPairs(
 	`
 
 	rows, err := a.learnedPatternStorage.db.QueryContext(ctx, query,
-		vectorDist, sameLocation, adjacent, timeGap)
+		vectorDist, sameLocation, adjacent, timeGap, pq.Array(topology.AdjacentPairs()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query similar pairs: %w", err)
 	}
@@ -621,11 +703,11 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 	// PHASE 1: Vector Screening (ALWAYS)
 	// ===========================================
 	// Fast structured distance screening to filter obvious cases
-	vectorDist := structuredDist(anchor1.SemanticEmbedding, anchor2.SemanticEmbedding)
+	vectorDist := a.vectorDistance(anchor1, anchor2)
 
 	// Very similar - high confidence, skip LLM (after initial seeding)
 	if vectorDist < 0.10 && currentTotal > 50 {
-		a.logger.Debug("Progressive: Vector screening - very similar",
+		a.logger.DebugContext(ctx, "Progressive: Vector screening - very similar",
 			"anchor1", anchor1.ID,
 			"anchor2", anchor2.ID,
 			"vector_dist", vectorDist)
@@ -634,7 +716,7 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 
 	// Very different - high confidence, skip LLM
 	if vectorDist > 0.70 {
-		a.logger.Debug("Progressive: Vector screening - very different",
+		a.logger.DebugContext(ctx, "Progressive: Vector screening - very different",
 			"anchor1", anchor1.ID,
 			"anchor2", anchor2.ID,
 			"vector_dist", vectorDist)
@@ -686,7 +768,7 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 			cachedObservations, now, a.learnedPatternConfig)
 
 		if confidence >= a.learnedPatternConfig.HighConfidenceThreshold {
-			a.logger.Debug("Progressive: Exact pattern - high confidence",
+			a.logger.DebugContext(ctx, "Progressive: Exact pattern - high confidence",
 				"pattern_key", patternKey,
 				"confidence", confidence,
 				"distance", weightedDistance,
@@ -701,7 +783,7 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 
 		if confidence >= a.learnedPatternConfig.MediumConfidenceThreshold {
 			// Medium confidence - use but maybe queue for verification
-			a.logger.Debug("Progressive: Exact pattern - medium confidence",
+			a.logger.DebugContext(ctx, "Progressive: Exact pattern - medium confidence",
 				"pattern_key", patternKey,
 				"confidence", confidence,
 				"distance", weightedDistance)
@@ -735,7 +817,7 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 			consistent, avgDistance := a.checkSimilarityConsistency(similarPairs, 0.10)
 
 			if consistent {
-				a.logger.Debug("Progressive: Similarity-based cache hit",
+				a.logger.DebugContext(ctx, "Progressive: Similarity-based cache hit",
 					"anchor1", anchor1.ID,
 					"anchor2", anchor2.ID,
 					"similar_pairs", len(similarPairs),
@@ -766,7 +848,7 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 		if a.shouldSampleForLearning(anchor1, anchor2) {
 			shouldUseLLM = true
 			source = "llm_seed"
-			a.logger.Debug("Progressive: LLM seeding",
+			a.logger.DebugContext(ctx, "Progressive: LLM seeding",
 				"computation", currentTotal,
 				"anchor1", anchor1.ID,
 				"anchor2", anchor2.ID)
@@ -774,7 +856,7 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 	} else {
 		// After seeding, use LLM for novel patterns
 		shouldUseLLM = true
-		a.logger.Debug("Progressive: Novel pattern - using LLM",
+		a.logger.DebugContext(ctx, "Progressive: Novel pattern - using LLM",
 			"pattern_key", patternKey,
 			"computation", currentTotal)
 	}
@@ -788,7 +870,7 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 		}
 
 		// LLM failed - log warning
-		a.logger.Warn("LLM computation failed, using vector fallback",
+		a.logger.WarnContext(ctx, "LLM computation failed, using vector fallback",
 			"error", err,
 			"anchor1", anchor1.ID,
 			"anchor2", anchor2.ID)
@@ -797,7 +879,7 @@ func (a *ComputationAgent) computeProgressiveLearnedDistance(
 	// ===========================================
 	// FALLBACK: Vector Distance
 	// ===========================================
-	a.logger.Debug("Progressive: Using vector fallback",
+	a.logger.DebugContext(ctx, "Progressive: Using vector fallback",
 		"anchor1", anchor1.ID,
 		"anchor2", anchor2.ID,
 		"vector_dist", vectorDist)
@@ -973,55 +1055,14 @@ func (a *ComputationAgent) computeLLMDistance(
 	anchor1, anchor2 *types.SemanticAnchor,
 ) (float64, string, error) {
 
-	prompt := fmt.Sprintf(`Rate the semantic relatedness of these two behavioral anchors.
-
-Anchor 1:
-- Location: %s
-- Time: %s
-- Context: %s (day: %s, season: %s)
-- Signals: %d observed
-
-Anchor 2:
-- Location: %s
-- Time: %s
-- Context: %s (day: %s, season: %s)
-- Signals: %d observed
-
-Consider:
-- Temporal proximity (but context matters more than clock time)
-- Location transitions (kitchen→dining natural, bedroom→garage unusual)
-- Time of day context (morning prep vs late night)
-- Seasonal patterns (winter mornings darker, routines different)
-- Day type (weekday routine vs weekend leisure)
-- Concurrent activities: Different locations at the SAME time usually indicate SEPARATE activities (distance >= 0.5)
-- Sequential activities: Different locations with time progression often indicate RELATED flow (distance < 0.3)
-
-Rate semantic distance on scale 0.0 (same activity/pattern) to 1.0 (completely unrelated).
-
-Examples:
-- Kitchen @ 7am Monday winter + Dining @ 7:30am Monday winter = 0.15 (breakfast sequence across locations)
-- Kitchen @ 7am Monday + Kitchen @ 2am Saturday = 0.8 (same space, very different context)
-- Bedroom @ 10pm + Bedroom @ 7am = 0.7 (same space, sleep boundary between)
-- Living_room @ 20:00 + Study @ 20:00 = 0.6 (concurrent activities in different spaces)
-- Bedroom @ 7:00 + Bathroom @ 7:15 = 0.1 (morning routine flow across locations)
-
-Respond with ONLY valid JSON (no markdown, no explanation):
-{
-  "distance": 0.0-1.0,
-  "reasoning": "brief explanation"
-}`,
-		anchor1.Location,
-		anchor1.Timestamp.Format("15:04"),
-		getContextValue(anchor1.Context, "time_of_day"),
-		getContextValue(anchor1.Context, "day_type"),
-		getContextValue(anchor1.Context, "season"),
-		len(anchor1.Signals),
-		anchor2.Location,
-		anchor2.Timestamp.Format("15:04"),
-		getContextValue(anchor2.Context, "time_of_day"),
-		getContextValue(anchor2.Context, "day_type"),
-		getContextValue(anchor2.Context, "season"),
-		len(anchor2.Signals))
+	prompt, err := a.prompts().Render(prompts.Distance, a.config.Model, prompts.DistanceData{
+		Anchor1:    promptAnchor(anchor1),
+		Anchor2:    promptAnchor(anchor2),
+		HomeLayout: a.homeTopology().Describe(anchor1.Location, anchor2.Location),
+	})
+	if err != nil {
+		return 0, "", err
+	}
 
 	req := llm.GenerateRequest{
 		Model:  a.config.Model,
@@ -1048,7 +1089,7 @@ Respond with ONLY valid JSON (no markdown, no explanation):
 		return 0, "", fmt.Errorf("invalid distance value: %f", result.Distance)
 	}
 
-	a.logger.Debug("LLM computed distance",
+	a.logger.DebugContext(ctx, "LLM computed distance",
 		"anchor1", anchor1.ID,
 		"anchor2", anchor2.ID,
 		"distance", result.Distance,
@@ -1084,6 +1125,26 @@ func generatePatternKey(anchor1, anchor2 *types.SemanticAnchor) string {
 }
 
 // getContextValue safely extracts string value from context map
+// prompts returns the configured prompt templates
+func (a *ComputationAgent) prompts() *llm.Prompts {
+	if a.config.Prompts != nil {
+		return a.config.Prompts
+	}
+	return prompts.Default()
+}
+
+// promptAnchor describes an anchor for the distance prompt
+func promptAnchor(anchor *types.SemanticAnchor) prompts.Anchor {
+	return prompts.Anchor{
+		Location:  anchor.Location,
+		Time:      anchor.Timestamp.Format("15:04"),
+		TimeOfDay: getContextValue(anchor.Context, "time_of_day"),
+		DayType:   getContextValue(anchor.Context, "day_type"),
+		Season:    getContextValue(anchor.Context, "season"),
+		Signals:   len(anchor.Signals),
+	}
+}
+
 func getContextValue(context map[string]interface{}, key string) string {
 	if val, ok := context[key].(string); ok {
 		return val
@@ -1091,14 +1152,15 @@ func getContextValue(context map[string]interface{}, key string) string {
 	return "unknown"
 }
 
-func (a *ComputationAgent) publishCompletion(distancesComputed int) {
-	payload := map[string]interface{}{
-		"distances_computed": distancesComputed,
-		"timestamp":          time.Now().Format(time.RFC3339),
+func (a *ComputationAgent) publishCompletion(distancesComputed int, cancelled bool) {
+	completion := events.DistanceCompletion{
+		DistancesComputed: distancesComputed,
+		Cancelled:         cancelled,
+		Timestamp:         time.Now().Format(time.RFC3339),
 	}
 
-	payloadBytes, _ := json.Marshal(payload)
-	if err := a.mqtt.Publish("automation/behavior/distances/completed", 0, false, payloadBytes); err != nil {
+	payloadBytes, _ := events.Marshal(events.ProducerBehavior, completion)
+	if err := a.mqtt.Publish(completion.Topic(), 0, false, payloadBytes); err != nil {
 		a.logger.Error("Failed to publish completion", "error", err)
 	} else {
 		a.logger.Info("Published distance computation completion",
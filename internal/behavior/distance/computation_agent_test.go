@@ -1,6 +1,8 @@
 package distance
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"testing"
@@ -8,7 +10,12 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pgvector/pgvector-go"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/llm"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
 )
 
 // TestTimeManager for testing
@@ -664,3 +671,184 @@ func TestPatternCache_MultiplePatterns(t *testing.T) {
 
 // Legacy uncertain queue tests removed - queue management was removed along with
 // learned_first, vector_first, and hybrid strategies.
+
+func TestInIdleWindow(t *testing.T) {
+	tests := []struct {
+		hour, start, end int
+		want             bool
+	}{
+		{hour: 3, start: 1, end: 6, want: true},
+		{hour: 6, start: 1, end: 6, want: false},
+		{hour: 0, start: 1, end: 6, want: false},
+		{hour: 23, start: 22, end: 5, want: true},
+		{hour: 2, start: 22, end: 5, want: true},
+		{hour: 12, start: 22, end: 5, want: false},
+		{hour: 12, start: 0, end: 0, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := inIdleWindow(tt.hour, tt.start, tt.end); got != tt.want {
+			t.Errorf("inIdleWindow(%d, %d, %d) = %v, want %v", tt.hour, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestHomeTopology_Configured(t *testing.T) {
+	agent := &ComputationAgent{}
+
+	// Built-in floor plan when nothing is configured
+	if !agent.homeTopology().IsAdjacent("kitchen", "dining_room") {
+		t.Error("expected default topology to treat kitchen/dining_room as adjacent")
+	}
+
+	agent.SetTopology(ontology.NewTopology([]ontology.Room{
+		{ID: "kitchen", Floor: 0, Adjacent: []string{"hallway"}},
+		{ID: "office", Floor: 1, Adjacent: []string{"landing"}},
+	}))
+
+	topology := agent.homeTopology()
+	if topology.IsAdjacent("kitchen", "dining_room") {
+		t.Error("configured topology should replace the built-in floor plan")
+	}
+	if !topology.IsAdjacent("hallway", "kitchen") {
+		t.Error("adjacency should be symmetric")
+	}
+	if got := topology.Describe("kitchen", "office"); got != "kitchen and office are not adjacent, on floors 0 and 1" {
+		t.Errorf("unexpected description: %q", got)
+	}
+}
+
+// stubLLM answers every prompt with a fixed distance
+type stubLLM struct {
+	calls int
+}
+
+func (s *stubLLM) Generate(ctx context.Context, req llm.GenerateRequest) (*llm.GenerateResponse, error) {
+	s.calls++
+	return &llm.GenerateResponse{Response: `{"distance": 0.25, "reasoning": "breakfast sequence"}`, Done: true}, nil
+}
+
+func (s *stubLLM) Health(ctx context.Context) error { return nil }
+
+// stubMQTT records published topics
+type stubMQTT struct {
+	published []string
+}
+
+func (s *stubMQTT) Connect(ctx context.Context) error { return nil }
+func (s *stubMQTT) Disconnect()                       {}
+func (s *stubMQTT) IsConnected() bool                 { return true }
+func (s *stubMQTT) Subscribe(topic string, qos byte, handler mqtt.MessageHandler) error {
+	return nil
+}
+func (s *stubMQTT) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	s.published = append(s.published, topic)
+	return nil
+}
+
+func TestComputeDistances_MemoryRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := storage.NewMemoryAnchorStorage()
+	base := time.Date(2025, 10, 30, 7, 0, 0, 0, time.UTC)
+	morning := map[string]interface{}{"time_of_day": "morning", "day_type": "weekday"}
+
+	kitchen := &types.SemanticAnchor{Location: "kitchen", Timestamp: base, Context: morning,
+		SemanticEmbedding: pgvector.NewVector(make([]float32, 128))}
+	dining := &types.SemanticAnchor{Location: "dining_room", Timestamp: base.Add(20 * time.Minute), Context: morning,
+		SemanticEmbedding: pgvector.NewVector(make([]float32, 128))}
+	study := &types.SemanticAnchor{Location: "study", Timestamp: base.Add(10 * time.Minute), Context: morning,
+		SemanticEmbedding: pgvector.NewVector(make([]float32, 128))}
+	if err := repo.CreateAnchors(ctx, []*types.SemanticAnchor{kitchen, dining, study}); err != nil {
+		t.Fatalf("CreateAnchors: %v", err)
+	}
+
+	llmClient := &stubLLM{}
+	mqttClient := &stubMQTT{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	agent := NewComputationAgent(ComputationConfig{Strategy: "llm_first", BatchSize: 100},
+		repo, llmClient, mqttClient, logger, &TestTimeManager{currentTime: base.Add(time.Hour)})
+
+	if err := agent.computeDistances(ctx, 24); err != nil {
+		t.Fatalf("computeDistances: %v", err)
+	}
+
+	// Only kitchen/dining_room are adjacent; study pairs are never candidates
+	if llmClient.calls != 1 {
+		t.Errorf("expected 1 LLM call, got %d", llmClient.calls)
+	}
+	distance, err := repo.GetDistance(ctx, dining.ID, kitchen.ID)
+	if err != nil || distance == nil {
+		t.Fatalf("expected stored kitchen/dining_room distance, got %v (err %v)", distance, err)
+	}
+	if distance.Distance != 0.25 || distance.Source != "llm" {
+		t.Errorf("unexpected distance %+v", distance)
+	}
+	if d, _ := repo.GetDistance(ctx, kitchen.ID, study.ID); d != nil {
+		t.Errorf("kitchen/study should not have a distance, got %+v", d)
+	}
+	if len(mqttClient.published) != 1 || mqttClient.published[0] != "automation/behavior/distances/completed" {
+		t.Errorf("expected completion event, got %v", mqttClient.published)
+	}
+
+	// A second run finds nothing left to compute
+	pairs, _ := repo.GetAnchorsNeedingDistances(ctx, 100)
+	if len(pairs) != 0 {
+		t.Errorf("expected no remaining pairs, got %d", len(pairs))
+	}
+}
+
+// cancellingLLM cancels the running computation during its first call
+type cancellingLLM struct {
+	stubLLM
+	agent *ComputationAgent
+}
+
+func (c *cancellingLLM) Generate(ctx context.Context, req llm.GenerateRequest) (*llm.GenerateResponse, error) {
+	if c.calls == 0 && !c.agent.Cancel("other-run") {
+		c.agent.Cancel("")
+	}
+	return c.stubLLM.Generate(ctx, req)
+}
+
+func TestComputeDistances_Cancel(t *testing.T) {
+	ctx := logging.WithCorrelationID(context.Background(), "run-1")
+	repo := storage.NewMemoryAnchorStorage()
+	base := time.Date(2025, 10, 30, 7, 0, 0, 0, time.UTC)
+	morning := map[string]interface{}{"time_of_day": "morning", "day_type": "weekday"}
+
+	var anchors []*types.SemanticAnchor
+	for i, location := range []string{"kitchen", "dining_room", "kitchen", "dining_room"} {
+		anchors = append(anchors, &types.SemanticAnchor{Location: location, Timestamp: base.Add(time.Duration(i) * 10 * time.Minute),
+			Context: morning, SemanticEmbedding: pgvector.NewVector(make([]float32, 128))})
+	}
+	if err := repo.CreateAnchors(ctx, anchors); err != nil {
+		t.Fatalf("CreateAnchors: %v", err)
+	}
+	before, _ := repo.GetAnchorsNeedingDistances(ctx, 100)
+	if len(before) < 2 {
+		t.Fatalf("expected several candidate pairs, got %d", len(before))
+	}
+
+	llmClient := &cancellingLLM{}
+	mqttClient := &stubMQTT{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	agent := NewComputationAgent(ComputationConfig{Strategy: "llm_first", BatchSize: 100},
+		repo, llmClient, mqttClient, logger, &TestTimeManager{currentTime: base.Add(time.Hour)})
+	llmClient.agent = agent
+
+	if err := agent.computeDistances(ctx, 24); !errors.Is(err, ErrCancelled) {
+		t.Fatalf("expected ErrCancelled, got %v", err)
+	}
+
+	// The pair in progress finishes and is stored; the rest are left for the next run
+	if llmClient.calls != 1 {
+		t.Errorf("expected 1 LLM call before stopping, got %d", llmClient.calls)
+	}
+	after, _ := repo.GetAnchorsNeedingDistances(ctx, 100)
+	if len(after) != len(before)-1 {
+		t.Errorf("expected %d remaining pairs, got %d", len(before)-1, len(after))
+	}
+	if agent.Cancel("") {
+		t.Error("no run should be registered after the computation returned")
+	}
+}
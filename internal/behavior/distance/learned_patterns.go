@@ -467,7 +467,7 @@ func (s *LearnedPatternStorage) SavePattern(ctx context.Context, pattern *Learne
 			min_distance, max_distance, std_deviation,
 			sample_anchor1_id, sample_anchor2_id
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
-		ON CONFLICT (pattern_key) DO UPDATE SET
+		ON CONFLICT (household_id, pattern_key) DO UPDATE SET
 			weighted_distance = EXCLUDED.weighted_distance,
 			confidence_score = EXCLUDED.confidence_score,
 			observation_count = EXCLUDED.observation_count,
@@ -555,7 +555,7 @@ func (s *LearnedPatternStorage) QueueForRelearning(ctx context.Context, patternK
 		INSERT INTO pattern_relearning_queue (
 			pattern_key, reason, priority, queued_at, original_confidence, original_distance
 		) VALUES ($1, $2, $3, NOW(), $4, $5)
-		ON CONFLICT (pattern_key) DO UPDATE SET
+		ON CONFLICT (household_id, pattern_key) DO UPDATE SET
 			reason = EXCLUDED.reason,
 			priority = GREATEST(pattern_relearning_queue.priority, EXCLUDED.priority),
 			queued_at = EXCLUDED.queued_at,
@@ -566,3 +566,64 @@ func (s *LearnedPatternStorage) QueueForRelearning(ctx context.Context, patternK
 	_, err := s.db.ExecContext(ctx, query, patternKey, reason, priority, originalConfidence, originalDistance)
 	return err
 }
+
+// RelearningItem is a queued pattern with the anchor pair that was sampled for it
+type RelearningItem struct {
+	PatternKey      string
+	Reason          string
+	Priority        int
+	AttemptCount    int
+	SampleAnchor1ID *uuid.UUID
+	SampleAnchor2ID *uuid.UUID
+}
+
+// NextRelearningItems returns up to limit queued patterns by priority, skipping
+// items that have used up maxAttempts or were attempted within retryAfter
+func (s *LearnedPatternStorage) NextRelearningItems(ctx context.Context, limit, maxAttempts int, retryAfter time.Duration) ([]RelearningItem, error) {
+	query := `
+		SELECT q.pattern_key, q.reason, q.priority, q.attempt_count,
+		       lp.sample_anchor1_id, lp.sample_anchor2_id
+		FROM pattern_relearning_queue q
+		JOIN learned_patterns lp ON lp.pattern_key = q.pattern_key
+		WHERE q.attempt_count < $2
+		  AND (q.last_attempt IS NULL OR q.last_attempt < NOW() - INTERVAL '1 second' * $3)
+		ORDER BY q.priority DESC, q.queued_at ASC
+		LIMIT $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, maxAttempts, int(retryAfter.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relearning queue: %w", err)
+	}
+	defer rows.Close()
+
+	var items []RelearningItem
+	for rows.Next() {
+		var item RelearningItem
+		if err := rows.Scan(&item.PatternKey, &item.Reason, &item.Priority, &item.AttemptCount,
+			&item.SampleAnchor1ID, &item.SampleAnchor2ID); err != nil {
+			return nil, fmt.Errorf("failed to scan relearning item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// MarkRelearningAttempt records a failed verification attempt
+func (s *LearnedPatternStorage) MarkRelearningAttempt(ctx context.Context, patternKey string) error {
+	query := `
+		UPDATE pattern_relearning_queue
+		SET attempt_count = attempt_count + 1, last_attempt = NOW()
+		WHERE pattern_key = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, patternKey)
+	return err
+}
+
+// DequeueRelearning removes a pattern from the re-learning queue
+func (s *LearnedPatternStorage) DequeueRelearning(ctx context.Context, patternKey string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM pattern_relearning_queue WHERE pattern_key = $1`, patternKey)
+	return err
+}
@@ -0,0 +1,142 @@
+package distance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/progress"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+const (
+	// progressTopic receives periodic progress of a distance computation
+	progressTopic = "automation/behavior/distances/progress"
+
+	// cancelTopic aborts the running computation between pairs
+	cancelTopic = "automation/behavior/distances/cancel"
+
+	// progressInterval is how often progress is published; shorter runs
+	// publish none
+	progressInterval = 10 * time.Second
+)
+
+// ErrCancelled is returned by a computation stopped through cancelTopic.
+// Distances computed before the cancel are kept.
+var ErrCancelled = errors.New("distance computation cancelled")
+
+// Progress is the payload of automation/behavior/distances/progress
+type Progress struct {
+	RunID             string    `json:"run_id"` // the run's correlation ID
+	PairsDone         int       `json:"pairs_done"`
+	PairsTotal        int       `json:"pairs_total"`
+	DistancesComputed int       `json:"distances_computed"` // so far, including distances not yet stored
+	ElapsedSeconds    float64   `json:"elapsed_seconds"`
+	ETASeconds        float64   `json:"eta_seconds"` // at the average pace so far
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// distanceRun is a computation in progress
+type distanceRun struct {
+	id        string
+	total     int
+	started   time.Time // wall clock, so the ETA holds under virtual time
+	published time.Time
+	cancelled atomic.Bool
+}
+
+// beginRun registers the computation running under ctx so it can be cancelled
+func (a *ComputationAgent) beginRun(ctx context.Context, total int) *distanceRun {
+	id := logging.CorrelationID(ctx)
+	if id == "" {
+		id = logging.NewCorrelationID()
+	}
+	now := time.Now()
+	run := &distanceRun{id: id, total: total, started: now, published: now}
+
+	a.runMutex.Lock()
+	a.run = run
+	a.runMutex.Unlock()
+	return run
+}
+
+// endRun unregisters run
+func (a *ComputationAgent) endRun(run *distanceRun) {
+	a.runMutex.Lock()
+	if a.run == run {
+		a.run = nil
+	}
+	a.runMutex.Unlock()
+}
+
+// Cancel stops the running computation after the pair in progress. An empty
+// runID cancels whatever is running. It reports whether a run was cancelled.
+func (a *ComputationAgent) Cancel(runID string) bool {
+	a.runMutex.Lock()
+	defer a.runMutex.Unlock()
+
+	if a.run == nil || (runID != "" && runID != a.run.id) {
+		return false
+	}
+	a.run.cancelled.Store(true)
+	return true
+}
+
+func (a *ComputationAgent) handleCancel(msg mqtt.Message) {
+	var req struct {
+		RunID string `json:"run_id"`
+	}
+	if len(msg.Payload()) > 0 {
+		if err := json.Unmarshal(msg.Payload(), &req); err != nil {
+			a.logger.Error("Failed to parse cancel request", "error", err)
+			return
+		}
+	}
+
+	if a.Cancel(req.RunID) {
+		a.logger.Info("Distance computation cancel requested", "run_id", req.RunID)
+	} else {
+		a.logger.Info("No matching distance computation to cancel", "run_id", req.RunID)
+	}
+}
+
+// reportProgress publishes progress when progressInterval has passed since
+// the last report
+func (a *ComputationAgent) reportProgress(ctx context.Context, run *distanceRun, done, computed int) {
+	now := time.Now()
+	if now.Sub(run.published) < progressInterval {
+		return
+	}
+	run.published = now
+
+	elapsed := now.Sub(run.started)
+	p := Progress{
+		RunID:             run.id,
+		PairsDone:         done,
+		PairsTotal:        run.total,
+		DistancesComputed: computed,
+		ElapsedSeconds:    elapsed.Seconds(),
+		Timestamp:         now.UTC(),
+	}
+	if done > 0 {
+		p.ETASeconds = (elapsed / time.Duration(done) * time.Duration(run.total-done)).Seconds()
+	}
+
+	progress.Phase(ctx, "computing_distances", map[string]interface{}{
+		"pairs_done":  done,
+		"pairs_total": run.total,
+	})
+
+	payload, _ := events.Marshal(events.ProducerBehavior, p)
+	if err := a.mqtt.Publish(progressTopic, 0, false, payload); err != nil {
+		a.logger.WarnContext(ctx, "Failed to publish distance progress", "error", err)
+	}
+	a.logger.InfoContext(ctx, "Distance computation progress",
+		"pairs_done", done,
+		"pairs_total", run.total,
+		"eta_seconds", int(p.ETASeconds))
+}
@@ -0,0 +1,118 @@
+package distance
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Re-learning reasons and priorities for context transitions. Confidence drops
+// (priority 5) are verified first.
+const (
+	reasonSeasonalRefresh = "seasonal_refresh"
+	reasonDSTTransition   = "dst_transition"
+	transitionPriority    = 3
+)
+
+// queueTransitionPatterns queues the learned patterns that have had no LLM
+// observation since the last season change or DST transition, the points
+// where computeContextualDecay starts discounting their observations
+func (a *ComputationAgent) queueTransitionPatterns(ctx context.Context) (int, error) {
+	now := a.timeManager.Now()
+	transition, reason := lastTransition(now)
+
+	// Patterns without observations in the retention window are dead, not stale
+	activeSince := transition.AddDate(0, 0, -a.learnedPatternConfig.MaxObservationAgeDays)
+
+	queued, err := a.learnedPatternStorage.QueueStalePatterns(ctx, transition, activeSince, reason, transitionPriority)
+	if err != nil {
+		return 0, err
+	}
+	if queued > 0 {
+		a.logger.Info("Queued learned patterns for re-learning after transition",
+			"reason", reason,
+			"transition", transition,
+			"queued", queued)
+	}
+	return queued, nil
+}
+
+// lastTransition returns the latest season start or UTC offset change (DST)
+// at or before now, in now's location
+func lastTransition(now time.Time) (time.Time, string) {
+	transition, reason := seasonStart(now), reasonSeasonalRefresh
+	if dst := lastOffsetChange(now, 366*24*time.Hour); dst.After(transition) {
+		transition, reason = dst, reasonDSTTransition
+	}
+	return transition, reason
+}
+
+// seasonStart returns midnight on the first day of now's season, matching
+// getCurrentSeason (winter starts in December)
+func seasonStart(now time.Time) time.Time {
+	month := now.Month() - now.Month()%3
+	return time.Date(now.Year(), month, 1, 0, 0, 0, 0, now.Location())
+}
+
+// lastOffsetChange returns the first instant with now's UTC offset after the
+// last change within lookback, or the zero time when the offset did not change
+func lastOffsetChange(now time.Time, lookback time.Duration) time.Time {
+	_, offset := now.Zone()
+	changed := func(t time.Time) bool {
+		_, o := t.Zone()
+		return o != offset
+	}
+
+	// Step back a day at a time, then bisect to the minute
+	after := now
+	for before := now.Add(-24 * time.Hour); now.Sub(before) <= lookback; before = before.Add(-24 * time.Hour) {
+		if !changed(before) {
+			after = before
+			continue
+		}
+		for after.Sub(before) > time.Minute {
+			mid := before.Add(after.Sub(before) / 2)
+			if changed(mid) {
+				before = mid
+			} else {
+				after = mid
+			}
+		}
+		return after.Truncate(time.Minute)
+	}
+	return time.Time{}
+}
+
+// QueueStalePatterns queues patterns first seen before transition and updated
+// since activeSince that have no LLM observation since transition, leaving
+// patterns already in the queue as they are. Returns how many were queued.
+func (s *LearnedPatternStorage) QueueStalePatterns(ctx context.Context, transition, activeSince time.Time, reason string, priority int) (int, error) {
+	query := `
+		INSERT INTO pattern_relearning_queue (
+			pattern_key, reason, priority, queued_at, original_confidence, original_distance
+		)
+		SELECT lp.pattern_key, $3, $4, NOW(), lp.confidence_score, lp.weighted_distance
+		FROM learned_patterns lp
+		WHERE lp.first_seen < $1
+		  AND lp.last_updated >= $2
+		  AND lp.sample_anchor1_id IS NOT NULL
+		  AND lp.sample_anchor2_id IS NOT NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM pattern_observations po
+			WHERE po.pattern_key = lp.pattern_key
+			  AND po.source IN ('llm', 'llm_verify', 'llm_seed')
+			  AND po.timestamp >= $1
+		  )
+		ON CONFLICT (household_id, pattern_key) DO NOTHING
+	`
+
+	result, err := s.db.ExecContext(ctx, query, transition, activeSince, reason, priority)
+	if err != nil {
+		return 0, fmt.Errorf("failed to queue stale patterns: %w", err)
+	}
+	queued, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count queued patterns: %w", err)
+	}
+	return int(queued), nil
+}
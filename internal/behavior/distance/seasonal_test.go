@@ -0,0 +1,51 @@
+package distance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeasonStart(t *testing.T) {
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC), time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 11, 30, 23, 0, 0, 0, time.UTC), time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 12, 24, 18, 0, 0, 0, time.UTC), time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := seasonStart(tt.now); !got.Equal(tt.want) {
+			t.Errorf("seasonStart(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+		if getCurrentSeason(seasonStart(tt.now)) != getCurrentSeason(tt.now) {
+			t.Errorf("season of %v starts in another season", tt.now)
+		}
+	}
+}
+
+func TestLastTransition(t *testing.T) {
+	// Without DST only seasons change
+	transition, reason := lastTransition(time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC))
+	if reason != reasonSeasonalRefresh || !transition.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("UTC: got %v %s", transition, reason)
+	}
+
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// Summer time started 2025-03-30 01:00 UTC, after the start of spring
+	transition, reason = lastTransition(time.Date(2025, 4, 10, 12, 0, 0, 0, helsinki))
+	if reason != reasonDSTTransition || !transition.Equal(time.Date(2025, 3, 30, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("Helsinki spring: got %v %s", transition, reason)
+	}
+
+	// Summer starts after the March transition
+	transition, reason = lastTransition(time.Date(2025, 7, 1, 12, 0, 0, 0, helsinki))
+	if reason != reasonSeasonalRefresh || !transition.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, helsinki)) {
+		t.Errorf("Helsinki summer: got %v %s", transition, reason)
+	}
+}
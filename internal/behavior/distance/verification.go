@@ -0,0 +1,157 @@
+package distance
+
+import (
+	"context"
+	"time"
+)
+
+// maxVerificationAttempts drops a queued pattern after this many failed LLM calls
+const maxVerificationAttempts = 3
+
+// VerificationConfig controls the background worker that drains the pattern
+// re-learning queue with LLM verification. A zero Interval disables it.
+type VerificationConfig struct {
+	Interval      time.Duration // How often to check the queue
+	RatePerMinute int           // Max LLM calls per minute (default 6)
+	BatchSize     int           // Max patterns per run (default 20)
+	IdleStartHour int           // Only run between these hours (virtual time);
+	IdleEndHour   int           // equal values mean any time
+
+	// SeasonalRefresh queues patterns without LLM observations since the last
+	// season change or DST transition on every check, not only in idle hours
+	SeasonalRefresh bool
+}
+
+// runVerificationWorker periodically drains the re-learning queue during idle
+// hours, after queueing patterns made stale by a season or DST transition
+func (a *ComputationAgent) runVerificationWorker(ctx context.Context) {
+	cfg := a.config.Verification
+
+	a.logger.Info("Starting distance verification worker",
+		"interval", cfg.Interval,
+		"rate_per_minute", cfg.RatePerMinute,
+		"idle_hours", [2]int{cfg.IdleStartHour, cfg.IdleEndHour},
+		"seasonal_refresh", cfg.SeasonalRefresh)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if cfg.SeasonalRefresh {
+				if _, err := a.queueTransitionPatterns(ctx); err != nil {
+					a.logger.Error("Failed to queue patterns after transition", "error", err)
+				}
+			}
+			if !inIdleWindow(a.timeManager.Now().Hour(), cfg.IdleStartHour, cfg.IdleEndHour) {
+				continue
+			}
+			verified, err := a.processVerificationQueue(ctx)
+			if err != nil {
+				a.logger.Error("Verification queue processing failed", "error", err)
+			} else if verified > 0 {
+				a.logger.Info("Verification queue processed", "verified", verified)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processVerificationQueue re-verifies queued patterns by asking the LLM about
+// each pattern's sample anchor pair, records the answer as an llm_verify
+// observation and removes the pattern from the queue
+func (a *ComputationAgent) processVerificationQueue(ctx context.Context) (int, error) {
+	cfg := a.config.Verification
+
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+	rate := cfg.RatePerMinute
+	if rate <= 0 {
+		rate = 6
+	}
+
+	items, err := a.learnedPatternStorage.NextRelearningItems(ctx, batchSize, maxVerificationAttempts, cfg.Interval)
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	a.logger.Debug("Processing verification queue", "items", len(items))
+
+	limiter := time.NewTicker(time.Minute / time.Duration(rate))
+	defer limiter.Stop()
+
+	verified := 0
+	for i, item := range items {
+		if item.SampleAnchor1ID == nil || item.SampleAnchor2ID == nil {
+			// Nothing to show the LLM; the pattern relearns from new computations instead
+			a.dequeueVerification(ctx, item.PatternKey)
+			continue
+		}
+
+		anchor1, err1 := a.storage.GetAnchor(ctx, *item.SampleAnchor1ID)
+		anchor2, err2 := a.storage.GetAnchor(ctx, *item.SampleAnchor2ID)
+		if err1 != nil || err2 != nil {
+			a.logger.Debug("Sample anchors unavailable, dropping queued pattern",
+				"pattern_key", item.PatternKey)
+			a.dequeueVerification(ctx, item.PatternKey)
+			continue
+		}
+
+		// Rate limit LLM calls (first call goes immediately)
+		if i > 0 {
+			select {
+			case <-limiter.C:
+			case <-ctx.Done():
+				return verified, ctx.Err()
+			}
+		}
+
+		dist, _, err := a.computeLLMDistance(ctx, anchor1, anchor2)
+		if err != nil {
+			a.logger.Warn("LLM verification failed",
+				"pattern_key", item.PatternKey,
+				"attempt", item.AttemptCount+1,
+				"error", err)
+			if err := a.learnedPatternStorage.MarkRelearningAttempt(ctx, item.PatternKey); err != nil {
+				a.logger.Error("Failed to record verification attempt", "pattern_key", item.PatternKey, "error", err)
+			}
+			continue
+		}
+
+		vectorDist := a.vectorDistance(anchor1, anchor2)
+		a.recordObservationWithMetadata(ctx, anchor1, anchor2, dist, "llm_verify", vectorDist)
+		a.dequeueVerification(ctx, item.PatternKey)
+		verified++
+
+		a.logger.Debug("Verified queued pattern",
+			"pattern_key", item.PatternKey,
+			"reason", item.Reason,
+			"distance", dist)
+	}
+
+	return verified, nil
+}
+
+func (a *ComputationAgent) dequeueVerification(ctx context.Context, patternKey string) {
+	if err := a.learnedPatternStorage.DequeueRelearning(ctx, patternKey); err != nil {
+		a.logger.Error("Failed to dequeue pattern", "pattern_key", patternKey, "error", err)
+	}
+}
+
+// inIdleWindow reports whether hour lies in [start, end), wrapping past midnight
+func inIdleWindow(hour, start, end int) bool {
+	if start == end {
+		return true
+	}
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
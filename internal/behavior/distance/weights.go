@@ -0,0 +1,282 @@
+package distance
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+// numBlocks is the number of embedding blocks combined by structuredDist
+const numBlocks = 8
+
+// BlockWeights weights the structuredDist blocks, in order: temporal, seasonal,
+// day type, spatial, weather, lighting, activity, household rhythm
+type BlockWeights [numBlocks]float64
+
+// DefaultBlockWeights are the hand-tuned weights used until weights are fitted.
+// Location and activity are most important for semantic distance.
+var DefaultBlockWeights = BlockWeights{0.10, 0.05, 0.10, 0.30, 0.05, 0.10, 0.25, 0.05}
+
+// distance combines block distances into a single value in [0, 1]
+func (w BlockWeights) distance(blocks [numBlocks]float64) float64 {
+	var d float64
+	for i := range blocks {
+		d += w[i] * blocks[i]
+	}
+	return math.Max(0, math.Min(1, d))
+}
+
+// WeightSample is an LLM-labeled anchor pair reduced to its block distances
+type WeightSample struct {
+	Blocks [numBlocks]float64
+	Target float64 // LLM distance
+}
+
+// FittedWeights is the result of fitting block weights to LLM labels
+type FittedWeights struct {
+	Weights     BlockWeights
+	SampleCount int
+	MSE         float64 // Mean squared error of the fitted weights
+	BaselineMSE float64 // Mean squared error of DefaultBlockWeights on the same samples
+	FittedAt    time.Time
+}
+
+// FitBlockWeights finds non-negative weights summing to 1 that minimise the
+// squared error against the LLM labels, using projected gradient descent on
+// the probability simplex starting from initial
+func FitBlockWeights(samples []WeightSample, initial BlockWeights, iterations int) (BlockWeights, float64) {
+	if len(samples) == 0 {
+		return initial, 0
+	}
+
+	// Step size from the mean squared feature norm keeps the descent stable
+	var norm float64
+	for _, s := range samples {
+		for _, x := range s.Blocks {
+			norm += x * x
+		}
+	}
+	norm /= float64(len(samples))
+	step := 0.5 / math.Max(norm, 1e-9)
+
+	w := projectToSimplex(initial)
+	n := float64(len(samples))
+
+	for iter := 0; iter < iterations; iter++ {
+		var grad [numBlocks]float64
+		for _, s := range samples {
+			residual := dot(w, s.Blocks) - s.Target
+			for i, x := range s.Blocks {
+				grad[i] += 2 * residual * x / n
+			}
+		}
+		for i := range w {
+			w[i] -= step * grad[i]
+		}
+		w = projectToSimplex(w)
+	}
+
+	return w, meanSquaredError(w, samples)
+}
+
+// meanSquaredError evaluates weights on samples (without clamping)
+func meanSquaredError(w BlockWeights, samples []WeightSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		residual := dot(w, s.Blocks) - s.Target
+		sum += residual * residual
+	}
+	return sum / float64(len(samples))
+}
+
+func dot(w BlockWeights, x [numBlocks]float64) float64 {
+	var d float64
+	for i := range x {
+		d += w[i] * x[i]
+	}
+	return d
+}
+
+// projectToSimplex returns the closest point to w with non-negative entries summing to 1
+func projectToSimplex(w BlockWeights) BlockWeights {
+	sorted := w
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted[:])))
+
+	var cumulative, theta float64
+	for i, v := range sorted {
+		cumulative += v
+		t := (cumulative - 1) / float64(i+1)
+		if v-t > 0 {
+			theta = t
+		}
+	}
+
+	var projected BlockWeights
+	for i, v := range w {
+		projected[i] = math.Max(0, v-theta)
+	}
+	return projected
+}
+
+// vectorDistance computes structuredDist with the agent's current block weights
+func (a *ComputationAgent) vectorDistance(anchor1, anchor2 *types.SemanticAnchor) float64 {
+	a.cacheMutex.RLock()
+	weights := a.blockWeights
+	a.cacheMutex.RUnlock()
+
+	if weights == (BlockWeights{}) {
+		weights = DefaultBlockWeights
+	}
+	return weights.distance(blockDistances(anchor1.SemanticEmbedding, anchor2.SemanticEmbedding))
+}
+
+// loadBlockWeights switches to the latest fitted weights, if any
+func (a *ComputationAgent) loadBlockWeights(ctx context.Context) {
+	fitted, err := a.learnedPatternStorage.LoadBlockWeights(ctx)
+	if err != nil {
+		a.logger.Warn("Failed to load fitted distance weights, using defaults", "error", err)
+		return
+	}
+	if fitted == nil {
+		return
+	}
+
+	a.cacheMutex.Lock()
+	a.blockWeights = fitted.Weights
+	a.cacheMutex.Unlock()
+
+	a.logger.Info("Using fitted distance weights",
+		"weights", fitted.Weights,
+		"samples", fitted.SampleCount,
+		"mse", fitted.MSE,
+		"baseline_mse", fitted.BaselineMSE,
+		"fitted_at", fitted.FittedAt)
+}
+
+// OptimizeBlockWeights fits block weights to recent LLM-labeled pairs and saves
+// them when they beat DefaultBlockWeights. Returns the fit either way.
+func OptimizeBlockWeights(ctx context.Context, db *sql.DB, minSamples int, logger *slog.Logger) (*FittedWeights, bool, error) {
+	storage := NewLearnedPatternStorage(db, logger)
+
+	samples, err := storage.LoadWeightSamples(ctx, 5000)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(samples) < minSamples {
+		return nil, false, fmt.Errorf("not enough LLM-labeled pairs to fit weights: have %d, need %d", len(samples), minSamples)
+	}
+
+	weights, mse := FitBlockWeights(samples, DefaultBlockWeights, 2000)
+	fitted := &FittedWeights{
+		Weights:     weights,
+		SampleCount: len(samples),
+		MSE:         mse,
+		BaselineMSE: meanSquaredError(DefaultBlockWeights, samples),
+		FittedAt:    time.Now(),
+	}
+
+	logger.Info("Fitted distance block weights",
+		"samples", fitted.SampleCount,
+		"weights", fitted.Weights,
+		"mse", fitted.MSE,
+		"baseline_mse", fitted.BaselineMSE)
+
+	if fitted.MSE >= fitted.BaselineMSE {
+		logger.Info("Fitted weights do not improve on defaults, not saving")
+		return fitted, false, nil
+	}
+
+	if err := storage.SaveBlockWeights(ctx, fitted); err != nil {
+		return fitted, false, err
+	}
+	return fitted, true, nil
+}
+
+// LoadWeightSamples loads up to limit recent LLM-labeled pairs as block distances
+func (s *LearnedPatternStorage) LoadWeightSamples(ctx context.Context, limit int) ([]WeightSample, error) {
+	query := `
+		SELECT embedding1, embedding2, distance
+		FROM recent_llm_distances
+		ORDER BY computed_at DESC
+		LIMIT $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LLM-labeled pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []WeightSample
+	for rows.Next() {
+		var e1, e2 pgvector.Vector
+		var target float64
+		if err := rows.Scan(&e1, &e2, &target); err != nil {
+			return nil, fmt.Errorf("failed to scan LLM-labeled pair: %w", err)
+		}
+		samples = append(samples, WeightSample{
+			Blocks: blockDistances(e1, e2),
+			Target: target,
+		})
+	}
+
+	return samples, rows.Err()
+}
+
+// SaveBlockWeights stores a new set of fitted weights
+func (s *LearnedPatternStorage) SaveBlockWeights(ctx context.Context, fitted *FittedWeights) error {
+	weightsJSON, err := json.Marshal(fitted.Weights)
+	if err != nil {
+		return fmt.Errorf("failed to marshal weights: %w", err)
+	}
+
+	query := `
+		INSERT INTO distance_block_weights (weights, sample_count, mse, baseline_mse, fitted_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	if _, err := s.db.ExecContext(ctx, query, weightsJSON, fitted.SampleCount,
+		fitted.MSE, fitted.BaselineMSE, fitted.FittedAt); err != nil {
+		return fmt.Errorf("failed to save weights: %w", err)
+	}
+	return nil
+}
+
+// LoadBlockWeights returns the most recently fitted weights, or nil if none exist
+func (s *LearnedPatternStorage) LoadBlockWeights(ctx context.Context) (*FittedWeights, error) {
+	query := `
+		SELECT weights, sample_count, mse, baseline_mse, fitted_at
+		FROM distance_block_weights
+		ORDER BY fitted_at DESC
+		LIMIT 1
+	`
+
+	var weightsJSON []byte
+	fitted := &FittedWeights{}
+	err := s.db.QueryRowContext(ctx, query).Scan(&weightsJSON, &fitted.SampleCount,
+		&fitted.MSE, &fitted.BaselineMSE, &fitted.FittedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load weights: %w", err)
+	}
+
+	if err := json.Unmarshal(weightsJSON, &fitted.Weights); err != nil {
+		return nil, fmt.Errorf("failed to parse weights: %w", err)
+	}
+	return fitted, nil
+}
@@ -0,0 +1,57 @@
+package distance
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestProjectToSimplex(t *testing.T) {
+	projected := projectToSimplex(BlockWeights{0.5, -0.2, 0.9, 0, 0, 0, 0, 0.1})
+
+	var sum float64
+	for i, w := range projected {
+		if w < 0 {
+			t.Errorf("weight %d is negative: %f", i, w)
+		}
+		sum += w
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("weights sum to %f, want 1", sum)
+	}
+
+	// Points already on the simplex are unchanged
+	if got := projectToSimplex(DefaultBlockWeights); got != DefaultBlockWeights {
+		t.Errorf("default weights changed by projection: %v", got)
+	}
+}
+
+func TestFitBlockWeights_RecoversTrueWeights(t *testing.T) {
+	truth := BlockWeights{0.05, 0.05, 0.05, 0.50, 0, 0.05, 0.25, 0.05}
+	rng := rand.New(rand.NewSource(1))
+
+	samples := make([]WeightSample, 500)
+	for i := range samples {
+		for b := range samples[i].Blocks {
+			samples[i].Blocks[b] = rng.Float64()
+		}
+		samples[i].Target = dot(truth, samples[i].Blocks)
+	}
+
+	fitted, mse := FitBlockWeights(samples, DefaultBlockWeights, 3000)
+
+	if baseline := meanSquaredError(DefaultBlockWeights, samples); mse >= baseline {
+		t.Errorf("fitted MSE %f should beat default MSE %f", mse, baseline)
+	}
+	for i := range truth {
+		if math.Abs(fitted[i]-truth[i]) > 0.02 {
+			t.Errorf("block %d: fitted %.3f, want %.3f", i, fitted[i], truth[i])
+		}
+	}
+}
+
+func TestFitBlockWeights_NoSamples(t *testing.T) {
+	if fitted, _ := FitBlockWeights(nil, DefaultBlockWeights, 10); fitted != DefaultBlockWeights {
+		t.Errorf("expected initial weights without samples, got %v", fitted)
+	}
+}
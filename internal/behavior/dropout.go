@@ -0,0 +1,247 @@
+package behavior
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+)
+
+// sensorHealthTopic receives one event per detected dropout, per device
+const sensorHealthTopic = "automation/behavior/sensor_health/%s/%s"
+
+// SensorDropout is a window in which a normally chatty sensor went silent
+// while reporting activity. End is zero while the sensor is still silent.
+type SensorDropout struct {
+	Location   string
+	SensorType string
+	Device     string
+	Start      time.Time     // last event before the silence
+	End        time.Time     // first event after the silence
+	Until      time.Time     // while still silent, the next event from another sensor in the location
+	Typical    time.Duration // the sensor's median interval between events
+}
+
+// bound is the last time the dropout explains: End, or Until while the
+// sensor is still silent. It is zero when nothing in the location has
+// reported since.
+func (d SensorDropout) bound() time.Time {
+	if !d.End.IsZero() {
+		return d.End
+	}
+	return d.Until
+}
+
+// Covers reports whether the dropout spans the gap between from and to in location.
+// tolerance allows for activity just before the sensor went silent.
+func (d SensorDropout) Covers(location string, from, to time.Time, tolerance time.Duration) bool {
+	if d.Location != location || d.Start.After(from.Add(tolerance)) {
+		return false
+	}
+	bound := d.bound()
+	return bound.IsZero() || !bound.Before(to)
+}
+
+// Overlaps reports whether the dropout intersects [start, end] in location
+func (d SensorDropout) Overlaps(location string, start, end time.Time) bool {
+	if d.Location != location || d.Start.After(end) {
+		return false
+	}
+	bound := d.bound()
+	return bound.IsZero() || !bound.Before(start)
+}
+
+// activeStates are sensor states after which silence is not explained by
+// absence: a motion sensor never stays "on" for long without reporting again
+var activeStates = map[string]bool{
+	"on":       true,
+	"occupied": true,
+	"home":     true,
+	"playing":  true,
+}
+
+// detectDropouts finds dropout windows in each sensor's event stream between
+// the first event and until. Events must be sorted by timestamp.
+func (a *Agent) detectDropouts(events []Event, until time.Time) []SensorDropout {
+	if !a.cfg.SensorDropoutEnabled {
+		return nil
+	}
+
+	type streamKey struct{ location, sensorType, device string }
+	streams := make(map[streamKey][]Event)
+	var keys []streamKey
+	for _, event := range events {
+		key := streamKey{event.Location, event.Type, event.Device}
+		if _, ok := streams[key]; !ok {
+			keys = append(keys, key)
+		}
+		streams[key] = append(streams[key], event)
+	}
+
+	var dropouts []SensorDropout
+	for _, key := range keys {
+		stream := streams[key]
+		if len(stream) < a.cfg.SensorDropoutMinEvents {
+			continue // not chatty enough to tell silence from absence
+		}
+
+		typical := medianInterval(stream)
+		threshold := time.Duration(float64(typical) * a.cfg.SensorDropoutFactor)
+		if threshold < a.cfg.SensorDropoutMinGap {
+			threshold = a.cfg.SensorDropoutMinGap
+		}
+
+		for i, event := range stream {
+			if !activeStates[event.State] {
+				continue
+			}
+			next := until
+			if i+1 < len(stream) {
+				next = stream[i+1].Timestamp
+			}
+			if next.Sub(event.Timestamp) < threshold {
+				continue
+			}
+
+			dropout := SensorDropout{
+				Location:   key.location,
+				SensorType: key.sensorType,
+				Device:     key.device,
+				Start:      event.Timestamp,
+				Typical:    typical,
+			}
+			if i+1 < len(stream) {
+				dropout.End = next
+			} else {
+				dropout.Until = nextInLocation(events, key.location, event.Timestamp)
+			}
+			dropouts = append(dropouts, dropout)
+		}
+	}
+
+	return dropouts
+}
+
+// nextInLocation returns the time of the first event in location after ts,
+// or zero when there is none
+func nextInLocation(events []Event, location string, ts time.Time) time.Time {
+	for _, event := range events {
+		if event.Location == location && event.Timestamp.After(ts) {
+			return event.Timestamp
+		}
+	}
+	return time.Time{}
+}
+
+// medianInterval returns the median time between consecutive events
+func medianInterval(stream []Event) time.Duration {
+	intervals := make([]time.Duration, 0, len(stream)-1)
+	for i := 1; i < len(stream); i++ {
+		intervals = append(intervals, stream[i].Timestamp.Sub(stream[i-1].Timestamp))
+	}
+	if len(intervals) == 0 {
+		return 0
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
+
+// droppedOutThrough reports whether a gap between two events in location is
+// explained by a sensor dropout rather than absence
+func droppedOutThrough(dropouts []SensorDropout, location string, from, to time.Time, tolerance time.Duration) bool {
+	for _, dropout := range dropouts {
+		if dropout.Covers(location, from, to, tolerance) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropoutsDuring returns the JSON-LD annotation of dropouts that affected an episode
+func dropoutsDuring(dropouts []SensorDropout, location string, start, end time.Time) []map[string]interface{} {
+	var affected []map[string]interface{}
+	for _, dropout := range dropouts {
+		if !dropout.Overlaps(location, start, end) {
+			continue
+		}
+		entry := map[string]interface{}{
+			"sensorType": dropout.SensorType,
+			"startedAt":  dropout.Start.Format(time.RFC3339),
+		}
+		if dropout.Device != "" {
+			entry["device"] = dropout.Device
+		}
+		if !dropout.End.IsZero() {
+			entry["endedAt"] = dropout.End.Format(time.RFC3339)
+		}
+		affected = append(affected, entry)
+	}
+	return affected
+}
+
+// publishSensorHealth publishes each dropout once per device and start time
+func (a *Agent) publishSensorHealth(dropouts []SensorDropout) {
+	for _, dropout := range dropouts {
+		device := dropout.Device
+		if device == "" {
+			device = dropout.SensorType
+		}
+		reportKey := fmt.Sprintf("%s/%s/%d/%t", dropout.Location, device, dropout.Start.UnixMilli(), dropout.End.IsZero())
+
+		a.stateMux.Lock()
+		_, reported := a.reportedDropouts[reportKey]
+		if !reported {
+			a.reportedDropouts[reportKey] = dropout.Start
+		}
+		a.stateMux.Unlock()
+		if reported {
+			continue
+		}
+
+		status := "recovered"
+		silence := a.timeManager.Now().Sub(dropout.Start)
+		if dropout.End.IsZero() {
+			status = "dropout"
+		} else {
+			silence = dropout.End.Sub(dropout.Start)
+		}
+
+		event := map[string]interface{}{
+			"location":                 dropout.Location,
+			"device":                   device,
+			"sensor_type":              dropout.SensorType,
+			"status":                   status,
+			"silent_since":             dropout.Start.Format(time.RFC3339),
+			"silence_minutes":          int(silence.Minutes()),
+			"typical_interval_seconds": int(dropout.Typical.Seconds()),
+			"timestamp":                a.timeManager.Now().Format(time.RFC3339),
+		}
+		if !dropout.End.IsZero() {
+			event["resumed_at"] = dropout.End.Format(time.RFC3339)
+		}
+
+		payload, _ := events.Marshal(events.ProducerBehavior, event)
+		topic := fmt.Sprintf(sensorHealthTopic, dropout.Location, device)
+		if err := a.mqtt.Publish(topic, 0, false, payload); err != nil {
+			a.logger.Warn("Failed to publish sensor health event", "topic", topic, "error", err)
+			continue
+		}
+
+		a.logger.Info("Sensor dropout detected",
+			"location", dropout.Location,
+			"device", device,
+			"status", status,
+			"silence_minutes", int(silence.Minutes()))
+	}
+
+	// Forget reports older than the sensor data kept in Redis
+	cutoff := a.timeManager.Now().Add(-24 * time.Hour)
+	a.stateMux.Lock()
+	for key, start := range a.reportedDropouts {
+		if start.Before(cutoff) {
+			delete(a.reportedDropouts, key)
+		}
+	}
+	a.stateMux.Unlock()
+}
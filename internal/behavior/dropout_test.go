@@ -0,0 +1,176 @@
+package behavior
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+func newDropoutTestAgent() *Agent {
+	return &Agent{
+		cfg:    config.NewConfig(),
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+}
+
+// chattyMotion returns n "on" events from the kitchen motion sensor a minute apart
+func chattyMotion(start time.Time, n int) []Event {
+	events := make([]Event, n)
+	for i := range events {
+		events[i] = Event{
+			Location:  "kitchen",
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Type:      "motion",
+			State:     "on",
+			Device:    "binary_sensor.kitchen_motion",
+		}
+	}
+	return events
+}
+
+func TestDetectDropouts(t *testing.T) {
+	t0 := time.Date(2025, 10, 17, 18, 0, 0, 0, time.UTC)
+	silentFrom := t0.Add(19 * time.Minute)
+
+	tests := []struct {
+		name      string
+		events    []Event
+		until     time.Time
+		disabled  bool
+		wantCount int
+		wantEnd   time.Time
+		wantUntil time.Time
+	}{
+		{
+			name: "sensor resumes",
+			events: append(chattyMotion(t0, 20),
+				Event{Location: "kitchen", Timestamp: silentFrom.Add(45 * time.Minute), Type: "motion", State: "off", Device: "binary_sensor.kitchen_motion"},
+			),
+			until:     t0.Add(2 * time.Hour),
+			wantCount: 1,
+			wantEnd:   silentFrom.Add(45 * time.Minute),
+		},
+		{
+			name:      "still silent with nothing else in the location",
+			events:    chattyMotion(t0, 20),
+			until:     t0.Add(2 * time.Hour),
+			wantCount: 1,
+		},
+		{
+			name: "still silent, capped at the next event in the location",
+			events: append(chattyMotion(t0, 20),
+				Event{Location: "hallway", Timestamp: silentFrom.Add(5 * time.Minute), Type: "motion", State: "on"},
+				Event{Location: "kitchen", Timestamp: silentFrom.Add(40 * time.Minute), Type: "lighting", State: "off"},
+				Event{Location: "kitchen", Timestamp: silentFrom.Add(70 * time.Minute), Type: "lighting", State: "on"},
+			),
+			until:     t0.Add(2 * time.Hour),
+			wantCount: 1,
+			wantUntil: silentFrom.Add(40 * time.Minute),
+		},
+		{
+			name:   "gap shorter than the minimum",
+			events: chattyMotion(t0, 20),
+			until:  silentFrom.Add(20 * time.Minute),
+		},
+		{
+			name:   "too few events to be chatty",
+			events: chattyMotion(t0, 5),
+			until:  t0.Add(2 * time.Hour),
+		},
+		{
+			name: "silence after the sensor turned off",
+			events: append(chattyMotion(t0, 19),
+				Event{Location: "kitchen", Timestamp: silentFrom, Type: "motion", State: "off", Device: "binary_sensor.kitchen_motion"},
+			),
+			until: t0.Add(2 * time.Hour),
+		},
+		{
+			name:     "disabled",
+			events:   chattyMotion(t0, 20),
+			until:    t0.Add(2 * time.Hour),
+			disabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := newDropoutTestAgent()
+			agent.cfg.SensorDropoutEnabled = !tt.disabled
+
+			dropouts := agent.detectDropouts(tt.events, tt.until)
+			if len(dropouts) != tt.wantCount {
+				t.Fatalf("Expected %d dropouts, got %d: %+v", tt.wantCount, len(dropouts), dropouts)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+
+			d := dropouts[0]
+			if d.Location != "kitchen" || d.Device != "binary_sensor.kitchen_motion" || !d.Start.Equal(silentFrom) {
+				t.Errorf("Expected the kitchen motion sensor silent from %s, got %+v", silentFrom, d)
+			}
+			if d.Typical != time.Minute {
+				t.Errorf("Expected a typical interval of 1m, got %s", d.Typical)
+			}
+			if !d.End.Equal(tt.wantEnd) {
+				t.Errorf("Expected end %s, got %s", tt.wantEnd, d.End)
+			}
+			if !d.Until.Equal(tt.wantUntil) {
+				t.Errorf("Expected until %s, got %s", tt.wantUntil, d.Until)
+			}
+		})
+	}
+}
+
+func TestSensorDropout_Covers(t *testing.T) {
+	start := time.Date(2025, 10, 17, 18, 0, 0, 0, time.UTC)
+	closed := SensorDropout{Location: "kitchen", Start: start, End: start.Add(time.Hour)}
+	open := SensorDropout{Location: "kitchen", Start: start}
+	capped := SensorDropout{Location: "kitchen", Start: start, Until: start.Add(time.Hour)}
+	tolerance := 5 * time.Minute
+
+	tests := []struct {
+		name     string
+		dropout  SensorDropout
+		location string
+		from, to time.Time
+		want     bool
+	}{
+		{"gap inside a closed dropout", closed, "kitchen", start, start.Add(50 * time.Minute), true},
+		{"gap ending with the recovery", closed, "kitchen", start, start.Add(time.Hour), true},
+		{"gap past the recovery", closed, "kitchen", start, start.Add(2 * time.Hour), false},
+		{"other location", closed, "hallway", start, start.Add(50 * time.Minute), false},
+		{"activity just before the silence", closed, "kitchen", start.Add(-3 * time.Minute), start.Add(50 * time.Minute), true},
+		{"gap starting well before the silence", closed, "kitchen", start.Add(-time.Hour), start.Add(50 * time.Minute), false},
+		{"open dropout with nothing since", open, "kitchen", start.Add(3 * time.Hour), start.Add(4 * time.Hour), true},
+		{"gap up to the next event in the location", capped, "kitchen", start, start.Add(time.Hour), true},
+		{"gap past the next event in the location", capped, "kitchen", start, start.Add(90 * time.Minute), false},
+		{"later gap after the next event in the location", capped, "kitchen", start.Add(2 * time.Hour), start.Add(3 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dropout.Covers(tt.location, tt.from, tt.to, tolerance); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSensorDropout_Overlaps(t *testing.T) {
+	start := time.Date(2025, 10, 17, 18, 0, 0, 0, time.UTC)
+	capped := SensorDropout{Location: "kitchen", Start: start, Until: start.Add(time.Hour)}
+
+	if !capped.Overlaps("kitchen", start.Add(30*time.Minute), start.Add(2*time.Hour)) {
+		t.Error("Expected an episode during the dropout to overlap it")
+	}
+	if capped.Overlaps("kitchen", start.Add(2*time.Hour), start.Add(3*time.Hour)) {
+		t.Error("Expected an episode after the next event in the location not to overlap")
+	}
+	if capped.Overlaps("kitchen", start.Add(-2*time.Hour), start.Add(-time.Hour)) {
+		t.Error("Expected an episode before the dropout not to overlap")
+	}
+}
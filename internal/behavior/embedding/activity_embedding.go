@@ -170,6 +170,11 @@ func normalizeSignalType(signal types.ActivitySignal) string {
 			}
 		}
 		return "lights_on"
+	case "power":
+		if appliance, ok := signal.Value["appliance"].(string); ok && appliance != "" {
+			return appliance + "_running"
+		}
+		return ""
 	case "temperature":
 		// Could normalize to comfort zones, but skip for now
 		return ""
@@ -63,7 +63,7 @@ func (s *ActivityEmbeddingStorageImpl) StoreActivityEmbedding(
 			created_at,
 			last_used_at
 		) VALUES ($1, $2, $3, 1, NOW(), NOW())
-		ON CONFLICT (fingerprint_hash)
+		ON CONFLICT (household_id, fingerprint_hash)
 		DO UPDATE SET
 			usage_count = activity_embeddings.usage_count + 1,
 			last_used_at = NOW()
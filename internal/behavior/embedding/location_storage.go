@@ -209,7 +209,7 @@ func (s *LocationEmbeddingStorage) storeInDB(ctx context.Context, data LocationE
 			movement_intensity, social_context, classification_confidence,
 			classified_by, llm_reasoning
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT (location) DO UPDATE SET
+		ON CONFLICT (household_id, location) DO UPDATE SET
 			embedding = EXCLUDED.embedding,
 			privacy_level = EXCLUDED.privacy_level,
 			function_type = EXCLUDED.function_type,
@@ -0,0 +1,85 @@
+package embedding
+
+import (
+	"math"
+	"time"
+)
+
+// standbyWatts is the draw below which a named appliance counts as off
+const standbyWatts = 10.0
+
+// PowerReading is a metered power draw from sensor:power:{location}
+type PowerReading struct {
+	Timestamp time.Time
+	Watts     float64
+	Appliance string // named by the sender, "" when unknown
+}
+
+// ApplianceSignature is the draw of an appliance while it runs
+type ApplianceSignature struct {
+	Name        string
+	MinWatts    float64
+	MaxWatts    float64
+	MinDuration time.Duration // shortest run
+	MaxDuration time.Duration // longest run (0 = unbounded)
+}
+
+// ApplianceSignatures are matched in order; the washing machine's heating
+// phase draws like a kettle but runs far longer
+var ApplianceSignatures = []ApplianceSignature{
+	{Name: "kettle", MinWatts: 1500, MaxWatts: 3200, MinDuration: 30 * time.Second, MaxDuration: 6 * time.Minute},
+	{Name: "washing_machine", MinWatts: 1500, MaxWatts: 2600, MinDuration: 8 * time.Minute},
+	{Name: "tv", MinWatts: 40, MaxWatts: 400, MinDuration: 5 * time.Minute},
+}
+
+// MatchAppliance returns the appliance the readings (oldest first) up to end
+// show running, with a confidence, or "" when none matches. An appliance
+// named by the sender wins over the signatures.
+func MatchAppliance(readings []PowerReading, end time.Time) (string, float64) {
+	if len(readings) == 0 {
+		return "", 0
+	}
+
+	latest := readings[len(readings)-1]
+	if latest.Appliance != "" {
+		if latest.Watts >= standbyWatts {
+			return latest.Appliance, 0.9
+		}
+		return "", 0
+	}
+
+	for _, sig := range ApplianceSignatures {
+		run := longestRun(readings, end, sig.MinWatts, sig.MaxWatts)
+		if run > 0 && run >= sig.MinDuration && (sig.MaxDuration == 0 || run <= sig.MaxDuration) {
+			return sig.Name, 0.6
+		}
+	}
+	return "", 0
+}
+
+// longestRun returns the longest span the draw stayed within [minWatts,
+// maxWatts]; each reading holds until the next one, the last until end
+func longestRun(readings []PowerReading, end time.Time, minWatts, maxWatts float64) time.Duration {
+	var longest, current time.Duration
+	for i, r := range readings {
+		until := end
+		if i+1 < len(readings) {
+			until = readings[i+1].Timestamp
+		}
+		if r.Watts < minWatts || r.Watts > maxWatts {
+			current = 0
+			continue
+		}
+		current += until.Sub(r.Timestamp)
+		longest = max(longest, current)
+	}
+	return longest
+}
+
+// encodePowerLevel maps a draw to 0-1 on a log scale, 3 kW and above = 1.0
+func encodePowerLevel(watts float64) float32 {
+	if watts <= 0 {
+		return 0
+	}
+	return float32(math.Min(math.Log10(1+watts)/math.Log10(1+3000), 1))
+}
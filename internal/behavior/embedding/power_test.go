@@ -0,0 +1,69 @@
+package embedding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestMatchAppliance(t *testing.T) {
+	start := time.Date(2025, 1, 15, 7, 0, 0, 0, time.UTC)
+	at := func(minutes float64, watts float64) PowerReading {
+		return PowerReading{Timestamp: start.Add(time.Duration(minutes * float64(time.Minute))), Watts: watts}
+	}
+
+	tests := []struct {
+		name     string
+		readings []PowerReading
+		end      time.Time
+		want     string
+	}{
+		{"kettle boils for three minutes", []PowerReading{at(0, 2), at(1, 2200), at(4, 3)}, start.Add(10 * time.Minute), "kettle"},
+		{"kettle still boiling", []PowerReading{at(0, 2), at(8, 2200)}, start.Add(10 * time.Minute), "kettle"},
+		{"washing machine heats for twenty minutes", []PowerReading{at(0, 2), at(2, 2000), at(22, 300)}, start.Add(25 * time.Minute), "washing_machine"},
+		{"tv on for ten minutes", []PowerReading{at(0, 1), at(5, 120)}, start.Add(15 * time.Minute), "tv"},
+		{"tv just switched on", []PowerReading{at(0, 1), at(5, 120)}, start.Add(7 * time.Minute), ""},
+		{"standby only", []PowerReading{at(0, 1), at(5, 2)}, start.Add(15 * time.Minute), ""},
+		{"no readings", nil, start, ""},
+	}
+	for _, tt := range tests {
+		got, confidence := MatchAppliance(tt.readings, tt.end)
+		assert.Equal(t, tt.want, got, tt.name)
+		assert.Equal(t, got != "", confidence > 0, tt.name)
+	}
+
+	// A named appliance wins over the signatures while drawing power
+	named := []PowerReading{{Timestamp: start, Watts: 900, Appliance: "coffee_machine"}}
+	got, confidence := MatchAppliance(named, start.Add(time.Minute))
+	assert.Equal(t, "coffee_machine", got)
+	assert.Equal(t, 0.9, confidence)
+
+	named[0].Watts = 1
+	got, _ = MatchAppliance(named, start.Add(time.Minute))
+	assert.Empty(t, got)
+}
+
+func TestEncodeSignalsPower(t *testing.T) {
+	timestamp := time.Date(2025, 1, 15, 7, 0, 0, 0, time.UTC)
+	vec := encodeSignals([]types.ActivitySignal{{
+		Type:       "power",
+		Confidence: 0.6,
+		Timestamp:  timestamp,
+		Value:      map[string]interface{}{"watts": 2200.0, "appliance": "kettle"},
+	}})
+
+	assert.Greater(t, vec[8], float32(0.9))
+	assert.Equal(t, float32(1.0), vec[9])
+	assert.InDelta(t, 0.6, vec[11], 1e-6)
+	assert.Zero(t, vec[12])
+	assert.Zero(t, vec[13])
+
+	// The fingerprint distinguishes appliance use from motion alone
+	assert.Equal(t, "kettle_running", normalizeSignalType(types.ActivitySignal{
+		Type:  "power",
+		Value: map[string]interface{}{"appliance": "kettle"},
+	}))
+}
@@ -0,0 +1,292 @@
+package embedding
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+const hoursPerWeek = 7 * 24
+
+// Global household rhythm profiler (nil = hour-of-day heuristics)
+var rhythmProfiler *RhythmProfiler
+
+// SetRhythmProfiler sets the profiler used for the household rhythm block
+func SetRhythmProfiler(profiler *RhythmProfiler) {
+	rhythmProfiler = profiler
+}
+
+// TimeManager interface for getting current time (real or virtual)
+type TimeManager interface {
+	Now() time.Time
+}
+
+// RhythmConfig configures household rhythm profiling
+type RhythmConfig struct {
+	Weeks    int            // weeks of episode history the profile covers
+	Interval time.Duration  // how often the profile is recomputed
+	Location *time.Location // local time hours are profiled in (default time.Local)
+}
+
+// RhythmProfile is the household's occupancy by hour of week (0 = Monday
+// 00:00 local time): the average fraction of each hour a location had an
+// active episode
+type RhythmProfile struct {
+	Locations  map[string]*[hoursPerWeek]float64
+	Household  [hoursPerWeek]float64 // location occupancy summed, capped at 1
+	Location   *time.Location
+	ComputedAt time.Time
+}
+
+// RhythmProfiler recomputes the household rhythm profile from episode history
+type RhythmProfiler struct {
+	config      RhythmConfig
+	db          *sql.DB
+	logger      *slog.Logger
+	timeManager TimeManager
+
+	mu      sync.RWMutex
+	profile *RhythmProfile
+}
+
+// NewRhythmProfiler creates a profiler; the profile is empty until the first Refresh
+func NewRhythmProfiler(config RhythmConfig, db *sql.DB, timeManager TimeManager, logger *slog.Logger) *RhythmProfiler {
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+	return &RhythmProfiler{
+		config:      config,
+		db:          db,
+		logger:      logger.With("component", "rhythm_profiler"),
+		timeManager: timeManager,
+	}
+}
+
+// Profile returns the latest profile, nil before the first successful refresh
+func (p *RhythmProfiler) Profile() *RhythmProfile {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.profile
+}
+
+// Start refreshes once, then on the configured interval
+func (p *RhythmProfiler) Start(ctx context.Context) error {
+	p.logger.Info("Rhythm profiler started",
+		"weeks", p.config.Weeks,
+		"interval", p.config.Interval)
+
+	if err := p.Refresh(ctx); err != nil {
+		p.logger.Error("Rhythm profile refresh failed", "error", err)
+	}
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := p.Refresh(ctx); err != nil {
+			p.logger.Error("Rhythm profile refresh failed", "error", err)
+		}
+	}
+}
+
+// Refresh recomputes the profile over the last Weeks full weeks of episodes.
+// Without episodes the previous profile is kept.
+func (p *RhythmProfiler) Refresh(ctx context.Context) error {
+	now := p.timeManager.Now().In(p.config.Location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, p.config.Location)
+	from := to.AddDate(0, 0, -7*p.config.Weeks)
+
+	occupied, err := p.occupiedSeconds(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	if len(occupied) == 0 {
+		p.logger.Info("No episodes for rhythm profile yet", "from", from, "to", to)
+		return nil
+	}
+
+	profile := buildRhythmProfile(occupied, from, to, p.config.Location)
+	profile.ComputedAt = now
+
+	p.mu.Lock()
+	p.profile = profile
+	p.mu.Unlock()
+
+	p.logger.Info("Rhythm profile refreshed",
+		"locations", len(profile.Locations),
+		"from", from,
+		"to", to)
+	return nil
+}
+
+// occupiedSeconds returns occupied seconds per location and local hour of week
+func (p *RhythmProfiler) occupiedSeconds(ctx context.Context, from, to time.Time) (map[string]*[hoursPerWeek]float64, error) {
+	// Hours are bucketed in UTC and mapped to local hour of week below so DST
+	// shifts land in the right slot
+	query := `
+		WITH hours AS (
+			SELECT generate_series(date_trunc('hour', $1::timestamptz), $2::timestamptz - interval '1 hour', interval '1 hour') AS hour
+		),
+		eps AS (
+			SELECT
+				location,
+				started_at_text::timestamptz AS started,
+				COALESCE(ended_at_text::timestamptz, LEAST(NOW(), $2::timestamptz)) AS ended
+			FROM behavioral_episodes
+			WHERE location IS NOT NULL
+			  AND started_at_text::timestamptz < $2
+			  AND COALESCE(ended_at_text::timestamptz, NOW()) > $1
+		)
+		SELECT
+			eps.location,
+			h.hour,
+			LEAST(3600, SUM(EXTRACT(EPOCH FROM (
+				LEAST(eps.ended, h.hour + interval '1 hour') - GREATEST(eps.started, h.hour)
+			))))
+		FROM hours h
+		JOIN eps ON eps.started < h.hour + interval '1 hour' AND eps.ended > h.hour
+		GROUP BY eps.location, h.hour
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episode occupancy: %w", err)
+	}
+	defer rows.Close()
+
+	occupied := make(map[string]*[hoursPerWeek]float64)
+	for rows.Next() {
+		var location string
+		var hour time.Time
+		var seconds float64
+		if err := rows.Scan(&location, &hour, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan episode occupancy: %w", err)
+		}
+		if occupied[location] == nil {
+			occupied[location] = &[hoursPerWeek]float64{}
+		}
+		occupied[location][hourOfWeek(hour.In(p.config.Location))] += seconds
+	}
+
+	return occupied, rows.Err()
+}
+
+// buildRhythmProfile turns occupied seconds per hour of week over [from, to)
+// into occupancy fractions
+func buildRhythmProfile(occupied map[string]*[hoursPerWeek]float64, from, to time.Time, loc *time.Location) *RhythmProfile {
+	var samples [hoursPerWeek]int
+	for t := from.Truncate(time.Hour); t.Before(to); t = t.Add(time.Hour) {
+		samples[hourOfWeek(t.In(loc))]++
+	}
+
+	profile := &RhythmProfile{
+		Locations: make(map[string]*[hoursPerWeek]float64, len(occupied)),
+		Location:  loc,
+	}
+	for location, seconds := range occupied {
+		row := &[hoursPerWeek]float64{}
+		for h := range row {
+			if samples[h] > 0 {
+				row[h] = math.Min(seconds[h]/(3600*float64(samples[h])), 1)
+			}
+			profile.Household[h] = math.Min(profile.Household[h]+row[h], 1)
+		}
+		profile.Locations[location] = row
+	}
+	return profile
+}
+
+// hourOfWeek returns 0-167 with Monday 00:00 as 0
+func hourOfWeek(t time.Time) int {
+	return ((int(t.Weekday())+6)%7)*24 + t.Hour()
+}
+
+// rhythmPeriods are the waking, active day, evening and sleep hours of the
+// heuristic encoding, as [start, end) local hours
+var rhythmPeriods = [4][2]int{{5, 9}, {9, 18}, {18, 22}, {22, 29}}
+
+// encodeRhythmProfile encodes the learned household rhythm at timestamp:
+//
+// [0-1]:   household and location occupancy this hour of week
+// [2-5]:   location, then household occupancy the hour before and after
+// [6]:     location's share of household occupancy this hour
+// [7]:     location occupancy relative to its busiest hour
+// [8-9]:   location and household occupancy this hour, averaged over the week
+// [10-11]: location and household occupancy this day, averaged over its hours
+// [12-15]: location occupancy in the waking, day, evening and sleep periods of this day
+func encodeRhythmProfile(profile *RhythmProfile, timestamp time.Time, location string) []float32 {
+	vec := make([]float32, 16)
+
+	local := timestamp.In(profile.Location)
+	h := hourOfWeek(local)
+	day := h - local.Hour()
+	prev, next := (h+hoursPerWeek-1)%hoursPerWeek, (h+1)%hoursPerWeek
+
+	loc := profile.Locations[location]
+	if loc == nil {
+		// Never occupied in the profiled weeks
+		loc = &[hoursPerWeek]float64{}
+	}
+	house := &profile.Household
+
+	vec[0] = float32(house[h])
+	vec[1] = float32(loc[h])
+	vec[2] = float32(loc[prev])
+	vec[3] = float32(loc[next])
+	vec[4] = float32(house[prev])
+	vec[5] = float32(house[next])
+	if house[h] > 0 {
+		vec[6] = float32(math.Min(loc[h]/house[h], 1))
+	}
+
+	var peak float64
+	for _, v := range loc {
+		peak = math.Max(peak, v)
+	}
+	if peak > 0 {
+		vec[7] = float32(loc[h] / peak)
+	}
+
+	vec[8] = float32(hourAverage(loc, local.Hour()))
+	vec[9] = float32(hourAverage(house, local.Hour()))
+	vec[10] = float32(rangeAverage(loc, day, 0, 24))
+	vec[11] = float32(rangeAverage(house, day, 0, 24))
+
+	for i, period := range rhythmPeriods {
+		vec[12+i] = float32(rangeAverage(loc, day, period[0], period[1]))
+	}
+
+	return vec
+}
+
+// hourAverage averages hour of day over the seven days of the week
+func hourAverage(profile *[hoursPerWeek]float64, hour int) float64 {
+	var sum float64
+	for d := 0; d < 7; d++ {
+		sum += profile[d*24+hour]
+	}
+	return sum / 7
+}
+
+// rangeAverage averages hours [start, end) of the day starting at hour of week
+// day; hours past midnight run into the next day
+func rangeAverage(profile *[hoursPerWeek]float64, day, start, end int) float64 {
+	var sum float64
+	for hour := start; hour < end; hour++ {
+		sum += profile[(day+hour)%hoursPerWeek]
+	}
+	return sum / float64(end-start)
+}
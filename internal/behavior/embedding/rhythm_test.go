@@ -0,0 +1,89 @@
+package embedding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRhythmProfile(t *testing.T) {
+	// Two weeks starting Monday 2025-01-13
+	from := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 14)
+
+	kitchen := &[hoursPerWeek]float64{}
+	kitchen[7] = 2 * 3600 // Monday 07:00, fully occupied both weeks
+	kitchen[8] = 1800     // Monday 08:00, half an hour in one week
+	bedroom := &[hoursPerWeek]float64{}
+	bedroom[7] = 3600
+
+	profile := buildRhythmProfile(map[string]*[hoursPerWeek]float64{
+		"kitchen": kitchen,
+		"bedroom": bedroom,
+	}, from, to, time.UTC)
+
+	require.Len(t, profile.Locations, 2)
+	assert.InDelta(t, 1.0, profile.Locations["kitchen"][7], 1e-9)
+	assert.InDelta(t, 0.25, profile.Locations["kitchen"][8], 1e-9)
+	assert.InDelta(t, 0.5, profile.Locations["bedroom"][7], 1e-9)
+
+	// Household occupancy is capped at 1
+	assert.InDelta(t, 1.0, profile.Household[7], 1e-9)
+	assert.InDelta(t, 0.25, profile.Household[8], 1e-9)
+	assert.Zero(t, profile.Household[9])
+}
+
+func TestEncodeRhythmProfile(t *testing.T) {
+	profile := &RhythmProfile{
+		Locations: map[string]*[hoursPerWeek]float64{"kitchen": {}},
+		Location:  time.UTC,
+	}
+	profile.Locations["kitchen"][7] = 0.8 // Monday 07:00
+	profile.Locations["kitchen"][8] = 0.4
+	profile.Household[7] = 1.0
+	profile.Household[8] = 0.5
+
+	monday7 := time.Date(2025, 1, 13, 7, 30, 0, 0, time.UTC)
+	vec := encodeRhythmProfile(profile, monday7, "kitchen")
+	require.Len(t, vec, 16)
+
+	assert.InDelta(t, 1.0, vec[0], 1e-6)   // household this hour
+	assert.InDelta(t, 0.8, vec[1], 1e-6)   // kitchen this hour
+	assert.InDelta(t, 0.4, vec[3], 1e-6)   // kitchen next hour
+	assert.InDelta(t, 0.8, vec[6], 1e-6)   // kitchen's share of the household
+	assert.InDelta(t, 1.0, vec[7], 1e-6)   // kitchen's busiest hour
+	assert.InDelta(t, 0.8/7, vec[8], 1e-6) // 07:00 averaged over the week
+	assert.InDelta(t, (0.8+0.4)/24, vec[10], 1e-6)
+	assert.InDelta(t, (0.8+0.4)/4, vec[12], 1e-6) // waking period (05-09)
+
+	// The profile is learned, so a quiet hour encodes differently
+	tuesday7 := monday7.AddDate(0, 0, 1)
+	assert.NotEqual(t, vec, encodeRhythmProfile(profile, tuesday7, "kitchen"))
+
+	// Locations never occupied only carry the household dimensions
+	vec = encodeRhythmProfile(profile, monday7, "garage")
+	assert.InDelta(t, 1.0, vec[0], 1e-6)
+	assert.Zero(t, vec[1])
+	assert.Zero(t, vec[7])
+}
+
+func TestHouseholdRhythmUsesProfile(t *testing.T) {
+	profile := &RhythmProfile{
+		Locations: map[string]*[hoursPerWeek]float64{"kitchen": {}},
+		Location:  time.UTC,
+	}
+	profile.Locations["kitchen"][7] = 0.8
+	profiler := &RhythmProfiler{profile: profile}
+
+	SetRhythmProfiler(profiler)
+	defer SetRhythmProfiler(nil)
+
+	monday7 := time.Date(2025, 1, 13, 7, 0, 0, 0, time.UTC)
+	assert.Equal(t, encodeRhythmProfile(profile, monday7, "kitchen"), encodeHouseholdRhythm(monday7, "kitchen"))
+
+	// Without a profile the heuristics apply (waking period)
+	SetRhythmProfiler(nil)
+	assert.Equal(t, float32(1.0), encodeHouseholdRhythm(monday7, "kitchen")[0])
+}
@@ -3,13 +3,20 @@ package embedding
 import (
 	"context"
 	"math"
+	"slices"
 	"time"
 
 	"github.com/pgvector/pgvector-go"
 
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/internal/weather"
 )
 
+// Version identifies the embedding layout and encoders. Bump it whenever a
+// block moves or an encoder changes, then re-embed stored anchors
+// (behavior-agent --reembed-anchors) so old and new vectors are not compared.
+const Version = 2
+
 // Global location embedding storage (set during initialization)
 var locationEmbeddingStorage *LocationEmbeddingStorage
 
@@ -76,7 +83,7 @@ func ComputeSemanticEmbedding(
 	signalVec := encodeSignals(signals)
 	copy(embedding[60:80], signalVec)
 
-	// [80-95]: Household rhythm (derived from time patterns)
+	// [80-95]: Household rhythm (learned from episode history when profiled)
 	rhythmVec := encodeHouseholdRhythm(timestamp, location)
 	copy(embedding[80:96], rhythmVec)
 
@@ -168,30 +175,52 @@ func SetLocationEmbeddingStorage(storage *LocationEmbeddingStorage) {
 func encodeWeather(context map[string]interface{}) []float32 {
 	vec := make([]float32, 16)
 
-	// Extract weather info from context
-	if weather, ok := context["weather"].(map[string]interface{}); ok {
+	// Extract weather info from context (written by the weather agent)
+	if current, ok := context["weather"].(map[string]interface{}); ok {
 		// Brightness level (0.0-1.0)
-		if brightness, ok := weather["brightness"].(float64); ok {
+		if brightness, ok := current["brightness"].(float64); ok {
 			vec[0] = float32(brightness)
 		}
 
 		// Rain/snow (0.0-1.0)
-		if precip, ok := weather["precipitation"].(float64); ok {
+		if precip, ok := current["precipitation"].(float64); ok {
 			vec[1] = float32(precip)
 		}
 
 		// Temperature normalized (-1.0 to 1.0, -20°C to 40°C)
-		if temp, ok := weather["temperature"].(float64); ok {
+		if temp, ok := current["temperature"].(float64); ok {
 			vec[2] = float32((temp+20)/60*2 - 1)
 		}
 
 		// Cloudiness (0.0-1.0)
-		if clouds, ok := weather["cloudiness"].(float64); ok {
+		if clouds, ok := current["cloudiness"].(float64); ok {
 			vec[3] = float32(clouds)
 		}
+
+		// Wind speed (0.0-1.0, capped at 20 m/s)
+		if wind, ok := current["wind_speed"].(float64); ok {
+			vec[4] = float32(math.Min(wind, 20) / 20)
+		}
+
+		// Relative humidity (0.0-1.0)
+		if humidity, ok := current["humidity"].(float64); ok {
+			vec[5] = float32(humidity)
+		}
+
+		// Daylight outside
+		if isDay, ok := current["is_day"].(bool); ok && isDay {
+			vec[6] = 1.0
+		}
+
+		// Condition one-hot [7-14]
+		if condition, ok := current["condition"].(string); ok {
+			if i := slices.Index(weather.Conditions, condition); i >= 0 {
+				vec[7+i] = 1.0
+			}
+		}
 	}
 
-	// Rest zeros for now (future: wind, humidity, etc.)
+	// [15] reserved
 	return vec
 }
 
@@ -278,6 +307,22 @@ func encodeSignals(signals []types.ActivitySignal) []float32 {
 			presenceDetected = true
 			vec[2] = float32(signal.Confidence)
 
+		case "sleep":
+			// Anchor falls inside a detected sleep period
+			vec[7] = float32(signal.Confidence)
+
+		case "power":
+			// Metered draw and the appliance signature it matched
+			if watts, ok := signal.Value["watts"].(float64); ok {
+				vec[8] = encodePowerLevel(watts)
+			}
+			if appliance, ok := signal.Value["appliance"].(string); ok && appliance != "" {
+				vec[9] = 1.0
+				if dim, ok := applianceDims[appliance]; ok {
+					vec[dim] = float32(signal.Confidence)
+				}
+			}
+
 		case "lighting":
 			// Already handled in encodeLighting
 			continue
@@ -308,6 +353,13 @@ func encodeSignals(signals []types.ActivitySignal) []float32 {
 	return vec
 }
 
+// applianceDims are the activity signal dimensions of known appliances
+var applianceDims = map[string]int{
+	"kettle":          11,
+	"tv":              12,
+	"washing_machine": 13,
+}
+
 // encodeMediaType converts media type to scalar
 func encodeMediaType(mediaType string) float32 {
 	switch mediaType {
@@ -322,8 +374,13 @@ func encodeMediaType(mediaType string) float32 {
 	}
 }
 
-// encodeHouseholdRhythm encodes typical activity patterns by time and location
+// encodeHouseholdRhythm encodes typical activity patterns by time and location,
+// from the household's rhythm profile when one is available
 func encodeHouseholdRhythm(timestamp time.Time, location string) []float32 {
+	if profile := rhythmProfiler.Profile(); profile != nil {
+		return encodeRhythmProfile(profile, timestamp, location)
+	}
+
 	vec := make([]float32, 16)
 
 	hour := timestamp.Hour()
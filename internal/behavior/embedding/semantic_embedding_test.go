@@ -191,6 +191,23 @@ func TestWeatherContextEncoding(t *testing.T) {
 	assert.Less(t, similarity, 0.99, "Weather context should affect embedding")
 }
 
+func TestEncodeWeather_WeatherAgentContext(t *testing.T) {
+	vec := encodeWeather(map[string]interface{}{
+		"weather": map[string]interface{}{
+			"wind_speed": 30.0,
+			"humidity":   0.6,
+			"is_day":     true,
+			"condition":  "rain",
+		},
+	})
+
+	assert.Equal(t, float32(1.0), vec[4], "wind speed is capped at 20 m/s")
+	assert.InDelta(t, 0.6, vec[5], 1e-6)
+	assert.Equal(t, float32(1.0), vec[6])
+	assert.Equal(t, float32(1.0), vec[12], "rain is the sixth condition")
+	assert.Equal(t, float32(0), vec[7])
+}
+
 func TestLightingSignalsEncoding(t *testing.T) {
 	location := "living_room"
 	timestamp := time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)
@@ -0,0 +1,193 @@
+package behavior
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// fakeDB is a scripted database/sql driver. Each statement is answered by
+// the first rule whose fragment it contains; statements without a rule
+// succeed and return no rows.
+type fakeDB struct {
+	mu         sync.Mutex
+	rules      []*fakeRule
+	statements []fakeStatement
+	commits    int
+	rollbacks  int
+}
+
+// fakeRule answers statements containing fragment with rows or err
+type fakeRule struct {
+	fragment string
+	columns  []string
+	rows     [][]driver.Value
+	err      error
+}
+
+// fakeStatement is an executed statement
+type fakeStatement struct {
+	query string
+	args  []driver.Value
+	inTx  bool
+}
+
+// on adds a rule answering statements containing fragment
+func (f *fakeDB) on(fragment string, columns []string, rows ...[]driver.Value) *fakeRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rule := &fakeRule{fragment: fragment, columns: columns, rows: rows}
+	f.rules = append(f.rules, rule)
+	return rule
+}
+
+// fail makes statements containing fragment return err
+func (f *fakeDB) fail(fragment string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, &fakeRule{fragment: fragment, err: err})
+}
+
+// executed returns the statements containing fragment
+func (f *fakeDB) executed(fragment string) []fakeStatement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []fakeStatement
+	for _, stmt := range f.statements {
+		if strings.Contains(stmt.query, fragment) {
+			matched = append(matched, stmt)
+		}
+	}
+	return matched
+}
+
+// client returns a postgres.Client backed by the fake
+func (f *fakeDB) client() postgres.Client {
+	return &fakePostgres{db: sql.OpenDB(fakeConnector{f})}
+}
+
+func (f *fakeDB) run(query string, args []driver.NamedValue, inTx bool) (*fakeRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	f.statements = append(f.statements, fakeStatement{query: query, args: values, inTx: inTx})
+
+	for _, rule := range f.rules {
+		if strings.Contains(query, rule.fragment) {
+			return rule, rule.err
+		}
+	}
+	return &fakeRule{}, nil
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: c.db}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db   *fakeDB
+	inTx bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	return fakeTx{c}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rule, err := c.db.run(query, args, c.inTx)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(len(rule.rows)), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rule, err := c.db.run(query, args, c.inTx)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: rule.columns, rows: rule.rows}, nil
+}
+
+type fakeTx struct{ conn *fakeConn }
+
+func (t fakeTx) Commit() error {
+	t.conn.inTx = false
+	t.conn.db.mu.Lock()
+	t.conn.db.commits++
+	t.conn.db.mu.Unlock()
+	return nil
+}
+
+func (t fakeTx) Rollback() error {
+	t.conn.inTx = false
+	t.conn.db.mu.Lock()
+	t.conn.db.rollbacks++
+	t.conn.db.mu.Unlock()
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// fakePostgres is a postgres.Client over a fakeDB
+type fakePostgres struct {
+	db *sql.DB
+}
+
+func (p *fakePostgres) Connect(ctx context.Context) error { return nil }
+func (p *fakePostgres) Disconnect() error                 { return p.db.Close() }
+func (p *fakePostgres) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.db.ExecContext(ctx, query, args...)
+}
+func (p *fakePostgres) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.db.QueryContext(ctx, query, args...)
+}
+func (p *fakePostgres) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.db.QueryRowContext(ctx, query, args...)
+}
+func (p *fakePostgres) HealthCheck(ctx context.Context) (*postgres.HealthStatus, error) {
+	return &postgres.HealthStatus{Connected: true}, nil
+}
+
+func (p *fakePostgres) Transaction(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
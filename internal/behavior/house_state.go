@@ -0,0 +1,406 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// House states published on automation/behavior/house_state
+const (
+	HouseStateHome     = "home"
+	HouseStateAway     = "away"
+	HouseStateVacation = "vacation"
+)
+
+const (
+	houseStateTopic = "automation/behavior/house_state"
+
+	// housePresenceTopic is the occupancy agent's whole-house summary
+	housePresenceTopic = "automation/presence/house"
+
+	// arrivalConfirmWindow is how close together activity events must be to end an away period.
+	// A single isolated event (pet, automated device) during away is not treated as arrival.
+	arrivalConfirmWindow = 10 * time.Minute
+
+	// awayPeriodRetention bounds how long closed away periods are kept for episode suppression
+	awayPeriodRetention = 7 * 24 * time.Hour
+)
+
+// awayPeriod is a span of whole-house inactivity. End is zero while the period is open.
+type awayPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// HouseStateDetector tracks whole-house activity and classifies the household
+// as home, away, or on vacation based on how long the house has been inactive.
+type HouseStateDetector struct {
+	cfg         *config.Config
+	mqtt        mqtt.Client
+	timeManager *clock.TimeManager
+	logger      *slog.Logger
+
+	mu             sync.RWMutex
+	state          string
+	confidence     float64
+	since          time.Time
+	lastActivity   time.Time
+	pendingArrival time.Time // first unconfirmed activity while away
+	anyoneHome     bool      // occupancy agent reports an occupied room
+	awayPeriods    []awayPeriod
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewHouseStateDetector creates a new house state detector
+func NewHouseStateDetector(cfg *config.Config, mqttClient mqtt.Client, timeManager *clock.TimeManager, logger *slog.Logger) *HouseStateDetector {
+	return &HouseStateDetector{
+		cfg:         cfg,
+		mqtt:        mqttClient,
+		timeManager: timeManager,
+		logger:      logger.With("component", "house_state"),
+		state:       HouseStateHome,
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start subscribes to sensor triggers and begins periodic evaluation
+func (d *HouseStateDetector) Start(ctx context.Context) error {
+	topics := []string{
+		"automation/sensor/motion/+",
+		"automation/sensor/lighting/+",
+		"automation/sensor/media/+",
+		"automation/sensor/presence/+",
+	}
+	for _, topic := range topics {
+		if err := d.mqtt.Subscribe(topic, 0, d.handleSensorMessage); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+		}
+	}
+	if err := d.mqtt.Subscribe(housePresenceTopic, 0, d.handleHousePresence); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", housePresenceTopic, err)
+	}
+
+	d.logger.Info("House state detector started",
+		"away_after", d.cfg.HouseAwayThreshold,
+		"vacation_after", d.cfg.HouseVacationThreshold,
+		"check_interval", d.cfg.HouseStateCheckInterval)
+
+	go d.evaluationLoop(ctx)
+	return nil
+}
+
+// Stop halts periodic evaluation
+func (d *HouseStateDetector) Stop() {
+	d.stopOnce.Do(func() { close(d.stopChan) })
+}
+
+// State returns the current house state and its confidence
+func (d *HouseStateDetector) State() (string, float64) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.state, d.confidence
+}
+
+// IsAway reports whether the house is currently away or on vacation
+func (d *HouseStateDetector) IsAway() bool {
+	state, _ := d.State()
+	return state != HouseStateHome
+}
+
+// IsAwayAt reports whether ts falls inside a detected away period.
+// Used to suppress episode creation from stray events while nobody is home.
+func (d *HouseStateDetector) IsAwayAt(ts time.Time) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, period := range d.awayPeriods {
+		// Start is the last activity before leaving, which was not away
+		if !ts.After(period.Start) {
+			continue
+		}
+		if period.End.IsZero() || ts.Before(period.End) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *HouseStateDetector) evaluationLoop(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.HouseStateCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stopChan:
+			return
+		case <-ticker.C:
+			d.evaluate()
+		}
+	}
+}
+
+// handleSensorMessage records activity from collector sensor triggers
+func (d *HouseStateDetector) handleSensorMessage(msg mqtt.Message) {
+	// Topic: automation/sensor/{type}/{location}
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) != 4 {
+		return
+	}
+	sensorType := parts[2]
+
+	var data struct {
+		Timestamp string `json:"timestamp"`
+		State     string `json:"state"`
+		Source    string `json:"source"`
+	}
+	if err := json.Unmarshal(msg.Payload(), &data); err != nil {
+		d.logger.Debug("Failed to parse sensor message", "topic", msg.Topic(), "error", err)
+		return
+	}
+
+	if !isHouseActivity(sensorType, data.State, data.Source) {
+		return
+	}
+
+	ts, err := time.Parse(time.RFC3339, data.Timestamp)
+	if err != nil {
+		ts = d.timeManager.Now()
+	}
+
+	// Presence "home" is an explicit arrival and needs no confirmation
+	d.recordActivity(ts, sensorType == "presence")
+}
+
+// handleHousePresence tracks whether any room is occupied. Someone sitting
+// still (reading, sleeping) produces no sensor activity, so the house is not
+// declared away while a room is still occupied.
+func (d *HouseStateDetector) handleHousePresence(msg mqtt.Message) {
+	var presence struct {
+		AnyoneHome bool      `json:"anyone_home"`
+		Timestamp  time.Time `json:"timestamp"`
+	}
+	if _, err := events.Unmarshal(msg.Payload(), &presence); err != nil {
+		d.logger.Debug("Failed to parse house presence", "error", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The house emptied now, not at the last sensor event
+	if d.anyoneHome && !presence.AnyoneHome && d.state == HouseStateHome {
+		if ts := d.timeManager.Now(); ts.After(d.lastActivity) {
+			d.lastActivity = ts
+		}
+	}
+	d.anyoneHome = presence.AnyoneHome
+}
+
+// isHouseActivity reports whether a sensor reading indicates someone is in the house
+func isHouseActivity(sensorType, state, source string) bool {
+	switch sensorType {
+	case "motion":
+		return state == "on"
+	case "lighting":
+		// Automated lighting changes are our own decisions, not occupant activity
+		return source == "manual"
+	case "media":
+		return state == "playing"
+	case "presence":
+		return isPresentState(state)
+	default:
+		return false
+	}
+}
+
+// recordActivity updates the last activity time and handles arrival while away
+func (d *HouseStateDetector) recordActivity(ts time.Time, explicitArrival bool) {
+	d.mu.Lock()
+
+	if d.state == HouseStateHome {
+		if ts.After(d.lastActivity) {
+			d.lastActivity = ts
+		}
+		if d.since.IsZero() {
+			d.since = ts
+		}
+		d.mu.Unlock()
+		return
+	}
+
+	// While away, require a second event within arrivalConfirmWindow (or explicit presence)
+	if !explicitArrival {
+		if d.pendingArrival.IsZero() || ts.Sub(d.pendingArrival) > arrivalConfirmWindow {
+			d.pendingArrival = ts
+			d.mu.Unlock()
+			d.logger.Debug("Activity while away, waiting for confirmation",
+				"timestamp", ts.Format(time.RFC3339))
+			return
+		}
+		ts = d.pendingArrival
+	}
+
+	previous := d.state
+	d.lastActivity = ts
+	d.closeAwayPeriod(ts)
+	d.state = HouseStateHome
+	d.confidence = 0.9
+	if explicitArrival {
+		d.confidence = 1.0
+	}
+	d.since = ts
+	d.pendingArrival = time.Time{}
+	d.mu.Unlock()
+
+	d.logger.Info("Household returned home",
+		"previous_state", previous,
+		"arrived_at", ts.Format(time.RFC3339))
+	d.publish()
+}
+
+// evaluate classifies the house state from the current inactivity duration
+func (d *HouseStateDetector) evaluate() {
+	d.mu.Lock()
+
+	// Nothing observed yet - don't guess
+	if d.lastActivity.IsZero() {
+		d.mu.Unlock()
+		return
+	}
+
+	// An occupied room keeps the house home regardless of idle time
+	if d.anyoneHome && d.state == HouseStateHome {
+		d.mu.Unlock()
+		return
+	}
+
+	idle := d.timeManager.Now().Sub(d.lastActivity)
+	if idle < 0 {
+		idle = 0
+	}
+	state, confidence := classifyHouseState(idle, d.cfg.HouseAwayThreshold, d.cfg.HouseVacationThreshold)
+
+	// Only escalate here; returning home is driven by activity
+	if state == d.state {
+		d.confidence = confidence
+	}
+	if state == HouseStateHome || state == d.state {
+		d.mu.Unlock()
+		return
+	}
+
+	previous := d.state
+	if previous == HouseStateHome {
+		d.awayPeriods = append(d.awayPeriods, awayPeriod{Start: d.lastActivity})
+		d.since = d.lastActivity
+	}
+	d.state = state
+	d.confidence = confidence
+	d.pruneAwayPeriods()
+	d.mu.Unlock()
+
+	d.logger.Info("House state changed",
+		"previous_state", previous,
+		"state", state,
+		"confidence", confidence,
+		"idle", idle.Round(time.Minute))
+	d.publish()
+}
+
+// classifyHouseState maps an inactivity duration to a house state with confidence.
+// Confidence grows with inactivity: away starts at 0.6 and approaches 0.9 as it nears
+// the vacation threshold; vacation starts at 0.9 and approaches 0.99.
+func classifyHouseState(idle, awayThreshold, vacationThreshold time.Duration) (string, float64) {
+	switch {
+	case idle >= vacationThreshold:
+		extra := float64(idle-vacationThreshold) / float64(vacationThreshold)
+		return HouseStateVacation, min(0.9+0.09*extra, 0.99)
+	case idle >= awayThreshold:
+		progress := float64(idle-awayThreshold) / float64(vacationThreshold-awayThreshold)
+		return HouseStateAway, 0.6 + 0.3*progress
+	default:
+		// Recent activity: confident someone is home, fading as the idle time approaches away
+		return HouseStateHome, 0.9 - 0.3*float64(idle)/float64(awayThreshold)
+	}
+}
+
+// closeAwayPeriod ends the open away period (caller holds the lock)
+func (d *HouseStateDetector) closeAwayPeriod(end time.Time) {
+	for i := range d.awayPeriods {
+		if d.awayPeriods[i].End.IsZero() {
+			d.awayPeriods[i].End = end
+		}
+	}
+}
+
+// pruneAwayPeriods drops closed periods past retention (caller holds the lock)
+func (d *HouseStateDetector) pruneAwayPeriods() {
+	cutoff := d.timeManager.Now().Add(-awayPeriodRetention)
+	kept := d.awayPeriods[:0]
+	for _, period := range d.awayPeriods {
+		if period.End.IsZero() || period.End.After(cutoff) {
+			kept = append(kept, period)
+		}
+	}
+	d.awayPeriods = kept
+}
+
+// publish sends the current house state as a retained message
+func (d *HouseStateDetector) publish() {
+	d.mu.RLock()
+	payload, err := events.Marshal(events.ProducerBehavior, map[string]interface{}{
+		"state":         d.state,
+		"confidence":    d.confidence,
+		"since":         d.since.Format(time.RFC3339),
+		"last_activity": d.lastActivity.Format(time.RFC3339),
+		"timestamp":     d.timeManager.Now().Format(time.RFC3339),
+	})
+	d.mu.RUnlock()
+	if err != nil {
+		d.logger.Error("Failed to marshal house state", "error", err)
+		return
+	}
+
+	if err := d.mqtt.Publish(houseStateTopic, 0, true, payload); err != nil {
+		d.logger.Error("Failed to publish house state", "error", err)
+	}
+}
+
+// filterAwayEvents removes events that occurred while the household was away
+func (a *Agent) filterAwayEvents(events []Event) []Event {
+	if a.houseState == nil {
+		return events
+	}
+
+	kept := events[:0]
+	suppressed := 0
+	for _, event := range events {
+		if a.houseState.IsAwayAt(event.Timestamp) {
+			suppressed++
+			continue
+		}
+		kept = append(kept, event)
+	}
+
+	if suppressed > 0 {
+		a.logger.Info("Suppressed events during away periods",
+			"suppressed", suppressed,
+			"remaining", len(kept))
+	}
+
+	return kept
+}
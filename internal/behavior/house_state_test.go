@@ -0,0 +1,211 @@
+package behavior
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// publishCounter counts published messages and fails them with err
+type publishCounter struct {
+	published int
+	topics    []string
+	err       error
+}
+
+func (p *publishCounter) Connect(ctx context.Context) error { return nil }
+func (p *publishCounter) Disconnect()                       {}
+func (p *publishCounter) IsConnected() bool                 { return true }
+func (p *publishCounter) Subscribe(topic string, qos byte, handler mqtt.MessageHandler) error {
+	return nil
+}
+func (p *publishCounter) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published++
+	p.topics = append(p.topics, topic)
+	return nil
+}
+
+func newTestHouseStateDetector(client mqtt.Client) *HouseStateDetector {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewHouseStateDetector(config.NewConfig(), client, clock.NewTimeManager(logger), logger)
+}
+
+func TestClassifyHouseState(t *testing.T) {
+	away, vacation := 4*time.Hour, 48*time.Hour
+
+	tests := []struct {
+		idle           time.Duration
+		wantState      string
+		wantConfidence float64
+	}{
+		{0, HouseStateHome, 0.9},
+		{2 * time.Hour, HouseStateHome, 0.75},
+		{4 * time.Hour, HouseStateAway, 0.6},
+		{26 * time.Hour, HouseStateAway, 0.75},
+		{48 * time.Hour, HouseStateVacation, 0.9},
+		{72 * time.Hour, HouseStateVacation, 0.945},
+		{30 * 24 * time.Hour, HouseStateVacation, 0.99},
+	}
+
+	for _, tt := range tests {
+		state, confidence := classifyHouseState(tt.idle, away, vacation)
+		if state != tt.wantState {
+			t.Errorf("idle %s: expected state %s, got %s", tt.idle, tt.wantState, state)
+		}
+		if math.Abs(confidence-tt.wantConfidence) > 1e-9 {
+			t.Errorf("idle %s: expected confidence %.3f, got %.3f", tt.idle, tt.wantConfidence, confidence)
+		}
+	}
+}
+
+func TestRecordActivity_WhileHome(t *testing.T) {
+	client := &publishCounter{}
+	d := newTestHouseStateDetector(client)
+	t0 := time.Date(2025, 10, 17, 8, 0, 0, 0, time.UTC)
+
+	d.recordActivity(t0, false)
+	d.recordActivity(t0.Add(time.Hour), false)
+	d.recordActivity(t0.Add(30*time.Minute), false) // late event
+
+	if !d.lastActivity.Equal(t0.Add(time.Hour)) {
+		t.Errorf("Expected last activity %s, got %s", t0.Add(time.Hour), d.lastActivity)
+	}
+	if !d.since.Equal(t0) {
+		t.Errorf("Expected since %s, got %s", t0, d.since)
+	}
+	if client.published != 0 {
+		t.Errorf("Expected no publish while home, got %d", client.published)
+	}
+}
+
+func TestRecordActivity_WhileAway(t *testing.T) {
+	t0 := time.Date(2025, 10, 17, 8, 0, 0, 0, time.UTC)
+	leftAt := t0.Add(-6 * time.Hour)
+
+	tests := []struct {
+		name       string
+		events     []time.Time
+		explicit   bool
+		wantState  string
+		wantSince  time.Time
+		confidence float64
+	}{
+		{
+			name:      "single event is not an arrival",
+			events:    []time.Time{t0},
+			wantState: HouseStateAway,
+		},
+		{
+			name:      "events further apart than the confirm window",
+			events:    []time.Time{t0, t0.Add(arrivalConfirmWindow + time.Minute)},
+			wantState: HouseStateAway,
+		},
+		{
+			name:       "second event confirms arrival at the first",
+			events:     []time.Time{t0, t0.Add(5 * time.Minute)},
+			wantState:  HouseStateHome,
+			wantSince:  t0,
+			confidence: 0.9,
+		},
+		{
+			name:       "pending arrival restarts after a stray event",
+			events:     []time.Time{t0, t0.Add(time.Hour), t0.Add(time.Hour + 5*time.Minute)},
+			wantState:  HouseStateHome,
+			wantSince:  t0.Add(time.Hour),
+			confidence: 0.9,
+		},
+		{
+			name:       "explicit presence needs no confirmation",
+			events:     []time.Time{t0},
+			explicit:   true,
+			wantState:  HouseStateHome,
+			wantSince:  t0,
+			confidence: 1.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &publishCounter{}
+			d := newTestHouseStateDetector(client)
+			d.state = HouseStateAway
+			d.lastActivity = leftAt
+			d.awayPeriods = []awayPeriod{{Start: leftAt}}
+
+			for _, ts := range tt.events {
+				d.recordActivity(ts, tt.explicit)
+			}
+
+			state, confidence := d.State()
+			if state != tt.wantState {
+				t.Fatalf("Expected state %s, got %s", tt.wantState, state)
+			}
+
+			if state == HouseStateAway {
+				if client.published != 0 {
+					t.Errorf("Expected no publish, got %d", client.published)
+				}
+				if !d.IsAwayAt(tt.events[len(tt.events)-1]) {
+					t.Error("Expected the away period to stay open")
+				}
+				return
+			}
+
+			if confidence != tt.confidence {
+				t.Errorf("Expected confidence %.2f, got %.2f", tt.confidence, confidence)
+			}
+			if !d.since.Equal(tt.wantSince) || !d.lastActivity.Equal(tt.wantSince) {
+				t.Errorf("Expected since and last activity %s, got %s and %s", tt.wantSince, d.since, d.lastActivity)
+			}
+			if !d.awayPeriods[0].End.Equal(tt.wantSince) {
+				t.Errorf("Expected the away period to end at %s, got %s", tt.wantSince, d.awayPeriods[0].End)
+			}
+			if d.IsAwayAt(tt.wantSince) || !d.IsAwayAt(tt.wantSince.Add(-time.Minute)) {
+				t.Error("Expected the away period to cover only the time before arrival")
+			}
+			if client.published != 1 {
+				t.Errorf("Expected 1 publish, got %d", client.published)
+			}
+		})
+	}
+}
+
+func TestIsAwayAt(t *testing.T) {
+	d := newTestHouseStateDetector(&publishCounter{})
+	leftAt := time.Date(2025, 10, 17, 8, 0, 0, 0, time.UTC)
+	back := leftAt.Add(6 * time.Hour)
+	d.awayPeriods = []awayPeriod{{Start: leftAt, End: back}}
+
+	tests := []struct {
+		name string
+		ts   time.Time
+		want bool
+	}{
+		{"before leaving", leftAt.Add(-time.Minute), false},
+		{"last activity before leaving", leftAt, false},
+		{"while away", leftAt.Add(time.Minute), true},
+		{"arrival", back, false},
+	}
+
+	for _, tt := range tests {
+		if got := d.IsAwayAt(tt.ts); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestHouseStateDetector_StopTwice(t *testing.T) {
+	d := newTestHouseStateDetector(&publishCounter{})
+	d.Stop()
+	d.Stop()
+}
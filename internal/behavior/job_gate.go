@@ -0,0 +1,211 @@
+package behavior
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/progress"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+const (
+	// jobLeaseTTL bounds how long a crashed agent keeps a job locked; running
+	// jobs refresh the lease at a third of it
+	jobLeaseTTL = 2 * time.Minute
+
+	// jobRetryInterval is how often a queued run checks for a lease released
+	// by another agent
+	jobRetryInterval = 10 * time.Second
+)
+
+// jobGate runs consolidation and discovery one at a time per job, whichever
+// trigger (schedule, MQTT, admin API, late data) asked and whichever agent
+// received it. A run holds a Redis lease for its job. A request arriving while
+// the lease is held waits for it as the job's queued run; further requests
+// are covered by that queued run and return at once. Both answer with a
+// JobInProgress event.
+type jobGate struct {
+	redis  redis.Client
+	mqtt   mqtt.Client
+	clock  clock.Clock
+	owner  string
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	queued   map[string]bool          // job → a request is waiting for the lease
+	released map[string]chan struct{} // job → wakes the waiter when this agent releases
+}
+
+func newJobGate(redisClient redis.Client, mqttClient mqtt.Client, clk clock.Clock, serviceName string, logger *slog.Logger) *jobGate {
+	owner := serviceName
+	if host, err := os.Hostname(); err == nil {
+		owner = serviceName + "@" + host
+	}
+
+	return &jobGate{
+		redis:    redisClient,
+		mqtt:     mqttClient,
+		clock:    clk,
+		owner:    owner,
+		logger:   logger.With("component", "job_gate"),
+		queued:   make(map[string]bool),
+		released: make(map[string]chan struct{}),
+	}
+}
+
+// Run runs fn once job's lease is free. It returns nil without running fn
+// when an already queued request covers this one.
+func (g *jobGate) Run(ctx context.Context, job, correlationID string, fn func(ctx context.Context) error) error {
+	lock, err := redis.AcquireLock(ctx, g.redis, redis.JobLockKey(job), g.owner, jobLeaseTTL)
+	var held *redis.LockHeldError
+	switch {
+	case err == nil:
+	case errors.As(err, &held):
+		if lock, err = g.wait(ctx, job, correlationID, held.Holder); lock == nil {
+			return err
+		}
+	default:
+		// Overlapping runs are better than none while Redis is unavailable
+		g.logger.WarnContext(ctx, "Running job without lease", "job", job, "error", err)
+		return fn(ctx)
+	}
+
+	defer g.release(job, lock)
+
+	runCtx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+	go g.refresh(runCtx, stop, job, lock)
+
+	if err := fn(runCtx); err != nil {
+		if cause := context.Cause(runCtx); errors.Is(cause, redis.ErrLockLost) {
+			return cause
+		}
+		return err
+	}
+	return nil
+}
+
+// wait queues a request for job until its lease is free. It returns a nil lock
+// when the request was covered by another queued one or ctx ended.
+func (g *jobGate) wait(ctx context.Context, job, correlationID, holder string) (*redis.Lock, error) {
+	g.mu.Lock()
+	if g.queued[job] {
+		g.mu.Unlock()
+		g.logger.InfoContext(ctx, "Job already queued, request covered", "job", job, "holder", holder)
+		g.publish(job, holder, correlationID, false)
+		progress.Phase(ctx, "covered_by_queued_run", map[string]interface{}{"holder": holder})
+		return nil, nil
+	}
+	g.queued[job] = true
+	released := g.releasedChan(job)
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.queued, job)
+		g.mu.Unlock()
+	}()
+
+	g.logger.InfoContext(ctx, "Job in progress, request queued", "job", job, "holder", holder)
+	g.publish(job, holder, correlationID, true)
+	progress.Phase(ctx, "queued", map[string]interface{}{"holder": holder})
+
+	ticker := time.NewTicker(jobRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		case <-ticker.C:
+		}
+
+		lock, err := redis.AcquireLock(ctx, g.redis, redis.JobLockKey(job), g.owner, jobLeaseTTL)
+		if err == nil {
+			return lock, nil
+		}
+		if !errors.Is(err, redis.ErrLockHeld) {
+			g.logger.WarnContext(ctx, "Failed to acquire job lease, retrying", "job", job, "error", err)
+		}
+	}
+}
+
+// refresh keeps lock alive until ctx ends. Losing the lease cancels the run,
+// since another agent may have started the job.
+func (g *jobGate) refresh(ctx context.Context, cancel context.CancelCauseFunc, job string, lock *redis.Lock) {
+	ticker := time.NewTicker(jobLeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := lock.Refresh(ctx)
+			if errors.Is(err, redis.ErrLockLost) {
+				g.logger.ErrorContext(ctx, "Job lease lost, cancelling run", "job", job, "error", err)
+				cancel(err)
+				return
+			}
+			if err != nil {
+				g.logger.WarnContext(ctx, "Failed to refresh job lease", "job", job, "error", err)
+			}
+		}
+	}
+}
+
+// release frees lock and wakes a request of this agent waiting for it
+func (g *jobGate) release(job string, lock *redis.Lock) {
+	// The run's context may be cancelled, the lease should still go
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := lock.Release(ctx); err != nil {
+		g.logger.Warn("Failed to release job lease, it expires on its own", "job", job, "error", err)
+	}
+
+	g.mu.Lock()
+	released := g.releasedChan(job)
+	g.mu.Unlock()
+	select {
+	case released <- struct{}{}:
+	default:
+	}
+}
+
+// releasedChan returns job's release signal; g.mu must be held
+func (g *jobGate) releasedChan(job string) chan struct{} {
+	ch, ok := g.released[job]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		g.released[job] = ch
+	}
+	return ch
+}
+
+// publish answers a request that found job in progress
+func (g *jobGate) publish(job, holder, correlationID string, queued bool) {
+	event := events.JobInProgress{
+		Job:           job,
+		Holder:        holder,
+		Queued:        queued,
+		CorrelationID: correlationID,
+		Timestamp:     g.clock.Now().Format(time.RFC3339),
+	}
+
+	payload, err := events.Marshal(events.ProducerBehavior, event)
+	if err != nil {
+		g.logger.Error("Failed to marshal job in progress event", "error", err)
+		return
+	}
+	if err := g.mqtt.Publish(event.Topic(), 0, false, payload); err != nil {
+		g.logger.Warn("Failed to publish job in progress event", "job", job, "error", err)
+	}
+}
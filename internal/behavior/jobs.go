@@ -0,0 +1,227 @@
+package behavior
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/progress"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+)
+
+// maxRetainedJobs bounds how many finished jobs are kept for GET /api/jobs
+const maxRetainedJobs = 100
+
+// errJobAlreadyRunning is returned when a job of the same kind is in progress
+var errJobAlreadyRunning = errors.New("a job of this kind is already running")
+
+// JobStatus is the lifecycle state of an admin job
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// ConsolidateJobRequest is the body of POST /api/admin/consolidate; it mirrors
+// the automation/behavior/consolidate trigger
+type ConsolidateJobRequest struct {
+	LookbackHours int    `json:"lookback_hours"`
+	Location      string `json:"location"`
+}
+
+// DiscoverJobRequest is the body of POST /api/admin/discover; it mirrors the
+// automation/behavior/discover_patterns trigger
+type DiscoverJobRequest struct {
+	MinAnchors    int `json:"min_anchors"`
+	LookbackHours int `json:"lookback_hours"`
+}
+
+// JobStartedResponse is returned when a job is accepted
+type JobStartedResponse struct {
+	JobID     uuid.UUID `json:"job_id"`
+	StatusURL string    `json:"status_url"`
+}
+
+// JobPhase is one step reported by the running operation
+type JobPhase struct {
+	Name      string                 `json:"name"`
+	StartedAt time.Time              `json:"started_at"`
+	Detail    map[string]interface{} `json:"detail,omitempty"`
+}
+
+// Job is an admin-triggered consolidation or discovery run
+type Job struct {
+	ID         uuid.UUID   `json:"id"`
+	Kind       string      `json:"kind"` // "consolidate" | "discover"
+	Status     JobStatus   `json:"status"`
+	Params     interface{} `json:"params"`
+	Phases     []JobPhase  `json:"phases"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
+
+// CurrentPhase returns the name of the most recent phase, or "" before the first
+func (j *Job) CurrentPhase() string {
+	if len(j.Phases) == 0 {
+		return ""
+	}
+	return j.Phases[len(j.Phases)-1].Name
+}
+
+// jobTracker runs admin jobs in the background and keeps their progress in
+// memory. Only one job per kind runs at a time.
+type jobTracker struct {
+	mu      sync.Mutex
+	jobs    map[uuid.UUID]*Job
+	order   []uuid.UUID          // oldest first
+	running map[string]uuid.UUID // kind → running job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	logger  *slog.Logger
+}
+
+func newJobTracker(logger *slog.Logger) *jobTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &jobTracker{
+		jobs:    make(map[uuid.UUID]*Job),
+		running: make(map[string]uuid.UUID),
+		ctx:     ctx,
+		cancel:  cancel,
+		logger:  logger.With("component", "jobs"),
+	}
+}
+
+// start launches run in the background and returns a snapshot of the new job
+func (t *jobTracker) start(kind string, params interface{}, run func(ctx context.Context) error) (Job, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if id, ok := t.running[kind]; ok {
+		return Job{}, fmt.Errorf("%w: %s", errJobAlreadyRunning, id)
+	}
+
+	job := &Job{
+		ID:        uuid.New(),
+		Kind:      kind,
+		Status:    JobRunning,
+		Params:    params,
+		Phases:    []JobPhase{},
+		CreatedAt: time.Now(),
+	}
+	t.jobs[job.ID] = job
+	t.order = append(t.order, job.ID)
+	t.running[kind] = job.ID
+	t.prune()
+
+	ctx := progress.WithReporter(t.ctx, func(phase string, detail map[string]interface{}) {
+		t.recordPhase(job.ID, phase, detail)
+	})
+	ctx = logging.WithCorrelationID(ctx, job.ID.String())
+
+	go func() {
+		t.logger.InfoContext(ctx, "Job started", "job_id", job.ID, "kind", kind)
+		err := run(ctx)
+		t.finish(job.ID, err)
+	}()
+
+	return t.snapshot(job), nil
+}
+
+// recordPhase appends a phase, or refreshes its detail when the operation
+// reports the same phase again
+func (t *jobTracker) recordPhase(id uuid.UUID, phase string, detail map[string]interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	job, ok := t.jobs[id]
+	if !ok {
+		return
+	}
+	if n := len(job.Phases); n > 0 && job.Phases[n-1].Name == phase {
+		job.Phases[n-1].Detail = detail
+		return
+	}
+	job.Phases = append(job.Phases, JobPhase{Name: phase, StartedAt: time.Now(), Detail: detail})
+}
+
+func (t *jobTracker) finish(id uuid.UUID, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	job, ok := t.jobs[id]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	job.FinishedAt = &now
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = err.Error()
+		t.logger.Error("Job failed", "job_id", id, "kind", job.Kind, "error", err)
+	} else {
+		job.Status = JobSucceeded
+		t.logger.Info("Job completed", "job_id", id, "kind", job.Kind, "duration", now.Sub(job.CreatedAt))
+	}
+	if t.running[job.Kind] == id {
+		delete(t.running, job.Kind)
+	}
+}
+
+// prune drops the oldest finished jobs beyond maxRetainedJobs. Caller holds mu.
+func (t *jobTracker) prune() {
+	for i := 0; len(t.order) > maxRetainedJobs && i < len(t.order); {
+		id := t.order[i]
+		if t.jobs[id].Status == JobRunning {
+			i++
+			continue
+		}
+		delete(t.jobs, id)
+		t.order = append(t.order[:i], t.order[i+1:]...)
+	}
+}
+
+// get returns a copy of the job so callers can encode it without holding mu
+func (t *jobTracker) get(id uuid.UUID) (Job, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	job, ok := t.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return t.snapshot(job), true
+}
+
+// list returns all retained jobs, newest first
+func (t *jobTracker) list() []Job {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	jobs := make([]Job, 0, len(t.order))
+	for i := len(t.order) - 1; i >= 0; i-- {
+		jobs = append(jobs, t.snapshot(t.jobs[t.order[i]]))
+	}
+	return jobs
+}
+
+// snapshot copies a job including its phase slice. Caller holds mu.
+func (t *jobTracker) snapshot(job *Job) Job {
+	cp := *job
+	cp.Phases = make([]JobPhase, len(job.Phases))
+	copy(cp.Phases, job.Phases)
+	return cp
+}
+
+// stop cancels running jobs; they finish as failed once they observe the
+// cancelled context
+func (t *jobTracker) stop() {
+	t.cancel()
+}
@@ -0,0 +1,298 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/progress"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+)
+
+const (
+	// consolidatedKey is a Redis hash of location (or "universe") → virtual
+	// time its sensor data was last read for consolidation (RFC3339)
+	consolidatedKey = "behavior:consolidated"
+
+	// lateDataMargin widens a re-consolidated window so the episodes next to
+	// a late event are rebuilt with it
+	lateDataMargin = 5 * time.Minute
+
+	// sensorDataRetention is how long the collector keeps sensor data in Redis;
+	// older windows can no longer be rebuilt
+	sensorDataRetention = 24 * time.Hour
+)
+
+// lateDataSensorTypes are the sensor streams episodes are built from
+var lateDataSensorTypes = []string{"motion", "lighting"}
+
+// LateDataJobRequest describes a re-consolidation scheduled for late sensor data
+type LateDataJobRequest struct {
+	Locations map[string]time.Time `json:"locations"` // location → earliest late event
+}
+
+// markConsolidated records that location's sensor data was read at consolidatedAt
+func (a *Agent) markConsolidated(ctx context.Context, location string, consolidatedAt time.Time) {
+	if location == "" {
+		location = "universe"
+	}
+	if err := a.redis.HSet(ctx, consolidatedKey, location, consolidatedAt.Format(time.RFC3339Nano)); err != nil {
+		a.logger.Warn("Failed to record consolidation watermark", "location", location, "error", err)
+	}
+}
+
+// consolidationWatermarks returns when each sensor location was last consolidated
+func (a *Agent) consolidationWatermarks(ctx context.Context) (map[string]time.Time, error) {
+	fields, err := a.redis.HGetAll(ctx, consolidatedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read consolidation watermarks: %w", err)
+	}
+
+	parse := func(field string) time.Time {
+		ts, _ := time.Parse(time.RFC3339Nano, fields[field])
+		return ts
+	}
+	universe := parse("universe")
+
+	watermarks := make(map[string]time.Time)
+	for _, location := range a.privacy.FilterLocations(sensorLocations) {
+		watermark := parse(location)
+		if universe.After(watermark) {
+			watermark = universe
+		}
+		if !watermark.IsZero() {
+			watermarks[location] = watermark
+		}
+	}
+	return watermarks, nil
+}
+
+// findLateData returns, per location, the earliest sensor event that was
+// collected after its location was consolidated although it happened before
+func (a *Agent) findLateData(ctx context.Context) (map[string]time.Time, error) {
+	watermarks, err := a.consolidationWatermarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.timeManager.Now()
+	late := make(map[string]time.Time)
+	for location, watermark := range watermarks {
+		for _, sensorType := range lateDataSensorTypes {
+			key := fmt.Sprintf("sensor:%s:%s", sensorType, location)
+			members, err := a.redis.ZRangeByScoreWithScores(ctx, key, float64(watermark.UnixMilli()), float64(now.UnixMilli()))
+			if err != nil {
+				continue
+			}
+
+			for _, member := range members {
+				var data struct {
+					Timestamp string `json:"timestamp"`
+				}
+				if err := json.Unmarshal([]byte(member.Member), &data); err != nil {
+					continue
+				}
+				ts, err := time.Parse(time.RFC3339, data.Timestamp)
+				if err != nil || !ts.Before(watermark) {
+					continue
+				}
+
+				collectedAt := time.UnixMilli(int64(member.Score))
+				if collectedAt.Sub(ts) < a.cfg.LateDataMinDelay {
+					continue // ordinary pipeline latency
+				}
+				if earliest, ok := late[location]; !ok || ts.Before(earliest) {
+					late[location] = ts
+				}
+			}
+		}
+	}
+	return late, nil
+}
+
+// runLateDataCheck periodically schedules re-consolidation for locations with late data
+func (a *Agent) runLateDataCheck(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.LateDataCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.checkLateData(ctx)
+		}
+	}
+}
+
+// checkLateData schedules a "reconsolidate" job when late sensor data is found
+func (a *Agent) checkLateData(ctx context.Context) {
+	late, err := a.findLateData(ctx)
+	if err != nil {
+		a.logger.Error("Late data check failed", "error", err)
+		return
+	}
+	if len(late) == 0 {
+		return
+	}
+
+	for location, earliest := range late {
+		a.logger.Info("Late sensor data detected",
+			"location", location,
+			"earliest_event", earliest.Format(time.RFC3339))
+	}
+
+	req := LateDataJobRequest{Locations: late}
+	job, err := a.jobs.start("reconsolidate", req, func(ctx context.Context) error {
+		return a.jobGate.Run(ctx, events.JobConsolidation, logging.CorrelationID(ctx), func(ctx context.Context) error {
+			return a.reconsolidateLateData(ctx, req)
+		})
+	})
+	if err != nil {
+		// Still detected on the next check, the watermark has not moved
+		a.logger.Info("Re-consolidation already running, retrying on next check", "error", err)
+		return
+	}
+	a.logger.Info("Re-consolidation scheduled", "job_id", job.ID, "locations", len(late))
+}
+
+// reconsolidateLateData rebuilds the timeline of every location in req
+func (a *Agent) reconsolidateLateData(ctx context.Context, req LateDataJobRequest) error {
+	var failed int
+	for _, location := range sensorLocations {
+		from, ok := req.Locations[location]
+		if !ok {
+			continue
+		}
+		if err := a.reconsolidateLocation(ctx, location, from); err != nil {
+			a.logger.Error("Re-consolidation failed", "location", location, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to re-consolidate %d of %d locations", failed, len(req.Locations))
+	}
+	return nil
+}
+
+// reconsolidateLocation replaces location's micro- and macro-episodes from the
+// first episode touched by a late event at from onwards, and rebuilds them
+// from Redis. Windows with user annotations are left alone.
+func (a *Agent) reconsolidateLocation(ctx context.Context, location string, from time.Time) error {
+	progress.Phase(ctx, "late_data_reset", map[string]interface{}{"location": location, "from": from.Format(time.RFC3339)})
+
+	windowStart, err := a.affectedWindowStart(ctx, location, from.Add(-lateDataMargin))
+	if err != nil {
+		return err
+	}
+
+	readAt := a.timeManager.Now()
+	if readAt.Sub(windowStart) > sensorDataRetention {
+		a.logger.Warn("Late data is older than Redis retention, not re-consolidating",
+			"location", location,
+			"window_start", windowStart.Format(time.RFC3339))
+		a.markConsolidated(ctx, location, readAt)
+		return nil
+	}
+
+	annotated, err := a.windowHasAnnotations(ctx, location, windowStart)
+	if err != nil {
+		return err
+	}
+	if annotated {
+		a.logger.Warn("Annotated episodes in late data window, keeping the edited timeline",
+			"location", location,
+			"window_start", windowStart.Format(time.RFC3339))
+		a.markConsolidated(ctx, location, readAt)
+		return nil
+	}
+
+	macros, micros, err := a.deleteEpisodesSince(ctx, location, windowStart)
+	if err != nil {
+		return err
+	}
+	a.logger.Info("Re-consolidating location for late sensor data",
+		"location", location,
+		"window_start", windowStart.Format(time.RFC3339),
+		"macro_episodes_replaced", macros,
+		"micro_episodes_replaced", micros)
+
+	progress.Phase(ctx, "episode_creation", map[string]interface{}{"location": location})
+	created, err := a.createEpisodesFromSensors(ctx, windowStart, location)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild episodes: %w", err)
+	}
+	a.markConsolidated(ctx, location, readAt)
+
+	a.logger.Info("Episodes rebuilt from sensor data",
+		"location", location,
+		"count", created)
+	return a.consolidateEpisodes(ctx, windowStart, location)
+}
+
+// affectedWindowStart moves since back to the start of any episode or
+// macro-episode in location that is still open at since
+func (a *Agent) affectedWindowStart(ctx context.Context, location string, since time.Time) (time.Time, error) {
+	var earliest *time.Time
+	err := a.pgClient.QueryRow(ctx, `
+		SELECT MIN(start_time) FROM (
+			SELECT started_at_text::timestamptz AS start_time
+			FROM behavioral_episodes
+			WHERE location = $1 AND ended_at_text::timestamptz >= $2
+			UNION ALL
+			SELECT start_time
+			FROM macro_episodes
+			WHERE $1 = ANY(locations) AND end_time >= $2
+		) affected`, location, since).Scan(&earliest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find affected episodes: %w", err)
+	}
+	if earliest != nil && earliest.Before(since) {
+		return *earliest, nil
+	}
+	return since, nil
+}
+
+// windowHasAnnotations reports whether any episode in location since windowStart was annotated
+func (a *Agent) windowHasAnnotations(ctx context.Context, location string, windowStart time.Time) (bool, error) {
+	var annotated bool
+	err := a.pgClient.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM episode_annotations n
+			JOIN behavioral_episodes e
+			  ON n.episode_id = e.id OR e.id = ANY(n.related_episode_ids)
+			WHERE e.location = $1 AND e.started_at_text::timestamptz >= $2
+		)`, location, windowStart).Scan(&annotated)
+	if err != nil {
+		return false, fmt.Errorf("failed to check annotations: %w", err)
+	}
+	return annotated, nil
+}
+
+// deleteEpisodesSince removes location's micro-episodes started since
+// windowStart and the macro-episodes built from them
+func (a *Agent) deleteEpisodesSince(ctx context.Context, location string, windowStart time.Time) (int64, int64, error) {
+	macros, err := a.pgClient.Exec(ctx, `
+		DELETE FROM macro_episodes
+		WHERE micro_episode_ids && ARRAY(
+			SELECT id FROM behavioral_episodes
+			WHERE location = $1 AND started_at_text::timestamptz >= $2
+		)`, location, windowStart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to delete affected macro-episodes: %w", err)
+	}
+
+	micros, err := a.pgClient.Exec(ctx, `
+		DELETE FROM behavioral_episodes
+		WHERE location = $1 AND started_at_text::timestamptz >= $2`, location, windowStart)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to delete affected episodes: %w", err)
+	}
+
+	macroCount, _ := macros.RowsAffected()
+	microCount, _ := micros.RowsAffected()
+	return macroCount, microCount, nil
+}
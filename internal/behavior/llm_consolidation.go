@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/prompts"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/llm"
 )
@@ -21,6 +22,7 @@ import (
 type ConsolidationInput struct {
 	Episodes []*MicroEpisode      `json:"episodes"`
 	Context  ConsolidationContext `json:"context"`
+	Examples []AnnotationExample  `json:"examples,omitempty"` // user-labeled episodes (few-shot)
 }
 
 // ConsolidationContext provides temporal and spatial context
@@ -47,16 +49,22 @@ type ConsolidationOutput struct {
 
 // ConsolidationAnalyzer implements llm.Analyzer for episode consolidation
 type ConsolidationAnalyzer struct {
-	cfg *config.Config
+	cfg     *config.Config
+	model   string
+	prompts *llm.Prompts
 }
 
-// NewConsolidationAnalyzer creates a new analyzer
-func NewConsolidationAnalyzer(cfg *config.Config) *ConsolidationAnalyzer {
-	return &ConsolidationAnalyzer{cfg: cfg}
+// NewConsolidationAnalyzer creates a new analyzer rendering promptSet (the
+// built-in prompts when nil) for model
+func NewConsolidationAnalyzer(cfg *config.Config, model string, promptSet *llm.Prompts) *ConsolidationAnalyzer {
+	if promptSet == nil {
+		promptSet = prompts.Default()
+	}
+	return &ConsolidationAnalyzer{cfg: cfg, model: model, prompts: promptSet}
 }
 
 // BuildPrompt creates the LLM prompt from episode data
-func (a *ConsolidationAnalyzer) BuildPrompt(input ConsolidationInput) string {
+func (a *ConsolidationAnalyzer) BuildPrompt(input ConsolidationInput) (string, error) {
 	episodes := input.Episodes
 	ctx := input.Context
 
@@ -89,38 +97,16 @@ func (a *ConsolidationAnalyzer) BuildPrompt(input ConsolidationInput) string {
 
 	jsonData, _ := json.MarshalIndent(data, "", "  ")
 
-	return fmt.Sprintf(`Analyze these behavioral episodes to determine if they represent a SINGLE continuous activity pattern or SEPARATE unrelated activities.
-
-IMPORTANT: It is PERFECTLY ACCEPTABLE to say should_merge=false. Many episodes are naturally separate activities and should NOT be merged.
-
-Red flags that indicate SEPARATE activities (do NOT merge):
-- Gaps > 4 hours (likely sleep, work, or different activity)
-- Overnight gaps (crossing sleep period)
-- Illogical location sequences
-- Very different activity contexts
-
-Consider:
-1. Temporal proximity - Are gaps < 2 hours?
-2. Location sequence - Does the flow make sense?
-3. Time of day - Does it cross major boundaries?
-4. Duration patterns - Quick transitions vs. long gaps
-
-Examples:
-✓ MERGE: bedroom(8:00)→kitchen(8:15)→dining(8:35) - Morning routine
-✗ DON'T MERGE: bedroom(22:00)→[8h gap]→kitchen(7:00) - Sleep in between
-✗ DON'T MERGE: study(14:00)→[6h gap]→living_room(20:00) - Different activities
-
-Data:
-%s
-
-Respond ONLY with valid JSON (no markdown, no explanation):
-{
-  "should_merge": true/false,
-  "pattern_type": "morning_routine" | "meal_preparation" | "work_session" | "entertainment" | "evening_routine" | null,
-  "macro_name": "human readable name",
-  "confidence": 0.0-1.0,
-  "reasoning": "explanation"
-}`, jsonData)
+	// User annotations are ground truth for this household
+	examples := make([]string, len(input.Examples))
+	for i, ex := range input.Examples {
+		examples[i] = formatAnnotationExample(ex)
+	}
+
+	return a.prompts.Render(prompts.Consolidation, a.model, prompts.ConsolidationData{
+		Examples: examples,
+		Data:     string(jsonData),
+	})
 }
 
 // ParseResponse parses the LLM's JSON response
@@ -159,6 +145,9 @@ func consolidateWithLLM(
 	ctx context.Context,
 	episodes []*MicroEpisode,
 	llmClient llm.Client,
+	model string,
+	examples []AnnotationExample,
+	promptSet *llm.Prompts,
 	cfg *config.Config,
 	logger *slog.Logger,
 	now time.Time,
@@ -169,7 +158,7 @@ func consolidateWithLLM(
 
 	logger.Info("LLM consolidation starting",
 		"episodes", len(episodes),
-		"model", cfg.LLMModel)
+		"model", model)
 
 	// Group episodes into time windows
 	windows := groupByTimeWindow(episodes, 2*time.Hour)
@@ -212,6 +201,7 @@ func consolidateWithLLM(
 				TotalDuration:    calculateTotalDuration(window),
 				Gaps:             calculateGaps(window),
 			},
+			Examples: examples,
 		}
 
 		logger.Info("Calling LLM for window analysis",
@@ -221,8 +211,8 @@ func consolidateWithLLM(
 			"total_duration_min", input.Context.TotalDuration)
 
 		// Call LLM
-		analyzer := NewConsolidationAnalyzer(cfg)
-		output, err := llm.Analyze(ctx, llmClient, analyzer, cfg.LLMModel, input, logger)
+		analyzer := NewConsolidationAnalyzer(cfg, model, promptSet)
+		output, err := llm.Analyze(ctx, llmClient, analyzer, model, input, logger)
 		if err != nil {
 			logger.Error("LLM analysis failed for window",
 				"window_index", windowIdx,
@@ -0,0 +1,189 @@
+package behavior
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/llm"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+const (
+	// llmStatusTopic carries LLM availability (retained)
+	llmStatusTopic = "automation/behavior/llm/status"
+
+	llmProbeTimeout  = 5 * time.Second
+	llmWarmUpTimeout = 3 * time.Minute // loading a large model onto the GPU is slow
+)
+
+var errLLMNotProbed = errcode.New(errcode.ErrTransient, "LLM has not been probed yet")
+
+// LLMStatus is the payload of automation/behavior/llm/status
+type LLMStatus struct {
+	Available bool      `json:"available"`
+	Models    []string  `json:"models"`
+	Warm      bool      `json:"warm"` // the models have been loaded since Ollama came up
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// LLMMonitor probes Ollama periodically and warms the configured models up
+// whenever it comes up, so the first consolidation or distance run does not
+// pay for loading them. Consolidation skips its LLM phase while the last probe
+// failed. A nil *LLMMonitor (probing disabled) leaves the check to callers.
+type LLMMonitor struct {
+	router   *llm.Router
+	interval time.Duration
+	warmUp   bool
+	mqtt     mqtt.Client
+	logger   *slog.Logger
+
+	mu     sync.RWMutex
+	status LLMStatus
+	err    error
+}
+
+// NewLLMMonitor returns a monitor probing every cfg.LLMProbeInterval, or nil
+// when the interval is zero
+func NewLLMMonitor(cfg *config.Config, router *llm.Router, mqttClient mqtt.Client, logger *slog.Logger) *LLMMonitor {
+	if cfg.LLMProbeInterval <= 0 {
+		return nil
+	}
+	return &LLMMonitor{
+		router:   router,
+		interval: cfg.LLMProbeInterval,
+		warmUp:   cfg.LLMWarmUp,
+		mqtt:     mqttClient,
+		logger:   logger.With("component", "llm_monitor"),
+		status:   LLMStatus{Models: routedModels(router)},
+		err:      errLLMNotProbed,
+	}
+}
+
+// Start probes (and warms up) immediately, then every interval
+func (m *LLMMonitor) Start(ctx context.Context) {
+	go func() {
+		m.probe(ctx)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.probe(ctx)
+			}
+		}
+	}()
+}
+
+// Err returns why the LLM is unavailable, nil when the last probe succeeded
+func (m *LLMMonitor) Err() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.err
+}
+
+// Status returns the result of the last probe
+func (m *LLMMonitor) Status() LLMStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// probe checks Ollama, warms the models up when it has just come up, and
+// publishes the status when availability changes
+func (m *LLMMonitor) probe(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, llmProbeTimeout)
+	err := m.router.Health(probeCtx)
+	cancel()
+
+	m.mu.RLock()
+	previous := m.status
+	m.mu.RUnlock()
+
+	warm := previous.Warm && err == nil
+	if err == nil && m.warmUp && !warm {
+		if err = m.warmUpModels(ctx, previous.Models); err == nil {
+			warm = true
+		}
+	}
+
+	status := LLMStatus{
+		Available: err == nil,
+		Models:    previous.Models,
+		Warm:      warm,
+		CheckedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	m.mu.Lock()
+	m.status = status
+	m.err = err
+	m.mu.Unlock()
+
+	if status.Available == previous.Available && status.Warm == previous.Warm && !previous.CheckedAt.IsZero() {
+		return
+	}
+	if status.Available {
+		m.logger.Info("LLM available", "models", status.Models, "warm", status.Warm)
+	} else {
+		m.logger.Warn("LLM unavailable, consolidation will be rule-based only", "error", err)
+	}
+	m.publish(status)
+}
+
+// warmUpModels loads each model with a one-token generation
+func (m *LLMMonitor) warmUpModels(ctx context.Context, models []string) error {
+	for _, model := range models {
+		start := time.Now()
+		warmCtx, cancel := context.WithTimeout(ctx, llmWarmUpTimeout)
+		_, err := m.router.Generate(warmCtx, llm.GenerateRequest{
+			Model:     model,
+			Prompt:    "Reply with OK.",
+			Options:   map[string]interface{}{"num_predict": 1},
+			KeepAlive: "30m",
+		})
+		cancel()
+		if err != nil {
+			m.logger.Warn("LLM warm-up failed", "model", model, "error", err)
+			return err
+		}
+		m.logger.Info("LLM model warmed up", "model", model, "duration_ms", time.Since(start).Milliseconds())
+	}
+	return nil
+}
+
+func (m *LLMMonitor) publish(status LLMStatus) {
+	payload, err := events.Marshal(events.ProducerBehavior, status)
+	if err != nil {
+		m.logger.Error("Failed to marshal LLM status", "error", err)
+		return
+	}
+	if err := m.mqtt.Publish(llmStatusTopic, 0, true, payload); err != nil {
+		m.logger.Error("Failed to publish LLM status", "error", err)
+	}
+}
+
+// routedModels returns the distinct models the router sends tasks to
+func routedModels(router *llm.Router) []string {
+	seen := make(map[string]bool)
+	var models []string
+	for _, model := range router.Routes() {
+		if !seen[model] {
+			seen[model] = true
+			models = append(models, model)
+		}
+	}
+	sort.Strings(models)
+	return models
+}
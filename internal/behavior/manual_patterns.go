@@ -0,0 +1,336 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// defaultManualPatternWeight ranks a user-defined routine above a freshly
+// discovered pattern (0.1) until prediction outcomes say otherwise
+const defaultManualPatternWeight = 0.5
+
+var (
+	// errInvalidPattern wraps pattern definition errors
+	errInvalidPattern = errors.New("invalid pattern")
+
+	// errDiscoveredPatternShape is returned when an update tries to redefine the
+	// locations or window of a discovered pattern, which come from its anchors
+	errDiscoveredPatternShape = errors.New("locations, window and duration of discovered patterns cannot be changed")
+)
+
+// PatternRequest defines a manual pattern (POST /api/patterns) or replaces the
+// definition of an existing one (PUT /api/patterns/{id})
+type PatternRequest struct {
+	Name                   string   `json:"name"`
+	Description            string   `json:"description,omitempty"`
+	PatternType            string   `json:"pattern_type,omitempty"`
+	Locations              []string `json:"locations,omitempty"`    // in visiting order
+	WindowStart            string   `json:"window_start,omitempty"` // "HH:MM" local
+	WindowEnd              string   `json:"window_end,omitempty"`   // "HH:MM" local, may wrap past midnight
+	TypicalDurationMinutes *int     `json:"typical_duration_minutes,omitempty"`
+	Weight                 float64  `json:"weight,omitempty"` // 0 = default
+}
+
+// Validate checks a manual pattern definition
+func (r PatternRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.Locations) == 0 {
+		return fmt.Errorf("at least one location is required")
+	}
+	for _, location := range r.Locations {
+		if strings.TrimSpace(location) == "" {
+			return fmt.Errorf("locations must not be empty")
+		}
+	}
+	if (r.WindowStart == "") != (r.WindowEnd == "") {
+		return fmt.Errorf("window_start and window_end must be given together")
+	}
+	for _, t := range []string{r.WindowStart, r.WindowEnd} {
+		if t == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", t); err != nil {
+			return fmt.Errorf("invalid window time %q (expected HH:MM)", t)
+		}
+	}
+	if r.TypicalDurationMinutes != nil && *r.TypicalDurationMinutes <= 0 {
+		return fmt.Errorf("typical_duration_minutes must be positive")
+	}
+	if r.Weight != 0 && r.Weight < 0.1 {
+		return fmt.Errorf("weight must be at least 0.1")
+	}
+	return nil
+}
+
+// definesShape reports whether the request sets fields only manual patterns have
+func (r PatternRequest) definesShape() bool {
+	return len(r.Locations) > 0 || r.WindowStart != "" || r.WindowEnd != "" || r.TypicalDurationMinutes != nil
+}
+
+// createManualPattern stores a user-defined pattern
+func (a *Agent) createManualPattern(ctx context.Context, req PatternRequest) (*types.BehavioralPattern, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	store, err := a.patternStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.timeManager.Now()
+	pattern := &types.BehavioralPattern{
+		Name:                   strings.TrimSpace(req.Name),
+		Description:            req.Description,
+		PatternType:            req.PatternType,
+		Weight:                 req.Weight,
+		Locations:              req.Locations,
+		TypicalDurationMinutes: req.TypicalDurationMinutes,
+		Source:                 types.PatternSourceManual,
+		WindowStart:            req.WindowStart,
+		WindowEnd:              req.WindowEnd,
+		FirstSeen:              now,
+		LastSeen:               now,
+	}
+	if pattern.Weight == 0 {
+		pattern.Weight = defaultManualPatternWeight
+	}
+
+	if err := store.CreatePattern(ctx, pattern); err != nil {
+		return nil, fmt.Errorf("failed to create pattern: %w", err)
+	}
+
+	a.logger.Info("Manual pattern defined",
+		"pattern_id", pattern.ID,
+		"name", pattern.Name,
+		"locations", pattern.Locations,
+		"window_start", pattern.WindowStart,
+		"window_end", pattern.WindowEnd)
+
+	return pattern, nil
+}
+
+// updatePattern replaces a manual pattern's definition, or renames and
+// re-describes a discovered one
+func (a *Agent) updatePattern(ctx context.Context, id uuid.UUID, req PatternRequest) (*types.BehavioralPattern, error) {
+	store, err := a.patternStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := store.GetPattern(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if pattern.Source != types.PatternSourceManual {
+		if req.definesShape() {
+			return nil, errDiscoveredPatternShape
+		}
+		if strings.TrimSpace(req.Name) == "" {
+			return nil, fmt.Errorf("%w: name is required", errInvalidPattern)
+		}
+	} else {
+		if err := req.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidPattern, err)
+		}
+		pattern.Locations = req.Locations
+		pattern.WindowStart = req.WindowStart
+		pattern.WindowEnd = req.WindowEnd
+		pattern.TypicalDurationMinutes = req.TypicalDurationMinutes
+		if req.Weight != 0 {
+			pattern.Weight = req.Weight
+		}
+	}
+	pattern.Name = strings.TrimSpace(req.Name)
+	pattern.Description = req.Description
+	pattern.PatternType = req.PatternType
+
+	if err := store.UpdatePattern(ctx, pattern); err != nil {
+		return nil, fmt.Errorf("failed to update pattern: %w", err)
+	}
+	return pattern, nil
+}
+
+// deletePattern removes a manual pattern, or archives a discovered one and
+// releases its anchors to discovery
+func (a *Agent) deletePattern(ctx context.Context, id uuid.UUID) error {
+	store, err := a.patternStorage()
+	if err != nil {
+		return err
+	}
+
+	pattern, err := store.GetPattern(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if pattern.Source == types.PatternSourceManual {
+		if err := store.DeleteManualPattern(ctx, id); err != nil {
+			return err
+		}
+		a.logger.Info("Manual pattern deleted", "pattern_id", id, "name", pattern.Name)
+		return nil
+	}
+
+	released, err := store.RetirePattern(ctx, id, "deleted")
+	if err != nil {
+		return err
+	}
+	a.logger.Info("Discovered pattern archived by user",
+		"pattern_id", id,
+		"name", pattern.Name,
+		"anchors_released", released)
+	return nil
+}
+
+func (a *Agent) patternStorage() (*storage.AnchorStorage, error) {
+	db, err := a.getDBConnection()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	return a.createAnchorStorage(db), nil
+}
+
+// handleListPatterns handles GET /api/patterns[?include_archived=true][&source=manual|discovered]
+func (a *Agent) handleListPatterns(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source")
+	if source != "" && source != types.PatternSourceManual && source != types.PatternSourceDiscovered {
+		http.Error(w, "invalid source", http.StatusBadRequest)
+		return
+	}
+
+	store, err := a.patternStorage()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	patterns, err := store.GetPatterns(r.Context(), r.URL.Query().Get("include_archived") == "true", source)
+	if err != nil {
+		a.logger.Error("Failed to list patterns", "error", err)
+		http.Error(w, "failed to list patterns", http.StatusInternalServerError)
+		return
+	}
+	if patterns == nil {
+		patterns = []*types.BehavioralPattern{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(patterns)
+}
+
+// handleGetPattern handles GET /api/patterns/{id}
+func (a *Agent) handleGetPattern(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid pattern id", http.StatusBadRequest)
+		return
+	}
+
+	store, err := a.patternStorage()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	pattern, err := store.GetPattern(r.Context(), id)
+	if err != nil {
+		a.writePatternError(w, id, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pattern)
+}
+
+// handleCreatePattern handles POST /api/patterns
+func (a *Agent) handleCreatePattern(w http.ResponseWriter, r *http.Request) {
+	var req PatternRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pattern, err := a.createManualPattern(r.Context(), req)
+	if err != nil {
+		a.logger.Error("Failed to create pattern", "name", req.Name, "error", err)
+		http.Error(w, "failed to create pattern", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(pattern)
+}
+
+// handleUpdatePattern handles PUT /api/patterns/{id}
+func (a *Agent) handleUpdatePattern(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid pattern id", http.StatusBadRequest)
+		return
+	}
+
+	var req PatternRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	pattern, err := a.updatePattern(r.Context(), id, req)
+	if err != nil {
+		a.writePatternError(w, id, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pattern)
+}
+
+// handleDeletePattern handles DELETE /api/patterns/{id}
+func (a *Agent) handleDeletePattern(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid pattern id", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.deletePattern(r.Context(), id); err != nil {
+		a.writePatternError(w, id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePatternError maps pattern API errors to status codes
+func (a *Agent) writePatternError(w http.ResponseWriter, id uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrNotFound):
+		http.Error(w, "pattern not found", http.StatusNotFound)
+	case errors.Is(err, errInvalidPattern), errors.Is(err, errDiscoveredPatternShape):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		a.logger.Error("Pattern request failed", "pattern_id", id, "error", err)
+		http.Error(w, "pattern request failed", http.StatusInternalServerError)
+	}
+}
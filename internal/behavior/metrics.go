@@ -0,0 +1,86 @@
+package behavior
+
+import (
+	"context"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/metrics"
+)
+
+// patternMetricsInterval is how often pattern statistics are sampled
+const patternMetricsInterval = 5 * time.Minute
+
+// recordEpisodeMetric writes the duration of a closed episode
+// (behavior_episode_duration_minutes)
+func (a *Agent) recordEpisodeMetric(location, occupant string, start, end time.Time, reason string) {
+	a.metrics.Write(metrics.Point{
+		Measurement: "behavior_episode",
+		Tags: map[string]string{
+			"location":   location,
+			"occupant":   occupant,
+			"end_reason": reason,
+		},
+		Fields: map[string]interface{}{"duration_minutes": end.Sub(start).Minutes()},
+		Time:   end,
+	})
+}
+
+// runPatternMetrics samples per-pattern statistics (behavior_pattern_*) and
+// the active pattern count (behavior_patterns_active) until ctx is cancelled
+func (a *Agent) runPatternMetrics(ctx context.Context) {
+	ticker := time.NewTicker(patternMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		a.samplePatternMetrics(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Agent) samplePatternMetrics(ctx context.Context) {
+	db, err := a.getDBConnection()
+	if err != nil {
+		a.logger.Debug("Skipping pattern metrics", "error", err)
+		return
+	}
+
+	patterns, err := a.createAnchorStorage(db).GetActivePatterns(ctx)
+	if err != nil {
+		a.logger.Warn("Failed to load patterns for metrics", "error", err)
+		return
+	}
+
+	now := time.Now()
+	points := make([]metrics.Point, 0, len(patterns)+1)
+	for _, p := range patterns {
+		points = append(points, metrics.Point{
+			Measurement: "behavior_pattern",
+			Tags: map[string]string{
+				"pattern_id":   p.ID.String(),
+				"pattern_name": p.Name,
+				"pattern_type": p.PatternType,
+			},
+			Fields: map[string]interface{}{
+				"weight":       p.Weight,
+				"observations": p.Observations,
+				"cluster_size": p.ClusterSize,
+				"predictions":  p.Predictions,
+				"acceptances":  p.Acceptances,
+				"rejections":   p.Rejections,
+			},
+			Time: now,
+		})
+	}
+	points = append(points, metrics.Point{
+		Measurement: "behavior_patterns",
+		Fields:      map[string]interface{}{"active": len(patterns)},
+		Time:        now,
+	})
+
+	a.metrics.Write(points...)
+}
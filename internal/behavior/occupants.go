@@ -0,0 +1,136 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// presenceAttributionWindow is how long a presence "home" event attributes
+// activity in the same room to that occupant
+const presenceAttributionWindow = 10 * time.Minute
+
+// presenceEvent is a per-occupant presence observation (BLE beacon, phone)
+type presenceEvent struct {
+	Occupant  string
+	State     string
+	Timestamp time.Time
+}
+
+// loadPresenceEvents reads presence events for a location from Redis, sorted by time.
+// The lookup starts presenceAttributionWindow before since so that events at the
+// start of the range can still be attributed.
+func (a *Agent) loadPresenceEvents(ctx context.Context, location string, since, until time.Time) []presenceEvent {
+	key := redis.PresenceSensorKey(location)
+
+	members, err := a.redis.ZRangeByScoreWithScores(ctx, key,
+		float64(since.Add(-presenceAttributionWindow).UnixMilli()),
+		float64(until.UnixMilli()))
+	if err != nil {
+		a.logger.Debug("No presence data for location", "location", location, "error", err)
+		return nil
+	}
+
+	var events []presenceEvent
+	for _, member := range members {
+		var presenceData struct {
+			Timestamp string `json:"timestamp"`
+			State     string `json:"state"`
+			Occupant  string `json:"occupant"`
+		}
+		if err := json.Unmarshal([]byte(member.Member), &presenceData); err != nil {
+			continue
+		}
+		if presenceData.Occupant == "" {
+			continue
+		}
+
+		ts, err := time.Parse(time.RFC3339, presenceData.Timestamp)
+		if err != nil {
+			continue
+		}
+
+		events = append(events, presenceEvent{
+			Occupant:  presenceData.Occupant,
+			State:     presenceData.State,
+			Timestamp: ts,
+		})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	return events
+}
+
+// isPresentState reports whether a presence state means the occupant is in the room
+func isPresentState(state string) bool {
+	switch strings.ToLower(state) {
+	case "home", "present", "on", "detected", "in":
+		return true
+	default:
+		return false
+	}
+}
+
+// attributeOccupant picks the occupant most recently seen present before ts.
+// Occupants whose latest presence event is a departure, or is older than
+// presenceAttributionWindow, are not considered. Returns "" if nobody matches.
+func attributeOccupant(events []presenceEvent, ts time.Time) string {
+	latest := make(map[string]presenceEvent)
+	for _, event := range events {
+		if event.Timestamp.After(ts) {
+			break
+		}
+		latest[event.Occupant] = event
+	}
+
+	var occupant string
+	var seenAt time.Time
+	for name, event := range latest {
+		if !isPresentState(event.State) || ts.Sub(event.Timestamp) > presenceAttributionWindow {
+			continue
+		}
+		// Ties broken by name so attribution is deterministic
+		if event.Timestamp.After(seenAt) || (event.Timestamp.Equal(seenAt) && name < occupant) {
+			occupant = name
+			seenAt = event.Timestamp
+		}
+	}
+
+	return occupant
+}
+
+// attributeEvents sets Event.Occupant using presence data from the event's location
+func (a *Agent) attributeEvents(ctx context.Context, events []Event, since, until time.Time) {
+	presenceByLocation := make(map[string][]presenceEvent)
+
+	for i := range events {
+		loc := events[i].Location
+		presence, ok := presenceByLocation[loc]
+		if !ok {
+			presence = a.loadPresenceEvents(ctx, loc, since, until)
+			presenceByLocation[loc] = presence
+		}
+		events[i].Occupant = attributeOccupant(presence, events[i].Timestamp)
+	}
+}
+
+// presenceSignal builds the activity signal that carries occupant attribution into anchors
+func presenceSignal(occupant string, timestamp time.Time) types.ActivitySignal {
+	return types.ActivitySignal{
+		Type:       "presence",
+		Confidence: 0.9,
+		Timestamp:  timestamp,
+		Value: map[string]interface{}{
+			"state":    "present",
+			"occupant": occupant,
+		},
+	}
+}
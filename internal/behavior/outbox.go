@@ -0,0 +1,227 @@
+package behavior
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+)
+
+const (
+	// outboxPollInterval is how often the relay retries pending events when
+	// it is not woken by a new one
+	outboxPollInterval = 5 * time.Second
+
+	// outboxBatchSize is how many pending events the relay reads at a time
+	outboxBatchSize = 100
+
+	// outboxRetention is how long published events are kept for inspection
+	outboxRetention = 7 * 24 * time.Hour
+)
+
+// insertEpisodeEvent records an episode lifecycle event in episode_outbox as
+// part of tx, so the event exists exactly when the episode change commits
+func insertEpisodeEvent(ctx context.Context, tx *sql.Tx, event events.EpisodeEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal episode event: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO episode_outbox (event_type, payload) VALUES ($1, $2)",
+		event.Kind, payload,
+	); err != nil {
+		return fmt.Errorf("failed to queue episode event: %w", err)
+	}
+	return nil
+}
+
+// wakeOutboxRelay makes the relay publish newly committed events now
+func (a *Agent) wakeOutboxRelay() {
+	select {
+	case a.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// runOutboxRelay publishes episode events from the outbox until ctx is
+// cancelled. Events stay pending while MQTT is unavailable and go out in
+// order once it is back.
+func (a *Agent) runOutboxRelay(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	lastPrune := time.Time{}
+	for {
+		a.relayOutbox(ctx)
+
+		if time.Since(lastPrune) > time.Hour {
+			a.pruneOutbox(ctx)
+			lastPrune = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-a.outboxWake:
+		}
+	}
+}
+
+// outboxEvent is a pending row of episode_outbox
+type outboxEvent struct {
+	id        int64
+	eventType string
+	payload   []byte
+}
+
+// relayOutbox publishes pending events oldest first. Each batch is claimed
+// with FOR UPDATE SKIP LOCKED, so when several agents share the database
+// only one publishes a given event. It stops at the first publish failure
+// so a later event never overtakes an earlier one; events that can never be
+// published are marked failed and skipped.
+func (a *Agent) relayOutbox(ctx context.Context) {
+	for {
+		more, err := a.relayOutboxBatch(ctx)
+		if err != nil {
+			a.logger.Warn("Failed to relay episode outbox", "error", err)
+			return
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// relayOutboxBatch publishes one claimed batch and reports whether more
+// events may be pending. The published and failed marks commit together at
+// the end, so an event is republished if the agent stops mid-batch.
+func (a *Agent) relayOutboxBatch(ctx context.Context) (bool, error) {
+	more := false
+	err := a.pgClient.Transaction(ctx, func(tx *sql.Tx) error {
+		events, err := claimOutboxEvents(ctx, tx)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		// Another relay holds older events; publishing ours first would
+		// reorder them
+		var oldest int64
+		if err := tx.QueryRowContext(ctx,
+			"SELECT MIN(id) FROM episode_outbox WHERE published_at IS NULL AND failed_at IS NULL",
+		).Scan(&oldest); err != nil {
+			return fmt.Errorf("failed to read oldest outbox event: %w", err)
+		}
+		if oldest < events[0].id {
+			return nil
+		}
+
+		for _, event := range events {
+			topic, payload, err := outboxMessage(event)
+			if err != nil {
+				a.logger.Error("Dropping undeliverable episode event",
+					"event_id", event.id,
+					"event_type", event.eventType,
+					"error", err)
+				if err := markOutboxEventFailed(ctx, tx, event.id, err); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := a.mqtt.Publish(topic, 1, false, payload); err != nil {
+				a.logger.Warn("Failed to publish episode event, will retry",
+					"event_id", event.id,
+					"event_type", event.eventType,
+					"error", err)
+				return nil
+			}
+
+			if _, err := tx.ExecContext(ctx,
+				"UPDATE episode_outbox SET published_at = NOW() WHERE id = $1",
+				event.id,
+			); err != nil {
+				return fmt.Errorf("failed to mark episode event published: %w", err)
+			}
+		}
+
+		more = len(events) == outboxBatchSize
+		return nil
+	})
+	return more, err
+}
+
+// markOutboxEventFailed sets an event aside with the reason it failed
+func markOutboxEventFailed(ctx context.Context, tx *sql.Tx, id int64, cause error) error {
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE episode_outbox SET failed_at = NOW(), error = $2 WHERE id = $1",
+		id, cause.Error(),
+	); err != nil {
+		return fmt.Errorf("failed to mark episode event failed: %w", err)
+	}
+	return nil
+}
+
+// claimOutboxEvents locks the oldest pending events not already claimed by
+// another relay
+func claimOutboxEvents(ctx context.Context, tx *sql.Tx) ([]outboxEvent, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, event_type, payload
+		FROM episode_outbox
+		WHERE published_at IS NULL AND failed_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, outboxBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []outboxEvent
+	for rows.Next() {
+		var event outboxEvent
+		if err := rows.Scan(&event.id, &event.eventType, &event.payload); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// outboxMessage builds the MQTT message for an event, with its outbox id as
+// event_id. An error means the event can never be published.
+func outboxMessage(event outboxEvent) (string, []byte, error) {
+	var episode events.EpisodeEvent
+	if err := json.Unmarshal(event.payload, &episode); err != nil {
+		return "", nil, fmt.Errorf("failed to decode outbox payload: %w", err)
+	}
+	episode.Kind = event.eventType
+	episode.EventID = event.id
+
+	payload, err := events.Marshal(events.ProducerBehavior, episode)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal episode event: %w", err)
+	}
+	return episode.Topic(), payload, nil
+}
+
+// pruneOutbox deletes events published longer ago than outboxRetention.
+// Failed events are kept until removed by hand.
+func (a *Agent) pruneOutbox(ctx context.Context) {
+	result, err := a.pgClient.Exec(ctx,
+		"DELETE FROM episode_outbox WHERE published_at < $1",
+		time.Now().Add(-outboxRetention),
+	)
+	if err != nil {
+		a.logger.Warn("Failed to prune episode outbox", "error", err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		a.logger.Debug("Pruned episode outbox", "deleted", n)
+	}
+}
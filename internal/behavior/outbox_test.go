@@ -0,0 +1,131 @@
+package behavior
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// newOutboxTestAgent returns an agent on db whose oldest pending event is
+// oldest, with the given rows claimed
+func newOutboxTestAgent(db *fakeDB, client mqtt.Client, oldest int64, rows ...[]driver.Value) *Agent {
+	db.on("SKIP LOCKED", []string{"id", "event_type", "payload"}, rows...)
+	db.on("MIN(id)", []string{"min"}, []driver.Value{oldest})
+	return &Agent{
+		pgClient: db.client(),
+		mqtt:     client,
+		logger:   slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+}
+
+func outboxRow(id int64, eventType, payload string) []driver.Value {
+	return []driver.Value{id, eventType, []byte(payload)}
+}
+
+func TestRelayOutbox_PublishesInOrder(t *testing.T) {
+	db := &fakeDB{}
+	client := &publishCounter{}
+	agent := newOutboxTestAgent(db, client, 1,
+		outboxRow(1, "started", `{"episode_id":"a","location":"kitchen"}`),
+		outboxRow(2, "closed", `{"episode_id":"a","location":"kitchen"}`),
+	)
+
+	agent.relayOutbox(context.Background())
+
+	want := []string{"automation/behavior/episode/started", "automation/behavior/episode/closed"}
+	if len(client.topics) != len(want) {
+		t.Fatalf("Expected topics %v, got %v", want, client.topics)
+	}
+	for i := range want {
+		if client.topics[i] != want[i] {
+			t.Errorf("Expected topic %d to be %s, got %s", i, want[i], client.topics[i])
+		}
+	}
+
+	marked := db.executed("SET published_at")
+	if len(marked) != 2 || marked[0].args[0] != int64(1) || marked[1].args[0] != int64(2) {
+		t.Errorf("Expected events 1 and 2 marked published, got %v", marked)
+	}
+	for _, stmt := range marked {
+		if !stmt.inTx {
+			t.Error("Expected the published marks in the claiming transaction")
+		}
+	}
+	if db.commits != 1 {
+		t.Errorf("Expected 1 commit, got %d", db.commits)
+	}
+}
+
+func TestRelayOutbox_SkipsUndecodableEvent(t *testing.T) {
+	db := &fakeDB{}
+	client := &publishCounter{}
+	agent := newOutboxTestAgent(db, client, 1,
+		outboxRow(1, "started", `not json`),
+		outboxRow(2, "started", `{"episode_id":"b","location":"hallway"}`),
+	)
+
+	agent.relayOutbox(context.Background())
+
+	failed := db.executed("SET failed_at")
+	if len(failed) != 1 || failed[0].args[0] != int64(1) {
+		t.Fatalf("Expected event 1 marked failed, got %v", failed)
+	}
+	if msg, _ := failed[0].args[1].(string); msg == "" {
+		t.Error("Expected the failure reason to be recorded")
+	}
+
+	if client.published != 1 {
+		t.Errorf("Expected the later event to be published, got %d publishes", client.published)
+	}
+	marked := db.executed("SET published_at")
+	if len(marked) != 1 || marked[0].args[0] != int64(2) {
+		t.Errorf("Expected event 2 marked published, got %v", marked)
+	}
+	if db.commits != 1 {
+		t.Errorf("Expected 1 commit, got %d", db.commits)
+	}
+}
+
+func TestRelayOutbox_StopsOnPublishError(t *testing.T) {
+	db := &fakeDB{}
+	client := &publishCounter{err: errors.New("not connected")}
+	agent := newOutboxTestAgent(db, client, 1,
+		outboxRow(1, "started", `{"episode_id":"a","location":"kitchen"}`),
+		outboxRow(2, "closed", `{"episode_id":"a","location":"kitchen"}`),
+	)
+
+	agent.relayOutbox(context.Background())
+
+	if n := len(db.executed("SET published_at")); n != 0 {
+		t.Errorf("Expected no event marked published, got %d", n)
+	}
+	if n := len(db.executed("SET failed_at")); n != 0 {
+		t.Errorf("Expected publish errors to be retried rather than failed, got %d failed", n)
+	}
+	if n := len(db.executed("SKIP LOCKED")); n != 1 {
+		t.Errorf("Expected the relay to stop after the failed batch, got %d claims", n)
+	}
+}
+
+func TestRelayOutbox_WaitsForOlderClaim(t *testing.T) {
+	db := &fakeDB{}
+	client := &publishCounter{}
+	// Events 1-4 are claimed by another relay, so this one only sees 5
+	agent := newOutboxTestAgent(db, client, 1,
+		outboxRow(5, "started", `{"episode_id":"c","location":"bedroom"}`),
+	)
+
+	agent.relayOutbox(context.Background())
+
+	if client.published != 0 {
+		t.Errorf("Expected no publish ahead of older events, got %d", client.published)
+	}
+	if n := len(db.executed("SET published_at")); n != 0 {
+		t.Errorf("Expected no event marked published, got %d", n)
+	}
+}
@@ -0,0 +1,167 @@
+package patterns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// centroidRefreshInterval bounds how stale the cached centroids may get before
+// they are reloaded (picks up patterns created or merged by other jobs)
+const centroidRefreshInterval = 10 * time.Minute
+
+// patternCentroid is a cached centroid that is updated as anchors are assigned
+type patternCentroid struct {
+	vector   []float32
+	count    int
+	occupant string
+}
+
+// PatternAssigner attaches newly created anchors to existing patterns whose
+// centroid lies within epsilon, so patterns pick up new observations immediately
+// instead of waiting for the next full discovery run.
+type PatternAssigner struct {
+	epsilon float64
+	storage *storage.AnchorStorage
+	mqtt    mqtt.Client
+	logger  *slog.Logger
+
+	mu          sync.Mutex
+	centroids   map[uuid.UUID]*patternCentroid
+	refreshedAt time.Time
+}
+
+// NewPatternAssigner creates a new incremental pattern assigner
+func NewPatternAssigner(
+	epsilon float64,
+	storage *storage.AnchorStorage,
+	mqttClient mqtt.Client,
+	logger *slog.Logger,
+) *PatternAssigner {
+	return &PatternAssigner{
+		epsilon: epsilon,
+		storage: storage,
+		mqtt:    mqttClient,
+		logger:  logger.With("component", "pattern_assigner"),
+	}
+}
+
+// AssignAnchor attaches anchor to the nearest pattern within epsilon. Returns the
+// pattern ID and true when assigned; unassigned anchors are left for discovery.
+func (p *PatternAssigner) AssignAnchor(ctx context.Context, anchor *types.SemanticAnchor) (uuid.UUID, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.refreshLocked(ctx); err != nil {
+		return uuid.Nil, false, err
+	}
+
+	occupant := ""
+	if anchor.Occupant != nil {
+		occupant = *anchor.Occupant
+	}
+
+	embedding := anchor.SemanticEmbedding.Slice()
+	patternID, ok := nearestCentroid(embedding, p.candidatesLocked(occupant), p.epsilon)
+	if !ok {
+		return uuid.Nil, false, nil
+	}
+
+	if err := p.storage.UpdateAnchorPattern(ctx, anchor.ID, patternID); err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to assign anchor: %w", err)
+	}
+	if err := p.storage.UpdatePatternObserved(ctx, patternID); err != nil {
+		p.logger.Warn("Failed to record pattern observation", "pattern_id", patternID, "error", err)
+	}
+
+	// Keep the cached centroid current without reloading
+	centroid := p.centroids[patternID]
+	centroid.vector = updateCentroid(centroid.vector, centroid.count, embedding)
+	centroid.count++
+
+	anchor.PatternID = &patternID
+
+	p.logger.Debug("Anchor assigned to existing pattern",
+		"anchor_id", anchor.ID,
+		"pattern_id", patternID,
+		"location", anchor.Location)
+
+	p.publishAssignment(anchor, patternID)
+	return patternID, true, nil
+}
+
+// refreshLocked reloads centroids when stale (caller holds the lock)
+func (p *PatternAssigner) refreshLocked(ctx context.Context) error {
+	if p.centroids != nil && time.Since(p.refreshedAt) < centroidRefreshInterval {
+		return nil
+	}
+
+	list, err := p.storage.GetPatternCentroids(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load pattern centroids: %w", err)
+	}
+
+	p.centroids = make(map[uuid.UUID]*patternCentroid, len(list))
+	for _, c := range list {
+		p.centroids[c.PatternID] = &patternCentroid{
+			vector:   c.Centroid.Slice(),
+			count:    c.AnchorCount,
+			occupant: c.Occupant,
+		}
+	}
+	p.refreshedAt = time.Now()
+
+	p.logger.Debug("Pattern centroids refreshed", "patterns", len(p.centroids))
+	return nil
+}
+
+// candidatesLocked returns centroids an anchor may join. An occupant's anchor never
+// joins another occupant's pattern; household patterns accept anyone.
+func (p *PatternAssigner) candidatesLocked(occupant string) map[uuid.UUID][]float32 {
+	candidates := make(map[uuid.UUID][]float32, len(p.centroids))
+	for id, c := range p.centroids {
+		if c.occupant != "" && occupant != "" && c.occupant != occupant {
+			continue
+		}
+		candidates[id] = c.vector
+	}
+	return candidates
+}
+
+func (p *PatternAssigner) publishAssignment(anchor *types.SemanticAnchor, patternID uuid.UUID) {
+	payload, err := events.Marshal(events.ProducerBehavior, map[string]interface{}{
+		"anchor_id":  anchor.ID,
+		"pattern_id": patternID,
+		"location":   anchor.Location,
+		"timestamp":  anchor.Timestamp.Format(time.RFC3339),
+	})
+	if err != nil {
+		return
+	}
+	if err := p.mqtt.Publish("automation/behavior/patterns/assigned", 0, false, payload); err != nil {
+		p.logger.Debug("Failed to publish anchor assignment", "error", err)
+	}
+}
+
+// updateCentroid folds one more vector into a running mean of count vectors
+func updateCentroid(centroid []float32, count int, vector []float32) []float32 {
+	if len(centroid) != len(vector) {
+		return centroid
+	}
+
+	updated := make([]float32, len(centroid))
+	n := float32(count)
+	for i := range centroid {
+		updated[i] = (centroid[i]*n + vector[i]) / (n + 1)
+	}
+	return updated
+}
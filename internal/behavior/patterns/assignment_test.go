@@ -0,0 +1,69 @@
+package patterns
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUpdateCentroid(t *testing.T) {
+	centroid := []float32{1, 0}
+
+	updated := updateCentroid(centroid, 3, []float32{0, 1})
+
+	expected := []float32{0.75, 0.25}
+	for i := range expected {
+		if math.Abs(float64(updated[i]-expected[i])) > 1e-6 {
+			t.Errorf("Expected %v, got %v", expected, updated)
+			break
+		}
+	}
+	if centroid[0] != 1 {
+		t.Error("Expected original centroid to be left untouched")
+	}
+}
+
+func TestUpdateCentroid_DimensionMismatch(t *testing.T) {
+	centroid := []float32{1, 0}
+
+	if updated := updateCentroid(centroid, 1, []float32{1, 0, 0}); len(updated) != 2 {
+		t.Errorf("Expected centroid unchanged on dimension mismatch, got %v", updated)
+	}
+}
+
+func TestAssignerCandidates(t *testing.T) {
+	household := uuid.New()
+	alice := uuid.New()
+	bob := uuid.New()
+
+	assigner := &PatternAssigner{centroids: map[uuid.UUID]*patternCentroid{
+		household: {vector: []float32{1, 0}},
+		alice:     {vector: []float32{1, 0}, occupant: "alice"},
+		bob:       {vector: []float32{1, 0}, occupant: "bob"},
+	}}
+
+	tests := []struct {
+		name     string
+		occupant string
+		expected []uuid.UUID
+	}{
+		{"unattributed anchor joins any pattern", "", []uuid.UUID{household, alice, bob}},
+		{"occupant anchor skips other occupants", "alice", []uuid.UUID{household, alice}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := assigner.candidatesLocked(tt.occupant)
+
+			if len(candidates) != len(tt.expected) {
+				t.Fatalf("Expected %d candidates, got %d", len(tt.expected), len(candidates))
+			}
+			for _, id := range tt.expected {
+				if _, ok := candidates[id]; !ok {
+					t.Errorf("Expected pattern %s among candidates", id)
+				}
+			}
+		})
+	}
+}
@@ -10,8 +10,13 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/saaga0h/jeeves-platform/internal/behavior/clustering"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/progress"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/internal/notify"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 )
 
@@ -21,26 +26,37 @@ type TimeManager interface {
 	IsTestMode() bool
 }
 
+// JobGate runs a job exclusively across agents, queuing requests that arrive
+// while it runs
+type JobGate interface {
+	Run(ctx context.Context, job, correlationID string, fn func(ctx context.Context) error) error
+}
+
 // DiscoveryConfig configures pattern discovery behavior
 type DiscoveryConfig struct {
 	Interval                      time.Duration // production: 24h, tests: triggered
+	Schedule                      clock.Schedule // overrides Interval, e.g. a nightly cron expression
+	Jitter                        time.Duration // random delay added to each scheduled run
 	MinAnchors                    int           // minimum anchors needed (default: 10)
 	LookbackHours                 int           // how far back to analyze
 	TemporalGroupingEnabled       bool          // enable multi-stage clustering
 	TemporalGroupingWindow        time.Duration // window size for grouping
 	TemporalGroupingOverlapRatio  float64       // overlap threshold for parallelism
 	UseLocationTemporalClustering bool          // NEW: use location-aware temporal clustering
+	PerOccupantClustering         bool          // cluster each occupant's anchors separately
 }
 
 // DiscoveryAgent orchestrates clustering and pattern interpretation
 type DiscoveryAgent struct {
 	config      DiscoveryConfig
-	storage     *storage.AnchorStorage
+	storage     storage.AnchorRepository
 	clustering  *clustering.ClusteringEngine
 	interpreter *PatternInterpreter
 	mqtt        mqtt.Client
 	logger      *slog.Logger
 	timeManager TimeManager
+	notifier    *notify.Notifier
+	gate        JobGate
 
 	// Test mode support
 	testMode     bool
@@ -56,7 +72,7 @@ type TriggerEvent struct {
 // NewDiscoveryAgent creates a new pattern discovery agent
 func NewDiscoveryAgent(
 	config DiscoveryConfig,
-	storage *storage.AnchorStorage,
+	storage storage.AnchorRepository,
 	clustering *clustering.ClusteringEngine,
 	interpreter *PatternInterpreter,
 	mqttClient mqtt.Client,
@@ -75,6 +91,17 @@ func NewDiscoveryAgent(
 	}
 }
 
+// SetNotifier enables webhook delivery of newly discovered patterns
+func (a *DiscoveryAgent) SetNotifier(n *notify.Notifier) {
+	a.notifier = n
+}
+
+// SetJobGate runs every discovery through gate, so triggers and agents do not
+// discover at the same time
+func (a *DiscoveryAgent) SetJobGate(gate JobGate) {
+	a.gate = gate
+}
+
 // EnableTestMode switches to test mode (trigger-based instead of interval-based)
 func (a *DiscoveryAgent) EnableTestMode() {
 	a.testMode = true
@@ -93,7 +120,7 @@ func (a *DiscoveryAgent) Start(ctx context.Context) error {
 		for {
 			select {
 			case trigger := <-a.testTriggers:
-				if err := a.discoverPatterns(ctx, trigger.MinAnchors, trigger.LookbackHours); err != nil {
+				if err := a.discover(ctx, trigger.MinAnchors, trigger.LookbackHours); err != nil {
 					a.logger.Error("Pattern discovery failed", "error", err)
 				}
 			case <-ctx.Done():
@@ -104,20 +131,25 @@ func (a *DiscoveryAgent) Start(ctx context.Context) error {
 
 	// Production mode: periodic execution AND MQTT triggers
 	a.logger.Info("Pattern discovery agent running in production mode",
-		"interval", a.config.Interval)
+		"interval", a.config.Interval,
+		"scheduled", a.config.Schedule != nil)
 
-	ticker := time.NewTicker(a.config.Interval)
+	schedule := a.config.Schedule
+	if schedule == nil {
+		schedule = clock.Every(a.config.Interval)
+	}
+	ticker := clock.NewScheduler(a.timeManager, schedule, a.config.Jitter)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case trigger := <-a.testTriggers:
 			// Also process MQTT triggers in production mode (for test scenarios)
-			if err := a.discoverPatterns(ctx, trigger.MinAnchors, trigger.LookbackHours); err != nil {
+			if err := a.discover(ctx, trigger.MinAnchors, trigger.LookbackHours); err != nil {
 				a.logger.Error("Pattern discovery failed", "error", err)
 			}
 		case <-ticker.C:
-			if err := a.discoverPatterns(ctx, a.config.MinAnchors, a.config.LookbackHours); err != nil {
+			if err := a.discover(ctx, a.config.MinAnchors, a.config.LookbackHours); err != nil {
 				a.logger.Error("Pattern discovery failed", "error", err)
 			}
 		case <-ctx.Done():
@@ -126,6 +158,16 @@ func (a *DiscoveryAgent) Start(ctx context.Context) error {
 	}
 }
 
+// discover runs discoverPatterns through the job gate, if any
+func (a *DiscoveryAgent) discover(ctx context.Context, minAnchors, lookbackHours int) error {
+	if a.gate == nil {
+		return a.discoverPatterns(ctx, minAnchors, lookbackHours)
+	}
+	return a.gate.Run(ctx, events.JobDiscovery, logging.CorrelationID(ctx), func(ctx context.Context) error {
+		return a.discoverPatterns(ctx, minAnchors, lookbackHours)
+	})
+}
+
 func (a *DiscoveryAgent) handleTrigger(msg mqtt.Message) {
 	var trigger struct {
 		MinAnchors    int `json:"min_anchors"`
@@ -150,7 +192,7 @@ func (a *DiscoveryAgent) handleTrigger(msg mqtt.Message) {
 
 // DiscoverPatternsWithLookback performs pattern discovery with the specified lookback period (for batch coordinator)
 func (a *DiscoveryAgent) DiscoverPatternsWithLookback(ctx context.Context, minAnchors, lookbackHours int) (int, error) {
-	if err := a.discoverPatterns(ctx, minAnchors, lookbackHours); err != nil {
+	if err := a.discover(ctx, minAnchors, lookbackHours); err != nil {
 		return 0, err
 	}
 	// TODO: Return actual count of patterns created
@@ -199,7 +241,191 @@ func (a *DiscoveryAgent) discoverPatternsInWindow(
 
 	a.logger.Info("Clustering anchors in window", "count", len(anchors))
 
-	// Use the same multi-stage clustering logic as discoverPatterns
+	// Use the same multi-stage clustering logic as discoverPatterns, per occupant when enabled
+	var validClusters []*clustering.Cluster
+
+	for _, group := range a.partitionAnchors(anchors) {
+		if len(group) < minAnchors {
+			continue
+		}
+		validClusters = append(validClusters, a.findValidClustersInWindow(ctx, group, minAnchors)...)
+	}
+
+	a.logger.Info("Valid clusters found", "count", len(validClusters))
+
+	if len(validClusters) == 0 {
+		a.publishCompletion(0)
+		return nil
+	}
+
+	// Interpret and create patterns
+	patternsCreated := 0
+	for _, cluster := range validClusters {
+		pattern, err := a.interpreter.InterpretCluster(ctx, cluster.Members)
+		if err != nil {
+			a.logger.Error("Failed to interpret cluster", "error", err)
+			continue
+		}
+
+		if err := a.storage.CreatePattern(ctx, pattern); err != nil {
+			a.logger.Error("Failed to store pattern", "error", err)
+			continue
+		}
+
+		for _, anchorID := range cluster.Members {
+			if err := a.storage.UpdateAnchorPattern(ctx, anchorID, pattern.ID); err != nil {
+				a.logger.Warn("Failed to update anchor pattern", "error", err)
+			}
+		}
+
+		a.notifyPattern(pattern, len(cluster.Members))
+		patternsCreated++
+	}
+
+	duration := time.Since(startTime)
+	a.logger.Info("Pattern discovery in window completed",
+		"patterns_created", patternsCreated,
+		"duration", duration)
+
+	a.publishCompletion(patternsCreated)
+	return nil
+}
+
+// discoverPatterns performs pattern discovery from recent anchors
+func (a *DiscoveryAgent) discoverPatterns(ctx context.Context, minAnchors, lookbackHours int) error {
+	startTime := a.timeManager.Now()
+
+	currentTime := a.timeManager.Now()
+	since := currentTime.Add(-time.Duration(lookbackHours) * time.Hour)
+
+	a.logger.Info("Starting pattern discovery",
+		"min_anchors", minAnchors,
+		"lookback_hours", lookbackHours,
+		"current_time", currentTime,
+		"since", since)
+
+	// Get recent anchors (distances will be computed in-memory during clustering)
+	progress.Phase(ctx, "loading_anchors", nil)
+	anchors, err := a.storage.GetAnchorsSince(ctx, since)
+	if err != nil {
+		return fmt.Errorf("failed to get anchors: %w", err)
+	}
+
+	if len(anchors) < minAnchors {
+		a.logger.Info("Insufficient anchors for pattern discovery",
+			"found", len(anchors),
+			"required", minAnchors)
+		progress.Phase(ctx, "complete", map[string]interface{}{"anchors": len(anchors), "patterns_created": 0})
+		a.publishCompletion(0)
+		return nil
+	}
+
+	a.logger.Info("Clustering anchors", "count", len(anchors))
+	progress.Phase(ctx, "clustering", map[string]interface{}{"anchors": len(anchors)})
+
+	// NEW: Location-temporal clustering path
+	if a.config.UseLocationTemporalClustering {
+		return a.discoverPatternsWithLocationTemporal(ctx, anchors, minAnchors, startTime)
+	}
+
+	// Multi-stage clustering, run independently per occupant when enabled
+	var validClusters []*clustering.Cluster
+
+	for _, group := range a.partitionAnchors(anchors) {
+		if len(group) < minAnchors {
+			a.logger.Debug("Skipping occupant partition (too few anchors)",
+				"occupant", occupantLabel(group),
+				"anchor_count", len(group),
+				"required", minAnchors)
+			continue
+		}
+
+		clusters, err := a.findValidClusters(ctx, group, minAnchors)
+		if err != nil {
+			return err
+		}
+		validClusters = append(validClusters, clusters...)
+	}
+
+	a.logger.Info("Valid clusters found", "count", len(validClusters))
+
+	if len(validClusters) == 0 {
+		a.logger.Info("No valid clusters found")
+		progress.Phase(ctx, "complete", map[string]interface{}{"anchors": len(anchors), "clusters": 0, "patterns_created": 0})
+		a.publishCompletion(0)
+		return nil
+	}
+
+	// Interpret each cluster as a pattern
+	progress.Phase(ctx, "interpreting", map[string]interface{}{"clusters": len(validClusters)})
+	patternsCreated := 0
+
+	for _, cluster := range validClusters {
+		pattern, err := a.interpreter.InterpretCluster(ctx, cluster.Members)
+		if err != nil {
+			a.logger.Error("Failed to interpret cluster",
+				"cluster_id", cluster.ID,
+				"error", err)
+			continue
+		}
+
+		// Store pattern
+		if err := a.storage.CreatePattern(ctx, pattern); err != nil {
+			a.logger.Error("Failed to store pattern",
+				"pattern_id", pattern.ID,
+				"error", err)
+			continue
+		}
+
+		// Update anchors to reference this pattern
+		for _, anchorID := range cluster.Members {
+			if err := a.storage.UpdateAnchorPattern(ctx, anchorID, pattern.ID); err != nil {
+				a.logger.Warn("Failed to update anchor pattern",
+					"anchor_id", anchorID,
+					"pattern_id", pattern.ID,
+					"error", err)
+			}
+		}
+
+		a.notifyPattern(pattern, len(cluster.Members))
+		patternsCreated++
+	}
+
+	duration := time.Since(startTime)
+
+	a.logger.Info("Pattern discovery completed",
+		"anchors_analyzed", len(anchors),
+		"clusters_found", len(validClusters),
+		"patterns_created", patternsCreated,
+		"duration", duration)
+
+	// Publish completion event
+	progress.Phase(ctx, "complete", map[string]interface{}{
+		"anchors":          len(anchors),
+		"clusters":         len(validClusters),
+		"patterns_created": patternsCreated,
+	})
+	a.publishCompletion(patternsCreated)
+
+	return nil
+}
+
+// findValidClustersInWindow runs two-phase clustering over one anchor set from a batch window
+func (a *DiscoveryAgent) findValidClustersInWindow(
+	ctx context.Context,
+	anchors []*types.SemanticAnchor,
+	minAnchors int,
+) []*clustering.Cluster {
+	// HDBSCAN adapts to local density, so the per-phase epsilons below are not needed
+	if !a.clustering.UsesEpsilon() {
+		clusters, err := a.findValidClusters(ctx, anchors, minAnchors)
+		if err != nil {
+			a.logger.Error("Density-adaptive clustering failed", "error", err)
+			return nil
+		}
+		return clusters
+	}
+
 	var validClusters []*clustering.Cluster
 
 	if a.config.TemporalGroupingEnabled {
@@ -355,80 +581,16 @@ func (a *DiscoveryAgent) discoverPatternsInWindow(
 		}
 	}
 
-	a.logger.Info("Valid clusters found", "count", len(validClusters))
-
-	if len(validClusters) == 0 {
-		a.publishCompletion(0)
-		return nil
-	}
-
-	// Interpret and create patterns
-	patternsCreated := 0
-	for _, cluster := range validClusters {
-		pattern, err := a.interpreter.InterpretCluster(ctx, cluster.Members)
-		if err != nil {
-			a.logger.Error("Failed to interpret cluster", "error", err)
-			continue
-		}
-
-		if err := a.storage.CreatePattern(ctx, pattern); err != nil {
-			a.logger.Error("Failed to store pattern", "error", err)
-			continue
-		}
-
-		for _, anchorID := range cluster.Members {
-			if err := a.storage.UpdateAnchorPattern(ctx, anchorID, pattern.ID); err != nil {
-				a.logger.Warn("Failed to update anchor pattern", "error", err)
-			}
-		}
-
-		patternsCreated++
-	}
-
-	duration := time.Since(startTime)
-	a.logger.Info("Pattern discovery in window completed",
-		"patterns_created", patternsCreated,
-		"duration", duration)
-
-	a.publishCompletion(patternsCreated)
-	return nil
+	return validClusters
 }
 
-// discoverPatterns performs pattern discovery from recent anchors
-func (a *DiscoveryAgent) discoverPatterns(ctx context.Context, minAnchors, lookbackHours int) error {
-	startTime := a.timeManager.Now()
-
-	currentTime := a.timeManager.Now()
-	since := currentTime.Add(-time.Duration(lookbackHours) * time.Hour)
-
-	a.logger.Info("Starting pattern discovery",
-		"min_anchors", minAnchors,
-		"lookback_hours", lookbackHours,
-		"current_time", currentTime,
-		"since", since)
-
-	// Get recent anchors (distances will be computed in-memory during clustering)
-	anchors, err := a.storage.GetAnchorsSince(ctx, since)
-	if err != nil {
-		return fmt.Errorf("failed to get anchors: %w", err)
-	}
-
-	if len(anchors) < minAnchors {
-		a.logger.Info("Insufficient anchors for pattern discovery",
-			"found", len(anchors),
-			"required", minAnchors)
-		a.publishCompletion(0)
-		return nil
-	}
-
-	a.logger.Info("Clustering anchors", "count", len(anchors))
-
-	// NEW: Location-temporal clustering path
-	if a.config.UseLocationTemporalClustering {
-		return a.discoverPatternsWithLocationTemporal(ctx, anchors, minAnchors, startTime)
-	}
-
-	// Multi-stage clustering: check if temporal grouping is enabled
+// findValidClusters runs multi-stage (or single-stage) DBSCAN over one anchor set
+// and returns the non-noise clusters that satisfy minAnchors
+func (a *DiscoveryAgent) findValidClusters(
+	ctx context.Context,
+	anchors []*types.SemanticAnchor,
+	minAnchors int,
+) ([]*clustering.Cluster, error) {
 	var validClusters []*clustering.Cluster
 
 	if a.config.TemporalGroupingEnabled {
@@ -553,7 +715,7 @@ func (a *DiscoveryAgent) discoverPatterns(ctx context.Context, minAnchors, lookb
 		// Perform clustering
 		clusters, err := a.clustering.ClusterAnchors(ctx, anchorIDs)
 		if err != nil {
-			return fmt.Errorf("clustering failed: %w", err)
+			return nil, fmt.Errorf("clustering failed: %w", err)
 		}
 
 		// Filter out noise cluster and small clusters
@@ -564,69 +726,28 @@ func (a *DiscoveryAgent) discoverPatterns(ctx context.Context, minAnchors, lookb
 		}
 	}
 
-	a.logger.Info("Valid clusters found", "count", len(validClusters))
-
-	if len(validClusters) == 0 {
-		a.logger.Info("No valid clusters found")
-		a.publishCompletion(0)
-		return nil
-	}
-
-	// Interpret each cluster as a pattern
-	patternsCreated := 0
-
-	for _, cluster := range validClusters {
-		pattern, err := a.interpreter.InterpretCluster(ctx, cluster.Members)
-		if err != nil {
-			a.logger.Error("Failed to interpret cluster",
-				"cluster_id", cluster.ID,
-				"error", err)
-			continue
-		}
-
-		// Store pattern
-		if err := a.storage.CreatePattern(ctx, pattern); err != nil {
-			a.logger.Error("Failed to store pattern",
-				"pattern_id", pattern.ID,
-				"error", err)
-			continue
-		}
-
-		// Update anchors to reference this pattern
-		for _, anchorID := range cluster.Members {
-			if err := a.storage.UpdateAnchorPattern(ctx, anchorID, pattern.ID); err != nil {
-				a.logger.Warn("Failed to update anchor pattern",
-					"anchor_id", anchorID,
-					"pattern_id", pattern.ID,
-					"error", err)
-			}
-		}
-
-		patternsCreated++
-	}
-
-	duration := time.Since(startTime)
-
-	a.logger.Info("Pattern discovery completed",
-		"anchors_analyzed", len(anchors),
-		"clusters_found", len(validClusters),
-		"patterns_created", patternsCreated,
-		"duration", duration)
-
-	// Publish completion event
-	a.publishCompletion(patternsCreated)
+	return validClusters, nil
+}
 
-	return nil
+func (a *DiscoveryAgent) notifyPattern(pattern *types.BehavioralPattern, anchorCount int) {
+	a.notifier.Notify(notify.EventPatternDiscovered, map[string]interface{}{
+		"pattern_id":   pattern.ID.String(),
+		"name":         pattern.Name,
+		"description":  pattern.Description,
+		"pattern_type": pattern.PatternType,
+		"locations":    pattern.Locations,
+		"anchor_count": anchorCount,
+	})
 }
 
 func (a *DiscoveryAgent) publishCompletion(patternsCreated int) {
-	payload := map[string]interface{}{
-		"patterns_created": patternsCreated,
-		"timestamp":        time.Now().Format(time.RFC3339),
+	completion := events.PatternDiscoveryCompletion{
+		PatternsCreated: patternsCreated,
+		Timestamp:       time.Now().Format(time.RFC3339),
 	}
 
-	payloadBytes, _ := json.Marshal(payload)
-	if err := a.mqtt.Publish("automation/behavior/patterns/discovered", 0, false, payloadBytes); err != nil {
+	payloadBytes, _ := events.Marshal(events.ProducerBehavior, completion)
+	if err := a.mqtt.Publish(completion.Topic(), 0, false, payloadBytes); err != nil {
 		a.logger.Error("Failed to publish completion", "error", err)
 	} else {
 		a.logger.Info("Published pattern discovery completion",
@@ -646,13 +767,18 @@ func (a *DiscoveryAgent) discoverPatternsWithLocationTemporal(
 		"min_anchors", minAnchors)
 
 	// STEP 1: Location-temporal clustering
+	progress.Phase(ctx, "clustering", map[string]interface{}{"anchors": len(anchors)})
 	clusterer := NewLocationTemporalClusterer(a.logger)
-	sequences := clusterer.ClusterByLocationTemporal(anchors)
+	var sequences []*ActivitySequence
+	for _, group := range a.partitionAnchors(anchors) {
+		sequences = append(sequences, clusterer.ClusterByLocationTemporal(group)...)
+	}
 
 	a.logger.Info("Location-temporal clustering complete",
 		"sequences_found", len(sequences))
 
 	// STEP 2: Semantic validation
+	progress.Phase(ctx, "validating", map[string]interface{}{"sequences": len(sequences)})
 	validator := NewSemanticValidator(a.logger)
 	var validSequences []*ActivitySequence
 
@@ -692,11 +818,13 @@ func (a *DiscoveryAgent) discoverPatternsWithLocationTemporal(
 
 	if len(validSequences) == 0 {
 		a.logger.Info("No valid sequences found")
+		progress.Phase(ctx, "complete", map[string]interface{}{"anchors": len(anchors), "sequences": 0, "patterns_created": 0})
 		a.publishCompletion(0)
 		return nil
 	}
 
 	// STEP 3: Convert sequences to patterns
+	progress.Phase(ctx, "interpreting", map[string]interface{}{"sequences": len(validSequences)})
 	patternsCreated := 0
 
 	for _, seq := range validSequences {
@@ -742,6 +870,7 @@ func (a *DiscoveryAgent) discoverPatternsWithLocationTemporal(
 			}
 		}
 
+		a.notifyPattern(pattern, len(anchorIDs))
 		patternsCreated++
 
 		a.logger.Info("Pattern created from sequence",
@@ -762,6 +891,11 @@ func (a *DiscoveryAgent) discoverPatternsWithLocationTemporal(
 		"duration", duration)
 
 	// Publish completion event
+	progress.Phase(ctx, "complete", map[string]interface{}{
+		"anchors":          len(anchors),
+		"sequences":        len(validSequences),
+		"patterns_created": patternsCreated,
+	})
 	a.publishCompletion(patternsCreated)
 
 	return nil
@@ -0,0 +1,157 @@
+package patterns
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+// Explanation shows why a pattern exists: its member anchors nearest the
+// centroid first, the context they share, and the LLM's interpretation
+type Explanation struct {
+	Pattern         *types.BehavioralPattern     `json:"pattern"`
+	AnchorCount     int                          `json:"anchor_count"`
+	CentroidAnchor  *ExplainedAnchor             `json:"centroid_anchor,omitempty"` // member nearest the centroid
+	CentroidContext map[string]ValueShare        `json:"centroid_context"`          // most common value of each context key
+	TimesOfDay      []ValueShare                 `json:"times_of_day"`
+	DayTypes        []ValueShare                 `json:"day_types"`
+	Locations       []ValueShare                 `json:"locations"`
+	Anchors         []ExplainedAnchor            `json:"anchors"`
+	Interpretation  *types.PatternInterpretation `json:"interpretation,omitempty"`
+}
+
+// ValueShare is how many member anchors have a value, and their share of all members
+type ValueShare struct {
+	Value string  `json:"value"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// ExplainedAnchor is a member anchor with its cosine distance to the centroid
+type ExplainedAnchor struct {
+	ID                 uuid.UUID              `json:"id"`
+	Timestamp          time.Time              `json:"timestamp"`
+	Location           string                 `json:"location"`
+	Occupant           *string                `json:"occupant,omitempty"`
+	Context            map[string]interface{} `json:"context"`
+	DistanceToCentroid float64                `json:"distance_to_centroid"`
+}
+
+// Explain builds the explanation of pattern from its member anchors, listing
+// at most limit anchors (all when limit <= 0)
+func Explain(pattern *types.BehavioralPattern, anchors []*types.SemanticAnchor, limit int) *Explanation {
+	explanation := &Explanation{
+		Pattern:         pattern,
+		AnchorCount:     len(anchors),
+		CentroidContext: make(map[string]ValueShare),
+		TimesOfDay:      []ValueShare{},
+		DayTypes:        []ValueShare{},
+		Locations:       []ValueShare{},
+		Anchors:         []ExplainedAnchor{},
+		Interpretation:  pattern.Interpretation,
+	}
+	if len(anchors) == 0 {
+		return explanation
+	}
+
+	centroid := meanEmbedding(anchors)
+	members := make([]ExplainedAnchor, len(anchors))
+	for i, anchor := range anchors {
+		members[i] = ExplainedAnchor{
+			ID:                 anchor.ID,
+			Timestamp:          anchor.Timestamp,
+			Location:           anchor.Location,
+			Occupant:           anchor.Occupant,
+			Context:            anchor.Context,
+			DistanceToCentroid: 1 - cosineSimilaritySlice(anchor.SemanticEmbedding.Slice(), centroid),
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].DistanceToCentroid < members[j].DistanceToCentroid
+	})
+
+	explanation.CentroidAnchor = &members[0]
+	if limit > 0 && len(members) > limit {
+		members = members[:limit]
+	}
+	explanation.Anchors = members
+
+	locations := make([]string, len(anchors))
+	for i, anchor := range anchors {
+		locations[i] = anchor.Location
+	}
+	explanation.Locations = shares(locations)
+	explanation.TimesOfDay = shares(contextValues(anchors, "time_of_day"))
+	explanation.DayTypes = shares(contextValues(anchors, "day_type"))
+
+	keys := make(map[string]bool)
+	for _, anchor := range anchors {
+		for key, value := range anchor.Context {
+			if _, ok := value.(string); ok {
+				keys[key] = true
+			}
+		}
+	}
+	for key := range keys {
+		if top := shares(contextValues(anchors, key)); len(top) > 0 {
+			// Share of all members, not only those carrying the key
+			top[0].Share = float64(top[0].Count) / float64(len(anchors))
+			explanation.CentroidContext[key] = top[0]
+		}
+	}
+
+	return explanation
+}
+
+// meanEmbedding averages the anchors' embeddings
+func meanEmbedding(anchors []*types.SemanticAnchor) []float32 {
+	var mean []float32
+	for _, anchor := range anchors {
+		embedding := anchor.SemanticEmbedding.Slice()
+		if mean == nil {
+			mean = make([]float32, len(embedding))
+		}
+		for i := 0; i < len(mean) && i < len(embedding); i++ {
+			mean[i] += embedding[i] / float32(len(anchors))
+		}
+	}
+	return mean
+}
+
+// contextValues collects the string values of a context key
+func contextValues(anchors []*types.SemanticAnchor, key string) []string {
+	var values []string
+	for _, anchor := range anchors {
+		if value, ok := anchor.Context[key].(string); ok && value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// shares counts values, most common first
+func shares(values []string) []ValueShare {
+	counts := make(map[string]int)
+	for _, value := range values {
+		counts[value]++
+	}
+
+	result := make([]ValueShare, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, ValueShare{
+			Value: value,
+			Count: count,
+			Share: float64(count) / float64(len(values)),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
@@ -0,0 +1,69 @@
+package patterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestExplain(t *testing.T) {
+	base := time.Date(2025, 10, 17, 7, 0, 0, 0, time.UTC)
+	anchor := func(location, timeOfDay string, embedding []float32) *types.SemanticAnchor {
+		a := createTestAnchorWithEmbedding(location, base, pgvector.NewVector(embedding))
+		a.Context = map[string]interface{}{"time_of_day": timeOfDay, "day_type": "weekday"}
+		return a
+	}
+
+	pattern := createTestPattern("Morning routine", 0.5)
+	pattern.Interpretation = &types.PatternInterpretation{Confidence: 0.8, Reasoning: "Kitchen every weekday morning"}
+
+	central := anchor("kitchen", "morning", []float32{1, 0.5})
+	anchors := []*types.SemanticAnchor{
+		anchor("kitchen", "morning", []float32{1, 0}),
+		central,
+		anchor("bathroom", "morning", []float32{1, 1}),
+		anchor("kitchen", "evening", []float32{0.9, 0.6}),
+	}
+
+	explanation := Explain(pattern, anchors, 2)
+
+	if explanation.AnchorCount != 4 {
+		t.Errorf("Expected anchor count 4, got %d", explanation.AnchorCount)
+	}
+	if len(explanation.Anchors) != 2 {
+		t.Fatalf("Expected 2 listed anchors, got %d", len(explanation.Anchors))
+	}
+	if explanation.CentroidAnchor == nil || explanation.CentroidAnchor.ID != central.ID {
+		t.Errorf("Expected the central anchor nearest the centroid, got %+v", explanation.CentroidAnchor)
+	}
+	if explanation.Anchors[0].DistanceToCentroid > explanation.Anchors[1].DistanceToCentroid {
+		t.Errorf("Expected anchors ordered by distance to centroid")
+	}
+
+	if got := explanation.Locations[0]; got.Value != "kitchen" || got.Count != 3 || got.Share != 0.75 {
+		t.Errorf("Expected kitchen 3/0.75 as top location, got %+v", got)
+	}
+	if got := explanation.TimesOfDay[0]; got.Value != "morning" || got.Count != 3 {
+		t.Errorf("Expected morning as top time of day, got %+v", got)
+	}
+	if got := explanation.CentroidContext["day_type"]; got.Value != "weekday" || got.Share != 1 {
+		t.Errorf("Expected weekday shared by all anchors, got %+v", got)
+	}
+	if explanation.Interpretation == nil || explanation.Interpretation.Reasoning == "" {
+		t.Errorf("Expected the stored interpretation")
+	}
+}
+
+func TestExplain_NoAnchors(t *testing.T) {
+	explanation := Explain(createTestPattern("Manual", 0.5), nil, 10)
+
+	if explanation.AnchorCount != 0 || explanation.CentroidAnchor != nil {
+		t.Errorf("Expected an empty explanation, got %+v", explanation)
+	}
+	if explanation.Anchors == nil || explanation.Locations == nil {
+		t.Errorf("Expected empty lists rather than nil")
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/saaga0h/jeeves-platform/internal/behavior/prompts"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
 	"github.com/saaga0h/jeeves-platform/pkg/llm"
@@ -16,23 +17,26 @@ import (
 
 // PatternInterpreter uses LLM to interpret clusters as behavioral patterns
 type PatternInterpreter struct {
-	storage *storage.AnchorStorage
+	storage storage.AnchorRepository
 	llm     llm.Client
 	model   string // LLM model name
+	prompts *llm.Prompts
 	logger  *slog.Logger
 }
 
 // NewPatternInterpreter creates a new pattern interpreter
 func NewPatternInterpreter(
-	storage *storage.AnchorStorage,
+	storage storage.AnchorRepository,
 	llmClient llm.Client,
 	model string,
+	prompts *llm.Prompts,
 	logger *slog.Logger,
 ) *PatternInterpreter {
 	return &PatternInterpreter{
 		storage: storage,
 		llm:     llmClient,
 		model:   model,
+		prompts: prompts,
 		logger:  logger,
 	}
 }
@@ -54,7 +58,10 @@ func (p *PatternInterpreter) InterpretCluster(
 	}
 
 	// Build prompt
-	prompt := p.buildInterpretationPrompt(anchors)
+	prompt, err := p.buildInterpretationPrompt(anchors)
+	if err != nil {
+		return nil, err
+	}
 
 	// Ask LLM
 	req := llm.GenerateRequest{
@@ -75,6 +82,7 @@ func (p *PatternInterpreter) InterpretCluster(
 		Confidence             float64  `json:"confidence"`
 		TypicalDurationMinutes *int     `json:"typical_duration_minutes"`
 		KeyCharacteristics     []string `json:"key_characteristics"`
+		Reasoning              string   `json:"reasoning"`
 	}
 
 	if err := json.Unmarshal([]byte(response.Response), &llmResult); err != nil {
@@ -92,8 +100,15 @@ func (p *PatternInterpreter) InterpretCluster(
 		Context:                p.extractCommonContext(anchors),
 		FirstSeen:              p.findEarliestTimestamp(anchors),
 		LastSeen:               p.findLatestTimestamp(anchors),
-		CreatedAt:              time.Now(),
-		UpdatedAt:              time.Now(),
+		Interpretation: &types.PatternInterpretation{
+			Model:              p.model,
+			Confidence:         llmResult.Confidence,
+			KeyCharacteristics: llmResult.KeyCharacteristics,
+			Reasoning:          llmResult.Reasoning,
+			InterpretedAt:      time.Now(),
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
 
 	p.logger.Info("Pattern interpreted",
@@ -106,62 +121,38 @@ func (p *PatternInterpreter) InterpretCluster(
 	return pattern, nil
 }
 
-func (p *PatternInterpreter) buildInterpretationPrompt(anchors []*types.SemanticAnchor) string {
-	// Build anchor summary
-	anchorSummary := ""
+func (p *PatternInterpreter) buildInterpretationPrompt(anchors []*types.SemanticAnchor) (string, error) {
+	data := prompts.InterpretationData{
+		Total:      len(anchors),
+		Locations:  p.extractUniqueValues(anchors, "location"),
+		TimesOfDay: p.extractUniqueContextValues(anchors, "time_of_day"),
+		DayTypes:   p.extractUniqueContextValues(anchors, "day_type"),
+	}
+
+	// Limit to first 10 for prompt size
 	for i, anchor := range anchors {
-		if i < 10 { // Limit to first 10 for prompt size
-			anchorSummary += fmt.Sprintf("\nAnchor %d: %s @ %s (%s, %s, %s)",
-				i+1,
-				anchor.Location,
-				anchor.Timestamp.Format("15:04"),
-				getContextValue(anchor.Context, "time_of_day"),
-				getContextValue(anchor.Context, "day_type"),
-				getContextValue(anchor.Context, "season"))
+		if i >= 10 {
+			data.More = len(anchors) - 10
+			break
 		}
+		data.Anchors = append(data.Anchors, prompts.ClusterAnchor{
+			Number: i + 1,
+			Anchor: prompts.Anchor{
+				Location:  anchor.Location,
+				Time:      anchor.Timestamp.Format("15:04"),
+				TimeOfDay: getContextValue(anchor.Context, "time_of_day"),
+				DayType:   getContextValue(anchor.Context, "day_type"),
+				Season:    getContextValue(anchor.Context, "season"),
+				Signals:   len(anchor.Signals),
+			},
+		})
 	}
 
-	if len(anchors) > 10 {
-		anchorSummary += fmt.Sprintf("\n... and %d more anchors", len(anchors)-10)
+	promptSet := p.prompts
+	if promptSet == nil {
+		promptSet = prompts.Default()
 	}
-
-	// Extract common patterns
-	locations := p.extractUniqueValues(anchors, "location")
-	timesOfDay := p.extractUniqueContextValues(anchors, "time_of_day")
-	dayTypes := p.extractUniqueContextValues(anchors, "day_type")
-
-	return fmt.Sprintf(`Analyze this cluster of behavioral anchors and identify the pattern they represent.
-
-Anchors in cluster (%d total):%s
-
-Common characteristics:
-- Locations: %v
-- Times of day: %v
-- Day types: %v
-
-These anchors were grouped together because they have small semantic distance in behavioral space.
-
-What behavioral pattern does this cluster represent?
-
-Consider:
-- Is this a routine (morning_routine, evening_wind_down)?
-- Is this an activity type (meal_preparation, work_session, leisure_time)?
-- Is this a transition (waking_up, going_to_bed)?
-- Are there multiple interpretations (concurrent activities)?
-
-Respond with ONLY valid JSON (no markdown):
-{
-  "pattern_type": "morning_routine" | "meal_preparation" | "work_session" | "leisure" | "transition" | etc,
-  "name": "Human-readable pattern name",
-  "confidence": 0.0-1.0,
-  "typical_duration_minutes": estimated_duration or null,
-  "key_characteristics": ["characteristic1", "characteristic2"]
-}`,
-		len(anchors),
-		anchorSummary,
-		locations,
-		timesOfDay,
-		dayTypes)
+	return promptSet.Render(prompts.Interpretation, p.model, data)
 }
 
 func (p *PatternInterpreter) extractUniqueValues(anchors []*types.SemanticAnchor, field string) []string {
@@ -216,6 +207,11 @@ func (p *PatternInterpreter) extractCommonContext(anchors []*types.SemanticAncho
 		context["typical_day_type"] = dayType
 	}
 
+	// Occupant, only if every anchor is attributed to the same person
+	if occupant := sharedOccupant(anchors); occupant != "" {
+		context["occupant"] = occupant
+	}
+
 	return context
 }
 
@@ -0,0 +1,370 @@
+package patterns
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// minPatternWeight is the weight floor enforced by behavioral_patterns
+const minPatternWeight = 0.1
+
+// Lifecycle actions for a single pattern
+const (
+	lifecycleKeep    = "keep"
+	lifecycleDecay   = "decay"
+	lifecycleArchive = "archive"
+)
+
+// LifecycleConfig configures pattern lifecycle maintenance
+type LifecycleConfig struct {
+	Interval         time.Duration // how often maintenance runs
+	MergeSimilarity  float64       // centroid cosine similarity at which patterns merge
+	DecayAfter       time.Duration // unseen duration before weight decays
+	DecayFactor      float64       // weight multiplier per run while unseen
+	ArchiveAfter     time.Duration // unseen duration before a fully decayed pattern is archived
+	ReassignDistance float64       // max cosine distance from anchor to centroid for reassignment
+}
+
+// LifecycleResult summarizes one maintenance run
+type LifecycleResult struct {
+	Merged            int `json:"merged"`
+	Decayed           int `json:"decayed"`
+	Archived          int `json:"archived"`
+	AnchorsReassigned int `json:"anchors_reassigned"`
+	AnchorsReleased   int `json:"anchors_released"`
+}
+
+// LifecycleManager keeps the pattern library healthy: it merges near-duplicate
+// patterns, decays the weight of patterns that stop being observed, and archives
+// patterns that have fully decayed, reassigning their anchors to the nearest
+// surviving pattern.
+type LifecycleManager struct {
+	config      LifecycleConfig
+	storage     *storage.AnchorStorage
+	mqtt        mqtt.Client
+	logger      *slog.Logger
+	timeManager TimeManager
+
+	triggers chan struct{}
+}
+
+// NewLifecycleManager creates a new pattern lifecycle manager
+func NewLifecycleManager(
+	config LifecycleConfig,
+	storage *storage.AnchorStorage,
+	mqttClient mqtt.Client,
+	logger *slog.Logger,
+	timeManager TimeManager,
+) *LifecycleManager {
+	return &LifecycleManager{
+		config:      config,
+		storage:     storage,
+		mqtt:        mqttClient,
+		logger:      logger.With("component", "pattern_lifecycle"),
+		timeManager: timeManager,
+		triggers:    make(chan struct{}, 1),
+	}
+}
+
+// Start runs maintenance on the configured interval and on MQTT triggers
+func (m *LifecycleManager) Start(ctx context.Context) error {
+	if err := m.mqtt.Subscribe("automation/behavior/maintain_patterns", 0, m.handleTrigger); err != nil {
+		return fmt.Errorf("failed to subscribe to triggers: %w", err)
+	}
+
+	m.logger.Info("Pattern lifecycle manager started",
+		"interval", m.config.Interval,
+		"merge_similarity", m.config.MergeSimilarity,
+		"decay_after", m.config.DecayAfter,
+		"archive_after", m.config.ArchiveAfter)
+
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-m.triggers:
+		}
+
+		if _, err := m.RunMaintenance(ctx); err != nil {
+			m.logger.Error("Pattern lifecycle maintenance failed", "error", err)
+		}
+	}
+}
+
+func (m *LifecycleManager) handleTrigger(msg mqtt.Message) {
+	select {
+	case m.triggers <- struct{}{}:
+	default:
+		// A run is already queued
+	}
+}
+
+// RunMaintenance performs one merge/decay/archive pass over all active patterns
+func (m *LifecycleManager) RunMaintenance(ctx context.Context) (*LifecycleResult, error) {
+	result := &LifecycleResult{}
+
+	patterns, err := m.storage.GetActivePatterns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load patterns: %w", err)
+	}
+	centroidList, err := m.storage.GetPatternCentroids(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pattern centroids: %w", err)
+	}
+
+	centroids := make(map[uuid.UUID][]float32, len(centroidList))
+	for _, c := range centroidList {
+		centroids[c.PatternID] = c.Centroid.Slice()
+	}
+
+	m.logger.Info("Pattern lifecycle maintenance starting",
+		"patterns", len(patterns),
+		"with_centroids", len(centroids))
+
+	// Phase 1: merge near-duplicates. Both sides are skipped by phase 2 since the
+	// target's statistics changed underneath the loaded copy.
+	handled := make(map[uuid.UUID]bool)
+	for _, merge := range planMerges(patterns, centroids, m.config.MergeSimilarity) {
+		moved, err := m.storage.MergePattern(ctx, merge.Source.ID, merge.Target.ID)
+		if err != nil {
+			m.logger.Error("Failed to merge patterns",
+				"source", merge.Source.ID,
+				"target", merge.Target.ID,
+				"error", err)
+			continue
+		}
+
+		handled[merge.Source.ID] = true
+		handled[merge.Target.ID] = true
+		delete(centroids, merge.Source.ID)
+		result.Merged++
+		result.AnchorsReassigned += int(moved)
+
+		m.logger.Info("Merged near-duplicate patterns",
+			"source", merge.Source.Name,
+			"target", merge.Target.Name,
+			"similarity", merge.Similarity,
+			"anchors_moved", moved)
+
+		m.publish("automation/behavior/patterns/merged", map[string]interface{}{
+			"source_pattern_id": merge.Source.ID,
+			"target_pattern_id": merge.Target.ID,
+			"source_name":       merge.Source.Name,
+			"target_name":       merge.Target.Name,
+			"similarity":        merge.Similarity,
+			"anchors_moved":     moved,
+		})
+	}
+
+	// Phase 2: decay and archive patterns that stopped being observed
+	now := m.timeManager.Now()
+	for _, pattern := range patterns {
+		if handled[pattern.ID] {
+			continue
+		}
+
+		switch lifecycleAction(pattern, now, m.config) {
+		case lifecycleDecay:
+			weight, err := m.storage.DecayPatternWeight(ctx, pattern.ID, m.config.DecayFactor)
+			if err != nil {
+				m.logger.Error("Failed to decay pattern", "pattern_id", pattern.ID, "error", err)
+				continue
+			}
+			result.Decayed++
+
+			m.publish("automation/behavior/patterns/decayed", map[string]interface{}{
+				"pattern_id":      pattern.ID,
+				"name":            pattern.Name,
+				"previous_weight": pattern.Weight,
+				"weight":          weight,
+				"last_seen":       pattern.LastSeen.Format(time.RFC3339),
+			})
+
+		case lifecycleArchive:
+			delete(centroids, pattern.ID)
+			reassigned, released, err := m.releaseAnchors(ctx, pattern.ID, centroids)
+			if err != nil {
+				m.logger.Error("Failed to reassign anchors of archived pattern", "pattern_id", pattern.ID, "error", err)
+				continue
+			}
+			if err := m.storage.ArchivePattern(ctx, pattern.ID, "unseen"); err != nil {
+				m.logger.Error("Failed to archive pattern", "pattern_id", pattern.ID, "error", err)
+				continue
+			}
+			handled[pattern.ID] = true
+			result.Archived++
+			result.AnchorsReassigned += reassigned
+			result.AnchorsReleased += released
+
+			m.logger.Info("Archived unseen pattern",
+				"pattern", pattern.Name,
+				"last_seen", pattern.LastSeen,
+				"anchors_reassigned", reassigned,
+				"anchors_released", released)
+
+			m.publish("automation/behavior/patterns/archived", map[string]interface{}{
+				"pattern_id":         pattern.ID,
+				"name":               pattern.Name,
+				"reason":             "unseen",
+				"last_seen":          pattern.LastSeen.Format(time.RFC3339),
+				"anchors_reassigned": reassigned,
+				"anchors_released":   released,
+			})
+		}
+	}
+
+	m.logger.Info("Pattern lifecycle maintenance completed",
+		"merged", result.Merged,
+		"decayed", result.Decayed,
+		"archived", result.Archived,
+		"anchors_reassigned", result.AnchorsReassigned,
+		"anchors_released", result.AnchorsReleased)
+
+	m.publish("automation/behavior/patterns/maintained", result)
+	return result, nil
+}
+
+// releaseAnchors moves a retiring pattern's anchors to the nearest remaining pattern
+// centroid within ReassignDistance, and unassigns the rest so discovery can reuse them.
+func (m *LifecycleManager) releaseAnchors(ctx context.Context, patternID uuid.UUID, centroids map[uuid.UUID][]float32) (int, int, error) {
+	anchors, err := m.storage.GetAnchorsByPattern(ctx, patternID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	reassigned, released := 0, 0
+	for _, anchor := range anchors {
+		target, ok := nearestCentroid(anchor.SemanticEmbedding.Slice(), centroids, m.config.ReassignDistance)
+		if ok {
+			if err := m.storage.UpdateAnchorPattern(ctx, anchor.ID, target); err != nil {
+				return reassigned, released, err
+			}
+			reassigned++
+			continue
+		}
+
+		if err := m.storage.ClearAnchorPattern(ctx, anchor.ID); err != nil {
+			return reassigned, released, err
+		}
+		released++
+	}
+
+	return reassigned, released, nil
+}
+
+func (m *LifecycleManager) publish(topic string, payload interface{}) {
+	data, err := events.Marshal(events.ProducerBehavior, payload)
+	if err != nil {
+		m.logger.Error("Failed to marshal lifecycle event", "topic", topic, "error", err)
+		return
+	}
+	if err := m.mqtt.Publish(topic, 0, false, data); err != nil {
+		m.logger.Error("Failed to publish lifecycle event", "topic", topic, "error", err)
+	}
+}
+
+// patternMerge is a planned merge of Source into Target
+type patternMerge struct {
+	Source     *types.BehavioralPattern
+	Target     *types.BehavioralPattern
+	Similarity float64
+}
+
+// planMerges pairs near-duplicate patterns by centroid similarity. Stronger patterns
+// (higher weight, then more observations, then older) absorb weaker ones, and each
+// pattern takes part in at most one merge per run as the source.
+func planMerges(patterns []*types.BehavioralPattern, centroids map[uuid.UUID][]float32, threshold float64) []patternMerge {
+	ranked := make([]*types.BehavioralPattern, 0, len(patterns))
+	for _, p := range patterns {
+		if _, ok := centroids[p.ID]; ok {
+			ranked = append(ranked, p)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		if a.Observations != b.Observations {
+			return a.Observations > b.Observations
+		}
+		return a.FirstSeen.Before(b.FirstSeen)
+	})
+
+	merged := make(map[uuid.UUID]bool)
+	var merges []patternMerge
+
+	for i, target := range ranked {
+		if merged[target.ID] {
+			continue
+		}
+		for _, source := range ranked[i+1:] {
+			if merged[source.ID] {
+				continue
+			}
+			similarity := cosineSimilaritySlice(centroids[target.ID], centroids[source.ID])
+			if similarity >= threshold {
+				merged[source.ID] = true
+				merges = append(merges, patternMerge{Source: source, Target: target, Similarity: similarity})
+			}
+		}
+	}
+
+	return merges
+}
+
+// lifecycleAction decides what maintenance does with a pattern based on how long
+// it has gone unobserved. Patterns are archived only once decay has brought them
+// down to the weight floor.
+func lifecycleAction(pattern *types.BehavioralPattern, now time.Time, config LifecycleConfig) string {
+	// Manual patterns have no anchors to be seen through; they live until deleted
+	if pattern.Source == types.PatternSourceManual {
+		return lifecycleKeep
+	}
+
+	unseen := now.Sub(pattern.LastSeen)
+
+	if unseen >= config.ArchiveAfter && pattern.Weight <= minPatternWeight+1e-9 {
+		return lifecycleArchive
+	}
+	if unseen >= config.DecayAfter && pattern.Weight > minPatternWeight {
+		return lifecycleDecay
+	}
+	return lifecycleKeep
+}
+
+// nearestCentroid finds the closest centroid within maxDistance (cosine distance)
+func nearestCentroid(embedding []float32, centroids map[uuid.UUID][]float32, maxDistance float64) (uuid.UUID, bool) {
+	var best uuid.UUID
+	var bestDistance float64
+	found := false
+
+	for id, centroid := range centroids {
+		distance := 1 - cosineSimilaritySlice(embedding, centroid)
+		if distance > maxDistance {
+			continue
+		}
+		if !found || distance < bestDistance {
+			best = id
+			bestDistance = distance
+			found = true
+		}
+	}
+
+	return best, found
+}
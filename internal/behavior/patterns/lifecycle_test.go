@@ -0,0 +1,134 @@
+package patterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestPlanMerges(t *testing.T) {
+	strong := createTestPattern("evening wind-down", 0.5)
+	duplicate := createTestPattern("evening relaxation", 0.2)
+	distinct := createTestPattern("morning routine", 0.3)
+
+	centroids := map[uuid.UUID][]float32{
+		strong.ID:    {1, 0, 0},
+		duplicate.ID: {0.99, 0.05, 0},
+		distinct.ID:  {0, 1, 0},
+	}
+
+	merges := planMerges([]*types.BehavioralPattern{duplicate, distinct, strong}, centroids, 0.95)
+
+	if len(merges) != 1 {
+		t.Fatalf("Expected 1 merge, got %d", len(merges))
+	}
+	if merges[0].Target.ID != strong.ID {
+		t.Errorf("Expected the higher-weight pattern to survive, got %s", merges[0].Target.Name)
+	}
+	if merges[0].Source.ID != duplicate.ID {
+		t.Errorf("Expected the duplicate to be merged, got %s", merges[0].Source.Name)
+	}
+}
+
+func TestPlanMerges_EachSourceOnce(t *testing.T) {
+	a := createTestPattern("a", 0.4)
+	b := createTestPattern("b", 0.3)
+	c := createTestPattern("c", 0.2)
+
+	// All three near-identical: b and c both fold into a, never into each other
+	centroids := map[uuid.UUID][]float32{
+		a.ID: {1, 0},
+		b.ID: {1, 0.01},
+		c.ID: {1, 0.02},
+	}
+
+	merges := planMerges([]*types.BehavioralPattern{a, b, c}, centroids, 0.95)
+
+	if len(merges) != 2 {
+		t.Fatalf("Expected 2 merges, got %d", len(merges))
+	}
+	for _, merge := range merges {
+		if merge.Target.ID != a.ID {
+			t.Errorf("Expected all merges into a, got target %s", merge.Target.Name)
+		}
+	}
+}
+
+func TestPlanMerges_SkipsPatternsWithoutCentroid(t *testing.T) {
+	a := createTestPattern("a", 0.4)
+	b := createTestPattern("b", 0.3)
+
+	merges := planMerges([]*types.BehavioralPattern{a, b}, map[uuid.UUID][]float32{a.ID: {1, 0}}, 0.5)
+
+	if len(merges) != 0 {
+		t.Errorf("Expected no merges, got %d", len(merges))
+	}
+}
+
+func TestLifecycleAction(t *testing.T) {
+	now := time.Date(2025, 10, 17, 12, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	config := LifecycleConfig{
+		DecayAfter:   4 * week,
+		ArchiveAfter: 12 * week,
+	}
+
+	tests := []struct {
+		name     string
+		weight   float64
+		unseen   time.Duration
+		source   string
+		expected string
+	}{
+		{"recently seen", 0.5, week, types.PatternSourceDiscovered, lifecycleKeep},
+		{"unseen past decay threshold", 0.5, 5 * week, types.PatternSourceDiscovered, lifecycleDecay},
+		{"already at weight floor", 0.1, 5 * week, types.PatternSourceDiscovered, lifecycleKeep},
+		{"unseen long but still weighted", 0.3, 13 * week, types.PatternSourceDiscovered, lifecycleDecay},
+		{"unseen long and fully decayed", 0.1, 13 * week, types.PatternSourceDiscovered, lifecycleArchive},
+		{"manual pattern never decays", 0.5, 5 * week, types.PatternSourceManual, lifecycleKeep},
+		{"manual pattern never archived", 0.1, 13 * week, types.PatternSourceManual, lifecycleKeep},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern := createTestPattern("p", tt.weight)
+			pattern.LastSeen = now.Add(-tt.unseen)
+			pattern.Source = tt.source
+
+			if got := lifecycleAction(pattern, now, config); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNearestCentroid(t *testing.T) {
+	near := uuid.New()
+	far := uuid.New()
+	centroids := map[uuid.UUID][]float32{
+		near: {1, 0.1},
+		far:  {0, 1},
+	}
+
+	id, ok := nearestCentroid([]float32{1, 0}, centroids, 0.3)
+	if !ok || id != near {
+		t.Errorf("Expected nearest centroid to be chosen")
+	}
+
+	if _, ok := nearestCentroid([]float32{-1, 0}, centroids, 0.3); ok {
+		t.Errorf("Expected no centroid within distance")
+	}
+}
+
+func createTestPattern(name string, weight float64) *types.BehavioralPattern {
+	return &types.BehavioralPattern{
+		ID:        uuid.New(),
+		Name:      name,
+		Weight:    weight,
+		FirstSeen: time.Now(),
+		LastSeen:  time.Now(),
+	}
+}
@@ -105,7 +105,10 @@ func (c *LocationTemporalClusterer) findLocationSessions(
 				lastAnchor := currentSession.Anchors[len(currentSession.Anchors)-1]
 				gap := anchor.Timestamp.Sub(lastAnchor.Timestamp)
 
-				if gap > c.TemporalGapThreshold {
+				// Sparse activity during sleep is one session, not a series of gaps
+				sleeping := lastAnchor.IsSleeping() && anchor.IsSleeping()
+
+				if gap > c.TemporalGapThreshold && !sleeping {
 					// Gap too large - finish current session and start new one
 					sessions = append(sessions, currentSession)
 					currentSession = &LocationSession{
@@ -76,6 +76,29 @@ func TestLocationTemporalClusterer_TemporalGap(t *testing.T) {
 	}
 }
 
+func TestLocationTemporalClusterer_SleepSpansGaps(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	clusterer := NewLocationTemporalClusterer(logger)
+
+	// Sparse bedroom anchors overnight, all inside a detected sleep period
+	baseTime := time.Date(2025, 10, 30, 23, 0, 0, 0, time.UTC)
+	anchors := []*types.SemanticAnchor{
+		createSleepingTestAnchor("bedroom", baseTime),
+		createSleepingTestAnchor("bedroom", baseTime.Add(2*time.Hour)),
+		createSleepingTestAnchor("bedroom", baseTime.Add(5*time.Hour)),
+	}
+
+	sequences := clusterer.ClusterByLocationTemporal(anchors)
+
+	// Gaps between sleeping anchors should not split the session
+	if len(sequences) != 1 {
+		t.Fatalf("Expected 1 sequence for sleep period, got %d", len(sequences))
+	}
+	if len(sequences[0].Anchors) != 3 {
+		t.Errorf("Expected 3 anchors in sleep sequence, got %d", len(sequences[0].Anchors))
+	}
+}
+
 func TestLocationTemporalClusterer_CrossLocationRoutine(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	clusterer := NewLocationTemporalClusterer(logger)
@@ -362,6 +385,14 @@ func createTestAnchor(location string, timestamp time.Time) *types.SemanticAncho
 	}
 }
 
+func createSleepingTestAnchor(location string, timestamp time.Time) *types.SemanticAnchor {
+	anchor := createTestAnchor(location, timestamp)
+	anchor.Signals = []types.ActivitySignal{
+		{Type: "sleep", Confidence: 0.9, Timestamp: timestamp, Value: map[string]interface{}{"state": "asleep"}},
+	}
+	return anchor
+}
+
 func uniqueLocations(locations []string) []string {
 	seen := make(map[string]bool)
 	var unique []string
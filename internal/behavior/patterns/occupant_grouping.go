@@ -0,0 +1,78 @@
+package patterns
+
+import (
+	"sort"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+// householdOccupant is the partition key for anchors without an attributed occupant
+const householdOccupant = ""
+
+// GroupByOccupant partitions anchors by their attributed occupant.
+// Anchors without an occupant are grouped under the household key ("").
+func GroupByOccupant(anchors []*types.SemanticAnchor) map[string][]*types.SemanticAnchor {
+	groups := make(map[string][]*types.SemanticAnchor)
+
+	for _, anchor := range anchors {
+		occupant := householdOccupant
+		if anchor.Occupant != nil {
+			occupant = *anchor.Occupant
+		}
+		groups[occupant] = append(groups[occupant], anchor)
+	}
+
+	return groups
+}
+
+// partitionAnchors returns the anchor sets that should be clustered independently.
+// Without per-occupant clustering all anchors form a single partition.
+func (a *DiscoveryAgent) partitionAnchors(anchors []*types.SemanticAnchor) [][]*types.SemanticAnchor {
+	if !a.config.PerOccupantClustering {
+		return [][]*types.SemanticAnchor{anchors}
+	}
+
+	groups := GroupByOccupant(anchors)
+
+	// Deterministic ordering so repeated runs produce the same pattern order
+	occupants := make([]string, 0, len(groups))
+	for occupant := range groups {
+		occupants = append(occupants, occupant)
+	}
+	sort.Strings(occupants)
+
+	partitions := make([][]*types.SemanticAnchor, 0, len(occupants))
+	for _, occupant := range occupants {
+		partitions = append(partitions, groups[occupant])
+	}
+
+	a.logger.Info("Partitioned anchors by occupant",
+		"partitions", len(partitions),
+		"occupants", occupants)
+
+	return partitions
+}
+
+// occupantLabel returns a human-readable occupant name for a partition (for logging)
+func occupantLabel(anchors []*types.SemanticAnchor) string {
+	if len(anchors) == 0 || anchors[0].Occupant == nil {
+		return "household"
+	}
+	return *anchors[0].Occupant
+}
+
+// sharedOccupant returns the occupant common to all anchors, or "" if they differ or are unattributed
+func sharedOccupant(anchors []*types.SemanticAnchor) string {
+	var occupant string
+	for i, anchor := range anchors {
+		if anchor.Occupant == nil {
+			return ""
+		}
+		if i == 0 {
+			occupant = *anchor.Occupant
+		} else if *anchor.Occupant != occupant {
+			return ""
+		}
+	}
+	return occupant
+}
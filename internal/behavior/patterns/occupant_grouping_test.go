@@ -0,0 +1,116 @@
+package patterns
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestGroupByOccupant(t *testing.T) {
+	baseTime := time.Now()
+	anchors := []*types.SemanticAnchor{
+		createOccupantAnchor("kitchen", baseTime, "alice"),
+		createOccupantAnchor("study", baseTime, "bob"),
+		createOccupantAnchor("kitchen", baseTime.Add(5*time.Minute), "alice"),
+		createTestAnchor("hallway", baseTime),
+	}
+
+	groups := GroupByOccupant(anchors)
+
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %d", len(groups))
+	}
+	if len(groups["alice"]) != 2 {
+		t.Errorf("Expected 2 anchors for alice, got %d", len(groups["alice"]))
+	}
+	if len(groups["bob"]) != 1 {
+		t.Errorf("Expected 1 anchor for bob, got %d", len(groups["bob"]))
+	}
+	if len(groups[householdOccupant]) != 1 {
+		t.Errorf("Expected 1 unattributed anchor, got %d", len(groups[householdOccupant]))
+	}
+}
+
+func TestPartitionAnchors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	baseTime := time.Now()
+	anchors := []*types.SemanticAnchor{
+		createOccupantAnchor("study", baseTime, "bob"),
+		createOccupantAnchor("kitchen", baseTime, "alice"),
+		createTestAnchor("hallway", baseTime),
+	}
+
+	tests := []struct {
+		name           string
+		perOccupant    bool
+		expectedLabels []string
+	}{
+		{
+			name:           "disabled keeps a single partition",
+			perOccupant:    false,
+			expectedLabels: []string{"bob"},
+		},
+		{
+			name:           "enabled splits by occupant in sorted order",
+			perOccupant:    true,
+			expectedLabels: []string{"household", "alice", "bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &DiscoveryAgent{
+				config: DiscoveryConfig{PerOccupantClustering: tt.perOccupant},
+				logger: logger,
+			}
+
+			partitions := agent.partitionAnchors(anchors)
+
+			if len(partitions) != len(tt.expectedLabels) {
+				t.Fatalf("Expected %d partitions, got %d", len(tt.expectedLabels), len(partitions))
+			}
+			for i, label := range tt.expectedLabels {
+				if got := occupantLabel(partitions[i]); got != label {
+					t.Errorf("Partition %d: expected %s, got %s", i, label, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSharedOccupant(t *testing.T) {
+	baseTime := time.Now()
+
+	same := []*types.SemanticAnchor{
+		createOccupantAnchor("kitchen", baseTime, "alice"),
+		createOccupantAnchor("dining_room", baseTime, "alice"),
+	}
+	if got := sharedOccupant(same); got != "alice" {
+		t.Errorf("Expected alice, got %q", got)
+	}
+
+	mixed := []*types.SemanticAnchor{
+		createOccupantAnchor("kitchen", baseTime, "alice"),
+		createOccupantAnchor("study", baseTime, "bob"),
+	}
+	if got := sharedOccupant(mixed); got != "" {
+		t.Errorf("Expected no shared occupant, got %q", got)
+	}
+
+	partial := []*types.SemanticAnchor{
+		createOccupantAnchor("kitchen", baseTime, "alice"),
+		createTestAnchor("kitchen", baseTime),
+	}
+	if got := sharedOccupant(partial); got != "" {
+		t.Errorf("Expected no shared occupant, got %q", got)
+	}
+}
+
+func createOccupantAnchor(location string, timestamp time.Time, occupant string) *types.SemanticAnchor {
+	anchor := createTestAnchor(location, timestamp)
+	anchor.Occupant = &occupant
+	return anchor
+}
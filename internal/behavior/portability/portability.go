@@ -0,0 +1,397 @@
+// Package portability exports and imports learned behavior as a versioned JSON
+// bundle, so a trained model can be moved to another household or restored
+// after the database is rebuilt.
+//
+// A bundle holds the active behavioral patterns, the learned distance patterns
+// and their observations. Anchors are household-specific and are not exported:
+// imported behavioral patterns start without member anchors, and references
+// from learned patterns and observations to anchors are dropped.
+package portability
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+const (
+	// FormatName identifies a pattern bundle
+	FormatName = "jeeves-patterns"
+
+	// FormatVersion is the bundle version written by Export
+	FormatVersion = 1
+)
+
+// Bundle is the portable representation of learned behavior
+type Bundle struct {
+	Format             string                     `json:"format"`
+	Version            int                        `json:"version"`
+	ExportedAt         time.Time                  `json:"exported_at"`
+	BehavioralPatterns []*types.BehavioralPattern `json:"behavioral_patterns"`
+	LearnedPatterns    []*LearnedPatternRecord    `json:"learned_patterns"`
+}
+
+// LearnedPatternRecord is a learned distance pattern with its observations
+type LearnedPatternRecord struct {
+	PatternKey         string              `json:"pattern_key"`
+	WeightedDistance   float64             `json:"weighted_distance"`
+	ConfidenceScore    float64             `json:"confidence_score"`
+	ObservationCount   int                 `json:"observation_count"`
+	FirstSeen          time.Time           `json:"first_seen"`
+	LastUpdated        time.Time           `json:"last_updated"`
+	LastComputed       time.Time           `json:"last_computed"`
+	DecayHalfLifeHours int                 `json:"decay_half_life_hours"`
+	Location1          string              `json:"location1,omitempty"`
+	Location2          string              `json:"location2,omitempty"`
+	TimeOfDay1         string              `json:"time_of_day1,omitempty"`
+	TimeOfDay2         string              `json:"time_of_day2,omitempty"`
+	DayType1           string              `json:"day_type1,omitempty"`
+	DayType2           string              `json:"day_type2,omitempty"`
+	MinDistance        *float64            `json:"min_distance,omitempty"`
+	MaxDistance        *float64            `json:"max_distance,omitempty"`
+	StdDeviation       *float64            `json:"std_deviation,omitempty"`
+	Observations       []ObservationRecord `json:"observations"`
+}
+
+// ObservationRecord is a single distance observation of a learned pattern
+type ObservationRecord struct {
+	ID             uuid.UUID `json:"id"`
+	Distance       float64   `json:"distance"`
+	Source         string    `json:"source"`
+	Timestamp      time.Time `json:"timestamp"`
+	Weight         float64   `json:"weight"`
+	Season         string    `json:"season,omitempty"`
+	DayType        string    `json:"day_type,omitempty"`
+	TimeOfDay      string    `json:"time_of_day,omitempty"`
+	VectorDistance *float64  `json:"vector_distance,omitempty"`
+}
+
+// ImportResult summarizes an import
+type ImportResult struct {
+	BehavioralPatternsImported int `json:"behavioral_patterns_imported"`
+	BehavioralPatternsSkipped  int `json:"behavioral_patterns_skipped"`
+	LearnedPatternsImported    int `json:"learned_patterns_imported"`
+	LearnedPatternsSkipped     int `json:"learned_patterns_skipped"`
+	ObservationsImported       int `json:"observations_imported"`
+}
+
+// Validate checks that the bundle is one this version can import
+func (b *Bundle) Validate() error {
+	if b.Format != FormatName {
+		return fmt.Errorf("unsupported bundle format %q", b.Format)
+	}
+	if b.Version < 1 || b.Version > FormatVersion {
+		return fmt.Errorf("unsupported bundle version %d (supported: 1-%d)", b.Version, FormatVersion)
+	}
+	for _, p := range b.BehavioralPatterns {
+		if p.ID == uuid.Nil || p.Name == "" {
+			return fmt.Errorf("behavioral pattern without id or name")
+		}
+	}
+	for _, lp := range b.LearnedPatterns {
+		if lp.PatternKey == "" {
+			return fmt.Errorf("learned pattern without pattern_key")
+		}
+	}
+	return nil
+}
+
+// Exporter reads and writes pattern bundles
+type Exporter struct {
+	db      *sql.DB
+	storage *storage.AnchorStorage
+	logger  *slog.Logger
+}
+
+// NewExporter creates a new pattern exporter
+func NewExporter(db *sql.DB, logger *slog.Logger) *Exporter {
+	return &Exporter{
+		db:      db,
+		storage: storage.NewAnchorStorage(db),
+		logger:  logger.With("component", "pattern_portability"),
+	}
+}
+
+// Export collects active behavioral patterns and learned patterns into a bundle
+func (e *Exporter) Export(ctx context.Context) (*Bundle, error) {
+	patterns, err := e.storage.GetActivePatterns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load behavioral patterns: %w", err)
+	}
+
+	learned, err := e.loadLearnedPatterns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if patterns == nil {
+		patterns = []*types.BehavioralPattern{}
+	}
+
+	return &Bundle{
+		Format:             FormatName,
+		Version:            FormatVersion,
+		ExportedAt:         time.Now().UTC(),
+		BehavioralPatterns: patterns,
+		LearnedPatterns:    learned,
+	}, nil
+}
+
+func (e *Exporter) loadLearnedPatterns(ctx context.Context) ([]*LearnedPatternRecord, error) {
+	rows, err := e.db.QueryContext(ctx, `
+		SELECT pattern_key, weighted_distance, confidence_score, observation_count,
+		       first_seen, last_updated, last_computed, decay_half_life_hours,
+		       COALESCE(location1, ''), COALESCE(location2, ''),
+		       COALESCE(time_of_day1, ''), COALESCE(time_of_day2, ''),
+		       COALESCE(day_type1, ''), COALESCE(day_type2, ''),
+		       min_distance, max_distance, std_deviation
+		FROM learned_patterns
+		ORDER BY pattern_key`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query learned patterns: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*LearnedPatternRecord{}
+	byKey := make(map[string]*LearnedPatternRecord)
+	for rows.Next() {
+		var r LearnedPatternRecord
+		if err := rows.Scan(
+			&r.PatternKey, &r.WeightedDistance, &r.ConfidenceScore, &r.ObservationCount,
+			&r.FirstSeen, &r.LastUpdated, &r.LastComputed, &r.DecayHalfLifeHours,
+			&r.Location1, &r.Location2,
+			&r.TimeOfDay1, &r.TimeOfDay2,
+			&r.DayType1, &r.DayType2,
+			&r.MinDistance, &r.MaxDistance, &r.StdDeviation,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan learned pattern: %w", err)
+		}
+		r.Observations = []ObservationRecord{}
+		records = append(records, &r)
+		byKey[r.PatternKey] = &r
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating learned patterns: %w", err)
+	}
+
+	obsRows, err := e.db.QueryContext(ctx, `
+		SELECT id, pattern_key, distance, source, timestamp, weight,
+		       COALESCE(season, ''), COALESCE(day_type, ''), COALESCE(time_of_day, ''),
+		       vector_distance
+		FROM pattern_observations
+		ORDER BY pattern_key, timestamp`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query observations: %w", err)
+	}
+	defer obsRows.Close()
+
+	for obsRows.Next() {
+		var o ObservationRecord
+		var key string
+		if err := obsRows.Scan(
+			&o.ID, &key, &o.Distance, &o.Source, &o.Timestamp, &o.Weight,
+			&o.Season, &o.DayType, &o.TimeOfDay,
+			&o.VectorDistance,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan observation: %w", err)
+		}
+		if r, ok := byKey[key]; ok {
+			r.Observations = append(r.Observations, o)
+		}
+	}
+	if err := obsRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating observations: %w", err)
+	}
+
+	return records, nil
+}
+
+// Import writes a bundle into the database in a single transaction. Patterns that
+// already exist (same id or pattern_key) are kept as they are; observations are
+// only imported for learned patterns that were newly created.
+func (e *Exporter) Import(ctx context.Context, bundle *Bundle) (*ImportResult, error) {
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &ImportResult{}
+
+	for _, p := range bundle.BehavioralPatterns {
+		inserted, err := insertBehavioralPattern(ctx, tx, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import pattern %s: %w", p.ID, err)
+		}
+		if inserted {
+			result.BehavioralPatternsImported++
+		} else {
+			result.BehavioralPatternsSkipped++
+		}
+	}
+
+	for _, lp := range bundle.LearnedPatterns {
+		inserted, err := insertLearnedPattern(ctx, tx, lp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import learned pattern %s: %w", lp.PatternKey, err)
+		}
+		if !inserted {
+			result.LearnedPatternsSkipped++
+			continue
+		}
+		result.LearnedPatternsImported++
+
+		for _, o := range lp.Observations {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO pattern_observations (
+					id, pattern_key, distance, source, timestamp, weight,
+					season, day_type, time_of_day, vector_distance
+				) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10)
+				ON CONFLICT (id) DO NOTHING`,
+				o.ID, lp.PatternKey, o.Distance, o.Source, o.Timestamp, o.Weight,
+				o.Season, o.DayType, o.TimeOfDay, o.VectorDistance,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import observation %s: %w", o.ID, err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				result.ObservationsImported++
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	e.logger.Info("Pattern bundle imported",
+		"behavioral_imported", result.BehavioralPatternsImported,
+		"behavioral_skipped", result.BehavioralPatternsSkipped,
+		"learned_imported", result.LearnedPatternsImported,
+		"learned_skipped", result.LearnedPatternsSkipped,
+		"observations_imported", result.ObservationsImported)
+
+	return result, nil
+}
+
+func insertBehavioralPattern(ctx context.Context, tx *sql.Tx, p *types.BehavioralPattern) (bool, error) {
+	contextJSON, err := marshalJSONB(p.Context)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal context: %w", err)
+	}
+	dominantJSON, err := marshalJSONB(p.DominantContext)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal dominant_context: %w", err)
+	}
+	var interpretationJSON []byte
+	if p.Interpretation != nil {
+		if interpretationJSON, err = json.Marshal(p.Interpretation); err != nil {
+			return false, fmt.Errorf("failed to marshal interpretation: %w", err)
+		}
+	}
+
+	locations := p.Locations
+	if locations == nil {
+		locations = []string{}
+	}
+	weight := p.Weight
+	if weight < 0.1 {
+		weight = 0.1
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO behavioral_patterns (
+			id, name, description, pattern_type, weight, cluster_size, locations,
+			observations, times_observed, predictions, acceptances, rejections,
+			first_seen, last_seen, last_useful, typical_duration_minutes,
+			context, dominant_context, source, window_start, window_end, interpretation
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18,
+			COALESCE(NULLIF($19, ''), 'discovered'), NULLIF($20, '')::time, NULLIF($21, '')::time, $22)
+		ON CONFLICT (id) DO NOTHING`,
+		p.ID, p.Name, p.Description, p.PatternType, weight, p.ClusterSize, pq.Array(locations),
+		p.Observations, p.TimesObserved, p.Predictions, p.Acceptances, p.Rejections,
+		p.FirstSeen, p.LastSeen, p.LastUseful, p.TypicalDurationMinutes,
+		contextJSON, dominantJSON, p.Source, p.WindowStart, p.WindowEnd, interpretationJSON,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func insertLearnedPattern(ctx context.Context, tx *sql.Tx, lp *LearnedPatternRecord) (bool, error) {
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO learned_patterns (
+			pattern_key, weighted_distance, confidence_score, observation_count,
+			first_seen, last_updated, last_computed, decay_half_life_hours,
+			location1, location2, time_of_day1, time_of_day2, day_type1, day_type2,
+			min_distance, max_distance, std_deviation
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+			NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''), NULLIF($12, ''), NULLIF($13, ''), NULLIF($14, ''),
+			$15, $16, $17)
+		ON CONFLICT (household_id, pattern_key) DO NOTHING`,
+		lp.PatternKey, lp.WeightedDistance, lp.ConfidenceScore, lp.ObservationCount,
+		lp.FirstSeen, lp.LastUpdated, lp.LastComputed, lp.DecayHalfLifeHours,
+		lp.Location1, lp.Location2, lp.TimeOfDay1, lp.TimeOfDay2, lp.DayType1, lp.DayType2,
+		lp.MinDistance, lp.MaxDistance, lp.StdDeviation,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// marshalJSONB marshals a JSONB value, using {} for nil/empty maps
+func marshalJSONB(v map[string]interface{}) ([]byte, error) {
+	if len(v) == 0 {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+// WriteFile writes a bundle as indented JSON
+func WriteFile(path string, bundle *Bundle) error {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// ReadFile reads and validates a bundle
+func ReadFile(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
@@ -0,0 +1,86 @@
+package portability
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	minDistance := 0.12
+	bundle := &Bundle{
+		Format:     FormatName,
+		Version:    FormatVersion,
+		ExportedAt: time.Date(2025, 10, 17, 12, 0, 0, 0, time.UTC),
+		BehavioralPatterns: []*types.BehavioralPattern{
+			{ID: uuid.New(), Name: "morning routine", Weight: 0.4, Locations: []string{"kitchen", "bathroom"}},
+		},
+		LearnedPatterns: []*LearnedPatternRecord{
+			{
+				PatternKey:       "kitchen_morning_weekday|dining_room_morning_weekday",
+				WeightedDistance: 0.15,
+				ConfidenceScore:  0.8,
+				MinDistance:      &minDistance,
+				Observations: []ObservationRecord{
+					{ID: uuid.New(), Distance: 0.15, Source: "llm", Weight: 1.0},
+				},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "patterns.json")
+	if err := WriteFile(path, bundle); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	loaded, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	if len(loaded.BehavioralPatterns) != 1 || loaded.BehavioralPatterns[0].Name != "morning routine" {
+		t.Errorf("Expected behavioral pattern to survive round trip, got %+v", loaded.BehavioralPatterns)
+	}
+	if len(loaded.LearnedPatterns) != 1 || len(loaded.LearnedPatterns[0].Observations) != 1 {
+		t.Fatalf("Expected learned pattern with one observation, got %+v", loaded.LearnedPatterns)
+	}
+	if loaded.LearnedPatterns[0].MinDistance == nil || *loaded.LearnedPatterns[0].MinDistance != minDistance {
+		t.Errorf("Expected min_distance %.2f to survive round trip", minDistance)
+	}
+}
+
+func TestBundleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		bundle  Bundle
+		wantErr bool
+	}{
+		{"current version", Bundle{Format: FormatName, Version: FormatVersion}, false},
+		{"wrong format", Bundle{Format: "other", Version: FormatVersion}, true},
+		{"newer version", Bundle{Format: FormatName, Version: FormatVersion + 1}, true},
+		{"missing version", Bundle{Format: FormatName}, true},
+		{"pattern without id", Bundle{
+			Format:             FormatName,
+			Version:            FormatVersion,
+			BehavioralPatterns: []*types.BehavioralPattern{{Name: "x"}},
+		}, true},
+		{"learned pattern without key", Bundle{
+			Format:          FormatName,
+			Version:         FormatVersion,
+			LearnedPatterns: []*LearnedPatternRecord{{}},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.bundle.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,371 @@
+// Package prediction forecasts the household's next activity from learned
+// behavioral patterns and tracks whether those forecasts come true.
+//
+// When activity moves to a new location, the engine looks up transitions out of
+// that location in the anchors of discovered patterns, publishes the most likely
+// next location on automation/behavior/prediction, and later resolves the
+// prediction as accepted (the household moved there) or rejected (it went
+// elsewhere, or nothing happened within the horizon). Outcomes are recorded on
+// the pattern via UpdatePatternPrediction so reliable patterns rank higher.
+// Users can also accept, reject or snooze a prediction on
+// automation/behavior/feedback; see ApplyFeedback.
+package prediction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	behaviorcontext "github.com/saaga0h/jeeves-platform/internal/behavior/context"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+const (
+	// PredictionTopic carries next-activity forecasts
+	PredictionTopic = "automation/behavior/prediction"
+
+	// OutcomeTopic carries the resolution of each forecast
+	OutcomeTopic = "automation/behavior/prediction/outcome"
+)
+
+// Outcome reasons
+const (
+	ReasonArrived           = "arrived"
+	ReasonDifferentLocation = "different_location"
+	ReasonExpired           = "expired"
+)
+
+// TimeManager interface for getting current time (real or virtual)
+type TimeManager interface {
+	Now() time.Time
+}
+
+// Config configures the prediction engine
+type Config struct {
+	MinConfidence    float64       // minimum confidence to publish
+	Horizon          time.Duration // how long a prediction stays open
+	MaxTransitionGap time.Duration // longest anchor gap treated as a transition
+	SnoozeDuration   time.Duration // how long snooze feedback silences a pattern by default
+}
+
+// Prediction is a published next-activity forecast
+type Prediction struct {
+	ID                      uuid.UUID `json:"prediction_id"`
+	CurrentLocation         string    `json:"current_location"`
+	NextLocation            string    `json:"next_location"`
+	ExpectedInMinutes       float64   `json:"expected_in_minutes"`
+	ExpectedDurationMinutes float64   `json:"expected_duration_minutes,omitempty"`
+	Confidence              float64   `json:"confidence"`
+	Support                 int       `json:"support"`
+	PatternID               uuid.UUID `json:"pattern_id"`
+	PatternName             string    `json:"pattern_name"`
+	PatternType             string    `json:"pattern_type,omitempty"`
+	Timestamp               time.Time `json:"timestamp"`
+	ExpiresAt               time.Time `json:"expires_at"`
+}
+
+// Outcome records whether a prediction came true
+type Outcome struct {
+	PredictionID      uuid.UUID `json:"prediction_id"`
+	PatternID         uuid.UUID `json:"pattern_id"`
+	PredictedLocation string    `json:"predicted_location"`
+	ActualLocation    string    `json:"actual_location,omitempty"`
+	Accepted          bool      `json:"accepted"`
+	Reason            string    `json:"reason"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// Engine publishes next-activity predictions and tracks their outcomes
+type Engine struct {
+	config      Config
+	storage     *storage.AnchorStorage
+	mqtt        mqtt.Client
+	logger      *slog.Logger
+	timeManager TimeManager
+
+	mu              sync.Mutex
+	currentLocation string
+	pending         *Prediction
+	recent          []*Prediction           // last published predictions, for feedback by ID
+	snoozed         map[uuid.UUID]time.Time // pattern -> end of snooze
+
+	stopChan chan struct{}
+}
+
+// NewEngine creates a new prediction engine
+func NewEngine(
+	config Config,
+	storage *storage.AnchorStorage,
+	mqttClient mqtt.Client,
+	logger *slog.Logger,
+	timeManager TimeManager,
+) *Engine {
+	return &Engine{
+		config:      config,
+		storage:     storage,
+		mqtt:        mqttClient,
+		logger:      logger.With("component", "prediction"),
+		timeManager: timeManager,
+		snoozed:     make(map[uuid.UUID]time.Time),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start subscribes to motion triggers and begins expiring stale predictions
+func (e *Engine) Start(ctx context.Context) error {
+	handler := func(msg mqtt.Message) {
+		e.handleMotionMessage(ctx, msg)
+	}
+	if err := e.mqtt.Subscribe("automation/sensor/motion/+", 0, handler); err != nil {
+		return fmt.Errorf("failed to subscribe to motion triggers: %w", err)
+	}
+
+	feedbackHandler := func(msg mqtt.Message) {
+		e.handleFeedbackMessage(ctx, msg)
+	}
+	if err := e.mqtt.Subscribe(FeedbackTopic, 0, feedbackHandler); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", FeedbackTopic, err)
+	}
+
+	e.logger.Info("Prediction engine started",
+		"min_confidence", e.config.MinConfidence,
+		"horizon", e.config.Horizon,
+		"max_transition_gap", e.config.MaxTransitionGap,
+		"snooze_duration", e.config.SnoozeDuration)
+
+	go e.expiryLoop(ctx)
+	return nil
+}
+
+// Stop halts the expiry loop
+func (e *Engine) Stop() {
+	close(e.stopChan)
+}
+
+// Pending returns the open prediction, if any
+func (e *Engine) Pending() *Prediction {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.pending
+}
+
+// handleMotionMessage tracks the active location and predicts on location changes
+func (e *Engine) handleMotionMessage(ctx context.Context, msg mqtt.Message) {
+	// Topic: automation/sensor/motion/{location}
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) != 4 {
+		return
+	}
+	location := parts[3]
+
+	var data struct {
+		Timestamp string `json:"timestamp"`
+		State     string `json:"state"`
+	}
+	if err := json.Unmarshal(msg.Payload(), &data); err != nil {
+		e.logger.Debug("Failed to parse motion message", "topic", msg.Topic(), "error", err)
+		return
+	}
+	if data.State != "on" {
+		return
+	}
+
+	ts, err := time.Parse(time.RFC3339, data.Timestamp)
+	if err != nil {
+		ts = e.timeManager.Now()
+	}
+
+	e.ObserveLocation(ctx, location, ts)
+}
+
+// ObserveLocation resolves the open prediction against activity in location and,
+// if the active location changed, publishes a new prediction from there.
+func (e *Engine) ObserveLocation(ctx context.Context, location string, ts time.Time) {
+	e.mu.Lock()
+	if location == e.currentLocation {
+		e.mu.Unlock()
+		return
+	}
+	e.currentLocation = location
+	outcome := e.resolveLocked(location, ts)
+	e.mu.Unlock()
+
+	if outcome != nil {
+		e.recordOutcome(ctx, outcome)
+	}
+
+	e.predict(ctx, location, ts)
+}
+
+// resolveLocked closes the pending prediction given a move to location (caller holds the lock)
+func (e *Engine) resolveLocked(location string, ts time.Time) *Outcome {
+	pending := e.pending
+	if pending == nil {
+		return nil
+	}
+	e.pending = nil
+
+	outcome := &Outcome{
+		PredictionID:      pending.ID,
+		PatternID:         pending.PatternID,
+		PredictedLocation: pending.NextLocation,
+		ActualLocation:    location,
+		Timestamp:         ts,
+	}
+
+	switch {
+	case ts.After(pending.ExpiresAt):
+		outcome.Reason = ReasonExpired
+	case location == pending.NextLocation:
+		outcome.Accepted = true
+		outcome.Reason = ReasonArrived
+	default:
+		outcome.Reason = ReasonDifferentLocation
+	}
+
+	return outcome
+}
+
+// predict publishes the most likely next location from location
+func (e *Engine) predict(ctx context.Context, location string, ts time.Time) {
+	transitions, err := e.storage.GetPatternTransitions(ctx, location, e.config.MaxTransitionGap)
+	if err != nil {
+		e.logger.Error("Failed to load pattern transitions", "location", location, "error", err)
+		return
+	}
+
+	e.mu.Lock()
+	active := transitions[:0]
+	for _, t := range transitions {
+		if !e.snoozedLocked(t.PatternID, ts) {
+			active = append(active, t)
+		}
+	}
+	e.mu.Unlock()
+
+	candidates := RankNextLocations(active, behaviorcontext.CategorizeTimeOfDay(ts))
+	if len(candidates) == 0 {
+		e.logger.Debug("No learned transitions from location", "location", location)
+		return
+	}
+
+	best := candidates[0]
+	if best.Confidence < e.config.MinConfidence {
+		e.logger.Debug("Best prediction below confidence threshold",
+			"location", location,
+			"next_location", best.Location,
+			"confidence", best.Confidence,
+			"min_confidence", e.config.MinConfidence)
+		return
+	}
+
+	prediction := &Prediction{
+		ID:                      uuid.New(),
+		CurrentLocation:         location,
+		NextLocation:            best.Location,
+		ExpectedInMinutes:       best.ExpectedInMinutes,
+		ExpectedDurationMinutes: best.ExpectedDurationMinutes,
+		Confidence:              best.Confidence,
+		Support:                 best.Support,
+		PatternID:               best.PatternID,
+		PatternName:             best.PatternName,
+		PatternType:             best.PatternType,
+		Timestamp:               ts,
+		ExpiresAt:               ts.Add(e.config.Horizon),
+	}
+
+	e.mu.Lock()
+	if e.currentLocation != location {
+		// Activity moved on while we were querying
+		e.mu.Unlock()
+		return
+	}
+	e.pending = prediction
+	e.rememberLocked(prediction)
+	e.mu.Unlock()
+
+	e.logger.Info("Next activity predicted",
+		"prediction_id", prediction.ID,
+		"from", location,
+		"to", prediction.NextLocation,
+		"expected_in_min", prediction.ExpectedInMinutes,
+		"confidence", prediction.Confidence,
+		"pattern", prediction.PatternName)
+
+	e.publish(PredictionTopic, prediction)
+}
+
+func (e *Engine) expiryLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			e.expire(ctx)
+		}
+	}
+}
+
+// expire rejects the pending prediction once its horizon has passed without a move
+func (e *Engine) expire(ctx context.Context) {
+	now := e.timeManager.Now()
+
+	e.mu.Lock()
+	if e.pending == nil || !now.After(e.pending.ExpiresAt) {
+		e.mu.Unlock()
+		return
+	}
+	outcome := &Outcome{
+		PredictionID:      e.pending.ID,
+		PatternID:         e.pending.PatternID,
+		PredictedLocation: e.pending.NextLocation,
+		Reason:            ReasonExpired,
+		Timestamp:         now,
+	}
+	e.pending = nil
+	e.mu.Unlock()
+
+	e.recordOutcome(ctx, outcome)
+}
+
+// recordOutcome feeds the outcome into pattern statistics and announces it
+func (e *Engine) recordOutcome(ctx context.Context, outcome *Outcome) {
+	if err := e.storage.UpdatePatternPrediction(ctx, outcome.PatternID, outcome.Accepted); err != nil {
+		e.logger.Error("Failed to record prediction outcome",
+			"pattern_id", outcome.PatternID,
+			"error", err)
+	}
+
+	e.logger.Info("Prediction resolved",
+		"prediction_id", outcome.PredictionID,
+		"predicted", outcome.PredictedLocation,
+		"actual", outcome.ActualLocation,
+		"accepted", outcome.Accepted,
+		"reason", outcome.Reason)
+
+	e.publish(OutcomeTopic, outcome)
+}
+
+func (e *Engine) publish(topic string, payload interface{}) {
+	data, err := events.Marshal(events.ProducerBehavior, payload)
+	if err != nil {
+		e.logger.Error("Failed to marshal prediction message", "topic", topic, "error", err)
+		return
+	}
+	if err := e.mqtt.Publish(topic, 0, false, data); err != nil {
+		e.logger.Error("Failed to publish prediction message", "topic", topic, "error", err)
+	}
+}
@@ -0,0 +1,200 @@
+package prediction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+const (
+	// FeedbackTopic accepts user responses to predictions and automations
+	FeedbackTopic = "automation/behavior/feedback"
+
+	// FeedbackRecordedTopic announces feedback once it has been applied
+	FeedbackRecordedTopic = "automation/behavior/feedback/recorded"
+)
+
+// Feedback responses
+const (
+	FeedbackAccept = "accept"
+	FeedbackReject = "reject"
+	FeedbackSnooze = "snooze" // silence the pattern's predictions for a while
+)
+
+// Outcome reasons for explicit feedback
+const (
+	ReasonUserAccepted = "user_accepted"
+	ReasonUserRejected = "user_rejected"
+)
+
+// recentPredictions is how many published predictions feedback can refer to by ID
+const recentPredictions = 20
+
+// ErrUnknownPrediction is returned for feedback on a prediction the engine no
+// longer remembers and that names no pattern
+var ErrUnknownPrediction = errors.New("unknown prediction")
+
+// Feedback is a user's response to a prediction, or to an automation acting on
+// a pattern, received over HTTP or MQTT
+type Feedback struct {
+	PredictionID  uuid.UUID  `json:"prediction_id,omitempty"`
+	PatternID     uuid.UUID  `json:"pattern_id,omitempty"` // filled from the prediction when omitted
+	Location      string     `json:"location,omitempty"`   // room the automation acted in
+	Response      string     `json:"response"`
+	SnoozeMinutes int        `json:"snooze_minutes,omitempty"` // 0 uses the configured snooze duration
+	Brightness    int        `json:"brightness,omitempty"`     // lighting wanted instead, with reject
+	ColorTemp     int        `json:"color_temp,omitempty"`
+	Author        string     `json:"author,omitempty"`
+	SnoozedUntil  *time.Time `json:"snoozed_until,omitempty"`
+	Timestamp     time.Time  `json:"timestamp"`
+}
+
+// Validate checks that the feedback names a response and what it is about
+func (f Feedback) Validate() error {
+	switch f.Response {
+	case FeedbackAccept, FeedbackReject, FeedbackSnooze:
+	default:
+		return fmt.Errorf("unknown response %q (expected %q, %q or %q)", f.Response, FeedbackAccept, FeedbackReject, FeedbackSnooze)
+	}
+
+	if f.PredictionID == uuid.Nil && f.PatternID == uuid.Nil {
+		if f.Response == FeedbackSnooze || f.Location == "" {
+			return fmt.Errorf("prediction_id or pattern_id is required")
+		}
+	}
+	if f.SnoozeMinutes < 0 {
+		return fmt.Errorf("snooze_minutes must not be negative")
+	}
+	if f.Brightness < 0 || f.Brightness > 100 {
+		return fmt.Errorf("brightness must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// ApplyFeedback records a user's response. Accept and reject count as a
+// prediction outcome for the pattern; feedback on the open prediction settles
+// it so the next move does not count it again. Snooze suppresses the pattern's
+// predictions until the snooze runs out. The applied feedback is published on
+// FeedbackRecordedTopic for agents that learn from it.
+func (e *Engine) ApplyFeedback(ctx context.Context, feedback Feedback) (*Feedback, error) {
+	if err := feedback.Validate(); err != nil {
+		return nil, err
+	}
+	now := e.timeManager.Now()
+	feedback.Timestamp = now
+
+	e.mu.Lock()
+	var predicted *Prediction
+	if feedback.PredictionID != uuid.Nil {
+		predicted = e.findLocked(feedback.PredictionID)
+		if predicted == nil && feedback.PatternID == uuid.Nil {
+			e.mu.Unlock()
+			return nil, ErrUnknownPrediction
+		}
+	}
+	if predicted != nil {
+		feedback.PatternID = predicted.PatternID
+		if feedback.Location == "" {
+			feedback.Location = predicted.NextLocation
+		}
+	}
+
+	if e.pending != nil && feedback.PatternID != uuid.Nil &&
+		(e.pending.ID == feedback.PredictionID || (feedback.Response == FeedbackSnooze && e.pending.PatternID == feedback.PatternID)) {
+		e.pending = nil
+	}
+
+	if feedback.Response == FeedbackSnooze {
+		duration := e.config.SnoozeDuration
+		if feedback.SnoozeMinutes > 0 {
+			duration = time.Duration(feedback.SnoozeMinutes) * time.Minute
+		}
+		until := now.Add(duration)
+		if e.snoozed == nil {
+			e.snoozed = make(map[uuid.UUID]time.Time)
+		}
+		e.snoozed[feedback.PatternID] = until
+		feedback.SnoozedUntil = &until
+	}
+	e.mu.Unlock()
+
+	if feedback.Response != FeedbackSnooze && feedback.PatternID != uuid.Nil {
+		outcome := &Outcome{
+			PredictionID:      feedback.PredictionID,
+			PatternID:         feedback.PatternID,
+			PredictedLocation: feedback.Location,
+			Accepted:          feedback.Response == FeedbackAccept,
+			Reason:            ReasonUserRejected,
+			Timestamp:         now,
+		}
+		if outcome.Accepted {
+			outcome.Reason = ReasonUserAccepted
+		}
+		e.recordOutcome(ctx, outcome)
+	}
+
+	e.logger.Info("Feedback recorded",
+		"prediction_id", feedback.PredictionID,
+		"pattern_id", feedback.PatternID,
+		"location", feedback.Location,
+		"response", feedback.Response,
+		"author", feedback.Author)
+
+	e.publish(FeedbackRecordedTopic, feedback)
+	return &feedback, nil
+}
+
+// findLocked returns a recently published prediction (caller holds the lock)
+func (e *Engine) findLocked(id uuid.UUID) *Prediction {
+	for _, p := range e.recent {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// rememberLocked keeps a published prediction for later feedback (caller holds the lock)
+func (e *Engine) rememberLocked(p *Prediction) {
+	e.recent = append(e.recent, p)
+	if len(e.recent) > recentPredictions {
+		e.recent = e.recent[len(e.recent)-recentPredictions:]
+	}
+}
+
+// snoozedLocked reports whether a pattern's predictions are suppressed at ts,
+// forgetting snoozes that have run out (caller holds the lock)
+func (e *Engine) snoozedLocked(patternID uuid.UUID, ts time.Time) bool {
+	until, ok := e.snoozed[patternID]
+	if !ok {
+		return false
+	}
+	if !ts.Before(until) {
+		delete(e.snoozed, patternID)
+		return false
+	}
+	return true
+}
+
+// handleFeedbackMessage handles feedback submitted over MQTT
+func (e *Engine) handleFeedbackMessage(ctx context.Context, msg mqtt.Message) {
+	var feedback Feedback
+	if err := json.Unmarshal(msg.Payload(), &feedback); err != nil {
+		e.logger.Error("Failed to parse feedback", "error", err)
+		return
+	}
+
+	if _, err := e.ApplyFeedback(ctx, feedback); err != nil {
+		e.logger.Error("Failed to apply feedback",
+			"prediction_id", feedback.PredictionID,
+			"response", feedback.Response,
+			"error", err)
+	}
+}
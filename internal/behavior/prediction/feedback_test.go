@@ -0,0 +1,106 @@
+package prediction
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+type fixedTime struct{ now time.Time }
+
+func (f fixedTime) Now() time.Time { return f.now }
+
+// stubMQTT records published topics
+type stubMQTT struct {
+	published []string
+}
+
+func (s *stubMQTT) Connect(ctx context.Context) error { return nil }
+func (s *stubMQTT) Disconnect()                       {}
+func (s *stubMQTT) IsConnected() bool                 { return true }
+func (s *stubMQTT) Subscribe(topic string, qos byte, handler mqtt.MessageHandler) error {
+	return nil
+}
+func (s *stubMQTT) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	s.published = append(s.published, topic)
+	return nil
+}
+
+func TestFeedback_Validate(t *testing.T) {
+	patternID := uuid.New()
+
+	tests := []struct {
+		name     string
+		feedback Feedback
+		valid    bool
+	}{
+		{"accept prediction", Feedback{PredictionID: uuid.New(), Response: FeedbackAccept}, true},
+		{"snooze pattern", Feedback{PatternID: patternID, Response: FeedbackSnooze, SnoozeMinutes: 60}, true},
+		{"reject automation in a room", Feedback{Location: "study", Response: FeedbackReject, Brightness: 40}, true},
+		{"unknown response", Feedback{PatternID: patternID, Response: "maybe"}, false},
+		{"nothing to snooze", Feedback{Location: "study", Response: FeedbackSnooze}, false},
+		{"no subject", Feedback{Response: FeedbackAccept}, false},
+		{"negative snooze", Feedback{PatternID: patternID, Response: FeedbackSnooze, SnoozeMinutes: -5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.feedback.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got error %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestApplyFeedback_Snooze(t *testing.T) {
+	now := time.Date(2025, 10, 17, 18, 0, 0, 0, time.UTC)
+	client := &stubMQTT{}
+	engine := NewEngine(Config{SnoozeDuration: 2 * time.Hour}, nil, client,
+		slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})), fixedTime{now})
+
+	predicted := &Prediction{ID: uuid.New(), PatternID: uuid.New(), NextLocation: "dining_room"}
+	engine.pending = predicted
+	engine.rememberLocked(predicted)
+
+	feedback, err := engine.ApplyFeedback(context.Background(), Feedback{PredictionID: predicted.ID, Response: FeedbackSnooze})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if feedback.PatternID != predicted.PatternID || feedback.Location != "dining_room" {
+		t.Errorf("Expected pattern and location from the prediction, got %+v", feedback)
+	}
+	if feedback.SnoozedUntil == nil || !feedback.SnoozedUntil.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("Expected default snooze until %v, got %v", now.Add(2*time.Hour), feedback.SnoozedUntil)
+	}
+	if engine.pending != nil {
+		t.Error("Expected snooze to settle the open prediction")
+	}
+	if len(client.published) != 1 || client.published[0] != FeedbackRecordedTopic {
+		t.Errorf("Expected feedback published on %s, got %v", FeedbackRecordedTopic, client.published)
+	}
+
+	if !engine.snoozedLocked(predicted.PatternID, now.Add(time.Hour)) {
+		t.Error("Expected pattern snoozed within the snooze")
+	}
+	if engine.snoozedLocked(predicted.PatternID, now.Add(3*time.Hour)) {
+		t.Error("Expected snooze to run out")
+	}
+}
+
+func TestApplyFeedback_UnknownPrediction(t *testing.T) {
+	engine := NewEngine(Config{}, nil, &stubMQTT{},
+		slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})), fixedTime{time.Now()})
+
+	_, err := engine.ApplyFeedback(context.Background(), Feedback{PredictionID: uuid.New(), Response: FeedbackReject})
+	if err != ErrUnknownPrediction {
+		t.Errorf("Expected ErrUnknownPrediction, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package prediction
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+// offContextFactor discounts transitions observed at a different time of day
+const offContextFactor = 0.5
+
+// Candidate is a possible next location with its aggregated evidence
+type Candidate struct {
+	Location                string
+	Confidence              float64   // 0.0-1.0
+	ExpectedInMinutes       float64   // weighted mean gap before the move
+	ExpectedDurationMinutes float64   // weighted mean time spent there, 0 when unknown
+	Support                 int       // number of transitions behind this candidate
+	PatternID               uuid.UUID // strongest supporting pattern
+	PatternName             string
+	PatternType             string
+}
+
+// RankNextLocations aggregates pattern transitions into next-location candidates,
+// most confident first.
+//
+// Each transition scores pattern weight × pattern reliability (smoothed acceptance
+// rate), halved when it was observed at a different time of day. A candidate's
+// confidence is its share of the total score, scaled down when few transitions
+// support it, so a single observation never yields a confident prediction.
+func RankNextLocations(transitions []*types.PatternTransition, timeOfDay string) []Candidate {
+	type aggregate struct {
+		score          float64
+		gapSum         float64
+		durationSum    float64
+		durationWeight float64
+		support        int
+		bestScore      float64
+		best           *types.PatternTransition
+	}
+
+	byLocation := make(map[string]*aggregate)
+	total := 0.0
+
+	for _, t := range transitions {
+		score := transitionScore(t, timeOfDay)
+		if score <= 0 {
+			continue
+		}
+
+		agg, ok := byLocation[t.ToLocation]
+		if !ok {
+			agg = &aggregate{}
+			byLocation[t.ToLocation] = agg
+		}
+
+		agg.score += score
+		agg.gapSum += t.GapMinutes * score
+		if t.DurationMinutes != nil {
+			agg.durationSum += float64(*t.DurationMinutes) * score
+			agg.durationWeight += score
+		}
+		agg.support++
+		if score > agg.bestScore {
+			agg.bestScore = score
+			agg.best = t
+		}
+		total += score
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	candidates := make([]Candidate, 0, len(byLocation))
+	for location, agg := range byLocation {
+		support := float64(agg.support)
+		candidate := Candidate{
+			Location:          location,
+			Confidence:        (agg.score / total) * support / (support + 2),
+			ExpectedInMinutes: agg.gapSum / agg.score,
+			Support:           agg.support,
+			PatternID:         agg.best.PatternID,
+			PatternName:       agg.best.PatternName,
+			PatternType:       agg.best.PatternType,
+		}
+		if agg.durationWeight > 0 {
+			candidate.ExpectedDurationMinutes = agg.durationSum / agg.durationWeight
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].Location < candidates[j].Location
+	})
+
+	return candidates
+}
+
+// transitionScore weighs a single transition by its pattern's weight and track record
+func transitionScore(t *types.PatternTransition, timeOfDay string) float64 {
+	reliability := float64(t.Acceptances+1) / float64(t.Predictions+2)
+	score := t.PatternWeight * reliability
+
+	if timeOfDay != "" && t.TimeOfDay != "" && t.TimeOfDay != timeOfDay {
+		score *= offContextFactor
+	}
+
+	return score
+}
@@ -0,0 +1,151 @@
+package prediction
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestRankNextLocations(t *testing.T) {
+	dinner := uuid.New()
+	tv := uuid.New()
+
+	transitions := []*types.PatternTransition{
+		createTestTransition(dinner, "kitchen", "dining_room", "evening", 5, intPtr(40)),
+		createTestTransition(dinner, "kitchen", "dining_room", "evening", 7, intPtr(30)),
+		createTestTransition(tv, "kitchen", "living_room", "evening", 10, nil),
+	}
+
+	candidates := RankNextLocations(transitions, "evening")
+
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d", len(candidates))
+	}
+
+	best := candidates[0]
+	if best.Location != "dining_room" {
+		t.Errorf("Expected dining_room first, got %s", best.Location)
+	}
+	if best.PatternID != dinner {
+		t.Errorf("Expected dinner pattern to back the best candidate")
+	}
+	if best.Support != 2 {
+		t.Errorf("Expected support 2, got %d", best.Support)
+	}
+	if math.Abs(best.ExpectedInMinutes-6) > 1e-9 {
+		t.Errorf("Expected mean gap 6 min, got %.2f", best.ExpectedInMinutes)
+	}
+	if math.Abs(best.ExpectedDurationMinutes-35) > 1e-9 {
+		t.Errorf("Expected mean duration 35 min, got %.2f", best.ExpectedDurationMinutes)
+	}
+	// 2/3 of the score, scaled by support 2/(2+2)
+	if math.Abs(best.Confidence-1.0/3.0) > 1e-9 {
+		t.Errorf("Expected confidence 0.333, got %.3f", best.Confidence)
+	}
+
+	if candidates[1].ExpectedDurationMinutes != 0 {
+		t.Errorf("Expected unknown duration for living_room, got %.2f", candidates[1].ExpectedDurationMinutes)
+	}
+}
+
+func TestRankNextLocations_TimeOfDayDiscount(t *testing.T) {
+	pattern := uuid.New()
+	transitions := []*types.PatternTransition{
+		createTestTransition(pattern, "kitchen", "dining_room", "evening", 5, nil),
+		createTestTransition(pattern, "kitchen", "study", "morning", 5, nil),
+	}
+
+	candidates := RankNextLocations(transitions, "morning")
+
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d", len(candidates))
+	}
+	if candidates[0].Location != "study" {
+		t.Errorf("Expected in-context transition to rank first, got %s", candidates[0].Location)
+	}
+}
+
+func TestRankNextLocations_ReliabilityFromOutcomes(t *testing.T) {
+	reliable := createTestTransition(uuid.New(), "hallway", "bathroom", "", 2, nil)
+	reliable.Predictions = 10
+	reliable.Acceptances = 9
+
+	unreliable := createTestTransition(uuid.New(), "hallway", "study", "", 2, nil)
+	unreliable.Predictions = 10
+	unreliable.Acceptances = 1
+
+	candidates := RankNextLocations([]*types.PatternTransition{unreliable, reliable}, "")
+
+	if candidates[0].Location != "bathroom" {
+		t.Errorf("Expected pattern with accepted predictions to rank first, got %s", candidates[0].Location)
+	}
+}
+
+func TestRankNextLocations_Empty(t *testing.T) {
+	if candidates := RankNextLocations(nil, "morning"); candidates != nil {
+		t.Errorf("Expected no candidates, got %d", len(candidates))
+	}
+}
+
+func TestResolvePending(t *testing.T) {
+	now := time.Date(2025, 10, 17, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		location string
+		at       time.Time
+		accepted bool
+		reason   string
+	}{
+		{"arrived at predicted location", "dining_room", now.Add(5 * time.Minute), true, ReasonArrived},
+		{"went somewhere else", "living_room", now.Add(5 * time.Minute), false, ReasonDifferentLocation},
+		{"arrived after horizon", "dining_room", now.Add(time.Hour), false, ReasonExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &Engine{pending: &Prediction{
+				ID:           uuid.New(),
+				NextLocation: "dining_room",
+				Timestamp:    now,
+				ExpiresAt:    now.Add(30 * time.Minute),
+			}}
+
+			outcome := engine.resolveLocked(tt.location, tt.at)
+
+			if outcome == nil {
+				t.Fatal("Expected an outcome")
+			}
+			if outcome.Accepted != tt.accepted {
+				t.Errorf("Expected accepted=%v, got %v", tt.accepted, outcome.Accepted)
+			}
+			if outcome.Reason != tt.reason {
+				t.Errorf("Expected reason %s, got %s", tt.reason, outcome.Reason)
+			}
+			if engine.pending != nil {
+				t.Error("Expected pending prediction to be cleared")
+			}
+		})
+	}
+}
+
+func createTestTransition(patternID uuid.UUID, from, to, timeOfDay string, gap float64, duration *int) *types.PatternTransition {
+	return &types.PatternTransition{
+		PatternID:       patternID,
+		PatternName:     "test pattern",
+		PatternWeight:   0.1,
+		FromLocation:    from,
+		ToLocation:      to,
+		TimeOfDay:       timeOfDay,
+		GapMinutes:      gap,
+		DurationMinutes: duration,
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
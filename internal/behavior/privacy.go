@@ -0,0 +1,53 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+)
+
+// privacyPurgedKey lists the excluded locations whose data has been purged
+// (string, JSON array), so a location is purged once when it becomes a
+// privacy zone and again if it is excluded after having been re-included
+const privacyPurgedKey = "behavior:privacy:purged_locations"
+
+// purgePrivacyZones deletes the anchors, episodes and patterns of locations
+// excluded since the last start. Failed locations are retried on next start.
+func (a *Agent) purgePrivacyZones(ctx context.Context) {
+	purged := make(map[string]bool)
+	if raw, err := a.redis.Get(ctx, privacyPurgedKey); err == nil && raw != "" {
+		var previous []string
+		if err := json.Unmarshal([]byte(raw), &previous); err != nil {
+			a.logger.Warn("Ignoring unreadable privacy purge record", "error", err)
+		}
+		for _, location := range previous {
+			purged[location] = true
+		}
+	}
+
+	var done []string
+	for _, location := range a.privacy.Locations() {
+		if purged[location] {
+			done = append(done, location)
+			continue
+		}
+
+		if _, err := a.purgeData(ctx, storage.PurgeFilter{Location: location}); err != nil {
+			a.logger.Error("Failed to purge privacy zone", "location", location, "error", err)
+			continue
+		}
+		a.logger.Info("Purged newly excluded privacy zone", "location", location)
+		done = append(done, location)
+	}
+
+	// Locations no longer excluded drop out, so excluding them again purges
+	payload, err := json.Marshal(done)
+	if err != nil {
+		a.logger.Error("Failed to marshal privacy purge record", "error", err)
+		return
+	}
+	if err := a.redis.Set(ctx, privacyPurgedKey, string(payload), 0); err != nil {
+		a.logger.Warn("Failed to record purged privacy zones", "error", err)
+	}
+}
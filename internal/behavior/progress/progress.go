@@ -0,0 +1,25 @@
+// Package progress lets long-running behavior operations (consolidation,
+// pattern discovery) report the phase they are in to whoever started them,
+// without threading a callback through every function signature.
+package progress
+
+import "context"
+
+// Reporter receives phase updates. Detail carries phase-specific counters and
+// may be nil.
+type Reporter func(phase string, detail map[string]interface{})
+
+type reporterKey struct{}
+
+// WithReporter returns a context that delivers Phase calls to r
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// Phase reports that the operation running under ctx entered (or updated) a
+// phase. It is a no-op when no reporter is attached.
+func Phase(ctx context.Context, phase string, detail map[string]interface{}) {
+	if r, ok := ctx.Value(reporterKey{}).(Reporter); ok && r != nil {
+		r(phase, detail)
+	}
+}
@@ -0,0 +1,44 @@
+package progress
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPhase_NoReporter(t *testing.T) {
+	// Must not panic without a reporter
+	Phase(context.Background(), "anything", nil)
+}
+
+func TestPhase_DeliversToReporter(t *testing.T) {
+	var phases []string
+	var last map[string]interface{}
+
+	ctx := WithReporter(context.Background(), func(phase string, detail map[string]interface{}) {
+		phases = append(phases, phase)
+		last = detail
+	})
+
+	Phase(ctx, "loading", nil)
+	Phase(ctx, "clustering", map[string]interface{}{"clusters": 3})
+
+	if len(phases) != 2 || phases[0] != "loading" || phases[1] != "clustering" {
+		t.Fatalf("unexpected phases: %v", phases)
+	}
+	if last["clusters"] != 3 {
+		t.Errorf("expected detail clusters=3, got %v", last["clusters"])
+	}
+}
+
+func TestPhase_SurvivesDerivedContext(t *testing.T) {
+	called := false
+	ctx := WithReporter(context.Background(), func(string, map[string]interface{}) { called = true })
+
+	derived, cancel := context.WithCancel(ctx)
+	defer cancel()
+	Phase(derived, "x", nil)
+
+	if !called {
+		t.Error("expected reporter to be reached through derived context")
+	}
+}
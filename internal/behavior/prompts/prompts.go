@@ -0,0 +1,137 @@
+// Package prompts holds the behavior agent's LLM prompt templates and the data
+// each is rendered with. The built-in templates in templates/ can be replaced,
+// per prompt and per model, by files in JEEVES_LLM_PROMPT_DIR (see
+// llm.Prompts).
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/saaga0h/jeeves-platform/pkg/llm"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// Prompt names, also the template file names
+const (
+	Distance       = "distance"       // rate the semantic distance of two anchors
+	Consolidation  = "consolidation"  // decide whether micro-episodes form one macro-episode
+	Interpretation = "interpretation" // name the pattern of an anchor cluster
+	Briefing       = "briefing"       // write the morning briefing about yesterday
+)
+
+// Anchor describes a semantic anchor in a prompt
+type Anchor struct {
+	Location  string
+	Time      string // 15:04
+	TimeOfDay string
+	DayType   string
+	Season    string
+	Signals   int
+}
+
+// DistanceData renders the Distance prompt
+type DistanceData struct {
+	Anchor1    Anchor
+	Anchor2    Anchor
+	HomeLayout string // topology description of the two locations
+}
+
+// ConsolidationData renders the Consolidation prompt
+type ConsolidationData struct {
+	Examples []string // user-labeled episodes, one line each
+	Data     string   // episodes and context as indented JSON
+}
+
+// ClusterAnchor is an anchor listed in the Interpretation prompt
+type ClusterAnchor struct {
+	Number int
+	Anchor
+}
+
+// InterpretationData renders the Interpretation prompt
+type InterpretationData struct {
+	Total      int
+	Anchors    []ClusterAnchor // the first few anchors
+	More       int             // anchors not listed
+	Locations  []string
+	TimesOfDay []string
+	DayTypes   []string
+}
+
+// BriefingData renders the Briefing prompt
+type BriefingData struct {
+	Date      string   // the day summarized, e.g. "Tuesday 14 October"
+	Tone      string   // e.g. "warm and concise"
+	Sleep     string   // last night, e.g. "7h20m (23:40-07:00)"; empty when none was detected
+	Episodes  []string // yesterday's routines, one line each
+	Rooms     []string // time per room, one line each
+	Anomalies []string // deviations from the preceding days
+}
+
+// samples fill every field, so validation reaches every branch of a template
+var samples = map[string]any{
+	Distance: DistanceData{
+		Anchor1:    Anchor{Location: "kitchen", Time: "07:00", TimeOfDay: "morning", DayType: "weekday", Season: "winter", Signals: 3},
+		Anchor2:    Anchor{Location: "dining_room", Time: "07:30", TimeOfDay: "morning", DayType: "weekday", Season: "winter", Signals: 2},
+		HomeLayout: "kitchen and dining_room are adjacent",
+	},
+	Consolidation: ConsolidationData{
+		Examples: []string{"kitchen(07:00)→dining_room(07:20) = breakfast"},
+		Data:     "{}",
+	},
+	Interpretation: InterpretationData{
+		Total:      11,
+		Anchors:    []ClusterAnchor{{Number: 1, Anchor: Anchor{Location: "kitchen", Time: "07:00", TimeOfDay: "morning", DayType: "weekday", Season: "winter"}}},
+		More:       1,
+		Locations:  []string{"kitchen"},
+		TimesOfDay: []string{"morning"},
+		DayTypes:   []string{"weekday"},
+	},
+	Briefing: BriefingData{
+		Date:      "Tuesday 14 October",
+		Tone:      "warm and concise",
+		Sleep:     "7h20m (23:40-07:00)",
+		Episodes:  []string{"21:10-23:35 work_session (study)"},
+		Rooms:     []string{"study: 3h05m"},
+		Anomalies: []string{"study: 185 min vs usual 60 min"},
+	},
+}
+
+// New loads and validates every prompt, with overrides from dir when it is
+// not empty
+func New(dir string) (*llm.Prompts, error) {
+	builtin, err := fs.Sub(templateFiles, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open built-in prompts: %w", err)
+	}
+
+	prompts := llm.NewPrompts(builtin, dir)
+	for name, sample := range samples {
+		if err := prompts.Register(name, sample); err != nil {
+			return nil, err
+		}
+	}
+	return prompts, nil
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultPrompts *llm.Prompts
+)
+
+// Default returns the built-in prompts, for components created without any
+func Default() *llm.Prompts {
+	defaultOnce.Do(func() {
+		prompts, err := New("")
+		if err != nil {
+			panic(fmt.Sprintf("invalid built-in prompts: %v", err))
+		}
+		defaultPrompts = prompts
+	})
+	return defaultPrompts
+}
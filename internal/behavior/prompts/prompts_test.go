@@ -0,0 +1,112 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinPrompts(t *testing.T) {
+	p, err := New("")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	prompt, err := p.Render(Distance, "mixtral:8x7b", samples[Distance])
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(prompt, "- Location: dining_room") || !strings.Contains(prompt, "Home layout: kitchen and dining_room are adjacent") {
+		t.Errorf("distance prompt missing anchor data:\n%s", prompt)
+	}
+
+	prompt, err = p.Render(Interpretation, "", samples[Interpretation])
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(prompt, "Anchor 1: kitchen @ 07:00 (morning, weekday, winter)\n... and 1 more anchors") {
+		t.Errorf("interpretation prompt missing anchors:\n%s", prompt)
+	}
+
+	prompt, err = p.Render(Briefing, "", BriefingData{Date: "Tuesday 14 October", Tone: "formal"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(prompt, "No sleep was detected") || !strings.Contains(prompt, "- none detected") || strings.Contains(prompt, "Unusual") {
+		t.Errorf("briefing prompt mishandles an empty day:\n%s", prompt)
+	}
+
+	prompt, err = p.Render(Consolidation, "", ConsolidationData{Data: "{}"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.Contains(prompt, "Confirmed by this household") {
+		t.Errorf("consolidation prompt lists examples when there are none:\n%s", prompt)
+	}
+}
+
+func TestPromptOverrides(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("distance.tmpl", "generic {{.Anchor1.Location}}")
+	write("distance.mixtral.tmpl", "mixtral {{.Anchor1.Location}}")
+	write("distance.llama3.2_3b.tmpl", "llama {{.Anchor1.Location}}")
+
+	p, err := New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"mixtral:8x7b", "mixtral kitchen"},
+		{"llama3.2:3b", "llama kitchen"},
+		{"llama3.2:1b", "generic kitchen"},
+		{"", "generic kitchen"},
+	}
+	for _, tt := range tests {
+		got, err := p.Render(Distance, tt.model, samples[Distance])
+		if err != nil {
+			t.Fatalf("Render(%q): %v", tt.model, err)
+		}
+		if got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+
+	// Prompts without overrides keep the built-in template
+	if got := p.Variants(Interpretation); len(got) != 1 || got[0] != Interpretation {
+		t.Errorf("Variants(%s) = %v, want built-in only", Interpretation, got)
+	}
+}
+
+func TestPromptValidation(t *testing.T) {
+	tests := map[string]string{
+		"unknown field": "{{.Anchor1.Room}}",
+		"syntax error":  "{{.Anchor1.Location",
+		"in a branch":   "{{if .HomeLayout}}{{.Layout}}{{end}}",
+	}
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "distance.tmpl"), []byte(text), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := New(dir); err == nil {
+				t.Errorf("New accepted %q", text)
+			}
+		})
+	}
+
+	if _, err := New(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("New accepted a missing prompt directory")
+	}
+}
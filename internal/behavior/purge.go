@@ -0,0 +1,85 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+const (
+	purgeTopic          = "automation/behavior/purge"
+	purgeCompletedTopic = "automation/behavior/purge/completed"
+)
+
+// PurgeResponse reports a completed purge
+type PurgeResponse struct {
+	Filter storage.PurgeFilter  `json:"filter"`
+	Result *storage.PurgeResult `json:"result"`
+}
+
+// purgeData deletes everything recorded for a location and/or time range,
+// e.g. while guests were visiting
+func (a *Agent) purgeData(ctx context.Context, filter storage.PurgeFilter) (*storage.PurgeResult, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	db, err := a.getDBConnection()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	result, err := storage.NewAnchorStorage(db).Purge(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge data: %w", err)
+	}
+
+	a.logger.Info("Purged behavioral data",
+		"location", filter.Location,
+		"since", filter.Since,
+		"until", filter.Until,
+		"anchors", result.Anchors,
+		"distances", result.Distances,
+		"observations", result.Observations,
+		"patterns_deleted", result.PatternsDeleted,
+		"episodes", result.Episodes,
+		"macro_episodes", result.MacroEpisodes,
+		"annotations", result.Annotations)
+
+	a.publishPurgeResult(filter, result)
+	return result, nil
+}
+
+// handlePurgeMessage handles purge requests submitted over MQTT
+func (a *Agent) handlePurgeMessage(msg mqtt.Message) {
+	var filter storage.PurgeFilter
+	if err := json.Unmarshal(msg.Payload(), &filter); err != nil {
+		a.logger.Error("Failed to parse purge request", "error", err)
+		return
+	}
+
+	if _, err := a.purgeData(context.Background(), filter); err != nil {
+		a.logger.Error("Failed to purge data",
+			"location", filter.Location,
+			"since", filter.Since,
+			"until", filter.Until,
+			"error", err)
+	}
+}
+
+// publishPurgeResult announces a completed purge
+func (a *Agent) publishPurgeResult(filter storage.PurgeFilter, result *storage.PurgeResult) {
+	payload, err := events.Marshal(events.ProducerBehavior, PurgeResponse{Filter: filter, Result: result})
+	if err != nil {
+		a.logger.Error("Failed to marshal purge result", "error", err)
+		return
+	}
+
+	if err := a.mqtt.Publish(purgeCompletedTopic, 0, false, payload); err != nil {
+		a.logger.Error("Failed to publish purge result", "error", err)
+	}
+}
@@ -0,0 +1,382 @@
+package behavior
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+)
+
+const (
+	// sleepPatternType is the macro-episode pattern type for detected sleep
+	sleepPatternType = "Sleeping"
+
+	// briefAwakeningWindow is how long someone may leave the bedroom at night
+	// (bathroom, water) and still be counted as the same sleep period
+	briefAwakeningWindow = 30 * time.Minute
+
+	// sleepPeriodRetention bounds how long detected periods are kept in memory
+	sleepPeriodRetention = 3 * 24 * time.Hour
+)
+
+// SleepPeriod is a detected night of sleep in one location
+type SleepPeriod struct {
+	Location      string
+	SleepAt       time.Time
+	WakeAt        time.Time
+	Interruptions int
+	Confidence    float64
+}
+
+// Contains reports whether ts falls inside the sleep period in the given location
+func (p SleepPeriod) Contains(location string, ts time.Time) bool {
+	return p.Location == location && !ts.Before(p.SleepAt) && !ts.After(p.WakeAt)
+}
+
+// isSleepOnsetHour reports whether lights-off at this hour can start a sleep period
+func isSleepOnsetHour(hour int) bool {
+	return hour >= 20 || hour < 4
+}
+
+// isWakeActivity reports whether an event outside the sleep location means someone is up
+func isWakeActivity(event Event) bool {
+	return (event.Type == "motion" && event.State == "on") ||
+		(event.Type == "lighting" && event.State == "on")
+}
+
+// detectSleepPeriods finds sleep periods from time-sorted motion and lighting events.
+//
+// Sleep starts when lights go off in the sleep location at night while that room was
+// the last place with activity (bedroom occupancy), and no activity anywhere follows
+// within inactivity. It ends at the first activity
+// elsewhere, or lights on in the sleep location. Short trips out of the room that end
+// with lights off again within briefAwakeningWindow count as interruptions, as does
+// motion in the room itself. Nights still in progress (no wake seen) are not returned.
+func detectSleepPeriods(events []Event, location string, inactivity, minDuration time.Duration) []SleepPeriod {
+	var periods []SleepPeriod
+	lastActiveLocation := ""
+
+	for i := 0; i < len(events); i++ {
+		event := events[i]
+
+		isOnset := event.Location == location &&
+			event.Type == "lighting" && event.State == "off" &&
+			isSleepOnsetHour(event.Timestamp.In(time.Local).Hour()) &&
+			(lastActiveLocation == "" || lastActiveLocation == location) &&
+			quietAfter(events, i+1, event.Timestamp, inactivity)
+
+		if isWakeActivity(event) {
+			lastActiveLocation = event.Location
+		}
+		if !isOnset {
+			continue
+		}
+
+		wakeAt, interruptions, next := scanForWake(events, i+1, location)
+		if wakeAt.IsZero() {
+			// Still asleep at end of data - analyze again later
+			break
+		}
+
+		duration := wakeAt.Sub(event.Timestamp)
+		if duration >= minDuration {
+			periods = append(periods, SleepPeriod{
+				Location:      location,
+				SleepAt:       event.Timestamp,
+				WakeAt:        wakeAt,
+				Interruptions: interruptions,
+				Confidence:    sleepConfidence(duration, interruptions),
+			})
+		}
+
+		i = next - 1
+		lastActiveLocation = events[next-1].Location
+	}
+
+	return periods
+}
+
+// quietAfter reports whether no wake activity, in the sleep location or elsewhere,
+// follows since within inactivity, starting at events[start]
+func quietAfter(events []Event, start int, since time.Time, inactivity time.Duration) bool {
+	for k := start; k < len(events); k++ {
+		event := events[k]
+		if event.Timestamp.Sub(since) >= inactivity {
+			return true
+		}
+		if isWakeActivity(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// scanForWake walks forward from start until the occupant is up for the day.
+// Returns the wake time (zero if not found), interruptions, and the index of the wake event.
+func scanForWake(events []Event, start int, location string) (time.Time, int, int) {
+	interruptions := 0
+
+	for k := start; k < len(events); k++ {
+		event := events[k]
+
+		if event.Location == location {
+			if event.Type == "motion" && event.State == "on" {
+				interruptions++
+				continue
+			}
+			if event.Type == "lighting" && event.State == "on" {
+				if resume := findLightsOff(events, k+1, location, event.Timestamp); resume > 0 {
+					interruptions++
+					k = resume
+					continue
+				}
+				return event.Timestamp, interruptions, k + 1
+			}
+			continue
+		}
+
+		if !isWakeActivity(event) {
+			continue
+		}
+
+		// Activity elsewhere: brief awakening if lights go off in the sleep location soon after
+		if resume := findLightsOff(events, k+1, location, event.Timestamp); resume > 0 {
+			interruptions++
+			k = resume
+			continue
+		}
+		return event.Timestamp, interruptions, k + 1
+	}
+
+	return time.Time{}, interruptions, len(events)
+}
+
+// findLightsOff returns the index of a lights-off event in location within
+// briefAwakeningWindow of since, or 0 if there is none
+func findLightsOff(events []Event, start int, location string, since time.Time) int {
+	for k := start; k < len(events); k++ {
+		event := events[k]
+		if event.Timestamp.Sub(since) > briefAwakeningWindow {
+			return 0
+		}
+		if event.Location == location && event.Type == "lighting" && event.State == "off" {
+			return k
+		}
+	}
+	return 0
+}
+
+// sleepConfidence scores a sleep period: longer, undisturbed nights are more certain
+func sleepConfidence(duration time.Duration, interruptions int) float64 {
+	confidence := 0.6 + 0.3*min(duration.Hours()/7.0, 1.0) - 0.05*float64(interruptions)
+	return max(0.3, min(confidence, 0.95))
+}
+
+// analyzeSleep detects sleep periods from Redis sensor data and remembers them
+// so episode detection and anchor creation can treat them specially
+func (a *Agent) analyzeSleep(ctx context.Context, since, until time.Time) []SleepPeriod {
+	// Sleep onset depends on where else there was activity, so read every room
+	events := a.readEventsConcurrently(ctx, "sleep_detection", a.topology.Rooms(), []string{"motion", "lighting"}, since, until)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	periods := detectSleepPeriods(events, a.cfg.SleepLocation, a.cfg.SleepInactivityThreshold, a.cfg.SleepMinDuration)
+
+	a.stateMux.Lock()
+	for _, period := range periods {
+		known := false
+		for _, existing := range a.sleepPeriods {
+			if existing.Location == period.Location && existing.SleepAt.Equal(period.SleepAt) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			a.sleepPeriods = append(a.sleepPeriods, period)
+		}
+	}
+	cutoff := a.timeManager.Now().Add(-sleepPeriodRetention)
+	kept := a.sleepPeriods[:0]
+	for _, period := range a.sleepPeriods {
+		if period.WakeAt.After(cutoff) {
+			kept = append(kept, period)
+		}
+	}
+	a.sleepPeriods = kept
+	a.stateMux.Unlock()
+
+	a.logger.Info("Sleep analysis completed",
+		"since", since.Format(time.RFC3339),
+		"until", until.Format(time.RFC3339),
+		"events", len(events),
+		"sleep_periods", len(periods))
+
+	return periods
+}
+
+// sleepPeriodAt returns the known sleep period covering ts in location, if any
+func (a *Agent) sleepPeriodAt(location string, ts time.Time) (SleepPeriod, bool) {
+	a.stateMux.RLock()
+	defer a.stateMux.RUnlock()
+
+	for _, period := range a.sleepPeriods {
+		if period.Contains(location, ts) {
+			return period, true
+		}
+	}
+	return SleepPeriod{}, false
+}
+
+// sleepSignal marks an anchor as created during sleep
+func sleepSignal(period SleepPeriod, timestamp time.Time) types.ActivitySignal {
+	return types.ActivitySignal{
+		Type:       "sleep",
+		Confidence: period.Confidence,
+		Timestamp:  timestamp,
+		Value: map[string]interface{}{
+			"state":    "asleep",
+			"sleep_at": period.SleepAt.Format(time.RFC3339),
+			"wake_at":  period.WakeAt.Format(time.RFC3339),
+		},
+	}
+}
+
+// storeSleepEpisodes persists sleep periods as "Sleeping" macro-episodes, claiming the
+// micro-episodes in the sleep location that overlap each period
+func (a *Agent) storeSleepEpisodes(ctx context.Context, periods []SleepPeriod) int {
+	stored := 0
+
+	for _, period := range periods {
+		var exists bool
+		err := a.pgClient.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM macro_episodes
+				WHERE pattern_type = $1
+				AND $2 = ANY(locations)
+				AND start_time < $4 AND end_time > $3
+			)`,
+			sleepPatternType, period.Location, period.SleepAt, period.WakeAt,
+		).Scan(&exists)
+		if err != nil {
+			a.logger.Error("Failed to check existing sleep episode", "error", err)
+			continue
+		}
+		if exists {
+			a.logger.Debug("Sleep episode already stored",
+				"location", period.Location,
+				"sleep_at", period.SleepAt.Format(time.RFC3339))
+			continue
+		}
+
+		microIDs, err := a.getOverlappingEpisodeIDs(ctx, period.Location, period.SleepAt, period.WakeAt)
+		if err != nil {
+			a.logger.Error("Failed to find episodes for sleep period", "error", err)
+			continue
+		}
+
+		duration := period.WakeAt.Sub(period.SleepAt)
+		macro := &MacroEpisode{
+			ID:              uuid.New(),
+			PatternType:     sleepPatternType,
+			StartTime:       period.SleepAt,
+			EndTime:         period.WakeAt,
+			DurationMinutes: int(duration.Minutes()),
+			Locations:       []string{period.Location},
+			MicroEpisodeIDs: microIDs,
+			Summary: fmt.Sprintf("Slept in %s from %s to %s (%.1fh, %d interruptions)",
+				period.Location,
+				period.SleepAt.Format("15:04"),
+				period.WakeAt.Format("15:04"),
+				duration.Hours(),
+				period.Interruptions),
+			SemanticTags: []string{sleepPatternType, period.Location, "night"},
+			ContextFeatures: map[string]interface{}{
+				"@type":                "saref:Sleeping",
+				"jeeves:sleepAt":       period.SleepAt.Format(time.RFC3339),
+				"jeeves:wakeAt":        period.WakeAt.Format(time.RFC3339),
+				"jeeves:interruptions": period.Interruptions,
+				"confidence":           period.Confidence,
+			},
+			CreatedAt: a.timeManager.Now(),
+		}
+
+		if err := a.createMacroEpisode(ctx, macro); err != nil {
+			a.logger.Error("Failed to store sleep episode", "error", err)
+			continue
+		}
+		stored++
+	}
+
+	return stored
+}
+
+// getOverlappingEpisodeIDs returns unconsolidated episodes in location overlapping [start, end]
+func (a *Agent) getOverlappingEpisodeIDs(ctx context.Context, location string, start, end time.Time) ([]uuid.UUID, error) {
+	rows, err := a.pgClient.Query(ctx, `
+		SELECT id
+		FROM behavioral_episodes
+		WHERE location = $1
+			AND started_at_text::timestamptz < $3
+			AND COALESCE(ended_at_text::timestamptz, $3) > $2
+			AND NOT EXISTS (
+				SELECT 1
+				FROM macro_episodes m
+				WHERE behavioral_episodes.id = ANY(m.micro_episode_ids)
+			)
+		ORDER BY started_at_text ASC`,
+		location, start, end,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// runSleepAnalysisJob analyzes the previous night once a day at SleepAnalysisHour
+func (a *Agent) runSleepAnalysisJob(ctx context.Context) {
+	schedule, err := clock.ParseCron(fmt.Sprintf("0 %d * * *", a.cfg.SleepAnalysisHour))
+	if err != nil {
+		a.logger.Error("Invalid sleep analysis hour, nightly analysis disabled",
+			"hour", a.cfg.SleepAnalysisHour, "error", err)
+		return
+	}
+
+	ticker := clock.NewScheduler(a.timeManager, schedule, a.cfg.ScheduleJitter)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := a.timeManager.Now()
+			periods := a.analyzeSleep(ctx, now.Add(-24*time.Hour), now)
+			stored := a.storeSleepEpisodes(ctx, periods)
+			a.logger.Info("Nightly sleep analysis completed",
+				"detected", len(periods),
+				"stored", stored)
+		}
+	}
+}
+
+// sleptThrough reports whether a gap between two events in location lies inside a sleep period
+func (a *Agent) sleptThrough(location string, from, to time.Time) bool {
+	period, ok := a.sleepPeriodAt(location, from)
+	return ok && period.Contains(location, to)
+}
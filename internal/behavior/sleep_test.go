@@ -0,0 +1,266 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// nightAt returns hh:mm local time on the evening of 2025-10-17 (hours >= 12) or
+// the following morning (hours < 12)
+func nightAt(hour, minute int) time.Time {
+	day := 17
+	if hour < 12 {
+		day = 18
+	}
+	return time.Date(2025, 10, day, hour, minute, 0, 0, time.Local)
+}
+
+func sleepEvent(location, eventType, state string, ts time.Time) Event {
+	return Event{Location: location, Type: eventType, State: state, Timestamp: ts}
+}
+
+func TestDetectSleepPeriods(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+		want   []SleepPeriod // Confidence is not compared
+	}{
+		{
+			name: "uninterrupted night",
+			events: []Event{
+				sleepEvent("bedroom", "motion", "on", nightAt(22, 30)),
+				sleepEvent("bedroom", "lighting", "off", nightAt(23, 0)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+			want: []SleepPeriod{{Location: "bedroom", SleepAt: nightAt(23, 0), WakeAt: nightAt(7, 0)}},
+		},
+		{
+			name: "bathroom trip is an interruption",
+			events: []Event{
+				sleepEvent("bedroom", "lighting", "off", nightAt(23, 0)),
+				sleepEvent("bathroom", "motion", "on", nightAt(3, 0)),
+				sleepEvent("bedroom", "lighting", "off", nightAt(3, 10)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+			want: []SleepPeriod{{Location: "bedroom", SleepAt: nightAt(23, 0), WakeAt: nightAt(7, 0), Interruptions: 1}},
+		},
+		{
+			name: "shorter than minimum duration",
+			events: []Event{
+				sleepEvent("bedroom", "lighting", "off", nightAt(23, 0)),
+				sleepEvent("kitchen", "motion", "on", nightAt(1, 0)),
+			},
+		},
+		{
+			name: "activity within inactivity threshold after lights-off",
+			events: []Event{
+				sleepEvent("bedroom", "lighting", "off", nightAt(23, 0)),
+				sleepEvent("bedroom", "motion", "on", nightAt(23, 10)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+		},
+		{
+			name: "sleep starts at the lights-off that is followed by quiet",
+			events: []Event{
+				sleepEvent("bedroom", "lighting", "off", nightAt(22, 0)),
+				sleepEvent("bedroom", "lighting", "on", nightAt(22, 10)),
+				sleepEvent("bedroom", "lighting", "off", nightAt(22, 40)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+			want: []SleepPeriod{{Location: "bedroom", SleepAt: nightAt(22, 40), WakeAt: nightAt(7, 0)}},
+		},
+		{
+			name: "lights-off during the day",
+			events: []Event{
+				sleepEvent("bedroom", "lighting", "off", time.Date(2025, 10, 17, 14, 0, 0, 0, time.Local)),
+				sleepEvent("kitchen", "motion", "on", nightAt(19, 0)),
+			},
+		},
+		{
+			name: "last activity in another room",
+			events: []Event{
+				sleepEvent("living_room", "motion", "on", nightAt(22, 55)),
+				sleepEvent("bedroom", "lighting", "off", nightAt(23, 0)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+		},
+		{
+			name: "still asleep at end of data",
+			events: []Event{
+				sleepEvent("bedroom", "lighting", "off", nightAt(23, 0)),
+				sleepEvent("bedroom", "motion", "on", nightAt(2, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectSleepPeriods(tt.events, "bedroom", 30*time.Minute, 3*time.Hour)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d sleep periods, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i, want := range tt.want {
+				p := got[i]
+				if p.Location != want.Location || !p.SleepAt.Equal(want.SleepAt) ||
+					!p.WakeAt.Equal(want.WakeAt) || p.Interruptions != want.Interruptions {
+					t.Errorf("Expected %+v, got %+v", want, p)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectSleepPeriods_OnsetHourInLocalTime(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	// 18:30 UTC is 21:30 local, inside the onset hours only in local time
+	lightsOff := time.Date(2025, 10, 17, 18, 30, 0, 0, time.UTC)
+	events := []Event{
+		sleepEvent("bedroom", "lighting", "off", lightsOff),
+		sleepEvent("kitchen", "motion", "on", time.Date(2025, 10, 18, 4, 0, 0, 0, time.UTC)),
+	}
+
+	got := detectSleepPeriods(events, "bedroom", 30*time.Minute, 3*time.Hour)
+	if len(got) != 1 || !got[0].SleepAt.Equal(lightsOff) {
+		t.Errorf("Expected one sleep period from %s, got %+v", lightsOff, got)
+	}
+}
+
+func TestScanForWake(t *testing.T) {
+	tests := []struct {
+		name              string
+		events            []Event
+		wantWake          time.Time
+		wantInterruptions int
+		wantNext          int
+	}{
+		{
+			name:     "activity elsewhere",
+			events:   []Event{sleepEvent("kitchen", "motion", "on", nightAt(7, 0))},
+			wantWake: nightAt(7, 0),
+			wantNext: 1,
+		},
+		{
+			name: "motion in the room is an interruption",
+			events: []Event{
+				sleepEvent("bedroom", "motion", "on", nightAt(2, 0)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+			wantWake:          nightAt(7, 0),
+			wantInterruptions: 1,
+			wantNext:          2,
+		},
+		{
+			name: "lights on and off again",
+			events: []Event{
+				sleepEvent("bedroom", "lighting", "on", nightAt(3, 0)),
+				sleepEvent("bedroom", "lighting", "off", nightAt(3, 10)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+			wantWake:          nightAt(7, 0),
+			wantInterruptions: 1,
+			wantNext:          3,
+		},
+		{
+			name: "lights stay on",
+			events: []Event{
+				sleepEvent("bedroom", "lighting", "on", nightAt(6, 30)),
+				sleepEvent("bedroom", "motion", "on", nightAt(6, 31)),
+			},
+			wantWake: nightAt(6, 30),
+			wantNext: 1,
+		},
+		{
+			name: "brief trip out",
+			events: []Event{
+				sleepEvent("hallway", "motion", "on", nightAt(3, 0)),
+				sleepEvent("bedroom", "lighting", "off", nightAt(3, 20)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+			wantWake:          nightAt(7, 0),
+			wantInterruptions: 1,
+			wantNext:          3,
+		},
+		{
+			name: "trip out longer than the awakening window",
+			events: []Event{
+				sleepEvent("hallway", "motion", "on", nightAt(3, 0)),
+				sleepEvent("bedroom", "lighting", "off", nightAt(3, 45)),
+			},
+			wantWake: nightAt(3, 0),
+			wantNext: 1,
+		},
+		{
+			name: "lights off elsewhere are ignored",
+			events: []Event{
+				sleepEvent("kitchen", "lighting", "off", nightAt(1, 0)),
+				sleepEvent("kitchen", "motion", "on", nightAt(7, 0)),
+			},
+			wantWake: nightAt(7, 0),
+			wantNext: 2,
+		},
+		{
+			name:              "no wake",
+			events:            []Event{sleepEvent("bedroom", "motion", "on", nightAt(2, 0))},
+			wantInterruptions: 1,
+			wantNext:          1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wake, interruptions, next := scanForWake(tt.events, 0, "bedroom")
+
+			if !wake.Equal(tt.wantWake) {
+				t.Errorf("Expected wake at %s, got %s", tt.wantWake, wake)
+			}
+			if interruptions != tt.wantInterruptions {
+				t.Errorf("Expected %d interruptions, got %d", tt.wantInterruptions, interruptions)
+			}
+			if next != tt.wantNext {
+				t.Errorf("Expected next index %d, got %d", tt.wantNext, next)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSleep_ReadsTopologyRooms(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	client := newFakeTimerRedis()
+	agent := &Agent{
+		cfg:         config.NewConfig(),
+		redis:       client,
+		logger:      logger,
+		timeManager: clock.NewTimeManager(logger),
+		topology:    ontology.NewTopology([]ontology.Room{{ID: "bedroom"}, {ID: "office"}}),
+	}
+	agent.timeManager.SetVirtualTime(nightAt(10, 0))
+
+	store := func(location, sensorType, state string, ts time.Time) {
+		member, _ := json.Marshal(map[string]string{"timestamp": ts.Format(time.RFC3339), "state": state})
+		client.ZAdd(context.Background(), redis.GenericSensorKey(sensorType, location), float64(ts.UnixMilli()), string(member))
+	}
+	store("bedroom", "lighting", "off", nightAt(23, 0))
+	// The office is only in the configured topology
+	store("office", "motion", "on", nightAt(7, 0))
+
+	periods := agent.analyzeSleep(context.Background(), nightAt(20, 0), nightAt(10, 0))
+	if len(periods) != 1 || !periods[0].SleepAt.Equal(nightAt(23, 0)) || !periods[0].WakeAt.Equal(nightAt(7, 0)) {
+		t.Fatalf("Expected one night from 23:00 to 07:00, got %+v", periods)
+	}
+	if _, ok := agent.sleepPeriodAt("bedroom", nightAt(3, 0)); !ok {
+		t.Error("Expected the detected period to be remembered")
+	}
+}
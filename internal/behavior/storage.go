@@ -8,6 +8,9 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
+
+	"database/sql"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
 )
 
 // MicroEpisode represents a micro-episode from database
@@ -53,6 +56,13 @@ func (a *Agent) getUnconsolidatedEpisodes(ctx context.Context, sinceTime time.Ti
             FROM macro_episodes m
             WHERE behavioral_episodes.id = ANY(m.micro_episode_ids)
         )
+        AND NOT EXISTS (
+            SELECT 1
+            FROM episode_tombstones t
+            WHERE t.episode_kind = 'macro'
+                AND t.reason = 'deleted'
+                AND behavioral_episodes.id = ANY(t.micro_episode_ids)
+        )
 `
 
 	args := []interface{}{sinceTime}
@@ -107,23 +117,81 @@ func (a *Agent) getUnconsolidatedEpisodes(ctx context.Context, sinceTime time.Ti
 	return episodes, nil
 }
 
+// tombstoneTolerance absorbs small boundary shifts when a removed episode is
+// detected again from the same sensor data
+const tombstoneTolerance = time.Minute
+
+// isTombstoned reports whether an episode in location over [start, end] falls
+// inside a micro-episode the user deleted or merged away
+func (a *Agent) isTombstoned(ctx context.Context, location string, start, end time.Time) bool {
+	var exists bool
+	err := a.pgClient.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM episode_tombstones
+			WHERE episode_kind = 'micro'
+			  AND location = $1
+			  AND started_at <= $2
+			  AND ended_at >= $3
+		)`,
+		location, start.Add(tombstoneTolerance), end.Add(-tombstoneTolerance),
+	).Scan(&exists)
+	if err != nil {
+		a.logger.Warn("Failed to check episode tombstones", "location", location, "error", err)
+		return false
+	}
+	return exists
+}
+
 // createMacroEpisode stores a macro-episode in the database
 func (a *Agent) createMacroEpisode(ctx context.Context, macro *MacroEpisode) error {
-	query := `
-		INSERT INTO macro_episodes (
-			id, pattern_type, start_time, end_time, duration_minutes,
-			locations, micro_episode_ids, summary, semantic_tags,
-			context_features, created_at
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-	`
+	args, err := macroEpisodeArgs(macro)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.pgClient.Exec(ctx, insertMacroEpisodeQuery, args...); err != nil {
+		return fmt.Errorf("failed to insert macro-episode: %w", err)
+	}
 
+	a.logger.Info("Macro-episode created",
+		"id", macro.ID,
+		"pattern", macro.PatternType,
+		"duration", macro.DurationMinutes,
+		"micro_episodes", len(macro.MicroEpisodeIDs))
+
+	return nil
+}
+
+// insertMacroEpisode stores a macro-episode as part of tx
+func insertMacroEpisode(ctx context.Context, tx *sql.Tx, macro *MacroEpisode) error {
+	args, err := macroEpisodeArgs(macro)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, insertMacroEpisodeQuery, args...); err != nil {
+		return fmt.Errorf("failed to insert macro-episode: %w", err)
+	}
+	return nil
+}
+
+const insertMacroEpisodeQuery = `
+	INSERT INTO macro_episodes (
+		id, pattern_type, start_time, end_time, duration_minutes,
+		locations, micro_episode_ids, summary, semantic_tags,
+		context_features, created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+`
+
+// macroEpisodeArgs returns the arguments of insertMacroEpisodeQuery
+func macroEpisodeArgs(macro *MacroEpisode) ([]interface{}, error) {
 	contextFeaturesJSON, err := json.Marshal(macro.ContextFeatures)
 	if err != nil {
-		return fmt.Errorf("failed to marshal context features: %w", err)
+		return nil, fmt.Errorf("failed to marshal context features: %w", err)
 	}
 
-	_, err = a.pgClient.Exec(ctx, query,
+	return []interface{}{
 		macro.ID,
 		macro.PatternType,
 		macro.StartTime,
@@ -135,19 +203,7 @@ func (a *Agent) createMacroEpisode(ctx context.Context, macro *MacroEpisode) err
 		pq.Array(macro.SemanticTags),
 		contextFeaturesJSON,
 		macro.CreatedAt,
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to insert macro-episode: %w", err)
-	}
-
-	a.logger.Info("Macro-episode created",
-		"id", macro.ID,
-		"pattern", macro.PatternType,
-		"duration", macro.DurationMinutes,
-		"micro_episodes", len(macro.MicroEpisodeIDs))
-
-	return nil
+	}, nil
 }
 
 // Add these functions to storage.go
@@ -363,3 +419,25 @@ func (a *Agent) getVectorsByPattern(ctx context.Context, startLocation, secondLo
 
 	return vectors, nil
 }
+
+// upgradeEpisodeDocuments migrates stored episode documents to the current ontology version
+func (a *Agent) upgradeEpisodeDocuments(ctx context.Context) {
+	db, err := a.getDBConnection()
+	if err != nil {
+		a.logger.Warn("Skipping episode document upgrade", "error", err)
+		return
+	}
+
+	result, err := a.createAnchorStorage(db).UpgradeEpisodeDocuments(ctx, 500)
+	if err != nil {
+		a.logger.Error("Episode document upgrade failed", "error", err)
+		return
+	}
+	if result.Upgraded > 0 || result.Failed > 0 {
+		a.logger.Info("Episode documents upgraded",
+			"ontology_version", ontology.ContextVersion,
+			"upgraded", result.Upgraded,
+			"invalid", result.Invalid,
+			"failed", result.Failed)
+	}
+}
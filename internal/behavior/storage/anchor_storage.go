@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,22 +14,83 @@ import (
 	"github.com/pgvector/pgvector-go"
 
 	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/encryption"
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
 )
 
 // AnchorStorage provides persistent storage for semantic anchors using PostgreSQL + pgvector.
 type AnchorStorage struct {
 	db *sql.DB
+
+	// Approximate nearest neighbor search tuning (0 = server default)
+	annProbes   int // ivfflat.probes
+	annEfSearch int // hnsw.ef_search
+
+	// Adjacent location pairs ("a|b") used to pre-filter distance candidates.
+	// Refreshed at runtime when adjacency is learned from transitions.
+	adjacentMu    sync.RWMutex
+	adjacentPairs []string
+
+	// Order of pairs needing distances
+	priority PairPriority
+
+	// Encrypts anchor context and episode documents (nil = stored in clear)
+	sealer *encryption.Sealer
+}
+
+// SimilarAnchorFilter restricts a similarity search to a location and/or time
+// window. Zero values leave that dimension unrestricted.
+type SimilarAnchorFilter struct {
+	Location string
+	Since    time.Time
+	Until    time.Time
 }
 
 // NewAnchorStorage creates a new anchor storage instance.
 func NewAnchorStorage(db *sql.DB) *AnchorStorage {
-	return &AnchorStorage{db: db}
+	return &AnchorStorage{
+		db:            db,
+		adjacentPairs: ontology.DefaultTopology().AdjacentPairs(),
+	}
+}
+
+// SetTopology sets the home topology whose adjacent rooms are paired for distance computation
+func (s *AnchorStorage) SetTopology(topology *ontology.Topology) {
+	pairs := topology.AdjacentPairs()
+
+	s.adjacentMu.Lock()
+	s.adjacentPairs = pairs
+	s.adjacentMu.Unlock()
+}
+
+// SetSealer enables encryption of anchor context and episode documents
+func (s *AnchorStorage) SetSealer(sealer *encryption.Sealer) {
+	s.sealer = sealer
+}
+
+// SetPairPriority sets how pairs needing distances are ordered
+func (s *AnchorStorage) SetPairPriority(priority PairPriority) {
+	s.priority = priority
+}
+
+// SetSearchParams tunes approximate nearest neighbor queries. probes applies to
+// IVFFlat indexes and efSearch to HNSW indexes; higher values trade speed for recall.
+func (s *AnchorStorage) SetSearchParams(probes, efSearch int) {
+	s.annProbes = probes
+	s.annEfSearch = efSearch
 }
 
 // CreateAnchor stores a new semantic anchor in the database.
 func (s *AnchorStorage) CreateAnchor(ctx context.Context, anchor *types.SemanticAnchor) error {
+	// Generate UUID if not provided; sealed context is bound to it
+	if anchor.ID == uuid.Nil {
+		anchor.ID = uuid.New()
+	}
+
 	// Marshal context and signals to JSONB
-	contextJSON, err := json.Marshal(anchor.Context)
+	contextJSON, err := s.marshalContext(anchor.ID, anchor.Context)
 	if err != nil {
 		return fmt.Errorf("failed to marshal context: %w", err)
 	}
@@ -37,11 +100,6 @@ func (s *AnchorStorage) CreateAnchor(ctx context.Context, anchor *types.Semantic
 		return fmt.Errorf("failed to marshal signals: %w", err)
 	}
 
-	// Generate UUID if not provided
-	if anchor.ID == uuid.Nil {
-		anchor.ID = uuid.New()
-	}
-
 	// Set created_at if not provided
 	if anchor.CreatedAt.IsZero() {
 		anchor.CreatedAt = time.Now()
@@ -51,8 +109,9 @@ func (s *AnchorStorage) CreateAnchor(ctx context.Context, anchor *types.Semantic
 		INSERT INTO semantic_anchors (
 			id, timestamp, location, semantic_embedding, context, signals,
 			duration_minutes, duration_source, duration_confidence,
-			preceding_anchor_id, following_anchor_id, pattern_id, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			preceding_anchor_id, following_anchor_id, pattern_id, created_at,
+			occupant, embedding_version
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err = s.db.ExecContext(ctx, query,
@@ -69,22 +128,115 @@ func (s *AnchorStorage) CreateAnchor(ctx context.Context, anchor *types.Semantic
 		anchor.FollowingAnchorID,
 		anchor.PatternID,
 		anchor.CreatedAt,
+		anchor.Occupant,
+		anchor.EmbeddingVersion,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to insert anchor: %w", err)
+		return fmt.Errorf("failed to insert anchor: %w", postgres.Classify(err))
 	}
 
 	return nil
 }
 
+// anchorInsertBatchSize and distanceInsertBatchSize bound the rows per multi-row
+// INSERT, keeping statements well under PostgreSQL's 65535 parameter limit
+const (
+	anchorInsertBatchSize   = 500
+	distanceInsertBatchSize = 2000
+)
+
+// CreateAnchors stores many anchors in one transaction using multi-row INSERTs.
+// Anchors may reference earlier anchors in the same slice.
+func (s *AnchorStorage) CreateAnchors(ctx context.Context, anchors []*types.SemanticAnchor) error {
+	if len(anchors) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", postgres.Classify(err))
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for start := 0; start < len(anchors); start += anchorInsertBatchSize {
+		end := min(start+anchorInsertBatchSize, len(anchors))
+
+		var values []string
+		args := make([]interface{}, 0, (end-start)*15)
+		for _, anchor := range anchors[start:end] {
+			if anchor.ID == uuid.Nil {
+				anchor.ID = uuid.New()
+			}
+			contextJSON, err := s.marshalContext(anchor.ID, anchor.Context)
+			if err != nil {
+				return fmt.Errorf("failed to marshal context: %w", err)
+			}
+			signalsJSON, err := json.Marshal(anchor.Signals)
+			if err != nil {
+				return fmt.Errorf("failed to marshal signals: %w", err)
+			}
+			if anchor.CreatedAt.IsZero() {
+				anchor.CreatedAt = now
+			}
+
+			values = append(values, placeholders(len(args), 15))
+			args = append(args,
+				anchor.ID,
+				anchor.Timestamp,
+				anchor.Location,
+				anchor.SemanticEmbedding,
+				contextJSON,
+				signalsJSON,
+				anchor.DurationMinutes,
+				anchor.DurationSource,
+				anchor.DurationConfidence,
+				anchor.PrecedingAnchorID,
+				anchor.FollowingAnchorID,
+				anchor.PatternID,
+				anchor.CreatedAt,
+				anchor.Occupant,
+				anchor.EmbeddingVersion,
+			)
+		}
+
+		query := `
+			INSERT INTO semantic_anchors (
+				id, timestamp, location, semantic_embedding, context, signals,
+				duration_minutes, duration_source, duration_confidence,
+				preceding_anchor_id, following_anchor_id, pattern_id, created_at,
+				occupant, embedding_version
+			) VALUES ` + strings.Join(values, ", ")
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to insert anchors: %w", postgres.Classify(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit anchors: %w", postgres.Classify(err))
+	}
+	return nil
+}
+
+// placeholders renders "($n+1, ..., $n+count)" for a multi-row VALUES list
+func placeholders(offset, count int) string {
+	params := make([]string, count)
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", offset+i+1)
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
+
 // GetAnchor retrieves a semantic anchor by ID.
 func (s *AnchorStorage) GetAnchor(ctx context.Context, id uuid.UUID) (*types.SemanticAnchor, error) {
 	query := `
 		SELECT
 			id, timestamp, location, semantic_embedding, context, signals,
 			duration_minutes, duration_source, duration_confidence,
-			preceding_anchor_id, following_anchor_id, pattern_id, created_at
+			preceding_anchor_id, following_anchor_id, pattern_id, created_at,
+			occupant, embedding_version
 		FROM semantic_anchors
 		WHERE id = $1
 	`
@@ -106,17 +258,19 @@ func (s *AnchorStorage) GetAnchor(ctx context.Context, id uuid.UUID) (*types.Sem
 		&anchor.FollowingAnchorID,
 		&anchor.PatternID,
 		&anchor.CreatedAt,
+		&anchor.Occupant,
+		&anchor.EmbeddingVersion,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("anchor not found: %s", id)
+		return nil, errcode.Wrap(postgres.ErrNotFound, fmt.Errorf("anchor not found: %s", id))
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query anchor: %w", err)
+		return nil, fmt.Errorf("failed to query anchor: %w", postgres.Classify(err))
 	}
 
 	// Unmarshal JSONB fields
-	if err := json.Unmarshal(contextJSON, &anchor.Context); err != nil {
+	if err := s.unmarshalContext(anchor.ID, contextJSON, &anchor.Context); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal context: %w", err)
 	}
 
@@ -130,20 +284,67 @@ func (s *AnchorStorage) GetAnchor(ctx context.Context, id uuid.UUID) (*types.Sem
 // FindSimilarAnchors finds anchors similar to the given embedding using vector similarity search.
 // Returns up to limit anchors ordered by similarity (most similar first).
 func (s *AnchorStorage) FindSimilarAnchors(ctx context.Context, embedding pgvector.Vector, limit int) ([]*types.SemanticAnchor, error) {
+	return s.FindSimilarAnchorsFiltered(ctx, embedding, SimilarAnchorFilter{}, limit)
+}
+
+// FindSimilarAnchorsFiltered finds anchors similar to the given embedding within the
+// filter's location and time window. The search uses the ANN index on
+// semantic_embedding with the probes/ef_search configured via SetSearchParams.
+func (s *AnchorStorage) FindSimilarAnchorsFiltered(ctx context.Context, embedding pgvector.Vector, filter SimilarAnchorFilter, limit int) ([]*types.SemanticAnchor, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", postgres.Classify(err))
+	}
+	defer tx.Rollback()
+
+	// SET LOCAL scopes the tuning to this transaction (parameters can't be bound here)
+	if s.annProbes > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL ivfflat.probes = %d", s.annProbes)); err != nil {
+			return nil, fmt.Errorf("failed to set ivfflat.probes: %w", postgres.Classify(err))
+		}
+	}
+	if s.annEfSearch > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", s.annEfSearch)); err != nil {
+			return nil, fmt.Errorf("failed to set hnsw.ef_search: %w", postgres.Classify(err))
+		}
+	}
+
+	args := []interface{}{embedding, limit}
+	where := ""
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		if where == "" {
+			where = "WHERE "
+		} else {
+			where += " AND "
+		}
+		where += fmt.Sprintf(condition, len(args))
+	}
+	if filter.Location != "" {
+		addCondition("location = $%d", filter.Location)
+	}
+	if !filter.Since.IsZero() {
+		addCondition("timestamp >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		addCondition("timestamp < $%d", filter.Until)
+	}
+
 	query := `
 		SELECT
 			id, timestamp, location, semantic_embedding, context, signals,
 			duration_minutes, duration_source, duration_confidence,
 			preceding_anchor_id, following_anchor_id, pattern_id, created_at,
-			semantic_embedding <=> $1 AS distance
+			occupant, embedding_version, semantic_embedding <=> $1 AS distance
 		FROM semantic_anchors
+		` + where + `
 		ORDER BY semantic_embedding <=> $1
 		LIMIT $2
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, embedding, limit)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query similar anchors: %w", err)
+		return nil, fmt.Errorf("failed to query similar anchors: %w", postgres.Classify(err))
 	}
 	defer rows.Close()
 
@@ -168,15 +369,17 @@ func (s *AnchorStorage) FindSimilarAnchors(ctx context.Context, embedding pgvect
 			&anchor.FollowingAnchorID,
 			&anchor.PatternID,
 			&anchor.CreatedAt,
+			&anchor.Occupant,
+			&anchor.EmbeddingVersion,
 			&distance,
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan anchor row: %w", err)
+			return nil, fmt.Errorf("failed to scan anchor row: %w", postgres.Classify(err))
 		}
 
 		// Unmarshal JSONB fields
-		if err := json.Unmarshal(contextJSON, &anchor.Context); err != nil {
+		if err := s.unmarshalContext(anchor.ID, contextJSON, &anchor.Context); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal context: %w", err)
 		}
 
@@ -188,7 +391,7 @@ func (s *AnchorStorage) FindSimilarAnchors(ctx context.Context, embedding pgvect
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating anchor rows: %w", err)
+		return nil, fmt.Errorf("error iterating anchor rows: %w", postgres.Classify(err))
 	}
 
 	return anchors, nil
@@ -209,9 +412,58 @@ func (s *AnchorStorage) GetAnchorsNeedingDistancesInWindow(
 	limit int,
 	windowStart, windowEnd time.Time,
 ) ([][2]uuid.UUID, error) {
-	// Build query with optional time window filtering
+	candidates, args := s.distanceCandidates(windowStart, windowEnd)
+
+	// Most valuable pairs first (see PairPriority)
+	orderBy, orderArgs := s.priority.orderBy(len(args) + 1)
+
+	query := `
+		SELECT a1.id, a2.id` + candidates + `
+		` + orderBy + `
+		LIMIT $` + fmt.Sprintf("%d", len(args)+len(orderArgs)+1)
+
+	args = append(args, orderArgs...)
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anchor pairs: %w", postgres.Classify(err))
+	}
+	defer rows.Close()
+
+	var pairs [][2]uuid.UUID
+
+	for rows.Next() {
+		var id1, id2 uuid.UUID
+		if err := rows.Scan(&id1, &id2); err != nil {
+			return nil, fmt.Errorf("failed to scan anchor pair: %w", postgres.Classify(err))
+		}
+		pairs = append(pairs, [2]uuid.UUID{id1, id2})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating anchor pairs: %w", postgres.Classify(err))
+	}
+
+	return pairs, nil
+}
+
+// CountAnchorsNeedingDistances counts the pairs GetAnchorsNeedingDistances
+// would eventually return, i.e. the distance backlog.
+func (s *AnchorStorage) CountAnchorsNeedingDistances(ctx context.Context) (int, error) {
+	candidates, args := s.distanceCandidates(time.Time{}, time.Time{})
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*)`+candidates, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count anchor pairs: %w", postgres.Classify(err))
+	}
+	return count, nil
+}
+
+// distanceCandidates returns the FROM/WHERE clause selecting anchor pairs a1/a2
+// without a distance, with its arguments numbered from $1
+func (s *AnchorStorage) distanceCandidates(windowStart, windowEnd time.Time) (string, []interface{}) {
 	queryBase := `
-		SELECT a1.id, a2.id
 		FROM semantic_anchors a1
 		CROSS JOIN semantic_anchors a2
 		WHERE a1.id < a2.id
@@ -237,14 +489,17 @@ func (s *AnchorStorage) GetAnchorsNeedingDistancesInWindow(
 		argIndex += 4
 	}
 
-	query := queryBase + timeFilter + `
+	s.adjacentMu.RLock()
+	adjacentPairs := s.adjacentPairs
+	s.adjacentMu.RUnlock()
+	args = append(args, pq.Array(adjacentPairs))
+
+	return queryBase + timeFilter + `
 		  -- FILTER 1: Same or adjacent locations (reduces pairs by ~80%)
 		  AND (
 			a1.location = a2.location
-			OR (a1.location IN ('bedroom', 'bathroom') AND a2.location IN ('bedroom', 'bathroom'))
-			OR (a1.location IN ('kitchen', 'dining_room') AND a2.location IN ('kitchen', 'dining_room'))
-			OR (a1.location IN ('living_room', 'dining_room') AND a2.location IN ('living_room', 'dining_room'))
-			OR (a1.location IN ('living_room', 'study') AND a2.location IN ('living_room', 'study'))
+			OR (a1.location || '|' || a2.location) = ANY($` + fmt.Sprintf("%d", argIndex) + `)
+			OR (a2.location || '|' || a1.location) = ANY($` + fmt.Sprintf("%d", argIndex) + `)
 		  )
 		  -- FILTER 2: Within 2-hour time window (tighter temporal proximity)
 		  AND ABS(EXTRACT(EPOCH FROM (a1.timestamp - a2.timestamp))) < 7200
@@ -257,33 +512,7 @@ func (s *AnchorStorage) GetAnchorsNeedingDistancesInWindow(
 			OR ((a1.context->>'time_of_day') = 'afternoon' AND (a2.context->>'time_of_day') = 'morning')
 			OR ((a1.context->>'time_of_day') = 'afternoon' AND (a2.context->>'time_of_day') = 'evening')
 			OR ((a1.context->>'time_of_day') = 'evening' AND (a2.context->>'time_of_day') = 'afternoon')
-		  )
-		ORDER BY a1.created_at DESC, a2.created_at DESC
-		LIMIT $` + fmt.Sprintf("%d", argIndex)
-
-	args = append(args, limit)
-
-	rows, err := s.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query anchor pairs: %w", err)
-	}
-	defer rows.Close()
-
-	var pairs [][2]uuid.UUID
-
-	for rows.Next() {
-		var id1, id2 uuid.UUID
-		if err := rows.Scan(&id1, &id2); err != nil {
-			return nil, fmt.Errorf("failed to scan anchor pair: %w", err)
-		}
-		pairs = append(pairs, [2]uuid.UUID{id1, id2})
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating anchor pairs: %w", err)
-	}
-
-	return pairs, nil
+		  )`, args
 }
 
 // StoreDistance stores a pre-computed distance between two anchors.
@@ -319,9 +548,81 @@ func (s *AnchorStorage) StoreDistance(ctx context.Context, distance *types.Ancho
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to store distance: %w", err)
+		return fmt.Errorf("failed to store distance: %w", postgres.Classify(err))
+	}
+
+	return nil
+}
+
+// StoreDistances upserts many distances in one transaction using multi-row
+// INSERTs. When the same pair appears more than once, the last entry wins.
+func (s *AnchorStorage) StoreDistances(ctx context.Context, distances []*types.AnchorDistance) error {
+	if len(distances) == 0 {
+		return nil
+	}
+
+	// ON CONFLICT cannot touch the same row twice in one statement, so dedupe pairs first
+	now := time.Now()
+	index := make(map[[2]uuid.UUID]int, len(distances))
+	unique := make([]*types.AnchorDistance, 0, len(distances))
+	for _, distance := range distances {
+		anchor1, anchor2 := distance.Anchor1ID, distance.Anchor2ID
+		if anchor1.String() > anchor2.String() {
+			anchor1, anchor2 = anchor2, anchor1
+		}
+		if distance.ComputedAt.IsZero() {
+			distance.ComputedAt = now
+		}
+
+		record := &types.AnchorDistance{
+			Anchor1ID:  anchor1,
+			Anchor2ID:  anchor2,
+			Distance:   distance.Distance,
+			Source:     distance.Source,
+			ComputedAt: distance.ComputedAt,
+		}
+		key := [2]uuid.UUID{anchor1, anchor2}
+		if i, ok := index[key]; ok {
+			unique[i] = record
+			continue
+		}
+		index[key] = len(unique)
+		unique = append(unique, record)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", postgres.Classify(err))
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(unique); start += distanceInsertBatchSize {
+		end := min(start+distanceInsertBatchSize, len(unique))
+
+		var values []string
+		args := make([]interface{}, 0, (end-start)*5)
+		for _, d := range unique[start:end] {
+			values = append(values, placeholders(len(args), 5))
+			args = append(args, d.Anchor1ID, d.Anchor2ID, d.Distance, d.Source, d.ComputedAt)
+		}
+
+		query := `
+			INSERT INTO anchor_distances (anchor1_id, anchor2_id, distance, source, computed_at)
+			VALUES ` + strings.Join(values, ", ") + `
+			ON CONFLICT (anchor1_id, anchor2_id)
+			DO UPDATE SET
+				distance = EXCLUDED.distance,
+				source = EXCLUDED.source,
+				computed_at = EXCLUDED.computed_at`
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to store distances: %w", postgres.Classify(err))
+		}
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit distances: %w", postgres.Classify(err))
+	}
 	return nil
 }
 
@@ -354,7 +655,7 @@ func (s *AnchorStorage) GetDistance(ctx context.Context, anchor1ID, anchor2ID uu
 		return nil, nil // No distance computed yet
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query distance: %w", err)
+		return nil, fmt.Errorf("failed to query distance: %w", postgres.Classify(err))
 	}
 
 	return &distance, nil
@@ -395,7 +696,7 @@ func (s *AnchorStorage) CreateInterpretation(ctx context.Context, interpretation
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to insert interpretation: %w", err)
+		return fmt.Errorf("failed to insert interpretation: %w", postgres.Classify(err))
 	}
 
 	return nil
@@ -412,7 +713,7 @@ func (s *AnchorStorage) GetInterpretations(ctx context.Context, anchorID uuid.UU
 
 	rows, err := s.db.QueryContext(ctx, query, anchorID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query interpretations: %w", err)
+		return nil, fmt.Errorf("failed to query interpretations: %w", postgres.Classify(err))
 	}
 	defer rows.Close()
 
@@ -433,7 +734,7 @@ func (s *AnchorStorage) GetInterpretations(ctx context.Context, anchorID uuid.UU
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan interpretation row: %w", err)
+			return nil, fmt.Errorf("failed to scan interpretation row: %w", postgres.Classify(err))
 		}
 
 		// Unmarshal evidence array
@@ -445,7 +746,7 @@ func (s *AnchorStorage) GetInterpretations(ctx context.Context, anchorID uuid.UU
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating interpretation rows: %w", err)
+		return nil, fmt.Errorf("error iterating interpretation rows: %w", postgres.Classify(err))
 	}
 
 	return interpretations, nil
@@ -477,6 +778,9 @@ func (s *AnchorStorage) CreatePattern(ctx context.Context, pattern *types.Behavi
 	if pattern.Weight == 0.0 {
 		pattern.Weight = 0.1
 	}
+	if pattern.Source == "" {
+		pattern.Source = types.PatternSourceDiscovered
+	}
 
 	// Marshal context and dominant_context to JSONB
 	// PostgreSQL JSONB columns should always have valid JSON, use {} for nil/empty
@@ -502,6 +806,14 @@ func (s *AnchorStorage) CreatePattern(ctx context.Context, pattern *types.Behavi
 		dominantContextJSON = []byte("{}")
 	}
 
+	var interpretationJSON []byte
+	if pattern.Interpretation != nil {
+		interpretationJSON, err = json.Marshal(pattern.Interpretation)
+		if err != nil {
+			return fmt.Errorf("failed to marshal interpretation: %w", err)
+		}
+	}
+
 	// Use pq.Array for TEXT[] fields
 	locations := pattern.Locations
 	if locations == nil {
@@ -513,8 +825,9 @@ func (s *AnchorStorage) CreatePattern(ctx context.Context, pattern *types.Behavi
 			id, name, description, pattern_type, weight, cluster_size, locations,
 			observations, times_observed, predictions, acceptances, rejections,
 			first_seen, last_seen, last_useful, typical_duration_minutes,
-			context, dominant_context, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+			context, dominant_context, source, window_start, window_end, interpretation,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`
 
 	_, err = s.db.ExecContext(ctx, query,
@@ -536,12 +849,16 @@ func (s *AnchorStorage) CreatePattern(ctx context.Context, pattern *types.Behavi
 		pattern.TypicalDurationMinutes,
 		contextJSON,
 		dominantContextJSON,
+		pattern.Source,
+		nullTimeOfDay(pattern.WindowStart),
+		nullTimeOfDay(pattern.WindowEnd),
+		interpretationJSON,
 		pattern.CreatedAt,
 		pattern.UpdatedAt,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to insert pattern: %w", err)
+		return fmt.Errorf("failed to insert pattern: %w", postgres.Classify(err))
 	}
 
 	return nil
@@ -554,7 +871,8 @@ func (s *AnchorStorage) GetPattern(ctx context.Context, id uuid.UUID) (*types.Be
 			id, name, description, pattern_type, weight, cluster_size, locations,
 			observations, times_observed, predictions, acceptances, rejections,
 			first_seen, last_seen, last_useful, typical_duration_minutes,
-			context, dominant_context, created_at, updated_at
+			context, dominant_context, source, window_start, window_end, interpretation,
+			created_at, updated_at
 		FROM behavioral_patterns
 		WHERE id = $1
 	`
@@ -562,6 +880,8 @@ func (s *AnchorStorage) GetPattern(ctx context.Context, id uuid.UUID) (*types.Be
 	var pattern types.BehavioralPattern
 	var contextJSON []byte
 	var dominantContextJSON []byte
+	var windowStart, windowEnd sql.NullString
+	var interpretationJSON []byte
 
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&pattern.ID,
@@ -582,15 +902,19 @@ func (s *AnchorStorage) GetPattern(ctx context.Context, id uuid.UUID) (*types.Be
 		&pattern.TypicalDurationMinutes,
 		&contextJSON,
 		&dominantContextJSON,
+		&pattern.Source,
+		&windowStart,
+		&windowEnd,
+		&interpretationJSON,
 		&pattern.CreatedAt,
 		&pattern.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("pattern not found: %s", id)
+		return nil, errcode.Wrap(postgres.ErrNotFound, fmt.Errorf("pattern not found: %s", id))
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query pattern: %w", err)
+		return nil, fmt.Errorf("failed to query pattern: %w", postgres.Classify(err))
 	}
 
 	// Unmarshal context if present
@@ -607,6 +931,14 @@ func (s *AnchorStorage) GetPattern(ctx context.Context, id uuid.UUID) (*types.Be
 		}
 	}
 
+	pattern.WindowStart = timeOfDay(windowStart)
+	pattern.WindowEnd = timeOfDay(windowEnd)
+	if interpretationJSON != nil {
+		if err := json.Unmarshal(interpretationJSON, &pattern.Interpretation); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal interpretation: %w", err)
+		}
+	}
+
 	return &pattern, nil
 }
 
@@ -658,7 +990,9 @@ func (s *AnchorStorage) UpdatePattern(ctx context.Context, pattern *types.Behavi
 			typical_duration_minutes = $15,
 			context = $16,
 			dominant_context = $17,
-			updated_at = $18
+			window_start = $18,
+			window_end = $19,
+			updated_at = $20
 		WHERE id = $1
 	`
 
@@ -680,20 +1014,22 @@ func (s *AnchorStorage) UpdatePattern(ctx context.Context, pattern *types.Behavi
 		pattern.TypicalDurationMinutes,
 		contextJSON,
 		dominantContextJSON,
+		nullTimeOfDay(pattern.WindowStart),
+		nullTimeOfDay(pattern.WindowEnd),
 		pattern.UpdatedAt,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update pattern: %w", err)
+		return fmt.Errorf("failed to update pattern: %w", postgres.Classify(err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to get rows affected: %w", postgres.Classify(err))
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("pattern not found: %s", pattern.ID)
+		return errcode.Wrap(postgres.ErrNotFound, fmt.Errorf("pattern not found: %s", pattern.ID))
 	}
 
 	return nil
@@ -706,24 +1042,56 @@ func (s *AnchorStorage) GetTopPatterns(ctx context.Context, limit int) ([]*types
 			id, name, description, pattern_type, weight, cluster_size, locations,
 			observations, times_observed, predictions, acceptances, rejections,
 			first_seen, last_seen, last_useful, typical_duration_minutes,
-			context, dominant_context, created_at, updated_at
+			context, dominant_context, source, window_start, window_end, interpretation,
+			created_at, updated_at
 		FROM behavioral_patterns
+		WHERE archived_at IS NULL
 		ORDER BY weight DESC
 		LIMIT $1
 	`
 
 	rows, err := s.db.QueryContext(ctx, query, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query patterns: %w", err)
+		return nil, fmt.Errorf("failed to query patterns: %w", postgres.Classify(err))
+	}
+	defer rows.Close()
+
+	return scanPatterns(rows)
+}
+
+// GetActivePatterns retrieves all patterns that have not been archived, ordered by weight.
+func (s *AnchorStorage) GetActivePatterns(ctx context.Context) ([]*types.BehavioralPattern, error) {
+	query := `
+		SELECT
+			id, name, description, pattern_type, weight, cluster_size, locations,
+			observations, times_observed, predictions, acceptances, rejections,
+			first_seen, last_seen, last_useful, typical_duration_minutes,
+			context, dominant_context, source, window_start, window_end, interpretation,
+			created_at, updated_at
+		FROM behavioral_patterns
+		WHERE archived_at IS NULL
+		ORDER BY weight DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query patterns: %w", postgres.Classify(err))
 	}
 	defer rows.Close()
 
+	return scanPatterns(rows)
+}
+
+// scanPatterns reads behavioral pattern rows selected in the column order of GetTopPatterns
+func scanPatterns(rows *sql.Rows) ([]*types.BehavioralPattern, error) {
 	var patterns []*types.BehavioralPattern
 
 	for rows.Next() {
 		var pattern types.BehavioralPattern
 		var contextJSON []byte
 		var dominantContextJSON []byte
+		var windowStart, windowEnd sql.NullString
+		var interpretationJSON []byte
 
 		err := rows.Scan(
 			&pattern.ID,
@@ -744,12 +1112,16 @@ func (s *AnchorStorage) GetTopPatterns(ctx context.Context, limit int) ([]*types
 			&pattern.TypicalDurationMinutes,
 			&contextJSON,
 			&dominantContextJSON,
+			&pattern.Source,
+			&windowStart,
+			&windowEnd,
+			&interpretationJSON,
 			&pattern.CreatedAt,
 			&pattern.UpdatedAt,
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan pattern row: %w", err)
+			return nil, fmt.Errorf("failed to scan pattern row: %w", postgres.Classify(err))
 		}
 
 		// Unmarshal context if present
@@ -766,11 +1138,19 @@ func (s *AnchorStorage) GetTopPatterns(ctx context.Context, limit int) ([]*types
 			}
 		}
 
+		pattern.WindowStart = timeOfDay(windowStart)
+		pattern.WindowEnd = timeOfDay(windowEnd)
+		if interpretationJSON != nil {
+			if err := json.Unmarshal(interpretationJSON, &pattern.Interpretation); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal interpretation: %w", err)
+			}
+		}
+
 		patterns = append(patterns, &pattern)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating pattern rows: %w", err)
+		return nil, fmt.Errorf("error iterating pattern rows: %w", postgres.Classify(err))
 	}
 
 	return patterns, nil
@@ -786,7 +1166,7 @@ func (s *AnchorStorage) UpdateAnchorPattern(ctx context.Context, anchorID, patte
 
 	_, err := s.db.ExecContext(ctx, query, anchorID, patternID)
 	if err != nil {
-		return fmt.Errorf("failed to update anchor pattern: %w", err)
+		return fmt.Errorf("failed to update anchor pattern: %w", postgres.Classify(err))
 	}
 
 	return nil
@@ -800,7 +1180,7 @@ func (s *AnchorStorage) GetAnchorsSince(ctx context.Context, since time.Time) ([
 		SELECT id, timestamp, location, semantic_embedding,
 		       context, signals, duration_minutes, duration_source,
 		       duration_confidence, preceding_anchor_id, following_anchor_id,
-		       pattern_id, created_at
+		       pattern_id, created_at, occupant, embedding_version
 		FROM semantic_anchors
 		WHERE timestamp >= $1
 		  AND pattern_id IS NULL
@@ -808,7 +1188,7 @@ func (s *AnchorStorage) GetAnchorsSince(ctx context.Context, since time.Time) ([
 
 	rows, err := s.db.QueryContext(ctx, query, since)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query anchors: %w", err)
+		return nil, fmt.Errorf("failed to query anchors: %w", postgres.Classify(err))
 	}
 	defer rows.Close()
 
@@ -831,13 +1211,15 @@ func (s *AnchorStorage) GetAnchorsSince(ctx context.Context, since time.Time) ([
 			&anchor.FollowingAnchorID,
 			&anchor.PatternID,
 			&anchor.CreatedAt,
+			&anchor.Occupant,
+			&anchor.EmbeddingVersion,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan anchor: %w", err)
+			return nil, fmt.Errorf("failed to scan anchor: %w", postgres.Classify(err))
 		}
 
 		// Unmarshal JSONB fields
-		if err := json.Unmarshal(contextJSON, &anchor.Context); err != nil {
+		if err := s.unmarshalContext(anchor.ID, contextJSON, &anchor.Context); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal context: %w", err)
 		}
 
@@ -849,7 +1231,7 @@ func (s *AnchorStorage) GetAnchorsSince(ctx context.Context, since time.Time) ([
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating anchors: %w", err)
+		return nil, fmt.Errorf("error iterating anchors: %w", postgres.Classify(err))
 	}
 
 	return anchors, nil
@@ -862,7 +1244,7 @@ func (s *AnchorStorage) GetAnchorsSinceInWindow(ctx context.Context, windowStart
 		SELECT id, timestamp, location, semantic_embedding,
 		       context, signals, duration_minutes, duration_source,
 		       duration_confidence, preceding_anchor_id, following_anchor_id,
-		       pattern_id, created_at
+		       pattern_id, created_at, occupant, embedding_version
 		FROM semantic_anchors
 		WHERE timestamp >= $1
 		  AND timestamp < $2
@@ -871,7 +1253,7 @@ func (s *AnchorStorage) GetAnchorsSinceInWindow(ctx context.Context, windowStart
 
 	rows, err := s.db.QueryContext(ctx, query, windowStart, windowEnd)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query anchors: %w", err)
+		return nil, fmt.Errorf("failed to query anchors: %w", postgres.Classify(err))
 	}
 	defer rows.Close()
 
@@ -894,13 +1276,15 @@ func (s *AnchorStorage) GetAnchorsSinceInWindow(ctx context.Context, windowStart
 			&anchor.FollowingAnchorID,
 			&anchor.PatternID,
 			&anchor.CreatedAt,
+			&anchor.Occupant,
+			&anchor.EmbeddingVersion,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan anchor: %w", err)
+			return nil, fmt.Errorf("failed to scan anchor: %w", postgres.Classify(err))
 		}
 
 		// Unmarshal JSONB fields
-		if err := json.Unmarshal(contextJSON, &anchor.Context); err != nil {
+		if err := s.unmarshalContext(anchor.ID, contextJSON, &anchor.Context); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal context: %w", err)
 		}
 
@@ -912,7 +1296,7 @@ func (s *AnchorStorage) GetAnchorsSinceInWindow(ctx context.Context, windowStart
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating anchors: %w", err)
+		return nil, fmt.Errorf("error iterating anchors: %w", postgres.Classify(err))
 	}
 
 	return anchors, nil
@@ -932,7 +1316,7 @@ func (s *AnchorStorage) GetAnchorsWithDistancesInWindow(
 		SELECT DISTINCT a.id, a.timestamp, a.location, a.semantic_embedding,
 		       a.context, a.signals, a.duration_minutes, a.duration_source,
 		       a.duration_confidence, a.preceding_anchor_id, a.following_anchor_id,
-		       a.pattern_id, a.created_at
+		       a.pattern_id, a.created_at, a.occupant, a.embedding_version
 		FROM semantic_anchors a
 		WHERE a.timestamp >= $1`
 
@@ -960,7 +1344,7 @@ func (s *AnchorStorage) GetAnchorsWithDistancesInWindow(
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query anchors with distances: %w", err)
+		return nil, fmt.Errorf("failed to query anchors with distances: %w", postgres.Classify(err))
 	}
 	defer rows.Close()
 
@@ -984,14 +1368,16 @@ func (s *AnchorStorage) GetAnchorsWithDistancesInWindow(
 			&anchor.FollowingAnchorID,
 			&anchor.PatternID,
 			&anchor.CreatedAt,
+			&anchor.Occupant,
+			&anchor.EmbeddingVersion,
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan anchor row: %w", err)
+			return nil, fmt.Errorf("failed to scan anchor row: %w", postgres.Classify(err))
 		}
 
 		// Unmarshal JSONB fields
-		if err := json.Unmarshal(contextJSON, &anchor.Context); err != nil {
+		if err := s.unmarshalContext(anchor.ID, contextJSON, &anchor.Context); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal context: %w", err)
 		}
 
@@ -1003,7 +1389,7 @@ func (s *AnchorStorage) GetAnchorsWithDistancesInWindow(
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating anchor rows: %w", err)
+		return nil, fmt.Errorf("error iterating anchor rows: %w", postgres.Classify(err))
 	}
 
 	return anchors, nil
@@ -1025,7 +1411,7 @@ func (s *AnchorStorage) GetAnchorsByIDs(ctx context.Context, ids []uuid.UUID) ([
 		SELECT id, timestamp, location, semantic_embedding,
 		       context, signals, duration_minutes, duration_source,
 		       duration_confidence, preceding_anchor_id, following_anchor_id,
-		       pattern_id, created_at
+		       pattern_id, created_at, occupant, embedding_version
 		FROM semantic_anchors
 		WHERE id::text = ANY($1)
 		ORDER BY timestamp ASC
@@ -1033,7 +1419,7 @@ func (s *AnchorStorage) GetAnchorsByIDs(ctx context.Context, ids []uuid.UUID) ([
 
 	rows, err := s.db.QueryContext(ctx, query, pq.Array(idStrings))
 	if err != nil {
-		return nil, fmt.Errorf("failed to query anchors by IDs: %w", err)
+		return nil, fmt.Errorf("failed to query anchors by IDs: %w", postgres.Classify(err))
 	}
 	defer rows.Close()
 
@@ -1057,14 +1443,16 @@ func (s *AnchorStorage) GetAnchorsByIDs(ctx context.Context, ids []uuid.UUID) ([
 			&anchor.FollowingAnchorID,
 			&anchor.PatternID,
 			&anchor.CreatedAt,
+			&anchor.Occupant,
+			&anchor.EmbeddingVersion,
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan anchor row: %w", err)
+			return nil, fmt.Errorf("failed to scan anchor row: %w", postgres.Classify(err))
 		}
 
 		// Unmarshal JSONB fields
-		if err := json.Unmarshal(contextJSON, &anchor.Context); err != nil {
+		if err := s.unmarshalContext(anchor.ID, contextJSON, &anchor.Context); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal context: %w", err)
 		}
 
@@ -1076,12 +1464,48 @@ func (s *AnchorStorage) GetAnchorsByIDs(ctx context.Context, ids []uuid.UUID) ([
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating anchor rows: %w", err)
+		return nil, fmt.Errorf("error iterating anchor rows: %w", postgres.Classify(err))
 	}
 
 	return anchors, nil
 }
 
+// GetAnchorTimestamps returns the timestamps of the given anchors without
+// loading their embeddings, for ordering large anchor sets
+func (s *AnchorStorage) GetAnchorTimestamps(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	timestamps := make(map[uuid.UUID]time.Time, len(ids))
+	if len(ids) == 0 {
+		return timestamps, nil
+	}
+
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, timestamp FROM semantic_anchors WHERE id::text = ANY($1)`,
+		pq.Array(idStrings))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anchor timestamps: %w", postgres.Classify(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var ts time.Time
+		if err := rows.Scan(&id, &ts); err != nil {
+			return nil, fmt.Errorf("failed to scan anchor timestamp: %w", postgres.Classify(err))
+		}
+		timestamps[id] = ts
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating anchor timestamps: %w", postgres.Classify(err))
+	}
+
+	return timestamps, nil
+}
+
 // UpdatePatternWeight increments a pattern's weight by delta
 func (s *AnchorStorage) UpdatePatternWeight(ctx context.Context, patternID uuid.UUID, weightDelta float64) error {
 	query := `
@@ -1093,7 +1517,7 @@ func (s *AnchorStorage) UpdatePatternWeight(ctx context.Context, patternID uuid.
 
 	_, err := s.db.ExecContext(ctx, query, patternID, weightDelta, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to update pattern weight: %w", err)
+		return fmt.Errorf("failed to update pattern weight: %w", postgres.Classify(err))
 	}
 
 	return nil
@@ -1112,7 +1536,7 @@ func (s *AnchorStorage) UpdatePatternObserved(ctx context.Context, patternID uui
 	now := time.Now()
 	_, err := s.db.ExecContext(ctx, query, patternID, now)
 	if err != nil {
-		return fmt.Errorf("failed to update pattern observed: %w", err)
+		return fmt.Errorf("failed to update pattern observed: %w", postgres.Classify(err))
 	}
 
 	return nil
@@ -1133,8 +1557,252 @@ func (s *AnchorStorage) UpdatePatternPrediction(ctx context.Context, patternID u
 	now := time.Now()
 	_, err := s.db.ExecContext(ctx, query, patternID, accepted, now)
 	if err != nil {
-		return fmt.Errorf("failed to update pattern prediction: %w", err)
+		return fmt.Errorf("failed to update pattern prediction: %w", postgres.Classify(err))
 	}
 
 	return nil
 }
+
+// GetPatternTransitions returns moves from fromLocation to a different location between
+// consecutive anchors of the same pattern, limited to gaps of at most maxGap. Manual
+// patterns contribute a move between each pair of consecutive locations, at the time
+// of day their window starts and spreading the window evenly over the locations.
+func (s *AnchorStorage) GetPatternTransitions(ctx context.Context, fromLocation string, maxGap time.Duration) ([]*types.PatternTransition, error) {
+	query := `
+		SELECT
+			p.id, p.name, COALESCE(p.pattern_type, ''), p.weight, p.predictions, p.acceptances,
+			t.location, t.next_location, COALESCE(t.time_of_day, ''), t.gap_minutes,
+			COALESCE(t.next_duration, p.typical_duration_minutes)
+		FROM (
+			SELECT
+				pattern_id,
+				location,
+				context->>'time_of_day' AS time_of_day,
+				LEAD(location) OVER w AS next_location,
+				EXTRACT(EPOCH FROM (LEAD(timestamp) OVER w - timestamp)) / 60 AS gap_minutes,
+				LEAD(duration_minutes) OVER w AS next_duration
+			FROM semantic_anchors
+			WHERE pattern_id IS NOT NULL
+			WINDOW w AS (PARTITION BY pattern_id ORDER BY timestamp)
+		) t
+		JOIN behavioral_patterns p ON p.id = t.pattern_id
+		WHERE t.location = $1
+			AND t.next_location IS NOT NULL
+			AND t.next_location <> t.location
+			AND t.gap_minutes <= $2
+			AND p.archived_at IS NULL
+		UNION ALL
+		SELECT
+			p.id, p.name, COALESCE(p.pattern_type, ''), p.weight, p.predictions, p.acceptances,
+			l.location, p.locations[l.ord + 1],
+			CASE
+				WHEN p.window_start IS NULL THEN ''
+				WHEN EXTRACT(HOUR FROM p.window_start) BETWEEN 5 AND 11 THEN 'morning'
+				WHEN EXTRACT(HOUR FROM p.window_start) BETWEEN 12 AND 16 THEN 'afternoon'
+				WHEN EXTRACT(HOUR FROM p.window_start) BETWEEN 17 AND 20 THEN 'evening'
+				ELSE 'night'
+			END,
+			COALESCE(((EXTRACT(EPOCH FROM (p.window_end - p.window_start)) / 60 + 1440)::numeric % 1440)::float8
+				/ cardinality(p.locations), 0),
+			p.typical_duration_minutes
+		FROM behavioral_patterns p
+		CROSS JOIN LATERAL unnest(p.locations) WITH ORDINALITY AS l(location, ord)
+		WHERE p.source = 'manual'
+			AND p.archived_at IS NULL
+			AND l.location = $1
+			AND l.ord < cardinality(p.locations)
+			AND p.locations[l.ord + 1] <> l.location
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, fromLocation, maxGap.Minutes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pattern transitions: %w", postgres.Classify(err))
+	}
+	defer rows.Close()
+
+	var transitions []*types.PatternTransition
+	for rows.Next() {
+		var t types.PatternTransition
+		var duration sql.NullInt64
+
+		err := rows.Scan(
+			&t.PatternID,
+			&t.PatternName,
+			&t.PatternType,
+			&t.PatternWeight,
+			&t.Predictions,
+			&t.Acceptances,
+			&t.FromLocation,
+			&t.ToLocation,
+			&t.TimeOfDay,
+			&t.GapMinutes,
+			&duration,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pattern transition: %w", postgres.Classify(err))
+		}
+
+		if duration.Valid {
+			minutes := int(duration.Int64)
+			t.DurationMinutes = &minutes
+		}
+
+		transitions = append(transitions, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pattern transitions: %w", postgres.Classify(err))
+	}
+
+	return transitions, nil
+}
+
+// GetPatternCentroids computes the mean anchor embedding of every active pattern
+func (s *AnchorStorage) GetPatternCentroids(ctx context.Context) ([]*types.PatternCentroid, error) {
+	query := `
+		SELECT sa.pattern_id, AVG(sa.semantic_embedding), COUNT(*),
+			COALESCE(p.context->>'occupant', '')
+		FROM semantic_anchors sa
+		JOIN behavioral_patterns p ON p.id = sa.pattern_id
+		WHERE p.archived_at IS NULL
+		GROUP BY sa.pattern_id, p.context->>'occupant'
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pattern centroids: %w", postgres.Classify(err))
+	}
+	defer rows.Close()
+
+	var centroids []*types.PatternCentroid
+	for rows.Next() {
+		var c types.PatternCentroid
+		if err := rows.Scan(&c.PatternID, &c.Centroid, &c.AnchorCount, &c.Occupant); err != nil {
+			return nil, fmt.Errorf("failed to scan pattern centroid: %w", postgres.Classify(err))
+		}
+		centroids = append(centroids, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pattern centroids: %w", postgres.Classify(err))
+	}
+
+	return centroids, nil
+}
+
+// GetAnchorsByPattern retrieves all anchors assigned to a pattern
+func (s *AnchorStorage) GetAnchorsByPattern(ctx context.Context, patternID uuid.UUID) ([]*types.SemanticAnchor, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM semantic_anchors WHERE pattern_id = $1`, patternID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pattern anchors: %w", postgres.Classify(err))
+	}
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan anchor id: %w", postgres.Classify(err))
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	return s.GetAnchorsByIDs(ctx, ids)
+}
+
+// ClearAnchorPattern removes an anchor's pattern assignment
+func (s *AnchorStorage) ClearAnchorPattern(ctx context.Context, anchorID uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE semantic_anchors SET pattern_id = NULL WHERE id = $1`, anchorID)
+	if err != nil {
+		return fmt.Errorf("failed to clear anchor pattern: %w", err)
+	}
+	return nil
+}
+
+// MergePattern folds source into target: anchors move to target, usage counters are
+// summed, target keeps the higher weight, and source is archived. Returns the number
+// of anchors reassigned.
+func (s *AnchorStorage) MergePattern(ctx context.Context, sourceID, targetID uuid.UUID) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", postgres.Classify(err))
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE semantic_anchors SET pattern_id = $2 WHERE pattern_id = $1`,
+		sourceID, targetID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reassign anchors: %w", err)
+	}
+	moved, _ := result.RowsAffected()
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE behavioral_patterns t
+		SET weight = GREATEST(t.weight, src.weight),
+			cluster_size = t.cluster_size + src.cluster_size,
+			observations = t.observations + src.observations,
+			times_observed = t.times_observed + src.times_observed,
+			predictions = t.predictions + src.predictions,
+			acceptances = t.acceptances + src.acceptances,
+			rejections = t.rejections + src.rejections,
+			first_seen = LEAST(t.first_seen, src.first_seen),
+			last_seen = GREATEST(t.last_seen, src.last_seen),
+			updated_at = $3
+		FROM behavioral_patterns src
+		WHERE t.id = $2 AND src.id = $1`,
+		sourceID, targetID, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fold pattern statistics: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE behavioral_patterns
+		SET archived_at = $3, archive_reason = 'merged', merged_into = $2, updated_at = $3
+		WHERE id = $1`,
+		sourceID, targetID, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive merged pattern: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit pattern merge: %w", postgres.Classify(err))
+	}
+
+	return moved, nil
+}
+
+// DecayPatternWeight multiplies a pattern's weight by factor, never below the 0.1 floor.
+// Returns the new weight.
+func (s *AnchorStorage) DecayPatternWeight(ctx context.Context, patternID uuid.UUID, factor float64) (float64, error) {
+	var weight float64
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE behavioral_patterns
+		SET weight = GREATEST(0.1, weight * $2),
+			updated_at = $3
+		WHERE id = $1
+		RETURNING weight`,
+		patternID, factor, time.Now(),
+	).Scan(&weight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decay pattern weight: %w", err)
+	}
+
+	return weight, nil
+}
+
+// ArchivePattern retires a pattern. Its anchors should be reassigned or cleared first.
+func (s *AnchorStorage) ArchivePattern(ctx context.Context, patternID uuid.UUID, reason string) error {
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE behavioral_patterns
+		SET archived_at = $2, archive_reason = $3, updated_at = $2
+		WHERE id = $1`,
+		patternID, now, reason)
+	if err != nil {
+		return fmt.Errorf("failed to archive pattern: %w", err)
+	}
+	return nil
+}
@@ -132,6 +132,57 @@ func TestFindSimilarAnchors(t *testing.T) {
 	assert.Equal(t, "cooking", similar[0].Context["activity"])
 }
 
+func TestFindSimilarAnchorsFiltered(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	storage := NewAnchorStorage(db)
+	storage.SetSearchParams(10, 64)
+	ctx := context.Background()
+
+	baseVector := makeTestVector(128)
+	now := time.Now()
+
+	anchors := []*types.SemanticAnchor{
+		{
+			Location:          "kitchen",
+			Timestamp:         now.Add(-48 * time.Hour),
+			SemanticEmbedding: baseVector,
+			Context:           map[string]interface{}{"activity": "cooking"},
+			Signals:           []types.ActivitySignal{},
+		},
+		{
+			Location:          "kitchen",
+			Timestamp:         now,
+			SemanticEmbedding: makeSlightlyDifferentVector(baseVector, 0.3),
+			Context:           map[string]interface{}{"activity": "breakfast"},
+			Signals:           []types.ActivitySignal{},
+		},
+		{
+			Location:          "dining_room",
+			Timestamp:         now,
+			SemanticEmbedding: makeSlightlyDifferentVector(baseVector, 0.1),
+			Context:           map[string]interface{}{"activity": "eating"},
+			Signals:           []types.ActivitySignal{},
+		},
+	}
+
+	for _, anchor := range anchors {
+		err := storage.CreateAnchor(ctx, anchor)
+		require.NoError(t, err)
+	}
+
+	// Restricting to the kitchen in the last day leaves only the breakfast anchor,
+	// even though closer matches exist elsewhere and earlier
+	similar, err := storage.FindSimilarAnchorsFiltered(ctx, baseVector, SimilarAnchorFilter{
+		Location: "kitchen",
+		Since:    now.Add(-24 * time.Hour),
+	}, 5)
+	require.NoError(t, err)
+	require.Len(t, similar, 1)
+	assert.Equal(t, "breakfast", similar[0].Context["activity"])
+}
+
 func TestStoreAndGetDistance(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -185,6 +236,50 @@ func TestStoreAndGetDistance(t *testing.T) {
 	assert.Equal(t, 0.15, retrieved2.Distance)
 }
 
+func TestBulkCreateAnchorsAndStoreDistances(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	storage := NewAnchorStorage(db)
+	ctx := context.Background()
+
+	// Chained anchors: each references the previous one in the same batch
+	anchors := make([]*types.SemanticAnchor, 3)
+	for i := range anchors {
+		anchors[i] = &types.SemanticAnchor{
+			ID:                uuid.New(),
+			Location:          "kitchen",
+			Timestamp:         time.Now().Add(time.Duration(i) * time.Minute),
+			SemanticEmbedding: makeTestVector(128),
+			Context:           map[string]interface{}{},
+			Signals:           []types.ActivitySignal{},
+		}
+		if i > 0 {
+			anchors[i].PrecedingAnchorID = &anchors[i-1].ID
+		}
+	}
+	require.NoError(t, storage.CreateAnchors(ctx, anchors))
+
+	// The same pair twice (in both orders): the last entry wins
+	distances := []*types.AnchorDistance{
+		{Anchor1ID: anchors[0].ID, Anchor2ID: anchors[1].ID, Distance: 0.2, Source: "vector"},
+		{Anchor1ID: anchors[1].ID, Anchor2ID: anchors[2].ID, Distance: 0.3, Source: "vector"},
+		{Anchor1ID: anchors[1].ID, Anchor2ID: anchors[0].ID, Distance: 0.4, Source: "llm"},
+	}
+	require.NoError(t, storage.StoreDistances(ctx, distances))
+
+	retrieved, err := storage.GetDistance(ctx, anchors[0].ID, anchors[1].ID)
+	require.NoError(t, err)
+	require.NotNil(t, retrieved)
+	assert.Equal(t, 0.4, retrieved.Distance)
+	assert.Equal(t, "llm", retrieved.Source)
+}
+
+func TestPlaceholders(t *testing.T) {
+	assert.Equal(t, "($1, $2, $3)", placeholders(0, 3))
+	assert.Equal(t, "($6, $7)", placeholders(5, 2))
+}
+
 func TestGetAnchorsNeedingDistances(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -241,6 +336,10 @@ func TestGetAnchorsNeedingDistances(t *testing.T) {
 	pairs, err = storage.GetAnchorsNeedingDistances(ctx, 10)
 	require.NoError(t, err)
 	assert.Len(t, pairs, 2)
+
+	count, err := storage.CountAnchorsNeedingDistances(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
 }
 
 func TestCreateAndGetInterpretation(t *testing.T) {
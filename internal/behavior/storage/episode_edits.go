@@ -0,0 +1,500 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// Episode edit errors, mapped to HTTP statuses by callers. They are classed
+// as postgres.ErrNotFound and postgres.ErrValidation.
+var (
+	ErrEpisodeNotFound = errcode.New(postgres.ErrNotFound, "episode not found")
+	ErrInvalidEdit     = errcode.New(postgres.ErrValidation, "invalid episode edit")
+)
+
+// SplitRequest splits a macro-episode at a point in time. Micro-episodes that
+// start before At go to the first half, the rest to the second.
+type SplitRequest struct {
+	At   time.Time `json:"at"`
+	Note string    `json:"note,omitempty"`
+}
+
+// Validate checks the split time is set
+func (r SplitRequest) Validate() error {
+	if r.At.IsZero() {
+		return fmt.Errorf("%w: split time is required", ErrInvalidEdit)
+	}
+	return nil
+}
+
+// MergeRequest merges micro-episodes in the same location into one spanning
+// all of them
+type MergeRequest struct {
+	EpisodeIDs []uuid.UUID `json:"episode_ids"`
+	Note       string      `json:"note,omitempty"`
+}
+
+// Validate checks at least two distinct episodes are given
+func (r MergeRequest) Validate() error {
+	seen := make(map[uuid.UUID]bool, len(r.EpisodeIDs))
+	for _, id := range r.EpisodeIDs {
+		if seen[id] {
+			return fmt.Errorf("%w: episode %s listed twice", ErrInvalidEdit, id)
+		}
+		seen[id] = true
+	}
+	if len(seen) < 2 {
+		return fmt.Errorf("%w: merge needs at least two episodes", ErrInvalidEdit)
+	}
+	return nil
+}
+
+// EditResult lists the episodes an edit removed and created
+type EditResult struct {
+	Deleted []uuid.UUID `json:"deleted"`
+	Created []uuid.UUID `json:"created"`
+}
+
+// editMicro is the part of a micro-episode edits need
+type editMicro struct {
+	ID        uuid.UUID
+	Location  string
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// splitMicros partitions micro-episodes (sorted by start) at the given time
+func splitMicros(micros []editMicro, at time.Time) (before, after []editMicro) {
+	for _, m := range micros {
+		if m.StartedAt.Before(at) {
+			before = append(before, m)
+		} else {
+			after = append(after, m)
+		}
+	}
+	return before, after
+}
+
+// microSpan returns the time span and distinct locations (in order of first
+// appearance) of sorted micro-episodes
+func microSpan(micros []editMicro) (start, end time.Time, locations []string) {
+	seen := make(map[string]bool)
+	for i, m := range micros {
+		if i == 0 || m.StartedAt.Before(start) {
+			start = m.StartedAt
+		}
+		if m.EndedAt.After(end) {
+			end = m.EndedAt
+		}
+		if !seen[m.Location] {
+			seen[m.Location] = true
+			locations = append(locations, m.Location)
+		}
+	}
+	return start, end, locations
+}
+
+// SplitMacroEpisode replaces a macro-episode with two covering the
+// micro-episodes before and after req.At. Both halves keep the original
+// pattern type and summary and are marked as manually edited.
+func (s *AnchorStorage) SplitMacroEpisode(ctx context.Context, id uuid.UUID, req SplitRequest) (*EditResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		patternType     string
+		summary         sql.NullString
+		tags            []string
+		contextFeatures []byte
+		microIDs        []uuid.UUID
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT pattern_type, summary, semantic_tags, COALESCE(context_features, '{}'::jsonb), micro_episode_ids
+		FROM macro_episodes WHERE id = $1 FOR UPDATE`, id).
+		Scan(&patternType, &summary, pq.Array(&tags), &contextFeatures, pq.Array(&microIDs))
+	if err == sql.ErrNoRows {
+		return nil, ErrEpisodeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load macro-episode: %w", err)
+	}
+
+	micros, err := loadEditMicros(ctx, tx, microIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after := splitMicros(micros, req.At)
+	if len(before) == 0 || len(after) == 0 {
+		return nil, fmt.Errorf("%w: split time must fall between the macro-episode's micro-episodes", ErrInvalidEdit)
+	}
+
+	var features map[string]interface{}
+	if err := json.Unmarshal(contextFeatures, &features); err != nil || features == nil {
+		features = map[string]interface{}{}
+	}
+	features["edited"] = true
+	features["split_from"] = id.String()
+	featuresJSON, err := json.Marshal(features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal context features: %w", err)
+	}
+
+	result := &EditResult{Deleted: []uuid.UUID{id}}
+	for _, half := range [][]editMicro{before, after} {
+		start, end, locations := microSpan(half)
+		ids := make([]uuid.UUID, len(half))
+		for i, m := range half {
+			ids[i] = m.ID
+		}
+
+		newID := uuid.New()
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO macro_episodes (
+				id, pattern_type, start_time, end_time, duration_minutes,
+				locations, micro_episode_ids, summary, semantic_tags,
+				context_features, created_at
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())`,
+			newID, patternType, start, end, int(end.Sub(start).Minutes()),
+			pq.Array(locations), pq.Array(ids), summary, pq.Array(tags), featuresJSON,
+		); err != nil {
+			return nil, fmt.Errorf("failed to insert split macro-episode: %w", err)
+		}
+		result.Created = append(result.Created, newID)
+	}
+
+	start, end, _ := microSpan(micros)
+	if err := insertTombstone(ctx, tx, tombstone{
+		episodeID: id, kind: "macro", reason: "split",
+		startedAt: start, endedAt: end,
+		microIDs: microIDs, replacedBy: result.Created, note: req.Note,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Annotations on the old macro stay as history but no longer point at it
+	if _, err := tx.ExecContext(ctx, `DELETE FROM macro_episodes WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to delete split macro-episode: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit split: %w", err)
+	}
+	return result, nil
+}
+
+// MergeMicroEpisodes replaces micro-episodes in one location with a single
+// episode spanning all of them. The episodes must belong to the same
+// macro-episode (or none), which is updated to reference the merged one.
+func (s *AnchorStorage) MergeMicroEpisodes(ctx context.Context, req MergeRequest) (*EditResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	micros, err := loadEditMicros(ctx, tx, req.EpisodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(micros) != len(req.EpisodeIDs) {
+		return nil, ErrEpisodeNotFound
+	}
+	for _, m := range micros[1:] {
+		if m.Location != micros[0].Location {
+			return nil, fmt.Errorf("%w: episodes are in different locations (%s, %s)", ErrInvalidEdit, micros[0].Location, m.Location)
+		}
+	}
+
+	var macroCount int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM macro_episodes WHERE micro_episode_ids && $1`,
+		pq.Array(req.EpisodeIDs)).Scan(&macroCount); err != nil {
+		return nil, fmt.Errorf("failed to look up macro-episodes: %w", err)
+	}
+	if macroCount > 1 {
+		return nil, fmt.Errorf("%w: episodes belong to different macro-episodes", ErrInvalidEdit)
+	}
+
+	start, end, _ := microSpan(micros)
+	newID := uuid.New()
+	doc, err := s.mergedEpisodeDoc(ctx, tx, micros[0].ID, newID, map[string]interface{}{
+		"jeeves:startedAt":   start.Format(time.RFC3339),
+		"jeeves:endedAt":     end.Format(time.RFC3339),
+		"jeeves:triggerType": "manual_merge",
+		"jeeves:mergedFrom":  req.EpisodeIDs,
+		"jeeves:edited":      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO behavioral_episodes (id, jsonld) VALUES ($1, $2)`, newID, doc); err != nil {
+		return nil, fmt.Errorf("failed to insert merged episode: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE macro_episodes
+		SET micro_episode_ids = array_append(
+			ARRAY(SELECT m FROM unnest(micro_episode_ids) AS m WHERE m <> ALL($1)), $2)
+		WHERE micro_episode_ids && $1`,
+		pq.Array(req.EpisodeIDs), newID); err != nil {
+		return nil, fmt.Errorf("failed to update macro-episode: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE episode_annotations SET episode_id = $2 WHERE episode_id = ANY($1)`,
+		pq.Array(req.EpisodeIDs), newID); err != nil {
+		return nil, fmt.Errorf("failed to move annotations: %w", err)
+	}
+
+	result := &EditResult{Created: []uuid.UUID{newID}}
+	for _, m := range micros {
+		if err := insertTombstone(ctx, tx, tombstone{
+			episodeID: m.ID, kind: "micro", reason: "merged", location: m.Location,
+			startedAt: m.StartedAt, endedAt: m.EndedAt,
+			replacedBy: result.Created, note: req.Note,
+		}); err != nil {
+			return nil, err
+		}
+		result.Deleted = append(result.Deleted, m.ID)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM behavioral_episodes WHERE id = ANY($1)`, pq.Array(req.EpisodeIDs)); err != nil {
+		return nil, fmt.Errorf("failed to delete merged episodes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge: %w", err)
+	}
+	return result, nil
+}
+
+// mergedEpisodeDoc returns the document of episode from with fields set,
+// sealed for the row newID. The earliest episode's document carries over to
+// a merge, stretched over the merged span.
+func (s *AnchorStorage) mergedEpisodeDoc(ctx context.Context, tx *sql.Tx, from, newID uuid.UUID, fields map[string]interface{}) ([]byte, error) {
+	var raw []byte
+	if err := tx.QueryRowContext(ctx,
+		`SELECT jsonld FROM behavioral_episodes WHERE id = $1`, from).Scan(&raw); err != nil {
+		return nil, fmt.Errorf("failed to read episode %s: %w", from, err)
+	}
+	raw, err := s.sealer.Open(raw, from.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt episode %s: %w", from, err)
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode episode %s: %w", from, err)
+	}
+	for field, value := range fields {
+		if doc[field], err = json.Marshal(value); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", field, err)
+		}
+	}
+	raw, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merged episode: %w", err)
+	}
+	sealed, err := s.sealer.Seal(raw, ontology.EpisodeIndexFields, newID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt merged episode: %w", err)
+	}
+	return sealed, nil
+}
+
+// DeleteMicroEpisode removes a spurious micro-episode and its annotations. It is
+// dropped from its macro-episode; a macro-episode left empty is deleted too.
+func (s *AnchorStorage) DeleteMicroEpisode(ctx context.Context, id uuid.UUID, note string) (*EditResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	micros, err := loadEditMicros(ctx, tx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(micros) == 0 {
+		return nil, ErrEpisodeNotFound
+	}
+	m := micros[0]
+
+	result := &EditResult{Deleted: []uuid.UUID{id}}
+
+	emptied, err := tx.QueryContext(ctx, `
+		DELETE FROM macro_episodes
+		WHERE micro_episode_ids = ARRAY[$1::uuid]
+		RETURNING id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete emptied macro-episode: %w", err)
+	}
+	for emptied.Next() {
+		var macroID uuid.UUID
+		if err := emptied.Scan(&macroID); err != nil {
+			emptied.Close()
+			return nil, fmt.Errorf("failed to scan macro-episode: %w", err)
+		}
+		result.Deleted = append(result.Deleted, macroID)
+	}
+	emptied.Close()
+
+	statements := []string{
+		`UPDATE macro_episodes SET micro_episode_ids = array_remove(micro_episode_ids, $1)
+		 WHERE $1 = ANY(micro_episode_ids)`,
+		`DELETE FROM episode_annotations WHERE episode_id = $1`,
+		`UPDATE episode_annotations SET related_episode_ids = array_remove(related_episode_ids, $1)
+		 WHERE $1 = ANY(related_episode_ids)`,
+		`DELETE FROM behavioral_episodes WHERE id = $1`,
+	}
+	for _, query := range statements {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return nil, fmt.Errorf("failed to delete episode: %w", err)
+		}
+	}
+
+	if err := insertTombstone(ctx, tx, tombstone{
+		episodeID: id, kind: "micro", reason: "deleted", location: m.Location,
+		startedAt: m.StartedAt, endedAt: m.EndedAt, note: note,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit delete: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteMacroEpisode removes a spurious macro-episode. Its micro-episodes are
+// kept but excluded from future consolidation runs so the same grouping is not
+// recreated.
+func (s *AnchorStorage) DeleteMacroEpisode(ctx context.Context, id uuid.UUID, note string) (*EditResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var start, end time.Time
+	var microIDs []uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM macro_episodes WHERE id = $1
+		RETURNING start_time, end_time, micro_episode_ids`, id).
+		Scan(&start, &end, pq.Array(&microIDs))
+	if err == sql.ErrNoRows {
+		return nil, ErrEpisodeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete macro-episode: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM episode_annotations WHERE episode_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to delete annotations: %w", err)
+	}
+
+	if err := insertTombstone(ctx, tx, tombstone{
+		episodeID: id, kind: "macro", reason: "deleted",
+		startedAt: start, endedAt: end, microIDs: microIDs, note: note,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit delete: %w", err)
+	}
+	return &EditResult{Deleted: []uuid.UUID{id}}, nil
+}
+
+// loadEditMicros locks and returns the given micro-episodes sorted by start.
+// Episodes that don't exist are left out; open episodes can't be edited.
+func loadEditMicros(ctx context.Context, tx *sql.Tx, ids []uuid.UUID) ([]editMicro, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, location, started_at_text::timestamptz, ended_at_text::timestamptz
+		FROM behavioral_episodes
+		WHERE id = ANY($1)
+		FOR UPDATE`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load episodes: %w", err)
+	}
+	defer rows.Close()
+
+	var micros []editMicro
+	for rows.Next() {
+		var m editMicro
+		var location sql.NullString
+		var endedAt sql.NullTime
+		if err := rows.Scan(&m.ID, &location, &m.StartedAt, &endedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan episode: %w", err)
+		}
+		if !endedAt.Valid {
+			return nil, fmt.Errorf("%w: episode %s is still open", ErrInvalidEdit, m.ID)
+		}
+		m.Location = location.String
+		m.EndedAt = endedAt.Time
+		micros = append(micros, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(micros, func(i, j int) bool { return micros[i].StartedAt.Before(micros[j].StartedAt) })
+	return micros, nil
+}
+
+type tombstone struct {
+	episodeID  uuid.UUID
+	kind       string
+	reason     string
+	location   string
+	startedAt  time.Time
+	endedAt    time.Time
+	microIDs   []uuid.UUID
+	replacedBy []uuid.UUID
+	note       string
+}
+
+func insertTombstone(ctx context.Context, tx *sql.Tx, t tombstone) error {
+	if t.microIDs == nil {
+		t.microIDs = []uuid.UUID{}
+	}
+	if t.replacedBy == nil {
+		t.replacedBy = []uuid.UUID{}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO episode_tombstones (
+			episode_id, episode_kind, reason, location, started_at, ended_at,
+			micro_episode_ids, replaced_by, note
+		)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, NULLIF($9, ''))`,
+		t.episodeID, t.kind, t.reason, t.location, t.startedAt, t.endedAt,
+		pq.Array(t.microIDs), pq.Array(t.replacedBy), t.note,
+	); err != nil {
+		return fmt.Errorf("failed to record tombstone: %w", err)
+	}
+	return nil
+}
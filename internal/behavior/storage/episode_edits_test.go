@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeRequestValidate(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	assert.ErrorIs(t, MergeRequest{}.Validate(), ErrInvalidEdit)
+	assert.ErrorIs(t, MergeRequest{EpisodeIDs: []uuid.UUID{a}}.Validate(), ErrInvalidEdit)
+	assert.ErrorIs(t, MergeRequest{EpisodeIDs: []uuid.UUID{a, a}}.Validate(), ErrInvalidEdit)
+	assert.NoError(t, MergeRequest{EpisodeIDs: []uuid.UUID{a, b}}.Validate())
+}
+
+func TestSplitRequestValidate(t *testing.T) {
+	assert.ErrorIs(t, SplitRequest{}.Validate(), ErrInvalidEdit)
+	assert.NoError(t, SplitRequest{At: time.Now()}.Validate())
+}
+
+func TestSplitMicros(t *testing.T) {
+	base := time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC)
+	micros := []editMicro{
+		{ID: uuid.New(), Location: "bedroom", StartedAt: base, EndedAt: base.Add(10 * time.Minute)},
+		{ID: uuid.New(), Location: "bathroom", StartedAt: base.Add(10 * time.Minute), EndedAt: base.Add(25 * time.Minute)},
+		{ID: uuid.New(), Location: "kitchen", StartedAt: base.Add(40 * time.Minute), EndedAt: base.Add(70 * time.Minute)},
+	}
+
+	before, after := splitMicros(micros, base.Add(30*time.Minute))
+	assert.Len(t, before, 2)
+	assert.Len(t, after, 1)
+	assert.Equal(t, "kitchen", after[0].Location)
+
+	// A micro starting exactly at the split time goes to the second half
+	before, after = splitMicros(micros, base.Add(10*time.Minute))
+	assert.Len(t, before, 1)
+	assert.Len(t, after, 2)
+}
+
+func TestMicroSpan(t *testing.T) {
+	base := time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC)
+	micros := []editMicro{
+		{Location: "kitchen", StartedAt: base, EndedAt: base.Add(30 * time.Minute)},
+		{Location: "dining_room", StartedAt: base.Add(5 * time.Minute), EndedAt: base.Add(20 * time.Minute)},
+		{Location: "kitchen", StartedAt: base.Add(25 * time.Minute), EndedAt: base.Add(40 * time.Minute)},
+	}
+
+	start, end, locations := microSpan(micros)
+	assert.Equal(t, base, start)
+	assert.Equal(t, base.Add(40*time.Minute), end)
+	assert.Equal(t, []string{"kitchen", "dining_room"}, locations)
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
+)
+
+// MigrationResult reports an episode document upgrade run
+type MigrationResult struct {
+	Upgraded int `json:"upgraded"`
+	Invalid  int `json:"invalid"` // upgraded but still failing validation
+	Failed   int `json:"failed"`  // could not be migrated, left unchanged
+}
+
+// UpgradeEpisodeDocuments migrates micro-episode documents older than the
+// current ontology version, batchSize rows at a time
+func (s *AnchorStorage) UpgradeEpisodeDocuments(ctx context.Context, batchSize int) (*MigrationResult, error) {
+	result := &MigrationResult{}
+	skipped := []uuid.UUID{}
+
+	for {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT id, jsonld
+			FROM behavioral_episodes
+			WHERE COALESCE((jsonld->>'jeeves:schemaVersion')::int, 1) < $1
+			  AND id <> ALL($2::uuid[])
+			ORDER BY id
+			LIMIT $3`,
+			ontology.ContextVersion, pq.Array(skipped), batchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to select outdated episodes: %w", err)
+		}
+
+		type row struct {
+			id  uuid.UUID
+			doc []byte
+		}
+		var batch []row
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.id, &r.doc); err != nil {
+				rows.Close()
+				return result, fmt.Errorf("failed to scan episode: %w", err)
+			}
+			batch = append(batch, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return result, fmt.Errorf("failed to read outdated episodes: %w", err)
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		for _, r := range batch {
+			doc, err := s.sealer.Open(r.doc, r.id.String())
+			if err != nil {
+				result.Failed++
+				skipped = append(skipped, r.id)
+				continue
+			}
+			upgraded, _, err := ontology.MigrateEpisodeJSON(doc)
+			if err != nil {
+				result.Failed++
+				skipped = append(skipped, r.id)
+				continue
+			}
+			if ontology.ValidateEpisodeJSON(upgraded) != nil {
+				result.Invalid++
+			}
+			if upgraded, err = s.sealer.Seal(upgraded, ontology.EpisodeIndexFields, r.id.String()); err != nil {
+				return result, fmt.Errorf("failed to encrypt upgraded episode %s: %w", r.id, err)
+			}
+
+			if _, err := s.db.ExecContext(ctx,
+				`UPDATE behavioral_episodes SET jsonld = $2 WHERE id = $1`, r.id, upgraded); err != nil {
+				return result, fmt.Errorf("failed to store upgraded episode %s: %w", r.id, err)
+			}
+			result.Upgraded++
+		}
+	}
+}
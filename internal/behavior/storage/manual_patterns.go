@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// GetPatterns retrieves every pattern ordered by weight, archived ones only when
+// includeArchived is set. An empty source matches both discovered and manual patterns.
+func (s *AnchorStorage) GetPatterns(ctx context.Context, includeArchived bool, source string) ([]*types.BehavioralPattern, error) {
+	query := `
+		SELECT
+			id, name, description, pattern_type, weight, cluster_size, locations,
+			observations, times_observed, predictions, acceptances, rejections,
+			first_seen, last_seen, last_useful, typical_duration_minutes,
+			context, dominant_context, source, window_start, window_end, interpretation,
+			created_at, updated_at
+		FROM behavioral_patterns
+		WHERE ($1 OR archived_at IS NULL)
+		  AND ($2 = '' OR source = $2)
+		ORDER BY weight DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, includeArchived, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query patterns: %w", postgres.Classify(err))
+	}
+	defer rows.Close()
+
+	return scanPatterns(rows)
+}
+
+// DeleteManualPattern removes a manual pattern. Discovered patterns are retired
+// with RetirePattern instead, so their anchors return to discovery.
+func (s *AnchorStorage) DeleteManualPattern(ctx context.Context, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM behavioral_patterns WHERE id = $1 AND source = $2`,
+		id, types.PatternSourceManual)
+	if err != nil {
+		return fmt.Errorf("failed to delete pattern: %w", postgres.Classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", postgres.Classify(err))
+	}
+	if rowsAffected == 0 {
+		return errcode.Wrap(postgres.ErrNotFound, fmt.Errorf("manual pattern not found: %s", id))
+	}
+
+	return nil
+}
+
+// RetirePattern unassigns a pattern's anchors and archives it with reason.
+// Returns the number of anchors released.
+func (s *AnchorStorage) RetirePattern(ctx context.Context, id uuid.UUID, reason string) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", postgres.Classify(err))
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE semantic_anchors SET pattern_id = NULL WHERE pattern_id = $1`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release anchors: %w", postgres.Classify(err))
+	}
+	released, _ := result.RowsAffected()
+
+	result, err = tx.ExecContext(ctx, `
+		UPDATE behavioral_patterns
+		SET archived_at = $2, archive_reason = $3, updated_at = $2
+		WHERE id = $1 AND archived_at IS NULL`,
+		id, time.Now(), reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive pattern: %w", postgres.Classify(err))
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, errcode.Wrap(postgres.ErrNotFound, fmt.Errorf("active pattern not found: %s", id))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit pattern retirement: %w", postgres.Classify(err))
+	}
+
+	return released, nil
+}
+
+// nullTimeOfDay maps an empty "HH:MM" to NULL for TIME columns
+func nullTimeOfDay(t string) interface{} {
+	if t == "" {
+		return nil
+	}
+	return t
+}
+
+// timeOfDay formats a scanned TIME column ("07:30:00") as "HH:MM"
+func timeOfDay(t sql.NullString) string {
+	if !t.Valid || len(t.String) < 5 {
+		return ""
+	}
+	return t.String[:5]
+}
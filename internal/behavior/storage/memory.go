@@ -0,0 +1,368 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
+)
+
+// MemoryAnchorStorage is an in-memory AnchorRepository for unit tests. It mirrors
+// the PostgreSQL semantics callers rely on (canonical distance pair order,
+// unassigned-only anchor listings, candidate pair filters) without a database.
+type MemoryAnchorStorage struct {
+	mu        sync.RWMutex
+	anchors   map[uuid.UUID]*types.SemanticAnchor
+	distances map[[2]uuid.UUID]*types.AnchorDistance
+	patterns  map[uuid.UUID]*types.BehavioralPattern
+	topology  *ontology.Topology
+	priority  PairPriority
+}
+
+// NewMemoryAnchorStorage creates an empty in-memory anchor repository
+func NewMemoryAnchorStorage() *MemoryAnchorStorage {
+	return &MemoryAnchorStorage{
+		anchors:   make(map[uuid.UUID]*types.SemanticAnchor),
+		distances: make(map[[2]uuid.UUID]*types.AnchorDistance),
+		patterns:  make(map[uuid.UUID]*types.BehavioralPattern),
+		topology:  ontology.DefaultTopology(),
+	}
+}
+
+// SetTopology sets the home topology whose adjacent rooms are paired for distance computation
+func (s *MemoryAnchorStorage) SetTopology(topology *ontology.Topology) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topology = topology
+}
+
+// SetPairPriority sets how pairs needing distances are ordered. There is no
+// re-learning queue in memory, so RelearnWeight is ignored.
+func (s *MemoryAnchorStorage) SetPairPriority(priority PairPriority) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priority = priority
+}
+
+// CreateAnchor stores a copy of the anchor
+func (s *MemoryAnchorStorage) CreateAnchor(ctx context.Context, anchor *types.SemanticAnchor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.insertAnchor(anchor)
+}
+
+// CreateAnchors stores copies of all anchors, or none if any already exists
+func (s *MemoryAnchorStorage) CreateAnchors(ctx context.Context, anchors []*types.SemanticAnchor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, anchor := range anchors {
+		if _, exists := s.anchors[anchor.ID]; exists && anchor.ID != uuid.Nil {
+			return fmt.Errorf("failed to insert anchors: duplicate id %s", anchor.ID)
+		}
+	}
+	for _, anchor := range anchors {
+		if err := s.insertAnchor(anchor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryAnchorStorage) insertAnchor(anchor *types.SemanticAnchor) error {
+	if anchor.ID == uuid.Nil {
+		anchor.ID = uuid.New()
+	}
+	if anchor.CreatedAt.IsZero() {
+		anchor.CreatedAt = time.Now()
+	}
+	if _, exists := s.anchors[anchor.ID]; exists {
+		return fmt.Errorf("failed to insert anchor: duplicate id %s", anchor.ID)
+	}
+
+	stored := *anchor
+	s.anchors[anchor.ID] = &stored
+	return nil
+}
+
+// GetAnchor retrieves a copy of an anchor by ID
+func (s *MemoryAnchorStorage) GetAnchor(ctx context.Context, id uuid.UUID) (*types.SemanticAnchor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	anchor, ok := s.anchors[id]
+	if !ok {
+		return nil, fmt.Errorf("anchor not found: %s", id)
+	}
+	copied := *anchor
+	return &copied, nil
+}
+
+// GetAnchorsByIDs retrieves the anchors that exist among ids, oldest first
+func (s *MemoryAnchorStorage) GetAnchorsByIDs(ctx context.Context, ids []uuid.UUID) ([]*types.SemanticAnchor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	anchors := []*types.SemanticAnchor{}
+	for _, id := range ids {
+		if anchor, ok := s.anchors[id]; ok {
+			copied := *anchor
+			anchors = append(anchors, &copied)
+		}
+	}
+	sortByTimestamp(anchors)
+	return anchors, nil
+}
+
+// GetAnchorTimestamps returns the timestamps of the anchors that exist among ids
+func (s *MemoryAnchorStorage) GetAnchorTimestamps(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	timestamps := make(map[uuid.UUID]time.Time, len(ids))
+	for _, id := range ids {
+		if anchor, ok := s.anchors[id]; ok {
+			timestamps[id] = anchor.Timestamp
+		}
+	}
+	return timestamps, nil
+}
+
+// GetAnchorsSince retrieves unassigned anchors at or after since, oldest first
+func (s *MemoryAnchorStorage) GetAnchorsSince(ctx context.Context, since time.Time) ([]*types.SemanticAnchor, error) {
+	return s.unassignedAnchors(func(a *types.SemanticAnchor) bool {
+		return !a.Timestamp.Before(since)
+	}), nil
+}
+
+// GetAnchorsSinceInWindow retrieves unassigned anchors in [windowStart, windowEnd), oldest first
+func (s *MemoryAnchorStorage) GetAnchorsSinceInWindow(ctx context.Context, windowStart, windowEnd time.Time) ([]*types.SemanticAnchor, error) {
+	return s.unassignedAnchors(func(a *types.SemanticAnchor) bool {
+		return !a.Timestamp.Before(windowStart) && a.Timestamp.Before(windowEnd)
+	}), nil
+}
+
+func (s *MemoryAnchorStorage) unassignedAnchors(match func(*types.SemanticAnchor) bool) []*types.SemanticAnchor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var anchors []*types.SemanticAnchor
+	for _, anchor := range s.anchors {
+		if anchor.PatternID == nil && match(anchor) {
+			copied := *anchor
+			anchors = append(anchors, &copied)
+		}
+	}
+	sortByTimestamp(anchors)
+	return anchors
+}
+
+// UpdateAnchorPattern sets an anchor's pattern reference
+func (s *MemoryAnchorStorage) UpdateAnchorPattern(ctx context.Context, anchorID, patternID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if anchor, ok := s.anchors[anchorID]; ok {
+		id := patternID
+		anchor.PatternID = &id
+	}
+	return nil
+}
+
+// GetAnchorsNeedingDistances finds anchor pairs without a stored distance, using
+// the same candidate filters as AnchorStorage: same or adjacent location, less
+// than two hours apart, same day type, and same or neighboring time of day
+func (s *MemoryAnchorStorage) GetAnchorsNeedingDistances(ctx context.Context, limit int) ([][2]uuid.UUID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	anchors := make([]*types.SemanticAnchor, 0, len(s.anchors))
+	for _, anchor := range s.anchors {
+		anchors = append(anchors, anchor)
+	}
+	// Newest first, matching ORDER BY created_at DESC
+	sort.Slice(anchors, func(i, j int) bool {
+		return anchors[i].CreatedAt.After(anchors[j].CreatedAt)
+	})
+
+	type candidate struct {
+		pair  [2]uuid.UUID
+		score float64
+	}
+	var candidates []candidate
+	for _, a1 := range anchors {
+		for _, a2 := range anchors {
+			if a1.ID.String() >= a2.ID.String() {
+				continue
+			}
+			if _, exists := s.distances[[2]uuid.UUID{a1.ID, a2.ID}]; exists {
+				continue
+			}
+			if s.isDistanceCandidate(a1, a2) {
+				candidates = append(candidates, candidate{pair: [2]uuid.UUID{a1.ID, a2.ID}, score: s.priority.score(a1, a2)})
+			}
+		}
+	}
+
+	// Highest priority first; the stable sort keeps creation order for ties
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	var pairs [][2]uuid.UUID
+	for _, c := range candidates[:min(len(candidates), max(limit, 0))] {
+		pairs = append(pairs, c.pair)
+	}
+	return pairs, nil
+}
+
+// CountAnchorsNeedingDistances counts the anchor pairs without a stored distance
+func (s *MemoryAnchorStorage) CountAnchorsNeedingDistances(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, a1 := range s.anchors {
+		for _, a2 := range s.anchors {
+			if a1.ID.String() >= a2.ID.String() {
+				continue
+			}
+			if _, exists := s.distances[[2]uuid.UUID{a1.ID, a2.ID}]; exists {
+				continue
+			}
+			if s.isDistanceCandidate(a1, a2) {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (s *MemoryAnchorStorage) isDistanceCandidate(a1, a2 *types.SemanticAnchor) bool {
+	if a1.Location != a2.Location && !s.topology.IsAdjacent(a1.Location, a2.Location) {
+		return false
+	}
+
+	gap := a1.Timestamp.Sub(a2.Timestamp)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap >= 2*time.Hour {
+		return false
+	}
+
+	if a1.Context["day_type"] != a2.Context["day_type"] {
+		return false
+	}
+
+	tod1, _ := a1.Context["time_of_day"].(string)
+	tod2, _ := a2.Context["time_of_day"].(string)
+	if tod1 == tod2 {
+		return true
+	}
+	neighbors := map[string]string{"morning": "afternoon", "evening": "afternoon"}
+	return neighbors[tod1] == tod2 || neighbors[tod2] == tod1
+}
+
+// StoreDistance upserts a distance in canonical pair order
+func (s *MemoryAnchorStorage) StoreDistance(ctx context.Context, distance *types.AnchorDistance) error {
+	return s.StoreDistances(ctx, []*types.AnchorDistance{distance})
+}
+
+// StoreDistances upserts many distances; the last entry for a pair wins
+func (s *MemoryAnchorStorage) StoreDistances(ctx context.Context, distances []*types.AnchorDistance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, distance := range distances {
+		if distance.ComputedAt.IsZero() {
+			distance.ComputedAt = now
+		}
+		key := distanceKey(distance.Anchor1ID, distance.Anchor2ID)
+		s.distances[key] = &types.AnchorDistance{
+			Anchor1ID:  key[0],
+			Anchor2ID:  key[1],
+			Distance:   distance.Distance,
+			Source:     distance.Source,
+			ComputedAt: distance.ComputedAt,
+		}
+	}
+	return nil
+}
+
+// GetDistance retrieves the stored distance between two anchors, or nil if none
+func (s *MemoryAnchorStorage) GetDistance(ctx context.Context, anchor1ID, anchor2ID uuid.UUID) (*types.AnchorDistance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	distance, ok := s.distances[distanceKey(anchor1ID, anchor2ID)]
+	if !ok {
+		return nil, nil
+	}
+	copied := *distance
+	return &copied, nil
+}
+
+// CreatePattern stores a copy of the pattern, applying the same defaults as AnchorStorage
+func (s *MemoryAnchorStorage) CreatePattern(ctx context.Context, pattern *types.BehavioralPattern) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pattern.ID == uuid.Nil {
+		pattern.ID = uuid.New()
+	}
+	now := time.Now()
+	if pattern.CreatedAt.IsZero() {
+		pattern.CreatedAt = now
+	}
+	if pattern.UpdatedAt.IsZero() {
+		pattern.UpdatedAt = now
+	}
+	if pattern.FirstSeen.IsZero() {
+		pattern.FirstSeen = now
+	}
+	if pattern.LastSeen.IsZero() {
+		pattern.LastSeen = now
+	}
+	if pattern.Weight == 0.0 {
+		pattern.Weight = 0.1
+	}
+
+	stored := *pattern
+	s.patterns[pattern.ID] = &stored
+	return nil
+}
+
+// GetPattern retrieves a copy of a pattern by ID
+func (s *MemoryAnchorStorage) GetPattern(ctx context.Context, id uuid.UUID) (*types.BehavioralPattern, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pattern, ok := s.patterns[id]
+	if !ok {
+		return nil, fmt.Errorf("pattern not found: %s", id)
+	}
+	copied := *pattern
+	return &copied, nil
+}
+
+// distanceKey orders a pair the way anchor_distances stores it
+func distanceKey(anchor1ID, anchor2ID uuid.UUID) [2]uuid.UUID {
+	if anchor1ID.String() > anchor2ID.String() {
+		return [2]uuid.UUID{anchor2ID, anchor1ID}
+	}
+	return [2]uuid.UUID{anchor1ID, anchor2ID}
+}
+
+func sortByTimestamp(anchors []*types.SemanticAnchor) {
+	sort.Slice(anchors, func(i, j int) bool {
+		return anchors[i].Timestamp.Before(anchors[j].Timestamp)
+	})
+}
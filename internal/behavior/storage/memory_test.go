@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestMemoryAnchorStorage(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryAnchorStorage()
+	base := time.Date(2025, 3, 3, 7, 0, 0, 0, time.UTC)
+
+	evening := map[string]interface{}{"time_of_day": "evening", "day_type": "weekday"}
+	morning := map[string]interface{}{"time_of_day": "morning", "day_type": "weekday"}
+	early := &types.SemanticAnchor{Location: "kitchen", Timestamp: base, Context: morning}
+	late := &types.SemanticAnchor{Location: "kitchen", Timestamp: base.Add(30 * time.Minute), Context: evening}
+	require.NoError(t, repo.CreateAnchors(ctx, []*types.SemanticAnchor{late, early}))
+	assert.NotEqual(t, uuid.Nil, early.ID)
+
+	// Listings are oldest first and exclude assigned anchors
+	anchors, err := repo.GetAnchorsSince(ctx, base)
+	require.NoError(t, err)
+	require.Len(t, anchors, 2)
+	assert.Equal(t, early.ID, anchors[0].ID)
+
+	pattern := &types.BehavioralPattern{Name: "breakfast"}
+	require.NoError(t, repo.CreatePattern(ctx, pattern))
+	assert.Equal(t, 0.1, pattern.Weight)
+	require.NoError(t, repo.UpdateAnchorPattern(ctx, early.ID, pattern.ID))
+
+	anchors, err = repo.GetAnchorsSinceInWindow(ctx, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, anchors, 1)
+	assert.Equal(t, late.ID, anchors[0].ID)
+
+	// Returned anchors are copies
+	anchors[0].Location = "garage"
+	stored, err := repo.GetAnchor(ctx, late.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "kitchen", stored.Location)
+
+	// morning/evening are not neighboring times of day
+	pairs, err := repo.GetAnchorsNeedingDistances(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pairs)
+
+	require.NoError(t, repo.StoreDistance(ctx, &types.AnchorDistance{
+		Anchor1ID: late.ID, Anchor2ID: early.ID, Distance: 0.6, Source: "vector",
+	}))
+	distance, err := repo.GetDistance(ctx, early.ID, late.ID)
+	require.NoError(t, err)
+	require.NotNil(t, distance)
+	assert.Equal(t, 0.6, distance.Distance)
+	assert.True(t, distance.Anchor1ID.String() < distance.Anchor2ID.String())
+
+	_, err = repo.GetAnchor(ctx, uuid.New())
+	assert.Error(t, err)
+}
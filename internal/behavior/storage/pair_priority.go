@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+// PairPriority orders the pairs returned by GetAnchorsNeedingDistances so the
+// most valuable distances are computed within the batch limit. A pair scores
+//
+//	1 / (1 + age/RecencyHalfLife)   the age of its newer anchor; newer pairs first
+//	+ WindowWeight                  when both anchors fall in the next clustering window
+//	+ RelearnWeight                 when it matches a pattern queued for re-learning
+//
+// Ties, and the zero value, fall back to anchor creation time, newest first.
+type PairPriority struct {
+	RecencyHalfLife time.Duration    // age at which the recency score halves; 0 disables it
+	Window          time.Duration    // the clustering lookback (anchors since Now-Window)
+	WindowWeight    float64          // 0 disables the window bonus
+	RelearnWeight   float64          // PostgreSQL only: pattern_relearning_queue
+	Now             func() time.Time // reference time (virtual in tests); time.Now when nil
+}
+
+// DefaultPairPriority favors pairs in the clustering window, then queued
+// patterns, then recent pairs
+func DefaultPairPriority(window time.Duration, now func() time.Time) PairPriority {
+	return PairPriority{
+		RecencyHalfLife: 24 * time.Hour,
+		Window:          window,
+		WindowWeight:    2,
+		RelearnWeight:   1,
+		Now:             now,
+	}
+}
+
+func (p PairPriority) enabled() bool {
+	return p.RecencyHalfLife > 0 || (p.Window > 0 && p.WindowWeight != 0) || p.RelearnWeight != 0
+}
+
+func (p PairPriority) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
+// score is the priority of a pair without the re-learning bonus
+func (p PairPriority) score(a1, a2 *types.SemanticAnchor) float64 {
+	now := p.now()
+	newer, older := a1.Timestamp, a2.Timestamp
+	if older.After(newer) {
+		newer, older = older, newer
+	}
+
+	var score float64
+	if p.RecencyHalfLife > 0 {
+		age := max(now.Sub(newer), 0)
+		score += 1 / (1 + float64(age)/float64(p.RecencyHalfLife))
+	}
+	if p.Window > 0 && !older.Before(now.Add(-p.Window)) {
+		score += p.WindowWeight
+	}
+	return score
+}
+
+// orderBy returns the ORDER BY clause for candidate pairs a1/a2, with its
+// arguments numbered from argIndex
+func (p PairPriority) orderBy(argIndex int) (string, []interface{}) {
+	const fallback = "a1.created_at DESC, a2.created_at DESC"
+	if !p.enabled() {
+		return "ORDER BY " + fallback, nil
+	}
+
+	now := p.now()
+	score := "0"
+	var args []interface{}
+	next := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", argIndex+len(args)-1)
+	}
+
+	if p.RecencyHalfLife > 0 {
+		score += fmt.Sprintf(`
+			+ 1.0 / (1.0 + GREATEST(EXTRACT(EPOCH FROM (%s::timestamptz - GREATEST(a1.timestamp, a2.timestamp))), 0) / %s)`,
+			next(now), next(p.RecencyHalfLife.Seconds()))
+	}
+	if p.Window > 0 && p.WindowWeight != 0 {
+		score += fmt.Sprintf(`
+			+ CASE WHEN LEAST(a1.timestamp, a2.timestamp) >= %s THEN %s ELSE 0 END`,
+			next(now.Add(-p.Window)), next(p.WindowWeight))
+	}
+	if p.RelearnWeight != 0 {
+		// Pattern keys as built by the distance agent: location_timeofday_daytype
+		// of both anchors, either order when the locations are equal
+		const key1 = `a1.location || '_' || COALESCE(a1.context->>'time_of_day', 'unknown') || '_' || COALESCE(a1.context->>'day_type', 'unknown')`
+		const key2 = `a2.location || '_' || COALESCE(a2.context->>'time_of_day', 'unknown') || '_' || COALESCE(a2.context->>'day_type', 'unknown')`
+		score += fmt.Sprintf(`
+			+ CASE WHEN EXISTS (
+				SELECT 1 FROM pattern_relearning_queue q
+				WHERE q.pattern_key IN ((%s) || '->' || (%s), (%s) || '->' || (%s))
+			  ) THEN %s ELSE 0 END`,
+			key1, key2, key2, key1, next(p.RelearnWeight))
+	}
+
+	return "ORDER BY (" + score + ") DESC, " + fallback, args
+}
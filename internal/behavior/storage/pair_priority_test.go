@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+func TestPairPriorityScore(t *testing.T) {
+	now := time.Date(2025, 10, 30, 12, 0, 0, 0, time.UTC)
+	p := PairPriority{
+		RecencyHalfLife: 24 * time.Hour,
+		Window:          6 * time.Hour,
+		WindowWeight:    2,
+		Now:             func() time.Time { return now },
+	}
+	anchor := func(age time.Duration) *types.SemanticAnchor {
+		return &types.SemanticAnchor{Timestamp: now.Add(-age)}
+	}
+
+	// Both anchors in the window: full recency plus the window bonus
+	assert.InDelta(t, 3.0, p.score(anchor(0), anchor(time.Hour)), 1e-9)
+	// One anchor before the window: recency of the newer anchor only
+	assert.InDelta(t, 1.0, p.score(anchor(0), anchor(7*time.Hour)), 1e-9)
+	// Recency halves after one half-life
+	assert.InDelta(t, 0.5, p.score(anchor(24*time.Hour), anchor(25*time.Hour)), 1e-9)
+
+	assert.Zero(t, PairPriority{}.score(anchor(0), anchor(0)))
+}
+
+func TestPairPriorityOrderBy(t *testing.T) {
+	clause, args := PairPriority{}.orderBy(3)
+	assert.Equal(t, "ORDER BY a1.created_at DESC, a2.created_at DESC", clause)
+	assert.Empty(t, args)
+
+	now := time.Date(2025, 10, 30, 12, 0, 0, 0, time.UTC)
+	p := DefaultPairPriority(6*time.Hour, func() time.Time { return now })
+	clause, args = p.orderBy(3)
+
+	// Arguments are numbered from argIndex in the order they are passed
+	for _, placeholder := range []string{"$3", "$4", "$5", "$6", "$7"} {
+		assert.Contains(t, clause, placeholder)
+	}
+	assert.NotContains(t, clause, "$8")
+	assert.Equal(t, []interface{}{now, 86400.0, now.Add(-6 * time.Hour), 2.0, 1.0}, args)
+	assert.True(t, strings.HasSuffix(clause, "DESC, a1.created_at DESC, a2.created_at DESC"))
+}
+
+func TestMemoryGetAnchorsNeedingDistances_Priority(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 30, 12, 0, 0, 0, time.UTC)
+	morning := map[string]interface{}{"time_of_day": "morning", "day_type": "weekday"}
+	pair := func(age time.Duration) []*types.SemanticAnchor {
+		return []*types.SemanticAnchor{
+			{Location: "kitchen", Timestamp: now.Add(-age), Context: morning, SemanticEmbedding: pgvector.NewVector(make([]float32, 128))},
+			{Location: "kitchen", Timestamp: now.Add(-age - 10*time.Minute), Context: morning, SemanticEmbedding: pgvector.NewVector(make([]float32, 128))},
+		}
+	}
+
+	repo := NewMemoryAnchorStorage()
+	recent, old := pair(time.Hour), pair(72*time.Hour)
+	// The old pair is created last, so creation order alone would return it first
+	require.NoError(t, repo.CreateAnchors(ctx, recent))
+	require.NoError(t, repo.CreateAnchors(ctx, old))
+	repo.anchors[old[0].ID].CreatedAt = now.Add(time.Minute)
+	repo.anchors[old[1].ID].CreatedAt = now.Add(time.Minute)
+
+	// Only the pairs within two hours of each other are candidates
+	count, err := repo.CountAnchorsNeedingDistances(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	repo.SetPairPriority(DefaultPairPriority(24*time.Hour, func() time.Time { return now }))
+	pairs, err := repo.GetAnchorsNeedingDistances(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, pairs, 1)
+	assert.ElementsMatch(t, []string{recent[0].ID.String(), recent[1].ID.String()},
+		[]string{pairs[0][0].String(), pairs[0][1].String()})
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PurgeFilter selects the data to delete: everything recorded in a location
+// and/or time range. At least one dimension must be set so a purge can never
+// wipe the whole database by accident.
+type PurgeFilter struct {
+	Location string    `json:"location,omitempty"`
+	Since    time.Time `json:"since,omitempty"` // inclusive
+	Until    time.Time `json:"until,omitempty"` // exclusive
+}
+
+// Validate checks that the filter is bounded
+func (f PurgeFilter) Validate() error {
+	if f.Location == "" && f.Since.IsZero() && f.Until.IsZero() {
+		return fmt.Errorf("purge requires a location or a time range")
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Since.Before(f.Until) {
+		return fmt.Errorf("purge since (%s) must be before until (%s)",
+			f.Since.Format(time.RFC3339), f.Until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// where renders the filter as a SQL condition over the given location and
+// timestamp expressions, appending its arguments to args
+func (f PurgeFilter) where(locationExpr, timeExpr string, args []interface{}) (string, []interface{}) {
+	var conditions []string
+	if f.Location != "" {
+		args = append(args, f.Location)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", locationExpr, len(args)))
+	}
+	if !f.Since.IsZero() {
+		args = append(args, f.Since)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", timeExpr, len(args)))
+	}
+	if !f.Until.IsZero() {
+		args = append(args, f.Until)
+		conditions = append(conditions, fmt.Sprintf("%s < $%d", timeExpr, len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// PurgeResult counts the rows removed by a purge
+type PurgeResult struct {
+	Anchors         int64 `json:"anchors"`
+	Distances       int64 `json:"distances"`
+	Observations    int64 `json:"observations"`
+	PatternsDeleted int64 `json:"patterns_deleted"`
+	PatternsResized int64 `json:"patterns_resized"`
+	Episodes        int64 `json:"episodes"`
+	MacroEpisodes   int64 `json:"macro_episodes"`
+	Annotations     int64 `json:"annotations"`
+}
+
+// Purge deletes all anchors and episodes matching the filter in one transaction,
+// along with their distances, learned-distance observations, annotations and the
+// macro-episodes built from them. Patterns left without anchors are deleted and
+// the remaining affected patterns get their cluster size recounted.
+func (s *AnchorStorage) Purge(ctx context.Context, filter PurgeFilter) (*PurgeResult, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &PurgeResult{}
+	if err := purgeAnchors(ctx, tx, filter, result); err != nil {
+		return nil, err
+	}
+	if err := purgeEpisodes(ctx, tx, filter, result); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit purge: %w", err)
+	}
+	return result, nil
+}
+
+// purgeAnchors removes matching anchors and everything derived from them
+func purgeAnchors(ctx context.Context, tx *sql.Tx, filter PurgeFilter, result *PurgeResult) error {
+	where, args := filter.where("location", "timestamp", nil)
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE purge_anchors ON COMMIT DROP AS
+		SELECT id, pattern_id FROM semantic_anchors WHERE `+where, args...); err != nil {
+		return fmt.Errorf("failed to select anchors to purge: %w", err)
+	}
+
+	// References without ON DELETE behavior have to be released first
+	unlink := []string{
+		`UPDATE semantic_anchors SET preceding_anchor_id = NULL
+		 WHERE preceding_anchor_id IN (SELECT id FROM purge_anchors)`,
+		`UPDATE semantic_anchors SET following_anchor_id = NULL
+		 WHERE following_anchor_id IN (SELECT id FROM purge_anchors)`,
+		`UPDATE anchor_interpretations SET spawned_anchor_id = NULL
+		 WHERE spawned_anchor_id IN (SELECT id FROM purge_anchors)`,
+	}
+	for _, query := range unlink {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to unlink purged anchors: %w", err)
+		}
+	}
+
+	var err error
+	if result.Observations, err = execCount(ctx, tx, `
+		DELETE FROM pattern_observations
+		WHERE anchor1_id IN (SELECT id FROM purge_anchors)
+		   OR anchor2_id IN (SELECT id FROM purge_anchors)`); err != nil {
+		return fmt.Errorf("failed to delete observations: %w", err)
+	}
+	if result.Distances, err = execCount(ctx, tx, `
+		DELETE FROM anchor_distances
+		WHERE anchor1_id IN (SELECT id FROM purge_anchors)
+		   OR anchor2_id IN (SELECT id FROM purge_anchors)`); err != nil {
+		return fmt.Errorf("failed to delete distances: %w", err)
+	}
+	if result.Anchors, err = execCount(ctx, tx, `
+		DELETE FROM semantic_anchors WHERE id IN (SELECT id FROM purge_anchors)`); err != nil {
+		return fmt.Errorf("failed to delete anchors: %w", err)
+	}
+
+	// Patterns that lost all their anchors go; merged patterns pointing at them are detached
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE purge_patterns ON COMMIT DROP AS
+		SELECT DISTINCT p.pattern_id AS id
+		FROM purge_anchors p
+		WHERE p.pattern_id IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM semantic_anchors a WHERE a.pattern_id = p.pattern_id)`); err != nil {
+		return fmt.Errorf("failed to select emptied patterns: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE behavioral_patterns SET merged_into = NULL
+		WHERE merged_into IN (SELECT id FROM purge_patterns)`); err != nil {
+		return fmt.Errorf("failed to detach merged patterns: %w", err)
+	}
+	if result.PatternsDeleted, err = execCount(ctx, tx, `
+		DELETE FROM behavioral_patterns WHERE id IN (SELECT id FROM purge_patterns)`); err != nil {
+		return fmt.Errorf("failed to delete emptied patterns: %w", err)
+	}
+	if result.PatternsResized, err = execCount(ctx, tx, `
+		UPDATE behavioral_patterns bp
+		SET cluster_size = (SELECT COUNT(*) FROM semantic_anchors a WHERE a.pattern_id = bp.id),
+			updated_at = NOW()
+		WHERE bp.id IN (SELECT DISTINCT pattern_id FROM purge_anchors WHERE pattern_id IS NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to resize patterns: %w", err)
+	}
+
+	return nil
+}
+
+// purgeEpisodes removes matching micro-episodes, the macro-episodes built from
+// them, and annotations on either
+func purgeEpisodes(ctx context.Context, tx *sql.Tx, filter PurgeFilter, result *PurgeResult) error {
+	where, args := filter.where("location", "started_at_text::timestamptz", nil)
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE purge_episodes ON COMMIT DROP AS
+		SELECT id FROM behavioral_episodes WHERE `+where, args...); err != nil {
+		return fmt.Errorf("failed to select episodes to purge: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TEMP TABLE purge_macro_episodes ON COMMIT DROP AS
+		SELECT id FROM macro_episodes
+		WHERE micro_episode_ids && ARRAY(SELECT id FROM purge_episodes)`); err != nil {
+		return fmt.Errorf("failed to select macro-episodes to purge: %w", err)
+	}
+
+	var err error
+	if result.Annotations, err = execCount(ctx, tx, `
+		DELETE FROM episode_annotations
+		WHERE episode_id IN (SELECT id FROM purge_episodes)
+		   OR episode_id IN (SELECT id FROM purge_macro_episodes)
+		   OR related_episode_ids && ARRAY(SELECT id FROM purge_episodes)`); err != nil {
+		return fmt.Errorf("failed to delete annotations: %w", err)
+	}
+	if result.MacroEpisodes, err = execCount(ctx, tx, `
+		DELETE FROM macro_episodes WHERE id IN (SELECT id FROM purge_macro_episodes)`); err != nil {
+		return fmt.Errorf("failed to delete macro-episodes: %w", err)
+	}
+	if result.Episodes, err = execCount(ctx, tx, `
+		DELETE FROM behavioral_episodes WHERE id IN (SELECT id FROM purge_episodes)`); err != nil {
+		return fmt.Errorf("failed to delete episodes: %w", err)
+	}
+
+	return nil
+}
+
+func execCount(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
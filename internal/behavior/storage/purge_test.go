@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeFilterValidate(t *testing.T) {
+	since := time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)
+	until := since.Add(48 * time.Hour)
+
+	assert.Error(t, PurgeFilter{}.Validate(), "unbounded purge must be rejected")
+	assert.Error(t, PurgeFilter{Since: until, Until: since}.Validate())
+	assert.NoError(t, PurgeFilter{Location: "guest_room"}.Validate())
+	assert.NoError(t, PurgeFilter{Since: since, Until: until}.Validate())
+}
+
+func TestPurgeFilterWhere(t *testing.T) {
+	since := time.Date(2025, 3, 1, 18, 0, 0, 0, time.UTC)
+	filter := PurgeFilter{Location: "guest_room", Since: since}
+
+	where, args := filter.where("location", "timestamp", nil)
+	assert.Equal(t, "location = $1 AND timestamp >= $2", where)
+	assert.Equal(t, []interface{}{"guest_room", since}, args)
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// llmDistanceSources are distance sources judged from anchor context by the
+// LLM rather than derived from embeddings, so they survive re-embedding
+var llmDistanceSources = []string{"llm", "llm_verify", "llm_seed"}
+
+// CountAnchorsBelowEmbeddingVersion counts anchors embedded before version
+func (s *AnchorStorage) CountAnchorsBelowEmbeddingVersion(ctx context.Context, version int) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM semantic_anchors WHERE embedding_version < $1`, version).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count outdated anchors: %w", postgres.Classify(err))
+	}
+	return count, nil
+}
+
+// GetAnchorsBelowEmbeddingVersion returns up to limit anchors embedded before
+// version with IDs after the given one, in ID order, for paging through them
+func (s *AnchorStorage) GetAnchorsBelowEmbeddingVersion(ctx context.Context, version int, after uuid.UUID, limit int) ([]*types.SemanticAnchor, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id FROM semantic_anchors
+		WHERE embedding_version < $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`, version, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outdated anchors: %w", postgres.Classify(err))
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan anchor id: %w", postgres.Classify(err))
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating anchor ids: %w", postgres.Classify(err))
+	}
+
+	return s.GetAnchorsByIDs(ctx, ids)
+}
+
+// UpdateAnchorEmbedding replaces an anchor's embedding and deletes the
+// distances derived from the old one, which the distance agent then
+// recomputes. LLM distances are kept. Returns the number of deleted distances.
+func (s *AnchorStorage) UpdateAnchorEmbedding(ctx context.Context, id uuid.UUID, embedding pgvector.Vector, version int) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", postgres.Classify(err))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE semantic_anchors SET semantic_embedding = $2, embedding_version = $3 WHERE id = $1`,
+		id, embedding, version); err != nil {
+		return 0, fmt.Errorf("failed to update anchor embedding: %w", postgres.Classify(err))
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM anchor_distances
+		WHERE (anchor1_id = $1 OR anchor2_id = $1)
+		  AND source <> ALL($2)
+	`, id, pq.Array(llmDistanceSources))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete anchor distances: %w", postgres.Classify(err))
+	}
+	deleted, _ := result.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit anchor embedding: %w", postgres.Classify(err))
+	}
+	return deleted, nil
+}
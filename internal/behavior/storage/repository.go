@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/types"
+)
+
+// AnchorRepository is the anchor, distance and pattern storage used by the
+// pattern discovery agents. AnchorStorage implements it on PostgreSQL and
+// MemoryAnchorStorage in memory for unit tests.
+type AnchorRepository interface {
+	CreateAnchor(ctx context.Context, anchor *types.SemanticAnchor) error
+	CreateAnchors(ctx context.Context, anchors []*types.SemanticAnchor) error
+	GetAnchor(ctx context.Context, id uuid.UUID) (*types.SemanticAnchor, error)
+	GetAnchorsByIDs(ctx context.Context, ids []uuid.UUID) ([]*types.SemanticAnchor, error)
+	GetAnchorsSince(ctx context.Context, since time.Time) ([]*types.SemanticAnchor, error)
+	GetAnchorsSinceInWindow(ctx context.Context, windowStart, windowEnd time.Time) ([]*types.SemanticAnchor, error)
+	UpdateAnchorPattern(ctx context.Context, anchorID, patternID uuid.UUID) error
+
+	GetAnchorsNeedingDistances(ctx context.Context, limit int) ([][2]uuid.UUID, error)
+	CountAnchorsNeedingDistances(ctx context.Context) (int, error)
+	StoreDistance(ctx context.Context, distance *types.AnchorDistance) error
+	StoreDistances(ctx context.Context, distances []*types.AnchorDistance) error
+	GetDistance(ctx context.Context, anchor1ID, anchor2ID uuid.UUID) (*types.AnchorDistance, error)
+
+	CreatePattern(ctx context.Context, pattern *types.BehavioralPattern) error
+	GetPattern(ctx context.Context, id uuid.UUID) (*types.BehavioralPattern, error)
+}
+
+var (
+	_ AnchorRepository = (*AnchorStorage)(nil)
+	_ AnchorRepository = (*MemoryAnchorStorage)(nil)
+)
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// AnchorContextIndexFields are the anchor context fields read in SQL
+// (distance candidates, pattern occupants, observer views); they stay in
+// clear when anchor context is encrypted
+var AnchorContextIndexFields = []string{
+	"time_of_day",
+	"day_type",
+	"season",
+	"household_mode",
+	"occupant",
+}
+
+// marshalContext encodes the context of anchor id for the context column,
+// sealed when encryption is enabled
+func (s *AnchorStorage) marshalContext(id uuid.UUID, context map[string]interface{}) ([]byte, error) {
+	raw, err := json.Marshal(context)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.sealer.Seal(raw, AnchorContextIndexFields, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt context: %w", err)
+	}
+	return sealed, nil
+}
+
+// unmarshalContext decodes the context column of anchor id, decrypting
+// sealed context
+func (s *AnchorStorage) unmarshalContext(id uuid.UUID, raw []byte, context *map[string]interface{}) error {
+	opened, err := s.sealer.Open(raw, id.String())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(opened, context)
+}
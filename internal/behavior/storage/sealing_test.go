@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/saaga0h/jeeves-platform/pkg/encryption"
+)
+
+func testSealer(t *testing.T, fill byte) *encryption.Sealer {
+	sealer, err := encryption.NewSealerFromKey(bytes.Repeat([]byte{fill}, 32))
+	require.NoError(t, err)
+	return sealer
+}
+
+func TestMarshalContext_Sealed(t *testing.T) {
+	s := NewAnchorStorage(nil)
+	s.SetSealer(testSealer(t, 1))
+
+	context := map[string]interface{}{
+		"time_of_day":    "morning",
+		"day_type":       "weekday",
+		"household_mode": "active",
+		"weather":        map[string]interface{}{"condition": "rain"},
+		"media_title":    "Morning News",
+	}
+
+	id := uuid.New()
+	raw, err := s.marshalContext(id, context)
+	require.NoError(t, err)
+
+	var stored map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &stored))
+	assert.Equal(t, "morning", stored["time_of_day"])
+	assert.Equal(t, "weekday", stored["day_type"])
+	assert.Contains(t, stored, encryption.SealedField)
+	assert.NotContains(t, stored, "weather")
+	assert.NotContains(t, string(raw), "Morning News")
+
+	var opened map[string]interface{}
+	require.NoError(t, s.unmarshalContext(id, raw, &opened))
+	assert.Equal(t, context, opened)
+
+	// Context copied onto another anchor does not open
+	assert.Error(t, s.unmarshalContext(uuid.New(), raw, &opened))
+}
+
+func TestUnmarshalContext_ClearFieldsOverlay(t *testing.T) {
+	s := NewAnchorStorage(nil)
+	s.SetSealer(testSealer(t, 1))
+
+	id := uuid.New()
+	raw, err := s.marshalContext(id, map[string]interface{}{"time_of_day": "morning", "note": "private"})
+	require.NoError(t, err)
+
+	// An update in SQL (jsonb_set) touches only the clear copy
+	var stored map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(raw, &stored))
+	stored["time_of_day"] = json.RawMessage(`"evening"`)
+	raw, err = json.Marshal(stored)
+	require.NoError(t, err)
+
+	var opened map[string]interface{}
+	require.NoError(t, s.unmarshalContext(id, raw, &opened))
+	assert.Equal(t, "evening", opened["time_of_day"])
+	assert.Equal(t, "private", opened["note"])
+}
+
+func TestUnmarshalContext_Unsealed(t *testing.T) {
+	raw := []byte(`{"time_of_day":"night","note":"plain"}`)
+
+	for name, sealer := range map[string]*encryption.Sealer{"no key": nil, "key": testSealer(t, 1)} {
+		s := NewAnchorStorage(nil)
+		s.SetSealer(sealer)
+
+		var opened map[string]interface{}
+		require.NoError(t, s.unmarshalContext(uuid.New(), raw, &opened), name)
+		assert.Equal(t, "plain", opened["note"], name)
+	}
+}
+
+func TestUnmarshalContext_WrongKey(t *testing.T) {
+	writer := NewAnchorStorage(nil)
+	writer.SetSealer(testSealer(t, 1))
+	id := uuid.New()
+	raw, err := writer.marshalContext(id, map[string]interface{}{"note": "private"})
+	require.NoError(t, err)
+
+	for name, sealer := range map[string]*encryption.Sealer{"no key": nil, "other key": testSealer(t, 2)} {
+		reader := NewAnchorStorage(nil)
+		reader.SetSealer(sealer)
+
+		var opened map[string]interface{}
+		assert.Error(t, reader.unmarshalContext(id, raw, &opened), name)
+	}
+}
@@ -0,0 +1,186 @@
+package behavior
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+const (
+	// timersKey is a Redis sorted set of pending episode timers scored by
+	// due time (Unix ms, virtual time in test mode)
+	timersKey = "behavior:timers"
+
+	// timerPollInterval is how often (real time) due timers are fired
+	timerPollInterval = time.Second
+
+	// timerRetryDelay is how long (agent time) a timer whose check failed
+	// waits before firing again
+	timerRetryDelay = time.Minute
+)
+
+// Timer kinds
+const (
+	timerDelayedCheck      = "delayed_check"
+	timerLightBasedClosure = "light_based_closure"
+)
+
+// episodeTimer is a pending delayed closure check for an open episode.
+// The member is identical for the same episode and kind, so rescheduling
+// moves the due time instead of adding a second timer.
+type episodeTimer struct {
+	Kind      string `json:"kind"`
+	Location  string `json:"location"`
+	EpisodeID string `json:"episode_id"`
+}
+
+// scheduleTimer persists a timer that fires delay from now on the agent's clock
+func (a *Agent) scheduleTimer(ctx context.Context, kind, location string, delay time.Duration) {
+	a.stateMux.RLock()
+	episodeID := a.activeEpisodes[location]
+	a.stateMux.RUnlock()
+
+	member, _ := json.Marshal(episodeTimer{Kind: kind, Location: location, EpisodeID: episodeID})
+	due := a.timeManager.Now().Add(delay)
+	if err := a.redis.ZAdd(ctx, timersKey, float64(due.UnixMilli()), string(member)); err != nil {
+		a.logger.Error("Failed to schedule episode timer",
+			"kind", kind,
+			"location", location,
+			"error", err)
+		return
+	}
+
+	a.logger.Debug("Episode timer scheduled",
+		"kind", kind,
+		"location", location,
+		"delay", delay,
+		"due", due.Format(time.RFC3339))
+}
+
+// runTimers fires due timers until ctx is cancelled. Timers left in Redis by
+// a previous run fire on the first poll.
+func (a *Agent) runTimers(ctx context.Context) {
+	ticker := time.NewTicker(timerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.fireDueTimers(ctx)
+		}
+	}
+}
+
+// fireDueTimers runs every timer due at the current (virtual) time. A timer
+// is removed only once its check has run, so one interrupted by a crash
+// fires again after the restart; a check that fails is retried after
+// timerRetryDelay.
+func (a *Agent) fireDueTimers(ctx context.Context) {
+	now := a.timeManager.Now()
+	members, err := a.redis.ZRangeByScoreWithScores(ctx, timersKey, 0, float64(now.UnixMilli()))
+	if err != nil {
+		a.logger.Error("Failed to read due episode timers", "error", err)
+		return
+	}
+
+	for _, member := range members {
+		var timer episodeTimer
+		if err := json.Unmarshal([]byte(member.Member), &timer); err != nil {
+			a.logger.Warn("Dropping malformed episode timer", "member", member.Member)
+			a.removeTimer(ctx, member)
+			continue
+		}
+
+		if err := a.fireTimer(ctx, timer, time.UnixMilli(int64(member.Score))); err != nil {
+			retry := now.Add(timerRetryDelay)
+			a.logger.Warn("Episode timer check failed, will retry",
+				"kind", timer.Kind,
+				"location", timer.Location,
+				"retry_at", retry.Format(time.RFC3339),
+				"error", err)
+			if err := a.redis.ZAdd(ctx, timersKey, float64(retry.UnixMilli()), member.Member); err != nil {
+				a.logger.Error("Failed to reschedule episode timer", "kind", timer.Kind, "location", timer.Location, "error", err)
+			}
+			continue
+		}
+		a.removeTimer(ctx, member)
+	}
+}
+
+// fireTimer runs the check of a due timer
+func (a *Agent) fireTimer(ctx context.Context, timer episodeTimer, scheduledFor time.Time) error {
+	current, err := a.restoreTimerEpisode(ctx, timer)
+	if err != nil || !current {
+		return err
+	}
+
+	switch timer.Kind {
+	case timerDelayedCheck:
+		return a.runDelayedCheck(timer.Location, scheduledFor)
+	case timerLightBasedClosure:
+		return a.runLightBasedClosure(timer.Location, scheduledFor)
+	default:
+		a.logger.Warn("Dropping episode timer of unknown kind", "kind", timer.Kind)
+		return nil
+	}
+}
+
+// removeTimer deletes a fired timer unless it was rescheduled while its
+// check ran
+func (a *Agent) removeTimer(ctx context.Context, member redis.ZMember) {
+	if _, err := a.redis.CompareAndZRem(ctx, timersKey, member.Member, member.Score); err != nil {
+		a.logger.Error("Failed to remove fired episode timer", "member", member.Member, "error", err)
+	}
+}
+
+// restoreTimerEpisode reports whether the timer's episode is still the open
+// episode for its location. After a restart the episode is no longer tracked
+// in memory; it is adopted again if it is still open in Postgres.
+func (a *Agent) restoreTimerEpisode(ctx context.Context, timer episodeTimer) (bool, error) {
+	a.stateMux.RLock()
+	current, tracked := a.activeEpisodes[timer.Location]
+	a.stateMux.RUnlock()
+
+	if tracked {
+		return timer.EpisodeID == "" || current == timer.EpisodeID, nil
+	}
+	if timer.EpisodeID == "" {
+		return false, nil
+	}
+
+	var open bool
+	err := a.pgClient.QueryRow(ctx,
+		"SELECT jsonld->>'jeeves:endedAt' IS NULL FROM behavioral_episodes WHERE id = $1",
+		timer.EpisodeID,
+	).Scan(&open)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up episode %s: %w", timer.EpisodeID, err)
+	}
+	if !open {
+		return false, nil
+	}
+
+	a.stateMux.Lock()
+	if _, tracked := a.activeEpisodes[timer.Location]; tracked {
+		a.stateMux.Unlock()
+		return false, nil
+	}
+	a.activeEpisodes[timer.Location] = timer.EpisodeID
+	a.stateMux.Unlock()
+
+	a.logger.Info("Restored open episode from persisted timer",
+		"location", timer.Location,
+		"id", timer.EpisodeID,
+		"kind", timer.Kind)
+	return true, nil
+}
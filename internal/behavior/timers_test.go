@@ -0,0 +1,186 @@
+package behavior
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// fakeTimerRedis holds sorted sets; every other sorted set read is empty
+type fakeTimerRedis struct {
+	redis.Client
+	sets map[string]map[string]float64
+}
+
+func newFakeTimerRedis() *fakeTimerRedis {
+	return &fakeTimerRedis{sets: make(map[string]map[string]float64)}
+}
+
+func (r *fakeTimerRedis) ZAdd(ctx context.Context, key string, score float64, member interface{}) error {
+	if r.sets[key] == nil {
+		r.sets[key] = make(map[string]float64)
+	}
+	r.sets[key][member.(string)] = score
+	return nil
+}
+
+func (r *fakeTimerRedis) ZRangeByScoreWithScores(ctx context.Context, key string, min, max float64) ([]redis.ZMember, error) {
+	var members []redis.ZMember
+	for member, score := range r.sets[key] {
+		if score >= min && score <= max {
+			members = append(members, redis.ZMember{Member: member, Score: score})
+		}
+	}
+	return members, nil
+}
+
+func (r *fakeTimerRedis) ZRevRangeByScoreWithScores(ctx context.Context, key string, max, min float64, offset, count int64) ([]redis.ZMember, error) {
+	return nil, nil
+}
+
+func (r *fakeTimerRedis) CompareAndZRem(ctx context.Context, key string, member string, score float64) (bool, error) {
+	if current, ok := r.sets[key][member]; !ok || current != score {
+		return false, nil
+	}
+	delete(r.sets[key], member)
+	return true, nil
+}
+
+// newTimerTestAgent returns an agent with an open kitchen episode and a
+// light-based closure timer for it that is already due
+func newTimerTestAgent(db *fakeDB) (*Agent, *fakeTimerRedis, string) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	client := newFakeTimerRedis()
+	agent := &Agent{
+		redis:              client,
+		pgClient:           db.client(),
+		logger:             logger,
+		timeManager:        clock.NewTimeManager(logger),
+		activeEpisodes:     map[string]string{"kitchen": "episode-1"},
+		lastLightState:     map[string]string{"kitchen": "off"},
+		lastEpisodeEndTime: map[string]time.Time{},
+	}
+
+	member, _ := json.Marshal(episodeTimer{Kind: timerLightBasedClosure, Location: "kitchen", EpisodeID: "episode-1"})
+	due := agent.timeManager.Now().Add(-time.Second)
+	client.ZAdd(context.Background(), timersKey, float64(due.UnixMilli()), string(member))
+	return agent, client, string(member)
+}
+
+func TestFireDueTimers_RemovesAfterCheck(t *testing.T) {
+	db := &fakeDB{}
+	agent, client, member := newTimerTestAgent(db)
+
+	agent.fireDueTimers(context.Background())
+
+	if _, open := agent.activeEpisodes["kitchen"]; open {
+		t.Error("Expected the episode to be closed")
+	}
+	if len(db.executed("UPDATE behavioral_episodes")) != 1 {
+		t.Error("Expected the episode end to be stored")
+	}
+	if _, pending := client.sets[timersKey][member]; pending {
+		t.Error("Expected the fired timer to be removed")
+	}
+}
+
+func TestFireDueTimers_RetriesFailedCheck(t *testing.T) {
+	db := &fakeDB{}
+	db.fail("UPDATE behavioral_episodes", errors.New("connection refused"))
+	agent, client, member := newTimerTestAgent(db)
+	before := agent.timeManager.Now()
+
+	agent.fireDueTimers(context.Background())
+
+	if _, open := agent.activeEpisodes["kitchen"]; !open {
+		t.Error("Expected the episode to stay open")
+	}
+	score, pending := client.sets[timersKey][member]
+	if !pending {
+		t.Fatal("Expected the timer to be kept for a retry")
+	}
+	if retry := time.UnixMilli(int64(score)); retry.Before(before.Add(timerRetryDelay).Truncate(time.Millisecond)) {
+		t.Errorf("Expected a retry at least %s later, got %s", timerRetryDelay, retry.Sub(before))
+	}
+
+	// The retry succeeds once the database is back
+	db.rules = nil
+	client.sets[timersKey][member] = float64(before.UnixMilli())
+	agent.fireDueTimers(context.Background())
+
+	if _, open := agent.activeEpisodes["kitchen"]; open {
+		t.Error("Expected the retried check to close the episode")
+	}
+	if _, pending := client.sets[timersKey][member]; pending {
+		t.Error("Expected the timer to be removed after the retry")
+	}
+}
+
+func TestRemoveTimer_KeepsRescheduledTimer(t *testing.T) {
+	db := &fakeDB{}
+	agent, client, member := newTimerTestAgent(db)
+
+	fired := redis.ZMember{Member: member, Score: client.sets[timersKey][member]}
+	later := fired.Score + float64(time.Minute.Milliseconds())
+	client.sets[timersKey][member] = later // rescheduled while the check ran
+
+	agent.removeTimer(context.Background(), fired)
+
+	if score, pending := client.sets[timersKey][member]; !pending || score != later {
+		t.Errorf("Expected the rescheduled timer to stay due at %v, got %v (pending %v)", later, score, pending)
+	}
+}
+
+func TestFireDueTimers_DropsStaleTimers(t *testing.T) {
+	tests := []struct {
+		name   string
+		member string
+	}{
+		{"malformed", "not json"},
+		{"other episode", `{"kind":"light_based_closure","location":"kitchen","episode_id":"episode-0"}`},
+		{"unknown kind", `{"kind":"reminder","location":"kitchen","episode_id":"episode-1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{}
+			agent, client, member := newTimerTestAgent(db)
+			delete(client.sets[timersKey], member)
+			client.ZAdd(context.Background(), timersKey, float64(agent.timeManager.Now().Add(-time.Second).UnixMilli()), tt.member)
+
+			agent.fireDueTimers(context.Background())
+
+			if len(client.sets[timersKey]) != 0 {
+				t.Errorf("Expected the timer to be dropped, got %v", client.sets[timersKey])
+			}
+			if _, open := agent.activeEpisodes["kitchen"]; !open {
+				t.Error("Expected the episode to stay open")
+			}
+		})
+	}
+}
+
+func TestFireDueTimers_RestoresEpisodeAfterRestart(t *testing.T) {
+	db := &fakeDB{}
+	db.on("FROM behavioral_episodes WHERE id", []string{"open"}, []driver.Value{true})
+	agent, client, member := newTimerTestAgent(db)
+	agent.activeEpisodes = map[string]string{} // restarted
+	agent.lastLightState = map[string]string{}
+
+	agent.fireDueTimers(context.Background())
+
+	if len(db.executed("UPDATE behavioral_episodes")) != 1 {
+		t.Error("Expected the restored episode to be closed")
+	}
+	if _, pending := client.sets[timersKey][member]; pending {
+		t.Error("Expected the fired timer to be removed")
+	}
+}
@@ -13,7 +13,9 @@ type SemanticAnchor struct {
 	ID                 uuid.UUID              `json:"id"`
 	Timestamp          time.Time              `json:"timestamp"`
 	Location           string                 `json:"location"`
+	Occupant           *string                `json:"occupant,omitempty"` // attributed from presence signals, nil = unknown
 	SemanticEmbedding  pgvector.Vector        `json:"semantic_embedding"` // 128-dimensional vector
+	EmbeddingVersion   int                    `json:"embedding_version"`  // embedding.Version that generated it, 0 = unknown
 	Context            map[string]interface{} `json:"context"`
 	Signals            []ActivitySignal       `json:"signals"`
 	DurationMinutes    *int                   `json:"duration_minutes,omitempty"`
@@ -47,7 +49,8 @@ type ActivityInterpretation struct {
 }
 
 // BehavioralPattern represents a discovered pattern with weight-based ranking.
-// Weight starts at 0.1 and only increases through successful predictions.
+// Weight starts at 0.1 and increases through successful predictions; lifecycle
+// maintenance decays it back toward 0.1 for patterns that stop being observed.
 type BehavioralPattern struct {
 	ID                     uuid.UUID              `json:"id"`
 	Name                   string                 `json:"name"`
@@ -67,10 +70,29 @@ type BehavioralPattern struct {
 	TypicalDurationMinutes *int                   `json:"typical_duration_minutes,omitempty"` // Expected duration
 	Context                map[string]interface{} `json:"context,omitempty"`                 // Typical context (deprecated)
 	DominantContext        map[string]interface{} `json:"dominant_context,omitempty"`        // Dominant context from cluster
+	Source                 string                 `json:"source"`                             // PatternSourceDiscovered or PatternSourceManual
+	WindowStart            string                 `json:"window_start,omitempty"`             // "HH:MM" local, manual patterns
+	WindowEnd              string                 `json:"window_end,omitempty"`               // "HH:MM" local, manual patterns
+	Interpretation         *PatternInterpretation `json:"interpretation,omitempty"`           // LLM reading of the cluster, nil for manual patterns
 	CreatedAt              time.Time              `json:"created_at"`
 	UpdatedAt              time.Time              `json:"updated_at"`
 }
 
+// PatternInterpretation is the LLM's account of why a cluster is a pattern
+type PatternInterpretation struct {
+	Model              string    `json:"model,omitempty"`
+	Confidence         float64   `json:"confidence"`
+	KeyCharacteristics []string  `json:"key_characteristics,omitempty"`
+	Reasoning          string    `json:"reasoning,omitempty"`
+	InterpretedAt      time.Time `json:"interpreted_at"`
+}
+
+// Pattern sources
+const (
+	PatternSourceDiscovered = "discovered" // found by clustering anchors
+	PatternSourceManual     = "manual"     // defined by a user through the API
+)
+
 // AnchorDistance represents a pre-computed semantic distance between two anchors.
 type AnchorDistance struct {
 	Anchor1ID  uuid.UUID `json:"anchor1_id"`
@@ -91,3 +113,37 @@ type LearnedDistance struct {
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 }
+
+// PatternTransition is one observed move between consecutive anchors of a pattern.
+// Transitions are the basis for next-activity predictions.
+type PatternTransition struct {
+	PatternID       uuid.UUID `json:"pattern_id"`
+	PatternName     string    `json:"pattern_name"`
+	PatternType     string    `json:"pattern_type,omitempty"`
+	PatternWeight   float64   `json:"pattern_weight"`
+	Predictions     int       `json:"predictions"`
+	Acceptances     int       `json:"acceptances"`
+	FromLocation    string    `json:"from_location"`
+	ToLocation      string    `json:"to_location"`
+	TimeOfDay       string    `json:"time_of_day,omitempty"`      // context of the from anchor
+	GapMinutes      float64   `json:"gap_minutes"`                // time between the two anchors
+	DurationMinutes *int      `json:"duration_minutes,omitempty"` // duration at the destination, when known
+}
+
+// PatternCentroid is the mean semantic embedding of a pattern's anchors
+type PatternCentroid struct {
+	PatternID   uuid.UUID       `json:"pattern_id"`
+	Centroid    pgvector.Vector `json:"centroid"`
+	AnchorCount int             `json:"anchor_count"`
+	Occupant    string          `json:"occupant,omitempty"` // pattern's shared occupant, "" for household
+}
+
+// IsSleeping reports whether the anchor was created during a detected sleep period
+func (a *SemanticAnchor) IsSleeping() bool {
+	for _, signal := range a.Signals {
+		if signal.Type == "sleep" {
+			return true
+		}
+	}
+	return false
+}
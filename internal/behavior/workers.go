@@ -0,0 +1,174 @@
+package behavior
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/progress"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// runLocations runs fn for every location on at most cfg.ConsolidationWorkers
+// goroutines and returns the summed counts. Each finished location is
+// reported as progress of phase; failures are logged and do not stop the
+// other locations. Locations not yet started when ctx is cancelled are skipped.
+func (a *Agent) runLocations(ctx context.Context, phase string, locations []string, fn func(ctx context.Context, location string) (int, error)) int {
+	workers := a.cfg.ConsolidationWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(locations) {
+		workers = len(locations)
+	}
+
+	var (
+		mu    sync.Mutex
+		total int
+		done  int
+		wg    sync.WaitGroup
+	)
+	queue := make(chan string)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for location := range queue {
+				start := time.Now()
+				count, err := fn(ctx, location)
+				if err != nil {
+					a.logger.Error("Location processing failed",
+						"phase", phase,
+						"location", location,
+						"error", err)
+				}
+
+				mu.Lock()
+				total += count
+				done++
+				progress.Phase(ctx, phase, map[string]interface{}{
+					"location":        location,
+					"locations_done":  done,
+					"locations_total": len(locations),
+					"created":         total,
+				})
+				mu.Unlock()
+
+				a.logger.Debug("Location processed",
+					"phase", phase,
+					"location", location,
+					"count", count,
+					"duration_ms", time.Since(start).Milliseconds())
+			}
+		}()
+	}
+
+	for _, location := range locations {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- location
+	}
+	close(queue)
+	wg.Wait()
+
+	return total
+}
+
+// readLocationEvents reads one location's sensor events of the given types
+// (motion, lighting, media) from Redis; privacy zones have none
+func (a *Agent) readLocationEvents(ctx context.Context, location string, sensorTypes []string, since, until time.Time) []Event {
+	// Privacy zones keep transient motion for occupancy only
+	if a.privacy.ExcludesLocation(location) {
+		return nil
+	}
+
+	var events []Event
+	for _, sensorType := range sensorTypes {
+		if a.privacy.ExcludesSensorType(sensorType) {
+			continue
+		}
+		members, err := a.redis.ZRangeByScoreWithScores(ctx, redis.GenericSensorKey(sensorType, location), float64(since.UnixMilli()), float64(until.UnixMilli()))
+		if err != nil {
+			a.logger.Debug("No sensor data for location", "location", location, "type", sensorType, "error", err)
+			continue
+		}
+
+		for _, member := range members {
+			var data struct {
+				Timestamp string      `json:"timestamp"`
+				State     string      `json:"state"`
+				Source    string      `json:"source"`
+				EntityID  interface{} `json:"entity_id"`
+			}
+			if err := json.Unmarshal([]byte(member.Member), &data); err != nil {
+				continue
+			}
+
+			ts, _ := time.Parse(time.RFC3339, data.Timestamp)
+			event := Event{
+				Location:  location,
+				Timestamp: ts,
+				Type:      sensorType,
+				State:     data.State,
+			}
+			if sensorType == "lighting" {
+				event.Source = data.Source
+			}
+			if entityID, ok := data.EntityID.(string); ok {
+				event.Device = entityID
+			}
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// readEventsConcurrently reads sensor events for all locations on the worker
+// pool. Events are returned grouped in location order.
+func (a *Agent) readEventsConcurrently(ctx context.Context, phase string, locations []string, sensorTypes []string, since, until time.Time) []Event {
+	var mu sync.Mutex
+	byLocation := make(map[string][]Event, len(locations))
+
+	a.runLocations(ctx, phase, locations, func(ctx context.Context, location string) (int, error) {
+		events := a.readLocationEvents(ctx, location, sensorTypes, since, until)
+		mu.Lock()
+		byLocation[location] = events
+		mu.Unlock()
+		return 0, nil
+	})
+
+	var all []Event
+	for _, location := range locations {
+		all = append(all, byLocation[location]...)
+	}
+	return all
+}
+
+// episodeLocations returns the locations with episodes started since sinceTime
+func (a *Agent) episodeLocations(ctx context.Context, sinceTime time.Time) ([]string, error) {
+	rows, err := a.pgClient.Query(ctx, `
+		SELECT DISTINCT jsonld->'adl:activity'->'adl:location'->>'name'
+		FROM behavioral_episodes
+		WHERE (jsonld->>'jeeves:startedAt')::timestamptz >= $1
+		AND jsonld->'adl:activity'->'adl:location'->>'name' IS NOT NULL
+		ORDER BY 1
+	`, sinceTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episode locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []string
+	for rows.Next() {
+		var location string
+		if err := rows.Scan(&location); err != nil {
+			return nil, fmt.Errorf("failed to scan episode location: %w", err)
+		}
+		locations = append(locations, location)
+	}
+	return locations, rows.Err()
+}
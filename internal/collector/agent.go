@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/metrics"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/privacy"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
 
@@ -19,17 +23,22 @@ type Agent struct {
 	storage     *Storage
 	cfg         *config.Config
 	logger      *slog.Logger
-	timeManager *TimeManager
+	timeManager *clock.TimeManager
+	metrics     *metrics.Writer // nil unless VictoriaMetrics forwarding is enabled
+	archiver    *Archiver       // nil unless the InfluxDB archive is configured
+	dlq         *DeadLetterQueue
+	queue       *IngestQueue   // nil when messages are processed inline
+	privacy     *privacy.Zones // nil when no privacy zones are configured
 }
 
 // NewAgent creates a new collector agent with the given dependencies
-func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Config, logger *slog.Logger) *Agent {
-	timeManager := NewTimeManager(logger)
+func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Config, logger *slog.Logger) (*Agent, error) {
+	timeManager := clock.NewTimeManager(logger)
 
 	processor := NewProcessor(logger, timeManager)
 	storage := NewStorage(redisClient, mqttClient, cfg, logger, timeManager)
 
-	return &Agent{
+	a := &Agent{
 		mqtt:        mqttClient,
 		redis:       redisClient,
 		processor:   processor,
@@ -37,7 +46,21 @@ func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Conf
 		cfg:         cfg,
 		logger:      logger,
 		timeManager: timeManager,
+		metrics:     metrics.NewFromConfig(cfg, logger),
+		archiver:    NewArchiver(cfg, logger),
+		dlq:         NewDeadLetterQueue(redisClient, cfg, logger),
+		privacy:     privacy.NewZones(cfg),
 	}
+
+	queue, err := NewIngestQueue(cfg, func(msg queuedMessage) {
+		a.processMessage(msg.Topic, []byte(msg.Payload), msg.ReceivedAt)
+	}, a.metrics, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ingest queue: %w", err)
+	}
+	a.queue = queue
+
+	return a, nil
 }
 
 // Start starts the collector agent and begins processing sensor messages
@@ -56,11 +79,34 @@ func (a *Agent) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to ping Redis: %w", err)
 	}
 
+	// Remove data stored for privacy zones before they were excluded
+	if a.privacy != nil {
+		deleted, err := purgePrivateKeys(ctx, a.redis, a.privacy, a.timeManager.Now())
+		if err != nil {
+			a.logger.Warn("Failed to purge privacy zone data", "error", err)
+		} else {
+			a.logger.Info("Privacy zones active",
+				"excluded_locations", a.privacy.Locations(),
+				"excluded_sensor_types", a.privacy.SensorTypes(),
+				"purged_keys", deleted)
+		}
+	}
+
 	if err := a.timeManager.ConfigureFromMQTT(a.mqtt); err != nil {
 		a.logger.Warn("Failed to subscribe to test mode config", "error", err)
 		// Not fatal - continue without test mode support
 	}
 
+	// Forward numeric readings to VictoriaMetrics (no-op when disabled)
+	a.metrics.Start(ctx)
+	go mqtt.ReportDeliveryMetrics(ctx, a.mqtt, a.metrics, a.cfg.ServiceName)
+
+	// Mirror sensor events to the InfluxDB archive (no-op when disabled)
+	a.archiver.Start(ctx)
+
+	// Process buffered sensor messages (no-op when processing inline)
+	a.queue.Start(ctx)
+
 	// Subscribe to sensor topics
 	for _, topic := range a.cfg.SensorTopics {
 		if err := a.mqtt.Subscribe(topic, 0, a.handleMessage); err != nil {
@@ -87,6 +133,9 @@ func (a *Agent) Stop() error {
 	// Disconnect from MQTT
 	a.mqtt.Disconnect()
 
+	// Let the queue finish before Redis goes away
+	a.queue.Wait()
+
 	// Close Redis connection
 	if err := a.redis.Close(); err != nil {
 		a.logger.Error("Error closing Redis connection", "error", err)
@@ -97,23 +146,38 @@ func (a *Agent) Stop() error {
 	return nil
 }
 
-// handleMessage processes incoming MQTT messages
+// handleMessage queues incoming MQTT messages, or processes them directly
+// when the ingest queue is disabled
 func (a *Agent) handleMessage(msg mqtt.Message) {
-	topic := msg.Topic()
-	payload := msg.Payload()
+	if a.queue != nil {
+		a.queue.Enqueue(msg.Topic(), msg.Payload())
+		return
+	}
+	a.processMessage(msg.Topic(), msg.Payload(), time.Now())
+}
 
-	a.logger.Debug("Received MQTT message", "topic", topic, "size", len(payload))
+// processMessage parses, stores and forwards a sensor message
+func (a *Agent) processMessage(topic string, payload []byte, receivedAt time.Time) {
+	a.logger.Debug("Received MQTT message", "topic", topic, "size", len(payload), "queued_for", time.Since(receivedAt))
 
 	// Parse the message
 	sensorMsg, err := a.processor.ParseMessage(topic, payload)
 	if err != nil {
 		a.logger.Error("Failed to parse message", "topic", topic, "error", err)
+		if !a.privacy.ExcludesTopic(topic) {
+			a.dlq.Add(context.Background(), topic, payload, err)
+		}
 		return
 	}
 
 	// Create context for storage operations
 	ctx := context.Background()
 
+	if a.privacy.Excludes(sensorMsg.SensorType, sensorMsg.Location) {
+		a.processPrivate(ctx, sensorMsg)
+		return
+	}
+
 	// Store sensor data in Redis
 	if err := a.storage.StoreSensorData(ctx, sensorMsg, a.processor); err != nil {
 		a.logger.Error("Failed to store sensor data",
@@ -124,6 +188,11 @@ func (a *Agent) handleMessage(msg mqtt.Message) {
 		// Downstream consumers can retry
 	}
 
+	if point, ok := sensorPoint(sensorMsg); ok {
+		a.metrics.Write(point)
+	}
+	a.archiver.Archive(sensorMsg)
+
 	// Publish trigger message to processed topic
 	if err := a.publishTrigger(sensorMsg); err != nil {
 		a.logger.Error("Failed to publish trigger message",
@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/metrics"
+)
+
+// Archiver mirrors normalized sensor events to InfluxDB so raw history
+// outlives the Redis retention window. A nil *Archiver archives nothing.
+type Archiver struct {
+	writer *metrics.Writer
+	types  map[string]bool // nil = every sensor type
+}
+
+// NewArchiver returns an archiver for cfg.InfluxURL, or nil when the archive
+// is not configured
+func NewArchiver(cfg *config.Config, logger *slog.Logger) *Archiver {
+	if cfg.InfluxURL == "" {
+		return nil
+	}
+
+	a := &Archiver{
+		writer: metrics.NewInfluxWriter(cfg.InfluxURL, cfg.InfluxOrg, cfg.InfluxBucket, cfg.InfluxToken, logger),
+	}
+	if len(cfg.InfluxSensorTypes) > 0 {
+		a.types = make(map[string]bool, len(cfg.InfluxSensorTypes))
+		for _, t := range cfg.InfluxSensorTypes {
+			a.types[t] = true
+		}
+	}
+	return a
+}
+
+// Start flushes archived events in the background until ctx is cancelled
+func (a *Archiver) Start(ctx context.Context) {
+	if a == nil {
+		return
+	}
+	a.writer.Start(ctx)
+}
+
+// Archive queues msg if its sensor type is selected
+func (a *Archiver) Archive(msg *SensorMessage) {
+	if a == nil || (a.types != nil && !a.types[msg.SensorType]) {
+		return
+	}
+	if point, ok := archivePoint(msg); ok {
+		a.writer.Write(point)
+	}
+}
+
+// archivePoint stores every scalar field of the normalized event in
+// measurement {sensor_type}, tagged by location and the original topic
+func archivePoint(msg *SensorMessage) (metrics.Point, bool) {
+	fields := make(map[string]interface{}, len(msg.Data))
+	for key, value := range msg.Data {
+		switch value.(type) {
+		case float64, bool, string:
+			fields[key] = value
+		}
+	}
+	if len(fields) == 0 {
+		return metrics.Point{}, false
+	}
+	fields["collected_at"] = msg.CollectedAt
+
+	return metrics.Point{
+		Measurement: msg.SensorType,
+		Tags: map[string]string{
+			"location": msg.Location,
+			"topic":    msg.OriginalTopic,
+		},
+		Fields: fields,
+		Time:   msg.Timestamp,
+	}, true
+}
@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+func TestArchivePoint(t *testing.T) {
+	msg := &SensorMessage{
+		SensorType:    "presence",
+		Location:      "hallway",
+		OriginalTopic: "automation/raw/presence/hallway",
+		Data: map[string]interface{}{
+			"state":    "occupied",
+			"occupant": "alice",
+			"rssi":     -61.0,
+			"home":     true,
+			"extra":    map[string]interface{}{"nested": 1},
+		},
+		Timestamp:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		CollectedAt: 1704110400123,
+	}
+
+	point, ok := archivePoint(msg)
+	if !ok {
+		t.Fatal("expected a point")
+	}
+
+	line, _ := point.Line()
+	want := `presence,location=hallway,topic=automation/raw/presence/hallway collected_at=1704110400123i,home=true,occupant="alice",rssi=-61,state="occupied" 1704110400000000000`
+	if line != want {
+		t.Errorf("line =\n%s\nwant\n%s", line, want)
+	}
+}
+
+func TestArchiver_SensorTypes(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	if NewArchiver(config.NewConfig(), logger) != nil {
+		t.Error("archiver should be disabled without an InfluxDB URL")
+	}
+
+	cfg := config.NewConfig()
+	cfg.InfluxURL = "http://influx:8086"
+	cfg.InfluxSensorTypes = []string{"motion"}
+	archiver := NewArchiver(cfg, logger)
+
+	if !archiver.types["motion"] || archiver.types["temperature"] {
+		t.Errorf("unexpected sensor type filter %v", archiver.types)
+	}
+
+	// Messages of other types are skipped before reaching the writer
+	archiver.Archive(&SensorMessage{SensorType: "temperature", Data: map[string]interface{}{"value": 21.0}})
+	if err := archiver.writer.Flush(t.Context()); err != nil {
+		t.Errorf("flush with nothing queued should not contact InfluxDB: %v", err)
+	}
+}
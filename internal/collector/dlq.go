@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// DeadLetter is a sensor message the collector could not parse
+type DeadLetter struct {
+	ID         string    `json:"id"`
+	Topic      string    `json:"topic"`
+	Payload    string    `json:"payload"`
+	Error      string    `json:"error"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// DeadLetterQueue keeps the latest unparseable messages in Redis list
+// dlq:sensor so they can be inspected and re-injected. A nil
+// *DeadLetterQueue drops them.
+type DeadLetterQueue struct {
+	redis  redis.Client
+	size   int
+	logger *slog.Logger
+}
+
+// NewDeadLetterQueue returns a queue holding cfg.CollectorDLQSize entries, or
+// nil when the size is zero
+func NewDeadLetterQueue(redisClient redis.Client, cfg *config.Config, logger *slog.Logger) *DeadLetterQueue {
+	if cfg.CollectorDLQSize <= 0 {
+		return nil
+	}
+	return &DeadLetterQueue{
+		redis:  redisClient,
+		size:   cfg.CollectorDLQSize,
+		logger: logger.With("component", "dlq"),
+	}
+}
+
+// Add records a message that failed to parse, evicting the oldest entries
+// beyond the queue size
+func (q *DeadLetterQueue) Add(ctx context.Context, topic string, payload []byte, parseErr error) {
+	if q == nil {
+		return
+	}
+
+	entry := DeadLetter{
+		ID:         uuid.New().String(),
+		Topic:      topic,
+		Payload:    string(payload),
+		Error:      parseErr.Error(),
+		ReceivedAt: time.Now().UTC(),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		q.logger.Error("Failed to marshal dead letter", "topic", topic, "error", err)
+		return
+	}
+
+	if err := q.redis.LPush(ctx, redis.SensorDLQKey, string(data)); err != nil {
+		q.logger.Error("Failed to store dead letter", "topic", topic, "error", err)
+		return
+	}
+	if err := q.redis.LTrim(ctx, redis.SensorDLQKey, 0, int64(q.size-1)); err != nil {
+		q.logger.Warn("Failed to trim dead-letter queue", "error", err)
+	}
+
+	q.logger.Debug("Stored dead letter", "id", entry.ID, "topic", topic)
+}
+
+// ListDeadLetters returns up to limit entries, newest first; every entry when
+// limit is zero
+func ListDeadLetters(ctx context.Context, client redis.Client, limit int) ([]DeadLetter, error) {
+	values, err := client.LRange(ctx, redis.SensorDLQKey, 0, int64(limit-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+	}
+
+	entries := make([]DeadLetter, 0, len(values))
+	for _, value := range values {
+		var entry DeadLetter
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// RemoveDeadLetter deletes the entry with the given id and returns it, nil
+// when no such entry is queued
+func RemoveDeadLetter(ctx context.Context, client redis.Client, id string) (*DeadLetter, error) {
+	values, err := client.LRange(ctx, redis.SensorDLQKey, 0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+	}
+
+	for _, value := range values {
+		var entry DeadLetter
+		if err := json.Unmarshal([]byte(value), &entry); err != nil || entry.ID != id {
+			continue
+		}
+		if _, err := client.LRem(ctx, redis.SensorDLQKey, 1, value); err != nil {
+			return nil, fmt.Errorf("failed to remove dead letter %s: %w", id, err)
+		}
+		return &entry, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// fakeListRedis implements the list commands used by the dead-letter queue
+type fakeListRedis struct {
+	redis.Client
+	lists map[string][]string
+}
+
+func newFakeListRedis() *fakeListRedis {
+	return &fakeListRedis{lists: make(map[string][]string)}
+}
+
+func (f *fakeListRedis) LPush(_ context.Context, key string, values ...interface{}) error {
+	for _, v := range values {
+		f.lists[key] = append([]string{fmt.Sprint(v)}, f.lists[key]...)
+	}
+	return nil
+}
+
+func (f *fakeListRedis) LTrim(_ context.Context, key string, start, stop int64) error {
+	list := f.lists[key]
+	if stop+1 < int64(len(list)) {
+		f.lists[key] = list[start : stop+1]
+	}
+	return nil
+}
+
+func (f *fakeListRedis) LRange(_ context.Context, key string, start, stop int64) ([]string, error) {
+	list := f.lists[key]
+	if stop < 0 || stop >= int64(len(list)) {
+		stop = int64(len(list)) - 1
+	}
+	if start > stop {
+		return nil, nil
+	}
+	return list[start : stop+1], nil
+}
+
+func (f *fakeListRedis) LRem(_ context.Context, key string, count int64, value interface{}) (int64, error) {
+	var kept []string
+	var removed int64
+	for _, v := range f.lists[key] {
+		if v == fmt.Sprint(value) && removed < count {
+			removed++
+			continue
+		}
+		kept = append(kept, v)
+	}
+	f.lists[key] = kept
+	return removed, nil
+}
+
+func TestDeadLetterQueue(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+	client := newFakeListRedis()
+
+	cfg := config.NewConfig()
+	cfg.CollectorDLQSize = 2
+	queue := NewDeadLetterQueue(client, cfg, logger)
+
+	for i := 1; i <= 3; i++ {
+		queue.Add(ctx, fmt.Sprintf("automation/raw/motion/room%d", i), []byte("{not json"), errors.New("invalid JSON"))
+	}
+
+	entries, err := ListDeadLetters(ctx, client, 10)
+	if err != nil {
+		t.Fatalf("ListDeadLetters: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2 (oldest evicted)", len(entries))
+	}
+	if entries[0].Topic != "automation/raw/motion/room3" || entries[1].Topic != "automation/raw/motion/room2" {
+		t.Errorf("entries not newest first: %s, %s", entries[0].Topic, entries[1].Topic)
+	}
+	if entries[0].Payload != "{not json" || entries[0].Error != "invalid JSON" || entries[0].ReceivedAt.IsZero() {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+
+	removed, err := RemoveDeadLetter(ctx, client, entries[1].ID)
+	if err != nil {
+		t.Fatalf("RemoveDeadLetter: %v", err)
+	}
+	if removed == nil || removed.Topic != "automation/raw/motion/room2" {
+		t.Errorf("removed %+v, want room2", removed)
+	}
+	if n := len(client.lists[redis.SensorDLQKey]); n != 1 {
+		t.Errorf("%d entries left, want 1", n)
+	}
+
+	if removed, _ := RemoveDeadLetter(ctx, client, "missing"); removed != nil {
+		t.Errorf("unknown id removed %+v", removed)
+	}
+}
+
+func TestDeadLetterQueue_Disabled(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	client := newFakeListRedis()
+
+	cfg := config.NewConfig()
+	cfg.CollectorDLQSize = 0
+	queue := NewDeadLetterQueue(client, cfg, logger)
+	if queue != nil {
+		t.Fatal("queue should be disabled with size 0")
+	}
+
+	queue.Add(context.Background(), "automation/raw/motion/hall", []byte("x"), errors.New("bad"))
+	if len(client.lists) != 0 {
+		t.Error("disabled queue stored a message")
+	}
+}
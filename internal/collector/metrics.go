@@ -0,0 +1,30 @@
+package collector
+
+import (
+	"github.com/saaga0h/jeeves-platform/pkg/metrics"
+)
+
+// sensorPoint converts the numeric fields of a sensor message into a metrics
+// point named sensor_{type} (series sensor_{type}_{field}). It returns false
+// when the message has no numeric fields.
+func sensorPoint(msg *SensorMessage) (metrics.Point, bool) {
+	fields := make(map[string]interface{})
+	for key, value := range msg.Data {
+		if v, ok := value.(float64); ok {
+			fields[key] = v
+		}
+	}
+	if len(fields) == 0 {
+		return metrics.Point{}, false
+	}
+
+	return metrics.Point{
+		Measurement: "sensor_" + msg.SensorType,
+		Tags: map[string]string{
+			"location":    msg.Location,
+			"sensor_type": msg.SensorType,
+		},
+		Fields: fields,
+		Time:   msg.Timestamp,
+	}, true
+}
@@ -0,0 +1,34 @@
+package collector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSensorPoint(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	point, ok := sensorPoint(&SensorMessage{
+		SensorType: "illuminance",
+		Location:   "living_room",
+		Data:       map[string]interface{}{"value": 320.5, "unit": "lux"},
+		Timestamp:  ts,
+	})
+	if !ok {
+		t.Fatal("expected a point for numeric illuminance data")
+	}
+
+	line, _ := point.Line()
+	want := "sensor_illuminance,location=living_room,sensor_type=illuminance value=320.5 1704110400000000000"
+	if line != want {
+		t.Errorf("line = %q, want %q", line, want)
+	}
+
+	if _, ok := sensorPoint(&SensorMessage{
+		SensorType: "motion",
+		Location:   "study",
+		Data:       map[string]interface{}{"state": "on"},
+	}); ok {
+		t.Error("expected no point for non-numeric motion data")
+	}
+}
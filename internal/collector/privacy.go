@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/privacy"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// processPrivate handles a message from a privacy zone. Motion from an
+// excluded location is kept for transientTTL and triggers occupancy as
+// usual; everything else is dropped. Nothing reaches metrics or the archive.
+func (a *Agent) processPrivate(ctx context.Context, msg *SensorMessage) {
+	if a.privacy.ExcludesSensorType(msg.SensorType) || msg.SensorType != privacy.TransientSensorType {
+		a.logger.Debug("Dropped privacy-excluded sensor message",
+			"sensor_type", msg.SensorType,
+			"location", msg.Location)
+		return
+	}
+
+	if err := a.storage.StoreTransientMotion(ctx, msg, a.processor); err != nil {
+		a.logger.Error("Failed to store transient motion",
+			"location", msg.Location,
+			"error", err)
+	}
+
+	if err := a.publishTrigger(msg); err != nil {
+		a.logger.Error("Failed to publish trigger message",
+			"sensor_type", msg.SensorType,
+			"location", msg.Location,
+			"error", err)
+	}
+}
+
+// storageKeyType is the key family a sensor type is stored under
+// (sensor:{type}:{location}, meta:{type}:{location})
+func storageKeyType(sensorType string) string {
+	switch sensorType {
+	case "temperature", "illuminance":
+		return "environmental"
+	case "energy":
+		return "power"
+	default:
+		return sensorType
+	}
+}
+
+// purgePrivateKeys removes the Redis data of privacy zones stored before they
+// were excluded: all keys of excluded sensor types and all keys of excluded
+// locations, except their motion history, which is trimmed to transientTTL.
+// It returns the number of keys deleted.
+func purgePrivateKeys(ctx context.Context, client redis.Client, zones *privacy.Zones, now time.Time) (int64, error) {
+	var patterns []string
+	for _, sensorType := range zones.SensorTypes() {
+		keyType := storageKeyType(sensorType)
+		patterns = append(patterns, fmt.Sprintf("sensor:%s:*", keyType), fmt.Sprintf("meta:%s:*", keyType))
+	}
+	for _, location := range zones.Locations() {
+		patterns = append(patterns, fmt.Sprintf("sensor:*:%s", location), fmt.Sprintf("meta:*:%s", location))
+	}
+
+	var doomed []string
+	for _, pattern := range patterns {
+		keys, err := client.Keys(ctx, pattern)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list keys %s: %w", pattern, err)
+		}
+		for _, key := range keys {
+			location := key[strings.LastIndex(key, ":")+1:]
+			if key == redis.MotionSensorKey(location) && !zones.ExcludesSensorType(privacy.TransientSensorType) {
+				cutoff := now.Add(-transientTTL).UnixMilli()
+				if err := client.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(cutoff, 10)); err != nil {
+					return 0, fmt.Errorf("failed to trim %s: %w", key, err)
+				}
+				if err := client.Expire(ctx, key, transientTTL); err != nil {
+					return 0, fmt.Errorf("failed to expire %s: %w", key, err)
+				}
+				continue
+			}
+			if key == redis.MotionMetaKey(location) && !zones.ExcludesSensorType(privacy.TransientSensorType) {
+				if err := client.Expire(ctx, key, transientTTL); err != nil {
+					return 0, fmt.Errorf("failed to expire %s: %w", key, err)
+				}
+				continue
+			}
+			doomed = append(doomed, key)
+		}
+	}
+
+	if len(doomed) == 0 {
+		return 0, nil
+	}
+	return client.Del(ctx, doomed...)
+}
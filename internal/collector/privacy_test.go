@@ -0,0 +1,141 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/privacy"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// fakeKeyRedis implements the key commands used by the privacy purge
+type fakeKeyRedis struct {
+	redis.Client
+	keys    map[string]bool
+	trimmed map[string]string
+	ttls    map[string]time.Duration
+}
+
+func newFakeKeyRedis(keys ...string) *fakeKeyRedis {
+	f := &fakeKeyRedis{
+		keys:    make(map[string]bool),
+		trimmed: make(map[string]string),
+		ttls:    make(map[string]time.Duration),
+	}
+	for _, key := range keys {
+		f.keys[key] = true
+	}
+	return f
+}
+
+func (f *fakeKeyRedis) Keys(_ context.Context, pattern string) ([]string, error) {
+	var matched []string
+	for key := range f.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			matched = append(matched, key)
+		}
+	}
+	return matched, nil
+}
+
+func (f *fakeKeyRedis) Del(_ context.Context, keys ...string) (int64, error) {
+	var deleted int64
+	for _, key := range keys {
+		if f.keys[key] {
+			delete(f.keys, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (f *fakeKeyRedis) ZRemRangeByScore(_ context.Context, key string, min, max string) error {
+	f.trimmed[key] = max
+	return nil
+}
+
+func (f *fakeKeyRedis) Expire(_ context.Context, key string, ttl time.Duration) error {
+	f.ttls[key] = ttl
+	return nil
+}
+
+func (f *fakeKeyRedis) remaining() []string {
+	var keys []string
+	for key := range f.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestPurgePrivateKeys(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.PrivacyExcludedLocations = []string{"bathroom"}
+	cfg.PrivacyExcludedSensorTypes = []string{"presence", "temperature"}
+	zones := privacy.NewZones(cfg)
+
+	client := newFakeKeyRedis(
+		"sensor:motion:bathroom",
+		"meta:motion:bathroom",
+		"sensor:lighting:bathroom",
+		"sensor:environmental:kitchen",
+		"sensor:presence:kitchen",
+		"sensor:motion:kitchen",
+		"dlq:sensor",
+	)
+	now := time.Date(2025, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	deleted, err := purgePrivateKeys(context.Background(), client, zones, now)
+	if err != nil {
+		t.Fatalf("purgePrivateKeys() error = %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("deleted %d keys, want 3", deleted)
+	}
+
+	want := "[dlq:sensor meta:motion:bathroom sensor:motion:bathroom sensor:motion:kitchen]"
+	if got := fmt.Sprint(client.remaining()); got != want {
+		t.Errorf("remaining keys %s, want %s", got, want)
+	}
+
+	// Motion in the excluded location is cut back to the transient window
+	if client.ttls["sensor:motion:bathroom"] != transientTTL || client.ttls["meta:motion:bathroom"] != transientTTL {
+		t.Errorf("bathroom motion TTLs = %v, want %v", client.ttls, transientTTL)
+	}
+	if _, ok := client.trimmed["sensor:motion:kitchen"]; ok {
+		t.Error("motion outside privacy zones was trimmed")
+	}
+}
+
+func TestPurgePrivateKeys_MotionExcluded(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.PrivacyExcludedLocations = []string{"bathroom"}
+	cfg.PrivacyExcludedSensorTypes = []string{"motion"}
+
+	client := newFakeKeyRedis("sensor:motion:bathroom", "meta:motion:bathroom", "sensor:motion:kitchen")
+
+	if _, err := purgePrivateKeys(context.Background(), client, privacy.NewZones(cfg), time.Now()); err != nil {
+		t.Fatalf("purgePrivateKeys() error = %v", err)
+	}
+	if got := client.remaining(); len(got) != 0 {
+		t.Errorf("remaining keys %v, want none", got)
+	}
+}
+
+func TestStorageKeyType(t *testing.T) {
+	for sensorType, want := range map[string]string{
+		"temperature": "environmental",
+		"illuminance": "environmental",
+		"energy":      "power",
+		"media":       "media",
+	} {
+		if got := storageKeyType(sensorType); got != want {
+			t.Errorf("storageKeyType(%q) = %q, want %q", sensorType, got, want)
+		}
+	}
+}
@@ -6,16 +6,18 @@ import (
 	"log/slog"
 	"strings"
 	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 )
 
 // Processor handles parsing and processing of sensor messages
 type Processor struct {
 	logger      *slog.Logger
-	timeManager *TimeManager
+	timeManager *clock.TimeManager
 }
 
 // NewProcessor creates a new message processor
-func NewProcessor(logger *slog.Logger, timeManager *TimeManager) *Processor {
+func NewProcessor(logger *slog.Logger, timeManager *clock.TimeManager) *Processor {
 	return &Processor{
 		logger:      logger,
 		timeManager: timeManager,
@@ -51,6 +53,26 @@ type EnvironmentalData struct {
 	IllumUnit   *string  `json:"illuminance_unit,omitempty"`
 }
 
+// PresenceData represents occupant presence data (BLE beacons, phone trackers)
+type PresenceData struct {
+	Timestamp   string   `json:"timestamp"`
+	State       string   `json:"state"`
+	Occupant    string   `json:"occupant"`
+	Source      string   `json:"source"`
+	RSSI        *float64 `json:"rssi,omitempty"`
+	CollectedAt int64    `json:"collected_at"`
+}
+
+// PowerData represents a power/energy metering reading (smart plugs, CT clamps)
+type PowerData struct {
+	Timestamp   string   `json:"timestamp"`
+	Watts       float64  `json:"watts"`
+	EnergyKWh   *float64 `json:"energy_kwh,omitempty"` // cumulative meter reading
+	Appliance   string   `json:"appliance,omitempty"`  // named by the sender, e.g. "kettle"
+	EntityID    string   `json:"entity_id,omitempty"`
+	CollectedAt int64    `json:"collected_at"`
+}
+
 // GenericData represents generic sensor data
 type GenericData struct {
 	Data          map[string]interface{} `json:"data"`
@@ -172,6 +194,73 @@ func (p *Processor) BuildEnvironmentalData(msg *SensorMessage) *EnvironmentalDat
 	return data
 }
 
+// BuildPresenceData converts a sensor message to presence data for Redis storage
+// The occupant is taken from "occupant", falling back to "person" and then "device_id"
+func (p *Processor) BuildPresenceData(msg *SensorMessage) *PresenceData {
+	state := "unknown"
+	if s, ok := msg.Data["state"].(string); ok {
+		state = s
+	}
+
+	occupant := ""
+	for _, field := range []string{"occupant", "person", "device_id"} {
+		if o, ok := msg.Data[field].(string); ok && o != "" {
+			occupant = o
+			break
+		}
+	}
+
+	source := "unknown"
+	if s, ok := msg.Data["source"].(string); ok {
+		source = s
+	}
+
+	data := &PresenceData{
+		Timestamp:   msg.Timestamp.Format(time.RFC3339Nano),
+		State:       state,
+		Occupant:    occupant,
+		Source:      source,
+		CollectedAt: msg.CollectedAt,
+	}
+
+	if rssi, ok := msg.Data["rssi"].(float64); ok {
+		data.RSSI = &rssi
+	}
+
+	return data
+}
+
+// BuildPowerData converts a power or energy sensor message to power data for
+// Redis storage. Watts are taken from "power", falling back to "watts" and then
+// "value"; energy from "energy" and then "kwh".
+func (p *Processor) BuildPowerData(msg *SensorMessage) *PowerData {
+	data := &PowerData{
+		Timestamp:   msg.Timestamp.Format(time.RFC3339Nano),
+		CollectedAt: msg.CollectedAt,
+	}
+
+	for _, field := range []string{"power", "watts", "value"} {
+		if w, ok := msg.Data[field].(float64); ok {
+			data.Watts = w
+			break
+		}
+	}
+	for _, field := range []string{"energy", "kwh"} {
+		if e, ok := msg.Data[field].(float64); ok {
+			data.EnergyKWh = &e
+			break
+		}
+	}
+	if a, ok := msg.Data["appliance"].(string); ok {
+		data.Appliance = a
+	}
+	if e, ok := msg.Data["entity_id"].(string); ok {
+		data.EntityID = e
+	}
+
+	return data
+}
+
 // BuildGenericData converts a sensor message to generic data for Redis storage
 func (p *Processor) BuildGenericData(msg *SensorMessage) *GenericData {
 	return &GenericData{
@@ -5,11 +5,13 @@ import (
 	"log/slog"
 	"os"
 	"testing"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 )
 
 func TestParseMessage(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	timeManager := NewTimeManager(logger)
+	timeManager := clock.NewTimeManager(logger)
 	processor := NewProcessor(logger, timeManager)
 
 	tests := []struct {
@@ -101,7 +103,7 @@ func TestParseMessage(t *testing.T) {
 
 func TestBuildMotionData(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	timeManager := NewTimeManager(logger)
+	timeManager := clock.NewTimeManager(logger)
 	processor := NewProcessor(logger, timeManager)
 
 	tests := []struct {
@@ -162,9 +164,154 @@ func TestBuildMotionData(t *testing.T) {
 	}
 }
 
+func TestBuildPresenceData(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	timeManager := clock.NewTimeManager(logger)
+	processor := NewProcessor(logger, timeManager)
+
+	tests := []struct {
+		name         string
+		payload      string
+		wantState    string
+		wantOccupant string
+		wantSource   string
+		wantRSSI     bool
+		description  string
+	}{
+		{
+			name:         "ble presence with rssi",
+			payload:      `{"data":{"state":"home","occupant":"alice","source":"ble","rssi":-62}}`,
+			wantState:    "home",
+			wantOccupant: "alice",
+			wantSource:   "ble",
+			wantRSSI:     true,
+			description:  "Should parse BLE presence with signal strength",
+		},
+		{
+			name:         "phone presence using person field",
+			payload:      `{"data":{"state":"not_home","person":"bob","source":"phone"}}`,
+			wantState:    "not_home",
+			wantOccupant: "bob",
+			wantSource:   "phone",
+			description:  "Should fall back to person field for occupant",
+		},
+		{
+			name:         "presence with device id only",
+			payload:      `{"data":{"state":"home","device_id":"phone_alice"}}`,
+			wantState:    "home",
+			wantOccupant: "phone_alice",
+			wantSource:   "unknown",
+			description:  "Should fall back to device_id and default source",
+		},
+		{
+			name:         "presence with defaults",
+			payload:      `{"data":{}}`,
+			wantState:    "unknown",
+			wantOccupant: "",
+			wantSource:   "unknown",
+			description:  "Should use defaults for missing fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := processor.ParseMessage("automation/raw/presence/kitchen", []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("ParseMessage() failed: %v", err)
+			}
+
+			presenceData := processor.BuildPresenceData(msg)
+
+			if presenceData.State != tt.wantState {
+				t.Errorf("BuildPresenceData() state = %v, want %v", presenceData.State, tt.wantState)
+			}
+
+			if presenceData.Occupant != tt.wantOccupant {
+				t.Errorf("BuildPresenceData() occupant = %v, want %v", presenceData.Occupant, tt.wantOccupant)
+			}
+
+			if presenceData.Source != tt.wantSource {
+				t.Errorf("BuildPresenceData() source = %v, want %v", presenceData.Source, tt.wantSource)
+			}
+
+			if (presenceData.RSSI != nil) != tt.wantRSSI {
+				t.Errorf("BuildPresenceData() rssi present = %v, want %v", presenceData.RSSI != nil, tt.wantRSSI)
+			}
+
+			if presenceData.CollectedAt == 0 {
+				t.Error("BuildPresenceData() collectedAt should not be zero")
+			}
+		})
+	}
+}
+
+func TestBuildPowerData(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	timeManager := clock.NewTimeManager(logger)
+	processor := NewProcessor(logger, timeManager)
+
+	tests := []struct {
+		name          string
+		payload       string
+		wantWatts     float64
+		wantEnergy    bool
+		wantAppliance string
+		description   string
+	}{
+		{
+			name:          "smart plug with appliance",
+			payload:       `{"data":{"power":2150.5,"energy":12.3,"appliance":"kettle","entity_id":"sensor.kettle_power"}}`,
+			wantWatts:     2150.5,
+			wantEnergy:    true,
+			wantAppliance: "kettle",
+			description:   "Should parse draw, meter reading and appliance name",
+		},
+		{
+			name:        "clamp using watts field",
+			payload:     `{"data":{"watts":85}}`,
+			wantWatts:   85,
+			description: "Should fall back to watts field",
+		},
+		{
+			name:        "energy sensor using value and kwh",
+			payload:     `{"data":{"value":40,"kwh":1.5}}`,
+			wantWatts:   40,
+			wantEnergy:  true,
+			description: "Should fall back to value and kwh fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := processor.ParseMessage("automation/raw/power/kitchen", []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("ParseMessage() failed: %v", err)
+			}
+
+			powerData := processor.BuildPowerData(msg)
+
+			if powerData.Watts != tt.wantWatts {
+				t.Errorf("BuildPowerData() watts = %v, want %v", powerData.Watts, tt.wantWatts)
+			}
+
+			if (powerData.EnergyKWh != nil) != tt.wantEnergy {
+				t.Errorf("BuildPowerData() energy present = %v, want %v", powerData.EnergyKWh != nil, tt.wantEnergy)
+			}
+
+			if powerData.Appliance != tt.wantAppliance {
+				t.Errorf("BuildPowerData() appliance = %v, want %v", powerData.Appliance, tt.wantAppliance)
+			}
+
+			if powerData.CollectedAt == 0 {
+				t.Error("BuildPowerData() collectedAt should not be zero")
+			}
+		})
+	}
+}
+
 func TestBuildEnvironmentalData(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	timeManager := NewTimeManager(logger)
+	timeManager := clock.NewTimeManager(logger)
 	processor := NewProcessor(logger, timeManager)
 
 	tests := []struct {
@@ -223,7 +370,7 @@ func TestBuildEnvironmentalData(t *testing.T) {
 
 func TestBuildTriggerPayload(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	timeManager := NewTimeManager(logger)
+	timeManager := clock.NewTimeManager(logger)
 	processor := NewProcessor(logger, timeManager)
 
 	payload := `{"data":{"state":"on"}}`
@@ -0,0 +1,291 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/metrics"
+)
+
+const (
+	// spoolFile is the overflow file inside JEEVES_COLLECTOR_SPOOL_DIR
+	spoolFile = "collector-spool.jsonl"
+
+	// queueStatsInterval is how often queue depth and drops are reported
+	queueStatsInterval = 30 * time.Second
+)
+
+// queuedMessage is a raw sensor message waiting to be processed
+type queuedMessage struct {
+	Topic      string    `json:"topic"`
+	Payload    string    `json:"payload"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// QueueStats are the ingest queue counters since start
+type QueueStats struct {
+	Depth    int   // messages in memory
+	Spooled  int   // messages waiting on disk
+	Received int64 // messages enqueued
+	Overflow int64 // messages written to the spool
+	Dropped  int64 // messages lost to a full queue and spool
+}
+
+// IngestQueue decouples MQTT delivery from Redis writes so reconnect storms
+// do not stall the MQTT client. Messages wait in a bounded in-memory queue;
+// when it is full they overflow to a spool file and are processed in arrival
+// order once the queue drains. Without a spool directory, overflowing
+// messages are dropped.
+type IngestQueue struct {
+	handle  func(queuedMessage)
+	logger  *slog.Logger
+	metrics *metrics.Writer
+
+	mu    sync.Mutex
+	queue chan queuedMessage
+	spool *spool // nil when spooling is disabled
+	wake  chan struct{}
+	done  chan struct{}
+
+	received atomic.Int64
+	overflow atomic.Int64
+	dropped  atomic.Int64
+}
+
+// NewIngestQueue returns a queue of cfg.CollectorQueueSize messages handled
+// by handle, or nil when the size is zero and messages are processed inline
+func NewIngestQueue(cfg *config.Config, handle func(queuedMessage), metricsWriter *metrics.Writer, logger *slog.Logger) (*IngestQueue, error) {
+	if cfg.CollectorQueueSize <= 0 {
+		return nil, nil
+	}
+
+	q := &IngestQueue{
+		handle:  handle,
+		logger:  logger.With("component", "ingest-queue"),
+		metrics: metricsWriter,
+		queue:   make(chan queuedMessage, cfg.CollectorQueueSize),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	if cfg.CollectorSpoolDir != "" {
+		s, err := openSpool(filepath.Join(cfg.CollectorSpoolDir, spoolFile), int64(cfg.CollectorSpoolMaxMB)<<20)
+		if err != nil {
+			return nil, err
+		}
+		q.spool = s
+		if s.Pending() > 0 {
+			q.logger.Info("Resuming spooled messages from previous run", "spooled", s.Pending())
+		}
+	}
+
+	return q, nil
+}
+
+// Enqueue queues a message without blocking
+func (q *IngestQueue) Enqueue(topic string, payload []byte) {
+	msg := queuedMessage{Topic: topic, Payload: string(payload), ReceivedAt: time.Now()}
+	q.received.Add(1)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Once messages are spooled, later ones follow them to keep arrival order
+	if q.spool == nil || q.spool.Pending() == 0 {
+		select {
+		case q.queue <- msg:
+			return
+		default:
+		}
+	}
+
+	if q.spool == nil {
+		q.dropped.Add(1)
+		return
+	}
+	if err := q.spool.Append(msg); err != nil {
+		q.dropped.Add(1)
+		if !errors.Is(err, errSpoolFull) {
+			q.logger.Debug("Failed to spool message", "topic", topic, "error", err)
+		}
+		return
+	}
+	q.overflow.Add(1)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start processes queued messages in the background until ctx is cancelled
+func (q *IngestQueue) Start(ctx context.Context) {
+	if q == nil {
+		return
+	}
+	go q.run(ctx)
+	go q.report(ctx)
+}
+
+// Wait blocks until the queue has stopped after ctx was cancelled
+func (q *IngestQueue) Wait() {
+	if q == nil {
+		return
+	}
+	<-q.done
+}
+
+// Stats returns the current queue counters
+func (q *IngestQueue) Stats() QueueStats {
+	q.mu.Lock()
+	spooled := 0
+	if q.spool != nil {
+		spooled = q.spool.Pending()
+	}
+	q.mu.Unlock()
+
+	return QueueStats{
+		Depth:    len(q.queue),
+		Spooled:  spooled,
+		Received: q.received.Load(),
+		Overflow: q.overflow.Load(),
+		Dropped:  q.dropped.Load(),
+	}
+}
+
+func (q *IngestQueue) run(ctx context.Context) {
+	defer close(q.done)
+
+	for {
+		if ctx.Err() != nil {
+			q.shutdown()
+			return
+		}
+
+		// Queued messages are older than spooled ones
+		select {
+		case msg := <-q.queue:
+			q.handle(msg)
+			continue
+		default:
+		}
+
+		if msg, ok := q.nextSpooled(); ok {
+			q.handle(msg)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			q.shutdown()
+			return
+		case msg := <-q.queue:
+			q.handle(msg)
+		case <-q.wake:
+		}
+	}
+}
+
+// nextSpooled returns the oldest spooled message, skipping unreadable lines
+func (q *IngestQueue) nextSpooled() (queuedMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.spool == nil {
+		return queuedMessage{}, false
+	}
+	for q.spool.Pending() > 0 {
+		msg, ok, err := q.spool.Next()
+		if err != nil {
+			q.logger.Warn("Skipping unreadable spooled message", "error", err)
+			continue
+		}
+		return msg, ok
+	}
+	return queuedMessage{}, false
+}
+
+// shutdown keeps queued messages in the spool for the next run, or processes
+// them when spooling is disabled
+func (q *IngestQueue) shutdown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.spool == nil {
+		for len(q.queue) > 0 {
+			q.handle(<-q.queue)
+		}
+		return
+	}
+
+	// Spooled messages are newer than queued ones, so rewrite both in order
+	var msgs []queuedMessage
+	for len(q.queue) > 0 {
+		msgs = append(msgs, <-q.queue)
+	}
+	for q.spool.Pending() > 0 {
+		msg, ok, err := q.spool.Next()
+		if err != nil || !ok {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	for _, msg := range msgs {
+		if err := q.spool.Append(msg); err != nil {
+			q.dropped.Add(1)
+		}
+	}
+
+	if err := q.spool.Close(); err != nil {
+		q.logger.Error("Failed to close spool", "error", err)
+		return
+	}
+	if len(msgs) > 0 {
+		q.logger.Info("Spooled queued messages for next start", "spooled", len(msgs))
+	}
+}
+
+// report writes the queue counters (collector_queue_*) and warns about drops
+func (q *IngestQueue) report(ctx context.Context) {
+	ticker := time.NewTicker(queueStatsInterval)
+	defer ticker.Stop()
+
+	var lastDropped int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			stats := q.Stats()
+			q.metrics.Write(metrics.Point{
+				Measurement: "collector_queue",
+				Fields: map[string]interface{}{
+					"depth":    stats.Depth,
+					"spooled":  stats.Spooled,
+					"received": stats.Received,
+					"overflow": stats.Overflow,
+					"dropped":  stats.Dropped,
+				},
+				Time: now,
+			})
+
+			if dropped := stats.Dropped - lastDropped; dropped > 0 {
+				q.logger.Warn("Ingest queue full, dropped sensor messages",
+					"dropped", dropped,
+					"depth", stats.Depth,
+					"spooled", stats.Spooled)
+			}
+			lastDropped = stats.Dropped
+
+			if stats.Spooled > 0 {
+				q.logger.Info("Ingest queue backlog", "depth", stats.Depth, "spooled", stats.Spooled)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+// recorder collects handled message topics
+type recorder struct {
+	mu     sync.Mutex
+	topics []string
+}
+
+func (r *recorder) handle(msg queuedMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.topics = append(r.topics, msg.Topic)
+}
+
+func (r *recorder) handled() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.topics...)
+}
+
+func queueConfig(size int, spoolDir string) *config.Config {
+	cfg := config.NewConfig()
+	cfg.CollectorQueueSize = size
+	cfg.CollectorSpoolDir = spoolDir
+	return cfg
+}
+
+func TestNewIngestQueueDisabled(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	q, err := NewIngestQueue(queueConfig(0, ""), func(queuedMessage) {}, nil, logger)
+	if err != nil {
+		t.Fatalf("NewIngestQueue() error = %v", err)
+	}
+	if q != nil {
+		t.Fatal("expected nil queue when the size is zero")
+	}
+
+	// A nil queue is safe to start and wait on
+	q.Start(context.Background())
+	q.Wait()
+}
+
+func TestIngestQueueDropsWithoutSpool(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	rec := &recorder{}
+
+	q, err := NewIngestQueue(queueConfig(2, ""), rec.handle, nil, logger)
+	if err != nil {
+		t.Fatalf("NewIngestQueue() error = %v", err)
+	}
+
+	// Not started, so the third message finds the queue full
+	for i := 0; i < 3; i++ {
+		q.Enqueue(fmt.Sprintf("automation/raw/motion/room%d", i), []byte(`{}`))
+	}
+
+	stats := q.Stats()
+	if stats.Depth != 2 || stats.Dropped != 1 || stats.Received != 3 {
+		t.Errorf("stats = %+v, want depth 2, dropped 1, received 3", stats)
+	}
+
+	// Shutdown processes what is queued
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Start(ctx)
+	q.Wait()
+
+	if got := rec.handled(); len(got) != 2 {
+		t.Errorf("handled %v, want the 2 queued messages", got)
+	}
+}
+
+func TestIngestQueueOverflowKeepsOrder(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	rec := &recorder{}
+
+	q, err := NewIngestQueue(queueConfig(2, t.TempDir()), rec.handle, nil, logger)
+	if err != nil {
+		t.Fatalf("NewIngestQueue() error = %v", err)
+	}
+
+	var want []string
+	for i := 0; i < 6; i++ {
+		topic := fmt.Sprintf("automation/raw/motion/room%d", i)
+		want = append(want, topic)
+		q.Enqueue(topic, []byte(`{}`))
+	}
+
+	stats := q.Stats()
+	if stats.Depth != 2 || stats.Spooled != 4 || stats.Dropped != 0 {
+		t.Fatalf("stats = %+v, want depth 2, spooled 4, dropped 0", stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Start(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.handled()) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	q.Wait()
+
+	got := rec.handled()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("handled %v, want %v", got, want)
+	}
+}
+
+func TestIngestQueueSpoolSurvivesRestart(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	dir := t.TempDir()
+
+	first := &recorder{}
+	q, err := NewIngestQueue(queueConfig(1, dir), first.handle, nil, logger)
+	if err != nil {
+		t.Fatalf("NewIngestQueue() error = %v", err)
+	}
+	q.Enqueue("automation/raw/motion/a", []byte(`{}`))
+	q.Enqueue("automation/raw/motion/b", []byte(`{}`))
+	q.Enqueue("automation/raw/motion/c", []byte(`{}`))
+
+	// Stopping before processing spools everything in order
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Start(ctx)
+	q.Wait()
+	if got := first.handled(); len(got) != 0 {
+		t.Fatalf("handled %v before restart, want none", got)
+	}
+
+	second := &recorder{}
+	q, err = NewIngestQueue(queueConfig(1, dir), second.handle, nil, logger)
+	if err != nil {
+		t.Fatalf("NewIngestQueue() after restart error = %v", err)
+	}
+	if stats := q.Stats(); stats.Spooled != 3 {
+		t.Fatalf("spooled after restart = %d, want 3", stats.Spooled)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	q.Start(ctx)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(second.handled()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	q.Wait()
+
+	want := "[automation/raw/motion/a automation/raw/motion/b automation/raw/motion/c]"
+	if got := fmt.Sprint(second.handled()); got != want {
+		t.Errorf("handled %s, want %s", got, want)
+	}
+}
+
+func TestSpoolLimit(t *testing.T) {
+	s, err := openSpool(t.TempDir()+"/spool.jsonl", 200)
+	if err != nil {
+		t.Fatalf("openSpool() error = %v", err)
+	}
+	defer s.Close()
+
+	msg := queuedMessage{Topic: "automation/raw/motion/hallway", Payload: `{"state":"on"}`}
+	if err := s.Append(msg); err != nil {
+		t.Fatalf("first Append() error = %v", err)
+	}
+	if err := s.Append(msg); err != errSpoolFull {
+		t.Errorf("second Append() error = %v, want errSpoolFull", err)
+	}
+
+	got, ok, err := s.Next()
+	if err != nil || !ok || got.Topic != msg.Topic {
+		t.Fatalf("Next() = %+v, %v, %v", got, ok, err)
+	}
+	if _, ok, _ := s.Next(); ok {
+		t.Error("expected an empty spool")
+	}
+
+	// Draining frees the space
+	if err := s.Append(msg); err != nil {
+		t.Errorf("Append() after drain error = %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errSpoolFull is returned when an append would exceed the spool size limit
+var errSpoolFull = errors.New("spool is full")
+
+// spool is an append-only JSON lines file of messages that overflowed the
+// ingest queue. It is consumed from the front and truncated once empty;
+// messages left by a previous run are picked up when it is reopened.
+type spool struct {
+	path     string
+	maxBytes int64 // 0 = unbounded
+
+	w      *os.File
+	r      *os.File
+	reader *bufio.Reader
+
+	size    int64 // bytes in the file
+	offset  int64 // bytes consumed
+	pending int
+}
+
+// openSpool opens or creates the spool at path
+func openSpool(path string, maxBytes int64) (*spool, error) {
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool %s: %w", path, err)
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		w.Close()
+		return nil, fmt.Errorf("failed to open spool %s: %w", path, err)
+	}
+
+	s := &spool{path: path, maxBytes: maxBytes, w: w, r: r, reader: bufio.NewReader(r)}
+
+	// Count complete lines; a partial last line from a crash is cut off
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.close()
+			return nil, fmt.Errorf("failed to read spool %s: %w", path, err)
+		}
+		s.size += int64(len(line))
+		s.pending++
+	}
+	if err := w.Truncate(s.size); err != nil {
+		s.close()
+		return nil, fmt.Errorf("failed to truncate spool %s: %w", path, err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		s.close()
+		return nil, fmt.Errorf("failed to rewind spool %s: %w", path, err)
+	}
+	s.reader.Reset(r)
+
+	return s, nil
+}
+
+// Pending returns the number of messages not yet consumed
+func (s *spool) Pending() int {
+	return s.pending
+}
+
+// Append writes msg to the end of the spool
+func (s *spool) Append(msg queuedMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spooled message: %w", err)
+	}
+	data = append(data, '\n')
+
+	if s.maxBytes > 0 && s.size+int64(len(data)) > s.maxBytes {
+		return errSpoolFull
+	}
+	if _, err := s.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	s.size += int64(len(data))
+	s.pending++
+	return nil
+}
+
+// Next consumes the oldest message. ok is false when the spool is empty; a
+// line that cannot be decoded is consumed and returned as an error.
+func (s *spool) Next() (msg queuedMessage, ok bool, err error) {
+	if s.pending == 0 {
+		return queuedMessage{}, false, nil
+	}
+
+	line, err := s.reader.ReadBytes('\n')
+	if err != nil {
+		return queuedMessage{}, false, fmt.Errorf("failed to read spool: %w", err)
+	}
+	s.offset += int64(len(line))
+	s.pending--
+
+	if s.pending == 0 {
+		if err := s.reset(); err != nil {
+			return queuedMessage{}, false, err
+		}
+	}
+
+	if err := json.Unmarshal(bytes.TrimSpace(line), &msg); err != nil {
+		return queuedMessage{}, false, fmt.Errorf("failed to decode spooled message: %w", err)
+	}
+	return msg, true, nil
+}
+
+// reset truncates the drained spool so it does not grow across bursts
+func (s *spool) reset() error {
+	if err := s.w.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate spool: %w", err)
+	}
+	if _, err := s.r.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind spool: %w", err)
+	}
+	s.reader.Reset(s.r)
+	s.size, s.offset = 0, 0
+	return nil
+}
+
+// Close drops the consumed part of the spool, keeping unconsumed messages for
+// the next run
+func (s *spool) Close() error {
+	defer s.close()
+
+	if s.pending == 0 || s.offset == 0 {
+		return nil
+	}
+
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to compact spool: %w", err)
+	}
+	if _, err := io.Copy(f, s.reader); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to compact spool: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to compact spool: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to compact spool: %w", err)
+	}
+	return nil
+}
+
+func (s *spool) close() {
+	s.w.Close()
+	s.r.Close()
+}
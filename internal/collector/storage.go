@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
@@ -19,6 +20,10 @@ const (
 
 	// Max age for sorted set entries (24 hours in milliseconds)
 	maxAge = 24 * 60 * 60 * 1000
+
+	// transientTTL keeps privacy-zone motion for the occupancy agent's
+	// longest analysis window only
+	transientTTL = time.Hour
 )
 
 // Storage handles Redis storage operations for sensor data
@@ -27,11 +32,11 @@ type Storage struct {
 	mqtt             mqtt.Client
 	maxSensorHistory int
 	logger           *slog.Logger
-	timeManager      *TimeManager
+	timeManager      *clock.TimeManager
 }
 
 // NewStorage creates a new storage handler
-func NewStorage(redisClient redis.Client, mqttClient mqtt.Client, cfg *config.Config, logger *slog.Logger, timeManager *TimeManager) *Storage {
+func NewStorage(redisClient redis.Client, mqttClient mqtt.Client, cfg *config.Config, logger *slog.Logger, timeManager *clock.TimeManager) *Storage {
 	return &Storage{
 		redis:            redisClient,
 		mqtt:             mqttClient,
@@ -53,6 +58,10 @@ func (s *Storage) StoreSensorData(ctx context.Context, msg *SensorMessage, proce
 		return s.storeMediaData(ctx, msg, processor)
 	case "lighting":
 		return s.storeLightingData(ctx, msg, processor)
+	case "presence":
+		return s.storePresenceData(ctx, msg, processor)
+	case "power", "energy":
+		return s.storePowerData(ctx, msg, processor)
 	default:
 		return s.storeGenericData(ctx, msg, processor)
 	}
@@ -63,6 +72,17 @@ func (s *Storage) StoreSensorData(ctx context.Context, msg *SensorMessage, proce
 // - sensor:motion:{location} (sorted set)
 // - meta:motion:{location} (hash with lastMotionTime)
 func (s *Storage) storeMotionData(ctx context.Context, msg *SensorMessage, processor *Processor) error {
+	return s.storeMotion(ctx, msg, processor, sensorDataTTL)
+}
+
+// StoreTransientMotion stores motion from a privacy zone for transientTTL
+// only, long enough for occupancy analysis and nothing more
+func (s *Storage) StoreTransientMotion(ctx context.Context, msg *SensorMessage, processor *Processor) error {
+	return s.storeMotion(ctx, msg, processor, transientTTL)
+}
+
+// storeMotion stores motion data, keeping entries and keys for retention
+func (s *Storage) storeMotion(ctx context.Context, msg *SensorMessage, processor *Processor, retention time.Duration) error {
 	key := redis.MotionSensorKey(msg.Location)
 	metaKey := redis.MotionMetaKey(msg.Location)
 
@@ -96,19 +116,19 @@ func (s *Storage) storeMotionData(ctx context.Context, msg *SensorMessage, proce
 			s.logger.Warn("Failed to update motion metadata", "location", msg.Location, "error", err)
 			// Don't fail the entire operation if metadata update fails
 		}
-		if err := s.redis.Expire(ctx, metaKey, sensorDataTTL); err != nil {
+		if err := s.redis.Expire(ctx, metaKey, retention); err != nil {
 			s.logger.Warn("Failed to set TTL on motion metadata", "location", msg.Location, "error", err)
 		}
 	}
 
-	// Clean old entries (older than 24 hours)
-	maxAgeTimestamp := msg.CollectedAt - maxAge
+	// Clean old entries (older than the retention)
+	maxAgeTimestamp := msg.CollectedAt - retention.Milliseconds()
 	if err := s.redis.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(maxAgeTimestamp, 10)); err != nil {
 		s.logger.Warn("Failed to clean old motion data", "location", msg.Location, "error", err)
 	}
 
 	// Set TTL
-	if err := s.redis.Expire(ctx, key, sensorDataTTL); err != nil {
+	if err := s.redis.Expire(ctx, key, retention); err != nil {
 		return fmt.Errorf("failed to set TTL on motion data: %w", err)
 	}
 
@@ -330,6 +350,105 @@ func (s *Storage) storeLightingData(ctx context.Context, msg *SensorMessage, pro
 	return nil
 }
 
+// storePresenceData stores occupant presence events (BLE/phone) for occupant attribution
+// Pattern: sorted set for time-series queries
+// - sensor:presence:{location} (sorted set, members carry the occupant identifier)
+func (s *Storage) storePresenceData(ctx context.Context, msg *SensorMessage, processor *Processor) error {
+	key := redis.PresenceSensorKey(msg.Location)
+
+	// Build presence data
+	presenceData := processor.BuildPresenceData(msg)
+
+	if presenceData.Occupant == "" {
+		s.logger.Warn("Presence event without occupant identifier", "location", msg.Location)
+	}
+
+	jsonData, err := json.Marshal(presenceData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal presence data: %w", err)
+	}
+
+	// Add to sorted set with timestamp as score
+	score := float64(msg.CollectedAt)
+	if err := s.redis.ZAdd(ctx, key, score, jsonData); err != nil {
+		return fmt.Errorf("failed to add presence data to sorted set: %w", err)
+	}
+
+	// Publish to automation/sensor/presence/{location} as trigger
+	topic := fmt.Sprintf("automation/sensor/presence/%s", msg.Location)
+	if err := s.mqtt.Publish(topic, 0, false, jsonData); err != nil {
+		s.logger.Warn("Failed to publish presence sensor trigger",
+			"topic", topic,
+			"error", err)
+		// Don't fail the whole operation if publish fails
+	}
+
+	// Clean old entries (older than 24 hours)
+	maxAgeTimestamp := msg.CollectedAt - maxAge
+	if err := s.redis.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(maxAgeTimestamp, 10)); err != nil {
+		s.logger.Warn("Failed to clean old presence data", "location", msg.Location, "error", err)
+	}
+
+	// Set TTL
+	if err := s.redis.Expire(ctx, key, sensorDataTTL); err != nil {
+		return fmt.Errorf("failed to set TTL on presence data: %w", err)
+	}
+
+	s.logger.Debug("Stored presence data",
+		"location", msg.Location,
+		"occupant", presenceData.Occupant,
+		"state", presenceData.State,
+		"source", presenceData.Source)
+
+	return nil
+}
+
+// storePowerData stores power/energy readings used for appliance signatures
+// Pattern: sorted set for time-series queries
+func (s *Storage) storePowerData(ctx context.Context, msg *SensorMessage, processor *Processor) error {
+	key := redis.PowerSensorKey(msg.Location)
+
+	powerData := processor.BuildPowerData(msg)
+
+	jsonData, err := json.Marshal(powerData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal power data: %w", err)
+	}
+
+	// Add to sorted set with timestamp as score
+	score := float64(msg.CollectedAt)
+	if err := s.redis.ZAdd(ctx, key, score, jsonData); err != nil {
+		return fmt.Errorf("failed to add power data to sorted set: %w", err)
+	}
+
+	// Publish to automation/sensor/power/{location} as trigger
+	topic := fmt.Sprintf("automation/sensor/power/%s", msg.Location)
+	if err := s.mqtt.Publish(topic, 0, false, jsonData); err != nil {
+		s.logger.Warn("Failed to publish power sensor trigger",
+			"topic", topic,
+			"error", err)
+		// Don't fail the whole operation if publish fails
+	}
+
+	// Clean old entries (older than 24 hours)
+	maxAgeTimestamp := msg.CollectedAt - maxAge
+	if err := s.redis.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(maxAgeTimestamp, 10)); err != nil {
+		s.logger.Warn("Failed to clean old power data", "location", msg.Location, "error", err)
+	}
+
+	// Set TTL
+	if err := s.redis.Expire(ctx, key, sensorDataTTL); err != nil {
+		return fmt.Errorf("failed to set TTL on power data: %w", err)
+	}
+
+	s.logger.Debug("Stored power data",
+		"location", msg.Location,
+		"watts", powerData.Watts,
+		"appliance", powerData.Appliance)
+
+	return nil
+}
+
 // storeGenericData stores unknown sensor types using list + metadata hash
 // Pattern from redis-schema.md:
 // - sensor:{sensor_type}:{location} (list)
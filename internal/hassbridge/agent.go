@@ -0,0 +1,232 @@
+package hassbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// Agent bridges Jeeves and Home Assistant over MQTT: it announces Jeeves
+// outputs through HA MQTT discovery and feeds HA state changes into the
+// collector's raw sensor topics
+type Agent struct {
+	mqtt     mqtt.Client
+	cfg      *config.Config
+	logger   *slog.Logger
+	mappings map[string]EntityMapping
+
+	// Rooms whose occupancy entity has been announced
+	roomsMux sync.Mutex
+	rooms    map[string]bool
+}
+
+// NewAgent creates a Home Assistant bridge agent
+func NewAgent(mqttClient mqtt.Client, cfg *config.Config, logger *slog.Logger) (*Agent, error) {
+	mappings, err := ParseEntityMap(cfg.HassEntityMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse entity map: %w", err)
+	}
+
+	return &Agent{
+		mqtt:     mqttClient,
+		cfg:      cfg,
+		logger:   logger.With("component", "hass-bridge"),
+		mappings: mappings,
+		rooms:    make(map[string]bool),
+	}, nil
+}
+
+// Start connects to MQTT, announces the household entities and starts bridging
+func (a *Agent) Start(ctx context.Context) error {
+	a.logger.Info("Starting Home Assistant bridge",
+		"discovery_prefix", a.cfg.HassDiscoveryPrefix,
+		"node_id", a.cfg.HassNodeID,
+		"event_topic", a.cfg.HassEventTopic,
+		"mapped_entities", len(a.mappings))
+
+	if err := a.mqtt.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to MQTT: %w", err)
+	}
+
+	for _, entity := range staticEntities(a.cfg.HassNodeID) {
+		if err := a.announce(entity); err != nil {
+			return err
+		}
+	}
+
+	// Rooms are announced as their first occupancy context arrives
+	occupancyTopic := fmt.Sprintf(occupancyTopicFmt, "+")
+	if err := a.mqtt.Subscribe(occupancyTopic, 0, a.handleOccupancy); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", occupancyTopic, err)
+	}
+
+	// Home Assistant restarts forget non-retained state; re-announce when it comes back
+	statusTopic := a.cfg.HassDiscoveryPrefix + "/status"
+	if err := a.mqtt.Subscribe(statusTopic, 0, a.handleHassStatus); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", statusTopic, err)
+	}
+
+	if len(a.mappings) > 0 {
+		if err := a.mqtt.Subscribe(a.cfg.HassEventTopic, 0, a.handleEvent); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", a.cfg.HassEventTopic, err)
+		}
+	} else {
+		a.logger.Info("No entity mappings configured, Home Assistant sensor ingestion disabled")
+	}
+
+	if err := a.mqtt.Publish(availabilityTopic, 1, true, []byte(payloadOnline)); err != nil {
+		a.logger.Warn("Failed to publish availability", "error", err)
+	}
+
+	a.logger.Info("Home Assistant bridge started and ready")
+
+	<-ctx.Done()
+	a.logger.Info("Home Assistant bridge stopping")
+	return nil
+}
+
+// Stop marks the entities unavailable and disconnects
+func (a *Agent) Stop() error {
+	a.logger.Info("Stopping Home Assistant bridge")
+
+	if a.mqtt.IsConnected() {
+		if err := a.mqtt.Publish(availabilityTopic, 1, true, []byte(payloadOffline)); err != nil {
+			a.logger.Warn("Failed to publish availability", "error", err)
+		}
+	}
+	a.mqtt.Disconnect()
+
+	a.logger.Info("Home Assistant bridge stopped")
+	return nil
+}
+
+// announce publishes an entity's retained discovery config
+func (a *Agent) announce(entity discoveryEntity) error {
+	payload, err := json.Marshal(entity.config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal discovery config: %w", err)
+	}
+
+	topic := entity.configTopic(a.cfg.HassDiscoveryPrefix, a.cfg.HassNodeID)
+	if err := a.mqtt.Publish(topic, 1, true, payload); err != nil {
+		return fmt.Errorf("failed to publish discovery config %s: %w", topic, err)
+	}
+
+	a.logger.Debug("Announced Home Assistant entity", "topic", topic, "name", entity.config.Name)
+	return nil
+}
+
+// handleOccupancy announces the occupancy entity of a room seen for the first time
+func (a *Agent) handleOccupancy(msg mqtt.Message) {
+	parts := strings.Split(msg.Topic(), "/")
+	location := parts[len(parts)-1]
+	if location == "" {
+		return
+	}
+
+	a.roomsMux.Lock()
+	seen := a.rooms[location]
+	a.rooms[location] = true
+	a.roomsMux.Unlock()
+	if seen {
+		return
+	}
+
+	if err := a.announceRoom(location); err != nil {
+		a.logger.Error("Failed to announce room occupancy", "location", location, "error", err)
+		a.roomsMux.Lock()
+		delete(a.rooms, location)
+		a.roomsMux.Unlock()
+		return
+	}
+	a.logger.Info("Announced room occupancy entity", "location", location)
+}
+
+// announceRoom announces a room's occupancy entity, plus label buttons when
+// the occupancy agent runs in training mode
+func (a *Agent) announceRoom(location string) error {
+	entities := []discoveryEntity{occupancyEntity(a.cfg.HassNodeID, location)}
+	if a.cfg.OccupancyTrainingMode {
+		entities = append(entities, labelButtons(a.cfg.HassNodeID, location)...)
+	}
+	for _, entity := range entities {
+		if err := a.announce(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleHassStatus re-announces every entity when Home Assistant comes online
+func (a *Agent) handleHassStatus(msg mqtt.Message) {
+	if string(msg.Payload()) != payloadOnline {
+		return
+	}
+
+	a.logger.Info("Home Assistant online, re-announcing entities")
+	for _, entity := range staticEntities(a.cfg.HassNodeID) {
+		if err := a.announce(entity); err != nil {
+			a.logger.Error("Failed to re-announce entity", "error", err)
+		}
+	}
+
+	a.roomsMux.Lock()
+	rooms := make([]string, 0, len(a.rooms))
+	for location := range a.rooms {
+		rooms = append(rooms, location)
+	}
+	a.roomsMux.Unlock()
+
+	for _, location := range rooms {
+		if err := a.announceRoom(location); err != nil {
+			a.logger.Error("Failed to re-announce room occupancy", "location", location, "error", err)
+		}
+	}
+	if err := a.mqtt.Publish(availabilityTopic, 1, true, []byte(payloadOnline)); err != nil {
+		a.logger.Warn("Failed to publish availability", "error", err)
+	}
+}
+
+// handleEvent forwards state changes of mapped entities to automation/raw/...
+func (a *Agent) handleEvent(msg mqtt.Message) {
+	var event Event
+	if err := json.Unmarshal(msg.Payload(), &event); err != nil {
+		a.logger.Warn("Failed to parse Home Assistant event", "error", err)
+		return
+	}
+
+	mapping, ok := a.mappings[event.EventData.EntityID]
+	if !ok {
+		return
+	}
+
+	raw, ok, err := translateEvent(event, mapping)
+	if err != nil {
+		a.logger.Warn("Failed to translate Home Assistant event", "entity_id", mapping.EntityID, "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"data": raw.Data})
+	if err != nil {
+		a.logger.Error("Failed to marshal sensor message", "error", err)
+		return
+	}
+	if err := a.mqtt.Publish(raw.Topic, 0, false, payload); err != nil {
+		a.logger.Error("Failed to publish sensor message", "topic", raw.Topic, "error", err)
+		return
+	}
+
+	a.logger.Debug("Forwarded Home Assistant state",
+		"entity_id", mapping.EntityID,
+		"topic", raw.Topic,
+		"state", raw.Data["state"])
+}
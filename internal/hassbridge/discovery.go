@@ -0,0 +1,168 @@
+package hassbridge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/prediction"
+	"github.com/saaga0h/jeeves-platform/internal/occupancy"
+)
+
+// Jeeves topics exposed as Home Assistant entities
+const (
+	occupancyTopicFmt = "automation/context/occupancy/%s"
+	dailyReportTopic  = "automation/behavior/report/daily"
+	availabilityTopic = "automation/hass/status"
+	payloadOnline     = "online"
+	payloadOffline    = "offline"
+)
+
+// discoveryConfig is one entity's MQTT discovery message. Entities read the
+// Jeeves topics directly through value templates, so the bridge never has to
+// republish state.
+type discoveryConfig struct {
+	Name                   string                 `json:"name"`
+	UniqueID               string                 `json:"unique_id"`
+	ObjectID               string                 `json:"object_id"`
+	DeviceClass            string                 `json:"device_class,omitempty"`
+	Icon                   string                 `json:"icon,omitempty"`
+	StateTopic             string                 `json:"state_topic,omitempty"`
+	ValueTemplate          string                 `json:"value_template,omitempty"`
+	CommandTopic           string                 `json:"command_topic,omitempty"`
+	PayloadPress           string                 `json:"payload_press,omitempty"`
+	JSONAttributesTopic    string                 `json:"json_attributes_topic,omitempty"`
+	JSONAttributesTemplate string                 `json:"json_attributes_template,omitempty"`
+	AvailabilityTopic      string                 `json:"availability_topic"`
+	Device                 map[string]interface{} `json:"device"`
+}
+
+// discoveryEntity pairs a component ("sensor", "binary_sensor", "button") with its config
+type discoveryEntity struct {
+	component string
+	config    discoveryConfig
+}
+
+// eventTemplate renders expr with e bound to an event's payload, unwrapped
+// from its envelope when it has one (see pkg/events)
+func eventTemplate(expr string) string {
+	return "{% set e = value_json.payload if value_json.payload is defined else value_json %}{{ " + expr + " }}"
+}
+
+// configTopic returns {prefix}/{component}/{node_id}/{object_id}/config
+func (e discoveryEntity) configTopic(prefix, nodeID string) string {
+	return fmt.Sprintf("%s/%s/%s/%s/config", prefix, e.component, nodeID, e.config.ObjectID)
+}
+
+func device(nodeID string) map[string]interface{} {
+	return map[string]interface{}{
+		"identifiers":  []string{nodeID},
+		"name":         "J.E.E.V.E.S.",
+		"manufacturer": "Jeeves",
+		"model":        "Behavior platform",
+	}
+}
+
+// occupancyEntity exposes a room's occupancy context as a binary sensor
+func occupancyEntity(nodeID, location string) discoveryEntity {
+	topic := fmt.Sprintf(occupancyTopicFmt, location)
+	objectID := fmt.Sprintf("%s_occupancy_%s", nodeID, location)
+	return discoveryEntity{
+		component: "binary_sensor",
+		config: discoveryConfig{
+			Name:                   fmt.Sprintf("%s occupancy", roomName(location)),
+			UniqueID:               objectID,
+			ObjectID:               objectID,
+			DeviceClass:            "occupancy",
+			StateTopic:             topic,
+			ValueTemplate:          eventTemplate("'ON' if e.state in ['occupied', 'likely_empty'] else 'OFF'"),
+			JSONAttributesTopic:    topic,
+			JSONAttributesTemplate: eventTemplate("{'confidence': e.data.confidence, 'method': e.data.method, 'reasoning': e.data.reasoning} | tojson"),
+			AvailabilityTopic:      availabilityTopic,
+			Device:                 device(nodeID),
+		},
+	}
+}
+
+// labelButtons let the user mark a room's actual occupancy while the
+// occupancy agent is in training mode
+func labelButtons(nodeID, location string) []discoveryEntity {
+	topic := fmt.Sprintf(occupancy.LabelTopic, location)
+	buttons := make([]discoveryEntity, 0, 2)
+	for _, label := range []string{"occupied", "empty"} {
+		objectID := fmt.Sprintf("%s_label_%s_%s", nodeID, location, label)
+		buttons = append(buttons, discoveryEntity{
+			component: "button",
+			config: discoveryConfig{
+				Name:              fmt.Sprintf("%s is %s", roomName(location), label),
+				UniqueID:          objectID,
+				ObjectID:          objectID,
+				Icon:              "mdi:tag-check-outline",
+				CommandTopic:      topic,
+				PayloadPress:      label,
+				AvailabilityTopic: availabilityTopic,
+				Device:            device(nodeID),
+			},
+		})
+	}
+	return buttons
+}
+
+// staticEntities are the household-wide sensors
+func staticEntities(nodeID string) []discoveryEntity {
+	return []discoveryEntity{
+		{
+			component: "sensor",
+			config: discoveryConfig{
+				Name:                   "Current pattern",
+				UniqueID:               nodeID + "_current_pattern",
+				ObjectID:               nodeID + "_current_pattern",
+				Icon:                   "mdi:timeline-clock",
+				StateTopic:             prediction.PredictionTopic,
+				ValueTemplate:          eventTemplate("e.pattern_name"),
+				JSONAttributesTopic:    prediction.PredictionTopic,
+				JSONAttributesTemplate: eventTemplate("{'pattern_id': e.pattern_id, 'pattern_type': e.pattern_type, 'current_location': e.current_location} | tojson"),
+				AvailabilityTopic:      availabilityTopic,
+				Device:                 device(nodeID),
+			},
+		},
+		{
+			component: "sensor",
+			config: discoveryConfig{
+				Name:                   "Predicted next room",
+				UniqueID:               nodeID + "_predicted_location",
+				ObjectID:               nodeID + "_predicted_location",
+				Icon:                   "mdi:crystal-ball",
+				StateTopic:             prediction.PredictionTopic,
+				ValueTemplate:          eventTemplate("e.next_location"),
+				JSONAttributesTopic:    prediction.PredictionTopic,
+				JSONAttributesTemplate: eventTemplate("{'confidence': e.confidence, 'expected_in_minutes': e.expected_in_minutes, 'expires_at': e.expires_at} | tojson"),
+				AvailabilityTopic:      availabilityTopic,
+				Device:                 device(nodeID),
+			},
+		},
+		{
+			component: "sensor",
+			config: discoveryConfig{
+				Name:                   "Behavior anomalies",
+				UniqueID:               nodeID + "_anomalies",
+				ObjectID:               nodeID + "_anomalies",
+				Icon:                   "mdi:alert-circle-outline",
+				StateTopic:             dailyReportTopic,
+				ValueTemplate:          "{{ value_json.anomalies | length }}",
+				JSONAttributesTopic:    dailyReportTopic,
+				JSONAttributesTemplate: "{{ {'period_start': value_json.start, 'anomalies': value_json.anomalies} | tojson }}",
+				AvailabilityTopic:      availabilityTopic,
+				Device:                 device(nodeID),
+			},
+		},
+	}
+}
+
+// roomName turns living_room into "Living room"
+func roomName(location string) string {
+	name := strings.ReplaceAll(location, "_", " ")
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
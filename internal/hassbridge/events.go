@@ -0,0 +1,127 @@
+package hassbridge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a Home Assistant bus event as published by mqtt_eventstream
+type Event struct {
+	EventType string    `json:"event_type"`
+	EventData EventData `json:"event_data"`
+}
+
+// EventData is the payload of a state_changed event
+type EventData struct {
+	EntityID string `json:"entity_id"`
+	NewState *State `json:"new_state"`
+}
+
+// State is an entity state snapshot
+type State struct {
+	State       string                 `json:"state"`
+	Attributes  map[string]interface{} `json:"attributes"`
+	LastChanged time.Time              `json:"last_changed"`
+	Context     struct {
+		UserID *string `json:"user_id"`
+	} `json:"context"`
+}
+
+// RawMessage is a sensor reading for automation/raw/{sensor_type}/{location}
+type RawMessage struct {
+	Topic string
+	Data  map[string]interface{}
+}
+
+// translateEvent converts a state_changed event for a mapped entity into the
+// raw sensor message the collector expects. ok is false for events that carry
+// no usable reading (other event types, unavailable states, non-numeric values).
+func translateEvent(event Event, mapping EntityMapping) (msg RawMessage, ok bool, err error) {
+	if event.EventType != "state_changed" || event.EventData.NewState == nil {
+		return RawMessage{}, false, nil
+	}
+	state := event.EventData.NewState
+	if state.State == "unavailable" || state.State == "unknown" {
+		return RawMessage{}, false, nil
+	}
+
+	sensorType := mapping.SensorType
+	if sensorType == "" {
+		sensorType = inferSensorType(mapping.EntityID, state.Attributes)
+	}
+
+	data := map[string]interface{}{
+		"entity_id": mapping.EntityID,
+		"source":    "home_assistant",
+	}
+	if !state.LastChanged.IsZero() {
+		data["timestamp"] = state.LastChanged.Format(time.RFC3339)
+	}
+
+	switch sensorType {
+	case SensorMotion:
+		data["state"] = onOff(state.State)
+
+	case SensorPresence:
+		// Trackers report "home"/zone names, presence binary sensors "on"/"off"
+		present := state.State == "on" || state.State == "home"
+		data["state"] = "empty"
+		if present {
+			data["state"] = "occupied"
+		}
+		data["occupant"] = occupantName(mapping.EntityID, state.Attributes)
+
+	case SensorIlluminance, SensorTemperature:
+		value, err := strconv.ParseFloat(state.State, 64)
+		if err != nil {
+			return RawMessage{}, false, fmt.Errorf("non-numeric %s state %q for %s", sensorType, state.State, mapping.EntityID)
+		}
+		data["value"] = value
+		if unit, ok := state.Attributes["unit_of_measurement"].(string); ok {
+			data["unit"] = unit
+		}
+
+	case SensorLighting:
+		data["state"] = onOff(state.State)
+		if v, ok := state.Attributes["brightness"]; ok {
+			data["brightness"] = v
+		}
+		if v, ok := state.Attributes["color_temp"]; ok {
+			data["color_temp"] = v
+		}
+		// Changes made by a logged-in user count as manual adjustments
+		data["source"] = "automated"
+		if state.Context.UserID != nil {
+			data["source"] = "manual"
+		}
+
+	default:
+		return RawMessage{}, false, fmt.Errorf("cannot infer sensor type for %s; map it as entity_id=sensor_type:location", mapping.EntityID)
+	}
+
+	return RawMessage{
+		Topic: fmt.Sprintf("automation/raw/%s/%s", sensorType, mapping.Location),
+		Data:  data,
+	}, true, nil
+}
+
+func onOff(state string) string {
+	if state == "on" {
+		return "on"
+	}
+	return "off"
+}
+
+// occupantName uses a person's object ID (person.anna → anna), falling back to
+// the friendly name for other trackers
+func occupantName(entityID string, attributes map[string]interface{}) string {
+	domain, objectID, _ := strings.Cut(entityID, ".")
+	if domain != "person" {
+		if name, ok := attributes["friendly_name"].(string); ok && name != "" {
+			return name
+		}
+	}
+	return objectID
+}
@@ -0,0 +1,212 @@
+package hassbridge
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseEntityMap(t *testing.T) {
+	mappings, err := ParseEntityMap([]string{
+		"binary_sensor.kitchen_pir=kitchen",
+		"sensor.hall_lux=illuminance:hallway",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m := mappings["binary_sensor.kitchen_pir"]; m.Location != "kitchen" || m.SensorType != "" {
+		t.Errorf("unexpected kitchen mapping: %+v", m)
+	}
+	if m := mappings["sensor.hall_lux"]; m.Location != "hallway" || m.SensorType != SensorIlluminance {
+		t.Errorf("unexpected hallway mapping: %+v", m)
+	}
+
+	invalid := [][]string{
+		{"kitchen"},
+		{"binary_sensor.kitchen_pir="},
+		{"binary_sensor.kitchen_pir=sound:kitchen"},
+		{"light.kitchen=lighting:"},
+		{"light.kitchen=kitchen", "light.kitchen=dining_room"},
+	}
+	for _, entries := range invalid {
+		if _, err := ParseEntityMap(entries); err == nil {
+			t.Errorf("expected error for %v", entries)
+		}
+	}
+}
+
+func TestInferSensorType(t *testing.T) {
+	tests := []struct {
+		entityID    string
+		deviceClass string
+		expected    string
+	}{
+		{"light.kitchen_ceiling", "", SensorLighting},
+		{"person.anna", "", SensorPresence},
+		{"binary_sensor.kitchen_pir", "motion", SensorMotion},
+		{"binary_sensor.study_mmwave", "occupancy", SensorMotion},
+		{"binary_sensor.front_door", "door", ""},
+		{"sensor.hall_lux", "illuminance", SensorIlluminance},
+		{"sensor.bedroom_temp", "temperature", SensorTemperature},
+		{"sensor.bedroom_humidity", "humidity", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entityID, func(t *testing.T) {
+			attrs := map[string]interface{}{}
+			if tt.deviceClass != "" {
+				attrs["device_class"] = tt.deviceClass
+			}
+			if got := inferSensorType(tt.entityID, attrs); got != tt.expected {
+				t.Errorf("inferSensorType(%s) = %q, want %q", tt.entityID, got, tt.expected)
+			}
+		})
+	}
+}
+
+func parseEvent(t *testing.T, raw string) Event {
+	t.Helper()
+	var event Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatalf("failed to parse event: %v", err)
+	}
+	return event
+}
+
+func TestTranslateEvent_Motion(t *testing.T) {
+	event := parseEvent(t, `{
+		"event_type": "state_changed",
+		"event_data": {
+			"entity_id": "binary_sensor.kitchen_pir",
+			"new_state": {
+				"state": "on",
+				"attributes": {"device_class": "motion"},
+				"last_changed": "2025-03-01T07:00:00+00:00"
+			}
+		}
+	}`)
+
+	msg, ok, err := translateEvent(event, EntityMapping{EntityID: "binary_sensor.kitchen_pir", Location: "kitchen"})
+	if err != nil || !ok {
+		t.Fatalf("expected translation, got ok=%v err=%v", ok, err)
+	}
+	if msg.Topic != "automation/raw/motion/kitchen" {
+		t.Errorf("unexpected topic %s", msg.Topic)
+	}
+	if msg.Data["state"] != "on" {
+		t.Errorf("expected state on, got %v", msg.Data["state"])
+	}
+	if msg.Data["timestamp"] != "2025-03-01T07:00:00Z" {
+		t.Errorf("unexpected timestamp %v", msg.Data["timestamp"])
+	}
+}
+
+func TestTranslateEvent_Illuminance(t *testing.T) {
+	event := parseEvent(t, `{
+		"event_type": "state_changed",
+		"event_data": {
+			"entity_id": "sensor.hall_lux",
+			"new_state": {"state": "123.5", "attributes": {"unit_of_measurement": "lx"}}
+		}
+	}`)
+
+	mapping := EntityMapping{EntityID: "sensor.hall_lux", SensorType: SensorIlluminance, Location: "hallway"}
+	msg, ok, err := translateEvent(event, mapping)
+	if err != nil || !ok {
+		t.Fatalf("expected translation, got ok=%v err=%v", ok, err)
+	}
+	if msg.Topic != "automation/raw/illuminance/hallway" || msg.Data["value"] != 123.5 || msg.Data["unit"] != "lx" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	event.EventData.NewState.State = "bright"
+	if _, _, err := translateEvent(event, mapping); err == nil {
+		t.Error("expected error for non-numeric illuminance")
+	}
+}
+
+func TestTranslateEvent_LightingSource(t *testing.T) {
+	event := parseEvent(t, `{
+		"event_type": "state_changed",
+		"event_data": {
+			"entity_id": "light.kitchen_ceiling",
+			"new_state": {"state": "off", "attributes": {}, "context": {"user_id": "abc123"}}
+		}
+	}`)
+	mapping := EntityMapping{EntityID: "light.kitchen_ceiling", Location: "kitchen"}
+
+	msg, ok, err := translateEvent(event, mapping)
+	if err != nil || !ok {
+		t.Fatalf("expected translation, got ok=%v err=%v", ok, err)
+	}
+	if msg.Data["source"] != "manual" {
+		t.Errorf("expected manual source for user change, got %v", msg.Data["source"])
+	}
+
+	event.EventData.NewState.Context.UserID = nil
+	msg, _, _ = translateEvent(event, mapping)
+	if msg.Data["source"] != "automated" {
+		t.Errorf("expected automated source, got %v", msg.Data["source"])
+	}
+}
+
+func TestTranslateEvent_Presence(t *testing.T) {
+	event := parseEvent(t, `{
+		"event_type": "state_changed",
+		"event_data": {"entity_id": "person.anna", "new_state": {"state": "home", "attributes": {}}}
+	}`)
+
+	msg, ok, err := translateEvent(event, EntityMapping{EntityID: "person.anna", Location: "hallway"})
+	if err != nil || !ok {
+		t.Fatalf("expected translation, got ok=%v err=%v", ok, err)
+	}
+	if msg.Data["state"] != "occupied" || msg.Data["occupant"] != "anna" {
+		t.Errorf("unexpected presence data: %+v", msg.Data)
+	}
+}
+
+func TestTranslateEvent_Skipped(t *testing.T) {
+	mapping := EntityMapping{EntityID: "binary_sensor.kitchen_pir", SensorType: SensorMotion, Location: "kitchen"}
+
+	for _, raw := range []string{
+		`{"event_type": "call_service", "event_data": {"entity_id": "binary_sensor.kitchen_pir"}}`,
+		`{"event_type": "state_changed", "event_data": {"entity_id": "binary_sensor.kitchen_pir", "new_state": null}}`,
+		`{"event_type": "state_changed", "event_data": {"entity_id": "binary_sensor.kitchen_pir", "new_state": {"state": "unavailable"}}}`,
+	} {
+		if _, ok, err := translateEvent(parseEvent(t, raw), mapping); ok || err != nil {
+			t.Errorf("expected %s to be skipped, got ok=%v err=%v", raw, ok, err)
+		}
+	}
+}
+
+func TestOccupancyEntity(t *testing.T) {
+	entity := occupancyEntity("jeeves", "living_room")
+
+	if got := entity.configTopic("homeassistant", "jeeves"); got != "homeassistant/binary_sensor/jeeves/jeeves_occupancy_living_room/config" {
+		t.Errorf("unexpected config topic %s", got)
+	}
+	if entity.config.Name != "Living room occupancy" {
+		t.Errorf("unexpected name %q", entity.config.Name)
+	}
+	if entity.config.StateTopic != "automation/context/occupancy/living_room" {
+		t.Errorf("unexpected state topic %s", entity.config.StateTopic)
+	}
+}
+
+func TestLabelButtons(t *testing.T) {
+	buttons := labelButtons("jeeves", "study")
+	if len(buttons) != 2 {
+		t.Fatalf("got %d buttons, want 2", len(buttons))
+	}
+
+	occupied := buttons[0]
+	if got := occupied.configTopic("homeassistant", "jeeves"); got != "homeassistant/button/jeeves/jeeves_label_study_occupied/config" {
+		t.Errorf("unexpected config topic %s", got)
+	}
+	if occupied.config.CommandTopic != "automation/occupancy/label/study" || occupied.config.PayloadPress != "occupied" {
+		t.Errorf("unexpected command %s %q", occupied.config.CommandTopic, occupied.config.PayloadPress)
+	}
+	if buttons[1].config.PayloadPress != "empty" || buttons[1].config.Name != "Study is empty" {
+		t.Errorf("unexpected empty button %+v", buttons[1].config)
+	}
+}
@@ -0,0 +1,90 @@
+package hassbridge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sensor types understood by the collector (automation/raw/{type}/{location})
+const (
+	SensorMotion      = "motion"
+	SensorPresence    = "presence"
+	SensorIlluminance = "illuminance"
+	SensorTemperature = "temperature"
+	SensorLighting    = "lighting"
+)
+
+var knownSensorTypes = map[string]bool{
+	SensorMotion:      true,
+	SensorPresence:    true,
+	SensorIlluminance: true,
+	SensorTemperature: true,
+	SensorLighting:    true,
+}
+
+// EntityMapping routes one Home Assistant entity to a Jeeves location. An
+// empty SensorType is inferred from the entity's domain and device class.
+type EntityMapping struct {
+	EntityID   string
+	SensorType string
+	Location   string
+}
+
+// ParseEntityMap parses "entity_id=location" or "entity_id=sensor_type:location"
+// entries, e.g. "binary_sensor.kitchen_pir=kitchen" or
+// "sensor.hall_lux=illuminance:hallway"
+func ParseEntityMap(entries []string) (map[string]EntityMapping, error) {
+	mappings := make(map[string]EntityMapping, len(entries))
+	for _, entry := range entries {
+		entityID, target, ok := strings.Cut(entry, "=")
+		entityID, target = strings.TrimSpace(entityID), strings.TrimSpace(target)
+		if !ok || entityID == "" || target == "" || !strings.Contains(entityID, ".") {
+			return nil, fmt.Errorf("invalid entity mapping %q (expected entity_id=[sensor_type:]location)", entry)
+		}
+
+		m := EntityMapping{EntityID: entityID, Location: target}
+		if sensorType, location, ok := strings.Cut(target, ":"); ok {
+			if !knownSensorTypes[sensorType] {
+				return nil, fmt.Errorf("invalid entity mapping %q: unknown sensor type %q", entry, sensorType)
+			}
+			m.SensorType, m.Location = sensorType, location
+		}
+		if m.Location == "" {
+			return nil, fmt.Errorf("invalid entity mapping %q: empty location", entry)
+		}
+		if _, dup := mappings[entityID]; dup {
+			return nil, fmt.Errorf("entity %s is mapped twice", entityID)
+		}
+		mappings[entityID] = m
+	}
+	return mappings, nil
+}
+
+// inferSensorType derives the Jeeves sensor type from an entity's domain and
+// device_class attribute, or "" when it can't be used as sensor input
+func inferSensorType(entityID string, attributes map[string]interface{}) string {
+	domain, _, _ := strings.Cut(entityID, ".")
+	deviceClass, _ := attributes["device_class"].(string)
+
+	switch domain {
+	case "light":
+		return SensorLighting
+	case "person", "device_tracker":
+		return SensorPresence
+	case "binary_sensor":
+		switch deviceClass {
+		case "motion", "occupancy":
+			return SensorMotion
+		case "presence":
+			return SensorPresence
+		}
+	case "sensor":
+		switch deviceClass {
+		case "illuminance":
+			return SensorIlluminance
+		case "temperature":
+			return SensorTemperature
+		}
+	}
+	return ""
+}
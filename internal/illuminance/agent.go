@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
@@ -18,8 +20,15 @@ import (
 type LocationState struct {
 	LastAnalysis time.Time
 	CurrentLabel string
+	Sufficiency  string // natural light sufficiency last published
 }
 
+// Age limits for the inputs of the cloud correction
+const (
+	maxOutdoorReadingAge = 15 * time.Minute
+	maxWeatherAge        = 2 * time.Hour
+)
+
 // Agent represents the illuminance analysis agent
 type Agent struct {
 	mqtt    mqtt.Client
@@ -27,11 +36,18 @@ type Agent struct {
 	storage *Storage
 	cfg     *config.Config
 	logger  *slog.Logger
+	clock   *clock.TimeManager
 
 	// In-memory state management
 	stateMux sync.RWMutex
 	states   map[string]*LocationState
 
+	// Per-room daylight model
+	windows      map[string]RoomWindow
+	weatherMux   sync.RWMutex
+	cloudCover   *float64 // percent, from the weather context
+	cloudCoverAt time.Time
+
 	// Periodic analysis
 	ticker   *time.Ticker
 	stopChan chan struct{}
@@ -39,7 +55,14 @@ type Agent struct {
 
 // NewAgent creates a new illuminance agent
 func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Config, logger *slog.Logger) *Agent {
-	storage := NewStorage(redisClient, cfg, logger)
+	clk := clock.NewTimeManager(logger)
+	storage := NewStorage(redisClient, cfg, logger, clk)
+
+	windows, err := ParseRoomWindows(cfg.IlluminanceWindows)
+	if err != nil {
+		logger.Warn("Ignoring invalid room windows, using the default window everywhere", "error", err)
+		windows = map[string]RoomWindow{}
+	}
 
 	return &Agent{
 		mqtt:     mqttClient,
@@ -47,7 +70,9 @@ func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Conf
 		storage:  storage,
 		cfg:      cfg,
 		logger:   logger,
+		clock:    clk,
 		states:   make(map[string]*LocationState),
+		windows:  windows,
 		stopChan: make(chan struct{}),
 	}
 }
@@ -70,6 +95,11 @@ func (a *Agent) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to ping Redis: %w", err)
 	}
 
+	// Follow the e2e test runner's virtual time
+	if err := a.clock.ConfigureFromMQTT(a.mqtt); err != nil {
+		a.logger.Warn("Failed to subscribe to test mode config", "error", err)
+	}
+
 	// Subscribe to illuminance trigger topics
 	triggerTopic := "automation/sensor/illuminance/+"
 	if err := a.mqtt.Subscribe(triggerTopic, 0, a.handleTrigger); err != nil {
@@ -78,6 +108,12 @@ func (a *Agent) Start(ctx context.Context) error {
 
 	a.logger.Info("Subscribed to trigger topic", "topic", triggerTopic)
 
+	// Subscribe to weather context (cloud correction of the daylight model)
+	weatherTopic := "automation/context/weather"
+	if err := a.mqtt.Subscribe(weatherTopic, 0, a.handleWeather); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", weatherTopic, err)
+	}
+
 	// Start periodic analysis
 	a.startPeriodicAnalysis()
 
@@ -178,6 +214,51 @@ func (a *Agent) handleTrigger(msg mqtt.Message) {
 	a.analyzeLocation(ctx, location, "sensor_trigger")
 }
 
+// handleWeather keeps the latest cloud cover from the weather context
+func (a *Agent) handleWeather(msg mqtt.Message) {
+	var weather struct {
+		Data struct {
+			CloudCover *float64 `json:"cloud_cover"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg.Payload(), &weather); err != nil {
+		a.logger.Warn("Failed to parse weather context", "error", err)
+		return
+	}
+	if weather.Data.CloudCover == nil {
+		return
+	}
+
+	a.weatherMux.Lock()
+	a.cloudCover = weather.Data.CloudCover
+	a.cloudCoverAt = a.clock.Now()
+	a.weatherMux.Unlock()
+
+	a.logger.Debug("Updated cloud cover", "cloud_cover", *weather.Data.CloudCover)
+}
+
+// cloudCorrection builds the cloud correction from the outdoor sensor or
+// the latest weather context
+func (a *Agent) cloudCorrection(ctx context.Context, daylight DaylightContext) CloudCorrection {
+	var outdoorLux *float64
+	if a.cfg.IlluminanceOutdoorLocation != "" {
+		summary, err := a.storage.GetIlluminanceSummary(ctx, a.cfg.IlluminanceOutdoorLocation)
+		if err == nil && summary.LatestReading != nil &&
+			a.clock.Since(summary.LatestReading.Timestamp) < maxOutdoorReadingAge {
+			outdoorLux = &summary.LatestReading.Lux
+		}
+	}
+
+	a.weatherMux.RLock()
+	cloudCover := a.cloudCover
+	if a.clock.Since(a.cloudCoverAt) > maxWeatherAge {
+		cloudCover = nil
+	}
+	a.weatherMux.RUnlock()
+
+	return NewCloudCorrection(daylight.TheoreticalOutdoorLux, outdoorLux, cloudCover)
+}
+
 // analyzeLocation performs complete analysis for a location
 func (a *Agent) analyzeLocation(ctx context.Context, location string, trigger string) {
 	// Get data summary from Redis
@@ -212,7 +293,7 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, trigger st
 	}
 
 	// Generate illuminance abstraction
-	abstraction, err := GenerateIlluminanceAbstraction(summary, a.cfg.Latitude, a.cfg.Longitude)
+	abstraction, err := GenerateIlluminanceAbstraction(summary, a.cfg.Latitude, a.cfg.Longitude, a.clock.Now())
 	if err != nil {
 		a.logger.Error("Failed to generate abstraction",
 			"location", location,
@@ -220,12 +301,32 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, trigger st
 		return
 	}
 
+	// Model this room's daylight (the outdoor sensor itself has no windows)
+	sufficiency := ""
+	if location != a.cfg.IlluminanceOutdoorLocation {
+		window, ok := a.windows[location]
+		if !ok {
+			window = DefaultRoomWindow
+		}
+		estimate := EstimateNaturalLight(window, abstraction.Daylight,
+			a.cloudCorrection(ctx, abstraction.Daylight), a.cfg.IlluminanceTargetLux)
+		abstraction.NaturalLight = &estimate
+		sufficiency = estimate.Sufficiency
+	}
+
 	// Get or create state for this location
 	state := a.getOrCreateState(location)
 
 	// Determine if we should publish
 	newLabel := abstraction.Current.Label
 	shouldPublish := a.shouldPublish(location, state, newLabel, trigger)
+	if state.Sufficiency != "" && state.Sufficiency != sufficiency {
+		a.logger.Debug("Publishing due to natural light change",
+			"location", location,
+			"old_sufficiency", state.Sufficiency,
+			"new_sufficiency", sufficiency)
+		shouldPublish = true
+	}
 
 	if shouldPublish {
 		// Publish context message
@@ -237,7 +338,7 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, trigger st
 		}
 
 		// Update state
-		a.updateState(location, newLabel)
+		a.updateState(location, newLabel, sufficiency)
 
 		a.logger.Info("Illuminance analysis published",
 			"location", location,
@@ -273,13 +374,14 @@ func (a *Agent) getOrCreateState(location string) *LocationState {
 }
 
 // updateState updates the state for a location
-func (a *Agent) updateState(location string, newLabel string) {
+func (a *Agent) updateState(location string, newLabel string, sufficiency string) {
 	a.stateMux.Lock()
 	defer a.stateMux.Unlock()
 
 	if state, exists := a.states[location]; exists {
-		state.LastAnalysis = time.Now()
+		state.LastAnalysis = a.clock.Now()
 		state.CurrentLabel = newLabel
+		state.Sufficiency = sufficiency
 	}
 }
 
@@ -289,7 +391,7 @@ func (a *Agent) wasRecentlyAnalyzed(location string, within time.Duration) bool
 	defer a.stateMux.RUnlock()
 
 	if state, exists := a.states[location]; exists {
-		return time.Since(state.LastAnalysis) < within
+		return a.clock.Since(state.LastAnalysis) < within
 	}
 
 	return false
@@ -307,10 +409,10 @@ func (a *Agent) shouldPublish(location string, state *LocationState, newLabel st
 	}
 
 	// Condition 2: Periodic update needed (> 5 minutes since last)
-	if !state.LastAnalysis.IsZero() && time.Since(state.LastAnalysis) > 5*time.Minute {
+	if !state.LastAnalysis.IsZero() && a.clock.Since(state.LastAnalysis) > 5*time.Minute {
 		a.logger.Debug("Publishing due to periodic update",
 			"location", location,
-			"time_since_last", time.Since(state.LastAnalysis))
+			"time_since_last", a.clock.Since(state.LastAnalysis))
 		return true
 	}
 
@@ -352,12 +454,24 @@ func (a *Agent) publishContext(location string, abstraction *IlluminanceAbstract
 			"is_daytime":              abstraction.Daylight.IsDaytime,
 			"theoretical_outdoor_lux": abstraction.Daylight.TheoreticalOutdoorLux,
 			"time_of_day":             abstraction.Context.TimeOfDay,
+			"circadian_phase":         abstraction.Daylight.CircadianPhase,
 		},
-		"timestamp": time.Now().Format(time.RFC3339),
+		"timestamp": a.clock.Now().Format(time.RFC3339),
+	}
+
+	if nl := abstraction.NaturalLight; nl != nil {
+		contextMsg["data"].(map[string]interface{})["natural_light"] = map[string]interface{}{
+			"sufficiency":  nl.Sufficiency,
+			"expected_lux": nl.ExpectedLux,
+			"outdoor_lux":  nl.OutdoorLux,
+			"exposure":     nl.Exposure,
+			"cloud_factor": nl.CloudFactor,
+			"cloud_source": nl.CloudSource,
+		}
 	}
 
 	// Serialize to JSON
-	payload, err := json.Marshal(contextMsg)
+	payload, err := events.Marshal(events.ProducerIlluminance, contextMsg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal context message: %w", err)
 	}
@@ -2,10 +2,9 @@ package illuminance
 
 import (
 	"fmt"
-	"math"
 	"time"
 
-	"github.com/sixdouglas/suncalc"
+	"github.com/saaga0h/jeeves-platform/pkg/solar"
 )
 
 // IlluminanceAbstraction represents the complete temporal abstraction
@@ -34,12 +33,8 @@ type IlluminanceAbstraction struct {
 		Max10Min   float64
 		Variation  float64
 	}
-	Daylight struct {
-		TheoreticalOutdoorLux float64
-		SunAltitude           float64
-		IsDaytime             bool
-		IsGoldenHour          bool
-	}
+	Daylight     DaylightContext
+	NaturalLight *NaturalLight // per-room daylight estimate, nil when not modelled
 	Context struct {
 		TimeOfDay          string
 		LikelySources      []string
@@ -49,13 +44,22 @@ type IlluminanceAbstraction struct {
 	DataAge    time.Duration
 }
 
-// GenerateIlluminanceAbstraction creates a complete temporal abstraction
-func GenerateIlluminanceAbstraction(summary *DataSummary, lat, lon float64) (*IlluminanceAbstraction, error) {
+// DaylightContext describes the sun position and clear-sky outdoor light
+type DaylightContext struct {
+	TheoreticalOutdoorLux float64
+	SunAltitude           float64
+	SunAzimuth            float64 // compass degrees, 180 = south
+	CircadianPhase        string  // solar phase of the day (see pkg/solar)
+	IsDaytime             bool
+	IsGoldenHour          bool
+}
+
+// GenerateIlluminanceAbstraction creates a complete temporal abstraction as of now
+func GenerateIlluminanceAbstraction(summary *DataSummary, lat, lon float64, now time.Time) (*IlluminanceAbstraction, error) {
 	if summary.LatestReading == nil {
 		return nil, fmt.Errorf("no latest reading available")
 	}
 
-	now := time.Now()
 	abstraction := &IlluminanceAbstraction{}
 
 	// Set current values
@@ -148,53 +152,16 @@ func GenerateIlluminanceAbstraction(summary *DataSummary, lat, lon float64) (*Il
 }
 
 // calculateDaylightContext calculates sun position and theoretical outdoor lux
-func calculateDaylightContext(lat, lon float64, t time.Time) struct {
-	TheoreticalOutdoorLux float64
-	SunAltitude           float64
-	IsDaytime             bool
-	IsGoldenHour          bool
-} {
-	// Get sun position
-	position := suncalc.GetPosition(t, lat, lon)
-
-	// Get sun times for the day
-	times := suncalc.GetTimes(t, lat, lon)
-
-	// Calculate theoretical outdoor lux based on sun altitude
-	// Sun altitude is in radians, convert to degrees
-	altitudeDegrees := position.Altitude * (180.0 / math.Pi)
-
-	var theoreticalLux float64
-	if altitudeDegrees > 0 {
-		// Rough approximation: lux increases with sun altitude
-		// At sun altitude of 90° (overhead), theoretical max is ~120,000 lux
-		// This is a simplified model
-		theoreticalLux = 120000.0 * math.Sin(position.Altitude)
-		if theoreticalLux < 0 {
-			theoreticalLux = 0
-		}
-	} else {
-		theoreticalLux = 0
-	}
-
-	// Determine if it's daytime (sun above horizon)
-	isDaytime := altitudeDegrees > 0
-
-	// Check if it's golden hour (sun altitude between 0 and 6 degrees)
-	isGoldenHour := altitudeDegrees > 0 && altitudeDegrees < 6
-
-	// Suppress unused variable warning
-	_ = times
-
-	return struct {
-		TheoreticalOutdoorLux float64
-		SunAltitude           float64
-		IsDaytime             bool
-		IsGoldenHour          bool
-	}{
-		TheoreticalOutdoorLux: theoreticalLux,
-		SunAltitude:           altitudeDegrees,
-		IsDaytime:             isDaytime,
-		IsGoldenHour:          isGoldenHour,
+func calculateDaylightContext(lat, lon float64, t time.Time) DaylightContext {
+	position := solar.SunPosition(t, lat, lon)
+
+	return DaylightContext{
+		TheoreticalOutdoorLux: solar.TheoreticalLux(position.Elevation),
+		SunAltitude:           position.Elevation,
+		SunAzimuth:            position.Azimuth,
+		CircadianPhase:        string(solar.CircadianAt(t, lat, lon).Phase),
+		IsDaytime:             position.Elevation > 0,
+		// Golden hour: sun between 0 and 6 degrees
+		IsGoldenHour: position.Elevation > 0 && position.Elevation < 6,
 	}
 }
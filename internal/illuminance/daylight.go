@@ -0,0 +1,141 @@
+package illuminance
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoomWindow describes how a room's sensor sees daylight
+type RoomWindow struct {
+	Orientation string  // compass direction the window faces, "" = unknown
+	Azimuth     float64 // compass degrees the window faces
+	Factor      float64 // share of outdoor lux reaching the sensor with the sun straight in
+}
+
+// DefaultRoomWindow is used for rooms without configured windows: a small
+// window of unknown orientation, lit by diffuse skylight only
+var DefaultRoomWindow = RoomWindow{Factor: 0.02}
+
+var orientations = map[string]float64{
+	"n": 0, "ne": 45, "e": 90, "se": 135, "s": 180, "sw": 225, "w": 270, "nw": 315,
+	"north": 0, "northeast": 45, "east": 90, "southeast": 135,
+	"south": 180, "southwest": 225, "west": 270, "northwest": 315,
+}
+
+// diffuseShare is the part of window light that does not depend on where the sun is
+const diffuseShare = 0.4
+
+// ParseRoomWindows parses location=orientation:factor entries, where
+// orientation is a compass direction (n, se, south, ...) or degrees, e.g.
+// "living_room=south:0.03", "study=250:0.02"
+func ParseRoomWindows(entries []string) (map[string]RoomWindow, error) {
+	windows := make(map[string]RoomWindow, len(entries))
+	for _, entry := range entries {
+		location, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		location = strings.TrimSpace(location)
+		orientation, factorText, hasFactor := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok || !hasFactor || location == "" {
+			return nil, fmt.Errorf("invalid room window %q, expected location=orientation:factor", entry)
+		}
+
+		orientation = strings.ToLower(strings.TrimSpace(orientation))
+		azimuth, known := orientations[orientation]
+		if !known {
+			degrees, err := strconv.ParseFloat(orientation, 64)
+			if err != nil || degrees < 0 || degrees >= 360 {
+				return nil, fmt.Errorf("room window %q: invalid orientation %q", entry, orientation)
+			}
+			azimuth = degrees
+		}
+
+		factor, err := strconv.ParseFloat(strings.TrimSpace(factorText), 64)
+		if err != nil || factor <= 0 || factor > 1 {
+			return nil, fmt.Errorf("room window %q: factor must be in (0, 1]", entry)
+		}
+
+		windows[location] = RoomWindow{Orientation: orientation, Azimuth: azimuth, Factor: factor}
+	}
+	return windows, nil
+}
+
+// Exposure returns how much of the window's light reaches the room for the
+// given sun position: diffuse skylight plus direct sun on the (vertical) pane
+func (w RoomWindow) Exposure(daylight DaylightContext) float64 {
+	if !daylight.IsDaytime {
+		return 0
+	}
+	if w.Orientation == "" {
+		return diffuseShare
+	}
+
+	incidence := math.Cos((daylight.SunAzimuth-w.Azimuth)*math.Pi/180) *
+		math.Cos(daylight.SunAltitude*math.Pi/180)
+	return diffuseShare + (1-diffuseShare)*math.Max(0, incidence)
+}
+
+// CloudCorrection scales clear-sky outdoor light to the actual sky
+type CloudCorrection struct {
+	Factor     float64
+	Source     string   // outdoor_sensor, weather or clear_sky
+	OutdoorLux *float64 // measured outdoor lux, when a sensor is available
+}
+
+// NewCloudCorrection prefers a measured outdoor lux, then weather cloud
+// cover (percent), and otherwise assumes a clear sky
+func NewCloudCorrection(theoreticalLux float64, outdoorLux, cloudCover *float64) CloudCorrection {
+	if outdoorLux != nil && theoreticalLux > 0 {
+		return CloudCorrection{
+			Factor:     math.Min(1, math.Max(0.05, *outdoorLux/theoreticalLux)),
+			Source:     "outdoor_sensor",
+			OutdoorLux: outdoorLux,
+		}
+	}
+	if cloudCover != nil {
+		// Kasten-Czeplak: overcast sky lets through about a quarter of clear-sky light
+		cover := math.Min(100, math.Max(0, *cloudCover)) / 100
+		return CloudCorrection{Factor: 1 - 0.75*math.Pow(cover, 3.4), Source: "weather"}
+	}
+	return CloudCorrection{Factor: 1, Source: "clear_sky"}
+}
+
+// NaturalLight is a room's expected daylight and whether it is enough
+type NaturalLight struct {
+	ExpectedLux float64
+	OutdoorLux  float64
+	Exposure    float64
+	CloudFactor float64
+	CloudSource string
+	Sufficiency string // none, insufficient, partial or sufficient
+}
+
+// EstimateNaturalLight predicts the daylight reaching a room and rates it
+// against targetLux
+func EstimateNaturalLight(window RoomWindow, daylight DaylightContext, cloud CloudCorrection, targetLux float64) NaturalLight {
+	outdoor := daylight.TheoreticalOutdoorLux * cloud.Factor
+	if cloud.OutdoorLux != nil {
+		outdoor = *cloud.OutdoorLux
+	}
+
+	exposure := window.Exposure(daylight)
+	estimate := NaturalLight{
+		ExpectedLux: outdoor * window.Factor * exposure,
+		OutdoorLux:  outdoor,
+		Exposure:    exposure,
+		CloudFactor: cloud.Factor,
+		CloudSource: cloud.Source,
+	}
+
+	switch {
+	case !daylight.IsDaytime:
+		estimate.Sufficiency = "none"
+	case estimate.ExpectedLux >= targetLux:
+		estimate.Sufficiency = "sufficient"
+	case estimate.ExpectedLux >= targetLux/2:
+		estimate.Sufficiency = "partial"
+	default:
+		estimate.Sufficiency = "insufficient"
+	}
+	return estimate
+}
@@ -0,0 +1,87 @@
+package illuminance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseRoomWindows(t *testing.T) {
+	windows, err := ParseRoomWindows([]string{"living_room=south:0.03", " study = 250:0.02 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := windows["living_room"]; w.Azimuth != 180 || w.Factor != 0.03 {
+		t.Errorf("living_room = %+v", w)
+	}
+	if w := windows["study"]; w.Azimuth != 250 || w.Factor != 0.02 {
+		t.Errorf("study = %+v", w)
+	}
+
+	for _, bad := range []string{"study", "study=south", "=south:0.1", "study=up:0.1", "study=400:0.1", "study=s:0", "study=s:1.5"} {
+		if _, err := ParseRoomWindows([]string{bad}); err == nil {
+			t.Errorf("ParseRoomWindows(%q): expected error", bad)
+		}
+	}
+}
+
+func TestRoomWindow_Exposure(t *testing.T) {
+	// Low afternoon sun in the south-west
+	daylight := DaylightContext{TheoreticalOutdoorLux: 40000, SunAltitude: 20, SunAzimuth: 225, IsDaytime: true}
+
+	southWest := RoomWindow{Orientation: "sw", Azimuth: 225, Factor: 0.03}
+	northEast := RoomWindow{Orientation: "ne", Azimuth: 45, Factor: 0.03}
+
+	if got := northEast.Exposure(daylight); got != diffuseShare {
+		t.Errorf("window facing away from the sun: exposure %.2f, want diffuse only", got)
+	}
+	if got, want := southWest.Exposure(daylight), diffuseShare+(1-diffuseShare)*math.Cos(20*math.Pi/180); math.Abs(got-want) > 1e-9 {
+		t.Errorf("window facing the sun: exposure %.3f, want %.3f", got, want)
+	}
+	if got := DefaultRoomWindow.Exposure(daylight); got != diffuseShare {
+		t.Errorf("unknown orientation: exposure %.2f, want diffuse only", got)
+	}
+	if got := southWest.Exposure(DaylightContext{}); got != 0 {
+		t.Errorf("night: exposure %.2f, want 0", got)
+	}
+}
+
+func TestNewCloudCorrection(t *testing.T) {
+	measured, overcast := 10000.0, 100.0
+
+	if c := NewCloudCorrection(40000, &measured, &overcast); c.Source != "outdoor_sensor" || c.Factor != 0.25 {
+		t.Errorf("outdoor sensor should win: %+v", c)
+	}
+	if c := NewCloudCorrection(40000, nil, &overcast); c.Source != "weather" || math.Abs(c.Factor-0.25) > 1e-9 {
+		t.Errorf("overcast sky: %+v", c)
+	}
+	if c := NewCloudCorrection(40000, nil, nil); c.Source != "clear_sky" || c.Factor != 1 {
+		t.Errorf("no data: %+v", c)
+	}
+}
+
+func TestEstimateNaturalLight(t *testing.T) {
+	daylight := DaylightContext{TheoreticalOutdoorLux: 40000, SunAltitude: 20, SunAzimuth: 225, IsDaytime: true}
+	window := RoomWindow{Orientation: "ne", Azimuth: 45, Factor: 0.03}
+	overcast := 100.0
+
+	tests := []struct {
+		name  string
+		cloud CloudCorrection
+		want  string
+	}{
+		{"clear sky", NewCloudCorrection(40000, nil, nil), "sufficient"},        // 480 lux
+		{"overcast", NewCloudCorrection(40000, nil, &overcast), "insufficient"}, // 120 lux
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateNaturalLight(window, daylight, tt.cloud, 300)
+			if got.Sufficiency != tt.want {
+				t.Errorf("sufficiency = %s (%.0f lux), want %s", got.Sufficiency, got.ExpectedLux, tt.want)
+			}
+		})
+	}
+
+	if got := EstimateNaturalLight(window, DaylightContext{}, NewCloudCorrection(0, nil, nil), 300); got.Sufficiency != "none" {
+		t.Errorf("night: sufficiency = %s, want none", got.Sufficiency)
+	}
+}
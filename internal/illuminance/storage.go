@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
@@ -33,21 +34,23 @@ type Storage struct {
 	redis  redis.Client
 	cfg    *config.Config
 	logger *slog.Logger
+	clock  clock.Clock
 }
 
 // NewStorage creates a new Storage instance
-func NewStorage(redisClient redis.Client, cfg *config.Config, logger *slog.Logger) *Storage {
+func NewStorage(redisClient redis.Client, cfg *config.Config, logger *slog.Logger, clk clock.Clock) *Storage {
 	return &Storage{
 		redis:  redisClient,
 		cfg:    cfg,
 		logger: logger,
+		clock:  clk,
 	}
 }
 
 // GetIlluminanceSummary retrieves a comprehensive summary of illuminance data for a location
 func (s *Storage) GetIlluminanceSummary(ctx context.Context, location string) (*DataSummary, error) {
 	key := fmt.Sprintf("sensor:environmental:%s", location)
-	now := time.Now()
+	now := s.clock.Now()
 
 	// Calculate time boundaries
 	fiveMinAgo := now.Add(-5 * time.Minute)
@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
@@ -27,6 +29,7 @@ type Agent struct {
 	redis    redis.Client
 	cfg      *config.Config
 	logger   *slog.Logger
+	clock    *clock.TimeManager
 	analyzer *IlluminanceAnalyzer
 
 	// State management
@@ -36,6 +39,21 @@ type Agent struct {
 	overrideManager *OverrideManager
 	rateLimiter     *RateLimiter
 
+	// Household state from behavior agent (home/away/vacation)
+	houseStateMux sync.RWMutex
+	houseState    string
+	anyoneHome    *bool // from the occupancy agent's house presence, nil until known
+
+	// Pattern scenes (nil = disabled)
+	scenes   ScenePreferences
+	patterns *patternTracker
+
+	// Preferences learned from manual adjustments (nil = disabled)
+	learned LearnedPreferences
+
+	// Named multi-light scenes per room (nil = disabled)
+	sceneManager *SceneManager
+
 	// Periodic decision loop
 	ticker   *time.Ticker
 	stopChan chan struct{}
@@ -43,16 +61,18 @@ type Agent struct {
 
 // NewAgent creates a new light agent
 func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Config, logger *slog.Logger) *Agent {
-	analyzer := NewIlluminanceAnalyzer(redisClient, cfg, logger)
+	clk := clock.NewTimeManager(logger)
+	analyzer := NewIlluminanceAnalyzer(redisClient, cfg, logger, clk)
 
 	return &Agent{
 		mqtt:             mqttClient,
 		redis:            redisClient,
 		cfg:              cfg,
 		logger:           logger,
+		clock:            clk,
 		analyzer:         analyzer,
 		locationContexts: make(map[string]*LocationContext),
-		overrideManager:  NewOverrideManager(),
+		overrideManager:  NewOverrideManager(clk),
 		rateLimiter:      NewRateLimiter(),
 		stopChan:         make(chan struct{}),
 	}
@@ -64,7 +84,12 @@ func (a *Agent) Start(ctx context.Context) error {
 		"service_name", a.cfg.ServiceName,
 		"decision_interval_sec", a.cfg.DecisionIntervalSec,
 		"manual_override_minutes", a.cfg.ManualOverrideMinutes,
-		"min_decision_interval_ms", a.cfg.MinDecisionIntervalMs)
+		"min_decision_interval_ms", a.cfg.MinDecisionIntervalMs,
+		"shadow_mode", a.cfg.ShadowMode)
+
+	if a.cfg.ShadowMode {
+		a.logger.Warn("Shadow mode: lighting decisions are published to automation/shadow/* and no lights are commanded")
+	}
 
 	// Connect to MQTT broker
 	if err := a.mqtt.Connect(ctx); err != nil {
@@ -76,8 +101,13 @@ func (a *Agent) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to ping Redis: %w", err)
 	}
 
+	// Follow the e2e test runner's virtual time
+	if err := a.clock.ConfigureFromMQTT(a.mqtt); err != nil {
+		a.logger.Warn("Failed to subscribe to test mode config", "error", err)
+	}
+
 	// Subscribe to occupancy context
-	occupancyTopic := "automation/context/occupancy/+"
+	occupancyTopic := events.OccupancyTopicFilter
 	if err := a.mqtt.Subscribe(occupancyTopic, 0, a.handleOccupancyMessage); err != nil {
 		return fmt.Errorf("failed to subscribe to %s: %w", occupancyTopic, err)
 	}
@@ -96,6 +126,57 @@ func (a *Agent) Start(ctx context.Context) error {
 	}
 	a.logger.Info("Subscribed to raw light state changes", "topic", rawLightTopic)
 
+	// Subscribe to household state (decisions are suppressed while away)
+	houseStateTopic := "automation/behavior/house_state"
+	if err := a.mqtt.Subscribe(houseStateTopic, 0, a.handleHouseStateMessage); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", houseStateTopic, err)
+	}
+	a.logger.Info("Subscribed to house state", "topic", houseStateTopic)
+
+	// Subscribe to whole-house presence (all lights off when the last room empties)
+	housePresenceTopic := "automation/presence/house"
+	if err := a.mqtt.Subscribe(housePresenceTopic, 0, a.handleHousePresenceMessage); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", housePresenceTopic, err)
+	}
+	a.logger.Info("Subscribed to house presence", "topic", housePresenceTopic)
+
+	// Subscribe to predictions to apply per-pattern scenes
+	if a.scenes != nil {
+		if err := a.mqtt.Subscribe(PredictionTopic, 0, a.handlePredictionMessage); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", PredictionTopic, err)
+		}
+		a.logger.Info("Subscribed to behavior predictions for pattern scenes",
+			"topic", PredictionTopic,
+			"min_confidence", a.cfg.LightPatternMinConfidence)
+	}
+
+	// Subscribe to user feedback on predictions and automations
+	if a.feedbackEnabled() {
+		if err := a.mqtt.Subscribe(FeedbackRecordedTopic, 0, a.handleFeedbackMessage); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", FeedbackRecordedTopic, err)
+		}
+		a.logger.Info("Subscribed to behavior feedback", "topic", FeedbackRecordedTopic)
+	}
+
+	// Load scenes and accept scene commands from wall switches
+	if a.sceneManager != nil {
+		if err := a.sceneManager.Load(ctx); err != nil {
+			return fmt.Errorf("failed to load scenes: %w", err)
+		}
+		sceneCommandTopic := fmt.Sprintf(SceneCommandTopic, "+")
+		if err := a.mqtt.Subscribe(sceneCommandTopic, 0, a.handleSceneCommand); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", sceneCommandTopic, err)
+		}
+		sceneConfigTopic := fmt.Sprintf(SceneConfigTopic, "+", "+")
+		if err := a.mqtt.Subscribe(sceneConfigTopic, 0, a.handleSceneConfig); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", sceneConfigTopic, err)
+		}
+		a.logger.Info("Scenes enabled",
+			"scenes", len(a.sceneManager.All()),
+			"command_topic", sceneCommandTopic,
+			"config_topic", sceneConfigTopic)
+	}
+
 	// Start periodic decision loop
 	a.startPeriodicDecisionLoop()
 
@@ -177,6 +258,8 @@ func (a *Agent) performPeriodicDecisions() {
 		a.evaluateLightingNeed(ctx, location, context.OccupancyState, context.OccupancyConfidence, false)
 	}
 
+	a.recordScenesAccepted(ctx)
+
 	// Cleanup expired overrides periodically
 	cleaned := a.overrideManager.CleanupExpiredOverrides()
 	if cleaned > 0 {
@@ -187,7 +270,6 @@ func (a *Agent) performPeriodicDecisions() {
 // handleOccupancyMessage handles incoming occupancy context messages
 func (a *Agent) handleOccupancyMessage(msg mqtt.Message) {
 	topic := msg.Topic()
-	payload := msg.Payload()
 
 	// Extract location from topic: automation/context/occupancy/{location}
 	parts := strings.Split(topic, "/")
@@ -198,13 +280,8 @@ func (a *Agent) handleOccupancyMessage(msg mqtt.Message) {
 	location := parts[3]
 
 	// Parse message
-	var occupancyMsg struct {
-		State      string  `json:"state"`
-		Confidence float64 `json:"confidence"`
-		Timestamp  string  `json:"timestamp"`
-	}
-
-	if err := json.Unmarshal(payload, &occupancyMsg); err != nil {
+	occupancyMsg, _, err := events.Parse[events.OccupancyState](msg.Payload())
+	if err != nil {
 		a.logger.Error("Failed to parse occupancy message",
 			"location", location,
 			"error", err)
@@ -232,7 +309,7 @@ func (a *Agent) handleOccupancyMessage(msg mqtt.Message) {
 	a.locationContexts[location] = &LocationContext{
 		OccupancyState:      occupancyMsg.State,
 		OccupancyConfidence: occupancyMsg.Confidence,
-		LastUpdate:          time.Now(),
+		LastUpdate:          a.clock.Now(),
 	}
 	a.contextMux.Unlock()
 
@@ -288,9 +365,14 @@ func (a *Agent) handleRawLightStateChange(msg mqtt.Message) {
 			"brightness", lightMsg.Data.Brightness,
 			"expires_at", expiresAt.Format(time.RFC3339))
 
+		a.recordSceneOverride(context.Background(), location)
+		if lightMsg.Data.State == "on" {
+			a.observeManualAdjustment(context.Background(), location, lightMsg.Data.Brightness, lightMsg.Data.ColorTemp)
+		}
+
 		// Republish to automation/raw/lighting/{location} with source attribution
 		// so collector can store it
-		timestamp := time.Now().Format(time.RFC3339)
+		timestamp := a.clock.Now().Format(time.RFC3339)
 		rawLightingMsg := map[string]interface{}{
 			"data": map[string]interface{}{
 				"state":      lightMsg.Data.State,
@@ -319,7 +401,7 @@ func (a *Agent) handleRawLightStateChange(msg mqtt.Message) {
 // Currently just logs - illuminance data is read from Redis instead
 func (a *Agent) handleIlluminanceMessage(msg mqtt.Message) {
 	topic := msg.Topic()
-	payload := msg.Payload()
+	payload := events.Payload(msg.Payload())
 
 	// Extract location from topic
 	parts := strings.Split(topic, "/")
@@ -348,8 +430,91 @@ func (a *Agent) handleIlluminanceMessage(msg mqtt.Message) {
 	// Note: We don't take action here - illuminance is read from Redis during decision making
 }
 
+// handleHouseStateMessage tracks household away/vacation state
+func (a *Agent) handleHouseStateMessage(msg mqtt.Message) {
+	var houseMsg struct {
+		State      string  `json:"state"`
+		Confidence float64 `json:"confidence"`
+	}
+
+	if _, err := events.Unmarshal(msg.Payload(), &houseMsg); err != nil {
+		a.logger.Error("Failed to parse house state message", "error", err)
+		return
+	}
+
+	a.houseStateMux.Lock()
+	previous := a.houseState
+	a.houseState = houseMsg.State
+	a.houseStateMux.Unlock()
+
+	if previous != houseMsg.State {
+		a.logger.Info("House state changed",
+			"previous", previous,
+			"state", houseMsg.State,
+			"confidence", houseMsg.Confidence)
+	}
+}
+
+// handleHousePresenceMessage turns every known light off when the last
+// occupied room in the house empties
+func (a *Agent) handleHousePresenceMessage(msg mqtt.Message) {
+	var presence struct {
+		AnyoneHome  bool `json:"anyone_home"`
+		ActiveRooms int  `json:"active_rooms"`
+	}
+
+	if _, err := events.Unmarshal(msg.Payload(), &presence); err != nil {
+		a.logger.Error("Failed to parse house presence message", "error", err)
+		return
+	}
+
+	a.houseStateMux.Lock()
+	wasHome := a.anyoneHome != nil && *a.anyoneHome
+	a.anyoneHome = &presence.AnyoneHome
+	a.houseStateMux.Unlock()
+
+	if !wasHome || presence.AnyoneHome {
+		return
+	}
+
+	a.contextMux.RLock()
+	locations := make([]string, 0, len(a.locationContexts))
+	for location := range a.locationContexts {
+		locations = append(locations, location)
+	}
+	a.contextMux.RUnlock()
+
+	a.logger.Info("House is empty, turning all lights off", "locations", len(locations))
+	decision := &Decision{
+		Action:     "off",
+		Reason:     "house_empty",
+		Confidence: 1.0,
+	}
+	for _, location := range locations {
+		if err := a.publishLightingCommand(location, decision); err != nil {
+			a.logger.Error("Failed to publish all-off command",
+				"location", location,
+				"error", err)
+		}
+	}
+}
+
+// isHouseAway reports whether lighting decisions should be suppressed
+func (a *Agent) isHouseAway() bool {
+	a.houseStateMux.RLock()
+	defer a.houseStateMux.RUnlock()
+	return a.houseState == "away" || a.houseState == "vacation"
+}
+
 // evaluateLightingNeed makes a lighting decision and publishes if needed
 func (a *Agent) evaluateLightingNeed(ctx context.Context, location string, occupancyState string, occupancyConfidence float64, forceDecision bool) {
+	// Nobody home - occupancy signals are pets or noise
+	if a.isHouseAway() {
+		a.logger.Debug("House is away, skipping lighting decision",
+			"location", location)
+		return
+	}
+
 	// Check rate limiting (unless forced)
 	if !forceDecision {
 		if !a.rateLimiter.ShouldMakeDecision(location, a.cfg.MinDecisionIntervalMs) {
@@ -366,6 +531,7 @@ func (a *Agent) evaluateLightingNeed(ctx context.Context, location string, occup
 	// Make lighting decision
 	decision := MakeLightingDecision(
 		ctx,
+		a.clock.Now(),
 		location,
 		occupancyState,
 		occupancyConfidence,
@@ -373,6 +539,9 @@ func (a *Agent) evaluateLightingNeed(ctx context.Context, location string, occup
 		a.overrideManager,
 		a.logger,
 	)
+	a.applyActiveScene(ctx, location, decision)
+	a.applyLearnedPreference(ctx, location, decision)
+	a.selectScene(location, decision)
 
 	// If action is "maintain", don't publish anything
 	if decision.Action == "maintain" {
@@ -401,7 +570,7 @@ func (a *Agent) evaluateLightingNeed(ctx context.Context, location string, occup
 
 // publishLightingCommand publishes both command and context messages
 func (a *Agent) publishLightingCommand(location string, decision *Decision) error {
-	timestamp := time.Now().Format(time.RFC3339)
+	timestamp := a.clock.Now().Format(time.RFC3339)
 
 	// Build command message (unchanged)
 	commandMsg := map[string]interface{}{
@@ -418,8 +587,19 @@ func (a *Agent) publishLightingCommand(location string, decision *Decision) erro
 		commandMsg["color_temp"] = nil
 	}
 
+	// Scene-aware controllers set each light individually
+	if decision.Scene != nil {
+		commandMsg["scene"] = decision.Scene.Name
+		commandMsg["lights"] = decision.Scene.Lights
+	}
+
+	if a.cfg.ShadowMode {
+		commandMsg["shadow"] = true
+		commandMsg["details"] = decision.Details
+	}
+
 	// Publish command
-	commandTopic := fmt.Sprintf("automation/command/light/%s", location)
+	commandTopic := a.actuationTopic(fmt.Sprintf("automation/command/light/%s", location))
 	commandPayload, err := json.Marshal(commandMsg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal command message: %w", err)
@@ -432,30 +612,34 @@ func (a *Agent) publishLightingCommand(location string, decision *Decision) erro
 	a.logger.Debug("Published lighting command", "topic", commandTopic)
 
 	// Build context message (unchanged)
-	contextMsg := map[string]interface{}{
-		"source":     "light-agent",
-		"type":       "lighting",
-		"location":   location,
-		"state":      decision.Action,
-		"brightness": decision.Brightness,
-		"reason":     decision.Reason,
-		"confidence": decision.Confidence,
-		"timestamp":  timestamp,
+	contextMsg := events.LightingEvent{
+		Source:     "light-agent",
+		Type:       "lighting",
+		Location:   location,
+		State:      decision.Action,
+		Brightness: &decision.Brightness,
+		Reason:     decision.Reason,
+		Confidence: decision.Confidence,
+		Timestamp:  timestamp,
 	}
 
 	if decision.ColorTemp > 0 {
-		contextMsg["color_temp"] = decision.ColorTemp
-	} else {
-		contextMsg["color_temp"] = nil
+		contextMsg.ColorTemp = &decision.ColorTemp
 	}
 
 	if decision.Action == "on" {
-		contextMsg["illuminating"] = true
-		contextMsg["automated"] = true
+		contextMsg.Illuminating = true
+		contextMsg.Automated = true
 	}
 
+	if decision.Scene != nil {
+		contextMsg.Scene = decision.Scene.Name
+	}
+
+	contextMsg.Shadow = a.cfg.ShadowMode
+
 	// Publish context
-	contextTopic := fmt.Sprintf("automation/context/lighting/%s", location)
+	contextTopic := a.actuationTopic(contextMsg.Topic())
 	contextPayload, err := json.Marshal(contextMsg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal context message: %w", err)
@@ -495,7 +679,7 @@ func (a *Agent) publishRawLightingEvent(location string, decision *Decision, tim
 		rawMsg["data"].(map[string]interface{})["color_temp"] = decision.ColorTemp
 	}
 
-	rawTopic := fmt.Sprintf("automation/raw/lighting/%s", location)
+	rawTopic := a.actuationTopic(fmt.Sprintf("automation/raw/lighting/%s", location))
 	rawPayload, err := json.Marshal(rawMsg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal raw lighting event: %w", err)
@@ -512,6 +696,15 @@ func (a *Agent) publishRawLightingEvent(location string, decision *Decision, tim
 	return nil
 }
 
+// actuationTopic redirects topics that change or report device state to
+// automation/shadow/* in shadow mode, so nothing downstream acts on them
+func (a *Agent) actuationTopic(topic string) string {
+	if a.cfg.ShadowMode {
+		return mqtt.ShadowTopic(topic)
+	}
+	return topic
+}
+
 // GetLocationCount returns the number of tracked locations (for health check)
 func (a *Agent) GetLocationCount() int {
 	a.contextMux.RLock()
@@ -548,6 +741,7 @@ func (a *Agent) ForceDecision(location string) (*Decision, error) {
 	// Make decision
 	decision := MakeLightingDecision(
 		ctx,
+		a.clock.Now(),
 		location,
 		locationContext.OccupancyState,
 		locationContext.OccupancyConfidence,
@@ -555,6 +749,9 @@ func (a *Agent) ForceDecision(location string) (*Decision, error) {
 		a.overrideManager,
 		a.logger,
 	)
+	a.applyActiveScene(ctx, location, decision)
+	a.applyLearnedPreference(ctx, location, decision)
+	a.selectScene(location, decision)
 
 	// Publish if action is not maintain
 	if decision.Action != "maintain" {
@@ -0,0 +1,39 @@
+package light
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAgent_ShadowMode(t *testing.T) {
+	broker := &publishRecorder{published: map[string][]byte{}}
+	a := newSceneTestAgent(&memoryPreferences{scenes: map[string]*PatternScene{}})
+	a.mqtt = broker
+	a.cfg.ShadowMode = true
+
+	decision := &Decision{Action: "on", Brightness: 60, ColorTemp: 3000, Reason: "dark_room", Confidence: 0.9}
+	if err := a.publishLightingCommand("study", decision); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, topic := range []string{"automation/command/light/study", "automation/context/lighting/study", "automation/raw/lighting/study"} {
+		if _, ok := broker.published[topic]; ok {
+			t.Errorf("shadow mode published to actuation topic %s", topic)
+		}
+	}
+
+	var command struct {
+		Action string `json:"action"`
+		Shadow bool   `json:"shadow"`
+	}
+	payload, ok := broker.published["automation/shadow/command/light/study"]
+	if !ok {
+		t.Fatalf("no shadow command published, got %v", broker.published)
+	}
+	if err := json.Unmarshal(payload, &command); err != nil || command.Action != "on" || !command.Shadow {
+		t.Errorf("unexpected shadow command %s", payload)
+	}
+	if _, ok := broker.published["automation/shadow/raw/lighting/study"]; !ok {
+		t.Error("shadow raw lighting event missing")
+	}
+}
@@ -1,5 +1,18 @@
 package light
 
+import (
+	"math"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/solar"
+)
+
+// CircadianSource is implemented by assessors that know the sun position;
+// decisions then follow the solar day instead of fixed clock hours
+type CircadianSource interface {
+	Circadian(t time.Time) solar.Circadian
+}
+
 // calculateColorTemperature returns the appropriate color temperature based on time of day
 // Follows circadian rhythm principles - warmer light at night, cooler during day
 func calculateColorTemperature(timeOfDay string) int {
@@ -20,3 +33,17 @@ func calculateColorTemperature(timeOfDay string) int {
 	// Default to neutral if unknown time of day
 	return 4000
 }
+
+// circadianColorTemperature follows the sun: 2700K at the horizon rising to
+// 5500K at solar noon, 2700K through twilight and 2400K at night
+func circadianColorTemperature(c solar.Circadian) int {
+	switch c.Phase {
+	case solar.PhaseNight:
+		return 2400
+	case solar.PhaseDawn, solar.PhaseDusk:
+		return 2700
+	}
+
+	kelvin := 2700 + 2800*c.Intensity()
+	return int(math.Round(kelvin/50)) * 50 // 50K steps avoid constant small adjustments
+}
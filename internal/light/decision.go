@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 // Decision represents a lighting decision with action, settings, and reasoning
@@ -14,6 +15,7 @@ type Decision struct {
 	Reason     string                 // Concise reason for the decision
 	Confidence float64                // 0.0-1.0
 	Details    map[string]interface{} // Additional context for debugging
+	Scene      *Scene                 // Scene selected for the room, nil for plain brightness
 }
 
 // IlluminanceAssessor provides illuminance assessment for decision making
@@ -21,10 +23,11 @@ type IlluminanceAssessor interface {
 	GetIlluminanceAssessment(ctx context.Context, location, timeOfDay string) *IlluminanceAssessment
 }
 
-// MakeLightingDecision implements the core decision logic (Rules 0-4)
+// MakeLightingDecision implements the core decision logic (Rules 0-4) as of now
 // Returns a Decision struct with action, brightness, colorTemp, reason, and confidence
 func MakeLightingDecision(
 	ctx context.Context,
+	now time.Time,
 	location string,
 	occupancyState string,
 	occupancyConfidence float64,
@@ -33,7 +36,7 @@ func MakeLightingDecision(
 	logger *slog.Logger,
 ) *Decision {
 	// Get current time of day for all calculations
-	timeOfDay := getTimeOfDay()
+	timeOfDay := getTimeOfDay(now)
 
 	// Rule 0: Manual Override - Always maintain current state
 	if overrideManager.CheckManualOverride(location) {
@@ -73,9 +76,10 @@ func MakeLightingDecision(
 
 	// Rule 2: Uncertain Occupancy - Wait for clarity
 	uncertainStates := map[string]bool{
-		"likely":   true,
-		"unlikely": true,
-		"unknown":  true,
+		"likely":       true,
+		"unlikely":     true,
+		"unknown":      true,
+		"likely_empty": true,
 	}
 	if uncertainStates[occupancyState] {
 		logger.Debug("Rule 2: Uncertain occupancy, maintaining state",
@@ -136,8 +140,15 @@ func MakeLightingDecision(
 			timeOfDay,
 		)
 
-		// Calculate color temperature based on time of day
+		// Calculate color temperature from the sun position when known,
+		// otherwise from the time of day
 		colorTemp := calculateColorTemperature(timeOfDay)
+		circadianPhase := ""
+		if source, ok := analyzer.(CircadianSource); ok {
+			circadian := source.Circadian(now)
+			colorTemp = circadianColorTemperature(circadian)
+			circadianPhase = string(circadian.Phase)
+		}
 
 		// Determine action (on or off)
 		action := "on"
@@ -181,6 +192,7 @@ func MakeLightingDecision(
 				"illuminance_confidence": assessment.Confidence,
 				"is_natural_light":    isNaturalLight,
 				"time_of_day":         timeOfDay,
+				"circadian_phase":     circadianPhase,
 			},
 		}
 	}
@@ -5,6 +5,10 @@ import (
 	"log/slog"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/solar"
 )
 
 // Mock analyzer that returns a predictable assessment
@@ -18,7 +22,7 @@ func (m *mockAnalyzer) GetIlluminanceAssessment(ctx context.Context, location, t
 
 func TestMakeLightingDecision_Rule0_ManualOverride(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	// Set manual override
 	overrideManager.SetManualOverride("study", 30)
@@ -34,6 +38,7 @@ func TestMakeLightingDecision_Rule0_ManualOverride(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"occupied",
 		0.95,
@@ -57,7 +62,7 @@ func TestMakeLightingDecision_Rule0_ManualOverride(t *testing.T) {
 
 func TestMakeLightingDecision_Rule1_EmptyRoom(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -70,6 +75,7 @@ func TestMakeLightingDecision_Rule1_EmptyRoom(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"empty",
 		0.90,
@@ -97,7 +103,7 @@ func TestMakeLightingDecision_Rule1_EmptyRoom(t *testing.T) {
 
 func TestMakeLightingDecision_Rule2_UncertainOccupancy(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -115,12 +121,14 @@ func TestMakeLightingDecision_Rule2_UncertainOccupancy(t *testing.T) {
 		{"likely", "awaiting_occupancy_confirmation_likely"},
 		{"unlikely", "awaiting_occupancy_confirmation_unlikely"},
 		{"unknown", "awaiting_occupancy_confirmation_unknown"},
+		{"likely_empty", "awaiting_occupancy_confirmation_likely_empty"},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.state, func(t *testing.T) {
 			decision := MakeLightingDecision(
 				context.Background(),
+				time.Now(),
 				"study",
 				tc.state,
 				0.65,
@@ -142,7 +150,7 @@ func TestMakeLightingDecision_Rule2_UncertainOccupancy(t *testing.T) {
 
 func TestMakeLightingDecision_Rule3_LowConfidence(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -155,6 +163,7 @@ func TestMakeLightingDecision_Rule3_LowConfidence(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"occupied",
 		0.45, // Below 0.5 threshold
@@ -178,7 +187,7 @@ func TestMakeLightingDecision_Rule3_LowConfidence(t *testing.T) {
 
 func TestMakeLightingDecision_Rule4_OccupiedDark(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -191,6 +200,7 @@ func TestMakeLightingDecision_Rule4_OccupiedDark(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"occupied",
 		0.95,
@@ -222,7 +232,7 @@ func TestMakeLightingDecision_Rule4_OccupiedDark(t *testing.T) {
 
 func TestMakeLightingDecision_Rule4_OccupiedDim(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -235,6 +245,7 @@ func TestMakeLightingDecision_Rule4_OccupiedDim(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"occupied",
 		0.90,
@@ -261,7 +272,7 @@ func TestMakeLightingDecision_Rule4_OccupiedDim(t *testing.T) {
 
 func TestMakeLightingDecision_Rule4_OccupiedBright(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -274,6 +285,7 @@ func TestMakeLightingDecision_Rule4_OccupiedBright(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"occupied",
 		0.95,
@@ -291,7 +303,7 @@ func TestMakeLightingDecision_Rule4_OccupiedBright(t *testing.T) {
 
 func TestMakeLightingDecision_Rule4_LowIlluminanceConfidence(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -304,6 +316,7 @@ func TestMakeLightingDecision_Rule4_LowIlluminanceConfidence(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"occupied",
 		0.95, // High occupancy confidence
@@ -326,7 +339,7 @@ func TestMakeLightingDecision_Rule4_LowIlluminanceConfidence(t *testing.T) {
 
 func TestMakeLightingDecision_ReasonFormat(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -339,6 +352,7 @@ func TestMakeLightingDecision_ReasonFormat(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"occupied",
 		0.95,
@@ -361,7 +375,7 @@ func TestMakeLightingDecision_ReasonFormat(t *testing.T) {
 
 func TestMakeLightingDecision_DetailsIncluded(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	overrideManager := NewOverrideManager()
+	overrideManager := NewOverrideManager(clock.Real{})
 
 	analyzer := &mockAnalyzer{
 		assessment: &IlluminanceAssessment{
@@ -374,6 +388,7 @@ func TestMakeLightingDecision_DetailsIncluded(t *testing.T) {
 
 	decision := MakeLightingDecision(
 		context.Background(),
+		time.Now(),
 		"study",
 		"occupied",
 		0.90,
@@ -406,7 +421,7 @@ func TestMakeLightingDecision_DetailsIncluded(t *testing.T) {
 }
 
 func TestOverrideManager_BasicOperations(t *testing.T) {
-	om := NewOverrideManager()
+	om := NewOverrideManager(clock.Real{})
 
 	// Initially no override
 	if om.CheckManualOverride("study") {
@@ -451,3 +466,24 @@ func TestRateLimiter_BasicOperations(t *testing.T) {
 		t.Error("Expected decision for different location to be allowed")
 	}
 }
+
+func TestCircadianColorTemperature(t *testing.T) {
+	tests := []struct {
+		name      string
+		circadian solar.Circadian
+		want      int
+	}{
+		{"night", solar.Circadian{Phase: solar.PhaseNight, Elevation: -20, NoonElevation: 50}, 2400},
+		{"dusk", solar.Circadian{Phase: solar.PhaseDusk, Elevation: -3, NoonElevation: 50}, 2700},
+		{"solar noon", solar.Circadian{Phase: solar.PhaseMidday, Elevation: 50, NoonElevation: 50}, 5500},
+		{"low winter sun at noon", solar.Circadian{Phase: solar.PhaseMidday, Elevation: 6, NoonElevation: 6}, 5500},
+		{"low morning sun", solar.Circadian{Phase: solar.PhaseMorning, Elevation: 10, NoonElevation: 50}, 3350},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := circadianColorTemperature(tt.circadian); got != tt.want {
+				t.Errorf("circadianColorTemperature() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
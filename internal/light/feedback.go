@@ -0,0 +1,93 @@
+package light
+
+import (
+	"context"
+
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// FeedbackRecordedTopic carries user feedback applied by the behavior agent
+const FeedbackRecordedTopic = "automation/behavior/feedback/recorded"
+
+// Feedback is the part of a recorded feedback message the light agent learns from
+type Feedback struct {
+	PatternID  string `json:"pattern_id"`
+	Location   string `json:"location"`
+	Response   string `json:"response"` // "accept", "reject" or "snooze"
+	Brightness int    `json:"brightness"`
+	ColorTemp  int    `json:"color_temp"`
+}
+
+// handleFeedbackMessage applies a user's response to a prediction or automation:
+// accept and reject settle the pattern's applied scenes, a rejection that names
+// the lighting wanted instead is learned like a manual adjustment, and snooze
+// deactivates the pattern so its scenes stop applying
+func (a *Agent) handleFeedbackMessage(msg mqtt.Message) {
+	var feedback Feedback
+	if _, err := events.Unmarshal(msg.Payload(), &feedback); err != nil {
+		a.logger.Error("Failed to parse feedback message", "error", err)
+		return
+	}
+	a.applyFeedback(context.Background(), feedback)
+}
+
+func (a *Agent) applyFeedback(ctx context.Context, feedback Feedback) {
+	hasPattern := feedback.PatternID != "" && feedback.PatternID != noPattern
+
+	if a.scenes != nil && hasPattern {
+		switch feedback.Response {
+		case "accept", "reject":
+			overridden := feedback.Response == "reject"
+			for _, location := range a.patterns.settle(feedback.PatternID, feedback.Location) {
+				if err := a.scenes.RecordFeedback(ctx, feedback.PatternID, location, overridden); err != nil {
+					a.logger.Error("Failed to record scene feedback", "location", location, "error", err)
+				}
+			}
+		case "snooze":
+			a.patterns.deactivate(feedback.PatternID)
+		}
+	}
+
+	if feedback.Response == "reject" && feedback.Location != "" && feedback.Brightness > 0 {
+		a.observeManualAdjustment(ctx, feedback.Location, feedback.Brightness, feedback.ColorTemp)
+	}
+
+	a.logger.Debug("Applied feedback",
+		"pattern_id", feedback.PatternID,
+		"location", feedback.Location,
+		"response", feedback.Response)
+}
+
+// settle marks the unsettled scenes of a pattern as having feedback, in one
+// room or in every room when location is empty, and returns their rooms
+func (t *patternTracker) settle(patternID, location string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var settled []string
+	for room, scene := range t.applied {
+		if scene.PatternID != patternID || scene.Settled || (location != "" && room != location) {
+			continue
+		}
+		scene.Settled = true
+		t.applied[room] = scene
+		settled = append(settled, room)
+	}
+	return settled
+}
+
+// deactivate forgets a pattern in every room it is active in
+func (t *patternTracker) deactivate(patternID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for room, p := range t.active {
+		if p.ID == patternID {
+			delete(t.active, room)
+		}
+	}
+}
+
+// feedbackEnabled reports whether anything in the agent learns from feedback
+func (a *Agent) feedbackEnabled() bool {
+	return a.scenes != nil || a.learned != nil
+}
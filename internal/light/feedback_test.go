@@ -0,0 +1,53 @@
+package light
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAgent_FeedbackSettlesSceneAndLearns(t *testing.T) {
+	store := &memoryPreferences{scenes: map[string]*PatternScene{}}
+	learned := &memoryLearned{prefs: map[string]*LearnedPreference{}}
+	a := newSceneTestAgent(store)
+	a.SetLearnedPreferences(learned)
+	ctx := context.Background()
+	patternID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	a.patterns.activate("living_room", activePattern{ID: patternID, Name: "Evening wind-down", Type: "leisure", ExpiresAt: time.Now().Add(time.Hour)})
+	a.applyActiveScene(ctx, "living_room", &Decision{Action: "on"})
+
+	a.handleFeedbackMessage(&fakeMessage{topic: FeedbackRecordedTopic, payload: []byte(`{
+		"pattern_id": "` + patternID + `", "location": "living_room", "response": "reject",
+		"brightness": 60, "color_temp": 3000}`)})
+
+	scene := store.scenes[patternID+"/living_room"]
+	if scene.Overridden != 1 {
+		t.Errorf("rejection should count as an override, got %+v", scene)
+	}
+
+	// A settled scene is not counted again when the feedback window passes
+	a.cfg.LightPatternFeedbackMinutes = 0
+	a.recordScenesAccepted(ctx)
+	if scene.Accepted != 0 {
+		t.Errorf("rejected scene should not also be accepted, got %+v", scene)
+	}
+
+	if pref := learned.prefs["living_room/"+getTimeOfDay(time.Now())+"/"+patternID]; pref == nil || pref.Brightness != 60 {
+		t.Errorf("rejection with brightness should be learned for the pattern, got %+v", pref)
+	}
+}
+
+func TestAgent_FeedbackSnoozeDeactivatesPattern(t *testing.T) {
+	a := newSceneTestAgent(&memoryPreferences{scenes: map[string]*PatternScene{}})
+	patternID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	a.patterns.activate("living_room", activePattern{ID: patternID, Type: "leisure", ExpiresAt: time.Now().Add(time.Hour)})
+
+	a.applyFeedback(context.Background(), Feedback{PatternID: patternID, Response: "snooze"})
+
+	decision := &Decision{Action: "on", Brightness: 80}
+	a.applyActiveScene(context.Background(), "living_room", decision)
+	if decision.Brightness != 80 {
+		t.Errorf("snoozed pattern should not apply its scene, got %+v", decision)
+	}
+}
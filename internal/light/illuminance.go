@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/saaga0h/jeeves-platform/internal/illuminance"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
+	"github.com/saaga0h/jeeves-platform/pkg/solar"
 )
 
 // IlluminanceAssessment represents the illuminance state for decision making
@@ -23,18 +25,25 @@ type IlluminanceAnalyzer struct {
 	storage *illuminance.Storage
 	cfg     *config.Config
 	logger  *slog.Logger
+	clock   clock.Clock
 }
 
 // NewIlluminanceAnalyzer creates a new illuminance analyzer
-func NewIlluminanceAnalyzer(redisClient redis.Client, cfg *config.Config, logger *slog.Logger) *IlluminanceAnalyzer {
-	storage := illuminance.NewStorage(redisClient, cfg, logger)
+func NewIlluminanceAnalyzer(redisClient redis.Client, cfg *config.Config, logger *slog.Logger, clk clock.Clock) *IlluminanceAnalyzer {
+	storage := illuminance.NewStorage(redisClient, cfg, logger, clk)
 	return &IlluminanceAnalyzer{
 		storage: storage,
 		cfg:     cfg,
 		logger:  logger,
+		clock:   clk,
 	}
 }
 
+// Circadian returns the solar phase at the configured location
+func (ia *IlluminanceAnalyzer) Circadian(t time.Time) solar.Circadian {
+	return solar.CircadianAt(t, ia.cfg.Latitude, ia.cfg.Longitude)
+}
+
 // GetIlluminanceAssessment performs 3-tier fallback strategy
 func (ia *IlluminanceAnalyzer) GetIlluminanceAssessment(ctx context.Context, location, timeOfDay string) *IlluminanceAssessment {
 	// Strategy 1: Recent Data (highest confidence)
@@ -43,7 +52,7 @@ func (ia *IlluminanceAnalyzer) GetIlluminanceAssessment(ctx context.Context, loc
 	}
 
 	// Strategy 2: Historical Pattern (medium confidence)
-	if assessment := ia.tryHistoricalPattern(ctx, location, time.Now().Hour()); assessment != nil {
+	if assessment := ia.tryHistoricalPattern(ctx, location, ia.clock.Now().Hour()); assessment != nil {
 		return assessment
 	}
 
@@ -58,7 +67,7 @@ func (ia *IlluminanceAnalyzer) tryRecentData(ctx context.Context, location strin
 		return nil
 	}
 
-	age := time.Since(summary.LatestReading.Timestamp)
+	age := ia.clock.Now().Sub(summary.LatestReading.Timestamp)
 
 	// Only use if < 2 minutes old
 	if age < 2*time.Minute {
@@ -146,8 +155,8 @@ func isLikelyNaturalLight(lux float64, timeOfDay string) bool {
 }
 
 // getTimeOfDay returns the semantic time period based on current hour
-func getTimeOfDay() string {
-	hour := time.Now().Hour()
+func getTimeOfDay(now time.Time) string {
+	hour := now.Hour()
 
 	switch {
 	case hour >= 5 && hour < 7:
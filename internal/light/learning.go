@@ -0,0 +1,232 @@
+package light
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// anyPattern keys preferences learned regardless of the active pattern
+const anyPattern = ""
+
+// maxLearningWindow caps the running mean: after this many observations each
+// new manual adjustment moves the preference by 1/maxLearningWindow
+const maxLearningWindow = 10
+
+// LearnedPreference is the lighting a user has repeatedly chosen in a room at
+// a time of day, optionally while a pattern was active
+type LearnedPreference struct {
+	Location     string
+	TimeOfDay    string
+	PatternID    string // anyPattern when not tied to a pattern
+	Brightness   float64
+	ColorTemp    float64 // 0 when the user never set one
+	Observations int
+}
+
+// PreferenceObservation is one manual adjustment and the context it was made in
+type PreferenceObservation struct {
+	Location   string
+	TimeOfDay  string
+	PatternID  string
+	Brightness int
+	ColorTemp  int // 0 if not reported
+	ObservedAt time.Time
+}
+
+// LearnedPreferences records manual adjustments and looks up what they add up to
+type LearnedPreferences interface {
+	Observe(ctx context.Context, obs PreferenceObservation) error
+	// Lookup returns the preference for the key, nil when nothing was learned
+	Lookup(ctx context.Context, location, timeOfDay, patternID string) (*LearnedPreference, error)
+}
+
+// learn folds an observation into a preference: a plain mean for the first
+// observations, then an exponential moving average
+func (p *LearnedPreference) learn(brightness, colorTemp int) {
+	p.Observations++
+	weight := 1 / math.Min(float64(p.Observations), maxLearningWindow)
+	p.Brightness += (float64(brightness) - p.Brightness) * weight
+	switch {
+	case colorTemp <= 0:
+	case p.ColorTemp == 0:
+		p.ColorTemp = float64(colorTemp)
+	default:
+		p.ColorTemp += (float64(colorTemp) - p.ColorTemp) * weight
+	}
+}
+
+// Observe logs the adjustment and updates the learned preference for its key
+func (s *PreferenceStore) Observe(ctx context.Context, obs PreferenceObservation) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var colorTemp interface{}
+	if obs.ColorTemp > 0 {
+		colorTemp = obs.ColorTemp
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO light_preference_observations (location, time_of_day, pattern_id, brightness, color_temp, observed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, obs.Location, obs.TimeOfDay, obs.PatternID, obs.Brightness, colorTemp, obs.ObservedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert preference observation: %w", err)
+	}
+
+	pref := LearnedPreference{Location: obs.Location, TimeOfDay: obs.TimeOfDay, PatternID: obs.PatternID}
+	var storedColorTemp sql.NullFloat64
+	err = tx.QueryRowContext(ctx, `
+		SELECT brightness, color_temp, observations
+		FROM light_preferences
+		WHERE location = $1 AND time_of_day = $2 AND pattern_id = $3
+		FOR UPDATE
+	`, obs.Location, obs.TimeOfDay, obs.PatternID).Scan(&pref.Brightness, &storedColorTemp, &pref.Observations)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to query light preference: %w", err)
+	}
+	pref.ColorTemp = storedColorTemp.Float64
+	pref.learn(obs.Brightness, obs.ColorTemp)
+
+	var learnedColorTemp interface{}
+	if pref.ColorTemp > 0 {
+		learnedColorTemp = pref.ColorTemp
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO light_preferences (location, time_of_day, pattern_id, brightness, color_temp, observations)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (household_id, location, time_of_day, pattern_id) DO UPDATE
+		SET brightness = EXCLUDED.brightness,
+		    color_temp = EXCLUDED.color_temp,
+		    observations = EXCLUDED.observations,
+		    updated_at = NOW()
+	`, pref.Location, pref.TimeOfDay, pref.PatternID, pref.Brightness, learnedColorTemp, pref.Observations)
+	if err != nil {
+		return fmt.Errorf("failed to save light preference: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit preference observation: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the learned preference for a room, time of day and pattern
+func (s *PreferenceStore) Lookup(ctx context.Context, location, timeOfDay, patternID string) (*LearnedPreference, error) {
+	pref := LearnedPreference{Location: location, TimeOfDay: timeOfDay, PatternID: patternID}
+	var colorTemp sql.NullFloat64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT brightness, color_temp, observations
+		FROM light_preferences
+		WHERE location = $1 AND time_of_day = $2 AND pattern_id = $3
+	`, location, timeOfDay, patternID).Scan(&pref.Brightness, &colorTemp, &pref.Observations)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query light preference: %w", err)
+	}
+	pref.ColorTemp = colorTemp.Float64
+	return &pref, nil
+}
+
+// SetLearnedPreferences enables learning from manual adjustments
+func (a *Agent) SetLearnedPreferences(store LearnedPreferences) {
+	a.learned = store
+}
+
+// activePatternID returns the pattern currently active in a room, anyPattern if none
+func (a *Agent) activePatternID(location string) string {
+	if a.patterns == nil {
+		return anyPattern
+	}
+	if p, ok := a.patterns.current(location, a.clock.Now()); ok {
+		return p.ID
+	}
+	return anyPattern
+}
+
+// observeManualAdjustment records a manual "on" adjustment under the room's
+// time of day, both for any pattern and for the active one
+func (a *Agent) observeManualAdjustment(ctx context.Context, location string, brightness, colorTemp int) {
+	if a.learned == nil || brightness <= 0 {
+		return
+	}
+
+	obs := PreferenceObservation{
+		Location:   location,
+		TimeOfDay:  getTimeOfDay(a.clock.Now()),
+		PatternID:  anyPattern,
+		Brightness: brightness,
+		ColorTemp:  colorTemp,
+		ObservedAt: a.clock.Now(),
+	}
+	keys := []string{anyPattern}
+	if patternID := a.activePatternID(location); patternID != anyPattern {
+		keys = append(keys, patternID)
+	}
+	for _, patternID := range keys {
+		obs.PatternID = patternID
+		if err := a.learned.Observe(ctx, obs); err != nil {
+			a.logger.Error("Failed to record preference observation",
+				"location", location,
+				"error", err)
+			return
+		}
+	}
+
+	a.logger.Debug("Recorded preference observation",
+		"location", location,
+		"time_of_day", obs.TimeOfDay,
+		"brightness", brightness,
+		"color_temp", colorTemp)
+}
+
+// applyLearnedPreference replaces an "on" decision's settings with what the
+// user has chosen often enough in the same context. A preference learned for
+// the active pattern wins over the pattern's scene, which wins over a
+// preference learned for the time of day alone.
+func (a *Agent) applyLearnedPreference(ctx context.Context, location string, decision *Decision) {
+	if a.learned == nil || decision.Action != "on" {
+		return
+	}
+
+	timeOfDay := getTimeOfDay(a.clock.Now())
+	keys := []string{anyPattern}
+	if patternID := a.activePatternID(location); patternID != anyPattern {
+		keys = []string{patternID}
+		if decision.Reason != "pattern_scene" {
+			keys = append(keys, anyPattern)
+		}
+	}
+
+	for _, patternID := range keys {
+		pref, err := a.learned.Lookup(ctx, location, timeOfDay, patternID)
+		if err != nil {
+			a.logger.Error("Failed to look up learned preference",
+				"location", location,
+				"error", err)
+			return
+		}
+		if pref == nil || pref.Observations < a.cfg.LightPreferenceMinObservations {
+			continue
+		}
+
+		decision.Brightness = int(math.Round(pref.Brightness))
+		if pref.ColorTemp > 0 {
+			decision.ColorTemp = int(math.Round(pref.ColorTemp/50)) * 50
+		}
+		decision.Reason = "learned_preference"
+		if decision.Details == nil {
+			decision.Details = map[string]interface{}{}
+		}
+		decision.Details["preference_time_of_day"] = timeOfDay
+		decision.Details["preference_pattern_id"] = patternID
+		decision.Details["preference_observations"] = pref.Observations
+		return
+	}
+}
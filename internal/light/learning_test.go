@@ -0,0 +1,105 @@
+package light
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// memoryLearned is an in-memory LearnedPreferences
+type memoryLearned struct {
+	prefs map[string]*LearnedPreference
+}
+
+func (m *memoryLearned) Observe(ctx context.Context, obs PreferenceObservation) error {
+	key := obs.Location + "/" + obs.TimeOfDay + "/" + obs.PatternID
+	pref, ok := m.prefs[key]
+	if !ok {
+		pref = &LearnedPreference{Location: obs.Location, TimeOfDay: obs.TimeOfDay, PatternID: obs.PatternID}
+		m.prefs[key] = pref
+	}
+	pref.learn(obs.Brightness, obs.ColorTemp)
+	return nil
+}
+
+func (m *memoryLearned) Lookup(ctx context.Context, location, timeOfDay, patternID string) (*LearnedPreference, error) {
+	if pref, ok := m.prefs[location+"/"+timeOfDay+"/"+patternID]; ok {
+		stored := *pref
+		return &stored, nil
+	}
+	return nil, nil
+}
+
+func TestLearnedPreference_Learn(t *testing.T) {
+	var pref LearnedPreference
+	pref.learn(40, 0)
+	pref.learn(20, 2700)
+	if pref.Brightness != 30 || pref.ColorTemp != 2700 || pref.Observations != 2 {
+		t.Fatalf("expected mean of first observations, got %+v", pref)
+	}
+
+	// After the window fills, old habits fade instead of being averaged forever
+	for i := 0; i < 50; i++ {
+		pref.learn(80, 2700)
+	}
+	if pref.Brightness < 79 {
+		t.Errorf("brightness %.1f should have converged toward 80", pref.Brightness)
+	}
+}
+
+func TestAgent_LearnsFromRepeatedManualAdjustments(t *testing.T) {
+	learned := &memoryLearned{prefs: map[string]*LearnedPreference{}}
+	a := newSceneTestAgent(&memoryPreferences{scenes: map[string]*PatternScene{}})
+	a.SetLearnedPreferences(learned)
+	ctx := context.Background()
+
+	a.observeManualAdjustment(ctx, "study", 60, 4000)
+	a.observeManualAdjustment(ctx, "study", 70, 4100)
+
+	decision := &Decision{Action: "on", Brightness: 30, ColorTemp: 2700, Reason: "dark_room"}
+	a.applyLearnedPreference(ctx, "study", decision)
+	if decision.Reason != "dark_room" {
+		t.Fatalf("two adjustments should not be enough to learn, got %+v", decision)
+	}
+
+	a.observeManualAdjustment(ctx, "study", 80, 4200)
+	a.applyLearnedPreference(ctx, "study", decision)
+	if decision.Reason != "learned_preference" || decision.Brightness != 70 || decision.ColorTemp != 4100 {
+		t.Errorf("expected learned 70%%/4100K, got %+v", decision)
+	}
+
+	off := &Decision{Action: "off", Reason: "room_empty"}
+	a.applyLearnedPreference(ctx, "study", off)
+	if off.Reason != "room_empty" {
+		t.Error("learned preferences only apply to \"on\" decisions")
+	}
+}
+
+func TestAgent_LearnedPreferencePerPattern(t *testing.T) {
+	learned := &memoryLearned{prefs: map[string]*LearnedPreference{}}
+	a := newSceneTestAgent(&memoryPreferences{scenes: map[string]*PatternScene{}})
+	a.SetLearnedPreferences(learned)
+	a.cfg.LightPreferenceMinObservations = 1
+	ctx := context.Background()
+	patternID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	// Learned without a pattern: a pattern scene takes precedence
+	a.observeManualAdjustment(ctx, "living_room", 90, 4000)
+	a.patterns.activate("living_room", activePattern{ID: patternID, Name: "Evening wind-down", Type: "leisure", ExpiresAt: time.Now().Add(time.Hour)})
+
+	decision := &Decision{Action: "on", Brightness: 50}
+	a.applyActiveScene(ctx, "living_room", decision)
+	a.applyLearnedPreference(ctx, "living_room", decision)
+	if decision.Reason != "pattern_scene" || decision.Brightness != 30 {
+		t.Fatalf("pattern scene should win over a time-of-day preference, got %+v", decision)
+	}
+
+	// Adjusting during the pattern teaches the pattern's own preference
+	a.observeManualAdjustment(ctx, "living_room", 45, 2700)
+	decision = &Decision{Action: "on", Brightness: 50}
+	a.applyActiveScene(ctx, "living_room", decision)
+	a.applyLearnedPreference(ctx, "living_room", decision)
+	if decision.Reason != "learned_preference" || decision.Brightness != 45 || decision.Details["preference_pattern_id"] != patternID {
+		t.Errorf("expected the pattern's learned preference, got %+v", decision)
+	}
+}
@@ -3,6 +3,8 @@ package light
 import (
 	"sync"
 	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 )
 
 // Override represents a manual override for a location
@@ -15,12 +17,14 @@ type Override struct {
 type OverrideManager struct {
 	mu        sync.RWMutex
 	overrides map[string]time.Time
+	clock     clock.Clock
 }
 
-// NewOverrideManager creates a new override manager
-func NewOverrideManager() *OverrideManager {
+// NewOverrideManager creates a new override manager whose overrides expire on clk
+func NewOverrideManager(clk clock.Clock) *OverrideManager {
 	return &OverrideManager{
 		overrides: make(map[string]time.Time),
+		clock:     clk,
 	}
 }
 
@@ -29,7 +33,7 @@ func (om *OverrideManager) SetManualOverride(location string, durationMinutes in
 	om.mu.Lock()
 	defer om.mu.Unlock()
 
-	expiresAt := time.Now().Add(time.Duration(durationMinutes) * time.Minute)
+	expiresAt := om.clock.Now().Add(time.Duration(durationMinutes) * time.Minute)
 	om.overrides[location] = expiresAt
 
 	return expiresAt
@@ -47,7 +51,7 @@ func (om *OverrideManager) CheckManualOverride(location string) bool {
 	}
 
 	// Check if expired
-	if time.Now().After(expiresAt) {
+	if om.clock.Now().After(expiresAt) {
 		// Clean up expired override
 		delete(om.overrides, location)
 		return false
@@ -76,7 +80,7 @@ func (om *OverrideManager) GetManualOverrides() []string {
 	defer om.mu.RUnlock()
 
 	locations := make([]string, 0, len(om.overrides))
-	now := time.Now()
+	now := om.clock.Now()
 
 	for location, expiresAt := range om.overrides {
 		if now.Before(expiresAt) {
@@ -92,7 +96,7 @@ func (om *OverrideManager) CleanupExpiredOverrides() int {
 	om.mu.Lock()
 	defer om.mu.Unlock()
 
-	now := time.Now()
+	now := om.clock.Now()
 	cleaned := 0
 
 	for location, expiresAt := range om.overrides {
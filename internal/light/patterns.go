@@ -0,0 +1,463 @@
+package light
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// PredictionTopic carries next-activity predictions from the behavior agent
+const PredictionTopic = "automation/behavior/prediction"
+
+// noPattern is how an unset pattern_id UUID arrives in prediction messages
+const noPattern = "00000000-0000-0000-0000-000000000000"
+
+// PatternScene is the lighting applied in a room while a behavioral pattern is active
+type PatternScene struct {
+	PatternID  string
+	Location   string
+	Brightness int
+	ColorTemp  int
+	Source     string // "default" (derived from the pattern type) or "user"
+
+	Applied    int
+	Accepted   int
+	Overridden int
+}
+
+// minFeedback is how many applications a scene gets before feedback can retire it
+const minFeedback = 5
+
+// Trusted reports whether the scene should still be applied: scenes that are
+// overridden more often than not are left to the normal lighting rules
+func (s PatternScene) Trusted() bool {
+	return s.Applied < minFeedback || s.Overridden*2 <= s.Applied
+}
+
+// ScenePreferences stores per-pattern scenes and their feedback
+type ScenePreferences interface {
+	// Get returns the scene for a pattern in a room, nil when none is stored
+	Get(ctx context.Context, patternID, location string) (*PatternScene, error)
+	// Save stores a scene's settings, keeping its feedback counters
+	Save(ctx context.Context, scene PatternScene) error
+	RecordApplied(ctx context.Context, patternID, location string) error
+	RecordFeedback(ctx context.Context, patternID, location string, overridden bool) error
+}
+
+// PreferenceStore keeps pattern scenes in Postgres
+type PreferenceStore struct {
+	db *sql.DB
+}
+
+// NewPreferenceStore creates a preference store on db
+func NewPreferenceStore(db *sql.DB) *PreferenceStore {
+	return &PreferenceStore{db: db}
+}
+
+// Get returns the stored scene for a pattern in a room. Scenes of archived
+// patterns are ignored.
+func (s *PreferenceStore) Get(ctx context.Context, patternID, location string) (*PatternScene, error) {
+	scene := PatternScene{PatternID: patternID, Location: location}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT p.brightness, p.color_temp, p.source,
+		       p.applied_count, p.accepted_count, p.overridden_count
+		FROM pattern_lighting_preferences p
+		JOIN behavioral_patterns bp ON bp.id = p.pattern_id
+		WHERE p.pattern_id = $1 AND p.location = $2 AND bp.archived_at IS NULL
+	`, patternID, location).Scan(&scene.Brightness, &scene.ColorTemp, &scene.Source,
+		&scene.Applied, &scene.Accepted, &scene.Overridden)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pattern scene: %w", err)
+	}
+	return &scene, nil
+}
+
+// Save inserts or updates a scene's settings
+func (s *PreferenceStore) Save(ctx context.Context, scene PatternScene) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO pattern_lighting_preferences (pattern_id, location, brightness, color_temp, source)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (pattern_id, location) DO UPDATE
+		SET brightness = EXCLUDED.brightness,
+		    color_temp = EXCLUDED.color_temp,
+		    source = EXCLUDED.source,
+		    updated_at = NOW()
+	`, scene.PatternID, scene.Location, scene.Brightness, scene.ColorTemp, scene.Source)
+	if err != nil {
+		return fmt.Errorf("failed to save pattern scene: %w", err)
+	}
+	return nil
+}
+
+// RecordApplied counts one application of a scene
+func (s *PreferenceStore) RecordApplied(ctx context.Context, patternID, location string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE pattern_lighting_preferences
+		SET applied_count = applied_count + 1, last_applied_at = NOW()
+		WHERE pattern_id = $1 AND location = $2
+	`, patternID, location)
+	if err != nil {
+		return fmt.Errorf("failed to record scene application: %w", err)
+	}
+	return nil
+}
+
+// RecordFeedback counts whether an applied scene was kept or manually overridden
+func (s *PreferenceStore) RecordFeedback(ctx context.Context, patternID, location string, overridden bool) error {
+	column := "accepted_count"
+	if overridden {
+		column = "overridden_count"
+	}
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE pattern_lighting_preferences
+		SET %s = %s + 1
+		WHERE pattern_id = $1 AND location = $2
+	`, column, column), patternID, location)
+	if err != nil {
+		return fmt.Errorf("failed to record scene feedback: %w", err)
+	}
+	return nil
+}
+
+// defaultScenes seeds scenes from pattern type and name keywords, checked in order
+var defaultScenes = []struct {
+	keywords   []string
+	brightness int
+	colorTemp  int
+}{
+	{[]string{"sleep", "bedtime", "night"}, 10, 2200},
+	{[]string{"wind", "relax", "evening", "leisure"}, 30, 2400},
+	{[]string{"media", "tv", "movie"}, 20, 2400},
+	{[]string{"wake", "morning"}, 70, 4000},
+	{[]string{"meal", "cook", "dinner", "breakfast", "lunch"}, 80, 3000},
+	{[]string{"work", "study", "read"}, 90, 4500},
+}
+
+// defaultScene derives a scene from the pattern's type and name, e.g. dim warm
+// light for an evening wind-down. ok is false for patterns with no obvious lighting.
+func defaultScene(patternType, patternName string) (brightness, colorTemp int, ok bool) {
+	text := strings.ToLower(patternType + " " + patternName)
+	for _, d := range defaultScenes {
+		for _, keyword := range d.keywords {
+			if strings.Contains(text, keyword) {
+				return d.brightness, d.colorTemp, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// applyPatternScene replaces the brightness and color temperature of an "on"
+// decision with the pattern scene
+func applyPatternScene(decision *Decision, scene *PatternScene, patternName string) {
+	decision.Brightness = scene.Brightness
+	decision.ColorTemp = scene.ColorTemp
+	decision.Reason = "pattern_scene"
+	if decision.Details == nil {
+		decision.Details = map[string]interface{}{}
+	}
+	decision.Details["pattern_id"] = scene.PatternID
+	decision.Details["pattern_name"] = patternName
+	decision.Details["scene_source"] = scene.Source
+}
+
+// activePattern is a pattern the behavior agent expects to play out in a room
+type activePattern struct {
+	ID        string
+	Name      string
+	Type      string
+	ExpiresAt time.Time
+}
+
+// appliedScene is a scene in effect in a room
+type appliedScene struct {
+	PatternID string
+	At        time.Time
+	Settled   bool // feedback already recorded
+}
+
+// patternTracker follows active patterns per room and the scenes applied for them
+type patternTracker struct {
+	mu      sync.Mutex
+	active  map[string]activePattern
+	applied map[string]appliedScene
+}
+
+func newPatternTracker() *patternTracker {
+	return &patternTracker{
+		active:  make(map[string]activePattern),
+		applied: make(map[string]appliedScene),
+	}
+}
+
+func (t *patternTracker) activate(location string, p activePattern) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active[location] = p
+}
+
+// current returns the unexpired pattern for a room
+func (t *patternTracker) current(location string, now time.Time) (activePattern, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.active[location]
+	if ok && now.After(p.ExpiresAt) {
+		delete(t.active, location)
+		return activePattern{}, false
+	}
+	return p, ok
+}
+
+// markApplied records a scene application, reporting false when the same
+// pattern's scene is already in effect in the room
+func (t *patternTracker) markApplied(location, patternID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if prev, ok := t.applied[location]; ok && prev.PatternID == patternID {
+		return false
+	}
+	t.applied[location] = appliedScene{PatternID: patternID, At: now}
+	return true
+}
+
+// clear forgets the scene in a room once its lights go off
+func (t *patternTracker) clear(location string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.applied, location)
+}
+
+// overridden takes the room's scene on a manual change, reporting whether it
+// was still within the feedback window
+func (t *patternTracker) overridden(location string, now time.Time, window time.Duration) (appliedScene, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	scene, ok := t.applied[location]
+	if !ok {
+		return appliedScene{}, false
+	}
+	delete(t.applied, location)
+	return scene, !scene.Settled && now.Sub(scene.At) <= window
+}
+
+// accepted settles the scenes that outlived the feedback window without a
+// manual change
+func (t *patternTracker) accepted(now time.Time, window time.Duration) map[string]appliedScene {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	kept := make(map[string]appliedScene)
+	for location, scene := range t.applied {
+		if !scene.Settled && now.Sub(scene.At) > window {
+			kept[location] = scene
+			scene.Settled = true
+			t.applied[location] = scene
+		}
+	}
+	return kept
+}
+
+// SetScenePreferences enables pattern scenes backed by store
+func (a *Agent) SetScenePreferences(store ScenePreferences) {
+	a.scenes = store
+	a.patterns = newPatternTracker()
+}
+
+// handlePredictionMessage activates the predicted pattern in the current and
+// next room and pre-sets the next room's scene before anyone arrives
+func (a *Agent) handlePredictionMessage(msg mqtt.Message) {
+	var prediction struct {
+		CurrentLocation string    `json:"current_location"`
+		NextLocation    string    `json:"next_location"`
+		Confidence      float64   `json:"confidence"`
+		PatternID       string    `json:"pattern_id"`
+		PatternName     string    `json:"pattern_name"`
+		PatternType     string    `json:"pattern_type"`
+		ExpiresAt       time.Time `json:"expires_at"`
+	}
+
+	if _, err := events.Unmarshal(msg.Payload(), &prediction); err != nil {
+		a.logger.Error("Failed to parse prediction message", "error", err)
+		return
+	}
+
+	if prediction.PatternID == "" || prediction.PatternID == noPattern ||
+		prediction.Confidence < a.cfg.LightPatternMinConfidence {
+		return
+	}
+
+	pattern := activePattern{
+		ID:        prediction.PatternID,
+		Name:      prediction.PatternName,
+		Type:      prediction.PatternType,
+		ExpiresAt: prediction.ExpiresAt,
+	}
+	for _, location := range []string{prediction.CurrentLocation, prediction.NextLocation} {
+		if location != "" {
+			a.patterns.activate(location, pattern)
+		}
+	}
+
+	a.logger.Debug("Pattern activated",
+		"pattern", pattern.Name,
+		"current_location", prediction.CurrentLocation,
+		"next_location", prediction.NextLocation,
+		"expires_at", pattern.ExpiresAt)
+
+	if prediction.NextLocation != "" && prediction.NextLocation != prediction.CurrentLocation {
+		a.presetScene(context.Background(), prediction.NextLocation, pattern, prediction.Confidence)
+	}
+}
+
+// presetScene turns on the pattern scene in a room that is about to be
+// entered, unless it is already occupied, bright enough or under manual control
+func (a *Agent) presetScene(ctx context.Context, location string, pattern activePattern, confidence float64) {
+	if a.isHouseAway() || a.overrideManager.CheckManualOverride(location) {
+		return
+	}
+
+	a.contextMux.RLock()
+	locationContext, exists := a.locationContexts[location]
+	a.contextMux.RUnlock()
+	if exists && locationContext.OccupancyState == "occupied" {
+		return // the next occupancy decision applies the scene
+	}
+
+	if assessment := a.analyzer.GetIlluminanceAssessment(ctx, location, getTimeOfDay(a.clock.Now())); assessment.State == "bright" {
+		return
+	}
+
+	scene := a.patternScene(ctx, location, pattern)
+	if scene == nil {
+		return
+	}
+
+	decision := &Decision{Action: "on", Confidence: confidence}
+	applyPatternScene(decision, scene, pattern.Name)
+	decision.Reason = "pattern_preset"
+
+	if err := a.publishLightingCommand(location, decision); err != nil {
+		a.logger.Error("Failed to publish pattern preset",
+			"location", location,
+			"error", err)
+		return
+	}
+	a.recordSceneApplied(ctx, location, scene.PatternID)
+
+	a.logger.Info("Pattern scene preset",
+		"location", location,
+		"pattern", pattern.Name,
+		"brightness", scene.Brightness,
+		"color_temp", scene.ColorTemp)
+}
+
+// applyActiveScene swaps in the scene of the room's active pattern for an "on" decision
+func (a *Agent) applyActiveScene(ctx context.Context, location string, decision *Decision) {
+	if a.scenes == nil {
+		return
+	}
+	if decision.Action == "off" {
+		a.patterns.clear(location)
+		return
+	}
+	if decision.Action != "on" {
+		return
+	}
+	pattern, ok := a.patterns.current(location, a.clock.Now())
+	if !ok {
+		return
+	}
+	scene := a.patternScene(ctx, location, pattern)
+	if scene == nil {
+		return
+	}
+	applyPatternScene(decision, scene, pattern.Name)
+	a.recordSceneApplied(ctx, location, scene.PatternID)
+}
+
+// patternScene looks up the scene for a pattern in a room, seeding a default
+// from the pattern type the first time. Returns nil if there is no usable scene.
+func (a *Agent) patternScene(ctx context.Context, location string, pattern activePattern) *PatternScene {
+	scene, err := a.scenes.Get(ctx, pattern.ID, location)
+	if err != nil {
+		a.logger.Error("Failed to load pattern scene",
+			"location", location,
+			"pattern_id", pattern.ID,
+			"error", err)
+		return nil
+	}
+	if scene != nil {
+		if !scene.Trusted() {
+			return nil
+		}
+		return scene
+	}
+
+	brightness, colorTemp, ok := defaultScene(pattern.Type, pattern.Name)
+	if !ok {
+		return nil
+	}
+	scene = &PatternScene{
+		PatternID:  pattern.ID,
+		Location:   location,
+		Brightness: brightness,
+		ColorTemp:  colorTemp,
+		Source:     "default",
+	}
+	if err := a.scenes.Save(ctx, *scene); err != nil {
+		a.logger.Error("Failed to seed pattern scene",
+			"location", location,
+			"pattern_id", pattern.ID,
+			"error", err)
+	}
+	return scene
+}
+
+// recordSceneApplied counts a scene application once per pattern and room.
+// In shadow mode the scene never reaches the lights, so there is no feedback.
+func (a *Agent) recordSceneApplied(ctx context.Context, location, patternID string) {
+	if a.cfg.ShadowMode || !a.patterns.markApplied(location, patternID, a.clock.Now()) {
+		return
+	}
+	if err := a.scenes.RecordApplied(ctx, patternID, location); err != nil {
+		a.logger.Error("Failed to record pattern scene", "location", location, "error", err)
+	}
+}
+
+// recordSceneOverride counts a manual change shortly after a scene as an override
+func (a *Agent) recordSceneOverride(ctx context.Context, location string) {
+	if a.scenes == nil {
+		return
+	}
+	window := time.Duration(a.cfg.LightPatternFeedbackMinutes) * time.Minute
+	scene, ok := a.patterns.overridden(location, a.clock.Now(), window)
+	if !ok {
+		return
+	}
+	if err := a.scenes.RecordFeedback(ctx, scene.PatternID, location, true); err != nil {
+		a.logger.Error("Failed to record scene override", "location", location, "error", err)
+		return
+	}
+	a.logger.Info("Pattern scene overridden manually", "location", location, "pattern_id", scene.PatternID)
+}
+
+// recordScenesAccepted counts scenes left alone for the feedback window as accepted
+func (a *Agent) recordScenesAccepted(ctx context.Context) {
+	if a.scenes == nil {
+		return
+	}
+	window := time.Duration(a.cfg.LightPatternFeedbackMinutes) * time.Minute
+	for location, scene := range a.patterns.accepted(a.clock.Now(), window) {
+		if err := a.scenes.RecordFeedback(ctx, scene.PatternID, location, false); err != nil {
+			a.logger.Error("Failed to record scene acceptance", "location", location, "error", err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package light
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+)
+
+// memoryPreferences is an in-memory ScenePreferences
+type memoryPreferences struct {
+	scenes map[string]*PatternScene
+}
+
+func (m *memoryPreferences) key(patternID, location string) string { return patternID + "/" + location }
+
+func (m *memoryPreferences) Get(ctx context.Context, patternID, location string) (*PatternScene, error) {
+	if scene, ok := m.scenes[m.key(patternID, location)]; ok {
+		stored := *scene
+		return &stored, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryPreferences) Save(ctx context.Context, scene PatternScene) error {
+	if existing, ok := m.scenes[m.key(scene.PatternID, scene.Location)]; ok {
+		existing.Brightness, existing.ColorTemp, existing.Source = scene.Brightness, scene.ColorTemp, scene.Source
+		return nil
+	}
+	m.scenes[m.key(scene.PatternID, scene.Location)] = &scene
+	return nil
+}
+
+func (m *memoryPreferences) RecordApplied(ctx context.Context, patternID, location string) error {
+	m.scenes[m.key(patternID, location)].Applied++
+	return nil
+}
+
+func (m *memoryPreferences) RecordFeedback(ctx context.Context, patternID, location string, overridden bool) error {
+	if overridden {
+		m.scenes[m.key(patternID, location)].Overridden++
+	} else {
+		m.scenes[m.key(patternID, location)].Accepted++
+	}
+	return nil
+}
+
+type fakeMessage struct {
+	topic   string
+	payload []byte
+}
+
+func (m *fakeMessage) Topic() string   { return m.topic }
+func (m *fakeMessage) Payload() []byte { return m.payload }
+func (m *fakeMessage) Ack()            {}
+
+func newSceneTestAgent(store ScenePreferences) *Agent {
+	cfg := config.NewConfig()
+	a := &Agent{
+		cfg:              cfg,
+		logger:           slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})),
+		clock:            clock.NewTimeManager(slog.Default()),
+		locationContexts: map[string]*LocationContext{"living_room": {OccupancyState: "occupied"}},
+		overrideManager:  NewOverrideManager(clock.Real{}),
+	}
+	a.SetScenePreferences(store)
+	return a
+}
+
+func TestDefaultScene(t *testing.T) {
+	tests := []struct {
+		patternType, name     string
+		brightness, colorTemp int
+		ok                    bool
+	}{
+		{"leisure", "Evening wind-down", 30, 2400, true},
+		{"sleep_routine", "", 10, 2200, true},
+		{"morning_routine", "Weekday wake-up", 70, 4000, true},
+		{"meal_preparation", "", 80, 3000, true},
+		{"transition", "Hallway pass-through", 0, 0, false},
+	}
+	for _, tt := range tests {
+		brightness, colorTemp, ok := defaultScene(tt.patternType, tt.name)
+		if brightness != tt.brightness || colorTemp != tt.colorTemp || ok != tt.ok {
+			t.Errorf("defaultScene(%q, %q) = %d, %d, %v; want %d, %d, %v", tt.patternType, tt.name,
+				brightness, colorTemp, ok, tt.brightness, tt.colorTemp, tt.ok)
+		}
+	}
+}
+
+func TestPatternScene_Trusted(t *testing.T) {
+	if !(PatternScene{Applied: 4, Overridden: 4}).Trusted() {
+		t.Error("scene with too little feedback should be trusted")
+	}
+	if !(PatternScene{Applied: 10, Overridden: 5}).Trusted() {
+		t.Error("scene overridden half the time should be trusted")
+	}
+	if (PatternScene{Applied: 10, Overridden: 6}).Trusted() {
+		t.Error("scene overridden most of the time should not be trusted")
+	}
+}
+
+func TestAgent_PatternSceneFeedback(t *testing.T) {
+	store := &memoryPreferences{scenes: map[string]*PatternScene{}}
+	a := newSceneTestAgent(store)
+	ctx := context.Background()
+	patternID := "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+	a.handlePredictionMessage(&fakeMessage{topic: PredictionTopic, payload: []byte(`{
+		"current_location": "living_room", "next_location": "living_room", "confidence": 0.8,
+		"pattern_id": "` + patternID + `", "pattern_name": "Evening wind-down", "pattern_type": "leisure",
+		"expires_at": "` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`)})
+
+	decision := &Decision{Action: "on", Brightness: 80, ColorTemp: 2700, Reason: "dark_room"}
+	a.applyActiveScene(ctx, "living_room", decision)
+	if decision.Brightness != 30 || decision.ColorTemp != 2400 || decision.Reason != "pattern_scene" {
+		t.Fatalf("scene not applied: %+v", decision)
+	}
+
+	// Repeated decisions for the same pattern count as one application
+	a.applyActiveScene(ctx, "living_room", &Decision{Action: "on"})
+	scene := store.scenes[patternID+"/living_room"]
+	if scene.Applied != 1 || scene.Source != "default" {
+		t.Fatalf("unexpected stored scene %+v", scene)
+	}
+
+	a.recordSceneOverride(ctx, "living_room")
+	if scene.Overridden != 1 {
+		t.Errorf("manual change right after the scene should count as override, got %+v", scene)
+	}
+
+	// Lights off and on again is a new application, kept past the window
+	a.applyActiveScene(ctx, "living_room", &Decision{Action: "off"})
+	a.applyActiveScene(ctx, "living_room", &Decision{Action: "on"})
+	a.cfg.LightPatternFeedbackMinutes = 0
+	a.recordScenesAccepted(ctx)
+	a.recordScenesAccepted(ctx)
+	if scene.Applied != 2 || scene.Accepted != 1 {
+		t.Errorf("expected second application accepted once, got %+v", scene)
+	}
+}
+
+func TestAgent_PredictionBelowConfidenceIgnored(t *testing.T) {
+	store := &memoryPreferences{scenes: map[string]*PatternScene{}}
+	a := newSceneTestAgent(store)
+
+	a.handlePredictionMessage(&fakeMessage{topic: PredictionTopic, payload: []byte(`{
+		"current_location": "living_room", "next_location": "kitchen", "confidence": 0.3,
+		"pattern_id": "7c9e6679-7425-40de-944b-e07fc1f90ae7", "pattern_type": "leisure",
+		"expires_at": "` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`)})
+
+	decision := &Decision{Action: "on", Brightness: 80}
+	a.applyActiveScene(context.Background(), "living_room", decision)
+	if decision.Brightness != 80 || len(store.scenes) != 0 {
+		t.Errorf("low-confidence prediction should not activate a scene: %+v", decision)
+	}
+}
+
+func TestAgent_EnvelopedPrediction(t *testing.T) {
+	store := &memoryPreferences{scenes: map[string]*PatternScene{}}
+	a := newSceneTestAgent(store)
+
+	payload, err := events.Marshal(events.ProducerBehavior, map[string]interface{}{
+		"current_location": "living_room", "next_location": "living_room", "confidence": 0.8,
+		"pattern_id": "7c9e6679-7425-40de-944b-e07fc1f90ae7", "pattern_type": "leisure",
+		"expires_at": time.Now().Add(time.Hour).Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.handlePredictionMessage(&fakeMessage{topic: PredictionTopic, payload: payload})
+
+	decision := &Decision{Action: "on", Brightness: 80, ColorTemp: 2700}
+	a.applyActiveScene(context.Background(), "living_room", decision)
+	if decision.Brightness != 30 || decision.ColorTemp != 2400 {
+		t.Errorf("enveloped prediction should activate its scene: %+v", decision)
+	}
+}
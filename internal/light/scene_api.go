@@ -0,0 +1,116 @@
+package light
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/saaga0h/jeeves-platform/pkg/auth"
+)
+
+// SceneAPI serves scene definitions and activation over HTTP
+type SceneAPI struct {
+	agent  *Agent
+	logger *slog.Logger
+}
+
+// NewSceneAPI creates the scene API for an agent with scenes enabled
+func NewSceneAPI(agent *Agent, logger *slog.Logger) *SceneAPI {
+	return &SceneAPI{agent: agent, logger: logger.With("component", "scene-api")}
+}
+
+// Register adds the scene endpoints to mux. Listing scenes requires the viewer
+// role; defining, deleting and activating them the admin role.
+func (api *SceneAPI) Register(mux *http.ServeMux, authn auth.Authenticator) {
+	viewer := func(h http.HandlerFunc) http.HandlerFunc {
+		return auth.RequireRole(authn, auth.RoleViewer, api.logger, h)
+	}
+	admin := func(h http.HandlerFunc) http.HandlerFunc {
+		return auth.RequireRole(authn, auth.RoleAdmin, api.logger, h)
+	}
+
+	mux.HandleFunc("GET /api/scenes", viewer(api.handleList))
+	mux.HandleFunc("GET /api/scenes/{location}", viewer(api.handleList))
+	mux.HandleFunc("PUT /api/scenes/{location}/{name}", admin(api.handlePut))
+	mux.HandleFunc("DELETE /api/scenes/{location}/{name}", admin(api.handleDelete))
+	mux.HandleFunc("POST /api/scenes/{location}/{name}/activate", admin(api.handleActivate))
+}
+
+// handleList serves GET /api/scenes[/{location}]
+func (api *SceneAPI) handleList(w http.ResponseWriter, r *http.Request) {
+	manager := api.agent.GetSceneManager()
+	scenes := manager.All()
+	if location := r.PathValue("location"); location != "" {
+		scenes = manager.Scenes(location)
+	}
+	if scenes == nil {
+		scenes = []Scene{}
+	}
+	writeJSON(w, http.StatusOK, scenes)
+}
+
+// handlePut serves PUT /api/scenes/{location}/{name} with the scene's lights
+func (api *SceneAPI) handlePut(w http.ResponseWriter, r *http.Request) {
+	var scene Scene
+	if err := json.NewDecoder(r.Body).Decode(&scene); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	scene.Location, scene.Name = r.PathValue("location"), r.PathValue("name")
+
+	if err := api.agent.GetSceneManager().Save(r.Context(), scene); err != nil {
+		api.writeError(w, "save", err)
+		return
+	}
+
+	api.logger.Info("Scene saved", "location", scene.Location, "scene", scene.Name)
+	saved, _ := api.agent.GetSceneManager().Get(scene.Location, scene.Name)
+	writeJSON(w, http.StatusOK, saved)
+}
+
+// handleDelete serves DELETE /api/scenes/{location}/{name}
+func (api *SceneAPI) handleDelete(w http.ResponseWriter, r *http.Request) {
+	location, name := r.PathValue("location"), r.PathValue("name")
+	if err := api.agent.GetSceneManager().Delete(r.Context(), location, name); err != nil {
+		api.writeError(w, "delete", err)
+		return
+	}
+
+	api.logger.Info("Scene deleted", "location", location, "scene", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleActivate serves POST /api/scenes/{location}/{name}/activate
+func (api *SceneAPI) handleActivate(w http.ResponseWriter, r *http.Request) {
+	decision, err := api.agent.ActivateScene(r.PathValue("location"), r.PathValue("name"))
+	if err != nil {
+		api.writeError(w, "activate", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"action":     decision.Action,
+		"scene":      decision.Scene.Name,
+		"brightness": decision.Brightness,
+		"color_temp": decision.ColorTemp,
+	})
+}
+
+func (api *SceneAPI) writeError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, ErrSceneNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidScene):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		api.logger.Error("Scene request failed", "op", op, "error", err)
+		http.Error(w, "failed to "+op+" scene", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,443 @@
+package light
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// Scene topics: wall switches activate scenes by name, scene definitions are
+// managed with retained messages (an empty payload deletes the scene)
+const (
+	SceneCommandTopic = "automation/command/scene/%s"   // {"scene": "relax"}
+	SceneConfigTopic  = "automation/config/scene/%s/%s" // Scene JSON
+)
+
+var (
+	// ErrSceneNotFound is returned for unknown scenes
+	ErrSceneNotFound = errors.New("scene not found")
+	// ErrInvalidScene is returned for scenes that fail validation
+	ErrInvalidScene = errors.New("invalid scene")
+)
+
+var sceneNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// LightState is one light's setting within a scene
+type LightState struct {
+	Light      string `json:"light"`
+	State      string `json:"state"`                // "on" or "off"
+	Brightness int    `json:"brightness,omitempty"` // 0-100
+	ColorTemp  int    `json:"color_temp,omitempty"` // Kelvin
+}
+
+// Scene is a named state for the lights (the group) of one room
+type Scene struct {
+	Location string       `json:"location"`
+	Name     string       `json:"name"`
+	Lights   []LightState `json:"lights"`
+	// Manual-only scenes are never picked by lighting decisions, only activated
+	ManualOnly bool      `json:"manual_only,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate checks the scene name and light settings
+func (s *Scene) Validate() error {
+	if s.Location == "" || !sceneNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("%w: location and a lowercase name are required", ErrInvalidScene)
+	}
+	if len(s.Lights) == 0 {
+		return fmt.Errorf("%w: scene has no lights", ErrInvalidScene)
+	}
+	for _, l := range s.Lights {
+		if l.Light == "" || (l.State != "on" && l.State != "off") {
+			return fmt.Errorf("%w: every light needs an id and state on/off", ErrInvalidScene)
+		}
+		if l.Brightness < 0 || l.Brightness > 100 {
+			return fmt.Errorf("%w: brightness of %s out of range", ErrInvalidScene, l.Light)
+		}
+	}
+	return nil
+}
+
+// Level returns the scene's mean brightness over all its lights (off = 0%)
+// and the mean color temperature of the lit ones; decisions match against it
+func (s *Scene) Level() (brightness, colorTemp float64) {
+	var kelvinSum float64
+	var kelvinCount int
+	for _, l := range s.Lights {
+		if l.State != "on" {
+			continue
+		}
+		brightness += float64(l.Brightness)
+		if l.ColorTemp > 0 {
+			kelvinSum += float64(l.ColorTemp)
+			kelvinCount++
+		}
+	}
+	if len(s.Lights) > 0 {
+		brightness /= float64(len(s.Lights))
+	}
+	if kelvinCount > 0 {
+		colorTemp = kelvinSum / float64(kelvinCount)
+	}
+	return brightness, colorTemp
+}
+
+// kelvinPerPercent weighs color temperature against brightness when matching
+// scenes: 50K is as far off as one brightness percent
+const kelvinPerPercent = 50
+
+// maxSceneDistance is how far a scene may be from the decision to be selected
+const maxSceneDistance = 25
+
+// SelectScene picks the scene closest to an "on" decision's brightness
+// and color temperature; nil when none is close enough
+func SelectScene(scenes []Scene, decision *Decision) *Scene {
+	if decision.Action != "on" {
+		return nil
+	}
+
+	var best *Scene
+	bestDistance := math.Inf(1)
+	for i := range scenes {
+		if scenes[i].ManualOnly {
+			continue
+		}
+		brightness, colorTemp := scenes[i].Level()
+		if brightness == 0 {
+			continue
+		}
+		distance := math.Abs(brightness - float64(decision.Brightness))
+		if colorTemp > 0 && decision.ColorTemp > 0 {
+			distance += math.Abs(colorTemp-float64(decision.ColorTemp)) / kelvinPerPercent
+		}
+		if distance < bestDistance {
+			best, bestDistance = &scenes[i], distance
+		}
+	}
+	if bestDistance > maxSceneDistance {
+		return nil
+	}
+	return best
+}
+
+// SceneStore keeps scenes in Postgres
+type SceneStore struct {
+	db *sql.DB
+}
+
+// NewSceneStore creates a scene store on db
+func NewSceneStore(db *sql.DB) *SceneStore {
+	return &SceneStore{db: db}
+}
+
+// List returns all scenes, ordered by location and name
+func (s *SceneStore) List(ctx context.Context) ([]Scene, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT location, name, lights, manual_only, updated_at
+		FROM light_scenes
+		ORDER BY location, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scenes: %w", err)
+	}
+	defer rows.Close()
+
+	var scenes []Scene
+	for rows.Next() {
+		var scene Scene
+		var lights []byte
+		if err := rows.Scan(&scene.Location, &scene.Name, &lights, &scene.ManualOnly, &scene.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scene: %w", err)
+		}
+		if err := json.Unmarshal(lights, &scene.Lights); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal lights of scene %s/%s: %w", scene.Location, scene.Name, err)
+		}
+		scenes = append(scenes, scene)
+	}
+	return scenes, rows.Err()
+}
+
+// Save creates or replaces a scene
+func (s *SceneStore) Save(ctx context.Context, scene Scene) error {
+	lights, err := json.Marshal(scene.Lights)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scene lights: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO light_scenes (location, name, lights, manual_only)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (household_id, location, name) DO UPDATE
+		SET lights = EXCLUDED.lights,
+		    manual_only = EXCLUDED.manual_only,
+		    updated_at = NOW()
+	`, scene.Location, scene.Name, lights, scene.ManualOnly)
+	if err != nil {
+		return fmt.Errorf("failed to save scene: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a scene
+func (s *SceneStore) Delete(ctx context.Context, location, name string) error {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM light_scenes WHERE location = $1 AND name = $2
+	`, location, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete scene: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrSceneNotFound
+	}
+	return nil
+}
+
+// SceneRepository is the scene storage the agent needs
+type SceneRepository interface {
+	List(ctx context.Context) ([]Scene, error)
+	Save(ctx context.Context, scene Scene) error
+	Delete(ctx context.Context, location, name string) error
+}
+
+// SceneManager caches scenes per room in front of the repository
+type SceneManager struct {
+	repo SceneRepository
+
+	mu     sync.RWMutex
+	scenes map[string][]Scene // by location, sorted by name
+}
+
+// NewSceneManager creates a scene manager on repo
+func NewSceneManager(repo SceneRepository) *SceneManager {
+	return &SceneManager{repo: repo, scenes: make(map[string][]Scene)}
+}
+
+// Load reads every scene from the repository
+func (m *SceneManager) Load(ctx context.Context) error {
+	scenes, err := m.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	byLocation := make(map[string][]Scene)
+	for _, scene := range scenes {
+		byLocation[scene.Location] = append(byLocation[scene.Location], scene)
+	}
+
+	m.mu.Lock()
+	m.scenes = byLocation
+	m.mu.Unlock()
+	return nil
+}
+
+// Scenes returns the scenes of a room
+func (m *SceneManager) Scenes(location string) []Scene {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Scene(nil), m.scenes[location]...)
+}
+
+// All returns every scene
+func (m *SceneManager) All() []Scene {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var all []Scene
+	for _, scenes := range m.scenes {
+		all = append(all, scenes...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Location != all[j].Location {
+			return all[i].Location < all[j].Location
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+// Get returns one scene
+func (m *SceneManager) Get(location, name string) (Scene, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, scene := range m.scenes[location] {
+		if scene.Name == name {
+			return scene, true
+		}
+	}
+	return Scene{}, false
+}
+
+// Save validates and stores a scene
+func (m *SceneManager) Save(ctx context.Context, scene Scene) error {
+	if err := scene.Validate(); err != nil {
+		return err
+	}
+	if err := m.repo.Save(ctx, scene); err != nil {
+		return err
+	}
+	scene.UpdatedAt = time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	scenes := m.scenes[scene.Location]
+	replaced := false
+	for i := range scenes {
+		if scenes[i].Name == scene.Name {
+			scenes[i], replaced = scene, true
+		}
+	}
+	if !replaced {
+		scenes = append(scenes, scene)
+		sort.Slice(scenes, func(i, j int) bool { return scenes[i].Name < scenes[j].Name })
+	}
+	m.scenes[scene.Location] = scenes
+	return nil
+}
+
+// Delete removes a scene
+func (m *SceneManager) Delete(ctx context.Context, location, name string) error {
+	if err := m.repo.Delete(ctx, location, name); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	scenes := m.scenes[location]
+	for i := range scenes {
+		if scenes[i].Name == name {
+			m.scenes[location] = append(scenes[:i:i], scenes[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// SetSceneManager enables scenes: decisions select a room's closest scene and
+// scenes can be activated by name
+func (a *Agent) SetSceneManager(manager *SceneManager) {
+	a.sceneManager = manager
+}
+
+// GetSceneManager returns the scene manager for API access, nil if scenes are disabled
+func (a *Agent) GetSceneManager() *SceneManager {
+	return a.sceneManager
+}
+
+// selectScene replaces an "on" decision's brightness and color temperature
+// with the room's closest scene
+func (a *Agent) selectScene(location string, decision *Decision) {
+	if a.sceneManager == nil {
+		return
+	}
+	scene := SelectScene(a.sceneManager.Scenes(location), decision)
+	if scene == nil {
+		return
+	}
+	brightness, colorTemp := scene.Level()
+	decision.Brightness = int(math.Round(brightness))
+	if colorTemp > 0 {
+		decision.ColorTemp = int(math.Round(colorTemp))
+	}
+	decision.Scene = scene
+	if decision.Details == nil {
+		decision.Details = map[string]interface{}{}
+	}
+	decision.Details["scene"] = scene.Name
+}
+
+// ActivateScene publishes a scene by name. Activation is a user choice, so it
+// sets a manual override like a manual light change does.
+func (a *Agent) ActivateScene(location, name string) (*Decision, error) {
+	if a.sceneManager == nil {
+		return nil, fmt.Errorf("scenes are not enabled")
+	}
+	scene, ok := a.sceneManager.Get(location, name)
+	if !ok {
+		return nil, ErrSceneNotFound
+	}
+
+	brightness, colorTemp := scene.Level()
+	decision := &Decision{
+		Action:     "on",
+		Brightness: int(math.Round(brightness)),
+		ColorTemp:  int(math.Round(colorTemp)),
+		Reason:     "scene_activated",
+		Confidence: 1.0,
+		Scene:      &scene,
+	}
+	if brightness == 0 {
+		decision.Action = "off"
+	}
+
+	a.overrideManager.SetManualOverride(location, a.cfg.ManualOverrideMinutes)
+	if err := a.publishLightingCommand(location, decision); err != nil {
+		return decision, fmt.Errorf("failed to publish scene: %w", err)
+	}
+
+	a.logger.Info("Scene activated", "location", location, "scene", name)
+	return decision, nil
+}
+
+// handleSceneCommand activates a scene requested by a wall switch or remote
+func (a *Agent) handleSceneCommand(msg mqtt.Message) {
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) != 4 {
+		a.logger.Warn("Invalid scene command topic format", "topic", msg.Topic())
+		return
+	}
+	location := parts[3]
+
+	var command struct {
+		Scene string `json:"scene"`
+	}
+	if err := json.Unmarshal(msg.Payload(), &command); err != nil {
+		a.logger.Error("Failed to parse scene command", "location", location, "error", err)
+		return
+	}
+
+	if _, err := a.ActivateScene(location, command.Scene); err != nil {
+		a.logger.Error("Failed to activate scene",
+			"location", location,
+			"scene", command.Scene,
+			"error", err)
+	}
+}
+
+// handleSceneConfig stores or deletes a scene definition
+func (a *Agent) handleSceneConfig(msg mqtt.Message) {
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) != 5 {
+		a.logger.Warn("Invalid scene config topic format", "topic", msg.Topic())
+		return
+	}
+	location, name := parts[3], parts[4]
+	ctx := context.Background()
+
+	if len(msg.Payload()) == 0 {
+		if err := a.sceneManager.Delete(ctx, location, name); err != nil && !errors.Is(err, ErrSceneNotFound) {
+			a.logger.Error("Failed to delete scene", "location", location, "scene", name, "error", err)
+		}
+		return
+	}
+
+	var scene Scene
+	if err := json.Unmarshal(msg.Payload(), &scene); err != nil {
+		a.logger.Error("Failed to parse scene definition", "location", location, "scene", name, "error", err)
+		return
+	}
+	scene.Location, scene.Name = location, name
+
+	if err := a.sceneManager.Save(ctx, scene); err != nil {
+		a.logger.Error("Failed to save scene", "location", location, "scene", name, "error", err)
+		return
+	}
+	a.logger.Info("Scene defined", "location", location, "scene", name, "lights", len(scene.Lights))
+}
@@ -0,0 +1,239 @@
+package light
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saaga0h/jeeves-platform/pkg/auth"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// memorySceneRepo is an in-memory SceneRepository
+type memorySceneRepo struct {
+	scenes map[string]Scene
+}
+
+func (r *memorySceneRepo) List(ctx context.Context) ([]Scene, error) {
+	var scenes []Scene
+	for _, scene := range r.scenes {
+		scenes = append(scenes, scene)
+	}
+	return scenes, nil
+}
+
+func (r *memorySceneRepo) Save(ctx context.Context, scene Scene) error {
+	r.scenes[scene.Location+"/"+scene.Name] = scene
+	return nil
+}
+
+func (r *memorySceneRepo) Delete(ctx context.Context, location, name string) error {
+	if _, ok := r.scenes[location+"/"+name]; !ok {
+		return ErrSceneNotFound
+	}
+	delete(r.scenes, location+"/"+name)
+	return nil
+}
+
+// publishRecorder records published messages by topic
+type publishRecorder struct {
+	published map[string][]byte
+}
+
+func (m *publishRecorder) Connect(ctx context.Context) error { return nil }
+func (m *publishRecorder) Disconnect()                       {}
+func (m *publishRecorder) IsConnected() bool                 { return true }
+func (m *publishRecorder) Subscribe(topic string, qos byte, handler mqtt.MessageHandler) error {
+	return nil
+}
+func (m *publishRecorder) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	m.published[topic] = payload
+	return nil
+}
+
+var livingRoomScenes = []Scene{
+	{Location: "living_room", Name: "bright", Lights: []LightState{
+		{Light: "ceiling", State: "on", Brightness: 90, ColorTemp: 4000},
+		{Light: "floor", State: "on", Brightness: 80, ColorTemp: 4000},
+	}},
+	{Location: "living_room", Name: "relax", Lights: []LightState{
+		{Light: "ceiling", State: "off"},
+		{Light: "floor", State: "on", Brightness: 60, ColorTemp: 2400},
+	}},
+	{Location: "living_room", Name: "party", ManualOnly: true, Lights: []LightState{
+		{Light: "ceiling", State: "on", Brightness: 30, ColorTemp: 2400},
+		{Light: "floor", State: "on", Brightness: 30, ColorTemp: 2400},
+	}},
+}
+
+func TestSelectScene(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision Decision
+		want     string
+	}{
+		{"dim warm picks relax", Decision{Action: "on", Brightness: 30, ColorTemp: 2400}, "relax"},
+		{"bright neutral picks bright", Decision{Action: "on", Brightness: 80, ColorTemp: 4500}, "bright"},
+		{"nothing close enough", Decision{Action: "on", Brightness: 5, ColorTemp: 5500}, ""},
+		{"off selects nothing", Decision{Action: "off"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectScene(livingRoomScenes, &tt.decision)
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.want {
+				t.Errorf("selected %q, want %q", name, tt.want)
+			}
+		})
+	}
+}
+
+func TestScene_Validate(t *testing.T) {
+	valid := livingRoomScenes[0]
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid scene rejected: %v", err)
+	}
+
+	invalid := []Scene{
+		{Location: "living_room", Name: "Movie Night", Lights: valid.Lights},
+		{Location: "living_room", Name: "empty"},
+		{Location: "living_room", Name: "dim", Lights: []LightState{{Light: "floor", State: "dim"}}},
+		{Location: "living_room", Name: "hot", Lights: []LightState{{Light: "floor", State: "on", Brightness: 150}}},
+	}
+	for _, scene := range invalid {
+		if err := scene.Validate(); err == nil {
+			t.Errorf("scene %q should be invalid", scene.Name)
+		}
+	}
+}
+
+func newSceneAPITest(t *testing.T) (*Agent, *publishRecorder, *http.ServeMux) {
+	repo := &memorySceneRepo{scenes: map[string]Scene{}}
+	for _, scene := range livingRoomScenes {
+		repo.scenes[scene.Location+"/"+scene.Name] = scene
+	}
+	manager := NewSceneManager(repo)
+	if err := manager.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	broker := &publishRecorder{published: map[string][]byte{}}
+	a := newSceneTestAgent(&memoryPreferences{scenes: map[string]*PatternScene{}})
+	a.mqtt = broker
+	a.SetSceneManager(manager)
+
+	cfg := config.NewConfig()
+	cfg.ObserverAuthMode = "token"
+	cfg.ObserverViewerTokens = []string{"viewer-token"}
+	cfg.ObserverAdminTokens = []string{"admin-token"}
+	authn, err := auth.New(cfg, a.logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	NewSceneAPI(a, a.logger).Register(mux, authn)
+	return a, broker, mux
+}
+
+// sceneRequest builds a scene API request sent with token
+func sceneRequest(method, target, body, token string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestSceneAPI(t *testing.T) {
+	a, broker, mux := newSceneAPITest(t)
+
+	body := `{"lights": [{"light": "floor", "state": "on", "brightness": 20, "color_temp": 2200}], "manual_only": true}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, sceneRequest(http.MethodPut, "/api/scenes/living_room/movie", body, "admin-token"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, sceneRequest(http.MethodGet, "/api/scenes/living_room", "", "viewer-token"))
+	var scenes []Scene
+	if err := json.Unmarshal(rec.Body.Bytes(), &scenes); err != nil || len(scenes) != 4 {
+		t.Fatalf("expected 4 living room scenes, got %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, sceneRequest(http.MethodPost, "/api/scenes/living_room/movie/activate", "", "admin-token"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("activate status %d: %s", rec.Code, rec.Body)
+	}
+	var command struct {
+		Scene  string       `json:"scene"`
+		Lights []LightState `json:"lights"`
+	}
+	if err := json.Unmarshal(broker.published["automation/command/light/living_room"], &command); err != nil {
+		t.Fatal(err)
+	}
+	if command.Scene != "movie" || len(command.Lights) != 1 || command.Lights[0].Brightness != 20 {
+		t.Errorf("unexpected scene command %+v", command)
+	}
+	if !a.overrideManager.CheckManualOverride("living_room") {
+		t.Error("activating a scene should set a manual override")
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, sceneRequest(http.MethodPut, "/api/scenes/living_room/bad", `{"lights": []}`, "admin-token"))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid scene: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, sceneRequest(http.MethodDelete, "/api/scenes/living_room/nope", "", "admin-token"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown scene: status %d, want 404", rec.Code)
+	}
+}
+
+func TestSceneAPI_Roles(t *testing.T) {
+	_, broker, mux := newSceneAPITest(t)
+
+	tests := []struct {
+		method, target, token string
+		want                  int
+	}{
+		{http.MethodGet, "/api/scenes", "", http.StatusUnauthorized},
+		{http.MethodGet, "/api/scenes", "wrong-token", http.StatusUnauthorized},
+		{http.MethodGet, "/api/scenes", "viewer-token", http.StatusOK},
+		{http.MethodGet, "/api/scenes/living_room", "viewer-token", http.StatusOK},
+		{http.MethodPut, "/api/scenes/living_room/movie", "viewer-token", http.StatusForbidden},
+		{http.MethodDelete, "/api/scenes/living_room/relax", "viewer-token", http.StatusForbidden},
+		{http.MethodPost, "/api/scenes/living_room/relax/activate", "viewer-token", http.StatusForbidden},
+		{http.MethodPost, "/api/scenes/living_room/relax/activate", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, sceneRequest(tt.method, tt.target, `{"lights": [{"light": "floor", "state": "on"}]}`, tt.token))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s with %q: status %d, want %d", tt.method, tt.target, tt.token, rec.Code, tt.want)
+		}
+	}
+	if len(broker.published) != 0 {
+		t.Errorf("expected no scene commands, got %v", broker.published)
+	}
+}
+
+func TestAgent_SelectSceneForDecision(t *testing.T) {
+	a, _, _ := newSceneAPITest(t)
+
+	decision := &Decision{Action: "on", Brightness: 35, ColorTemp: 2500, Reason: "dim_room"}
+	a.selectScene("living_room", decision)
+	if decision.Scene == nil || decision.Scene.Name != "relax" || decision.Brightness != 30 || decision.ColorTemp != 2400 {
+		t.Errorf("expected the relax scene's level, got %+v", decision)
+	}
+}
@@ -0,0 +1,285 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/hassbridge"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+const (
+	// rawTopicFmt is the collector's media input (automation/raw/media/{location})
+	rawTopicFmt = "automation/raw/media/%s"
+
+	// sessionTopicFmt carries session boundaries (automation/media/session/{location})
+	sessionTopicFmt = "automation/media/session/%s"
+)
+
+// Agent normalizes Sonos, Chromecast and Home Assistant media players into the
+// collector's media schema and tracks their playback sessions
+type Agent struct {
+	mqtt    mqtt.Client
+	cfg     *config.Config
+	logger  *slog.Logger
+	players map[string]string // player key → location
+
+	mu       sync.Mutex
+	last     map[string]Update // last forwarded update by player
+	sessions *SessionTracker
+}
+
+// NewAgent creates a media agent
+func NewAgent(mqttClient mqtt.Client, cfg *config.Config, logger *slog.Logger) (*Agent, error) {
+	players, err := ParsePlayerMap(cfg.MediaPlayerMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse player map: %w", err)
+	}
+
+	return &Agent{
+		mqtt:     mqttClient,
+		cfg:      cfg,
+		logger:   logger.With("component", "media-agent"),
+		players:  players,
+		last:     make(map[string]Update),
+		sessions: NewSessionTracker(cfg.MediaPauseTimeout),
+	}, nil
+}
+
+// ParsePlayerMap parses "player=location" entries. Players are
+// "sonos:<device>", "chromecast:<device>" or a Home Assistant media_player
+// entity id, e.g. "sonos:Kitchen=kitchen" or "media_player.tv=living_room".
+func ParsePlayerMap(entries []string) (map[string]string, error) {
+	players := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		player, location, ok := strings.Cut(entry, "=")
+		player, location = strings.TrimSpace(player), strings.TrimSpace(location)
+		if !ok || player == "" || location == "" {
+			return nil, fmt.Errorf("invalid player mapping %q (expected player=location)", entry)
+		}
+		if !strings.HasPrefix(player, SourceSonos+":") && !strings.HasPrefix(player, SourceChromecast+":") && !strings.HasPrefix(player, "media_player.") {
+			return nil, fmt.Errorf("invalid player mapping %q: player must be sonos:<device>, chromecast:<device> or media_player.<entity>", entry)
+		}
+		if _, dup := players[player]; dup {
+			return nil, fmt.Errorf("player %s is mapped twice", player)
+		}
+		players[player] = location
+	}
+	return players, nil
+}
+
+// Start connects to MQTT and subscribes to the configured player sources
+func (a *Agent) Start(ctx context.Context) error {
+	a.logger.Info("Starting media agent",
+		"players", len(a.players),
+		"sonos_topic", a.cfg.MediaSonosTopic,
+		"chromecast_topic", a.cfg.MediaChromecastTopic,
+		"pause_timeout", a.cfg.MediaPauseTimeout)
+
+	if err := a.mqtt.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to MQTT: %w", err)
+	}
+
+	// Only sources with mapped players are subscribed
+	if a.hasPlayers(SourceSonos + ":") {
+		topic := a.cfg.MediaSonosTopic + "/+"
+		if err := a.mqtt.Subscribe(topic, 0, a.handleDevice(SourceSonos, parseSonos)); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+		}
+	}
+	if a.hasPlayers(SourceChromecast + ":") {
+		topic := a.cfg.MediaChromecastTopic + "/+"
+		if err := a.mqtt.Subscribe(topic, 0, a.handleDevice(SourceChromecast, parseChromecast)); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+		}
+	}
+	if a.hasPlayers("media_player.") {
+		if err := a.mqtt.Subscribe(a.cfg.HassEventTopic, 0, a.handleHassEvent); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", a.cfg.HassEventTopic, err)
+		}
+	}
+	if len(a.players) == 0 {
+		a.logger.Warn("No media players mapped, nothing to normalize")
+	}
+
+	a.logger.Info("Media agent started and ready")
+
+	// Paused sessions end without a further update
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.logger.Info("Media agent stopping")
+			return nil
+		case now := <-ticker.C:
+			a.mu.Lock()
+			events := a.sessions.Expire(now)
+			a.mu.Unlock()
+			a.publishSessionEvents(events)
+		}
+	}
+}
+
+// Stop disconnects from MQTT
+func (a *Agent) Stop() error {
+	a.logger.Info("Stopping media agent")
+	a.mqtt.Disconnect()
+	return nil
+}
+
+// hasPlayers reports whether any mapped player key starts with prefix
+func (a *Agent) hasPlayers(prefix string) bool {
+	for player := range a.players {
+		if strings.HasPrefix(player, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDevice handles per-device state topics ({prefix}/{device})
+func (a *Agent) handleDevice(source string, parse func([]byte) (string, string, string, bool, error)) func(mqtt.Message) {
+	return func(msg mqtt.Message) {
+		device := msg.Topic()[strings.LastIndex(msg.Topic(), "/")+1:]
+		player := source + ":" + device
+
+		location, mapped := a.players[player]
+		if !mapped {
+			a.logger.Debug("Ignoring unmapped player", "player", player)
+			return
+		}
+
+		state, mediaType, title, ok, err := parse(msg.Payload())
+		if err != nil {
+			a.logger.Warn("Failed to parse player state", "player", player, "error", err)
+			return
+		}
+		if !ok {
+			return
+		}
+
+		a.apply(Update{
+			Player:    player,
+			Location:  location,
+			State:     state,
+			MediaType: mediaType,
+			Title:     title,
+			Source:    source,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// handleHassEvent handles state_changed events of mapped media_player entities
+func (a *Agent) handleHassEvent(msg mqtt.Message) {
+	var event hassbridge.Event
+	if err := json.Unmarshal(msg.Payload(), &event); err != nil {
+		a.logger.Warn("Failed to parse Home Assistant event", "error", err)
+		return
+	}
+	if event.EventType != "state_changed" || event.EventData.NewState == nil {
+		return
+	}
+
+	player := event.EventData.EntityID
+	location, mapped := a.players[player]
+	if !mapped {
+		return
+	}
+
+	newState := event.EventData.NewState
+	state, mediaType, title, ok := parseHomeAssistant(newState.State, newState.Attributes)
+	if !ok {
+		return
+	}
+
+	timestamp := newState.LastChanged
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	a.apply(Update{
+		Player:    player,
+		Location:  location,
+		State:     state,
+		MediaType: mediaType,
+		Title:     title,
+		Source:    SourceHomeAssistant,
+		Timestamp: timestamp,
+	})
+}
+
+// apply forwards a changed player state to the collector and publishes the
+// session events it causes
+func (a *Agent) apply(u Update) {
+	a.mu.Lock()
+	last, seen := a.last[u.Player]
+	changed := !seen || last.State != u.State || last.Title != u.Title || last.MediaType != u.MediaType
+	a.last[u.Player] = u
+	var events []SessionEvent
+	if changed {
+		events = a.sessions.Update(u)
+	}
+	a.mu.Unlock()
+
+	// Players repeat their state on every volume or position change
+	if !changed {
+		return
+	}
+
+	data := map[string]interface{}{
+		"state":  u.State,
+		"source": u.Source,
+		"player": u.Player,
+	}
+	if u.MediaType != "" {
+		data["media_type"] = u.MediaType
+	}
+	if u.Title != "" {
+		data["title"] = u.Title
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		a.logger.Error("Failed to marshal media message", "error", err)
+		return
+	}
+	topic := fmt.Sprintf(rawTopicFmt, u.Location)
+	if err := a.mqtt.Publish(topic, 0, false, payload); err != nil {
+		a.logger.Error("Failed to publish media message", "topic", topic, "error", err)
+		return
+	}
+
+	a.logger.Debug("Forwarded player state",
+		"player", u.Player,
+		"location", u.Location,
+		"state", u.State,
+		"media_type", u.MediaType)
+
+	a.publishSessionEvents(events)
+}
+
+func (a *Agent) publishSessionEvents(events []SessionEvent) {
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			a.logger.Error("Failed to marshal session event", "error", err)
+			continue
+		}
+		topic := fmt.Sprintf(sessionTopicFmt, event.Location)
+		if err := a.mqtt.Publish(topic, 0, false, payload); err != nil {
+			a.logger.Error("Failed to publish session event", "topic", topic, "error", err)
+			continue
+		}
+		a.logger.Info("Media session "+event.Event,
+			"player", event.Player,
+			"location", event.Location,
+			"playing_seconds", event.PlayingSeconds)
+	}
+}
@@ -0,0 +1,155 @@
+package media
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Normalized player states, as stored in sensor:media:{location}
+const (
+	StatePlaying = "playing"
+	StatePaused  = "paused"
+	StateStopped = "stopped"
+)
+
+// Sources of player updates
+const (
+	SourceSonos         = "sonos"
+	SourceChromecast    = "chromecast"
+	SourceHomeAssistant = "home_assistant"
+)
+
+// Update is a player state normalized to the collector's media schema
+type Update struct {
+	Player    string // player key, e.g. "sonos:Kitchen" or "media_player.living_room_tv"
+	Location  string
+	State     string // playing, paused or stopped
+	MediaType string // tv, music, podcast or "" when unknown
+	Title     string
+	Source    string
+	Timestamp time.Time
+}
+
+// sonosState is the part of a sonos2mqtt device state the agent uses
+type sonosState struct {
+	TransportState string `json:"transportState"`
+	CurrentTrack   struct {
+		Title  string `json:"title"`
+		Artist string `json:"artist"`
+		URI    string `json:"trackUri"`
+	} `json:"currentTrack"`
+}
+
+// parseSonos normalizes a sonos2mqtt state message. ok is false for
+// transitional states.
+func parseSonos(payload []byte) (state, mediaType, title string, ok bool, err error) {
+	var s sonosState
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return "", "", "", false, fmt.Errorf("failed to parse Sonos state: %w", err)
+	}
+
+	switch s.TransportState {
+	case "PLAYING":
+		state = StatePlaying
+	case "PAUSED_PLAYBACK":
+		state = StatePaused
+	case "STOPPED":
+		state = StateStopped
+	default:
+		// TRANSITIONING, or a message without transport state (volume changes)
+		return "", "", "", false, nil
+	}
+
+	// Home theater inputs play the TV's sound
+	mediaType = "music"
+	if strings.HasPrefix(s.CurrentTrack.URI, "x-sonos-htastream:") {
+		mediaType = "tv"
+	}
+
+	title = s.CurrentTrack.Title
+	if title != "" && s.CurrentTrack.Artist != "" {
+		title = s.CurrentTrack.Artist + " - " + title
+	}
+	return state, mediaType, title, true, nil
+}
+
+// castStatus is the part of a Chromecast media status the agent uses
+// (pychromecast MediaStatus field names)
+type castStatus struct {
+	PlayerState string `json:"player_state"`
+	ContentType string `json:"content_type"`
+	Title       string `json:"title"`
+	AppName     string `json:"app_name"`
+}
+
+// parseChromecast normalizes a Chromecast media status message. ok is false
+// for buffering.
+func parseChromecast(payload []byte) (state, mediaType, title string, ok bool, err error) {
+	var s castStatus
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return "", "", "", false, fmt.Errorf("failed to parse Chromecast status: %w", err)
+	}
+
+	switch s.PlayerState {
+	case "PLAYING":
+		state = StatePlaying
+	case "PAUSED":
+		state = StatePaused
+	case "IDLE", "UNKNOWN", "":
+		state = StateStopped
+	default:
+		// BUFFERING
+		return "", "", "", false, nil
+	}
+
+	switch {
+	case strings.HasPrefix(s.ContentType, "video/"):
+		mediaType = "tv"
+	case strings.HasPrefix(s.ContentType, "audio/"):
+		mediaType = "music"
+	}
+
+	title = s.Title
+	if title == "" {
+		title = s.AppName
+	}
+	return state, mediaType, title, true, nil
+}
+
+// parseHomeAssistant normalizes a media_player entity state. ok is false for
+// unavailable and buffering players.
+func parseHomeAssistant(state string, attributes map[string]interface{}) (normalized, mediaType, title string, ok bool) {
+	switch state {
+	case "playing":
+		normalized = StatePlaying
+	case "paused":
+		normalized = StatePaused
+	case "idle", "off", "standby", "on":
+		// "on" is a powered player with nothing playing
+		normalized = StateStopped
+	default:
+		// buffering, unavailable, unknown
+		return "", "", "", false
+	}
+
+	contentType, _ := attributes["media_content_type"].(string)
+	switch contentType {
+	case "music", "playlist", "album", "track":
+		mediaType = "music"
+	case "podcast":
+		mediaType = "podcast"
+	case "tvshow", "movie", "video", "episode", "channel":
+		mediaType = "tv"
+	}
+
+	title, _ = attributes["media_title"].(string)
+	if artist, _ := attributes["media_artist"].(string); artist != "" && title != "" {
+		title = artist + " - " + title
+	}
+	if title == "" {
+		title, _ = attributes["app_name"].(string)
+	}
+	return normalized, mediaType, title, true
+}
@@ -0,0 +1,116 @@
+package media
+
+import (
+	"testing"
+)
+
+func TestParsePlayerMap(t *testing.T) {
+	players, err := ParsePlayerMap([]string{
+		"sonos:Kitchen=kitchen",
+		"chromecast:Living Room TV=living_room",
+		"media_player.bedroom_speaker=bedroom",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if players["chromecast:Living Room TV"] != "living_room" || len(players) != 3 {
+		t.Errorf("unexpected players: %v", players)
+	}
+
+	invalid := [][]string{
+		{"sonos:Kitchen"},
+		{"sonos:Kitchen="},
+		{"kitchen_speaker=kitchen"},
+		{"sonos:Kitchen=kitchen", "sonos:Kitchen=dining_room"},
+	}
+	for _, entries := range invalid {
+		if _, err := ParsePlayerMap(entries); err == nil {
+			t.Errorf("expected error for %v", entries)
+		}
+	}
+}
+
+func TestParseSonos(t *testing.T) {
+	tests := []struct {
+		payload       string
+		wantState     string
+		wantMediaType string
+		wantTitle     string
+		wantOK        bool
+	}{
+		{`{"transportState":"PLAYING","currentTrack":{"title":"So What","artist":"Miles Davis","trackUri":"x-sonos-spotify:abc"}}`, StatePlaying, "music", "Miles Davis - So What", true},
+		{`{"transportState":"PLAYING","currentTrack":{"trackUri":"x-sonos-htastream:RINCON_1:spdif"}}`, StatePlaying, "tv", "", true},
+		{`{"transportState":"PAUSED_PLAYBACK"}`, StatePaused, "music", "", true},
+		{`{"transportState":"STOPPED"}`, StateStopped, "music", "", true},
+		{`{"transportState":"TRANSITIONING"}`, "", "", "", false},
+		{`{"volume":{"Master":20}}`, "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		state, mediaType, title, ok, err := parseSonos([]byte(tt.payload))
+		if err != nil {
+			t.Fatalf("parseSonos(%s) failed: %v", tt.payload, err)
+		}
+		if state != tt.wantState || mediaType != tt.wantMediaType || title != tt.wantTitle || ok != tt.wantOK {
+			t.Errorf("parseSonos(%s) = %q, %q, %q, %v; want %q, %q, %q, %v",
+				tt.payload, state, mediaType, title, ok, tt.wantState, tt.wantMediaType, tt.wantTitle, tt.wantOK)
+		}
+	}
+
+	if _, _, _, _, err := parseSonos([]byte("not json")); err == nil {
+		t.Error("expected error for invalid payload")
+	}
+}
+
+func TestParseChromecast(t *testing.T) {
+	tests := []struct {
+		payload       string
+		wantState     string
+		wantMediaType string
+		wantTitle     string
+		wantOK        bool
+	}{
+		{`{"player_state":"PLAYING","content_type":"video/mp4","title":"Planet Earth"}`, StatePlaying, "tv", "Planet Earth", true},
+		{`{"player_state":"PAUSED","content_type":"audio/mpeg","app_name":"Spotify"}`, StatePaused, "music", "Spotify", true},
+		{`{"player_state":"IDLE"}`, StateStopped, "", "", true},
+		{`{"player_state":"BUFFERING"}`, "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		state, mediaType, title, ok, err := parseChromecast([]byte(tt.payload))
+		if err != nil {
+			t.Fatalf("parseChromecast(%s) failed: %v", tt.payload, err)
+		}
+		if state != tt.wantState || mediaType != tt.wantMediaType || title != tt.wantTitle || ok != tt.wantOK {
+			t.Errorf("parseChromecast(%s) = %q, %q, %q, %v; want %q, %q, %q, %v",
+				tt.payload, state, mediaType, title, ok, tt.wantState, tt.wantMediaType, tt.wantTitle, tt.wantOK)
+		}
+	}
+}
+
+func TestParseHomeAssistant(t *testing.T) {
+	tests := []struct {
+		state         string
+		attributes    map[string]interface{}
+		wantState     string
+		wantMediaType string
+		wantTitle     string
+		wantOK        bool
+	}{
+		{"playing", map[string]interface{}{"media_content_type": "tvshow", "media_title": "Episode 1"}, StatePlaying, "tv", "Episode 1", true},
+		{"playing", map[string]interface{}{"media_content_type": "music", "media_title": "Blue", "media_artist": "Joni Mitchell"}, StatePlaying, "music", "Joni Mitchell - Blue", true},
+		{"paused", map[string]interface{}{"media_content_type": "podcast"}, StatePaused, "podcast", "", true},
+		{"idle", map[string]interface{}{"app_name": "Netflix"}, StateStopped, "", "Netflix", true},
+		{"off", nil, StateStopped, "", "", true},
+		{"buffering", nil, "", "", "", false},
+		{"unavailable", nil, "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		state, mediaType, title, ok := parseHomeAssistant(tt.state, tt.attributes)
+		if state != tt.wantState || mediaType != tt.wantMediaType || title != tt.wantTitle || ok != tt.wantOK {
+			t.Errorf("parseHomeAssistant(%s) = %q, %q, %q, %v; want %q, %q, %q, %v",
+				tt.state, state, mediaType, title, ok, tt.wantState, tt.wantMediaType, tt.wantTitle, tt.wantOK)
+		}
+	}
+}
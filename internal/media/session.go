@@ -0,0 +1,151 @@
+package media
+
+import (
+	"fmt"
+	"time"
+)
+
+// Session event types
+const (
+	EventStarted = "started"
+	EventPaused  = "paused"
+	EventResumed = "resumed"
+	EventEnded   = "ended"
+)
+
+// Session is one stretch of playback on a player, from the first play until
+// it stops or stays paused longer than the pause timeout
+type Session struct {
+	ID        string    `json:"session_id"`
+	Player    string    `json:"player"`
+	Location  string    `json:"location"`
+	Source    string    `json:"source"`
+	MediaType string    `json:"media_type,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+
+	playing     time.Duration
+	playingFrom time.Time // zero while paused
+	pausedAt    time.Time // zero while playing
+}
+
+// SessionEvent is published to automation/media/session/{location}
+type SessionEvent struct {
+	Event string `json:"event"`
+	Session
+	EndedAt        *time.Time `json:"ended_at,omitempty"`
+	PlayingSeconds float64    `json:"playing_seconds"`
+	Timestamp      time.Time  `json:"timestamp"`
+}
+
+// SessionTracker derives play/pause/stop session boundaries from player updates
+type SessionTracker struct {
+	pauseTimeout time.Duration
+	sessions     map[string]*Session // by player
+}
+
+// NewSessionTracker creates a tracker ending sessions paused longer than pauseTimeout
+func NewSessionTracker(pauseTimeout time.Duration) *SessionTracker {
+	return &SessionTracker{
+		pauseTimeout: pauseTimeout,
+		sessions:     make(map[string]*Session),
+	}
+}
+
+// Update applies a player update and returns the session events it causes
+func (t *SessionTracker) Update(u Update) []SessionEvent {
+	// A session paused past the timeout ended before this update
+	events := t.expirePlayer(u.Player, u.Timestamp)
+
+	s := t.sessions[u.Player]
+	switch u.State {
+	case StatePlaying:
+		if s == nil {
+			s = &Session{
+				ID:          fmt.Sprintf("%s@%d", u.Player, u.Timestamp.UnixMilli()),
+				Player:      u.Player,
+				Location:    u.Location,
+				Source:      u.Source,
+				StartedAt:   u.Timestamp,
+				playingFrom: u.Timestamp,
+			}
+			t.sessions[u.Player] = s
+			s.describe(u)
+			return append(events, s.event(EventStarted, u.Timestamp))
+		}
+		s.describe(u)
+		if !s.pausedAt.IsZero() {
+			s.pausedAt = time.Time{}
+			s.playingFrom = u.Timestamp
+			return append(events, s.event(EventResumed, u.Timestamp))
+		}
+
+	case StatePaused:
+		if s != nil && s.pausedAt.IsZero() {
+			s.playing += u.Timestamp.Sub(s.playingFrom)
+			s.playingFrom = time.Time{}
+			s.pausedAt = u.Timestamp
+			return append(events, s.event(EventPaused, u.Timestamp))
+		}
+
+	case StateStopped:
+		if s != nil {
+			return append(events, t.end(s, u.Timestamp))
+		}
+	}
+	return events
+}
+
+// Expire ends the sessions paused longer than the pause timeout at now
+func (t *SessionTracker) Expire(now time.Time) []SessionEvent {
+	var events []SessionEvent
+	for player := range t.sessions {
+		events = append(events, t.expirePlayer(player, now)...)
+	}
+	return events
+}
+
+func (t *SessionTracker) expirePlayer(player string, now time.Time) []SessionEvent {
+	s := t.sessions[player]
+	if s == nil || s.pausedAt.IsZero() || t.pauseTimeout <= 0 || now.Sub(s.pausedAt) < t.pauseTimeout {
+		return nil
+	}
+	// The session ended when playback paused
+	return []SessionEvent{t.end(s, s.pausedAt)}
+}
+
+// end closes a session at endedAt
+func (t *SessionTracker) end(s *Session, endedAt time.Time) SessionEvent {
+	if s.pausedAt.IsZero() {
+		s.playing += endedAt.Sub(s.playingFrom)
+		s.playingFrom = time.Time{}
+	}
+	delete(t.sessions, s.Player)
+
+	event := s.event(EventEnded, endedAt)
+	event.EndedAt = &endedAt
+	return event
+}
+
+// describe keeps the latest known media type and title
+func (s *Session) describe(u Update) {
+	if u.MediaType != "" {
+		s.MediaType = u.MediaType
+	}
+	if u.Title != "" {
+		s.Title = u.Title
+	}
+}
+
+func (s *Session) event(kind string, at time.Time) SessionEvent {
+	playing := s.playing
+	if !s.playingFrom.IsZero() {
+		playing += at.Sub(s.playingFrom)
+	}
+	return SessionEvent{
+		Event:          kind,
+		Session:        *s,
+		PlayingSeconds: playing.Seconds(),
+		Timestamp:      at,
+	}
+}
@@ -0,0 +1,64 @@
+package media
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionTracker(t *testing.T) {
+	start := time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	update := func(state string, minutes int) Update {
+		return Update{Player: "sonos:Kitchen", Location: "kitchen", State: state, MediaType: "music", Source: SourceSonos, Timestamp: at(minutes)}
+	}
+
+	tracker := NewSessionTracker(15 * time.Minute)
+
+	steps := []struct {
+		update      Update
+		wantEvents  []string
+		wantPlaying float64 // seconds in the last event
+	}{
+		{update(StatePlaying, 0), []string{EventStarted}, 0},
+		{update(StatePaused, 10), []string{EventPaused}, 600},
+		{update(StatePlaying, 15), []string{EventResumed}, 600},
+		{update(StateStopped, 25), []string{EventEnded}, 1200},
+		// Stopped without a session is not an event
+		{update(StateStopped, 26), nil, 0},
+		{update(StatePlaying, 30), []string{EventStarted}, 0},
+		{update(StatePaused, 40), []string{EventPaused}, 600},
+		// Paused past the timeout: the old session ended at the pause
+		{update(StatePlaying, 60), []string{EventEnded, EventStarted}, 0},
+	}
+
+	for i, step := range steps {
+		events := tracker.Update(step.update)
+		if len(events) != len(step.wantEvents) {
+			t.Fatalf("step %d: got %d events, want %v", i, len(events), step.wantEvents)
+		}
+		for j, event := range events {
+			if event.Event != step.wantEvents[j] {
+				t.Errorf("step %d: event %d = %s, want %s", i, j, event.Event, step.wantEvents[j])
+			}
+		}
+		if len(events) > 0 && events[len(events)-1].PlayingSeconds != step.wantPlaying {
+			t.Errorf("step %d: playing %v s, want %v", i, events[len(events)-1].PlayingSeconds, step.wantPlaying)
+		}
+	}
+
+	// The expired session ended when it paused
+	events := tracker.Update(update(StatePaused, 70))
+	if len(events) != 1 || events[0].Event != EventPaused {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if events := tracker.Expire(at(80)); len(events) != 0 {
+		t.Errorf("session expired before the timeout: %+v", events)
+	}
+	events = tracker.Expire(at(85))
+	if len(events) != 1 || events[0].Event != EventEnded || !events[0].EndedAt.Equal(at(70)) {
+		t.Fatalf("unexpected expiry: %+v", events)
+	}
+	if events[0].StartedAt != at(60) || events[0].PlayingSeconds != 600 {
+		t.Errorf("unexpected ended session: started %v, playing %v s", events[0].StartedAt, events[0].PlayingSeconds)
+	}
+}
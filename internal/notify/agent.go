@@ -0,0 +1,295 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+const (
+	reportTopic     = "automation/behavior/report/+"
+	predictionTopic = "automation/behavior/prediction"
+
+	// reportMaxAge skips retained reports replayed on (re)subscribe
+	reportMaxAge = 6 * time.Hour
+
+	// heldCheckInterval is how often held messages are re-checked after quiet hours
+	heldCheckInterval = time.Minute
+)
+
+// Agent turns behavior anomalies and predictions into push notifications,
+// routed per event type, held back during quiet hours and rate limited per
+// channel
+type Agent struct {
+	mqtt     mqtt.Client
+	cfg      *config.Config
+	logger   *slog.Logger
+	channels map[string]Channel
+	routes   map[string][]string
+	quiet    quietHours
+	limiter  *rateLimiter
+	now      func() time.Time
+
+	mu          sync.Mutex
+	held        []Message
+	seenReports map[string]bool
+}
+
+// NewAgent creates a notification agent from the Pushover/Telegram settings in cfg
+func NewAgent(mqttClient mqtt.Client, cfg *config.Config, logger *slog.Logger) (*Agent, error) {
+	var channels []Channel
+	if cfg.PushoverAppToken != "" && cfg.PushoverUserKey != "" {
+		channels = append(channels, NewPushoverChannel(cfg.PushoverAppToken, cfg.PushoverUserKey))
+	}
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID != "" {
+		channels = append(channels, NewTelegramChannel(cfg.TelegramBotToken, cfg.TelegramChatID))
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no notification channel configured (set Pushover or Telegram credentials)")
+	}
+
+	return newAgent(mqttClient, cfg, channels, logger)
+}
+
+func newAgent(mqttClient mqtt.Client, cfg *config.Config, channels []Channel, logger *slog.Logger) (*Agent, error) {
+	if cfg.NotifyQuietStartHour < 0 || cfg.NotifyQuietStartHour > 23 || cfg.NotifyQuietEndHour < 0 || cfg.NotifyQuietEndHour > 23 {
+		return nil, fmt.Errorf("quiet hours must be between 0 and 23")
+	}
+
+	byName := make(map[string]Channel, len(channels))
+	names := make([]string, 0, len(channels))
+	for _, c := range channels {
+		byName[c.Name()] = c
+		names = append(names, c.Name())
+	}
+
+	routes, err := ParseRoutes(cfg.NotifyRoutes, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notification routes: %w", err)
+	}
+
+	return &Agent{
+		mqtt:        mqttClient,
+		cfg:         cfg,
+		logger:      logger.With("component", "notify-agent"),
+		channels:    byName,
+		routes:      routes,
+		quiet:       quietHours{start: cfg.NotifyQuietStartHour, end: cfg.NotifyQuietEndHour},
+		limiter:     newRateLimiter(cfg.NotifyRateLimitPerHour),
+		now:         time.Now,
+		seenReports: make(map[string]bool),
+	}, nil
+}
+
+// Start connects to MQTT and subscribes to reports and predictions
+func (a *Agent) Start(ctx context.Context) error {
+	a.logger.Info("Starting notification agent",
+		"channels", len(a.channels),
+		"quiet_start", a.cfg.NotifyQuietStartHour,
+		"quiet_end", a.cfg.NotifyQuietEndHour,
+		"rate_limit_per_hour", a.cfg.NotifyRateLimitPerHour)
+
+	if err := a.mqtt.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to MQTT: %w", err)
+	}
+
+	if err := a.mqtt.Subscribe(reportTopic, 0, a.handleReport); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", reportTopic, err)
+	}
+	if err := a.mqtt.Subscribe(predictionTopic, 0, a.handlePrediction); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", predictionTopic, err)
+	}
+
+	go a.releaseHeld(ctx)
+	return nil
+}
+
+// Stop disconnects from MQTT
+func (a *Agent) Stop() error {
+	a.logger.Info("Stopping notification agent")
+	a.mqtt.Disconnect()
+	return nil
+}
+
+// reportMessage is the part of the observer's behavior report the agent uses
+type reportMessage struct {
+	Period      string    `json:"period"`
+	Start       time.Time `json:"start"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Anomalies   []struct {
+		Kind        string `json:"kind"`
+		Subject     string `json:"subject"`
+		Description string `json:"description"`
+	} `json:"anomalies"`
+}
+
+func (a *Agent) handleReport(msg mqtt.Message) {
+	var report reportMessage
+	if err := json.Unmarshal(msg.Payload(), &report); err != nil {
+		a.logger.Warn("Invalid report payload", "topic", msg.Topic(), "error", err)
+		return
+	}
+
+	if a.now().Sub(report.GeneratedAt) > reportMaxAge {
+		a.logger.Debug("Skipping stale report", "period", report.Period, "generated_at", report.GeneratedAt)
+		return
+	}
+
+	key := report.Period + "/" + report.Start.Format(time.RFC3339)
+	a.mu.Lock()
+	seen := a.seenReports[key]
+	a.seenReports[key] = true
+	a.mu.Unlock()
+	if seen || len(report.Anomalies) == 0 {
+		return
+	}
+
+	lines := make([]string, 0, len(report.Anomalies))
+	for _, anomaly := range report.Anomalies {
+		lines = append(lines, "• "+anomaly.Description)
+	}
+	title := fmt.Sprintf("%d unusual %s in %s report", len(report.Anomalies), plural(len(report.Anomalies), "pattern", "patterns"), report.Period)
+
+	a.dispatch(Message{
+		Event: RouteAnomaly,
+		Title: title,
+		Body:  strings.Join(lines, "\n"),
+	})
+}
+
+// predictionMessage is the part of a prediction the agent uses
+type predictionMessage struct {
+	CurrentLocation   string    `json:"current_location"`
+	NextLocation      string    `json:"next_location"`
+	ExpectedInMinutes float64   `json:"expected_in_minutes"`
+	Confidence        float64   `json:"confidence"`
+	PatternName       string    `json:"pattern_name"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+func (a *Agent) handlePrediction(msg mqtt.Message) {
+	var p predictionMessage
+	if _, err := events.Unmarshal(msg.Payload(), &p); err != nil {
+		a.logger.Warn("Invalid prediction payload", "error", err)
+		return
+	}
+	if p.NextLocation == "" {
+		return
+	}
+
+	body := fmt.Sprintf("Expected in %s within about %.0f min (%.0f%% confidence)",
+		p.NextLocation, p.ExpectedInMinutes, p.Confidence*100)
+	if p.PatternName != "" {
+		body += ", based on " + p.PatternName
+	}
+
+	a.dispatch(Message{
+		Event:     RoutePrediction,
+		Title:     fmt.Sprintf("Next: %s → %s", p.CurrentLocation, p.NextLocation),
+		Body:      body,
+		ExpiresAt: p.ExpiresAt,
+	})
+}
+
+// dispatch sends a message now, or holds it until quiet hours end
+func (a *Agent) dispatch(msg Message) {
+	if len(a.routes[msg.Event]) == 0 {
+		return
+	}
+
+	if a.quiet.contains(a.now()) {
+		a.mu.Lock()
+		a.held = append(a.held, msg)
+		a.mu.Unlock()
+		a.logger.Debug("Holding notification during quiet hours", "event", msg.Event, "title", msg.Title)
+		return
+	}
+
+	a.send(context.Background(), msg)
+}
+
+func (a *Agent) send(ctx context.Context, msg Message) {
+	for _, name := range a.routes[msg.Event] {
+		if !a.limiter.allow(name, a.now()) {
+			a.logger.Warn("Notification rate limit reached, dropping message",
+				"channel", name,
+				"event", msg.Event,
+				"title", msg.Title)
+			continue
+		}
+
+		if err := a.channels[name].Send(ctx, msg); err != nil {
+			a.logger.Error("Failed to send notification", "channel", name, "event", msg.Event, "error", err)
+			continue
+		}
+		a.logger.Info("Sent notification", "channel", name, "event", msg.Event, "title", msg.Title)
+	}
+}
+
+func (a *Agent) releaseHeld(ctx context.Context) {
+	ticker := time.NewTicker(heldCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.flushHeld(ctx)
+		}
+	}
+}
+
+// flushHeld sends held messages once quiet hours are over, dropping expired
+// ones and collapsing several predictions into the latest
+func (a *Agent) flushHeld(ctx context.Context) {
+	now := a.now()
+	if a.quiet.contains(now) {
+		return
+	}
+
+	a.mu.Lock()
+	held := a.held
+	a.held = nil
+	a.mu.Unlock()
+
+	var latestPrediction *Message
+	pending := make([]Message, 0, len(held))
+	for i := range held {
+		msg := held[i]
+		if !msg.ExpiresAt.IsZero() && now.After(msg.ExpiresAt) {
+			continue
+		}
+		if msg.Event == RoutePrediction {
+			latestPrediction = &held[i]
+			continue
+		}
+		pending = append(pending, msg)
+	}
+	if latestPrediction != nil {
+		pending = append(pending, *latestPrediction)
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	a.logger.Info("Quiet hours over, sending held notifications", "count", len(pending), "dropped", len(held)-len(pending))
+	for _, msg := range pending {
+		a.send(ctx, msg)
+	}
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
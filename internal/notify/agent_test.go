@@ -0,0 +1,234 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+type fakeChannel struct {
+	name string
+	sent []Message
+}
+
+func (c *fakeChannel) Name() string { return c.name }
+
+func (c *fakeChannel) Send(ctx context.Context, msg Message) error {
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+type fakeMessage struct {
+	topic   string
+	payload []byte
+}
+
+func (m *fakeMessage) Topic() string   { return m.topic }
+func (m *fakeMessage) Payload() []byte { return m.payload }
+func (m *fakeMessage) Ack()            {}
+
+func newTestAgent(t *testing.T, cfg *config.Config, now time.Time) (*Agent, *fakeChannel, *fakeChannel) {
+	t.Helper()
+	pushover := &fakeChannel{name: ChannelPushover}
+	telegram := &fakeChannel{name: ChannelTelegram}
+	a, err := newAgent(nil, cfg, []Channel{pushover, telegram}, testLogger())
+	if err != nil {
+		t.Fatalf("newAgent: %v", err)
+	}
+	a.now = func() time.Time { return now }
+	return a, pushover, telegram
+}
+
+func TestParseRoutes(t *testing.T) {
+	available := []string{ChannelPushover, ChannelTelegram}
+
+	routes, err := ParseRoutes(nil, available)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes[RouteAnomaly]) != 2 || len(routes[RoutePrediction]) != 2 {
+		t.Errorf("default routes = %v, want every event on both channels", routes)
+	}
+
+	routes, err = ParseRoutes([]string{"anomaly=pushover", "anomaly = telegram", "prediction=telegram"}, available)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(routes[RouteAnomaly], ",") != "pushover,telegram" || strings.Join(routes[RoutePrediction], ",") != "telegram" {
+		t.Errorf("unexpected routes %v", routes)
+	}
+
+	for _, bad := range []string{"anomaly", "weather=pushover", "anomaly=email", "=pushover"} {
+		if _, err := ParseRoutes([]string{bad}, available); err == nil {
+			t.Errorf("ParseRoutes(%q): expected error", bad)
+		}
+	}
+	if _, err := ParseRoutes([]string{"anomaly=telegram"}, []string{ChannelPushover}); err == nil {
+		t.Error("expected error for unconfigured channel")
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 1, 1, hour, 30, 0, 0, time.UTC) }
+
+	overnight := quietHours{start: 22, end: 7}
+	for hour, want := range map[int]bool{21: false, 22: true, 23: true, 0: true, 6: true, 7: false, 12: false} {
+		if got := overnight.contains(at(hour)); got != want {
+			t.Errorf("22-7 contains %d:30 = %v, want %v", hour, got, want)
+		}
+	}
+
+	daytime := quietHours{start: 13, end: 15}
+	if !daytime.contains(at(13)) || daytime.contains(at(15)) || daytime.contains(at(12)) {
+		t.Error("13-15 window wrong")
+	}
+
+	if (quietHours{start: 5, end: 5}).contains(at(5)) {
+		t.Error("equal start and end should disable quiet hours")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	r := newRateLimiter(2)
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if !r.allow("pushover", start) || !r.allow("pushover", start.Add(time.Minute)) {
+		t.Fatal("first two messages should be allowed")
+	}
+	if r.allow("pushover", start.Add(2*time.Minute)) {
+		t.Error("third message within the hour should be limited")
+	}
+	if !r.allow("telegram", start.Add(2*time.Minute)) {
+		t.Error("limits are per channel")
+	}
+	if !r.allow("pushover", start.Add(61*time.Minute)) {
+		t.Error("first message should have left the window")
+	}
+
+	if unlimited := newRateLimiter(0); !unlimited.allow("pushover", start) {
+		t.Error("zero limit should be unlimited")
+	}
+}
+
+func TestHandleReport(t *testing.T) {
+	now := time.Date(2025, 3, 4, 7, 5, 0, 0, time.UTC)
+	cfg := config.NewConfig()
+	cfg.NotifyRoutes = []string{"anomaly=pushover"}
+	a, pushover, telegram := newTestAgent(t, cfg, now)
+
+	report := map[string]interface{}{
+		"period":       "daily",
+		"start":        now.Add(-31 * time.Hour),
+		"generated_at": now.Add(-5 * time.Minute),
+		"anomalies": []map[string]interface{}{
+			{"kind": "room_time", "subject": "study", "description": "Much more time in study than usual"},
+			{"kind": "short_sleep", "subject": "sleep", "description": "Slept 5h, usually 7h"},
+		},
+	}
+	payload, _ := json.Marshal(report)
+
+	a.handleReport(&fakeMessage{topic: "automation/behavior/report/daily", payload: payload})
+	a.handleReport(&fakeMessage{topic: "automation/behavior/report/daily", payload: payload})
+
+	if len(pushover.sent) != 1 {
+		t.Fatalf("pushover got %d messages, want 1 (duplicates skipped)", len(pushover.sent))
+	}
+	if len(telegram.sent) != 0 {
+		t.Errorf("telegram is not routed anomalies, got %d messages", len(telegram.sent))
+	}
+	msg := pushover.sent[0]
+	if msg.Title != "2 unusual patterns in daily report" || !strings.Contains(msg.Body, "Slept 5h") {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	// Retained reports from long ago are not re-sent after a restart
+	report["generated_at"] = now.Add(-24 * time.Hour)
+	report["start"] = now.Add(-55 * time.Hour)
+	payload, _ = json.Marshal(report)
+	a.handleReport(&fakeMessage{topic: "automation/behavior/report/daily", payload: payload})
+	if len(pushover.sent) != 1 {
+		t.Errorf("stale report was sent")
+	}
+}
+
+func TestQuietHoursHoldMessages(t *testing.T) {
+	now := time.Date(2025, 3, 4, 23, 0, 0, 0, time.UTC)
+	cfg := config.NewConfig()
+	cfg.NotifyQuietStartHour = 22
+	cfg.NotifyQuietEndHour = 7
+	a, pushover, _ := newTestAgent(t, cfg, now)
+
+	prediction := func(next string, expires time.Time) []byte {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"current_location":    "bedroom",
+			"next_location":       next,
+			"expected_in_minutes": 5,
+			"confidence":          0.8,
+			"expires_at":          expires,
+		})
+		return payload
+	}
+
+	a.dispatch(Message{Event: RouteAnomaly, Title: "anomaly"})
+	a.handlePrediction(&fakeMessage{payload: prediction("kitchen", now.Add(30*time.Minute))})
+	a.handlePrediction(&fakeMessage{payload: prediction("bathroom", now.Add(9*time.Hour))})
+	a.handlePrediction(&fakeMessage{payload: prediction("study", now.Add(9*time.Hour))})
+
+	a.flushHeld(context.Background())
+	if len(pushover.sent) != 0 {
+		t.Fatalf("sent %d messages during quiet hours", len(pushover.sent))
+	}
+
+	a.now = func() time.Time { return time.Date(2025, 3, 5, 7, 1, 0, 0, time.UTC) }
+	a.flushHeld(context.Background())
+
+	if len(pushover.sent) != 2 {
+		t.Fatalf("sent %d held messages, want anomaly and latest prediction", len(pushover.sent))
+	}
+	if pushover.sent[0].Title != "anomaly" || pushover.sent[1].Title != "Next: bedroom → study" {
+		t.Errorf("unexpected held messages %+v", pushover.sent)
+	}
+}
+
+func TestTelegramChannel(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendMessage" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	c := NewTelegramChannel("TOKEN", "42")
+	c.endpoint = server.URL + "/botTOKEN/sendMessage"
+	if err := c.Send(context.Background(), Message{Title: "Title", Body: "Body"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got["chat_id"] != "42" || got["text"] != "Title\nBody" {
+		t.Errorf("unexpected request %v", got)
+	}
+}
+
+func TestPushoverChannel_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("token") != "app" || r.Form.Get("user") != "user" || r.Form.Get("message") != "Body" {
+			t.Errorf("unexpected form %v", r.Form)
+		}
+		http.Error(w, `{"status":0}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	c := NewPushoverChannel("app", "user")
+	c.endpoint = server.URL
+	if err := c.Send(context.Background(), Message{Title: "Title", Body: "Body"}); err == nil {
+		t.Error("expected error for 400 response")
+	}
+}
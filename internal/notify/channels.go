@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Channel names used in routes
+const (
+	ChannelPushover = "pushover"
+	ChannelTelegram = "telegram"
+)
+
+// Message is a human-readable notification
+type Message struct {
+	Event     string    // event type the message was routed by ("anomaly", "prediction")
+	Title     string    // short summary
+	Body      string    // message text
+	ExpiresAt time.Time // held messages past this are dropped (zero = never)
+}
+
+// Channel delivers messages to a push service
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+const channelTimeout = 10 * time.Second
+
+// PushoverChannel sends messages through the Pushover API
+type PushoverChannel struct {
+	appToken string
+	userKey  string
+	endpoint string
+	client   *http.Client
+}
+
+// NewPushoverChannel creates a Pushover channel for an application token and
+// user (or group) key
+func NewPushoverChannel(appToken, userKey string) *PushoverChannel {
+	return &PushoverChannel{
+		appToken: appToken,
+		userKey:  userKey,
+		endpoint: "https://api.pushover.net/1/messages.json",
+		client:   &http.Client{Timeout: channelTimeout},
+	}
+}
+
+func (c *PushoverChannel) Name() string { return ChannelPushover }
+
+func (c *PushoverChannel) Send(ctx context.Context, msg Message) error {
+	form := url.Values{
+		"token":   {c.appToken},
+		"user":    {c.userKey},
+		"title":   {msg.Title},
+		"message": {msg.Body},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return doRequest(c.client, req)
+}
+
+// TelegramChannel sends messages through a Telegram bot
+type TelegramChannel struct {
+	chatID   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewTelegramChannel creates a Telegram channel posting to chatID as the bot
+// identified by botToken
+func NewTelegramChannel(botToken, chatID string) *TelegramChannel {
+	return &TelegramChannel{
+		chatID:   chatID,
+		endpoint: fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", botToken),
+		client:   &http.Client{Timeout: channelTimeout},
+	}
+}
+
+func (c *TelegramChannel) Name() string { return ChannelTelegram }
+
+func (c *TelegramChannel) Send(ctx context.Context, msg Message) error {
+	text := msg.Body
+	if msg.Title != "" {
+		text = msg.Title + "\n" + msg.Body
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"chat_id": c.chatID,
+		"text":    text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doRequest(c.client, req)
+}
+
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		// Errors include the URL, which carries the Telegram bot token
+		return fmt.Errorf("failed to send request to %s", req.URL.Host)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
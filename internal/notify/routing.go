@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types the notification agent routes
+const (
+	RouteAnomaly    = "anomaly"
+	RoutePrediction = "prediction"
+)
+
+var routeEvents = map[string]bool{RouteAnomaly: true, RoutePrediction: true}
+
+// ParseRoutes parses event=channel entries (e.g. "anomaly=pushover",
+// "prediction=telegram") into channel names per event type. An event may be
+// listed several times to reach several channels. With no entries every event
+// goes to every available channel.
+func ParseRoutes(entries []string, available []string) (map[string][]string, error) {
+	routes := make(map[string][]string)
+	if len(entries) == 0 {
+		for event := range routeEvents {
+			routes[event] = append([]string(nil), available...)
+		}
+		return routes, nil
+	}
+
+	isAvailable := make(map[string]bool, len(available))
+	for _, name := range available {
+		isAvailable[name] = true
+	}
+
+	for _, entry := range entries {
+		event, channel, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		event, channel = strings.TrimSpace(event), strings.TrimSpace(channel)
+		if !ok || event == "" || channel == "" {
+			return nil, fmt.Errorf("invalid route %q, expected event=channel", entry)
+		}
+		if !routeEvents[event] {
+			return nil, fmt.Errorf("route %q: unknown event %q", entry, event)
+		}
+		if !isAvailable[channel] {
+			return nil, fmt.Errorf("route %q: channel %q is not configured", entry, channel)
+		}
+		routes[event] = append(routes[event], channel)
+	}
+	return routes, nil
+}
+
+// quietHours is a daily local-time window [start, end) in which messages are
+// held back; start == end disables it
+type quietHours struct {
+	start, end int
+}
+
+func (q quietHours) contains(t time.Time) bool {
+	if q.start == q.end {
+		return false
+	}
+	h := t.Hour()
+	if q.start < q.end {
+		return h >= q.start && h < q.end
+	}
+	return h >= q.start || h < q.end
+}
+
+// rateLimiter caps messages per channel over a sliding hour
+type rateLimiter struct {
+	perHour int // 0 = unlimited
+
+	mu   sync.Mutex
+	sent map[string][]time.Time
+}
+
+func newRateLimiter(perHour int) *rateLimiter {
+	return &rateLimiter{perHour: perHour, sent: make(map[string][]time.Time)}
+}
+
+// allow records a send on channel at now if it is within the limit
+func (r *rateLimiter) allow(channel string, now time.Time) bool {
+	if r.perHour <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := now.Add(-time.Hour)
+	recent := r.sent[channel][:0]
+	for _, t := range r.sent[channel] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= r.perHour {
+		r.sent[channel] = recent
+		return false
+	}
+	r.sent[channel] = append(recent, now)
+	return true
+}
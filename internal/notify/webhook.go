@@ -0,0 +1,331 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Behavior events that can be delivered to webhooks
+const (
+	EventEpisodeClosed     = "episode.closed"
+	EventPatternDiscovered = "pattern.discovered"
+	EventAnomalyDetected   = "anomaly.detected"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultTimeout    = 10 * time.Second
+	defaultBackoff    = 2 * time.Second
+
+	// queueSize bounds pending deliveries per webhook; events beyond it are dropped
+	queueSize = 100
+
+	// Request headers
+	HeaderEvent     = "X-Jeeves-Event"
+	HeaderDelivery  = "X-Jeeves-Delivery"
+	HeaderTimestamp = "X-Jeeves-Timestamp"
+	HeaderSignature = "X-Jeeves-Signature"
+)
+
+// Event is a behavior event. Without a template it is sent as-is; templates
+// receive it as their data (.Event, .Timestamp, .Data).
+type Event struct {
+	Event     string                 `json:"event"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// WebhookConfig defines one webhook sink
+type WebhookConfig struct {
+	Name       string            `json:"name"`
+	URL        string            `json:"url"`
+	Events     []string          `json:"events,omitempty"`      // event types to deliver (empty = all)
+	Template   string            `json:"template,omitempty"`    // text/template rendering the JSON body (empty = the event itself)
+	Secret     string            `json:"secret,omitempty"`      // HMAC-SHA256 signing key (empty = unsigned)
+	Headers    map[string]string `json:"headers,omitempty"`     // extra request headers, e.g. Authorization
+	MaxRetries *int              `json:"max_retries,omitempty"` // retries after the first attempt (default 3)
+	Timeout    string            `json:"timeout,omitempty"`     // per-request timeout as a Go duration (default 10s)
+}
+
+type configFile struct {
+	Webhooks []WebhookConfig `json:"webhooks"`
+}
+
+// LoadConfig reads webhook definitions from a JSON file of the form
+// {"webhooks": [{...}, ...]}
+func LoadConfig(path string) ([]WebhookConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook config: %w", err)
+	}
+
+	var file configFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook config: %w", err)
+	}
+	return file.Webhooks, nil
+}
+
+type webhook struct {
+	cfg        WebhookConfig
+	events     map[string]bool
+	tmpl       *template.Template
+	timeout    time.Duration
+	maxRetries int
+	queue      chan Event
+}
+
+// Notifier delivers behavior events to the configured webhooks. Each webhook
+// has its own queue so a slow or failing endpoint does not delay the others.
+// A nil *Notifier discards all events.
+type Notifier struct {
+	webhooks []*webhook
+	client   *http.Client
+	backoff  time.Duration
+	logger   *slog.Logger
+}
+
+// New validates the webhook definitions and compiles their templates
+func New(configs []WebhookConfig, logger *slog.Logger) (*Notifier, error) {
+	n := &Notifier{
+		client:  &http.Client{},
+		backoff: defaultBackoff,
+		logger:  logger.With("component", "notifier"),
+	}
+
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("webhook-%d", i+1)
+		}
+		u, err := url.Parse(cfg.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhook %s: invalid url %q", cfg.Name, cfg.URL)
+		}
+
+		w := &webhook{
+			cfg:        cfg,
+			timeout:    defaultTimeout,
+			maxRetries: defaultMaxRetries,
+			queue:      make(chan Event, queueSize),
+		}
+		if len(cfg.Events) > 0 {
+			w.events = make(map[string]bool, len(cfg.Events))
+			for _, e := range cfg.Events {
+				w.events[e] = true
+			}
+		}
+		if cfg.Template != "" {
+			w.tmpl, err = template.New(cfg.Name).Funcs(templateFuncs).Parse(cfg.Template)
+			if err != nil {
+				return nil, fmt.Errorf("webhook %s: failed to parse template: %w", cfg.Name, err)
+			}
+		}
+		if cfg.Timeout != "" {
+			w.timeout, err = time.ParseDuration(cfg.Timeout)
+			if err != nil || w.timeout <= 0 {
+				return nil, fmt.Errorf("webhook %s: invalid timeout %q", cfg.Name, cfg.Timeout)
+			}
+		}
+		if cfg.MaxRetries != nil {
+			if *cfg.MaxRetries < 0 {
+				return nil, fmt.Errorf("webhook %s: max_retries must not be negative", cfg.Name)
+			}
+			w.maxRetries = *cfg.MaxRetries
+		}
+
+		n.webhooks = append(n.webhooks, w)
+	}
+
+	return n, nil
+}
+
+// NewFromFile loads webhooks from path. It returns a nil Notifier when path
+// is empty or defines no webhooks.
+func NewFromFile(path string, logger *slog.Logger) (*Notifier, error) {
+	if path == "" {
+		return nil, nil
+	}
+	configs, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	return New(configs, logger)
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value, e.g. "location": {{json .Data.location}}
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Start runs one delivery worker per webhook until ctx is cancelled
+func (n *Notifier) Start(ctx context.Context) {
+	if n == nil {
+		return
+	}
+	n.logger.Info("Starting webhook notifier", "webhooks", len(n.webhooks))
+	for _, w := range n.webhooks {
+		go n.run(ctx, w)
+	}
+}
+
+// Notify queues an event for every webhook subscribed to its type. It never
+// blocks; events are dropped when a webhook's queue is full.
+func (n *Notifier) Notify(eventType string, data map[string]interface{}) {
+	if n == nil {
+		return
+	}
+
+	event := Event{Event: eventType, Timestamp: time.Now().UTC(), Data: data}
+	for _, w := range n.webhooks {
+		if w.events != nil && !w.events[eventType] {
+			continue
+		}
+		select {
+		case w.queue <- event:
+		default:
+			n.logger.Warn("Webhook queue full, dropping event", "webhook", w.cfg.Name, "event", eventType)
+		}
+	}
+}
+
+func (n *Notifier) run(ctx context.Context, w *webhook) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-w.queue:
+			if err := n.deliver(ctx, w, event); err != nil {
+				n.logger.Error("Webhook delivery failed",
+					"webhook", w.cfg.Name,
+					"event", event.Event,
+					"error", err)
+			}
+		}
+	}
+}
+
+// render produces the request body for an event
+func (w *webhook) render(event Event) ([]byte, error) {
+	if w.tmpl == nil {
+		return json.Marshal(event)
+	}
+
+	var buf bytes.Buffer
+	if err := w.tmpl.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template did not produce valid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// deliver posts an event, retrying network errors, 429 and 5xx responses with
+// exponential backoff
+func (n *Notifier) deliver(ctx context.Context, w *webhook, event Event) error {
+	body, err := w.render(event)
+	if err != nil {
+		return err
+	}
+
+	deliveryID := uuid.New().String()
+	var lastErr error
+	for attempt := 0; attempt <= w.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(n.backoff << (attempt - 1)):
+			}
+		}
+
+		retry, err := n.post(ctx, w, event.Event, deliveryID, body)
+		if err == nil {
+			n.logger.Debug("Webhook delivered",
+				"webhook", w.cfg.Name,
+				"event", event.Event,
+				"delivery", deliveryID,
+				"attempts", attempt+1)
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+		n.logger.Warn("Webhook attempt failed",
+			"webhook", w.cfg.Name,
+			"event", event.Event,
+			"attempt", attempt+1,
+			"error", err)
+	}
+
+	return lastErr
+}
+
+// post sends one request and reports whether a failure is worth retrying
+func (n *Notifier) post(ctx context.Context, w *webhook, eventType, deliveryID string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "jeeves-notifier")
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if w.cfg.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(w.cfg.Secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+// Sign returns the X-Jeeves-Signature value: "sha256=" followed by the hex
+// HMAC-SHA256 of "<timestamp>.<body>". Receivers should recompute it from the
+// X-Jeeves-Timestamp header and raw body, and reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  WebhookConfig
+	}{
+		{"missing url", WebhookConfig{Name: "a"}},
+		{"bad scheme", WebhookConfig{URL: "ftp://example.com"}},
+		{"bad template", WebhookConfig{URL: "http://example.com", Template: "{{.Data"}},
+		{"bad timeout", WebhookConfig{URL: "http://example.com", Timeout: "soon"}},
+		{"negative retries", WebhookConfig{URL: "http://example.com", MaxRetries: intPtr(-1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]WebhookConfig{tt.cfg}, testLogger()); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestNewFromFile(t *testing.T) {
+	n, err := NewFromFile("", testLogger())
+	if err != nil || n != nil {
+		t.Fatalf("empty path: got %v, %v; want nil, nil", n, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	data := `{"webhooks": [{"name": "ha", "url": "https://example.com/hook", "events": ["anomaly.detected"], "max_retries": 0}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err = NewFromFile(path, testLogger())
+	if err != nil {
+		t.Fatalf("NewFromFile: %v", err)
+	}
+	if len(n.webhooks) != 1 {
+		t.Fatalf("got %d webhooks, want 1", len(n.webhooks))
+	}
+	w := n.webhooks[0]
+	if w.maxRetries != 0 || !w.events[EventAnomalyDetected] || w.events[EventEpisodeClosed] {
+		t.Errorf("unexpected webhook %+v", w)
+	}
+}
+
+func TestRender(t *testing.T) {
+	event := Event{
+		Event:     EventEpisodeClosed,
+		Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data:      map[string]interface{}{"location": "kitchen \"main\"", "duration_minutes": 12},
+	}
+
+	w := &webhook{}
+	body, err := w.render(event)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var decoded Event
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded.Event != EventEpisodeClosed {
+		t.Errorf("default body = %s", body)
+	}
+
+	n, err := New([]WebhookConfig{{
+		URL:      "http://example.com",
+		Template: `{"text": {{json (printf "%s left after %v min" .Data.location .Data.duration_minutes)}}, "at": {{json .Timestamp}}}`,
+	}}, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err = n.webhooks[0].render(event)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	var msg map[string]string
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("invalid body %s: %v", body, err)
+	}
+	if msg["text"] != `kitchen "main" left after 12 min` || msg["at"] != "2025-01-02T03:04:05Z" {
+		t.Errorf("unexpected body %v", msg)
+	}
+
+	bad, err := New([]WebhookConfig{{URL: "http://example.com", Template: `{"text": {{.Data.location}}}`}}, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bad.webhooks[0].render(event); err == nil {
+		t.Error("expected invalid JSON error")
+	}
+}
+
+func TestDeliver_SignsAndRetries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := attempts.Add(1)
+
+		body, _ := io.ReadAll(r.Body)
+		ts, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Errorf("bad timestamp header: %v", err)
+		}
+		if got, want := r.Header.Get(HeaderSignature), Sign("s3cret", ts, body); got != want {
+			t.Errorf("signature = %s, want %s", got, want)
+		}
+		if r.Header.Get(HeaderEvent) != EventPatternDiscovered || r.Header.Get("Authorization") != "Bearer x" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n, err := New([]WebhookConfig{{
+		URL:     server.URL,
+		Secret:  "s3cret",
+		Headers: map[string]string{"Authorization": "Bearer x"},
+	}}, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.backoff = time.Millisecond
+
+	event := Event{Event: EventPatternDiscovered, Timestamp: time.Now(), Data: map[string]interface{}{"name": "morning"}}
+	if err := n.deliver(context.Background(), n.webhooks[0], event); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("attempts = %d, want 3", attempts.Load())
+	}
+}
+
+func TestDeliver_NoRetryOnClientError(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	n, err := New([]WebhookConfig{{URL: server.URL}}, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.backoff = time.Millisecond
+
+	if err := n.deliver(context.Background(), n.webhooks[0], Event{Event: EventAnomalyDetected}); err == nil {
+		t.Error("expected error")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("attempts = %d, want 1", attempts.Load())
+	}
+}
+
+func TestNotify_FiltersEvents(t *testing.T) {
+	n, err := New([]WebhookConfig{
+		{URL: "http://example.com/a", Events: []string{EventAnomalyDetected}},
+		{URL: "http://example.com/b"},
+	}, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n.Notify(EventEpisodeClosed, nil)
+	n.Notify(EventAnomalyDetected, nil)
+
+	if got := len(n.webhooks[0].queue); got != 1 {
+		t.Errorf("filtered webhook queued %d events, want 1", got)
+	}
+	if got := len(n.webhooks[1].queue); got != 2 {
+		t.Errorf("catch-all webhook queued %d events, want 2", got)
+	}
+
+	var nilNotifier *Notifier
+	nilNotifier.Notify(EventEpisodeClosed, nil)
+	nilNotifier.Start(context.Background())
+}
@@ -0,0 +1,118 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/logging"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// Behavior agent trigger topics relayed by the admin endpoints
+const (
+	purgeTopic = "automation/behavior/purge"
+)
+
+// ConsolidateRequest is the body of POST /api/consolidate
+type ConsolidateRequest struct {
+	LookbackHours int    `json:"lookback_hours,omitempty"` // 0 uses the behavior agent default
+	Location      string `json:"location,omitempty"`
+}
+
+// handleConsolidate serves POST /api/consolidate by triggering consolidation in
+// the behavior agent
+func handleConsolidate(client mqtt.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req ConsolidateRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+		if req.LookbackHours < 0 {
+			http.Error(w, "lookback_hours must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		// The behavior agent logs the run under the same correlation ID
+		correlationID := logging.NewCorrelationID()
+		trigger := events.ConsolidationTrigger{
+			Action:        events.ConsolidateAction,
+			LookbackHours: req.LookbackHours,
+			Location:      req.Location,
+			CorrelationID: correlationID,
+		}
+		if !relay(w, client, trigger.Topic(), trigger, logger) {
+			return
+		}
+
+		logger.Info("Consolidation triggered from observer",
+			"lookback_hours", req.LookbackHours,
+			"location", req.Location,
+			logging.CorrelationKey, correlationID)
+		w.Header().Set("X-Correlation-ID", correlationID)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// handlePurge serves POST /api/purge by asking the behavior agent to delete data
+// for a location and/or time range
+func handlePurge(client mqtt.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var filter storage.PurgeFilter
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := filter.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !relay(w, client, purgeTopic, filter, logger) {
+			return
+		}
+
+		logger.Info("Purge requested from observer",
+			"location", filter.Location,
+			"since", filter.Since,
+			"until", filter.Until)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// relay publishes payload to the behavior agent, writing an error response on failure
+func relay(w http.ResponseWriter, client mqtt.Client, topic string, payload interface{}, logger *slog.Logger) bool {
+	if !client.IsConnected() {
+		http.Error(w, "MQTT unavailable", http.StatusServiceUnavailable)
+		return false
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if err := client.Publish(topic, 1, false, data); err != nil {
+		logger.Error("Failed to publish admin request", "topic", topic, "error", err)
+		http.Error(w, "failed to reach behavior agent", http.StatusBadGateway)
+		return false
+	}
+	return true
+}
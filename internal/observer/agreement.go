@@ -0,0 +1,92 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// maxAgreementLimit bounds the history returned by /api/distance-agreement
+const maxAgreementLimit = 365
+
+// AgreementReport is a row of distance_agreement_reports
+type AgreementReport struct {
+	ID        int             `json:"id"`
+	Samples   int             `json:"samples"`
+	Overall   json.RawMessage `json:"overall"`
+	Locations json.RawMessage `json:"locations"`
+	Weights   json.RawMessage `json:"weights"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// handleDistanceAgreement serves GET /api/distance-agreement: the latest
+// vector/learned vs LLM distance agreement report, or with limit the last
+// reports, newest first
+func handleDistanceAgreement(pg postgres.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 1
+		v := r.URL.Query().Get("limit")
+		if v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, fmt.Sprintf("Invalid limit: %s", v), http.StatusBadRequest)
+				return
+			}
+			limit = min(n, maxAgreementLimit)
+		}
+
+		reports, err := getAgreementReports(pg, limit)
+		if err != nil {
+			logger.Error("Failed to get distance agreement reports", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if v == "" {
+			if len(reports) == 0 {
+				http.Error(w, "No distance agreement report yet", http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(reports[0])
+			return
+		}
+		json.NewEncoder(w).Encode(reports)
+	}
+}
+
+func getAgreementReports(pg postgres.Client, limit int) ([]AgreementReport, error) {
+	query := `
+		SELECT id, samples, overall, locations, weights, created_at
+		FROM distance_agreement_reports
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+
+	rows, err := pg.Query(context.Background(), query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query distance agreement reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []AgreementReport{}
+	for rows.Next() {
+		var a AgreementReport
+		var overall, locations, weights []byte
+		if err := rows.Scan(&a.ID, &a.Samples, &overall, &locations, &weights, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan distance agreement report: %w", err)
+		}
+		a.Overall = json.RawMessage(overall)
+		a.Locations = json.RawMessage(locations)
+		a.Weights = json.RawMessage(weights)
+		reports = append(reports, a)
+	}
+
+	return reports, rows.Err()
+}
@@ -0,0 +1,135 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// Batch run list bounds for /api/batches
+const (
+	defaultBatchLimit = 50
+	maxBatchLimit     = 1000
+)
+
+// BatchRun is a row of batch_runs, as published on automation/behavior/batch_complete
+type BatchRun struct {
+	ID          string          `json:"batch_id"`
+	Trigger     string          `json:"trigger"`
+	Status      string          `json:"status"`
+	WindowStart time.Time       `json:"window_start"`
+	BatchStart  time.Time       `json:"batch_start"`
+	BatchEnd    time.Time       `json:"batch_end"`
+	Episodes    int             `json:"episodes"`
+	Anchors     int             `json:"anchors"`
+	Distances   int             `json:"distances"`
+	Patterns    int             `json:"patterns"`
+	Phases      json.RawMessage `json:"phases"`
+	Error       string          `json:"error,omitempty"`
+	StartedAt   time.Time       `json:"started_at"`
+	DurationMs  int64           `json:"duration_ms"`
+	Resumes     int             `json:"resumes"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// handleBatches serves GET /api/batches, newest first. id returns a single
+// run; status (running, completed or failed) and limit filter the list.
+func handleBatches(pg postgres.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		id := params.Get("id")
+		if id != "" {
+			if _, err := uuid.Parse(id); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid id: %s", id), http.StatusBadRequest)
+				return
+			}
+		}
+
+		limit := defaultBatchLimit
+		if v := params.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, fmt.Sprintf("Invalid limit: %s", v), http.StatusBadRequest)
+				return
+			}
+			limit = min(n, maxBatchLimit)
+		}
+
+		runs, err := getBatchRuns(pg, id, params.Get("status"), limit)
+		if err != nil {
+			logger.Error("Failed to get batch runs", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if id != "" {
+			if len(runs) == 0 {
+				http.Error(w, fmt.Sprintf("Batch run %s not found", id), http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(runs[0])
+			return
+		}
+		json.NewEncoder(w).Encode(runs)
+	}
+}
+
+func getBatchRuns(pg postgres.Client, id, status string, limit int) ([]BatchRun, error) {
+	query := `
+		SELECT
+			id::text, trigger, status, window_start, batch_start, batch_end,
+			episodes, anchors, distances, patterns, phases, COALESCE(error, ''),
+			started_at, duration_ms, resumes, updated_at
+		FROM batch_runs
+		WHERE ($1 = '' OR id::text = $1)
+			AND ($2 = '' OR status = $2)
+		ORDER BY started_at DESC
+		LIMIT $3
+	`
+
+	rows, err := pg.Query(context.Background(), query, id, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []BatchRun{}
+	for rows.Next() {
+		var b BatchRun
+		var phases []byte
+		if err := rows.Scan(
+			&b.ID,
+			&b.Trigger,
+			&b.Status,
+			&b.WindowStart,
+			&b.BatchStart,
+			&b.BatchEnd,
+			&b.Episodes,
+			&b.Anchors,
+			&b.Distances,
+			&b.Patterns,
+			&phases,
+			&b.Error,
+			&b.StartedAt,
+			&b.DurationMs,
+			&b.Resumes,
+			&b.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan batch run: %w", err)
+		}
+		b.Phases = json.RawMessage(phases)
+		runs = append(runs, b)
+	}
+
+	return runs, rows.Err()
+}
@@ -0,0 +1,289 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/prompts"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/llm"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+const (
+	// briefingTopic carries the latest morning briefing (retained)
+	briefingTopic = "automation/behavior/briefing"
+
+	// briefingTimeout bounds the LLM call
+	briefingTimeout = 2 * time.Minute
+)
+
+// Briefing is the morning summary of the previous day
+type Briefing struct {
+	Date        string    `json:"date"` // the day summarized, YYYY-MM-DD
+	Text        string    `json:"text"`
+	Source      string    `json:"source"` // "llm", or "fallback" when the LLM was unavailable
+	Model       string    `json:"model,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// briefingEpisode is a macro-episode listed in the briefing
+type briefingEpisode struct {
+	PatternType string
+	Start       time.Time
+	End         time.Time
+	Locations   []string
+}
+
+// briefingWriter turns the previous day's report into a short spoken briefing
+type briefingWriter struct {
+	cfg     *config.Config
+	pg      postgres.Client
+	llm     *llm.Router
+	prompts *llm.Prompts
+	tz      *time.Location
+	logger  *slog.Logger
+}
+
+func newBriefingWriter(cfg *config.Config, pg postgres.Client, tz *time.Location, logger *slog.Logger) (*briefingWriter, error) {
+	promptSet, err := prompts.New(cfg.LLMPromptDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load LLM prompts: %w", err)
+	}
+
+	return &briefingWriter{
+		cfg: cfg,
+		pg:  pg,
+		llm: llm.NewRouter(llm.NewOllamaClient(cfg.LLMEndpoint, logger), cfg.LLMModel, map[string]string{
+			llm.TaskBriefing: cfg.LLMBriefingModel,
+		}),
+		prompts: promptSet,
+		tz:      tz,
+		logger:  logger.With("component", "briefing"),
+	}, nil
+}
+
+// Start publishes the briefing about the previous day every day at
+// cfg.BriefingHour
+func (b *briefingWriter) Start(ctx context.Context, mqttClient mqtt.Client) {
+	b.logger.Info("Starting morning briefing",
+		"hour", b.cfg.BriefingHour,
+		"model", b.llm.Model(llm.TaskBriefing))
+
+	for {
+		next := nextReportTime(time.Now().In(b.tz), b.cfg.BriefingHour)
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		briefing, err := b.Write(ctx, next.AddDate(0, 0, -1))
+		if err != nil {
+			b.logger.Error("Failed to write briefing", "error", err)
+			continue
+		}
+
+		payload, err := json.Marshal(briefing)
+		if err != nil {
+			b.logger.Error("Failed to marshal briefing", "error", err)
+			continue
+		}
+		if err := mqttClient.Publish(briefingTopic, 1, true, payload); err != nil {
+			b.logger.Error("Failed to publish briefing", "topic", briefingTopic, "error", err)
+			continue
+		}
+
+		b.logger.Info("Published briefing", "date", briefing.Date, "source", briefing.Source)
+	}
+}
+
+// Write composes the briefing about date; last night is the sleep that ended
+// the following morning
+func (b *briefingWriter) Write(ctx context.Context, date time.Time) (*Briefing, error) {
+	start, end, err := reportRange("daily", date.In(b.tz))
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := generateReport(ctx, b.pg, "daily", start, end)
+	if err != nil {
+		return nil, err
+	}
+	sleep, err := querySleep(ctx, b.pg, end, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	episodes, err := queryBriefingEpisodes(ctx, b.pg, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	data := briefingData(start, b.cfg.BriefingTone, report, sleep, episodes, b.tz)
+	briefing := &Briefing{
+		Date:        start.Format("2006-01-02"),
+		GeneratedAt: time.Now(),
+	}
+
+	model := b.llm.Model(llm.TaskBriefing)
+	text, err := b.generate(ctx, model, data)
+	if err != nil {
+		b.logger.Warn("LLM briefing failed, using fallback", "model", model, "error", err)
+		briefing.Text = fallbackBriefing(data)
+		briefing.Source = "fallback"
+		return briefing, nil
+	}
+
+	briefing.Text = text
+	briefing.Source = "llm"
+	briefing.Model = model
+	return briefing, nil
+}
+
+func (b *briefingWriter) generate(ctx context.Context, model string, data prompts.BriefingData) (string, error) {
+	prompt, err := b.prompts.Render(prompts.Briefing, model, data)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := llm.WithTimeout(ctx, briefingTimeout)
+	defer cancel()
+
+	resp, err := b.llm.Generate(ctx, llm.GenerateRequest{
+		Model:     model,
+		Prompt:    prompt,
+		Options:   map[string]interface{}{"temperature": 0.7},
+		KeepAlive: "5m",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	text := strings.TrimSpace(resp.Response)
+	if text == "" {
+		return "", fmt.Errorf("empty briefing from %s", model)
+	}
+	return text, nil
+}
+
+// queryBriefingEpisodes returns the day's macro-episodes other than sleep
+func queryBriefingEpisodes(ctx context.Context, pg postgres.Client, start, end time.Time) ([]briefingEpisode, error) {
+	rows, err := pg.Query(ctx, `
+		SELECT pattern_type, start_time, end_time, array_to_json(locations)::text
+		FROM macro_episodes
+		WHERE start_time >= $1 AND start_time < $2
+		  AND pattern_type <> $3
+		ORDER BY start_time
+	`, start, end, sleepPatternType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query macro-episodes: %w", err)
+	}
+	defer rows.Close()
+
+	var episodes []briefingEpisode
+	for rows.Next() {
+		var ep briefingEpisode
+		var locationsJSON string
+		if err := rows.Scan(&ep.PatternType, &ep.Start, &ep.End, &locationsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan macro-episode: %w", err)
+		}
+		json.Unmarshal([]byte(locationsJSON), &ep.Locations)
+		episodes = append(episodes, ep)
+	}
+	return episodes, rows.Err()
+}
+
+// briefingData renders the day for the Briefing prompt
+func briefingData(day time.Time, tone string, report *Report, sleep SleepSummary, episodes []briefingEpisode, tz *time.Location) prompts.BriefingData {
+	data := prompts.BriefingData{
+		Date: day.Format("Monday 2 January"),
+		Tone: tone,
+	}
+
+	if len(sleep.Periods) > 0 {
+		// The longest period is the night; shorter ones are naps
+		night := sleep.Periods[0]
+		for _, p := range sleep.Periods[1:] {
+			if p.Minutes > night.Minutes {
+				night = p
+			}
+		}
+		data.Sleep = fmt.Sprintf("%s (%s-%s)",
+			formatMinutes(night.Minutes), night.Start.In(tz).Format("15:04"), night.End.In(tz).Format("15:04"))
+	}
+
+	for _, ep := range episodes {
+		data.Episodes = append(data.Episodes, fmt.Sprintf("%s-%s %s (%s)",
+			ep.Start.In(tz).Format("15:04"), ep.End.In(tz).Format("15:04"),
+			ep.PatternType, strings.Join(ep.Locations, ", ")))
+	}
+	for _, room := range report.TimePerRoom {
+		data.Rooms = append(data.Rooms, fmt.Sprintf("%s: %s", room.Location, formatMinutes(room.Minutes)))
+	}
+	for _, anomaly := range report.Anomalies {
+		data.Anomalies = append(data.Anomalies, anomaly.Description)
+	}
+	return data
+}
+
+// fallbackBriefing is the plain briefing used when the LLM is unavailable
+func fallbackBriefing(data prompts.BriefingData) string {
+	var sentences []string
+	if data.Sleep != "" {
+		sentences = append(sentences, fmt.Sprintf("You slept %s.", data.Sleep))
+	} else {
+		sentences = append(sentences, "I did not notice any sleep last night.")
+	}
+	if len(data.Episodes) > 0 {
+		sentences = append(sentences, fmt.Sprintf("Yesterday I recognized %d routines.", len(data.Episodes)))
+	}
+	for _, anomaly := range data.Anomalies {
+		sentences = append(sentences, anomaly+".")
+	}
+	return strings.Join(sentences, " ")
+}
+
+// formatMinutes renders minutes as e.g. "7h20m"
+func formatMinutes(minutes float64) string {
+	m := int(minutes + 0.5)
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
+
+// handleBriefing serves GET /api/briefing?date=ddmmyyyy (default: yesterday),
+// writing the briefing on demand, e.g. to try a tone or prompt override
+func handleBriefing(writer *briefingWriter, clk clock.Clock, tz *time.Location, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		date := clk.Now().In(tz).AddDate(0, 0, -1)
+		if v := r.URL.Query().Get("date"); v != "" {
+			parsed, err := parseDateToMidnight(v, tz)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid date: %v", err), http.StatusBadRequest)
+				return
+			}
+			date = parsed
+		}
+
+		briefing, err := writer.Write(r.Context(), date)
+		if err != nil {
+			logger.Error("Failed to write briefing", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(briefing)
+	}
+}
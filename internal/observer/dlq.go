@@ -0,0 +1,162 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/saaga0h/jeeves-platform/internal/collector"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// Dead-letter list bounds for /api/dlq
+const (
+	defaultDLQLimit = 100
+	maxDLQLimit     = 1000
+)
+
+// DeadLetterRequest is the body of POST /api/dlq/reinject and
+// POST /api/dlq/discard
+type DeadLetterRequest struct {
+	ID      string          `json:"id"`
+	Payload json.RawMessage `json:"payload,omitempty"` // reinject only; replaces the stored payload
+}
+
+// handleDeadLetters serves GET /api/dlq, the sensor messages the collector
+// could not parse, newest first
+func handleDeadLetters(client redis.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultDLQLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, fmt.Sprintf("Invalid limit: %s", v), http.StatusBadRequest)
+				return
+			}
+			limit = min(n, maxDLQLimit)
+		}
+
+		entries, err := collector.ListDeadLetters(r.Context(), client, limit)
+		if err != nil {
+			logger.Error("Failed to get dead letters", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+	}
+}
+
+// handleReinject serves POST /api/dlq/reinject by publishing a dead letter,
+// optionally with a corrected payload, back on its original topic. The entry
+// is removed once published; if it still fails to parse the collector queues
+// it again.
+func handleReinject(client redis.Client, mqttClient mqtt.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		req, ok := decodeDeadLetterRequest(w, r)
+		if !ok {
+			return
+		}
+
+		entry, err := findDeadLetter(r.Context(), client, req.ID)
+		if err != nil {
+			logger.Error("Failed to get dead letters", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if entry == nil {
+			http.Error(w, fmt.Sprintf("Dead letter %s not found", req.ID), http.StatusNotFound)
+			return
+		}
+
+		payload := []byte(entry.Payload)
+		if len(req.Payload) > 0 {
+			payload = req.Payload
+		}
+
+		if !mqttClient.IsConnected() {
+			http.Error(w, "MQTT unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if err := mqttClient.Publish(entry.Topic, 0, false, payload); err != nil {
+			logger.Error("Failed to re-inject dead letter", "id", entry.ID, "topic", entry.Topic, "error", err)
+			http.Error(w, "failed to publish message", http.StatusBadGateway)
+			return
+		}
+
+		if _, err := collector.RemoveDeadLetter(r.Context(), client, entry.ID); err != nil {
+			logger.Warn("Re-injected dead letter could not be removed", "id", entry.ID, "error", err)
+		}
+
+		logger.Info("Re-injected dead letter",
+			"id", entry.ID,
+			"topic", entry.Topic,
+			"corrected", len(req.Payload) > 0)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// handleDiscard serves POST /api/dlq/discard by deleting a dead letter
+func handleDiscard(client redis.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		req, ok := decodeDeadLetterRequest(w, r)
+		if !ok {
+			return
+		}
+
+		entry, err := collector.RemoveDeadLetter(r.Context(), client, req.ID)
+		if err != nil {
+			logger.Error("Failed to discard dead letter", "id", req.ID, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if entry == nil {
+			http.Error(w, fmt.Sprintf("Dead letter %s not found", req.ID), http.StatusNotFound)
+			return
+		}
+
+		logger.Info("Discarded dead letter", "id", entry.ID, "topic", entry.Topic)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func decodeDeadLetterRequest(w http.ResponseWriter, r *http.Request) (DeadLetterRequest, bool) {
+	var req DeadLetterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return req, false
+	}
+	if req.ID == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+func findDeadLetter(ctx context.Context, client redis.Client, id string) (*collector.DeadLetter, error) {
+	entries, err := collector.ListDeadLetters(ctx, client, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, nil
+}
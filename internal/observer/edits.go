@@ -0,0 +1,156 @@
+package observer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/saaga0h/jeeves-platform/internal/behavior/storage"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// DeleteEpisodeRequest is the optional body of the delete endpoints
+type DeleteEpisodeRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// episodeEditor applies manual corrections from the timeline UI directly to
+// Postgres; tombstones keep the behavior agent from recreating removed episodes
+type episodeEditor struct {
+	storage *storage.AnchorStorage
+	logger  *slog.Logger
+}
+
+func newEpisodeEditor(pg postgres.Client, logger *slog.Logger) (*episodeEditor, error) {
+	getter, ok := pg.(interface{ DB() *sql.DB })
+	if !ok || getter.DB() == nil {
+		return nil, fmt.Errorf("postgres client does not expose a database connection")
+	}
+	return &episodeEditor{
+		storage: storage.NewAnchorStorage(getter.DB()),
+		logger:  logger.With("component", "episode-editor"),
+	}, nil
+}
+
+// register adds the edit endpoints, all wrapped with the given role check
+func (e *episodeEditor) register(mux *http.ServeMux, wrap func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("POST /api/episodes/merge", wrap(e.handleMerge))
+	mux.HandleFunc("DELETE /api/episodes/{id}", wrap(e.handleDeleteMicro))
+	mux.HandleFunc("POST /api/macro-episodes/{id}/split", wrap(e.handleSplit))
+	mux.HandleFunc("DELETE /api/macro-episodes/{id}", wrap(e.handleDeleteMacro))
+}
+
+// handleMerge serves POST /api/episodes/merge {"episode_ids": [...]}
+func (e *episodeEditor) handleMerge(w http.ResponseWriter, r *http.Request) {
+	var req storage.MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := e.storage.MergeMicroEpisodes(r.Context(), req)
+	if err != nil {
+		e.writeError(w, "merge", err)
+		return
+	}
+
+	e.logger.Info("Merged micro-episodes", "deleted", result.Deleted, "created", result.Created)
+	writeEditResult(w, result)
+}
+
+// handleSplit serves POST /api/macro-episodes/{id}/split {"at": RFC3339}
+func (e *episodeEditor) handleSplit(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid episode id", http.StatusBadRequest)
+		return
+	}
+
+	var req storage.SplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := e.storage.SplitMacroEpisode(r.Context(), id, req)
+	if err != nil {
+		e.writeError(w, "split", err)
+		return
+	}
+
+	e.logger.Info("Split macro-episode", "macro_id", id, "at", req.At, "created", result.Created)
+	writeEditResult(w, result)
+}
+
+// handleDeleteMicro serves DELETE /api/episodes/{id}
+func (e *episodeEditor) handleDeleteMicro(w http.ResponseWriter, r *http.Request) {
+	id, note, ok := parseDeleteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := e.storage.DeleteMicroEpisode(r.Context(), id, note)
+	if err != nil {
+		e.writeError(w, "delete", err)
+		return
+	}
+
+	e.logger.Info("Deleted micro-episode", "episode_id", id, "deleted", result.Deleted)
+	writeEditResult(w, result)
+}
+
+// handleDeleteMacro serves DELETE /api/macro-episodes/{id}
+func (e *episodeEditor) handleDeleteMacro(w http.ResponseWriter, r *http.Request) {
+	id, note, ok := parseDeleteRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := e.storage.DeleteMacroEpisode(r.Context(), id, note)
+	if err != nil {
+		e.writeError(w, "delete", err)
+		return
+	}
+
+	e.logger.Info("Deleted macro-episode", "macro_id", id)
+	writeEditResult(w, result)
+}
+
+func parseDeleteRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, string, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid episode id", http.StatusBadRequest)
+		return uuid.Nil, "", false
+	}
+
+	var req DeleteEpisodeRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return uuid.Nil, "", false
+		}
+	}
+	return id, req.Note, true
+}
+
+func (e *episodeEditor) writeError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, storage.ErrEpisodeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, storage.ErrInvalidEdit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		e.logger.Error("Episode edit failed", "op", op, "error", err)
+		http.Error(w, "failed to edit episodes", http.StatusInternalServerError)
+	}
+}
+
+func writeEditResult(w http.ResponseWriter, result *storage.EditResult) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
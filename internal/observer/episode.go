@@ -0,0 +1,168 @@
+package observer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/encryption"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// EpisodeDetail is one micro or macro episode with its annotations
+type EpisodeDetail struct {
+	ID          string                 `json:"id"`
+	Kind        string                 `json:"kind"` // "micro" | "macro"
+	PatternType string                 `json:"pattern_type,omitempty"`
+	Locations   []string               `json:"locations"`
+	StartTime   time.Time              `json:"start_time"`
+	EndTime     *time.Time             `json:"end_time,omitempty"` // nil while a micro episode is open
+	Summary     string                 `json:"summary,omitempty"`
+	MacroID     string                 `json:"macro_episode_id,omitempty"`  // macro claiming a micro episode
+	MicroIDs    []string               `json:"micro_episode_ids,omitempty"` // micro episodes of a macro
+	Document    map[string]interface{} `json:"document,omitempty"`          // JSON-LD (micro) or context features (macro)
+	Annotations []EpisodeAnnotation    `json:"annotations"`
+}
+
+// EpisodeAnnotation is a user annotation on an episode
+type EpisodeAnnotation struct {
+	Type      string    `json:"type"`
+	Label     string    `json:"label,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// handleEpisode serves GET /api/episodes/{id}
+func handleEpisode(pg postgres.Client, sealer *encryption.Sealer, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "invalid episode id", http.StatusBadRequest)
+			return
+		}
+
+		episode, err := getEpisode(r.Context(), pg, sealer, id)
+		if err != nil {
+			logger.Error("Failed to get episode", "id", id, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if episode == nil {
+			http.Error(w, "episode not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(episode)
+	}
+}
+
+// getEpisode looks id up among micro, then macro episodes; nil when neither exists
+func getEpisode(ctx context.Context, pg postgres.Client, sealer *encryption.Sealer, id uuid.UUID) (*EpisodeDetail, error) {
+	episode, err := getMicroEpisode(ctx, pg, sealer, id)
+	if err == nil && episode == nil {
+		episode, err = getMacroEpisode(ctx, pg, id)
+	}
+	if err != nil || episode == nil {
+		return nil, err
+	}
+
+	rows, err := pg.Query(ctx, `
+		SELECT annotation_type, COALESCE(label, ''), COALESCE(note, ''), COALESCE(author, ''), created_at
+		FROM episode_annotations
+		WHERE episode_id = $1
+		ORDER BY created_at`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query annotations: %w", err)
+	}
+	defer rows.Close()
+
+	episode.Annotations = []EpisodeAnnotation{}
+	for rows.Next() {
+		var a EpisodeAnnotation
+		if err := rows.Scan(&a.Type, &a.Label, &a.Note, &a.Author, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan annotation: %w", err)
+		}
+		episode.Annotations = append(episode.Annotations, a)
+	}
+	return episode, rows.Err()
+}
+
+func getMicroEpisode(ctx context.Context, pg postgres.Client, sealer *encryption.Sealer, id uuid.UUID) (*EpisodeDetail, error) {
+	var location, triggerType, macroID sql.NullString
+	var startedAt, endedAt sql.NullString
+	var doc []byte
+	err := pg.QueryRow(ctx, `
+		SELECT location, jsonld->>'jeeves:triggerType', started_at_text, ended_at_text, jsonld,
+		       (SELECT m.id::text FROM macro_episodes m WHERE e.id = ANY(m.micro_episode_ids) LIMIT 1)
+		FROM behavioral_episodes e
+		WHERE id = $1`, id).Scan(&location, &triggerType, &startedAt, &endedAt, &doc, &macroID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episode: %w", err)
+	}
+
+	opened, err := sealer.Open(doc, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt episode: %w", err)
+	}
+	episode := &EpisodeDetail{
+		ID:          id.String(),
+		Kind:        "micro",
+		PatternType: triggerType.String,
+		Locations:   []string{},
+		MacroID:     macroID.String,
+	}
+	if err := json.Unmarshal(opened, &episode.Document); err != nil {
+		return nil, fmt.Errorf("failed to decode episode: %w", err)
+	}
+	if location.Valid {
+		episode.Locations = append(episode.Locations, location.String)
+	}
+	if t, err := time.Parse(time.RFC3339, startedAt.String); err == nil {
+		episode.StartTime = t
+	}
+	if t, err := time.Parse(time.RFC3339, endedAt.String); err == nil {
+		episode.EndTime = &t
+	}
+	return episode, nil
+}
+
+func getMacroEpisode(ctx context.Context, pg postgres.Client, id uuid.UUID) (*EpisodeDetail, error) {
+	episode := &EpisodeDetail{ID: id.String(), Kind: "macro"}
+	var endTime time.Time
+	var summary sql.NullString
+	var features []byte
+	err := pg.QueryRow(ctx, `
+		SELECT pattern_type, start_time, end_time, locations, micro_episode_ids::text[],
+		       summary, context_features
+		FROM macro_episodes
+		WHERE id = $1`, id).Scan(&episode.PatternType, &episode.StartTime, &endTime,
+		pq.Array(&episode.Locations), pq.Array(&episode.MicroIDs), &summary, &features)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query macro episode: %w", err)
+	}
+
+	episode.EndTime = &endTime
+	episode.Summary = summary.String
+	if len(features) > 0 {
+		if err := json.Unmarshal(features, &episode.Document); err != nil {
+			return nil, fmt.Errorf("failed to decode context features: %w", err)
+		}
+	}
+	return episode, nil
+}
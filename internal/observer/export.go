@@ -0,0 +1,316 @@
+package observer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/parquet"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// exportDataset is a table that can be downloaded from /api/export. The query
+// takes the range start ($1) and end ($2) and selects the columns in order.
+type exportDataset struct {
+	columns []parquet.Column
+	query   string
+}
+
+var exportDatasets = map[string]exportDataset{
+	"episodes": {
+		columns: []parquet.Column{
+			{Name: "id", Type: parquet.String},
+			{Name: "location", Type: parquet.String},
+			{Name: "occupant", Type: parquet.String},
+			{Name: "activity_type", Type: parquet.String},
+			{Name: "trigger_type", Type: parquet.String},
+			{Name: "started_at", Type: parquet.Timestamp},
+			{Name: "ended_at", Type: parquet.Timestamp},
+			{Name: "duration_minutes", Type: parquet.Double},
+			{Name: "macro_episode_id", Type: parquet.String},
+		},
+		query: `
+			SELECT
+				e.id::text,
+				e.location,
+				e.occupant,
+				e.activity_type,
+				e.jsonld->>'jeeves:triggerType',
+				e.started_at_text::timestamptz,
+				e.ended_at_text::timestamptz,
+				EXTRACT(EPOCH FROM (e.ended_at_text::timestamptz - e.started_at_text::timestamptz)) / 60,
+				(SELECT m.id::text FROM macro_episodes m WHERE e.id = ANY(m.micro_episode_ids) LIMIT 1)
+			FROM behavioral_episodes e
+			WHERE e.started_at_text::timestamptz >= $1
+			  AND e.started_at_text::timestamptz < $2
+			ORDER BY e.started_at_text::timestamptz
+		`,
+	},
+	"macro_episodes": {
+		columns: []parquet.Column{
+			{Name: "id", Type: parquet.String},
+			{Name: "pattern_type", Type: parquet.String},
+			{Name: "start_time", Type: parquet.Timestamp},
+			{Name: "end_time", Type: parquet.Timestamp},
+			{Name: "duration_minutes", Type: parquet.Int64},
+			{Name: "locations", Type: parquet.String},
+			{Name: "micro_episode_count", Type: parquet.Int64},
+			{Name: "summary", Type: parquet.String},
+			{Name: "semantic_tags", Type: parquet.String},
+		},
+		query: `
+			SELECT
+				id::text,
+				pattern_type,
+				start_time,
+				end_time,
+				duration_minutes,
+				array_to_string(locations, ';'),
+				COALESCE(array_length(micro_episode_ids, 1), 0),
+				summary,
+				array_to_string(semantic_tags, ';')
+			FROM macro_episodes
+			WHERE start_time >= $1 AND start_time < $2
+			ORDER BY start_time
+		`,
+	},
+	"anchors": {
+		columns: []parquet.Column{
+			{Name: "id", Type: parquet.String},
+			{Name: "timestamp", Type: parquet.Timestamp},
+			{Name: "location", Type: parquet.String},
+			{Name: "occupant", Type: parquet.String},
+			{Name: "pattern_id", Type: parquet.String},
+			{Name: "time_of_day", Type: parquet.String},
+			{Name: "day_type", Type: parquet.String},
+			{Name: "season", Type: parquet.String},
+			{Name: "household_mode", Type: parquet.String},
+			{Name: "signal_count", Type: parquet.Int64},
+			{Name: "duration_minutes", Type: parquet.Int64},
+			{Name: "duration_source", Type: parquet.String},
+			{Name: "embedding", Type: parquet.String},
+		},
+		query: `
+			SELECT
+				id::text,
+				timestamp,
+				location,
+				occupant,
+				pattern_id::text,
+				context->>'time_of_day',
+				context->>'day_type',
+				context->>'season',
+				context->>'household_mode',
+				jsonb_array_length(signals),
+				duration_minutes,
+				duration_source,
+				semantic_embedding::text
+			FROM semantic_anchors
+			WHERE timestamp >= $1 AND timestamp < $2
+			ORDER BY timestamp
+		`,
+	},
+}
+
+// rowWriter receives exported rows; nil values are NULLs
+type rowWriter interface {
+	Write(row []interface{}) error
+	Close() error
+}
+
+// handleExport serves GET /api/export?dataset=episodes|macro_episodes|anchors
+// &from=ddmmyyyy&to=ddmmyyyy&format=csv|parquet as a file download. The to
+// date is inclusive.
+func handleExport(pg postgres.Client, tz *time.Location, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		name := params.Get("dataset")
+		dataset, ok := exportDatasets[name]
+		if !ok {
+			http.Error(w, "dataset must be episodes, macro_episodes or anchors", http.StatusBadRequest)
+			return
+		}
+
+		format := params.Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "parquet" {
+			http.Error(w, "format must be csv or parquet", http.StatusBadRequest)
+			return
+		}
+
+		fromStr, toStr := params.Get("from"), params.Get("to")
+		if fromStr == "" || toStr == "" {
+			http.Error(w, "Missing from or to parameter (format: ddmmyyyy)", http.StatusBadRequest)
+			return
+		}
+		from, err := parseDateToMidnight(fromStr, tz)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid from date: %v", err), http.StatusBadRequest)
+			return
+		}
+		to, err := parseDateToMidnight(toStr, tz)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid to date: %v", err), http.StatusBadRequest)
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+
+		rows, err := pg.Query(r.Context(), dataset.query, from, to)
+		if err != nil {
+			logger.Error("Failed to query export", "dataset", name, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		filename := fmt.Sprintf("%s_%s_%s.%s", name, from.Format("20060102"), to.AddDate(0, 0, -1).Format("20060102"), format)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		var out rowWriter
+		if format == "parquet" {
+			w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+			out = parquet.NewWriter(w, dataset.columns)
+		} else {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			out = newCSVRowWriter(w, dataset.columns)
+		}
+
+		count, err := exportRows(r.Context(), rows, dataset.columns, out)
+		if err != nil {
+			// Headers are already sent; the truncated download is all we can signal
+			logger.Error("Failed to export rows", "dataset", name, "rows", count, "error", err)
+			return
+		}
+
+		logger.Info("Exported data",
+			"dataset", name,
+			"format", format,
+			"from", from,
+			"to", to,
+			"rows", count)
+	}
+}
+
+// exportRows copies query rows to out, converting NULLs to nil
+func exportRows(ctx context.Context, rows *sql.Rows, columns []parquet.Column, out rowWriter) (int, error) {
+	count := 0
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		dest := make([]interface{}, len(columns))
+		for i, col := range columns {
+			switch col.Type {
+			case parquet.Int64:
+				dest[i] = &sql.NullInt64{}
+			case parquet.Double:
+				dest[i] = &sql.NullFloat64{}
+			case parquet.Bool:
+				dest[i] = &sql.NullBool{}
+			case parquet.Timestamp:
+				dest[i] = &sql.NullTime{}
+			default:
+				dest[i] = &sql.NullString{}
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return count, fmt.Errorf("failed to scan export row: %w", err)
+		}
+
+		row := make([]interface{}, len(columns))
+		for i, d := range dest {
+			switch v := d.(type) {
+			case *sql.NullInt64:
+				if v.Valid {
+					row[i] = v.Int64
+				}
+			case *sql.NullFloat64:
+				if v.Valid {
+					row[i] = v.Float64
+				}
+			case *sql.NullBool:
+				if v.Valid {
+					row[i] = v.Bool
+				}
+			case *sql.NullTime:
+				if v.Valid {
+					row[i] = v.Time
+				}
+			case *sql.NullString:
+				if v.Valid {
+					row[i] = v.String
+				}
+			}
+		}
+
+		if err := out.Write(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, out.Close()
+}
+
+// csvRowWriter writes rows as CSV with a header line; NULLs become empty fields
+type csvRowWriter struct {
+	w      *csv.Writer
+	header []string
+}
+
+func newCSVRowWriter(w http.ResponseWriter, columns []parquet.Column) *csvRowWriter {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return &csvRowWriter{w: csv.NewWriter(w), header: header}
+}
+
+func (c *csvRowWriter) Write(row []interface{}) error {
+	if c.header != nil {
+		if err := c.w.Write(c.header); err != nil {
+			return err
+		}
+		c.header = nil
+	}
+
+	record := make([]string, len(row))
+	for i, v := range row {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case time.Time:
+			record[i] = v.Format(time.RFC3339)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	if c.header != nil {
+		// No rows: still emit the header
+		if err := c.w.Write(c.header); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,418 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// Grafana query bounds
+const (
+	grafanaMinInterval      = time.Minute
+	grafanaDefaultMaxPoints = 1000
+)
+
+// grafanaMetrics maps a target name to a query returning (series, bucket, value)
+// rows. Parameters: $1 range start (aligned to the interval), $2 range end, $3
+// interval in seconds, $4 series filter (empty for all series).
+var grafanaMetrics = map[string]string{
+	// Fraction of each bucket a room had an active episode
+	"occupancy": `
+		WITH buckets AS (
+			SELECT generate_series($1::timestamptz, $2::timestamptz, make_interval(secs => $3::float8)) AS bucket
+		),
+		eps AS (
+			SELECT
+				location,
+				started_at_text::timestamptz AS started,
+				COALESCE(ended_at_text::timestamptz, NOW()) AS ended
+			FROM behavioral_episodes
+			WHERE location IS NOT NULL
+			  AND started_at_text::timestamptz < $2
+			  AND COALESCE(ended_at_text::timestamptz, NOW()) > $1
+			  AND ($4 = '' OR location = $4)
+		)
+		SELECT
+			eps.location,
+			b.bucket,
+			LEAST(1.0, SUM(EXTRACT(EPOCH FROM (
+				LEAST(eps.ended, b.bucket + make_interval(secs => $3::float8)) - GREATEST(eps.started, b.bucket)
+			))) / $3::float8)
+		FROM buckets b
+		JOIN eps ON eps.started < b.bucket + make_interval(secs => $3::float8) AND eps.ended > b.bucket
+		GROUP BY eps.location, b.bucket
+	`,
+	// Micro-episodes started per bucket, by room
+	"episodes": `
+		SELECT
+			location,
+			to_timestamp(floor(EXTRACT(EPOCH FROM started_at_text::timestamptz) / $3::float8) * $3::float8),
+			COUNT(*)::float8
+		FROM behavioral_episodes
+		WHERE location IS NOT NULL
+		  AND started_at_text::timestamptz >= $1
+		  AND started_at_text::timestamptz < $2
+		  AND ($4 = '' OR location = $4)
+		GROUP BY 1, 2
+	`,
+	// Macro-episodes started per bucket, by pattern type
+	"macro_episodes": `
+		SELECT
+			pattern_type,
+			to_timestamp(floor(EXTRACT(EPOCH FROM start_time) / $3::float8) * $3::float8),
+			COUNT(*)::float8
+		FROM macro_episodes
+		WHERE start_time >= $1 AND start_time < $2
+		  AND ($4 = '' OR pattern_type = $4)
+		GROUP BY 1, 2
+	`,
+	// Anchors assigned to a discovered pattern per bucket, by pattern name
+	"pattern_hits": `
+		SELECT
+			p.name,
+			to_timestamp(floor(EXTRACT(EPOCH FROM a.timestamp) / $3::float8) * $3::float8),
+			COUNT(*)::float8
+		FROM semantic_anchors a
+		JOIN behavioral_patterns p ON p.id = a.pattern_id
+		WHERE a.timestamp >= $1 AND a.timestamp < $2
+		  AND ($4 = '' OR p.name = $4)
+		GROUP BY 1, 2
+	`,
+}
+
+// grafanaSearchQueries list the series names offered as "<metric>:<series>" targets
+var grafanaSearchQueries = map[string]string{
+	"occupancy":      `SELECT DISTINCT location FROM behavioral_episodes WHERE location IS NOT NULL`,
+	"episodes":       `SELECT DISTINCT location FROM behavioral_episodes WHERE location IS NOT NULL`,
+	"macro_episodes": `SELECT DISTINCT pattern_type FROM macro_episodes`,
+	"pattern_hits":   `SELECT DISTINCT name FROM behavioral_patterns WHERE archived_at IS NULL`,
+}
+
+// GrafanaQueryRequest is the SimpleJSON /query request body
+type GrafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	IntervalMs    int64 `json:"intervalMs"`
+	MaxDataPoints int   `json:"maxDataPoints"`
+	Targets       []struct {
+		Target string `json:"target"`
+		RefID  string `json:"refId"`
+		Type   string `json:"type"` // "timeserie" (default) or "table"
+	} `json:"targets"`
+}
+
+// GrafanaSeries is one time series; datapoints are [value, unix ms] pairs
+type GrafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// GrafanaTable is the SimpleJSON table response format
+type GrafanaTable struct {
+	Type    string          `json:"type"`
+	Columns []GrafanaColumn `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+type GrafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// GrafanaAnnotation marks a macro-episode on a dashboard
+type GrafanaAnnotation struct {
+	Time    int64    `json:"time"`
+	TimeEnd int64    `json:"timeEnd"`
+	Title   string   `json:"title"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags"`
+}
+
+// registerGrafana mounts a SimpleJSON-compatible datasource under prefix. The
+// Infinity plugin can use GET {prefix}/series?target=...&from=...&to=... instead.
+func registerGrafana(mux *http.ServeMux, prefix string, pg postgres.Client, wrap func(http.HandlerFunc) http.HandlerFunc, logger *slog.Logger) {
+	logger = logger.With("component", "grafana")
+
+	// Connection test
+	mux.HandleFunc(prefix+"/", wrap(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prefix+"/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	mux.HandleFunc(prefix+"/search", wrap(handleGrafanaSearch(pg, logger)))
+	mux.HandleFunc(prefix+"/query", wrap(handleGrafanaQuery(pg, logger)))
+	mux.HandleFunc(prefix+"/annotations", wrap(handleGrafanaAnnotations(pg, logger)))
+	mux.HandleFunc(prefix+"/series", wrap(handleGrafanaSeries(pg, logger)))
+}
+
+func handleGrafanaSearch(pg postgres.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Target string `json:"target"`
+		}
+		if r.ContentLength != 0 {
+			json.NewDecoder(r.Body).Decode(&req)
+		}
+
+		targets := []string{}
+		for metric, query := range grafanaSearchQueries {
+			targets = append(targets, metric)
+
+			rows, err := pg.Query(r.Context(), query)
+			if err != nil {
+				logger.Error("Failed to list series", "metric", metric, "error", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			for rows.Next() {
+				var series string
+				if err := rows.Scan(&series); err == nil {
+					targets = append(targets, metric+":"+series)
+				}
+			}
+			rows.Close()
+		}
+
+		matching := targets[:0]
+		for _, t := range targets {
+			if strings.Contains(t, req.Target) {
+				matching = append(matching, t)
+			}
+		}
+		sort.Strings(matching)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(matching)
+	}
+}
+
+func handleGrafanaQuery(pg postgres.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req GrafanaQueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		interval := grafanaInterval(req.Range.From, req.Range.To, time.Duration(req.IntervalMs)*time.Millisecond, req.MaxDataPoints)
+
+		response := []interface{}{}
+		for _, target := range req.Targets {
+			series, err := grafanaTimeSeries(r.Context(), pg, target.Target, req.Range.From, req.Range.To, interval)
+			if err != nil {
+				logger.Error("Failed to query target", "target", target.Target, "error", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if target.Type == "table" {
+				response = append(response, grafanaSeriesTable(series))
+				continue
+			}
+			for _, s := range series {
+				response = append(response, s)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// handleGrafanaSeries serves one target over GET for the Infinity plugin. from and
+// to accept RFC3339 or unix milliseconds (Grafana's ${__from}/${__to}).
+func handleGrafanaSeries(pg postgres.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		from, err := parseGrafanaTime(params.Get("from"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid from: %v", err), http.StatusBadRequest)
+			return
+		}
+		to, err := parseGrafanaTime(params.Get("to"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid to: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		var step time.Duration
+		if v := params.Get("interval"); v != "" {
+			if step, err = time.ParseDuration(v); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid interval: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+		interval := grafanaInterval(from, to, step, 0)
+
+		series, err := grafanaTimeSeries(r.Context(), pg, params.Get("target"), from, to, interval)
+		if err != nil {
+			logger.Error("Failed to query target", "target", params.Get("target"), "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(series)
+	}
+}
+
+func handleGrafanaAnnotations(pg postgres.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Range struct {
+				From time.Time `json:"from"`
+				To   time.Time `json:"to"`
+			} `json:"range"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		query := `
+			SELECT pattern_type, start_time, end_time, COALESCE(summary, ''), locations
+			FROM macro_episodes
+			WHERE start_time < $2 AND end_time > $1
+			ORDER BY start_time
+		`
+		rows, err := pg.Query(r.Context(), query, req.Range.From, req.Range.To)
+		if err != nil {
+			logger.Error("Failed to query annotations", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		annotations := []GrafanaAnnotation{}
+		for rows.Next() {
+			var a GrafanaAnnotation
+			var start, end time.Time
+			var locations []string
+			if err := rows.Scan(&a.Title, &start, &end, &a.Text, pq.Array(&locations)); err != nil {
+				logger.Error("Failed to scan annotation", "error", err)
+				continue
+			}
+			a.Time = start.UnixMilli()
+			a.TimeEnd = end.UnixMilli()
+			a.Tags = append([]string{a.Title}, locations...)
+			annotations = append(annotations, a)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(annotations)
+	}
+}
+
+// grafanaInterval picks the bucket size: the requested interval, at least a
+// minute, widened so the range fits in maxPoints buckets
+func grafanaInterval(from, to time.Time, requested time.Duration, maxPoints int) time.Duration {
+	if maxPoints <= 0 {
+		maxPoints = grafanaDefaultMaxPoints
+	}
+	interval := max(requested, grafanaMinInterval)
+	if minimum := to.Sub(from) / time.Duration(maxPoints); interval < minimum {
+		interval = minimum
+	}
+	return interval.Truncate(time.Second)
+}
+
+// grafanaTimeSeries runs a "<metric>" or "<metric>:<series>" target and returns
+// one zero-filled series per result series
+func grafanaTimeSeries(ctx context.Context, pg postgres.Client, target string, from, to time.Time, interval time.Duration) ([]GrafanaSeries, error) {
+	metric, filter, _ := strings.Cut(target, ":")
+	query, ok := grafanaMetrics[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown target: %s", target)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("range from must be before to")
+	}
+
+	// Align buckets to the interval so adjacent queries share boundaries
+	seconds := interval.Seconds()
+	start := time.Unix(int64(float64(from.Unix())/seconds)*int64(seconds), 0).UTC()
+
+	rows, err := pg.Query(ctx, query, start, to, seconds, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", metric, err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]map[int64]float64)
+	for rows.Next() {
+		var series string
+		var bucket time.Time
+		var value float64
+		if err := rows.Scan(&series, &bucket, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", metric, err)
+		}
+		if values[series] == nil {
+			values[series] = make(map[int64]float64)
+		}
+		values[series][bucket.UnixMilli()] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if filter != "" && values[filter] == nil {
+		values[filter] = map[int64]float64{} // keep a flat zero line for a quiet series
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]GrafanaSeries, 0, len(names))
+	for _, name := range names {
+		s := GrafanaSeries{Target: metric + ":" + name, Datapoints: [][2]float64{}}
+		for t := start; t.Before(to); t = t.Add(interval) {
+			ms := t.UnixMilli()
+			s.Datapoints = append(s.Datapoints, [2]float64{values[name][ms], float64(ms)})
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// grafanaSeriesTable flattens series into a Time/Series/Value table
+func grafanaSeriesTable(series []GrafanaSeries) GrafanaTable {
+	table := GrafanaTable{
+		Type: "table",
+		Columns: []GrafanaColumn{
+			{Text: "Time", Type: "time"},
+			{Text: "Series", Type: "string"},
+			{Text: "Value", Type: "number"},
+		},
+		Rows: [][]interface{}{},
+	}
+	for _, s := range series {
+		for _, point := range s.Datapoints {
+			table.Rows = append(table.Rows, []interface{}{int64(point[1]), s.Target, point[0]})
+		}
+	}
+	return table
+}
+
+func parseGrafanaTime(v string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
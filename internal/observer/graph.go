@@ -0,0 +1,474 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/encryption"
+	"github.com/saaga0h/jeeves-platform/pkg/ontology"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// graphNode is one JSON-LD node of the exported knowledge graph
+type graphNode map[string]interface{}
+
+// graphContext is the ontology context plus the saref prefix, which the
+// stored documents use in compact IRIs (saref:Room) but only declare as @vocab
+func graphContext() map[string]interface{} {
+	ldContext := ontology.GetDefaultContext()
+	ldContext["saref"] = "https://saref.etsi.org/core#"
+	ldContext["jeeves:timestamp"] = map[string]interface{}{"@type": "xsd:dateTime"}
+	return ldContext
+}
+
+func uuidIRI(id string) string {
+	return "urn:uuid:" + id
+}
+
+func roomIRI(location string) string {
+	return "urn:room:" + location
+}
+
+func ref(iri string) map[string]interface{} {
+	return map[string]interface{}{"@id": iri}
+}
+
+// handleGraphExport serves GET /api/graph?from=ddmmyyyy&to=ddmmyyyy&format=jsonld|turtle:
+// the episodes, macro-episodes, anchors and patterns of the range (to inclusive)
+// as one linked graph of SAREF/ADL-annotated nodes
+func handleGraphExport(pg postgres.Client, sealer *encryption.Sealer, tz *time.Location, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		format := params.Get("format")
+		if format == "" {
+			format = "jsonld"
+		}
+		if format != "jsonld" && format != "turtle" {
+			http.Error(w, "format must be jsonld or turtle", http.StatusBadRequest)
+			return
+		}
+
+		fromStr, toStr := params.Get("from"), params.Get("to")
+		if fromStr == "" || toStr == "" {
+			http.Error(w, "Missing from or to parameter (format: ddmmyyyy)", http.StatusBadRequest)
+			return
+		}
+		from, err := parseDateToMidnight(fromStr, tz)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid from date: %v", err), http.StatusBadRequest)
+			return
+		}
+		to, err := parseDateToMidnight(toStr, tz)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid to date: %v", err), http.StatusBadRequest)
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+
+		nodes, err := buildGraph(r.Context(), pg, sealer, from, to)
+		if err != nil {
+			logger.Error("Failed to build knowledge graph", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		filename := fmt.Sprintf("graph_%s_%s", from.Format("20060102"), to.AddDate(0, 0, -1).Format("20060102"))
+		if format == "turtle" {
+			w.Header().Set("Content-Type", "text/turtle; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".ttl"))
+			err = writeTurtle(w, graphContext(), nodes)
+		} else {
+			w.Header().Set("Content-Type", "application/ld+json")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".jsonld"))
+			err = json.NewEncoder(w).Encode(map[string]interface{}{
+				"@context": graphContext(),
+				"@graph":   nodes,
+			})
+		}
+		if err != nil {
+			logger.Error("Failed to write knowledge graph", "format", format, "error", err)
+			return
+		}
+
+		logger.Info("Exported knowledge graph",
+			"format", format,
+			"from", from,
+			"to", to,
+			"nodes", len(nodes))
+	}
+}
+
+// buildGraph loads the range's behavior data as graph nodes, rooms first
+func buildGraph(ctx context.Context, pg postgres.Client, sealer *encryption.Sealer, from, to time.Time) ([]graphNode, error) {
+	rooms := make(map[string]bool)
+
+	episodes, err := graphEpisodes(ctx, pg, sealer, from, to, rooms)
+	if err != nil {
+		return nil, err
+	}
+	macros, err := graphMacroEpisodes(ctx, pg, from, to, rooms)
+	if err != nil {
+		return nil, err
+	}
+	anchors, err := graphAnchors(ctx, pg, from, to, rooms)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := graphPatterns(ctx, pg, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(rooms))
+	for name := range rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := make([]graphNode, 0, len(names)+len(episodes)+len(macros)+len(anchors)+len(patterns))
+	for _, name := range names {
+		nodes = append(nodes, graphNode{"@id": roomIRI(name), "@type": "saref:Room", "name": name})
+	}
+	nodes = append(nodes, episodes...)
+	nodes = append(nodes, macros...)
+	nodes = append(nodes, anchors...)
+	return append(nodes, patterns...), nil
+}
+
+// graphEpisodes returns the stored episode documents. Their @id becomes the
+// row's UUID so macro-episodes can reference them.
+func graphEpisodes(ctx context.Context, pg postgres.Client, sealer *encryption.Sealer, from, to time.Time, rooms map[string]bool) ([]graphNode, error) {
+	rows, err := pg.Query(ctx, `
+		SELECT e.id::text, e.jsonld,
+			(SELECT m.id::text FROM macro_episodes m WHERE e.id = ANY(m.micro_episode_ids) LIMIT 1)
+		FROM behavioral_episodes e
+		WHERE e.started_at_text::timestamptz >= $1
+		  AND e.started_at_text::timestamptz < $2
+		ORDER BY e.started_at_text::timestamptz`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []graphNode
+	for rows.Next() {
+		var id string
+		var doc []byte
+		var macroID *string
+		if err := rows.Scan(&id, &doc, &macroID); err != nil {
+			return nil, fmt.Errorf("failed to scan episode: %w", err)
+		}
+
+		doc, err = sealer.Open(doc, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt episode %s: %w", id, err)
+		}
+
+		var node graphNode
+		if err := json.Unmarshal(doc, &node); err != nil {
+			continue
+		}
+		delete(node, "@context")
+		node["@id"] = uuidIRI(id)
+		if macroID != nil {
+			node["jeeves:partOf"] = ref(uuidIRI(*macroID))
+		}
+
+		// The location is linked to its room node instead of repeated inline
+		if activity, ok := node["adl:activity"].(map[string]interface{}); ok {
+			if location, ok := activity["adl:location"].(map[string]interface{}); ok {
+				if name, _ := location["name"].(string); name != "" {
+					rooms[name] = true
+					activity["adl:location"] = ref(roomIRI(name))
+				}
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+func graphMacroEpisodes(ctx context.Context, pg postgres.Client, from, to time.Time, rooms map[string]bool) ([]graphNode, error) {
+	rows, err := pg.Query(ctx, `
+		SELECT id::text, pattern_type, start_time, end_time, locations, micro_episode_ids::text[],
+			COALESCE(summary, ''), COALESCE(context_features->>'@type', '')
+		FROM macro_episodes
+		WHERE start_time >= $1 AND start_time < $2
+		ORDER BY start_time`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query macro-episodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []graphNode
+	for rows.Next() {
+		var id, patternType, summary, activityType string
+		var start, end time.Time
+		var locations, microIDs []string
+		if err := rows.Scan(&id, &patternType, &start, &end, pq.Array(&locations), pq.Array(&microIDs), &summary, &activityType); err != nil {
+			return nil, fmt.Errorf("failed to scan macro-episode: %w", err)
+		}
+
+		types := []interface{}{"jeeves:MacroEpisode"}
+		if activityType != "" {
+			types = append(types, activityType)
+		}
+		node := graphNode{
+			"@id":                uuidIRI(id),
+			"@type":              types,
+			"jeeves:patternType": patternType,
+			"jeeves:startedAt":   start.Format(time.RFC3339),
+			"jeeves:endedAt":     end.Format(time.RFC3339),
+		}
+		if summary != "" {
+			node["jeeves:summary"] = summary
+		}
+
+		var places []interface{}
+		for _, location := range locations {
+			rooms[location] = true
+			places = append(places, ref(roomIRI(location)))
+		}
+		node["adl:location"] = places
+
+		var parts []interface{}
+		for _, microID := range microIDs {
+			parts = append(parts, ref(uuidIRI(microID)))
+		}
+		node["jeeves:hasPart"] = parts
+
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+func graphAnchors(ctx context.Context, pg postgres.Client, from, to time.Time, rooms map[string]bool) ([]graphNode, error) {
+	rows, err := pg.Query(ctx, `
+		SELECT id::text, timestamp, location, pattern_id::text,
+			COALESCE(context->>'time_of_day', ''), COALESCE(context->>'day_type', ''), duration_minutes
+		FROM semantic_anchors
+		WHERE timestamp >= $1 AND timestamp < $2
+		ORDER BY timestamp`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anchors: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []graphNode
+	for rows.Next() {
+		var id, location, timeOfDay, dayType string
+		var timestamp time.Time
+		var patternID *string
+		var duration *int
+		if err := rows.Scan(&id, &timestamp, &location, &patternID, &timeOfDay, &dayType, &duration); err != nil {
+			return nil, fmt.Errorf("failed to scan anchor: %w", err)
+		}
+
+		rooms[location] = true
+		node := graphNode{
+			"@id":              uuidIRI(id),
+			"@type":            "jeeves:SemanticAnchor",
+			"jeeves:timestamp": timestamp.Format(time.RFC3339),
+			"adl:location":     ref(roomIRI(location)),
+		}
+		if timeOfDay != "" {
+			node["jeeves:timeOfDay"] = timeOfDay
+		}
+		if dayType != "" {
+			node["jeeves:dayType"] = dayType
+		}
+		if duration != nil {
+			node["jeeves:durationMinutes"] = *duration
+		}
+		if patternID != nil {
+			node["jeeves:memberOf"] = ref(uuidIRI(*patternID))
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+// graphPatterns returns the patterns the range's anchors belong to
+func graphPatterns(ctx context.Context, pg postgres.Client, from, to time.Time) ([]graphNode, error) {
+	rows, err := pg.Query(ctx, `
+		SELECT id::text, name, COALESCE(pattern_type, ''), COALESCE(description, ''), weight, observations
+		FROM behavioral_patterns
+		WHERE id IN (
+			SELECT DISTINCT pattern_id FROM semantic_anchors
+			WHERE timestamp >= $1 AND timestamp < $2 AND pattern_id IS NOT NULL
+		)
+		ORDER BY name`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query patterns: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []graphNode
+	for rows.Next() {
+		var id, name, patternType, description string
+		var weight float64
+		var observations int
+		if err := rows.Scan(&id, &name, &patternType, &description, &weight, &observations); err != nil {
+			return nil, fmt.Errorf("failed to scan pattern: %w", err)
+		}
+
+		node := graphNode{
+			"@id":                 uuidIRI(id),
+			"@type":               "jeeves:BehavioralPattern",
+			"name":                name,
+			"jeeves:weight":       weight,
+			"jeeves:observations": observations,
+		}
+		if patternType != "" {
+			node["jeeves:patternType"] = patternType
+		}
+		if description != "" {
+			node["jeeves:description"] = description
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+// writeTurtle serializes graph nodes as Turtle. It covers the shapes the
+// graph uses: IRI subjects, compact IRIs, nested blank nodes, @id references,
+// typed terms from the context and plain literals.
+func writeTurtle(w io.Writer, ldContext map[string]interface{}, nodes []graphNode) error {
+	t := &turtleWriter{prefixes: make(map[string]bool), datatypes: make(map[string]string)}
+
+	var prefixes []string
+	for term, definition := range ldContext {
+		switch def := definition.(type) {
+		case string:
+			if !strings.HasPrefix(term, "@") {
+				t.prefixes[term] = true
+				prefixes = append(prefixes, fmt.Sprintf("@prefix %s: <%s> .", term, def))
+			}
+		case map[string]interface{}:
+			if datatype, ok := def["@type"].(string); ok {
+				t.datatypes[term] = datatype
+			}
+		}
+	}
+	sort.Strings(prefixes)
+	vocab, _ := ldContext["@vocab"].(string)
+
+	var b strings.Builder
+	for _, prefix := range prefixes {
+		b.WriteString(prefix + "\n")
+	}
+	if vocab != "" {
+		b.WriteString(fmt.Sprintf("@prefix : <%s> .\n", vocab))
+	}
+
+	for _, node := range nodes {
+		id, _ := node["@id"].(string)
+		b.WriteString("\n" + t.iri(id) + "\n")
+		b.WriteString(t.predicates(node, "    "))
+		b.WriteString(" .\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type turtleWriter struct {
+	prefixes  map[string]bool
+	datatypes map[string]string // term → xsd datatype
+}
+
+// iri writes a compact IRI when its prefix is declared, otherwise a full IRI
+func (t *turtleWriter) iri(value string) string {
+	if prefix, local, ok := strings.Cut(value, ":"); ok && t.prefixes[prefix] && !strings.ContainsAny(local, "/#") {
+		return value
+	}
+	return "<" + value + ">"
+}
+
+// predicate maps a JSON-LD key to a Turtle predicate; plain terms use @vocab
+func (t *turtleWriter) predicate(key string) string {
+	if key == "@type" {
+		return "a"
+	}
+	if strings.Contains(key, ":") {
+		return t.iri(key)
+	}
+	return ":" + key
+}
+
+func (t *turtleWriter) predicates(node map[string]interface{}, indent string) string {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		if key == "@id" || key == "@context" {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		// rdf:type first, as is customary
+		if (keys[i] == "@type") != (keys[j] == "@type") {
+			return keys[i] == "@type"
+		}
+		return keys[i] < keys[j]
+	})
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values, ok := node[key].([]interface{})
+		if !ok {
+			values = []interface{}{node[key]}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		objects := make([]string, 0, len(values))
+		for _, value := range values {
+			objects = append(objects, t.object(key, value, indent))
+		}
+		lines = append(lines, indent+t.predicate(key)+" "+strings.Join(objects, ", "))
+	}
+	return strings.Join(lines, " ;\n")
+}
+
+func (t *turtleWriter) object(key string, value interface{}, indent string) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if id, ok := v["@id"].(string); ok && len(v) == 1 {
+			return t.iri(id)
+		}
+		return "[\n" + t.predicates(v, indent+"    ") + "\n" + indent + "]"
+	case string:
+		if key == "@type" {
+			return t.iri(v)
+		}
+		if datatype, ok := t.datatypes[key]; ok {
+			return strconv.Quote(v) + "^^" + t.iri(datatype)
+		}
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		if v == float64(int64(v)) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'e', -1, 64)
+	case nil:
+		return `""`
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,160 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+const (
+	hoursPerWeek = 7 * 24
+
+	// defaultHeatmapWeeks is the lookback when no range is given
+	defaultHeatmapWeeks = 4
+)
+
+// Heatmap is a room x hour-of-week occupancy matrix. Occupancy[i][h] is the
+// average fraction of hour h (0 = Monday 00:00 local time) that Rooms[i] had an
+// active episode.
+type Heatmap struct {
+	From      time.Time   `json:"from"`
+	To        time.Time   `json:"to"`
+	Rooms     []string    `json:"rooms"`
+	Occupancy [][]float64 `json:"occupancy"`
+	Samples   []int       `json:"samples"` // how many times each hour of week occurs in the range
+}
+
+// handleHeatmap serves GET /api/heatmap?from=ddmmyyyy&to=ddmmyyyy (to inclusive,
+// defaults to the last four full weeks)
+func handleHeatmap(pg postgres.Client, clk clock.Clock, tz *time.Location, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		now := clk.Now().In(tz)
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz)
+		from := to.AddDate(0, 0, -7*defaultHeatmapWeeks)
+
+		if v := params.Get("from"); v != "" {
+			parsed, err := parseDateToMidnight(v, tz)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid from date: %v", err), http.StatusBadRequest)
+				return
+			}
+			from = parsed
+		}
+		if v := params.Get("to"); v != "" {
+			parsed, err := parseDateToMidnight(v, tz)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid to date: %v", err), http.StatusBadRequest)
+				return
+			}
+			to = parsed.AddDate(0, 0, 1)
+		}
+		if !from.Before(to) {
+			http.Error(w, "from must be before to", http.StatusBadRequest)
+			return
+		}
+
+		heatmap, err := computeHeatmap(r.Context(), pg, from, to, tz)
+		if err != nil {
+			logger.Error("Failed to compute heatmap", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(heatmap)
+	}
+}
+
+// hourOfWeek returns 0-167 with Monday 00:00 as 0
+func hourOfWeek(t time.Time) int {
+	return ((int(t.Weekday())+6)%7)*24 + t.Hour()
+}
+
+func computeHeatmap(ctx context.Context, pg postgres.Client, from, to time.Time, tz *time.Location) (*Heatmap, error) {
+	// Occupied seconds per room and clock hour; hours are bucketed in UTC and
+	// mapped to local hour of week below so DST shifts land in the right slot
+	query := `
+		WITH hours AS (
+			SELECT generate_series(date_trunc('hour', $1::timestamptz), $2::timestamptz - interval '1 hour', interval '1 hour') AS hour
+		),
+		eps AS (
+			SELECT
+				location,
+				started_at_text::timestamptz AS started,
+				COALESCE(ended_at_text::timestamptz, NOW()) AS ended
+			FROM behavioral_episodes
+			WHERE location IS NOT NULL
+			  AND started_at_text::timestamptz < $2
+			  AND COALESCE(ended_at_text::timestamptz, NOW()) > $1
+		)
+		SELECT
+			eps.location,
+			h.hour,
+			LEAST(3600, SUM(EXTRACT(EPOCH FROM (
+				LEAST(eps.ended, h.hour + interval '1 hour') - GREATEST(eps.started, h.hour)
+			))))
+		FROM hours h
+		JOIN eps ON eps.started < h.hour + interval '1 hour' AND eps.ended > h.hour
+		GROUP BY eps.location, h.hour
+	`
+
+	rows, err := pg.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query occupancy: %w", err)
+	}
+	defer rows.Close()
+
+	occupied := make(map[string]*[hoursPerWeek]float64)
+	for rows.Next() {
+		var location string
+		var hour time.Time
+		var seconds float64
+		if err := rows.Scan(&location, &hour, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan occupancy: %w", err)
+		}
+		if occupied[location] == nil {
+			occupied[location] = &[hoursPerWeek]float64{}
+		}
+		occupied[location][hourOfWeek(hour.In(tz))] += seconds
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	heatmap := &Heatmap{
+		From:      from,
+		To:        to,
+		Rooms:     []string{},
+		Occupancy: [][]float64{},
+		Samples:   make([]int, hoursPerWeek),
+	}
+	for t := from.Truncate(time.Hour); t.Before(to); t = t.Add(time.Hour) {
+		heatmap.Samples[hourOfWeek(t.In(tz))]++
+	}
+
+	for location := range occupied {
+		heatmap.Rooms = append(heatmap.Rooms, location)
+	}
+	sort.Strings(heatmap.Rooms)
+
+	for _, location := range heatmap.Rooms {
+		row := make([]float64, hoursPerWeek)
+		for h, seconds := range occupied[location] {
+			if heatmap.Samples[h] > 0 {
+				row[h] = seconds / (3600 * float64(heatmap.Samples[h]))
+			}
+		}
+		heatmap.Occupancy = append(heatmap.Occupancy, row)
+	}
+
+	return heatmap, nil
+}
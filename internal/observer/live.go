@@ -0,0 +1,166 @@
+package observer
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// liveTopics maps bridged MQTT topics to the event type sent to browsers
+var liveTopics = map[string]string{
+	"automation/behavior/episode/started":         "episode_started",
+	"automation/behavior/episode/closed":          "episode_closed",
+	"automation/behavior/consolidation/completed": "consolidation_completed",
+	"automation/behavior/patterns/discovered":     "patterns_discovered",
+}
+
+const (
+	liveClientBuffer = 32               // events queued per browser before it is dropped as too slow
+	livePingInterval = 30 * time.Second // keeps idle connections open through proxies
+	liveWriteTimeout = 10 * time.Second
+)
+
+// LiveEvent is a message sent over /ws
+type LiveEvent struct {
+	Type      string          `json:"type"`
+	Topic     string          `json:"topic"`
+	Timestamp time.Time       `json:"timestamp"`
+	Producer  string          `json:"producer,omitempty"`
+	TraceID   string          `json:"trace_id,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// liveHub fans MQTT events out to connected WebSocket clients
+type liveHub struct {
+	mu      sync.Mutex
+	clients map[chan LiveEvent]struct{}
+	logger  *slog.Logger
+}
+
+func newLiveHub(logger *slog.Logger) *liveHub {
+	return &liveHub{
+		clients: make(map[chan LiveEvent]struct{}),
+		logger:  logger,
+	}
+}
+
+// subscribe bridges liveTopics from MQTT into the hub
+func (h *liveHub) subscribe(client mqtt.Client) error {
+	for topic := range liveTopics {
+		if err := client.Subscribe(topic, 0, h.handleMessage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *liveHub) handleMessage(msg mqtt.Message) {
+	envelope := events.Decode(msg.Payload())
+	event := LiveEvent{
+		Type:      liveTopics[msg.Topic()],
+		Topic:     msg.Topic(),
+		Timestamp: time.Now(),
+		Producer:  envelope.Producer,
+		TraceID:   envelope.TraceID,
+	}
+	if json.Valid(envelope.Payload) {
+		event.Data = envelope.Payload
+	}
+	h.broadcast(event)
+}
+
+func (h *liveHub) broadcast(event LiveEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.clients {
+		select {
+		case ch <- event:
+		default:
+			// Slow client: disconnect rather than block MQTT delivery
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *liveHub) add() chan LiveEvent {
+	ch := make(chan LiveEvent, liveClientBuffer)
+	h.mu.Lock()
+	h.clients[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *liveHub) remove(ch chan LiveEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[ch]; ok {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// handleWebSocket serves /ws, streaming LiveEvents as JSON text messages
+func handleWebSocket(hub *liveHub, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Warn("WebSocket upgrade failed", "error", err)
+			return
+		}
+		defer conn.Close()
+
+		events := hub.add()
+		defer hub.remove(events)
+
+		logger.Debug("Live client connected", "remote", r.RemoteAddr)
+
+		// Reader: the browser sends nothing, but reading is needed to notice a close
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ping := time.NewTicker(livePingInterval)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-closed:
+				logger.Debug("Live client disconnected", "remote", r.RemoteAddr)
+				return
+			case event, ok := <-events:
+				if !ok {
+					logger.Debug("Dropped slow live client", "remote", r.RemoteAddr)
+					return
+				}
+				conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			case <-ping.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveWriteTimeout)); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package observer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// manualRoutine is a manual pattern with a daily window, checked against the
+// episodes of a report period
+type manualRoutine struct {
+	Name        string
+	Locations   []string
+	WindowStart string // "HH:MM" local
+	WindowEnd   string
+	Days        int // days whose window had passed
+	Occurred    int // of those, days with an episode starting in the window
+}
+
+// queryManualRoutines checks every active manual pattern with a window: a day
+// counts as occurred when an episode in one of its locations started inside
+// the window. Windows that have not ended by now are left out.
+func queryManualRoutines(ctx context.Context, pg postgres.Client, start, end, now time.Time) ([]manualRoutine, error) {
+	rows, err := pg.Query(ctx, `
+		SELECT name, locations, to_char(window_start, 'HH24:MI'), to_char(window_end, 'HH24:MI')
+		FROM behavioral_patterns
+		WHERE source = 'manual'
+		  AND archived_at IS NULL
+		  AND window_start IS NOT NULL AND window_end IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query manual patterns: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []manualRoutine
+	var locations []string
+	for rows.Next() {
+		var r manualRoutine
+		if err := rows.Scan(&r.Name, pq.Array(&r.Locations), &r.WindowStart, &r.WindowEnd); err != nil {
+			return nil, fmt.Errorf("failed to scan manual pattern: %w", err)
+		}
+		routines = append(routines, r)
+		locations = append(locations, r.Locations...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(routines) == 0 {
+		return nil, nil
+	}
+
+	// Windows may run past the end of the period, e.g. 22:00-01:00
+	episodes, err := pg.Query(ctx, `
+		SELECT location, started_at_text::timestamptz
+		FROM behavioral_episodes
+		WHERE started_at_text::timestamptz >= $1
+		  AND started_at_text::timestamptz < $2
+		  AND location = ANY($3)`,
+		start, end.Add(24*time.Hour), pq.Array(locations))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episodes for manual patterns: %w", err)
+	}
+	defer episodes.Close()
+
+	starts := make(map[string][]time.Time)
+	for episodes.Next() {
+		var location string
+		var startedAt time.Time
+		if err := episodes.Scan(&location, &startedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan episode: %w", err)
+		}
+		starts[location] = append(starts[location], startedAt)
+	}
+	if err := episodes.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range routines {
+		countManualRoutine(&routines[i], starts, start, end, now)
+	}
+	return routines, nil
+}
+
+// countManualRoutine fills Days and Occurred for the days in [start, end)
+func countManualRoutine(r *manualRoutine, starts map[string][]time.Time, start, end, now time.Time) {
+	ws, err1 := time.Parse("15:04", r.WindowStart)
+	we, err2 := time.Parse("15:04", r.WindowEnd)
+	if err1 != nil || err2 != nil {
+		return
+	}
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		from := time.Date(day.Year(), day.Month(), day.Day(), ws.Hour(), ws.Minute(), 0, 0, start.Location())
+		to := time.Date(day.Year(), day.Month(), day.Day(), we.Hour(), we.Minute(), 0, 0, start.Location())
+		if !to.After(from) {
+			to = to.AddDate(0, 0, 1)
+		}
+		if to.After(now) {
+			continue
+		}
+
+		r.Days++
+		if routineOccurred(r.Locations, starts, from, to) {
+			r.Occurred++
+		}
+	}
+}
+
+func routineOccurred(locations []string, starts map[string][]time.Time, from, to time.Time) bool {
+	for _, location := range locations {
+		for _, t := range starts[location] {
+			if !t.Before(from) && t.Before(to) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// manualRoutineAnomalies reports manual patterns missed on some days of the period
+func manualRoutineAnomalies(routines []manualRoutine) []Anomaly {
+	var anomalies []Anomaly
+	for _, r := range routines {
+		if r.Days == 0 || r.Occurred == r.Days {
+			continue
+		}
+		description := fmt.Sprintf("%s did not occur between %s and %s", r.Name, r.WindowStart, r.WindowEnd)
+		if r.Days > 1 {
+			description = fmt.Sprintf("%s was missed on %d of %d days (%s-%s)", r.Name, r.Days-r.Occurred, r.Days, r.WindowStart, r.WindowEnd)
+		}
+		anomalies = append(anomalies, Anomaly{
+			Kind:        "missed_pattern",
+			Subject:     r.Name,
+			Description: description,
+			Actual:      float64(r.Occurred),
+			Expected:    float64(r.Days),
+		})
+	}
+	return anomalies
+}
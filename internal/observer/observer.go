@@ -0,0 +1,763 @@
+// Package observer serves the episode timeline, pattern views and read-only
+// APIs over the behavior data, plus admin actions relayed to the agents.
+package observer
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/notify"
+	"github.com/saaga0h/jeeves-platform/pkg/auth"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/encryption"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+//go:embed web/*
+var webFiles embed.FS
+
+type EpisodeData struct {
+	ID              string                 `json:"id"`
+	Type            string                 `json:"type"` // "macro" or "micro"
+	PatternType     string                 `json:"pattern_type,omitempty"`
+	StartTime       time.Time              `json:"start_time"`
+	EndTime         time.Time              `json:"end_time"`
+	DurationMinutes float64                `json:"duration_minutes"`
+	Locations       []string               `json:"locations"`
+	Summary         string                 `json:"summary,omitempty"`
+	SemanticTags    []string               `json:"semantic_tags,omitempty"`
+	Children        []EpisodeData          `json:"children,omitempty"` // Micro episodes if macro
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Server serves the observer UI and HTTP API
+type Server struct {
+	cfg    *config.Config
+	mux    *http.ServeMux
+	mqtt   mqtt.Client
+	hub    *liveHub
+	clock  *clock.TimeManager
+	auth   auth.Authenticator
+	logger *slog.Logger
+
+	// Background jobs started by Run
+	jobs []func(ctx context.Context)
+}
+
+// New sets up the observer's routes. Redis and MQTT are optional: without them
+// the dead-letter queue, live updates and admin relays report errors.
+func New(cfg *config.Config, pgClient postgres.Client, mqttClient mqtt.Client, redisClient redis.Client, logger *slog.Logger) (*Server, error) {
+	s := &Server{
+		cfg:    cfg,
+		mux:    http.NewServeMux(),
+		mqtt:   mqttClient,
+		hub:    newLiveHub(logger),
+		clock:  clock.NewTimeManager(logger),
+		logger: logger,
+	}
+	mux := s.mux
+
+	authn, err := auth.New(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid observer auth configuration: %w", err)
+	}
+	s.auth = authn
+	viewer := func(h http.HandlerFunc) http.HandlerFunc { return auth.RequireRole(authn, auth.RoleViewer, logger, h) }
+	admin := func(h http.HandlerFunc) http.HandlerFunc { return auth.RequireRole(authn, auth.RoleAdmin, logger, h) }
+
+	// Live updates bridged from MQTT (optional - the UI falls back to manual reloads)
+	mux.HandleFunc("/ws", viewer(handleWebSocket(s.hub, logger)))
+
+	// Admin actions relayed to the behavior agent
+	mux.HandleFunc("/api/consolidate", admin(handleConsolidate(mqttClient, logger)))
+	mux.HandleFunc("/api/purge", admin(handlePurge(mqttClient, logger)))
+
+	// Sensor messages the collector could not parse
+	mux.HandleFunc("/api/dlq", viewer(handleDeadLetters(redisClient, logger)))
+	mux.HandleFunc("/api/dlq/reinject", admin(handleReinject(redisClient, mqttClient, logger)))
+	mux.HandleFunc("/api/dlq/discard", admin(handleDiscard(redisClient, logger)))
+
+	// Manual corrections from the timeline (split, merge, delete)
+	editor, err := newEpisodeEditor(pgClient, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up episode editing: %w", err)
+	}
+	editor.register(mux, admin)
+
+	// Get local timezone (EEST or whatever system is set to)
+	localTZ := time.Local
+
+	// Decrypts episode documents stored with JEEVES_ENCRYPTION_KEY
+	sealer, err := encryption.NewSealer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encryption key: %w", err)
+	}
+
+	// Anchor visualization endpoint
+	mux.HandleFunc("/api/anchors/visualization", viewer(func(w http.ResponseWriter, r *http.Request) {
+		logger.Debug("Received request for anchor visualization")
+
+		anchors, err := getAnchorsWithPatterns(pgClient, logger)
+		if err != nil {
+			logger.Error("Failed to get anchors with patterns", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		logger.Debug("Successfully retrieved anchors",
+			"count", len(anchors.Anchors),
+			"outliers", anchors.Stats.OutlierCount)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(anchors); err != nil {
+			logger.Error("Failed to encode anchors response", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		logger.Debug("Successfully sent anchor visualization response")
+	}))
+
+	// Pattern discovery results
+	mux.HandleFunc("/api/patterns", viewer(handlePatterns(pgClient, logger)))
+	mux.HandleFunc("/api/anchors", viewer(handleAnchors(pgClient, logger)))
+
+	// Sliding-window batch results
+	mux.HandleFunc("/api/batches", viewer(handleBatches(pgClient, logger)))
+
+	// Vector/learned vs LLM distance agreement
+	mux.HandleFunc("/api/distance-agreement", viewer(handleDistanceAgreement(pgClient, logger)))
+
+	// Room x hour-of-week occupancy
+	mux.HandleFunc("/api/heatmap", viewer(handleHeatmap(pgClient, s.clock, localTZ, logger)))
+
+	// Bulk downloads for offline analysis
+	mux.HandleFunc("/api/export", viewer(handleExport(pgClient, localTZ, logger)))
+
+	// Knowledge graph as JSON-LD or Turtle for semantic-web tooling
+	mux.HandleFunc("/api/graph", viewer(handleGraphExport(pgClient, sealer, localTZ, logger)))
+
+	// Grafana SimpleJSON / Infinity datasource
+	registerGrafana(mux, "/grafana", pgClient, viewer, logger)
+
+	// Daily/weekly summaries
+	mux.HandleFunc("/api/reports", viewer(handleReports(pgClient, s.clock, localTZ, logger)))
+	if cfg.ReportPublishEnabled {
+		notifier, err := notify.NewFromFile(cfg.WebhookConfigPath, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load webhooks: %w", err)
+		}
+		publisher := newReportPublisher(cfg, pgClient, mqttClient, notifier, localTZ, logger)
+		s.jobs = append(s.jobs, notifier.Start, func(ctx context.Context) { go publisher.Start(ctx) })
+	}
+
+	// LLM-written morning briefing about the previous day
+	briefing, err := newBriefingWriter(cfg, pgClient, localTZ, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up briefing: %w", err)
+	}
+	mux.HandleFunc("/api/briefing", viewer(handleBriefing(briefing, s.clock, localTZ, logger)))
+	if cfg.BriefingEnabled {
+		s.jobs = append(s.jobs, func(ctx context.Context) { go briefing.Start(ctx, mqttClient) })
+	}
+
+	// API endpoint
+	mux.HandleFunc("/api/episodes", viewer(func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseEpisodeQuery(r, localTZ)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		page, err := getEpisodesWithChildren(pgClient, sealer, q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+		json.NewEncoder(w).Encode(page)
+	}))
+
+	// Summary counts for the UI cards (no episode payloads)
+	mux.HandleFunc("GET /api/episodes/stats", viewer(handleEpisodeStats(pgClient, s.clock, localTZ, logger)))
+
+	// One episode with its document and annotations
+	mux.HandleFunc("GET /api/episodes/{id}", viewer(handleEpisode(pgClient, sealer, logger)))
+
+	// Serve static files (pages hold no data; the APIs they call are authenticated)
+	mux.Handle("/", http.FileServer(http.FS(webFiles)))
+
+	return s, nil
+}
+
+// Handle registers an extra API when the observer shares its port with other
+// agents, e.g. light scenes. Reads require the viewer role, other methods admin.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	viewer := auth.RequireRole(s.auth, auth.RoleViewer, s.logger, handler.ServeHTTP)
+	admin := auth.RequireRole(s.auth, auth.RoleAdmin, s.logger, handler.ServeHTTP)
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			viewer(w, r)
+			return
+		}
+		admin(w, r)
+	})
+}
+
+// HandlePublic registers an extra handler served without authentication,
+// e.g. a health check
+func (s *Server) HandlePublic(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Run connects MQTT for live updates, starts background jobs and serves
+// on JEEVES_OBSERVER_PORT until ctx is cancelled
+func (s *Server) Run(ctx context.Context) error {
+	if err := s.mqtt.Connect(ctx); err != nil {
+		s.logger.Warn("Failed to connect to MQTT, live updates disabled", "error", err)
+	} else {
+		defer s.mqtt.Disconnect()
+		if err := s.hub.subscribe(s.mqtt); err != nil {
+			s.logger.Warn("Failed to subscribe to live update topics", "error", err)
+		}
+		// Default date ranges follow the test runner's virtual time
+		if err := s.clock.ConfigureFromMQTT(s.mqtt); err != nil {
+			s.logger.Warn("Failed to subscribe to time config", "error", err)
+		}
+	}
+
+	for _, job := range s.jobs {
+		job(ctx)
+	}
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", s.cfg.ObserverPort),
+		Handler: s.mux,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	s.logger.Info("Starting observer server", "port", s.cfg.ObserverPort)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("observer server failed: %w", err)
+	}
+	return nil
+}
+
+// parseDateToMidnight parses ddmmyyyy and returns midnight in local timezone
+func parseDateToMidnight(dateStr string, tz *time.Location) (time.Time, error) {
+	if len(dateStr) != 8 {
+		return time.Time{}, fmt.Errorf("date must be 8 characters (ddmmyyyy), got %d", len(dateStr))
+	}
+
+	day := dateStr[0:2]
+	month := dateStr[2:4]
+	year := dateStr[4:8]
+
+	// Parse as "02-01-2006" in local timezone
+	t, err := time.ParseInLocation("02-01-2006", fmt.Sprintf("%s-%s-%s", day, month, year), tz)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format: %w", err)
+	}
+
+	return t, nil
+}
+
+// Episode list pagination bounds
+const (
+	defaultEpisodeLimit = 200
+	maxEpisodeLimit     = 1000
+)
+
+// episodeSortColumns maps the sort parameter to ORDER BY expressions
+var episodeSortColumns = map[string]string{
+	"start_time":   "start_time",
+	"duration":     "duration_minutes",
+	"pattern_type": "pattern_type",
+}
+
+// EpisodeQuery is a parsed /api/episodes request
+type EpisodeQuery struct {
+	From        time.Time
+	To          time.Time
+	Limit       int
+	Offset      int
+	Location    string
+	PatternType string
+	MinDuration float64 // minutes
+	Sort        string  // key of episodeSortColumns
+	Descending  bool
+}
+
+// EpisodePage is one page of episodes with the total matching count
+type EpisodePage struct {
+	Episodes []EpisodeData `json:"episodes"`
+	Total    int           `json:"total"`
+	Limit    int           `json:"limit"`
+	Offset   int           `json:"offset"`
+}
+
+// parseEpisodeQuery reads from/to (ddmmyyyy, required), limit, offset, location,
+// pattern_type, min_duration (minutes), sort and order (asc|desc)
+func parseEpisodeQuery(r *http.Request, tz *time.Location) (EpisodeQuery, error) {
+	params := r.URL.Query()
+	q := EpisodeQuery{
+		Limit:       defaultEpisodeLimit,
+		Location:    params.Get("location"),
+		PatternType: params.Get("pattern_type"),
+		Sort:        "start_time",
+	}
+
+	fromStr := params.Get("from") // ddmmyyyy
+	toStr := params.Get("to")     // ddmmyyyy
+	if fromStr == "" || toStr == "" {
+		return q, fmt.Errorf("Missing from or to parameter (format: ddmmyyyy)")
+	}
+
+	from, err := parseDateToMidnight(fromStr, tz)
+	if err != nil {
+		return q, fmt.Errorf("Invalid from date: %v", err)
+	}
+	to, err := parseDateToMidnight(toStr, tz)
+	if err != nil {
+		return q, fmt.Errorf("Invalid to date: %v", err)
+	}
+	q.From = from
+	// Add 24 hours to 'to' to include the entire end day
+	q.To = to.Add(24 * time.Hour)
+
+	if v := params.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return q, fmt.Errorf("Invalid limit: %s", v)
+		}
+		q.Limit = min(limit, maxEpisodeLimit)
+	}
+	if v := params.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return q, fmt.Errorf("Invalid offset: %s", v)
+		}
+		q.Offset = offset
+	}
+	if v := params.Get("min_duration"); v != "" {
+		minDuration, err := strconv.ParseFloat(v, 64)
+		if err != nil || minDuration < 0 {
+			return q, fmt.Errorf("Invalid min_duration: %s", v)
+		}
+		q.MinDuration = minDuration
+	}
+	if v := params.Get("sort"); v != "" {
+		if _, ok := episodeSortColumns[v]; !ok {
+			return q, fmt.Errorf("Invalid sort: %s (expected start_time, duration or pattern_type)", v)
+		}
+		q.Sort = v
+	}
+	switch params.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.Descending = true
+	default:
+		return q, fmt.Errorf("Invalid order: %s (expected asc or desc)", params.Get("order"))
+	}
+
+	return q, nil
+}
+
+func getEpisodesWithChildren(pg postgres.Client, sealer *encryption.Sealer, q EpisodeQuery) (*EpisodePage, error) {
+	// Macros with their children plus standalone micro episodes, filtered
+	episodesCTE := `
+        WITH macro_eps AS (
+            SELECT 
+                id,
+                'macro' as type,
+                pattern_type,
+                start_time,
+                end_time,
+                duration_minutes,
+                locations,
+                summary,
+                semantic_tags,
+                micro_episode_ids,
+                context_features
+            FROM macro_episodes
+            WHERE start_time >= $1
+              AND start_time < $2
+            ORDER BY start_time
+        ),
+        micro_eps AS (
+            SELECT
+                id,
+                'micro' as type,
+                COALESCE(jsonld->>'jeeves:triggerType', 'occupancy_transition') as pattern_type,
+                started_at_text::timestamptz as start_time,
+                COALESCE(ended_at_text::timestamptz, NOW()) as end_time,
+                COALESCE(
+                    EXTRACT(EPOCH FROM (ended_at_text::timestamptz - started_at_text::timestamptz))/60,
+                    EXTRACT(EPOCH FROM (NOW() - started_at_text::timestamptz))/60
+                ) as duration_minutes,
+                ARRAY[location] as locations,
+                '' as summary,
+                ARRAY[]::text[] as semantic_tags,
+                jsonld as metadata
+            FROM behavioral_episodes
+            WHERE started_at_text::timestamptz >= $1
+              AND started_at_text::timestamptz < $2
+        ),
+        episodes AS (
+        -- Return macros with their children
+        SELECT 
+            m.id::text AS id,
+            m.type,
+            m.pattern_type,
+            m.start_time,
+            m.end_time,
+            m.duration_minutes,
+            array_to_json(m.locations)::text as locations_json,
+            m.summary,
+            array_to_json(m.semantic_tags)::text as tags_json,
+            array_to_json(m.micro_episode_ids)::text as micro_ids_json,
+            m.context_features::text as context_json,
+            m.locations,
+            COALESCE(
+                json_agg(
+                    json_build_object(
+                        'id', me.id::text,
+                        'type', me.type,
+                        'pattern_type', me.pattern_type,
+                        'start_time', me.start_time,
+                        'end_time', me.end_time,
+                        'duration_minutes', me.duration_minutes,
+                        'locations', array_to_json(me.locations),
+                        'metadata', me.metadata
+                    ) ORDER BY me.start_time
+                ) FILTER (WHERE me.id IS NOT NULL),
+                '[]'
+            )::text as children
+        FROM macro_eps m
+        LEFT JOIN micro_eps me ON me.id = ANY(m.micro_episode_ids)
+        GROUP BY m.id, m.type, m.pattern_type, m.start_time, m.end_time, 
+                 m.duration_minutes, m.locations, m.summary, m.semantic_tags,
+                 m.micro_episode_ids, m.context_features
+        
+        UNION ALL
+        
+        -- Return standalone micro episodes (not in any macro)
+        SELECT
+            me.id::text,
+            me.type,
+            me.pattern_type,
+            me.start_time,
+            me.end_time,
+            me.duration_minutes,
+            array_to_json(me.locations)::text as locations_json,
+            me.summary,
+            '[]'::text as tags_json,
+            '[]'::text as micro_ids_json,
+            me.metadata::text as context_json,
+            me.locations,
+            '[]'::text as children
+        FROM micro_eps me
+        WHERE NOT EXISTS (
+            SELECT 1 FROM macro_episodes m
+            WHERE me.id = ANY(m.micro_episode_ids)
+        )
+        ),
+        filtered AS (
+            SELECT * FROM episodes
+            WHERE ($3::text = '' OR $3::text = ANY(locations))
+              AND ($4::text = '' OR pattern_type = $4::text)
+              AND duration_minutes >= $5
+        )
+    `
+	args := []interface{}{q.From, q.To, q.Location, q.PatternType, q.MinDuration}
+
+	page := &EpisodePage{
+		Episodes: []EpisodeData{},
+		Limit:    q.Limit,
+		Offset:   q.Offset,
+	}
+
+	if err := pg.QueryRow(context.Background(),
+		episodesCTE+`SELECT COUNT(*) FROM filtered`, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count episodes: %w", err)
+	}
+
+	direction := "ASC"
+	if q.Descending {
+		direction = "DESC"
+	}
+	query := episodesCTE + fmt.Sprintf(`
+        SELECT id, type, pattern_type, start_time, end_time, duration_minutes,
+               locations_json, summary, tags_json, micro_ids_json, context_json, children
+        FROM filtered
+        ORDER BY %s %s, start_time, id
+        LIMIT $6 OFFSET $7`, episodeSortColumns[q.Sort], direction)
+
+	rows, err := pg.Query(context.Background(), query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ep EpisodeData
+		var locationsJSON, tagsJSON, microIDsJSON, contextJSON, childrenJSON string
+
+		err := rows.Scan(
+			&ep.ID,
+			&ep.Type,
+			&ep.PatternType,
+			&ep.StartTime,
+			&ep.EndTime,
+			&ep.DurationMinutes,
+			&locationsJSON,
+			&ep.Summary,
+			&tagsJSON,
+			&microIDsJSON,
+			&contextJSON,
+			&childrenJSON,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Parse JSON strings back to arrays/objects
+		if locationsJSON != "" && locationsJSON != "null" {
+			json.Unmarshal([]byte(locationsJSON), &ep.Locations)
+		}
+
+		if tagsJSON != "" && tagsJSON != "null" {
+			json.Unmarshal([]byte(tagsJSON), &ep.SemanticTags)
+		}
+
+		if contextJSON != "" && contextJSON != "null" {
+			json.Unmarshal([]byte(contextJSON), &ep.Metadata)
+		}
+
+		// Parse children
+		if childrenJSON != "" && childrenJSON != "[]" {
+			json.Unmarshal([]byte(childrenJSON), &ep.Children)
+		}
+
+		if err := openEpisodeMetadata(sealer, &ep); err != nil {
+			return nil, err
+		}
+
+		page.Episodes = append(page.Episodes, ep)
+	}
+
+	return page, rows.Err()
+}
+
+// openEpisodeMetadata decrypts the episode documents of ep and its children
+func openEpisodeMetadata(sealer *encryption.Sealer, ep *EpisodeData) error {
+	if ep.Metadata != nil {
+		metadata, err := sealer.OpenMap(ep.Metadata, ep.ID)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt episode %s: %w", ep.ID, err)
+		}
+		ep.Metadata = metadata
+	}
+	for i := range ep.Children {
+		if err := openEpisodeMetadata(sealer, &ep.Children[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type AnchorVisualizationData struct {
+	Anchors []AnchorPoint `json:"anchors"`
+	Stats   AnchorStats   `json:"stats"`
+}
+
+type AnchorPoint struct {
+	ID          string    `json:"id"`
+	Embedding   []float64 `json:"embedding"`
+	Location    string    `json:"location"`
+	Timestamp   time.Time `json:"timestamp"`
+	PatternID   *string   `json:"pattern_id"`
+	PatternName *string   `json:"pattern_name"`
+	PatternType *string   `json:"pattern_type"`
+	TimeOfDay   string    `json:"time_of_day"`
+	DayType     string    `json:"day_type"`
+	Weight      float64   `json:"weight"`
+}
+
+type AnchorStats struct {
+	TotalCount    int                `json:"total_count"`
+	OutlierCount  int                `json:"outlier_count"`
+	OutlierRatio  float64            `json:"outlier_ratio"`
+	PatternCounts map[string]int     `json:"pattern_counts"`
+}
+
+func getAnchorsWithPatterns(pg postgres.Client, logger *slog.Logger) (*AnchorVisualizationData, error) {
+	logger.Debug("Starting getAnchorsWithPatterns")
+
+	query := `
+		SELECT
+			a.id,
+			a.semantic_embedding,
+			a.location,
+			a.timestamp,
+			a.pattern_id,
+			p.name as pattern_name,
+			p.pattern_type,
+			p.weight,
+			a.context->>'time_of_day' as time_of_day,
+			a.context->>'day_type' as day_type
+		FROM semantic_anchors a
+		LEFT JOIN behavioral_patterns p ON a.pattern_id = p.id
+		ORDER BY a.timestamp DESC
+	`
+
+	logger.Debug("Executing anchor query")
+	rows, err := pg.Query(context.Background(), query)
+	if err != nil {
+		logger.Error("Failed to execute anchor query", "error", err)
+		return nil, fmt.Errorf("failed to query anchors: %w", err)
+	}
+	defer rows.Close()
+
+	var anchors []AnchorPoint
+	patternCounts := make(map[string]int)
+	outlierCount := 0
+
+	rowCount := 0
+	for rows.Next() {
+		rowCount++
+		logger.Debug("Processing anchor row", "row_number", rowCount)
+
+		var anchor AnchorPoint
+		var embeddingText string
+		var patternID *string
+		var patternName *string
+		var patternType *string
+		var weight *float64
+		var timeOfDay *string
+		var dayType *string
+
+		err := rows.Scan(
+			&anchor.ID,
+			&embeddingText,
+			&anchor.Location,
+			&anchor.Timestamp,
+			&patternID,
+			&patternName,
+			&patternType,
+			&weight,
+			&timeOfDay,
+			&dayType,
+		)
+		if err != nil {
+			logger.Error("Failed to scan anchor row", "row_number", rowCount, "error", err)
+			return nil, fmt.Errorf("failed to scan anchor: %w", err)
+		}
+
+		logger.Debug("Scanned anchor",
+			"id", anchor.ID,
+			"location", anchor.Location,
+			"embedding_text_len", len(embeddingText),
+			"has_pattern", patternID != nil)
+
+		// Parse pgvector text format: [val1,val2,val3,...]
+		// Remove brackets and split by comma
+		if len(embeddingText) < 2 || embeddingText[0] != '[' || embeddingText[len(embeddingText)-1] != ']' {
+			logger.Error("Invalid embedding text format", "text", embeddingText[:min(50, len(embeddingText))])
+			return nil, fmt.Errorf("invalid embedding text format")
+		}
+
+		// Remove brackets
+		embeddingText = embeddingText[1 : len(embeddingText)-1]
+
+		// Split by comma and parse each value
+		values := strings.Split(embeddingText, ",")
+		embedding := make([]float64, len(values))
+
+		for i, valStr := range values {
+			val, err := strconv.ParseFloat(strings.TrimSpace(valStr), 64)
+			if err != nil {
+				logger.Error("Failed to parse embedding value",
+					"index", i,
+					"value", valStr,
+					"error", err)
+				return nil, fmt.Errorf("failed to parse embedding value at index %d: %w", i, err)
+			}
+			embedding[i] = val
+		}
+
+		logger.Debug("Successfully parsed embedding", "dimensions", len(embedding))
+
+		anchor.Embedding = embedding
+		anchor.PatternID = patternID
+		anchor.PatternName = patternName
+		anchor.PatternType = patternType
+
+		if weight != nil {
+			anchor.Weight = *weight
+		}
+
+		if timeOfDay != nil {
+			anchor.TimeOfDay = *timeOfDay
+		}
+		if dayType != nil {
+			anchor.DayType = *dayType
+		}
+
+		// Count outliers and patterns
+		if patternID == nil {
+			outlierCount++
+		} else if patternName != nil {
+			patternCounts[*patternName]++
+		}
+
+		anchors = append(anchors, anchor)
+	}
+
+	logger.Debug("Finished processing anchor rows", "total_rows", rowCount, "total_anchors", len(anchors))
+
+	// Check for any errors from iterating over rows
+	if err := rows.Err(); err != nil {
+		logger.Error("Error iterating over anchor rows", "error", err)
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	totalCount := len(anchors)
+	outlierRatio := 0.0
+	if totalCount > 0 {
+		outlierRatio = float64(outlierCount) / float64(totalCount)
+	}
+
+	logger.Debug("Computed anchor statistics",
+		"total_count", totalCount,
+		"outlier_count", outlierCount,
+		"outlier_ratio", outlierRatio,
+		"pattern_count", len(patternCounts))
+
+	return &AnchorVisualizationData{
+		Anchors: anchors,
+		Stats: AnchorStats{
+			TotalCount:    totalCount,
+			OutlierCount:  outlierCount,
+			OutlierRatio:  outlierRatio,
+			PatternCounts: patternCounts,
+		},
+	}, nil
+}
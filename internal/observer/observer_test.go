@@ -0,0 +1,133 @@
+package observer
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/encryption"
+)
+
+func TestParseEpisodeQuery(t *testing.T) {
+	tz := time.UTC
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+		check   func(t *testing.T, q EpisodeQuery)
+	}{
+		{
+			name:  "defaults",
+			query: "from=17102025&to=18102025",
+			check: func(t *testing.T, q EpisodeQuery) {
+				if !q.From.Equal(time.Date(2025, 10, 17, 0, 0, 0, 0, tz)) || !q.To.Equal(time.Date(2025, 10, 19, 0, 0, 0, 0, tz)) {
+					t.Errorf("Expected the 17th through the whole 18th, got %s to %s", q.From, q.To)
+				}
+				if q.Limit != defaultEpisodeLimit || q.Sort != "start_time" || q.Descending {
+					t.Errorf("Expected default paging and sort, got %+v", q)
+				}
+			},
+		},
+		{
+			name:  "limit is capped",
+			query: "from=17102025&to=17102025&limit=5000&offset=20&sort=duration&order=desc",
+			check: func(t *testing.T, q EpisodeQuery) {
+				if q.Limit != maxEpisodeLimit || q.Offset != 20 || q.Sort != "duration" || !q.Descending {
+					t.Errorf("Expected a capped limit, offset 20 and duration descending, got %+v", q)
+				}
+			},
+		},
+		{name: "missing to", query: "from=17102025", wantErr: true},
+		{name: "bad date", query: "from=2025-10-17&to=18102025", wantErr: true},
+		{name: "zero limit", query: "from=17102025&to=18102025&limit=0", wantErr: true},
+		{name: "negative offset", query: "from=17102025&to=18102025&offset=-1", wantErr: true},
+		{name: "negative min duration", query: "from=17102025&to=18102025&min_duration=-5", wantErr: true},
+		{name: "unknown sort", query: "from=17102025&to=18102025&sort=location", wantErr: true},
+		{name: "unknown order", query: "from=17102025&to=18102025&order=up", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parseEpisodeQuery(httptest.NewRequest("GET", "/api/episodes?"+tt.query, nil), tz)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected %q to be rejected", tt.query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected %q to be accepted, got %v", tt.query, err)
+			}
+			tt.check(t, q)
+		})
+	}
+}
+
+func TestReportRange(t *testing.T) {
+	thursday := time.Date(2025, 10, 16, 15, 30, 0, 0, time.UTC)
+
+	start, end, err := reportRange("weekly", thursday)
+	if err != nil {
+		t.Fatalf("reportRange failed: %v", err)
+	}
+	if want := time.Date(2025, 10, 13, 0, 0, 0, 0, time.UTC); !start.Equal(want) || !end.Equal(want.AddDate(0, 0, 7)) {
+		t.Errorf("Expected the week starting %s, got %s to %s", want, start, end)
+	}
+
+	start, end, err = reportRange("daily", thursday)
+	if err != nil {
+		t.Fatalf("reportRange failed: %v", err)
+	}
+	if want := time.Date(2025, 10, 16, 0, 0, 0, 0, time.UTC); !start.Equal(want) || !end.Equal(want.AddDate(0, 0, 1)) {
+		t.Errorf("Expected the day starting %s, got %s to %s", want, start, end)
+	}
+
+	if _, _, err := reportRange("monthly", thursday); err == nil {
+		t.Error("Expected an unknown period to be rejected")
+	}
+}
+
+func decodeMetadata(t *testing.T, doc []byte) map[string]interface{} {
+	t.Helper()
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(doc, &metadata); err != nil {
+		t.Fatalf("Failed to decode %s: %v", doc, err)
+	}
+	return metadata
+}
+
+func TestOpenEpisodeMetadata(t *testing.T) {
+	sealer, err := encryption.NewSealerFromKey(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("Failed to create sealer: %v", err)
+	}
+	microID := "6f1c2a9e-3b7d-4c1a-9a55-2f0e8d4b7c11"
+	sealed, err := sealer.Seal([]byte(`{"jeeves:triggerType":"occupancy_transition","note":"private"}`), []string{"jeeves:triggerType"}, microID)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+
+	ep := EpisodeData{
+		ID:       "0b3e8f52-9c41-4d7e-8a1f-6d2c5b9e4a30",
+		Metadata: map[string]interface{}{"context": "macro"},
+		Children: []EpisodeData{{ID: microID, Metadata: decodeMetadata(t, sealed)}},
+	}
+	if err := openEpisodeMetadata(sealer, &ep); err != nil {
+		t.Fatalf("openEpisodeMetadata failed: %v", err)
+	}
+	if ep.Children[0].Metadata["note"] != "private" {
+		t.Errorf("Expected the child document decrypted, got %v", ep.Children[0].Metadata)
+	}
+	if ep.Metadata["context"] != "macro" {
+		t.Errorf("Expected the macro metadata unchanged, got %v", ep.Metadata)
+	}
+
+	// A document under another episode's id does not open
+	ep.Children[0] = EpisodeData{ID: ep.ID, Metadata: decodeMetadata(t, sealed)}
+	if err := openEpisodeMetadata(sealer, &ep); err == nil {
+		t.Error("Expected a document moved to another episode to fail")
+	}
+}
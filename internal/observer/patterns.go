@@ -0,0 +1,220 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// Anchor list bounds for /api/anchors
+const (
+	defaultAnchorLimit = 500
+	maxAnchorLimit     = 5000
+)
+
+type PatternData struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description,omitempty"`
+	PatternType    string     `json:"pattern_type,omitempty"`
+	Source         string     `json:"source"` // discovered | manual
+	Weight         float64    `json:"weight"`
+	ClusterSize    int        `json:"cluster_size"`
+	AnchorCount    int        `json:"anchor_count"`
+	Locations      []string   `json:"locations"`
+	Observations   int        `json:"observations"`
+	Predictions    int        `json:"predictions"`
+	Acceptances    int        `json:"acceptances"`
+	Rejections     int        `json:"rejections"`
+	AcceptanceRate *float64   `json:"acceptance_rate"` // nil until a prediction was judged
+	FirstSeen      time.Time  `json:"first_seen"`
+	LastSeen       time.Time  `json:"last_seen"`
+	ArchivedAt     *time.Time `json:"archived_at,omitempty"`
+	ArchiveReason  string     `json:"archive_reason,omitempty"`
+}
+
+type AnchorData struct {
+	ID        string    `json:"id"`
+	Location  string    `json:"location"`
+	Timestamp time.Time `json:"timestamp"`
+	PatternID *string   `json:"pattern_id"`
+	Occupant  *string   `json:"occupant,omitempty"`
+	TimeOfDay string    `json:"time_of_day"`
+	DayType   string    `json:"day_type"`
+	Signals   int       `json:"signals"`
+}
+
+// handlePatterns serves GET /api/patterns. Archived patterns are included with
+// include_archived=true.
+func handlePatterns(pg postgres.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		includeArchived := r.URL.Query().Get("include_archived") == "true"
+
+		patterns, err := getPatterns(pg, includeArchived)
+		if err != nil {
+			logger.Error("Failed to get patterns", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(patterns)
+	}
+}
+
+// handleAnchors serves GET /api/anchors. pattern_id filters to one pattern
+// ("none" for unassigned anchors); location and limit are optional.
+func handleAnchors(pg postgres.Client, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		patternID := params.Get("pattern_id")
+		if patternID != "" && patternID != "none" {
+			if _, err := uuid.Parse(patternID); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid pattern_id: %s", patternID), http.StatusBadRequest)
+				return
+			}
+		}
+
+		limit := defaultAnchorLimit
+		if v := params.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, fmt.Sprintf("Invalid limit: %s", v), http.StatusBadRequest)
+				return
+			}
+			limit = min(n, maxAnchorLimit)
+		}
+
+		anchors, err := getAnchors(pg, patternID, params.Get("location"), limit)
+		if err != nil {
+			logger.Error("Failed to get anchors", "pattern_id", patternID, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(anchors)
+	}
+}
+
+func getPatterns(pg postgres.Client, includeArchived bool) ([]PatternData, error) {
+	query := `
+		SELECT
+			p.id::text,
+			p.name,
+			COALESCE(p.description, ''),
+			COALESCE(p.pattern_type, ''),
+			p.source,
+			p.weight,
+			p.cluster_size,
+			(SELECT COUNT(*) FROM semantic_anchors a WHERE a.pattern_id = p.id),
+			p.locations,
+			p.times_observed,
+			p.predictions,
+			p.acceptances,
+			p.rejections,
+			p.first_seen,
+			p.last_seen,
+			p.archived_at,
+			COALESCE(p.archive_reason, '')
+		FROM behavioral_patterns p
+		WHERE $1 OR p.archived_at IS NULL
+		ORDER BY p.weight DESC, p.last_seen DESC
+	`
+
+	rows, err := pg.Query(context.Background(), query, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query patterns: %w", err)
+	}
+	defer rows.Close()
+
+	patterns := []PatternData{}
+	for rows.Next() {
+		var p PatternData
+		if err := rows.Scan(
+			&p.ID,
+			&p.Name,
+			&p.Description,
+			&p.PatternType,
+			&p.Source,
+			&p.Weight,
+			&p.ClusterSize,
+			&p.AnchorCount,
+			pq.Array(&p.Locations),
+			&p.Observations,
+			&p.Predictions,
+			&p.Acceptances,
+			&p.Rejections,
+			&p.FirstSeen,
+			&p.LastSeen,
+			&p.ArchivedAt,
+			&p.ArchiveReason,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pattern: %w", err)
+		}
+
+		if judged := p.Acceptances + p.Rejections; judged > 0 {
+			rate := float64(p.Acceptances) / float64(judged)
+			p.AcceptanceRate = &rate
+		}
+
+		patterns = append(patterns, p)
+	}
+
+	return patterns, rows.Err()
+}
+
+func getAnchors(pg postgres.Client, patternID, location string, limit int) ([]AnchorData, error) {
+	query := `
+		SELECT
+			a.id::text,
+			a.location,
+			a.timestamp,
+			a.pattern_id::text,
+			a.occupant,
+			COALESCE(a.context->>'time_of_day', ''),
+			COALESCE(a.context->>'day_type', ''),
+			jsonb_array_length(a.signals)
+		FROM semantic_anchors a
+		WHERE ($1 = '' OR ($1 = 'none' AND a.pattern_id IS NULL) OR a.pattern_id::text = $1)
+		  AND ($2 = '' OR a.location = $2)
+		ORDER BY a.timestamp DESC
+		LIMIT $3
+	`
+
+	rows, err := pg.Query(context.Background(), query, patternID, location, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anchors: %w", err)
+	}
+	defer rows.Close()
+
+	anchors := []AnchorData{}
+	for rows.Next() {
+		var a AnchorData
+		if err := rows.Scan(
+			&a.ID,
+			&a.Location,
+			&a.Timestamp,
+			&a.PatternID,
+			&a.Occupant,
+			&a.TimeOfDay,
+			&a.DayType,
+			&a.Signals,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan anchor: %w", err)
+		}
+		anchors = append(anchors, a)
+	}
+
+	return anchors, rows.Err()
+}
@@ -0,0 +1,598 @@
+package observer
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/internal/notify"
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateText string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"hours": func(minutes float64) string {
+		return fmt.Sprintf("%dh %02dm", int(minutes)/60, int(minutes)%60)
+	},
+}).Parse(reportTemplateText))
+
+const (
+	// sleepPatternType matches the behavior agent's sleep macro-episodes
+	sleepPatternType = "Sleeping"
+
+	// reportBaselinePeriods is how many preceding periods form the anomaly baseline
+	reportBaselinePeriods = 4
+
+	// Anomaly thresholds
+	anomalyMinRoomDeviation = 30.0 // minutes per day
+	anomalyRoomDeviation    = 0.5  // share of the baseline
+	anomalySleepShortfall   = 0.2  // share of the baseline sleep
+)
+
+// Report is a daily or weekly behavior summary
+type Report struct {
+	Period      string           `json:"period"` // "daily" or "weekly"
+	Start       time.Time        `json:"start"`
+	End         time.Time        `json:"end"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	TimePerRoom []RoomTime       `json:"time_per_room"`
+	Routines    []RoutineSummary `json:"routines"`
+	Sleep       SleepSummary     `json:"sleep"`
+	Anomalies   []Anomaly        `json:"anomalies"`
+}
+
+// RoomTime is the time spent in one location
+type RoomTime struct {
+	Location string  `json:"location"`
+	Minutes  float64 `json:"minutes"`
+	Episodes int     `json:"episodes"`
+}
+
+// RoutineSummary aggregates the macro-episodes of one pattern type
+type RoutineSummary struct {
+	PatternType  string  `json:"pattern_type"`
+	Count        int     `json:"count"`
+	TotalMinutes float64 `json:"total_minutes"`
+	AvgStart     string  `json:"avg_start"` // HH:MM local time
+}
+
+// SleepSummary aggregates detected sleep periods
+type SleepSummary struct {
+	Nights       int           `json:"nights"`
+	TotalMinutes float64       `json:"total_minutes"`
+	AvgMinutes   float64       `json:"avg_minutes"`
+	Periods      []SleepPeriod `json:"periods"`
+}
+
+// SleepPeriod is one detected night of sleep
+type SleepPeriod struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Minutes float64   `json:"minutes"`
+}
+
+// Anomaly is a notable deviation from the preceding periods
+type Anomaly struct {
+	Kind        string  `json:"kind"` // room_time, new_room, missing_routine, missed_pattern, short_sleep
+	Subject     string  `json:"subject"`
+	Description string  `json:"description"`
+	Actual      float64 `json:"actual"`
+	Expected    float64 `json:"expected"`
+}
+
+// reportBaseline holds per-period averages over the preceding periods
+type reportBaseline struct {
+	roomMinutes   map[string]float64
+	routineCounts map[string]float64
+	sleepMinutes  float64 // average per night
+}
+
+// reportRange returns the [start, end) range for a period containing date
+func reportRange(period string, date time.Time) (time.Time, time.Time, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	switch period {
+	case "daily":
+		return day, day.AddDate(0, 0, 1), nil
+	case "weekly":
+		// Weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		start := day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown report period: %s (must be daily or weekly)", period)
+	}
+}
+
+// generateReport builds the report for [start, end) and compares it to the
+// preceding periods of the same length
+func generateReport(ctx context.Context, pg postgres.Client, period string, start, end time.Time) (*Report, error) {
+	report := &Report{
+		Period:      period,
+		Start:       start,
+		End:         end,
+		GeneratedAt: time.Now(),
+		Anomalies:   []Anomaly{},
+	}
+
+	var err error
+	if report.TimePerRoom, err = queryRoomTime(ctx, pg, start, end); err != nil {
+		return nil, err
+	}
+	if report.Routines, err = queryRoutines(ctx, pg, start, end); err != nil {
+		return nil, err
+	}
+	if report.Sleep, err = querySleep(ctx, pg, start, end); err != nil {
+		return nil, err
+	}
+
+	length := end.Sub(start)
+	baselineStart := start.Add(-reportBaselinePeriods * length)
+	baseline, err := queryBaseline(ctx, pg, baselineStart, start)
+	if err != nil {
+		return nil, err
+	}
+
+	manual, err := queryManualRoutines(ctx, pg, start, end, report.GeneratedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Anomalies = detectAnomalies(report, baseline, length.Hours()/24, manual)
+	return report, nil
+}
+
+func queryRoomTime(ctx context.Context, pg postgres.Client, start, end time.Time) ([]RoomTime, error) {
+	// Episodes are clipped to the range so ones spanning midnight count once per day
+	query := `
+		SELECT
+			location,
+			COUNT(*),
+			COALESCE(SUM(EXTRACT(EPOCH FROM (
+				LEAST(COALESCE(ended_at_text::timestamptz, NOW()), $2::timestamptz)
+				- GREATEST(started_at_text::timestamptz, $1::timestamptz)
+			)) / 60), 0)
+		FROM behavioral_episodes
+		WHERE location IS NOT NULL
+		  AND started_at_text::timestamptz < $2
+		  AND COALESCE(ended_at_text::timestamptz, NOW()) > $1
+		GROUP BY location
+		ORDER BY 3 DESC
+	`
+
+	rows, err := pg.Query(ctx, query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query room time: %w", err)
+	}
+	defer rows.Close()
+
+	rooms := []RoomTime{}
+	for rows.Next() {
+		var room RoomTime
+		if err := rows.Scan(&room.Location, &room.Episodes, &room.Minutes); err != nil {
+			return nil, fmt.Errorf("failed to scan room time: %w", err)
+		}
+		room.Minutes = math.Round(room.Minutes)
+		rooms = append(rooms, room)
+	}
+	return rooms, rows.Err()
+}
+
+func queryRoutines(ctx context.Context, pg postgres.Client, start, end time.Time) ([]RoutineSummary, error) {
+	query := `
+		SELECT pattern_type, start_time, duration_minutes
+		FROM macro_episodes
+		WHERE start_time >= $1 AND start_time < $2
+		  AND pattern_type <> $3
+		ORDER BY start_time
+	`
+
+	rows, err := pg.Query(ctx, query, start, end, sleepPatternType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query routines: %w", err)
+	}
+	defer rows.Close()
+
+	byType := make(map[string]*RoutineSummary)
+	startMinutes := make(map[string]float64)
+	for rows.Next() {
+		var patternType string
+		var startTime time.Time
+		var duration float64
+		if err := rows.Scan(&patternType, &startTime, &duration); err != nil {
+			return nil, fmt.Errorf("failed to scan routine: %w", err)
+		}
+
+		summary, ok := byType[patternType]
+		if !ok {
+			summary = &RoutineSummary{PatternType: patternType}
+			byType[patternType] = summary
+		}
+		summary.Count++
+		summary.TotalMinutes += duration
+
+		local := startTime.In(start.Location())
+		startMinutes[patternType] += float64(local.Hour()*60 + local.Minute())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	routines := make([]RoutineSummary, 0, len(byType))
+	for patternType, summary := range byType {
+		avg := int(startMinutes[patternType] / float64(summary.Count))
+		summary.AvgStart = fmt.Sprintf("%02d:%02d", avg/60, avg%60)
+		routines = append(routines, *summary)
+	}
+	sort.Slice(routines, func(i, j int) bool {
+		if routines[i].Count != routines[j].Count {
+			return routines[i].Count > routines[j].Count
+		}
+		return routines[i].PatternType < routines[j].PatternType
+	})
+	return routines, nil
+}
+
+func querySleep(ctx context.Context, pg postgres.Client, start, end time.Time) (SleepSummary, error) {
+	// A night belongs to the day it ends on
+	query := `
+		SELECT start_time, end_time, duration_minutes
+		FROM macro_episodes
+		WHERE pattern_type = $3
+		  AND end_time >= $1 AND end_time < $2
+		ORDER BY start_time
+	`
+
+	summary := SleepSummary{Periods: []SleepPeriod{}}
+
+	rows, err := pg.Query(ctx, query, start, end, sleepPatternType)
+	if err != nil {
+		return summary, fmt.Errorf("failed to query sleep: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var period SleepPeriod
+		if err := rows.Scan(&period.Start, &period.End, &period.Minutes); err != nil {
+			return summary, fmt.Errorf("failed to scan sleep period: %w", err)
+		}
+		period.Start = period.Start.In(start.Location())
+		period.End = period.End.In(start.Location())
+		summary.Periods = append(summary.Periods, period)
+		summary.TotalMinutes += period.Minutes
+	}
+	if err := rows.Err(); err != nil {
+		return summary, err
+	}
+
+	summary.Nights = len(summary.Periods)
+	if summary.Nights > 0 {
+		summary.AvgMinutes = math.Round(summary.TotalMinutes / float64(summary.Nights))
+	}
+	return summary, nil
+}
+
+func queryBaseline(ctx context.Context, pg postgres.Client, start, end time.Time) (*reportBaseline, error) {
+	baseline := &reportBaseline{
+		roomMinutes:   make(map[string]float64),
+		routineCounts: make(map[string]float64),
+	}
+
+	rooms, err := queryRoomTime(ctx, pg, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, room := range rooms {
+		baseline.roomMinutes[room.Location] = room.Minutes / reportBaselinePeriods
+	}
+
+	routines, err := queryRoutines(ctx, pg, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, routine := range routines {
+		baseline.routineCounts[routine.PatternType] = float64(routine.Count) / reportBaselinePeriods
+	}
+
+	sleep, err := querySleep(ctx, pg, start, end)
+	if err != nil {
+		return nil, err
+	}
+	baseline.sleepMinutes = sleep.AvgMinutes
+
+	return baseline, nil
+}
+
+// detectAnomalies compares a report to its baseline and to the manual
+// patterns' windows. days scales the minimum room deviation to the report length.
+func detectAnomalies(report *Report, baseline *reportBaseline, days float64, manual []manualRoutine) []Anomaly {
+	anomalies := []Anomaly{}
+	minDeviation := anomalyMinRoomDeviation * days
+
+	seen := make(map[string]bool)
+	for _, room := range report.TimePerRoom {
+		seen[room.Location] = true
+		expected := baseline.roomMinutes[room.Location]
+		if expected == 0 {
+			if room.Minutes >= minDeviation {
+				anomalies = append(anomalies, Anomaly{
+					Kind:        "new_room",
+					Subject:     room.Location,
+					Description: fmt.Sprintf("%s was used for %.0f minutes but not in the previous %d periods", room.Location, room.Minutes, reportBaselinePeriods),
+					Actual:      room.Minutes,
+				})
+			}
+			continue
+		}
+		if deviation := math.Abs(room.Minutes - expected); deviation >= minDeviation && deviation >= anomalyRoomDeviation*expected {
+			direction := "more"
+			if room.Minutes < expected {
+				direction = "less"
+			}
+			anomalies = append(anomalies, Anomaly{
+				Kind:        "room_time",
+				Subject:     room.Location,
+				Description: fmt.Sprintf("%.0f minutes %s than usual in %s", deviation, direction, room.Location),
+				Actual:      room.Minutes,
+				Expected:    math.Round(expected),
+			})
+		}
+	}
+	for location, expected := range baseline.roomMinutes {
+		if !seen[location] && expected >= minDeviation {
+			anomalies = append(anomalies, Anomaly{
+				Kind:        "room_time",
+				Subject:     location,
+				Description: fmt.Sprintf("%s was not used (usually %.0f minutes)", location, expected),
+				Expected:    math.Round(expected),
+			})
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, routine := range report.Routines {
+		counts[routine.PatternType] = routine.Count
+	}
+	for patternType, expected := range baseline.routineCounts {
+		if expected >= 1 && counts[patternType] == 0 {
+			anomalies = append(anomalies, Anomaly{
+				Kind:        "missing_routine",
+				Subject:     patternType,
+				Description: fmt.Sprintf("%s did not occur (usually %.1f times)", patternType, expected),
+				Expected:    expected,
+			})
+		}
+	}
+
+	if baseline.sleepMinutes > 0 && report.Sleep.Nights > 0 &&
+		report.Sleep.AvgMinutes < (1-anomalySleepShortfall)*baseline.sleepMinutes {
+		anomalies = append(anomalies, Anomaly{
+			Kind:        "short_sleep",
+			Subject:     sleepPatternType,
+			Description: fmt.Sprintf("Average sleep of %.0f minutes is below the usual %.0f", report.Sleep.AvgMinutes, baseline.sleepMinutes),
+			Actual:      report.Sleep.AvgMinutes,
+			Expected:    baseline.sleepMinutes,
+		})
+	}
+
+	anomalies = append(anomalies, manualRoutineAnomalies(manual)...)
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].Kind != anomalies[j].Kind {
+			return anomalies[i].Kind < anomalies[j].Kind
+		}
+		return anomalies[i].Subject < anomalies[j].Subject
+	})
+	return anomalies
+}
+
+// renderReportHTML renders a report as a standalone HTML page
+func renderReportHTML(report *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// handleReports serves GET /api/reports?period=daily|weekly&date=ddmmyyyy&format=json|html.
+// The date defaults to yesterday for daily and last week for weekly reports.
+func handleReports(pg postgres.Client, clk clock.Clock, tz *time.Location, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		period := params.Get("period")
+		if period == "" {
+			period = "daily"
+		}
+
+		date := clk.Now().In(tz).AddDate(0, 0, -1)
+		if period == "weekly" {
+			date = clk.Now().In(tz).AddDate(0, 0, -7)
+		}
+		if v := params.Get("date"); v != "" {
+			parsed, err := parseDateToMidnight(v, tz)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid date: %v", err), http.StatusBadRequest)
+				return
+			}
+			date = parsed
+		}
+
+		start, end, err := reportRange(period, date)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		report, err := generateReport(r.Context(), pg, period, start, end)
+		if err != nil {
+			logger.Error("Failed to generate report", "period", period, "start", start, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		switch params.Get("format") {
+		case "", "json":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(report)
+		case "html":
+			page, err := renderReportHTML(report)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(page)
+		default:
+			http.Error(w, "format must be json or html", http.StatusBadRequest)
+		}
+	}
+}
+
+// reportPublisher generates reports on schedule and pushes them via MQTT and
+// e-mail; anomalies also go to webhooks
+type reportPublisher struct {
+	cfg      *config.Config
+	pg       postgres.Client
+	mqtt     mqtt.Client
+	notifier *notify.Notifier
+	tz       *time.Location
+	logger   *slog.Logger
+}
+
+func newReportPublisher(cfg *config.Config, pg postgres.Client, mqttClient mqtt.Client, notifier *notify.Notifier, tz *time.Location, logger *slog.Logger) *reportPublisher {
+	return &reportPublisher{
+		cfg:      cfg,
+		pg:       pg,
+		mqtt:     mqttClient,
+		notifier: notifier,
+		tz:       tz,
+		logger:   logger.With("component", "report_publisher"),
+	}
+}
+
+// Start publishes the previous day's report every day at cfg.ReportHour, and the
+// previous week's report on Mondays
+func (p *reportPublisher) Start(ctx context.Context) {
+	p.logger.Info("Starting report publisher",
+		"hour", p.cfg.ReportHour,
+		"email_recipients", len(p.cfg.ReportEmailTo))
+
+	for {
+		next := nextReportTime(time.Now().In(p.tz), p.cfg.ReportHour)
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		yesterday := next.AddDate(0, 0, -1)
+		p.publish(ctx, "daily", yesterday)
+		if next.Weekday() == time.Monday {
+			p.publish(ctx, "weekly", yesterday)
+		}
+	}
+}
+
+// nextReportTime returns the next occurrence of hour:00 after now
+func nextReportTime(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func (p *reportPublisher) publish(ctx context.Context, period string, date time.Time) {
+	start, end, err := reportRange(period, date)
+	if err != nil {
+		p.logger.Error("Invalid report period", "period", period, "error", err)
+		return
+	}
+
+	report, err := generateReport(ctx, p.pg, period, start, end)
+	if err != nil {
+		p.logger.Error("Failed to generate report", "period", period, "start", start, "error", err)
+		return
+	}
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		p.logger.Error("Failed to marshal report", "error", err)
+		return
+	}
+
+	topic := fmt.Sprintf("automation/behavior/report/%s", period)
+	if err := p.mqtt.Publish(topic, 1, true, payload); err != nil {
+		p.logger.Error("Failed to publish report", "topic", topic, "error", err)
+	}
+
+	for _, anomaly := range report.Anomalies {
+		p.notifier.Notify(notify.EventAnomalyDetected, map[string]interface{}{
+			"period":       period,
+			"period_start": start.Format(time.RFC3339),
+			"kind":         anomaly.Kind,
+			"subject":      anomaly.Subject,
+			"description":  anomaly.Description,
+			"actual":       anomaly.Actual,
+			"expected":     anomaly.Expected,
+		})
+	}
+
+	if len(p.cfg.ReportEmailTo) > 0 && p.cfg.SMTPHost != "" {
+		if err := p.email(report); err != nil {
+			p.logger.Error("Failed to e-mail report", "period", period, "error", err)
+		}
+	}
+
+	p.logger.Info("Published report",
+		"period", period,
+		"start", start,
+		"anomalies", len(report.Anomalies))
+}
+
+func (p *reportPublisher) email(report *Report) error {
+	body, err := renderReportHTML(report)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("J.E.E.V.E.S. %s report for %s", report.Period, report.Start.Format("2006-01-02"))
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", p.cfg.SMTPFrom)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(p.cfg.ReportEmailTo, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	msg.Write(body)
+
+	var auth smtp.Auth
+	if p.cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", p.cfg.SMTPUser, p.cfg.SMTPPassword, p.cfg.SMTPHost)
+	}
+
+	addr := fmt.Sprintf("%s:%d", p.cfg.SMTPHost, p.cfg.SMTPPort)
+	if err := smtp.SendMail(addr, auth, p.cfg.SMTPFrom, p.cfg.ReportEmailTo, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package observer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/postgres"
+)
+
+// defaultStatsDays is the lookback when no range is given
+const defaultStatsDays = 7
+
+// EpisodeCounts are episode counts and durations for one bucket
+type EpisodeCounts struct {
+	Macro           int     `json:"macro"`
+	Micro           int     `json:"micro"`
+	MacroMinutes    float64 `json:"macro_minutes"`
+	MicroMinutes    float64 `json:"micro_minutes"`
+	AvgMacroMinutes float64 `json:"avg_macro_minutes"`
+	AvgMicroMinutes float64 `json:"avg_micro_minutes"`
+}
+
+// DayStats are the counts of one local calendar day
+type DayStats struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	EpisodeCounts
+}
+
+// LocationStats are the counts of one room; a macro-episode counts for every
+// room it spans
+type LocationStats struct {
+	Location string `json:"location"`
+	EpisodeCounts
+}
+
+// PatternTypeStats are the counts of one pattern type (macro-episodes) or
+// trigger type (micro-episodes), as filtered by /api/episodes?pattern_type=
+type PatternTypeStats struct {
+	PatternType string `json:"pattern_type"`
+	EpisodeCounts
+}
+
+// EpisodeStats summarizes the episodes started in a range
+type EpisodeStats struct {
+	From           time.Time          `json:"from"`
+	To             time.Time          `json:"to"`
+	Totals         EpisodeCounts      `json:"totals"`
+	Standalone     int                `json:"standalone"`     // micro-episodes in no macro-episode
+	MacroCoverage  float64            `json:"macro_coverage"` // % of micro-episodes inside a macro-episode
+	PerDay         []DayStats         `json:"per_day"`
+	PerLocation    []LocationStats    `json:"per_location"`
+	PerPatternType []PatternTypeStats `json:"per_pattern_type"`
+}
+
+// statsEpisode is the part of an episode the stats are computed from
+type statsEpisode struct {
+	macro       bool
+	patternType string
+	start       time.Time
+	minutes     float64
+	locations   []string
+	inMacro     bool // micro-episodes only
+}
+
+// handleEpisodeStats serves GET /api/episodes/stats?from=ddmmyyyy&to=ddmmyyyy
+// (to inclusive, defaults to the last seven days) with the same location
+// filter as /api/episodes
+func handleEpisodeStats(pg postgres.Client, clk clock.Clock, tz *time.Location, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		now := clk.Now().In(tz)
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tz).AddDate(0, 0, 1)
+		from := to.AddDate(0, 0, -defaultStatsDays)
+
+		if v := params.Get("from"); v != "" {
+			parsed, err := parseDateToMidnight(v, tz)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid from date: %v", err), http.StatusBadRequest)
+				return
+			}
+			from = parsed
+		}
+		if v := params.Get("to"); v != "" {
+			parsed, err := parseDateToMidnight(v, tz)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid to date: %v", err), http.StatusBadRequest)
+				return
+			}
+			to = parsed.AddDate(0, 0, 1)
+		}
+		if !from.Before(to) {
+			http.Error(w, "from must be before to", http.StatusBadRequest)
+			return
+		}
+
+		episodes, err := loadStatsEpisodes(r.Context(), pg, from, to, params.Get("location"))
+		if err != nil {
+			logger.Error("Failed to compute episode stats", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(aggregateEpisodeStats(episodes, from, to, tz))
+	}
+}
+
+// loadStatsEpisodes reads the start, duration, rooms and type of the macro-
+// and micro-episodes started in [from, to), without their documents
+func loadStatsEpisodes(ctx context.Context, pg postgres.Client, from, to time.Time, location string) ([]statsEpisode, error) {
+	query := `
+		SELECT
+			FALSE,
+			COALESCE(b.jsonld->>'jeeves:triggerType', 'occupancy_transition'),
+			b.started_at_text::timestamptz,
+			EXTRACT(EPOCH FROM (COALESCE(b.ended_at_text::timestamptz, NOW()) - b.started_at_text::timestamptz))/60,
+			array_to_json(ARRAY[b.location])::text,
+			EXISTS (SELECT 1 FROM macro_episodes m WHERE b.id = ANY(m.micro_episode_ids))
+		FROM behavioral_episodes b
+		WHERE b.started_at_text::timestamptz >= $1
+		  AND b.started_at_text::timestamptz < $2
+		  AND ($3::text = '' OR b.location = $3::text)
+
+		UNION ALL
+
+		SELECT
+			TRUE,
+			pattern_type,
+			start_time,
+			duration_minutes,
+			array_to_json(locations)::text,
+			FALSE
+		FROM macro_episodes
+		WHERE start_time >= $1
+		  AND start_time < $2
+		  AND ($3::text = '' OR $3::text = ANY(locations))
+	`
+
+	rows, err := pg.Query(ctx, query, from, to, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query episodes: %w", err)
+	}
+	defer rows.Close()
+
+	var episodes []statsEpisode
+	for rows.Next() {
+		var ep statsEpisode
+		var locationsJSON string
+		if err := rows.Scan(&ep.macro, &ep.patternType, &ep.start, &ep.minutes, &locationsJSON, &ep.inMacro); err != nil {
+			return nil, fmt.Errorf("failed to scan episode: %w", err)
+		}
+		json.Unmarshal([]byte(locationsJSON), &ep.locations)
+		episodes = append(episodes, ep)
+	}
+	return episodes, rows.Err()
+}
+
+// aggregateEpisodeStats buckets episodes by local start day, room and
+// pattern type. Every day of the range is listed, empty days included.
+func aggregateEpisodeStats(episodes []statsEpisode, from, to time.Time, tz *time.Location) *EpisodeStats {
+	stats := &EpisodeStats{
+		From:           from,
+		To:             to,
+		PerDay:         []DayStats{},
+		PerLocation:    []LocationStats{},
+		PerPatternType: []PatternTypeStats{},
+	}
+
+	days := make(map[string]*EpisodeCounts)
+	var dayOrder []string
+	for d := from.In(tz); d.Before(to); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		days[key] = &EpisodeCounts{}
+		dayOrder = append(dayOrder, key)
+	}
+	locations := make(map[string]*EpisodeCounts)
+	patternTypes := make(map[string]*EpisodeCounts)
+
+	bucket := func(m map[string]*EpisodeCounts, key string) *EpisodeCounts {
+		if m[key] == nil {
+			m[key] = &EpisodeCounts{}
+		}
+		return m[key]
+	}
+
+	inMacro := 0
+	for _, ep := range episodes {
+		targets := []*EpisodeCounts{&stats.Totals, bucket(patternTypes, ep.patternType)}
+		if day, ok := days[ep.start.In(tz).Format("2006-01-02")]; ok {
+			targets = append(targets, day)
+		}
+		for _, location := range ep.locations {
+			targets = append(targets, bucket(locations, location))
+		}
+		for _, counts := range targets {
+			counts.add(ep)
+		}
+
+		if !ep.macro && ep.inMacro {
+			inMacro++
+		}
+	}
+
+	stats.Standalone = stats.Totals.Micro - inMacro
+	if stats.Totals.Micro > 0 {
+		stats.MacroCoverage = 100 * float64(inMacro) / float64(stats.Totals.Micro)
+	}
+	stats.Totals.average()
+
+	for _, key := range dayOrder {
+		days[key].average()
+		stats.PerDay = append(stats.PerDay, DayStats{Date: key, EpisodeCounts: *days[key]})
+	}
+	for _, location := range sortedKeys(locations) {
+		locations[location].average()
+		stats.PerLocation = append(stats.PerLocation, LocationStats{Location: location, EpisodeCounts: *locations[location]})
+	}
+	for _, patternType := range sortedKeys(patternTypes) {
+		patternTypes[patternType].average()
+		stats.PerPatternType = append(stats.PerPatternType, PatternTypeStats{PatternType: patternType, EpisodeCounts: *patternTypes[patternType]})
+	}
+
+	return stats
+}
+
+func (c *EpisodeCounts) add(ep statsEpisode) {
+	if ep.macro {
+		c.Macro++
+		c.MacroMinutes += ep.minutes
+	} else {
+		c.Micro++
+		c.MicroMinutes += ep.minutes
+	}
+}
+
+func (c *EpisodeCounts) average() {
+	if c.Macro > 0 {
+		c.AvgMacroMinutes = c.MacroMinutes / float64(c.Macro)
+	}
+	if c.Micro > 0 {
+		c.AvgMicroMinutes = c.MicroMinutes / float64(c.Micro)
+	}
+}
+
+func sortedKeys(m map[string]*EpisodeCounts) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
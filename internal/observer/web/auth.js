@@ -0,0 +1,13 @@
+// Observer API access: when the server requires a token, ask for it once and
+// keep it in a cookie so API requests and the /ws live stream carry it.
+async function authFetch(url, options) {
+    let response = await fetch(url, options);
+    if (response.status === 401) {
+        const token = prompt('Observer API token:');
+        if (token) {
+            document.cookie = `jeeves_token=${encodeURIComponent(token)}; path=/; SameSite=Strict`;
+            response = await fetch(url, options);
+        }
+    }
+    return response;
+}
@@ -5,7 +5,8 @@ import (
 	"time"
 )
 
-// TemporalAbstraction represents a multi-scale semantic interpretation of motion data
+// TemporalAbstraction represents a multi-scale semantic interpretation of motion data,
+// optionally fused with other sensors in the location
 type TemporalAbstraction struct {
 	CurrentState struct {
 		MinutesSinceLastMotion float64 `json:"minutes_since_last_motion"`
@@ -28,6 +29,11 @@ type TemporalAbstraction struct {
 	EnvironmentalSignals struct {
 		TimeOfDay string `json:"time_of_day"`
 	} `json:"environmental_signals"`
+
+	SensorSignals *SensorSignals `json:"sensor_signals,omitempty"`
+
+	// DecayProfile is the location's motion timeout profile (zero = default)
+	DecayProfile DecayProfile `json:"decay_profile"`
 }
 
 // DataProvider interface abstracts data access for testability
@@ -104,5 +110,12 @@ func GenerateTemporalAbstraction(
 	// Environmental signals
 	abstraction.EnvironmentalSignals.TimeOfDay = GetTimeOfDay(analysisTime)
 
+	// Non-motion sensors, when the provider supports them
+	if signalProvider, ok := dataProvider.(SignalProvider); ok {
+		if signals, err := signalProvider.GetSensorSignals(ctx, location, analysisTime); err == nil {
+			abstraction.SensorSignals = signals
+		}
+	}
+
 	return abstraction, nil
 }
@@ -2,14 +2,17 @@ package occupancy
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/metrics"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/privacy"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
 
@@ -20,6 +23,12 @@ type Agent struct {
 	storage *Storage
 	cfg     *config.Config
 	logger  *slog.Logger
+	metrics *metrics.Writer // nil unless VictoriaMetrics forwarding is enabled
+	decay   map[string]DecayProfile
+	labels  *LabelStore // set in training mode
+	house   *HouseAggregator
+	clock   *clock.TimeManager
+	privacy *privacy.Zones // locations analyzed without the LLM or metrics
 
 	// Periodic analysis
 	ticker   *time.Ticker
@@ -28,7 +37,14 @@ type Agent struct {
 
 // NewAgent creates a new occupancy agent
 func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Config, logger *slog.Logger) *Agent {
-	storage := NewStorage(redisClient, cfg, logger)
+	clk := clock.NewTimeManager(logger)
+	storage := NewStorage(redisClient, cfg, logger, clk)
+
+	decay, err := ParseDecayProfiles(cfg.OccupancyDecayProfiles)
+	if err != nil {
+		logger.Warn("Ignoring occupancy decay profiles", "error", err)
+		decay = nil
+	}
 
 	return &Agent{
 		mqtt:     mqttClient,
@@ -36,10 +52,20 @@ func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Conf
 		storage:  storage,
 		cfg:      cfg,
 		logger:   logger,
+		metrics:  metrics.NewFromConfig(cfg, logger),
+		decay:    decay,
+		house:    NewHouseAggregator(mqttClient, logger, clk),
+		clock:    clk,
+		privacy:  privacy.NewZones(cfg),
 		stopChan: make(chan struct{}),
 	}
 }
 
+// SetLabelStore enables training mode, recording ground-truth labels to store
+func (a *Agent) SetLabelStore(store *LabelStore) {
+	a.labels = store
+}
+
 // Start starts the occupancy agent and begins processing
 func (a *Agent) Start(ctx context.Context) error {
 	a.logger.Info("Starting occupancy agent",
@@ -56,6 +82,14 @@ func (a *Agent) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to ping Redis: %w", err)
 	}
 
+	// Follow the test runner's virtual time
+	if err := a.clock.ConfigureFromMQTT(a.mqtt); err != nil {
+		a.logger.Warn("Failed to subscribe to time config", "error", err)
+	}
+
+	// Publish household presence from the stored room states
+	a.house.Seed(ctx, a.storage)
+
 	// Subscribe to motion trigger topics
 	triggerTopic := "automation/sensor/motion/+"
 	if err := a.mqtt.Subscribe(triggerTopic, 0, a.handleTrigger); err != nil {
@@ -64,6 +98,19 @@ func (a *Agent) Start(ctx context.Context) error {
 
 	a.logger.Info("Subscribed to trigger topic", "topic", triggerTopic)
 
+	// Training mode: record ground-truth labels
+	if a.labels != nil {
+		labelTopic := fmt.Sprintf(LabelTopic, "+")
+		if err := a.mqtt.Subscribe(labelTopic, 0, a.handleLabel); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", labelTopic, err)
+		}
+		a.logger.Info("Training mode enabled, recording occupancy labels", "topic", labelTopic)
+	}
+
+	// Forward occupancy state to VictoriaMetrics (no-op when disabled)
+	a.metrics.Start(ctx)
+	go mqtt.ReportDeliveryMetrics(ctx, a.mqtt, a.metrics, a.cfg.ServiceName)
+
 	// Start periodic analysis
 	a.startPeriodicAnalysis()
 
@@ -145,7 +192,7 @@ func (a *Agent) performPeriodicAnalysis() {
 		}
 
 		// Check rate limiting (skip if analyzed < 25s ago)
-		if state.LastAnalysis != nil && time.Since(*state.LastAnalysis) < 25*time.Second {
+		if state.LastAnalysis != nil && a.clock.Since(*state.LastAnalysis) < 25*time.Second {
 			a.logger.Debug("Skipping recently analyzed location",
 				"location", location,
 				"reason", "analyzed_less_than_25s_ago")
@@ -180,7 +227,7 @@ func (a *Agent) handleTrigger(msg mqtt.Message) {
 	ctx := context.Background()
 
 	// Check for recent motion (< 2 minutes)
-	now := time.Now()
+	now := a.clock.Now()
 	recentMotionCount, err := a.storage.GetMotionCountInWindow(ctx, location, now.Add(-Window2Min), now)
 	if err != nil {
 		a.logger.Warn("Failed to check recent motion", "location", location, "error", err)
@@ -229,18 +276,21 @@ func (a *Agent) handleTrigger(msg mqtt.Message) {
 		}
 
 		// Update occupancy
-		if err := a.storage.UpdateOccupancy(ctx, location, result.Occupied); err != nil {
+		transition := DefaultHysteresis.Next(StateUnknown, nil, result, StabilizationResult{}, now)
+		if err := a.storage.UpdateState(ctx, location, transition.State); err != nil {
 			a.logger.Error("Failed to update occupancy", "location", location, "error", err)
 			return
 		}
 
 		// Publish context
 		minutesSince, _ := a.storage.GetMinutesSinceLastMotion(ctx, location, now)
-		if err := a.publishContext(location, result, "initial_motion", minutesSince, recentMotionCount, 0); err != nil {
+		if err := a.publishContext(location, result, transition, "initial_motion", minutesSince, recentMotionCount, 0); err != nil {
 			a.logger.Error("Failed to publish context", "location", location, "error", err)
 			return
 		}
 
+		a.house.Update(location, transition.State)
+
 		a.logger.Info("Fast path occupancy published",
 			"location", location,
 			"occupied", result.Occupied,
@@ -256,7 +306,7 @@ func (a *Agent) handleTrigger(msg mqtt.Message) {
 
 // analyzeLocation performs complete occupancy analysis for a location
 func (a *Agent) analyzeLocation(ctx context.Context, location string, method string) {
-	now := time.Now()
+	now := a.clock.Now()
 
 	a.logger.Info("analyzeLocation: STARTING analysis",
 		"location", location,
@@ -271,6 +321,7 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, method str
 			"error", err)
 		return
 	}
+	abstraction.DecayProfile = a.decay[location]
 
 	// Get temporal state
 	state, err := a.storage.GetTemporalState(ctx, location)
@@ -288,8 +339,13 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, method str
 		"should_dampen", stabilization.ShouldDampen,
 		"recommendation", stabilization.Recommendation)
 
-	// Analyze with LLM (with fallback)
-	result := AnalyzeWithFallback(ctx, location, abstraction, stabilization, a.cfg, a.logger)
+	// Analyze with LLM (with fallback); privacy zones never reach the LLM
+	var result AnalysisResult
+	if a.privacy.ExcludesLocation(location) {
+		result = FallbackAnalysis(abstraction, stabilization)
+	} else {
+		result = AnalyzeWithFallback(ctx, location, abstraction, stabilization, a.cfg, a.logger)
+	}
 
 	a.logger.Info("analyzeLocation: Analysis complete",
 		"location", location,
@@ -297,22 +353,19 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, method str
 		"confidence", result.Confidence,
 		"reasoning", result.Reasoning)
 
-	// Apply gates
-	shouldUpdate := ShouldUpdateOccupancy(
-		state.CurrentOccupancy,
-		state.LastStateChange,
-		result,
-		stabilization,
-	)
+	// Advance the occupancy state machine
+	transition := DefaultHysteresis.Next(state.State, state.LastStateChange, result, stabilization, now)
 
-	a.logger.Info("analyzeLocation: Gate decision",
+	a.logger.Info("analyzeLocation: State machine decision",
 		"location", location,
-		"should_update", shouldUpdate,
-		"current_occupancy", state.CurrentOccupancy,
+		"state", transition.State,
+		"previous_state", transition.PreviousState,
+		"changed", transition.Changed,
+		"publish", transition.Publish,
 		"new_occupied", result.Occupied,
 		"confidence", result.Confidence)
 
-	if shouldUpdate {
+	if transition.Publish {
 		// Create prediction record
 		prediction := PredictionRecord{
 			Timestamp:            now,
@@ -327,10 +380,12 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, method str
 			a.logger.Warn("Failed to add prediction to history", "location", location, "error", err)
 		}
 
-		// Update occupancy state
-		if err := a.storage.UpdateOccupancy(ctx, location, result.Occupied); err != nil {
-			a.logger.Error("Failed to update occupancy", "location", location, "error", err)
-			return
+		// Record the transition
+		if transition.Changed {
+			if err := a.storage.UpdateState(ctx, location, transition.State); err != nil {
+				a.logger.Error("Failed to update occupancy", "location", location, "error", err)
+				return
+			}
 		}
 
 		// Publish context message
@@ -338,22 +393,28 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, method str
 		motion2Min := abstraction.MotionDensity.Last2Min
 		motion8Min := abstraction.MotionDensity.Last8Min
 
-		if err := a.publishContext(location, result, method, minutesSince, motion2Min, motion8Min); err != nil {
+		if err := a.publishContext(location, result, transition, method, minutesSince, motion2Min, motion8Min); err != nil {
 			a.logger.Error("Failed to publish context", "location", location, "error", err)
 			return
 		}
 
+		if transition.Changed {
+			a.house.Update(location, transition.State)
+		}
+
 		a.logger.Info("Occupancy analysis published",
 			"location", location,
-			"occupied", result.Occupied,
+			"state", transition.State,
+			"previous_state", transition.PreviousState,
+			"transition_reason", transition.Reason,
 			"confidence", result.Confidence,
 			"method", method)
 	} else {
-		a.logger.Debug("Occupancy update blocked by gates",
+		a.logger.Debug("Occupancy update held by state machine",
 			"location", location,
+			"state", transition.State,
 			"occupied", result.Occupied,
 			"confidence", result.Confidence,
-			"current_occupancy", state.CurrentOccupancy,
 			"stabilization_dampening", stabilization.ShouldDampen)
 	}
 }
@@ -362,51 +423,64 @@ func (a *Agent) analyzeLocation(ctx context.Context, location string, method str
 func (a *Agent) publishContext(
 	location string,
 	result AnalysisResult,
+	transition StateTransition,
 	method string,
 	minutesSinceMotion float64,
 	motion2Min int,
 	motion8Min int,
 ) error {
-	// Determine state string
-	state := "empty"
-	if result.Occupied {
-		state = "occupied"
-	}
+	state := string(transition.State)
+	occupied := transition.State != StateEmpty // likely_empty is still occupied until confirmed
 
 	// Build message string
 	message := fmt.Sprintf("Room is %s (confidence: %.2f)", state, result.Confidence)
 
 	// Build context message
-	contextMsg := map[string]interface{}{
-		"source":    "temporal-occupancy-agent",
-		"type":      "occupancy",
-		"location":  location,
-		"state":     state,
-		"message":   message,
-		"data": map[string]interface{}{
-			"occupied":             result.Occupied,
-			"confidence":           result.Confidence,
-			"reasoning":            result.Reasoning,
-			"method":               method,
-			"minutes_since_motion": minutesSinceMotion,
-			"motion_last_2min":     motion2Min,
-			"motion_last_8min":     motion8Min,
+	contextMsg := events.OccupancyState{
+		Source:   "temporal-occupancy-agent",
+		Type:     "occupancy",
+		Location: location,
+		State:    state,
+		Message:  message,
+		Data: events.OccupancyData{
+			Occupied:           occupied,
+			Confidence:         result.Confidence,
+			Reasoning:          result.Reasoning,
+			Method:             method,
+			PreviousState:      string(transition.PreviousState),
+			TransitionReason:   transition.Reason,
+			MinutesSinceMotion: minutesSinceMotion,
+			MotionLast2Min:     motion2Min,
+			MotionLast8Min:     motion8Min,
 		},
-		"timestamp": time.Now().Format(time.RFC3339),
+		Timestamp: a.clock.Now().Format(time.RFC3339),
 	}
 
 	// Serialize to JSON
-	payload, err := json.Marshal(contextMsg)
+	payload, err := events.Marshal(events.ProducerOccupancy, contextMsg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal context message: %w", err)
 	}
 
 	// Publish to MQTT
-	topic := fmt.Sprintf("automation/context/occupancy/%s", location)
+	topic := contextMsg.Topic()
 	if err := a.mqtt.Publish(topic, 0, false, payload); err != nil {
 		return fmt.Errorf("failed to publish to MQTT: %w", err)
 	}
 
+	if !a.privacy.ExcludesLocation(location) {
+		occupiedValue := 0
+		if occupied {
+			occupiedValue = 1
+		}
+		a.metrics.Write(metrics.Point{
+			Measurement: "occupancy",
+			Tags:        map[string]string{"location": location, "method": method},
+			Fields:      map[string]interface{}{"occupied": occupiedValue, "confidence": result.Confidence},
+			Time:        a.clock.Now(),
+		})
+	}
+
 	a.logger.Debug("Published context message",
 		"topic", topic,
 		"state", state,
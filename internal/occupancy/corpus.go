@@ -0,0 +1,258 @@
+package occupancy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+// CorpusCase is one labeled temporal abstraction of an evaluation corpus,
+// stored as a JSON file
+type CorpusCase struct {
+	Name             string              `json:"name"` // defaults to the file name
+	Description      string              `json:"description,omitempty"`
+	Location         string              `json:"location"`
+	ExpectedOccupied bool                `json:"expected_occupied"`
+	MinConfidence    float64             `json:"min_confidence,omitempty"` // a correct answer below this counts as wrong
+	History          []PredictionRecord  `json:"history,omitempty"`        // earlier predictions, for stabilization
+	Abstraction      TemporalAbstraction `json:"abstraction"`
+}
+
+// LoadCorpus reads every *.json case in dir, ordered by file name
+func LoadCorpus(dir string) ([]CorpusCase, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list corpus: %w", err)
+	}
+	sort.Strings(paths)
+
+	cases := make([]CorpusCase, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read corpus case: %w", err)
+		}
+		var c CorpusCase
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse corpus case %s: %w", filepath.Base(path), err)
+		}
+		if c.Name == "" {
+			c.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		if c.Location == "" {
+			c.Location = "corpus"
+		}
+		cases = append(cases, c)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no corpus cases in %s", dir)
+	}
+	return cases, nil
+}
+
+// Analyzer is an occupancy analysis under evaluation
+type Analyzer func(ctx context.Context, location string, abstraction *TemporalAbstraction, stabilization StabilizationResult) (AnalysisResult, error)
+
+// LLMAnalyzer evaluates AnalyzeWithLLM with the configured endpoint and model
+func LLMAnalyzer(cfg *config.Config, logger *slog.Logger) Analyzer {
+	return func(ctx context.Context, location string, abstraction *TemporalAbstraction, stabilization StabilizationResult) (AnalysisResult, error) {
+		return AnalyzeWithLLM(ctx, location, abstraction, stabilization, cfg, logger)
+	}
+}
+
+// CaseVerdict is one analyzer's answer to a corpus case
+type CaseVerdict struct {
+	Occupied   bool    `json:"occupied"`
+	Confidence float64 `json:"confidence"`
+	Reasoning  string  `json:"reasoning"`
+	Correct    bool    `json:"correct"`
+}
+
+// CaseResult compares the LLM and fallback answers to a case's label
+type CaseResult struct {
+	Name             string       `json:"name"`
+	ExpectedOccupied bool         `json:"expected_occupied"`
+	LLM              *CaseVerdict `json:"llm,omitempty"` // nil when the LLM failed or was not evaluated
+	LLMError         string       `json:"llm_error,omitempty"`
+	Fallback         CaseVerdict  `json:"fallback"`
+}
+
+// ModeAccuracy summarises one analyzer over the corpus
+type ModeAccuracy struct {
+	Answered      int `json:"answered"`
+	Correct       int `json:"correct"`
+	FalseEmpty    int `json:"false_empty"`
+	FalseOccupied int `json:"false_occupied"`
+}
+
+// Accuracy is the share of correct answers, over cases answered
+func (m ModeAccuracy) Accuracy() float64 {
+	return ratio(m.Correct, m.Answered)
+}
+
+func (m *ModeAccuracy) add(v CaseVerdict, expected bool) {
+	m.Answered++
+	switch {
+	case v.Correct:
+		m.Correct++
+	case expected && !v.Occupied:
+		m.FalseEmpty++
+	case !expected && v.Occupied:
+		m.FalseOccupied++
+	}
+}
+
+// CorpusReport is the result of evaluating a corpus
+type CorpusReport struct {
+	Cases     []CaseResult `json:"cases"`
+	LLM       ModeAccuracy `json:"llm"`
+	LLMErrors int          `json:"llm_errors"`
+	Fallback  ModeAccuracy `json:"fallback"`
+}
+
+// EvaluateCorpus runs every case through llm and FallbackAnalysis. A nil llm
+// evaluates the fallback only.
+func EvaluateCorpus(ctx context.Context, cases []CorpusCase, llm Analyzer) *CorpusReport {
+	report := &CorpusReport{Cases: make([]CaseResult, 0, len(cases))}
+
+	for _, c := range cases {
+		abstraction := c.Abstraction
+		stabilization := ComputeVonichHakimStabilization(c.History)
+		result := CaseResult{Name: c.Name, ExpectedOccupied: c.ExpectedOccupied}
+
+		result.Fallback = c.verdict(FallbackAnalysis(&abstraction, stabilization))
+		report.Fallback.add(result.Fallback, c.ExpectedOccupied)
+
+		if llm != nil {
+			analysis, err := llm(ctx, c.Location, &abstraction, stabilization)
+			if err != nil {
+				result.LLMError = err.Error()
+				report.LLMErrors++
+			} else {
+				verdict := c.verdict(analysis)
+				result.LLM = &verdict
+				report.LLM.add(verdict, c.ExpectedOccupied)
+			}
+		}
+
+		report.Cases = append(report.Cases, result)
+	}
+
+	return report
+}
+
+func (c CorpusCase) verdict(result AnalysisResult) CaseVerdict {
+	return CaseVerdict{
+		Occupied:   result.Occupied,
+		Confidence: result.Confidence,
+		Reasoning:  result.Reasoning,
+		Correct:    result.Occupied == c.ExpectedOccupied && result.Confidence >= c.MinConfidence,
+	}
+}
+
+// Regression is a case an analyzer got right in the baseline and wrong now
+type Regression struct {
+	Case     string `json:"case"`
+	Analyzer string `json:"analyzer"` // "llm" or "fallback"
+}
+
+// Regressions compares the report against an earlier one. Cases missing from
+// either report, or not answered by the LLM in both, are not compared.
+func (r *CorpusReport) Regressions(baseline *CorpusReport) []Regression {
+	previous := make(map[string]CaseResult, len(baseline.Cases))
+	for _, c := range baseline.Cases {
+		previous[c.Name] = c
+	}
+
+	var regressions []Regression
+	for _, c := range r.Cases {
+		before, ok := previous[c.Name]
+		if !ok {
+			continue
+		}
+		if before.Fallback.Correct && !c.Fallback.Correct {
+			regressions = append(regressions, Regression{Case: c.Name, Analyzer: "fallback"})
+		}
+		if before.LLM != nil && c.LLM != nil && before.LLM.Correct && !c.LLM.Correct {
+			regressions = append(regressions, Regression{Case: c.Name, Analyzer: "llm"})
+		}
+	}
+	return regressions
+}
+
+// ReadCorpusReport loads a report written by WriteCorpusReportJSON
+func ReadCorpusReport(path string) (*CorpusReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus report: %w", err)
+	}
+	var report CorpusReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse corpus report: %w", err)
+	}
+	return &report, nil
+}
+
+// WriteCorpusReportJSON saves a report as a baseline for later runs
+func WriteCorpusReportJSON(path string, report *CorpusReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal corpus report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write corpus report: %w", err)
+	}
+	return nil
+}
+
+// WriteCorpusReport prints each case's LLM and fallback answers and the
+// accuracy of both
+func WriteCorpusReport(w io.Writer, report *CorpusReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tEXPECTED\tLLM\tFALLBACK")
+	for _, c := range report.Cases {
+		llm := "-"
+		switch {
+		case c.LLM != nil:
+			llm = formatVerdict(*c.LLM)
+		case c.LLMError != "":
+			llm = "error"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Name, occupiedWord(c.ExpectedOccupied), llm, formatVerdict(c.Fallback))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "ANALYZER\tACCURACY\tFALSE EMPTY/OCC\tERRORS")
+	if report.LLM.Answered > 0 || report.LLMErrors > 0 {
+		fmt.Fprintf(tw, "llm\t%.0f%% (%d/%d)\t%d/%d\t%d\n",
+			report.LLM.Accuracy()*100, report.LLM.Correct, report.LLM.Answered,
+			report.LLM.FalseEmpty, report.LLM.FalseOccupied, report.LLMErrors)
+	}
+	fmt.Fprintf(tw, "fallback\t%.0f%% (%d/%d)\t%d/%d\t0\n",
+		report.Fallback.Accuracy()*100, report.Fallback.Correct, report.Fallback.Answered,
+		report.Fallback.FalseEmpty, report.Fallback.FalseOccupied)
+	return tw.Flush()
+}
+
+func formatVerdict(v CaseVerdict) string {
+	mark := "ok"
+	if !v.Correct {
+		mark = "WRONG"
+	}
+	return fmt.Sprintf("%s %.2f %s", occupiedWord(v.Occupied), v.Confidence, mark)
+}
+
+func occupiedWord(occupied bool) string {
+	if occupied {
+		return "occupied"
+	}
+	return "empty"
+}
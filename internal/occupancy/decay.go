@@ -0,0 +1,71 @@
+package occupancy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DecayProfile sets how quickly a location is considered empty after motion
+// stops. Rooms where people sit still (study, living room) decay slowly;
+// rooms people pass through (bathroom, hallway) decay fast.
+type DecayProfile struct {
+	RecentMinutes float64 `json:"recent_minutes"` // below this since motion: still present
+	AbsentMinutes float64 `json:"absent_minutes"` // from this: extended absence
+	EmptyMinutes  float64 `json:"empty_minutes"`  // from this: clearly empty
+}
+
+// Named decay profiles usable in JEEVES_OCCUPANCY_DECAY_PROFILES
+var decayPresets = map[string]DecayProfile{
+	"fast":    {RecentMinutes: 2, AbsentMinutes: 4, EmptyMinutes: 8},
+	"default": {RecentMinutes: 5, AbsentMinutes: 10, EmptyMinutes: 15},
+	"slow":    {RecentMinutes: 15, AbsentMinutes: 25, EmptyMinutes: 40},
+}
+
+// DefaultDecayProfile is used for locations without a configured profile
+var DefaultDecayProfile = decayPresets["default"]
+
+// orDefault returns the default profile for an unset (zero) profile
+func (p DecayProfile) orDefault() DecayProfile {
+	if p == (DecayProfile{}) {
+		return DefaultDecayProfile
+	}
+	return p
+}
+
+// ParseDecayProfiles parses location=profile entries, where profile is a
+// preset name (fast, default, slow) or recent:absent:empty minutes, e.g.
+// "bathroom=fast", "study=15:25:40"
+func ParseDecayProfiles(entries []string) (map[string]DecayProfile, error) {
+	profiles := make(map[string]DecayProfile, len(entries))
+	for _, entry := range entries {
+		location, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		location, spec = strings.TrimSpace(location), strings.TrimSpace(spec)
+		if !ok || location == "" || spec == "" {
+			return nil, fmt.Errorf("invalid decay profile %q, expected location=profile", entry)
+		}
+
+		if preset, ok := decayPresets[spec]; ok {
+			profiles[location] = preset
+			continue
+		}
+
+		parts := strings.Split(spec, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("decay profile %q: expected a preset or recent:absent:empty minutes", entry)
+		}
+		var minutes [3]float64
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || v <= 0 {
+				return nil, fmt.Errorf("decay profile %q: invalid minutes %q", entry, part)
+			}
+			minutes[i] = v
+		}
+		if minutes[0] > minutes[1] || minutes[1] > minutes[2] {
+			return nil, fmt.Errorf("decay profile %q: minutes must not decrease", entry)
+		}
+		profiles[location] = DecayProfile{RecentMinutes: minutes[0], AbsentMinutes: minutes[1], EmptyMinutes: minutes[2]}
+	}
+	return profiles, nil
+}
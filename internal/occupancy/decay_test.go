@@ -0,0 +1,58 @@
+package occupancy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDecayProfiles(t *testing.T) {
+	profiles, err := ParseDecayProfiles([]string{"bathroom=fast", " study = 15:25:40 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profiles["bathroom"] != decayPresets["fast"] {
+		t.Errorf("bathroom = %+v, want fast preset", profiles["bathroom"])
+	}
+	if want := (DecayProfile{RecentMinutes: 15, AbsentMinutes: 25, EmptyMinutes: 40}); profiles["study"] != want {
+		t.Errorf("study = %+v, want %+v", profiles["study"], want)
+	}
+
+	for _, bad := range []string{"bathroom", "=fast", "bathroom=quick", "study=15:25", "study=15:x:40", "study=20:10:30", "study=0:1:2"} {
+		if _, err := ParseDecayProfiles([]string{bad}); err == nil {
+			t.Errorf("ParseDecayProfiles(%q): expected error", bad)
+		}
+	}
+}
+
+func TestFallbackAnalysis_DecayProfiles(t *testing.T) {
+	// Single motion 7 minutes ago
+	abstraction := &TemporalAbstraction{}
+	abstraction.CurrentState.MinutesSinceLastMotion = 7.0
+	abstraction.MotionDensity.Last8Min = 1
+
+	result := FallbackAnalysis(abstraction, StabilizationResult{})
+	if result.Occupied || !strings.Contains(result.Reasoning, "pass-through") {
+		t.Errorf("default profile: expected pass-through, got %+v", result)
+	}
+
+	abstraction.DecayProfile = decayPresets["slow"]
+	result = FallbackAnalysis(abstraction, StabilizationResult{})
+	if !result.Occupied {
+		t.Errorf("slow profile: expected room still occupied, got %+v", result)
+	}
+
+	abstraction.DecayProfile = decayPresets["fast"]
+	result = FallbackAnalysis(abstraction, StabilizationResult{})
+	if result.Occupied || result.Confidence != 0.8 {
+		t.Errorf("fast profile: expected extended absence, got %+v", result)
+	}
+}
+
+func TestBuildLLMPrompt_DecayProfile(t *testing.T) {
+	abstraction := &TemporalAbstraction{DecayProfile: decayPresets["slow"]}
+	prompt := buildLLMPrompt("study", abstraction, StabilizationResult{})
+
+	if !strings.Contains(prompt, "still present within 15 min") || !strings.Contains(prompt, "No motion for 25+ minutes") {
+		t.Errorf("prompt does not use the room's decay profile:\n%s", prompt)
+	}
+}
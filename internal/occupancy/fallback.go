@@ -10,6 +10,7 @@ func FallbackAnalysis(abstraction *TemporalAbstraction, stabilization Stabilizat
 	minutesSinceMotion := abstraction.CurrentState.MinutesSinceLastMotion
 	motion2Min := abstraction.MotionDensity.Last2Min
 	motion8Min := abstraction.MotionDensity.Last8Min
+	profile := abstraction.DecayProfile.orDefault()
 
 	// Pattern 1: Active Presence (motion in last 2 minutes)
 	if motion2Min > 0 {
@@ -24,8 +25,13 @@ func FallbackAnalysis(abstraction *TemporalAbstraction, stabilization Stabilizat
 		}
 	}
 
-	// Pattern 2: Recent Motion (less than 5 minutes since last motion)
-	if minutesSinceMotion < 5.0 {
+	// Other sensors override the motion-only patterns when their evidence is strong
+	if result, ok := fusedAnalysis(abstraction.SensorSignals, minutesSinceMotion, profile, stabilization); ok {
+		return result
+	}
+
+	// Pattern 2: Recent Motion (within the profile's recent window)
+	if minutesSinceMotion < profile.RecentMinutes {
 		// Check if settling in (multiple recent motions, now quiet)
 		if motion8Min >= 3 {
 			reasoning := fmt.Sprintf("Multiple motions in recent past (%d in 2-8min), now quiet (%.1f min since) - person likely settled (working/reading)", motion8Min, minutesSinceMotion)
@@ -51,8 +57,8 @@ func FallbackAnalysis(abstraction *TemporalAbstraction, stabilization Stabilizat
 		}
 	}
 
-	// Pattern 3: Pass-Through (single motion 5-10 minutes ago)
-	if minutesSinceMotion >= 5.0 && minutesSinceMotion < 10.0 {
+	// Pattern 3: Pass-Through (single motion between the recent and absent thresholds)
+	if minutesSinceMotion >= profile.RecentMinutes && minutesSinceMotion < profile.AbsentMinutes {
 		totalRecent := motion2Min + motion8Min
 		if totalRecent <= 1 {
 			reasoning := fmt.Sprintf("Single motion event %.1f minutes ago - pass-through detected", minutesSinceMotion)
@@ -78,14 +84,14 @@ func FallbackAnalysis(abstraction *TemporalAbstraction, stabilization Stabilizat
 		}
 	}
 
-	// Pattern 4: Extended Absence (10+ minutes)
-	if minutesSinceMotion >= 10.0 {
+	// Pattern 4: Extended Absence (past the absent threshold)
+	if minutesSinceMotion >= profile.AbsentMinutes {
 		reasoning := fmt.Sprintf("No motion for %.1f minutes - extended absence", minutesSinceMotion)
 		if stabilization.ShouldDampen {
 			reasoning += fmt.Sprintf(" (V-H stabilization: %s)", stabilization.Recommendation)
 		}
 		confidence := 0.8
-		if minutesSinceMotion >= 15.0 {
+		if minutesSinceMotion >= profile.EmptyMinutes {
 			confidence = 0.9
 			reasoning = fmt.Sprintf("No motion for %.1f minutes - room clearly empty", minutesSinceMotion)
 			if stabilization.ShouldDampen {
@@ -110,3 +116,38 @@ func FallbackAnalysis(abstraction *TemporalAbstraction, stabilization Stabilizat
 		Reasoning:  reasoning,
 	}
 }
+
+// fusedAnalysis decides from non-motion signals once motion has been quiet
+// for 2+ minutes: strong positive evidence keeps a still person (watching TV,
+// at a desk) occupied, strong negative evidence (presence radar reporting
+// nobody) clears the room before the motion timeouts would
+func fusedAnalysis(signals *SensorSignals, minutesSinceMotion float64, profile DecayProfile, stabilization StabilizationResult) (AnalysisResult, bool) {
+	if signals == nil {
+		return AnalysisResult{}, false
+	}
+
+	var result AnalysisResult
+	switch {
+	case signals.Evidence >= fusedOccupiedThreshold && minutesSinceMotion >= profile.RecentMinutes:
+		result = AnalysisResult{
+			Occupied:   true,
+			Confidence: 0.6 + 0.3*signals.Evidence,
+			Reasoning: fmt.Sprintf("No motion for %.1f min but other sensors indicate presence (%s) - person likely still",
+				minutesSinceMotion, signals.describe()),
+		}
+	case signals.Evidence <= fusedEmptyThreshold:
+		result = AnalysisResult{
+			Occupied:   false,
+			Confidence: 0.6 - 0.3*signals.Evidence,
+			Reasoning: fmt.Sprintf("No motion for %.1f min and other sensors indicate nobody (%s)",
+				minutesSinceMotion, signals.describe()),
+		}
+	default:
+		return AnalysisResult{}, false
+	}
+
+	if stabilization.ShouldDampen {
+		result.Reasoning += fmt.Sprintf(" (V-H stabilization: %s)", stabilization.Recommendation)
+	}
+	return result, true
+}
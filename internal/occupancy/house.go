@@ -0,0 +1,130 @@
+package occupancy
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// HousePresenceTopic carries the retained household-level presence summary
+const HousePresenceTopic = "automation/presence/house"
+
+// HousePresence summarises per-room occupancy for the whole house
+type HousePresence struct {
+	AnyoneHome    bool      `json:"anyone_home"`
+	ActiveRooms   int       `json:"active_rooms"`
+	OccupiedRooms []string  `json:"occupied_rooms"` // occupied or likely_empty, sorted
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// HouseAggregator derives household presence from room states and publishes
+// it whenever the set of occupied rooms changes
+type HouseAggregator struct {
+	mqtt   mqtt.Client
+	logger *slog.Logger
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	rooms     map[string]OccupancyState
+	published *HousePresence
+}
+
+// NewHouseAggregator creates an aggregator publishing on mqttClient
+func NewHouseAggregator(mqttClient mqtt.Client, logger *slog.Logger, clk clock.Clock) *HouseAggregator {
+	return &HouseAggregator{
+		mqtt:   mqttClient,
+		logger: logger.With("component", "house_presence"),
+		clock:  clk,
+		rooms:  make(map[string]OccupancyState),
+	}
+}
+
+// Seed loads the stored state of every known location, then publishes the summary
+func (h *HouseAggregator) Seed(ctx context.Context, storage *Storage) {
+	locations, err := storage.GetAllLocations(ctx)
+	if err != nil {
+		h.logger.Warn("Failed to load locations for house presence", "error", err)
+		return
+	}
+
+	h.mu.Lock()
+	for _, location := range locations {
+		if state, err := storage.GetTemporalState(ctx, location); err == nil && state.State != StateUnknown {
+			h.rooms[location] = state.State
+		}
+	}
+	h.mu.Unlock()
+
+	h.publishIfChanged()
+}
+
+// Update records a room's state and publishes the summary if it changed
+func (h *HouseAggregator) Update(location string, state OccupancyState) {
+	h.mu.Lock()
+	h.rooms[location] = state
+	h.mu.Unlock()
+
+	h.publishIfChanged()
+}
+
+// Summary returns the current household presence
+func (h *HouseAggregator) Summary() HousePresence {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.summaryLocked()
+}
+
+func (h *HouseAggregator) summaryLocked() HousePresence {
+	occupied := make([]string, 0, len(h.rooms))
+	for location, state := range h.rooms {
+		if state == StateOccupied || state == StateLikelyEmpty {
+			occupied = append(occupied, location)
+		}
+	}
+	sort.Strings(occupied)
+
+	return HousePresence{
+		AnyoneHome:    len(occupied) > 0,
+		ActiveRooms:   len(occupied),
+		OccupiedRooms: occupied,
+		Timestamp:     h.clock.Now(),
+	}
+}
+
+func (h *HouseAggregator) publishIfChanged() {
+	h.mu.Lock()
+	summary := h.summaryLocked()
+	if h.published != nil && slices.Equal(h.published.OccupiedRooms, summary.OccupiedRooms) {
+		h.mu.Unlock()
+		return
+	}
+	previous := h.published
+	h.published = &summary
+	h.mu.Unlock()
+
+	payload, err := events.Marshal(events.ProducerOccupancy, summary)
+	if err != nil {
+		h.logger.Error("Failed to marshal house presence", "error", err)
+		return
+	}
+	if err := h.mqtt.Publish(HousePresenceTopic, 0, true, payload); err != nil {
+		h.logger.Error("Failed to publish house presence", "error", err)
+		// Retry on the next update
+		h.mu.Lock()
+		h.published = previous
+		h.mu.Unlock()
+		return
+	}
+
+	h.logger.Info("House presence updated",
+		"anyone_home", summary.AnyoneHome,
+		"active_rooms", summary.ActiveRooms,
+		"occupied_rooms", summary.OccupiedRooms)
+}
@@ -0,0 +1,88 @@
+package occupancy
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
+	"github.com/saaga0h/jeeves-platform/pkg/events"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// recordingMQTT keeps every published payload
+type recordingMQTT struct {
+	payloads [][]byte
+	fail     bool
+}
+
+func (r *recordingMQTT) Connect(ctx context.Context) error { return nil }
+func (r *recordingMQTT) Disconnect()                       {}
+func (r *recordingMQTT) IsConnected() bool                 { return true }
+func (r *recordingMQTT) Subscribe(topic string, qos byte, handler mqtt.MessageHandler) error {
+	return nil
+}
+func (r *recordingMQTT) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	if r.fail {
+		return errors.New("broker unavailable")
+	}
+	r.payloads = append(r.payloads, payload)
+	return nil
+}
+
+func (r *recordingMQTT) last(t *testing.T) HousePresence {
+	t.Helper()
+	var presence HousePresence
+	envelope, err := events.Unmarshal(r.payloads[len(r.payloads)-1], &presence)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Producer != events.ProducerOccupancy {
+		t.Errorf("expected an occupancy-agent envelope, got producer %q", envelope.Producer)
+	}
+	return presence
+}
+
+func TestHouseAggregator(t *testing.T) {
+	client := &recordingMQTT{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	house := NewHouseAggregator(client, logger, clock.Real{})
+
+	house.Update("kitchen", StateOccupied)
+	house.Update("study", StateLikelyEmpty)
+	if len(client.payloads) != 2 {
+		t.Fatalf("expected 2 publishes, got %d", len(client.payloads))
+	}
+	if p := client.last(t); !p.AnyoneHome || p.ActiveRooms != 2 || p.OccupiedRooms[0] != "kitchen" {
+		t.Errorf("unexpected presence %+v", p)
+	}
+
+	// likely_empty → occupied keeps the same set of rooms; nothing to publish
+	house.Update("study", StateOccupied)
+	house.Update("hallway", StateEmpty)
+	if len(client.payloads) != 2 {
+		t.Errorf("expected no publish for unchanged rooms, got %d publishes", len(client.payloads))
+	}
+
+	house.Update("kitchen", StateEmpty)
+	house.Update("study", StateEmpty)
+	if p := client.last(t); p.AnyoneHome || p.ActiveRooms != 0 {
+		t.Errorf("expected empty house, got %+v", p)
+	}
+}
+
+func TestHouseAggregator_RetriesFailedPublish(t *testing.T) {
+	client := &recordingMQTT{fail: true}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	house := NewHouseAggregator(client, logger, clock.Real{})
+
+	house.Update("kitchen", StateOccupied)
+	client.fail = false
+	house.Update("kitchen", StateOccupied)
+
+	if len(client.payloads) != 1 || !client.last(t).AnyoneHome {
+		t.Errorf("expected the failed summary to be published on the next update, got %d publishes", len(client.payloads))
+	}
+}
@@ -0,0 +1,257 @@
+package occupancy
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// LabelTopic receives ground-truth labels in training mode. The payload is
+// "occupied"/"empty" or {"occupied": true, "author": "..."}.
+const LabelTopic = "automation/occupancy/label/%s"
+
+// LabelRecord pairs a ground-truth label with what the analyzers concluded
+type LabelRecord struct {
+	Location       string
+	LabeledAt      time.Time
+	ActualOccupied bool
+	Author         string
+	Abstraction    *TemporalAbstraction
+	LLM            *AnalysisResult // nil when the LLM was unavailable
+	Fallback       AnalysisResult
+	PublishedState OccupancyState
+}
+
+// LabelStore persists ground-truth labels in Postgres
+type LabelStore struct {
+	db *sql.DB
+}
+
+// NewLabelStore creates a label store on db
+func NewLabelStore(db *sql.DB) *LabelStore {
+	return &LabelStore{db: db}
+}
+
+// Save stores a label record
+func (s *LabelStore) Save(ctx context.Context, r LabelRecord) error {
+	abstraction, err := json.Marshal(r.Abstraction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal abstraction: %w", err)
+	}
+
+	var llmOccupied, llmConfidence, llmReasoning, publishedState interface{}
+	if r.LLM != nil {
+		llmOccupied, llmConfidence, llmReasoning = r.LLM.Occupied, r.LLM.Confidence, r.LLM.Reasoning
+	}
+	if r.PublishedState != StateUnknown {
+		publishedState = string(r.PublishedState)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO occupancy_labels (
+			location, labeled_at, actual_occupied, author, abstraction,
+			llm_occupied, llm_confidence, llm_reasoning,
+			fallback_occupied, fallback_confidence, fallback_reasoning,
+			published_state
+		) VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11, $12)`,
+		r.Location, r.LabeledAt, r.ActualOccupied, r.Author, abstraction,
+		llmOccupied, llmConfidence, llmReasoning,
+		r.Fallback.Occupied, r.Fallback.Confidence, r.Fallback.Reasoning,
+		publishedState,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert occupancy label: %w", err)
+	}
+	return nil
+}
+
+// RoomAccuracy summarises labels for one location. False empties (room
+// reported empty while someone was there) suggest a slower decay profile;
+// false occupancies suggest a faster one.
+type RoomAccuracy struct {
+	Location string `json:"location"`
+	Labels   int    `json:"labels"`
+
+	LLMAnswered      int `json:"llm_answered"`
+	LLMCorrect       int `json:"llm_correct"`
+	LLMFalseEmpty    int `json:"llm_false_empty"`
+	LLMFalseOccupied int `json:"llm_false_occupied"`
+
+	FallbackCorrect       int `json:"fallback_correct"`
+	FallbackFalseEmpty    int `json:"fallback_false_empty"`
+	FallbackFalseOccupied int `json:"fallback_false_occupied"`
+
+	PublishedLabels  int `json:"published_labels"`
+	PublishedCorrect int `json:"published_correct"`
+}
+
+// LLMAccuracy is the share of correct LLM answers, over labels it answered
+func (r RoomAccuracy) LLMAccuracy() float64 {
+	return ratio(r.LLMCorrect, r.LLMAnswered)
+}
+
+// FallbackAccuracy is the share of correct fallback answers
+func (r RoomAccuracy) FallbackAccuracy() float64 {
+	return ratio(r.FallbackCorrect, r.Labels)
+}
+
+// PublishedAccuracy is the share of labels where the published state
+// (likely_empty counting as occupied) matched
+func (r RoomAccuracy) PublishedAccuracy() float64 {
+	return ratio(r.PublishedCorrect, r.PublishedLabels)
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// Report returns per-location accuracy for labels since the given time
+func (s *LabelStore) Report(ctx context.Context, since time.Time) ([]RoomAccuracy, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT location,
+			COUNT(*),
+			COUNT(llm_occupied),
+			COUNT(*) FILTER (WHERE llm_occupied = actual_occupied),
+			COUNT(*) FILTER (WHERE NOT llm_occupied AND actual_occupied),
+			COUNT(*) FILTER (WHERE llm_occupied AND NOT actual_occupied),
+			COUNT(*) FILTER (WHERE fallback_occupied = actual_occupied),
+			COUNT(*) FILTER (WHERE NOT fallback_occupied AND actual_occupied),
+			COUNT(*) FILTER (WHERE fallback_occupied AND NOT actual_occupied),
+			COUNT(published_state),
+			COUNT(*) FILTER (WHERE (published_state <> 'empty') = actual_occupied)
+		FROM occupancy_labels
+		WHERE labeled_at >= $1
+		GROUP BY location
+		ORDER BY location`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query occupancy labels: %w", err)
+	}
+	defer rows.Close()
+
+	var report []RoomAccuracy
+	for rows.Next() {
+		var r RoomAccuracy
+		if err := rows.Scan(&r.Location, &r.Labels,
+			&r.LLMAnswered, &r.LLMCorrect, &r.LLMFalseEmpty, &r.LLMFalseOccupied,
+			&r.FallbackCorrect, &r.FallbackFalseEmpty, &r.FallbackFalseOccupied,
+			&r.PublishedLabels, &r.PublishedCorrect); err != nil {
+			return nil, fmt.Errorf("failed to scan occupancy label stats: %w", err)
+		}
+		report = append(report, r)
+	}
+	return report, rows.Err()
+}
+
+// WriteLabelReport prints a per-room comparison of LLM and fallback accuracy
+func WriteLabelReport(w io.Writer, report []RoomAccuracy) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOCATION\tLABELS\tLLM\tLLM FALSE EMPTY/OCC\tFALLBACK\tFALLBACK FALSE EMPTY/OCC\tPUBLISHED")
+	for _, r := range report {
+		llm := "-"
+		if r.LLMAnswered > 0 {
+			llm = fmt.Sprintf("%.0f%% (%d)", r.LLMAccuracy()*100, r.LLMAnswered)
+		}
+		published := "-"
+		if r.PublishedLabels > 0 {
+			published = fmt.Sprintf("%.0f%%", r.PublishedAccuracy()*100)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d/%d\t%.0f%%\t%d/%d\t%s\n",
+			r.Location, r.Labels,
+			llm, r.LLMFalseEmpty, r.LLMFalseOccupied,
+			r.FallbackAccuracy()*100, r.FallbackFalseEmpty, r.FallbackFalseOccupied,
+			published)
+	}
+	return tw.Flush()
+}
+
+// parseLabel reads a label payload
+func parseLabel(payload []byte) (occupied bool, author string, err error) {
+	text := strings.TrimSpace(string(payload))
+	switch strings.ToLower(text) {
+	case "occupied", "true", "on":
+		return true, "", nil
+	case "empty", "false", "off":
+		return false, "", nil
+	}
+
+	var label struct {
+		Occupied *bool  `json:"occupied"`
+		Author   string `json:"author"`
+	}
+	if err := json.Unmarshal(payload, &label); err != nil || label.Occupied == nil {
+		return false, "", fmt.Errorf("expected \"occupied\", \"empty\" or {\"occupied\": bool}, got %q", text)
+	}
+	return *label.Occupied, label.Author, nil
+}
+
+// handleLabel records what the analyzers conclude at the moment a user
+// labels a room's actual occupancy
+func (a *Agent) handleLabel(msg mqtt.Message) {
+	parts := strings.Split(msg.Topic(), "/")
+	location := parts[len(parts)-1]
+
+	occupied, author, err := parseLabel(msg.Payload())
+	if err != nil || location == "" {
+		a.logger.Warn("Invalid occupancy label", "topic", msg.Topic(), "error", err)
+		return
+	}
+
+	// Labels are stored for training, which privacy zones are excluded from
+	if a.privacy.ExcludesLocation(location) {
+		a.logger.Info("Ignoring occupancy label for privacy zone", "location", location)
+		return
+	}
+
+	ctx := context.Background()
+	now := a.clock.Now()
+
+	abstraction, err := GenerateTemporalAbstraction(ctx, location, a.storage, now)
+	if err != nil {
+		a.logger.Error("Failed to generate temporal abstraction for label", "location", location, "error", err)
+		return
+	}
+	abstraction.DecayProfile = a.decay[location]
+
+	state, err := a.storage.GetTemporalState(ctx, location)
+	if err != nil {
+		state = &TemporalState{}
+	}
+	stabilization := ComputeVonichHakimStabilization(state.PredictionHistory)
+
+	record := LabelRecord{
+		Location:       location,
+		LabeledAt:      now,
+		ActualOccupied: occupied,
+		Author:         author,
+		Abstraction:    abstraction,
+		Fallback:       FallbackAnalysis(abstraction, stabilization),
+		PublishedState: state.State,
+	}
+	if result, err := AnalyzeWithLLM(ctx, location, abstraction, stabilization, a.cfg, a.logger); err == nil {
+		record.LLM = &result
+	} else {
+		a.logger.Warn("LLM unavailable for labeled analysis", "location", location, "error", err)
+	}
+
+	if err := a.labels.Save(ctx, record); err != nil {
+		a.logger.Error("Failed to store occupancy label", "location", location, "error", err)
+		return
+	}
+
+	a.logger.Info("Recorded occupancy label",
+		"location", location,
+		"actual_occupied", occupied,
+		"fallback_occupied", record.Fallback.Occupied,
+		"llm_answered", record.LLM != nil,
+		"published_state", state.State)
+}
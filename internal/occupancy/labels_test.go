@@ -0,0 +1,65 @@
+package occupancy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseLabel(t *testing.T) {
+	tests := []struct {
+		payload  string
+		occupied bool
+		author   string
+	}{
+		{"occupied", true, ""},
+		{" EMPTY\n", false, ""},
+		{"true", true, ""},
+		{`{"occupied": false, "author": "sam"}`, false, "sam"},
+	}
+	for _, tt := range tests {
+		occupied, author, err := parseLabel([]byte(tt.payload))
+		if err != nil {
+			t.Errorf("parseLabel(%q): %v", tt.payload, err)
+			continue
+		}
+		if occupied != tt.occupied || author != tt.author {
+			t.Errorf("parseLabel(%q) = %v, %q, want %v, %q", tt.payload, occupied, author, tt.occupied, tt.author)
+		}
+	}
+
+	for _, bad := range []string{"", "maybe", `{"author": "sam"}`} {
+		if _, _, err := parseLabel([]byte(bad)); err == nil {
+			t.Errorf("parseLabel(%q): expected error", bad)
+		}
+	}
+}
+
+func TestWriteLabelReport(t *testing.T) {
+	report := []RoomAccuracy{
+		{
+			Location: "bathroom", Labels: 10,
+			LLMAnswered: 8, LLMCorrect: 6, LLMFalseEmpty: 0, LLMFalseOccupied: 2,
+			FallbackCorrect: 9, FallbackFalseOccupied: 1,
+			PublishedLabels: 10, PublishedCorrect: 9,
+		},
+		{Location: "study", Labels: 4, FallbackCorrect: 2, FallbackFalseEmpty: 2},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteLabelReport(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header and 2 rooms:\n%s", len(lines), buf.String())
+	}
+	for _, want := range []string{"bathroom", "75% (8)", "90%", "0/2"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("bathroom row missing %q: %s", want, lines[1])
+		}
+	}
+	if fields := strings.Fields(lines[2]); fields[2] != "-" || fields[len(fields)-1] != "-" {
+		t.Errorf("study row should show no LLM or published accuracy: %s", lines[2])
+	}
+}
@@ -133,6 +133,8 @@ func AnalyzeWithLLM(
 
 // buildLLMPrompt constructs the prompt for the LLM
 func buildLLMPrompt(location string, abstraction *TemporalAbstraction, stabilization StabilizationResult) string {
+	profile := abstraction.DecayProfile.orDefault()
+
 	prompt := fmt.Sprintf(`You are an occupancy detection system analyzing motion sensor data for location: %s
 
 CURRENT DATA:
@@ -142,6 +144,7 @@ CURRENT DATA:
 - Motion in 8-20 min window: %d events (%s)
 - Motion in 20-60 min window: %d events (%s)
 - Time of day: %s
+- Room decay profile: still present within %g min of motion, likely empty after %g min, clearly empty after %g min
 
 DECISION PATTERNS:
 
@@ -151,19 +154,19 @@ Pattern 1 - Active Presence:
 → Reasoning: Someone is currently moving
 
 Pattern 2 - Pass-Through:
-- Total 1-2 motion events, quiet for 5+ minutes
+- Total 1-2 motion events, quiet for %g+ minutes
 - Labels: single_motion, pass_through, brief_visit
 → Decision: EMPTY (confidence: 0.7-0.8)
 → Reasoning: Single motion event, person left
 
 Pattern 3 - Settling In:
-- Multiple motions (3+) in recent windows, now quiet < 8 min
+- Multiple motions (3+) in recent windows, now quiet < %g min
 - Labels: continuous_activity, periodic_motion in 2-8min, but no_motion in 0-2min
 → Decision: OCCUPIED (confidence: 0.6-0.8)
 → Reasoning: Person entered, now sitting still (working/reading)
 
 Pattern 4 - Extended Absence:
-- No motion for 10+ minutes
+- No motion for %g+ minutes
 - Labels: no_motion, empty, unused
 → Decision: EMPTY (confidence: 0.8-0.9)
 → Reasoning: Long time since any activity
@@ -176,8 +179,26 @@ Pattern 4 - Extended Absence:
 		abstraction.MotionDensity.Last20Min, abstraction.TemporalPatterns.Last20Min,
 		abstraction.MotionDensity.Last60Min, abstraction.TemporalPatterns.Last60Min,
 		abstraction.EnvironmentalSignals.TimeOfDay,
+		profile.RecentMinutes, profile.AbsentMinutes, profile.EmptyMinutes,
+		profile.RecentMinutes,
+		profile.RecentMinutes,
+		profile.AbsentMinutes,
 	)
 
+	// Add other sensors when the location has them
+	if signals := abstraction.SensorSignals; signals != nil {
+		prompt += fmt.Sprintf(`OTHER SENSORS:
+- %s
+- Weighted evidence: %.2f (-1 = clearly empty, +1 = clearly occupied)
+Motion sensors miss people sitting still. Strong evidence (above 0.5 or below -0.5)
+should outweigh quiet motion windows; weak evidence should not.
+
+`,
+			signals.describe(),
+			signals.Evidence,
+		)
+	}
+
 	// Add stabilization guidance if needed
 	if stabilization.ShouldDampen {
 		prompt += fmt.Sprintf(`
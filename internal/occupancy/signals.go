@@ -0,0 +1,136 @@
+package occupancy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+const (
+	// doorActivityWindow is how long after a door opens or closes it counts
+	// as evidence of someone entering or leaving
+	doorActivityWindow = 5 * time.Minute
+
+	// signalMaxAge ignores power and media readings older than this
+	signalMaxAge = Window60Min
+
+	// fusedOccupiedThreshold and fusedEmptyThreshold are the evidence levels
+	// at which non-motion signals override the motion-only decision
+	fusedOccupiedThreshold = 0.5
+	fusedEmptyThreshold    = -0.5
+)
+
+// SensorSignals holds the latest non-motion readings for a location. Nil
+// fields mean the location has no such sensor or the reading is stale.
+type SensorSignals struct {
+	PresenceDetected       *bool    `json:"presence_detected,omitempty"` // mmWave/BLE presence state
+	MinutesSinceDoorChange *float64 `json:"minutes_since_door_change,omitempty"`
+	PowerWatts             *float64 `json:"power_watts,omitempty"`
+	MediaState             string   `json:"media_state,omitempty"` // playing, paused, stopped
+
+	// Evidence is the weighted sum of the signals in [-1, 1]; positive
+	// values suggest the location is occupied
+	Evidence float64 `json:"evidence"`
+}
+
+// SignalProvider is implemented by data providers that can also read
+// non-motion sensors. Providers without it yield motion-only abstractions.
+type SignalProvider interface {
+	GetSensorSignals(ctx context.Context, location string, referenceTime time.Time) (*SensorSignals, error)
+}
+
+// SignalWeights sets how much each signal contributes to the fused evidence
+type SignalWeights struct {
+	Presence            float64
+	Door                float64
+	Power               float64
+	Media               float64
+	PowerThresholdWatts float64
+}
+
+// SignalWeightsFromConfig reads the fusion weights from cfg
+func SignalWeightsFromConfig(cfg *config.Config) SignalWeights {
+	return SignalWeights{
+		Presence:            cfg.OccupancyPresenceWeight,
+		Door:                cfg.OccupancyDoorWeight,
+		Power:               cfg.OccupancyPowerWeight,
+		Media:               cfg.OccupancyMediaWeight,
+		PowerThresholdWatts: cfg.OccupancyPowerThresholdWatt,
+	}
+}
+
+// Evidence combines the available signals into a score in [-1, 1]. Each
+// signal votes between -1 and 1 and is scaled by its weight; the sum is
+// normalised by the weights of the signals that are present.
+func (w SignalWeights) Evidence(s *SensorSignals) float64 {
+	if s == nil {
+		return 0
+	}
+
+	var score, total float64
+	vote := func(weight, v float64) {
+		if weight <= 0 {
+			return
+		}
+		score += weight * v
+		total += weight
+	}
+
+	if s.PresenceDetected != nil {
+		// A presence radar reporting nobody is as strong as one reporting someone
+		if *s.PresenceDetected {
+			vote(w.Presence, 1)
+		} else {
+			vote(w.Presence, -1)
+		}
+	}
+	if s.MinutesSinceDoorChange != nil && *s.MinutesSinceDoorChange <= doorActivityWindow.Minutes() {
+		vote(w.Door, 1)
+	}
+	if s.PowerWatts != nil {
+		if *s.PowerWatts >= w.PowerThresholdWatts {
+			vote(w.Power, 1)
+		} else {
+			vote(w.Power, 0)
+		}
+	}
+	switch s.MediaState {
+	case "playing":
+		vote(w.Media, 1)
+	case "paused":
+		vote(w.Media, 0.5)
+	case "":
+	default:
+		vote(w.Media, 0)
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return score / total
+}
+
+// describe summarises the signals for prompts and reasoning
+func (s *SensorSignals) describe() string {
+	var parts []string
+	if s.PresenceDetected != nil {
+		if *s.PresenceDetected {
+			parts = append(parts, "presence sensor detects someone")
+		} else {
+			parts = append(parts, "presence sensor reports nobody")
+		}
+	}
+	if s.MinutesSinceDoorChange != nil {
+		parts = append(parts, fmt.Sprintf("door changed %.1f min ago", *s.MinutesSinceDoorChange))
+	}
+	if s.PowerWatts != nil {
+		parts = append(parts, fmt.Sprintf("power draw %.0fW", *s.PowerWatts))
+	}
+	if s.MediaState != "" {
+		parts = append(parts, "media "+s.MediaState)
+	}
+	return strings.Join(parts, ", ")
+}
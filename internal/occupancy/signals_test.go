@@ -0,0 +1,103 @@
+package occupancy
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var testWeights = SignalWeights{Presence: 1.0, Door: 0.3, Power: 0.5, Media: 0.7, PowerThresholdWatts: 30}
+
+func TestSignalWeights_Evidence(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals *SensorSignals
+		want    float64
+	}{
+		{"nil signals", nil, 0},
+		{"no readings", &SensorSignals{}, 0},
+		{"presence detected", &SensorSignals{PresenceDetected: ptr(true)}, 1},
+		{"presence clear", &SensorSignals{PresenceDetected: ptr(false)}, -1},
+		{"media playing, power high", &SensorSignals{MediaState: "playing", PowerWatts: ptr(120.0)}, 1},
+		{"media stopped, power low", &SensorSignals{MediaState: "stopped", PowerWatts: ptr(5.0)}, 0},
+		{"media paused", &SensorSignals{MediaState: "paused"}, 0.5},
+		{"old door change ignored", &SensorSignals{MinutesSinceDoorChange: ptr(30.0)}, 0},
+		{"presence clear outweighs media", &SensorSignals{PresenceDetected: ptr(false), MediaState: "playing"}, (-1.0 + 0.7) / 1.7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testWeights.Evidence(tt.signals); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Evidence = %.3f, want %.3f", got, tt.want)
+			}
+		})
+	}
+
+	noPresence := testWeights
+	noPresence.Presence = 0
+	if got := noPresence.Evidence(&SensorSignals{PresenceDetected: ptr(false), MediaState: "playing"}); got != 1 {
+		t.Errorf("zero weight should disable the signal, Evidence = %.3f", got)
+	}
+}
+
+func TestFallbackAnalysis_FusedPresence(t *testing.T) {
+	abstraction := &TemporalAbstraction{}
+	abstraction.CurrentState.MinutesSinceLastMotion = 25.0
+	abstraction.SensorSignals = &SensorSignals{MediaState: "playing", PowerWatts: ptr(150.0)}
+	abstraction.SensorSignals.Evidence = testWeights.Evidence(abstraction.SensorSignals)
+
+	result := FallbackAnalysis(abstraction, StabilizationResult{})
+
+	if !result.Occupied {
+		t.Errorf("expected Occupied = true while media is playing, got reasoning: %s", result.Reasoning)
+	}
+	if !strings.Contains(result.Reasoning, "media playing") {
+		t.Errorf("expected reasoning to mention the media state, got: %s", result.Reasoning)
+	}
+}
+
+func TestFallbackAnalysis_FusedAbsence(t *testing.T) {
+	// Settling-in motion pattern, but the presence radar reports nobody
+	abstraction := &TemporalAbstraction{}
+	abstraction.CurrentState.MinutesSinceLastMotion = 3.0
+	abstraction.MotionDensity.Last8Min = 4
+	abstraction.SensorSignals = &SensorSignals{PresenceDetected: ptr(false)}
+	abstraction.SensorSignals.Evidence = testWeights.Evidence(abstraction.SensorSignals)
+
+	result := FallbackAnalysis(abstraction, StabilizationResult{})
+
+	if result.Occupied {
+		t.Errorf("expected Occupied = false, got reasoning: %s", result.Reasoning)
+	}
+	if math.Abs(result.Confidence-0.9) > 1e-9 {
+		t.Errorf("expected Confidence = 0.9, got %f", result.Confidence)
+	}
+}
+
+func TestFallbackAnalysis_WeakSignalsKeepMotionDecision(t *testing.T) {
+	abstraction := &TemporalAbstraction{}
+	abstraction.CurrentState.MinutesSinceLastMotion = 20.0
+	abstraction.SensorSignals = &SensorSignals{MediaState: "stopped", PowerWatts: ptr(4.0)}
+	abstraction.SensorSignals.Evidence = testWeights.Evidence(abstraction.SensorSignals)
+
+	result := FallbackAnalysis(abstraction, StabilizationResult{})
+
+	if result.Occupied || !strings.Contains(result.Reasoning, "room clearly empty") {
+		t.Errorf("expected motion-only extended absence, got %+v", result)
+	}
+}
+
+func TestBuildLLMPrompt_SensorSignals(t *testing.T) {
+	abstraction := &TemporalAbstraction{}
+	if strings.Contains(buildLLMPrompt("study", abstraction, StabilizationResult{}), "OTHER SENSORS") {
+		t.Error("motion-only prompt should not mention other sensors")
+	}
+
+	abstraction.SensorSignals = &SensorSignals{PresenceDetected: ptr(true), Evidence: 1}
+	prompt := buildLLMPrompt("study", abstraction, StabilizationResult{})
+	if !strings.Contains(prompt, "presence sensor detects someone") || !strings.Contains(prompt, "Weighted evidence: 1.00") {
+		t.Errorf("prompt missing sensor signals:\n%s", prompt)
+	}
+}
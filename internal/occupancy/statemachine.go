@@ -0,0 +1,133 @@
+package occupancy
+
+import (
+	"time"
+)
+
+// OccupancyState is a location's published occupancy state
+type OccupancyState string
+
+const (
+	StateUnknown     OccupancyState = ""
+	StateOccupied    OccupancyState = "occupied"
+	StateLikelyEmpty OccupancyState = "likely_empty" // absence suspected, not yet confirmed
+	StateEmpty       OccupancyState = "empty"
+)
+
+// Transition reasons published with state changes
+const (
+	ReasonInitial          = "initial_state"
+	ReasonPresenceDetected = "presence_detected"
+	ReasonPresenceResumed  = "presence_resumed"
+	ReasonAbsenceSuspected = "absence_suspected"
+	ReasonAbsenceConfirmed = "absence_confirmed"
+)
+
+// Hysteresis holds the thresholds of the occupancy state machine. Leaving a
+// state takes more confidence than returning to it, and each state must be
+// held for a minimum dwell time, so single borderline analyses cannot flip
+// the published state back and forth.
+type Hysteresis struct {
+	OccupyConfidence       float64 // empty → occupied
+	ReoccupyConfidence     float64 // likely_empty → occupied
+	VacateConfidence       float64 // occupied → likely_empty
+	ConfirmEmptyConfidence float64 // likely_empty → empty
+	MaintainConfidence     float64 // re-publish the current state
+
+	MinOccupiedDwell    time.Duration
+	MinLikelyEmptyDwell time.Duration
+	MinEmptyDwell       time.Duration
+}
+
+// DefaultHysteresis keeps the 0.6/0.3 confidence gates and 45s hold of the
+// previous two-state gating, and adds a 2 minute likely_empty stage
+var DefaultHysteresis = Hysteresis{
+	OccupyConfidence:       0.6,
+	ReoccupyConfidence:     0.4,
+	VacateConfidence:       0.6,
+	ConfirmEmptyConfidence: 0.6,
+	MaintainConfidence:     0.3,
+	MinOccupiedDwell:       45 * time.Second,
+	MinLikelyEmptyDwell:    2 * time.Minute,
+	MinEmptyDwell:          45 * time.Second,
+}
+
+// StateTransition is the outcome of feeding one analysis to the state machine
+type StateTransition struct {
+	State         OccupancyState
+	PreviousState OccupancyState
+	Changed       bool
+	Publish       bool   // state changed, or the analysis confirms the current state
+	Reason        string // transition reason when Changed
+}
+
+// Next applies an analysis result to the current state. lastChange is when
+// the current state was entered (nil = long ago). V-H stabilization raises
+// every threshold by its stabilization factor.
+func (h Hysteresis) Next(
+	current OccupancyState,
+	lastChange *time.Time,
+	result AnalysisResult,
+	stabilization StabilizationResult,
+	now time.Time,
+) StateTransition {
+	t := StateTransition{State: current, PreviousState: current}
+
+	if current == StateUnknown {
+		t.State = StateEmpty
+		if result.Occupied {
+			t.State = StateOccupied
+		}
+		t.Changed, t.Publish, t.Reason = true, true, ReasonInitial
+		return t
+	}
+
+	threshold := func(base float64) float64 {
+		if stabilization.ShouldDampen {
+			return base + stabilization.StabilizationFactor
+		}
+		return base
+	}
+	dwelled := func(min time.Duration) bool {
+		return lastChange == nil || now.Sub(*lastChange) >= min
+	}
+	move := func(to OccupancyState, reason string) StateTransition {
+		t.State, t.Changed, t.Publish, t.Reason = to, true, true, reason
+		return t
+	}
+
+	switch current {
+	case StateOccupied:
+		if !result.Occupied {
+			if result.Confidence >= threshold(h.VacateConfidence) && dwelled(h.MinOccupiedDwell) {
+				return move(StateLikelyEmpty, ReasonAbsenceSuspected)
+			}
+			return t
+		}
+
+	case StateLikelyEmpty:
+		if result.Occupied {
+			if result.Confidence >= threshold(h.ReoccupyConfidence) {
+				return move(StateOccupied, ReasonPresenceResumed)
+			}
+			return t
+		}
+		if result.Confidence >= threshold(h.ConfirmEmptyConfidence) && dwelled(h.MinLikelyEmptyDwell) {
+			return move(StateEmpty, ReasonAbsenceConfirmed)
+		}
+		// Still waiting for confirmation; nothing new to publish
+		return t
+
+	case StateEmpty:
+		if result.Occupied {
+			if result.Confidence >= threshold(h.OccupyConfidence) && dwelled(h.MinEmptyDwell) {
+				return move(StateOccupied, ReasonPresenceDetected)
+			}
+			return t
+		}
+	}
+
+	// The analysis agrees with the current state
+	t.Publish = result.Confidence >= threshold(h.MaintainConfidence)
+	return t
+}
@@ -0,0 +1,85 @@
+package occupancy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHysteresis_Initial(t *testing.T) {
+	now := time.Now()
+	tr := DefaultHysteresis.Next(StateUnknown, nil, AnalysisResult{Occupied: true, Confidence: 0.2}, StabilizationResult{}, now)
+	if tr.State != StateOccupied || !tr.Changed || !tr.Publish || tr.Reason != ReasonInitial {
+		t.Errorf("unexpected initial transition %+v", tr)
+	}
+}
+
+func TestHysteresis_OccupiedToEmptyPassesLikelyEmpty(t *testing.T) {
+	now := time.Now()
+	longAgo := now.Add(-10 * time.Minute)
+	empty := AnalysisResult{Occupied: false, Confidence: 0.8}
+
+	tr := DefaultHysteresis.Next(StateOccupied, &longAgo, empty, StabilizationResult{}, now)
+	if tr.State != StateLikelyEmpty || tr.PreviousState != StateOccupied || tr.Reason != ReasonAbsenceSuspected {
+		t.Fatalf("expected occupied → likely_empty, got %+v", tr)
+	}
+
+	// Confirmation must wait for the likely_empty dwell time
+	justNow := now.Add(-30 * time.Second)
+	tr = DefaultHysteresis.Next(StateLikelyEmpty, &justNow, empty, StabilizationResult{}, now)
+	if tr.Changed || tr.Publish {
+		t.Errorf("likely_empty confirmed before dwell time: %+v", tr)
+	}
+
+	tr = DefaultHysteresis.Next(StateLikelyEmpty, &longAgo, empty, StabilizationResult{}, now)
+	if tr.State != StateEmpty || tr.Reason != ReasonAbsenceConfirmed {
+		t.Errorf("expected likely_empty → empty, got %+v", tr)
+	}
+}
+
+func TestHysteresis_ReoccupyNeedsLessConfidence(t *testing.T) {
+	now := time.Now()
+	justNow := now.Add(-10 * time.Second)
+	weakOccupied := AnalysisResult{Occupied: true, Confidence: 0.45}
+
+	tr := DefaultHysteresis.Next(StateLikelyEmpty, &justNow, weakOccupied, StabilizationResult{}, now)
+	if tr.State != StateOccupied || tr.Reason != ReasonPresenceResumed {
+		t.Errorf("expected likely_empty → occupied, got %+v", tr)
+	}
+
+	longAgo := now.Add(-10 * time.Minute)
+	tr = DefaultHysteresis.Next(StateEmpty, &longAgo, weakOccupied, StabilizationResult{}, now)
+	if tr.Changed {
+		t.Errorf("empty → occupied should need %.1f confidence: %+v", DefaultHysteresis.OccupyConfidence, tr)
+	}
+}
+
+func TestHysteresis_MinimumDwell(t *testing.T) {
+	now := time.Now()
+	justNow := now.Add(-20 * time.Second)
+
+	tr := DefaultHysteresis.Next(StateOccupied, &justNow, AnalysisResult{Occupied: false, Confidence: 0.9}, StabilizationResult{}, now)
+	if tr.Changed {
+		t.Errorf("occupied left before minimum dwell: %+v", tr)
+	}
+
+	tr = DefaultHysteresis.Next(StateEmpty, &justNow, AnalysisResult{Occupied: true, Confidence: 0.9}, StabilizationResult{}, now)
+	if tr.Changed {
+		t.Errorf("empty left before minimum dwell: %+v", tr)
+	}
+}
+
+func TestHysteresis_MaintainAndStabilization(t *testing.T) {
+	now := time.Now()
+	longAgo := now.Add(-10 * time.Minute)
+
+	tr := DefaultHysteresis.Next(StateOccupied, &longAgo, AnalysisResult{Occupied: true, Confidence: 0.5}, StabilizationResult{}, now)
+	if tr.Changed || !tr.Publish {
+		t.Errorf("confirming analysis should re-publish without change: %+v", tr)
+	}
+
+	dampen := StabilizationResult{ShouldDampen: true, StabilizationFactor: 0.3}
+	tr = DefaultHysteresis.Next(StateOccupied, &longAgo, AnalysisResult{Occupied: false, Confidence: 0.8}, dampen, now)
+	if tr.Changed {
+		t.Errorf("stabilization should raise the vacate threshold to 0.9: %+v", tr)
+	}
+}
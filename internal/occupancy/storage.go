@@ -3,11 +3,13 @@ package occupancy
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"time"
 
+	"github.com/saaga0h/jeeves-platform/pkg/clock"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
@@ -17,14 +19,16 @@ type Storage struct {
 	redis  redis.Client
 	cfg    *config.Config
 	logger *slog.Logger
+	clock  clock.Clock
 }
 
 // NewStorage creates a new storage wrapper
-func NewStorage(redisClient redis.Client, cfg *config.Config, logger *slog.Logger) *Storage {
+func NewStorage(redisClient redis.Client, cfg *config.Config, logger *slog.Logger, clk clock.Clock) *Storage {
 	return &Storage{
 		redis:  redisClient,
 		cfg:    cfg,
 		logger: logger,
+		clock:  clk,
 	}
 }
 
@@ -72,6 +76,9 @@ func (s *Storage) GetMinutesSinceLastMotion(ctx context.Context, location string
 
 	// Get last motion time from metadata hash
 	lastMotionStr, err := s.redis.HGet(ctx, key, "lastMotionTime")
+	if err != nil && !errors.Is(err, redis.ErrNotFound) {
+		return 999.0, fmt.Errorf("failed to get last motion time: %w", err)
+	}
 	if err != nil {
 		// No motion history
 		s.logger.Debug("GetMinutesSinceLastMotion: no metadata found, returning 999.0",
@@ -141,6 +148,7 @@ func (s *Storage) HasMotionHistory(ctx context.Context, location string) bool {
 
 // TemporalState represents the current state of a location
 type TemporalState struct {
+	State            OccupancyState
 	CurrentOccupancy *bool
 	LastStateChange  *time.Time
 	LastAnalysis     *time.Time
@@ -172,6 +180,17 @@ func (s *Storage) GetTemporalState(ctx context.Context, location string) (*Tempo
 		}
 	}
 
+	// Parse state, deriving it from currentOccupancy for locations stored
+	// before the state machine
+	switch {
+	case fields["state"] != "":
+		state.State = OccupancyState(fields["state"])
+	case state.CurrentOccupancy != nil && *state.CurrentOccupancy:
+		state.State = StateOccupied
+	case state.CurrentOccupancy != nil:
+		state.State = StateEmpty
+	}
+
 	// Parse lastStateChange
 	if changeStr, ok := fields["lastStateChange"]; ok {
 		if changeMs, err := strconv.ParseInt(changeStr, 10, 64); err == nil {
@@ -206,17 +225,29 @@ func (s *Storage) UpdateOccupancy(ctx context.Context, location string, occupied
 	}
 
 	// Update lastStateChange
-	if err := s.redis.HSet(ctx, key, "lastStateChange", fmt.Sprintf("%d", time.Now().UnixMilli())); err != nil {
+	if err := s.redis.HSet(ctx, key, "lastStateChange", fmt.Sprintf("%d", s.clock.Now().UnixMilli())); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// UpdateState records a state machine transition. likely_empty still counts
+// as occupied for currentOccupancy.
+func (s *Storage) UpdateState(ctx context.Context, location string, newState OccupancyState) error {
+	key := fmt.Sprintf("temporal:%s", location)
+
+	if err := s.redis.HSet(ctx, key, "state", string(newState)); err != nil {
+		return err
+	}
+
+	return s.UpdateOccupancy(ctx, location, newState != StateEmpty)
+}
+
 // UpdateLastAnalysis updates the last analysis timestamp
 func (s *Storage) UpdateLastAnalysis(ctx context.Context, location string) error {
 	key := fmt.Sprintf("temporal:%s", location)
-	return s.redis.HSet(ctx, key, "lastAnalysis", time.Now().Format(time.RFC3339))
+	return s.redis.HSet(ctx, key, "lastAnalysis", s.clock.Now().Format(time.RFC3339))
 }
 
 // AddPredictionHistory adds a prediction to the history (FIFO, max 10)
@@ -272,3 +303,96 @@ func (s *Storage) GetPredictionHistory(ctx context.Context, location string) ([]
 
 	return predictions, nil
 }
+
+// GetSensorSignals reads the latest presence, door contact, power and media
+// readings for a location and fuses them with the configured weights.
+// Returns nil when the location has none of these sensors.
+func (s *Storage) GetSensorSignals(ctx context.Context, location string, referenceTime time.Time) (*SensorSignals, error) {
+	signals := &SensorSignals{}
+	found := false
+
+	// Presence sensors report changes, so the last state holds until the next one
+	var presence struct {
+		State string `json:"state"`
+	}
+	if s.latestSortedSetEntry(ctx, redis.PresenceSensorKey(location), referenceTime.Add(-24*time.Hour), referenceTime, &presence) {
+		if detected, ok := presenceState(presence.State); ok {
+			signals.PresenceDetected = &detected
+			found = true
+		}
+	}
+
+	var media struct {
+		State string `json:"state"`
+	}
+	if s.latestSortedSetEntry(ctx, fmt.Sprintf("sensor:media:%s", location), referenceTime.Add(-signalMaxAge), referenceTime, &media) && media.State != "" && media.State != "unknown" {
+		signals.MediaState = media.State
+		found = true
+	}
+
+	if _, collectedAt, ok := s.latestGenericEntry(ctx, "contact", location); ok {
+		minutes := referenceTime.Sub(time.UnixMilli(collectedAt)).Minutes()
+		if minutes >= 0 && minutes <= signalMaxAge.Minutes() {
+			signals.MinutesSinceDoorChange = &minutes
+			found = true
+		}
+	}
+
+	var power struct {
+		Watts *float64 `json:"watts"`
+	}
+	if s.latestSortedSetEntry(ctx, redis.PowerSensorKey(location), referenceTime.Add(-signalMaxAge), referenceTime, &power) && power.Watts != nil {
+		signals.PowerWatts = power.Watts
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+	signals.Evidence = SignalWeightsFromConfig(s.cfg).Evidence(signals)
+	return signals, nil
+}
+
+// latestSortedSetEntry decodes the newest member scored within [start, end]
+func (s *Storage) latestSortedSetEntry(ctx context.Context, key string, start, end time.Time, v interface{}) bool {
+	members, err := s.redis.ZRangeByScoreWithScores(ctx, key, float64(start.UnixMilli()), float64(end.UnixMilli()))
+	if err != nil || len(members) == 0 {
+		return false
+	}
+	if err := json.Unmarshal([]byte(members[len(members)-1].Member), v); err != nil {
+		s.logger.Debug("Failed to parse sensor entry", "key", key, "error", err)
+		return false
+	}
+	return true
+}
+
+// latestGenericEntry returns the data and collection time of the newest
+// reading of a sensor type the collector stores as a generic list
+func (s *Storage) latestGenericEntry(ctx context.Context, sensorType, location string) (map[string]interface{}, int64, bool) {
+	values, err := s.redis.LRange(ctx, redis.GenericSensorKey(sensorType, location), 0, 0)
+	if err != nil || len(values) == 0 {
+		return nil, 0, false
+	}
+
+	var entry struct {
+		Data        map[string]interface{} `json:"data"`
+		CollectedAt int64                  `json:"collected_at"`
+	}
+	if err := json.Unmarshal([]byte(values[0]), &entry); err != nil {
+		s.logger.Debug("Failed to parse sensor entry", "sensor_type", sensorType, "location", location, "error", err)
+		return nil, 0, false
+	}
+	return entry.Data, entry.CollectedAt, true
+}
+
+// presenceState maps presence sensor states to detected/not detected
+func presenceState(state string) (detected bool, ok bool) {
+	switch state {
+	case "present", "detected", "occupied", "on", "home":
+		return true, true
+	case "absent", "clear", "not_present", "off", "away":
+		return false, true
+	default:
+		return false, false
+	}
+}
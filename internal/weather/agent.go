@@ -0,0 +1,152 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// ContextTopic carries the retained normalized weather context
+const ContextTopic = "automation/context/weather"
+
+// Agent polls a weather service and shares the current conditions over MQTT
+// and Redis
+type Agent struct {
+	mqtt     mqtt.Client
+	redis    redis.Client
+	provider Provider
+	cfg      *config.Config
+	logger   *slog.Logger
+}
+
+// NewAgent creates a weather agent for the provider configured in cfg
+func NewAgent(mqttClient mqtt.Client, redisClient redis.Client, cfg *config.Config, logger *slog.Logger) (*Agent, error) {
+	if cfg.WeatherPollIntervalMin <= 0 {
+		return nil, fmt.Errorf("weather poll interval must be positive")
+	}
+
+	provider, err := NewProvider(cfg.WeatherProvider, cfg.WeatherUserAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAgent(mqttClient, redisClient, provider, cfg, logger), nil
+}
+
+func newAgent(mqttClient mqtt.Client, redisClient redis.Client, provider Provider, cfg *config.Config, logger *slog.Logger) *Agent {
+	return &Agent{
+		mqtt:     mqttClient,
+		redis:    redisClient,
+		provider: provider,
+		cfg:      cfg,
+		logger:   logger.With("component", "weather-agent"),
+	}
+}
+
+// Start connects to MQTT and Redis and polls the weather service until ctx
+// is cancelled
+func (a *Agent) Start(ctx context.Context) error {
+	a.logger.Info("Starting weather agent",
+		"provider", a.provider.Name(),
+		"latitude", a.cfg.Latitude,
+		"longitude", a.cfg.Longitude,
+		"poll_interval_min", a.cfg.WeatherPollIntervalMin)
+
+	if err := a.mqtt.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to MQTT: %w", err)
+	}
+	if err := a.redis.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	interval := time.Duration(a.cfg.WeatherPollIntervalMin) * time.Minute
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.poll(ctx); err != nil {
+			a.logger.Error("Weather update failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			a.logger.Info("Weather agent stopping")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop disconnects from MQTT and Redis
+func (a *Agent) Stop() error {
+	a.logger.Info("Stopping weather agent")
+	a.mqtt.Disconnect()
+
+	if err := a.redis.Close(); err != nil {
+		a.logger.Error("Error closing Redis connection", "error", err)
+		return err
+	}
+	return nil
+}
+
+// poll fetches the current weather, stores it for anchor creation and
+// publishes it as context
+func (a *Agent) poll(ctx context.Context) error {
+	observation, err := a.provider.Current(ctx, a.cfg.Latitude, a.cfg.Longitude)
+	if err != nil {
+		return fmt.Errorf("failed to fetch weather from %s: %w", a.provider.Name(), err)
+	}
+
+	weather := observation.Context()
+	stored, err := json.Marshal(weather)
+	if err != nil {
+		return fmt.Errorf("failed to marshal weather: %w", err)
+	}
+
+	// Expire after a few missed polls so anchors never use stale weather
+	ttl := 3 * time.Duration(a.cfg.WeatherPollIntervalMin) * time.Minute
+	if err := a.redis.Set(ctx, redis.WeatherCurrentKey, string(stored), ttl); err != nil {
+		return fmt.Errorf("failed to store weather: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"temperature":   observation.Temperature,
+		"humidity":      observation.Humidity,
+		"precipitation": observation.Precipitation,
+		"cloud_cover":   observation.CloudCover,
+		"wind_speed":    observation.WindSpeed,
+		"brightness":    weather["brightness"],
+		"is_day":        observation.IsDay,
+		"observed_at":   weather["observed_at"],
+	}
+	contextMsg := map[string]interface{}{
+		"source":    "weather-agent",
+		"type":      "weather",
+		"state":     observation.Condition,
+		"message":   fmt.Sprintf("Weather is %s, %.1f°C", observation.Condition, observation.Temperature),
+		"provider":  observation.Source,
+		"data":      data,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+
+	payload, err := json.Marshal(contextMsg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal weather context: %w", err)
+	}
+	if err := a.mqtt.Publish(ContextTopic, 0, true, payload); err != nil {
+		return fmt.Errorf("failed to publish weather context: %w", err)
+	}
+
+	a.logger.Info("Weather updated",
+		"condition", observation.Condition,
+		"temperature", observation.Temperature,
+		"cloud_cover", observation.CloudCover,
+		"observed_at", observation.ObservedAt)
+	return nil
+}
@@ -0,0 +1,100 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+type fakeProvider struct {
+	obs *Observation
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Current(ctx context.Context, lat, lon float64) (*Observation, error) {
+	return p.obs, nil
+}
+
+// fakeRedis records Set calls; other methods are not used by the agent
+type fakeRedis struct {
+	redis.Client
+	values map[string]string
+	ttls   map[string]time.Duration
+}
+
+func (r *fakeRedis) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	r.values[key] = value.(string)
+	r.ttls[key] = ttl
+	return nil
+}
+
+type fakeMQTT struct {
+	topic    string
+	retained bool
+	payload  []byte
+}
+
+func (m *fakeMQTT) Connect(ctx context.Context) error { return nil }
+func (m *fakeMQTT) Disconnect()                       {}
+func (m *fakeMQTT) IsConnected() bool                 { return true }
+func (m *fakeMQTT) Subscribe(topic string, qos byte, handler mqtt.MessageHandler) error {
+	return nil
+}
+func (m *fakeMQTT) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	m.topic, m.retained, m.payload = topic, retained, payload
+	return nil
+}
+
+func TestAgent_Poll(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.WeatherPollIntervalMin = 10
+
+	provider := &fakeProvider{obs: &Observation{
+		Temperature: 12.5,
+		CloudCover:  40,
+		Condition:   ConditionPartlyCloudy,
+		IsDay:       true,
+		Source:      ProviderOpenMeteo,
+		ObservedAt:  time.Date(2025, 10, 17, 12, 0, 0, 0, time.UTC),
+	}}
+	store := &fakeRedis{values: map[string]string{}, ttls: map[string]time.Duration{}}
+	broker := &fakeMQTT{}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	agent := newAgent(broker, store, provider, cfg, logger)
+	if err := agent.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored map[string]interface{}
+	if err := json.Unmarshal([]byte(store.values[redis.WeatherCurrentKey]), &stored); err != nil {
+		t.Fatalf("weather:current not stored as JSON: %v", err)
+	}
+	if stored["temperature"] != 12.5 || stored["cloudiness"] != 0.4 || stored["condition"] != ConditionPartlyCloudy {
+		t.Errorf("unexpected stored weather %v", stored)
+	}
+	if store.ttls[redis.WeatherCurrentKey] != 30*time.Minute {
+		t.Errorf("ttl = %v, want three poll intervals", store.ttls[redis.WeatherCurrentKey])
+	}
+
+	var published struct {
+		State string `json:"state"`
+		Data  struct {
+			CloudCover float64 `json:"cloud_cover"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(broker.payload, &published); err != nil {
+		t.Fatal(err)
+	}
+	if broker.topic != ContextTopic || !broker.retained || published.State != ConditionPartlyCloudy || published.Data.CloudCover != 40 {
+		t.Errorf("unexpected weather context on %s (retained %v): %s", broker.topic, broker.retained, broker.payload)
+	}
+}
@@ -0,0 +1,127 @@
+package weather
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Normalized weather conditions, shared by every provider
+const (
+	ConditionClear        = "clear"
+	ConditionPartlyCloudy = "partly_cloudy"
+	ConditionCloudy       = "cloudy"
+	ConditionFog          = "fog"
+	ConditionDrizzle      = "drizzle"
+	ConditionRain         = "rain"
+	ConditionSnow         = "snow"
+	ConditionThunderstorm = "thunderstorm"
+)
+
+// Conditions lists the normalized conditions in embedding order
+var Conditions = []string{
+	ConditionClear, ConditionPartlyCloudy, ConditionCloudy, ConditionFog,
+	ConditionDrizzle, ConditionRain, ConditionSnow, ConditionThunderstorm,
+}
+
+// Observation is the current weather in provider-independent units
+type Observation struct {
+	Temperature   float64 // °C
+	Humidity      float64 // percent
+	Precipitation float64 // mm in the last/next hour
+	CloudCover    float64 // percent
+	WindSpeed     float64 // m/s
+	Condition     string
+	IsDay         bool
+	Source        string
+	ObservedAt    time.Time
+}
+
+// heavyPrecipitation is the hourly amount treated as the top of the 0-1 scale
+const heavyPrecipitation = 4.0
+
+// Brightness estimates outdoor daylight on a 0-1 scale from cloud cover
+// (Kasten-Czeplak: an overcast sky lets through about a quarter of the light)
+func (o Observation) Brightness() float64 {
+	if !o.IsDay {
+		return 0
+	}
+	cover := math.Min(100, math.Max(0, o.CloudCover)) / 100
+	return 1 - 0.75*math.Pow(cover, 3.4)
+}
+
+// Context returns the weather map stored at weather:current, read by the
+// behavior agent's context gatherer for the semantic embedding
+func (o Observation) Context() map[string]interface{} {
+	return map[string]interface{}{
+		"brightness":    o.Brightness(),
+		"precipitation": math.Min(1, o.Precipitation/heavyPrecipitation),
+		"temperature":   o.Temperature,
+		"cloudiness":    math.Min(100, math.Max(0, o.CloudCover)) / 100,
+		"wind_speed":    o.WindSpeed,
+		"humidity":      o.Humidity / 100,
+		"is_day":        o.IsDay,
+		"condition":     o.Condition,
+		"source":        o.Source,
+		"observed_at":   o.ObservedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// conditionFromWMO maps WMO weather interpretation codes (Open-Meteo)
+func conditionFromWMO(code int) string {
+	switch {
+	case code == 0:
+		return ConditionClear
+	case code == 1 || code == 2:
+		return ConditionPartlyCloudy
+	case code == 3:
+		return ConditionCloudy
+	case code == 45 || code == 48:
+		return ConditionFog
+	case code >= 51 && code <= 57:
+		return ConditionDrizzle
+	case (code >= 61 && code <= 67) || (code >= 80 && code <= 82):
+		return ConditionRain
+	case (code >= 71 && code <= 77) || code == 85 || code == 86:
+		return ConditionSnow
+	case code >= 95:
+		return ConditionThunderstorm
+	default:
+		return ConditionCloudy
+	}
+}
+
+// conditionFromSymbol maps MET Norway symbol codes such as "lightrainshowers_day"
+func conditionFromSymbol(symbol string) string {
+	symbol, _, _ = strings.Cut(symbol, "_")
+	switch {
+	case strings.Contains(symbol, "thunder"):
+		return ConditionThunderstorm
+	case strings.Contains(symbol, "snow"), strings.Contains(symbol, "sleet"):
+		return ConditionSnow
+	case symbol == "lightrain" || symbol == "lightrainshowers":
+		return ConditionDrizzle
+	case strings.Contains(symbol, "rain"):
+		return ConditionRain
+	case symbol == "fog":
+		return ConditionFog
+	case symbol == "clearsky":
+		return ConditionClear
+	case symbol == "fair" || symbol == "partlycloudy":
+		return ConditionPartlyCloudy
+	default:
+		return ConditionCloudy
+	}
+}
+
+// conditionFromCloudCover is used when a provider gives no condition
+func conditionFromCloudCover(cover float64) string {
+	switch {
+	case cover < 20:
+		return ConditionClear
+	case cover < 70:
+		return ConditionPartlyCloudy
+	default:
+		return ConditionCloudy
+	}
+}
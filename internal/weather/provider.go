@@ -0,0 +1,200 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider names used in JEEVES_WEATHER_PROVIDER
+const (
+	ProviderOpenMeteo = "open-meteo"
+	ProviderMETNorway = "met-norway"
+)
+
+// Provider fetches current weather for a location
+type Provider interface {
+	Name() string
+	Current(ctx context.Context, lat, lon float64) (*Observation, error)
+}
+
+const providerTimeout = 15 * time.Second
+
+// NewProvider returns the named weather provider
+func NewProvider(name, userAgent string) (Provider, error) {
+	client := &http.Client{Timeout: providerTimeout}
+	switch name {
+	case ProviderOpenMeteo:
+		return &OpenMeteoProvider{
+			endpoint:  "https://api.open-meteo.com/v1/forecast",
+			userAgent: userAgent,
+			client:    client,
+		}, nil
+	case ProviderMETNorway:
+		if userAgent == "" {
+			return nil, fmt.Errorf("MET Norway requires a User-Agent identifying the application")
+		}
+		return &METNorwayProvider{
+			endpoint:  "https://api.met.no/weatherapi/locationforecast/2.0/compact",
+			userAgent: userAgent,
+			client:    client,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q (expected %s or %s)", name, ProviderOpenMeteo, ProviderMETNorway)
+	}
+}
+
+// OpenMeteoProvider reads current conditions from the Open-Meteo forecast API
+type OpenMeteoProvider struct {
+	endpoint  string
+	userAgent string
+	client    *http.Client
+}
+
+func (p *OpenMeteoProvider) Name() string { return ProviderOpenMeteo }
+
+func (p *OpenMeteoProvider) Current(ctx context.Context, lat, lon float64) (*Observation, error) {
+	query := url.Values{
+		"latitude":        {strconv.FormatFloat(lat, 'f', 4, 64)},
+		"longitude":       {strconv.FormatFloat(lon, 'f', 4, 64)},
+		"current":         {"temperature_2m,relative_humidity_2m,precipitation,cloud_cover,wind_speed_10m,weather_code,is_day"},
+		"wind_speed_unit": {"ms"},
+		"timezone":        {"UTC"},
+	}
+
+	var response struct {
+		Current struct {
+			Time          string  `json:"time"`
+			Temperature   float64 `json:"temperature_2m"`
+			Humidity      float64 `json:"relative_humidity_2m"`
+			Precipitation float64 `json:"precipitation"`
+			CloudCover    float64 `json:"cloud_cover"`
+			WindSpeed     float64 `json:"wind_speed_10m"`
+			WeatherCode   int     `json:"weather_code"`
+			IsDay         int     `json:"is_day"`
+		} `json:"current"`
+	}
+	if err := getJSON(ctx, p.client, p.endpoint+"?"+query.Encode(), p.userAgent, &response); err != nil {
+		return nil, err
+	}
+
+	current := response.Current
+	observedAt, err := time.Parse("2006-01-02T15:04", current.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse observation time %q: %w", current.Time, err)
+	}
+
+	return &Observation{
+		Temperature:   current.Temperature,
+		Humidity:      current.Humidity,
+		Precipitation: current.Precipitation,
+		CloudCover:    current.CloudCover,
+		WindSpeed:     current.WindSpeed,
+		Condition:     conditionFromWMO(current.WeatherCode),
+		IsDay:         current.IsDay == 1,
+		Source:        ProviderOpenMeteo,
+		ObservedAt:    observedAt,
+	}, nil
+}
+
+// METNorwayProvider reads the first step of the MET Norway locationforecast
+type METNorwayProvider struct {
+	endpoint  string
+	userAgent string
+	client    *http.Client
+}
+
+func (p *METNorwayProvider) Name() string { return ProviderMETNorway }
+
+func (p *METNorwayProvider) Current(ctx context.Context, lat, lon float64) (*Observation, error) {
+	// MET asks for at most 4 decimals to keep its cache effective
+	query := url.Values{
+		"lat": {strconv.FormatFloat(lat, 'f', 4, 64)},
+		"lon": {strconv.FormatFloat(lon, 'f', 4, 64)},
+	}
+
+	var response struct {
+		Properties struct {
+			Timeseries []struct {
+				Time time.Time `json:"time"`
+				Data struct {
+					Instant struct {
+						Details struct {
+							Temperature float64 `json:"air_temperature"`
+							Humidity    float64 `json:"relative_humidity"`
+							CloudCover  float64 `json:"cloud_area_fraction"`
+							WindSpeed   float64 `json:"wind_speed"`
+						} `json:"details"`
+					} `json:"instant"`
+					Next1Hours *struct {
+						Summary struct {
+							SymbolCode string `json:"symbol_code"`
+						} `json:"summary"`
+						Details struct {
+							Precipitation float64 `json:"precipitation_amount"`
+						} `json:"details"`
+					} `json:"next_1_hours"`
+				} `json:"data"`
+			} `json:"timeseries"`
+		} `json:"properties"`
+	}
+	if err := getJSON(ctx, p.client, p.endpoint+"?"+query.Encode(), p.userAgent, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Properties.Timeseries) == 0 {
+		return nil, fmt.Errorf("MET Norway returned no timeseries")
+	}
+
+	step := response.Properties.Timeseries[0]
+	details := step.Data.Instant.Details
+	observation := &Observation{
+		Temperature: details.Temperature,
+		Humidity:    details.Humidity,
+		CloudCover:  details.CloudCover,
+		WindSpeed:   details.WindSpeed,
+		Condition:   conditionFromCloudCover(details.CloudCover),
+		Source:      ProviderMETNorway,
+		ObservedAt:  step.Time,
+	}
+	if next := step.Data.Next1Hours; next != nil {
+		observation.Precipitation = next.Details.Precipitation
+		observation.Condition = conditionFromSymbol(next.Summary.SymbolCode)
+		observation.IsDay = !strings.HasSuffix(next.Summary.SymbolCode, "_night") &&
+			!strings.HasSuffix(next.Summary.SymbolCode, "_polartwilight")
+	}
+	return observation, nil
+}
+
+// getJSON performs a GET request and decodes a JSON response into out
+func getJSON(ctx context.Context, client *http.Client, target, userAgent string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("weather request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("weather service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode weather response: %w", err)
+	}
+	return nil
+}
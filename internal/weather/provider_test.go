@@ -0,0 +1,118 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenMeteoProvider_Current(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("latitude") != "60.1695" || r.URL.Query().Get("wind_speed_unit") != "ms" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"current": {"time": "2025-10-17T14:30", "temperature_2m": 8.4,
+			"relative_humidity_2m": 81, "precipitation": 0.6, "cloud_cover": 100,
+			"wind_speed_10m": 5.2, "weather_code": 61, "is_day": 1}}`))
+	}))
+	defer server.Close()
+
+	provider := &OpenMeteoProvider{endpoint: server.URL, client: server.Client()}
+	obs, err := provider.Current(context.Background(), 60.1695, 24.9354)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if obs.Condition != ConditionRain || !obs.IsDay || obs.CloudCover != 100 || obs.WindSpeed != 5.2 {
+		t.Errorf("unexpected observation %+v", obs)
+	}
+	if want := time.Date(2025, 10, 17, 14, 30, 0, 0, time.UTC); !obs.ObservedAt.Equal(want) {
+		t.Errorf("observed at %v, want %v", obs.ObservedAt, want)
+	}
+}
+
+func TestMETNorwayProvider_Current(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "jeeves-test" {
+			t.Errorf("missing User-Agent, got %q", r.Header.Get("User-Agent"))
+		}
+		w.Write([]byte(`{"properties": {"timeseries": [{"time": "2025-10-17T14:00:00Z", "data": {
+			"instant": {"details": {"air_temperature": -2.5, "relative_humidity": 90,
+				"cloud_area_fraction": 95, "wind_speed": 3.1}},
+			"next_1_hours": {"summary": {"symbol_code": "lightsnowshowers_night"},
+				"details": {"precipitation_amount": 0.4}}}}]}}`))
+	}))
+	defer server.Close()
+
+	provider := &METNorwayProvider{endpoint: server.URL, userAgent: "jeeves-test", client: server.Client()}
+	obs, err := provider.Current(context.Background(), 60.1695, 24.9354)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if obs.Condition != ConditionSnow || obs.IsDay || obs.Precipitation != 0.4 || obs.Temperature != -2.5 {
+		t.Errorf("unexpected observation %+v", obs)
+	}
+}
+
+func TestProvider_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := &OpenMeteoProvider{endpoint: server.URL, client: server.Client()}
+	if _, err := provider.Current(context.Background(), 60, 24); err == nil {
+		t.Error("expected error for 429 response")
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(ProviderMETNorway, ""); err == nil {
+		t.Error("MET Norway without User-Agent: expected error")
+	}
+	if _, err := NewProvider("yr", "jeeves"); err == nil {
+		t.Error("unknown provider: expected error")
+	}
+	if p, err := NewProvider(ProviderOpenMeteo, ""); err != nil || p.Name() != ProviderOpenMeteo {
+		t.Errorf("open-meteo: got %v, %v", p, err)
+	}
+}
+
+func TestConditionMapping(t *testing.T) {
+	wmo := map[int]string{0: ConditionClear, 2: ConditionPartlyCloudy, 3: ConditionCloudy, 45: ConditionFog,
+		53: ConditionDrizzle, 81: ConditionRain, 75: ConditionSnow, 95: ConditionThunderstorm}
+	for code, want := range wmo {
+		if got := conditionFromWMO(code); got != want {
+			t.Errorf("WMO %d = %s, want %s", code, got, want)
+		}
+	}
+
+	symbols := map[string]string{"clearsky_day": ConditionClear, "fair_night": ConditionPartlyCloudy,
+		"cloudy": ConditionCloudy, "fog": ConditionFog, "lightrain": ConditionDrizzle, "heavyrainshowers_day": ConditionRain,
+		"sleet": ConditionSnow, "rainandthunder": ConditionThunderstorm}
+	for symbol, want := range symbols {
+		if got := conditionFromSymbol(symbol); got != want {
+			t.Errorf("symbol %s = %s, want %s", symbol, got, want)
+		}
+	}
+}
+
+func TestObservation_Context(t *testing.T) {
+	obs := Observation{CloudCover: 100, Precipitation: 8, Humidity: 50, IsDay: true, Condition: ConditionRain}
+	ctx := obs.Context()
+
+	if b := ctx["brightness"].(float64); b < 0.249 || b > 0.251 {
+		t.Errorf("overcast brightness = %.3f, want 0.25", b)
+	}
+	if ctx["precipitation"].(float64) != 1 || ctx["cloudiness"].(float64) != 1 || ctx["humidity"].(float64) != 0.5 {
+		t.Errorf("unexpected context %v", ctx)
+	}
+
+	obs.IsDay = false
+	if obs.Brightness() != 0 {
+		t.Error("brightness at night should be 0")
+	}
+}
@@ -0,0 +1,401 @@
+// Package auth resolves bearer tokens to viewer or admin roles for the HTTP
+// APIs (observer, behavior agent, light scenes), using static tokens or an
+// OpenID provider configured by the JEEVES_OBSERVER_AUTH_* settings.
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+// Role is the access level granted to a request
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleViewer
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// TokenCookie carries the token for browser requests, including /ws where
+// headers cannot be set
+const TokenCookie = "jeeves_token"
+
+// Authenticator resolves a bearer token to a role
+type Authenticator interface {
+	RoleFor(ctx context.Context, token string) (Role, error)
+}
+
+// New builds the authenticator selected by cfg.ObserverAuthMode
+func New(cfg *config.Config, logger *slog.Logger) (Authenticator, error) {
+	switch cfg.ObserverAuthMode {
+	case "", "none":
+		logger.Warn("API authentication disabled")
+		return openAuth{}, nil
+	case "token":
+		if len(cfg.ObserverViewerTokens) == 0 && len(cfg.ObserverAdminTokens) == 0 {
+			return nil, fmt.Errorf("token auth requires JEEVES_OBSERVER_VIEWER_TOKENS or JEEVES_OBSERVER_ADMIN_TOKENS")
+		}
+		return &tokenAuth{viewers: cfg.ObserverViewerTokens, admins: cfg.ObserverAdminTokens}, nil
+	case "oidc":
+		if cfg.ObserverOIDCIssuer == "" || cfg.ObserverOIDCAudience == "" {
+			return nil, fmt.Errorf("oidc auth requires an issuer and audience")
+		}
+		return newOIDCAuth(cfg, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown observer auth mode: %s", cfg.ObserverAuthMode)
+	}
+}
+
+// openAuth grants admin to everyone (auth disabled)
+type openAuth struct{}
+
+func (openAuth) RoleFor(ctx context.Context, token string) (Role, error) {
+	return RoleAdmin, nil
+}
+
+// tokenAuth checks static tokens from the configuration
+type tokenAuth struct {
+	viewers []string
+	admins  []string
+}
+
+func (a *tokenAuth) RoleFor(ctx context.Context, token string) (Role, error) {
+	if token == "" {
+		return RoleNone, nil
+	}
+	if containsToken(a.admins, token) {
+		return RoleAdmin, nil
+	}
+	if containsToken(a.viewers, token) {
+		return RoleViewer, nil
+	}
+	return RoleNone, nil
+}
+
+// containsToken compares in constant time so tokens can't be guessed byte by byte
+func containsToken(tokens []string, token string) bool {
+	found := false
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			found = true
+		}
+	}
+	return found
+}
+
+// RequireRole wraps a handler so it only runs for requests with at least the given role
+func RequireRole(auth Authenticator, required Role, logger *slog.Logger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		granted, err := auth.RoleFor(r.Context(), requestToken(r))
+		if err != nil {
+			logger.Debug("Rejected API token", "path", r.URL.Path, "error", err)
+		}
+
+		if granted == RoleNone {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="jeeves"`)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+		if granted < required {
+			logger.Warn("API access denied",
+				"path", r.URL.Path,
+				"role", granted.String(),
+				"required", required.String())
+			http.Error(w, fmt.Sprintf("%s role required", required), http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// requestToken reads the bearer token from the Authorization header or the token cookie
+func requestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	if cookie, err := r.Cookie(TokenCookie); err == nil {
+		if token, err := url.QueryUnescape(cookie.Value); err == nil {
+			return token
+		}
+	}
+	return ""
+}
+
+// JWT validation tuning
+const (
+	oidcClockSkew      = time.Minute
+	oidcKeyRefreshWait = time.Minute // minimum time between JWKS refetches for unknown key IDs
+	oidcHTTPTimeout    = 10 * time.Second
+)
+
+// oidcAuth validates RS256-signed JWTs issued by an OpenID provider. Signing keys
+// are discovered from the issuer's well-known configuration and refreshed when
+// a token references an unknown key ID.
+type oidcAuth struct {
+	issuer     string
+	audience   string
+	rolesClaim string
+	adminRole  string
+	httpClient *http.Client
+	logger     *slog.Logger
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	lastRefresh time.Time
+}
+
+func newOIDCAuth(cfg *config.Config, logger *slog.Logger) *oidcAuth {
+	return &oidcAuth{
+		issuer:     strings.TrimSuffix(cfg.ObserverOIDCIssuer, "/"),
+		audience:   cfg.ObserverOIDCAudience,
+		rolesClaim: cfg.ObserverOIDCRolesClaim,
+		adminRole:  cfg.ObserverOIDCAdminRole,
+		httpClient: &http.Client{Timeout: oidcHTTPTimeout},
+		logger:     logger,
+		keys:       make(map[string]*rsa.PublicKey),
+	}
+}
+
+func (a *oidcAuth) RoleFor(ctx context.Context, token string) (Role, error) {
+	if token == "" {
+		return RoleNone, nil
+	}
+
+	claims, err := a.verify(ctx, token)
+	if err != nil {
+		return RoleNone, err
+	}
+
+	for _, r := range claimStrings(claims, a.rolesClaim) {
+		if r == a.adminRole {
+			return RoleAdmin, nil
+		}
+	}
+	return RoleViewer, nil
+}
+
+// verify checks the token signature and standard claims, returning all claims
+func (a *oidcAuth) verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("failed to decode token header: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", header.Alg)
+	}
+
+	key, err := a.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("failed to decode token claims: %w", err)
+	}
+
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != a.issuer {
+		return nil, fmt.Errorf("unexpected issuer: %s", iss)
+	}
+	if !containsAudience(claims["aud"], a.audience) {
+		return nil, fmt.Errorf("token not issued for audience %s", a.audience)
+	}
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.After(time.Unix(int64(exp), 0).Add(oidcClockSkew)) {
+		return nil, fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(oidcClockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("token not yet valid")
+	}
+
+	return claims, nil
+}
+
+// key returns the signing key for kid, refetching the JWKS if it is unknown
+func (a *oidcAuth) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if key, ok := a.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(a.lastRefresh) < oidcKeyRefreshWait {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	a.lastRefresh = time.Now()
+	keys, err := a.fetchKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	a.keys = keys
+	a.logger.Info("Refreshed OIDC signing keys", "issuer", a.issuer, "keys", len(keys))
+
+	if key, ok := a.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key: %s", kid)
+}
+
+// fetchKeys loads the issuer's RSA signing keys via OpenID discovery
+func (a *oidcAuth) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := a.getJSON(ctx, a.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("issuer did not advertise a jwks_uri")
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := a.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+func (a *oidcAuth) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get %s: status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", url, err)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// containsAudience handles "aud" as either a string or an array of strings
+func containsAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimStrings reads a string or string-array claim at a dotted path, e.g.
+// "realm_access.roles". Space-separated strings (like "scope") are split.
+func claimStrings(claims map[string]interface{}, path string) []string {
+	var value interface{} = claims
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[key]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
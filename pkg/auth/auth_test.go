@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+func newTestAuth(t *testing.T, mode string) Authenticator {
+	t.Helper()
+	cfg := config.NewConfig()
+	cfg.ObserverAuthMode = mode
+	cfg.ObserverViewerTokens = []string{"viewer-token"}
+	cfg.ObserverAdminTokens = []string{"admin-token"}
+
+	authn, err := New(cfg, testLogger)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	return authn
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		required Role
+		header   string
+		cookie   string
+		want     int
+	}{
+		{"missing token", "token", RoleViewer, "", "", http.StatusUnauthorized},
+		{"unknown token", "token", RoleViewer, "Bearer guess", "", http.StatusUnauthorized},
+		{"not a bearer token", "token", RoleViewer, "Basic viewer-token", "", http.StatusUnauthorized},
+		{"viewer on a viewer route", "token", RoleViewer, "Bearer viewer-token", "", http.StatusOK},
+		{"viewer on an admin route", "token", RoleAdmin, "Bearer viewer-token", "", http.StatusForbidden},
+		{"admin on a viewer route", "token", RoleViewer, "Bearer admin-token", "", http.StatusOK},
+		{"admin on an admin route", "token", RoleAdmin, "Bearer admin-token", "", http.StatusOK},
+		{"token cookie", "token", RoleAdmin, "", "admin-token", http.StatusOK},
+		{"auth disabled", "none", RoleAdmin, "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := RequireRole(newTestAuth(t, tt.mode), tt.required, testLogger, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: TokenCookie, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rec.Code)
+			}
+			if called != (tt.want == http.StatusOK) {
+				t.Errorf("Expected handler called to be %v", tt.want == http.StatusOK)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		tokens  bool
+		wantErr bool
+	}{
+		{"default", "", false, false},
+		{"none", "none", false, false},
+		{"token", "token", true, false},
+		{"token without tokens", "token", false, true},
+		{"oidc without issuer", "oidc", false, true},
+		{"unknown mode", "basic", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.ObserverAuthMode = tt.mode
+			cfg.ObserverViewerTokens = nil
+			cfg.ObserverAdminTokens = nil
+			if tt.tokens {
+				cfg.ObserverAdminTokens = []string{"admin-token"}
+			}
+
+			_, err := New(cfg, testLogger)
+			if tt.wantErr && err == nil {
+				t.Error("Expected an error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
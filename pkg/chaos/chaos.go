@@ -0,0 +1,211 @@
+// Package chaos injects faults into an agent for e2e resilience tests. The
+// test runner publishes faults on automation/test/chaos; agents only honour
+// them with JEEVES_CHAOS_ENABLED.
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// Topic carries fault requests from the test runner
+const Topic = "automation/test/chaos"
+
+// Fault kinds
+const (
+	FaultMQTTDrop   = "mqtt_drop"   // publishes fail and incoming messages are lost
+	FaultRedisError = "redis_error" // every Redis call returns an error
+	FaultLLMDelay   = "llm_delay"   // requests to the LLM endpoint are held back
+)
+
+// ErrInjected is returned by calls failed on purpose. It is transient, like
+// the outages it simulates.
+var ErrInjected = errcode.New(errcode.ErrTransient, "chaos: injected fault")
+
+// Fault is a fault request published on Topic
+type Fault struct {
+	Fault           string `json:"fault"`
+	Service         string `json:"service,omitempty"` // empty targets every agent
+	DurationSeconds int    `json:"duration_seconds"`
+	DelayMs         int    `json:"delay_ms,omitempty"` // llm_delay only
+}
+
+// Injector tracks the active faults of one agent. A nil Injector injects
+// nothing and its Wrap methods return what they are given.
+type Injector struct {
+	service     string
+	llmEndpoint string
+	logger      *slog.Logger
+
+	mu       sync.Mutex
+	until    map[string]time.Time
+	llmDelay time.Duration
+}
+
+// NewInjector returns an injector for the agent, or nil unless
+// cfg.ChaosEnabled
+func NewInjector(cfg *config.Config, logger *slog.Logger) *Injector {
+	if !cfg.ChaosEnabled {
+		return nil
+	}
+
+	logger = logger.With("component", "chaos")
+	logger.Warn("Fault injection enabled", "topic", Topic)
+
+	return &Injector{
+		service:     cfg.ServiceName,
+		llmEndpoint: cfg.LLMEndpoint,
+		logger:      logger,
+		until:       make(map[string]time.Time),
+	}
+}
+
+// Active reports whether a fault of the given kind is in effect
+func (i *Injector) Active(kind string) bool {
+	if i == nil {
+		return false
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return time.Now().Before(i.until[kind])
+}
+
+// Apply starts a fault, replacing any active fault of the same kind
+func (i *Injector) Apply(f Fault) {
+	if f.Service != "" && f.Service != i.service {
+		return
+	}
+
+	duration := time.Duration(f.DurationSeconds) * time.Second
+
+	i.mu.Lock()
+	i.until[f.Fault] = time.Now().Add(duration)
+	if f.Fault == FaultLLMDelay {
+		i.llmDelay = time.Duration(f.DelayMs) * time.Millisecond
+	}
+	i.mu.Unlock()
+
+	i.logger.Warn("Injecting fault", "fault", f.Fault, "duration", duration, "delay_ms", f.DelayMs)
+}
+
+func (i *Injector) handleFault(msg mqtt.Message) {
+	var f Fault
+	if err := json.Unmarshal(msg.Payload(), &f); err != nil {
+		i.logger.Error("Failed to parse fault", "error", err)
+		return
+	}
+
+	switch f.Fault {
+	case FaultMQTTDrop, FaultRedisError, FaultLLMDelay:
+		i.Apply(f)
+	default:
+		i.logger.Warn("Unknown fault", "fault", f.Fault)
+	}
+}
+
+// WrapMQTT returns a client that subscribes to Topic on connect and drops
+// traffic while mqtt_drop is active
+func (i *Injector) WrapMQTT(client mqtt.Client) mqtt.Client {
+	if i == nil {
+		return client
+	}
+	return &mqttClient{Client: client, injector: i}
+}
+
+type mqttClient struct {
+	mqtt.Client
+	injector *Injector
+}
+
+func (c *mqttClient) Connect(ctx context.Context) error {
+	if err := c.Client.Connect(ctx); err != nil {
+		return err
+	}
+	// Subscribed on the inner client so faults still arrive during a drop
+	return c.Client.Subscribe(Topic, 1, c.injector.handleFault)
+}
+
+func (c *mqttClient) Subscribe(topic string, qos byte, handler mqtt.MessageHandler) error {
+	return c.Client.Subscribe(topic, qos, func(msg mqtt.Message) {
+		if c.injector.Active(FaultMQTTDrop) {
+			return
+		}
+		handler(msg)
+	})
+}
+
+func (c *mqttClient) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	if c.injector.Active(FaultMQTTDrop) {
+		return ErrInjected
+	}
+	return c.Client.Publish(topic, qos, retained, payload)
+}
+
+func (c *mqttClient) PublishWithResult(topic string, qos byte, retained bool, payload []byte, handler mqtt.ResultHandler) {
+	if c.injector.Active(FaultMQTTDrop) {
+		if handler != nil {
+			handler(mqtt.PublishResult{Topic: topic, QoS: qos, Err: ErrInjected})
+		}
+		return
+	}
+	mqtt.PublishWithResult(c.Client, topic, qos, retained, payload, handler)
+}
+
+func (c *mqttClient) DeliveryStats() mqtt.DeliveryStats {
+	stats, _ := mqtt.Stats(c.Client)
+	return stats
+}
+
+func (c *mqttClient) IsConnected() bool {
+	return !c.injector.Active(FaultMQTTDrop) && c.Client.IsConnected()
+}
+
+// WrapTransport returns a transport that holds back requests to the LLM
+// endpoint while llm_delay is active
+func (i *Injector) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if i == nil {
+		return rt
+	}
+	return &transport{RoundTripper: rt, injector: i}
+}
+
+type transport struct {
+	http.RoundTripper
+	injector *Injector
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if delay := t.injector.delayFor(req.URL); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func (i *Injector) delayFor(target *url.URL) time.Duration {
+	if !i.Active(FaultLLMDelay) {
+		return 0
+	}
+
+	endpoint, err := url.Parse(i.llmEndpoint)
+	if err != nil || endpoint.Host != target.Host {
+		return 0
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.llmDelay
+}
@@ -0,0 +1,193 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/redis"
+)
+
+// WrapRedis returns a client whose calls fail while redis_error is active
+func (i *Injector) WrapRedis(client redis.Client) redis.Client {
+	if i == nil {
+		return client
+	}
+	return &redisClient{Client: client, injector: i}
+}
+
+type redisClient struct {
+	redis.Client
+	injector *Injector
+}
+
+func (c *redisClient) failing() bool {
+	return c.injector.Active(FaultRedisError)
+}
+
+func (c *redisClient) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if c.failing() {
+		return ErrInjected
+	}
+	return c.Client.Set(ctx, key, value, ttl)
+}
+
+func (c *redisClient) Get(ctx context.Context, key string) (string, error) {
+	if c.failing() {
+		return "", ErrInjected
+	}
+	return c.Client.Get(ctx, key)
+}
+
+func (c *redisClient) HSet(ctx context.Context, key string, field string, value interface{}) error {
+	if c.failing() {
+		return ErrInjected
+	}
+	return c.Client.HSet(ctx, key, field, value)
+}
+
+func (c *redisClient) HGet(ctx context.Context, key string, field string) (string, error) {
+	if c.failing() {
+		return "", ErrInjected
+	}
+	return c.Client.HGet(ctx, key, field)
+}
+
+func (c *redisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	if c.failing() {
+		return nil, ErrInjected
+	}
+	return c.Client.HGetAll(ctx, key)
+}
+
+func (c *redisClient) ZAdd(ctx context.Context, key string, score float64, member interface{}) error {
+	if c.failing() {
+		return ErrInjected
+	}
+	return c.Client.ZAdd(ctx, key, score, member)
+}
+
+func (c *redisClient) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	if c.failing() {
+		return 0, ErrInjected
+	}
+	return c.Client.ZRem(ctx, key, members...)
+}
+
+func (c *redisClient) ZRemRangeByScore(ctx context.Context, key string, min, max string) error {
+	if c.failing() {
+		return ErrInjected
+	}
+	return c.Client.ZRemRangeByScore(ctx, key, min, max)
+}
+
+func (c *redisClient) ZCard(ctx context.Context, key string) (int64, error) {
+	if c.failing() {
+		return 0, ErrInjected
+	}
+	return c.Client.ZCard(ctx, key)
+}
+
+func (c *redisClient) ZRangeByScoreWithScores(ctx context.Context, key string, min, max float64) ([]redis.ZMember, error) {
+	if c.failing() {
+		return nil, ErrInjected
+	}
+	return c.Client.ZRangeByScoreWithScores(ctx, key, min, max)
+}
+
+func (c *redisClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if c.failing() {
+		return nil, ErrInjected
+	}
+	return c.Client.Keys(ctx, pattern)
+}
+
+func (c *redisClient) LPush(ctx context.Context, key string, values ...interface{}) error {
+	if c.failing() {
+		return ErrInjected
+	}
+	return c.Client.LPush(ctx, key, values...)
+}
+
+func (c *redisClient) LTrim(ctx context.Context, key string, start, stop int64) error {
+	if c.failing() {
+		return ErrInjected
+	}
+	return c.Client.LTrim(ctx, key, start, stop)
+}
+
+func (c *redisClient) LLen(ctx context.Context, key string) (int64, error) {
+	if c.failing() {
+		return 0, ErrInjected
+	}
+	return c.Client.LLen(ctx, key)
+}
+
+func (c *redisClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	if c.failing() {
+		return nil, ErrInjected
+	}
+	return c.Client.LRange(ctx, key, start, stop)
+}
+
+func (c *redisClient) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
+	if c.failing() {
+		return 0, ErrInjected
+	}
+	return c.Client.LRem(ctx, key, count, value)
+}
+
+func (c *redisClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	if c.failing() {
+		return ErrInjected
+	}
+	return c.Client.Expire(ctx, key, ttl)
+}
+
+func (c *redisClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	if c.failing() {
+		return 0, ErrInjected
+	}
+	return c.Client.Del(ctx, keys...)
+}
+
+func (c *redisClient) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if c.failing() {
+		return false, ErrInjected
+	}
+	return c.Client.SetNX(ctx, key, value, ttl)
+}
+
+func (c *redisClient) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	if c.failing() {
+		return false, ErrInjected
+	}
+	return c.Client.CompareAndDelete(ctx, key, value)
+}
+
+func (c *redisClient) CompareAndExpire(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	if c.failing() {
+		return false, ErrInjected
+	}
+	return c.Client.CompareAndExpire(ctx, key, value, ttl)
+}
+
+func (c *redisClient) CompareAndZRem(ctx context.Context, key string, member string, score float64) (bool, error) {
+	if c.failing() {
+		return false, ErrInjected
+	}
+	return c.Client.CompareAndZRem(ctx, key, member, score)
+}
+
+func (c *redisClient) ZRevRangeByScoreWithScores(ctx context.Context, key string, max, min float64, offset, count int64) ([]redis.ZMember, error) {
+	if c.failing() {
+		return nil, ErrInjected
+	}
+	return c.Client.ZRevRangeByScoreWithScores(ctx, key, max, min, offset, count)
+}
+
+func (c *redisClient) Ping(ctx context.Context) error {
+	if c.failing() {
+		return ErrInjected
+	}
+	return c.Client.Ping(ctx)
+}
@@ -1,4 +1,7 @@
-package behavior
+// Package clock provides the time agents run on: real time normally, or a
+// virtual time configured over MQTT by the e2e test runner, so a scenario
+// spanning hours can drive every agent in minutes.
+package clock
 
 import (
 	"encoding/json"
@@ -9,6 +12,25 @@ import (
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 )
 
+// ConfigTopic carries virtual time configuration
+const ConfigTopic = "automation/test/time_config"
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+var (
+	_ Clock = (*TimeManager)(nil)
+	_ Clock = Real{}
+)
+
+// Real is wall-clock time
+type Real struct{}
+
+// Now returns time.Now()
+func (Real) Now() time.Time { return time.Now() }
+
 // TimeManager manages virtual time for testing scenarios
 type TimeManager struct {
 	mu           sync.RWMutex
@@ -19,7 +41,7 @@ type TimeManager struct {
 	logger       *slog.Logger
 }
 
-// NewTimeManager creates a new time manager
+// NewTimeManager creates a time manager running on real time
 func NewTimeManager(logger *slog.Logger) *TimeManager {
 	return &TimeManager{
 		testMode:  false,
@@ -35,7 +57,7 @@ func (tm *TimeManager) ConfigureFromMQTT(mqttClient mqtt.Client) error {
 		tm.handleTestModeConfig(msg.Payload())
 	}
 
-	return mqttClient.Subscribe("automation/test/time_config", 1, handler)
+	return mqttClient.Subscribe(ConfigTopic, 1, handler)
 }
 
 // handleTestModeConfig processes test mode configuration from MQTT
@@ -44,6 +66,7 @@ func (tm *TimeManager) handleTestModeConfig(payload []byte) {
 		VirtualStart string `json:"virtual_start"`
 		TimeScale    int    `json:"time_scale"`
 		TestMode     bool   `json:"test_mode"`
+		RealStart    string `json:"real_start,omitempty"` // wall time virtual_start maps to, default now
 	}
 
 	if err := json.Unmarshal(payload, &config); err != nil {
@@ -65,10 +88,21 @@ func (tm *TimeManager) handleTestModeConfig(payload []byte) {
 		return
 	}
 
+	// With a shared real_start every agent's virtual clock lines up with the
+	// test runner's, however late the config arrived
+	realStart := time.Now()
+	if config.RealStart != "" {
+		if parsed, err := time.Parse(time.RFC3339Nano, config.RealStart); err == nil {
+			realStart = parsed
+		} else {
+			tm.logger.Warn("Invalid real_start, using now", "error", err)
+		}
+	}
+
 	tm.mu.Lock()
 	tm.testMode = true
 	tm.virtualStart = virtualStart
-	tm.realStart = time.Now()
+	tm.realStart = realStart
 	tm.timeScale = config.TimeScale
 	tm.mu.Unlock()
 
@@ -92,9 +126,38 @@ func (tm *TimeManager) Now() time.Time {
 	return tm.virtualStart.Add(virtualElapsed)
 }
 
+// Since returns the time elapsed since t, on the same clock as Now
+func (tm *TimeManager) Since(t time.Time) time.Duration {
+	return tm.Now().Sub(t)
+}
+
+// Scale returns how many times faster than wall time the clock runs: 1 on
+// real time, 0 while frozen by SetVirtualTime
+func (tm *TimeManager) Scale() int {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if !tm.testMode {
+		return 1
+	}
+	return tm.timeScale
+}
+
 // IsTestMode returns whether test mode is active
 func (tm *TimeManager) IsTestMode() bool {
 	tm.mu.RLock()
 	defer tm.mu.RUnlock()
 	return tm.testMode
 }
+
+// SetVirtualTime freezes virtual time at t until it is set again. Used when
+// replaying historical data so stored entries keep their original timestamps.
+func (tm *TimeManager) SetVirtualTime(t time.Time) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.testMode = true
+	tm.virtualStart = t
+	tm.realStart = time.Now()
+	tm.timeScale = 0
+}
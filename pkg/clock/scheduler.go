@@ -0,0 +1,314 @@
+package clock
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxWait bounds a single wall-clock wait, so a scheduler notices the test
+// runner reconfiguring virtual time within a minute
+const maxWait = time.Minute
+
+// Schedule tells when a job runs next
+type Schedule interface {
+	// Next returns the first run after after, or the zero time for never
+	Next(after time.Time) time.Time
+}
+
+type every time.Duration
+
+// Every runs a job at a fixed interval
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+func (e every) Next(after time.Time) time.Time {
+	if e <= 0 {
+		return time.Time{}
+	}
+	return after.Add(time.Duration(e))
+}
+
+// ParseSchedule parses a schedule: a duration ("6h" or "@every 6h"), one of
+// @hourly, @daily, @weekly and @monthly, or a cron expression
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		return ParseCron("0 * * * *")
+	case "@daily", "@midnight":
+		return ParseCron("0 0 * * *")
+	case "@weekly":
+		return ParseCron("0 0 * * 0")
+	case "@monthly":
+		return ParseCron("0 0 1 * *")
+	}
+
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		spec = strings.TrimSpace(d)
+	}
+	if d, err := time.ParseDuration(spec); err == nil {
+		if d <= 0 {
+			return nil, fmt.Errorf("schedule interval must be positive: %s", spec)
+		}
+		return Every(d), nil
+	}
+	return ParseCron(spec)
+}
+
+// Cron is a five-field cron schedule (minute hour day-of-month month
+// day-of-week) evaluated in local time. Fields take numbers, *, ranges (1-5),
+// steps (*/15, 0-30/10) and lists (0,30); day-of-week 0 and 7 are Sunday.
+// As in cron, when both days are restricted either one matching is enough.
+// Around daylight saving changes, times in the skipped hour do not run and
+// times in the repeated hour run once, unless the hour is *.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny, hourAny       bool
+	loc                           *time.Location
+}
+
+// ParseCron parses a cron expression such as "30 3 * * *" (03:30 daily)
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: want 5 fields, got %d", expr, len(fields))
+	}
+
+	c := &Cron{loc: time.Local}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid cron minute %q: %w", fields[0], err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid cron hour %q: %w", fields[1], err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid cron day of month %q: %w", fields[2], err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid cron month %q: %w", fields[3], err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid cron day of week %q: %w", fields[4], err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is Sunday too
+	}
+	c.hourAny = fields[1] == "*"
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return c, nil
+}
+
+// parseCronField returns the values a field matches as a bit set
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		start, end := lo, hi
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			n, err := strconv.Atoi(from)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", from)
+			}
+			start = n
+			switch {
+			case isRange:
+				if end, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q", to)
+				}
+			case !hasStep:
+				end = start
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+
+		for v := start; v <= end; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first matching minute after after
+func (c *Cron) Next(after time.Time) time.Time {
+	t := after.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+
+	// Every valid expression matches within a few years (29 February at most
+	// every eight); expressions like "0 0 31 2 *" never do
+	limit := t.AddDate(8, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !has(c.month, int(t.Month())):
+			t = c.date(t.Year(), t.Month()+1, 1, 0)
+		case !c.dayMatches(t):
+			t = c.date(t.Year(), t.Month(), t.Day()+1, 0)
+		case !has(c.hour, t.Hour()):
+			t = c.date(t.Year(), t.Month(), t.Day(), t.Hour()+1)
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		case !c.hourAny && repeated(t):
+			// Second pass through a repeated hour
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// date returns the start of the given hour, the first one when the hour
+// repeats as daylight saving ends
+func (c *Cron) date(year int, month time.Month, day, hour int) time.Time {
+	t := time.Date(year, month, day, hour, 0, 0, 0, c.loc)
+	if repeated(t) {
+		return t.Add(-time.Hour)
+	}
+	return t
+}
+
+// repeated reports whether the wall time of t already occurred an hour earlier
+func repeated(t time.Time) bool {
+	return t.Add(-time.Hour).Format("15:04") == t.Format("15:04")
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<v) != 0
+}
+
+// Scheduler delivers a job's run times on C, like time.Ticker but following a
+// Schedule on a Clock. On a TimeManager in test mode the waits shrink with the
+// time scale, so a daily job runs every few minutes of a 300x scenario. A run
+// is dropped when the receiver is still busy with the previous one.
+type Scheduler struct {
+	C <-chan time.Time
+
+	clock  Clock
+	jitter time.Duration
+	c      chan time.Time
+	reset  chan Schedule
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// NewScheduler starts delivering runs of schedule. Each run is delayed by a
+// random amount up to jitter, so jobs sharing a schedule do not start at once.
+func NewScheduler(clk Clock, schedule Schedule, jitter time.Duration) *Scheduler {
+	c := make(chan time.Time, 1)
+	s := &Scheduler{
+		C:      c,
+		clock:  clk,
+		jitter: jitter,
+		c:      c,
+		reset:  make(chan Schedule),
+		stop:   make(chan struct{}),
+	}
+	go s.run(schedule)
+	return s
+}
+
+// Reset switches to schedule, counting from now
+func (s *Scheduler) Reset(schedule Schedule) {
+	select {
+	case s.reset <- schedule:
+	case <-s.stop:
+	}
+}
+
+// Stop stops delivering runs. It does not close C.
+func (s *Scheduler) Stop() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *Scheduler) run(schedule Schedule) {
+	from := s.clock.Now()
+	next := s.next(schedule, from)
+	timer := time.NewTimer(s.wait(next))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case schedule = <-s.reset:
+			from = s.clock.Now()
+			next = s.next(schedule, from)
+		case <-timer.C:
+			now := s.clock.Now()
+			switch {
+			case now.Before(from):
+				// The clock went back, e.g. a scenario set an earlier virtual start
+				from = now
+				next = s.next(schedule, now)
+			case !next.IsZero() && !now.Before(next):
+				select {
+				case s.c <- now:
+				default:
+				}
+				from = now
+				next = s.next(schedule, now)
+			}
+		}
+		timer.Reset(s.wait(next))
+	}
+}
+
+// next returns schedule's next run after from, with jitter
+func (s *Scheduler) next(schedule Schedule, from time.Time) time.Time {
+	next := schedule.Next(from)
+	if next.IsZero() || s.jitter <= 0 {
+		return next
+	}
+	return next.Add(rand.N(s.jitter))
+}
+
+// wait returns the wall-clock time until next on the scheduler's clock
+func (s *Scheduler) wait(next time.Time) time.Duration {
+	if next.IsZero() {
+		return maxWait
+	}
+
+	scale := 1
+	if scaled, ok := s.clock.(interface{ Scale() int }); ok {
+		scale = scaled.Scale()
+	}
+	if scale <= 0 {
+		// Frozen virtual time only moves when set, so poll
+		return maxWait
+	}
+
+	d := next.Sub(s.clock.Now()) / time.Duration(scale)
+	return max(0, min(d, maxWait))
+}
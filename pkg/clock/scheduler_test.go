@@ -0,0 +1,148 @@
+package clock
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustCron(t *testing.T, expr string, loc *time.Location) *Cron {
+	t.Helper()
+	c, err := ParseCron(expr)
+	if err != nil {
+		t.Fatalf("ParseCron(%q): %v", expr, err)
+	}
+	c.loc = loc
+	return c
+}
+
+func TestCronNext(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	// 2025-10-15 is a Wednesday
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"step", "*/15 * * * *", utc(2025, 10, 15, 10, 7), utc(2025, 10, 15, 10, 15)},
+		{"strictly after", "*/15 * * * *", utc(2025, 10, 15, 10, 15), utc(2025, 10, 15, 10, 30)},
+		{"stepped range", "0-30/10 * * * *", utc(2025, 10, 15, 10, 25), utc(2025, 10, 15, 10, 30)},
+		{"stepped range wraps to next hour", "0-30/10 * * * *", utc(2025, 10, 15, 10, 31), utc(2025, 10, 15, 11, 0)},
+		{"list and range", "0,30 9-17 * * *", utc(2025, 10, 15, 17, 31), utc(2025, 10, 16, 9, 0)},
+		{"fixed time", "30 3 * * *", utc(2025, 10, 15, 3, 30), utc(2025, 10, 16, 3, 30)},
+		{"dow 0 is Sunday", "0 0 * * 0", utc(2025, 10, 15, 12, 0), utc(2025, 10, 19, 0, 0)},
+		{"dow 7 is Sunday", "0 0 * * 7", utc(2025, 10, 15, 12, 0), utc(2025, 10, 19, 0, 0)},
+		{"dow range ending in 7", "0 0 * * 5-7", utc(2025, 10, 15, 12, 0), utc(2025, 10, 17, 0, 0)},
+		{"weekdays", "0 0 * * 1-5", utc(2025, 10, 17, 12, 0), utc(2025, 10, 20, 0, 0)},
+		{"dom only", "0 0 13 * *", utc(2025, 10, 15, 12, 0), utc(2025, 11, 13, 0, 0)},
+		{"dom or dow, dow first", "0 0 13 * 5", utc(2025, 10, 15, 12, 0), utc(2025, 10, 17, 0, 0)},
+		{"dom or dow, dom first", "0 0 16 * 5", utc(2025, 10, 15, 12, 0), utc(2025, 10, 16, 0, 0)},
+		{"month", "0 12 * 6 *", utc(2025, 10, 15, 12, 0), utc(2026, 6, 1, 12, 0)},
+		{"leap day", "0 0 29 2 *", utc(2025, 3, 1, 0, 0), utc(2028, 2, 29, 0, 0)},
+		{"impossible date", "0 0 31 2 *", utc(2025, 10, 15, 12, 0), time.Time{}},
+		{"31st skips short months", "0 0 31 * *", utc(2025, 10, 31, 12, 0), utc(2025, 12, 31, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mustCron(t, tt.expr, time.UTC).Next(tt.after)
+			if !got.Equal(tt.want) {
+				t.Errorf("%q after %s: expected %s, got %s", tt.expr, tt.after, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCronNext_DaylightSaving(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, helsinki)
+	}
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	// Clocks go from 03:00 to 04:00 on 30 March and from 04:00 back to 03:00
+	// on 26 October
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"skipped hour does not run", "30 3 * * *", local(3, 30, 0, 0), local(3, 31, 3, 30)},
+		{"wildcard hour continues after the gap", "*/30 * * * *", local(3, 30, 2, 45), utc(3, 30, 1, 0)},
+		{"repeated hour runs first", "30 3 * * *", local(10, 26, 0, 0), utc(10, 26, 0, 30)},
+		{"repeated hour runs once", "30 3 * * *", utc(10, 26, 0, 30), local(10, 27, 3, 30)},
+		{"wildcard hour runs in both passes", "*/30 * * * *", utc(10, 26, 0, 30), utc(10, 26, 1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mustCron(t, tt.expr, helsinki).Next(tt.after)
+			if !got.Equal(tt.want) {
+				t.Errorf("%q after %s: expected %s, got %s", tt.expr, tt.after.In(helsinki), tt.want.In(helsinki), got.In(helsinki))
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	after := time.Date(2025, 10, 15, 10, 7, 0, 0, time.Local)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"6h", after.Add(6 * time.Hour)},
+		{"@every 90m", after.Add(90 * time.Minute)},
+		{"@hourly", time.Date(2025, 10, 15, 11, 0, 0, 0, time.Local)},
+		{"@daily", time.Date(2025, 10, 16, 0, 0, 0, 0, time.Local)},
+		{"@weekly", time.Date(2025, 10, 19, 0, 0, 0, 0, time.Local)},
+		{"@monthly", time.Date(2025, 11, 1, 0, 0, 0, 0, time.Local)},
+		{"30 3 * * *", time.Date(2025, 10, 16, 3, 30, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.spec)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.Next(after); !got.Equal(tt.want) {
+			t.Errorf("%q: expected %s, got %s", tt.spec, tt.want, got)
+		}
+	}
+
+	for _, spec := range []string{"0s", "-1h", "@every", "@yearly", "bogus"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -11,12 +12,22 @@ import (
 
 // Config holds the configuration for a J.E.E.V.E.S. agent
 type Config struct {
+	// HouseholdID scopes MQTT topics and Postgres rows to one home, so several
+	// homes can share a broker and a database; empty for a single household
+	HouseholdID string
+
 	// MQTT configuration
 	MQTTBroker   string
 	MQTTPort     int
 	MQTTUser     string
 	MQTTPassword string
 	MQTTClientID string
+	// MQTTTopicPrefix is prepended to every topic the agent publishes or
+	// subscribes to, isolating parallel e2e scenarios on one broker
+	MQTTTopicPrefix string
+	// MQTTPublishTimeout bounds the wait for the broker to acknowledge a
+	// publish (0 = wait indefinitely)
+	MQTTPublishTimeout time.Duration
 
 	// Redis configuration
 	RedisHost     string
@@ -31,16 +42,31 @@ type Config struct {
 	PostgresPassword string
 	PostgresDB       string
 	PostgresSSLMode  string
+	PostgresSchema   string // search_path schema; public when empty
 
 	// PostgreSQL connection pool settings
 	PostgresMaxConnections     int
 	PostgresMaxIdleConnections int
 	PostgresConnMaxLifetime    time.Duration
 
+	// Encryption at rest of episode documents and anchor context (AES-256-GCM)
+	EncryptionKey     string // base64 32-byte key (empty = not encrypted)
+	EncryptionKeyFile string // file holding the base64 key, used when EncryptionKey is empty
+
 	// Service configuration
 	ServiceName string
 	HealthPort  int
 	LogLevel    string
+	LogFormat   string // text or json
+	LogLevels   string // per-module overrides, e.g. "behavior=debug,distance=warn"
+
+	// Shadow mode: actuation agents publish intended actions to automation/shadow/*
+	// instead of commanding devices
+	ShadowMode bool
+
+	// ChaosEnabled lets e2e scenarios inject faults through
+	// automation/test/chaos; never enable outside tests
+	ChaosEnabled bool
 
 	// Agent-specific configuration (can be extended by agents)
 	SensorTopics          []string
@@ -48,6 +74,25 @@ type Config struct {
 	EnableVictoriaMetrics bool
 	VictoriaMetricsURL    string
 
+	// InfluxDB raw sensor archive (collector)
+	InfluxURL         string   // InfluxDB base URL (empty = archive disabled)
+	InfluxOrg         string   // InfluxDB organization
+	InfluxBucket      string   // Bucket receiving sensor events
+	InfluxToken       string   // API token
+	InfluxSensorTypes []string // Sensor types to archive (empty = all)
+
+	// Dead-letter queue for sensor messages the collector cannot parse
+	CollectorDLQSize int // Entries kept in dlq:sensor (0 = disabled)
+
+	// Collector ingest buffering
+	CollectorQueueSize  int    // Messages buffered in memory (0 = process inline)
+	CollectorSpoolDir   string // Directory for overflow to disk (empty = drop on overflow)
+	CollectorSpoolMaxMB int    // Spool file size limit (0 = unbounded)
+
+	// Privacy zoning: data never kept beyond transient occupancy, never learned from
+	PrivacyExcludedLocations   []string // Locations whose data is not stored, learned or sent to the LLM
+	PrivacyExcludedSensorTypes []string // Sensor types that are dropped everywhere
+
 	// Illuminance agent configuration
 	Latitude            float64
 	Longitude           float64
@@ -55,34 +100,117 @@ type Config struct {
 	MaxDataAgeHours     float64
 	MinReadingsRequired int
 
+	// Per-room daylight model
+	IlluminanceWindows         []string // location=orientation:factor, e.g. living_room=south:0.03
+	IlluminanceOutdoorLocation string   // location of an outdoor lux sensor used for cloud correction
+	IlluminanceTargetLux       float64  // indoor lux that counts as sufficient natural light
+
 	// Light agent configuration
 	DecisionIntervalSec   int
 	ManualOverrideMinutes int
 	MinDecisionIntervalMs int
 	APIPort               int
 
+	// Pattern scenes: light preferences for active behavioral patterns (Postgres)
+	LightPatternScenes          bool
+	LightPatternMinConfidence   float64 // minimum prediction confidence to activate a pattern
+	LightPatternFeedbackMinutes int     // a manual change within this window counts as a rejected scene
+
+	// Learn preferred brightness/color temperature from manual adjustments (Postgres)
+	LightPreferenceLearning        bool
+	LightPreferenceMinObservations int // adjustments needed before a learned preference is applied
+
+	// Named multi-light scenes per room (Postgres), selected by decisions and wall switches
+	LightScenes bool
+
 	// Occupancy agent configuration
 	OccupancyAnalysisIntervalSec int
 	LLMEndpoint                  string
 	LLMModel                     string
 	LLMMinConfidence             float64
+	LLMPromptDir                 string // directory of prompt template overrides, empty for built-in prompts
 	MaxEventHistory              int
 
+	// Per-task LLM models (empty uses LLMModel)
+	LLMDistanceModel       string // pairwise distance rating; a small, fast model
+	LLMConsolidationModel  string
+	LLMInterpretationModel string // pattern interpretation
+	LLMBriefingModel       string // morning briefing text
+
+	// LLM availability (behavior agent)
+	LLMProbeInterval time.Duration // How often Ollama is probed; 0 disables probing and warm-up
+	LLMWarmUp        bool          // Load the configured models with a small generation when Ollama comes up
+
+	// Occupancy sensor fusion weights (0 disables a signal)
+	OccupancyPresenceWeight     float64
+	OccupancyDoorWeight         float64
+	OccupancyPowerWeight        float64
+	OccupancyMediaWeight        float64
+	OccupancyPowerThresholdWatt float64
+
+	// Per-location decay profiles, e.g. bathroom=fast, study=15:25:40
+	OccupancyDecayProfiles []string
+
+	// Training mode: record ground-truth labels to Postgres
+	OccupancyTrainingMode bool
+	OccupancyLabelReport  int // print label accuracy for the last N days and exit (0 = run the agent)
+
+	// Corpus evaluation: run labeled abstractions through LLM and fallback and exit
+	OccupancyEvalCorpus      string  // directory of labeled TemporalAbstraction cases (empty = run the agent)
+	OccupancyEvalBaseline    string  // earlier report; regressions against it fail the run, written when missing
+	OccupancyEvalMinAccuracy float64 // fail the run when an analyzer scores below this (0 = no gate)
+
 	// Consolidation settings
-	ConsolidationIntervalHours int
+	ConsolidationIntervalHours int    // deprecated: runs consolidation every N hours when ConsolidationSchedule is empty
+	ConsolidationSchedule      string // cron expression or interval ("30 3 * * *", "24h"); empty = trigger-only
 	ConsolidationLookbackHours int
 	ConsolidationMaxGapMinutes int
+	ConsolidationWorkers       int // locations processed concurrently during consolidation
+
+	// Random delay added to each scheduled consolidation, distance and
+	// discovery run, so they do not all start at once (0 = none)
+	ScheduleJitter time.Duration
 
 	// Pattern Discovery configuration
 	PatternDiscoveryEnabled        bool
 	PatternDistanceStrategy        string // "llm_first", "progressive_learned"
 	PatternDiscoveryIntervalHours  int
+	PatternDiscoverySchedule       string // cron expression or interval, overrides PatternDiscoveryIntervalHours
 	PatternDiscoveryBatchSize      int
+	DistanceMaxBatchSize           int           // Auto-tuning bound for the distance batch size (<= batch size = disabled)
+	DistanceMinInterval            time.Duration // Auto-tuning bound for the distance interval
+	DistanceTargetDrain            time.Duration // Distance backlog should drain within this (0 = no auto-tuning)
 	PatternClusteringEpsilon       float64
 	PatternClusteringMinPoints     int
+	PatternClusteringAlgorithm     string // "dbscan" (fixed epsilon) or "hdbscan" (density-adaptive)
+	PatternClusteringWorkers       int    // Goroutines for distance matrix and neighborhood queries (0 = NumCPU)
+	PatternClusteringMemoryMB      int    // Distance matrix budget; larger runs are clustered in temporal blocks (0 = unbounded)
+	PatternDistanceCacheSize       int    // Anchor-pair distances kept in the LRU cache (0 = disabled)
+	ANNProbes                      int    // ivfflat.probes for anchor similarity search (0 = server default)
+	ANNEfSearch                    int    // hnsw.ef_search for anchor similarity search (0 = server default)
+	HomeTopologyPath               string // JSON file with rooms, floors and adjacency (empty = built-in floor plan)
+	AdjacencyLearningEnabled       bool          // Add room pairs with frequent episode transitions to the topology
+	AdjacencyLearningInterval      time.Duration // How often learned adjacency is refreshed
+	AdjacencyMinTransitions        int           // Minimum observed transitions for a learned adjacent pair
+	RhythmProfileWeeks             int           // Weeks of episodes the household rhythm embedding block is learned from (0 = heuristics)
+	RhythmProfileInterval          time.Duration // How often the household rhythm profile is recomputed
 	PatternMinAnchorsForDiscovery  int
 	PatternLookbackHours           int
 	ProgressiveActivityEmbeddings  bool // Enable LLM-based activity embeddings with caching
+	PatternIncrementalAssignment   bool // Attach new anchors to existing patterns on creation; cluster only unassigned anchors
+	VerifyQueueEnabled             bool // Drain pattern_relearning_queue with LLM verification during idle hours
+	VerifyRatePerMinute            int  // Max LLM verification calls per minute
+	VerifyIdleStartHour            int  // Idle window start (local hour, inclusive)
+	VerifyIdleEndHour              int  // Idle window end (local hour, exclusive); equal to start = always idle
+	VerifySeasonalRefresh          bool // Queue learned patterns for verification after season changes and DST transitions
+	DistanceAgreementInterval      time.Duration // How often vector/learned distances are cross-validated against LLM labels (0 = disabled)
+	DistanceAgreementSamples       int           // LLM-labeled pairs sampled per agreement report
+
+	// One-shot commands (behavior-agent exits afterwards)
+	ExportPatternsPath string // Write learned patterns to this JSON file
+	ImportPatternsPath string // Load learned patterns from this JSON file
+	FitDistanceWeights bool   // Fit structured distance block weights to LLM-labeled pairs
+	ReembedAnchors     bool   // Recompute anchor embeddings from an older embedding version
 
 	// Temporal Grouping configuration
 	TemporalGroupingEnabled       bool
@@ -92,6 +220,53 @@ type Config struct {
 	// Location-Temporal Clustering (NEW)
 	UseLocationTemporalClustering bool // Use location-aware temporal density clustering instead of DBSCAN
 
+	// Multi-occupant tracking
+	PerOccupantClustering bool // Cluster anchors separately for each attributed occupant
+
+	// House state (away/vacation) detection
+	HouseStateEnabled       bool          // Detect whole-house inactivity and publish house_state
+	HouseAwayThreshold      time.Duration // Inactivity before the house is considered away
+	HouseVacationThreshold  time.Duration // Inactivity before the house is considered on vacation
+	HouseStateCheckInterval time.Duration // How often inactivity is evaluated
+
+	// Sleep detection
+	SleepDetectionEnabled    bool          // Detect nightly sleep as "Sleeping" macro-episodes
+	SleepLocation            string        // Room where sleep is detected (e.g., "bedroom")
+	SleepMinDuration         time.Duration // Shortest period accepted as sleep
+	SleepInactivityThreshold time.Duration // Inactivity after lights-off before sleep is assumed
+	SleepAnalysisHour        int           // Hour of day (0-23) to run the nightly analysis
+
+	// Sensor dropout tolerance
+	SensorDropoutEnabled   bool          // Detect silent sensors so dropouts don't split episodes
+	SensorDropoutMinGap    time.Duration // Shortest silence considered a dropout
+	SensorDropoutFactor    float64       // Silence must also exceed this multiple of the sensor's median interval
+	SensorDropoutMinEvents int           // Events a sensor needs in the window to count as chatty
+
+	// Late-arriving sensor data
+	LateDataEnabled       bool          // Re-consolidate windows that receive sensor events after consolidation
+	LateDataCheckInterval time.Duration // How often Redis is checked for late events
+	LateDataMinDelay      time.Duration // Collection delay after which an event counts as late
+
+	// Behavior HTTP API (episode annotations)
+	BehaviorAPIEnabled     bool // Serve the behavior agent HTTP API
+	BehaviorAPIPort        int  // Port for the behavior agent HTTP API
+	AnnotationFewShotLimit int  // Max user annotations included as LLM consolidation examples
+
+	// Next-activity prediction
+	PredictionEnabled          bool          // Publish next-activity forecasts from learned patterns
+	PredictionMinConfidence    float64       // Minimum confidence for a prediction to be published
+	PredictionHorizon          time.Duration // How long a prediction stays open before it counts as rejected
+	PredictionMaxTransitionGap time.Duration // Longest gap between anchors treated as a transition
+	PredictionSnoozeDuration   time.Duration // How long a snoozed pattern stays silent when feedback gives no duration
+
+	// Pattern lifecycle maintenance
+	PatternLifecycleEnabled  bool          // Merge, decay, and archive patterns periodically
+	PatternLifecycleInterval time.Duration // How often lifecycle maintenance runs
+	PatternMergeSimilarity   float64       // Centroid cosine similarity at which patterns are merged
+	PatternDecayAfterWeeks   int           // Weeks unseen before a pattern's weight decays
+	PatternDecayFactor       float64       // Weight multiplier applied per maintenance run while unseen
+	PatternArchiveAfterWeeks int           // Weeks unseen before a fully decayed pattern is archived
+
 	// Batch Processing configuration (sliding window)
 	BatchProcessingEnabled  bool          // Enable sliding window batch processing
 	BatchDuration           time.Duration // Duration of each batch window (e.g., 2 hours)
@@ -99,6 +274,70 @@ type Config struct {
 	BatchScheduleEnabled    bool          // Enable automatic batch scheduling (vs manual MQTT trigger)
 	BatchScheduleInterval   time.Duration // Interval between automatic batch runs
 	BatchMetadataEnabled    bool          // Store batch metadata (batch_id, timestamps) for debugging
+
+	// Observer
+	ObserverPort int // Port of the observer UI and HTTP API
+
+	// Observer HTTP API authentication
+	ObserverAuthMode       string   // "none", "token" (static bearer tokens) or "oidc" (JWTs from an OpenID provider)
+	ObserverViewerTokens   []string // Static tokens granted read-only access
+	ObserverAdminTokens    []string // Static tokens granted admin access (consolidation, data deletion)
+	ObserverOIDCIssuer     string   // OpenID provider issuer URL
+	ObserverOIDCAudience   string   // Required "aud" claim (client ID)
+	ObserverOIDCRolesClaim string   // Claim holding role names; dots address nested claims (e.g. "realm_access.roles")
+	ObserverOIDCAdminRole  string   // Role name granting admin access; any other valid token is a viewer
+
+	// Behavior summary reports
+	ReportPublishEnabled bool     // Generate daily/weekly reports on schedule and push them via MQTT (and e-mail if configured)
+	ReportHour           int      // Local hour (0-23) when the previous day's report is generated; weekly reports go out on Mondays
+	ReportEmailTo        []string // Report e-mail recipients (empty disables e-mail)
+	SMTPHost             string   // SMTP server for report e-mail
+	SMTPPort             int      // SMTP server port
+	SMTPUser             string   // SMTP username (empty for unauthenticated relay)
+	SMTPPassword         string   // SMTP password
+
+	// Morning briefing (observer)
+	BriefingEnabled bool   // Write an LLM briefing about the previous day on schedule and publish it via MQTT
+	BriefingHour    int    // Local hour (0-23) when the briefing is generated
+	BriefingTone    string // Tone the LLM is asked to write in
+	SMTPFrom             string   // Sender address for report e-mail
+
+	// Webhook notifications
+	WebhookConfigPath string // JSON file defining webhook sinks for behavior events (empty = disabled)
+
+	// Push notifications (notify-agent)
+	PushoverAppToken       string   // Pushover application token
+	PushoverUserKey        string   // Pushover user or group key
+	TelegramBotToken       string   // Telegram bot token
+	TelegramChatID         string   // Telegram chat receiving notifications
+	NotifyRoutes           []string // event=channel routes, e.g. anomaly=pushover (empty = every event to every channel)
+	NotifyQuietStartHour   int      // Quiet hours start (local hour, inclusive); notifications are held until they end
+	NotifyQuietEndHour     int      // Quiet hours end (local hour, exclusive); equal to start = no quiet hours
+	NotifyRateLimitPerHour int      // Max notifications per channel per hour (0 = unlimited)
+
+	// Weather service (weather-agent)
+	WeatherProvider        string // open-meteo or met-norway
+	WeatherPollIntervalMin int    // Minutes between weather fetches
+	WeatherUserAgent       string // User-Agent sent to the weather service (MET Norway requires contact details)
+
+	// Home Assistant bridge
+	HassDiscoveryPrefix string   // HA MQTT discovery prefix
+	HassNodeID          string   // Node ID grouping the Jeeves entities in HA
+	HassEventTopic      string   // Topic HA's mqtt_eventstream publishes state_changed events to
+	HassEntityMap       []string // entity_id=location or entity_id=sensor_type:location mappings for ingested entities
+
+	// Media agent
+	MediaPlayerMap       []string      // player=location mappings (sonos:<device>, chromecast:<device> or media_player.<entity>)
+	MediaSonosTopic      string        // sonos2mqtt state topic prefix
+	MediaChromecastTopic string        // Chromecast media status topic prefix
+	MediaPauseTimeout    time.Duration // A paused playback session ends after this
+
+	// Supervisor (cmd/jeeves): agents run as goroutines of one process
+	RunBehavior    bool
+	RunIlluminance bool
+	RunOccupancy   bool
+	RunLight       bool
+	RunObserver    bool
 }
 
 // NewConfig creates a new Config with default values
@@ -109,6 +348,7 @@ func NewConfig() *Config {
 		MQTTUser:                   "",
 		MQTTPassword:               "",
 		MQTTClientID:               "",
+		MQTTPublishTimeout:         10 * time.Second,
 		RedisHost:                  "localhost",
 		RedisPort:                  6379,
 		RedisPassword:              "",
@@ -125,8 +365,12 @@ func NewConfig() *Config {
 		ServiceName:                "jeeves-agent",
 		HealthPort:                 8080,
 		LogLevel:                   "info",
+		LogFormat:                  "text",
 		SensorTopics:               []string{"automation/raw/+/+"},
 		MaxSensorHistory:           1000,
+		CollectorDLQSize:           1000,
+		CollectorQueueSize:         1000,
+		CollectorSpoolMaxMB:        100,
 		EnableVictoriaMetrics:      false,
 		VictoriaMetricsURL:         "",
 		// Illuminance agent defaults (Helsinki coordinates)
@@ -135,35 +379,106 @@ func NewConfig() *Config {
 		AnalysisIntervalSec: 30,
 		MaxDataAgeHours:     1.0,
 		MinReadingsRequired: 3,
+		IlluminanceOutdoorLocation: "outdoor",
+		IlluminanceTargetLux:       300,
 		// Light agent defaults
 		DecisionIntervalSec:   30,
 		ManualOverrideMinutes: 30,
 		MinDecisionIntervalMs: 10000,
 		APIPort:               3002,
+		LightPatternMinConfidence:   0.6,
+		LightPatternFeedbackMinutes: 10,
+		LightPreferenceMinObservations: 3,
 		// Occupancy agent defaults
 		OccupancyAnalysisIntervalSec: 30,
 		LLMEndpoint:                  "http://localhost:11434",
 		LLMModel:                     "mixtral:8x7b",
 		LLMMinConfidence:             0.7,
+		LLMProbeInterval:             time.Minute,
+		LLMWarmUp:                    true,
 		MaxEventHistory:              100,
+		OccupancyPresenceWeight:      1.0,
+		OccupancyDoorWeight:          0.3,
+		OccupancyPowerWeight:         0.5,
+		OccupancyMediaWeight:         0.7,
+		OccupancyPowerThresholdWatt:  30,
 		// Consolidation defaults
-		ConsolidationIntervalHours: 24,
+		ConsolidationIntervalHours: 0,
 		ConsolidationLookbackHours: 48,
 		ConsolidationMaxGapMinutes: 120,
+		ConsolidationWorkers:       4,
 		// Pattern Discovery defaults
 		PatternDiscoveryEnabled:       false,
 		PatternDistanceStrategy:       "progressive_learned",
 		PatternDiscoveryIntervalHours: 6,
 		PatternDiscoveryBatchSize:     100,
+		DistanceMaxBatchSize:          1000,
+		DistanceMinInterval:           30 * time.Minute,
+		DistanceTargetDrain:           24 * time.Hour,
 		PatternClusteringEpsilon:      0.3,
 		PatternClusteringMinPoints:    3,
+		PatternClusteringAlgorithm:    "dbscan",
+		PatternClusteringWorkers:      0,
+		PatternDistanceCacheSize:      500000,
+		ANNProbes:                     10,
+		ANNEfSearch:                   64,
+		AdjacencyLearningEnabled:      true,
+		AdjacencyLearningInterval:     24 * time.Hour,
+		AdjacencyMinTransitions:       10,
+		RhythmProfileWeeks:            4,
+		RhythmProfileInterval:         7 * 24 * time.Hour,
 		PatternMinAnchorsForDiscovery: 10,
 		PatternLookbackHours:          168, // 7 days
 		ProgressiveActivityEmbeddings: false, // Disabled by default
+		PatternIncrementalAssignment:  true,
+		VerifyQueueEnabled:            true,
+		VerifyRatePerMinute:           6,
+		VerifyIdleStartHour:           1,
+		VerifyIdleEndHour:             6,
+		VerifySeasonalRefresh:         true,
+		DistanceAgreementInterval:     24 * time.Hour,
+		DistanceAgreementSamples:      500,
 		// Temporal Grouping defaults
 		TemporalGroupingEnabled:       true,
 		TemporalGroupingWindowMinutes: 60,  // 60 minute window (better for longer activities)
 		TemporalGroupingOverlapRatio:  0.5, // 50% overlap = parallel
+		// House state defaults
+		HouseStateEnabled:       true,
+		HouseAwayThreshold:      4 * time.Hour,
+		HouseVacationThreshold:  48 * time.Hour,
+		HouseStateCheckInterval: 5 * time.Minute,
+		// Sleep detection defaults
+		SleepDetectionEnabled:    true,
+		SleepLocation:            "bedroom",
+		SleepMinDuration:         3 * time.Hour,
+		SleepInactivityThreshold: 30 * time.Minute,
+		SleepAnalysisHour:        10,
+		// Sensor dropout defaults
+		SensorDropoutEnabled:   true,
+		SensorDropoutMinGap:    30 * time.Minute,
+		SensorDropoutFactor:    10,
+		SensorDropoutMinEvents: 20,
+		// Late data defaults
+		LateDataEnabled:       true,
+		LateDataCheckInterval: 10 * time.Minute,
+		LateDataMinDelay:      2 * time.Minute,
+		// Behavior API defaults
+		BehaviorAPIEnabled:     true,
+		BehaviorAPIPort:        3003,
+		AnnotationFewShotLimit: 5,
+		// Prediction defaults
+		PredictionEnabled:          true,
+		PredictionMinConfidence:    0.3,
+		PredictionHorizon:          30 * time.Minute,
+		PredictionMaxTransitionGap: 30 * time.Minute,
+		PredictionSnoozeDuration:   2 * time.Hour,
+		// Pattern lifecycle defaults
+		PatternLifecycleEnabled:  true,
+		PatternLifecycleInterval: 24 * time.Hour,
+		PatternMergeSimilarity:   0.95,
+		PatternDecayAfterWeeks:   4,
+		PatternDecayFactor:       0.8,
+		PatternArchiveAfterWeeks: 12,
 		// Batch Processing defaults
 		BatchProcessingEnabled:  false,          // Disabled by default, use traditional approach
 		BatchDuration:           2 * time.Hour,  // 2 hour batch windows
@@ -171,6 +486,38 @@ func NewConfig() *Config {
 		BatchScheduleEnabled:    false,          // Manual MQTT trigger by default
 		BatchScheduleInterval:   2 * time.Hour,  // Run every 2 hours if enabled
 		BatchMetadataEnabled:    true,           // Store metadata for debugging
+		ObserverPort: 8080,
+		// Observer auth defaults
+		ObserverAuthMode:       "none",
+		ObserverOIDCRolesClaim: "roles",
+		ObserverOIDCAdminRole:  "admin",
+		// Report defaults
+		ReportPublishEnabled: false,
+		ReportHour:           7,
+		SMTPPort:             587,
+		// Briefing defaults
+		BriefingHour: 7,
+		BriefingTone: "warm, concise and gently witty, like an attentive butler",
+		// Notification defaults
+		NotifyRateLimitPerHour: 10,
+		// Weather defaults
+		WeatherProvider:        "open-meteo",
+		WeatherPollIntervalMin: 15,
+		WeatherUserAgent:       "jeeves-platform/2.0 github.com/saaga0h/jeeves",
+		// Home Assistant bridge defaults
+		HassDiscoveryPrefix: "homeassistant",
+		HassNodeID:          "jeeves",
+		HassEventTopic:      "homeassistant/events",
+		// Media agent defaults
+		MediaSonosTopic:      "sonos",
+		MediaChromecastTopic: "chromecast",
+		MediaPauseTimeout:    15 * time.Minute,
+		// Supervisor defaults
+		RunBehavior:    true,
+		RunIlluminance: true,
+		RunOccupancy:   true,
+		RunLight:       true,
+		RunObserver:    true,
 	}
 }
 
@@ -194,6 +541,17 @@ func (c *Config) LoadFromEnv() {
 	if v := os.Getenv("JEEVES_MQTT_CLIENT_ID"); v != "" {
 		c.MQTTClientID = v
 	}
+	if v := os.Getenv("JEEVES_MQTT_TOPIC_PREFIX"); v != "" {
+		c.MQTTTopicPrefix = v
+	}
+	if v := os.Getenv("JEEVES_MQTT_PUBLISH_TIMEOUT"); v != "" {
+		if timeout, err := time.ParseDuration(v); err == nil {
+			c.MQTTPublishTimeout = timeout
+		}
+	}
+	if v := os.Getenv("JEEVES_HOUSEHOLD_ID"); v != "" {
+		c.HouseholdID = v
+	}
 
 	// Redis configuration
 	if v := os.Getenv("JEEVES_REDIS_HOST"); v != "" {
@@ -234,6 +592,9 @@ func (c *Config) LoadFromEnv() {
 	if v := os.Getenv("JEEVES_POSTGRES_SSLMODE"); v != "" {
 		c.PostgresSSLMode = v
 	}
+	if v := os.Getenv("JEEVES_POSTGRES_SCHEMA"); v != "" {
+		c.PostgresSchema = v
+	}
 	if v := os.Getenv("JEEVES_POSTGRES_MAX_OPEN_CONNS"); v != "" {
 		if maxConns, err := strconv.Atoi(v); err == nil {
 			c.PostgresMaxConnections = maxConns
@@ -250,6 +611,14 @@ func (c *Config) LoadFromEnv() {
 		}
 	}
 
+	// Encryption at rest
+	if v := os.Getenv("JEEVES_ENCRYPTION_KEY"); v != "" {
+		c.EncryptionKey = v
+	}
+	if v := os.Getenv("JEEVES_ENCRYPTION_KEY_FILE"); v != "" {
+		c.EncryptionKeyFile = v
+	}
+
 	// Service configuration
 	if v := os.Getenv("JEEVES_SERVICE_NAME"); v != "" {
 		c.ServiceName = v
@@ -262,6 +631,22 @@ func (c *Config) LoadFromEnv() {
 	if v := os.Getenv("JEEVES_LOG_LEVEL"); v != "" {
 		c.LogLevel = v
 	}
+	if v := os.Getenv("JEEVES_LOG_FORMAT"); v != "" {
+		c.LogFormat = v
+	}
+	if v := os.Getenv("JEEVES_LOG_LEVELS"); v != "" {
+		c.LogLevels = v
+	}
+	if v := os.Getenv("JEEVES_SHADOW_MODE"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.ShadowMode = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_CHAOS_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.ChaosEnabled = enabled
+		}
+	}
 
 	// Agent-specific configuration
 	if v := os.Getenv("JEEVES_MAX_SENSOR_HISTORY"); v != "" {
@@ -278,6 +663,49 @@ func (c *Config) LoadFromEnv() {
 		c.VictoriaMetricsURL = v
 	}
 
+	// InfluxDB archive configuration
+	if v := os.Getenv("JEEVES_INFLUX_URL"); v != "" {
+		c.InfluxURL = v
+	}
+	if v := os.Getenv("JEEVES_INFLUX_ORG"); v != "" {
+		c.InfluxOrg = v
+	}
+	if v := os.Getenv("JEEVES_INFLUX_BUCKET"); v != "" {
+		c.InfluxBucket = v
+	}
+	if v := os.Getenv("JEEVES_INFLUX_TOKEN"); v != "" {
+		c.InfluxToken = v
+	}
+	if v := os.Getenv("JEEVES_INFLUX_SENSOR_TYPES"); v != "" {
+		c.InfluxSensorTypes = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_COLLECTOR_DLQ_SIZE"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			c.CollectorDLQSize = size
+		}
+	}
+	if v := os.Getenv("JEEVES_COLLECTOR_QUEUE_SIZE"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			c.CollectorQueueSize = size
+		}
+	}
+	if v := os.Getenv("JEEVES_COLLECTOR_SPOOL_DIR"); v != "" {
+		c.CollectorSpoolDir = v
+	}
+	if v := os.Getenv("JEEVES_COLLECTOR_SPOOL_MAX_MB"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			c.CollectorSpoolMaxMB = size
+		}
+	}
+
+	// Privacy zoning
+	if v := os.Getenv("JEEVES_PRIVACY_EXCLUDED_LOCATIONS"); v != "" {
+		c.PrivacyExcludedLocations = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_PRIVACY_EXCLUDED_SENSOR_TYPES"); v != "" {
+		c.PrivacyExcludedSensorTypes = splitList(v)
+	}
+
 	// Illuminance agent configuration
 	if v := os.Getenv("JEEVES_LATITUDE"); v != "" {
 		if lat, err := strconv.ParseFloat(v, 64); err == nil {
@@ -304,6 +732,17 @@ func (c *Config) LoadFromEnv() {
 			c.MinReadingsRequired = minReadings
 		}
 	}
+	if v := os.Getenv("JEEVES_ILLUMINANCE_WINDOWS"); v != "" {
+		c.IlluminanceWindows = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_ILLUMINANCE_OUTDOOR_LOCATION"); v != "" {
+		c.IlluminanceOutdoorLocation = v
+	}
+	if v := os.Getenv("JEEVES_ILLUMINANCE_TARGET_LUX"); v != "" {
+		if lux, err := strconv.ParseFloat(v, 64); err == nil {
+			c.IlluminanceTargetLux = lux
+		}
+	}
 
 	// Light agent configuration
 	if v := os.Getenv("JEEVES_DECISION_INTERVAL_SEC"); v != "" {
@@ -326,6 +765,36 @@ func (c *Config) LoadFromEnv() {
 			c.APIPort = port
 		}
 	}
+	if v := os.Getenv("JEEVES_LIGHT_PATTERN_SCENES"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.LightPatternScenes = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_LIGHT_PATTERN_MIN_CONFIDENCE"); v != "" {
+		if confidence, err := strconv.ParseFloat(v, 64); err == nil {
+			c.LightPatternMinConfidence = confidence
+		}
+	}
+	if v := os.Getenv("JEEVES_LIGHT_PATTERN_FEEDBACK_MINUTES"); v != "" {
+		if minutes, err := strconv.Atoi(v); err == nil {
+			c.LightPatternFeedbackMinutes = minutes
+		}
+	}
+	if v := os.Getenv("JEEVES_LIGHT_PREFERENCE_LEARNING"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.LightPreferenceLearning = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_LIGHT_PREFERENCE_MIN_OBSERVATIONS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.LightPreferenceMinObservations = n
+		}
+	}
+	if v := os.Getenv("JEEVES_LIGHT_SCENES"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.LightScenes = enabled
+		}
+	}
 
 	// Occupancy agent configuration
 	if v := os.Getenv("JEEVES_OCCUPANCY_ANALYSIS_INTERVAL_SEC"); v != "" {
@@ -344,11 +813,61 @@ func (c *Config) LoadFromEnv() {
 			c.LLMMinConfidence = conf
 		}
 	}
+	if v := os.Getenv("JEEVES_LLM_PROMPT_DIR"); v != "" {
+		c.LLMPromptDir = v
+	}
+	if v := os.Getenv("JEEVES_LLM_DISTANCE_MODEL"); v != "" {
+		c.LLMDistanceModel = v
+	}
+	if v := os.Getenv("JEEVES_LLM_CONSOLIDATION_MODEL"); v != "" {
+		c.LLMConsolidationModel = v
+	}
+	if v := os.Getenv("JEEVES_LLM_INTERPRETATION_MODEL"); v != "" {
+		c.LLMInterpretationModel = v
+	}
+	if v := os.Getenv("JEEVES_LLM_BRIEFING_MODEL"); v != "" {
+		c.LLMBriefingModel = v
+	}
+	if v := os.Getenv("JEEVES_LLM_PROBE_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			c.LLMProbeInterval = interval
+		}
+	}
+	if v := os.Getenv("JEEVES_LLM_WARMUP"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.LLMWarmUp = enabled
+		}
+	}
 	if v := os.Getenv("JEEVES_MAX_EVENT_HISTORY"); v != "" {
 		if max, err := strconv.Atoi(v); err == nil {
 			c.MaxEventHistory = max
 		}
 	}
+	for env, weight := range map[string]*float64{
+		"JEEVES_OCCUPANCY_PRESENCE_WEIGHT": &c.OccupancyPresenceWeight,
+		"JEEVES_OCCUPANCY_DOOR_WEIGHT":     &c.OccupancyDoorWeight,
+		"JEEVES_OCCUPANCY_POWER_WEIGHT":    &c.OccupancyPowerWeight,
+		"JEEVES_OCCUPANCY_MEDIA_WEIGHT":    &c.OccupancyMediaWeight,
+	} {
+		if v := os.Getenv(env); v != "" {
+			if w, err := strconv.ParseFloat(v, 64); err == nil {
+				*weight = w
+			}
+		}
+	}
+	if v := os.Getenv("JEEVES_OCCUPANCY_POWER_THRESHOLD_WATT"); v != "" {
+		if watt, err := strconv.ParseFloat(v, 64); err == nil {
+			c.OccupancyPowerThresholdWatt = watt
+		}
+	}
+	if v := os.Getenv("JEEVES_OCCUPANCY_DECAY_PROFILES"); v != "" {
+		c.OccupancyDecayProfiles = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_OCCUPANCY_TRAINING_MODE"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.OccupancyTrainingMode = enabled
+		}
+	}
 
 	// Consolidation configuration
 	if v := os.Getenv("JEEVES_CONSOLIDATION_INTERVAL_HOURS"); v != "" {
@@ -356,6 +875,9 @@ func (c *Config) LoadFromEnv() {
 			c.ConsolidationIntervalHours = hours
 		}
 	}
+	if v := os.Getenv("JEEVES_CONSOLIDATION_SCHEDULE"); v != "" {
+		c.ConsolidationSchedule = v
+	}
 	if v := os.Getenv("JEEVES_CONSOLIDATION_LOOKBACK_HOURS"); v != "" {
 		if hours, err := strconv.Atoi(v); err == nil {
 			c.ConsolidationLookbackHours = hours
@@ -366,6 +888,16 @@ func (c *Config) LoadFromEnv() {
 			c.ConsolidationMaxGapMinutes = minutes
 		}
 	}
+	if v := os.Getenv("JEEVES_CONSOLIDATION_WORKERS"); v != "" {
+		if workers, err := strconv.Atoi(v); err == nil {
+			c.ConsolidationWorkers = workers
+		}
+	}
+	if v := os.Getenv("JEEVES_SCHEDULE_JITTER"); v != "" {
+		if jitter, err := time.ParseDuration(v); err == nil {
+			c.ScheduleJitter = jitter
+		}
+	}
 
 	// Pattern Discovery configuration
 	if v := os.Getenv("JEEVES_PATTERN_DISCOVERY_ENABLED"); v != "" {
@@ -381,11 +913,29 @@ func (c *Config) LoadFromEnv() {
 			c.PatternDiscoveryIntervalHours = hours
 		}
 	}
+	if v := os.Getenv("JEEVES_PATTERN_DISCOVERY_SCHEDULE"); v != "" {
+		c.PatternDiscoverySchedule = v
+	}
 	if v := os.Getenv("JEEVES_PATTERN_DISCOVERY_BATCH_SIZE"); v != "" {
 		if batchSize, err := strconv.Atoi(v); err == nil {
 			c.PatternDiscoveryBatchSize = batchSize
 		}
 	}
+	if v := os.Getenv("JEEVES_DISTANCE_MAX_BATCH_SIZE"); v != "" {
+		if batchSize, err := strconv.Atoi(v); err == nil {
+			c.DistanceMaxBatchSize = batchSize
+		}
+	}
+	if v := os.Getenv("JEEVES_DISTANCE_MIN_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			c.DistanceMinInterval = interval
+		}
+	}
+	if v := os.Getenv("JEEVES_DISTANCE_TARGET_DRAIN"); v != "" {
+		if target, err := time.ParseDuration(v); err == nil {
+			c.DistanceTargetDrain = target
+		}
+	}
 	if v := os.Getenv("JEEVES_PATTERN_CLUSTERING_EPSILON"); v != "" {
 		if epsilon, err := strconv.ParseFloat(v, 64); err == nil {
 			c.PatternClusteringEpsilon = epsilon
@@ -396,6 +946,97 @@ func (c *Config) LoadFromEnv() {
 			c.PatternClusteringMinPoints = minPoints
 		}
 	}
+	if v := os.Getenv("JEEVES_PATTERN_CLUSTERING_ALGORITHM"); v != "" {
+		c.PatternClusteringAlgorithm = v
+	}
+	if v := os.Getenv("JEEVES_PATTERN_CLUSTERING_WORKERS"); v != "" {
+		if workers, err := strconv.Atoi(v); err == nil {
+			c.PatternClusteringWorkers = workers
+		}
+	}
+	if v := os.Getenv("JEEVES_PATTERN_CLUSTERING_MEMORY_MB"); v != "" {
+		if mb, err := strconv.Atoi(v); err == nil {
+			c.PatternClusteringMemoryMB = mb
+		}
+	}
+	if v := os.Getenv("JEEVES_PATTERN_DISTANCE_CACHE_SIZE"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			c.PatternDistanceCacheSize = size
+		}
+	}
+	if v := os.Getenv("JEEVES_ANN_PROBES"); v != "" {
+		if probes, err := strconv.Atoi(v); err == nil {
+			c.ANNProbes = probes
+		}
+	}
+	if v := os.Getenv("JEEVES_ANN_EF_SEARCH"); v != "" {
+		if efSearch, err := strconv.Atoi(v); err == nil {
+			c.ANNEfSearch = efSearch
+		}
+	}
+	if v := os.Getenv("JEEVES_VERIFY_QUEUE_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.VerifyQueueEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_VERIFY_RATE_PER_MINUTE"); v != "" {
+		if rate, err := strconv.Atoi(v); err == nil {
+			c.VerifyRatePerMinute = rate
+		}
+	}
+	if v := os.Getenv("JEEVES_VERIFY_IDLE_START_HOUR"); v != "" {
+		if hour, err := strconv.Atoi(v); err == nil {
+			c.VerifyIdleStartHour = hour
+		}
+	}
+	if v := os.Getenv("JEEVES_VERIFY_IDLE_END_HOUR"); v != "" {
+		if hour, err := strconv.Atoi(v); err == nil {
+			c.VerifyIdleEndHour = hour
+		}
+	}
+	if v := os.Getenv("JEEVES_VERIFY_SEASONAL_REFRESH"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.VerifySeasonalRefresh = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_DISTANCE_AGREEMENT_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			c.DistanceAgreementInterval = interval
+		}
+	}
+	if v := os.Getenv("JEEVES_DISTANCE_AGREEMENT_SAMPLES"); v != "" {
+		if samples, err := strconv.Atoi(v); err == nil {
+			c.DistanceAgreementSamples = samples
+		}
+	}
+	if v := os.Getenv("JEEVES_HOME_TOPOLOGY_PATH"); v != "" {
+		c.HomeTopologyPath = v
+	}
+	if v := os.Getenv("JEEVES_ADJACENCY_LEARNING_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.AdjacencyLearningEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_ADJACENCY_LEARNING_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			c.AdjacencyLearningInterval = interval
+		}
+	}
+	if v := os.Getenv("JEEVES_ADJACENCY_MIN_TRANSITIONS"); v != "" {
+		if minTransitions, err := strconv.Atoi(v); err == nil {
+			c.AdjacencyMinTransitions = minTransitions
+		}
+	}
+	if v := os.Getenv("JEEVES_RHYTHM_PROFILE_WEEKS"); v != "" {
+		if weeks, err := strconv.Atoi(v); err == nil {
+			c.RhythmProfileWeeks = weeks
+		}
+	}
+	if v := os.Getenv("JEEVES_RHYTHM_PROFILE_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			c.RhythmProfileInterval = interval
+		}
+	}
 	if v := os.Getenv("JEEVES_PATTERN_MIN_ANCHORS_FOR_DISCOVERY"); v != "" {
 		if minAnchors, err := strconv.Atoi(v); err == nil {
 			c.PatternMinAnchorsForDiscovery = minAnchors
@@ -436,6 +1077,181 @@ func (c *Config) LoadFromEnv() {
 		}
 	}
 
+	if v := os.Getenv("JEEVES_PATTERN_INCREMENTAL_ASSIGNMENT"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.PatternIncrementalAssignment = enabled
+		}
+	}
+
+	// Multi-occupant tracking configuration
+	if v := os.Getenv("JEEVES_PER_OCCUPANT_CLUSTERING"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.PerOccupantClustering = enabled
+		}
+	}
+
+	// House state configuration
+	if v := os.Getenv("JEEVES_HOUSE_STATE_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.HouseStateEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_HOUSE_AWAY_THRESHOLD"); v != "" {
+		if threshold, err := time.ParseDuration(v); err == nil {
+			c.HouseAwayThreshold = threshold
+		}
+	}
+	if v := os.Getenv("JEEVES_HOUSE_VACATION_THRESHOLD"); v != "" {
+		if threshold, err := time.ParseDuration(v); err == nil {
+			c.HouseVacationThreshold = threshold
+		}
+	}
+	if v := os.Getenv("JEEVES_HOUSE_STATE_CHECK_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			c.HouseStateCheckInterval = interval
+		}
+	}
+
+	// Sleep detection configuration
+	if v := os.Getenv("JEEVES_SLEEP_DETECTION_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.SleepDetectionEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_SLEEP_LOCATION"); v != "" {
+		c.SleepLocation = v
+	}
+	if v := os.Getenv("JEEVES_SLEEP_MIN_DURATION"); v != "" {
+		if duration, err := time.ParseDuration(v); err == nil {
+			c.SleepMinDuration = duration
+		}
+	}
+	if v := os.Getenv("JEEVES_SLEEP_INACTIVITY_THRESHOLD"); v != "" {
+		if threshold, err := time.ParseDuration(v); err == nil {
+			c.SleepInactivityThreshold = threshold
+		}
+	}
+	if v := os.Getenv("JEEVES_SLEEP_ANALYSIS_HOUR"); v != "" {
+		if hour, err := strconv.Atoi(v); err == nil {
+			c.SleepAnalysisHour = hour
+		}
+	}
+
+	// Sensor dropout configuration
+	if v := os.Getenv("JEEVES_SENSOR_DROPOUT_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.SensorDropoutEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_SENSOR_DROPOUT_MIN_GAP"); v != "" {
+		if gap, err := time.ParseDuration(v); err == nil {
+			c.SensorDropoutMinGap = gap
+		}
+	}
+	if v := os.Getenv("JEEVES_SENSOR_DROPOUT_FACTOR"); v != "" {
+		if factor, err := strconv.ParseFloat(v, 64); err == nil {
+			c.SensorDropoutFactor = factor
+		}
+	}
+	if v := os.Getenv("JEEVES_SENSOR_DROPOUT_MIN_EVENTS"); v != "" {
+		if count, err := strconv.Atoi(v); err == nil {
+			c.SensorDropoutMinEvents = count
+		}
+	}
+
+	// Late data configuration
+	if v := os.Getenv("JEEVES_LATE_DATA_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.LateDataEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_LATE_DATA_CHECK_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			c.LateDataCheckInterval = interval
+		}
+	}
+	if v := os.Getenv("JEEVES_LATE_DATA_MIN_DELAY"); v != "" {
+		if delay, err := time.ParseDuration(v); err == nil {
+			c.LateDataMinDelay = delay
+		}
+	}
+
+	// Behavior API configuration
+	if v := os.Getenv("JEEVES_BEHAVIOR_API_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.BehaviorAPIEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_BEHAVIOR_API_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			c.BehaviorAPIPort = port
+		}
+	}
+	if v := os.Getenv("JEEVES_ANNOTATION_FEW_SHOT_LIMIT"); v != "" {
+		if limit, err := strconv.Atoi(v); err == nil {
+			c.AnnotationFewShotLimit = limit
+		}
+	}
+
+	// Prediction configuration
+	if v := os.Getenv("JEEVES_PREDICTION_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.PredictionEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_PREDICTION_MIN_CONFIDENCE"); v != "" {
+		if confidence, err := strconv.ParseFloat(v, 64); err == nil {
+			c.PredictionMinConfidence = confidence
+		}
+	}
+	if v := os.Getenv("JEEVES_PREDICTION_HORIZON"); v != "" {
+		if horizon, err := time.ParseDuration(v); err == nil {
+			c.PredictionHorizon = horizon
+		}
+	}
+	if v := os.Getenv("JEEVES_PREDICTION_MAX_TRANSITION_GAP"); v != "" {
+		if gap, err := time.ParseDuration(v); err == nil {
+			c.PredictionMaxTransitionGap = gap
+		}
+	}
+	if v := os.Getenv("JEEVES_PREDICTION_SNOOZE_DURATION"); v != "" {
+		if snooze, err := time.ParseDuration(v); err == nil {
+			c.PredictionSnoozeDuration = snooze
+		}
+	}
+
+	// Pattern lifecycle configuration
+	if v := os.Getenv("JEEVES_PATTERN_LIFECYCLE_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.PatternLifecycleEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_PATTERN_LIFECYCLE_INTERVAL"); v != "" {
+		if interval, err := time.ParseDuration(v); err == nil {
+			c.PatternLifecycleInterval = interval
+		}
+	}
+	if v := os.Getenv("JEEVES_PATTERN_MERGE_SIMILARITY"); v != "" {
+		if similarity, err := strconv.ParseFloat(v, 64); err == nil {
+			c.PatternMergeSimilarity = similarity
+		}
+	}
+	if v := os.Getenv("JEEVES_PATTERN_DECAY_AFTER_WEEKS"); v != "" {
+		if weeks, err := strconv.Atoi(v); err == nil {
+			c.PatternDecayAfterWeeks = weeks
+		}
+	}
+	if v := os.Getenv("JEEVES_PATTERN_DECAY_FACTOR"); v != "" {
+		if factor, err := strconv.ParseFloat(v, 64); err == nil {
+			c.PatternDecayFactor = factor
+		}
+	}
+	if v := os.Getenv("JEEVES_PATTERN_ARCHIVE_AFTER_WEEKS"); v != "" {
+		if weeks, err := strconv.Atoi(v); err == nil {
+			c.PatternArchiveAfterWeeks = weeks
+		}
+	}
+
 	// Batch Processing configuration
 	if v := os.Getenv("JEEVES_BATCH_PROCESSING_ENABLED"); v != "" {
 		if enabled, err := strconv.ParseBool(v); err == nil {
@@ -467,6 +1283,186 @@ func (c *Config) LoadFromEnv() {
 			c.BatchMetadataEnabled = enabled
 		}
 	}
+
+	if v := os.Getenv("JEEVES_OBSERVER_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			c.ObserverPort = port
+		}
+	}
+
+	// Observer auth configuration
+	if v := os.Getenv("JEEVES_OBSERVER_AUTH_MODE"); v != "" {
+		c.ObserverAuthMode = v
+	}
+	if v := os.Getenv("JEEVES_OBSERVER_VIEWER_TOKENS"); v != "" {
+		c.ObserverViewerTokens = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_OBSERVER_ADMIN_TOKENS"); v != "" {
+		c.ObserverAdminTokens = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_OBSERVER_OIDC_ISSUER"); v != "" {
+		c.ObserverOIDCIssuer = v
+	}
+	if v := os.Getenv("JEEVES_OBSERVER_OIDC_AUDIENCE"); v != "" {
+		c.ObserverOIDCAudience = v
+	}
+	if v := os.Getenv("JEEVES_OBSERVER_OIDC_ROLES_CLAIM"); v != "" {
+		c.ObserverOIDCRolesClaim = v
+	}
+	if v := os.Getenv("JEEVES_OBSERVER_OIDC_ADMIN_ROLE"); v != "" {
+		c.ObserverOIDCAdminRole = v
+	}
+
+	// Report configuration
+	if v := os.Getenv("JEEVES_REPORT_PUBLISH_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.ReportPublishEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_REPORT_HOUR"); v != "" {
+		if hour, err := strconv.Atoi(v); err == nil {
+			c.ReportHour = hour
+		}
+	}
+	if v := os.Getenv("JEEVES_BRIEFING_ENABLED"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			c.BriefingEnabled = enabled
+		}
+	}
+	if v := os.Getenv("JEEVES_BRIEFING_HOUR"); v != "" {
+		if hour, err := strconv.Atoi(v); err == nil {
+			c.BriefingHour = hour
+		}
+	}
+	if v := os.Getenv("JEEVES_BRIEFING_TONE"); v != "" {
+		c.BriefingTone = v
+	}
+	if v := os.Getenv("JEEVES_REPORT_EMAIL_TO"); v != "" {
+		c.ReportEmailTo = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_SMTP_HOST"); v != "" {
+		c.SMTPHost = v
+	}
+	if v := os.Getenv("JEEVES_SMTP_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			c.SMTPPort = port
+		}
+	}
+	if v := os.Getenv("JEEVES_SMTP_USER"); v != "" {
+		c.SMTPUser = v
+	}
+	if v := os.Getenv("JEEVES_SMTP_PASSWORD"); v != "" {
+		c.SMTPPassword = v
+	}
+	if v := os.Getenv("JEEVES_SMTP_FROM"); v != "" {
+		c.SMTPFrom = v
+	}
+
+	// Webhook configuration
+	if v := os.Getenv("JEEVES_WEBHOOK_CONFIG"); v != "" {
+		c.WebhookConfigPath = v
+	}
+
+	// Push notification configuration
+	if v := os.Getenv("JEEVES_PUSHOVER_APP_TOKEN"); v != "" {
+		c.PushoverAppToken = v
+	}
+	if v := os.Getenv("JEEVES_PUSHOVER_USER_KEY"); v != "" {
+		c.PushoverUserKey = v
+	}
+	if v := os.Getenv("JEEVES_TELEGRAM_BOT_TOKEN"); v != "" {
+		c.TelegramBotToken = v
+	}
+	if v := os.Getenv("JEEVES_TELEGRAM_CHAT_ID"); v != "" {
+		c.TelegramChatID = v
+	}
+	if v := os.Getenv("JEEVES_NOTIFY_ROUTES"); v != "" {
+		c.NotifyRoutes = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_NOTIFY_QUIET_START_HOUR"); v != "" {
+		if hour, err := strconv.Atoi(v); err == nil {
+			c.NotifyQuietStartHour = hour
+		}
+	}
+	if v := os.Getenv("JEEVES_NOTIFY_QUIET_END_HOUR"); v != "" {
+		if hour, err := strconv.Atoi(v); err == nil {
+			c.NotifyQuietEndHour = hour
+		}
+	}
+	if v := os.Getenv("JEEVES_NOTIFY_RATE_LIMIT_PER_HOUR"); v != "" {
+		if limit, err := strconv.Atoi(v); err == nil {
+			c.NotifyRateLimitPerHour = limit
+		}
+	}
+
+	// Weather service configuration
+	if v := os.Getenv("JEEVES_WEATHER_PROVIDER"); v != "" {
+		c.WeatherProvider = v
+	}
+	if v := os.Getenv("JEEVES_WEATHER_POLL_INTERVAL_MIN"); v != "" {
+		if minutes, err := strconv.Atoi(v); err == nil {
+			c.WeatherPollIntervalMin = minutes
+		}
+	}
+	if v := os.Getenv("JEEVES_WEATHER_USER_AGENT"); v != "" {
+		c.WeatherUserAgent = v
+	}
+
+	// Home Assistant bridge configuration
+	if v := os.Getenv("JEEVES_HASS_DISCOVERY_PREFIX"); v != "" {
+		c.HassDiscoveryPrefix = v
+	}
+	if v := os.Getenv("JEEVES_HASS_NODE_ID"); v != "" {
+		c.HassNodeID = v
+	}
+	if v := os.Getenv("JEEVES_HASS_EVENT_TOPIC"); v != "" {
+		c.HassEventTopic = v
+	}
+	if v := os.Getenv("JEEVES_HASS_ENTITY_MAP"); v != "" {
+		c.HassEntityMap = splitList(v)
+	}
+
+	// Media agent configuration
+	if v := os.Getenv("JEEVES_MEDIA_PLAYER_MAP"); v != "" {
+		c.MediaPlayerMap = splitList(v)
+	}
+	if v := os.Getenv("JEEVES_MEDIA_SONOS_TOPIC"); v != "" {
+		c.MediaSonosTopic = v
+	}
+	if v := os.Getenv("JEEVES_MEDIA_CHROMECAST_TOPIC"); v != "" {
+		c.MediaChromecastTopic = v
+	}
+	if v := os.Getenv("JEEVES_MEDIA_PAUSE_TIMEOUT"); v != "" {
+		if timeout, err := time.ParseDuration(v); err == nil {
+			c.MediaPauseTimeout = timeout
+		}
+	}
+
+	// Supervisor configuration
+	for env, field := range map[string]*bool{
+		"JEEVES_RUN_BEHAVIOR":    &c.RunBehavior,
+		"JEEVES_RUN_ILLUMINANCE": &c.RunIlluminance,
+		"JEEVES_RUN_OCCUPANCY":   &c.RunOccupancy,
+		"JEEVES_RUN_LIGHT":       &c.RunLight,
+		"JEEVES_RUN_OBSERVER":    &c.RunObserver,
+	} {
+		if v := os.Getenv(env); v != "" {
+			if enabled, err := strconv.ParseBool(v); err == nil {
+				*field = enabled
+			}
+		}
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // LoadFromFlags parses command-line flags and overrides config values
@@ -477,6 +1473,9 @@ func (c *Config) LoadFromFlags() {
 	pflag.StringVar(&c.MQTTUser, "mqtt-user", c.MQTTUser, "MQTT username")
 	pflag.StringVar(&c.MQTTPassword, "mqtt-password", c.MQTTPassword, "MQTT password")
 	pflag.StringVar(&c.MQTTClientID, "mqtt-client-id", c.MQTTClientID, "MQTT client ID")
+	pflag.StringVar(&c.MQTTTopicPrefix, "mqtt-topic-prefix", c.MQTTTopicPrefix, "Prefix for all MQTT topics")
+	pflag.DurationVar(&c.MQTTPublishTimeout, "mqtt-publish-timeout", c.MQTTPublishTimeout, "How long to wait for the broker to acknowledge a publish (0 = no limit)")
+	pflag.StringVar(&c.HouseholdID, "household-id", c.HouseholdID, "Household served by this agent (scopes MQTT topics and Postgres rows)")
 
 	// Redis flags
 	pflag.StringVar(&c.RedisHost, "redis-host", c.RedisHost, "Redis hostname")
@@ -491,19 +1490,35 @@ func (c *Config) LoadFromFlags() {
 	pflag.StringVar(&c.PostgresPassword, "postgres-password", c.PostgresPassword, "PostgreSQL password")
 	pflag.StringVar(&c.PostgresDB, "postgres-db", c.PostgresDB, "PostgreSQL database name")
 	pflag.StringVar(&c.PostgresSSLMode, "postgres-sslmode", c.PostgresSSLMode, "PostgreSQL SSL mode")
+	pflag.StringVar(&c.PostgresSchema, "postgres-schema", c.PostgresSchema, "PostgreSQL schema (search_path)")
 	pflag.IntVar(&c.PostgresMaxConnections, "postgres-max-conns", c.PostgresMaxConnections, "PostgreSQL max connections")
 	pflag.IntVar(&c.PostgresMaxIdleConnections, "postgres-max-idle-conns", c.PostgresMaxIdleConnections, "PostgreSQL max idle connections")
 	pflag.DurationVar(&c.PostgresConnMaxLifetime, "postgres-conn-max-life", c.PostgresConnMaxLifetime, "PostgreSQL connection max lifetime")
+	pflag.StringVar(&c.EncryptionKeyFile, "encryption-key-file", c.EncryptionKeyFile, "File with the base64 AES-256 key encrypting stored behavior data")
 
 	// Service flags
 	pflag.StringVar(&c.ServiceName, "service-name", c.ServiceName, "Service name")
 	pflag.IntVar(&c.HealthPort, "health-port", c.HealthPort, "Health check HTTP port")
 	pflag.StringVar(&c.LogLevel, "log-level", c.LogLevel, "Log level (debug, info, warn, error)")
+	pflag.StringVar(&c.LogFormat, "log-format", c.LogFormat, "Log format (text, json)")
+	pflag.StringVar(&c.LogLevels, "log-levels", c.LogLevels, "Per-module log levels (e.g. behavior=debug,distance=warn)")
+	pflag.BoolVar(&c.ShadowMode, "shadow-mode", c.ShadowMode, "Publish intended device actions to automation/shadow/* instead of commanding devices")
+	pflag.BoolVar(&c.ChaosEnabled, "chaos-enabled", c.ChaosEnabled, "Accept fault injection on automation/test/chaos (e2e tests only)")
 
 	// Agent-specific flags
 	pflag.IntVar(&c.MaxSensorHistory, "max-sensor-history", c.MaxSensorHistory, "Maximum sensor history entries")
 	pflag.BoolVar(&c.EnableVictoriaMetrics, "enable-victoria-metrics", c.EnableVictoriaMetrics, "Enable VictoriaMetrics forwarding")
 	pflag.StringVar(&c.VictoriaMetricsURL, "victoria-metrics-url", c.VictoriaMetricsURL, "VictoriaMetrics URL")
+	pflag.StringVar(&c.InfluxURL, "influx-url", c.InfluxURL, "InfluxDB URL for the raw sensor archive")
+	pflag.StringVar(&c.InfluxOrg, "influx-org", c.InfluxOrg, "InfluxDB organization")
+	pflag.StringVar(&c.InfluxBucket, "influx-bucket", c.InfluxBucket, "InfluxDB bucket for sensor events")
+	pflag.StringSliceVar(&c.InfluxSensorTypes, "influx-sensor-types", c.InfluxSensorTypes, "Sensor types to archive to InfluxDB (empty = all)")
+	pflag.IntVar(&c.CollectorDLQSize, "collector-dlq-size", c.CollectorDLQSize, "Unparseable sensor messages kept in the dead-letter queue (0 = disabled)")
+	pflag.IntVar(&c.CollectorQueueSize, "collector-queue-size", c.CollectorQueueSize, "Sensor messages buffered in memory before Redis (0 = process inline)")
+	pflag.StringVar(&c.CollectorSpoolDir, "collector-spool-dir", c.CollectorSpoolDir, "Directory for sensor messages overflowing the queue (empty = drop)")
+	pflag.IntVar(&c.CollectorSpoolMaxMB, "collector-spool-max-mb", c.CollectorSpoolMaxMB, "Spool file size limit in MB (0 = unbounded)")
+	pflag.StringSliceVar(&c.PrivacyExcludedLocations, "privacy-excluded-locations", c.PrivacyExcludedLocations, "Locations excluded from storage and learning")
+	pflag.StringSliceVar(&c.PrivacyExcludedSensorTypes, "privacy-excluded-sensor-types", c.PrivacyExcludedSensorTypes, "Sensor types excluded from storage and learning")
 
 	// Illuminance agent flags
 	pflag.Float64Var(&c.Latitude, "latitude", c.Latitude, "Geographic latitude for daylight calculation")
@@ -511,35 +1526,124 @@ func (c *Config) LoadFromFlags() {
 	pflag.IntVar(&c.AnalysisIntervalSec, "analysis-interval", c.AnalysisIntervalSec, "Analysis interval in seconds")
 	pflag.Float64Var(&c.MaxDataAgeHours, "max-data-age-hours", c.MaxDataAgeHours, "Maximum age of data to consider (hours)")
 	pflag.IntVar(&c.MinReadingsRequired, "min-readings-required", c.MinReadingsRequired, "Minimum readings required for sufficient data")
+	pflag.StringSliceVar(&c.IlluminanceWindows, "illuminance-windows", c.IlluminanceWindows, "Per-room windows (location=orientation:factor, e.g. living_room=south:0.03)")
+	pflag.StringVar(&c.IlluminanceOutdoorLocation, "illuminance-outdoor-location", c.IlluminanceOutdoorLocation, "Location of the outdoor lux sensor used for cloud correction")
+	pflag.Float64Var(&c.IlluminanceTargetLux, "illuminance-target-lux", c.IlluminanceTargetLux, "Indoor lux that counts as sufficient natural light")
 
 	// Light agent flags
 	pflag.IntVar(&c.DecisionIntervalSec, "decision-interval", c.DecisionIntervalSec, "Decision loop interval in seconds")
 	pflag.IntVar(&c.ManualOverrideMinutes, "manual-override-minutes", c.ManualOverrideMinutes, "Manual override duration in minutes")
 	pflag.IntVar(&c.MinDecisionIntervalMs, "min-decision-interval-ms", c.MinDecisionIntervalMs, "Minimum time between decisions per location (ms)")
 	pflag.IntVar(&c.APIPort, "api-port", c.APIPort, "HTTP API port")
+	pflag.BoolVar(&c.LightPatternScenes, "light-pattern-scenes", c.LightPatternScenes, "Apply per-pattern lighting scenes from behavior predictions (requires Postgres)")
+	pflag.Float64Var(&c.LightPatternMinConfidence, "light-pattern-min-confidence", c.LightPatternMinConfidence, "Minimum prediction confidence for a pattern scene")
+	pflag.IntVar(&c.LightPatternFeedbackMinutes, "light-pattern-feedback-minutes", c.LightPatternFeedbackMinutes, "Minutes after a pattern scene in which a manual change counts as rejection")
+	pflag.BoolVar(&c.LightPreferenceLearning, "light-preference-learning", c.LightPreferenceLearning, "Learn lighting preferences from manual adjustments (requires Postgres)")
+	pflag.IntVar(&c.LightPreferenceMinObservations, "light-preference-min-observations", c.LightPreferenceMinObservations, "Manual adjustments needed before a learned preference is applied")
+	pflag.BoolVar(&c.LightScenes, "light-scenes", c.LightScenes, "Enable named multi-light scenes and the scene API (requires Postgres)")
 
 	// Occupancy agent flags
 	pflag.IntVar(&c.OccupancyAnalysisIntervalSec, "occupancy-analysis-interval", c.OccupancyAnalysisIntervalSec, "Occupancy analysis interval in seconds")
 	pflag.StringVar(&c.LLMEndpoint, "llm-endpoint", c.LLMEndpoint, "LLM API endpoint URL")
 	pflag.StringVar(&c.LLMModel, "llm-model", c.LLMModel, "LLM model name")
 	pflag.Float64Var(&c.LLMMinConfidence, "llm-min-confidence", c.LLMMinConfidence, "Minimum LLM confidence threshold")
+	pflag.StringVar(&c.LLMPromptDir, "llm-prompt-dir", c.LLMPromptDir, "Directory of LLM prompt template overrides")
+	pflag.StringVar(&c.LLMDistanceModel, "llm-distance-model", c.LLMDistanceModel, "LLM model for distance rating (default: llm-model)")
+	pflag.StringVar(&c.LLMConsolidationModel, "llm-consolidation-model", c.LLMConsolidationModel, "LLM model for episode consolidation (default: llm-model)")
+	pflag.StringVar(&c.LLMInterpretationModel, "llm-interpretation-model", c.LLMInterpretationModel, "LLM model for pattern interpretation (default: llm-model)")
+	pflag.StringVar(&c.LLMBriefingModel, "llm-briefing-model", c.LLMBriefingModel, "LLM model for the morning briefing (default: llm-model)")
+	pflag.DurationVar(&c.LLMProbeInterval, "llm-probe-interval", c.LLMProbeInterval, "How often the LLM is probed (0 disables probing and warm-up)")
+	pflag.BoolVar(&c.LLMWarmUp, "llm-warmup", c.LLMWarmUp, "Load the configured LLM models when the LLM becomes available")
 	pflag.IntVar(&c.MaxEventHistory, "max-event-history", c.MaxEventHistory, "Maximum motion event history to keep")
+	pflag.Float64Var(&c.OccupancyPresenceWeight, "occupancy-presence-weight", c.OccupancyPresenceWeight, "Fusion weight of presence (mmWave/BLE) sensors")
+	pflag.Float64Var(&c.OccupancyDoorWeight, "occupancy-door-weight", c.OccupancyDoorWeight, "Fusion weight of door contacts")
+	pflag.Float64Var(&c.OccupancyPowerWeight, "occupancy-power-weight", c.OccupancyPowerWeight, "Fusion weight of power consumption")
+	pflag.Float64Var(&c.OccupancyMediaWeight, "occupancy-media-weight", c.OccupancyMediaWeight, "Fusion weight of media playback state")
+	pflag.Float64Var(&c.OccupancyPowerThresholdWatt, "occupancy-power-threshold", c.OccupancyPowerThresholdWatt, "Power draw (W) above which a location counts as in use")
+	pflag.BoolVar(&c.OccupancyTrainingMode, "occupancy-training-mode", c.OccupancyTrainingMode, "Record ground-truth occupancy labels to Postgres")
+	pflag.IntVar(&c.OccupancyLabelReport, "occupancy-label-report", c.OccupancyLabelReport, "Print LLM vs fallback accuracy of the last N days of labels and exit")
+	pflag.StringVar(&c.OccupancyEvalCorpus, "occupancy-eval-corpus", c.OccupancyEvalCorpus, "Evaluate LLM and fallback on a directory of labeled abstraction cases and exit")
+	pflag.StringVar(&c.OccupancyEvalBaseline, "occupancy-eval-baseline", c.OccupancyEvalBaseline, "Corpus report to compare against; regressions fail the evaluation (written when missing)")
+	pflag.Float64Var(&c.OccupancyEvalMinAccuracy, "occupancy-eval-min-accuracy", c.OccupancyEvalMinAccuracy, "Fail the corpus evaluation when LLM or fallback accuracy is below this")
+	pflag.StringSliceVar(&c.OccupancyDecayProfiles, "occupancy-decay-profiles", c.OccupancyDecayProfiles, "Per-location decay profiles (location=fast|default|slow or location=recent:absent:empty)")
 
 	// Consolidation flags
-	pflag.IntVar(&c.ConsolidationIntervalHours, "consolidation-interval-hours", c.ConsolidationIntervalHours, "Episode consolidation interval in hours")
+	pflag.IntVar(&c.ConsolidationIntervalHours, "consolidation-interval-hours", c.ConsolidationIntervalHours, "Deprecated: use --consolidation-schedule; runs consolidation every N hours when no schedule is set")
+	pflag.StringVar(&c.ConsolidationSchedule, "consolidation-schedule", c.ConsolidationSchedule, "Run consolidation on a cron expression or interval, e.g. \"30 3 * * *\" (empty = triggers only)")
 	pflag.IntVar(&c.ConsolidationLookbackHours, "consolidation-lookback-hours", c.ConsolidationLookbackHours, "Episode consolidation lookback period in hours")
 	pflag.IntVar(&c.ConsolidationMaxGapMinutes, "consolidation-max-gap-minutes", c.ConsolidationMaxGapMinutes, "Maximum gap between episodes for consolidation in minutes")
+	pflag.IntVar(&c.ConsolidationWorkers, "consolidation-workers", c.ConsolidationWorkers, "Locations processed concurrently during consolidation (1 = sequential)")
 
 	// Pattern Discovery flags
 	pflag.BoolVar(&c.PatternDiscoveryEnabled, "pattern-discovery-enabled", c.PatternDiscoveryEnabled, "Enable pattern discovery")
 	pflag.StringVar(&c.PatternDistanceStrategy, "pattern-distance-strategy", c.PatternDistanceStrategy, "Distance computation strategy (llm_first, progressive_learned)")
 	pflag.IntVar(&c.PatternDiscoveryIntervalHours, "pattern-discovery-interval-hours", c.PatternDiscoveryIntervalHours, "Pattern discovery interval in hours")
+	pflag.StringVar(&c.PatternDiscoverySchedule, "pattern-discovery-schedule", c.PatternDiscoverySchedule, "Run pattern discovery on a cron expression or interval instead of the interval hours")
 	pflag.IntVar(&c.PatternDiscoveryBatchSize, "pattern-discovery-batch-size", c.PatternDiscoveryBatchSize, "Pattern discovery batch size")
+	pflag.IntVar(&c.DistanceMaxBatchSize, "distance-max-batch-size", c.DistanceMaxBatchSize, "Largest distance batch size auto-tuning may use")
+	pflag.DurationVar(&c.DistanceMinInterval, "distance-min-interval", c.DistanceMinInterval, "Shortest distance interval auto-tuning may use")
+	pflag.DurationVar(&c.DistanceAgreementInterval, "distance-agreement-interval", c.DistanceAgreementInterval, "How often distances are cross-validated against LLM labels (0 disables)")
+	pflag.IntVar(&c.DistanceAgreementSamples, "distance-agreement-samples", c.DistanceAgreementSamples, "LLM-labeled pairs sampled per distance agreement report")
+	pflag.DurationVar(&c.DistanceTargetDrain, "distance-target-drain", c.DistanceTargetDrain, "Time the distance backlog should drain within (0 disables auto-tuning)")
 	pflag.Float64Var(&c.PatternClusteringEpsilon, "pattern-clustering-epsilon", c.PatternClusteringEpsilon, "DBSCAN epsilon (maximum distance for neighborhood)")
 	pflag.IntVar(&c.PatternClusteringMinPoints, "pattern-clustering-min-points", c.PatternClusteringMinPoints, "DBSCAN minimum points to form cluster")
 	pflag.IntVar(&c.PatternMinAnchorsForDiscovery, "pattern-min-anchors-for-discovery", c.PatternMinAnchorsForDiscovery, "Minimum anchors required for pattern discovery")
 	pflag.IntVar(&c.PatternLookbackHours, "pattern-lookback-hours", c.PatternLookbackHours, "Pattern discovery lookback period in hours")
 
+	// One-shot command flags
+	pflag.StringVar(&c.ExportPatternsPath, "export-patterns", c.ExportPatternsPath, "Export learned patterns to a JSON file and exit")
+	pflag.StringVar(&c.ImportPatternsPath, "import-patterns", c.ImportPatternsPath, "Import learned patterns from a JSON file and exit")
+	pflag.BoolVar(&c.FitDistanceWeights, "fit-distance-weights", c.FitDistanceWeights, "Fit vector distance weights to LLM-labeled pairs and exit")
+	pflag.BoolVar(&c.ReembedAnchors, "reembed-anchors", c.ReembedAnchors, "Re-embed anchors from older embedding versions and exit")
+
+	pflag.IntVar(&c.ObserverPort, "observer-port", c.ObserverPort, "Observer UI and HTTP API port")
+
+	// Observer auth flags (tokens are only read from the environment)
+	pflag.StringVar(&c.ObserverAuthMode, "observer-auth-mode", c.ObserverAuthMode, "Observer API authentication (none, token, oidc)")
+	pflag.StringVar(&c.ObserverOIDCIssuer, "observer-oidc-issuer", c.ObserverOIDCIssuer, "Observer OpenID provider issuer URL")
+	pflag.StringVar(&c.ObserverOIDCAudience, "observer-oidc-audience", c.ObserverOIDCAudience, "Observer required token audience")
+
+	// Report flags
+	pflag.BoolVar(&c.ReportPublishEnabled, "report-publish-enabled", c.ReportPublishEnabled, "Publish scheduled daily/weekly behavior reports")
+	pflag.IntVar(&c.ReportHour, "report-hour", c.ReportHour, "Local hour when scheduled reports are generated")
+
+	// Briefing flags
+	pflag.BoolVar(&c.BriefingEnabled, "briefing-enabled", c.BriefingEnabled, "Publish an LLM-written morning briefing about the previous day")
+	pflag.IntVar(&c.BriefingHour, "briefing-hour", c.BriefingHour, "Local hour when the morning briefing is generated")
+	pflag.StringVar(&c.BriefingTone, "briefing-tone", c.BriefingTone, "Tone of the morning briefing")
+
+	// Webhook flags
+	pflag.StringVar(&c.WebhookConfigPath, "webhook-config", c.WebhookConfigPath, "JSON file defining webhook sinks for behavior events")
+
+	// Push notification flags (credentials are only read from the environment)
+	pflag.StringSliceVar(&c.NotifyRoutes, "notify-routes", c.NotifyRoutes, "Notification routes (event=channel)")
+	pflag.IntVar(&c.NotifyQuietStartHour, "notify-quiet-start-hour", c.NotifyQuietStartHour, "Quiet hours start (local hour)")
+	pflag.IntVar(&c.NotifyQuietEndHour, "notify-quiet-end-hour", c.NotifyQuietEndHour, "Quiet hours end (local hour)")
+	pflag.IntVar(&c.NotifyRateLimitPerHour, "notify-rate-limit", c.NotifyRateLimitPerHour, "Max notifications per channel per hour (0 = unlimited)")
+
+	// Weather flags
+	pflag.StringVar(&c.WeatherProvider, "weather-provider", c.WeatherProvider, "Weather service (open-meteo or met-norway)")
+	pflag.IntVar(&c.WeatherPollIntervalMin, "weather-poll-interval", c.WeatherPollIntervalMin, "Minutes between weather fetches")
+	pflag.StringVar(&c.WeatherUserAgent, "weather-user-agent", c.WeatherUserAgent, "User-Agent sent to the weather service")
+
+	// Home Assistant bridge flags
+	pflag.StringVar(&c.HassDiscoveryPrefix, "hass-discovery-prefix", c.HassDiscoveryPrefix, "Home Assistant MQTT discovery prefix")
+	pflag.StringVar(&c.HassEventTopic, "hass-event-topic", c.HassEventTopic, "Home Assistant event stream topic")
+	pflag.StringSliceVar(&c.HassEntityMap, "hass-entity-map", c.HassEntityMap, "Home Assistant entity mappings (entity_id=[sensor_type:]location)")
+
+	// Media agent flags
+	pflag.StringSliceVar(&c.MediaPlayerMap, "media-player-map", c.MediaPlayerMap, "Media player mappings (player=location)")
+	pflag.StringVar(&c.MediaSonosTopic, "media-sonos-topic", c.MediaSonosTopic, "sonos2mqtt state topic prefix")
+	pflag.StringVar(&c.MediaChromecastTopic, "media-chromecast-topic", c.MediaChromecastTopic, "Chromecast media status topic prefix")
+	pflag.DurationVar(&c.MediaPauseTimeout, "media-pause-timeout", c.MediaPauseTimeout, "How long a paused playback session lasts before it ends")
+
+	// Supervisor flags
+	pflag.BoolVar(&c.RunBehavior, "run-behavior", c.RunBehavior, "Run the behavior agent (jeeves supervisor)")
+	pflag.BoolVar(&c.RunIlluminance, "run-illuminance", c.RunIlluminance, "Run the illuminance agent (jeeves supervisor)")
+	pflag.BoolVar(&c.RunOccupancy, "run-occupancy", c.RunOccupancy, "Run the occupancy agent (jeeves supervisor)")
+	pflag.BoolVar(&c.RunLight, "run-light", c.RunLight, "Run the light agent (jeeves supervisor)")
+	pflag.BoolVar(&c.RunObserver, "run-observer", c.RunObserver, "Run the observer (jeeves supervisor)")
+
 	pflag.Parse()
 }
 
@@ -563,6 +1667,11 @@ func (c *Config) Validate() error {
 	if c.ServiceName == "" {
 		return fmt.Errorf("Service name is required")
 	}
+	for _, r := range c.HouseholdID {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("household ID must contain only lowercase letters, digits, '-' and '_': %q", c.HouseholdID)
+		}
+	}
 
 	// Validate log level
 	validLogLevels := map[string]bool{
@@ -574,15 +1683,61 @@ func (c *Config) Validate() error {
 	if !validLogLevels[c.LogLevel] {
 		return fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", c.LogLevel)
 	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		return fmt.Errorf("invalid log format: %s (must be text or json)", c.LogFormat)
+	}
+	if c.PatternClusteringAlgorithm != "dbscan" && c.PatternClusteringAlgorithm != "hdbscan" {
+		return fmt.Errorf("invalid pattern clustering algorithm: %s (must be dbscan or hdbscan)", c.PatternClusteringAlgorithm)
+	}
+	if c.HouseStateEnabled {
+		if c.HouseAwayThreshold <= 0 {
+			return fmt.Errorf("house away threshold must be positive")
+		}
+		if c.HouseVacationThreshold <= c.HouseAwayThreshold {
+			return fmt.Errorf("house vacation threshold (%s) must be longer than the away threshold (%s)", c.HouseVacationThreshold, c.HouseAwayThreshold)
+		}
+		if c.HouseStateCheckInterval <= 0 {
+			return fmt.Errorf("house state check interval must be positive")
+		}
+	}
 
 	return nil
 }
 
+// EffectiveConsolidationSchedule returns ConsolidationSchedule, or an
+// interval of ConsolidationIntervalHours when only the deprecated setting is given
+func (c *Config) EffectiveConsolidationSchedule() string {
+	if c.ConsolidationSchedule == "" && c.ConsolidationIntervalHours > 0 {
+		return fmt.Sprintf("%dh", c.ConsolidationIntervalHours)
+	}
+	return c.ConsolidationSchedule
+}
+
 // MQTTAddress returns the full MQTT broker address
 func (c *Config) MQTTAddress() string {
 	return fmt.Sprintf("tcp://%s:%d", c.MQTTBroker, c.MQTTPort)
 }
 
+// DefaultHousehold is the household of rows written without a household ID
+const DefaultHousehold = "default"
+
+// Household returns the household ID, DefaultHousehold when unset
+func (c *Config) Household() string {
+	if c.HouseholdID == "" {
+		return DefaultHousehold
+	}
+	return c.HouseholdID
+}
+
+// TopicPrefix returns the prefix of every MQTT topic: MQTTTopicPrefix
+// followed by "households/{id}/" when a household ID is set
+func (c *Config) TopicPrefix() string {
+	if c.HouseholdID == "" {
+		return c.MQTTTopicPrefix
+	}
+	return c.MQTTTopicPrefix + "households/" + c.HouseholdID + "/"
+}
+
 // RedisAddress returns the full Redis address
 func (c *Config) RedisAddress() string {
 	return fmt.Sprintf("%s:%d", c.RedisHost, c.RedisPort)
@@ -590,6 +1745,15 @@ func (c *Config) RedisAddress() string {
 
 // PostgresConnectionString returns a PostgreSQL connection string
 func (c *Config) PostgresConnectionString() string {
-	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+	conn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.PostgresHost, c.PostgresPort, c.PostgresUser, c.PostgresPassword, c.PostgresDB, c.PostgresSSLMode)
+	if c.PostgresSchema != "" {
+		// Extensions (uuid-ossp, vector) stay resolvable from public
+		conn += fmt.Sprintf(" search_path='%s,public'", c.PostgresSchema)
+	}
+	if c.HouseholdID != "" {
+		// Read by jeeves_household() for column defaults and row security
+		conn += fmt.Sprintf(" jeeves.household_id='%s'", c.HouseholdID)
+	}
+	return conn
 }
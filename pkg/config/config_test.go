@@ -0,0 +1,162 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate_PatternClusteringAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		wantErr   bool
+	}{
+		{"dbscan", false},
+		{"hdbscan", false},
+		{"HDBSCAN", true},
+		{"hdbscn", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.PatternClusteringAlgorithm = tt.algorithm
+
+			err := cfg.Validate()
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected algorithm %q to be rejected", tt.algorithm)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected algorithm %q to be accepted, got %v", tt.algorithm, err)
+			}
+		})
+	}
+}
+
+func TestValidate_HouseholdID(t *testing.T) {
+	tests := []struct {
+		householdID string
+		wantErr     bool
+	}{
+		{"", false},
+		{"smith", false},
+		{"smith-2_b", false},
+		{"Smith", true},
+		{"smith/jones", true},
+		{"smith jones", true},
+		{"smith'", true},
+		{"+", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.householdID, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.HouseholdID = tt.householdID
+
+			err := cfg.Validate()
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected household ID %q to be rejected", tt.householdID)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected household ID %q to be accepted, got %v", tt.householdID, err)
+			}
+		})
+	}
+}
+
+func TestValidate_HouseState(t *testing.T) {
+	tests := []struct {
+		name     string
+		away     time.Duration
+		vacation time.Duration
+		interval time.Duration
+		disabled bool
+		wantErr  bool
+	}{
+		{"defaults", 4 * time.Hour, 48 * time.Hour, 5 * time.Minute, false, false},
+		{"zero away threshold", 0, 48 * time.Hour, 5 * time.Minute, false, true},
+		{"equal thresholds", 4 * time.Hour, 4 * time.Hour, 5 * time.Minute, false, true},
+		{"vacation before away", 4 * time.Hour, time.Hour, 5 * time.Minute, false, true},
+		{"zero check interval", 4 * time.Hour, 48 * time.Hour, 0, false, true},
+		{"ignored when disabled", 0, 0, 0, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.HouseStateEnabled = !tt.disabled
+			cfg.HouseAwayThreshold = tt.away
+			cfg.HouseVacationThreshold = tt.vacation
+			cfg.HouseStateCheckInterval = tt.interval
+
+			err := cfg.Validate()
+			if tt.wantErr && err == nil {
+				t.Error("Expected the house state settings to be rejected")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected the house state settings to be accepted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEffectiveConsolidationSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		hours    int
+		want     string
+	}{
+		{"trigger only", "", 0, ""},
+		{"schedule", "30 3 * * *", 0, "30 3 * * *"},
+		{"deprecated interval", "", 6, "6h"},
+		{"schedule wins over interval", "30 3 * * *", 6, "30 3 * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.ConsolidationSchedule = tt.schedule
+			cfg.ConsolidationIntervalHours = tt.hours
+
+			if got := cfg.EffectiveConsolidationSchedule(); got != tt.want {
+				t.Errorf("Expected schedule %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHousehold(t *testing.T) {
+	cfg := NewConfig()
+	if got := cfg.Household(); got != DefaultHousehold {
+		t.Errorf("Expected %q without a household ID, got %q", DefaultHousehold, got)
+	}
+
+	cfg.HouseholdID = "smith"
+	if got := cfg.Household(); got != "smith" {
+		t.Errorf("Expected %q, got %q", "smith", got)
+	}
+}
+
+func TestTopicPrefix(t *testing.T) {
+	tests := []struct {
+		topicPrefix string
+		householdID string
+		want        string
+	}{
+		{"", "", ""},
+		{"site1/", "", "site1/"},
+		{"", "smith", "households/smith/"},
+		{"site1/", "smith", "site1/households/smith/"},
+	}
+
+	for _, tt := range tests {
+		cfg := NewConfig()
+		cfg.MQTTTopicPrefix = tt.topicPrefix
+		cfg.HouseholdID = tt.householdID
+
+		if got := cfg.TopicPrefix(); got != tt.want {
+			t.Errorf("prefix %q, household %q: expected %q, got %q", tt.topicPrefix, tt.householdID, tt.want, got)
+		}
+	}
+}
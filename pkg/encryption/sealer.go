@@ -0,0 +1,216 @@
+// Package encryption seals JSON documents stored in Postgres with AES-256-GCM
+// for installations keeping behavior data on shared hosts.
+//
+// A sealed document keeps the fields SQL queries and generated columns read
+// (times, location, day type, ...) in clear and carries the full document,
+// encrypted, under "jeeves:sealed":
+//
+//	{"jeeves:startedAt": "...", "jeeves:sealed": {"kid": "3f2a9c1e", "data": "<base64 nonce+ciphertext>"}}
+//
+// Opening a document overlays its clear fields on the decrypted one, so
+// fields changed in SQL after sealing (jsonb_set, ||) still take effect.
+// The ciphertext is bound to the id of the row it is stored in, so a sealed
+// document copied onto another row fails to open.
+// Documents without "jeeves:sealed" are returned unchanged, so encryption can
+// be enabled on an existing database.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+// SealedField holds the encrypted document
+const SealedField = "jeeves:sealed"
+
+// keySize is the AES-256 key length in bytes
+const keySize = 32
+
+// envelope is the value of SealedField
+type envelope struct {
+	KeyID string `json:"kid"`  // first 4 bytes of the key's SHA-256, hex
+	Data  string `json:"data"` // base64 of nonce followed by ciphertext
+}
+
+// Sealer encrypts and decrypts documents. A nil *Sealer stores documents in
+// clear and only fails on documents that were sealed.
+type Sealer struct {
+	aead  cipher.AEAD
+	keyID string
+}
+
+// NewSealer returns a sealer for the key in JEEVES_ENCRYPTION_KEY or the file
+// named by JEEVES_ENCRYPTION_KEY_FILE (base64, 32 bytes), or nil when neither
+// is set
+func NewSealer(cfg *config.Config) (*Sealer, error) {
+	encoded := cfg.EncryptionKey
+	if encoded == "" && cfg.EncryptionKeyFile != "" {
+		raw, err := os.ReadFile(cfg.EncryptionKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+		}
+		encoded = string(raw)
+	}
+	if encoded == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption key: %w", err)
+	}
+	return NewSealerFromKey(key)
+}
+
+// NewSealerFromKey returns a sealer for a raw 32-byte key
+func NewSealerFromKey(key []byte) (*Sealer, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", keySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	sum := sha256.Sum256(key)
+	return &Sealer{aead: aead, keyID: hex.EncodeToString(sum[:4])}, nil
+}
+
+// KeyID identifies the key documents are sealed with
+func (s *Sealer) KeyID() string {
+	if s == nil {
+		return ""
+	}
+	return s.keyID
+}
+
+// Seal encrypts a JSON object for the row rowID, keeping clearFields
+// readable beside it. With a nil sealer the document is returned unchanged.
+func (s *Sealer) Seal(doc []byte, clearFields []string, rowID string) ([]byte, error) {
+	if s == nil {
+		return doc, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	if _, ok := fields[SealedField]; ok {
+		return doc, nil
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, doc, []byte(rowID))
+
+	clear := make(map[string]json.RawMessage, len(clearFields)+1)
+	for _, field := range clearFields {
+		if value, ok := fields[field]; ok {
+			clear[field] = value
+		}
+	}
+	env, err := json.Marshal(envelope{KeyID: s.keyID, Data: base64.StdEncoding.EncodeToString(sealed)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode envelope: %w", err)
+	}
+	clear[SealedField] = env
+
+	return json.Marshal(clear)
+}
+
+// Open returns the full document of a JSON object sealed for the row rowID,
+// or doc unchanged when it is not sealed
+func (s *Sealer) Open(doc []byte, rowID string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		// Not an object (e.g. null); nothing to open
+		return doc, nil
+	}
+	raw, ok := fields[SealedField]
+	if !ok {
+		return doc, nil
+	}
+
+	plain, err := s.decrypt(raw, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	var full map[string]json.RawMessage
+	if err := json.Unmarshal(plain, &full); err != nil {
+		return nil, fmt.Errorf("failed to decode sealed document: %w", err)
+	}
+	for field, value := range fields {
+		if field != SealedField {
+			full[field] = value
+		}
+	}
+	return json.Marshal(full)
+}
+
+// OpenMap is Open for an already decoded document
+func (s *Sealer) OpenMap(doc map[string]interface{}, rowID string) (map[string]interface{}, error) {
+	if _, ok := doc[SealedField]; !ok {
+		return doc, nil
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	opened, err := s.Open(raw, rowID)
+	if err != nil {
+		return nil, err
+	}
+
+	var full map[string]interface{}
+	if err := json.Unmarshal(opened, &full); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	return full, nil
+}
+
+func (s *Sealer) decrypt(raw json.RawMessage, rowID string) ([]byte, error) {
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, fmt.Errorf("failed to decode envelope: %w", err)
+	}
+	if s == nil {
+		return nil, fmt.Errorf("document is encrypted (key %s) but no encryption key is configured", env.KeyID)
+	}
+	if env.KeyID != s.keyID {
+		return nil, fmt.Errorf("document is encrypted with key %s, configured key is %s", env.KeyID, s.keyID)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	plain, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(rowID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt document: %w", err)
+	}
+	return plain, nil
+}
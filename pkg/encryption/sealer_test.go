@@ -0,0 +1,154 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testRowID = "6f1c2a9e-3b7d-4c1a-9a55-2f0e8d4b7c11"
+
+func testSealer(t *testing.T, fill byte) *Sealer {
+	t.Helper()
+	sealer, err := NewSealerFromKey(bytes.Repeat([]byte{fill}, keySize))
+	if err != nil {
+		t.Fatalf("Failed to create sealer: %v", err)
+	}
+	return sealer
+}
+
+func decode(t *testing.T, doc []byte) map[string]json.RawMessage {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		t.Fatalf("Failed to decode %s: %v", doc, err)
+	}
+	return fields
+}
+
+func TestSeal_RoundTrip(t *testing.T) {
+	s := testSealer(t, 1)
+	doc := []byte(`{"jeeves:startedAt":"2025-10-17T08:00:00Z","location":"kitchen","note":"private"}`)
+
+	sealed, err := s.Seal(doc, []string{"jeeves:startedAt", "location"}, testRowID)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if strings.Contains(string(sealed), "private") {
+		t.Errorf("Expected the sealed document to hide unlisted fields, got %s", sealed)
+	}
+
+	stored := decode(t, sealed)
+	if string(stored["location"]) != `"kitchen"` {
+		t.Errorf("Expected location in clear, got %s", stored["location"])
+	}
+	if _, ok := stored["note"]; ok {
+		t.Error("Expected note to be sealed")
+	}
+	if _, ok := stored[SealedField]; !ok {
+		t.Fatalf("Expected %s in the sealed document", SealedField)
+	}
+
+	opened, err := s.Open(sealed, testRowID)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got := decode(t, opened); string(got["note"]) != `"private"` || len(got) != 3 {
+		t.Errorf("Expected the original document, got %s", opened)
+	}
+
+	// Sealing twice leaves the document alone
+	again, err := s.Seal(sealed, nil, testRowID)
+	if err != nil || !bytes.Equal(again, sealed) {
+		t.Errorf("Expected an already sealed document unchanged, got %s (%v)", again, err)
+	}
+}
+
+func TestOpen_ClearFieldsOverlay(t *testing.T) {
+	s := testSealer(t, 1)
+	sealed, err := s.Seal([]byte(`{"jeeves:endedAt":"2025-10-17T09:00:00Z","note":"private"}`), []string{"jeeves:endedAt"}, testRowID)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+
+	// jsonb_set on the clear copy after sealing
+	stored := decode(t, sealed)
+	stored["jeeves:endedAt"] = json.RawMessage(`"2025-10-17T10:00:00Z"`)
+	stored["jeeves:userLabel"] = json.RawMessage(`"cooking"`)
+	sealed, _ = json.Marshal(stored)
+
+	opened, err := s.Open(sealed, testRowID)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	got := decode(t, opened)
+	if string(got["jeeves:endedAt"]) != `"2025-10-17T10:00:00Z"` || string(got["jeeves:userLabel"]) != `"cooking"` {
+		t.Errorf("Expected the clear fields to override the sealed ones, got %s", opened)
+	}
+	if _, ok := got[SealedField]; ok {
+		t.Errorf("Expected %s removed from the opened document", SealedField)
+	}
+}
+
+func TestOpen_Rejects(t *testing.T) {
+	s := testSealer(t, 1)
+	sealed, err := s.Seal([]byte(`{"note":"private"}`), nil, testRowID)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(decode(t, sealed)[SealedField], &env); err != nil {
+		t.Fatalf("Failed to decode envelope: %v", err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(env.Data)
+	data[len(data)-1] ^= 1
+	tampered, _ := json.Marshal(map[string]envelope{
+		SealedField: {KeyID: env.KeyID, Data: base64.StdEncoding.EncodeToString(data)},
+	})
+
+	tests := []struct {
+		name   string
+		sealer *Sealer
+		doc    []byte
+		rowID  string
+	}{
+		{"tampered ciphertext", s, tampered, testRowID},
+		{"other row", s, sealed, "0b3e8f52-9c41-4d7e-8a1f-6d2c5b9e4a30"},
+		{"wrong key", testSealer(t, 2), sealed, testRowID},
+		{"no key", nil, sealed, testRowID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if opened, err := tt.sealer.Open(tt.doc, tt.rowID); err == nil {
+				t.Errorf("Expected Open to fail, got %s", opened)
+			}
+		})
+	}
+}
+
+func TestOpen_Unsealed(t *testing.T) {
+	doc := []byte(`{"note":"plain"}`)
+
+	for name, sealer := range map[string]*Sealer{"no key": nil, "key": testSealer(t, 1)} {
+		opened, err := sealer.Open(doc, testRowID)
+		if err != nil || !bytes.Equal(opened, doc) {
+			t.Errorf("%s: expected the document unchanged, got %s (%v)", name, opened, err)
+		}
+	}
+
+	var nilSealer *Sealer
+	sealed, err := nilSealer.Seal(doc, nil, testRowID)
+	if err != nil || !bytes.Equal(sealed, doc) {
+		t.Errorf("Expected a nil sealer to store the document in clear, got %s (%v)", sealed, err)
+	}
+}
+
+func TestNewSealerFromKey_Length(t *testing.T) {
+	if _, err := NewSealerFromKey(make([]byte, 16)); err == nil {
+		t.Error("Expected a 16-byte key to be rejected")
+	}
+}
@@ -0,0 +1,96 @@
+// Package errcode classifies errors so callers can branch on the kind of
+// failure (retry a transient one, drop an invalid one) with errors.Is instead
+// of matching messages.
+package errcode
+
+import (
+	"errors"
+	"sync"
+)
+
+// Error classes. Classified errors match one of these with errors.Is while
+// keeping their own message and wrapped cause.
+var (
+	ErrNotFound   = errors.New("not found")        // the key, row or resource does not exist
+	ErrTransient  = errors.New("transient error")  // the dependency is unavailable; retrying may succeed
+	ErrValidation = errors.New("validation error") // the input or response is invalid; retrying will not help
+)
+
+// Codes returned by Of, as reported by health checks
+const (
+	NotFound   = "not_found"
+	Transient  = "transient"
+	Validation = "validation"
+	Internal   = "internal" // unclassified
+)
+
+var (
+	mu     sync.Mutex
+	counts = make(map[string]int64)
+)
+
+type classified struct {
+	class error
+	err   error
+}
+
+func (e *classified) Error() string   { return e.err.Error() }
+func (e *classified) Unwrap() []error { return []error{e.err, e.class} }
+
+// New returns a sentinel error of the given class, for packages that define
+// their own errors (storage.ErrEpisodeNotFound is a NotFound)
+func New(class error, message string) error {
+	return &classified{class: class, err: errors.New(message)}
+}
+
+// Wrap classifies err, leaving its message unchanged, and counts it. Errors
+// that already have a class, and nil, are returned as they are.
+func Wrap(class error, err error) error {
+	if err == nil {
+		return nil
+	}
+	if Of(err) != Internal {
+		return err
+	}
+
+	wrapped := &classified{class: class, err: err}
+	record(Of(wrapped))
+	return wrapped
+}
+
+// Of returns the code of err's class, Internal when it has none
+func Of(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return NotFound
+	case errors.Is(err, ErrTransient):
+		return Transient
+	case errors.Is(err, ErrValidation):
+		return Validation
+	}
+	return Internal
+}
+
+// Retryable reports whether err is transient
+func Retryable(err error) bool {
+	return errors.Is(err, ErrTransient)
+}
+
+// Counts returns how many errors of each class Wrap has seen since the
+// process started
+func Counts() map[string]int64 {
+	mu.Lock()
+	defer mu.Unlock()
+
+	snapshot := make(map[string]int64, len(counts))
+	for code, n := range counts {
+		snapshot[code] = n
+	}
+	return snapshot
+}
+
+func record(code string) {
+	mu.Lock()
+	counts[code]++
+	mu.Unlock()
+}
@@ -0,0 +1,110 @@
+// Package events defines the envelope agents publish their events in. The
+// envelope carries a schema version, the producing agent, the publish time and
+// a trace ID next to the event payload:
+//
+//	{"schema_version": 1, "producer": "occupancy-agent", "ts": "...", "trace_id": "...", "payload": {...}}
+//
+// Consumers decode with Unmarshal, which also accepts bare payloads published
+// before the envelope was introduced.
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the envelope version written by Marshal. Bare payloads
+// decode as version 0.
+const SchemaVersion = 1
+
+// Producers of enveloped events
+const (
+	ProducerBehavior    = "behavior-agent"
+	ProducerOccupancy   = "occupancy-agent"
+	ProducerIlluminance = "illuminance-agent"
+)
+
+// Envelope wraps an event payload with metadata
+type Envelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	Producer      string          `json:"producer"`
+	Timestamp     time.Time       `json:"ts"`
+	TraceID       string          `json:"trace_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Marshal wraps payload in an envelope with a new trace ID
+func Marshal(producer string, payload interface{}) ([]byte, error) {
+	return MarshalTrace(producer, "", payload)
+}
+
+// MarshalTrace wraps payload in an envelope that continues traceID, so an event
+// published in response to another can be followed back to it. An empty
+// traceID starts a new trace.
+func MarshalTrace(producer, traceID string, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+	if traceID == "" {
+		traceID = uuid.NewString()
+	}
+
+	envelope, err := json.Marshal(Envelope{
+		SchemaVersion: SchemaVersion,
+		Producer:      producer,
+		Timestamp:     time.Now().UTC(),
+		TraceID:       traceID,
+		Payload:       data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event envelope: %w", err)
+	}
+	return envelope, nil
+}
+
+// Unmarshal decodes an event's payload into v and returns its envelope. A bare
+// payload, published without an envelope, is decoded as it is and returned in
+// an envelope with SchemaVersion 0 and no other metadata.
+func Unmarshal(data []byte, v interface{}) (*Envelope, error) {
+	envelope := Decode(data)
+	if v != nil {
+		if err := json.Unmarshal(envelope.Payload, v); err != nil {
+			return envelope, fmt.Errorf("failed to decode event payload: %w", err)
+		}
+	}
+	return envelope, nil
+}
+
+// Decode returns data's envelope without decoding the payload. Anything that
+// is not an envelope is treated as a bare payload.
+func Decode(data []byte) *Envelope {
+	var probe struct {
+		SchemaVersion *int            `json:"schema_version"`
+		Producer      string          `json:"producer"`
+		Timestamp     time.Time       `json:"ts"`
+		TraceID       string          `json:"trace_id"`
+		Payload       json.RawMessage `json:"payload"`
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' && json.Unmarshal(trimmed, &probe) == nil &&
+		probe.SchemaVersion != nil && *probe.SchemaVersion >= 1 && len(probe.Payload) > 0 {
+		return &Envelope{
+			SchemaVersion: *probe.SchemaVersion,
+			Producer:      probe.Producer,
+			Timestamp:     probe.Timestamp,
+			TraceID:       probe.TraceID,
+			Payload:       probe.Payload,
+		}
+	}
+	return &Envelope{Payload: data}
+}
+
+// Payload returns data's payload, unwrapping it if data is an envelope
+func Payload(data []byte) []byte {
+	return Decode(data).Payload
+}
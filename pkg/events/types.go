@@ -0,0 +1,178 @@
+package events
+
+import "fmt"
+
+// Event is a typed MQTT message. Topic returns the topic it is published on.
+type Event interface {
+	Topic() string
+}
+
+// Compile-time checks that every contract is an Event
+var (
+	_ Event = OccupancyState{}
+	_ Event = LightingEvent{}
+	_ Event = EpisodeEvent{}
+	_ Event = ConsolidationTrigger{}
+	_ Event = ConsolidationResult{}
+	_ Event = DistanceCompletion{}
+	_ Event = PatternDiscoveryCompletion{}
+	_ Event = JobInProgress{}
+)
+
+// Topics of the typed events
+const (
+	OccupancyTopicFilter           = "automation/context/occupancy/+"
+	LightingTopicFilter            = "automation/context/lighting/+"
+	EpisodeTopicPrefix             = "automation/behavior/episode/"
+	ConsolidateTopic               = "automation/behavior/consolidate"
+	ConsolidationCompletedTopic    = "automation/behavior/consolidation/completed"
+	DistancesCompletedTopic        = "automation/behavior/distances/completed"
+	PatternDiscoveryCompletedTopic = "automation/behavior/patterns/discovered"
+	JobInProgressTopic             = "automation/behavior/job/in_progress"
+)
+
+// OccupancyState is a room's occupancy, published by the occupancy agent on
+// automation/context/occupancy/{location}
+type OccupancyState struct {
+	Source     string        `json:"source"`
+	Type       string        `json:"type"` // "occupancy"
+	Location   string        `json:"location"`
+	State      string        `json:"state"`                // "occupied", "likely_empty" or "empty"
+	Confidence float64       `json:"confidence,omitempty"` // simple format used by scenarios; the agent reports Data.Confidence
+	Message    string        `json:"message,omitempty"`
+	Data       OccupancyData `json:"data"`
+	Timestamp  string        `json:"timestamp"` // RFC 3339
+}
+
+// OccupancyData is the analysis behind an occupancy state
+type OccupancyData struct {
+	Occupied           bool    `json:"occupied"`
+	Confidence         float64 `json:"confidence"`
+	Reasoning          string  `json:"reasoning"`
+	Method             string  `json:"method"`
+	PreviousState      string  `json:"previous_state"`
+	TransitionReason   string  `json:"transition_reason"`
+	MinutesSinceMotion float64 `json:"minutes_since_motion"`
+	MotionLast2Min     int     `json:"motion_last_2min"`
+	MotionLast8Min     int     `json:"motion_last_8min"`
+}
+
+// Topic returns automation/context/occupancy/{location}
+func (e OccupancyState) Topic() string {
+	return fmt.Sprintf("automation/context/occupancy/%s", e.Location)
+}
+
+// LightingEvent is a change of a room's lights: published by the light agent
+// on automation/context/lighting/{location}, and by the collector for every
+// lighting sensor reading on automation/sensor/lighting/{location}
+type LightingEvent struct {
+	Source       string  `json:"source"` // "manual" or "automated" from sensors, "light-agent" from the light agent
+	Type         string  `json:"type,omitempty"`
+	Location     string  `json:"location,omitempty"`
+	State        string  `json:"state"`      // "on" or "off"
+	Brightness   *int    `json:"brightness"` // 0-100
+	ColorTemp    *int    `json:"color_temp"` // Kelvin
+	Reason       string  `json:"reason,omitempty"`
+	Confidence   float64 `json:"confidence"`
+	Illuminating bool    `json:"illuminating,omitempty"`
+	Automated    bool    `json:"automated,omitempty"`
+	Scene        string  `json:"scene,omitempty"`
+	Shadow       bool    `json:"shadow,omitempty"`
+	Timestamp    string  `json:"timestamp"` // RFC 3339
+}
+
+// Topic returns automation/context/lighting/{location}
+func (e LightingEvent) Topic() string {
+	return fmt.Sprintf("automation/context/lighting/%s", e.Location)
+}
+
+// Episode lifecycle kinds
+const (
+	EpisodeStarted = "started"
+	EpisodeClosed  = "closed"
+)
+
+// EpisodeEvent announces that a behavioral episode started or closed, on
+// automation/behavior/episode/{kind}
+type EpisodeEvent struct {
+	Kind        string `json:"-"`                  // EpisodeStarted or EpisodeClosed, carried by the topic
+	EventID     int64  `json:"event_id,omitempty"` // outbox id, the same when an event is delivered twice
+	EpisodeID   string `json:"episode_id"`
+	Location    string `json:"location"`
+	TriggerType string `json:"trigger_type,omitempty"` // started only
+	EndReason   string `json:"end_reason,omitempty"`   // closed only
+}
+
+// Topic returns automation/behavior/episode/{kind}
+func (e EpisodeEvent) Topic() string {
+	return EpisodeTopicPrefix + e.Kind
+}
+
+// ConsolidateAction is the only action a ConsolidationTrigger accepts
+const ConsolidateAction = "consolidate"
+
+// ConsolidationTrigger asks the behavior agent to consolidate recent episodes
+type ConsolidationTrigger struct {
+	Action        string `json:"action"`                   // ConsolidateAction
+	LookbackHours int    `json:"lookback_hours,omitempty"` // 0 uses the agent's default
+	Location      string `json:"location,omitempty"`       // "universe" or empty for all locations
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// Topic returns automation/behavior/consolidate
+func (e ConsolidationTrigger) Topic() string { return ConsolidateTopic }
+
+// ConsolidationResult reports a finished consolidation
+type ConsolidationResult struct {
+	MacroEpisodesCreated   int    `json:"macro_episodes_created"`
+	MicroEpisodesProcessed int    `json:"micro_episodes_processed"`
+	Timestamp              string `json:"timestamp"` // RFC 3339
+}
+
+// Topic returns automation/behavior/consolidation/completed
+func (e ConsolidationResult) Topic() string { return ConsolidationCompletedTopic }
+
+// DistanceCompletion reports a finished (or cancelled) distance computation run
+type DistanceCompletion struct {
+	DistancesComputed int    `json:"distances_computed"`
+	Cancelled         bool   `json:"cancelled"`
+	Timestamp         string `json:"timestamp"` // RFC 3339
+}
+
+// Topic returns automation/behavior/distances/completed
+func (e DistanceCompletion) Topic() string { return DistancesCompletedTopic }
+
+// PatternDiscoveryCompletion reports a finished pattern discovery run
+type PatternDiscoveryCompletion struct {
+	PatternsCreated int    `json:"patterns_created"`
+	Timestamp       string `json:"timestamp"` // RFC 3339
+}
+
+// Topic returns automation/behavior/patterns/discovered
+func (e PatternDiscoveryCompletion) Topic() string { return PatternDiscoveryCompletedTopic }
+
+// Jobs that run one at a time across behavior agents
+const (
+	JobConsolidation = "consolidation"
+	JobDiscovery     = "discovery"
+)
+
+// JobInProgress answers a consolidation or discovery request that arrived
+// while a run of the same job was in progress
+type JobInProgress struct {
+	Job           string `json:"job"`    // JobConsolidation or JobDiscovery
+	Holder        string `json:"holder"` // agent running it
+	Queued        bool   `json:"queued"` // false when an earlier queued request already covers this one
+	CorrelationID string `json:"correlation_id,omitempty"`
+	Timestamp     string `json:"timestamp"` // RFC 3339
+}
+
+// Topic returns automation/behavior/job/in_progress
+func (e JobInProgress) Topic() string { return JobInProgressTopic }
+
+// Parse decodes an event of type T, enveloped or bare
+func Parse[T any](data []byte) (T, *Envelope, error) {
+	var event T
+	envelope, err := Unmarshal(data, &event)
+	return event, envelope, err
+}
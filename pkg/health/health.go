@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
 	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
 	"github.com/saaga0h/jeeves-platform/pkg/redis"
 )
@@ -28,9 +29,10 @@ func NewChecker(mqttClient mqtt.Client, redisClient redis.Client, logger *slog.L
 
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status    string    `json:"status"`
-	Timestamp string    `json:"timestamp"`
-	Services  *Services `json:"services,omitempty"`
+	Status    string           `json:"status"`
+	Timestamp string           `json:"timestamp"`
+	Services  *Services        `json:"services,omitempty"`
+	Errors    map[string]int64 `json:"errors,omitempty"` // classified errors since start, by errcode code
 }
 
 // Services represents the status of external dependencies
@@ -98,6 +100,7 @@ func (h *Checker) DetailedHandlerFunc() http.HandlerFunc {
 			Status:    status,
 			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
 			Services:  services,
+			Errors:    errcode.Counts(),
 		}
 
 		w.Header().Set("Content-Type", "application/json")
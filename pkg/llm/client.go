@@ -9,8 +9,28 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+)
+
+// Error classes returned by Client and the parsing helpers, see package errcode
+var (
+	ErrNotFound   = errcode.ErrNotFound   // the model is not installed
+	ErrTransient  = errcode.ErrTransient  // the LLM is unreachable, overloaded or timed out
+	ErrValidation = errcode.ErrValidation // invalid requests, and responses that fail to parse or validate
 )
 
+// classifyStatus maps an unsuccessful HTTP status to an error class
+func classifyStatus(status int) error {
+	switch {
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusTooManyRequests || status == http.StatusRequestTimeout || status >= 500:
+		return ErrTransient
+	}
+	return ErrValidation
+}
+
 // Client is the interface for LLM interactions
 type Client interface {
 	// Generate sends a prompt and returns structured JSON response
@@ -60,7 +80,7 @@ func NewOllamaClient(baseURL string, logger *slog.Logger) Client {
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second, // Generous timeout for LLM
 		},
-		logger: logger,
+		logger: logger.With("component", "llm"),
 	}
 }
 
@@ -70,10 +90,10 @@ func (c *ollamaClient) Generate(ctx context.Context, req GenerateRequest) (*Gene
 
 	// Validate request
 	if req.Model == "" {
-		return nil, fmt.Errorf("model is required")
+		return nil, errcode.Wrap(ErrValidation, fmt.Errorf("model is required"))
 	}
 	if req.Prompt == "" {
-		return nil, fmt.Errorf("prompt is required")
+		return nil, errcode.Wrap(ErrValidation, fmt.Errorf("prompt is required"))
 	}
 
 	// Force non-streaming
@@ -85,7 +105,7 @@ func (c *ollamaClient) Generate(ctx context.Context, req GenerateRequest) (*Gene
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	c.logger.Debug("LLM request",
+	c.logger.DebugContext(ctx, "LLM request",
 		"model", req.Model,
 		"prompt_length", len(req.Prompt),
 		"format", req.Format)
@@ -102,25 +122,26 @@ func (c *ollamaClient) Generate(ctx context.Context, req GenerateRequest) (*Gene
 	// Send request
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, errcode.Wrap(ErrTransient, fmt.Errorf("HTTP request failed: %w", err))
 	}
 	defer resp.Body.Close()
 
 	// Check status
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("LLM returned status %d: %s", resp.StatusCode, string(body))
+		return nil, errcode.Wrap(classifyStatus(resp.StatusCode),
+			fmt.Errorf("LLM returned status %d: %s", resp.StatusCode, string(body)))
 	}
 
 	// Parse response
 	var genResp GenerateResponse
 	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, errcode.Wrap(ErrTransient, fmt.Errorf("failed to decode response: %w", err))
 	}
 
 	duration := time.Since(startTime)
 
-	c.logger.Info("LLM response received",
+	c.logger.InfoContext(ctx, "LLM response received",
 		"model", req.Model,
 		"duration_ms", duration.Milliseconds(),
 		"eval_count", genResp.EvalCount,
@@ -139,12 +160,12 @@ func (c *ollamaClient) Health(ctx context.Context) error {
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return fmt.Errorf("health check failed: %w", err)
+		return errcode.Wrap(ErrTransient, fmt.Errorf("health check failed: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("health check returned status %d", resp.StatusCode)
+		return errcode.Wrap(classifyStatus(resp.StatusCode), fmt.Errorf("health check returned status %d", resp.StatusCode))
 	}
 
 	return nil
@@ -180,8 +201,8 @@ func ParseJSONResponse[T any](resp *GenerateResponse) (*T, error) {
 	var result T
 
 	if err := json.Unmarshal([]byte(resp.Response), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse LLM JSON: %w (response: %s)",
-			err, resp.Response)
+		return nil, errcode.Wrap(ErrValidation, fmt.Errorf("failed to parse LLM JSON: %w (response: %s)",
+			err, resp.Response))
 	}
 
 	return &result, nil
@@ -191,7 +212,7 @@ func ParseJSONResponse[T any](resp *GenerateResponse) (*T, error) {
 func ValidateJSONResponse(resp *GenerateResponse) error {
 	var js json.RawMessage
 	if err := json.Unmarshal([]byte(resp.Response), &js); err != nil {
-		return fmt.Errorf("response is not valid JSON: %w", err)
+		return errcode.Wrap(ErrValidation, fmt.Errorf("response is not valid JSON: %w", err))
 	}
 	return nil
 }
@@ -238,7 +259,7 @@ func NewMockClient() *MockClient {
 // Analyzer is a generic interface for domain-specific LLM analysis
 type Analyzer[TInput any, TOutput any] interface {
 	// BuildPrompt creates the LLM prompt from input data
-	BuildPrompt(input TInput) string
+	BuildPrompt(input TInput) (string, error)
 
 	// ParseResponse extracts structured output from LLM response
 	ParseResponse(response string) (TOutput, error)
@@ -259,9 +280,12 @@ func Analyze[TInput any, TOutput any](
 	var zero TOutput
 
 	// Build prompt
-	prompt := analyzer.BuildPrompt(input)
+	prompt, err := analyzer.BuildPrompt(input)
+	if err != nil {
+		return zero, fmt.Errorf("failed to build prompt: %w", err)
+	}
 
-	logger.Debug("Building LLM prompt", "prompt_length", len(prompt))
+	logger.DebugContext(ctx, "Building LLM prompt", "prompt_length", len(prompt))
 
 	// Create request
 	req := DefaultGenerateRequest(model, prompt)
@@ -275,18 +299,18 @@ func Analyze[TInput any, TOutput any](
 	// Parse response
 	output, err := analyzer.ParseResponse(resp.Response)
 	if err != nil {
-		logger.Error("Failed to parse LLM response",
+		logger.ErrorContext(ctx, "Failed to parse LLM response",
 			"response", resp.Response,
 			"error", err)
-		return zero, fmt.Errorf("parse response failed: %w", err)
+		return zero, fmt.Errorf("parse response failed: %w", errcode.Wrap(ErrValidation, err))
 	}
 
 	// Validate
 	if err := analyzer.Validate(output); err != nil {
-		return zero, fmt.Errorf("validation failed: %w", err)
+		return zero, fmt.Errorf("validation failed: %w", errcode.Wrap(ErrValidation, err))
 	}
 
-	logger.Debug("LLM analysis complete",
+	logger.DebugContext(ctx, "LLM analysis complete",
 		"eval_count", resp.EvalCount,
 		"duration_ms", resp.TotalDuration/1_000_000)
 
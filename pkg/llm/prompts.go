@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// promptExt is the file extension of prompt templates
+const promptExt = ".tmpl"
+
+// Prompts renders named text/template prompts. A prompt "distance" is read
+// from distance.tmpl, with per-model variants such as distance.mixtral.tmpl
+// (the model name up to ':', or the full name with ':' and '/' replaced by
+// '_', e.g. distance.mixtral_8x7b.tmpl). Files in the override directory
+// replace built-in files of the same name, so prompts can be tuned without
+// recompiling.
+type Prompts struct {
+	builtin fs.FS
+	dir     string // override directory, empty for built-in templates only
+
+	mu        sync.RWMutex
+	templates map[string]*template.Template // by file name without extension
+}
+
+// NewPrompts returns prompts read from builtin, overridden by files in dir
+func NewPrompts(builtin fs.FS, dir string) *Prompts {
+	return &Prompts{
+		builtin:   builtin,
+		dir:       dir,
+		templates: make(map[string]*template.Template),
+	}
+}
+
+// Register loads every variant of a prompt and validates it against sample,
+// a value of the type later passed to Render. Templates referring to fields
+// sample does not have are rejected (in the branches sample reaches, so it
+// should fill every field), and mistakes in an override surface at startup
+// rather than on the first LLM call.
+func (p *Prompts) Register(name string, sample any) error {
+	files := make(map[string][]byte)
+	if err := readPromptFiles(p.builtin, name, files); err != nil {
+		return fmt.Errorf("failed to read built-in prompt %s: %w", name, err)
+	}
+	if p.dir != "" {
+		if err := readPromptFiles(os.DirFS(p.dir), name, files); err != nil {
+			return fmt.Errorf("failed to read prompt %s from %s: %w", name, p.dir, err)
+		}
+	}
+	if _, ok := files[name]; !ok {
+		return fmt.Errorf("prompt %s has no %s%s", name, name, promptExt)
+	}
+
+	parsed := make(map[string]*template.Template, len(files))
+	for variant, text := range files {
+		tmpl, err := template.New(variant).Option("missingkey=error").Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("failed to parse prompt %s: %w", variant, err)
+		}
+		if err := tmpl.Execute(io.Discard, sample); err != nil {
+			return fmt.Errorf("invalid prompt %s: %w", variant, err)
+		}
+		parsed[variant] = tmpl
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for variant, tmpl := range parsed {
+		p.templates[variant] = tmpl
+	}
+	return nil
+}
+
+// Render executes the registered prompt name, using the variant for model
+// when there is one
+func (p *Prompts) Render(name, model string, data any) (string, error) {
+	p.mu.RLock()
+	var tmpl *template.Template
+	for _, variant := range promptVariants(name, model) {
+		if tmpl = p.templates[variant]; tmpl != nil {
+			break
+		}
+	}
+	p.mu.RUnlock()
+
+	if tmpl == nil {
+		return "", fmt.Errorf("prompt %s is not registered", name)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
+
+// Variants returns the registered variants of name (e.g. "distance",
+// "distance.mixtral"), sorted
+func (p *Prompts) Variants(name string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var variants []string
+	for variant := range p.templates {
+		if variant == name || strings.HasPrefix(variant, name+".") {
+			variants = append(variants, variant)
+		}
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+// promptVariants lists the template names tried for model, most specific first
+func promptVariants(name, model string) []string {
+	var variants []string
+	if model != "" {
+		full := strings.NewReplacer(":", "_", "/", "_").Replace(model)
+		variants = append(variants, name+"."+full)
+		if family, _, found := strings.Cut(model, ":"); found {
+			variants = append(variants, name+"."+family)
+		}
+	}
+	return append(variants, name)
+}
+
+// readPromptFiles adds name.tmpl and name.*.tmpl from fsys to files, keyed by
+// file name without extension
+func readPromptFiles(fsys fs.FS, name string, files map[string][]byte) error {
+	if fsys == nil {
+		return nil
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || path.Ext(file) != promptExt {
+			continue
+		}
+		variant := strings.TrimSuffix(file, promptExt)
+		if variant != name && !strings.HasPrefix(variant, name+".") {
+			continue
+		}
+
+		text, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		files[variant] = text
+	}
+	return nil
+}
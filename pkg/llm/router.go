@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tasks routed to their own model
+const (
+	TaskDistance       = "distance"       // pairwise anchor distance rating, frequent and simple
+	TaskConsolidation  = "consolidation"  // micro-episode consolidation
+	TaskInterpretation = "interpretation" // pattern interpretation of anchor clusters
+	TaskBriefing       = "briefing"       // morning briefing text (observer)
+)
+
+// Router picks the model for each task and records latency and errors per
+// model. It is a Client itself, so the components it is passed to report
+// through it.
+type Router struct {
+	client   Client
+	fallback string
+	models   map[string]string // task → model
+
+	mu    sync.Mutex
+	stats map[string]*modelStats
+}
+
+type modelStats struct {
+	requests  int64
+	errors    int64
+	totalTime time.Duration
+	lastTime  time.Duration
+	lastError string
+	lastUsed  time.Time
+}
+
+// ModelStats summarizes the requests sent to one model
+type ModelStats struct {
+	Model         string    `json:"model"`
+	Requests      int64     `json:"requests"`
+	Errors        int64     `json:"errors"`
+	ErrorRate     float64   `json:"error_rate"`
+	MeanLatencyMs int64     `json:"mean_latency_ms"` // successful requests only
+	LastLatencyMs int64     `json:"last_latency_ms"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastUsed      time.Time `json:"last_used"`
+}
+
+// NewRouter routes tasks to models, fallback for tasks without one (an empty
+// model in models also falls back)
+func NewRouter(client Client, fallback string, models map[string]string) *Router {
+	routes := make(map[string]string, len(models))
+	for task, model := range models {
+		if model != "" {
+			routes[task] = model
+		}
+	}
+	return &Router{
+		client:   client,
+		fallback: fallback,
+		models:   routes,
+		stats:    make(map[string]*modelStats),
+	}
+}
+
+// Model returns the model for task
+func (r *Router) Model(task string) string {
+	if model, ok := r.models[task]; ok {
+		return model
+	}
+	return r.fallback
+}
+
+// Routes returns the model of every task, and the fallback as "default"
+func (r *Router) Routes() map[string]string {
+	routes := map[string]string{"default": r.fallback}
+	for _, task := range []string{TaskDistance, TaskConsolidation, TaskInterpretation, TaskBriefing} {
+		routes[task] = r.Model(task)
+	}
+	return routes
+}
+
+// Generate sends req to req.Model (the fallback when empty) and records the
+// outcome
+func (r *Router) Generate(ctx context.Context, req GenerateRequest) (*GenerateResponse, error) {
+	if req.Model == "" {
+		req.Model = r.fallback
+	}
+
+	start := time.Now()
+	resp, err := r.client.Generate(ctx, req)
+	r.record(req.Model, time.Since(start), err)
+	return resp, err
+}
+
+// Health checks the underlying client
+func (r *Router) Health(ctx context.Context) error {
+	return r.client.Health(ctx)
+}
+
+// Stats returns per-model statistics since the router was created, sorted by
+// model
+func (r *Router) Stats() []ModelStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]ModelStats, 0, len(r.stats))
+	for model, s := range r.stats {
+		ms := ModelStats{
+			Model:         model,
+			Requests:      s.requests,
+			Errors:        s.errors,
+			LastLatencyMs: s.lastTime.Milliseconds(),
+			LastError:     s.lastError,
+			LastUsed:      s.lastUsed,
+		}
+		if s.requests > 0 {
+			ms.ErrorRate = float64(s.errors) / float64(s.requests)
+		}
+		if ok := s.requests - s.errors; ok > 0 {
+			ms.MeanLatencyMs = (s.totalTime / time.Duration(ok)).Milliseconds()
+		}
+		stats = append(stats, ms)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Model < stats[j].Model })
+	return stats
+}
+
+func (r *Router) record(model string, elapsed time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.stats[model]
+	if s == nil {
+		s = &modelStats{}
+		r.stats[model] = s
+	}
+	s.requests++
+	s.lastTime = elapsed
+	s.lastUsed = time.Now()
+	if err != nil {
+		s.errors++
+		s.lastError = err.Error()
+		return
+	}
+	s.totalTime += elapsed
+}
@@ -0,0 +1,183 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/saaga0h/jeeves-platform/pkg/mqtt"
+)
+
+// LevelsTopic carries runtime log level changes, see Levels.HandleMessage
+const LevelsTopic = "automation/config/log_levels"
+
+// ModuleKey is the logger attribute naming a module. Loggers derived with
+// logger.With("component", name) log at that module's level.
+const ModuleKey = "component"
+
+// Levels holds the minimum log level of a process and per-module overrides.
+// The root module names the agent itself ("behavior"): overriding it changes
+// the default. Levels is safe for concurrent use and changes apply to existing
+// loggers immediately.
+type Levels struct {
+	root string
+
+	mu       sync.RWMutex
+	fallback slog.Level
+	modules  map[string]slog.Level
+}
+
+// NewLevels returns levels for the root module with the given default
+func NewLevels(root string, fallback slog.Level) *Levels {
+	return &Levels{
+		root:     root,
+		fallback: fallback,
+		modules:  make(map[string]slog.Level),
+	}
+}
+
+// ParseLevel converts debug, info, warn or error to a level, info when unknown
+func ParseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// Level returns the minimum level of a module
+func (l *Levels) Level(module string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if level, ok := l.modules[module]; ok {
+		return level
+	}
+	return l.fallback
+}
+
+// Apply parses overrides such as "behavior=debug,distance=warn". An empty
+// level ("distance=") removes a module's override; the root module, or a
+// bare level, sets the default. Nothing is changed when the spec is invalid.
+func (l *Levels) Apply(spec string) error {
+	type change struct {
+		module string
+		level  *slog.Level
+	}
+
+	var changes []change
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		module, name, found := strings.Cut(item, "=")
+		if !found {
+			module, name = l.root, item
+		}
+		module = strings.TrimSpace(module)
+		name = strings.TrimSpace(name)
+		if module == "" {
+			return fmt.Errorf("missing module in %q", item)
+		}
+
+		if name == "" {
+			changes = append(changes, change{module: module})
+			continue
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(name)); err != nil {
+			return fmt.Errorf("invalid log level %q for module %s", name, module)
+		}
+		changes = append(changes, change{module: module, level: &level})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range changes {
+		switch {
+		case c.module == l.root && c.level != nil:
+			l.fallback = *c.level
+		case c.level == nil:
+			delete(l.modules, c.module)
+		default:
+			l.modules[c.module] = *c.level
+		}
+	}
+	return nil
+}
+
+// LevelsSnapshot is the JSON form of Levels
+type LevelsSnapshot struct {
+	Default string            `json:"default"`
+	Modules map[string]string `json:"modules"`
+}
+
+// Snapshot returns the current levels
+func (l *Levels) Snapshot() LevelsSnapshot {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	snapshot := LevelsSnapshot{
+		Default: strings.ToLower(l.fallback.String()),
+		Modules: make(map[string]string, len(l.modules)),
+	}
+	for module, level := range l.modules {
+		snapshot.Modules[module] = strings.ToLower(level.String())
+	}
+	return snapshot
+}
+
+// LevelsRequest changes levels over HTTP or MQTT
+type LevelsRequest struct {
+	Service string `json:"service,omitempty"` // MQTT only; empty targets every agent
+	Levels  string `json:"levels"`            // as for Apply
+}
+
+// ServeHTTP serves GET (current levels) and PUT (a LevelsRequest)
+func (l *Levels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req LevelsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := l.Apply(req.Levels); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l.Snapshot())
+}
+
+// HandleMessage returns an MQTT handler applying LevelsRequests on
+// LevelsTopic addressed to service
+func (l *Levels) HandleMessage(service string, logger *slog.Logger) mqtt.MessageHandler {
+	return func(msg mqtt.Message) {
+		var req LevelsRequest
+		if err := json.Unmarshal(msg.Payload(), &req); err != nil {
+			logger.Error("Failed to parse log level request", "error", err)
+			return
+		}
+		if req.Service != "" && req.Service != service {
+			return
+		}
+		if err := l.Apply(req.Levels); err != nil {
+			logger.Warn("Invalid log level request", "levels", req.Levels, "error", err)
+			return
+		}
+		snapshot := l.Snapshot()
+		logger.Info("Log levels changed", "default", snapshot.Default, "modules", snapshot.Modules)
+	}
+}
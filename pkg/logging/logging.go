@@ -0,0 +1,108 @@
+// Package logging builds agent loggers and carries correlation IDs through
+// contexts, so the log lines of one MQTT trigger or consolidation run can be
+// joined across components (and agents) in Loki or ELK.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// CorrelationKey is the attribute holding the correlation ID, in log records
+// and in MQTT trigger payloads
+const CorrelationKey = "correlation_id"
+
+// New returns a logger writing format (text or json) to w, filtered by
+// levels. Records logged with a context carrying a correlation ID
+// (InfoContext etc.) include it as correlation_id.
+func New(w io.Writer, format string, levels *Levels) *slog.Logger {
+	// Filtering is done by levels, so the handler itself accepts everything
+	opts := &slog.HandlerOptions{Level: slog.Level(-8)}
+
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(&agentHandler{Handler: handler, levels: levels})
+}
+
+// LevelsOf returns the levels of a logger built by New, nil for any other
+// logger
+func LevelsOf(logger *slog.Logger) *Levels {
+	if h, ok := logger.Handler().(*agentHandler); ok {
+		return h.levels
+	}
+	return nil
+}
+
+type correlationKey struct{}
+
+// WithCorrelationID returns a context whose log records carry id
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of ctx, empty when there is none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// NewCorrelationID returns a fresh correlation ID
+func NewCorrelationID() string {
+	return uuid.New().String()
+}
+
+// Correlate returns ctx carrying id, or a fresh ID when id is empty. It is
+// used where work starts: an MQTT trigger (passing the ID from its payload),
+// a scheduled run or a job.
+func Correlate(ctx context.Context, id string) context.Context {
+	if id == "" {
+		id = NewCorrelationID()
+	}
+	return WithCorrelationID(ctx, id)
+}
+
+// agentHandler filters records by their module's level and adds the
+// correlation ID of the record's context
+type agentHandler struct {
+	slog.Handler
+	levels *Levels
+	module string // last ModuleKey attribute, empty for the root module
+}
+
+func (h *agentHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.Level(h.module)
+}
+
+func (h *agentHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := CorrelationID(ctx); id != "" {
+		r.AddAttrs(slog.String(CorrelationKey, id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *agentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	module := h.module
+	for _, attr := range attrs {
+		if attr.Key == ModuleKey {
+			module = attr.Value.String()
+		}
+	}
+	return &agentHandler{Handler: h.Handler.WithAttrs(attrs), levels: h.levels, module: module}
+}
+
+func (h *agentHandler) WithGroup(name string) slog.Handler {
+	return &agentHandler{Handler: h.Handler.WithGroup(name), levels: h.levels, module: h.module}
+}
@@ -0,0 +1,295 @@
+// Package metrics writes points in InfluxDB line protocol, either to
+// VictoriaMetrics (POST {url}/write) or to an InfluxDB v2 bucket.
+// VictoriaMetrics names each series {measurement}_{field}, so a
+// Point{Measurement: "sensor_temperature", Fields: {"value": 22.5}} becomes
+// sensor_temperature_value.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+const (
+	flushInterval = 10 * time.Second
+	maxBatch      = 5000
+
+	// maxBuffered bounds memory while the server is unreachable; the oldest
+	// points are dropped first
+	maxBuffered = 50000
+)
+
+// Point is one line protocol sample. Fields hold float64, int, int64, bool or
+// string values; tags with empty values are omitted.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time
+}
+
+// Writer buffers points and writes them in batches. A nil *Writer discards
+// all points, so callers need not check whether forwarding is enabled.
+type Writer struct {
+	url    string
+	header http.Header
+	client *http.Client
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	pending []Point
+	dropped int
+}
+
+// NewWriter creates a writer posting to baseURL/write
+func NewWriter(baseURL string, logger *slog.Logger) *Writer {
+	return &Writer{
+		url:    strings.TrimRight(baseURL, "/") + "/write",
+		header: http.Header{},
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger.With("component", "metrics"),
+	}
+}
+
+// NewInfluxWriter creates a writer for the InfluxDB v2 write API. InfluxDB
+// 1.8+ accepts the same API with bucket "database/retention_policy" and
+// token "username:password".
+func NewInfluxWriter(baseURL, org, bucket, token string, logger *slog.Logger) *Writer {
+	query := url.Values{"bucket": {bucket}, "precision": {"ns"}}
+	if org != "" {
+		query.Set("org", org)
+	}
+
+	w := NewWriter(baseURL, logger)
+	w.url = strings.TrimRight(baseURL, "/") + "/api/v2/write?" + query.Encode()
+	if token != "" {
+		w.header.Set("Authorization", "Token "+token)
+	}
+	return w
+}
+
+// NewFromConfig returns a writer for cfg.VictoriaMetricsURL, or nil when
+// forwarding is disabled
+func NewFromConfig(cfg *config.Config, logger *slog.Logger) *Writer {
+	if !cfg.EnableVictoriaMetrics || cfg.VictoriaMetricsURL == "" {
+		return nil
+	}
+	return NewWriter(cfg.VictoriaMetricsURL, logger)
+}
+
+// Write queues points for the next flush. It never blocks on the network.
+func (w *Writer) Write(points ...Point) {
+	if w == nil || len(points) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, points...)
+	if over := len(w.pending) - maxBuffered; over > 0 {
+		w.pending = append(w.pending[:0], w.pending[over:]...)
+		w.dropped += over
+	}
+}
+
+// Start flushes buffered points periodically until ctx is cancelled, then
+// makes a final attempt
+func (w *Writer) Start(ctx context.Context) {
+	if w == nil {
+		return
+	}
+
+	w.logger.Info("Forwarding metrics", "url", w.url, "interval", flushInterval)
+	go func() {
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := w.Flush(shutdownCtx); err != nil {
+					w.logger.Warn("Final metrics flush failed", "error", err)
+				}
+				cancel()
+				return
+			case <-ticker.C:
+				if err := w.Flush(ctx); err != nil {
+					w.logger.Warn("Failed to write metrics", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Flush writes all buffered points. On failure the points are kept for the
+// next attempt.
+func (w *Writer) Flush(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	points := w.pending
+	w.pending = nil
+	if w.dropped > 0 {
+		w.logger.Warn("Metrics buffer full, dropped oldest points", "dropped", w.dropped)
+		w.dropped = 0
+	}
+	w.mu.Unlock()
+
+	for start := 0; start < len(points); start += maxBatch {
+		end := min(start+maxBatch, len(points))
+		if err := w.post(ctx, points[start:end]); err != nil {
+			w.requeue(points[start:])
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) requeue(points []Point) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(append([]Point(nil), points...), w.pending...)
+	if over := len(w.pending) - maxBuffered; over > 0 {
+		w.pending = w.pending[over:]
+		w.dropped += over
+	}
+}
+
+func (w *Writer) post(ctx context.Context, points []Point) error {
+	var body bytes.Buffer
+	for _, p := range points {
+		line, ok := p.Line()
+		if !ok {
+			continue
+		}
+		body.WriteString(line)
+		body.WriteByte('\n')
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	for k, v := range w.header {
+		req.Header[k] = v
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("metrics write returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// Line encodes the point in line protocol with a nanosecond timestamp (the
+// server's receive time when Time is zero). It returns false when the point
+// has no usable fields.
+func (p Point) Line() (string, bool) {
+	var b strings.Builder
+	b.WriteString(escape(p.Measurement, ", "))
+
+	tagKeys := make([]string, 0, len(p.Tags))
+	for k, v := range p.Tags {
+		if v != "" {
+			tagKeys = append(tagKeys, k)
+		}
+	}
+	sort.Strings(tagKeys)
+	for _, k := range tagKeys {
+		b.WriteByte(',')
+		b.WriteString(escape(k, ",= "))
+		b.WriteByte('=')
+		b.WriteString(escape(p.Tags[k], ",= "))
+	}
+
+	fieldKeys := make([]string, 0, len(p.Fields))
+	for k := range p.Fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+
+	written := 0
+	for _, k := range fieldKeys {
+		value, ok := formatField(p.Fields[k])
+		if !ok {
+			continue
+		}
+		if written == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(escape(k, ",= "))
+		b.WriteByte('=')
+		b.WriteString(value)
+		written++
+	}
+	if written == 0 {
+		return "", false
+	}
+
+	if !p.Time.IsZero() {
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(p.Time.UnixNano(), 10))
+	}
+	return b.String(), true
+}
+
+func formatField(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32), true
+	case int:
+		return strconv.Itoa(val) + "i", true
+	case int64:
+		return strconv.FormatInt(val, 10) + "i", true
+	case bool:
+		return strconv.FormatBool(val), true
+	case string:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(val) + `"`, true
+	default:
+		return "", false
+	}
+}
+
+func escape(s, special string) string {
+	if !strings.ContainsAny(s, special) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	pahomqtt "github.com/eclipse/paho.mqtt.golang"
@@ -12,9 +13,10 @@ import (
 
 // mqttClient implements the Client interface using the Paho MQTT client
 type mqttClient struct {
-	client pahomqtt.Client
-	cfg    *config.Config
-	logger *slog.Logger
+	client   pahomqtt.Client
+	cfg      *config.Config
+	logger   *slog.Logger
+	delivery deliveryTracker
 }
 
 // NewClient creates a new MQTT client with the given configuration
@@ -94,10 +96,10 @@ func (m *mqttClient) Subscribe(topic string, qos byte, handler MessageHandler) e
 
 	// Wrap the handler to convert paho message to our interface
 	pahoHandler := func(client pahomqtt.Client, msg pahomqtt.Message) {
-		handler(&mqttMessage{msg: msg})
+		handler(&mqttMessage{msg: msg, topic: strings.TrimPrefix(msg.Topic(), m.cfg.TopicPrefix())})
 	}
 
-	token := m.client.Subscribe(topic, qos, pahoHandler)
+	token := m.client.Subscribe(m.cfg.TopicPrefix()+topic, qos, pahoHandler)
 	token.Wait()
 
 	if token.Error() != nil {
@@ -108,19 +110,42 @@ func (m *mqttClient) Subscribe(topic string, qos byte, handler MessageHandler) e
 	return nil
 }
 
-// Publish publishes a message to a topic
+// Publish publishes a message to a topic, waiting up to MQTTPublishTimeout
+// for the broker to acknowledge it
 func (m *mqttClient) Publish(topic string, qos byte, retained bool, payload []byte) error {
-	token := m.client.Publish(topic, qos, retained, payload)
-	token.Wait()
-
-	if token.Error() != nil {
-		return fmt.Errorf("failed to publish to topic %s: %w", topic, token.Error())
+	m.delivery.inFlight.Add(1)
+	token := m.client.Publish(m.cfg.TopicPrefix()+topic, qos, retained, payload)
+	if err := m.delivery.await(token, topic, m.cfg.MQTTPublishTimeout); err != nil {
+		return err
 	}
 
 	m.logger.Debug("Published message", "topic", topic, "size", len(payload))
 	return nil
 }
 
+// PublishWithResult publishes without blocking and calls handler once the
+// broker acknowledged the message, rejected it or the timeout passed
+func (m *mqttClient) PublishWithResult(topic string, qos byte, retained bool, payload []byte, handler ResultHandler) {
+	start := time.Now()
+	m.delivery.inFlight.Add(1)
+	token := m.client.Publish(m.cfg.TopicPrefix()+topic, qos, retained, payload)
+
+	go func() {
+		err := m.delivery.await(token, topic, m.cfg.MQTTPublishTimeout)
+		if err != nil {
+			m.logger.Warn("Publish not delivered", "topic", topic, "qos", qos, "error", err)
+		}
+		if handler != nil {
+			handler(PublishResult{Topic: topic, QoS: qos, Err: err, Latency: time.Since(start)})
+		}
+	}()
+}
+
+// DeliveryStats returns publish counts by outcome
+func (m *mqttClient) DeliveryStats() DeliveryStats {
+	return m.delivery.stats()
+}
+
 // IsConnected returns whether the client is currently connected
 func (m *mqttClient) IsConnected() bool {
 	return m.client.IsConnected()
@@ -128,11 +153,12 @@ func (m *mqttClient) IsConnected() bool {
 
 // mqttMessage wraps a Paho MQTT message to implement our Message interface
 type mqttMessage struct {
-	msg pahomqtt.Message
+	msg   pahomqtt.Message
+	topic string // without the client's topic prefix
 }
 
 func (m *mqttMessage) Topic() string {
-	return m.msg.Topic()
+	return m.topic
 }
 
 func (m *mqttMessage) Payload() []byte {
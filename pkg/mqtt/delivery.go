@@ -0,0 +1,153 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/saaga0h/jeeves-platform/pkg/metrics"
+)
+
+// deliveryMetricsInterval is how often delivery statistics are sampled
+const deliveryMetricsInterval = 30 * time.Second
+
+// ErrPublishTimeout is returned when the broker does not acknowledge a
+// publish within the configured timeout. The message may still arrive.
+var ErrPublishTimeout = errors.New("publish not acknowledged in time")
+
+// PublishResult is the outcome of one publish. Err is nil once the broker
+// acknowledged a QoS 1/2 message, or once a QoS 0 message was sent.
+type PublishResult struct {
+	Topic   string
+	QoS     byte
+	Err     error
+	Latency time.Duration
+}
+
+// ResultHandler receives the outcome of a publish, exactly once
+type ResultHandler func(PublishResult)
+
+// ResultPublisher is implemented by clients that report delivery without
+// blocking the caller
+type ResultPublisher interface {
+	PublishWithResult(topic string, qos byte, retained bool, payload []byte, handler ResultHandler)
+}
+
+// DeliveryStats counts publishes by outcome. InFlight are publishes still
+// waiting for the broker's acknowledgement, including those that already
+// counted as TimedOut but that paho has not completed yet.
+type DeliveryStats struct {
+	InFlight     int64
+	Acknowledged int64
+	Failed       int64
+	TimedOut     int64
+}
+
+// DeliveryReporter is implemented by clients that track publish delivery
+type DeliveryReporter interface {
+	DeliveryStats() DeliveryStats
+}
+
+// PublishWithResult publishes through c and calls handler with the outcome.
+// Clients without delivery tracking publish synchronously before handler runs.
+func PublishWithResult(c Client, topic string, qos byte, retained bool, payload []byte, handler ResultHandler) {
+	if p, ok := c.(ResultPublisher); ok {
+		p.PublishWithResult(topic, qos, retained, payload, handler)
+		return
+	}
+
+	start := time.Now()
+	err := c.Publish(topic, qos, retained, payload)
+	if handler != nil {
+		handler(PublishResult{Topic: topic, QoS: qos, Err: err, Latency: time.Since(start)})
+	}
+}
+
+// Stats returns c's delivery statistics, and false when c does not track them
+func Stats(c Client) (DeliveryStats, bool) {
+	if r, ok := c.(DeliveryReporter); ok {
+		return r.DeliveryStats(), true
+	}
+	return DeliveryStats{}, false
+}
+
+// ReportDeliveryMetrics writes c's delivery statistics (mqtt_publish_*) until
+// ctx is cancelled. Does nothing for clients without delivery tracking.
+func ReportDeliveryMetrics(ctx context.Context, c Client, w *metrics.Writer, service string) {
+	if w == nil {
+		return
+	}
+	if _, ok := Stats(c); !ok {
+		return
+	}
+
+	ticker := time.NewTicker(deliveryMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats, _ := Stats(c)
+		w.Write(metrics.Point{
+			Measurement: "mqtt_publish",
+			Tags:        map[string]string{"service": service},
+			Fields: map[string]interface{}{
+				"unacknowledged": stats.InFlight,
+				"acknowledged":   stats.Acknowledged,
+				"failed":         stats.Failed,
+				"timed_out":      stats.TimedOut,
+			},
+			Time: time.Now(),
+		})
+	}
+}
+
+// deliveryTracker counts publishes by outcome
+type deliveryTracker struct {
+	inFlight     atomic.Int64
+	acknowledged atomic.Int64
+	failed       atomic.Int64
+	timedOut     atomic.Int64
+}
+
+func (t *deliveryTracker) stats() DeliveryStats {
+	return DeliveryStats{
+		InFlight:     t.inFlight.Load(),
+		Acknowledged: t.acknowledged.Load(),
+		Failed:       t.failed.Load(),
+		TimedOut:     t.timedOut.Load(),
+	}
+}
+
+// await waits for a publish token up to timeout (0 = no limit) and records the outcome.
+// A timed-out publish stays in flight until paho completes its token.
+func (t *deliveryTracker) await(token pahomqtt.Token, topic string, timeout time.Duration) error {
+	if timeout > 0 {
+		if !token.WaitTimeout(timeout) {
+			t.timedOut.Add(1)
+			go func() {
+				<-token.Done()
+				t.inFlight.Add(-1)
+			}()
+			return fmt.Errorf("failed to publish to topic %s: %w", topic, ErrPublishTimeout)
+		}
+	} else {
+		token.Wait()
+	}
+	t.inFlight.Add(-1)
+
+	if err := token.Error(); err != nil {
+		t.failed.Add(1)
+		return fmt.Errorf("failed to publish to topic %s: %w", topic, err)
+	}
+	t.acknowledged.Add(1)
+	return nil
+}
@@ -0,0 +1,218 @@
+package mqtt
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+// fakeToken completes when complete is called
+type fakeToken struct {
+	done chan struct{}
+	err  error
+}
+
+func newFakeToken() *fakeToken {
+	return &fakeToken{done: make(chan struct{})}
+}
+
+func (t *fakeToken) complete(err error) {
+	t.err = err
+	close(t.done)
+}
+
+func (t *fakeToken) Wait() bool {
+	<-t.done
+	return true
+}
+
+func (t *fakeToken) WaitTimeout(d time.Duration) bool {
+	select {
+	case <-t.done:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func (t *fakeToken) Done() <-chan struct{} { return t.done }
+
+func (t *fakeToken) Error() error { return t.err }
+
+// fakePaho hands out the queued tokens from Publish
+type fakePaho struct {
+	pahomqtt.Client
+	tokens []*fakeToken
+	topics []string
+}
+
+func (p *fakePaho) Publish(topic string, qos byte, retained bool, payload interface{}) pahomqtt.Token {
+	p.topics = append(p.topics, topic)
+	token := p.tokens[0]
+	p.tokens = p.tokens[1:]
+	return token
+}
+
+func newTestClient(timeout time.Duration, tokens ...*fakeToken) (*mqttClient, *fakePaho) {
+	cfg := config.NewConfig()
+	cfg.MQTTPublishTimeout = timeout
+	paho := &fakePaho{tokens: tokens}
+	return &mqttClient{
+		client: paho,
+		cfg:    cfg,
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+	}, paho
+}
+
+// waitFor polls cond until it holds or a second passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPublish_Outcomes(t *testing.T) {
+	acked, rejected := newFakeToken(), newFakeToken()
+	acked.complete(nil)
+	rejected.complete(errors.New("not authorized"))
+	client, paho := newTestClient(time.Second, acked, rejected)
+
+	if err := client.Publish("automation/behavior/episode", 1, false, []byte("{}")); err != nil {
+		t.Errorf("Expected the acknowledged publish to succeed, got %v", err)
+	}
+	if err := client.Publish("automation/behavior/episode", 1, false, []byte("{}")); err == nil {
+		t.Error("Expected the rejected publish to fail")
+	}
+
+	if want := client.cfg.TopicPrefix() + "automation/behavior/episode"; paho.topics[0] != want {
+		t.Errorf("Expected topic %s, got %s", want, paho.topics[0])
+	}
+	if stats := client.DeliveryStats(); stats != (DeliveryStats{Acknowledged: 1, Failed: 1}) {
+		t.Errorf("Expected one acknowledged and one failed publish, got %+v", stats)
+	}
+}
+
+func TestPublish_TimedOutStaysInFlight(t *testing.T) {
+	token := newFakeToken()
+	client, _ := newTestClient(10*time.Millisecond, token)
+
+	err := client.Publish("automation/behavior/episode", 1, false, []byte("{}"))
+	if !errors.Is(err, ErrPublishTimeout) {
+		t.Fatalf("Expected ErrPublishTimeout, got %v", err)
+	}
+	if stats := client.DeliveryStats(); stats != (DeliveryStats{InFlight: 1, TimedOut: 1}) {
+		t.Errorf("Expected the timed-out publish still in flight, got %+v", stats)
+	}
+
+	// paho delivers it late
+	token.complete(nil)
+	waitFor(t, func() bool { return client.DeliveryStats().InFlight == 0 })
+	if stats := client.DeliveryStats(); stats.TimedOut != 1 || stats.Acknowledged != 0 {
+		t.Errorf("Expected the publish counted once as timed out, got %+v", stats)
+	}
+}
+
+func TestPublishWithResult_Tracked(t *testing.T) {
+	token := newFakeToken()
+	client, _ := newTestClient(time.Second, token)
+
+	results := make(chan PublishResult, 1)
+	PublishWithResult(client, "automation/behavior/episode", 1, false, []byte("{}"), func(r PublishResult) {
+		results <- r
+	})
+
+	if stats := client.DeliveryStats(); stats.InFlight != 1 {
+		t.Errorf("Expected the publish in flight before the acknowledgement, got %+v", stats)
+	}
+	token.complete(nil)
+
+	select {
+	case r := <-results:
+		if r.Err != nil || r.Topic != "automation/behavior/episode" || r.QoS != 1 {
+			t.Errorf("Expected a delivered QoS 1 result, got %+v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the handler to run")
+	}
+	waitFor(t, func() bool { return client.DeliveryStats() == DeliveryStats{Acknowledged: 1} })
+}
+
+func TestPublishWithResult_TimedOut(t *testing.T) {
+	token := newFakeToken()
+	client, _ := newTestClient(10*time.Millisecond, token)
+
+	results := make(chan PublishResult, 1)
+	PublishWithResult(client, "automation/behavior/episode", 1, false, []byte("{}"), func(r PublishResult) {
+		results <- r
+	})
+
+	select {
+	case r := <-results:
+		if !errors.Is(r.Err, ErrPublishTimeout) {
+			t.Errorf("Expected ErrPublishTimeout, got %v", r.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the handler to run")
+	}
+	if stats := client.DeliveryStats(); stats != (DeliveryStats{InFlight: 1, TimedOut: 1}) {
+		t.Errorf("Expected the timed-out publish still in flight, got %+v", stats)
+	}
+
+	token.complete(errors.New("connection lost"))
+	waitFor(t, func() bool { return client.DeliveryStats().InFlight == 0 })
+}
+
+// plainClient publishes synchronously without delivery tracking
+type plainClient struct {
+	Client
+	published []string
+	err       error
+}
+
+func (c *plainClient) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	c.published = append(c.published, topic)
+	return c.err
+}
+
+func TestPublishWithResult_Fallback(t *testing.T) {
+	client := &plainClient{}
+
+	var got []PublishResult
+	PublishWithResult(client, "automation/behavior/episode", 1, false, []byte("{}"), func(r PublishResult) {
+		got = append(got, r)
+	})
+
+	// The handler runs before PublishWithResult returns
+	if len(client.published) != 1 || len(got) != 1 || got[0].Err != nil || got[0].Topic != "automation/behavior/episode" {
+		t.Fatalf("Expected one synchronous publish and result, got %v and %+v", client.published, got)
+	}
+
+	client.err = errors.New("not connected")
+	PublishWithResult(client, "automation/behavior/episode", 0, false, []byte("{}"), func(r PublishResult) {
+		got = append(got, r)
+	})
+	if len(got) != 2 || got[1].Err != client.err || got[1].QoS != 0 {
+		t.Errorf("Expected the publish error in the result, got %+v", got)
+	}
+
+	// A nil handler is allowed
+	PublishWithResult(client, "automation/behavior/episode", 0, false, []byte("{}"), nil)
+	if len(client.published) != 3 {
+		t.Errorf("Expected 3 publishes, got %d", len(client.published))
+	}
+
+	if _, ok := Stats(client); ok {
+		t.Error("Expected no delivery stats for an untracked client")
+	}
+}
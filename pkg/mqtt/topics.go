@@ -1,6 +1,9 @@
 package mqtt
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Topic constants based on mqtt-topics.md specification
 const (
@@ -36,3 +39,12 @@ func ConvertRawToProcessed(rawTopic string) string {
 	// Simple string replacement as per specification
 	return rawTopic[0:14] + "sensor" + rawTopic[17:]
 }
+
+// TopicShadowBase prefixes the intended actions of agents running in shadow mode
+const TopicShadowBase = "automation/shadow"
+
+// ShadowTopic maps an actuation topic to its shadow-mode equivalent
+// automation/command/light/{location} -> automation/shadow/command/light/{location}
+func ShadowTopic(topic string) string {
+	return TopicShadowBase + "/" + strings.TrimPrefix(topic, "automation/")
+}
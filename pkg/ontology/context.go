@@ -1,13 +1,48 @@
 package ontology
 
-// GetDefaultContext returns the standard JSON-LD context
-func GetDefaultContext() map[string]interface{} {
-	return map[string]interface{}{
+// ContextVersion is the ontology version written into new documents as
+// jeeves:schemaVersion. Documents without a version are version 1.
+const ContextVersion = 2
+
+// contexts holds the JSON-LD context of every ontology version
+var contexts = map[int]map[string]interface{}{
+	1: {
 		"@vocab": "https://saref.etsi.org/core#",
 		"jeeves": "https://jeeves.home/vocab#",
 		"adl":    "http://purl.org/adl#",
 		"sosa":   "http://www.w3.org/ns/sosa/",
 		"prov":   "http://www.w3.org/ns/prov#",
 		"xsd":    "http://www.w3.org/2001/XMLSchema#",
+	},
+	// Version 2 types the episode timestamps and version
+	2: {
+		"@vocab":               "https://saref.etsi.org/core#",
+		"jeeves":               "https://jeeves.home/vocab#",
+		"adl":                  "http://purl.org/adl#",
+		"sosa":                 "http://www.w3.org/ns/sosa/",
+		"prov":                 "http://www.w3.org/ns/prov#",
+		"xsd":                  "http://www.w3.org/2001/XMLSchema#",
+		"jeeves:startedAt":     map[string]interface{}{"@type": "xsd:dateTime"},
+		"jeeves:endedAt":       map[string]interface{}{"@type": "xsd:dateTime"},
+		"jeeves:schemaVersion": map[string]interface{}{"@type": "xsd:integer"},
+	},
+}
+
+// GetDefaultContext returns the standard JSON-LD context
+func GetDefaultContext() map[string]interface{} {
+	context, _ := GetContext(ContextVersion)
+	return context
+}
+
+// GetContext returns a copy of the JSON-LD context of an ontology version
+func GetContext(version int) (map[string]interface{}, bool) {
+	context, ok := contexts[version]
+	if !ok {
+		return nil, false
+	}
+	copied := make(map[string]interface{}, len(context))
+	for term, definition := range context {
+		copied[term] = definition
 	}
+	return copied, true
 }
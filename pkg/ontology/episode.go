@@ -7,18 +7,37 @@ import (
 	"github.com/google/uuid"
 )
 
+// EpisodeType is the @type of behavioral episode documents
+const EpisodeType = "jeeves:BehavioralEpisode"
+
+// EpisodeIndexFields are the episode fields read in SQL (generated columns,
+// filters, the observer's queries); they stay in clear when episode
+// documents are encrypted
+var EpisodeIndexFields = []string{
+	"@type",
+	"@id",
+	"jeeves:schemaVersion",
+	"jeeves:startedAt",
+	"jeeves:endedAt",
+	"jeeves:triggerType",
+	"jeeves:occupant",
+	"jeeves:triggeredAdjustment",
+	"adl:activity",
+}
+
 // BehavioralEpisode is the root JSON-LD document
 type BehavioralEpisode struct {
-	Context    map[string]interface{} `json:"@context"`
-	Type       string                 `json:"@type"`
-	ID         string                 `json:"@id"`
-	StartedAt  time.Time              `json:"jeeves:startedAt"`
-	EndedAt    *time.Time             `json:"jeeves:endedAt,omitempty"`
-	DayOfWeek  string                 `json:"jeeves:dayOfWeek"`
-	TimeOfDay  string                 `json:"jeeves:timeOfDay"`
-	Duration   string                 `json:"jeeves:duration,omitempty"`
-	Activity   Activity               `json:"adl:activity"`
-	EnvContext EnvironmentalContext   `json:"jeeves:hadEnvironmentalContext"`
+	Context       map[string]interface{} `json:"@context"`
+	Type          string                 `json:"@type"`
+	ID            string                 `json:"@id"`
+	SchemaVersion int                    `json:"jeeves:schemaVersion"`
+	StartedAt     time.Time              `json:"jeeves:startedAt"`
+	EndedAt       *time.Time             `json:"jeeves:endedAt,omitempty"`
+	DayOfWeek     string                 `json:"jeeves:dayOfWeek"`
+	TimeOfDay     string                 `json:"jeeves:timeOfDay"`
+	Duration      string                 `json:"jeeves:duration,omitempty"`
+	Activity      Activity               `json:"adl:activity"`
+	EnvContext    EnvironmentalContext   `json:"jeeves:hadEnvironmentalContext"`
 }
 
 type Activity struct {
@@ -44,12 +63,13 @@ func NewEpisode(activity Activity, location Location) *BehavioralEpisode {
 	now := time.Now()
 
 	return &BehavioralEpisode{
-		Context:   GetDefaultContext(),
-		Type:      "jeeves:BehavioralEpisode",
-		ID:        fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
-		StartedAt: now,
-		DayOfWeek: now.Weekday().String(),
-		TimeOfDay: getTimeOfDay(now),
+		Context:       GetDefaultContext(),
+		Type:          EpisodeType,
+		ID:            fmt.Sprintf("urn:uuid:%s", uuid.New().String()),
+		SchemaVersion: ContextVersion,
+		StartedAt:     now,
+		DayOfWeek:     now.Weekday().String(),
+		TimeOfDay:     getTimeOfDay(now),
 		Activity: Activity{
 			Type:     activity.Type,
 			Name:     activity.Name,
@@ -0,0 +1,81 @@
+package ontology
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// episodeMigrations upgrade an episode document from the version it is keyed
+// by to the next one
+var episodeMigrations = map[int]func(doc map[string]interface{}){
+	1: migrateEpisodeV1,
+}
+
+// MigrateEpisode upgrades an episode document in place to ContextVersion and
+// reports whether it changed. Documents from a newer ontology are rejected.
+func MigrateEpisode(doc map[string]interface{}) (bool, error) {
+	version := DocumentVersion(doc)
+	if version > ContextVersion {
+		return false, fmt.Errorf("%w: jeeves:schemaVersion %d is newer than %d", ErrInvalidDocument, version, ContextVersion)
+	}
+
+	changed := false
+	for ; version < ContextVersion; version++ {
+		migrate, ok := episodeMigrations[version]
+		if !ok {
+			return changed, fmt.Errorf("no migration from ontology version %d", version)
+		}
+		migrate(doc)
+		doc["jeeves:schemaVersion"] = version + 1
+		doc["@context"], _ = GetContext(version + 1)
+		changed = true
+	}
+	return changed, nil
+}
+
+// MigrateEpisodeJSON upgrades a serialized episode document, returning the
+// new document and whether it changed
+func MigrateEpisodeJSON(raw []byte) ([]byte, bool, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	changed, err := MigrateEpisode(doc)
+	if err != nil || !changed {
+		return raw, false, err
+	}
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal migrated document: %w", err)
+	}
+	return upgraded, true, nil
+}
+
+// migrateEpisodeV1 fills in the location structure that early version 1
+// documents (and hand-written test fixtures) left out
+func migrateEpisodeV1(doc map[string]interface{}) {
+	if doc["@type"] == nil {
+		doc["@type"] = EpisodeType
+	}
+
+	activity, ok := doc["adl:activity"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	location, ok := activity["adl:location"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if locType, _ := location["@type"].(string); !strings.HasPrefix(locType, "saref:") {
+		location["@type"] = "saref:Room"
+	}
+	if name, _ := location["name"].(string); name != "" {
+		if id, _ := location["@id"].(string); id == "" {
+			location["@id"] = fmt.Sprintf("urn:room:%s", name)
+		}
+	}
+}
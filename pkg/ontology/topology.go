@@ -0,0 +1,216 @@
+package ontology
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Room is a location in the home with its floor and directly connected rooms
+type Room struct {
+	ID       string   `json:"id"`
+	Floor    int      `json:"floor"`
+	Adjacent []string `json:"adjacent,omitempty"`
+}
+
+// Topology describes the home's rooms and which rooms are adjacent. Adjacency
+// is symmetric: listing a neighbour on either room is enough.
+type Topology struct {
+	rooms     map[string]*Room
+	adjacency map[string]map[string]bool
+}
+
+// topologyFile is the JSON layout of a topology configuration file
+type topologyFile struct {
+	Rooms []Room `json:"rooms"`
+}
+
+// NewTopology builds a topology from rooms, adding unknown neighbours as rooms
+// on the same floor
+func NewTopology(rooms []Room) *Topology {
+	t := &Topology{
+		rooms:     make(map[string]*Room),
+		adjacency: make(map[string]map[string]bool),
+	}
+
+	for i := range rooms {
+		room := rooms[i]
+		t.rooms[room.ID] = &room
+	}
+	for _, room := range rooms {
+		for _, neighbor := range room.Adjacent {
+			if _, ok := t.rooms[neighbor]; !ok {
+				t.rooms[neighbor] = &Room{ID: neighbor, Floor: room.Floor}
+			}
+			t.link(room.ID, neighbor)
+		}
+	}
+
+	return t
+}
+
+func (t *Topology) link(a, b string) {
+	if a == b {
+		return
+	}
+	if t.adjacency[a] == nil {
+		t.adjacency[a] = make(map[string]bool)
+	}
+	if t.adjacency[b] == nil {
+		t.adjacency[b] = make(map[string]bool)
+	}
+	t.adjacency[a][b] = true
+	t.adjacency[b][a] = true
+}
+
+// DefaultTopology returns the built-in single-floor plan used by the test scenarios
+func DefaultTopology() *Topology {
+	return NewTopology([]Room{
+		{ID: "bedroom", Adjacent: []string{"bathroom", "kitchen"}},
+		{ID: "bathroom", Adjacent: []string{"kitchen"}},
+		{ID: "kitchen", Adjacent: []string{"dining_room"}},
+		{ID: "dining_room", Adjacent: []string{"living_room"}},
+		{ID: "living_room", Adjacent: []string{"study"}},
+		{ID: "study"},
+		{ID: "hallway"},
+	})
+}
+
+// LoadTopology reads a topology from a JSON file:
+//
+//	{"rooms": [{"id": "kitchen", "floor": 0, "adjacent": ["dining_room", "hallway"]}]}
+func LoadTopology(path string) (*Topology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topology: %w", err)
+	}
+
+	var file topologyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse topology: %w", err)
+	}
+	if len(file.Rooms) == 0 {
+		return nil, fmt.Errorf("topology %s defines no rooms", path)
+	}
+
+	for _, room := range file.Rooms {
+		if room.ID == "" {
+			return nil, fmt.Errorf("topology %s has a room without an id", path)
+		}
+	}
+
+	return NewTopology(file.Rooms), nil
+}
+
+// LoadTopologyOrDefault loads the topology at path, or returns DefaultTopology when path is empty
+func LoadTopologyOrDefault(path string) (*Topology, error) {
+	if path == "" {
+		return DefaultTopology(), nil
+	}
+	return LoadTopology(path)
+}
+
+// WithAdjacent returns a copy of the topology with extra adjacent pairs added,
+// e.g. pairs learned from observed transitions. Unknown rooms are added on floor 0.
+func (t *Topology) WithAdjacent(pairs [][2]string) *Topology {
+	merged := &Topology{
+		rooms:     make(map[string]*Room, len(t.rooms)),
+		adjacency: make(map[string]map[string]bool, len(t.adjacency)),
+	}
+	for id, room := range t.rooms {
+		r := *room
+		merged.rooms[id] = &r
+	}
+	for a, neighbors := range t.adjacency {
+		for b := range neighbors {
+			merged.link(a, b)
+		}
+	}
+
+	for _, pair := range pairs {
+		for _, id := range pair {
+			if _, ok := merged.rooms[id]; !ok {
+				merged.rooms[id] = &Room{ID: id}
+			}
+		}
+		merged.link(pair[0], pair[1])
+	}
+
+	return merged
+}
+
+// IsAdjacent reports whether two different rooms are directly connected
+func (t *Topology) IsAdjacent(a, b string) bool {
+	return t.adjacency[a][b]
+}
+
+// Neighbors returns the rooms adjacent to room, sorted
+func (t *Topology) Neighbors(room string) []string {
+	neighbors := make([]string, 0, len(t.adjacency[room]))
+	for neighbor := range t.adjacency[room] {
+		neighbors = append(neighbors, neighbor)
+	}
+	sort.Strings(neighbors)
+	return neighbors
+}
+
+// Floor returns the floor of a room and whether the room is known
+func (t *Topology) Floor(room string) (int, bool) {
+	r, ok := t.rooms[room]
+	if !ok {
+		return 0, false
+	}
+	return r.Floor, true
+}
+
+// Rooms returns all room IDs, sorted
+func (t *Topology) Rooms() []string {
+	ids := make([]string, 0, len(t.rooms))
+	for id := range t.rooms {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// AdjacentPairs returns every adjacent pair once, as "a|b" with a < b. Used to
+// pass adjacency into SQL queries.
+func (t *Topology) AdjacentPairs() []string {
+	var pairs []string
+	for a, neighbors := range t.adjacency {
+		for b := range neighbors {
+			if a < b {
+				pairs = append(pairs, a+"|"+b)
+			}
+		}
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// Describe explains how two rooms relate, for LLM prompts
+func (t *Topology) Describe(a, b string) string {
+	if a == b {
+		return fmt.Sprintf("same room (%s)", a)
+	}
+
+	floorA, okA := t.Floor(a)
+	floorB, okB := t.Floor(b)
+
+	var parts []string
+	if t.IsAdjacent(a, b) {
+		parts = append(parts, fmt.Sprintf("%s and %s are adjacent", a, b))
+	} else {
+		parts = append(parts, fmt.Sprintf("%s and %s are not adjacent", a, b))
+	}
+	switch {
+	case okA && okB && floorA == floorB:
+		parts = append(parts, fmt.Sprintf("both on floor %d", floorA))
+	case okA && okB:
+		parts = append(parts, fmt.Sprintf("on floors %d and %d", floorA, floorB))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,115 @@
+package ontology
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDocument is wrapped by every validation failure
+var ErrInvalidDocument = errors.New("invalid JSON-LD document")
+
+// ValidationError lists everything wrong with a document
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidDocument, strings.Join(e.Problems, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidDocument
+}
+
+// ValidateEpisodeJSON validates a serialized episode document
+func ValidateEpisodeJSON(raw []byte) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	return ValidateEpisode(doc)
+}
+
+// ValidateEpisode checks an episode document against the current ontology:
+// context, type and version, an RFC3339 jeeves:startedAt (and jeeves:endedAt
+// not before it), and an adl:activity whose adl:location is a saref location
+// with an @id and name. Older versions should be migrated first.
+func ValidateEpisode(doc map[string]interface{}) error {
+	var problems []string
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if _, ok := doc["@context"].(map[string]interface{}); !ok {
+		fail("@context is missing")
+	}
+	if doc["@type"] != EpisodeType {
+		fail("@type must be %s", EpisodeType)
+	}
+	if version := DocumentVersion(doc); version != ContextVersion {
+		fail("jeeves:schemaVersion %d is not the current version %d", version, ContextVersion)
+	}
+
+	startedAt, ok := timestampField(doc, "jeeves:startedAt")
+	if !ok {
+		fail("jeeves:startedAt must be an RFC3339 timestamp")
+	}
+	if _, present := doc["jeeves:endedAt"]; present {
+		endedAt, ok := timestampField(doc, "jeeves:endedAt")
+		switch {
+		case !ok:
+			fail("jeeves:endedAt must be an RFC3339 timestamp")
+		case !startedAt.IsZero() && endedAt.Before(startedAt):
+			fail("jeeves:endedAt is before jeeves:startedAt")
+		}
+	}
+
+	activity, ok := doc["adl:activity"].(map[string]interface{})
+	if !ok {
+		fail("adl:activity is missing")
+	} else {
+		location, ok := activity["adl:location"].(map[string]interface{})
+		if !ok {
+			fail("adl:activity.adl:location is missing")
+		} else {
+			if locType, _ := location["@type"].(string); !strings.HasPrefix(locType, "saref:") {
+				fail("adl:location @type must be a saref type")
+			}
+			if id, _ := location["@id"].(string); id == "" {
+				fail("adl:location @id is missing")
+			}
+			if name, _ := location["name"].(string); name == "" {
+				fail("adl:location name is missing")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// DocumentVersion returns a document's jeeves:schemaVersion, 1 when absent
+func DocumentVersion(doc map[string]interface{}) int {
+	// Numbers decode from JSON as float64
+	if version, ok := doc["jeeves:schemaVersion"].(float64); ok {
+		return int(version)
+	}
+	if version, ok := doc["jeeves:schemaVersion"].(int); ok {
+		return version
+	}
+	return 1
+}
+
+func timestampField(doc map[string]interface{}, field string) (time.Time, bool) {
+	value, ok := doc[field].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339, value)
+	return ts, err == nil
+}
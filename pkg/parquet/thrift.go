@@ -0,0 +1,168 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type codes
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactWriter encodes the parquet.thrift structures the writer needs with the
+// Thrift compact protocol
+type compactWriter struct {
+	buf    bytes.Buffer
+	lastID []int16 // last field id per open struct
+}
+
+func (c *compactWriter) beginStruct() {
+	c.lastID = append(c.lastID, 0)
+}
+
+func (c *compactWriter) endStruct() {
+	c.buf.WriteByte(0) // stop field
+	c.lastID = c.lastID[:len(c.lastID)-1]
+}
+
+func (c *compactWriter) fieldHeader(id int16, typ byte) {
+	last := &c.lastID[len(c.lastID)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		c.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		c.buf.WriteByte(typ)
+		c.varint(int64(id))
+	}
+	*last = id
+}
+
+func (c *compactWriter) varint(v int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v) // zigzag
+	c.buf.Write(tmp[:n])
+}
+
+func (c *compactWriter) i32(id int16, v int32) {
+	c.fieldHeader(id, compactI32)
+	c.varint(int64(v))
+}
+
+func (c *compactWriter) i64(id int16, v int64) {
+	c.fieldHeader(id, compactI64)
+	c.varint(v)
+}
+
+func (c *compactWriter) str(id int16, v string) {
+	c.fieldHeader(id, compactBinary)
+	c.stringValue(v)
+}
+
+func (c *compactWriter) stringValue(v string) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(len(v)))
+	c.buf.Write(tmp[:n])
+	c.buf.WriteString(v)
+}
+
+func (c *compactWriter) listHeader(id int16, elemType byte, size int) {
+	c.fieldHeader(id, compactList)
+	if size < 15 {
+		c.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	c.buf.WriteByte(0xF0 | elemType)
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(size))
+	c.buf.Write(tmp[:n])
+}
+
+func (c *compactWriter) structField(id int16) {
+	c.fieldHeader(id, compactStruct)
+	c.beginStruct()
+}
+
+// writePageHeader writes a PageHeader for an uncompressed data page (v1)
+func (c *compactWriter) writePageHeader(numValues, size int) {
+	c.beginStruct()
+	c.i32(1, pageTypeData)
+	c.i32(2, int32(size)) // uncompressed_page_size
+	c.i32(3, int32(size)) // compressed_page_size
+
+	c.structField(5) // data_page_header
+	c.i32(1, int32(numValues))
+	c.i32(2, encodingPlain)
+	c.i32(3, encodingRLE) // definition levels
+	c.i32(4, encodingRLE) // repetition levels
+	c.endStruct()
+
+	c.endStruct()
+}
+
+// writeFileMetaData writes the footer describing one row group
+func (c *compactWriter) writeFileMetaData(columns []Column, chunks []columnChunk, numRows int64) {
+	c.beginStruct()
+	c.i32(1, 1) // version
+
+	// Schema: root element followed by one leaf per column
+	c.listHeader(2, compactStruct, len(columns)+1)
+	c.beginStruct()
+	c.str(4, "schema")
+	c.i32(5, int32(len(columns)))
+	c.endStruct()
+	for _, col := range columns {
+		c.beginStruct()
+		c.i32(1, physicalType(col.Type))
+		c.i32(3, repetitionOptional)
+		c.str(4, col.Name)
+		switch col.Type {
+		case String:
+			c.i32(6, convertedUTF8)
+		case Timestamp:
+			c.i32(6, convertedTimestampMillis)
+		}
+		c.endStruct()
+	}
+
+	c.i64(3, numRows)
+
+	// Row groups
+	var totalSize int64
+	for _, chunk := range chunks {
+		totalSize += chunk.size
+	}
+	c.listHeader(4, compactStruct, 1)
+	c.beginStruct()
+	c.listHeader(1, compactStruct, len(columns))
+	for i, col := range columns {
+		chunk := chunks[i]
+		c.beginStruct()
+		c.i64(2, chunk.offset) // file_offset
+
+		c.structField(3) // meta_data
+		c.i32(1, physicalType(col.Type))
+		c.listHeader(2, compactI32, 2)
+		c.varint(encodingPlain)
+		c.varint(encodingRLE)
+		c.listHeader(3, compactBinary, 1)
+		c.stringValue(col.Name)
+		c.i32(4, codecUncompressed)
+		c.i64(5, numRows)
+		c.i64(6, chunk.size) // total_uncompressed_size
+		c.i64(7, chunk.size) // total_compressed_size
+		c.i64(9, chunk.offset)
+		c.endStruct()
+
+		c.endStruct()
+	}
+	c.i64(2, totalSize)
+	c.i64(3, numRows)
+	c.endStruct()
+
+	c.str(6, "jeeves-platform")
+	c.endStruct()
+}
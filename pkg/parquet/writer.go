@@ -0,0 +1,223 @@
+// Package parquet writes flat Apache Parquet files for data export.
+//
+// The writer covers what exports need and nothing more: a single row group,
+// optional (nullable) primitive columns, PLAIN encoding and no compression.
+// Rows are buffered in memory until Close.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Type is a column's value type
+type Type int
+
+const (
+	String    Type = iota // UTF-8 byte array
+	Int64                 // 64-bit signed integer
+	Double                // 64-bit float
+	Bool                  // boolean
+	Timestamp             // milliseconds since the Unix epoch (UTC)
+)
+
+// Column describes one column of the file
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Parquet physical and converted type codes (parquet.thrift)
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageTypeData      = 0
+	codecUncompressed = 0
+)
+
+var magic = []byte("PAR1")
+
+// Writer buffers rows and writes them as a Parquet file on Close
+type Writer struct {
+	w       io.Writer
+	columns []Column
+	values  [][]interface{} // per column
+	rows    int
+}
+
+// NewWriter creates a writer for the given columns
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:       w,
+		columns: columns,
+		values:  make([][]interface{}, len(columns)),
+	}
+}
+
+// Write adds a row. Values must match the column types (string, int64, float64,
+// bool, time.Time) or be nil.
+func (w *Writer) Write(row []interface{}) error {
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("row has %d values, expected %d", len(row), len(w.columns))
+	}
+	for i, v := range row {
+		if v != nil && !matchesType(w.columns[i].Type, v) {
+			return fmt.Errorf("column %s: unexpected value type %T", w.columns[i].Name, v)
+		}
+		w.values[i] = append(w.values[i], v)
+	}
+	w.rows++
+	return nil
+}
+
+func matchesType(t Type, v interface{}) bool {
+	switch v.(type) {
+	case string:
+		return t == String
+	case int64:
+		return t == Int64
+	case float64:
+		return t == Double
+	case bool:
+		return t == Bool
+	case time.Time:
+		return t == Timestamp
+	}
+	return false
+}
+
+// Close writes the file. The underlying writer is not closed.
+func (w *Writer) Close() error {
+	var out bytes.Buffer
+	out.Write(magic)
+
+	chunks := make([]columnChunk, len(w.columns))
+	for i, col := range w.columns {
+		page := encodePage(col.Type, w.values[i])
+
+		header := &compactWriter{}
+		header.writePageHeader(len(w.values[i]), len(page))
+
+		chunks[i] = columnChunk{
+			offset: int64(out.Len()),
+			size:   int64(header.buf.Len() + len(page)),
+		}
+		out.Write(header.buf.Bytes())
+		out.Write(page)
+	}
+
+	meta := &compactWriter{}
+	meta.writeFileMetaData(w.columns, chunks, int64(w.rows))
+	out.Write(meta.buf.Bytes())
+
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(meta.buf.Len()))
+	out.Write(length[:])
+	out.Write(magic)
+
+	if _, err := w.w.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("failed to write parquet file: %w", err)
+	}
+	return nil
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+// encodePage encodes a data page body: definition levels followed by the
+// PLAIN-encoded non-null values
+func encodePage(t Type, values []interface{}) []byte {
+	var levels bytes.Buffer
+	for i := 0; i < len(values); {
+		// RLE runs of identical definition levels (1 = present, 0 = null)
+		present := values[i] != nil
+		run := 1
+		for i+run < len(values) && (values[i+run] != nil) == present {
+			run++
+		}
+		writeUvarint(&levels, uint64(run)<<1)
+		if present {
+			levels.WriteByte(1)
+		} else {
+			levels.WriteByte(0)
+		}
+		i += run
+	}
+
+	var page bytes.Buffer
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(levels.Len()))
+	page.Write(length[:])
+	page.Write(levels.Bytes())
+
+	var bits byte
+	var nbits uint
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		switch t {
+		case String:
+			s := v.(string)
+			binary.LittleEndian.PutUint32(length[:], uint32(len(s)))
+			page.Write(length[:])
+			page.WriteString(s)
+		case Int64:
+			binary.Write(&page, binary.LittleEndian, v.(int64))
+		case Double:
+			binary.Write(&page, binary.LittleEndian, math.Float64bits(v.(float64)))
+		case Timestamp:
+			binary.Write(&page, binary.LittleEndian, v.(time.Time).UnixMilli())
+		case Bool:
+			// Bit-packed, least significant bit first
+			if v.(bool) {
+				bits |= 1 << nbits
+			}
+			if nbits++; nbits == 8 {
+				page.WriteByte(bits)
+				bits, nbits = 0, 0
+			}
+		}
+	}
+	if nbits > 0 {
+		page.WriteByte(bits)
+	}
+
+	return page.Bytes()
+}
+
+func physicalType(t Type) int32 {
+	switch t {
+	case Int64, Timestamp:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	case Bool:
+		return physicalBoolean
+	default:
+		return physicalByteArray
+	}
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	buf.Write(tmp[:n])
+}
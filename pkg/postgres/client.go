@@ -49,7 +49,15 @@ func (c *PostgresClient) Connect(ctx context.Context) error {
 	// Test connection
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return fmt.Errorf("failed to ping postgres: %w", err)
+		return fmt.Errorf("failed to ping postgres: %w", Classify(err))
+	}
+
+	// Household scoping relies on row security, which this role must not bypass
+	if c.config.HouseholdID != "" {
+		if err := c.checkRowSecurity(ctx, db); err != nil {
+			db.Close()
+			return err
+		}
 	}
 
 	c.db = db
@@ -89,17 +97,19 @@ func (c *PostgresClient) IsConnected() bool {
 // Exec executes a query without returning rows
 func (c *PostgresClient) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	if c.db == nil {
-		return nil, fmt.Errorf("postgres client not connected")
+		return nil, errNotConnected
 	}
-	return c.db.ExecContext(ctx, query, args...)
+	result, err := c.db.ExecContext(ctx, query, args...)
+	return result, Classify(err)
 }
 
 // Query executes a query that returns rows
 func (c *PostgresClient) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	if c.db == nil {
-		return nil, fmt.Errorf("postgres client not connected")
+		return nil, errNotConnected
 	}
-	return c.db.QueryContext(ctx, query, args...)
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	return rows, Classify(err)
 }
 
 // QueryRow executes a query that returns a single row
@@ -114,23 +124,23 @@ func (c *PostgresClient) QueryRow(ctx context.Context, query string, args ...int
 // Transaction executes a function within a database transaction
 func (c *PostgresClient) Transaction(ctx context.Context, fn func(*sql.Tx) error) error {
 	if c.db == nil {
-		return fmt.Errorf("postgres client not connected")
+		return errNotConnected
 	}
 
 	tx, err := c.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", Classify(err))
 	}
 
 	if err := fn(tx); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("failed to rollback transaction: %v (original error: %w)", rbErr, err)
+			return fmt.Errorf("failed to rollback transaction: %v (original error: %w)", rbErr, Classify(err))
 		}
-		return err
+		return Classify(err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", Classify(err))
 	}
 
 	return nil
@@ -139,7 +149,23 @@ func (c *PostgresClient) Transaction(ctx context.Context, fn func(*sql.Tx) error
 // Ping tests the database connection
 func (c *PostgresClient) Ping(ctx context.Context) error {
 	if c.db == nil {
-		return fmt.Errorf("postgres client not connected")
+		return errNotConnected
 	}
-	return c.db.PingContext(ctx)
+	return Classify(c.db.PingContext(ctx))
+}
+
+// checkRowSecurity fails when the connection's role bypasses row security,
+// in which case household scoping would not hide other households' rows
+func (c *PostgresClient) checkRowSecurity(ctx context.Context, db *sql.DB) error {
+	var bypass bool
+	err := db.QueryRowContext(ctx,
+		`SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypass)
+	if err != nil {
+		return fmt.Errorf("failed to check row security bypass: %w", Classify(err))
+	}
+	if bypass {
+		return fmt.Errorf("postgres role %q bypasses row security; household %q needs a role without SUPERUSER or BYPASSRLS",
+			c.config.PostgresUser, c.config.HouseholdID)
+	}
+	return nil
 }
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/lib/pq"
+
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+)
+
+// Error classes returned by Client and Classify, see package errcode
+var (
+	ErrNotFound   = errcode.ErrNotFound   // sql.ErrNoRows
+	ErrTransient  = errcode.ErrTransient  // connection failures, timeouts, deadlocks and serialization failures
+	ErrValidation = errcode.ErrValidation // data exceptions and constraint violations
+
+	errNotConnected = errcode.New(errcode.ErrTransient, "postgres client not connected")
+)
+
+// Classify returns err marked with its class, for code that runs queries on
+// the *sql.DB or *sql.Tx directly. Unrecognized errors are returned as they
+// are.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return errcode.Wrap(ErrNotFound, err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", // connection exception
+			"40", // transaction rollback (deadlock, serialization failure)
+			"53", // insufficient resources
+			"57": // operator intervention (shutdown, query canceled)
+			return errcode.Wrap(ErrTransient, err)
+		case "22", // data exception
+			"23": // integrity constraint violation
+			return errcode.Wrap(ErrValidation, err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return errcode.Wrap(ErrTransient, err)
+	}
+
+	return err
+}
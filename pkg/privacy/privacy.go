@@ -0,0 +1,107 @@
+// Package privacy decides which sensor data Jeeves may keep. Locations and
+// sensor types listed in JEEVES_PRIVACY_EXCLUDED_LOCATIONS and
+// JEEVES_PRIVACY_EXCLUDED_SENSOR_TYPES are privacy zones: their data is never
+// stored beyond the short motion history the occupancy agent needs, never
+// turned into episodes or patterns and never sent to the LLM.
+package privacy
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/saaga0h/jeeves-platform/pkg/config"
+)
+
+// TransientSensorType is the one sensor type still kept (briefly) for
+// excluded locations, so their occupancy can be tracked
+const TransientSensorType = "motion"
+
+// Zones is the set of excluded locations and sensor types. A nil *Zones
+// excludes nothing, so callers need not check whether zoning is configured.
+type Zones struct {
+	locations   map[string]bool
+	sensorTypes map[string]bool
+}
+
+// NewZones returns the zones configured in cfg, or nil when none are
+func NewZones(cfg *config.Config) *Zones {
+	if len(cfg.PrivacyExcludedLocations) == 0 && len(cfg.PrivacyExcludedSensorTypes) == 0 {
+		return nil
+	}
+
+	z := &Zones{
+		locations:   make(map[string]bool),
+		sensorTypes: make(map[string]bool),
+	}
+	for _, location := range cfg.PrivacyExcludedLocations {
+		z.locations[location] = true
+	}
+	for _, sensorType := range cfg.PrivacyExcludedSensorTypes {
+		z.sensorTypes[sensorType] = true
+	}
+	return z
+}
+
+// ExcludesLocation reports whether location is a privacy zone
+func (z *Zones) ExcludesLocation(location string) bool {
+	return z != nil && z.locations[location]
+}
+
+// ExcludesSensorType reports whether sensorType is never kept
+func (z *Zones) ExcludesSensorType(sensorType string) bool {
+	return z != nil && z.sensorTypes[sensorType]
+}
+
+// Excludes reports whether data of sensorType from location must not be kept
+func (z *Zones) Excludes(sensorType, location string) bool {
+	return z.ExcludesSensorType(sensorType) || z.ExcludesLocation(location)
+}
+
+// ExcludesTopic reports whether a sensor topic
+// (automation/{raw,sensor}/{sensor_type}/{location}) belongs to a privacy zone
+func (z *Zones) ExcludesTopic(topic string) bool {
+	parts := strings.Split(topic, "/")
+	if len(parts) < 4 {
+		return false
+	}
+	return z.Excludes(parts[2], parts[3])
+}
+
+// FilterLocations returns locations without the excluded ones
+func (z *Zones) FilterLocations(locations []string) []string {
+	if z == nil || len(z.locations) == 0 {
+		return locations
+	}
+	kept := make([]string, 0, len(locations))
+	for _, location := range locations {
+		if !z.locations[location] {
+			kept = append(kept, location)
+		}
+	}
+	return kept
+}
+
+// Locations returns the excluded locations, sorted
+func (z *Zones) Locations() []string {
+	if z == nil {
+		return nil
+	}
+	return sortedSet(z.locations)
+}
+
+// SensorTypes returns the excluded sensor types, sorted
+func (z *Zones) SensorTypes() []string {
+	if z == nil {
+		return nil
+	}
+	return sortedSet(z.sensorTypes)
+}
+
+func sortedSet(set map[string]bool) []string {
+	items := make([]string, 0, len(set))
+	for item := range set {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return items
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/saaga0h/jeeves-platform/pkg/config"
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+	"strconv"
 )
 
 // redisClient implements the Client interface using go-redis
@@ -38,7 +40,7 @@ func NewClient(cfg *config.Config, logger *slog.Logger) Client {
 func (r *redisClient) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	err := r.client.Set(ctx, key, value, ttl).Err()
 	if err != nil {
-		return fmt.Errorf("failed to set key %s: %w", key, err)
+		return fmt.Errorf("failed to set key %s: %w", key, classify(err))
 	}
 	return nil
 }
@@ -47,10 +49,10 @@ func (r *redisClient) Set(ctx context.Context, key string, value interface{}, tt
 func (r *redisClient) Get(ctx context.Context, key string) (string, error) {
 	val, err := r.client.Get(ctx, key).Result()
 	if err == redis.Nil {
-		return "", fmt.Errorf("key %s does not exist", key)
+		return "", errcode.Wrap(ErrNotFound, fmt.Errorf("key %s does not exist", key))
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to get key %s: %w", key, err)
+		return "", fmt.Errorf("failed to get key %s: %w", key, classify(err))
 	}
 	return val, nil
 }
@@ -59,7 +61,7 @@ func (r *redisClient) Get(ctx context.Context, key string) (string, error) {
 func (r *redisClient) HSet(ctx context.Context, key string, field string, value interface{}) error {
 	err := r.client.HSet(ctx, key, field, value).Err()
 	if err != nil {
-		return fmt.Errorf("failed to set hash field %s:%s: %w", key, field, err)
+		return fmt.Errorf("failed to set hash field %s:%s: %w", key, field, classify(err))
 	}
 	return nil
 }
@@ -68,10 +70,10 @@ func (r *redisClient) HSet(ctx context.Context, key string, field string, value
 func (r *redisClient) HGet(ctx context.Context, key string, field string) (string, error) {
 	val, err := r.client.HGet(ctx, key, field).Result()
 	if err == redis.Nil {
-		return "", fmt.Errorf("hash field %s:%s does not exist", key, field)
+		return "", errcode.Wrap(ErrNotFound, fmt.Errorf("hash field %s:%s does not exist", key, field))
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to get hash field %s:%s: %w", key, field, err)
+		return "", fmt.Errorf("failed to get hash field %s:%s: %w", key, field, classify(err))
 	}
 	return val, nil
 }
@@ -80,7 +82,7 @@ func (r *redisClient) HGet(ctx context.Context, key string, field string) (strin
 func (r *redisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
 	val, err := r.client.HGetAll(ctx, key).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get hash %s: %w", key, err)
+		return nil, fmt.Errorf("failed to get hash %s: %w", key, classify(err))
 	}
 	return val, nil
 }
@@ -92,16 +94,25 @@ func (r *redisClient) ZAdd(ctx context.Context, key string, score float64, membe
 		Member: member,
 	}).Err()
 	if err != nil {
-		return fmt.Errorf("failed to add to sorted set %s: %w", key, err)
+		return fmt.Errorf("failed to add to sorted set %s: %w", key, classify(err))
 	}
 	return nil
 }
 
+// ZRem removes members from a sorted set and returns how many were removed
+func (r *redisClient) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	removed, err := r.client.ZRem(ctx, key, members...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove from sorted set %s: %w", key, classify(err))
+	}
+	return removed, nil
+}
+
 // ZRemRangeByScore removes members with scores between min and max
 func (r *redisClient) ZRemRangeByScore(ctx context.Context, key string, min, max string) error {
 	err := r.client.ZRemRangeByScore(ctx, key, min, max).Err()
 	if err != nil {
-		return fmt.Errorf("failed to remove from sorted set %s: %w", key, err)
+		return fmt.Errorf("failed to remove from sorted set %s: %w", key, classify(err))
 	}
 	return nil
 }
@@ -110,7 +121,7 @@ func (r *redisClient) ZRemRangeByScore(ctx context.Context, key string, min, max
 func (r *redisClient) ZCard(ctx context.Context, key string) (int64, error) {
 	count, err := r.client.ZCard(ctx, key).Result()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get cardinality of sorted set %s: %w", key, err)
+		return 0, fmt.Errorf("failed to get cardinality of sorted set %s: %w", key, classify(err))
 	}
 	return count, nil
 }
@@ -122,7 +133,7 @@ func (r *redisClient) ZRangeByScoreWithScores(ctx context.Context, key string, m
 		Max: fmt.Sprintf("%f", max),
 	}).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to query sorted set %s: %w", key, err)
+		return nil, fmt.Errorf("failed to query sorted set %s: %w", key, classify(err))
 	}
 
 	members := make([]ZMember, len(results))
@@ -139,7 +150,7 @@ func (r *redisClient) ZRangeByScoreWithScores(ctx context.Context, key string, m
 func (r *redisClient) Keys(ctx context.Context, pattern string) ([]string, error) {
 	keys, err := r.client.Keys(ctx, pattern).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get keys for pattern %s: %w", pattern, err)
+		return nil, fmt.Errorf("failed to get keys for pattern %s: %w", pattern, classify(err))
 	}
 	return keys, nil
 }
@@ -148,7 +159,7 @@ func (r *redisClient) Keys(ctx context.Context, pattern string) ([]string, error
 func (r *redisClient) LPush(ctx context.Context, key string, values ...interface{}) error {
 	err := r.client.LPush(ctx, key, values...).Err()
 	if err != nil {
-		return fmt.Errorf("failed to push to list %s: %w", key, err)
+		return fmt.Errorf("failed to push to list %s: %w", key, classify(err))
 	}
 	return nil
 }
@@ -157,7 +168,7 @@ func (r *redisClient) LPush(ctx context.Context, key string, values ...interface
 func (r *redisClient) LTrim(ctx context.Context, key string, start, stop int64) error {
 	err := r.client.LTrim(ctx, key, start, stop).Err()
 	if err != nil {
-		return fmt.Errorf("failed to trim list %s: %w", key, err)
+		return fmt.Errorf("failed to trim list %s: %w", key, classify(err))
 	}
 	return nil
 }
@@ -166,7 +177,7 @@ func (r *redisClient) LTrim(ctx context.Context, key string, start, stop int64)
 func (r *redisClient) LLen(ctx context.Context, key string) (int64, error) {
 	length, err := r.client.LLen(ctx, key).Result()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get length of list %s: %w", key, err)
+		return 0, fmt.Errorf("failed to get length of list %s: %w", key, classify(err))
 	}
 	return length, nil
 }
@@ -175,11 +186,20 @@ func (r *redisClient) LLen(ctx context.Context, key string) (int64, error) {
 func (r *redisClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
 	values, err := r.client.LRange(ctx, key, start, stop).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get range from list %s: %w", key, err)
+		return nil, fmt.Errorf("failed to get range from list %s: %w", key, classify(err))
 	}
 	return values, nil
 }
 
+// LRem removes up to count occurrences of value from a list
+func (r *redisClient) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
+	removed, err := r.client.LRem(ctx, key, count, value).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove from list %s: %w", key, classify(err))
+	}
+	return removed, nil
+}
+
 // ZRevRangeByScoreWithScores returns members in a sorted set within a score range with their scores (reverse order - highest first)
 func (r *redisClient) ZRevRangeByScoreWithScores(ctx context.Context, key string, max, min float64, offset, count int64) ([]ZMember, error) {
 	results, err := r.client.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
@@ -189,7 +209,7 @@ func (r *redisClient) ZRevRangeByScoreWithScores(ctx context.Context, key string
 		Count:  count,
 	}).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to query sorted set %s: %w", key, err)
+		return nil, fmt.Errorf("failed to query sorted set %s: %w", key, classify(err))
 	}
 
 	members := make([]ZMember, len(results))
@@ -206,16 +226,83 @@ func (r *redisClient) ZRevRangeByScoreWithScores(ctx context.Context, key string
 func (r *redisClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
 	err := r.client.Expire(ctx, key, ttl).Err()
 	if err != nil {
-		return fmt.Errorf("failed to set expiration on key %s: %w", key, err)
+		return fmt.Errorf("failed to set expiration on key %s: %w", key, classify(err))
 	}
 	return nil
 }
 
+// Del deletes keys and returns how many existed
+func (r *redisClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	deleted, err := r.client.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete keys: %w", classify(err))
+	}
+	return deleted, nil
+}
+
+// SetNX sets a key with a TTL only if it does not exist and reports whether it did
+func (r *redisClient) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	set, err := r.client.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to set key %s: %w", key, classify(err))
+	}
+	return set, nil
+}
+
+// compareAndDelete, compareAndExpire and compareAndZRem check the value and
+// act in one step, so an owner never removes or extends a key another owner
+// has taken since
+var (
+	compareAndDelete = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+	compareAndExpire = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	compareAndZRem = redis.NewScript(`
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if score and tonumber(score) == tonumber(ARGV[2]) then
+	return redis.call("ZREM", KEYS[1], ARGV[1])
+end
+return 0`)
+)
+
+// CompareAndDelete deletes a key only if it holds value and reports whether it did
+func (r *redisClient) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	n, err := compareAndDelete.Run(ctx, r.client, []string{key}, value).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete key %s: %w", key, classify(err))
+	}
+	return n == 1, nil
+}
+
+// CompareAndExpire sets a TTL on a key only if it holds value and reports whether it did
+func (r *redisClient) CompareAndExpire(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	n, err := compareAndExpire.Run(ctx, r.client, []string{key}, value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to set expiration on key %s: %w", key, classify(err))
+	}
+	return n == 1, nil
+}
+
+// CompareAndZRem removes a sorted set member only if it still has score and reports whether it did
+func (r *redisClient) CompareAndZRem(ctx context.Context, key string, member string, score float64) (bool, error) {
+	n, err := compareAndZRem.Run(ctx, r.client, []string{key}, member, strconv.FormatFloat(score, 'f', -1, 64)).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove member from %s: %w", key, classify(err))
+	}
+	return n == 1, nil
+}
+
 // Ping checks the connection to Redis
 func (r *redisClient) Ping(ctx context.Context) error {
 	err := r.client.Ping(ctx).Err()
 	if err != nil {
-		return fmt.Errorf("redis ping failed: %w", err)
+		return fmt.Errorf("redis ping failed: %w", classify(err))
 	}
 	r.logger.Info("Connected to Redis", "address", r.cfg.RedisAddress())
 	return nil
@@ -0,0 +1,25 @@
+package redis
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/saaga0h/jeeves-platform/pkg/errcode"
+)
+
+// Error classes returned by Client, see package errcode
+var (
+	ErrNotFound  = errcode.ErrNotFound  // Get or HGet of a missing key or field
+	ErrTransient = errcode.ErrTransient // connection failures and timeouts
+)
+
+// classify marks failures to reach Redis as transient. Replies from the
+// server, such as WRONGTYPE, are left unclassified.
+func classify(err error) error {
+	var reply redis.Error
+	if errors.As(err, &reply) {
+		return err
+	}
+	return errcode.Wrap(ErrTransient, err)
+}
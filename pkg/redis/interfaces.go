@@ -31,6 +31,9 @@ type Client interface {
 	// ZAdd adds a member with a score to a sorted set
 	ZAdd(ctx context.Context, key string, score float64, member interface{}) error
 
+	// ZRem removes members from a sorted set and returns how many were removed
+	ZRem(ctx context.Context, key string, members ...interface{}) (int64, error)
+
 	// ZRemRangeByScore removes members with scores between min and max
 	ZRemRangeByScore(ctx context.Context, key string, min, max string) error
 
@@ -55,9 +58,27 @@ type Client interface {
 	// LRange returns a range of elements from a list
 	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
 
+	// LRem removes up to count occurrences of value from a list and returns how many were removed
+	LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error)
+
 	// Expire sets a TTL on a key
 	Expire(ctx context.Context, key string, ttl time.Duration) error
 
+	// Del deletes keys and returns how many existed
+	Del(ctx context.Context, keys ...string) (int64, error)
+
+	// SetNX sets a key with a TTL only if it does not exist and reports whether it did
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+
+	// CompareAndDelete deletes a key only if it holds value and reports whether it did
+	CompareAndDelete(ctx context.Context, key string, value string) (bool, error)
+
+	// CompareAndExpire sets a TTL on a key only if it holds value and reports whether it did
+	CompareAndExpire(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+
+	// CompareAndZRem removes a sorted set member only if it still has score and reports whether it did
+	CompareAndZRem(ctx context.Context, key string, member string, score float64) (bool, error)
+
 	// ZRevRangeByScoreWithScores returns members in a sorted set within a score range with their scores (reverse order - highest first)
 	ZRevRangeByScoreWithScores(ctx context.Context, key string, max, min float64, offset, count int64) ([]ZMember, error)
 
@@ -22,6 +22,18 @@ func EnvironmentalSensorKey(location string) string {
 	return fmt.Sprintf("sensor:environmental:%s", location)
 }
 
+// PresenceSensorKey returns the key for occupant presence data (sorted set)
+// Pattern: sensor:presence:{location}
+func PresenceSensorKey(location string) string {
+	return fmt.Sprintf("sensor:presence:%s", location)
+}
+
+// PowerSensorKey returns the key for power metering data (sorted set)
+// Pattern: sensor:power:{location}
+func PowerSensorKey(location string) string {
+	return fmt.Sprintf("sensor:power:%s", location)
+}
+
 // GenericSensorKey returns the key for generic sensor data (list)
 // Pattern: sensor:{sensor_type}:{location}
 func GenericSensorKey(sensorType, location string) string {
@@ -33,3 +45,19 @@ func GenericSensorKey(sensorType, location string) string {
 func GenericMetaKey(sensorType, location string) string {
 	return fmt.Sprintf("meta:%s:%s", sensorType, location)
 }
+
+// WeatherCurrentKey holds the latest normalized weather (string, JSON)
+// Pattern: weather:current
+const WeatherCurrentKey = "weather:current"
+
+// JobLockKey holds the lease of the agent running a job (string, holder ID
+// with a TTL)
+// Pattern: lock:job:{job}
+func JobLockKey(job string) string {
+	return fmt.Sprintf("lock:job:%s", job)
+}
+
+// SensorDLQKey holds sensor messages the collector could not parse (list,
+// JSON entries, newest first)
+// Pattern: dlq:sensor
+const SensorDLQKey = "dlq:sensor"
@@ -0,0 +1,86 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lock errors
+var (
+	ErrLockHeld = errors.New("lock is held by another owner")
+	ErrLockLost = errors.New("lock expired or was taken over")
+)
+
+// LockHeldError names the owner holding a lock; it matches ErrLockHeld
+type LockHeldError struct {
+	Key    string
+	Holder string
+}
+
+func (e *LockHeldError) Error() string {
+	return fmt.Sprintf("lock %s is held by %s", e.Key, e.Holder)
+}
+
+func (e *LockHeldError) Unwrap() error {
+	return ErrLockHeld
+}
+
+// Lock is a lease on a key: held by one owner until released, or until its TTL
+// runs out if the owner stops refreshing it, e.g. because it crashed
+type Lock struct {
+	client Client
+	key    string
+	holder string
+	ttl    time.Duration
+}
+
+// AcquireLock takes the lease on key for owner. When another owner holds it
+// the error is a *LockHeldError.
+func AcquireLock(ctx context.Context, client Client, key, owner string, ttl time.Duration) (*Lock, error) {
+	// The random suffix keeps two acquisitions by the same owner apart
+	holder := owner + "/" + uuid.NewString()
+
+	ok, err := client.SetNX(ctx, key, holder, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if !ok {
+		current, err := client.Get(ctx, key)
+		if err != nil {
+			current = "unknown" // released or expired since, the next attempt may succeed
+		}
+		return nil, &LockHeldError{Key: key, Holder: current}
+	}
+
+	return &Lock{client: client, key: key, holder: holder, ttl: ttl}, nil
+}
+
+// Holder identifies the lease in the key's value
+func (l *Lock) Holder() string {
+	return l.holder
+}
+
+// Refresh extends the lease by its TTL
+func (l *Lock) Refresh(ctx context.Context) error {
+	ok, err := l.client.CompareAndExpire(ctx, l.key, l.holder, l.ttl)
+	if err != nil {
+		return fmt.Errorf("failed to refresh lock %s: %w", l.key, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLockLost, l.key)
+	}
+	return nil
+}
+
+// Release gives up the lease, leaving the key alone if it has expired and
+// been taken by another owner
+func (l *Lock) Release(ctx context.Context) error {
+	if _, err := l.client.CompareAndDelete(ctx, l.key, l.holder); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeLockRedis keeps string keys with expiry times against a manual clock;
+// CompareAndDelete and CompareAndExpire follow the Lua scripts
+type fakeLockRedis struct {
+	Client
+	now     time.Time
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func newFakeLockRedis() *fakeLockRedis {
+	return &fakeLockRedis{
+		now:     time.Date(2025, 10, 17, 8, 0, 0, 0, time.UTC),
+		values:  make(map[string]string),
+		expires: make(map[string]time.Time),
+	}
+}
+
+func (r *fakeLockRedis) get(key string) (string, bool) {
+	if exp, ok := r.expires[key]; ok && !r.now.Before(exp) {
+		delete(r.values, key)
+		delete(r.expires, key)
+	}
+	value, ok := r.values[key]
+	return value, ok
+}
+
+func (r *fakeLockRedis) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if _, ok := r.get(key); ok {
+		return false, nil
+	}
+	r.values[key] = value.(string)
+	r.expires[key] = r.now.Add(ttl)
+	return true, nil
+}
+
+func (r *fakeLockRedis) Get(ctx context.Context, key string) (string, error) {
+	value, ok := r.get(key)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (r *fakeLockRedis) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	if current, ok := r.get(key); !ok || current != value {
+		return false, nil
+	}
+	delete(r.values, key)
+	delete(r.expires, key)
+	return true, nil
+}
+
+func (r *fakeLockRedis) CompareAndExpire(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	if current, ok := r.get(key); !ok || current != value {
+		return false, nil
+	}
+	r.expires[key] = r.now.Add(ttl)
+	return true, nil
+}
+
+func TestAcquireLock_Held(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeLockRedis()
+
+	first, err := AcquireLock(ctx, client, "lock:job", "agent-a", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+
+	_, err = AcquireLock(ctx, client, "lock:job", "agent-b", time.Minute)
+	var held *LockHeldError
+	if !errors.As(err, &held) || !errors.Is(err, ErrLockHeld) {
+		t.Fatalf("Expected a LockHeldError, got %v", err)
+	}
+	if held.Holder != first.Holder() {
+		t.Errorf("Expected holder %s, got %s", first.Holder(), held.Holder)
+	}
+
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := AcquireLock(ctx, client, "lock:job", "agent-b", time.Minute); err != nil {
+		t.Errorf("Expected the released lock to be free, got %v", err)
+	}
+}
+
+func TestLock_SameOwnerHoldersDiffer(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeLockRedis()
+
+	first, err := AcquireLock(ctx, client, "lock:job", "agent-a", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	client.now = client.now.Add(2 * time.Minute)
+	second, err := AcquireLock(ctx, client, "lock:job", "agent-a", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+
+	if first.Holder() == second.Holder() || !strings.HasPrefix(second.Holder(), "agent-a/") {
+		t.Errorf("Expected distinct holders for agent-a, got %s and %s", first.Holder(), second.Holder())
+	}
+}
+
+func TestLock_Refresh(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeLockRedis()
+
+	lock, err := AcquireLock(ctx, client, "lock:job", "agent-a", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+
+	client.now = client.now.Add(45 * time.Second)
+	if err := lock.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if want := client.now.Add(time.Minute); !client.expires["lock:job"].Equal(want) {
+		t.Errorf("Expected the lease to run until %s, got %s", want, client.expires["lock:job"])
+	}
+
+	// Past the original TTL but within the refreshed one
+	client.now = client.now.Add(45 * time.Second)
+	if _, err := AcquireLock(ctx, client, "lock:job", "agent-b", time.Minute); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("Expected the refreshed lock to still be held, got %v", err)
+	}
+}
+
+func TestLock_LostLease(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeLockRedis()
+
+	stale, err := AcquireLock(ctx, client, "lock:job", "agent-a", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+
+	// agent-a stalls past its TTL and agent-b takes over
+	client.now = client.now.Add(2 * time.Minute)
+	current, err := AcquireLock(ctx, client, "lock:job", "agent-b", time.Minute)
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	expires := client.expires["lock:job"]
+
+	if err := stale.Refresh(ctx); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Expected ErrLockLost refreshing a lost lease, got %v", err)
+	}
+	if !client.expires["lock:job"].Equal(expires) {
+		t.Errorf("Expected the new holder's TTL untouched, got %s", client.expires["lock:job"])
+	}
+
+	if err := stale.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if holder, err := client.Get(ctx, "lock:job"); err != nil || holder != current.Holder() {
+		t.Errorf("Expected the lock to stay with %s, got %q (%v)", current.Holder(), holder, err)
+	}
+}
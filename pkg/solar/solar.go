@@ -0,0 +1,139 @@
+// Package solar computes sun position, daylight times and circadian phase
+// for a geographic location.
+package solar
+
+import (
+	"math"
+	"time"
+
+	"github.com/sixdouglas/suncalc"
+)
+
+// Position is the sun's place in the sky
+type Position struct {
+	Elevation float64 // degrees above the horizon (negative below)
+	Azimuth   float64 // compass degrees, 180 = south
+}
+
+// SunPosition returns the sun position at t
+func SunPosition(t time.Time, lat, lon float64) Position {
+	p := suncalc.GetPosition(t, lat, lon)
+	return Position{
+		Elevation: p.Altitude * 180 / math.Pi,
+		Azimuth:   180 + p.Azimuth*180/math.Pi, // suncalc measures from south, westward
+	}
+}
+
+// Times holds the sun events of one day. Events that do not happen (polar
+// day or night) are zero.
+type Times struct {
+	Dawn      time.Time // civil dawn, sun 6° below the horizon
+	Sunrise   time.Time
+	SolarNoon time.Time
+	Sunset    time.Time
+	Dusk      time.Time // civil dusk
+}
+
+// SunTimes returns the sun events for the day containing t
+func SunTimes(t time.Time, lat, lon float64) Times {
+	times := suncalc.GetTimes(t, lat, lon)
+	noon := times[suncalc.SolarNoon].Value
+	noonElevation := SunPosition(noon, lat, lon).Elevation
+	nadirElevation := SunPosition(times[suncalc.Nadir].Value, lat, lon).Elevation
+
+	// An event only happens if the sun crosses its elevation during the day
+	event := func(name suncalc.DayTimeName, elevation float64) time.Time {
+		if noonElevation <= elevation || nadirElevation >= elevation {
+			return time.Time{}
+		}
+		return times[name].Value
+	}
+	return Times{
+		Dawn:      event(suncalc.Dawn, civilTwilight),
+		Sunrise:   event(suncalc.Sunrise, sunriseElevation),
+		SolarNoon: noon,
+		Sunset:    event(suncalc.Sunset, sunriseElevation),
+		Dusk:      event(suncalc.Dusk, civilTwilight),
+	}
+}
+
+// TheoreticalLux approximates clear-sky outdoor illuminance for a sun
+// elevation: about 120,000 lux with the sun overhead, 0 below the horizon
+func TheoreticalLux(elevation float64) float64 {
+	if elevation <= 0 {
+		return 0
+	}
+	return 120000 * math.Sin(elevation*math.Pi/180)
+}
+
+// Phase is a circadian phase of the day, following the sun rather than the clock
+type Phase string
+
+const (
+	PhaseNight     Phase = "night"     // sun more than 6° below the horizon
+	PhaseDawn      Phase = "dawn"      // civil twilight before sunrise
+	PhaseMorning   Phase = "morning"   // sunrise until two hours before solar noon
+	PhaseMidday    Phase = "midday"    // within two hours of solar noon
+	PhaseAfternoon Phase = "afternoon" // two hours after solar noon until sunset
+	PhaseDusk      Phase = "dusk"      // civil twilight after sunset
+)
+
+// Sun elevations (degrees) of the twilight and sunrise/sunset events
+const (
+	civilTwilight    = -6.0
+	sunriseElevation = -0.833 // upper limb on the horizon, with refraction
+)
+
+// middayHalfWidth is how far from solar noon the midday phase extends
+const middayHalfWidth = 2 * time.Hour
+
+// Circadian describes where t falls in the solar day
+type Circadian struct {
+	Phase         Phase
+	Elevation     float64 // current sun elevation
+	NoonElevation float64 // highest sun elevation of the day
+}
+
+// CircadianAt returns the circadian phase at t. Phases are derived from sun
+// elevation, so they also work where the sun never sets or rises.
+func CircadianAt(t time.Time, lat, lon float64) Circadian {
+	// Use the solar noon nearest to t, so early hours after UTC midnight
+	// count as before noon
+	noon := SunTimes(t, lat, lon).SolarNoon
+	if d := t.Sub(noon); d > 12*time.Hour {
+		noon = noon.Add(24 * time.Hour)
+	} else if d < -12*time.Hour {
+		noon = noon.Add(-24 * time.Hour)
+	}
+	elevation := SunPosition(t, lat, lon).Elevation
+	c := Circadian{
+		Elevation:     elevation,
+		NoonElevation: SunPosition(noon, lat, lon).Elevation,
+	}
+
+	beforeNoon := t.Before(noon)
+	switch {
+	case elevation < civilTwilight:
+		c.Phase = PhaseNight
+	case elevation < 0 && beforeNoon:
+		c.Phase = PhaseDawn
+	case elevation < 0:
+		c.Phase = PhaseDusk
+	case t.Sub(noon).Abs() <= middayHalfWidth:
+		c.Phase = PhaseMidday
+	case beforeNoon:
+		c.Phase = PhaseMorning
+	default:
+		c.Phase = PhaseAfternoon
+	}
+	return c
+}
+
+// Intensity is the sun's height relative to today's noon, from 0 (at or
+// below the horizon) to 1 (solar noon)
+func (c Circadian) Intensity() float64 {
+	if c.Elevation <= 0 || c.NoonElevation <= 0 {
+		return 0
+	}
+	return math.Min(1, math.Sin(c.Elevation*math.Pi/180)/math.Sin(c.NoonElevation*math.Pi/180))
+}